@@ -0,0 +1,304 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/federation"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// BanRequest names a key or network to ban or unban. Exactly one of the
+// fields is set.
+type BanRequest struct {
+	Key     string `json:"key,omitempty"`     // Base64 server public key
+	Network string `json:"network,omitempty"` // CIDR prefix or single IP
+}
+
+// BanResponse reports the outcome of a ban or delisting.
+type BanResponse struct {
+	Delisted int                `json:"delisted"`
+	Bans     federation.BanList `json:"bans"`
+}
+
+// RateLimitRequest sets or reports the per-IP rate limit.
+type RateLimitRequest struct {
+	PerMinute int `json:"perMinute"`
+}
+
+// registerAdmin adds the admin endpoints to mux. They are only served when
+// an admin token is set.
+func (s *HubServer) registerAdmin(mux *http.ServeMux) {
+	if s.adminToken == "" {
+		return
+	}
+	mux.HandleFunc("GET /admin/servers", s.withAdmin(s.handleAdminServers))
+	mux.HandleFunc("DELETE /admin/servers/{name}", s.withAdmin(s.handleAdminDelist))
+	mux.HandleFunc("GET /admin/bans", s.withAdmin(s.handleAdminBans))
+	mux.HandleFunc("POST /admin/bans", s.withAdmin(s.handleAdminBan))
+	mux.HandleFunc("DELETE /admin/bans", s.withAdmin(s.handleAdminUnban))
+	mux.HandleFunc("GET /admin/rate-limit", s.withAdmin(s.handleAdminRateLimit))
+	mux.HandleFunc("PUT /admin/rate-limit", s.withAdmin(s.handleAdminRateLimit))
+}
+
+// withAdmin wraps a handler so it requires the admin bearer token. Admin
+// requests are rate limited like any other, which also slows token
+// guessing.
+func (s *HubServer) withAdmin(handler http.HandlerFunc) http.HandlerFunc {
+	want := []byte("Bearer " + s.adminToken)
+	return s.instrument("/admin", s.withRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			logrus.WithField("ip", s.clientIP(r)).Warn("unauthorized admin request")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}))
+}
+
+// handleAdminServers returns the full registry, including the player lists
+// and presence settings that /query redacts.
+func (s *HubServer) handleAdminServers(w http.ResponseWriter, r *http.Request) {
+	servers := s.hub.QueryServers(&federation.ServerQuery{})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(servers); err != nil {
+		logrus.WithError(err).Error("failed to encode admin servers response")
+	}
+}
+
+// handleAdminDelist removes a server from the registry. With ?ban=true it
+// also bans the server's key if it is verified, or else the IP its
+// announcements came from, so it can't simply announce again. The address
+// a server announces is never banned: anyone can announce someone else's.
+func (s *HubServer) handleAdminDelist(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	var target *federation.ServerAnnouncement
+	for _, server := range s.hub.QueryServers(&federation.ServerQuery{}) {
+		if server.Name == name {
+			target = server
+			break
+		}
+	}
+	if target == nil {
+		http.Error(w, "server not found", http.StatusNotFound)
+		return
+	}
+
+	ban := r.URL.Query().Get("ban") == "true"
+	if ban && !target.Verified && target.SourceIP == "" {
+		http.Error(w, "server has no recorded source IP to ban; ban its network instead", http.StatusBadRequest)
+		return
+	}
+
+	var response BanResponse
+	if s.hub.Delist(name) {
+		response.Delisted = 1
+	}
+	if ban {
+		var err error
+		var removed int
+		if target.Verified {
+			removed, err = s.hub.BanKey(base64.StdEncoding.EncodeToString(target.PublicKey))
+		} else {
+			removed, err = s.hub.BanNetwork(target.SourceIP)
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("delisted but not banned: %v", err), http.StatusBadRequest)
+			return
+		}
+		response.Delisted += removed
+		s.saveBans()
+	}
+	response.Bans = s.hub.Bans()
+
+	logrus.WithFields(logrus.Fields{
+		"server_name": name,
+		"ban":         ban,
+	}).Info("admin delisted server")
+
+	writeAdminJSON(w, response)
+}
+
+// handleAdminBans lists the current bans.
+func (s *HubServer) handleAdminBans(w http.ResponseWriter, r *http.Request) {
+	writeAdminJSON(w, s.hub.Bans())
+}
+
+// handleAdminBan bans a key or network and delists the servers it covers.
+func (s *HubServer) handleAdminBan(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeBanRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var removed int
+	var err error
+	if req.Key != "" {
+		removed, err = s.hub.BanKey(req.Key)
+	} else {
+		removed, err = s.hub.BanNetwork(req.Network)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.saveBans()
+
+	logrus.WithFields(logrus.Fields{
+		"key":      req.Key,
+		"network":  req.Network,
+		"delisted": removed,
+	}).Info("admin added ban")
+
+	writeAdminJSON(w, BanResponse{Delisted: removed, Bans: s.hub.Bans()})
+}
+
+// handleAdminUnban lifts a key or network ban.
+func (s *HubServer) handleAdminUnban(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeBanRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var lifted bool
+	if req.Key != "" {
+		lifted = s.hub.UnbanKey(req.Key)
+	} else {
+		lifted = s.hub.UnbanNetwork(req.Network)
+	}
+	if !lifted {
+		http.Error(w, "ban not found", http.StatusNotFound)
+		return
+	}
+	s.saveBans()
+
+	logrus.WithFields(logrus.Fields{
+		"key":     req.Key,
+		"network": req.Network,
+	}).Info("admin lifted ban")
+
+	writeAdminJSON(w, BanResponse{Bans: s.hub.Bans()})
+}
+
+// decodeBanRequest reads a BanRequest, answering 400 if it doesn't name
+// exactly one key or network.
+func decodeBanRequest(w http.ResponseWriter, r *http.Request) (BanRequest, bool) {
+	var req BanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return req, false
+	}
+	if (req.Key == "") == (req.Network == "") {
+		http.Error(w, "exactly one of key and network is required", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// handleAdminRateLimit reports the per-IP rate limit, or changes it for
+// new and existing clients alike.
+func (s *HubServer) handleAdminRateLimit(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPut {
+		var req RateLimitRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PerMinute <= 0 {
+			http.Error(w, "perMinute must be a positive number", http.StatusBadRequest)
+			return
+		}
+		s.SetRateLimit(req.PerMinute)
+		logrus.WithField("per_minute", req.PerMinute).Info("admin changed rate limit")
+	}
+
+	s.mu.Lock()
+	perMinute := s.rateLimit
+	s.mu.Unlock()
+	writeAdminJSON(w, RateLimitRequest{PerMinute: perMinute})
+}
+
+// SetRateLimit changes the per-IP rate limit in requests per minute,
+// including for clients that already have a limiter.
+func (s *HubServer) SetRateLimit(perMinute int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateLimit = perMinute
+	limit := rate.Every(time.Minute / time.Duration(perMinute))
+	for _, entry := range s.rateLimits {
+		entry.limiter.SetLimit(limit)
+		entry.limiter.SetBurst(perMinute)
+	}
+}
+
+// LoadBans restores the bans saved at path and keeps it updated as bans
+// change. A missing file starts with no bans.
+func (s *HubServer) LoadBans(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read bans: %w", err)
+	}
+	if err == nil {
+		var bans federation.BanList
+		if err := json.Unmarshal(data, &bans); err != nil {
+			return fmt.Errorf("failed to parse bans: %w", err)
+		}
+		if err := s.hub.SetBans(bans); err != nil {
+			return err
+		}
+		logrus.WithFields(logrus.Fields{
+			"keys":     len(bans.Keys),
+			"networks": len(bans.Networks),
+		}).Info("bans loaded")
+	}
+	s.mu.Lock()
+	s.bansPath = path
+	s.mu.Unlock()
+	return nil
+}
+
+// saveBans writes the current bans to the bans file, if there is one. The
+// file is replaced atomically so a crash can't leave it half written.
+func (s *HubServer) saveBans() {
+	s.mu.Lock()
+	path := s.bansPath
+	s.mu.Unlock()
+	if path == "" {
+		return
+	}
+
+	data, err := json.MarshalIndent(s.hub.Bans(), "", "  ")
+	if err != nil {
+		logrus.WithError(err).Error("failed to marshal bans")
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".bans-*")
+	if err != nil {
+		logrus.WithError(err).Error("failed to save bans")
+		return
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		logrus.WithError(err).Error("failed to save bans")
+	}
+}
+
+// writeAdminJSON encodes an admin response.
+func writeAdminJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.WithError(err).Error("failed to encode admin response")
+	}
+}
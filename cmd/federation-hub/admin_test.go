@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/federation"
+)
+
+// adminRequest sends an admin API request and decodes a JSON response into
+// out, if given, returning the status code.
+func adminRequest(t *testing.T, method, url, token string, body, out any) int {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, _ := http.NewRequest(method, url, reader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAdminAPI(t *testing.T) {
+	server := NewHubServer("", nil)
+	server.adminToken = "admin-secret"
+	bansFile := filepath.Join(t.TempDir(), "bans.json")
+	if err := server.LoadBans(bansFile); err != nil {
+		t.Fatalf("LoadBans failed: %v", err)
+	}
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Stop()
+	base := "http://" + server.GetAddr()
+
+	// The spammer announces the address of a server it wants banned
+	server.hub.RegisterServer(&federation.ServerAnnouncement{
+		Name:       "spam",
+		Address:    "198.51.100.2:7777",
+		PlayerList: []string{"p1"},
+		SourceIP:   "203.0.113.7",
+		Timestamp:  time.Now(),
+	})
+	server.hub.RegisterServer(&federation.ServerAnnouncement{
+		Name:      "fine",
+		Address:   "198.51.100.2:7777",
+		Timestamp: time.Now(),
+	})
+	server.hub.RegisterServer(&federation.ServerAnnouncement{
+		Name:      "replicated",
+		Address:   "192.0.2.5:7777",
+		Timestamp: time.Now(),
+	})
+
+	if code := adminRequest(t, http.MethodGet, base+"/admin/servers", "", nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := adminRequest(t, http.MethodGet, base+"/admin/servers", "wrong", nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	var servers []federation.ServerAnnouncement
+	if code := adminRequest(t, http.MethodGet, base+"/admin/servers", "admin-secret", nil, &servers); code != http.StatusOK || len(servers) != 3 {
+		t.Fatalf("servers: status = %d, %d servers", code, len(servers))
+	}
+
+	var banned BanResponse
+	if code := adminRequest(t, http.MethodDelete, base+"/admin/servers/spam?ban=true", "admin-secret", nil, &banned); code != http.StatusOK {
+		t.Fatalf("delist: status = %d", code)
+	}
+	if banned.Delisted != 1 || len(banned.Bans.Networks) != 1 || banned.Bans.Networks[0] != "203.0.113.7/32" {
+		t.Errorf("delist = %+v, want spam delisted and its source IP banned", banned)
+	}
+	if code := adminRequest(t, http.MethodDelete, base+"/admin/servers/spam", "admin-secret", nil, nil); code != http.StatusNotFound {
+		t.Errorf("delisting again: status = %d, want %d", code, http.StatusNotFound)
+	}
+	if code := adminRequest(t, http.MethodDelete, base+"/admin/servers/replicated?ban=true", "admin-secret", nil, nil); code != http.StatusBadRequest {
+		t.Errorf("banning without a source IP: status = %d, want %d", code, http.StatusBadRequest)
+	}
+
+	// The banned source can't announce again under any address, and the
+	// server whose address it used is untouched
+	announce := federation.ServerAnnouncement{Name: "spam", Address: "spam.example.com:7777"}
+	if err := server.hub.AnnounceFrom(&announce, net.ParseIP("203.0.113.7")); !errors.Is(err, federation.ErrAddressBanned) {
+		t.Errorf("banned announce: err = %v, want %v", err, federation.ErrAddressBanned)
+	}
+	if servers := server.hub.QueryServers(&federation.ServerQuery{}); len(servers) != 2 {
+		t.Errorf("%d servers left, want fine and replicated", len(servers))
+	}
+
+	if code := adminRequest(t, http.MethodPost, base+"/admin/bans", "admin-secret", BanRequest{Network: "198.51.100.0/24"}, &banned); code != http.StatusOK || banned.Delisted != 1 {
+		t.Errorf("ban network: status = %d, %+v", code, banned)
+	}
+	if code := adminRequest(t, http.MethodPost, base+"/admin/bans", "admin-secret", BanRequest{}, nil); code != http.StatusBadRequest {
+		t.Errorf("empty ban: status = %d, want %d", code, http.StatusBadRequest)
+	}
+	if code := adminRequest(t, http.MethodDelete, base+"/admin/bans", "admin-secret", BanRequest{Network: "198.51.100.0/24"}, nil); code != http.StatusOK {
+		t.Errorf("unban: status = %d", code)
+	}
+
+	// Bans survive a restart through the bans file
+	restarted := NewHubServer("", nil)
+	if err := restarted.LoadBans(bansFile); err != nil {
+		t.Fatalf("LoadBans failed: %v", err)
+	}
+	if bans := restarted.hub.Bans(); len(bans.Networks) != 1 || bans.Networks[0] != "203.0.113.7/32" {
+		t.Errorf("reloaded bans = %+v", bans)
+	}
+	if _, err := os.Stat(bansFile); err != nil {
+		t.Errorf("bans file: %v", err)
+	}
+}
+
+func TestAdminAPI_RateLimit(t *testing.T) {
+	oldRateLimit := *rateLimit
+	*rateLimit = 60
+	defer func() { *rateLimit = oldRateLimit }()
+
+	server := NewHubServer("", nil)
+	server.adminToken = "admin-secret"
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Stop()
+	base := "http://" + server.GetAddr()
+
+	var limit RateLimitRequest
+	if code := adminRequest(t, http.MethodGet, base+"/admin/rate-limit", "admin-secret", nil, &limit); code != http.StatusOK || limit.PerMinute != 60 {
+		t.Errorf("rate limit = %d (status %d), want 60", limit.PerMinute, code)
+	}
+	if code := adminRequest(t, http.MethodPut, base+"/admin/rate-limit", "admin-secret", RateLimitRequest{PerMinute: 0}, nil); code != http.StatusBadRequest {
+		t.Errorf("zero limit: status = %d, want %d", code, http.StatusBadRequest)
+	}
+	if code := adminRequest(t, http.MethodPut, base+"/admin/rate-limit", "admin-secret", RateLimitRequest{PerMinute: 4}, &limit); code != http.StatusOK || limit.PerMinute != 4 {
+		t.Fatalf("set limit = %d (status %d), want 4", limit.PerMinute, code)
+	}
+
+	// The lowered limit caps this client's existing limiter at a burst of 4
+	for i := 0; i < 4; i++ {
+		adminRequest(t, http.MethodGet, base+"/admin/rate-limit", "admin-secret", nil, nil)
+	}
+	if code := adminRequest(t, http.MethodGet, base+"/admin/rate-limit", "admin-secret", nil, nil); code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
+
+func TestAdminAPI_DisabledWithoutToken(t *testing.T) {
+	server := NewHubServer("", nil)
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	if code := adminRequest(t, http.MethodGet, "http://"+server.GetAddr()+"/admin/servers", "", nil, nil); code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", code, http.StatusNotFound)
+	}
+}
@@ -13,6 +13,7 @@
 //   - Optional Authentication: Bearer token protection for server registration
 //   - Hub-to-Hub Peering: Sync server lists between multiple federation hubs
 //   - Health Monitoring: Health check endpoint for load balancers
+//   - Metrics: Prometheus endpoint for registrations, request rates and latencies, and peer sync
 //
 // # Usage
 //
@@ -33,7 +34,10 @@
 //
 //   - addr: HTTP server address (default: :8080)
 //   - auth-token: Optional authentication token for server registration
+//   - admin-token: Bearer token for the /admin endpoints (disabled when empty)
+//   - bans: JSON file the admin API saves bans to
 //   - peers: Comma-separated list of peer hub URLs for syncing
+//   - gossip-interval: How often to gossip with a random peer (default: 30s)
 //   - log-level: Log level: debug, info, warn, error (default: info)
 //   - rate-limit: Rate limit per IP in requests per minute (default: 60)
 //
@@ -47,12 +51,19 @@
 //
 // GET /health - Health check endpoint
 //
+// GET /metrics - Prometheus metrics
+//
 // GET /peers - List configured peer hubs
 //
+// POST /gossip - Exchange changed announcements with a peer hub
+//
+// /admin/... - Delist servers, ban keys and networks, and adjust the rate limit
+//
 // # Hub Peering
 //
-// Multiple hubs can be configured to sync their server registries every 5 minutes,
-// providing redundancy and geographic distribution:
+// Multiple hubs can be configured to replicate their server registries by gossip,
+// exchanging only changed announcements and keeping their origin timestamps so
+// that servers expire on every hub at once:
 //
 //	Hub 1: ./federation-hub -addr=:8080 -peers=http://hub2.example.com:8080
 //	Hub 2: ./federation-hub -addr=:8080 -peers=http://hub1.example.com:8080
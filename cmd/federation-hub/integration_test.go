@@ -189,8 +189,8 @@ func TestFederationHubPeering(t *testing.T) {
 
 	time.Sleep(50 * time.Millisecond)
 
-	// Manually trigger sync (normally happens every 5 minutes)
-	hub2.syncWithPeer(hub1URL)
+	// Manually trigger a gossip round (normally every -gossip-interval)
+	hub2.gossipWithPeer(hub1URL)
 
 	// Verify hub2 has the server from hub1
 	servers := hub2.hub.QueryServers(&federation.ServerQuery{})
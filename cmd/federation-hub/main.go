@@ -3,18 +3,23 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/opd-ai/violence/pkg/federation"
+	"github.com/opd-ai/violence/pkg/federation/sqlstore"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 )
@@ -23,10 +28,19 @@ const version = "6.0.0"
 
 var (
 	addr      = flag.String("addr", ":8080", "HTTP server address")
+	punchAddr = flag.String("punch-addr", ":8081", "UDP address for NAT hole punching and relay (empty to disable)")
 	authToken = flag.String("auth-token", "", "Optional auth token for server registration")
+	adminTok  = flag.String("admin-token", "", "Bearer token for the /admin endpoints (empty disables them)")
+	bansPath  = flag.String("bans", "", "JSON file the admin API saves key and network bans to")
 	peerURLs  = flag.String("peers", "", "Comma-separated list of peer hub URLs for syncing")
+	gossipInt = flag.Duration("gossip-interval", 30*time.Second, "How often to gossip registry changes with a peer hub")
+	dataPath  = flag.String("data", "", "SQLite database for persisting server registrations (empty keeps them in memory)")
+	signed    = flag.Bool("require-signed", false, "Reject announcements that aren't signed with a server key")
+	allowKeys = flag.String("allowed-keys", "", "File of base64 server public keys allowed to announce, one per line")
+	geoIP     = flag.String("geoip", "", "File of cidr,region lines for inferring client and server regions")
 	logLevel  = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	rateLimit = flag.Int("rate-limit", 60, "Rate limit per IP (requests per minute)")
+	proxies   = flag.String("trusted-proxies", "", "Comma-separated IPs or CIDRs of reverse proxies whose X-Forwarded-For and X-Real-IP headers are trusted")
 )
 
 // rateLimiterEntry tracks a rate limiter and its last access time.
@@ -42,6 +56,11 @@ type HubServer struct {
 	peers      []string
 	startTime  time.Time
 	rateLimits map[string]*rateLimiterEntry
+	rateLimit  int // Requests per minute per IP
+	adminToken string
+	bansPath   string
+	proxies    []*net.IPNet
+	metrics    *hubMetrics
 	httpServer *http.Server
 	addr       string
 	ctx        context.Context
@@ -51,10 +70,11 @@ type HubServer struct {
 
 // HealthResponse contains health check information.
 type HealthResponse struct {
-	Status      string `json:"status"`
-	Version     string `json:"version"`
-	Uptime      string `json:"uptime"`
-	ServerCount int    `json:"serverCount"`
+	Status      string                 `json:"status"`
+	Version     string                 `json:"version"`
+	Uptime      string                 `json:"uptime"`
+	ServerCount int                    `json:"serverCount"`
+	Relay       *federation.RelayStats `json:"relay,omitempty"`
 }
 
 // NewHubServer creates a new hub server.
@@ -62,6 +82,7 @@ func NewHubServer(authToken string, peers []string) *HubServer {
 	hub := federation.NewFederationHub()
 	hub.SetStaleTimeout(15 * time.Minute)
 	hub.SetCleanupInterval(1 * time.Minute)
+	hub.SetGossipToken(authToken)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -71,6 +92,8 @@ func NewHubServer(authToken string, peers []string) *HubServer {
 		peers:      peers,
 		startTime:  time.Now(),
 		rateLimits: make(map[string]*rateLimiterEntry),
+		rateLimit:  *rateLimit,
+		metrics:    newHubMetrics(peers),
 		ctx:        ctx,
 		cancel:     cancel,
 	}
@@ -80,12 +103,19 @@ func NewHubServer(authToken string, peers []string) *HubServer {
 func (s *HubServer) Start(addr string) error {
 	mux := http.NewServeMux()
 
-	// Wrap handlers with rate limiting
-	mux.HandleFunc("/announce", s.withRateLimit(s.handleAnnounceHTTP))
-	mux.HandleFunc("/query", s.withRateLimit(s.handleQuery))
-	mux.HandleFunc("/lookup", s.withRateLimit(s.handleLookup))
-	mux.HandleFunc("/health", s.handleHealth)
-	mux.HandleFunc("/peers", s.withRateLimit(s.handlePeers))
+	// Wrap handlers with rate limiting, and time the request-response
+	// endpoints. Subscriptions are long-lived, so they are counted by the
+	// subscriber gauge instead.
+	mux.HandleFunc("/announce", s.instrument("/announce", s.withRateLimit(s.handleAnnounceHTTP)))
+	mux.HandleFunc("/query", s.instrument("/query", s.withRateLimit(s.handleQuery)))
+	mux.HandleFunc("/lookup", s.instrument("/lookup", s.withRateLimit(s.handleLookup)))
+	mux.HandleFunc("/nearest", s.instrument("/nearest", s.withRateLimit(s.handleNearest)))
+	mux.HandleFunc("/subscribe", s.withRateLimit(s.hub.ServeSubscribe))
+	mux.HandleFunc("/health", s.instrument("/health", s.handleHealth))
+	mux.HandleFunc("/peers", s.instrument("/peers", s.withRateLimit(s.handlePeers)))
+	mux.HandleFunc("/gossip", s.instrument("/gossip", s.withRateLimit(s.handleGossip)))
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	s.registerAdmin(mux)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -109,8 +139,8 @@ func (s *HubServer) Start(addr string) error {
 		}
 	}()
 
-	// Start hub-to-hub sync
-	go s.syncWithPeers()
+	// Start hub-to-hub gossip
+	go s.gossipWithPeers()
 
 	// Start cleanup routine
 	go s.runCleanup()
@@ -164,6 +194,30 @@ func (s *HubServer) runCleanup() {
 	}
 }
 
+// StartRendezvous opens the UDP rendezvous that coordinates hole punching
+// between peers and relays for them when punching fails.
+func (s *HubServer) StartRendezvous(addr string) error {
+	if err := s.hub.StartRendezvous(addr); err != nil {
+		return err
+	}
+	logrus.WithField("addr", s.hub.RendezvousAddr()).Info("rendezvous started")
+	return nil
+}
+
+// OpenStore persists server registrations in the SQLite database at path,
+// recovering any that haven't expired since the hub last ran.
+func (s *HubServer) OpenStore(path string) error {
+	store, err := sqlstore.Open(path)
+	if err != nil {
+		return err
+	}
+	if err := s.hub.SetStore(store); err != nil {
+		store.Close()
+		return err
+	}
+	return nil
+}
+
 // GetAddr returns the address the hub is listening on.
 func (s *HubServer) GetAddr() string {
 	return s.addr
@@ -271,13 +325,17 @@ func (s *HubServer) evictOldestEntries(entries []limiterSortEntry, maxLimiters i
 // withRateLimit wraps a handler with rate limiting.
 func (s *HubServer) withRateLimit(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ip := getClientIP(r)
+		ip := s.clientIP(r)
+		if s.hub.IsBannedIP(net.ParseIP(ip)) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
 
 		s.mu.Lock()
 		entry, exists := s.rateLimits[ip]
 		if !exists {
 			entry = &rateLimiterEntry{
-				limiter:    rate.NewLimiter(rate.Every(time.Minute/time.Duration(*rateLimit)), *rateLimit),
+				limiter:    rate.NewLimiter(rate.Every(time.Minute/time.Duration(s.rateLimit)), s.rateLimit),
 				lastAccess: time.Now(),
 			}
 			s.rateLimits[ip] = entry
@@ -289,6 +347,7 @@ func (s *HubServer) withRateLimit(handler http.HandlerFunc) http.HandlerFunc {
 		s.mu.Unlock()
 
 		if !limiter.Allow() {
+			s.metrics.observeRateLimited(r.URL.Path)
 			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
 			logrus.WithField("ip", ip).Warn("rate limit exceeded")
 			return
@@ -309,6 +368,7 @@ func (s *HubServer) handleAnnounceHTTP(w http.ResponseWriter, r *http.Request) {
 	if s.authToken != "" {
 		token := r.Header.Get("Authorization")
 		if token != "Bearer "+s.authToken {
+			s.metrics.observeAnnouncement("unauthorized")
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
 			return
 		}
@@ -316,19 +376,29 @@ func (s *HubServer) handleAnnounceHTTP(w http.ResponseWriter, r *http.Request) {
 
 	var announcement federation.ServerAnnouncement
 	if err := json.NewDecoder(r.Body).Decode(&announcement); err != nil {
+		s.metrics.observeAnnouncement("invalid")
 		http.Error(w, "invalid announcement", http.StatusBadRequest)
 		return
 	}
 
-	announcement.Timestamp = time.Now()
-	s.hub.RegisterServer(&announcement)
+	if err := s.hub.AnnounceFrom(&announcement, net.ParseIP(s.clientIP(r))); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"server_name": announcement.Name,
+			"ip":          s.clientIP(r),
+		}).WithError(err).Warn("announcement rejected")
+		s.metrics.observeAnnouncement("rejected")
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"server_name": announcement.Name,
 		"region":      announcement.Region,
 		"genre":       announcement.Genre,
 		"players":     announcement.Players,
+		"verified":    announcement.Verified,
 	}).Info("server registered")
+	s.metrics.observeAnnouncement("accepted")
 
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(map[string]string{"status": "registered"}); err != nil {
@@ -350,13 +420,39 @@ func (s *HubServer) handleQuery(w http.ResponseWriter, r *http.Request) {
 	}
 
 	servers := s.hub.QueryServers(&query)
+	redacted := make([]*federation.ServerAnnouncement, len(servers))
+	for i, server := range servers {
+		redacted[i] = server.Redacted()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(servers); err != nil {
+	if err := json.NewEncoder(w).Encode(redacted); err != nil {
 		logrus.WithError(err).Error("failed to encode query response")
 	}
 }
 
+// handleNearest returns servers sorted by estimated latency from the
+// client, inferring its region from its IP if it doesn't report one.
+func (s *HubServer) handleNearest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var query federation.ServerQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		http.Error(w, "invalid query", http.StatusBadRequest)
+		return
+	}
+
+	servers := s.hub.NearestServers(&query, net.ParseIP(s.clientIP(r)))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(servers); err != nil {
+		logrus.WithError(err).Error("failed to encode nearest response")
+	}
+}
+
 // handleLookup handles player presence lookup requests.
 func (s *HubServer) handleLookup(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -375,23 +471,8 @@ func (s *HubServer) handleLookup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Look up player by querying all servers
-	servers := s.hub.QueryServers(&federation.ServerQuery{})
-	var response federation.PlayerLookupResponse
-
-	for _, server := range servers {
-		for _, playerID := range server.PlayerList {
-			if playerID == req.PlayerID {
-				response.Online = true
-				response.ServerAddress = server.Address
-				response.ServerName = server.Name
-				break
-			}
-		}
-		if response.Online {
-			break
-		}
-	}
+	// Players hidden from this caller look offline
+	response := s.hub.FindPlayer(&req)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -409,6 +490,10 @@ func (s *HubServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 		Uptime:      uptime.String(),
 		ServerCount: s.hub.GetServerCount(),
 	}
+	if s.hub.RendezvousAddr() != "" {
+		stats := s.hub.RelayStats()
+		response.Relay = &stats
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -433,58 +518,125 @@ func (s *HubServer) handlePeers(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// syncWithPeers periodically syncs server registry with peer hubs.
-func (s *HubServer) syncWithPeers() {
+// handleGossip answers a gossip round from a peer hub. Peers share the
+// hub's auth token, since gossip registers servers just as /announce does
+// and replicates the presence settings /query hides; the hub checks it.
+func (s *HubServer) handleGossip(w http.ResponseWriter, r *http.Request) {
+	s.hub.ServeGossip(w, r)
+}
+
+// gossipWithPeers periodically gossips with a randomly chosen peer hub,
+// so changes spread through the federation without every hub polling
+// every other.
+func (s *HubServer) gossipWithPeers() {
 	if len(s.peers) == 0 {
 		return
 	}
 
-	ticker := time.NewTicker(5 * time.Minute)
+	ticker := time.NewTicker(*gossipInt)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		for _, peerURL := range s.peers {
-			s.syncWithPeer(peerURL)
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.gossipWithPeer(s.peers[rand.Intn(len(s.peers))])
 		}
 	}
 }
 
-// syncWithPeer syncs server registry with a single peer hub.
-func (s *HubServer) syncWithPeer(peerURL string) {
-	servers, err := federation.DiscoverServers(peerURL, &federation.ServerQuery{}, 10*time.Second)
+// gossipWithPeer exchanges changed announcements with a single peer hub.
+// Announcements keep the timestamps their origin hub gave them, so a
+// server that stops announcing expires on every hub.
+func (s *HubServer) gossipWithPeer(peerURL string) {
+	result, err := s.hub.GossipWith(peerURL, s.authToken, 10*time.Second)
+	s.metrics.observePeerSync(peerURL, result, err)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"peer":  peerURL,
 			"error": err,
-		}).Warn("failed to sync with peer")
+		}).Warn("failed to gossip with peer")
 		return
 	}
 
-	for _, server := range servers {
-		// Re-register servers from peer with current timestamp
-		serverCopy := server
-		serverCopy.Timestamp = time.Now()
-		s.hub.RegisterServer(&serverCopy)
+	logrus.WithFields(logrus.Fields{
+		"peer":     peerURL,
+		"received": result.Received,
+		"sent":     result.Sent,
+	}).Debug("gossiped with peer")
+}
+
+// SetTrustedProxies sets the reverse proxies whose forwarding headers the
+// hub believes, as IPs or CIDRs. Anyone can send X-Forwarded-For, so
+// without this the hub only uses a request's remote address.
+func (s *HubServer) SetTrustedProxies(proxies []string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted proxy %q", p)
+			}
+			bits := 8 * len(ip.To16())
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(p)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+		}
+		nets = append(nets, network)
 	}
+	s.proxies = nets
+	return nil
+}
 
-	logrus.WithFields(logrus.Fields{
-		"peer":    peerURL,
-		"servers": len(servers),
-	}).Debug("synced with peer")
+// isTrustedProxy reports whether ip is one of the trusted proxies.
+func (s *HubServer) isTrustedProxy(ip net.IP) bool {
+	for _, network := range s.proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
-// getClientIP extracts the client IP from the request.
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
+// clientIP returns the IP of the client that made the request. Forwarding
+// headers are only used when the request comes from a trusted proxy, and
+// then X-Forwarded-For is read from the right, skipping the proxies' own
+// hops, since a client can put anything on its left.
+func (s *HubServer) clientIP(r *http.Request) string {
+	ip := parseIP(r.RemoteAddr)
+	if ip == nil {
+		return r.RemoteAddr
+	}
+	if !s.isTrustedProxy(ip) {
+		return ip.String()
+	}
+
 	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-		return forwarded
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				break
+			}
+			ip = hop
+			if !s.isTrustedProxy(hop) {
+				break
+			}
+		}
+		return ip.String()
 	}
-	// Check X-Real-IP header
-	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
-		return realIP
+	if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+		return realIP.String()
 	}
-	// Fall back to RemoteAddr
-	return r.RemoteAddr
+	return ip.String()
 }
 
 func main() {
@@ -508,11 +660,48 @@ func main() {
 
 	// Create hub server
 	server := NewHubServer(*authToken, peers)
+	server.adminToken = *adminTok
+	if *proxies != "" {
+		if err := server.SetTrustedProxies(strings.Split(*proxies, ",")); err != nil {
+			logrus.WithError(err).Fatal("failed to parse trusted proxies")
+		}
+	}
+	if *bansPath != "" {
+		if err := server.LoadBans(*bansPath); err != nil {
+			logrus.WithError(err).Fatal("failed to load bans")
+		}
+	}
+	if *dataPath != "" {
+		if err := server.OpenStore(*dataPath); err != nil {
+			logrus.WithError(err).Fatal("failed to open registry store")
+		}
+	}
+	server.hub.SetRequireSigned(*signed)
+	if *geoIP != "" {
+		resolver, err := federation.LoadPrefixResolver(*geoIP)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to load region prefixes")
+		}
+		server.hub.SetRegionResolver(resolver)
+	}
+	if *allowKeys != "" {
+		keys, err := loadAllowedKeys(*allowKeys)
+		if err != nil {
+			logrus.WithError(err).Fatal("failed to load allowed keys")
+		}
+		server.hub.SetAllowedKeys(keys)
+		logrus.WithField("keys", len(keys)).Info("announcement allowlist loaded")
+	}
 
 	// Start server
 	if err := server.Start(*addr); err != nil {
 		logrus.WithError(err).Fatal("failed to start hub server")
 	}
+	if *punchAddr != "" {
+		if err := server.StartRendezvous(*punchAddr); err != nil {
+			logrus.WithError(err).Fatal("failed to start rendezvous")
+		}
+	}
 
 	// Wait for interrupt signal
 	sigChan := make(chan os.Signal, 1)
@@ -542,6 +731,37 @@ func main() {
 	}
 }
 
+// parseIP extracts the IP from an address that may carry a port.
+func parseIP(addr string) net.IP {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// loadAllowedKeys reads base64 server public keys from a file, one per
+// line. Blank lines and lines starting with # are skipped.
+func loadAllowedKeys(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key %q", line)
+		}
+		keys = append(keys, line)
+	}
+	return keys, nil
+}
+
 // splitPeers splits a comma-separated string into a slice of peer URLs.
 func splitPeers(s string) []string {
 	var peers []string
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -83,6 +87,22 @@ func TestHandleHealth(t *testing.T) {
 	if health.ServerCount < 0 {
 		t.Errorf("serverCount = %d, want >= 0", health.ServerCount)
 	}
+	if health.Relay != nil {
+		t.Errorf("relay = %+v without a rendezvous, want none", health.Relay)
+	}
+
+	if err := server.StartRendezvous("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start rendezvous: %v", err)
+	}
+	w = httptest.NewRecorder()
+	server.handleHealth(w, req)
+	health = HealthResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&health); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if health.Relay == nil || health.Relay.Sessions != 0 {
+		t.Errorf("relay = %+v, want an idle relay", health.Relay)
+	}
 }
 
 func TestHandleAnnounceHTTP(t *testing.T) {
@@ -179,6 +199,91 @@ func TestHandleAnnounceHTTP(t *testing.T) {
 	}
 }
 
+func TestHandleAnnounceHTTP_Signed(t *testing.T) {
+	server := NewHubServer("", nil)
+	id, _ := federation.NewServerIdentity()
+	server.hub.SetAllowedKeys([]string{id.PublicKey()})
+
+	post := func(a *federation.ServerAnnouncement) int {
+		data, _ := json.Marshal(a)
+		w := httptest.NewRecorder()
+		server.handleAnnounceHTTP(w, httptest.NewRequest(http.MethodPost, "/announce", bytes.NewReader(data)))
+		return w.Code
+	}
+
+	signed := &federation.ServerAnnouncement{Name: "real", Address: "real:7777"}
+	id.Sign(signed)
+	if code := post(signed); code != http.StatusOK {
+		t.Fatalf("signed announcement: status %d", code)
+	}
+	if code := post(&federation.ServerAnnouncement{Name: "real", Address: "evil:7777"}); code != http.StatusForbidden {
+		t.Errorf("spoofed announcement: status %d, want %d", code, http.StatusForbidden)
+	}
+	servers := server.hub.QueryServers(&federation.ServerQuery{})
+	if len(servers) != 1 || !servers[0].Verified || servers[0].Address != "real:7777" {
+		t.Errorf("servers = %+v", servers)
+	}
+}
+
+func TestHandleNearest(t *testing.T) {
+	server := NewHubServer("", nil)
+	resolver := federation.NewPrefixResolver()
+	resolver.Add("203.0.113.0/24", federation.RegionEUWest)
+	server.hub.SetRegionResolver(resolver)
+	server.hub.RegisterServer(&federation.ServerAnnouncement{Name: "far", Region: federation.RegionAsiaPac, Timestamp: time.Now()})
+	server.hub.RegisterServer(&federation.ServerAnnouncement{Name: "near", Region: federation.RegionEUWest, Timestamp: time.Now()})
+	if err := server.SetTrustedProxies([]string{"192.0.2.1", "10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/nearest", bytes.NewReader([]byte("{}")))
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+	w := httptest.NewRecorder()
+	server.handleNearest(w, req)
+
+	var ranked []federation.RankedServer
+	if err := json.NewDecoder(w.Body).Decode(&ranked); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(ranked) != 2 || ranked[0].Name != "near" || ranked[0].EstimatedPing != 20 {
+		t.Errorf("ranked = %+v", ranked)
+	}
+}
+
+func TestParseIP(t *testing.T) {
+	tests := map[string]string{
+		"192.0.2.1:5000":   "192.0.2.1",
+		"192.0.2.1":        "192.0.2.1",
+		"[2001:db8::1]:80": "2001:db8::1",
+	}
+	for addr, want := range tests {
+		if got := parseIP(addr); got.String() != want {
+			t.Errorf("parseIP(%q) = %v, want %s", addr, got, want)
+		}
+	}
+	if parseIP("not an ip") != nil {
+		t.Error("parseIP() of a hostname should be nil")
+	}
+}
+
+func TestLoadAllowedKeys(t *testing.T) {
+	id, _ := federation.NewServerIdentity()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "keys")
+	os.WriteFile(path, []byte("# hub operators' servers\n\n"+id.PublicKey()+"\n"), 0o600)
+	keys, err := loadAllowedKeys(path)
+	if err != nil || len(keys) != 1 || keys[0] != id.PublicKey() {
+		t.Errorf("loadAllowedKeys() = %v, %v", keys, err)
+	}
+
+	bad := filepath.Join(dir, "bad")
+	os.WriteFile(bad, []byte("not-a-key\n"), 0o600)
+	if _, err := loadAllowedKeys(bad); err == nil {
+		t.Error("loadAllowedKeys() accepted an invalid key")
+	}
+}
+
 func TestHandleQuery(t *testing.T) {
 	server := NewHubServer("", nil)
 	if err := server.Start("127.0.0.1:0"); err != nil {
@@ -275,15 +380,20 @@ func TestHandleLookup(t *testing.T) {
 		Genre:      "horror",
 		Players:    2,
 		MaxPlayers: 16,
-		PlayerList: []string{"player1", "player2"},
-		Timestamp:  time.Now(),
+		PlayerList: []string{"player1", "player2", "hidden"},
+		Presence: map[string]federation.PlayerPresence{
+			"player2": {Visibility: federation.PresenceFriends, FriendTokens: []string{federation.HashFriendToken("friend-token")}},
+			"hidden":  {Visibility: federation.PresenceInvisible},
+		},
+		Timestamp: time.Now(),
 	})
 
 	tests := []struct {
-		name       string
-		playerID   string
-		wantOnline bool
-		wantStatus int
+		name        string
+		playerID    string
+		friendToken string
+		wantOnline  bool
+		wantStatus  int
 	}{
 		{
 			name:       "player online",
@@ -297,6 +407,26 @@ func TestHandleLookup(t *testing.T) {
 			wantOnline: false,
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "friends only without token",
+			playerID:   "player2",
+			wantOnline: false,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "friends only with token",
+			playerID:    "player2",
+			friendToken: "friend-token",
+			wantOnline:  true,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "invisible",
+			playerID:    "hidden",
+			friendToken: "friend-token",
+			wantOnline:  false,
+			wantStatus:  http.StatusOK,
+		},
 		{
 			name:       "empty player id",
 			playerID:   "",
@@ -306,7 +436,7 @@ func TestHandleLookup(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			req := federation.PlayerLookupRequest{PlayerID: tt.playerID}
+			req := federation.PlayerLookupRequest{PlayerID: tt.playerID, FriendToken: tt.friendToken}
 			data, _ := json.Marshal(req)
 			httpReq := httptest.NewRequest(http.MethodPost, "/lookup", bytes.NewReader(data))
 			w := httptest.NewRecorder()
@@ -390,7 +520,85 @@ func TestRateLimit(t *testing.T) {
 	}
 }
 
-func TestGetClientIP(t *testing.T) {
+func TestHandleMetrics(t *testing.T) {
+	oldRateLimit := *rateLimit
+	*rateLimit = 2
+	defer func() { *rateLimit = oldRateLimit }()
+
+	server := NewHubServer("", []string{"http://127.0.0.1:1"})
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer server.Stop()
+
+	base := "http://" + server.GetAddr()
+	announcement := federation.ServerAnnouncement{
+		Name:       "metrics-server",
+		Address:    "127.0.0.1:7777",
+		Region:     federation.RegionEUWest,
+		Genre:      "fantasy",
+		Players:    3,
+		MaxPlayers: 8,
+	}
+	body, _ := json.Marshal(announcement)
+	resp, err := http.Post(base+"/announce", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("announce failed: %v", err)
+	}
+	resp.Body.Close()
+	for i := 0; i < 2; i++ {
+		resp, err := http.Post(base+"/query", "application/json", bytes.NewReader([]byte("{}")))
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+	server.gossipWithPeer("http://127.0.0.1:1")
+
+	resp, err = http.Get(base + "/metrics")
+	if err != nil {
+		t.Fatalf("metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read metrics: %v", err)
+	}
+	metrics := string(data)
+
+	for _, want := range []string{
+		"# TYPE federation_hub_registered_servers gauge\nfederation_hub_registered_servers 1\n",
+		`federation_hub_servers_by_region{region="eu-west"} 1`,
+		`federation_hub_registered_players 3`,
+		`federation_hub_announcements_total{result="accepted"} 1`,
+		`federation_hub_requests_total{endpoint="/announce",code="200"} 1`,
+		`federation_hub_requests_total{endpoint="/query",code="200"} 1`,
+		`federation_hub_requests_total{endpoint="/query",code="429"} 1`,
+		`federation_hub_rate_limited_total{endpoint="/query"} 1`,
+		`federation_hub_request_duration_seconds_bucket{endpoint="/announce",le="+Inf"} 1`,
+		`federation_hub_request_duration_seconds_count{endpoint="/query"} 2`,
+		`federation_hub_peer_up{peer="http://127.0.0.1:1"} 0`,
+		`federation_hub_peer_sync_failures_total{peer="http://127.0.0.1:1"} 1`,
+		`federation_hub_subscribers 0`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics missing %q\n%s", want, metrics)
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	got := labels("peer", `a"b\c`+"\n", "le", "+Inf")
+	want := `{peer="a\"b\\c\n",le="+Inf"}`
+	if got != want {
+		t.Errorf("labels = %s, want %s", got, want)
+	}
+}
+
+func TestClientIP(t *testing.T) {
 	tests := []struct {
 		name       string
 		remoteAddr string
@@ -398,13 +606,31 @@ func TestGetClientIP(t *testing.T) {
 		wantIP     string
 	}{
 		{
-			name:       "x-forwarded-for header",
+			name:       "untrusted x-forwarded-for header",
+			remoteAddr: "198.51.100.7:12345",
+			headers:    map[string]string{"X-Forwarded-For": "192.168.1.1"},
+			wantIP:     "198.51.100.7",
+		},
+		{
+			name:       "untrusted x-real-ip header",
+			remoteAddr: "198.51.100.7:12345",
+			headers:    map[string]string{"X-Real-IP": "192.168.1.2"},
+			wantIP:     "198.51.100.7",
+		},
+		{
+			name:       "x-forwarded-for from proxy",
 			remoteAddr: "10.0.0.1:12345",
 			headers:    map[string]string{"X-Forwarded-For": "192.168.1.1"},
 			wantIP:     "192.168.1.1",
 		},
 		{
-			name:       "x-real-ip header",
+			name:       "spoofed x-forwarded-for entry",
+			remoteAddr: "10.0.0.1:12345",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.66, 192.168.1.1, 10.0.0.2"},
+			wantIP:     "192.168.1.1",
+		},
+		{
+			name:       "x-real-ip from proxy",
 			remoteAddr: "10.0.0.1:12345",
 			headers:    map[string]string{"X-Real-IP": "192.168.1.2"},
 			wantIP:     "192.168.1.2",
@@ -413,10 +639,14 @@ func TestGetClientIP(t *testing.T) {
 			name:       "remote addr fallback",
 			remoteAddr: "10.0.0.1:12345",
 			headers:    map[string]string{},
-			wantIP:     "10.0.0.1:12345",
+			wantIP:     "10.0.0.1",
 		},
 	}
 
+	server := NewHubServer("", nil)
+	if err := server.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -425,14 +655,24 @@ func TestGetClientIP(t *testing.T) {
 				req.Header.Set(k, v)
 			}
 
-			ip := getClientIP(req)
+			ip := server.clientIP(req)
 			if ip != tt.wantIP {
-				t.Errorf("getClientIP() = %q, want %q", ip, tt.wantIP)
+				t.Errorf("clientIP() = %q, want %q", ip, tt.wantIP)
 			}
 		})
 	}
 }
 
+func TestSetTrustedProxies_Invalid(t *testing.T) {
+	server := NewHubServer("", nil)
+	if err := server.SetTrustedProxies([]string{"proxy.example.com"}); err == nil {
+		t.Error("SetTrustedProxies() accepted a hostname")
+	}
+	if err := server.SetTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("SetTrustedProxies() accepted an invalid CIDR")
+	}
+}
+
 func TestSplitPeers(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -477,7 +717,7 @@ func TestSplitPeers(t *testing.T) {
 	}
 }
 
-func TestSyncWithPeer(t *testing.T) {
+func TestGossipWithPeer(t *testing.T) {
 	// Create a mock peer hub
 	peerHub := federation.NewFederationHub()
 	if err := peerHub.Start("127.0.0.1:0"); err != nil {
@@ -488,6 +728,7 @@ func TestSyncWithPeer(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 
 	// Register servers on peer hub
+	announced := time.Now().Add(-5 * time.Minute)
 	peerHub.RegisterServer(&federation.ServerAnnouncement{
 		Name:       "peer-server",
 		Address:    "peer.example.com:7777",
@@ -495,7 +736,7 @@ func TestSyncWithPeer(t *testing.T) {
 		Genre:      "horror",
 		Players:    5,
 		MaxPlayers: 16,
-		Timestamp:  time.Now(),
+		Timestamp:  announced,
 	})
 
 	// Create main hub
@@ -507,19 +748,48 @@ func TestSyncWithPeer(t *testing.T) {
 
 	time.Sleep(50 * time.Millisecond)
 
-	// Sync with peer
+	// Gossip with peer
 	peerURL := "http://" + peerHub.GetAddr()
-	server.syncWithPeer(peerURL)
+	server.gossipWithPeer(peerURL)
 
 	// Check that server was synced
 	servers := server.hub.QueryServers(&federation.ServerQuery{})
 	if len(servers) != 1 {
-		t.Errorf("len(servers) = %d, want 1", len(servers))
+		t.Fatalf("len(servers) = %d, want 1", len(servers))
 	}
 
-	if len(servers) > 0 && servers[0].Name != "peer-server" {
+	if servers[0].Name != "peer-server" {
 		t.Errorf("server name = %q, want %q", servers[0].Name, "peer-server")
 	}
+	if !servers[0].Timestamp.Equal(announced) {
+		t.Errorf("timestamp = %v, want the origin timestamp %v", servers[0].Timestamp, announced)
+	}
+}
+
+func TestHandleGossip_Auth(t *testing.T) {
+	server := NewHubServer("secret", nil)
+	body := []byte(`{"servers":[{"name":"pushed","timestamp":"` + time.Now().Format(time.RFC3339Nano) + `"}]}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/gossip", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	server.handleGossip(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("without token: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if server.hub.GetServerCount() != 0 {
+		t.Error("unauthenticated gossip registered a server")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/gossip", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	server.handleGossip(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("with token: status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if server.hub.GetServerCount() != 1 {
+		t.Error("authenticated gossip did not register the server")
+	}
 }
 
 func TestServerLifecycle(t *testing.T) {
@@ -687,7 +957,7 @@ func TestRateLimiterCleanup_UpdateLastAccess(t *testing.T) {
 	server.withRateLimit(server.handleHealth)(w, req)
 
 	server.mu.Lock()
-	entry, exists := server.rateLimits["192.168.1.100:12345"]
+	entry, exists := server.rateLimits["192.168.1.100"]
 	if !exists {
 		t.Fatal("rate limiter entry not created")
 	}
@@ -702,7 +972,7 @@ func TestRateLimiterCleanup_UpdateLastAccess(t *testing.T) {
 	server.withRateLimit(server.handleHealth)(w, req)
 
 	server.mu.Lock()
-	entry, exists = server.rateLimits["192.168.1.100:12345"]
+	entry, exists = server.rateLimits["192.168.1.100"]
 	if !exists {
 		t.Fatal("rate limiter entry disappeared")
 	}
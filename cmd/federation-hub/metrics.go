@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/federation"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the request latency
+// histogram. They match the Prometheus client defaults.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// requestKey identifies a request counter by endpoint and status code.
+type requestKey struct {
+	endpoint string
+	code     int
+}
+
+// histogram counts observations into cumulative latency buckets.
+type histogram struct {
+	counts []uint64 // One per latencyBuckets entry
+	count  uint64
+	sum    float64
+}
+
+// observe records one observation in seconds.
+func (h *histogram) observe(seconds float64) {
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// peerSyncStatus is the outcome of gossip rounds with a peer hub.
+type peerSyncStatus struct {
+	up          bool
+	lastAttempt time.Time
+	lastSuccess time.Time
+	failures    uint64
+	received    uint64
+	sent        uint64
+}
+
+// hubMetrics collects the counters the hub exports on /metrics. It is safe
+// for concurrent use.
+type hubMetrics struct {
+	mu            sync.Mutex
+	requests      map[requestKey]uint64
+	latencies     map[string]*histogram
+	rateLimited   map[string]uint64
+	announcements map[string]uint64
+	peers         map[string]*peerSyncStatus
+}
+
+// newHubMetrics creates an empty metrics set tracking the given peers.
+func newHubMetrics(peers []string) *hubMetrics {
+	m := &hubMetrics{
+		requests:      make(map[requestKey]uint64),
+		latencies:     make(map[string]*histogram),
+		rateLimited:   make(map[string]uint64),
+		announcements: make(map[string]uint64),
+		peers:         make(map[string]*peerSyncStatus),
+	}
+	for _, peer := range peers {
+		m.peers[peer] = &peerSyncStatus{}
+	}
+	return m
+}
+
+// observeRequest records a finished request.
+func (m *hubMetrics) observeRequest(endpoint string, code int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[requestKey{endpoint: endpoint, code: code}]++
+	h, ok := m.latencies[endpoint]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(latencyBuckets))}
+		m.latencies[endpoint] = h
+	}
+	h.observe(elapsed.Seconds())
+}
+
+// observeRateLimited records a request rejected by the rate limiter.
+func (m *hubMetrics) observeRateLimited(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rateLimited[endpoint]++
+}
+
+// observeAnnouncement records the outcome of an announcement: accepted,
+// rejected, unauthorized or invalid.
+func (m *hubMetrics) observeAnnouncement(result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.announcements[result]++
+}
+
+// observePeerSync records the outcome of a gossip round with a peer hub.
+func (m *hubMetrics) observePeerSync(peer string, result federation.GossipResult, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, ok := m.peers[peer]
+	if !ok {
+		status = &peerSyncStatus{}
+		m.peers[peer] = status
+	}
+	status.lastAttempt = time.Now()
+	status.up = err == nil
+	status.received += uint64(result.Received)
+	status.sent += uint64(result.Sent)
+	if err != nil {
+		status.failures++
+		return
+	}
+	status.lastSuccess = status.lastAttempt
+}
+
+// instrument wraps a handler to count its requests by status code and
+// time them. Endpoint is the route the handler serves.
+func (s *HubServer) instrument(endpoint string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		handler(rec, r)
+		s.metrics.observeRequest(endpoint, rec.code, time.Since(start))
+	}
+}
+
+// statusRecorder remembers the status code a handler wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+// WriteHeader records the status code before writing it.
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// handleMetrics exports the hub's metrics in the Prometheus text format.
+func (s *HubServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	servers := s.hub.QueryServers(&federation.ServerQuery{})
+	verified := 0
+	regions := make(map[string]int)
+	players := 0
+	for _, server := range servers {
+		if server.Verified {
+			verified++
+		}
+		regions[string(server.Region)]++
+		players += server.Players
+	}
+
+	var b strings.Builder
+	writeMetric(&b, "federation_hub_info", "gauge", "Hub version.", sample{labels: labels("version", version), value: 1})
+	writeMetric(&b, "federation_hub_uptime_seconds", "gauge", "Seconds since the hub started.",
+		sample{value: time.Since(s.startTime).Seconds()})
+	writeMetric(&b, "federation_hub_registered_servers", "gauge", "Servers currently registered.",
+		sample{value: float64(len(servers))})
+	writeMetric(&b, "federation_hub_verified_servers", "gauge", "Registered servers with verified signatures.",
+		sample{value: float64(verified)})
+	writeMetric(&b, "federation_hub_registered_players", "gauge", "Players on registered servers.",
+		sample{value: float64(players)})
+
+	regionSamples := make([]sample, 0, len(regions))
+	for _, region := range sortedKeys(regions) {
+		regionSamples = append(regionSamples, sample{labels: labels("region", region), value: float64(regions[region])})
+	}
+	writeMetric(&b, "federation_hub_servers_by_region", "gauge", "Registered servers per region.", regionSamples...)
+	writeMetric(&b, "federation_hub_subscribers", "gauge", "Live registry subscriptions.",
+		sample{value: float64(s.hub.SubscriberCount())})
+
+	if s.hub.RendezvousAddr() != "" {
+		stats := s.hub.RelayStats()
+		writeMetric(&b, "federation_hub_relay_sessions", "gauge", "Active relay sessions.", sample{value: float64(stats.Sessions)})
+		writeMetric(&b, "federation_hub_relay_packets_total", "counter", "Packets relayed.", sample{value: float64(stats.Packets)})
+		writeMetric(&b, "federation_hub_relay_bytes_total", "counter", "Bytes relayed.", sample{value: float64(stats.Bytes)})
+	}
+
+	s.metrics.write(&b)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, b.String())
+}
+
+// write appends the collected counters in the Prometheus text format.
+func (m *hubMetrics) write(b *strings.Builder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].endpoint != keys[j].endpoint {
+			return keys[i].endpoint < keys[j].endpoint
+		}
+		return keys[i].code < keys[j].code
+	})
+	requests := make([]sample, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, sample{
+			labels: labels("endpoint", key.endpoint, "code", fmt.Sprint(key.code)),
+			value:  float64(m.requests[key]),
+		})
+	}
+	writeMetric(b, "federation_hub_requests_total", "counter", "HTTP requests by endpoint and status code.", requests...)
+
+	var latencies []sample
+	for _, endpoint := range sortedKeys(m.latencies) {
+		h := m.latencies[endpoint]
+		for i, bound := range latencyBuckets {
+			latencies = append(latencies, sample{
+				suffix: "_bucket",
+				labels: labels("endpoint", endpoint, "le", fmt.Sprint(bound)),
+				value:  float64(h.counts[i]),
+			})
+		}
+		latencies = append(latencies,
+			sample{suffix: "_bucket", labels: labels("endpoint", endpoint, "le", "+Inf"), value: float64(h.count)},
+			sample{suffix: "_sum", labels: labels("endpoint", endpoint), value: h.sum},
+			sample{suffix: "_count", labels: labels("endpoint", endpoint), value: float64(h.count)},
+		)
+	}
+	writeMetric(b, "federation_hub_request_duration_seconds", "histogram", "HTTP request latency by endpoint.", latencies...)
+
+	var limited []sample
+	for _, endpoint := range sortedKeys(m.rateLimited) {
+		limited = append(limited, sample{labels: labels("endpoint", endpoint), value: float64(m.rateLimited[endpoint])})
+	}
+	writeMetric(b, "federation_hub_rate_limited_total", "counter", "Requests rejected by the per-IP rate limit.", limited...)
+
+	var announcements []sample
+	for _, result := range sortedKeys(m.announcements) {
+		announcements = append(announcements, sample{labels: labels("result", result), value: float64(m.announcements[result])})
+	}
+	writeMetric(b, "federation_hub_announcements_total", "counter", "Server announcements by result.", announcements...)
+
+	var up, lastSuccess, failures, exchanged []sample
+	for _, peer := range sortedKeys(m.peers) {
+		status := m.peers[peer]
+		l := labels("peer", peer)
+		value := 0.0
+		if status.up {
+			value = 1
+		}
+		up = append(up, sample{labels: l, value: value})
+		if !status.lastSuccess.IsZero() {
+			lastSuccess = append(lastSuccess, sample{labels: l, value: float64(status.lastSuccess.Unix())})
+		}
+		failures = append(failures, sample{labels: l, value: float64(status.failures)})
+		exchanged = append(exchanged,
+			sample{labels: labels("peer", peer, "direction", "received"), value: float64(status.received)},
+			sample{labels: labels("peer", peer, "direction", "sent"), value: float64(status.sent)},
+		)
+	}
+	writeMetric(b, "federation_hub_peer_up", "gauge", "Whether the last gossip round with a peer hub succeeded.", up...)
+	writeMetric(b, "federation_hub_peer_last_sync_timestamp_seconds", "gauge", "Unix time of the last successful gossip round with a peer hub.", lastSuccess...)
+	writeMetric(b, "federation_hub_peer_sync_failures_total", "counter", "Failed gossip rounds with a peer hub.", failures...)
+	writeMetric(b, "federation_hub_peer_announcements_total", "counter", "Announcements gossiped with a peer hub, by direction.", exchanged...)
+}
+
+// sample is one line of a metric family.
+type sample struct {
+	suffix string // Appended to the family name, for histograms
+	labels string
+	value  float64
+}
+
+// writeMetric appends a metric family in the Prometheus text format.
+// Families without samples are left out.
+func writeMetric(b *strings.Builder, name, kind, help string, samples ...sample) {
+	if len(samples) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	for _, s := range samples {
+		fmt.Fprintf(b, "%s%s%s %v\n", name, s.suffix, s.labels, s.value)
+	}
+}
+
+// labelEscaper escapes label values as the Prometheus text format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats name/value pairs as a Prometheus label set.
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+`="`+labelEscaper.Replace(pairs[i+1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// sortedKeys returns a map's keys in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
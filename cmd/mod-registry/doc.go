@@ -14,4 +14,41 @@
 //   - -storage: Mod storage directory (default: mod-storage)
 //   - -log-level: Logging verbosity: debug, info, warn, error (default: info)
 //   - -max-mod-size: Maximum mod size in bytes (default: 10MB)
+//   - -admin-token: Bearer token for the /admin endpoints, which are
+//     disabled without one
+//
+// Endpoints marked (account) need an "Authorization: Bearer <token>"
+// header with the token POST /accounts returned; those marked (admin) need
+// the admin token.
+//
+// Endpoints:
+//   - POST /accounts: Claim an author name and get its account token.
+//     Authors who published before accounts existed are reserved and get
+//     their token from an admin
+//   - POST /upload (account): Upload a mod as multipart "wasm", "manifest"
+//     and an optional "signature" (the mod.sig of a signed package). The
+//     manifest author must be the account's, and only the author who first
+//     uploaded a mod can upload new versions of it
+//   - GET /search: Search mods by name, author or tag; sort=rating or
+//     sort=downloads orders by rating or total downloads
+//   - GET /download/{name}/{version}: Download a mod's WASM module
+//   - GET /manifest/{name}/{version}: The mod.json the version was
+//     uploaded with
+//   - GET /keys: The key ring of author signing keys and blocked keys and
+//     mods, which clients use to decide which mods they trust
+//   - POST /keys (account): Publish an author signing key. An author's
+//     first key is accepted as is; later keys need a proof signed by an
+//     existing key
+//   - POST /rate/{name} (account): Rate a mod 1 to 5 stars
+//   - POST /report/{name}: Report a mod to the moderation queue, with an
+//     optional account token
+//   - GET /admin/reports (admin): The open reports, or ?status=all
+//   - POST /admin/reports/{id}/dismiss (admin): Close a report
+//   - POST /admin/takedowns (admin): Take a mod down, closing its reports
+//   - DELETE /admin/takedowns/{name} (admin): Lift a takedown
+//   - POST /admin/blocked-keys (admin): Revoke a signing key
+//   - POST /admin/suspensions (admin): Suspend an account
+//   - DELETE /admin/suspensions/{author} (admin): Reinstate an account
+//   - POST /admin/accounts/{author}/token (admin): Issue a new token for an
+//     author who lost theirs, creating the account of a reserved author
 package main
@@ -26,6 +26,7 @@ var (
 	storagePath = flag.String("storage", "mod-storage", "Mod storage directory")
 	logLevel    = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
 	maxModSize  = flag.Int64("max-mod-size", 10*1024*1024, "Maximum mod size in bytes (default 10MB)")
+	adminToken  = flag.String("admin-token", "", "Bearer token for the /admin endpoints (empty disables them)")
 )
 
 func main() {
@@ -62,13 +63,23 @@ func main() {
 
 	// Configure max mod size
 	reg.SetMaxModSize(*maxModSize)
+	reg.SetAdminToken(*adminToken)
 
 	// Create HTTP server
 	mux := http.NewServeMux()
 	mux.HandleFunc("/upload", reg.HandleUpload)
 	mux.HandleFunc("/search", reg.HandleSearch)
 	mux.HandleFunc("/download/", reg.HandleDownload)
+	mux.HandleFunc("/manifest/", reg.HandleManifest)
+	mux.HandleFunc("/keys", reg.HandleKeys)
+	mux.HandleFunc("/accounts", reg.HandleAccounts)
+	mux.HandleFunc("/rate/", reg.HandleRate)
+	mux.HandleFunc("/report/", reg.HandleReport)
 	mux.HandleFunc("/health", handleHealth)
+	reg.RegisterAdmin(mux)
+	if *adminToken == "" {
+		logrus.Warn("No admin token set, moderation endpoints disabled")
+	}
 
 	server := &http.Server{
 		Addr:    *addr,
@@ -1,7 +1,8 @@
 // Package main provides the dedicated multiplayer server for VIOLENCE.
 //
 // The server manages game state, handles client connections, and synchronizes
-// player actions across the network. It runs headless without graphics.
+// player actions across the network. It generates the level from a seed and
+// simulates it headless without graphics.
 //
 // Usage:
 //
@@ -11,4 +12,8 @@
 // Server flags:
 //   - -port: TCP port to listen on (default: 7777)
 //   - -log-level: Logging verbosity: debug, info, warn, error (default: info)
+//   - -seed: Level seed, 0 for a random one (default: 0)
+//   - -genre: Level genre (default: fantasy)
+//   - -anticheat: Anti-cheat responses, e.g. movement=warn,damage=kick
+//   - -record: File to save a replay of the match to on shutdown
 package main
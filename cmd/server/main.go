@@ -5,8 +5,8 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
-	"github.com/opd-ai/violence/pkg/engine"
 	"github.com/opd-ai/violence/pkg/network"
 	"github.com/sirupsen/logrus"
 )
@@ -15,6 +15,10 @@ import (
 var (
 	port     = flag.Int("port", 7777, "Server port to listen on")
 	logLevel = flag.String("log-level", "info", "Log level (debug, info, warn, error)")
+	seed     = flag.Uint64("seed", 0, "Level seed (0 picks one at random)")
+	genreID  = flag.String("genre", "fantasy", "Level genre (fantasy, scifi, horror, cyberpunk, postapoc)")
+	cheats   = flag.String("anticheat", "", "Anti-cheat responses over the defaults, e.g. movement=warn,damage=kick")
+	record   = flag.String("record", "", "Record the match and save the replay to this file on shutdown")
 )
 
 func main() {
@@ -33,14 +37,38 @@ func main() {
 		"log_level": *logLevel,
 	}).Info("Starting VIOLENCE dedicated server")
 
-	// Initialize game world
-	world := engine.NewWorld()
+	responses, err := network.ParseCheatResponses(*cheats)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid anti-cheat responses")
+	}
+
+	// Generate the level the server simulates
+	if *seed == 0 {
+		*seed = uint64(time.Now().UnixNano())
+	}
+	lvl, err := newLevel(*seed, *genreID)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to generate level")
+	}
+	logrus.WithFields(logrus.Fields{
+		"seed":  *seed,
+		"genre": *genreID,
+	}).Info("Level generated")
 
 	// Create and start game server
-	server, err := network.NewGameServer(*port, world)
+	server, err := network.NewGameServer(*port, lvl.world)
 	if err != nil {
 		logrus.WithError(err).Fatal("Failed to create game server")
 	}
+	server.SetValidator(network.NewAntiCheat(responses))
+	server.SetPlayerSpawner(lvl.spawnPlayer)
+	server.SetCommandApplier(&network.DefaultApplier{Blocked: lvl.blocked})
+
+	var recorder *network.MatchRecorder
+	if *record != "" {
+		recorder = network.NewMatchRecorder(network.MatchInfo{Seed: *seed, Genre: *genreID})
+		server.SetRecorder(recorder)
+	}
 
 	if err := server.Start(); err != nil {
 		logrus.WithError(err).Fatal("Failed to start game server")
@@ -58,6 +86,11 @@ func main() {
 	if err := server.Stop(); err != nil {
 		logrus.WithError(err).Error("Error during server shutdown")
 	}
+	if recorder != nil {
+		if err := recorder.Save(*record); err != nil {
+			logrus.WithError(err).Error("Failed to save match replay")
+		}
+	}
 
 	logrus.Info("Server stopped")
 }
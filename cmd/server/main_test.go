@@ -254,3 +254,31 @@ func TestServerStopBeforeStart(t *testing.T) {
 		t.Error("Expected error when stopping non-running server")
 	}
 }
+
+func TestLevel(t *testing.T) {
+	lvl, err := newLevel(42, "scifi")
+	if err != nil {
+		t.Fatalf("newLevel() error = %v", err)
+	}
+	again, _ := newLevel(42, "scifi")
+	if len(lvl.spawns) != len(again.spawns) || lvl.spawns[0] != again.spawns[0] {
+		t.Error("same seed generated a different level")
+	}
+
+	player := lvl.spawnPlayer(lvl.world, 0)
+	if !lvl.world.IsPlayer(player) {
+		t.Fatal("spawned entity is not a player")
+	}
+	spawn := lvl.spawns[0]
+	if lvl.blocked(spawn[0], spawn[1]) {
+		t.Error("spawn point is blocked")
+	}
+	if !lvl.blocked(-1, -1) || !lvl.blocked(0.5, 0.5) {
+		t.Error("outside the map and its border walls should be blocked")
+	}
+
+	// The world steps headless without panicking
+	for i := 0; i < network.SimulationSteps*network.TickRate; i++ {
+		lvl.world.Update()
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/opd-ai/violence/pkg/bsp"
+	"github.com/opd-ai/violence/pkg/common"
+	"github.com/opd-ai/violence/pkg/engine"
+	"github.com/opd-ai/violence/pkg/hazard"
+	"github.com/opd-ai/violence/pkg/projectile"
+	"github.com/opd-ai/violence/pkg/rng"
+	"github.com/opd-ai/violence/pkg/status"
+)
+
+// playerRadius is the half-width of a player's footprint, as in the game.
+const playerRadius = 0.25
+
+// level is the server's copy of the game: the generated map and the world
+// simulating it.
+type level struct {
+	world   *engine.World
+	tiles   [][]int
+	spawns  [][2]float64
+	hazards *hazard.ECSSystem
+	status  *status.Registry
+}
+
+// newLevel generates the level for seed and genre, as a client with the
+// same seed does, and sets up the systems that simulate it headless.
+// AI and weapon combat live in packages tied to the renderer, so
+// monsters aren't simulated on a dedicated server yet.
+func newLevel(seed uint64, genreID string) (*level, error) {
+	gen, err := bsp.NewGenerator(common.DefaultMapSize, common.DefaultMapSize, rng.NewRNG(seed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create level generator: %w", err)
+	}
+	gen.SetGenre(genreID)
+	tree, tiles := gen.Generate()
+
+	l := &level{
+		world:   engine.NewWorld(),
+		tiles:   tiles,
+		hazards: hazard.NewECSSystem(int64(seed)),
+		status:  status.NewRegistry(),
+	}
+	l.world.SetGenre(genreID)
+	for _, r := range bsp.GetRooms(tree) {
+		l.spawns = append(l.spawns, [2]float64{float64(r.X+r.W/2) + 0.5, float64(r.Y+r.H/2) + 0.5})
+	}
+	if len(l.spawns) == 0 {
+		return nil, fmt.Errorf("level for seed %d has no rooms", seed)
+	}
+
+	l.hazards.SetGenre(genreID)
+	l.hazards.GenerateHazards(l.world, tiles, int64(seed))
+	l.world.AddSystem(l.hazards)
+	l.world.AddSystem(hazardDamage{l})
+	l.world.AddSystem(projectile.NewSystem())
+	l.world.AddSystem(status.NewSystem(l.status))
+	return l, nil
+}
+
+// spawnPlayer creates a player in a room, spreading players over the
+// rooms by their ID.
+func (l *level) spawnPlayer(w *engine.World, clientID uint64) engine.Entity {
+	spawn := l.spawns[clientID%uint64(len(l.spawns))]
+	return w.NewPlayerEntity(spawn[0], spawn[1])
+}
+
+// blocked reports whether a player's footprint at x, y overlaps a tile
+// they can't walk on.
+func (l *level) blocked(x, y float64) bool {
+	for _, off := range [4][2]float64{
+		{-playerRadius, -playerRadius},
+		{playerRadius, -playerRadius},
+		{-playerRadius, playerRadius},
+		{playerRadius, playerRadius},
+	} {
+		tx, ty := int(math.Floor(x+off[0])), int(math.Floor(y+off[1]))
+		if ty < 0 || ty >= len(l.tiles) || tx < 0 || tx >= len(l.tiles[ty]) || !walkable(l.tiles[ty][tx]) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkable reports whether players can stand on a tile; closed doors
+// block them, as in the game.
+func walkable(tile int) bool {
+	return tile == bsp.TileFloor || (tile >= 20 && tile <= 29)
+}
+
+// hazardDamage hurts players standing in active hazards.
+type hazardDamage struct{ l *level }
+
+// Update applies hazard damage and status effects to players.
+func (h hazardDamage) Update(w *engine.World) {
+	posType := reflect.TypeOf(&engine.Position{})
+	healthType := reflect.TypeOf(&engine.Health{})
+	for _, e := range w.Query(posType, healthType) {
+		if !w.IsPlayer(e) {
+			continue
+		}
+		comp, _ := w.GetComponent(e, posType)
+		pos := comp.(*engine.Position)
+		hit, damage, effect := h.l.hazards.CheckCollision(w, pos.X, pos.Y)
+		if !hit {
+			continue
+		}
+		comp, _ = w.GetComponent(e, healthType)
+		health := comp.(*engine.Health)
+		health.Current = max(health.Current-damage, 0)
+		if effect != "" {
+			h.l.status.ApplyToEntity(w, e, effect)
+		}
+	}
+}
@@ -21,6 +21,7 @@ require (
 	github.com/spf13/viper v1.20.1
 	github.com/studio-b12/gowebdav v0.12.0
 	github.com/wasmerio/wasmer-go v1.0.4
+	github.com/yuin/gopher-lua v1.1.1
 	golang.org/x/crypto v0.32.0
 	golang.org/x/image v0.20.0
 	golang.org/x/text v0.21.0
@@ -80,6 +81,8 @@ require (
 	github.com/jbenet/go-temp-err-catcher v0.1.0 // indirect
 	github.com/jbenet/goprocess v0.1.4 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
+	github.com/jfreymuth/oggvorbis v1.0.5 // indirect
+	github.com/jfreymuth/vorbis v1.0.2 // indirect
 	github.com/klauspost/compress v1.17.11 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.9 // indirect
 	github.com/koron/go-ssdp v0.0.4 // indirect
@@ -1,21 +1,31 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
+	"image/png"
 	"log"
+	"maps"
 	"math"
 	"math/rand"
 	"net"
 	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/opd-ai/violence/pkg/achievements"
 	"github.com/opd-ai/violence/pkg/ai"
 	"github.com/opd-ai/violence/pkg/ammo"
 	"github.com/opd-ai/violence/pkg/animation"
@@ -33,6 +43,7 @@ import (
 	"github.com/opd-ai/violence/pkg/class"
 	"github.com/opd-ai/violence/pkg/collision"
 	"github.com/opd-ai/violence/pkg/combat"
+	"github.com/opd-ai/violence/pkg/combatlog"
 	"github.com/opd-ai/violence/pkg/common"
 	"github.com/opd-ai/violence/pkg/config"
 	"github.com/opd-ai/violence/pkg/corpse"
@@ -45,6 +56,7 @@ import (
 	"github.com/opd-ai/violence/pkg/decoration"
 	"github.com/opd-ai/violence/pkg/destruct"
 	"github.com/opd-ai/violence/pkg/dialogue"
+	"github.com/opd-ai/violence/pkg/director"
 	"github.com/opd-ai/violence/pkg/dmgfx"
 	"github.com/opd-ai/violence/pkg/door"
 	"github.com/opd-ai/violence/pkg/dustmote"
@@ -87,6 +99,7 @@ import (
 	"github.com/opd-ai/violence/pkg/parallax"
 	"github.com/opd-ai/violence/pkg/particle"
 	"github.com/opd-ai/violence/pkg/playersprite"
+	"github.com/opd-ai/violence/pkg/profiler"
 	"github.com/opd-ai/violence/pkg/progression"
 	"github.com/opd-ai/violence/pkg/projectile"
 	"github.com/opd-ai/violence/pkg/props"
@@ -151,6 +164,11 @@ const (
 	StateMultiplayer                  // StateMultiplayer is the multiplayer menu state.
 	StateCodex                        // StateCodex is the codex menu state.
 	StateMinigame                     // StateMinigame is the minigame state.
+	StatePhoto                        // StatePhoto is the frozen photo mode state.
+	StateSummary                      // StateSummary is the end-of-level statistics screen.
+	StateSaves                        // StateSaves is the save slot browser state.
+	StateStats                        // StateStats is the lifetime statistics screen.
+	StateReplay                       // StateReplay is match replay playback.
 )
 
 // Game implements ebiten.Game for the VIOLENCE raycasting FPS.
@@ -168,7 +186,11 @@ type Game struct {
 	tutorialSystem     *tutorial.Tutorial
 	rng                *rng.RNG
 	bspGenerator       *bsp.Generator
+	genConfig          bsp.GenerationConfig
+	levelDepth         int
 	currentMap         [][]int
+	portalArrival      [2]int // Portal tile the player was last placed in
+	inPortal           bool   // Whether the player is still in portalArrival
 	genreID            string
 	seed               uint64
 	automap            *automap.Map
@@ -176,6 +198,10 @@ type Game struct {
 	keycards           map[string]bool
 	automapVisible     bool
 	playerEntity       engine.Entity // ECS player entity for status effects and other systems
+	playerEntityHealth int           // Player entity health as of the last HUD sync
+
+	// Elemental combos set off since the last update, waiting to go off
+	reactions []reactionEvent
 
 	// v2.0 systems
 	arsenal      *weapon.Arsenal
@@ -183,30 +209,101 @@ type Game struct {
 	combatSystem *combat.System
 	statusReg    *status.Registry
 	statusSystem *status.System
-	lootTable    *loot.LootTable
+	lootTables   *loot.Tables // Enemy and container loot, by archetype or container
+	lootPity     *loot.Pity   // Misses of each loot table since its last rare drop
 	progression  *progression.Progression
 	aiAgents     []*ai.Agent
+	agentPos     []*engine.Position // ECS positions of aiAgents, by index
+	agentEnts    []engine.Entity    // ECS entities of aiAgents, by index
+	agentTrees   []*ai.BehaviorTree // Behavior trees of aiAgents, by index
+	behaviors    *ai.NodeRegistry
+	archetypes   *ai.ArchetypeRegistry
+	navGrid      *ai.NavGrid
+	aiTick       int
+	perception   *ai.Perception
+	squadPlanner *ai.SquadPlanner
+	director     *director.Director
+	waveCount    int // Reinforcement waves sent this level
+	combatLog    *combatlog.Log
+	levelSummary *ui.LevelSummaryState // Shown while in StateSummary
 	playerClass  string
 
 	// v3.0 systems
 	textureAtlas    *texture.Atlas
 	lightMap        *lighting.SectorLightMap
+	dayCycle        *lighting.DayCycle
+	impulseLights   *lighting.ImpulsePool
+	frameLights     []lighting.Light
 	shadowSystem    *lighting.ShadowSystem
 	particleSystem  *particle.ParticleSystem
+	trailSystem     *particle.TrailSystem
+	trailRenderer   *particle.TrailRenderer
+	particleBatch   *particle.ParticleBatch
+	batchRenderer   *particle.BatchRenderer
+	visibleParts    []particle.Particle
+	windField       *particle.VectorField
+	skyMask         [][]bool // Tiles open to the sky, [y][x]
+	windTime        float64
 	weatherEmitter  *particle.WeatherEmitter
 	postProcessor   *render.PostProcessor
 	currentBSPTree  *bsp.Node
 	animationTicker int
 
+	// Render quality tier and dynamic resolution, re-applied when the
+	// config is hot-reloaded
+	quality          render.QualitySettings
+	qualityConfig    qualityConfig
+	resolutionScaler *render.ResolutionScaler
+
+	// Frame-time profiler overlay, toggled with ActionProfiler
+	profiler *profiler.Profiler
+	// AI debug overlay, toggled with ActionAIDebug
+	aiDebug *ai.DebugOverlay
+
+	// Photo mode and screenshots
+	photoFilter       render.PhotoFilter
+	photoReturn       camera.Camera // Player camera restored when photo mode ends
+	photoHideHint     bool
+	screenshotPending bool
+
+	// Match replay playback
+	replay      *network.ReplayPlayback
+	replayTiles [][]int // Level the replay was recorded on
+
 	// v4.0 systems
 	destructibleSystem *destruct.System
 	squadCompanions    *squad.Squad
+	squadTarget        *ai.Agent                // Enemy the squad was ordered to attack
+	companionEntities  map[string]engine.Entity // ECS entity per squad member, carrying its lantern
 	questTracker       *quest.Tracker
+	campaign           *quest.Graph // Quests chained across the run's levels
 	alarmTrigger       *event.AlarmTrigger
 	lockdownTrigger    *event.TimedLockdown
 	bossArena          *event.BossArenaEvent
+	bossArenaRoom      *bsp.Room
+	shopRoom           *bsp.Room         // Merchant's room; the shop only opens inside it
+	exitRoom           *bsp.Room         // Exit chamber; entering it descends a level
+	chunkWorld         *bsp.ChunkedWorld // Streamed overworld; nil for a single BSP level
+	boss               *combat.BossController
+	bossEntity         engine.Entity
+	bossPos            *engine.Position
+	bossHealth         *engine.Health
+	bossMarker         *telegraph.PositionComponent // Anchors the boss's attack telegraphs
 	levelStartTime     time.Time
 
+	// The level's event objective: an escortee, a position defended
+	// against waves, an extraction countdown; sabotage targets are
+	// destructibles.
+	escort       *ai.Escort
+	defense      *event.DefendEvent
+	defenseWaves [][]*ai.Agent
+	extraction   *event.ExtractionEvent
+
+	playtime      time.Duration        // Time played in the run, kept in saves
+	saveBrowser   *ui.SaveBrowserState // Shown while in StateSaves
+	saveReturn    GameState            // State the save browser goes back to
+	pendingDelete int                  // Slot asked to be deleted, or -1
+
 	// v5.0+ systems
 	craftingMenu    *crafting.CraftingMenu
 	scrapStorage    *crafting.ScrapStorage
@@ -216,14 +313,28 @@ type Game struct {
 	craftingResult  string
 	skillManager    *skills.Manager
 	modLoader       *mod.Loader
+	modKeysLoaded   bool           // The mod key ring was read and a registry fetch started
+	modBrowser      *ui.ModBrowser // Mod registry browser on the mods screen, nil until opened
+	modWatcher      *mod.Watcher   // Watches enabled mods' files with ModHotReload, nil otherwise
 	networkMode     bool
-	networkConn     net.Conn    // Active network connection for key exchange
-	multiplayerMgr  interface{} // Can be *network.FFAMatch, *network.TeamMatch, etc.
-	skillsTreeIdx   int         // Active tree tab in skills UI
-	skillsNodeIdx   int         // Selected node in skills UI
-	mpStatusMsg     string      // Multiplayer status message
-	mpSelectedMode  int         // Selected multiplayer mode
+	networkConn     net.Conn       // Active network connection for key exchange
+	multiplayerMgr  interface{}    // Can be *network.FFAMatch, *network.TeamMatch, etc.
+	scoreboard      *ui.Scoreboard // Team and territory matches' scores, nil in other modes
+	skillsTreeIdx   int            // Active tree tab in skills UI
+	skillsNodeIdx   int            // Selected node in skills UI
+	mpStatusMsg     string         // Multiplayer status message
+	mpSelectedMode  int            // Selected multiplayer mode
 	playerInventory *inventory.Inventory
+	playerArmor     *inventory.Loadout
+	stash           *inventory.Container // Persistent, shared across levels and runs
+	metaQuests      *quest.MetaBook      // Kept in the profile, across levels and runs
+	metaAtLevel     []quest.MetaProgress // Meta-quest progress when the level began
+	profileStats    save.Stats           // Lifetime totals, kept in the profile
+	statsAtLevel    save.Stats           // profileStats when the level began
+	achievements    *achievements.AchievementManager
+	statsScreen     *ui.StatsScreenState // Shown while in StateStats
+	levelCache      *inventory.Container // Belongs to the current level
+	encumbrance     inventory.Encumbrance
 	propsManager    *props.Manager
 	loreCodex       *lore.Codex
 	loreGenerator   *lore.Generator
@@ -252,9 +363,18 @@ type Game struct {
 
 	// Federation system
 	federationHub *federation.FederationHub
-	serverBrowser []*federation.ServerAnnouncement // Cached server list
-	browserIdx    int                              // Selected server in browser
-	useFederation bool                             // Whether to use federation matchmaking
+	serverList    *network.ServerBrowser         // Discovered servers, pings and favorites
+	serverFeed    *federation.ServerSubscription // Live registry events while the browser is open
+	serverBrowser []network.ServerListing        // Servers shown, filtered and sorted
+	browserIdx    int                            // Selected server in browser
+	useFederation bool                           // Whether to use federation matchmaking
+	mpAddrInput   string                         // Address typed for direct connect
+	mpAddrActive  bool                           // Direct connect field has focus
+	mpServerAddr  string                         // Address of the joined server
+	mpConn        net.Conn                       // Connection to the joined server
+	mpConnecting  chan serverConnectResult       // Outcome of a join in progress
+	voice         *network.VoiceChannel          // Who's talking on the server and who's muted
+	pushToTalk    network.PushToTalk
 
 	// E2E encrypted chat system
 	chatManager     *chat.Chat
@@ -286,6 +406,7 @@ type Game struct {
 
 	// Loot drop system
 	lootDropSystem *loot.LootDropSystem
+	pickups        *loot.PickupSystem // Loot lying on the floor
 
 	// Screen feedback system for combat juice
 	feedbackSystem *feedback.FeedbackSystem
@@ -340,6 +461,8 @@ type Game struct {
 
 	// Projectile system for spell/ranged combat with damage types and resistances
 	projectileSystem *projectile.System
+	rangedSystem     *combat.RangedSystem // Enemy shots in flight
+	projectiles      *projectile.Manager  // Player rockets and plasma in flight
 
 	// Biome material system for dungeon-specific crafting material drops
 	biomeMaterialSystem *biome.BiomeMaterialSystem
@@ -582,16 +705,25 @@ func NewGame() *Game {
 		arsenal:        weapon.NewArsenal(),
 		ammoPool:       ammo.NewPool(),
 		combatSystem:   combat.NewSystem(),
+		combatLog:      combatlog.New(),
 		statusReg:      status.NewRegistry(),
-		lootTable:      loot.NewLootTable(),
+		lootTables:     loot.NewTables(),
+		lootPity:       loot.NewPity(),
 		progression:    progression.NewProgression(),
 		aiAgents:       make([]*ai.Agent, 0),
+		behaviors:      ai.NewNodeRegistry(),
+		archetypes:     ai.NewArchetypeRegistry(),
+		perception:     ai.NewPerception(),
+		squadPlanner:   ai.NewSquadPlanner(),
 		playerClass:    class.Grunt,
 		// v3.0 systems
 		textureAtlas:    texture.NewAtlas(seed),
 		lightMap:        lighting.NewSectorLightMap(64, 64, 0.3),
+		dayCycle:        lighting.NewDayCycle("fantasy"),
+		impulseLights:   lighting.NewImpulsePool(maxImpulseLights, maxImpulsesPerFrame),
 		shadowSystem:    lighting.NewShadowSystem(config.C.InternalWidth, config.C.InternalHeight, "fantasy"),
 		particleSystem:  particle.NewParticleSystem(1024, int64(seed)),
+		trailSystem:     particle.NewTrailSystem(maxTrails, maxTrailPoints),
 		postProcessor:   render.NewPostProcessor(config.C.InternalWidth, config.C.InternalHeight, int64(seed)),
 		animationTicker: 0,
 		// v4.0 systems
@@ -599,6 +731,7 @@ func NewGame() *Game {
 		squadCompanions:     squad.NewSquad(3), // Max 3 squad members
 		questTracker:        quest.NewTracker(),
 		playerInventory:     inventory.NewInventory(),
+		playerArmor:         inventory.NewLoadout(),
 		propsManager:        props.NewManager(),
 		loreCodex:           lore.NewCodex(),
 		loreGenerator:       lore.NewGenerator(int64(seed)),
@@ -608,7 +741,9 @@ func NewGame() *Game {
 		upgradeManager:      upgrade.NewManager(),
 		masteryManager:      weapon.NewMasteryManager(),
 		federationHub:       federation.NewFederationHub(),
-		serverBrowser:       make([]*federation.ServerAnnouncement, 0),
+		serverList:          network.NewServerBrowser(config.C.FavoriteServers),
+		voice:               network.NewVoiceChannel(),
+		serverBrowser:       make([]network.ServerListing, 0),
 		browserIdx:          0,
 		useFederation:       false,
 		hazardECSSystem:     hazard.NewECSSystem(int64(seed)),
@@ -618,6 +753,7 @@ func NewGame() *Game {
 		motionSystem:        motion.NewSystem(),
 		comboSystem:         combat.NewComboSystem("fantasy", int64(seed)),
 		lootDropSystem:      loot.NewLootDropSystem(int64(seed)),
+		pickups:             loot.NewPickupSystem(),
 		feedbackSystem:      feedback.NewFeedbackSystem(int64(seed)),
 		spriteGenerator:     sprite.NewGenerator(100),
 		defenseSystem:       combat.NewDefenseSystem("fantasy"),
@@ -635,6 +771,8 @@ func NewGame() *Game {
 		aoSystem:            lighting.NewAOSystem("fantasy"),
 		colorTempSystem:     lighting.NewColorTempSystem(lighting.DefaultColorTempConfig()),
 		projectileSystem:    projectile.NewSystem(),
+		rangedSystem:        combat.NewRangedSystem(),
+		projectiles:         projectile.NewManager(),
 		biomeMaterialSystem: biome.NewBiomeMaterialSystem("fantasy"),
 		trapSystem:          trap.NewSystem(int64(seed)),
 		questLootSystem:     loot.NewQuestLootSystem("fantasy", seed),
@@ -645,6 +783,8 @@ func NewGame() *Game {
 		damageStateSystem:   damagestate.NewSystem("fantasy"),
 	}
 
+	g.perception.OnAlertChange = g.onAgentAlert
+
 	// Initialize faction system first
 	g.factionSystem = faction.NewReputationSystem()
 
@@ -654,8 +794,11 @@ func NewGame() *Game {
 	// Initialize sliding system with spatial index (will be set properly after spatial system init)
 	g.slidingSystem = collision.NewSlidingSystem(nil)
 
-	// Initialize status system with the registry
+	// Initialize status system with the registry, ticking effects through
+	// the combat damage pipeline
 	g.statusSystem = status.NewSystem(g.statusReg)
+	g.statusSystem.OnTick = g.onStatusTick
+	g.statusReg.OnReaction = g.onReaction
 
 	// Initialize entity label system for guaranteed text rendering
 	g.entityLabelSystem = entitylabel.NewSystem(g.genreID)
@@ -727,6 +870,9 @@ func NewGame() *Game {
 
 	// Initialize particle rendering system for enhanced particle visuals
 	g.particleRenderer = particle.NewRendererSystem()
+	g.trailRenderer = particle.NewTrailRenderer()
+	g.particleBatch = particle.NewParticleBatch(float32(config.C.InternalWidth), float32(config.C.InternalHeight))
+	g.batchRenderer = particle.NewBatchRenderer()
 
 	// Initialize floating damage number system for combat feedback
 	g.damageNumberSystem = damagenumber.NewSystem(g.genreID)
@@ -763,6 +909,7 @@ func NewGame() *Game {
 	// Initialize muzzle flash system for weapon firing visual feedback
 	g.muzzleFlashSystem = muzzleflash.NewSystem(g.genreID, int64(seed))
 	g.muzzleFlashRenderer = muzzleflash.NewRenderer(g.muzzleFlashSystem, g.genreID)
+	g.muzzleFlashSystem.SetLightEmitter(g.impulseLights)
 
 	// Initialize realistic flame flicker system for physics-based torch/fire lighting
 	g.flickerBridge = flicker.NewLightFlickerBridge(g.genreID)
@@ -917,6 +1064,19 @@ func NewGame() *Game {
 		EdgeAO:           g.edgeAOSystem,
 		WeaponSway:       g.weaponSwaySystem,
 	})
+	g.projectileSystem.SetLightEmitter(g.impulseLights)
+	g.projectileSystem.SetTrailEmitter(g.trailSystem)
+	g.projectiles.SetLightEmitter(g.impulseLights)
+	g.projectiles.SetTrailEmitter(g.trailSystem)
+	g.arsenal.Launch = g.launchProjectile
+	g.arsenal.Reserve = g.ammoPool
+
+	g.applyQualityConfig(config.Get())
+	g.profiler = profiler.New()
+	g.aiDebug = ai.NewDebugOverlay()
+	g.stash = loadStash()
+	g.loadProfile()
+	g.serverList.SetFilter(network.BrowserFilter{Genre: g.genreID})
 
 	// Show main menu
 	g.menuManager.Show(ui.MenuTypeMain)
@@ -928,13 +1088,14 @@ func NewGame() *Game {
 func (g *Game) Update() error {
 	// Update input manager
 	g.input.Update()
+	g.reloadChangedMods()
 
 	// Increment flicker tick for physics-based flame animation
 	g.flickerTick++
 
 	// Manage cursor capture: locked during gameplay, visible in menus
 	switch g.state {
-	case StatePlaying:
+	case StatePlaying, StatePhoto:
 		ebiten.SetCursorMode(ebiten.CursorModeCaptured)
 	default:
 		ebiten.SetCursorMode(ebiten.CursorModeVisible)
@@ -963,6 +1124,16 @@ func (g *Game) Update() error {
 		return g.updateCodex()
 	case StateMinigame:
 		return g.updateMinigame()
+	case StatePhoto:
+		return g.updatePhoto()
+	case StateSummary:
+		return g.updateLevelSummary()
+	case StateSaves:
+		return g.updateSaveBrowser()
+	case StateStats:
+		return g.updateStatsScreen()
+	case StateReplay:
+		return g.updateReplay()
 	}
 
 	return nil
@@ -1024,8 +1195,9 @@ func (g *Game) handleMenuAction(action string) {
 		g.genreID = g.menuManager.GetSelectedGenre()
 		g.startNewGame()
 	case "load_game":
-		// Load from slot 1 (first manual save)
-		g.loadGame(1)
+		g.openSaveBrowser(false)
+	case "stats":
+		g.openStatsScreen()
 	case "settings":
 		g.menuManager.Show(ui.MenuTypeSettings)
 	case "quit":
@@ -1037,27 +1209,47 @@ func (g *Game) handleMenuAction(action string) {
 func (g *Game) startNewGame() {
 	g.state = StateLoading
 	g.loadingScreen.Show(g.seed, "Generating level...")
+	g.levelDepth = 1
+	g.playtime = 0
+	g.lootPity = loot.NewPity()
+	g.campaign = quest.GenerateCampaign(g.seed, campaignLevels, g.genreID)
+	g.loadMods()
 
 	g.generateLevel()
 	g.populateLevel()
+	g.levelCache = newLevelCache()
+	g.pickups.Clear(g.world)
 	g.initializePlayer()
 	g.initializeGameSystems()
 	g.finalizeGameStart()
+	g.reportQuests(g.campaign.Evaluate(g.levelDepth))
 }
 
 // generateLevel generates the BSP level and initializes core map systems.
+// Each level is generated from its own seed, so loading a save regenerates
+// the level it was made on.
 func (g *Game) generateLevel() {
+	g.rng.Seed(levelSeed(g.seed, g.levelDepth))
+	g.configureGenerator()
 	g.bspGenerator.SetGenre(g.genreID)
 	g.spriteGenerator.SetGenre(g.genreID)
 	g.outlineSystem.SetGenre(g.genreID)
 	g.rimLightSystem.SetGenre(g.genreID)
-	bspTree, tiles := g.bspGenerator.Generate()
+	bspTree, tiles := g.generateMap()
 	g.currentMap = tiles
 	g.currentBSPTree = bspTree
 	g.raycaster.SetMap(tiles)
 
 	// Decorate rooms based on type and genre
 	g.decorateRooms(bspTree, tiles)
+	g.shopRoom = bsp.FindRoomByTag(bspTree, bsp.RoomTagShop)
+	g.exitRoom = nil
+	if g.chunkWorld == nil {
+		g.exitRoom = bsp.FindRoomByTag(bspTree, bsp.RoomTagExit)
+	}
+	if len(tiles) > 0 {
+		g.skyMask = bsp.SkyMask(bspTree, len(tiles[0]), len(tiles), isOutdoorRoom)
+	}
 
 	// Generate floor details for visual variety
 	g.generateFloorDetails(tiles)
@@ -1088,11 +1280,24 @@ func (g *Game) generateLevel() {
 		minimapCfg := automap.DefaultCollapsibleConfig()
 		g.collapsibleMinimap = automap.NewCollapsibleMinimap(g.automap, minimapCfg)
 		g.lightMap = lighting.NewSectorLightMap(len(tiles[0]), len(tiles), 0.3)
-		g.weatherEmitter = particle.NewWeatherEmitter(g.particleSystem, g.genreID, 0, 0, float64(len(tiles[0])), float64(len(tiles)))
+		g.weatherEmitter = g.newWeatherEmitter(g.genreID)
+		g.windField = particle.NewVectorField(len(tiles[0]), len(tiles), windCellSize)
 	} else {
 		g.lightMap = lighting.NewSectorLightMap(0, 0, 0.3)
 		g.weatherEmitter = nil
 		g.collapsibleMinimap = nil
+		g.windField = nil
+	}
+	g.updateLightOccluders()
+	g.impulseLights.Clear()
+	g.trailSystem.Clear()
+	if g.particleSystem != nil {
+		g.particleSystem.SetCollisionMap(g.blocksParticles)
+		g.particleSystem.SetVectorField(g.windField)
+		g.particleSystem.ClearAttractors()
+		// Effects follow the level seed so replays and clients match.
+		g.particleSystem.Clear()
+		g.particleSystem.Reseed(int64(g.seed) ^ 0x50415254) // "PART"
 	}
 	g.setGenre(g.genreID)
 
@@ -1103,6 +1308,100 @@ func (g *Game) generateLevel() {
 	}
 }
 
+// levelSeed returns the seed the level at depth is generated from. The
+// first level's is the run's seed.
+func levelSeed(seed uint64, depth int) uint64 {
+	return seed + uint64(max(depth, 1)-1)*0x9e3779b97f4a7c15
+}
+
+// difficulty returns the selected difficulty as a bsp.Difficulty constant.
+func (g *Game) difficulty() int {
+	if g.menuManager == nil {
+		return bsp.DifficultyNormal
+	}
+	return int(g.menuManager.GetDifficulty())
+}
+
+// generateMap builds the level's tiles: a single BSP level, or, when
+// WorldSize is set, an overworld whose chunks stream in around the player.
+// The overworld map covers the whole world; unloaded chunks are solid wall.
+func (g *Game) generateMap() (*bsp.Node, [][]int) {
+	g.chunkWorld = nil
+	size := config.C.WorldSize
+	if size <= 0 {
+		return g.bspGenerator.Generate()
+	}
+	world, err := bsp.NewChunkedWorld(size, size, bsp.DefaultChunkSize, g.seed, g.genreID)
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid world size, generating a single level")
+		return g.bspGenerator.Generate()
+	}
+
+	tiles := make([][]int, world.Height)
+	for y := range tiles {
+		tiles[y] = make([]int, world.Width)
+		for x := range tiles[y] {
+			tiles[y][x] = bsp.TileWall
+		}
+	}
+	world.OnLoad = func(c *bsp.Chunk) {
+		for y, row := range c.Tiles {
+			copy(tiles[c.Y+y][c.X:], row)
+		}
+	}
+	world.OnUnload = func(c *bsp.Chunk) {
+		for y := c.Y; y < c.Y+c.Size; y++ {
+			for x := c.X; x < c.X+c.Size; x++ {
+				tiles[y][x] = bsp.TileWall
+			}
+		}
+	}
+	if _, _, err := world.UpdateAround(float64(world.Width/2), float64(world.Height/2)); err != nil {
+		logrus.WithError(err).Warn("Failed to load starting chunks, generating a single level")
+		return g.bspGenerator.Generate()
+	}
+	g.chunkWorld = world
+	return world.Root(), tiles
+}
+
+// streamChunks loads overworld chunks around the player and unloads distant
+// ones, then refreshes the systems built from the tile map.
+func (g *Game) streamChunks() {
+	if g.chunkWorld == nil {
+		return
+	}
+	loaded, unloaded, err := g.chunkWorld.UpdateAround(g.camera.X, g.camera.Y)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to stream world chunks")
+	}
+	if len(loaded) == 0 && len(unloaded) == 0 {
+		return
+	}
+	g.currentBSPTree = g.chunkWorld.Root()
+	g.raycaster.SetMap(g.currentMap)
+	g.updateLightOccluders()
+	g.buildNavGrid()
+}
+
+// configureGenerator sizes the BSP generator for the selected difficulty and
+// current level depth. The existing generator is kept if the config is invalid.
+func (g *Game) configureGenerator() {
+	if g.levelDepth < 1 {
+		g.levelDepth = 1
+	}
+	cfg := bsp.NewGenerationConfig(g.difficulty(), g.levelDepth)
+	gen, err := bsp.NewGeneratorFromConfig(cfg, g.rng)
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid generation config, keeping current generator")
+		return
+	}
+	g.genConfig = cfg
+	g.bspGenerator = gen
+	if g.hazardECSSystem != nil {
+		g.hazardECSSystem.SetBudget(cfg.HazardBudget)
+	}
+}
+
 // populateLevel populates the generated level with content and entities.
 func (g *Game) populateLevel() {
 	rooms := bsp.GetRooms(g.currentBSPTree)
@@ -1117,7 +1416,79 @@ func (g *Game) populateLevel() {
 	g.setupQuests(rooms)
 	g.setupEventTriggers()
 	g.generateHazards()
+	g.buildNavGrid()
 	g.spawnDynamicLights(rooms)
+	g.bakeStaticLights()
+	g.dispatchModEvent(mod.EventTypeLevelGenerate, map[string]interface{}{
+		"depth": g.levelDepth,
+		"seed":  g.seed,
+		"genre": g.genreID,
+	})
+}
+
+// updateLightOccluders points the light map at the current tile map so
+// lights stop at walls and closed doors. Call it whenever tiles change; it
+// re-bakes the static lights.
+func (g *Game) updateLightOccluders() {
+	if g.lightMap == nil {
+		return
+	}
+	tiles := g.currentMap
+	g.lightMap.SetOccluder(func(x, y int) bool {
+		if y < 0 || y >= len(tiles) || x < 0 || x >= len(tiles[y]) {
+			return true
+		}
+		return blocksLight(tiles[y][x])
+	})
+}
+
+// blocksParticles reports whether the tile at (x, y) stops colliding
+// particles: any wall, including see-through ones, and everything outside
+// the map. It reads the current map, so opened doors let particles pass.
+func (g *Game) blocksParticles(x, y int) bool {
+	if y < 0 || y >= len(g.currentMap) || x < 0 || x >= len(g.currentMap[y]) {
+		return true
+	}
+	return raycaster.IsWallTile(g.currentMap[y][x])
+}
+
+// particleCeiling returns the height of the ceiling colliding particles stop
+// at. Post-apocalyptic maps are open to the sky.
+func particleCeiling(genreID string) float64 {
+	if genreID == "postapoc" {
+		return 0
+	}
+	return 3.0
+}
+
+// blocksLight reports whether a tile stops light. Walls, doors and secret
+// walls do; floors and see-through walls such as bars and windows do not.
+func blocksLight(tile int) bool {
+	return raycaster.IsWallTile(tile) && !raycaster.IsTransparentTile(tile)
+}
+
+// bakeStaticLights bakes wall torches and steady room lights into the light
+// map's static layer once per level, so only moving and animated lights are
+// recomputed each frame.
+func (g *Game) bakeStaticLights() {
+	if g.lightMap == nil {
+		return
+	}
+	g.lightMap.ClearStatic()
+	if g.propsManager != nil {
+		for _, prop := range g.propsManager.GetProps() {
+			if prop.SpriteType == props.PropTorch {
+				g.lightMap.AddStaticLight(baseTorchLight(prop))
+			}
+		}
+	}
+	if g.lightingSystem != nil {
+		for _, light := range g.lightingSystem.CollectStaticLights(g.world) {
+			g.lightMap.AddStaticLight(light)
+		}
+	}
+	g.lightMap.Bake()
+	logrus.WithField("lights", g.lightMap.StaticLightCount()).Debug("Baked static lights")
 }
 
 // decorateRooms assigns room types and generates decorations for each room.
@@ -1128,15 +1499,39 @@ func (g *Game) decorateRooms(bspTree *bsp.Node, tiles [][]int) {
 
 	rooms := bsp.GetRooms(bspTree)
 	totalRooms := len(rooms)
+	// Decorations use the level's own decoration seed so exported levels
+	// redecorate identically.
+	decoRNG := rng.NewRNG(bsp.LevelDecorationSeed(g.seed))
+
+	var materials *render.MaterialMap
+	if len(tiles) > 0 && len(tiles[0]) > 0 {
+		materials = render.NewMaterialMap(len(tiles[0]), len(tiles))
+	}
 
 	for i, room := range rooms {
 		// Determine room type based on size, position, and genre
-		roomType := g.decorationSystem.DetermineRoomType(room.W, room.H, i, totalRooms, g.rng)
+		roomType := g.decorationSystem.DetermineRoomType(room.W, room.H, i, totalRooms, decoRNG)
+		switch room.Tag {
+		case bsp.RoomTagBossArena:
+			roomType = decoration.RoomBoss
+		case bsp.RoomTagShrine:
+			roomType = decoration.RoomShrine
+		case bsp.RoomTagShop:
+			roomType = decoration.RoomArmory // The merchant's stock
+		}
 		room.Type = int(roomType)
 
 		// Generate decorations for the room
-		decor := g.decorationSystem.DecorateRoom(roomType, room.X, room.Y, room.W, room.H, tiles, g.rng)
+		decor := g.decorationSystem.DecorateRoom(roomType, room.X, room.Y, room.W, room.H, tiles, decoRNG)
 		g.roomDecorations[i] = decor
+		if materials != nil {
+			ceiling := texture.SurfaceTextureName("ceiling", decor.CeilingMaterial.String())
+			if isOutdoorRoom(room) {
+				ceiling = render.SkyMaterial
+			}
+			materials.SetRect(room.X, room.Y, room.W, room.H,
+				texture.SurfaceTextureName("floor", decor.FloorMaterial.String()), ceiling)
+		}
 
 		logrus.WithFields(logrus.Fields{
 			"room_index": i,
@@ -1144,6 +1539,79 @@ func (g *Game) decorateRooms(bspTree *bsp.Node, tiles [][]int) {
 			"decos":      len(decor.Decorations),
 		}).Debug("Room decorated")
 	}
+
+	if materials != nil {
+		materials.ApplyFloorDetails(tiles)
+	}
+	if g.renderer != nil {
+		g.renderer.SetMaterialMap(materials)
+	}
+}
+
+// newWeatherEmitter creates the map-wide weather emitter for a genre. It
+// only emits over open-air tiles, is blown by the wind field and builds from
+// drizzle to storm and back, cueing sounds as the weather turns.
+func (g *Game) newWeatherEmitter(genreID string) *particle.WeatherEmitter {
+	w, h := float64(len(g.currentMap[0])), float64(len(g.currentMap))
+	e := particle.NewWeatherEmitter(g.particleSystem, genreID, w/2, h/2, w, h)
+	e.SetForces(particle.ForceField)
+	e.SetMask(g.isSkyTile)
+	e.OnStageChange(g.cueWeatherStage)
+	e.SetStages(particle.DefaultWeatherStages, true)
+	return e
+}
+
+// isSkyTile reports whether a tile is open to the sky.
+func (g *Game) isSkyTile(x, y int) bool {
+	return y >= 0 && y < len(g.skyMask) && x >= 0 && x < len(g.skyMask[y]) && g.skyMask[y][x]
+}
+
+// weatherStageSounds are the sounds played as the weather turns.
+var weatherStageSounds = map[string]string{
+	"rain":  "weather_rain",
+	"storm": "weather_thunder",
+}
+
+// cueWeatherStage plays the sound of a new weather stage, when the level
+// has open sky for the weather to fall from.
+func (g *Game) cueWeatherStage(stage particle.WeatherStage) {
+	sound, ok := weatherStageSounds[stage.Name]
+	if !ok || g.audioEngine == nil || g.camera == nil || !g.hasSky() {
+		return
+	}
+	_ = g.audioEngine.PlaySFX(sound, g.camera.X, g.camera.Y)
+}
+
+// hasSky reports whether any tile of the level is open to the sky.
+func (g *Game) hasSky() bool {
+	for _, row := range g.skyMask {
+		for _, open := range row {
+			if open {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// outdoorRoomArea is the smallest room area, in tiles, left open to the sky.
+const outdoorRoomArea = 100
+
+// isOutdoorRoom reports whether a room is an open-air courtyard. Only large
+// untagged rooms qualify, so setpiece rooms keep their enclosed feel.
+func isOutdoorRoom(room *bsp.Room) bool {
+	return room.Tag == bsp.RoomTagNone && room.W*room.H >= outdoorRoomArea
+}
+
+// surfaceMaterialNames returns the names of all room surface materials, for
+// pre-generating their floor and ceiling textures.
+func surfaceMaterialNames() []string {
+	all := decoration.AllMaterials()
+	names := make([]string, len(all))
+	for i, m := range all {
+		names[i] = m.String()
+	}
+	return names
 }
 
 // generateFloorDetails creates procedural floor variation overlays for visual variety.
@@ -1376,16 +1844,46 @@ func (g *Game) determineSecretDirection(x, y int, tiles [][]int) secret.Directio
 	return dir
 }
 
+// enemyRoleCycle is the order roles are handed out to a level's enemies,
+// so even small groups mix front-liners with support.
+var enemyRoleCycle = []ai.EnemyRole{ai.RoleTank, ai.RoleRanged, ai.RoleScout, ai.RoleAmbusher, ai.RoleHealer}
+
+// enemySquadSize is how many consecutively spawned enemies, in neighbouring
+// rooms, share a squad and alert each other.
+const enemySquadSize = 3
+
 // spawnEnemies spawns AI enemies in the level.
 func (g *Game) spawnEnemies() {
 	g.aiAgents = make([]*ai.Agent, 0)
+	g.agentPos = g.agentPos[:0]
+	g.agentEnts = g.agentEnts[:0]
+	g.agentTrees = g.agentTrees[:0]
+	g.perception.Clear()
+	g.boss = nil
+	g.squadTarget = nil
+	g.rangedSystem.Clear(g.world)
+	g.projectiles.Clear()
 	ai.SetGenre(g.genreID)
 	rooms := bsp.GetRooms(g.currentBSPTree)
+	g.squadPlanner.Clear()
+	g.squadPlanner.SetCover(g.coverTiles(rooms))
+
+	g.director = director.New(g.difficulty(), g.genreID, uint64(g.seed)^directorSeedSalt)
+	g.waveCount = 0
+	g.combatLog.Reset()
 
 	// Use dialogue name generator for enemy names
 	nameGen := dialogue.NewNameGenerator()
 
-	for i := 0; i < 3; i++ {
+	enemyCount := g.genConfig.EnemyBudget
+	if enemyCount <= 0 {
+		enemyCount = 3
+	}
+	if coop, ok := g.multiplayerMgr.(*network.CoopSession); ok {
+		enemyCount = coop.EnemyBudget(enemyCount)
+	}
+
+	for i := 0; i < enemyCount; i++ {
 		var spawnX, spawnY float64
 		if i+1 < len(rooms) {
 			// Spawn in different rooms, skip room 0 (player spawn)
@@ -1400,55 +1898,114 @@ func (g *Game) spawnEnemies() {
 			spawnX = float64(10 + i*5)
 			spawnY = float64(10 + i*3)
 		}
-		agent := ai.NewAgent("enemy_"+string(rune(i+'0')), spawnX, spawnY)
-		g.aiAgents = append(g.aiAgents, agent)
-
-		// Create ECS entity for the enemy with health bar
-		enemyEntity := g.world.AddEntity()
-		g.world.AddComponent(enemyEntity, &engine.Position{X: spawnX, Y: spawnY})
-		g.world.AddComponent(enemyEntity, &engine.Health{Current: 100, Max: 100})
-		g.world.AddComponent(enemyEntity, &healthbar.Component{
-			Visible:      true,
-			Width:        40,
-			Height:       4,
-			OffsetY:      20,
-			ShowWhenFull: false,
-			ThreatLevel:  1,
-		})
-
-		// Generate a procedural name for the enemy
-		enemySeed := int64(g.seed) + int64(enemyEntity*100)
-		enemyName := nameGen.Generate(g.genreID, dialogue.SpeakerHostile, enemySeed)
-		enemyLabel := entitylabel.NewEnemyLabel(enemyName)
-		g.world.AddComponent(enemyEntity, enemyLabel)
-
-		logrus.WithFields(logrus.Fields{
-			"entity_id": enemyEntity,
-			"name":      enemyName,
-			"x":         spawnX,
-			"y":         spawnY,
-		}).Debug("Spawned enemy with label")
+		g.spawnEnemy(nameGen, fmt.Sprintf("squad_%d", i/enemySquadSize), spawnX, spawnY)
 	}
 
-	// Spawn a boss enemy in the last room (1 in 3 chance)
+	// Spawn a boss enemy in the generator's boss arena (1 in 3 chance)
 	if len(rooms) > 3 && g.rng.Float64() < 0.33 {
-		g.spawnBoss(rooms[len(rooms)-1])
+		if arena := bsp.FindRoomByTag(g.currentBSPTree, bsp.RoomTagBossArena); arena != nil {
+			g.spawnBoss(arena)
+		}
+	}
+}
+
+// coverTiles returns the tiles of blocking props and of the blocking
+// decorations of rooms, which enemy squads take cover behind.
+func (g *Game) coverTiles(rooms []*bsp.Room) []ai.Coord {
+	var tiles []ai.Coord
+	if g.propsManager != nil {
+		for _, prop := range g.propsManager.GetProps() {
+			if prop.Collision {
+				tiles = append(tiles, ai.Coord{X: int(prop.X), Y: int(prop.Y)})
+			}
+		}
+	}
+	for i := range rooms {
+		decor, ok := g.roomDecorations[i]
+		if !ok {
+			continue
+		}
+		for _, d := range decor.Decorations {
+			if d.Blocking {
+				tiles = append(tiles, ai.Coord{X: d.X, Y: d.Y})
+			}
+		}
 	}
+	return tiles
+}
+
+// spawnEnemy adds an enemy agent of the given squad at (x, y), with an ECS
+// entity carrying its health bar and a procedural name, and returns it.
+// Roles are handed out in enemyRoleCycle order and each enemy is built from
+// a genre archetype of its role.
+func (g *Game) spawnEnemy(nameGen *dialogue.NameGenerator, squadID string, x, y float64) *ai.Agent {
+	i := len(g.aiAgents)
+	role := enemyRoleCycle[i%len(enemyRoleCycle)]
+	arch := g.archetypes.Pick(g.genreID, role, g.seed+uint64(i))
+	agent := ai.NewAgentFrom(fmt.Sprintf("enemy_%d", i), x, y, arch)
+	agent.Role = role
+	agent.SquadID = squadID
+	g.aiAgents = append(g.aiAgents, agent)
+	g.agentTrees = append(g.agentTrees, g.behaviors.NewRoleTree(agent.Role))
+
+	// Create ECS entity for the enemy with health bar
+	enemyEntity := g.world.AddEntity()
+	enemyPos := &engine.Position{X: x, Y: y}
+	g.world.AddComponent(enemyEntity, enemyPos)
+	g.agentPos = append(g.agentPos, enemyPos)
+	g.agentEnts = append(g.agentEnts, enemyEntity)
+	g.world.AddComponent(enemyEntity, &engine.Health{Current: int(arch.MaxHealth), Max: int(arch.MaxHealth)})
+	status.SetResistances(g.world, enemyEntity, statusResistances(agent.Resistances))
+	g.world.AddComponent(enemyEntity, &healthbar.Component{
+		Visible:      true,
+		Width:        40,
+		Height:       4,
+		OffsetY:      20,
+		ShowWhenFull: false,
+		ThreatLevel:  1,
+	})
+
+	// Generate a procedural name for the enemy
+	enemySeed := int64(g.seed) + int64(enemyEntity*100)
+	enemyName := nameGen.Generate(g.genreID, dialogue.SpeakerHostile, enemySeed)
+	enemyLabel := entitylabel.NewEnemyLabel(enemyName)
+	g.world.AddComponent(enemyEntity, enemyLabel)
+
+	logrus.WithFields(logrus.Fields{
+		"entity_id": enemyEntity,
+		"archetype": arch.ID,
+		"name":      enemyName,
+		"x":         x,
+		"y":         y,
+	}).Debug("Spawned enemy with label")
+	return agent
 }
 
-// spawnBoss spawns a boss enemy with phase transitions.
+// bossMaxHealth is the health of a level's boss.
+const bossMaxHealth = 1000.0
+
+// spawnBoss spawns the genre's boss in its arena. The boss controller
+// scripts its phases, attacks and arena mechanics; its ECS entity carries
+// the health bar, label and telegraphs.
 func (g *Game) spawnBoss(room *bsp.Room) {
 	spawnX := float64(room.X+room.W/2) + 0.5
 	spawnY := float64(room.Y+room.H/2) + 0.5
 
+	g.boss = combat.NewBossController(g.genreID, spawnX, spawnY, bossMaxHealth, g.seed+uint64(room.X*1000+room.Y))
+
 	// Create boss entity
 	bossEntity := g.world.AddEntity()
+	g.bossEntity = bossEntity
 
 	// Add position component
-	g.world.AddComponent(bossEntity, &engine.Position{X: spawnX, Y: spawnY})
+	g.bossPos = &engine.Position{X: spawnX, Y: spawnY}
+	g.world.AddComponent(bossEntity, g.bossPos)
+	g.bossMarker = &telegraph.PositionComponent{X: spawnX, Y: spawnY}
+	g.world.AddComponent(bossEntity, g.bossMarker)
 
 	// Add health component for health bar rendering
-	g.world.AddComponent(bossEntity, &engine.Health{Current: 1000, Max: 1000})
+	g.bossHealth = &engine.Health{Current: int(bossMaxHealth), Max: int(bossMaxHealth)}
+	g.world.AddComponent(bossEntity, g.bossHealth)
 
 	// Add health bar display component
 	g.world.AddComponent(bossEntity, &healthbar.Component{
@@ -1460,25 +2017,6 @@ func (g *Game) spawnBoss(room *bsp.Room) {
 		ThreatLevel:  3,
 	})
 
-	// Add health component (combat system)
-	healthComp := &combat.HealthComponent{
-		Current: 1000.0,
-		Max:     1000.0,
-	}
-	g.world.AddComponent(bossEntity, healthComp)
-
-	// Create and add boss phase component with genre-specific phases
-	// Use a new rand.Rand seeded from the game RNG
-	bossRand := rand.New(rand.NewSource(int64(g.seed + uint64(room.X*1000+room.Y))))
-	phases := combat.CreateBossPhases(g.genreID, bossRand)
-	bossPhaseComp := &combat.BossPhaseComponent{
-		CurrentPhase:       0,
-		Phases:             phases,
-		TransitionCooldown: 2.0,
-		InitialMaxHealth:   1000.0,
-	}
-	g.world.AddComponent(bossEntity, bossPhaseComp)
-
 	// Add positional component for backstab/flank vulnerability
 	g.positionalSystem.AddPositionalComponent(g.world, bossEntity, 0, 0)
 
@@ -1492,10 +2030,11 @@ func (g *Game) spawnBoss(room *bsp.Room) {
 	logrus.WithFields(logrus.Fields{
 		"entity_id":   bossEntity,
 		"name":        bossName,
+		"archetype":   g.boss.Archetype.Name,
 		"x":           spawnX,
 		"y":           spawnY,
 		"genre":       g.genreID,
-		"phase_count": len(phases),
+		"phase_count": len(g.boss.Phases.Phases),
 	}).Info("Boss spawned with phase transitions and label")
 }
 
@@ -1546,9 +2085,64 @@ func (g *Game) spawnDestructibles() {
 // initializeSquad initializes squad companions near the player.
 func (g *Game) initializeSquad() {
 	g.squadCompanions = squad.NewSquad(3)
+	g.squadCompanions.CurrentGenre = g.genreID
 	squad.SetGenre(g.genreID)
 	g.squadCompanions.AddMember("companion_1", "grunt", "assault_rifle", g.camera.X-2, g.camera.Y+1, g.seed)
 	g.squadCompanions.AddMember("companion_2", "medic", "pistol", g.camera.X-2, g.camera.Y-1, g.seed)
+	g.attachCompanionLanterns()
+}
+
+// companionLanternPreset is the small light each squad companion carries.
+var companionLanternPreset = lighting.LightPreset{
+	Name: "lantern", Radius: 3.0, Intensity: 0.45, R: 1.0, G: 0.8, B: 0.5, Flicker: true,
+}
+
+// attachCompanionLanterns gives each squad member an ECS entity with a
+// lantern attached. The lighting system moves the lantern with the entity;
+// syncCompanionEntities keeps the entity at the member's position.
+func (g *Game) attachCompanionLanterns() {
+	for _, e := range g.companionEntities {
+		g.world.RemoveEntity(e)
+	}
+	g.companionEntities = make(map[string]engine.Entity)
+	if g.lightingSystem == nil || g.squadCompanions == nil {
+		return
+	}
+
+	for i, m := range g.squadCompanions.GetMembers() {
+		e := g.world.AddEntity()
+		g.world.AddComponent(e, &engine.Position{X: m.X, Y: m.Y})
+		lantern := lighting.NewLightComponent(companionLanternPreset, int64(g.seed)+int64(i))
+		g.lightingSystem.AttachLight(g.world, e, lantern, 0, 0)
+		g.companionEntities[m.ID] = e
+	}
+}
+
+// syncCompanionEntities moves companion entities to their squad members and
+// removes those of members who left the squad.
+func (g *Game) syncCompanionEntities() {
+	if g.squadCompanions == nil {
+		return
+	}
+	present := make(map[string]bool, len(g.companionEntities))
+	posType := reflect.TypeOf(&engine.Position{})
+	for _, m := range g.squadCompanions.GetMembers() {
+		e, ok := g.companionEntities[m.ID]
+		if !ok {
+			continue
+		}
+		present[m.ID] = true
+		if comp, found := g.world.GetComponent(e, posType); found {
+			pos := comp.(*engine.Position)
+			pos.X, pos.Y = m.X, m.Y
+		}
+	}
+	for id, e := range g.companionEntities {
+		if !present[id] {
+			g.world.RemoveEntity(e)
+			delete(g.companionEntities, id)
+		}
+	}
 }
 
 // claimTerritories assigns faction control to dungeon rooms for territorial warfare.
@@ -1659,6 +2253,10 @@ func (g *Game) setupQuests(rooms []*bsp.Room) {
 		Rooms:       questRooms,
 	}
 	g.questTracker.GenerateWithLayout(g.seed, layout)
+	g.setupEventObjective()
+	g.addModQuests()
+	g.metaAtLevel = g.metaQuests.Snapshot()
+	g.statsAtLevel = g.profileStats
 
 	// Sync quest objectives with compass system for navigation indicators
 	g.syncObjectiveCompass()
@@ -1717,12 +2315,12 @@ func (g *Game) setupEventTriggers() {
 	g.alarmTrigger = event.NewAlarmTrigger("alarm_1", 30.0)
 	g.lockdownTrigger = event.NewTimedLockdown("lockdown_1", 180.0)
 
-	centerX := len(g.currentMap[0]) / 2
-	centerY := len(g.currentMap) / 2
-	g.bossArena = event.NewBossArenaEvent("boss_1", "center_room", 3, 5.0)
-
-	if int(g.camera.X) == centerX && int(g.camera.Y) == centerY {
-		g.bossArena.Trigger()
+	g.bossArenaRoom = bsp.FindRoomByTag(g.currentBSPTree, bsp.RoomTagBossArena)
+	if g.bossArenaRoom != nil {
+		roomID := fmt.Sprintf("room_%d", g.bossArenaRoom.Index)
+		g.bossArena = event.NewBossArenaEvent("boss_1", roomID, 3, 5.0)
+	} else {
+		g.bossArena = nil
 	}
 
 	event.SetGenre(g.genreID)
@@ -1742,6 +2340,22 @@ func (g *Game) generateHazards() {
 	}
 }
 
+// buildNavGrid prepares enemy route planning for the level, steering
+// enemies around hazards and traps.
+func (g *Game) buildNavGrid() {
+	g.navGrid = ai.NewNavGrid(g.currentMap, ai.DefaultNavCosts)
+	if g.hazardECSSystem != nil && g.world != nil {
+		for _, h := range g.hazardECSSystem.GetHazardsForRendering(g.world) {
+			g.navGrid.MarkHazard(int(h.X), int(h.Y))
+		}
+	}
+	if g.trapSystem != nil {
+		for _, t := range g.trapSystem.GetTraps() {
+			g.navGrid.MarkHazard(int(t.X), int(t.Y))
+		}
+	}
+}
+
 // spawnDynamicLights places procedural light entities in rooms.
 func (g *Game) spawnDynamicLights(rooms []*bsp.Room) {
 	if g.lightingSystem == nil {
@@ -1839,18 +2453,21 @@ func (g *Game) initializePlayer() {
 	g.ammoPool.Add("cells", 20)
 	g.ammoPool.Add("rockets", 0)
 
-	currentWeapon := g.arsenal.GetCurrentWeapon()
-	g.hud.Ammo = g.ammoPool.Get(currentWeapon.AmmoType)
+	g.hud.Ammo = g.ammoPool.Get(g.arsenal.ReservePool(g.arsenal.CurrentSlot))
 
 	g.keycards = make(map[string]bool)
 	g.automapVisible = false
 
 	// Create ECS player entity for status effects and other systems
 	g.playerEntity = g.world.NewPlayerEntity(spawnX, spawnY)
+	if h, ok := g.world.GetComponent(g.playerEntity, reflect.TypeOf(&engine.Health{})); ok {
+		g.playerEntityHealth = h.(*engine.Health).Current
+	}
 
 	// Add defense component to player
 	defenseComp := combat.NewDefenseComponent(g.genreID)
 	g.world.AddComponent(g.playerEntity, defenseComp)
+	status.SetResistances(g.world, g.playerEntity, statusResistances(defenseComp.Resistances))
 
 	// Add faction reputation component to player
 	repComp := faction.InitializePlayerReputation(g.genreID)
@@ -1915,8 +2532,7 @@ func (g *Game) initializeGameSystems() {
 	if err := g.progression.SetGenre(g.genreID); err != nil {
 		logrus.WithError(err).Warn("Failed to set progression genre")
 	}
-	g.statusReg = status.NewRegistry()
-	g.statusSystem = status.NewSystem(g.statusReg)
+	g.statusReg.SetGenre(g.genreID)
 	status.SetGenre(g.genreID)
 
 	g.shopCredits = shop.NewCredit(100)
@@ -1933,12 +2549,8 @@ func (g *Game) initializeGameSystems() {
 	g.skillsTreeIdx = 0
 	g.skillsNodeIdx = 0
 
-	if g.modLoader == nil {
-		g.modLoader = mod.NewLoader()
-	}
-	g.scanMods()
-
 	g.playerInventory = inventory.NewInventory()
+	g.playerArmor = inventory.NewLoadout()
 	inventory.SetGenre(g.genreID)
 }
 
@@ -1969,6 +2581,10 @@ func (g *Game) setGenre(genreID string) {
 	g.setGenreForV5Systems(genreID)
 
 	g.textureAtlas.GenerateWallSet(genreID)
+	g.textureAtlas.GenerateCutoutSet(genreID)
+	g.textureAtlas.GenerateSurfaceSet(genreID, surfaceMaterialNames())
+	g.textureAtlas.GenerateDetailSet(genreID)
+	g.textureAtlas.GenerateSkySet(genreID)
 	g.textureAtlas.GenerateGenreAnimations(genreID)
 }
 
@@ -1988,6 +2604,7 @@ func (g *Game) setGenreForV2Systems(genreID string) {
 	g.arsenal.SetGenre(genreID)
 	ammo.SetGenre(genreID)
 	g.combatSystem.SetGenre(genreID)
+	g.statusReg.SetGenre(genreID)
 	status.SetGenre(genreID)
 	loot.SetGenre(genreID)
 	if err := g.progression.SetGenre(genreID); err != nil {
@@ -2001,15 +2618,17 @@ func (g *Game) setGenreForV2Systems(genreID string) {
 func (g *Game) setGenreForV3Systems(genreID string) {
 	g.textureAtlas.SetGenre(genreID)
 	g.lightMap.SetGenre(genreID)
+	g.dayCycle.SetGenre(genreID)
 	g.shadowSystem.SetGenre(genreID)
 	g.lightingSystem.SetGenre(genreID)
 	g.postProcessor.SetGenre(genreID)
 	g.renderer.SetGenre(genreID)
 
 	if g.particleSystem != nil && len(g.currentMap) > 0 && len(g.currentMap[0]) > 0 {
-		g.weatherEmitter = particle.NewWeatherEmitter(g.particleSystem, genreID, 0, 0, float64(len(g.currentMap[0])), float64(len(g.currentMap)))
+		g.weatherEmitter = g.newWeatherEmitter(genreID)
 	}
 	if g.particleSystem != nil {
+		g.particleSystem.SetCeiling(particleCeiling(genreID))
 		g.impactEmitter = particle.NewImpactEffectEmitter(g.particleSystem, genreID)
 	}
 }
@@ -2125,90 +2744,367 @@ func (g *Game) setGenreForGameplaySystems(genreID string) {
 	trySetGenre(g.eyeGlintSystem, genreID)
 }
 
-// loadGame loads a saved game state.
-func (g *Game) loadGame(slot int) {
-	state, err := save.Load(slot)
-	if err != nil {
-		return
-	}
-
-	g.genreID = state.Genre
-	g.seed = uint64(state.Seed)
-	g.rng.Seed(g.seed)
-
-	// Restore map
-	g.currentMap = state.Map.Tiles
-	g.raycaster.SetMap(g.currentMap)
-
-	// Restore camera/player
-	g.camera.X = state.Player.X
-	g.camera.Y = state.Player.Y
-	g.camera.DirX = state.Player.DirX
-	g.camera.DirY = state.Player.DirY
-	g.camera.Pitch = state.Player.Pitch
-
-	// Restore HUD
-	g.hud.Health = state.Player.Health
-	g.hud.Armor = state.Player.Armor
-	g.hud.Ammo = state.Player.Ammo
-
-	// Restore progression
-	if g.progression != nil {
-		// Create new progression and add XP to restore state
-		g.progression = progression.NewProgression()
-		// Set level by adding appropriate XP
-		// Calculate total XP needed to reach saved level
-		totalXP := 0
-		for lvl := 1; lvl < state.Progression.Level; lvl++ {
-			totalXP += lvl * 100 // Base XP per level
+// rolledWeaponSeeds returns the seeds of the rolled weapons carried, by
+// slot, or nil when all are stock.
+func (g *Game) rolledWeaponSeeds() map[int]uint64 {
+	var rolls map[int]uint64
+	for slot, w := range g.arsenal.Weapons {
+		if w.Seed == 0 {
+			continue
 		}
-		totalXP += state.Progression.XP // Add remaining XP
-		if err := g.progression.AddXP(totalXP); err != nil {
-			logrus.WithError(err).Warn("Failed to restore progression XP")
+		if rolls == nil {
+			rolls = make(map[int]uint64)
 		}
+		rolls[slot] = w.Seed
 	}
+	return rolls
+}
 
-	// Restore keycards
-	if state.Keycards != nil {
-		g.keycards = state.Keycards
-	} else {
-		g.keycards = make(map[string]bool)
+// attachmentIDs returns the IDs of the attachments fitted to each weapon
+// slot, or nil when none are.
+func (g *Game) attachmentIDs() map[int][]string {
+	var mods map[int][]string
+	for slot := range g.arsenal.Weapons {
+		for _, at := range g.arsenal.AttachedTo(slot) {
+			if mods == nil {
+				mods = make(map[int][]string)
+			}
+			mods[slot] = append(mods[slot], at.ID)
+		}
 	}
+	return mods
+}
 
-	// Restore ammo pool
-	if state.AmmoPool != nil && g.ammoPool != nil {
-		// Clear current ammo and restore from save
-		for ammoType, amount := range state.AmmoPool {
-			g.ammoPool.Set(ammoType, amount)
+// wornArmor returns the armor pieces the player wears and their condition.
+func (g *Game) wornArmor() []save.ArmorPiece {
+	var pieces []save.ArmorPiece
+	for _, w := range g.playerArmor.Slots {
+		if w != nil {
+			pieces = append(pieces, save.ArmorPiece{ID: w.ID, Seed: w.Seed, Condition: w.Condition})
 		}
 	}
+	return pieces
+}
 
-	// Restore inventory
-	if g.playerInventory != nil && len(state.Inventory.Items) > 0 {
-		g.playerInventory = inventory.NewInventory()
-		for _, saveItem := range state.Inventory.Items {
-			g.playerInventory.Add(inventory.Item{
-				ID:   saveItem.ID,
-				Name: saveItem.Name,
-				Qty:  saveItem.Qty,
-			})
+// restoreArmor wears the saved armor pieces, taking off any others.
+func (g *Game) restoreArmor(pieces []save.ArmorPiece) {
+	inventory.SetGenre(g.genreID)
+	g.playerArmor = inventory.NewLoadout()
+	for _, p := range pieces {
+		if !g.playerArmor.Restore(p.ID, p.Seed, p.Condition) {
+			logrus.WithField("armor", p.ID).Warn("Dropping unknown armor piece from save")
 		}
 	}
+	g.updateArmorResistances()
+}
 
-	// Set genre for all systems
-	g.world.SetGenre(g.genreID)
-	g.renderer.SetGenre(g.genreID)
-	g.raycaster.SetGenre(g.genreID)
-
-	g.state = StatePlaying
-	g.menuManager.Hide()
+// restoreAttachments refits the saved attachments, removing any others.
+func (g *Game) restoreAttachments(mods map[int][]string) {
+	g.arsenal.Attached = nil
+	for slot, ids := range mods {
+		for _, id := range ids {
+			g.arsenal.Attach(slot, id)
+		}
+	}
 }
 
-// updatePlaying handles gameplay updates.
+// restoreRolledWeapons rerolls the weapons saved by seed and returns every
+// other slot to its stock weapon.
+func (g *Game) restoreRolledWeapons(rolls map[int]uint64) {
+	stock := weapon.NewArsenal()
+	stock.SetGenre(g.genreID)
+	for slot := range g.arsenal.Weapons {
+		g.arsenal.Weapons[slot] = stock.Weapons[slot]
+	}
+	for _, seed := range rolls {
+		slot, w := weapon.Roll(seed, g.genreID)
+		g.arsenal.Weapons[slot] = w
+	}
+}
+
+// worldState returns the state of the current level's systems, to save.
+func (g *Game) worldState() *save.WorldState {
+	w := &save.WorldState{}
+	for _, agent := range g.aiAgents {
+		w.Enemies = append(w.Enemies, save.Enemy{
+			ID: agent.ID, Squad: agent.SquadID, X: agent.X, Y: agent.Y, Health: agent.Health, State: int(agent.State),
+		})
+	}
+	if g.boss != nil {
+		w.Boss = &save.Enemy{ID: "boss", X: g.boss.X, Y: g.boss.Y, Health: g.boss.Health}
+	}
+	if g.escort != nil {
+		a := g.escort.Agent
+		w.Escort = &save.Escort{X: a.X, Y: a.Y, Health: a.Health, Following: g.escort.Following}
+	}
+	if g.destructibleSystem != nil {
+		for _, d := range g.destructibleSystem.GetAll() {
+			w.Destructibles = append(w.Destructibles, save.Destructible{ID: d.ID, Health: d.GetHealth(), Destroyed: d.IsDestroyed()})
+		}
+		sort.Slice(w.Destructibles, func(i, j int) bool { return w.Destructibles[i].ID < w.Destructibles[j].ID })
+	}
+	if g.secretManager != nil {
+		for _, sw := range g.secretManager.GetAll() {
+			if sw.State != secret.StateIdle {
+				w.Secrets = append(w.Secrets, save.Secret{
+					X: sw.X, Y: sw.Y, State: sw.State, Progress: sw.Progress, DiscoveredBy: sw.DiscoveredBy, RewardSpawned: sw.RewardSpawned,
+				})
+			}
+		}
+	}
+	if g.questTracker != nil {
+		for _, p := range g.questTracker.Snapshot() {
+			obj := save.Objective{ID: p.ID, Progress: p.Progress, Complete: p.Complete, Failed: p.Failed, Claimed: p.Claimed}
+			for _, it := range p.Items {
+				obj.Items = append(obj.Items, save.Item{ID: it.ID, Name: it.Name, Qty: it.Qty})
+			}
+			w.Objectives = append(w.Objectives, obj)
+		}
+	}
+	for _, item := range g.loreItems {
+		w.Lore = append(w.Lore, save.LoreItem{
+			ID: item.ID, Type: int(item.Type), X: item.PosX, Y: item.PosY, Text: item.Text,
+			Context: item.Context, CodexID: item.CodexID, Activated: item.Activated,
+		})
+	}
+	for _, e := range g.loreCodex.GetFoundEntries() {
+		w.Codex = append(w.Codex, e.ID)
+	}
+	if g.propsManager != nil {
+		for _, p := range g.propsManager.GetProps() {
+			w.Props = append(w.Props, save.Prop{ID: p.ID, X: p.X, Y: p.Y, Type: int(p.SpriteType), Collision: p.Collision, Name: p.Name})
+		}
+	}
+	if g.hazardECSSystem != nil {
+		for _, p := range g.hazardECSSystem.Snapshot(g.world) {
+			h := p.Hazard
+			w.Hazards = append(w.Hazards, save.Hazard{
+				X: p.X, Y: p.Y, Type: int(h.Type), State: int(h.State), Timer: h.Timer,
+				CycleDuration: h.CycleDuration, ActiveDuration: h.ActiveDuration, ChargeDuration: h.ChargeDuration,
+				CooldownDuration: h.CooldownDuration, Damage: h.Damage, StatusEffect: h.StatusEffect,
+				Persistent: h.Persistent, Triggered: h.Triggered, Width: h.Width, Height: h.Height, Color: h.Color,
+			})
+		}
+	}
+	return w
+}
+
+// restoreWorld applies the saved state of the level's systems over the
+// regenerated level. Enemies are matched by spawn order; those spawned
+// after the level began are spawned again.
+func (g *Game) restoreWorld(w *save.WorldState) {
+	nameGen := dialogue.NewNameGenerator()
+	for i, e := range w.Enemies {
+		if i >= len(g.aiAgents) {
+			g.spawnEnemy(nameGen, e.Squad, e.X, e.Y)
+		}
+		agent := g.aiAgents[i]
+		agent.X, agent.Y, agent.Health, agent.State = e.X, e.Y, e.Health, ai.State(e.State)
+		g.agentPos[i].X, g.agentPos[i].Y = e.X, e.Y
+	}
+	for _, agent := range g.aiAgents[len(w.Enemies):] {
+		agent.Health = 0
+	}
+	if b := w.Boss; b != nil && g.boss != nil {
+		g.boss.X, g.boss.Y, g.boss.Health = b.X, b.Y, b.Health
+		g.bossPos.X, g.bossPos.Y = b.X, b.Y
+		g.bossMarker.X, g.bossMarker.Y = b.X, b.Y
+		g.bossHealth.Current = int(math.Ceil(b.Health))
+	}
+
+	for _, s := range w.Secrets {
+		if sw := g.secretManager.Get(s.X, s.Y); sw != nil {
+			sw.State, sw.Progress, sw.DiscoveredBy, sw.RewardSpawned = s.State, s.Progress, s.DiscoveredBy, s.RewardSpawned
+		}
+	}
+
+	progress := make([]quest.ObjectiveProgress, len(w.Objectives))
+	for i, o := range w.Objectives {
+		progress[i] = quest.ObjectiveProgress{ID: o.ID, Progress: o.Progress, Complete: o.Complete, Failed: o.Failed, Claimed: o.Claimed}
+		for _, it := range o.Items {
+			progress[i].Items = append(progress[i].Items, quest.ItemReward{ID: it.ID, Name: it.Name, Qty: it.Qty})
+		}
+	}
+	g.questTracker.Restore(progress)
+	g.setupEventObjective()
+	if e := w.Escort; e != nil && g.escort != nil {
+		g.escort.Agent.X, g.escort.Agent.Y, g.escort.Agent.Health = e.X, e.Y, e.Health
+		g.escort.Following = e.Following
+	}
+	// After setupEventObjective, which adds the sabotage targets
+	for _, d := range w.Destructibles {
+		if obj, ok := g.destructibleSystem.Get(d.ID); ok {
+			obj.Health, obj.Destroyed = d.Health, d.Destroyed
+		}
+	}
+	g.syncObjectiveCompass()
+
+	if w.Lore != nil {
+		g.loreItems = make([]*lore.LoreItem, len(w.Lore))
+		for i, l := range w.Lore {
+			g.loreItems[i] = &lore.LoreItem{
+				ID: l.ID, Type: lore.LoreItemType(l.Type), PosX: l.X, PosY: l.Y, Text: l.Text,
+				Context: l.Context, CodexID: l.CodexID, Activated: l.Activated,
+			}
+			if _, ok := g.loreCodex.GetEntry(l.CodexID); !ok {
+				g.loreCodex.AddEntry(g.loreGenerator.Generate(l.CodexID))
+			}
+		}
+	}
+	for _, id := range w.Codex {
+		if _, ok := g.loreCodex.GetEntry(id); !ok {
+			g.loreCodex.AddEntry(g.loreGenerator.Generate(id))
+		}
+		g.loreCodex.MarkFound(id)
+	}
+
+	if g.propsManager != nil && w.Props != nil {
+		g.propsManager.Clear()
+		for _, p := range w.Props {
+			g.propsManager.AddProp(&props.Prop{ID: p.ID, X: p.X, Y: p.Y, SpriteType: props.PropType(p.Type), Collision: p.Collision, Name: p.Name})
+		}
+		g.bakeStaticLights()
+	}
+	if g.hazardECSSystem != nil {
+		placed := make([]hazard.Placed, len(w.Hazards))
+		for i, h := range w.Hazards {
+			placed[i] = hazard.Placed{X: h.X, Y: h.Y, Hazard: hazard.HazardComponent{
+				Type: hazard.Type(h.Type), State: hazard.State(h.State), Timer: h.Timer,
+				CycleDuration: h.CycleDuration, ActiveDuration: h.ActiveDuration, ChargeDuration: h.ChargeDuration,
+				CooldownDuration: h.CooldownDuration, Damage: h.Damage, StatusEffect: h.StatusEffect,
+				Persistent: h.Persistent, Triggered: h.Triggered, Width: h.Width, Height: h.Height, Color: h.Color,
+			}}
+		}
+		g.hazardECSSystem.Restore(g.world, placed)
+	}
+}
+
+// loadGame loads a saved game state.
+func (g *Game) loadGame(slot int) error {
+	state, err := save.Load(slot)
+	if err != nil {
+		return err
+	}
+
+	g.genreID = state.Genre
+	g.seed = uint64(state.Seed)
+	g.levelDepth = max(state.Depth, 1)
+	g.playtime = state.Playtime
+	g.combatLog.Reset()
+	g.projectiles.Clear()
+
+	// Restore camera/player first, so the squad regenerates beside it
+	g.camera.X = state.Player.X
+	g.camera.Y = state.Player.Y
+	g.camera.DirX = state.Player.DirX
+	g.camera.DirY = state.Player.DirY
+	g.camera.Pitch = state.Player.Pitch
+
+	// Regenerate the level from its seed, then put back the saved map and
+	// the state of the level's systems. Saves hold the whole map, so
+	// overworlds stop streaming.
+	g.generateLevel()
+	g.populateLevel()
+	g.chunkWorld = nil
+	g.currentMap = state.Map.Tiles
+	g.raycaster.SetMap(g.currentMap)
+	g.updateLightOccluders()
+	g.buildNavGrid()
+	if state.World != nil {
+		g.restoreWorld(state.World)
+	}
+
+	// Restore HUD
+	g.hud.Health = state.Player.Health
+	g.hud.Armor = state.Player.Armor
+	g.hud.Ammo = state.Player.Ammo
+
+	// Restore progression
+	if g.progression != nil {
+		// Create new progression and add XP to restore state
+		g.progression = progression.NewProgression()
+		// Set level by adding appropriate XP
+		// Calculate total XP needed to reach saved level
+		totalXP := 0
+		for lvl := 1; lvl < state.Progression.Level; lvl++ {
+			totalXP += lvl * 100 // Base XP per level
+		}
+		totalXP += state.Progression.XP // Add remaining XP
+		if err := g.progression.AddXP(totalXP); err != nil {
+			logrus.WithError(err).Warn("Failed to restore progression XP")
+		}
+	}
+
+	// Restore time of day
+	if g.dayCycle != nil {
+		g.dayCycle.SetGenre(g.genreID)
+		if l := state.Lighting; l != nil {
+			g.dayCycle.Restore(l.Hour, l.Speed, l.TargetHour, l.TransitionLeft)
+		}
+	}
+
+	// Restore keycards
+	if state.Keycards != nil {
+		g.keycards = state.Keycards
+	} else {
+		g.keycards = make(map[string]bool)
+	}
+
+	// Restore ammo pool
+	if state.AmmoPool != nil && g.ammoPool != nil {
+		// Clear current ammo and restore from save
+		for ammoType, amount := range state.AmmoPool {
+			g.ammoPool.Set(ammoType, amount)
+		}
+	}
+
+	g.restoreRolledWeapons(state.Rolls)
+	g.restoreAttachments(state.Mods)
+	g.arsenal.Loaded = maps.Clone(state.Loads)
+	g.restoreArmor(state.Armor)
+	g.levelCache = newLevelCache()
+	g.pickups.Clear(g.world)
+	if state.Cache != nil {
+		g.levelCache = restoreContainer(state.Cache, false)
+	}
+	g.restoreCampaign(state.Quests)
+
+	// Restore magazines
+	g.arsenal.CancelReload()
+	for slot, rounds := range state.Clips {
+		g.arsenal.Clips[slot] = rounds
+	}
+
+	// Restore inventory
+	if g.playerInventory != nil && len(state.Inventory.Items) > 0 {
+		g.playerInventory = inventory.NewInventory()
+		for _, saveItem := range state.Inventory.Items {
+			g.playerInventory.Add(inventory.Item{
+				ID:   saveItem.ID,
+				Name: saveItem.Name,
+				Qty:  saveItem.Qty,
+				Seed: saveItem.Seed,
+			})
+		}
+	}
+
+	// Set genre for all systems
+	g.world.SetGenre(g.genreID)
+	g.renderer.SetGenre(g.genreID)
+	g.raycaster.SetGenre(g.genreID)
+
+	g.state = StatePlaying
+	g.menuManager.Hide()
+	return nil
+}
+
+// updatePlaying handles gameplay updates.
 func (g *Game) updatePlaying() error {
 	if handled := g.handleMenuActions(); handled {
 		return nil
 	}
+	g.updateVoice()
+	g.updateMatch()
 
 	// Update camera effects (shake, flash, zoom, chromatic aberration)
 	if g.cameraFXSystem != nil {
@@ -2222,10 +3118,23 @@ func (g *Game) updatePlaying() error {
 
 	g.handlePlayerActions()
 	g.handleWeaponFiring()
+	g.handleAltFire()
+	g.handleBayonet()
+	g.handleAmmoCycle()
+	g.handleStash()
 
 	g.arsenal.Update()
-	g.updateReloadBarState() // Update reload bar based on weapon animator state
+	g.updateAimZoom()
+	g.updateHUDAmmo()
+	g.updateReloadBarState()
 	g.updateAIAgents()
+	g.updateEnemyShots()
+	g.updatePlayerProjectiles()
+	g.updateReactions()
+	g.updateDirector()
+	g.combatLog.Advance(common.DeltaTime)
+	g.playtime += time.Second / common.TargetFPS
+	g.updateBoss()
 	g.updateSquadAndEventTriggers()
 	g.updateQuestObjectives()
 	g.updateV3Systems()
@@ -2233,8 +3142,11 @@ func (g *Game) updatePlaying() error {
 
 	g.animationTicker++
 
+	g.updateEncumbrance()
 	deltaX, deltaY, deltaPitch := g.processPlayerMovement()
 	g.handleCollisionAndMovement(deltaX, deltaY, deltaPitch)
+	g.updatePickups()
+	g.streamChunks()
 	g.checkTutorialCompletion(deltaX, deltaY)
 
 	// Handle defensive actions
@@ -2263,21 +3175,12 @@ func (g *Game) syncPlayerEntityHealth() {
 
 	health := comp.(*engine.Health)
 
-	// Sync HUD to entity (entity is authoritative for status effects)
-	if health.Current != g.hud.Health {
-		g.hud.Health = health.Current
-	}
-
-	// Keep entity health in sync with HUD changes (from other damage sources)
-	if g.hud.Health != health.Current {
-		health.Current = g.hud.Health
-		if health.Current < 0 {
-			health.Current = 0
-		}
-		if health.Current > health.Max {
-			health.Current = health.Max
-		}
-	}
+	// Health the entity lost or gained to ECS systems since the last sync
+	// carries over to the HUD, which status effects and every other
+	// damage source change directly.
+	g.hud.Health += health.Current - g.playerEntityHealth
+	health.Current = max(0, min(g.hud.Health, health.Max))
+	g.playerEntityHealth = health.Current
 }
 
 // syncPlayerFacing updates player entity facing to match camera direction.
@@ -2333,6 +3236,11 @@ func (g *Game) syncPlayerCrosshair() {
 		default:
 			ch.WeaponType = "ranged"
 		}
+
+		// Open the crosshair to the edge of the spread bloom's cone.
+		if g.camera.FOV > 0 {
+			ch.Spread = float64(config.C.InternalWidth) * g.arsenal.Spread() / 2 / g.camera.FOV
+		}
 	}
 }
 
@@ -2386,23 +3294,14 @@ func (g *Game) getPlayerWeaponSwayOffset() (float64, float64) {
 	return sway.GetSwayOffset()
 }
 
-// updateReloadBarState syncs reload progress from weapon animator to reload bar UI.
+// updateReloadBarState syncs reload progress from the arsenal to reload bar UI.
 func (g *Game) updateReloadBarState() {
-	if g.reloadBarSystem == nil || g.arsenal == nil || g.arsenal.Animator == nil {
+	if g.reloadBarSystem == nil || g.arsenal == nil {
 		return
 	}
 
-	animator := g.arsenal.Animator
-	isReloading := animator.CurrentState == weapon.AnimReload
-
-	if isReloading {
-		// Calculate progress from animation frames
-		anim, ok := animator.Animations[weapon.AnimReload]
-		if ok && len(anim.Frames) > 0 {
-			totalFrames := len(anim.Frames) * anim.FrameDuration
-			currentProgress := float64(animator.CurrentFrame*anim.FrameDuration+animator.FrameCounter) / float64(totalFrames)
-			g.reloadBarSystem.SetReloadState(true, currentProgress, 1.0)
-		}
+	if g.arsenal.Reloading() {
+		g.reloadBarSystem.SetReloadState(true, g.arsenal.ReloadProgress(), 1.0)
 	} else {
 		g.reloadBarSystem.SetReloadState(false, 0, 0)
 	}
@@ -2541,6 +3440,27 @@ func (g *Game) handleMenuActions() bool {
 		return true
 	}
 
+	if g.input.IsJustPressed(input.ActionProfiler) && g.profiler != nil {
+		g.profiler.Toggle()
+	}
+
+	if g.input.IsJustPressed(input.ActionAIDebug) && g.aiDebug != nil {
+		g.aiDebug.Toggle()
+	}
+
+	if g.input.IsJustPressed(input.ActionAIDump) {
+		g.dumpAIDebug()
+	}
+
+	if g.input.IsJustPressed(input.ActionScreenshot) {
+		g.screenshotPending = true
+	}
+
+	if g.input.IsJustPressed(input.ActionPhotoMode) {
+		g.enterPhotoMode()
+		return true
+	}
+
 	if g.input.IsJustPressed(input.ActionAutomap) {
 		g.automapVisible = !g.automapVisible
 		// Toggle collapsible minimap expansion when key pressed
@@ -2584,10 +3504,16 @@ func (g *Game) handlePlayerActions() {
 		g.useQuickSlotItem()
 	}
 
+	if g.input.IsJustPressed(input.ActionReload) {
+		g.arsenal.StartReload()
+	}
+
 	if g.input.IsJustPressed(input.ActionInteract) {
 		g.tryCollectLore()
 		g.tryInteractDoor()
 	}
+
+	g.handleSquadCommands()
 }
 
 // handleWeaponFiring processes weapon firing and hit detection.
@@ -2600,32 +3526,185 @@ func (g *Game) handleWeaponFiring() {
 	if currentWeapon.Name == "" {
 		return
 	}
-
-	ammoType := currentWeapon.AmmoType
-	availableAmmo := g.ammoPool.Get(ammoType)
-
-	if currentWeapon.Type != weapon.TypeMelee && availableAmmo <= 0 {
+	if currentWeapon.Type == weapon.TypeMelee {
+		g.handleMeleeSwing(false)
 		return
 	}
 
+	// Fire draws on the magazine and returns nil while cooling down,
+	// reloading or empty.
 	raycastFn := g.createEnemyRaycastFunction()
 	hitResults := g.arsenal.Fire(g.camera.X, g.camera.Y, g.camera.DirX, g.camera.DirY, raycastFn)
+	if hitResults == nil {
+		return
+	}
+	g.resolveShot(currentWeapon, hitResults)
+}
 
-	if currentWeapon.Type != weapon.TypeMelee {
-		g.ammoPool.Consume(ammoType, 1)
-		g.hud.Ammo = g.ammoPool.Get(ammoType)
+// handleAltFire fires the current weapon's secondary mode. Zoomed and
+// charged modes aim or charge while the button is held and fire on
+// release; the rest fire on press.
+func (g *Game) handleAltFire() {
+	alt := g.arsenal.GetCurrentWeapon().Alt
+	switch {
+	case alt.Mode == weapon.AltNone:
+		return
+	case alt.Held() && g.input.IsPressed(input.ActionAltFire):
+		g.arsenal.HoldAlt()
+		return
+	case alt.Held() && !g.arsenal.AltHeld():
+		return
+	case !alt.Held() && !g.input.IsJustPressed(input.ActionAltFire):
+		return
+	}
+
+	shot, hitResults := g.arsenal.FireAlt(g.camera.X, g.camera.Y, g.camera.DirX, g.camera.DirY, g.createEnemyRaycastFunction())
+	if hitResults == nil {
+		return
+	}
+	g.resolveShot(shot, hitResults)
+}
+
+// handleAmmoCycle loads the current weapon with the next ammo variant the
+// player has rounds of.
+func (g *Game) handleAmmoCycle() {
+	if !g.input.IsJustPressed(input.ActionCycleAmmo) {
+		return
+	}
+	id, ok := g.arsenal.CycleAmmo()
+	if !ok {
+		return
+	}
+	name := "Standard"
+	if v, found := ammo.FindVariant(id); found {
+		name = v.Name
+	}
+	g.hud.ShowMessage(fmt.Sprintf("Loading %s rounds", name))
+}
+
+// handleBayonet stabs with the current weapon's bayonet, or swings a melee
+// weapon's heavy attack.
+func (g *Game) handleBayonet() {
+	if !g.input.IsJustPressed(input.ActionMelee) {
+		return
+	}
+	if g.arsenal.GetCurrentWeapon().Type == weapon.TypeMelee {
+		g.handleMeleeSwing(true)
+		return
+	}
+	stab, hitResults := g.arsenal.Bash(g.camera.X, g.camera.Y, g.camera.DirX, g.camera.DirY, g.createEnemyRaycastFunction())
+	if hitResults == nil {
+		return
+	}
+	g.resolveShot(stab, hitResults)
+}
+
+// handleMeleeSwing swings the current melee weapon, light or heavy, and
+// strikes every enemy inside the swing's attack shape. Swings that stagger
+// stagger the enemies they hit.
+func (g *Game) handleMeleeSwing(heavy bool) {
+	swing, swung, ok := g.arsenal.Swing(heavy)
+	if !ok {
+		return
+	}
+	hitResults := g.sweepMelee(swing, swung)
+	g.resolveShot(swung, hitResults)
+
+	for _, hit := range hitResults {
+		i := int(hit.EntityID) - 1
+		if swing.Stagger > 0 && i >= 0 && i < len(g.aiAgents) && g.aiAgents[i].Health > 0 {
+			g.aiAgents[i].Stagger(swing.Stagger)
+		}
+	}
+	if combo := g.arsenal.Combo(); combo > 1 {
+		g.hud.ShowMessage(fmt.Sprintf("%s x%d", swing.Name, combo))
+	}
+}
+
+// sweepMelee returns a hit for every enemy and the boss the swing's attack
+// shape overlaps as swung from the player along their aim, or a single
+// miss when it strikes nothing.
+func (g *Game) sweepMelee(swing weapon.Swing, swung weapon.Weapon) []weapon.HitResult {
+	x, y, dirX, dirY := g.camera.X, g.camera.Y, g.camera.DirX, g.camera.DirY
+	var hitbox *collision.Collider
+	if shape := g.collisionGeometry.GetAttackShape(swing.Shape); shape != nil {
+		hitbox = shape.Place(x, y, dirX, dirY, swung.Range, collision.LayerPlayer, collision.LayerEnemy)
+	} else {
+		hitbox = collision.CreateConeCollider(x, y, dirX, dirY, swung.Range, math.Pi/2, collision.LayerPlayer, collision.LayerEnemy)
+	}
+
+	var results []weapon.HitResult
+	strike := func(id uint64, tx, ty, radius float64) {
+		body := collision.NewCircleCollider(tx, ty, radius, collision.LayerEnemy, collision.LayerPlayer)
+		if collision.TestCollision(hitbox, body) {
+			results = append(results, weapon.HitResult{Hit: true, Distance: math.Hypot(tx-x, ty-y), Damage: swung.Damage, HitX: tx, HitY: ty, EntityID: id})
+		}
+	}
+	for i, agent := range g.aiAgents {
+		if agent.Health > 0 {
+			strike(uint64(i+1), agent.X, agent.Y, agentShotRadius)
+		}
+	}
+	if b := g.boss; b != nil && !b.Dead() {
+		strike(bossTargetID, b.X, b.Y, bossShotRadius)
+	}
+	if len(results) == 0 {
+		results = append(results, weapon.HitResult{})
+	}
+	return results
+}
+
+// updateAimZoom narrows the view while the player aims a zooming alt fire.
+func (g *Game) updateAimZoom() {
+	if g.cameraFXSystem == nil {
+		return
 	}
+	g.cameraFXSystem.SetZoom(g.arsenal.Zoom())
+	fov := config.C.FOV / g.cameraFXSystem.GetZoom()
+	g.camera.FOV = fov
+	g.raycaster.FOV = fov
+}
 
+// resolveShot applies a shot from the current weapon, as fired by its
+// primary or alt fire: effects, hits, logging and the noise it makes.
+func (g *Game) resolveShot(currentWeapon weapon.Weapon, hitResults []weapon.HitResult) {
 	// Spawn muzzle flash for ranged weapons
 	if currentWeapon.Type != weapon.TypeMelee && g.muzzleFlashSystem != nil {
 		g.spawnMuzzleFlash(currentWeapon)
 	}
 
-	g.processWeaponHits(hitResults, currentWeapon)
+	if currentWeapon.Type == weapon.TypeMelee {
+		g.spawnMeleeArc()
+	}
+
+	hits := g.processWeaponHits(hitResults, currentWeapon)
+	// Launched shots return no results; they are logged when they land.
+	if len(hitResults) > 0 {
+		g.combatLog.Record(combatlog.Event{Kind: combatlog.KindShot, Source: currentWeapon.Name, Hits: hits})
+	}
 	g.checkDestructibleHits(hitResults, currentWeapon)
 	g.audioEngine.PlaySFX("weapon_fire", g.camera.X, g.camera.Y)
+	loudness := gunfireLoudness * (1 - currentWeapon.Muffle)
+	if currentWeapon.Type == weapon.TypeMelee {
+		loudness = meleeLoudness
+	}
+	g.perception.EmitSound(g.camera.X, g.camera.Y, loudness)
+}
+
+// spawnMeleeArc leaves a fading ribbon sweeping across the player's aim.
+func (g *Game) spawnMeleeArc() {
+	if g.trailSystem == nil {
+		return
+	}
+	aim := math.Atan2(g.camera.DirY, g.camera.DirX)
+	g.trailSystem.Arc(g.camera.X, g.camera.Y, meleeArcRadius,
+		aim-meleeArcHalfAngle, aim+meleeArcHalfAngle, meleeArcSweepTime, meleeArcTrail)
 }
 
+// bossTargetID is the hit entity ID of the boss; enemy agents are hit as
+// their index plus one.
+const bossTargetID = math.MaxUint64
+
 // createEnemyRaycastFunction creates a raycast function for enemy hit detection.
 func (g *Game) createEnemyRaycastFunction() func(float64, float64, float64, float64, float64) (bool, float64, float64, float64, uint64) {
 	return func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64) {
@@ -2639,20 +3718,37 @@ func (g *Game) createEnemyRaycastFunction() func(float64, float64, float64, floa
 				toAgentY := agent.Y - y
 				dot := toAgentX*dx + toAgentY*dy
 				if dot > 0 {
-					return true, agentDist, agent.X, agent.Y, uint64(i + 1)
+					// Report where the ray passes the agent so hit zones
+					// can tell arms from center mass.
+					return true, agentDist, x + dx*dot, y + dy*dot, uint64(i + 1)
 				}
 			}
 		}
+		if b := g.boss; b != nil && !b.Dead() {
+			bossDist := (b.X-x)*(b.X-x) + (b.Y-y)*(b.Y-y)
+			if bossDist < maxDist*maxDist && (b.X-x)*dx+(b.Y-y)*dy > 0 {
+				return true, bossDist, b.X, b.Y, bossTargetID
+			}
+		}
 		return false, 0, 0, 0, 0
 	}
 }
 
-// processWeaponHits applies damage to enemies hit by weapon fire.
-func (g *Game) processWeaponHits(hitResults []weapon.HitResult, currentWeapon weapon.Weapon) {
+// processWeaponHits applies damage to enemies hit by weapon fire and
+// returns how many hits landed.
+func (g *Game) processWeaponHits(hitResults []weapon.HitResult, currentWeapon weapon.Weapon) int {
+	hits := 0
 	for _, hitResult := range hitResults {
 		if !hitResult.Hit || hitResult.EntityID == 0 {
 			continue
 		}
+		if hitResult.EntityID == bossTargetID {
+			if g.boss != nil && !g.boss.Dead() {
+				g.damageBoss(currentWeapon, g.arsenal.CurrentSlot, g.getUpgradedWeaponDamage(currentWeapon))
+				hits++
+			}
+			continue
+		}
 
 		agentIdx := int(hitResult.EntityID - 1)
 		if agentIdx < 0 || agentIdx >= len(g.aiAgents) {
@@ -2664,32 +3760,158 @@ func (g *Game) processWeaponHits(hitResults []weapon.HitResult, currentWeapon we
 			continue
 		}
 
-		g.processSingleHit(agent, currentWeapon)
-
-		if agent.Health <= 0 {
-			g.handleEnemyDeath(agent.X, agent.Y)
-		}
+		g.shootAgent(agent, currentWeapon, hitResult)
+		hits += 1 + g.penetrate(agent, currentWeapon, hitResult)
 	}
+	return hits
 }
 
-// processSingleHit applies damage and effects to a single enemy.
-func (g *Game) processSingleHit(agent *ai.Agent, currentWeapon weapon.Weapon) {
-	upgradedDamage := g.getUpgradedWeaponDamage(currentWeapon)
-	posMultiplier := g.calculatePositionalDamage(agent)
-	finalDamage := upgradedDamage * posMultiplier
-	agent.Health -= finalDamage
-
-	isCritical := g.rng.Float64() < 0.15 || posMultiplier >= 2.0
-
-	g.applyHitFeedback(agent, finalDamage, isCritical)
-	g.spawnHitDecal(agent)
-
-	if g.masteryManager != nil {
-		g.masteryManager.AddMasteryXP(g.arsenal.CurrentSlot, 10)
+// shootAgent lands a hitscan hit on a living agent and handles the kill.
+func (g *Game) shootAgent(agent *ai.Agent, currentWeapon weapon.Weapon, hit weapon.HitResult) {
+	g.processSingleHit(agent, currentWeapon, hit)
+	if agent.Health <= 0 {
+		g.combatLog.Record(combatlog.Event{Kind: combatlog.KindKill, Source: currentWeapon.Name, Target: agent.ArchetypeID})
+		g.handleAgentDeath(agent)
 	}
 }
 
-// calculatePositionalDamage computes damage multiplier based on attack angle.
+// penetrate carries a hitscan shot on through the agent it struck into as
+// many agents behind it, along the line of fire, as the weapon's
+// Penetration, and returns how many it hit.
+func (g *Game) penetrate(struck *ai.Agent, w weapon.Weapon, hit weapon.HitResult) int {
+	if w.Penetration <= 0 {
+		return 0
+	}
+	dirX, dirY := hit.HitX-g.camera.X, hit.HitY-g.camera.Y
+	length := math.Hypot(dirX, dirY)
+	if length == 0 {
+		return 0
+	}
+	dirX, dirY = dirX/length, dirY/length
+
+	type behind struct {
+		agent *ai.Agent
+		along float64 // Distance along the line of fire
+	}
+	var line []behind
+	for _, agent := range g.aiAgents {
+		if agent == struck || agent.Health <= 0 {
+			continue
+		}
+		toX, toY := agent.X-g.camera.X, agent.Y-g.camera.Y
+		along := toX*dirX + toY*dirY
+		if along > length && along <= w.Range && math.Abs(toX*dirY-toY*dirX) <= agentShotRadius {
+			line = append(line, behind{agent: agent, along: along})
+		}
+	}
+	sort.Slice(line, func(i, j int) bool { return line[i].along < line[j].along })
+
+	line = line[:min(len(line), w.Penetration)]
+	for _, b := range line {
+		g.shootAgent(b.agent, w, weapon.HitResult{
+			Hit:      true,
+			Distance: b.along,
+			Damage:   hit.Damage,
+			HitX:     g.camera.X + dirX*b.along,
+			HitY:     g.camera.Y + dirY*b.along,
+		})
+	}
+	return len(line)
+}
+
+// Mastery XP granted per enemy hit; headshots teach more.
+const (
+	masteryHitXP      = 10
+	masteryHeadshotXP = 25
+)
+
+// processSingleHit applies damage and effects to a single enemy.
+func (g *Game) processSingleHit(agent *ai.Agent, currentWeapon weapon.Weapon, hit weapon.HitResult) {
+	g.strikeAgent(agent, currentWeapon, g.arsenal.CurrentSlot, g.getUpgradedWeaponDamage(currentWeapon), g.hitZone(agent, hit))
+}
+
+// strikeAgent lands damage from the weapon in slot on an enemy's zone,
+// scaled by the attack angle and the zone, with its knockback, logging,
+// feedback and mastery XP.
+func (g *Game) strikeAgent(agent *ai.Agent, currentWeapon weapon.Weapon, slot int, upgradedDamage float64, zone combat.HitZone) {
+	posMultiplier := g.calculatePositionalDamage(agent)
+	if currentWeapon.Type == weapon.TypeMelee {
+		posMultiplier *= g.meleeBackstabBonus(agent, posMultiplier)
+	}
+	damage := upgradedDamage * posMultiplier * combat.DefaultHitZones.Multiplier(zone)
+	if zone == combat.ZoneHead && g.masteryManager != nil {
+		damage *= g.masteryManager.GetBonus(slot).HeadshotDamage
+	}
+	dmgType := g.weaponDamageType(currentWeapon)
+	finalDamage := g.hurtAgent(agent, damage, dmgType)
+	g.applyImpact(agent, finalDamage, currentWeapon.Knockback)
+	if ent, ok := g.agentEntity(agent); ok && g.statusReg != nil {
+		g.statusReg.Strike(g.world, ent, string(dmgType))
+	}
+	if currentWeapon.Status != "" && g.rng.Float64() < currentWeapon.StatusChance {
+		g.afflictAgent(agent, currentWeapon.Status)
+	}
+
+	isCritical := g.rng.Float64() < 0.15 || posMultiplier >= 2.0 || zone == combat.ZoneHead
+	g.combatLog.Record(combatlog.Event{
+		Kind:       combatlog.KindHit,
+		Source:     currentWeapon.Name,
+		Target:     agent.ArchetypeID,
+		Amount:     finalDamage,
+		DamageType: string(dmgType),
+		Crit:       isCritical,
+		Headshot:   zone == combat.ZoneHead,
+	})
+
+	g.applyHitFeedback(agent, finalDamage, isCritical, zone)
+	g.spawnHitDecal(agent)
+
+	xp := masteryHitXP
+	if zone == combat.ZoneHead {
+		xp = masteryHeadshotXP
+		g.audioEngine.PlaySFX("headshot", agent.X, agent.Y)
+	}
+	if g.masteryManager != nil {
+		g.masteryManager.AddMasteryXP(slot, xp)
+	}
+}
+
+// weaponDamageType returns the damage type a weapon deals: its loaded
+// ammo's, or that of its ammo type in the genre.
+func (g *Game) weaponDamageType(w weapon.Weapon) combat.DamageType {
+	if w.DamageType != "" {
+		return combat.DamageType(w.DamageType)
+	}
+	return combat.WeaponDamageType(g.genreID, w.AmmoType)
+}
+
+// agentDamageType returns the damage type an enemy's weapon deals: its
+// shots' for a ranged weapon, and physical for melee blows.
+func agentDamageType(agent *ai.Agent) combat.DamageType {
+	if weapon, ranged := combat.RangedWeaponFor(agent.Weapon); ranged {
+		return weapon.DamageType
+	}
+	return combat.DamagePhysical
+}
+
+// hitZone works out which part of an agent a shot struck, from how high
+// the player's aim crosses the agent and how far the ray passed from its
+// center. Melee blows always land on the torso.
+func (g *Game) hitZone(agent *ai.Agent, hit weapon.HitResult) combat.HitZone {
+	if g.arsenal.GetCurrentWeapon().Type == weapon.TypeMelee {
+		return combat.ZoneTorso
+	}
+	dist := math.Hypot(agent.X-g.camera.X, agent.Y-g.camera.Y)
+	lateral := math.Hypot(hit.HitX-agent.X, hit.HitY-agent.Y)
+	zone := combat.DefaultHitZones.Zone(combat.ShotHeight(g.camera.Pitch, dist), lateral)
+	logrus.WithFields(logrus.Fields{
+		"agent": agent.ID,
+		"zone":  zone.String(),
+	}).Debug("Weapon hit zone")
+	return zone
+}
+
+// calculatePositionalDamage computes damage multiplier based on attack angle.
 func (g *Game) calculatePositionalDamage(agent *ai.Agent) float64 {
 	if g.playerEntity == 0 || g.positionalSystem == nil {
 		return 1.0
@@ -2727,14 +3949,32 @@ func (g *Game) calculatePositionalDamage(agent *ai.Agent) float64 {
 	return 1.0
 }
 
+// meleeAmbushMultiplier scales a melee backstab on an enemy that is not
+// yet fighting the player.
+const meleeAmbushMultiplier = 1.5
+
+// meleeBackstabBonus returns the extra damage multiplier for a melee blow
+// on agent at the given positional multiplier: an ambush from behind, and
+// the counter bonus a perfect parry opens.
+func (g *Game) meleeBackstabBonus(agent *ai.Agent, posMultiplier float64) float64 {
+	bonus := 1.0
+	if posMultiplier >= combat.GetPositionalConfig(g.genreID).BackstabMultiplier && agent.Alert != ai.AlertCombat {
+		bonus *= meleeAmbushMultiplier
+	}
+	if defense := g.getPlayerDefenseComponent(); defense != nil {
+		bonus *= combat.GetCounterDamageMultiplier(defense)
+	}
+	return bonus
+}
+
 // applyHitFeedback spawns visual and audio feedback for weapon hits.
-func (g *Game) applyHitFeedback(agent *ai.Agent, damage float64, isCritical bool) {
+func (g *Game) applyHitFeedback(agent *ai.Agent, damage float64, isCritical bool, zone combat.HitZone) {
 	impactAngle := math.Atan2(agent.Y-g.camera.Y, agent.X-g.camera.X)
 	g.applyFeedbackSystemEffects(agent, damage, isCritical)
 	g.applyImpactBurstEffects(agent, damage, isCritical, impactAngle)
 	g.applyCameraHitEffects(damage, isCritical)
 	g.applyParticleImpactEffects(agent, isCritical, impactAngle)
-	g.applyHitMarkerFeedback(agent, damage, isCritical)
+	g.applyHitMarkerFeedback(agent, damage, isCritical, zone)
 }
 
 // applyFeedbackSystemEffects applies screen shake and damage numbers.
@@ -2796,7 +4036,7 @@ func (g *Game) applyParticleImpactEffects(agent *ai.Agent, isCritical bool, impa
 }
 
 // applyHitMarkerFeedback triggers the crosshair hit marker for visual damage confirmation.
-func (g *Game) applyHitMarkerFeedback(agent *ai.Agent, damage float64, isCritical bool) {
+func (g *Game) applyHitMarkerFeedback(agent *ai.Agent, damage float64, isCritical bool, zone combat.HitZone) {
 	if g.hitMarkerSystem == nil || g.hitMarkerEntity == 0 {
 		return
 	}
@@ -2805,6 +4045,8 @@ func (g *Game) applyHitMarkerFeedback(agent *ai.Agent, damage float64, isCritica
 	ht := hitmarker.HitNormal
 	if agent.Health <= 0 {
 		ht = hitmarker.HitKill
+	} else if zone == combat.ZoneHead {
+		ht = hitmarker.HitHeadshot
 	} else if isCritical {
 		ht = hitmarker.HitCritical
 	}
@@ -2837,6 +4079,10 @@ func (g *Game) spawnMuzzleFlash(currentWeapon weapon.Weapon) {
 	intensity := clampFloat(currentWeapon.Damage/15.0, 0.8, 1.5)
 
 	g.muzzleFlashSystem.SpawnFlash(g.world, g.playerEntity, muzzleX, muzzleY, aimAngle, flashType, intensity)
+
+	if g.particleSystem != nil {
+		_, _ = g.particleSystem.SpawnPreset("muzzle_smoke", muzzleX, muzzleY, aimAngle)
+	}
 }
 
 // determineFlashType maps weapon properties to muzzle flash types.
@@ -2899,9 +4145,158 @@ func (g *Game) spawnHitDecal(agent *ai.Agent) {
 
 // handleEnemyDeath processes enemy death rewards and progression.
 func (g *Game) handleEnemyDeath(enemyX, enemyY float64) {
+	if g.director != nil {
+		g.director.RecordKill()
+	}
 	g.spawnDeathEffects(enemyX, enemyY)
 	g.spawnEnemyCorpse(enemyX, enemyY)
 	g.grantDeathRewards(enemyX, enemyY)
+	g.dispatchModEvent(mod.EventTypeEnemyKilled, map[string]interface{}{"x": enemyX, "y": enemyY})
+}
+
+// handleAgentDeath processes an enemy agent's death and drops its
+// archetype's loot, ammo and any rolled weapon on the floor.
+func (g *Game) handleAgentDeath(agent *ai.Agent) {
+	g.handleEnemyDeath(agent.X, agent.Y)
+	if g.territorySystem != nil {
+		if t := g.territorySystem.GetTerritoryByPosition(agent.X, agent.Y); t != nil && t.ControlFaction != "" {
+			g.recordMetaQuest("enemy:"+string(t.ControlFaction), "")
+		}
+	}
+	g.dropRolledWeapon(agent)
+	g.dropAmmo(agent.X, agent.Y)
+
+	arch, ok := g.archetypes.Get(agent.ArchetypeID)
+	if !ok {
+		return
+	}
+	table, ok := g.lootTables.Get(arch.ID)
+	if !ok {
+		table = &loot.Table{ID: arch.ID}
+		for _, l := range arch.Loot {
+			table.Entries = append(table.Entries, loot.Entry{Item: l.Item, Name: l.Name, Chance: l.Chance})
+		}
+	}
+	g.dropLoot(table, agent.X, agent.Y)
+}
+
+// dropLoot rolls the loot table at the current depth and drops what it
+// gives at x, y, each item at least at the rarity it rolled.
+func (g *Game) dropLoot(table *loot.Table, x, y float64) {
+	if len(table.Entries) == 0 {
+		return
+	}
+	for _, drop := range table.Roll(g.levelDepth, uint64(g.rng.Intn(math.MaxInt32)), g.lootPity) {
+		name := drop.Name
+		if name == "" {
+			name = drop.Item
+		}
+		item := inventory.RollItemAtLeast(drop.Item, name, uint64(g.rng.Intn(math.MaxInt32))+1, lootRarity(drop.Rarity))
+		g.dropPickup(loot.Pickup{
+			Kind:   loot.PickupItem,
+			Item:   item.ID,
+			Name:   item.Name,
+			Amount: item.Qty,
+			Seed:   item.Seed,
+			Rarity: glowRarity(int(item.Rarity())),
+		}, x, y)
+	}
+}
+
+// Chance an enemy drops half a magazine of rounds for the player's
+// current weapon.
+const ammoDropChance = 0.35
+
+// dropAmmo may drop rounds for the current weapon at x, y.
+func (g *Game) dropAmmo(x, y float64) {
+	w := g.arsenal.GetCurrentWeapon()
+	if w.AmmoType == "" || g.rng.Float64() >= ammoDropChance {
+		return
+	}
+	g.dropPickup(loot.Pickup{Kind: loot.PickupAmmo, Item: w.AmmoType, Name: w.AmmoType, Amount: max(w.ClipSize/2, 1)}, x, y)
+}
+
+// pickupScatter is how far, in tiles, drops land from where they fell.
+const pickupScatter = 0.3
+
+// dropPickup lays the pickup on the floor around x, y.
+func (g *Game) dropPickup(p loot.Pickup, x, y float64) {
+	angle := float64(g.rng.Intn(8)) * math.Pi / 4
+	g.pickups.Spawn(g.world, p, x+math.Cos(angle)*pickupScatter, y+math.Sin(angle)*pickupScatter)
+}
+
+// updatePickups drifts the loot on the floor towards the player and gives
+// them what they collect.
+func (g *Game) updatePickups() {
+	for _, p := range g.pickups.Update(g.world, g.camera.X, g.camera.Y, common.DeltaTime) {
+		g.collectPickup(p)
+	}
+}
+
+// collectPickup gives the player a pickup they walked over.
+func (g *Game) collectPickup(p loot.Pickup) {
+	var msg string
+	switch p.Kind {
+	case loot.PickupAmmo:
+		g.ammoPool.Add(p.Item, p.Amount)
+		msg = fmt.Sprintf("+%d %s", p.Amount, p.Name)
+	case loot.PickupCredits:
+		if g.shopCredits != nil {
+			g.shopCredits.Add(p.Amount)
+		}
+		if g.toastSystem != nil {
+			g.toastSystem.Queue(toast.TypeCurrency, fmt.Sprintf("+%d Credits", p.Amount), toast.PriorityLow)
+		}
+		return
+	case loot.PickupWeapon:
+		msg = g.collectRolledWeapon(p.Seed)
+	default:
+		if g.playerInventory == nil {
+			return
+		}
+		item := inventory.Item{ID: p.Item, Name: p.Name, Qty: p.Amount, Seed: p.Seed}
+		g.playerInventory.Add(item)
+		msg = "Looted: " + item.Name
+		if r := item.Rarity(); r != inventory.RarityCommon {
+			msg = fmt.Sprintf("Looted: %s (%s)", item.Name, r.Label())
+		}
+		if a, ok := inventory.FindRolledArmor(item.ID, item.Seed); ok {
+			g.autoEquipArmor(a)
+		}
+		g.recordQuest("item")
+		g.recordQuest("retrieve")
+		g.dispatchModEvent(mod.EventTypeItemPickup, map[string]interface{}{"item": item.ID, "name": item.Name, "quantity": item.Qty})
+	}
+	g.audioEngine.PlaySFX("item_pickup", g.camera.X, g.camera.Y)
+	g.hud.ShowMessage(msg)
+	if g.toastSystem != nil {
+		g.toastSystem.Queue(toast.TypeLoot, msg, toast.PriorityNormal)
+	}
+}
+
+// glowRarity returns the loot tier an item or weapon rarity glows at:
+// epic items glow as rare ones.
+func glowRarity(tier int) loot.Rarity {
+	switch {
+	case tier >= 4:
+		return loot.RarityLegendary
+	case tier >= 2:
+		return loot.RarityRare
+	}
+	return loot.Rarity(tier)
+}
+
+// lootRarity returns the item rarity a loot table's rarity tier guarantees.
+func lootRarity(r loot.Rarity) inventory.Rarity {
+	switch r {
+	case loot.RarityUncommon:
+		return inventory.RarityUncommon
+	case loot.RarityRare:
+		return inventory.RarityRare
+	case loot.RarityLegendary:
+		return inventory.RarityLegendary
+	}
+	return inventory.RarityCommon
 }
 
 // spawnDeathEffects creates particles and decals for enemy death.
@@ -2961,15 +4356,20 @@ func (g *Game) determineDeathType() corpse.DeathType {
 // grantDeathRewards awards XP, currency, and materials for killing an enemy.
 func (g *Game) grantDeathRewards(enemyX, enemyY float64) {
 	g.grantXPReward()
-	g.grantCurrencyRewards()
+	g.grantCurrencyRewards(enemyX, enemyY)
 	g.updateQuestProgress()
 	g.spawnBiomeMaterialsAtDeath(enemyX, enemyY)
 }
 
-// grantXPReward adds experience and handles level-up.
+// grantXPReward adds a kill's experience and handles level-up.
 func (g *Game) grantXPReward() {
+	g.grantXP(50)
+}
+
+// grantXP adds experience and handles level-up.
+func (g *Game) grantXP(amount int) {
 	oldLevel := g.progression.GetLevel()
-	if err := g.progression.AddXP(50); err != nil {
+	if err := g.progression.AddXP(amount); err != nil {
 		logrus.WithError(err).Warn("Failed to add XP")
 	}
 
@@ -2995,11 +4395,13 @@ func (g *Game) grantXPReward() {
 	}
 }
 
-// grantCurrencyRewards adds shop credits, upgrade tokens, and scrap.
-func (g *Game) grantCurrencyRewards() {
-	if g.shopCredits != nil {
-		g.shopCredits.Add(25)
-	}
+// Shop credits an enemy drops.
+const killCredits = 25
+
+// grantCurrencyRewards drops shop credits at the enemy's position and adds
+// upgrade tokens and scrap.
+func (g *Game) grantCurrencyRewards(enemyX, enemyY float64) {
+	g.dropPickup(loot.Pickup{Kind: loot.PickupCredits, Item: "credits", Name: "Credits", Amount: killCredits}, enemyX, enemyY)
 
 	if g.upgradeManager != nil {
 		g.upgradeManager.GetTokens().Add(1)
@@ -3009,11 +4411,6 @@ func (g *Game) grantCurrencyRewards() {
 		scrapName := crafting.GetScrapNameForGenre(g.genreID)
 		g.scrapStorage.Add(scrapName, 3)
 	}
-
-	// Toast notification for currency rewards
-	if g.toastSystem != nil {
-		g.toastSystem.Queue(toast.TypeCurrency, "+25 Credits", toast.PriorityLow)
-	}
 }
 
 // updateQuestProgress increments kill quest objectives and grants rewards.
@@ -3031,6 +4428,7 @@ func (g *Game) updateQuestProgress() {
 	}
 
 	g.questTracker.UpdateProgress("bonus_kills", 1)
+	g.recordQuest("enemy")
 
 	for i := range g.questTracker.Objectives {
 		obj := &g.questTracker.Objectives[i]
@@ -3123,11 +4521,89 @@ func (g *Game) checkDestructibleHits(hitResults []weapon.HitResult, currentWeapo
 	}
 }
 
+// Impulse light limits and the flash of an exploding barrel.
+const (
+	maxImpulseLights          = 24
+	maxImpulsesPerFrame       = 6
+	barrelBlastLightRadius    = 6.0
+	barrelBlastLightIntensity = 2.0
+	barrelBlastLightDuration  = 0.4
+)
+
+var barrelBlastLightColor = color.RGBA{R: 255, G: 150, B: 60, A: 255}
+
+// Ribbon trail limits and the arc left by melee swings.
+const (
+	maxTrails          = 64
+	maxTrailPoints     = 24
+	meleeArcRadius     = 0.8
+	meleeArcHalfAngle  = 0.9 // Radians either side of the aim direction
+	meleeArcSweepTime  = 0.12
+	particleScreenZoom = 10.0 // Screen pixels per world unit for particle overlays
+)
+
+var meleeArcTrail = particle.TrailConfig{
+	Length:    12,
+	Fade:      0.25,
+	Width:     0.3,
+	TailWidth: 0.2,
+	Color:     color.RGBA{R: 220, G: 235, B: 255, A: 200},
+}
+
+// debrisCollision makes destruction debris bounce off walls and settle.
+var debrisCollision = particle.Collision{Mode: particle.CollideBounce, Restitution: 0.4}
+
+// The brief outward push of a destroyed object that flings its debris.
+var blastPush = particle.Attractor{Strength: -60, Radius: 3, Life: 0.15}
+
+// Wind field tuning. Gusts travel across the map as a wave along the
+// weather's wind direction.
+const (
+	windCellSize       = 4.0  // Tiles per wind field cell
+	windStrength       = 1.5  // Mean acceleration, tiles per second squared
+	windGustAmount     = 0.8  // Gust strength as a fraction of windStrength
+	windGustSpeed      = 0.7  // Gust wave phase speed, radians per second
+	windGustWavelength = 0.35 // Gust wave phase step per cell, radians
+)
+
+// updateWindField blows gusts through the wind field along the weather's
+// wind direction.
+func (g *Game) updateWindField(deltaTime float64) {
+	if g.windField == nil || g.weatherSystem == nil {
+		return
+	}
+	ws := g.weatherSystem.GetWeatherSystem()
+	if ws == nil {
+		return
+	}
+	wx, wy := ws.Wind()
+	l := math.Hypot(wx, wy)
+	if l == 0 {
+		g.windField.Fill(0, 0)
+		return
+	}
+	wx, wy = wx/l, wy/l
+
+	g.windTime += deltaTime
+	cols, rows := g.windField.Size()
+	for cy := 0; cy < rows; cy++ {
+		for cx := 0; cx < cols; cx++ {
+			// Cells further downwind see each gust later.
+			along := float64(cx)*wx + float64(cy)*wy
+			gust := 1 + windGustAmount*math.Sin(g.windTime*windGustSpeed-along*windGustWavelength)
+			g.windField.Set(cx, cy, wx*windStrength*gust, wy*windStrength*gust)
+		}
+	}
+}
+
 // handleDestructibleDestroyed processes the destruction of a destructible object.
 func (g *Game) handleDestructibleDestroyed(obj *destruct.Destructible) {
 	if g.particleSystem != nil {
 		debrisColor := color.RGBA{R: 100, G: 80, B: 60, A: 255}
-		g.particleSystem.SpawnBurst(obj.X, obj.Y, 0, 15, 8.0, 1.0, 1.5, 1.0, debrisColor)
+		push := blastPush
+		push.X, push.Y = obj.X, obj.Y
+		g.particleSystem.AddAttractor(push)
+		g.particleSystem.SpawnForcedBurst(obj.X, obj.Y, 0, 15, 8.0, 1.0, 1.5, 1.0, debrisColor, debrisCollision, particle.ForceAttractors)
 	}
 
 	// Add screen shake for explosion
@@ -3148,182 +4624,1596 @@ func (g *Game) handleDestructibleDestroyed(obj *destruct.Destructible) {
 		g.shopCredits.Add(10)
 	}
 
+	if obj.Type == "barrel" && g.impulseLights != nil {
+		g.impulseLights.EmitLightImpulse(obj.X, obj.Y, barrelBlastLightRadius, barrelBlastLightIntensity, barrelBlastLightColor, barrelBlastLightDuration)
+	}
+
+	loudness := debrisLoudness
+	if obj.Type == "barrel" {
+		loudness = explosionLoudness
+	}
+	g.perception.EmitSound(obj.X, obj.Y, loudness)
+
+	if table, ok := g.lootTables.Get(obj.Type); ok {
+		g.dropLoot(table, obj.X, obj.Y)
+	}
+	g.recordQuest("destroy")
+	if obj.Type == sabotageType {
+		g.advanceEventObjective(1)
+	}
+
 	g.audioEngine.PlaySFX("barrel_explode", obj.X, obj.Y)
 }
 
-// updateAIAgents updates all AI agents' behavior and combat actions.
+// How far noises carry to enemies, as multiples of their hearing radius.
+const (
+	gunfireLoudness   = 1.0
+	meleeLoudness     = 0.3
+	explosionLoudness = 1.5
+	debrisLoudness    = 0.8
+)
+
+// updateAIAgents lets every living AI agent perceive the player and the
+// noises made since the last tick, ticks its behavior tree and carries out
+// the attacks the trees decide on.
 func (g *Game) updateAIAgents() {
-	for _, agent := range g.aiAgents {
-		if agent.Health <= 0 {
+	g.aiTick++
+	ctx := &ai.Context{
+		TileMap:     g.currentMap,
+		PlayerX:     g.camera.X,
+		PlayerY:     g.camera.Y,
+		CurrentTick: g.aiTick,
+		RNG:         g.rng,
+		Nav:         g.navGrid,
+		Perception:  g.perception,
+	}
+	g.perception.Update(g.aiAgents, ctx)
+	if g.squadPlanner != nil {
+		g.squadPlanner.Update(g.aiAgents, ctx)
+	}
+
+	for i, agent := range g.aiAgents {
+		if agent.Health <= 0 || i >= len(g.agentTrees) {
 			continue
 		}
 
-		dx := g.camera.X - agent.X
-		dy := g.camera.Y - agent.Y
-		distSq := dx*dx + dy*dy
-		dist := math.Sqrt(distSq)
-
-		if distSq < 100 && agent.Cooldown <= 0 {
-			// Determine attack animation type based on distance and agent archetype
-			animType := g.selectAttackAnimation(agent, dist)
-
-			// Start attack animation on agent's entity if available
-			if g.attackAnimSystem != nil && g.world != nil {
-				entity := g.findOrCreateAgentEntity(agent)
-				dirX := dx
-				dirY := dy
-				if dist > 0.01 {
-					dirX /= dist
-					dirY /= dist
-				}
-				intensity := 1.0 + (agent.Damage / 50.0)
-				g.attackAnimSystem.StartAttack(g.world, entity, animType, dirX, dirY, intensity)
-			}
-
-			g.handleAgentAttack(agent)
+		if agent.Staggered() || g.agentStunned(i) {
+			// Off balance: no decisions or attacks until it recovers.
+			agent.Recover()
+		} else {
+			g.agentTrees[i].Tick(agent, ctx)
+		}
+		if i < len(g.agentPos) {
+			g.agentPos[i].X, g.agentPos[i].Y = agent.X, agent.Y
 		}
 
-		if agent.Cooldown > 0 {
+		if agent.Blackboard.Bool(ai.KeyAttacked) {
+			agent.Blackboard.Delete(ai.KeyAttacked)
+			g.startAgentAttack(agent)
+		}
+		// Attacking agents count down their own cooldown.
+		if agent.State != ai.StateAttack && agent.Cooldown > 0 {
 			agent.Cooldown--
 		}
 	}
 }
 
-// handleAgentAttack processes an AI agent's attack on the player.
-func (g *Game) handleAgentAttack(agent *ai.Agent) {
-	damage := agent.Damage
-	healthDamage := damage
-
-	if g.hud.Armor > 0 {
-		armorDamage := damage * 0.5
-		g.hud.Armor -= int(armorDamage)
-		if g.hud.Armor < 0 {
-			healthDamage = -float64(g.hud.Armor)
-			g.hud.Armor = 0
-		} else {
-			healthDamage = damage * 0.5
-		}
-	}
+// agentStunned reports whether the agent at index i has a stun status.
+func (g *Game) agentStunned(i int) bool {
+	return i < len(g.agentEnts) && status.IsStunned(g.world, g.agentEnts[i])
+}
 
-	g.hud.Health -= int(healthDamage)
-	agent.Cooldown = 60
-	g.audioEngine.PlaySFX("enemy_attack", agent.X, agent.Y)
-	g.hud.ShowMessage("Taking damage!")
+// applyImpact shoves an agent away from the player after a hit of damage
+// with the given weapon force, and staggers or stuns it when the hit is
+// heavy enough for the genre. A stagger drops any shot it was aiming.
+func (g *Game) applyImpact(agent *ai.Agent, damage, force float64) {
+	if agent.Health <= 0 || force <= 0 {
+		return
+	}
+	cfg := combat.GetImpactConfig(g.genreID)
+	imp := cfg.Resolve(damage, force, agent.Mass, agent.MaxHealth, agent.X-g.camera.X, agent.Y-g.camera.Y)
+	agent.Knockback(imp.PushX, imp.PushY, g.currentMap)
+	if imp.Stagger {
+		agent.Stagger(int(cfg.StaggerTime / common.DeltaTime))
+		g.rangedSystem.Cancel(g.world, agent.ID)
+	}
+	if imp.Stun {
+		g.afflictAgent(agent, "stunned")
+	}
+}
 
-	// Add impact particles for player damage
-	if g.impactEmitter != nil {
-		impactAngle := math.Atan2(g.camera.Y-agent.Y, g.camera.X-agent.X)
-		g.impactEmitter.EmitImpact(g.camera.X, g.camera.Y, particle.ImpactMelee, particle.MaterialFlesh, impactAngle)
+// afflictAgent puts the named status effect on an agent.
+func (g *Game) afflictAgent(agent *ai.Agent, effect string) {
+	if g.statusReg == nil || agent.Health <= 0 {
+		return
 	}
+	if ent, ok := g.agentEntity(agent); ok {
+		g.statusReg.ApplyToEntity(g.world, ent, effect)
+	}
+}
 
-	// Add screen feedback for player damage
-	if g.feedbackSystem != nil {
-		shakeIntensity := healthDamage / 5.0
-		if shakeIntensity > 8.0 {
-			shakeIntensity = 8.0
+// agentEntity returns the ECS entity carrying an agent's status effects.
+func (g *Game) agentEntity(agent *ai.Agent) (engine.Entity, bool) {
+	for i, a := range g.aiAgents {
+		if a == agent && i < len(g.agentEnts) {
+			return g.agentEnts[i], true
 		}
-		g.feedbackSystem.AddScreenShake(shakeIntensity)
-		g.feedbackSystem.AddHitFlash(0.3 + (healthDamage / 100.0))
 	}
+	return 0, false
+}
 
-	// Trigger enhanced camera effects for player damage
-	if g.cameraFXSystem != nil {
-		shakeIntensity := healthDamage / 10.0
-		g.cameraFXSystem.TriggerShake(shakeIntensity)
-		fr, fg, fb, fa := camerafx.Flash.Red()
-		g.cameraFXSystem.TriggerFlash(fr, fg, fb, fa*(healthDamage/50.0))
-		if healthDamage > 30 {
-			g.cameraFXSystem.TriggerChromatic(0.3)
+// entityAgent returns the agent whose status effects an ECS entity
+// carries.
+func (g *Game) entityAgent(entity engine.Entity) (*ai.Agent, bool) {
+	for i, ent := range g.agentEnts {
+		if ent == entity && i < len(g.aiAgents) {
+			return g.aiAgents[i], true
 		}
 	}
+	return nil, false
+}
 
-	// Trigger directional damage indicator showing damage direction
-	if g.damageDirSystem != nil {
-		// Calculate player facing angle from direction vector
-		playerAngle := math.Atan2(g.camera.DirY, g.camera.DirX)
-		g.damageDirSystem.TriggerDamage(agent.X, agent.Y, g.camera.X, g.camera.Y, healthDamage, playerAngle)
+// statusResistances converts combat resistances for the status registry.
+func statusResistances(res combat.Resistances) status.Resistances {
+	out := make(status.Resistances, len(res))
+	for dmgType, r := range res {
+		out[string(dmgType)] = r
+	}
+	return out
+}
+
+// onStatusTick runs a tick of a status effect on the player or an enemy
+// through the combat damage pipeline, so armor and resistances apply and
+// kills are handled. Negative ticks heal.
+func (g *Game) onStatusTick(_ *engine.World, entity engine.Entity, effect status.ActiveEffect, amount float64) {
+	dmgType := combat.DamageType(effect.DamageType)
+	if entity == g.playerEntity {
+		if amount < 0 {
+			g.hud.Health = min(g.hud.MaxHealth, g.hud.Health+int(-amount))
+			return
+		}
+		g.hurtPlayer(amount, dmgType, effect.EffectName)
+		return
 	}
 
-	if g.hud.Health <= 0 {
-		g.hud.Health = 0
+	agent, ok := g.entityAgent(entity)
+	if !ok || agent.Health <= 0 {
+		return
 	}
+	if amount < 0 {
+		agent.Health = math.Min(agent.MaxHealth, agent.Health-amount)
+		return
+	}
+	g.woundAgent(agent, amount, dmgType, effect.EffectName)
 }
 
-// updateSquadAndEventTriggers updates squad companions and event trigger systems.
-func (g *Game) updateSquadAndEventTriggers() {
-	if g.squadCompanions != nil {
-		g.squadCompanions.Update(g.camera.X, g.camera.Y, g.currentMap, g.camera.X, g.camera.Y, g.seed)
+// woundAgent deals damage from a source other than the player's weapons to
+// a living agent, logging the hit and handling the kill.
+func (g *Game) woundAgent(agent *ai.Agent, amount float64, dmgType combat.DamageType, source string) {
+	dealt := g.hurtAgent(agent, amount, dmgType)
+	g.combatLog.Record(combatlog.Event{Kind: combatlog.KindHit, Source: source, Target: agent.ArchetypeID, Amount: dealt, DamageType: string(dmgType)})
+	if agent.Health <= 0 {
+		g.combatLog.Record(combatlog.Event{Kind: combatlog.KindKill, Source: source, Target: agent.ArchetypeID})
+		g.handleAgentDeath(agent)
 	}
+}
 
-	deltaTime := common.DeltaTime
-	g.updateAlarmTrigger(deltaTime)
-	g.updateLockdownTrigger(deltaTime)
-	g.checkBossArenaTrigger()
+// reactionEvent is an elemental combo set off on an entity.
+type reactionEvent struct {
+	entity   engine.Entity
+	reaction status.Reaction
 }
 
-// updateAlarmTrigger updates the alarm event trigger if active.
-func (g *Game) updateAlarmTrigger(deltaTime float64) {
-	if g.alarmTrigger != nil && g.alarmTrigger.IsActive() {
-		g.alarmTrigger.Update(deltaTime)
+// onReaction queues an elemental combo to go off on the next update, once
+// the hit or effect that set it off has been fully resolved.
+func (g *Game) onReaction(_ *engine.World, entity engine.Entity, reaction status.Reaction) {
+	g.reactions = append(g.reactions, reactionEvent{entity: entity, reaction: reaction})
+}
+
+// updateReactions sets off the queued elemental combos: each deals its
+// burst to the entity it went off on, spreads to whoever it reaches, and
+// plays its particles and sound there.
+func (g *Game) updateReactions() {
+	events := g.reactions
+	g.reactions = nil
+	for _, ev := range events {
+		re := ev.reaction
+		x, y := g.camera.X, g.camera.Y
+		if ev.entity != g.playerEntity {
+			agent, ok := g.entityAgent(ev.entity)
+			if !ok {
+				continue
+			}
+			x, y = agent.X, agent.Y
+		}
+		g.burstReaction(ev.entity, re)
+
+		if re.Radius > 0 {
+			for i, agent := range g.aiAgents {
+				if i >= len(g.agentEnts) || g.agentEnts[i] == ev.entity || agent.Health <= 0 {
+					continue
+				}
+				if math.Hypot(agent.X-x, agent.Y-y) <= re.Radius && g.statusReg.Spread(g.world, g.agentEnts[i], re) {
+					g.burstReaction(g.agentEnts[i], re)
+				}
+			}
+			if ev.entity != g.playerEntity && math.Hypot(g.camera.X-x, g.camera.Y-y) <= re.Radius && g.statusReg.Spread(g.world, g.playerEntity, re) {
+				g.burstReaction(g.playerEntity, re)
+			}
+		}
+
+		if g.particleSystem != nil {
+			c := color.RGBA{R: uint8(re.Color >> 24), G: uint8(re.Color >> 16), B: uint8(re.Color >> 8), A: uint8(re.Color)}
+			g.particleSystem.SpawnBurst(x, y, 0.5, 12+int(re.Radius*8), 6.0, 1.0, 1.0, 1.0, c)
+		}
+		if g.feedbackSystem != nil && re.Radius > 0 {
+			g.feedbackSystem.AddScreenShake(4.0 / (1.0 + math.Hypot(x-g.camera.X, y-g.camera.Y)*0.5))
+		}
+		g.audioEngine.PlaySFX(re.Sound, x, y)
+		g.hud.ShowMessage(re.Name + "!")
 	}
 }
 
-// updateLockdownTrigger updates the lockdown event trigger and checks time limits.
-func (g *Game) updateLockdownTrigger(deltaTime float64) {
-	if g.lockdownTrigger == nil || !g.lockdownTrigger.IsActive() {
+// burstReaction deals an elemental combo's burst damage to the player or a
+// living agent.
+func (g *Game) burstReaction(entity engine.Entity, re status.Reaction) {
+	if re.Damage <= 0 {
 		return
 	}
-
-	g.lockdownTrigger.Update(deltaTime)
-
-	if g.questTracker == nil {
+	if entity == g.playerEntity {
+		g.hurtPlayer(re.Damage, combat.DamageType(re.DamageType), re.Name)
 		return
 	}
-
-	remainingTime := g.lockdownTrigger.GetRemaining()
-	if g.lockdownTrigger.IsExpired() {
-		g.hud.ShowMessage("Lockdown complete - you are trapped!")
-	} else if remainingTime < 10 {
-		g.hud.ShowMessage("WARNING: 10 seconds remaining!")
+	if agent, ok := g.entityAgent(entity); ok && agent.Health > 0 {
+		g.woundAgent(agent, re.Damage, combat.DamageType(re.DamageType), re.Name)
 	}
 }
 
-// checkBossArenaTrigger checks if the player has entered the boss arena.
-func (g *Game) checkBossArenaTrigger() {
-	if g.bossArena == nil || g.bossArena.IsTriggered() {
-		return
+// onAgentAlert cues the player when an enemy spots them.
+func (g *Game) onAgentAlert(agent *ai.Agent, _, to ai.AlertLevel) {
+	if to == ai.AlertCombat && g.audioEngine != nil {
+		g.audioEngine.PlaySFX("enemy_alert", agent.X, agent.Y)
 	}
+}
 
-	centerX := float64(len(g.currentMap[0]) / 2)
-	centerY := float64(len(g.currentMap) / 2)
-	distToCenterSq := (g.camera.X-centerX)*(g.camera.X-centerX) + (g.camera.Y-centerY)*(g.camera.Y-centerY)
+// Director tuning: reinforcements arrive out of the player's way, and scares
+// sound from behind them.
+const (
+	directorSeedSalt     = 0x44495245
+	reinforcementMinDist = 12.0
+	reinforcementSpread  = 0.6 // Max offset of wave members from the room centre
+	scareDistance        = 4.0
+)
 
-	if distToCenterSq >= 25 {
+// updateDirector reports the player's status to the AI director and
+// carries out the waves and scares it schedules.
+func (g *Game) updateDirector() {
+	if g.director == nil || g.hud == nil {
 		return
 	}
+	enemies := 0
+	for _, agent := range g.aiAgents {
+		if agent.Health > 0 {
+			enemies++
+		}
+	}
+	status := director.Status{
+		Health:    g.hud.Health,
+		MaxHealth: g.hud.MaxHealth,
+		Ammo:      g.hud.Ammo,
+		MaxAmmo:   g.hud.MaxAmmo,
+		Enemies:   enemies,
+	}
+	for _, e := range g.director.Update(common.DeltaTime, status) {
+		switch e.Kind {
+		case director.EventWave:
+			g.spawnReinforcements(e.Count)
+		case director.EventScare:
+			g.playScare(e.Scare)
+		}
+	}
+}
+
+// spawnReinforcements brings a wave of n enemies into a room away from the
+// player and sends them searching toward where the player is now. Placement
+// draws from the director's RNG so waves replay from the seed.
+func (g *Game) spawnReinforcements(n int) {
+	room := g.reinforcementRoom()
+	if room == nil || n <= 0 {
+		return
+	}
+	g.waveCount++
+	squadID := fmt.Sprintf("wave_%d", g.waveCount)
+	nameGen := dialogue.NewNameGenerator()
+	cx := float64(room.X) + float64(room.W)/2
+	cy := float64(room.Y) + float64(room.H)/2
+	r := g.director.RNG()
+	for i := 0; i < n; i++ {
+		x := cx + (r.Float64()*2-1)*reinforcementSpread
+		y := cy + (r.Float64()*2-1)*reinforcementSpread
+		agent := g.spawnEnemy(nameGen, squadID, x, y)
+		g.perception.Dispatch(agent, g.camera.X, g.camera.Y)
+	}
+	logrus.WithFields(logrus.Fields{
+		"count": n,
+		"room":  room.Index,
+		"phase": g.director.Phase(),
+	}).Debug("Director sent reinforcements")
+}
+
+// reinforcementRoom picks a random room at least reinforcementMinDist from
+// the player, or the farthest room if none is.
+func (g *Game) reinforcementRoom() *bsp.Room {
+	if g.currentBSPTree == nil {
+		return nil
+	}
+	var far []*bsp.Room
+	var farthest *bsp.Room
+	best := -1.0
+	for _, r := range bsp.GetRooms(g.currentBSPTree) {
+		d := math.Hypot(float64(r.X)+float64(r.W)/2-g.camera.X, float64(r.Y)+float64(r.H)/2-g.camera.Y)
+		if d >= reinforcementMinDist {
+			far = append(far, r)
+		}
+		if d > best {
+			best, farthest = d, r
+		}
+	}
+	if len(far) > 0 {
+		return far[g.director.RNG().Intn(len(far))]
+	}
+	return farthest
+}
+
+// playScare plays an ambient scare cue from just behind the player.
+func (g *Game) playScare(cue string) {
+	if g.audioEngine == nil || g.camera == nil {
+		return
+	}
+	x := g.camera.X - g.camera.DirX*scareDistance
+	y := g.camera.Y - g.camera.DirY*scareDistance
+	g.audioEngine.PlaySFX(cue, x, y)
+}
+
+// bossAddSpread is the max offset of boss adds from the boss.
+const bossAddSpread = 1.5
+
+// bossAbilityEffects are the status effects the boss's utility abilities
+// inflict on the player.
+var bossAbilityEffects = map[string]string{
+	"intimidate":   "terrified",
+	"hack_attempt": "hacked",
+}
+
+// updateBoss runs the boss fight once the player has entered the arena and
+// carries out the boss's attacks and arena mechanics.
+func (g *Game) updateBoss() {
+	if g.boss == nil || g.boss.Dead() || (g.bossArena != nil && !g.bossArena.IsTriggered()) {
+		return
+	}
+	for _, e := range g.boss.Update(common.DeltaTime, g.camera.X, g.camera.Y, g.bossWalkable) {
+		switch e.Kind {
+		case combat.BossEventPhase:
+			g.onBossPhase(e.Phase)
+		case combat.BossEventTelegraph:
+			if g.telegraphSystem != nil {
+				g.telegraphSystem.StartTelegraph(g.world, g.bossEntity, bossTelegraphKind(e.Pattern.Shape), e.Pattern.WindupTime)
+			}
+		case combat.BossEventHit:
+			g.damagePlayer(g.boss.X, g.boss.Y, e.Damage, e.Pattern.DamageType, "boss")
+			g.audioEngine.PlaySFX("enemy_attack", g.boss.X, g.boss.Y)
+		case combat.BossEventAdds:
+			g.spawnBossAdds(e.Count)
+		case combat.BossEventHazards:
+			g.activateArenaHazards(e.Count)
+		case combat.BossEventAbility:
+			if effect, ok := bossAbilityEffects[e.Ability]; ok && g.statusReg != nil {
+				g.statusReg.ApplyToEntity(g.world, g.playerEntity, effect)
+			}
+		}
+	}
+	g.bossPos.X, g.bossPos.Y = g.boss.X, g.boss.Y
+	g.bossMarker.X, g.bossMarker.Y = g.boss.X, g.boss.Y
+	g.bossHealth.Current = int(math.Ceil(g.boss.Health))
+}
+
+// bossWalkable keeps the boss on open floor inside its arena.
+func (g *Game) bossWalkable(x, y float64) bool {
+	if r := g.bossArenaRoom; r != nil &&
+		(x < float64(r.X) || x >= float64(r.X+r.W) || y < float64(r.Y) || y >= float64(r.Y+r.H)) {
+		return false
+	}
+	return g.isWalkable(x, y)
+}
+
+// bossTelegraphKind returns the telegraph indicator for an attack shape.
+func bossTelegraphKind(shape combat.TelegraphShape) string {
+	switch shape {
+	case combat.ShapeCone:
+		return "melee"
+	case combat.ShapeLine:
+		return "charge"
+	default:
+		return "aoe"
+	}
+}
+
+// onBossPhase announces the boss entering a new phase.
+func (g *Game) onBossPhase(phase int) {
+	data := g.boss.Phases.GetCurrentPhaseData()
+	if g.feedbackSystem != nil {
+		g.feedbackSystem.AddScreenShake(4.0)
+	}
+	g.audioEngine.PlaySFX("boss_phase", g.boss.X, g.boss.Y)
+	if data != nil && data.Enraged {
+		g.hud.ShowMessage("The boss is enraged!")
+	}
+	logrus.WithFields(logrus.Fields{
+		"phase":     phase,
+		"archetype": g.boss.Archetype.Name,
+		"health":    g.boss.Health,
+	}).Info("Boss entered phase")
+}
+
+// spawnBossAdds summons n minions around the boss, already hunting the
+// player.
+func (g *Game) spawnBossAdds(n int) {
+	nameGen := dialogue.NewNameGenerator()
+	for i := 0; i < n; i++ {
+		x := g.boss.X + (g.rng.Float64()*2-1)*bossAddSpread
+		y := g.boss.Y + (g.rng.Float64()*2-1)*bossAddSpread
+		if !g.bossWalkable(x, y) {
+			x, y = g.boss.X, g.boss.Y
+		}
+		agent := g.spawnEnemy(nameGen, "boss_adds", x, y)
+		g.perception.Dispatch(agent, g.camera.X, g.camera.Y)
+	}
+}
+
+// activateArenaHazards places n hazards on open floor of the boss arena.
+func (g *Game) activateArenaHazards(n int) {
+	r := g.bossArenaRoom
+	if g.hazardECSSystem == nil || r == nil || r.W < 3 || r.H < 3 {
+		return
+	}
+	for placed, tries := 0, 0; placed < n && tries < n*10; tries++ {
+		x := float64(r.X+1+g.rng.Intn(r.W-2)) + 0.5
+		y := float64(r.Y+1+g.rng.Intn(r.H-2)) + 0.5
+		if !g.isWalkableTileAt(int(x), int(y)) {
+			continue
+		}
+		g.hazardECSSystem.SpawnHazard(g.world, x, y)
+		placed++
+	}
+}
+
+// damageBoss applies a hit for damage from the weapon in slot to the boss.
+func (g *Game) damageBoss(currentWeapon weapon.Weapon, slot int, damage float64) {
+	dealt := g.boss.TakeDamage(damage)
+	g.combatLog.Record(combatlog.Event{Kind: combatlog.KindHit, Source: currentWeapon.Name, Target: "boss", Amount: dealt})
+	if g.feedbackSystem != nil {
+		g.feedbackSystem.AddHitFlash(0.1)
+	}
+	if g.boss.Dead() {
+		g.combatLog.Record(combatlog.Event{Kind: combatlog.KindKill, Source: currentWeapon.Name, Target: "boss"})
+		g.bossHealth.Current = 0
+		g.handleEnemyDeath(g.boss.X, g.boss.Y)
+		g.hud.ShowMessage("Boss defeated!")
+		return
+	}
+	if dealt > 0 && g.masteryManager != nil {
+		g.masteryManager.AddMasteryXP(slot, masteryHitXP)
+	}
+}
+
+// startAgentAttack animates an agent's attack and applies it to the player.
+func (g *Game) startAgentAttack(agent *ai.Agent) {
+	dx := g.camera.X - agent.X
+	dy := g.camera.Y - agent.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+
+	// Start attack animation on agent's entity if available
+	if g.attackAnimSystem != nil && g.world != nil {
+		animType := g.selectAttackAnimation(agent, dist)
+		entity := g.findOrCreateAgentEntity(agent)
+		dirX := dx
+		dirY := dy
+		if dist > 0.01 {
+			dirX /= dist
+			dirY /= dist
+		}
+		intensity := 1.0 + (agent.Damage / 50.0)
+		g.attackAnimSystem.StartAttack(g.world, entity, animType, dirX, dirY, intensity)
+	}
+
+	g.handleAgentAttack(agent)
+}
+
+// enemyMeleeReach is the distance within which enemies strike in melee
+// even when carrying a ranged weapon.
+const enemyMeleeReach = 1.5
+
+// handleAgentAttack processes an AI agent's attack on the player. Agents
+// with ranged weapons out of melee reach aim a shot, telegraphed from the
+// agent, that flies to where the player stood; the rest hit at once.
+func (g *Game) handleAgentAttack(agent *ai.Agent) {
+	damage := agent.Damage
+	if agent.Order() == ai.OrderSuppress {
+		damage *= ai.SuppressDamageScale
+	}
+	agent.Cooldown = 60
+
+	weapon, ranged := combat.RangedWeaponFor(agent.Weapon)
+	if ranged && math.Hypot(g.camera.X-agent.X, g.camera.Y-agent.Y) > enemyMeleeReach {
+		shot := g.rangedSystem.Aim(g.world, agent.ID, weapon, damage, agent.X, agent.Y, g.camera.X, g.camera.Y)
+		if g.telegraphSystem != nil {
+			g.world.AddComponent(shot, &telegraph.PositionComponent{X: agent.X, Y: agent.Y})
+			g.telegraphSystem.StartTelegraph(g.world, shot, "ranged", weapon.WindupTime)
+		}
+		return
+	}
+	damage, negated := g.defendAgainst(agent, damage)
+	g.audioEngine.PlaySFX("enemy_attack", agent.X, agent.Y)
+	if negated {
+		return
+	}
+	g.damagePlayer(agent.X, agent.Y, damage, combat.DamagePhysical, agent.ArchetypeID)
+}
+
+// defendAgainst runs an enemy's melee blow through the player's parry or
+// block, returning the damage that gets through and whether the blow was
+// negated. A perfect parry staggers the attacker.
+func (g *Game) defendAgainst(agent *ai.Agent, damage float64) (float64, bool) {
+	defense := g.getPlayerDefenseComponent()
+	if defense == nil {
+		return damage, false
+	}
+	perfect := defense.IsPerfectParryWindow()
+	threat := math.Atan2(agent.Y-g.camera.Y, agent.X-g.camera.X)
+	facing := math.Atan2(g.camera.DirY, g.camera.DirX)
+
+	through, negated := combat.ProcessIncomingDamage(defense, damage, threat, facing)
+	switch {
+	case negated && perfect:
+		agent.Stagger(int(defense.ParryStunDuration * 60))
+		g.audioEngine.PlaySFX("parry", g.camera.X, g.camera.Y)
+		g.hud.ShowMessage("Parried!")
+	case !negated && through < damage:
+		g.audioEngine.PlaySFX("block", g.camera.X, g.camera.Y)
+	}
+	return through, negated
+}
+
+// updateEnemyShots flies enemy shots and applies their hits. Shots pass
+// through a player who is dodging.
+func (g *Game) updateEnemyShots() {
+	blocked := func(x, y float64) bool {
+		return !g.isWalkableTileAt(int(x), int(y))
+	}
+	for _, e := range g.rangedSystem.Update(g.world, common.DeltaTime, g.camera.X, g.camera.Y, blocked) {
+		switch e.Kind {
+		case combat.ShotEventFired:
+			g.audioEngine.PlaySFX("enemy_attack", e.X, e.Y)
+		case combat.ShotEventHit:
+			if defense := g.getPlayerDefenseComponent(); defense != nil && defense.IsInvulnerable() {
+				continue
+			}
+			g.damagePlayer(e.X, e.Y, e.Damage, e.DamageType, g.attackerName(e.Owner))
+		case combat.ShotEventBlocked:
+			if g.impactEmitter != nil {
+				angle := math.Atan2(e.Y-g.camera.Y, e.X-g.camera.X)
+				g.impactEmitter.EmitImpact(e.X, e.Y, particle.ImpactProjectile, particle.MaterialStone, angle)
+			}
+		}
+	}
+}
+
+// Player projectile tuning. Enemies and the boss present their hit radius
+// to player shots.
+const (
+	playerShotRadius = 0.15
+	agentShotRadius  = 0.3
+	bossShotRadius   = 0.6
+)
+
+// playerShotColor tints the streaks of the player's non-explosive shots.
+var playerShotColor = color.RGBA{R: 255, G: 200, B: 120, A: 255}
+
+// launchProjectile puts a shot of a projectile weapon in flight from the
+// player's eye, climbing or dropping with their aim.
+func (g *Game) launchProjectile(w weapon.Weapon, x, y, dirX, dirY float64) {
+	g.projectiles.Spawn(projectile.Flight{
+		Slot:    g.arsenal.CurrentSlot,
+		X:       x,
+		Y:       y,
+		Z:       combat.EyeHeight,
+		VelX:    dirX * w.Speed,
+		VelY:    dirY * w.Speed,
+		VelZ:    w.Speed * math.Tan(g.camera.Pitch*math.Pi/180),
+		Gravity: w.Gravity,
+		Radius:  playerShotRadius,
+		Damage:  g.getUpgradedWeaponDamage(w),
+		Splash:  w.Splash,
+		Range:   w.Range,
+		Color:   playerShotColor,
+	})
+}
+
+// projectileTargets lists the living enemies and the boss for player shots,
+// with the hit IDs the enemy raycast uses.
+func (g *Game) projectileTargets() []projectile.Target {
+	targets := make([]projectile.Target, 0, len(g.aiAgents)+1)
+	for i, agent := range g.aiAgents {
+		if agent.Health > 0 {
+			targets = append(targets, projectile.Target{ID: uint64(i + 1), X: agent.X, Y: agent.Y, Radius: agentShotRadius})
+		}
+	}
+	if b := g.boss; b != nil && !b.Dead() {
+		targets = append(targets, projectile.Target{ID: bossTargetID, X: b.X, Y: b.Y, Radius: bossShotRadius})
+	}
+	return targets
+}
+
+// updatePlayerProjectiles flies the player's shots and applies their
+// impacts. Each landed shot is logged with the targets it struck.
+func (g *Game) updatePlayerProjectiles() {
+	blocked := func(x, y float64) bool {
+		return !g.isWalkableTileAt(int(x), int(y))
+	}
+	for _, imp := range g.projectiles.Update(common.DeltaTime, blocked, g.projectileTargets()) {
+		f := imp.Flight
+		w := g.arsenal.Fitted(f.Slot)
+		if f.Splash > 0 {
+			g.explodeProjectile(f)
+		} else if imp.Target == 0 && g.impactEmitter != nil {
+			angle := math.Atan2(f.Y-g.camera.Y, f.X-g.camera.X)
+			g.impactEmitter.EmitImpact(f.X, f.Y, particle.ImpactProjectile, particle.MaterialStone, angle)
+		}
+		hits := 0
+		for _, s := range imp.Strikes {
+			if g.landStrike(w, f, s) {
+				hits++
+			}
+		}
+		g.combatLog.Record(combatlog.Event{Kind: combatlog.KindShot, Source: w.Name, Hits: hits})
+	}
+}
+
+// landStrike applies one strike of a player shot's impact and reports
+// whether it found a living target. Direct hits land on the zone the shot
+// reached; explosions hit the torso.
+func (g *Game) landStrike(w weapon.Weapon, f projectile.Flight, s projectile.Strike) bool {
+	if s.Target == bossTargetID {
+		if g.boss == nil || g.boss.Dead() {
+			return false
+		}
+		g.damageBoss(w, f.Slot, s.Damage)
+		return true
+	}
+	idx := int(s.Target - 1)
+	if idx < 0 || idx >= len(g.aiAgents) || g.aiAgents[idx].Health <= 0 {
+		return false
+	}
+	agent := g.aiAgents[idx]
+
+	zone := combat.ZoneTorso
+	if speed := math.Hypot(f.VelX, f.VelY); s.Direct && speed > 0 {
+		// How far the shot's line passes from the agent's center.
+		lateral := math.Abs((agent.X-f.X)*f.VelY-(agent.Y-f.Y)*f.VelX) / speed
+		zone = combat.DefaultHitZones.Zone(f.Z, lateral)
+	}
+	g.strikeAgent(agent, w, f.Slot, s.Damage, zone)
+
+	if agent.Health <= 0 {
+		g.combatLog.Record(combatlog.Event{Kind: combatlog.KindKill, Source: w.Name, Target: agent.ArchetypeID})
+		g.handleAgentDeath(agent)
+	}
+	return true
+}
+
+// explodeProjectile throws debris and shakes the screen where an explosive
+// shot landed, and alerts enemies to the blast.
+func (g *Game) explodeProjectile(f projectile.Flight) {
+	if g.particleSystem != nil {
+		push := blastPush
+		push.X, push.Y = f.X, f.Y
+		g.particleSystem.AddAttractor(push)
+		g.particleSystem.SpawnForcedBurst(f.X, f.Y, f.Z, 20, 8.0, 1.0, 1.5, 1.0, f.Color, debrisCollision, particle.ForceAttractors)
+	}
+	if g.feedbackSystem != nil {
+		dist := math.Hypot(f.X-g.camera.X, f.Y-g.camera.Y)
+		g.feedbackSystem.AddScreenShake(5.0 / (1.0 + dist*0.5))
+	}
+	g.perception.EmitSound(f.X, f.Y, explosionLoudness)
+	g.audioEngine.PlaySFX("explosion", f.X, f.Y)
+}
+
+// hurtPlayer resolves a hit on the player from source against their armor
+// and its resistances, takes the result off the HUD's armor and health and
+// logs it.
+func (g *Game) hurtPlayer(amount float64, dmgType combat.DamageType, source string) combat.DamageResult {
+	target := combat.Target{Health: float64(g.hud.Health), Armor: float64(g.hud.Armor), Resistances: g.playerResistances()}
+	r := g.combatSystem.Resolve(combat.Hit{Amount: amount, Type: dmgType}, target)
+	g.hud.Armor = max(0, g.hud.Armor-int(math.Round(r.ArmorDamage)))
+	alive := g.hud.Health > 0
+	g.hud.Health -= int(math.Round(r.HealthDamage))
+	if alive && g.hud.Health <= 0 {
+		g.profileStats.Deaths++
+	}
+	g.wearArmor(amount)
+	g.combatLog.Record(combatlog.Event{Kind: combatlog.KindHurt, Source: source, Amount: r.HealthDamage, DamageType: string(dmgType)})
+	return r
+}
+
+// attackerName returns the archetype of the agent with the given ID for
+// the combat log, or the ID itself once the agent is gone.
+func (g *Game) attackerName(id string) string {
+	for _, agent := range g.aiAgents {
+		if agent.ID == id {
+			return agent.ArchetypeID
+		}
+	}
+	return id
+}
+
+// hurtAgent resolves a hit on an enemy against its resistances, takes it off
+// the enemy's health and returns the health lost.
+func (g *Game) hurtAgent(agent *ai.Agent, amount float64, dmgType combat.DamageType) float64 {
+	r := g.combatSystem.Resolve(combat.Hit{Amount: amount, Type: dmgType}, combat.Target{Health: agent.Health, Resistances: agent.Resistances})
+	agent.Health -= r.HealthDamage
+	return r.HealthDamage
+}
+
+// damagePlayer applies damage of a type from an attacker at (fromX, fromY)
+// to the player, through hurtPlayer, along with impact and screen feedback.
+func (g *Game) damagePlayer(fromX, fromY, damage float64, dmgType combat.DamageType, source string) {
+	healthDamage := g.hurtPlayer(damage, dmgType, source).HealthDamage
+	g.hud.ShowMessage("Taking damage!")
+	g.arsenal.BreakCombo()
+	if g.statusReg != nil {
+		g.statusReg.Strike(g.world, g.playerEntity, string(dmgType))
+	}
+
+	// Add impact particles for player damage
+	if g.impactEmitter != nil {
+		impactAngle := math.Atan2(g.camera.Y-fromY, g.camera.X-fromX)
+		g.impactEmitter.EmitImpact(g.camera.X, g.camera.Y, particle.ImpactMelee, particle.MaterialFlesh, impactAngle)
+	}
+
+	// Add screen feedback for player damage
+	if g.feedbackSystem != nil {
+		shakeIntensity := healthDamage / 5.0
+		if shakeIntensity > 8.0 {
+			shakeIntensity = 8.0
+		}
+		g.feedbackSystem.AddScreenShake(shakeIntensity)
+		g.feedbackSystem.AddHitFlash(0.3 + (healthDamage / 100.0))
+	}
+
+	// Trigger enhanced camera effects for player damage
+	if g.cameraFXSystem != nil {
+		shakeIntensity := healthDamage / 10.0
+		g.cameraFXSystem.TriggerShake(shakeIntensity)
+		fr, fg, fb, fa := camerafx.Flash.Red()
+		g.cameraFXSystem.TriggerFlash(fr, fg, fb, fa*(healthDamage/50.0))
+		if healthDamage > 30 {
+			g.cameraFXSystem.TriggerChromatic(0.3)
+		}
+	}
+
+	// Trigger directional damage indicator showing damage direction
+	if g.damageDirSystem != nil {
+		// Calculate player facing angle from direction vector
+		playerAngle := math.Atan2(g.camera.DirY, g.camera.DirX)
+		g.damageDirSystem.TriggerDamage(fromX, fromY, g.camera.X, g.camera.Y, healthDamage, playerAngle)
+	}
+
+	if g.hud.Health <= 0 {
+		g.hud.Health = 0
+	}
+}
+
+// Squad command tuning: the attack order picks the living enemy closest to
+// the crosshair within squadAimCone radians and squadAimRange tiles;
+// abilities with no enemy in sight land squadAbilityReach tiles ahead.
+const (
+	squadAimCone      = 0.35
+	squadAimRange     = 20.0
+	squadAbilityReach = 5.0
+)
+
+// squadOrderLabels name squad behaviors on the HUD.
+var squadOrderLabels = map[squad.BehaviorState]string{
+	squad.BehaviorFollow: "FOLLOW",
+	squad.BehaviorHold:   "HOLD",
+	squad.BehaviorAttack: "ATTACK",
+}
+
+// handleSquadCommands issues squad orders from the command bindings. The
+// member who answers barks an acknowledgment that is also shown on the HUD.
+func (g *Game) handleSquadCommands() {
+	s := g.squadCompanions
+	if s == nil {
+		return
+	}
+
+	var ack squad.Acknowledgment
+	var err error
+	switch {
+	case g.input.IsJustPressed(input.ActionSquadHold):
+		ack, err = s.HoldPosition()
+	case g.input.IsJustPressed(input.ActionSquadRegroup):
+		g.squadTarget = nil
+		ack, err = s.Regroup()
+	case g.input.IsJustPressed(input.ActionSquadAttack):
+		target := g.aimedAgent()
+		if target == nil {
+			g.hud.ShowMessage("No target in sight")
+			return
+		}
+		g.squadTarget = target
+		ack, err = s.AttackTarget(target.X, target.Y)
+	case g.input.IsJustPressed(input.ActionSquadAbility):
+		var uses []squad.AbilityUse
+		x, y := g.squadAbilityAim()
+		uses, ack, err = s.UseAbility(x, y)
+		if err == nil {
+			g.applySquadAbilities(uses)
+		}
+	default:
+		return
+	}
+
+	switch {
+	case errors.Is(err, squad.ErrNoMembers):
+		g.hud.ShowMessage("No squad to command")
+	case errors.Is(err, squad.ErrAbilityNotReady):
+		g.hud.ShowMessage("Squad abilities recharging")
+	case err != nil:
+		logrus.WithError(err).Warn("Squad command failed")
+	default:
+		g.hud.ShowMessage(ack.Line)
+		if g.audioEngine != nil {
+			_ = g.audioEngine.PlayBark(ack.Voice, ack.Line, ack.X, ack.Y)
+		}
+	}
+}
+
+// aimedAgent returns the living enemy closest to the player's crosshair, or
+// nil if none is in the aim cone.
+func (g *Game) aimedAgent() *ai.Agent {
+	var best *ai.Agent
+	bestAngle := squadAimCone
+	for _, agent := range g.aiAgents {
+		if agent.Health <= 0 {
+			continue
+		}
+		dx, dy := agent.X-g.camera.X, agent.Y-g.camera.Y
+		dist := math.Hypot(dx, dy)
+		if dist == 0 || dist > squadAimRange {
+			continue
+		}
+		dot := (dx*g.camera.DirX + dy*g.camera.DirY) / (dist * math.Hypot(g.camera.DirX, g.camera.DirY))
+		if angle := math.Acos(math.Max(-1, math.Min(1, dot))); angle < bestAngle {
+			best, bestAngle = agent, angle
+		}
+	}
+	return best
+}
+
+// squadAbilityAim is where damaging squad abilities land: the enemy under
+// the crosshair, else the squad's attack target, else a point ahead.
+func (g *Game) squadAbilityAim() (float64, float64) {
+	if agent := g.aimedAgent(); agent != nil {
+		return agent.X, agent.Y
+	}
+	if t := g.squadTarget; t != nil && t.Health > 0 {
+		return t.X, t.Y
+	}
+	return g.camera.X + g.camera.DirX*squadAbilityReach, g.camera.Y + g.camera.DirY*squadAbilityReach
+}
+
+// applySquadAbilities heals the player or damages the enemies caught in each
+// ability's blast.
+func (g *Game) applySquadAbilities(uses []squad.AbilityUse) {
+	for _, use := range uses {
+		a := use.Ability
+		if a.Heal {
+			g.hud.Health += int(a.Amount)
+			if g.hud.Health > g.hud.MaxHealth {
+				g.hud.Health = g.hud.MaxHealth
+			}
+			g.audioEngine.PlaySFX("squad_heal", use.X, use.Y)
+			continue
+		}
+
+		for _, agent := range g.aiAgents {
+			if agent.Health <= 0 || math.Hypot(agent.X-use.X, agent.Y-use.Y) > a.Radius {
+				continue
+			}
+			g.hurtAgent(agent, a.Amount, combat.DamageExplosive)
+			if agent.Health <= 0 {
+				g.handleAgentDeath(agent)
+			}
+		}
+		g.perception.EmitSound(use.X, use.Y, explosionLoudness)
+		g.audioEngine.PlaySFX("squad_blast", use.X, use.Y)
+	}
+}
+
+// updateSquadAttack keeps an attack order on its target as it moves and
+// lands the companions' hits. The squad regroups once the target is down.
+func (g *Game) updateSquadAttack() {
+	s := g.squadCompanions
+	if s.Behavior != squad.BehaviorAttack {
+		return
+	}
+	t := g.squadTarget
+	if t == nil || t.Health <= 0 {
+		g.squadTarget = nil
+		s.Command("follow")
+		g.hud.ShowMessage("Target down")
+		return
+	}
+
+	s.SetTarget(t.X, t.Y)
+	for _, m := range s.GetMembers() {
+		if !m.Agent.Blackboard.Bool(ai.KeyAttacked) {
+			continue
+		}
+		m.Agent.Blackboard.Delete(ai.KeyAttacked)
+		g.hurtAgent(t, m.Agent.Damage, combat.DamagePhysical)
+		g.audioEngine.PlaySFX("weapon_fire", m.X, m.Y)
+		if t.Health <= 0 {
+			g.handleAgentDeath(t)
+			return
+		}
+	}
+}
+
+// updateSquadHUD mirrors the squad order and companion status on the HUD.
+func (g *Game) updateSquadHUD() {
+	g.hud.SquadOrder = squadOrderLabels[g.squadCompanions.Behavior]
+	g.hud.Squad = g.hud.Squad[:0]
+	for _, m := range g.squadCompanions.GetMembers() {
+		g.hud.Squad = append(g.hud.Squad, ui.SquadIndicator{
+			Name:         strings.ToUpper(m.ClassID),
+			Health:       m.Health,
+			MaxHealth:    m.MaxHealth,
+			AbilityReady: m.AbilityReady(),
+		})
+	}
+}
+
+// updateSquadAndEventTriggers updates squad companions and event trigger systems.
+func (g *Game) updateSquadAndEventTriggers() {
+	if g.squadCompanions != nil {
+		g.squadCompanions.Update(g.camera.X, g.camera.Y, g.currentMap, g.camera.X, g.camera.Y, g.seed)
+		g.updateSquadAttack()
+		g.syncCompanionEntities()
+		g.updateSquadHUD()
+	}
+
+	deltaTime := common.DeltaTime
+	g.updateAlarmTrigger(deltaTime)
+	g.updateLockdownTrigger(deltaTime)
+	g.checkBossArenaTrigger()
+	g.updateEventObjective(deltaTime)
+	g.checkLevelExit()
+}
+
+// updateAlarmTrigger updates the alarm event trigger if active. While the
+// alarm runs every dynamic light strobes.
+func (g *Game) updateAlarmTrigger(deltaTime float64) {
+	if g.alarmTrigger != nil && g.alarmTrigger.IsActive() {
+		g.alarmTrigger.Update(deltaTime)
+	}
+	if g.lightingSystem == nil {
+		return
+	}
+	alarmOn := g.alarmTrigger != nil && g.alarmTrigger.IsActive()
+	if alarmOn && g.lightingSystem.Override().Kind != lighting.AnimStrobe {
+		alarm := lighting.NewLightAnimator(lighting.AnimStrobe, int64(g.seed))
+		alarm.Period = 30
+		alarm.Depth = 0.7
+		g.lightingSystem.SetOverride(alarm)
+	} else if !alarmOn && g.lightingSystem.Override().Kind == lighting.AnimStrobe {
+		g.lightingSystem.SetOverride(lighting.LightAnimator{})
+	}
+}
+
+// updateLockdownTrigger updates the lockdown event trigger and checks time limits.
+func (g *Game) updateLockdownTrigger(deltaTime float64) {
+	if g.lockdownTrigger == nil || !g.lockdownTrigger.IsActive() {
+		return
+	}
+
+	g.lockdownTrigger.Update(deltaTime)
+
+	if g.questTracker == nil {
+		return
+	}
+
+	remainingTime := g.lockdownTrigger.GetRemaining()
+	if g.lockdownTrigger.IsExpired() {
+		g.hud.ShowMessage("Lockdown complete - you are trapped!")
+	} else if remainingTime < 10 {
+		g.hud.ShowMessage("WARNING: 10 seconds remaining!")
+	}
+}
+
+// Scripted nightfall when a boss arena triggers.
+const (
+	bossNightHour        = 22.0
+	bossNightfallSeconds = 8.0
+)
+
+// checkBossArenaTrigger checks if the player has entered the boss arena.
+func (g *Game) checkBossArenaTrigger() {
+	if g.bossArena == nil || g.bossArena.IsTriggered() || g.bossArenaRoom == nil {
+		return
+	}
+
+	room := g.bossArenaRoom
+	if g.camera.X < float64(room.X) || g.camera.X >= float64(room.X+room.W) ||
+		g.camera.Y < float64(room.Y) || g.camera.Y >= float64(room.Y+room.H) {
+		return
+	}
+
+	g.bossArena.Trigger()
+	// On maps with a running clock, night falls as the boss appears.
+	if g.dayCycle != nil && g.dayCycle.Speed > 0 && g.dayCycle.Phase() != lighting.PhaseNight {
+		g.dayCycle.TransitionTo(bossNightHour, bossNightfallSeconds)
+	}
+	_ = event.GenerateEventAudioSting(g.seed, event.EventBossArena)
+	g.audioEngine.PlaySFX("boss_encounter", g.camera.X, g.camera.Y)
+	eventText := event.GenerateEventText(g.seed, event.EventBossArena)
+	g.hud.ShowMessage(eventText)
+}
+
+// updateQuestObjectives updates quest progress and speedrun timers.
+func (g *Game) updateQuestObjectives() {
+	if g.questTracker == nil {
+		return
+	}
+
+	elapsedTime := time.Since(g.levelStartTime).Seconds()
+	for i := range g.questTracker.Objectives {
+		obj := &g.questTracker.Objectives[i]
+		if obj.ID == "bonus_speed" && !obj.Complete {
+			if elapsedTime > float64(obj.Count) {
+				obj.Complete = false
+			}
+		}
+	}
+}
+
+// Event objective tuning.
+const (
+	sabotageType      = "sabotage" // Destructible type of sabotage targets
+	sabotageHealth    = 60.0
+	defendRadius      = 2.5  // How close the player comes to start the defense
+	defendWaveDelay   = 20.0 // Seconds between waves
+	defendWaveSize    = 3    // Enemies in the first wave; each wave brings one more
+	beaconRadius      = 1.5  // How close the player comes to call extraction
+	extractionRadius  = 1.5
+	escortArrival     = 2.0 // How close to its destination an escort is delivered
+	escortSpriteSeed  = 4242
+	extractionWarning = 10.0 // Seconds left when the player is warned
+)
+
+// eventObjective returns the level's event objective while it is in play.
+func (g *Game) eventObjective() *quest.Objective {
+	if g.questTracker == nil {
+		return nil
+	}
+	for i := range g.questTracker.Objectives {
+		obj := &g.questTracker.Objectives[i]
+		if obj.ID == "bonus_event" && !obj.Complete && !obj.Failed {
+			return obj
+		}
+	}
+	return nil
+}
+
+// setupEventObjective sets up what the level's event objective plays out
+// with: the escortee, the defense waves, the extraction countdown or the
+// sabotage targets.
+func (g *Game) setupEventObjective() {
+	g.escort, g.defense, g.defenseWaves, g.extraction = nil, nil, nil, nil
+	obj := g.eventObjective()
+	if obj == nil {
+		return
+	}
+	switch obj.Type {
+	case quest.ObjEscort:
+		g.escort = ai.NewEscort("escort", obj.PosX, obj.PosY)
+	case quest.ObjDefend:
+		g.defense = event.NewDefendEvent("defend", obj.PosX, obj.PosY, defendRadius, obj.Count, defendWaveDelay)
+	case quest.ObjExtraction:
+		dest := obj.Sites[0]
+		g.extraction = event.NewExtractionEvent("extraction", dest.X, dest.Y, extractionRadius, float64(obj.Count))
+	case quest.ObjSabotage:
+		for i, site := range obj.Sites {
+			g.destructibleSystem.Add(destruct.NewDestructible(fmt.Sprintf("sabotage_%d", i), sabotageType, sabotageHealth, site.X, site.Y))
+		}
+	}
+}
+
+// updateEventObjective advances the level's event objective.
+func (g *Game) updateEventObjective(deltaTime float64) {
+	obj := g.eventObjective()
+	if obj == nil {
+		return
+	}
+	switch {
+	case g.escort != nil:
+		g.updateEscort(obj, deltaTime)
+	case g.defense != nil:
+		g.updateDefense(obj, deltaTime)
+	case g.extraction != nil:
+		g.updateExtraction(obj, deltaTime)
+	}
+}
+
+// updateEscort moves the escortee after the player, lets the enemies
+// attacking near it hurt it, and delivers or loses it.
+func (g *Game) updateEscort(obj *quest.Objective, deltaTime float64) {
+	following := g.escort.Following
+	g.escort.Update(&ai.Context{TileMap: g.currentMap, PlayerX: g.camera.X, PlayerY: g.camera.Y, Nav: g.navGrid})
+	dest := obj.Sites[0]
+	if !following && g.escort.Following {
+		g.hud.ShowMessage("Escort joined: " + obj.Desc)
+		if g.objectiveCompassSystem != nil {
+			g.objectiveCompassSystem.UpdateObjectivePosition(obj.ID, dest.X, dest.Y)
+		}
+	}
+	for _, enemy := range g.escort.Threats(g.aiAgents) {
+		g.hurtAgent(g.escort.Agent, enemy.Damage*deltaTime, agentDamageType(enemy))
+	}
+	switch {
+	case g.escort.Dead():
+		g.failEventObjective("Escort lost: " + obj.Desc)
+	case g.escort.Reached(dest.X, dest.Y, escortArrival):
+		g.advanceEventObjective(1)
+	}
+}
+
+// updateDefense sends the defense waves once the player holds the
+// position and counts a wave cleared when all its enemies are dead.
+func (g *Game) updateDefense(obj *quest.Objective, deltaTime float64) {
+	if g.defense.Update(deltaTime, g.camera.X, g.camera.Y) {
+		g.spawnDefenseWave(obj)
+	}
+	cleared := 0
+	for _, wave := range g.defenseWaves {
+		alive := false
+		for _, agent := range wave {
+			alive = alive || agent.Health > 0
+		}
+		if !alive {
+			cleared++
+		}
+	}
+	if n := cleared - int(obj.Progress); n > 0 {
+		g.advanceEventObjective(n)
+	}
+}
+
+// spawnDefenseWave brings the next defense wave into a room away from the
+// player and sends it at the defended position.
+func (g *Game) spawnDefenseWave(obj *quest.Objective) {
+	room := g.reinforcementRoom()
+	if room == nil {
+		return
+	}
+	wave := len(g.defenseWaves) + 1
+	squadID := fmt.Sprintf("defend_%d", wave)
+	nameGen := dialogue.NewNameGenerator()
+	cx := float64(room.X) + float64(room.W)/2
+	cy := float64(room.Y) + float64(room.H)/2
+	r := g.director.RNG()
+	agents := make([]*ai.Agent, 0, defendWaveSize+wave-1)
+	for range defendWaveSize + wave - 1 {
+		x := cx + (r.Float64()*2-1)*reinforcementSpread
+		y := cy + (r.Float64()*2-1)*reinforcementSpread
+		agent := g.spawnEnemy(nameGen, squadID, x, y)
+		g.perception.Dispatch(agent, obj.PosX, obj.PosY)
+		agents = append(agents, agent)
+	}
+	g.defenseWaves = append(g.defenseWaves, agents)
+	g.hud.ShowMessage(fmt.Sprintf("Wave %d of %d incoming!", wave, obj.Count))
+}
+
+// updateExtraction starts the extraction countdown at the beacon and
+// completes or fails the objective when the countdown ends.
+func (g *Game) updateExtraction(obj *quest.Objective, deltaTime float64) {
+	ex := g.extraction
+	if !ex.IsActive() && math.Hypot(obj.PosX-g.camera.X, obj.PosY-g.camera.Y) <= beaconRadius {
+		ex.Activate()
+		g.hud.ShowMessage(fmt.Sprintf("Extraction called: %d seconds!", obj.Count))
+		g.audioEngine.PlaySFX("alarm", obj.PosX, obj.PosY)
+		if g.objectiveCompassSystem != nil {
+			g.objectiveCompassSystem.UpdateObjectivePosition(obj.ID, ex.X, ex.Y)
+		}
+	}
+	before := ex.GetRemaining()
+	ex.Update(deltaTime, g.camera.X, g.camera.Y)
+	switch {
+	case ex.IsExtracted():
+		g.advanceEventObjective(obj.Count)
+	case ex.IsExpired():
+		g.failEventObjective("Extraction missed: " + obj.Desc)
+	case before > extractionWarning && ex.GetRemaining() <= extractionWarning:
+		g.hud.ShowMessage("WARNING: 10 seconds to extraction!")
+	}
+}
+
+// advanceEventObjective adds progress to the level's event objective and
+// rewards finishing it.
+func (g *Game) advanceEventObjective(amount int) {
+	obj := g.eventObjective()
+	if obj == nil {
+		return
+	}
+	g.questTracker.UpdateProgress(obj.ID, amount)
+	if obj.Complete {
+		g.markObjectiveComplete(obj.ID)
+		g.grantQuestReward(obj.ID, obj.Target, false, int(obj.Progress), obj.Count)
+	}
+}
+
+// failEventObjective fails the level's event objective and tells the
+// player why.
+func (g *Game) failEventObjective(msg string) {
+	obj := g.eventObjective()
+	if obj == nil {
+		return
+	}
+	g.questTracker.Fail(obj.ID)
+	if g.objectiveCompassSystem != nil {
+		g.objectiveCompassSystem.RemoveObjective(obj.ID)
+	}
+	g.hud.ShowMessage(msg)
+	if g.toastSystem != nil {
+		g.toastSystem.Queue(toast.TypeQuest, msg, toast.PriorityHigh)
+	}
+}
+
+// campaignLevels is how many levels a run's quest campaign spans.
+const campaignLevels = 8
+
+// recordQuest records a campaign event, e.g. "enemy" for a kill, and tells
+// the player about the quests it moves on.
+func (g *Game) recordQuest(target string) {
+	if g.campaign != nil {
+		g.reportQuests(g.campaign.Record(target, 1))
+	}
+}
+
+// reportQuests tells the player about campaign quests that changed state.
+// Quests closed by a branch taken or a failure go without a word.
+func (g *Game) reportQuests(transitions []quest.Transition) {
+	for _, t := range transitions {
+		var msg string
+		switch t.To {
+		case quest.NodeActive:
+			msg = "New quest: " + t.Node.Title
+		case quest.NodeCompleted:
+			msg = "Quest complete: " + t.Node.Title
+		case quest.NodeFailed:
+			msg = "Quest failed: " + t.Node.Title
+		default:
+			continue
+		}
+		g.hud.ShowMessage(msg)
+		if g.toastSystem != nil {
+			g.toastSystem.Queue(toast.TypeQuest, msg, toast.PriorityHigh)
+		}
+	}
+}
+
+// campaignState returns the campaign's progress for a save.
+func (g *Game) campaignState() []save.QuestNode {
+	if g.campaign == nil {
+		return nil
+	}
+	nodes := g.campaign.Snapshot()
+	state := make([]save.QuestNode, len(nodes))
+	for i, n := range nodes {
+		state[i] = save.QuestNode{ID: n.ID, State: int(n.State), Progress: n.Progress, Claimed: n.Claimed}
+	}
+	return state
+}
+
+// restoreCampaign rebuilds the run's campaign from its seed and puts back
+// the saved progress. Older saves without any start the campaign afresh.
+func (g *Game) restoreCampaign(state []save.QuestNode) {
+	g.campaign = quest.GenerateCampaign(g.seed, campaignLevels, g.genreID)
+	nodes := make([]quest.NodeProgress, len(state))
+	for i, n := range state {
+		nodes[i] = quest.NodeProgress{ID: n.ID, State: quest.NodeState(n.State), Progress: n.Progress, Claimed: n.Claimed}
+	}
+	g.campaign.Restore(g.levelDepth, nodes)
+	g.campaign.Evaluate(g.levelDepth)
+}
+
+// claimQuestRewards claims the rewards of the level's completed objectives
+// and campaign quests, pays them out and returns a summary line for each.
+func (g *Game) claimQuestRewards() []string {
+	var lines []string
+	if g.questTracker != nil {
+		for _, obj := range g.questTracker.Claimable() {
+			if r, err := g.questTracker.Claim(obj.ID); err == nil {
+				g.applyQuestReward(r)
+				lines = append(lines, obj.Desc+": "+r.String())
+			}
+		}
+	}
+	if g.campaign != nil {
+		for _, n := range g.campaign.Claimable() {
+			if r, err := g.campaign.Claim(n.ID); err == nil {
+				g.applyQuestReward(r)
+				lines = append(lines, n.Title+": "+r.String())
+			}
+		}
+	}
+	for _, q := range g.metaQuests.Claimable() {
+		if r, err := g.metaQuests.Claim(q.ID); err == nil {
+			g.applyQuestReward(r)
+			lines = append(lines, q.Title+": "+r.String())
+		}
+	}
+	return lines
+}
+
+// Meta-quest goals: kills per faction and distinct lore entries per genre.
+const (
+	metaFactionKills = 100
+	metaGenreLore    = 10
+)
+
+// metaGenres are the genres with a lore meta-quest.
+var metaGenres = []string{"fantasy", "scifi", "horror", "cyberpunk", "postapoc"}
+
+// loadProfile builds the meta-quests of every faction and genre, merging
+// in the progress kept in the profile, and restores the lifetime
+// statistics and achievements kept there.
+func (g *Game) loadProfile() {
+	var quests []quest.MetaQuest
+	seen := make(map[faction.FactionID]bool)
+	for _, genre := range metaGenres {
+		for _, f := range g.factionSystem.GetActiveFactions(genre) {
+			if !seen[f.ID] {
+				seen[f.ID] = true
+				quests = append(quests, quest.KillMetaQuest(string(f.ID), f.Name, metaFactionKills))
+			}
+		}
+	}
+	for _, genre := range metaGenres {
+		quests = append(quests, quest.LoreMetaQuest(genre, metaGenreLore))
+	}
+	g.metaQuests = quest.NewMetaBook(quests)
+	g.profileStats = save.Stats{}
+	// With no file of its own, unlocks are kept in the profile
+	g.achievements, _ = achievements.NewAchievementManager("")
+
+	profile, err := save.LoadProfile()
+	if err != nil {
+		if !errors.Is(err, save.ErrNoProfile) {
+			logrus.WithError(err).Warn("Failed to load profile, starting meta-quests and statistics afresh")
+		}
+		return
+	}
+	saved := make([]quest.MetaProgress, len(profile.MetaQuests))
+	for i, q := range profile.MetaQuests {
+		saved[i] = quest.MetaProgress{ID: q.ID, Progress: q.Progress, Keys: q.Keys, Claimed: q.Claimed}
+	}
+	g.metaQuests.Merge(saved)
+	g.profileStats = profile.Stats
+	unlocks := make([]achievements.UnlockedAchievement, len(profile.Stats.Achievements))
+	for i, a := range profile.Stats.Achievements {
+		unlocks[i] = achievements.UnlockedAchievement{ID: a.ID, UnlockedAt: a.UnlockedAt}
+	}
+	g.achievements.Restore(unlocks)
+}
+
+// saveProfile writes the meta-quests' progress, lifetime statistics and
+// achievements to the player's profile.
+func (g *Game) saveProfile() {
+	progress := g.metaQuests.Snapshot()
+	profile := &save.Profile{MetaQuests: make([]save.MetaQuest, len(progress)), Stats: g.profileStats}
+	for i, p := range progress {
+		profile.MetaQuests[i] = save.MetaQuest{ID: p.ID, Progress: p.Progress, Keys: p.Keys, Claimed: p.Claimed}
+	}
+	unlocks := g.achievements.GetUnlocked()
+	sort.Slice(unlocks, func(i, j int) bool { return unlocks[i].ID < unlocks[j].ID })
+	profile.Stats.Achievements = make([]save.UnlockedAchievement, len(unlocks))
+	for i, a := range unlocks {
+		profile.Stats.Achievements[i] = save.UnlockedAchievement{ID: a.ID, UnlockedAt: a.UnlockedAt}
+	}
+	if err := save.SaveProfile(profile); err != nil {
+		logrus.WithError(err).Warn("Failed to save profile")
+	}
+}
+
+// recordMetaQuest counts an event towards the meta-quests, announcing and
+// saving those it completes.
+func (g *Game) recordMetaQuest(target, key string) {
+	done := g.metaQuests.Record(target, key, 1)
+	for _, q := range done {
+		msg := "Meta-quest complete: " + q.Title
+		g.hud.ShowMessage(msg)
+		if g.toastSystem != nil {
+			g.toastSystem.Queue(toast.TypeQuest, msg, toast.PriorityCritical)
+		}
+	}
+	if len(done) > 0 {
+		g.saveProfile()
+	}
+}
+
+// metaQuestLines returns a summary line for each meta-quest that advanced
+// during the level.
+func (g *Game) metaQuestLines() []string {
+	before := make(map[string]int64, len(g.metaAtLevel))
+	for _, p := range g.metaAtLevel {
+		before[p.ID] = p.Progress
+	}
+	var lines []string
+	for _, q := range g.metaQuests.Quests {
+		if q.Progress > before[q.ID] {
+			progress := q.Progress
+			if progress > int64(q.Count) {
+				progress = int64(q.Count)
+			}
+			lines = append(lines, fmt.Sprintf("%s %d/%d", q.Title, progress, q.Count))
+		}
+	}
+	return lines
+}
+
+// recordLevelStats adds the level just completed to the lifetime
+// statistics and returns a line for each achievement it unlocked. Kills
+// come from the level's combat log; deaths and secrets were counted as
+// they happened.
+func (g *Game) recordLevelStats(summary combatlog.Summary) []string {
+	s := &g.profileStats
+	if s.WeaponKills == nil {
+		s.WeaponKills = make(map[string]int)
+	}
+	for _, e := range g.combatLog.Events() {
+		if e.Kind == combatlog.KindKill {
+			s.WeaponKills[e.Source]++
+		}
+	}
+	s.Kills += summary.Kills
+	s.LevelsCompleted++
+	s.DeepestLevel = max(s.DeepestLevel, g.levelDepth)
+	if g.levelDepth >= campaignLevels && !slices.Contains(s.Genres, g.genreID) {
+		s.Genres = append(s.Genres, g.genreID)
+	}
+
+	// Achievements about a single level are checked against the level's
+	// figures, and the rest against the lifetime totals
+	var damageTaken float64
+	for _, amount := range summary.DamageTaken {
+		damageTaken += amount
+	}
+	deaths := s.Deaths - g.statsAtLevel.Deaths
+	level := &achievements.PlayerStats{
+		Kills: summary.Kills, Deaths: deaths, TotalDeaths: deaths, DamageTaken: int64(damageTaken),
+		SecretsFound: s.SecretsFound - g.statsAtLevel.SecretsFound, CompletedLevels: 1,
+		LevelCompletionTime: time.Since(g.levelStartTime),
+	}
+	lifetime := &achievements.PlayerStats{
+		Kills: s.Kills, Deaths: s.Deaths, WeaponKills: s.WeaponKills, SecretsFound: s.SecretsFound,
+	}
+	var unlocked []achievements.Achievement
+	for _, stats := range []*achievements.PlayerStats{level, lifetime} {
+		newly, _ := g.achievements.CheckUnlocks(stats)
+		unlocked = append(unlocked, newly...)
+	}
+	sort.Slice(unlocked, func(i, j int) bool { return unlocked[i].Name < unlocked[j].Name })
+
+	lines := make([]string, len(unlocked))
+	for i, a := range unlocked {
+		lines[i] = "Achievement unlocked: " + a.Name
+		if g.toastSystem != nil {
+			g.toastSystem.Queue(toast.TypeQuest, lines[i], toast.PriorityCritical)
+		}
+	}
+	return lines
+}
+
+// favoriteWeapon returns the weapon with the most kills and its count, or
+// "" before any kill.
+func favoriteWeapon(kills map[string]int) (string, int) {
+	var name string
+	var most int
+	for weapon, n := range kills {
+		if n > most || (n == most && weapon < name) {
+			name, most = weapon, n
+		}
+	}
+	return name, most
+}
 
-	g.bossArena.Trigger()
-	_ = event.GenerateEventAudioSting(g.seed, event.EventBossArena)
-	g.audioEngine.PlaySFX("boss_encounter", g.camera.X, g.camera.Y)
-	eventText := event.GenerateEventText(g.seed, event.EventBossArena)
-	g.hud.ShowMessage(eventText)
+// openStatsScreen shows the lifetime statistics and achievements kept in
+// the profile.
+func (g *Game) openStatsScreen() {
+	s := g.profileStats
+	favorite := "none yet"
+	if weapon, kills := favoriteWeapon(s.WeaponKills); weapon != "" {
+		favorite = fmt.Sprintf("%s (%d kills)", weapon, kills)
+	}
+	genres := "none yet"
+	if len(s.Genres) > 0 {
+		genres = strings.Join(s.Genres, ", ")
+	}
+	g.statsScreen = &ui.StatsScreenState{Lines: []string{
+		fmt.Sprintf("Kills: %d", s.Kills),
+		fmt.Sprintf("Deaths: %d", s.Deaths),
+		"Favorite weapon: " + favorite,
+		fmt.Sprintf("Secrets found: %d", s.SecretsFound),
+		fmt.Sprintf("Levels completed: %d", s.LevelsCompleted),
+		fmt.Sprintf("Deepest level: %d", s.DeepestLevel),
+		"Campaigns completed: " + genres,
+	}}
+
+	all := g.achievements.GetAll()
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	for _, a := range all {
+		unlocked := g.achievements.IsUnlocked(a.ID)
+		if a.Hidden && !unlocked {
+			continue
+		}
+		g.statsScreen.Achievements = append(g.statsScreen.Achievements, ui.AchievementInfo{Name: a.Name, Unlocked: unlocked})
+	}
+	g.state = StateStats
 }
 
-// updateQuestObjectives updates quest progress and speedrun timers.
-func (g *Game) updateQuestObjectives() {
-	if g.questTracker == nil {
-		return
+// updateStatsScreen goes back to the main menu from the stats screen.
+func (g *Game) updateStatsScreen() error {
+	if g.input.IsJustPressed(input.ActionPause) || g.input.IsJustPressed(input.ActionFire) {
+		g.statsScreen = nil
+		g.state = StateMenu
 	}
+	return nil
+}
 
-	elapsedTime := time.Since(g.levelStartTime).Seconds()
-	for i := range g.questTracker.Objectives {
-		obj := &g.questTracker.Objectives[i]
-		if obj.ID == "bonus_speed" && !obj.Complete {
-			if elapsedTime > float64(obj.Count) {
-				obj.Complete = false
-			}
+// applyQuestReward pays out a claimed quest reward.
+func (g *Game) applyQuestReward(r quest.Reward) {
+	if r.XP > 0 {
+		g.grantXP(r.XP)
+	}
+	if r.Credits > 0 && g.shopCredits != nil {
+		g.shopCredits.Add(r.Credits)
+	}
+	for _, it := range r.Items {
+		g.playerInventory.Add(inventory.Item{ID: it.ID, Name: it.Name, Qty: it.Qty})
+	}
+	if r.SkillPoints > 0 && g.skillManager != nil {
+		g.skillManager.AddPoints(r.SkillPoints)
+	}
+	for _, id := range r.Codex {
+		if _, ok := g.loreCodex.GetEntry(id); !ok {
+			g.loreCodex.AddEntry(g.loreGenerator.Generate(id))
+		}
+		g.loreCodex.MarkFound(id)
+	}
+}
+
+// rewardItemName turns a reward item ID such as "health_potion" into its
+// display name, "Health Potion".
+func rewardItemName(id string) string {
+	words := strings.Split(id, "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
 		}
 	}
+	return strings.Join(words, " ")
 }
 
 // grantQuestReward generates and displays a quest reward for completing an objective.
@@ -3355,6 +6245,13 @@ func (g *Game) grantQuestReward(objectiveID, objectiveType string, isMain bool,
 		g.seed+uint64(len(objectiveID))*1000,
 	)
 
+	// The rolled loot joins the objective's reward, claimed at level end
+	g.questTracker.AddReward(objectiveID, quest.Reward{Items: []quest.ItemReward{{
+		ID:   reward.ItemID,
+		Name: rewardItemName(reward.ItemID),
+		Qty:  reward.Quantity,
+	}}})
+
 	// Display reward notification
 	msg := reward.GetRewardDescription()
 	if g.hud != nil {
@@ -3371,9 +6268,13 @@ func (g *Game) grantQuestReward(objectiveID, objectiveType string, isMain bool,
 func (g *Game) updateV3Systems() {
 	deltaTime := common.DeltaTime
 
+	g.updateWindField(deltaTime)
 	if g.particleSystem != nil {
 		g.particleSystem.Update(deltaTime)
 	}
+	if g.trailSystem != nil {
+		g.trailSystem.Update(deltaTime)
+	}
 
 	// Update combat decals (fade over time)
 	if g.decalSystem != nil {
@@ -3444,6 +6345,11 @@ func (g *Game) updateV3Systems() {
 		g.muzzleFlashSystem.Update(g.world)
 	}
 
+	// Fade out muzzle flash and explosion lights
+	if g.impulseLights != nil {
+		g.impulseLights.Update(common.DeltaTime)
+	}
+
 	// Update hit marker system for damage confirmation feedback
 	if g.hitMarkerSystem != nil {
 		g.hitMarkerSystem.Update(g.world)
@@ -3472,6 +6378,21 @@ func (g *Game) updateV3Systems() {
 	}
 }
 
+// hazardDamageType returns the damage type of a hazard from the status
+// effect it inflicts.
+func hazardDamageType(statusEffect string) combat.DamageType {
+	switch statusEffect {
+	case "burning":
+		return combat.DamageFire
+	case "poisoned", "corroded":
+		return combat.DamageToxic
+	case "stunned", "slowed":
+		return combat.DamageEnergy
+	default:
+		return combat.DamagePhysical
+	}
+}
+
 // checkHazardCollisions tests player collision with environmental hazards.
 func (g *Game) checkHazardCollisions() {
 	if g.hazardECSSystem == nil {
@@ -3484,22 +6405,14 @@ func (g *Game) checkHazardCollisions() {
 	}
 
 	// Apply damage
-	healthDamage := damage
-	if g.hud.Armor > 0 {
-		armorDamage := damage / 2
-		g.hud.Armor -= armorDamage
-		if g.hud.Armor < 0 {
-			healthDamage = -g.hud.Armor
-			g.hud.Armor = 0
-		} else {
-			healthDamage = damage / 2
-		}
-	}
-
-	g.hud.Health -= healthDamage
+	dmgType := hazardDamageType(statusEffect)
+	healthDamage := g.hurtPlayer(float64(damage), dmgType, "hazard").HealthDamage
 	if g.hud.Health < 0 {
 		g.hud.Health = 0
 	}
+	if g.statusReg != nil {
+		g.statusReg.Strike(g.world, g.playerEntity, string(dmgType))
+	}
 
 	// Apply status effect if present
 	if statusEffect != "" && g.statusReg != nil {
@@ -3509,7 +6422,7 @@ func (g *Game) checkHazardCollisions() {
 
 	// Screen shake and flash on hazard hit
 	if g.feedbackSystem != nil {
-		shakeIntensity := float64(healthDamage) / 8.0
+		shakeIntensity := healthDamage / 8.0
 		if shakeIntensity > 4.0 {
 			shakeIntensity = 4.0
 		}
@@ -3527,6 +6440,16 @@ func (g *Game) updateLightingAndAudio() {
 		flashlight := lighting.NewConeLight(g.camera.X, g.camera.Y, g.camera.DirX, g.camera.DirY, preset)
 		g.lightMap.Clear()
 		g.lightMap.AddLight(flashlight.GetContributionAsPointLight())
+		// Torches and steady room lights are baked at level load; only
+		// lights that change are added per frame, on top of that layer.
+		g.frameLights = g.collectFrameLights(g.frameLights[:0])
+		for _, light := range g.frameLights {
+			g.lightMap.AddLight(light)
+		}
+		if g.lightingSystem != nil {
+			g.lightMap.SetStaticScale(g.lightingSystem.OverrideMultiplier())
+		}
+		g.updateDayCycle()
 		g.lightMap.Calculate()
 	}
 
@@ -3535,9 +6458,25 @@ func (g *Game) updateLightingAndAudio() {
 	}
 }
 
+// updateDayCycle advances the time of day and applies its ambient level and
+// tint to the light map and its intensity to the weather emitter.
+func (g *Game) updateDayCycle() {
+	if g.dayCycle == nil {
+		return
+	}
+	g.dayCycle.Update(common.DeltaTime)
+	if g.lightMap != nil {
+		g.lightMap.SetAmbient(g.dayCycle.Ambient())
+		g.lightMap.SetAmbientTint(g.dayCycle.Tint())
+	}
+	if g.weatherEmitter != nil {
+		g.weatherEmitter.SetRate(g.dayCycle.WeatherScale())
+	}
+}
+
 // processPlayerMovement calculates player movement delta based on input.
 func (g *Game) processPlayerMovement() (float64, float64, float64) {
-	moveSpeed := 0.05
+	moveSpeed := 0.05 * g.encumbrance.SpeedMultiplier()
 	rotSpeed := 0.03
 	deltaX := 0.0
 	deltaY := 0.0
@@ -3563,15 +6502,84 @@ func (g *Game) processPlayerMovement() (float64, float64, float64) {
 
 	g.processGamepadMovement(&deltaX, &deltaY, moveSpeed)
 	g.processCameraRotation(rotSpeed, &deltaPitch)
+	g.applyRecoil(&deltaPitch)
 
 	// Update weapon sway movement state based on whether player is moving
 	isMoving := deltaX != 0 || deltaY != 0
 	isSprinting := false // Sprint not implemented yet, but ready for future
 	g.updateWeaponSwayMovementState(isMoving, isSprinting)
+	g.updateWeaponHandling(isMoving)
 
 	return deltaX, deltaY, deltaPitch
 }
 
+// updateEncumbrance weighs the player's inventory against their class's
+// carry capacity, raised by stamina, and announces when the load starts or
+// stops slowing them. With encumbrance off in the config the player
+// carries anything at full speed.
+func (g *Game) updateEncumbrance() {
+	if !config.C.Encumbrance || g.playerInventory == nil {
+		g.encumbrance = inventory.Unburdened
+		g.hud.Load, g.hud.Capacity = 0, 0
+		return
+	}
+	capacity := class.GetClass(g.playerClass).Carry
+	if g.skillManager != nil {
+		capacity *= 1 + g.skillManager.GetModifier("stamina")
+	}
+	load := g.playerInventory.Weight() + g.playerArmor.Weight()
+	g.hud.Load, g.hud.Capacity = load, capacity
+
+	e := inventory.Encumber(load, capacity)
+	if e == g.encumbrance {
+		return
+	}
+	if e == inventory.Unburdened {
+		g.hud.ShowMessage("No longer encumbered")
+	} else {
+		g.hud.ShowMessage(fmt.Sprintf("%s: moving at %.0f%% speed", e, e.SpeedMultiplier()*100))
+	}
+	g.encumbrance = e
+}
+
+// Weapon handling multipliers on recoil and spread bloom by stance.
+const (
+	movingHandling = 1.5
+	aimingHandling = 0.5
+)
+
+// applyRecoil turns the view by the recoil of the shots fired this tick,
+// adding its climb to the pitch change.
+func (g *Game) applyRecoil(deltaPitch *float64) {
+	yaw, pitch := g.arsenal.TakeKick()
+	if yaw != 0 {
+		g.camera.Rotate(yaw * math.Pi / 180)
+	}
+	*deltaPitch += pitch
+}
+
+// updateWeaponHandling sets how steadily the player holds the current
+// weapon: moving loosens it, while aiming and accuracy from skills and
+// weapon mastery steady it.
+func (g *Game) updateWeaponHandling(isMoving bool) {
+	handling := 1.0
+	if isMoving {
+		handling *= movingHandling
+	}
+	if g.arsenal.AltHeld() {
+		handling *= aimingHandling
+	}
+	if g.skillManager != nil {
+		handling /= 1 + g.skillManager.GetModifier("accuracy")
+	}
+	if g.masteryManager != nil {
+		if accuracy := g.masteryManager.GetBonus(g.arsenal.CurrentSlot).Accuracy; accuracy > 0 {
+			handling /= accuracy
+		}
+	}
+	g.arsenal.Handling = handling
+}
+
 // processGamepadMovement processes gamepad stick input for player movement.
 func (g *Game) processGamepadMovement(deltaX, deltaY *float64, moveSpeed float64) {
 	leftX, leftY := g.input.GamepadLeftStick()
@@ -3645,6 +6653,7 @@ func (g *Game) handleCollisionAndMovement(deltaX, deltaY, deltaPitch float64) {
 	} else {
 		g.camera.Update(0, 0, 0, 0, deltaPitch)
 	}
+	g.traversePortal()
 
 	if g.automap != nil {
 		g.automap.Reveal(int(g.camera.X), int(g.camera.Y))
@@ -3681,13 +6690,59 @@ func isWalkableTile(tile int) bool {
 		return true
 	case tile == bsp.TileDoor:
 		return false // Doors block movement until opened via interaction
-	case tile >= 20 && tile <= 29: // Genre-specific floor tiles (TileFloorStone..TileFloorDirt)
+	case tile >= 20 && tile <= 29: // Genre-specific floor tiles and detail variants
 		return true
 	default:
 		return false
 	}
 }
 
+// traversePortal moves the player out of the partner of a linked portal
+// they have stepped into. The offset across the portal is kept, and along the
+// travel axis the player lands in the middle of the partner tile so their
+// bounds clear the wall behind it. The arrival tile does not trigger again
+// until the player leaves it.
+func (g *Game) traversePortal() {
+	if g.raycaster == nil || g.currentMap == nil || len(g.currentMap) == 0 {
+		return
+	}
+	tileX, tileY := int(g.camera.X), int(g.camera.Y)
+	if g.inPortal && g.portalArrival == [2]int{tileX, tileY} {
+		return
+	}
+	g.inPortal = false
+
+	exitX, exitY, ok := g.raycaster.PortalExit(tileX, tileY)
+	if !ok {
+		return
+	}
+
+	x := g.camera.X + float64(exitX-tileX)
+	y := g.camera.Y + float64(exitY-tileY)
+	if g.isWalkableTileAt(exitX, exitY-1) || g.isWalkableTileAt(exitX, exitY+1) {
+		y = float64(exitY) + 0.5
+	} else {
+		x = float64(exitX) + 0.5
+	}
+	g.camera.X, g.camera.Y = x, y
+	g.portalArrival = [2]int{exitX, exitY}
+	g.inPortal = true
+}
+
+// isWalkableTileAt reports whether the tile at (x, y) permits movement.
+// Linked portals are walkable; unlinked ones block like walls.
+func (g *Game) isWalkableTileAt(x, y int) bool {
+	if y < 0 || y >= len(g.currentMap) || x < 0 || x >= len(g.currentMap[y]) {
+		return false
+	}
+	tile := g.currentMap[y][x]
+	if raycaster.IsPortalTile(tile) && g.raycaster != nil {
+		_, _, ok := g.raycaster.PortalExit(x, y)
+		return ok
+	}
+	return isWalkableTile(tile)
+}
+
 // playerRadius is the collision bounding radius around the player position.
 const playerRadius = 0.25
 
@@ -3707,12 +6762,7 @@ func (g *Game) isWalkable(x, y float64) bool {
 	for _, off := range offsets {
 		cx := x + off[0]
 		cy := y + off[1]
-		mapX := int(cx)
-		mapY := int(cy)
-		if mapY < 0 || mapY >= len(g.currentMap) || mapX < 0 || mapX >= len(g.currentMap[0]) {
-			return false
-		}
-		if !isWalkableTile(g.currentMap[mapY][mapX]) {
+		if !g.isWalkableTileAt(int(cx), int(cy)) {
 			return false
 		}
 	}
@@ -3757,14 +6807,26 @@ func (g *Game) getInteractionTileCoords() (int, int, bool) {
 	return mapX, mapY, true
 }
 
+// setTile changes a map tile and sends the change to everything built from
+// the map: the renderer, light occluders and enemy navigation, whose cached
+// routes are dropped so enemies replan around it.
+func (g *Game) setTile(x, y, tile int) {
+	g.currentMap[y][x] = tile
+	g.raycaster.SetMap(g.currentMap)
+	g.updateLightOccluders()
+	if g.navGrid != nil {
+		g.navGrid.Apply(ai.TileChange{X: x, Y: y, Tile: tile})
+	}
+}
+
 // handleSecretWall triggers a secret wall and updates quest progress.
 func (g *Game) handleSecretWall(mapX, mapY int) {
 	if g.secretManager != nil && g.secretManager.TriggerAt(mapX, mapY, "player") {
 		// Convert the secret wall tile to floor so the player can walk through
-		g.currentMap[mapY][mapX] = bsp.TileFloor
-		g.raycaster.SetMap(g.currentMap)
+		g.setTile(mapX, mapY, bsp.TileFloor)
 		g.audioEngine.PlaySFX("secret_open", float64(mapX), float64(mapY))
 		g.hud.ShowMessage("Secret discovered!")
+		g.profileStats.SecretsFound++
 		if g.questTracker != nil {
 			// Check if we just completed the secret objective
 			oldProgress := int64(0)
@@ -3792,8 +6854,7 @@ func (g *Game) handleSecretWall(mapX, mapY int) {
 func (g *Game) handleDoorInteraction(mapX, mapY int) {
 	requiredColor := g.getDoorColor(mapX, mapY)
 	if requiredColor == "" || g.keycards[requiredColor] {
-		g.currentMap[mapY][mapX] = bsp.TileFloor
-		g.raycaster.SetMap(g.currentMap)
+		g.setTile(mapX, mapY, bsp.TileFloor)
 		g.audioEngine.PlaySFX("door_open", float64(mapX), float64(mapY))
 	} else {
 		g.startMinigame(mapX, mapY)
@@ -3820,16 +6881,27 @@ func (g *Game) startMinigame(doorX, doorY int) {
 	g.state = StateMinigame
 	g.minigameInputTimer = 0
 
-	// Determine minigame type for rendering
-	switch g.genreID {
-	case "fantasy":
-		g.minigameType = "lockpick"
-	case "cyberpunk":
-		g.minigameType = "circuit"
-	case "scifi", "postapoc":
-		g.minigameType = "code"
+	// Determine minigame type for input and rendering
+	g.minigameType = minigameTypeOf(g.activeMinigame)
+}
+
+// minigameTypeOf returns the input and rendering type of a minigame.
+func minigameTypeOf(game minigame.MiniGame) string {
+	switch game.(type) {
+	case *minigame.LockpickGame:
+		return "lockpick"
+	case *minigame.CircuitTraceGame:
+		return "circuit"
+	case *minigame.BypassCodeGame:
+		return "code"
+	case *minigame.WireMatchGame:
+		return "wire"
+	case *minigame.FrequencyTuneGame:
+		return "frequency"
+	case *minigame.RhythmLockGame:
+		return "rhythm"
 	default:
-		g.minigameType = "hack"
+		return "hack"
 	}
 }
 
@@ -3852,7 +6924,7 @@ func (g *Game) useQuickSlotItem() {
 			medkit := &inventory.Medkit{
 				ID:         "medkit",
 				Name:       "Medkit",
-				HealAmount: 25,
+				HealAmount: medkitHeal,
 			}
 			g.playerInventory.SetQuickSlot(medkit)
 			activeItem = medkit
@@ -3862,6 +6934,13 @@ func (g *Game) useQuickSlotItem() {
 		}
 	}
 
+	// Medkits heal by the potency of the one about to be used
+	if m, ok := activeItem.(*inventory.Medkit); ok {
+		if it := g.playerInventory.Get(m.ID); it != nil {
+			m.HealAmount = medkitHeal * it.Potency()
+		}
+	}
+
 	// Create entity wrapper for player
 	playerEntity := &inventory.Entity{
 		Health:    float64(g.hud.Health),
@@ -3884,6 +6963,9 @@ func (g *Game) useQuickSlotItem() {
 	g.hud.ShowMessage("Used " + activeItem.GetName())
 }
 
+// medkitHeal is the health a plain medkit restores.
+const medkitHeal = 25
+
 // tryCollectLore checks if player is near a lore item and collects it.
 func (g *Game) tryCollectLore() {
 	collectDist := 2.0
@@ -3897,6 +6979,7 @@ func (g *Game) tryCollectLore() {
 		if dist < collectDist*collectDist {
 			loreItem.Activated = true
 			g.loreCodex.MarkFound(loreItem.CodexID)
+			g.recordMetaQuest("lore:"+g.genreID, loreItem.CodexID)
 			typeName := lore.GetLoreItemTypeName(loreItem.Type, g.genreID)
 			g.hud.ShowMessage("Found: " + typeName)
 			g.audioEngine.PlaySFX("lore_pickup", g.camera.X, g.camera.Y)
@@ -3925,69 +7008,401 @@ func (g *Game) getLoreContext(room bsp.Room) lore.ContextType {
 	if room.X > 40 || room.Y > 40 {
 		return lore.ContextEscape
 	}
-	return lore.ContextGeneral
-}
-
-// updatePaused handles pause menu updates.
-func (g *Game) updatePaused() error {
-	if g.input.IsJustPressed(input.ActionPause) {
-		g.state = StatePlaying
-		g.menuManager.Hide()
-		return nil
+	return lore.ContextGeneral
+}
+
+// updatePaused handles pause menu updates.
+func (g *Game) updatePaused() error {
+	if g.input.IsJustPressed(input.ActionPause) {
+		g.state = StatePlaying
+		g.menuManager.Hide()
+		return nil
+	}
+
+	if g.input.IsJustPressed(input.ActionMoveForward) {
+		g.menuManager.MoveUp()
+	}
+	if g.input.IsJustPressed(input.ActionMoveBackward) {
+		g.menuManager.MoveDown()
+	}
+	if g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract) {
+		action := g.menuManager.Select()
+		g.handlePauseAction(action)
+	}
+
+	return nil
+}
+
+// handlePauseAction processes pause menu selections.
+func (g *Game) handlePauseAction(action string) {
+	switch action {
+	case "resume":
+		g.state = StatePlaying
+		g.menuManager.Hide()
+	case "shop":
+		g.openShop()
+	case "skills":
+		g.openSkills()
+	case "multiplayer":
+		g.openMultiplayer()
+	case "save":
+		g.openSaveBrowser(true)
+	case "load":
+		g.openSaveBrowser(false)
+	case "settings":
+		g.menuManager.Show(ui.MenuTypeSettings)
+	case "quit_to_menu":
+		// Save replay before returning to menu
+		g.saveReplay(save.AutoSaveSlot)
+		g.state = StateMenu
+		g.menuManager.Show(ui.MenuTypeMain)
+	}
+}
+
+// openShop transitions to the shop state.
+func (g *Game) openShop() {
+	if !g.inShopRoom() {
+		g.hud.ShowMessage("Find the merchant to trade")
+		return
+	}
+	if g.shopArmory == nil {
+		g.shopArmory = shop.NewArmory(g.genreID)
+		g.shopInventory = &g.shopArmory.Inventory
+	}
+	if g.shopCredits == nil {
+		g.shopCredits = shop.NewCredit(0)
+	}
+	g.stockRolledWeapons()
+	g.stockArmor()
+	g.menuManager.Show(ui.MenuTypeShop)
+	g.state = StateShop
+}
+
+// shopRolls is how many procedurally rolled weapons the merchant offers on
+// each level.
+const shopRolls = 3
+
+// rollSeed derives the seed of a rolled weapon from the run seed, so the
+// same run offers the same weapons.
+func (g *Game) rollSeed(salt uint64) uint64 {
+	return g.seed ^ uint64(g.levelDepth)<<48 ^ (salt+1)*0x9E3779B97F4A7C15
+}
+
+// stockRolledWeapons puts the current level's rolled weapons on sale, once
+// per level; a weapon bought or left behind stays so until the next one.
+func (g *Game) stockRolledWeapons() {
+	items := make([]shop.Item, 0, shopRolls)
+	for i := range shopRolls {
+		seed := g.rollSeed(uint64(i))
+		_, w := weapon.Roll(seed, g.genreID)
+		items = append(items, shop.Item{
+			ID:    fmt.Sprintf("weapon_roll_%x", seed),
+			Name:  fmt.Sprintf("%s (%s)", w.Name, w.Rarity.Label()),
+			Type:  shop.ItemTypeWeapon,
+			Price: w.Rarity.Value(),
+			Stock: 1,
+			Seed:  seed,
+		})
+	}
+	if g.shopArmory.Inventory.FindItem(items[0].ID) != nil {
+		return
+	}
+	g.shopArmory.StockRolled(items)
+}
+
+// equipRolledWeapon rolls the weapon from seed and readies it in its slot,
+// replacing the weapon there.
+func (g *Game) equipRolledWeapon(seed uint64) weapon.Weapon {
+	slot, w := weapon.Roll(seed, g.genreID)
+	g.arsenal.Equip(slot, w)
+	return w
+}
+
+// Chance an enemy drops a rolled weapon, and the share of a weapon's value
+// it salvages for when no better than the one carried.
+const (
+	weaponDropChance  = 0.06
+	weaponSalvageRate = 0.25
+)
+
+// dropRolledWeapon may drop a rolled weapon where a slain enemy fell.
+func (g *Game) dropRolledWeapon(agent *ai.Agent) {
+	if g.rng.Float64() >= weaponDropChance {
+		return
+	}
+	seed := g.rollSeed(uint64(shopRolls + g.rng.Intn(1<<30)))
+	_, w := weapon.Roll(seed, g.genreID)
+	g.dropPickup(loot.Pickup{Kind: loot.PickupWeapon, Item: "weapon", Name: w.Name, Seed: seed, Rarity: glowRarity(int(w.Rarity))}, agent.X, agent.Y)
+	logrus.WithFields(logrus.Fields{
+		"system_name": "loot",
+		"agent":       agent.ID,
+		"weapon":      w.Name,
+		"rarity":      w.Rarity.Label(),
+	}).Debug("Weapon dropped")
+}
+
+// collectRolledWeapon takes up the weapon rolled from seed. A weapon of a
+// higher rarity than the one in its slot is equipped; the rest are
+// salvaged for credits. It returns the message to show.
+func (g *Game) collectRolledWeapon(seed uint64) string {
+	slot, w := weapon.Roll(seed, g.genreID)
+	name := fmt.Sprintf("%s (%s)", w.Name, w.Rarity.Label())
+	if w.Rarity > g.arsenal.Weapons[slot].Rarity {
+		g.equipRolledWeapon(seed)
+		return "Found: " + name
+	}
+	credits := int(float64(w.Rarity.Value()) * weaponSalvageRate)
+	if g.shopCredits != nil {
+		g.shopCredits.Add(credits)
+	}
+	return fmt.Sprintf("Salvaged %s for %d credits", name, credits)
+}
+
+// checkLevelExit descends to the next level once the player walks into the
+// exit chamber.
+func (g *Game) checkLevelExit() {
+	r := g.exitRoom
+	if r == nil || g.camera.X < float64(r.X) || g.camera.X >= float64(r.X+r.W) ||
+		g.camera.Y < float64(r.Y) || g.camera.Y >= float64(r.Y+r.H) {
+		return
+	}
+	g.endLevel()
+}
+
+// endLevel shows the combat summary of the level just cleared and, when a
+// combat log directory is configured, exports the level's combat log there.
+func (g *Game) endLevel() {
+	summary := g.combatLog.Summary()
+	lines := append(g.claimQuestRewards(), g.metaQuestLines()...)
+	lines = append(lines, g.recordLevelStats(summary)...)
+	lines = append(lines, summary.Lines()...)
+	g.saveProfile()
+	g.levelSummary = &ui.LevelSummaryState{Depth: g.levelDepth, Lines: lines}
+	g.state = StateSummary
+	logrus.WithFields(logrus.Fields{
+		"system_name": "combatlog",
+		"depth":       g.levelDepth,
+		"kills":       summary.Kills,
+		"accuracy":    summary.Accuracy(),
+	}).Info("Level cleared")
+
+	if config.C.CombatLogDir == "" {
+		return
+	}
+	name := fmt.Sprintf("%s-level%d.json", time.Now().Format("20060102-150405"), g.levelDepth)
+	path := filepath.Join(config.C.CombatLogDir, name)
+	if err := g.combatLog.Export(path); err != nil {
+		logrus.WithError(err).Error("Failed to export combat log")
+		return
+	}
+	logrus.WithField("path", path).Info("Combat log exported")
+}
+
+// updateLevelSummary waits on the summary screen for the player to move on
+// to the next level.
+func (g *Game) updateLevelSummary() error {
+	if g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract) {
+		g.levelSummary = nil
+		g.descend()
+	}
+	return nil
+}
+
+// descend generates and enters the next, deeper level. The player keeps
+// their health, inventory, ammo and progression; keycards belong to the
+// level left behind.
+func (g *Game) descend() {
+	g.levelDepth++
+	g.state = StateLoading
+	g.loadingScreen.Show(g.seed, fmt.Sprintf("Descending to level %d...", g.levelDepth))
+
+	g.generateLevel()
+	g.populateLevel()
+	g.levelCache = newLevelCache()
+	g.pickups.Clear(g.world)
+	if g.campaign != nil {
+		g.reportQuests(g.campaign.Evaluate(g.levelDepth))
+	}
+
+	spawnX, spawnY := g.findSpawnPosition(bsp.GetRooms(g.currentBSPTree))
+	g.camera.X, g.camera.Y = spawnX, spawnY
+	g.camera.DirX, g.camera.DirY = 1.0, 0.0
+	g.camera.Pitch = 0.0
+	g.keycards = make(map[string]bool)
+	g.automapVisible = false
+
+	g.levelStartTime = time.Now()
+	g.loadingScreen.Hide()
+	g.state = StatePlaying
+	g.hud.ShowMessage(fmt.Sprintf("Level %d", g.levelDepth))
+	logrus.WithFields(logrus.Fields{
+		"system_name": "level",
+		"depth":       g.levelDepth,
+	}).Info("Descended to next level")
+}
+
+// Storage sizes, in stacks: the stash kept at the merchant between levels
+// and runs, and the cache the player can stow gear in anywhere on a level.
+const (
+	stashCapacity = 24
+	cacheCapacity = 8
+)
+
+// Item ID prefixes for what containers hold besides inventory items: scrap
+// by type, and rolled weapons by genre with the weapon's seed.
+const (
+	scrapItemPrefix  = "scrap_"
+	weaponItemPrefix = "weapon_roll_"
+)
+
+// newLevelCache creates the empty cache of a new level.
+func newLevelCache() *inventory.Container {
+	return inventory.NewContainer("cache", "Cache", cacheCapacity, false)
+}
+
+// loadStash reads the persistent stash, or starts an empty one.
+func loadStash() *inventory.Container {
+	state, err := save.LoadStash()
+	if err != nil {
+		if !errors.Is(err, save.ErrNoStash) {
+			logrus.WithError(err).Warn("Failed to load stash, starting an empty one")
+		}
+		return inventory.NewContainer("stash", "Stash", stashCapacity, true)
+	}
+	return restoreContainer(state, true)
+}
+
+// containerState converts a container for saving.
+func containerState(c *inventory.Container) *save.Container {
+	if c == nil {
+		return nil
+	}
+	items := c.Contents()
+	state := &save.Container{ID: c.ID, Name: c.Name, Capacity: c.Capacity, Items: make([]save.Item, len(items))}
+	for i, it := range items {
+		state.Items[i] = save.Item{ID: it.ID, Name: it.Name, Qty: it.Qty, Seed: it.Seed}
+	}
+	return state
+}
+
+// restoreContainer rebuilds a saved container.
+func restoreContainer(state *save.Container, persistent bool) *inventory.Container {
+	c := inventory.NewContainer(state.ID, state.Name, state.Capacity, persistent)
+	for _, it := range state.Items {
+		c.Items = append(c.Items, inventory.Item{ID: it.ID, Name: it.Name, Qty: it.Qty, Seed: it.Seed})
+	}
+	return c
+}
+
+// storageHere returns the container the player can reach: the stash in
+// the merchant's room, or the level's cache anywhere else.
+func (g *Game) storageHere() *inventory.Container {
+	if g.inShopRoom() {
+		return g.stash
+	}
+	return g.levelCache
+}
+
+// handleStash banks the player's scrap and current rolled weapon in the
+// container at hand, or takes everything in it back out.
+func (g *Game) handleStash() {
+	switch {
+	case g.input.IsJustPressed(input.ActionStash):
+		g.depositGear(g.storageHere())
+	case g.input.IsJustPressed(input.ActionUnstash):
+		g.withdrawGear(g.storageHere())
+	default:
+		return
+	}
+	if g.stash != nil {
+		if err := save.SaveStash(containerState(g.stash)); err != nil {
+			logrus.WithError(err).Warn("Failed to save stash")
+		}
 	}
+}
 
-	if g.input.IsJustPressed(input.ActionMoveForward) {
-		g.menuManager.MoveUp()
+// depositGear moves all scrap and the current weapon, if it is a rolled
+// one, into the container. The weapon's slot goes back to its stock
+// weapon.
+func (g *Game) depositGear(c *inventory.Container) {
+	if c == nil {
+		return
 	}
-	if g.input.IsJustPressed(input.ActionMoveBackward) {
-		g.menuManager.MoveDown()
+	stored := 0
+	if g.scrapStorage != nil {
+		for scrap, amount := range g.scrapStorage.GetAll() {
+			if c.Deposit(inventory.Item{ID: scrapItemPrefix + scrap, Name: scrap, Qty: amount}) == nil {
+				g.scrapStorage.Remove(scrap, amount)
+				stored++
+			}
+		}
 	}
-	if g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract) {
-		action := g.menuManager.Select()
-		g.handlePauseAction(action)
+	slot := g.arsenal.CurrentSlot
+	if w := g.arsenal.Weapons[slot]; w.Seed != 0 {
+		item := inventory.Item{ID: weaponItemPrefix + g.genreID, Name: w.Name, Qty: 1, Seed: w.Seed}
+		if c.Deposit(item) == nil {
+			stock := weapon.NewArsenal()
+			stock.SetGenre(g.genreID)
+			g.arsenal.Equip(slot, stock.Weapons[slot])
+			g.updateHUDAmmo()
+			stored++
+		}
 	}
 
-	return nil
+	switch {
+	case stored > 0:
+		g.hud.ShowMessage(fmt.Sprintf("Stored gear in the %s", strings.ToLower(c.Name)))
+	case c.Free() == 0:
+		g.hud.ShowMessage(fmt.Sprintf("The %s is full", strings.ToLower(c.Name)))
+	default:
+		g.hud.ShowMessage("Nothing to store")
+	}
 }
 
-// handlePauseAction processes pause menu selections.
-func (g *Game) handlePauseAction(action string) {
-	switch action {
-	case "resume":
-		g.state = StatePlaying
-		g.menuManager.Hide()
-	case "shop":
-		g.openShop()
-	case "skills":
-		g.openSkills()
-	case "multiplayer":
-		g.openMultiplayer()
-	case "save":
-		// Save to slot 1
-		g.saveGame(1)
-	case "load":
-		g.loadGame(1)
-	case "settings":
-		g.menuManager.Show(ui.MenuTypeSettings)
-	case "quit_to_menu":
-		// Save replay before returning to menu
-		g.saveReplay(save.AutoSaveSlot)
-		g.state = StateMenu
-		g.menuManager.Show(ui.MenuTypeMain)
+// withdrawGear takes everything out of the container: scrap back into
+// storage, rolled weapons of the genre into slots holding stock weapons,
+// and items into the inventory. Weapons with no free slot stay stored.
+func (g *Game) withdrawGear(c *inventory.Container) {
+	if c == nil {
+		return
+	}
+	taken := 0
+	for _, it := range c.Contents() {
+		if scrap, ok := strings.CutPrefix(it.ID, scrapItemPrefix); ok {
+			if g.scrapStorage == nil {
+				continue
+			}
+			g.scrapStorage.Add(scrap, it.Qty)
+		} else if genre, ok := strings.CutPrefix(it.ID, weaponItemPrefix); ok {
+			slot, w := weapon.Roll(it.Seed, genre)
+			if genre != g.genreID || g.arsenal.Weapons[slot].Seed != 0 {
+				continue
+			}
+			g.arsenal.Equip(slot, w)
+			it.Qty = 1
+		} else {
+			g.playerInventory.Add(it)
+		}
+		c.Withdraw(it.ID, it.Seed, it.Qty)
+		taken++
 	}
+	g.updateHUDAmmo()
+	if taken == 0 {
+		g.hud.ShowMessage(fmt.Sprintf("Nothing to take from the %s", strings.ToLower(c.Name)))
+		return
+	}
+	g.hud.ShowMessage(fmt.Sprintf("Took gear from the %s", strings.ToLower(c.Name)))
 }
 
-// openShop transitions to the shop state.
-func (g *Game) openShop() {
-	if g.shopArmory == nil {
-		g.shopArmory = shop.NewArmory(g.genreID)
-		g.shopInventory = &g.shopArmory.Inventory
-	}
-	if g.shopCredits == nil {
-		g.shopCredits = shop.NewCredit(0)
+// inShopRoom reports whether the player is in the merchant's room. Levels
+// without a shop room let the player trade anywhere.
+func (g *Game) inShopRoom() bool {
+	r := g.shopRoom
+	if r == nil {
+		return true
 	}
-	g.menuManager.Show(ui.MenuTypeShop)
-	g.state = StateShop
+	return g.camera.X >= float64(r.X) && g.camera.X < float64(r.X+r.W) &&
+		g.camera.Y >= float64(r.Y) && g.camera.Y < float64(r.Y+r.H)
 }
 
 // openCrafting transitions to the crafting state.
@@ -4055,7 +7470,12 @@ func (g *Game) handleShopPurchase() {
 
 	if g.shopArmory.PurchaseWithModifier(item.ID, g.shopCredits, priceModifier) {
 		// Apply purchased item effects
-		g.applyShopItem(item.ID)
+		if item.Seed != 0 {
+			g.equipRolledWeapon(item.Seed)
+			g.updateHUDAmmo()
+		} else {
+			g.applyShopItem(item.ID)
+		}
 		g.hud.ShowMessage("Purchased: " + item.Name)
 		g.audioEngine.PlaySFX("shop_buy", g.camera.X, g.camera.Y)
 	} else {
@@ -4074,6 +7494,11 @@ func (g *Game) applyShopItem(itemID string) {
 		g.applyArmorItem()
 	case "upgrade_damage", "upgrade_firerate", "upgrade_clipsize", "upgrade_accuracy", "upgrade_range":
 		g.applyWeaponUpgrade(itemID)
+	default:
+		if a, ok := inventory.FindArmor(itemID); ok {
+			g.playerInventory.Add(inventory.Item{ID: a.ID, Name: a.Name, Qty: 1})
+			g.autoEquipArmor(a)
+		}
 	}
 	g.updateHUDAmmo()
 }
@@ -4115,40 +7540,139 @@ func (g *Game) applyArmorItem() {
 	}
 }
 
-// applyWeaponUpgrade applies an upgrade to the current weapon.
-func (g *Game) applyWeaponUpgrade(itemID string) {
-	currentWeapon := g.arsenal.GetCurrentWeapon()
-	weaponID := currentWeapon.Name
-
-	upgradeMap := map[string]upgrade.UpgradeType{
-		"upgrade_damage":   upgrade.UpgradeDamage,
-		"upgrade_firerate": upgrade.UpgradeFireRate,
-		"upgrade_clipsize": upgrade.UpgradeClipSize,
-		"upgrade_accuracy": upgrade.UpgradeAccuracy,
-		"upgrade_range":    upgrade.UpgradeRange,
+// stockArmor puts one of each armor piece of the genre on sale, once per
+// shop.
+func (g *Game) stockArmor() {
+	sets := inventory.ArmorSets()
+	if len(sets) == 0 || g.shopArmory.Inventory.FindItem(sets[0].Piece(inventory.SlotHead).ID) != nil {
+		return
+	}
+	var items []shop.Item
+	for _, s := range sets {
+		for slot := range inventory.ArmorSlotCount {
+			a := s.Piece(slot)
+			items = append(items, shop.Item{ID: a.ID, Name: a.Name, Type: shop.ItemTypeArmor, Price: a.Value, Stock: 1})
+		}
 	}
+	g.shopArmory.StockArmor(items)
+}
 
-	upgradeMessages := map[string]string{
-		"upgrade_damage":   "Damage upgrade applied!",
-		"upgrade_firerate": "Fire rate upgrade applied!",
-		"upgrade_clipsize": "Clip size upgrade applied!",
-		"upgrade_accuracy": "Accuracy upgrade applied!",
-		"upgrade_range":    "Range upgrade applied!",
+// autoEquipArmor wears a newly gained armor piece when its slot is empty
+// or holds a broken piece; otherwise it stays in the inventory.
+func (g *Game) autoEquipArmor(a inventory.Armor) {
+	if w := g.playerArmor.Slots[a.Slot]; w != nil && !w.Broken() {
+		return
+	}
+	if _, err := g.playerArmor.Equip(g.playerInventory, a.ID, a.Seed); err != nil {
+		logrus.WithError(err).Warn("Failed to equip armor")
+		return
 	}
+	g.hud.ShowMessage("Equipped: " + a.Name)
+	if s, ok := g.playerArmor.FullSet(); ok {
+		g.hud.ShowMessage(s.Name + " set bonus active")
+	}
+	g.updateArmorResistances()
+}
 
-	if upgradeType, ok := upgradeMap[itemID]; ok {
-		if g.upgradeManager.ApplyUpgrade(weaponID, upgradeType, 2) {
-			if msg, exists := upgradeMessages[itemID]; exists {
-				g.hud.ShowMessage(msg)
-			}
+// playerResistances returns what the player resists: the genre's base
+// protection from the defense component plus the armor worn.
+func (g *Game) playerResistances() combat.Resistances {
+	res := combat.Resistances{}
+	if defense := g.getPlayerDefenseComponent(); defense != nil {
+		maps.Copy(res, defense.Resistances)
+	}
+	if g.playerArmor != nil {
+		for t, v := range g.playerArmor.Resistances() {
+			res[combat.DamageType(t)] += v
 		}
 	}
+	return res
+}
+
+// updateArmorResistances passes the player's resistances on to status
+// effects after the armor worn changes.
+func (g *Game) updateArmorResistances() {
+	if g.playerEntity != 0 {
+		status.SetResistances(g.world, g.playerEntity, statusResistances(g.playerResistances()))
+	}
+}
+
+// wearArmor wears the armor worn down by a hit on the player, announcing
+// any piece it breaks.
+func (g *Game) wearArmor(amount float64) {
+	if g.playerArmor == nil {
+		return
+	}
+	broke := g.playerArmor.Wear(amount)
+	for _, a := range broke {
+		g.hud.ShowMessage(a.Name + " broke!")
+	}
+	if len(broke) > 0 {
+		g.updateArmorResistances()
+	}
+}
+
+// upgradeAttachments are the attachments the shop's weapon upgrades fit.
+var upgradeAttachments = map[string]string{
+	"upgrade_damage":   "barrel_heavy",
+	"upgrade_firerate": "barrel_ported",
+	"upgrade_clipsize": "mag_extended",
+	"upgrade_accuracy": "under_grip",
+	"upgrade_range":    "scope_long",
+}
+
+// upgradeTokenCost is how many upgrade tokens fitting a bought upgrade
+// takes.
+const upgradeTokenCost = 2
+
+// applyWeaponUpgrade fits the attachment a shop upgrade stands for.
+func (g *Game) applyWeaponUpgrade(itemID string) {
+	id, ok := upgradeAttachments[itemID]
+	if !ok {
+		return
+	}
+	if !g.upgradeManager.GetTokens().Spend(upgradeTokenCost) {
+		g.hud.ShowMessage(fmt.Sprintf("Fitting needs %d upgrade tokens", upgradeTokenCost))
+		return
+	}
+	g.fitAttachment(id)
+}
+
+// fitAttachment fits an attachment to the current weapon, or to the
+// sidearm when the current weapon has no mount for it, discarding any
+// part it replaces.
+func (g *Game) fitAttachment(id string) {
+	at, ok := weapon.FindAttachment(id)
+	if !ok {
+		return
+	}
+	slot := g.arsenal.CurrentSlot
+	if !at.Fits(g.arsenal.Weapons[slot]) {
+		slot = 1
+	}
+	old, ok := g.arsenal.Attach(slot, id)
+	if !ok {
+		g.hud.ShowMessage(at.Name + " does not fit")
+		return
+	}
+	msg := fmt.Sprintf("Fitted %s to %s", at.Name, g.arsenal.Weapons[slot].Name)
+	if prev, found := weapon.FindAttachment(old); found && old != id {
+		msg += ", replacing " + prev.Name
+	}
+	g.hud.ShowMessage(msg)
 }
 
-// updateHUDAmmo refreshes the HUD ammo display.
+// updateHUDAmmo refreshes the HUD ammo display: the reserve, the current
+// weapon's magazine and the reload under way.
 func (g *Game) updateHUDAmmo() {
 	currentWeapon := g.arsenal.GetCurrentWeapon()
-	g.hud.Ammo = g.ammoPool.Get(currentWeapon.AmmoType)
+	g.hud.Ammo = g.ammoPool.Get(g.arsenal.ReservePool(g.arsenal.CurrentSlot))
+	g.hud.Clip = g.arsenal.Clips[g.arsenal.CurrentSlot]
+	g.hud.ClipSize = 0
+	if currentWeapon.Type != weapon.TypeMelee {
+		g.hud.ClipSize = g.arsenal.MagazineSize(g.arsenal.CurrentSlot)
+	}
+	g.hud.Reload = g.arsenal.ReloadProgress()
 }
 
 // updateCrafting handles crafting screen input.
@@ -4208,10 +7732,15 @@ func (g *Game) applyCraftedItem(outputID string, qty int) {
 		g.ammoPool.Add(outputID, qty)
 	case "medkit", "potion":
 		g.playerInventory.Add(inventory.Item{ID: outputID, Name: "Medkit", Qty: qty})
+	default:
+		if id, ok := strings.CutPrefix(outputID, crafting.AttachmentPrefix); ok {
+			g.fitAttachment(id)
+		} else if _, _, ok := ammo.ParsePoolKey(outputID); ok {
+			g.ammoPool.Add(outputID, qty)
+		}
 	}
 	// Update HUD ammo display
-	currentWeapon := g.arsenal.GetCurrentWeapon()
-	g.hud.Ammo = g.ammoPool.Get(currentWeapon.AmmoType)
+	g.hud.Ammo = g.ammoPool.Get(g.arsenal.ReservePool(g.arsenal.CurrentSlot))
 }
 
 // getUpgradedWeaponDamage returns the weapon damage with all upgrades applied.
@@ -4227,12 +7756,6 @@ func (g *Game) getUpgradedWeaponDamage(baseWeapon weapon.Weapon) float64 {
 		}
 	}
 
-	// Apply mastery bonuses
-	if g.masteryManager != nil {
-		bonuses := g.masteryManager.GetBonus(g.arsenal.CurrentSlot)
-		damage *= bonuses.HeadshotDamage // Applies headshot damage bonus to all damage
-	}
-
 	return damage
 }
 
@@ -4531,8 +8054,12 @@ func (g *Game) openMultiplayer() {
 	g.mpSelectedMode = 0
 	g.mpStatusMsg = ""
 	g.useFederation = false
-	g.serverBrowser = make([]*federation.ServerAnnouncement, 0)
+	g.serverBrowser = make([]network.ServerListing, 0)
 	g.browserIdx = 0
+	g.mpAddrActive = false
+	filter := g.serverList.Filter()
+	filter.Genre = g.genreID
+	g.serverList.SetFilter(filter)
 	g.menuManager.Show(ui.MenuTypeMultiplayer)
 	g.state = StateMultiplayer
 
@@ -4542,6 +8069,13 @@ func (g *Game) openMultiplayer() {
 
 // updateMultiplayer handles multiplayer lobby input.
 func (g *Game) updateMultiplayer() error {
+	g.pollServerConnect()
+
+	if g.mpAddrActive {
+		g.handleDirectConnectInput()
+		return nil
+	}
+
 	g.handleChatInput()
 
 	if g.chatInputActive {
@@ -4553,9 +8087,17 @@ func (g *Game) updateMultiplayer() error {
 	}
 
 	g.handleMultiplayerModeToggle()
+	if g.useFederation {
+		g.drainServerFeed()
+		g.syncServerBrowser()
+		g.handleServerBrowserInput()
+	}
 	g.handleMultiplayerServerNavigation()
 	g.handleMultiplayerRefresh()
 	g.handleMultiplayerAction()
+	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
+		g.openReplay()
+	}
 
 	return nil
 }
@@ -4565,6 +8107,7 @@ func (g *Game) handleMultiplayerNavigation() bool {
 	if g.input.IsJustPressed(input.ActionPause) || g.input.IsJustPressed(input.ActionMultiplayer) {
 		g.state = StatePlaying
 		g.menuManager.Hide()
+		g.closeServerFeed()
 		return true
 	}
 	return false
@@ -4576,6 +8119,8 @@ func (g *Game) handleMultiplayerModeToggle() {
 		g.useFederation = !g.useFederation
 		if g.useFederation {
 			g.refreshServerBrowser()
+		} else {
+			g.closeServerFeed()
 		}
 	}
 }
@@ -4621,14 +8166,15 @@ func (g *Game) handleNavigationDown() {
 
 // handleMultiplayerRefresh refreshes the federation server browser.
 func (g *Game) handleMultiplayerRefresh() {
-	if g.useFederation && g.input.IsJustPressed(input.ActionCraft) {
+	if g.useFederation && (g.input.IsJustPressed(input.ActionCraft) || inpututil.IsKeyJustPressed(ebiten.KeyR)) {
 		g.refreshServerBrowser()
 	}
 }
 
 // handleMultiplayerAction handles mode selection or server join action.
 func (g *Game) handleMultiplayerAction() {
-	if g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract) {
+	if g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract) ||
+		(g.useFederation && inpututil.IsKeyJustPressed(ebiten.KeyEnter)) {
 		if g.useFederation {
 			g.handleFederationJoin()
 		} else {
@@ -4692,6 +8238,12 @@ func (g *Game) updateMinigame() error {
 		g.updateCircuitGame()
 	case "code":
 		g.updateCodeGame()
+	case "wire":
+		g.updateWireGame()
+	case "frequency":
+		g.updateFrequencyGame()
+	case "rhythm":
+		g.updateRhythmGame()
 	}
 
 	// Check if minigame completed
@@ -4699,8 +8251,7 @@ func (g *Game) updateMinigame() error {
 		progress := g.activeMinigame.GetProgress()
 		if progress >= 1.0 {
 			// Success - open door
-			g.currentMap[g.minigameDoorY][g.minigameDoorX] = bsp.TileFloor
-			g.raycaster.SetMap(g.currentMap)
+			g.setTile(g.minigameDoorX, g.minigameDoorY, bsp.TileFloor)
 			g.audioEngine.PlaySFX("door_open", float64(g.minigameDoorX), float64(g.minigameDoorY))
 			g.hud.ShowMessage("Lock bypassed!")
 		} else {
@@ -4827,6 +8378,89 @@ func (g *Game) updateCodeGame() {
 	}
 }
 
+// updateWireGame handles wire matching minigame input.
+func (g *Game) updateWireGame() {
+	if g.minigameInputTimer < 5 {
+		return
+	}
+
+	wireGame, ok := g.activeMinigame.(*minigame.WireMatchGame)
+	if !ok {
+		return
+	}
+
+	// Up/down to choose a wire or terminal
+	if g.input.IsJustPressed(input.ActionMoveForward) || inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		wireGame.MoveCursor(-1)
+		g.minigameInputTimer = 0
+	} else if g.input.IsJustPressed(input.ActionMoveBackward) || inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		wireGame.MoveCursor(1)
+		g.minigameInputTimer = 0
+	}
+
+	// Space/Fire to pick up a wire, then to connect it
+	if g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract) {
+		holding := wireGame.Held >= 0
+		success := wireGame.Select()
+		switch {
+		case !holding && success:
+			g.audioEngine.PlaySFX("wire_grab", g.camera.X, g.camera.Y)
+		case success:
+			g.audioEngine.PlaySFX("wire_connect", g.camera.X, g.camera.Y)
+		case holding:
+			g.audioEngine.PlaySFX("wire_spark", g.camera.X, g.camera.Y)
+		}
+		g.minigameInputTimer = 0
+	}
+}
+
+// frequencyTuneStep is how far the tuning dial turns each frame a key is
+// held.
+const frequencyTuneStep = 0.004
+
+// updateFrequencyGame handles frequency tuning minigame input.
+func (g *Game) updateFrequencyGame() {
+	freqGame, ok := g.activeMinigame.(*minigame.FrequencyTuneGame)
+	if !ok {
+		return
+	}
+
+	// Hold left/right to turn the dial
+	if g.input.IsPressed(input.ActionStrafeLeft) || ebiten.IsKeyPressed(ebiten.KeyLeft) {
+		freqGame.Tune(-frequencyTuneStep)
+	}
+	if g.input.IsPressed(input.ActionStrafeRight) || ebiten.IsKeyPressed(ebiten.KeyRight) {
+		freqGame.Tune(frequencyTuneStep)
+	}
+}
+
+// updateRhythmGame handles rhythm lock minigame input.
+func (g *Game) updateRhythmGame() {
+	rhythmGame, ok := g.activeMinigame.(*minigame.RhythmLockGame)
+	if !ok {
+		return
+	}
+
+	// Tick on each beat so it can be played by ear
+	if rhythmGame.Frame%rhythmGame.Interval == 0 {
+		g.audioEngine.PlaySFX("rhythm_beat", g.camera.X, g.camera.Y)
+	}
+
+	if g.minigameInputTimer < 3 {
+		return
+	}
+
+	// Space/Fire to strike
+	if g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract) {
+		if rhythmGame.Strike() {
+			g.audioEngine.PlaySFX("rhythm_hit", g.camera.X, g.camera.Y)
+		} else {
+			g.audioEngine.PlaySFX("rhythm_miss", g.camera.X, g.camera.Y)
+		}
+		g.minigameInputTimer = 0
+	}
+}
+
 // handleMultiplayerSelect initializes the selected multiplayer mode.
 func (g *Game) handleMultiplayerSelect() {
 	modes := g.getMultiplayerModes()
@@ -4835,6 +8469,7 @@ func (g *Game) handleMultiplayerSelect() {
 	}
 
 	mode := modes[g.mpSelectedMode]
+	g.scoreboard = nil
 	switch mode.ID {
 	case "coop":
 		session, err := network.NewCoopSession("local_coop", 4, g.seed)
@@ -4861,6 +8496,7 @@ func (g *Game) handleMultiplayerSelect() {
 			return
 		}
 		g.multiplayerMgr = match
+		g.scoreboard = ui.NewScoreboard(mode.Name, true)
 		g.networkMode = true
 		g.mpStatusMsg = "Team Deathmatch started!"
 	case "territory":
@@ -4870,6 +8506,7 @@ func (g *Game) handleMultiplayerSelect() {
 			return
 		}
 		g.multiplayerMgr = match
+		g.scoreboard = ui.NewScoreboard(mode.Name, true)
 		g.networkMode = true
 		g.mpStatusMsg = "Territory Control started!"
 	default:
@@ -4878,6 +8515,68 @@ func (g *Game) handleMultiplayerSelect() {
 	g.hud.ShowMessage(g.mpStatusMsg)
 }
 
+// updateMatch runs a team or territory match's rules and keeps the
+// scoreboard up to date with it. The scoreboard key shows it during play,
+// and it comes up by itself when the match ends.
+func (g *Game) updateMatch() {
+	match, ok := g.multiplayerMgr.(network.MatchMode)
+	if !ok || g.scoreboard == nil {
+		return
+	}
+	now := time.Now()
+	if result := match.Tick(now); result != nil {
+		g.hud.ShowMessage(matchWinnerText(result))
+		g.scoreboard.Show()
+	}
+	if g.input.IsJustPressed(input.ActionScoreboard) {
+		g.scoreboard.Toggle()
+	}
+	applyMatchStatus(g.scoreboard, match.Status(now))
+}
+
+// applyMatchStatus shows a match's state on the scoreboard: this round's
+// scores while it's on, and the totals once the match is over.
+func applyMatchStatus(sb *ui.Scoreboard, status network.MatchStatus) {
+	teams, players := status.Teams, status.Players
+	if status.Result != nil {
+		teams, players = status.Result.Teams, status.Result.Players
+		sb.SetWinner(matchWinnerText(status.Result))
+	} else {
+		sb.SetWinner("")
+	}
+
+	scoreTeams := make([]ui.ScoreboardTeam, len(teams))
+	for i, t := range teams {
+		scoreTeams[i] = ui.ScoreboardTeam{Team: t.Team, Score: t.Score, RoundsWon: t.RoundsWon}
+	}
+	sb.SetTeams(scoreTeams)
+
+	entries := make([]ui.ScoreboardEntry, len(players))
+	for i, p := range players {
+		entries[i] = ui.NewScoreboardEntry(p.PlayerID, fmt.Sprintf("Player %d", p.PlayerID), p.Team, p.Frags, p.Deaths, 0)
+	}
+	sb.SetEntries(entries)
+
+	line := fmt.Sprintf("Round %d/%d - %s", max(status.Round, 1), status.Rounds, status.Phase)
+	if status.Phase != network.PhaseWaiting && status.Phase != network.PhaseFinished {
+		left := int(status.TimeLeft / time.Second)
+		line += fmt.Sprintf(" - %d:%02d", left/60, left%60)
+	}
+	sb.SetStatus(line)
+}
+
+// matchWinnerText announces how a match ended.
+func matchWinnerText(result *network.MatchResult) string {
+	switch result.WinnerTeam {
+	case network.TeamRed:
+		return "Red team wins! (" + result.Reason + ")"
+	case network.TeamBlue:
+		return "Blue team wins! (" + result.Reason + ")"
+	default:
+		return "Draw (" + result.Reason + ")"
+	}
+}
+
 // getMultiplayerModes returns the available multiplayer modes.
 func (g *Game) getMultiplayerModes() []ui.MultiplayerMode {
 	return []ui.MultiplayerMode{
@@ -4888,60 +8587,237 @@ func (g *Game) getMultiplayerModes() []ui.MultiplayerMode {
 	}
 }
 
-// refreshServerBrowser queries the federation hub for available servers.
+// refreshServerBrowser queries the federation hub for available servers
+// and pings them in the background.
 func (g *Game) refreshServerBrowser() {
+	var servers []federation.ServerAnnouncement
+
 	// If a federation hub URL is configured, query it remotely
 	if config.C.FederationHubURL != "" {
-		genre := g.genreID
-		query := &federation.ServerQuery{
-			Genre: &genre,
+		query := &federation.ServerQuery{}
+		if config.C.ServerRegion != "" {
+			region := federation.Region(config.C.ServerRegion)
+			query.ClientRegion = &region
 		}
-
-		servers, err := federation.DiscoverServers(config.C.FederationHubURL, query, 5*time.Second)
+		nearest, err := federation.FindNearestServers(config.C.FederationHubURL, query, 5*time.Second)
 		if err != nil {
 			logrus.WithError(err).Warn("failed to discover servers from federation hub")
 			g.mpStatusMsg = "Failed to connect to federation hub. Press R to retry."
-			g.serverBrowser = nil
+			g.serverList.SetServers(nil)
+			g.syncServerBrowser()
+			return
+		}
+		for _, s := range nearest {
+			servers = append(servers, s.ServerAnnouncement)
+		}
+		g.openServerFeed(query)
+	} else if g.federationHub != nil {
+		// Otherwise use local federation hub (for testing/local servers)
+		for _, a := range g.federationHub.QueryServers(&federation.ServerQuery{}) {
+			servers = append(servers, *a)
+		}
+	} else {
+		g.mpStatusMsg = "Federation not available"
+		return
+	}
+
+	g.serverList.SetServers(servers)
+	g.browserIdx = 0
+	g.syncServerBrowser()
+	go g.serverList.PingAll(network.DefaultPingTimeout)
+
+	if len(g.serverBrowser) == 0 {
+		g.mpStatusMsg = "No servers found. Press R to refresh."
+	} else {
+		g.mpStatusMsg = fmt.Sprintf("Found %d servers. Press L for local mode.", len(g.serverBrowser))
+	}
+}
+
+// openServerFeed subscribes to the hub's registry events, so the browser
+// stays live without polling. Without a feed the list only changes on R.
+func (g *Game) openServerFeed(query *federation.ServerQuery) {
+	if g.serverFeed != nil {
+		return
+	}
+	feed, err := federation.SubscribeServers(config.C.FederationHubURL, query, 5*time.Second)
+	if err != nil {
+		logrus.WithError(err).Warn("failed to subscribe to federation hub updates")
+		return
+	}
+	g.serverFeed = feed
+}
+
+// closeServerFeed ends the browser's live updates.
+func (g *Game) closeServerFeed() {
+	if g.serverFeed != nil {
+		g.serverFeed.Close()
+		g.serverFeed = nil
+	}
+}
+
+// drainServerFeed applies the registry events that arrived since the last
+// frame, pinging servers as they appear.
+func (g *Game) drainServerFeed() {
+	if g.serverFeed == nil {
+		return
+	}
+	for {
+		select {
+		case event, ok := <-g.serverFeed.Events():
+			if !ok {
+				g.serverFeed = nil
+				g.mpStatusMsg = "Lost live updates from the federation hub. Press R to refresh."
+				return
+			}
+			g.applyServerEvent(event)
+		default:
 			return
 		}
+	}
+}
 
-		g.serverBrowser = make([]*federation.ServerAnnouncement, len(servers))
-		for i := range servers {
-			g.serverBrowser[i] = &servers[i]
+// applyServerEvent applies one registry event to the server browser.
+func (g *Game) applyServerEvent(event federation.RegistryEvent) {
+	switch event.Type {
+	case federation.EventSnapshot:
+		servers := make([]federation.ServerAnnouncement, len(event.Servers))
+		for i, s := range event.Servers {
+			servers[i] = *s
+		}
+		g.serverList.SetServers(servers)
+	case federation.EventRegistered:
+		if event.Server != nil && g.serverList.UpdateServer(*event.Server) {
+			go g.serverList.Ping(event.Server.Address, network.DefaultPingTimeout)
 		}
+	case federation.EventExpired:
+		if event.Server != nil {
+			g.serverList.RemoveServer(event.Server.Address)
+		}
+	}
+}
+
+// syncServerBrowser re-reads the filtered, sorted server list, picking up
+// pings as they come in.
+func (g *Game) syncServerBrowser() {
+	g.serverBrowser = g.serverList.Listings()
+	if g.browserIdx >= len(g.serverBrowser) {
+		g.browserIdx = len(g.serverBrowser) - 1
+	}
+	if g.browserIdx < 0 {
 		g.browserIdx = 0
+	}
+}
 
-		if len(servers) == 0 {
-			g.mpStatusMsg = "No servers found. Press R to refresh."
-		} else {
-			g.mpStatusMsg = "Found " + string(rune(len(servers)+'0')) + " servers. Press L for local mode."
+// browserRegions are the region filter choices, "" meaning any.
+var browserRegions = []string{"",
+	string(federation.RegionUSEast), string(federation.RegionUSWest),
+	string(federation.RegionEUWest), string(federation.RegionEUEast),
+	string(federation.RegionAsiaPac), string(federation.RegionSouthAm),
+}
+
+// cycleChoice returns the choice after cur, wrapping around.
+func cycleChoice(choices []string, cur string) string {
+	for i, c := range choices {
+		if c == cur {
+			return choices[(i+1)%len(choices)]
 		}
+	}
+	return choices[0]
+}
+
+// handleServerBrowserInput handles the filter, sort, favorite and direct
+// connect keys of the server browser.
+func (g *Game) handleServerBrowserInput() {
+	filter := g.serverList.Filter()
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.Key1):
+		modes := []string{""}
+		for _, m := range g.getMultiplayerModes() {
+			modes = append(modes, m.ID)
+		}
+		filter.Mode = cycleChoice(modes, filter.Mode)
+	case inpututil.IsKeyJustPressed(ebiten.Key2):
+		filter.Genre = cycleChoice(append([]string{""}, metaGenres...), filter.Genre)
+	case inpututil.IsKeyJustPressed(ebiten.Key3):
+		filter.Region = federation.Region(cycleChoice(browserRegions, string(filter.Region)))
+	case inpututil.IsKeyJustPressed(ebiten.Key4):
+		// Any, not empty, not full, then neither
+		switch {
+		case filter.MinPlayers == 0 && !filter.HideFull:
+			filter.MinPlayers = 1
+		case !filter.HideFull:
+			filter.MinPlayers, filter.HideFull = 0, true
+		case filter.MinPlayers == 0:
+			filter.MinPlayers = 1
+		default:
+			filter.MinPlayers, filter.HideFull = 0, false
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyV):
+		filter.FavoritesOnly = !filter.FavoritesOnly
+	case inpututil.IsKeyJustPressed(ebiten.KeyO):
+		g.serverList.SetSortKey(g.serverList.SortKey().Next())
+	case inpututil.IsKeyJustPressed(ebiten.KeyF):
+		g.toggleFavoriteServer()
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		g.mpAddrActive = true
+		g.mpAddrInput = ""
+		return
+	default:
 		return
 	}
+	g.serverList.SetFilter(filter)
+	g.syncServerBrowser()
+}
 
-	// Otherwise use local federation hub (for testing/local servers)
-	if g.federationHub == nil {
-		g.mpStatusMsg = "Federation not available"
+// toggleFavoriteServer stars or unstars the selected server and saves the
+// favorites to the config.
+func (g *Game) toggleFavoriteServer() {
+	if g.browserIdx < 0 || g.browserIdx >= len(g.serverBrowser) {
 		return
 	}
+	server := g.serverBrowser[g.browserIdx]
+	if g.serverList.ToggleFavorite(server.Address) {
+		g.mpStatusMsg = server.Name + " added to favorites"
+	} else {
+		g.mpStatusMsg = server.Name + " removed from favorites"
+	}
 
-	genre := g.genreID
-	query := &federation.ServerQuery{
-		Genre: &genre,
+	cfg := config.Get()
+	cfg.FavoriteServers = g.serverList.Favorites()
+	config.Set(cfg)
+	if err := config.Save(); err != nil {
+		logrus.WithError(err).Warn("failed to save favorite servers")
 	}
+}
 
-	servers := g.federationHub.QueryServers(query)
-	g.serverBrowser = servers
-	g.browserIdx = 0
+// handleDirectConnectInput edits the direct connect address and joins it
+// on Enter. Escape or Tab leaves the field.
+func (g *Game) handleDirectConnectInput() {
+	if g.input.IsJustPressed(input.ActionPause) || inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.mpAddrActive = false
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		addr, err := network.ParseServerAddress(g.mpAddrInput)
+		if err != nil {
+			g.mpStatusMsg = err.Error()
+			return
+		}
+		g.mpAddrActive = false
+		g.joinServer(addr, addr)
+		return
+	}
 
-	if len(servers) == 0 {
-		g.mpStatusMsg = "No servers found. Press R to refresh."
-	} else {
-		g.mpStatusMsg = "Found " + string(rune(len(servers)+'0')) + " servers. Press L for local mode."
+	g.mpAddrInput += string(ebiten.AppendInputChars(nil))
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.mpAddrInput) > 0 {
+		g.mpAddrInput = g.mpAddrInput[:len(g.mpAddrInput)-1]
+	}
+	if len(g.mpAddrInput) > 64 {
+		g.mpAddrInput = g.mpAddrInput[:64]
 	}
 }
 
-// handleFederationJoin connects to a federated server.
+// handleFederationJoin connects to the selected federated server.
 func (g *Game) handleFederationJoin() {
 	if len(g.serverBrowser) == 0 {
 		g.mpStatusMsg = "No servers available. Press R to refresh."
@@ -4954,9 +8830,159 @@ func (g *Game) handleFederationJoin() {
 	}
 
 	server := g.serverBrowser[g.browserIdx]
-	g.mpStatusMsg = "Connecting to " + server.Name + "..."
+	g.joinServer(server.Name, server.Address)
+}
+
+// serverConnectResult is the outcome of dialling a server to join.
+type serverConnectResult struct {
+	addr string
+	conn net.Conn
+	err  error
+}
+
+// joinServer starts connecting to a server in the background, dropping
+// any server joined before. pollServerConnect picks up the outcome.
+func (g *Game) joinServer(name, addr string) {
+	if g.mpConn != nil {
+		g.mpConn.Close()
+		g.mpConn = nil
+	}
+	g.pushToTalk = network.PushToTalk{}
+	g.voice = network.NewVoiceChannel()
+	g.mpServerAddr = addr
+	g.mpStatusMsg = "Connecting to " + name + "..."
 	g.networkMode = true
 	g.hud.ShowMessage(g.mpStatusMsg)
+
+	result := make(chan serverConnectResult, 1)
+	g.mpConnecting = result
+	go func() {
+		conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+		result <- serverConnectResult{addr: addr, conn: conn, err: err}
+	}()
+}
+
+// pollServerConnect finishes a join once its connection attempt is done.
+func (g *Game) pollServerConnect() {
+	if g.mpConnecting == nil {
+		return
+	}
+	select {
+	case res := <-g.mpConnecting:
+		g.mpConnecting = nil
+		if res.err != nil {
+			logrus.WithError(res.err).WithField("address", res.addr).Warn("failed to join server")
+			g.mpStatusMsg = "Could not connect to " + res.addr
+			g.networkMode = false
+			g.mpServerAddr = ""
+		} else {
+			g.mpConn = res.conn
+			g.mpStatusMsg = "Connected to " + res.addr
+		}
+		g.hud.ShowMessage(g.mpStatusMsg)
+	default:
+	}
+}
+
+// serverWriteTimeout bounds how long sending a command to the joined
+// server can hold up a frame.
+const serverWriteTimeout = 100 * time.Millisecond
+
+// sendServerCommand sends a command to the joined server, if there is one.
+func (g *Game) sendServerCommand(cmd *network.PlayerCommand) {
+	if g.mpConn == nil {
+		return
+	}
+	data, err := json.Marshal(cmd)
+	if err == nil {
+		g.mpConn.SetWriteDeadline(time.Now().Add(serverWriteTimeout))
+		_, err = g.mpConn.Write(append(data, '\n'))
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"system_name": "network",
+			"command":     cmd.Type,
+		}).WithError(err).Debug("Failed to send command to server")
+	}
+}
+
+// updateVoice turns the push-to-talk key into the player starting and
+// stopping talking on the joined server. Only the changes are sent, which
+// is all the voice traffic there is until a voice integration sends
+// frames.
+func (g *Game) updateVoice() {
+	held := g.mpConn != nil && !g.chatInputActive && g.input.IsPressed(input.ActionPushToTalk)
+	if speaking, changed := g.pushToTalk.Update(held, time.Now()); changed {
+		g.sendServerCommand(g.voice.SpeakingCommand(speaking))
+	}
+}
+
+// drawVoiceIndicators shows the player while they transmit, and the other
+// players who are talking or muted.
+func (g *Game) drawVoiceIndicators(screen *ebiten.Image) {
+	players := []ui.VoiceIndicator{{Name: "You", Speaking: g.pushToTalk.Speaking()}}
+	ids := append(g.voice.Speaking(), g.voice.Muted()...)
+	slices.Sort(ids)
+	for _, id := range slices.Compact(ids) {
+		players = append(players, ui.VoiceIndicator{
+			Name:     fmt.Sprintf("Player %d", id),
+			Speaking: g.voice.IsSpeaking(id),
+			Muted:    g.voice.IsMuted(id),
+		})
+	}
+	ui.DrawVoiceIndicators(screen, players)
+}
+
+// browserFilterText describes the server browser's filters and sort order.
+func (g *Game) browserFilterText() string {
+	filter := g.serverList.Filter()
+	anyIfEmpty := func(s string) string {
+		if s == "" {
+			return "any"
+		}
+		return s
+	}
+	players := "any"
+	switch {
+	case filter.MinPlayers > 0 && filter.HideFull:
+		players = "active, not full"
+	case filter.MinPlayers > 0:
+		players = "not empty"
+	case filter.HideFull:
+		players = "not full"
+	}
+	text := fmt.Sprintf("Mode %s | Genre %s | Region %s | Players %s | Sort %s",
+		anyIfEmpty(filter.Mode), anyIfEmpty(filter.Genre), anyIfEmpty(string(filter.Region)), players, g.serverList.SortKey())
+	if filter.FavoritesOnly {
+		text += " | Favorites"
+	}
+	return text
+}
+
+// serverRows converts the shown servers for the browser UI.
+func (g *Game) serverRows() []ui.ServerRow {
+	rows := make([]ui.ServerRow, len(g.serverBrowser))
+	for i, s := range g.serverBrowser {
+		ping := -1
+		if s.Pinged && !s.Reachable {
+			ping = -2
+		} else if s.Reachable {
+			ping = int(s.Ping.Milliseconds())
+		}
+		rows[i] = ui.ServerRow{
+			Name:       s.Name,
+			Address:    s.Address,
+			Mode:       s.Mode,
+			Genre:      s.Genre,
+			Region:     string(s.Region),
+			Players:    s.Players,
+			MaxPlayers: s.MaxPlayers,
+			PingMS:     ping,
+			Favorite:   s.Favorite,
+			Verified:   s.Verified,
+		}
+	}
+	return rows
 }
 
 // drawMultiplayer renders the multiplayer lobby screen.
@@ -4964,13 +8990,25 @@ func (g *Game) drawMultiplayer(screen *ebiten.Image) {
 	// Draw frozen game world
 	g.renderer.Render(screen, g.camera.X, g.camera.Y, g.camera.DirX, g.camera.DirY, g.camera.Pitch)
 
+	serverAddr := g.mpServerAddr
+	if serverAddr == "" {
+		serverAddr = "localhost"
+	}
 	state := &ui.MultiplayerState{
 		Modes:      g.getMultiplayerModes(),
 		Selected:   g.mpSelectedMode,
 		Connected:  g.networkMode,
-		ServerAddr: "localhost",
+		ServerAddr: serverAddr,
 		StatusMsg:  g.mpStatusMsg,
 	}
+	if g.useFederation {
+		state.Browsing = true
+		state.Servers = g.serverRows()
+		state.ServerSelected = g.browserIdx
+		state.FilterText = g.browserFilterText()
+		state.AddressInput = g.mpAddrInput
+		state.AddressActive = g.mpAddrActive
+	}
 	ui.DrawMultiplayer(screen, state)
 
 	// Draw encrypted chat interface
@@ -5017,6 +9055,11 @@ func (g *Game) drawEncryptedChat(screen *ebiten.Image) {
 
 // updateMods handles mods screen input.
 func (g *Game) updateMods() error {
+	g.registerInstalledMods()
+	if g.modBrowser != nil && g.modBrowser.IsVisible() {
+		return g.updateModBrowser()
+	}
+
 	// Back to playing
 	if g.input.IsJustPressed(input.ActionPause) {
 		g.state = StatePlaying
@@ -5037,9 +9080,113 @@ func (g *Game) updateMods() error {
 		g.handleModToggle()
 	}
 
+	// Browse the mod registry
+	if inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		g.openModBrowser()
+	}
+
 	return nil
 }
 
+// openModBrowser shows the mod registry browser over the mods screen.
+func (g *Game) openModBrowser() {
+	if g.modLoader == nil {
+		return
+	}
+	if config.C.ModRegistryURL == "" {
+		g.hud.ShowMessage("Set ModRegistryURL in config.toml to browse mods")
+		return
+	}
+	if g.modBrowser == nil {
+		g.modBrowser = ui.NewModBrowser(config.C.ModRegistryURL)
+	}
+
+	installed := make(map[string]string)
+	for _, m := range g.modLoader.ListMods() {
+		installed[m.Name] = m.Version
+	}
+	g.modBrowser.SetModsDir(g.modLoader.GetModsDir())
+	g.modBrowser.SetInstalledMods(installed)
+	g.modBrowser.SetVisible(true)
+}
+
+// updateModBrowser handles mod registry browser input. While the search
+// query is being edited, keys type into it.
+func (g *Game) updateModBrowser() error {
+	mb := g.modBrowser
+	if mb.IsSearching() {
+		switch {
+		case g.input.IsJustPressed(input.ActionPause):
+			mb.EndSearch(false)
+		case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+			mb.EndSearch(true)
+		default:
+			mb.EditSearch(string(ebiten.AppendInputChars(nil)), inpututil.IsKeyJustPressed(ebiten.KeyBackspace))
+		}
+		return nil
+	}
+
+	switch {
+	case g.input.IsJustPressed(input.ActionPause):
+		mb.Cancel()
+	case g.input.IsJustPressed(input.ActionMoveForward) || inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		mb.NavigateUp()
+	case g.input.IsJustPressed(input.ActionMoveBackward) || inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		mb.NavigateDown()
+	case g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		mb.Confirm()
+	case inpututil.IsKeyJustPressed(ebiten.KeySlash):
+		mb.BeginSearch()
+	case inpututil.IsKeyJustPressed(ebiten.KeyR):
+		mb.Refresh()
+	case inpututil.IsKeyJustPressed(ebiten.KeyU) && mb.GetUpdateCount() > 0:
+		go mb.AutoUpdate()
+	}
+	return mb.Update(g.input)
+}
+
+// registerInstalledMods loads the mods the browser has installed, replacing
+// any version already loaded, and reloads mod content so they apply
+// without a restart.
+func (g *Game) registerInstalledMods() {
+	if g.modBrowser == nil || g.modLoader == nil {
+		return
+	}
+	dirs := g.modBrowser.TakeInstalled()
+	if len(dirs) == 0 {
+		return
+	}
+
+	for _, dir := range dirs {
+		name := filepath.Base(dir)
+		enabled := true
+		if old, err := g.modLoader.GetMod(name); err == nil {
+			enabled = old.Enabled
+			g.modLoader.UnloadMod(name)
+		}
+		if err := g.modLoader.LoadMod(dir); err != nil {
+			logrus.WithError(err).WithField("mod", name).Warn("Failed to load installed mod")
+			g.hud.ShowMessage("Can't load " + name + ": " + err.Error())
+			continue
+		}
+		if !enabled {
+			g.modLoader.DisableMod(name)
+		}
+
+		m, err := g.modLoader.GetMod(name)
+		if err != nil {
+			continue
+		}
+		g.hud.ShowMessage("Installed: " + name + " v" + m.Version)
+		if m.ResolveError != nil {
+			g.hud.ShowMessage("Can't load " + name + ": " + m.ResolveError.Error())
+		}
+	}
+	g.loadModContent()
+	g.watchMods()
+}
+
 // handleModToggle toggles the selected mod on/off.
 func (g *Game) handleModToggle() {
 	if g.modLoader == nil {
@@ -5060,6 +9207,11 @@ func (g *Game) handleModToggle() {
 		g.modLoader.EnableMod(modEntry.Name)
 		g.hud.ShowMessage("Enabled: " + modEntry.Name)
 	}
+
+	// Toggling one mod can block or free others; say so for this one
+	if m, err := g.modLoader.GetMod(modEntry.Name); err == nil && m.ResolveError != nil {
+		g.hud.ShowMessage("Can't load " + modEntry.Name + ": " + m.ResolveError.Error())
+	}
 }
 
 // drawMods renders the mods screen.
@@ -5069,6 +9221,9 @@ func (g *Game) drawMods(screen *ebiten.Image) {
 
 	state := g.buildModsState()
 	ui.DrawMods(screen, state)
+	if g.modBrowser != nil {
+		g.modBrowser.Draw(screen)
+	}
 }
 
 // buildModsState creates the mods display state from game data.
@@ -5087,6 +9242,15 @@ func (g *Game) buildModsState() *ui.ModsState {
 			Author:      m.Author,
 			Enabled:     m.Enabled,
 		}
+		if m.ResolveError != nil {
+			uiMods[i].Error = m.ResolveError.Error()
+		}
+		uiMods[i].Trust = m.Trust.String()
+		if m.TrustError != nil {
+			uiMods[i].TrustNote = m.TrustError.Error()
+		} else if m.Signature != nil {
+			uiMods[i].TrustNote = "Signed by " + m.Signature.Author
+		}
 	}
 
 	return &ui.ModsState{
@@ -5096,26 +9260,414 @@ func (g *Game) buildModsState() *ui.ModsState {
 	}
 }
 
-// scanMods scans the mods directory for available mods.
-func (g *Game) scanMods() {
+// scanMods scans the mods directory for available mods.
+func (g *Game) scanMods() {
+	if g.modLoader == nil {
+		return
+	}
+
+	modsDir := g.modLoader.GetModsDir()
+	entries, err := os.ReadDir(modsDir)
+	if err != nil {
+		// Mods directory doesn't exist or can't be read; not an error
+		return
+	}
+
+	for _, entry := range entries {
+		// Dot directories hold installs in progress
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			modPath := modsDir + "/" + entry.Name()
+			// Attempt to load; ignore errors for invalid mods
+			_ = g.modLoader.LoadMod(modPath)
+		}
+	}
+}
+
+// gameVersion is the version of the game that mods' min_game_version and
+// max_game_version are checked against.
+const gameVersion = "6.1.0"
+
+// modKeyRingFile is the file in the mods directory caching the key ring
+// of the mod registry.
+const modKeyRingFile = "keyring.json"
+
+// loadModKeyRing checks mod signatures against the cached key ring of the
+// mod registry, once per session, and refreshes it from the registry in the
+// background. A refreshed key ring applies straight away to the mods
+// screen, and to mod content from the next game on.
+func (g *Game) loadModKeyRing() {
+	if g.modKeysLoaded {
+		return
+	}
+	g.modKeysLoaded = true
+
+	loader := g.modLoader
+	cachePath := filepath.Join(loader.GetModsDir(), modKeyRingFile)
+	if ring, err := mod.LoadKeyRing(cachePath); err == nil {
+		loader.SetKeyRing(ring)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		logrus.WithError(err).Warn("Failed to read cached mod key ring")
+	}
+
+	registryURL := config.C.ModRegistryURL
+	if registryURL == "" {
+		return
+	}
+	go func() {
+		ring, err := mod.FetchKeyRing(registryURL, 10*time.Second)
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to fetch mod key ring")
+			return
+		}
+		loader.SetKeyRing(ring)
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0o755); err != nil {
+			logrus.WithError(err).Warn("Failed to cache mod key ring")
+		} else if err := ring.Save(cachePath); err != nil {
+			logrus.WithError(err).Warn("Failed to cache mod key ring")
+		}
+		logrus.WithFields(logrus.Fields{
+			"authors": len(ring.Authors),
+			"blocked": len(ring.BlockedKeys) + len(ring.BlockedMods),
+		}).Info("Mod key ring updated")
+	}()
+}
+
+// loadMods scans the mods directory and loads the content of enabled
+// mods. It runs before the first level is built so that mod archetypes,
+// quests and script handlers apply to it.
+func (g *Game) loadMods() {
+	if g.modLoader == nil {
+		g.modLoader = mod.NewLoader()
+		g.modLoader.SetGameVersion(gameVersion)
+	}
+	g.loadModKeyRing()
+	g.scanMods()
+	for _, m := range g.modLoader.ListMods() {
+		if m.ResolveError != nil {
+			logrus.WithError(m.ResolveError).WithField("mod", m.Name).Warn("Mod can't load")
+		}
+	}
+	g.loadModContent()
+	g.watchMods()
+}
+
+// watchMods watches the directories of enabled mods for changes when
+// ModHotReload is set, replacing the mods watched before.
+func (g *Game) watchMods() {
+	if !config.C.ModHotReload || g.modLoader == nil {
+		return
+	}
+	if g.modWatcher == nil {
+		w, err := mod.NewWatcher(mod.DefaultWatchDebounce)
+		if err != nil {
+			logrus.WithError(err).Warn("Mod hot reload unavailable")
+			return
+		}
+		g.modWatcher = w
+	}
+	if err := g.modWatcher.Watch(g.modLoader.ActiveMods()); err != nil {
+		logrus.WithError(err).Warn("Failed to watch mod directories")
+	}
+}
+
+// reloadChangedMods reloads mod content once the files of watched mods
+// have changed, then tells mod plugins and scripts with a mod.reload
+// event. Content applies to what spawns from then on; enemies and items
+// already in the level keep what they had.
+func (g *Game) reloadChangedMods() {
+	if g.modWatcher == nil {
+		return
+	}
+	if !config.C.ModHotReload {
+		g.modWatcher.Close()
+		g.modWatcher = nil
+		return
+	}
+	changed := g.modWatcher.Changed()
+	if len(changed) == 0 {
+		return
+	}
+
+	for _, name := range changed {
+		if err := g.modLoader.ReloadMod(name); err != nil {
+			logrus.WithError(err).WithField("mod", name).Warn("Failed to reload mod")
+			g.hud.ShowMessage("Can't reload " + name + ": " + err.Error())
+		}
+	}
+	g.loadModContent()
+	// Reloading can enable or block mods
+	g.watchMods()
+
+	event := mod.ReloadEvent{Mods: changed}
+	if err := g.modLoader.PluginManager().Hooks().Trigger(mod.HookTypeModReload, event); err != nil {
+		logrus.WithError(err).Warn("Mod reload hook failed")
+	}
+	g.dispatchModEvent(mod.EventTypeModReload, map[string]interface{}{"mods": strings.Join(changed, ",")})
+
+	logrus.WithField("mods", changed).Info("Reloaded mod content")
+	g.hud.ShowMessage("Reloaded: " + strings.Join(changed, ", "))
+}
+
+// loadModContent loads the content of the active mods, replacing what was
+// loaded before.
+func (g *Game) loadModContent() {
+	g.loadModParticlePresets()
+	g.loadModBehaviors()
+	g.loadModArchetypes()
+	g.loadModLootTables()
+	g.loadModScripts()
+	g.loadModAssets()
+}
+
+// modParticlesFile is the file in a mod directory that defines particle
+// presets.
+const modParticlesFile = "particles.json"
+
+// loadModParticlePresets registers the particle presets of enabled mods.
+// Mod presets may replace built-in ones of the same name.
+func (g *Game) loadModParticlePresets() {
+	if g.modLoader == nil || g.particleSystem == nil {
+		return
+	}
+	for _, m := range g.modLoader.ActiveMods() {
+		n, err := g.particleSystem.Presets().LoadFile(m.Path + "/" + modParticlesFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logrus.WithError(err).WithField("mod", m.Name).Warn("Failed to load mod particle presets")
+			}
+			continue
+		}
+		logrus.WithFields(logrus.Fields{"mod": m.Name, "presets": n}).Info("Loaded mod particle presets")
+	}
+}
+
+// modBehaviorsFile is the file in a mod directory that defines behavior
+// trees, e.g. replacements for the enemy role trees.
+const modBehaviorsFile = "behaviors.json"
+
+// loadModBehaviors registers the behavior trees of every enabled mod.
+func (g *Game) loadModBehaviors() {
+	if g.modLoader == nil || g.behaviors == nil {
+		return
+	}
+	for _, m := range g.modLoader.ActiveMods() {
+		n, err := g.behaviors.LoadFile(m.Path + "/" + modBehaviorsFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logrus.WithError(err).WithField("mod", m.Name).Warn("Failed to load mod behavior trees")
+			}
+			continue
+		}
+		logrus.WithFields(logrus.Fields{"mod": m.Name, "trees": n}).Info("Loaded mod behavior trees")
+	}
+}
+
+// modArchetypesFile is the file in a mod directory that defines enemy
+// archetypes, new ones or overrides of built-in ones.
+const modArchetypesFile = "enemies.json"
+
+// loadModArchetypes registers the enemy archetypes of every enabled mod.
+func (g *Game) loadModArchetypes() {
+	if g.modLoader == nil || g.archetypes == nil {
+		return
+	}
+	for _, m := range g.modLoader.ActiveMods() {
+		n, err := g.archetypes.LoadFile(m.Path + "/" + modArchetypesFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logrus.WithError(err).WithField("mod", m.Name).Warn("Failed to load mod enemy archetypes")
+			}
+			continue
+		}
+		logrus.WithFields(logrus.Fields{"mod": m.Name, "archetypes": n}).Info("Loaded mod enemy archetypes")
+	}
+}
+
+// modLootFile is the file in a mod directory that defines loot tables:
+// tables of containers, or of enemy archetypes by their IDs.
+const modLootFile = "loot.json"
+
+// loadModLootTables registers the loot tables of every enabled mod.
+func (g *Game) loadModLootTables() {
+	if g.modLoader == nil || g.lootTables == nil {
+		return
+	}
+	for _, m := range g.modLoader.ActiveMods() {
+		n, err := g.lootTables.LoadFile(m.Path + "/" + modLootFile)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logrus.WithError(err).WithField("mod", m.Name).Warn("Failed to load mod loot tables")
+			}
+			continue
+		}
+		logrus.WithFields(logrus.Fields{"mod": m.Name, "tables": n}).Info("Loaded mod loot tables")
+	}
+}
+
+// loadModScripts runs the Lua scripts of enabled mods in load order, each
+// with the permissions its manifest asks for, and applies the weapons and
+// items they register. Scripts of mods that are disabled or can't load are
+// stopped.
+func (g *Game) loadModScripts() {
+	if g.modLoader == nil {
+		return
+	}
+	scripts := g.modLoader.Scripts()
+	scripts.OnQuestProgress = g.progressModQuest
+	// Scripts start afresh each game, in load order
+	scripts.Close()
+	for _, m := range g.modLoader.ActiveMods() {
+		if m.Manifest == nil || m.Manifest.Script == "" {
+			continue
+		}
+		api := mod.NewModAPI(m.Name, m.Manifest.Permissions.ToModPermissions())
+		api.BindGameSystems(g.world, g.audioEngine, nil, &g.hud.Message, &g.hud.MessageTime)
+		if err := scripts.LoadScript(&m, api); err != nil {
+			logrus.WithError(err).WithField("mod", m.Name).Warn("Failed to load mod script")
+		}
+	}
+	g.applyModWeapons()
+	g.applyModItems()
+}
+
+// loadModAssets replaces generated textures, sounds and sprites with the
+// files in the asset directories of enabled mods that have the asset_load
+// permission. Mods load in order, so a later mod's file for the same asset
+// wins; anything no mod supplies stays procedural.
+func (g *Game) loadModAssets() {
+	if g.modLoader == nil || g.textureAtlas == nil || g.spriteGenerator == nil {
+		return
+	}
+	g.textureAtlas.ClearOverrides()
+	if g.audioEngine != nil {
+		g.audioEngine.ClearSFXOverrides()
+	}
+	g.spriteGenerator.ClearSheets()
+
+	for _, m := range g.modLoader.ActiveMods() {
+		if m.Manifest == nil || m.Manifest.Assets == "" {
+			continue
+		}
+		log := logrus.WithField("mod", m.Name)
+		if !m.Manifest.Permissions.AssetLoad {
+			log.Warn("Mod assets need the asset_load permission")
+			continue
+		}
+		dir := filepath.Join(m.Path, filepath.Clean("/"+m.Manifest.Assets))
+
+		textures, err := g.textureAtlas.LoadOverrides(filepath.Join(dir, "textures"))
+		if err != nil {
+			log.WithError(err).Warn("Failed to load mod textures")
+		}
+		sounds := 0
+		if g.audioEngine != nil {
+			if sounds, err = g.audioEngine.LoadSFXOverrides(filepath.Join(dir, "sounds")); err != nil {
+				log.WithError(err).Warn("Failed to load mod sounds")
+			}
+		}
+		sprites, err := g.spriteGenerator.LoadSheets(filepath.Join(dir, "sprites"))
+		if err != nil {
+			log.WithError(err).Warn("Failed to load mod sprite sheets")
+		}
+		log.WithFields(logrus.Fields{
+			"textures": textures,
+			"sounds":   sounds,
+			"sprites":  sprites,
+		}).Info("Loaded mod assets")
+	}
+}
+
+// applyModWeapons puts the weapons registered by mod scripts in the slots
+// they name. A weapon without an ammo type is melee; the rest are hitscan.
+func (g *Game) applyModWeapons() {
+	if g.arsenal == nil {
+		return
+	}
+	for _, w := range g.modLoader.Scripts().Weapons() {
+		if w.Slot >= len(g.arsenal.Weapons) {
+			logrus.WithFields(logrus.Fields{"mod": w.Mod, "weapon": w.Name, "slot": w.Slot}).Warn("Mod weapon slot out of range")
+			continue
+		}
+		kind := weapon.TypeHitscan
+		if w.AmmoType == "" {
+			kind = weapon.TypeMelee
+		}
+		g.arsenal.Weapons[w.Slot] = weapon.Weapon{
+			Name:        w.Name,
+			Type:        kind,
+			Damage:      w.Damage,
+			FireRate:    w.FireRate,
+			AmmoType:    w.AmmoType,
+			ClipSize:    w.ClipSize,
+			Range:       w.Range,
+			ReloadTime:  w.ReloadTime,
+			SpreadAngle: w.SpreadAngle,
+			RayCount:    w.RayCount,
+			Knockback:   w.Knockback,
+		}
+		g.arsenal.Clips[w.Slot] = w.ClipSize
+	}
+}
+
+// applyModItems adds the items registered by mod scripts to the loot
+// tables they name, replacing an entry for the same item.
+func (g *Game) applyModItems() {
+	if g.lootTables == nil {
+		return
+	}
+	for _, item := range g.modLoader.Scripts().Items() {
+		table, ok := g.lootTables.Get(item.Table)
+		if !ok {
+			logrus.WithFields(logrus.Fields{"mod": item.Mod, "item": item.ID, "table": item.Table}).Warn("Mod item names an unknown loot table")
+			continue
+		}
+		entry := loot.Entry{Item: item.ID, Name: item.Name, Chance: item.Chance, MinDepth: item.MinDepth}
+		replaced := false
+		for i := range table.Entries {
+			if table.Entries[i].Item == item.ID {
+				table.Entries[i] = entry
+				replaced = true
+			}
+		}
+		if !replaced {
+			table.Entries = append(table.Entries, entry)
+		}
+	}
+}
+
+// addModQuests adds the objectives registered by mod scripts to the
+// level's bonus objectives.
+func (g *Game) addModQuests() {
+	if g.modLoader == nil || g.questTracker == nil {
+		return
+	}
+	for _, q := range g.modLoader.Scripts().Quests() {
+		g.questTracker.Add(quest.Objective{
+			ID:       q.ID,
+			Type:     quest.ObjFindItem,
+			Category: quest.CategoryBonus,
+			Desc:     q.Desc,
+			Target:   q.Target,
+			Count:    q.Count,
+		})
+	}
+}
+
+// progressModQuest advances an objective for the mod script that
+// registered it.
+func (g *Game) progressModQuest(id string, amount int) {
+	if g.questTracker != nil {
+		g.questTracker.UpdateProgress(id, amount)
+	}
+}
+
+// dispatchModEvent passes a game event to the handlers of mod scripts.
+// Script errors are logged by the runtime.
+func (g *Game) dispatchModEvent(eventType string, params map[string]interface{}) {
 	if g.modLoader == nil {
 		return
 	}
-
-	modsDir := g.modLoader.GetModsDir()
-	entries, err := os.ReadDir(modsDir)
-	if err != nil {
-		// Mods directory doesn't exist or can't be read; not an error
-		return
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			modPath := modsDir + "/" + entry.Name()
-			// Attempt to load; ignore errors for invalid mods
-			_ = g.modLoader.LoadMod(modPath)
-		}
-	}
+	_ = g.modLoader.Scripts().Dispatch(mod.EventData{Type: eventType, Params: params})
 }
 
 // convertInventoryToSaveItems converts inventory.Item slice to save.Item slice
@@ -5133,13 +9685,14 @@ func convertInventoryToSaveItems(inv *inventory.Inventory) []save.Item {
 			ID:   item.ID,
 			Name: item.Name,
 			Qty:  item.Qty,
+			Seed: item.Seed,
 		}
 	}
 	return saveItems
 }
 
 // saveGame saves the current game state.
-func (g *Game) saveGame(slot int) {
+func (g *Game) saveGame(slot int) error {
 	// Collect ammo pool state
 	ammoPoolState := make(map[string]int)
 	if g.ammoPool != nil {
@@ -5147,10 +9700,15 @@ func (g *Game) saveGame(slot int) {
 		ammoPoolState["shells"] = g.ammoPool.Get("shells")
 		ammoPoolState["cells"] = g.ammoPool.Get("cells")
 		ammoPoolState["rockets"] = g.ammoPool.Get("rockets")
+		for pool, amount := range g.ammoPool.All() {
+			if _, _, ok := ammo.ParsePoolKey(pool); ok {
+				ammoPoolState[pool] = amount
+			}
+		}
 	}
 
 	state := &save.GameState{
-		Version:   "1.0.0",
+		Version:   save.CurrentVersion,
 		Seed:      int64(g.seed),
 		Timestamp: time.Now(),
 		Genre:     g.genreID,
@@ -5176,6 +9734,23 @@ func (g *Game) saveGame(slot int) {
 		},
 		Keycards: g.keycards,
 		AmmoPool: ammoPoolState,
+		Depth:    g.levelDepth,
+		Clips:    maps.Clone(g.arsenal.Clips),
+		Rolls:    g.rolledWeaponSeeds(),
+		Mods:     g.attachmentIDs(),
+		Loads:    maps.Clone(g.arsenal.Loaded),
+		Armor:    g.wornArmor(),
+		Cache:    containerState(g.levelCache),
+		Quests:   g.campaignState(),
+		World:    g.worldState(),
+
+		Playtime:  g.playtime,
+		Thumbnail: g.mapThumbnail(),
+	}
+	g.saveProfile()
+	if g.dayCycle != nil {
+		hour, speed, target, left := g.dayCycle.State()
+		state.Lighting = &save.LightingState{Hour: hour, Speed: speed, TargetHour: target, TransitionLeft: left}
 	}
 	if err := save.Save(slot, state); err != nil {
 		logrus.WithFields(logrus.Fields{
@@ -5185,11 +9760,204 @@ func (g *Game) saveGame(slot int) {
 		}).Error("Failed to save game")
 		g.hud.Message = fmt.Sprintf("Save failed: %v", err)
 		g.hud.MessageTime = 180 // 3 seconds at 60 FPS
-		return
+		return err
 	}
 
 	// Save replay recording if active
 	g.saveReplay(slot)
+	return nil
+}
+
+// thumbnailSize is the most pixels a side a save's map thumbnail has.
+const thumbnailSize = 64
+
+// mapThumbnail returns a PNG of the current map, walls dark and floors
+// light, with the player marked, for the save slot list. Large maps are
+// sampled down to fit.
+func (g *Game) mapThumbnail() []byte {
+	if len(g.currentMap) == 0 || len(g.currentMap[0]) == 0 {
+		return nil
+	}
+	w, h := len(g.currentMap[0]), len(g.currentMap)
+	step := (max(w, h) + thumbnailSize - 1) / thumbnailSize
+	img := image.NewRGBA(image.Rect(0, 0, (w+step-1)/step, (h+step-1)/step))
+	for y := 0; y < h; y += step {
+		for x := 0; x < len(g.currentMap[y]); x += step {
+			c := color.RGBA{40, 40, 50, 255}
+			if !raycaster.IsWallTile(g.currentMap[y][x]) {
+				c = color.RGBA{170, 170, 160, 255}
+			}
+			img.SetRGBA(x/step, y/step, c)
+		}
+	}
+	img.SetRGBA(int(g.camera.X)/step, int(g.camera.Y)/step, color.RGBA{255, 60, 60, 255})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		logrus.WithError(err).Warn("Failed to encode save thumbnail")
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// openSaveBrowser lists the save slots to save to or load from. Saving
+// starts on the first manual slot; the auto-save slot is load-only.
+func (g *Game) openSaveBrowser(saving bool) {
+	g.saveBrowser = &ui.SaveBrowserState{Saving: saving}
+	if saving {
+		g.saveBrowser.Selected = save.AutoSaveSlot + 1
+	}
+	g.refreshSaveSlots()
+	g.saveReturn = g.state
+	g.pendingDelete = -1
+	g.state = StateSaves
+}
+
+// refreshSaveSlots reads every slot's metadata into the save browser.
+func (g *Game) refreshSaveSlots() {
+	slots, err := save.ListSlots()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to list save slots")
+	}
+	g.saveBrowser.Slots = make([]ui.SaveSlotInfo, len(slots))
+	for i, slot := range slots {
+		info := ui.SaveSlotInfo{
+			ID: slot.ID, Exists: slot.Exists, Timestamp: slot.Timestamp,
+			Genre: slot.Genre, Depth: slot.Depth, Playtime: slot.Playtime,
+			Corrupt: slot.Corrupt, Locked: slot.Locked,
+		}
+		if len(slot.Thumbnail) > 0 {
+			if img, err := png.Decode(bytes.NewReader(slot.Thumbnail)); err == nil {
+				info.Thumbnail = ebiten.NewImageFromImage(img)
+			}
+		}
+		g.saveBrowser.Slots[i] = info
+	}
+}
+
+// updateSaveBrowser handles save browser input: saving to or loading the
+// selected slot, copying it to the first empty slot, and deleting it.
+func (g *Game) updateSaveBrowser() error {
+	b := g.saveBrowser
+	if g.input.IsJustPressed(input.ActionPause) {
+		g.state = g.saveReturn
+		return nil
+	}
+	if g.input.IsJustPressed(input.ActionMoveForward) && b.Selected > 0 {
+		b.Selected--
+		g.pendingDelete = -1
+	}
+	if g.input.IsJustPressed(input.ActionMoveBackward) && b.Selected < len(b.Slots)-1 {
+		b.Selected++
+		g.pendingDelete = -1
+	}
+	if b.Selected >= len(b.Slots) {
+		return nil
+	}
+
+	slot := b.Slots[b.Selected]
+	switch {
+	case g.input.IsJustPressed(input.ActionFire) || g.input.IsJustPressed(input.ActionInteract):
+		g.confirmSaveSlot(slot)
+	case g.input.IsJustPressed(input.ActionCraft):
+		g.copySaveSlot(slot)
+	case g.input.IsJustPressed(input.ActionSquadAttack):
+		g.deleteSaveSlot(slot)
+	}
+	return nil
+}
+
+// slotName names a save slot in the save browser's messages.
+func slotName(id int) string {
+	if id == save.AutoSaveSlot {
+		return "the auto-save"
+	}
+	return fmt.Sprintf("slot %d", id)
+}
+
+// confirmSaveSlot saves to or loads the slot.
+func (g *Game) confirmSaveSlot(slot ui.SaveSlotInfo) {
+	b := g.saveBrowser
+	switch {
+	case b.Saving && slot.ID == save.AutoSaveSlot:
+		b.StatusMsg = "The auto-save slot can't be saved to"
+	case b.Saving:
+		if err := g.saveGame(slot.ID); err != nil {
+			b.StatusMsg = "Save failed"
+			return
+		}
+		b.StatusMsg = "Saved to " + slotName(slot.ID)
+		g.refreshSaveSlots()
+	case !slot.Exists:
+		b.StatusMsg = "Nothing saved in " + slotName(slot.ID)
+	default:
+		err := g.loadGame(slot.ID)
+		switch {
+		case err == nil:
+		case errors.Is(err, save.ErrCorruptSave):
+			logrus.WithError(err).WithField("slot", slot.ID).Error("Save file is corrupted")
+			b.StatusMsg = "Save in " + slotName(slot.ID) + " is corrupted and can't be loaded"
+		case errors.Is(err, save.ErrPassphraseRequired), errors.Is(err, save.ErrWrongPassphrase):
+			b.StatusMsg = "Save is encrypted: set SavePassphrase in config.toml to load it"
+		default:
+			logrus.WithError(err).WithField("slot", slot.ID).Warn("Failed to load game")
+			b.StatusMsg = "Load failed: " + err.Error()
+		}
+	}
+}
+
+// copySaveSlot copies the slot's save to the first empty manual slot.
+func (g *Game) copySaveSlot(slot ui.SaveSlotInfo) {
+	b := g.saveBrowser
+	if !slot.Exists {
+		return
+	}
+	for _, target := range b.Slots {
+		if target.Exists || target.ID == save.AutoSaveSlot {
+			continue
+		}
+		if err := save.CopySlot(slot.ID, target.ID); err != nil {
+			logrus.WithError(err).WithField("slot", slot.ID).Warn("Failed to copy save")
+			b.StatusMsg = "Copy failed"
+			return
+		}
+		b.StatusMsg = fmt.Sprintf("Copied %s to %s", slotName(slot.ID), slotName(target.ID))
+		g.refreshSaveSlots()
+		return
+	}
+	b.StatusMsg = "No empty slot to copy to"
+}
+
+// deleteSaveSlot deletes the slot's save once asked twice in a row.
+func (g *Game) deleteSaveSlot(slot ui.SaveSlotInfo) {
+	b := g.saveBrowser
+	if !slot.Exists {
+		return
+	}
+	if g.pendingDelete != slot.ID {
+		g.pendingDelete = slot.ID
+		b.StatusMsg = "Press X again to delete " + slotName(slot.ID)
+		return
+	}
+	g.pendingDelete = -1
+	if err := save.DeleteSlot(slot.ID); err != nil {
+		logrus.WithError(err).WithField("slot", slot.ID).Warn("Failed to delete save")
+		b.StatusMsg = "Delete failed"
+		return
+	}
+	b.StatusMsg = "Deleted " + slotName(slot.ID)
+	g.refreshSaveSlots()
+}
+
+// drawSaveBrowser renders the save browser over the menu or the frozen
+// game world it was opened from.
+func (g *Game) drawSaveBrowser(screen *ebiten.Image) {
+	if g.saveReturn == StateMenu {
+		ui.DrawMenu(screen, g.menuManager)
+	} else {
+		g.renderer.Render(screen, g.camera.X, g.camera.Y, g.camera.DirX, g.camera.DirY, g.camera.Pitch)
+	}
+	ui.DrawSaveBrowser(screen, g.saveBrowser)
 }
 
 // saveReplay saves the current replay recording to disk.
@@ -5258,6 +10026,21 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.drawCodex(screen)
 	case StateMinigame:
 		g.drawMinigame(screen)
+	case StatePhoto:
+		g.drawPhoto(screen)
+	case StateSummary:
+		ui.DrawLevelSummary(screen, g.levelSummary)
+	case StateSaves:
+		g.drawSaveBrowser(screen)
+	case StateStats:
+		ui.DrawMenu(screen, g.menuManager)
+		ui.DrawStatsScreen(screen, g.statsScreen)
+	case StateReplay:
+		g.drawReplay(screen)
+	}
+
+	if g.screenshotPending {
+		g.takeScreenshot(screen)
 	}
 }
 
@@ -5268,22 +10051,498 @@ func (g *Game) drawMenu(screen *ebiten.Image) {
 	if g.interactiveUI != nil {
 		g.interactiveUI.Draw(screen)
 	}
-	// Draw focus ring for keyboard navigation accessibility
-	if g.focusRingSystem != nil {
-		g.focusRingSystem.Draw(screen)
+	// Draw focus ring for keyboard navigation accessibility
+	if g.focusRingSystem != nil {
+		g.focusRingSystem.Draw(screen)
+	}
+	// Draw tooltips above all other UI elements
+	if g.tooltipSystem != nil {
+		g.tooltipSystem.Render(screen)
+	}
+}
+
+// drawPlaying renders the game world and HUD.
+func (g *Game) drawPlaying(screen *ebiten.Image) {
+	g.syncQualityConfig()
+	start := time.Now()
+
+	camX, camY := g.applyCameraShake()
+	g.setupRenderer()
+	g.renderWorldLayers(screen, camX, camY)
+	if g.screenshotPending && config.C.ScreenshotHideHUD {
+		g.takeScreenshot(screen)
+	}
+	uiStart := time.Now()
+	g.renderOverlaysAndHUD(screen, camX, camY)
+	g.profiler.Since(profiler.StageUI, uiStart)
+
+	frame := time.Since(start)
+	g.adjustRenderScale(frame)
+	g.endProfilerFrame(frame)
+	g.aiDebug.Draw(screen, g.aiAgents, g.particleProjection().Project)
+	g.profiler.Draw(screen)
+}
+
+// endProfilerFrame records the renderer's own stage timings and the frame's
+// draw counts, then closes the profiler frame.
+func (g *Game) endProfilerFrame(frame time.Duration) {
+	if !g.profiler.Enabled() {
+		return
+	}
+	if g.renderer != nil {
+		stats := g.renderer.Stats()
+		g.profiler.Add(profiler.StageRaycast, stats.World)
+		g.profiler.Add(profiler.StagePostProcess, stats.PostProcess)
+		g.profiler.Count("sprites", stats.SpritesDrawn)
+		g.profiler.Count("spr draws", stats.SpriteDraws)
+	}
+	if g.spriteBatchSystem != nil {
+		g.profiler.Count("batch draws", g.spriteBatchSystem.GetStats().DrawCalls)
+	}
+	g.profiler.EndFrame(frame)
+}
+
+// takeScreenshot saves the current screen contents as a PNG and reports the
+// result on the HUD.
+func (g *Game) takeScreenshot(screen *ebiten.Image) {
+	g.screenshotPending = false
+	path, err := render.SaveScreenshot(screen, config.C.ScreenshotDir)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save screenshot")
+		g.hud.ShowMessage("Screenshot failed")
+		return
+	}
+	logrus.WithField("path", path).Info("Screenshot saved")
+	g.hud.ShowMessage("Screenshot saved")
+}
+
+// dumpAIDebug writes every agent's debug snapshot as JSON to a timestamped
+// directory under the AI dump directory.
+func (g *Game) dumpAIDebug() {
+	dir := filepath.Join(config.C.AIDumpDir, time.Now().Format("20060102-150405"))
+	if err := ai.DumpDebug(dir, g.aiAgents); err != nil {
+		logrus.WithError(err).Error("Failed to dump AI state")
+		g.hud.ShowMessage("AI dump failed")
+		return
+	}
+	logrus.WithFields(logrus.Fields{"path": dir, "agents": len(g.aiAgents)}).Info("AI state dumped")
+	g.hud.ShowMessage("AI state dumped")
+}
+
+// Photo mode tuning.
+const (
+	photoMaxDistance   = 8.0  // Tiles the photo camera may roam from the player
+	photoMoveSpeed     = 0.05 // Tiles per tick
+	photoTurnSpeed     = 0.03 // Radians per tick
+	photoExposureStep  = 0.25
+	photoAdjustStep    = 0.1
+	photoVignetteStep  = 0.25
+	photoHintLineSpace = 12
+)
+
+// enterPhotoMode freezes the game and detaches the camera from the player.
+func (g *Game) enterPhotoMode() {
+	g.photoReturn = *g.camera
+	g.photoFilter = render.NewPhotoFilter()
+	g.photoHideHint = false
+	if g.postProcessor != nil {
+		g.postProcessor.SetPhotoFilter(&g.photoFilter)
+	}
+	g.state = StatePhoto
+}
+
+// exitPhotoMode returns the camera to the player and resumes play.
+func (g *Game) exitPhotoMode() {
+	*g.camera = g.photoReturn
+	if g.postProcessor != nil {
+		g.postProcessor.SetPhotoFilter(nil)
+	}
+	g.state = StatePlaying
+}
+
+// updatePhoto moves the free camera and adjusts filters. Nothing else is
+// updated, so the world stays frozen.
+func (g *Game) updatePhoto() error {
+	if g.input.IsJustPressed(input.ActionPause) || g.input.IsJustPressed(input.ActionPhotoMode) {
+		g.exitPhotoMode()
+		return nil
+	}
+	if g.input.IsJustPressed(input.ActionScreenshot) {
+		g.screenshotPending = true
+	}
+	g.movePhotoCamera()
+	g.adjustPhotoFilter()
+	return nil
+}
+
+// movePhotoCamera flies the camera with the movement keys and mouse. It
+// ignores actors but not walls, and stays within photoMaxDistance of the
+// player.
+func (g *Game) movePhotoCamera() {
+	dx, dy := 0.0, 0.0
+	if g.input.IsPressed(input.ActionMoveForward) {
+		dx += g.camera.DirX * photoMoveSpeed
+		dy += g.camera.DirY * photoMoveSpeed
+	}
+	if g.input.IsPressed(input.ActionMoveBackward) {
+		dx -= g.camera.DirX * photoMoveSpeed
+		dy -= g.camera.DirY * photoMoveSpeed
+	}
+	if g.input.IsPressed(input.ActionStrafeLeft) {
+		dx += g.camera.DirY * photoMoveSpeed
+		dy -= g.camera.DirX * photoMoveSpeed
+	}
+	if g.input.IsPressed(input.ActionStrafeRight) {
+		dx -= g.camera.DirY * photoMoveSpeed
+		dy += g.camera.DirX * photoMoveSpeed
+	}
+
+	if g.input.IsPressed(input.ActionTurnLeft) {
+		g.camera.Rotate(-photoTurnSpeed)
+	}
+	if g.input.IsPressed(input.ActionTurnRight) {
+		g.camera.Rotate(photoTurnSpeed)
+	}
+	pitch := 0.0
+	if mouseDX, mouseDY := g.input.MouseDelta(); mouseDX != 0 || mouseDY != 0 {
+		sensitivity := config.C.MouseSensitivity * 0.002
+		g.camera.Rotate(mouseDX * sensitivity)
+		pitch = -mouseDY * sensitivity * 3.0
+	}
+
+	x, y := clampToRadius(g.photoReturn.X, g.photoReturn.Y, g.camera.X+dx, g.camera.Y+dy, photoMaxDistance)
+	if !g.isWalkableTileAt(int(x), int(y)) {
+		x, y = g.camera.X, g.camera.Y
+	}
+	g.camera.Update(x-g.camera.X, y-g.camera.Y, 0, 0, pitch)
+}
+
+// clampToRadius moves (x, y) onto the circle of the given radius around
+// (originX, originY) when it lies outside it.
+func clampToRadius(originX, originY, x, y, radius float64) (float64, float64) {
+	dx, dy := x-originX, y-originY
+	dist := math.Hypot(dx, dy)
+	if dist <= radius || dist == 0 {
+		return x, y
+	}
+	scale := radius / dist
+	return originX + dx*scale, originY + dy*scale
+}
+
+// adjustPhotoFilter applies the photo mode filter keys: T tone, [ ] exposure,
+// - = contrast, , . saturation, V vignette, C clean, Backspace reset and H
+// hint visibility.
+func (g *Game) adjustPhotoFilter() {
+	f := &g.photoFilter
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyT):
+		f.Tone = f.Tone.Next()
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft):
+		f.Exposure -= photoExposureStep
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketRight):
+		f.Exposure += photoExposureStep
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus):
+		f.Contrast -= photoAdjustStep
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual):
+		f.Contrast += photoAdjustStep
+	case inpututil.IsKeyJustPressed(ebiten.KeyComma):
+		f.Saturation -= photoAdjustStep
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		f.Saturation += photoAdjustStep
+	case inpututil.IsKeyJustPressed(ebiten.KeyV):
+		f.Vignette += photoVignetteStep
+		if f.Vignette > 1 {
+			f.Vignette = 0
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyC):
+		f.Clean = !f.Clean
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		*f = render.NewPhotoFilter()
+	case inpututil.IsKeyJustPressed(ebiten.KeyH):
+		g.photoHideHint = !g.photoHideHint
+	}
+	f.Clamp()
+}
+
+// drawPhoto renders the frozen world from the photo camera without the HUD.
+// Screenshots are taken before the hint is drawn.
+func (g *Game) drawPhoto(screen *ebiten.Image) {
+	g.setupRenderer()
+	g.renderWorldLayers(screen, g.camera.X, g.camera.Y)
+	if g.screenshotPending {
+		g.takeScreenshot(screen)
+	}
+	if g.photoHideHint {
+		return
+	}
+
+	f := g.photoFilter
+	lines := []string{
+		"PHOTO MODE  F12 capture  P exit  H hide",
+		fmt.Sprintf("T tone %s  [ ] exposure %+.2f", f.Tone, f.Exposure),
+		fmt.Sprintf("- = contrast %.1f  , . saturation %.1f", f.Contrast, f.Saturation),
+		fmt.Sprintf("V vignette %.2f  C clean %v", f.Vignette, f.Clean),
+	}
+	for i, line := range lines {
+		text.Draw(screen, line, basicfont.Face7x13, 4, 12+i*photoHintLineSpace, color.RGBA{255, 255, 255, 220})
+	}
+}
+
+const (
+	replaySeekStep  = 5 * time.Second
+	replayMinSpeed  = 0.25
+	replayMaxSpeed  = 8.0
+	replayTurnSpeed = 0.03 // Radians per tick
+	replayMapScale  = 2    // Pixels per tile on the overview map
+	replayHint      = "Space pause  [ ] seek  , . step  - = speed  WASD fly  ESC exit"
+)
+
+// newestReplay returns the most recently written file in dir.
+func newestReplay(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var newest string
+	var newestTime time.Time
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || e.IsDir() {
+			continue
+		}
+		if newest == "" || info.ModTime().After(newestTime) {
+			newest, newestTime = filepath.Join(dir, e.Name()), info.ModTime()
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("no replays in %s", dir)
+	}
+	return newest, nil
+}
+
+// openReplay plays the newest replay in ReplayDir over the level it was
+// recorded on, generated again from its seed. The game's own level is put
+// back when playback ends.
+func (g *Game) openReplay() {
+	path, err := newestReplay(config.C.ReplayDir)
+	var replay *network.MatchReplay
+	if err == nil {
+		replay, err = network.LoadMatchReplay(path)
+	}
+	var gen *bsp.Generator
+	if err == nil {
+		gen, err = bsp.NewGenerator(common.DefaultMapSize, common.DefaultMapSize, rng.NewRNG(replay.Info.Seed))
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"system_name": "replay",
+			"dir":         config.C.ReplayDir,
+		}).WithError(err).Warn("Failed to open match replay")
+		g.mpStatusMsg = "No replay to watch"
+		return
+	}
+	gen.SetGenre(replay.Info.Genre)
+	tree, tiles := gen.Generate()
+
+	// Start over the first room, as the server spawns players there
+	start := network.CameraView{X: 1.5, Y: 1.5, DirX: 1}
+	if rooms := bsp.GetRooms(tree); len(rooms) > 0 {
+		start.X, start.Y = float64(rooms[0].X+rooms[0].W/2)+0.5, float64(rooms[0].Y+rooms[0].H/2)+0.5
+	}
+	g.replayTiles = tiles
+	g.replay = network.NewReplayPlayback(replay, start)
+	g.raycaster.SetMap(tiles)
+	g.state = StateReplay
+	logrus.WithFields(logrus.Fields{
+		"system_name": "replay",
+		"path":        path,
+		"seed":        replay.Info.Seed,
+		"genre":       replay.Info.Genre,
+	}).Info("Match replay opened")
+}
+
+// closeReplay puts the game's level back and returns to the multiplayer
+// menu.
+func (g *Game) closeReplay() {
+	g.replay = nil
+	g.replayTiles = nil
+	g.raycaster.SetMap(g.currentMap)
+	g.renderer.SetLightMap(g.lightMap)
+	g.renderer.SetEdgeAO(g.edgeAOSystem)
+	g.state = StateMultiplayer
+}
+
+// updateReplay handles the playback keys, flies the free camera and plays
+// the replay on.
+func (g *Game) updateReplay() error {
+	if g.input.IsJustPressed(input.ActionPause) {
+		g.closeReplay()
+		return nil
+	}
+
+	p := g.replay
+	var err error
+	switch {
+	case g.input.IsJustPressed(input.ActionFire):
+		if p.Done() {
+			err = p.Seek(p.Replay().FirstTick())
+		}
+		p.SetPaused(!p.Paused())
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketLeft):
+		err = p.SeekBy(-replaySeekStep)
+	case inpututil.IsKeyJustPressed(ebiten.KeyBracketRight):
+		err = p.SeekBy(replaySeekStep)
+	case inpututil.IsKeyJustPressed(ebiten.KeyComma):
+		p.SetPaused(true)
+		err = p.SeekBy(-network.TickDuration)
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod):
+		p.SetPaused(true)
+		_, err = p.Step()
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus):
+		p.SetSpeed(math.Max(p.Speed()/2, replayMinSpeed))
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual):
+		p.SetSpeed(math.Min(p.Speed()*2, replayMaxSpeed))
+	}
+	if err == nil {
+		err = p.Update(time.Second / common.TargetFPS)
+	}
+	if err != nil {
+		logrus.WithField("system_name", "replay").WithError(err).Warn("Match replay playback failed")
+		g.mpStatusMsg = "Replay is damaged"
+		g.closeReplay()
+		return nil
+	}
+
+	g.moveReplayCamera()
+	return nil
+}
+
+// moveReplayCamera flies the replay's free camera with the movement keys
+// and mouse. It passes through walls to reach any part of the level.
+func (g *Game) moveReplayCamera() {
+	cam := g.replay.Camera()
+	forward, strafe := 0.0, 0.0
+	if g.input.IsPressed(input.ActionMoveForward) {
+		forward++
+	}
+	if g.input.IsPressed(input.ActionMoveBackward) {
+		forward--
+	}
+	if g.input.IsPressed(input.ActionStrafeRight) {
+		strafe++
+	}
+	if g.input.IsPressed(input.ActionStrafeLeft) {
+		strafe--
+	}
+	cam.Move(forward, strafe, common.DeltaTime)
+
+	if g.input.IsPressed(input.ActionTurnLeft) {
+		cam.Turn(-replayTurnSpeed)
+	}
+	if g.input.IsPressed(input.ActionTurnRight) {
+		cam.Turn(replayTurnSpeed)
+	}
+	if mouseDX, _ := g.input.MouseDelta(); mouseDX != 0 {
+		cam.Turn(mouseDX * config.C.MouseSensitivity * 0.002)
+	}
+}
+
+// drawReplay renders the replay's level from the free camera, an overview
+// map of the players, and the playback controls. The game's lighting
+// belongs to its own level, so the replay is drawn unlit.
+func (g *Game) drawReplay(screen *ebiten.Image) {
+	p := g.replay
+	view := p.Camera().View()
+	g.renderer.SetTextureAtlas(g.textureAtlas)
+	g.renderer.SetLightMap(nil)
+	g.renderer.SetEdgeAO(nil)
+	g.renderer.Render(screen, view.X, view.Y, view.DirX, view.DirY, 0)
+
+	g.drawReplayMap(screen, view)
+	ui.DrawReplayControls(screen, &ui.ReplayState{
+		Tick:     p.Tick(),
+		Elapsed:  p.Elapsed(),
+		Duration: p.Replay().Duration(),
+		Progress: p.Progress(),
+		Paused:   p.Paused(),
+		Speed:    p.Speed(),
+		Hint:     replayHint,
+	})
+}
+
+// drawReplayMap draws the replay's level in the top-left corner with a dot
+// for every entity in the snapshot and one for the camera.
+func (g *Game) drawReplayMap(screen *ebiten.Image, view network.CameraView) {
+	const scale = replayMapScale
+	for y, row := range g.replayTiles {
+		for x, tile := range row {
+			if isWalkableTile(tile) {
+				vector.DrawFilledRect(screen, float32(4+x*scale), float32(4+y*scale), scale, scale, color.RGBA{60, 60, 70, 180}, false)
+			}
+		}
+	}
+	for _, es := range g.replay.Snapshot().Entities {
+		if x, y, ok := es.Position(); ok {
+			vector.DrawFilledCircle(screen, float32(4+x*scale), float32(4+y*scale), 2, color.RGBA{255, 80, 60, 255}, false)
+		}
+	}
+	vector.DrawFilledCircle(screen, float32(4+view.X*scale), float32(4+view.Y*scale), 2, color.RGBA{100, 200, 255, 255}, false)
+}
+
+// qualityConfig is the subset of config that selects render quality.
+type qualityConfig struct {
+	tier              string
+	dynamicResolution bool
+	frameBudgetMS     float64
+}
+
+// syncQualityConfig re-applies quality settings when a config hot-reload
+// changed them.
+func (g *Game) syncQualityConfig() {
+	cfg := config.Get()
+	if (qualityConfig{cfg.QualityTier, cfg.DynamicResolution, cfg.FrameBudgetMS}) != g.qualityConfig {
+		g.applyQualityConfig(cfg)
+	}
+}
+
+// applyQualityConfig switches the render quality tier and configures dynamic
+// resolution scaling. Unknown tiers fall back to high quality.
+func (g *Game) applyQualityConfig(cfg config.Config) {
+	g.qualityConfig = qualityConfig{cfg.QualityTier, cfg.DynamicResolution, cfg.FrameBudgetMS}
+
+	tier, err := render.ParseQualityTier(cfg.QualityTier)
+	if err != nil {
+		logrus.WithField("tier", cfg.QualityTier).Warn("Unknown quality tier, using high")
+	}
+	g.quality = tier.Settings()
+	if g.postProcessor != nil {
+		g.postProcessor.SetEffects(g.quality.PostEffects)
 	}
-	// Draw tooltips above all other UI elements
-	if g.tooltipSystem != nil {
-		g.tooltipSystem.Render(screen)
+
+	if !cfg.DynamicResolution || cfg.FrameBudgetMS <= 0 {
+		g.resolutionScaler = nil
+		if g.renderer != nil {
+			g.renderer.SetRenderScale(1)
+		}
+		return
+	}
+	budget := time.Duration(cfg.FrameBudgetMS * float64(time.Millisecond))
+	if g.resolutionScaler == nil {
+		g.resolutionScaler = render.NewResolutionScaler(budget, g.quality.MinRenderScale)
 	}
+	g.resolutionScaler.Budget = budget
+	g.resolutionScaler.MinScale = g.quality.MinRenderScale
 }
 
-// drawPlaying renders the game world and HUD.
-func (g *Game) drawPlaying(screen *ebiten.Image) {
-	camX, camY := g.applyCameraShake()
-	g.setupRenderer()
-	g.renderWorldLayers(screen, camX, camY)
-	g.renderOverlaysAndHUD(screen, camX, camY)
+// adjustRenderScale feeds the frame's render time to the dynamic resolution
+// scaler and resizes the renderer when the scale changes.
+func (g *Game) adjustRenderScale(frame time.Duration) {
+	if g.resolutionScaler == nil || g.renderer == nil {
+		return
+	}
+	if scale, changed := g.resolutionScaler.Observe(frame); changed {
+		g.renderer.SetRenderScale(scale)
+	}
 }
 
 // applyCameraShake calculates camera position with shake offset.
@@ -5313,7 +10572,10 @@ func (g *Game) setupRenderer() {
 	g.renderer.SetLightMap(g.lightMap)
 	g.renderer.SetEdgeAO(g.edgeAOSystem)
 	g.renderer.SetPostProcessor(g.postProcessor)
-	g.renderer.Tick()
+	// Photo mode freezes animated textures along with the simulation.
+	if g.state != StatePhoto {
+		g.renderer.Tick()
+	}
 }
 
 // renderWorldLayers draws all world elements in proper depth order.
@@ -5324,7 +10586,9 @@ func (g *Game) renderWorldLayers(screen *ebiten.Image, camX, camY float64) {
 	}
 
 	g.renderBackgroundAndWorld(screen, camX, camY)
+	entitiesStart := time.Now()
 	g.renderWorldEntities(screen, camX, camY)
+	g.profiler.Since(profiler.StageSprites, entitiesStart)
 	g.renderCombatEffects(screen, camX, camY)
 
 	// End sprite batch and flush all queued draws
@@ -5342,7 +10606,7 @@ func (g *Game) renderBackgroundAndWorld(screen *ebiten.Image, camX, camY float64
 	g.renderer.Render(screen, camX, camY, g.camera.DirX, g.camera.DirY, g.camera.Pitch)
 }
 
-// renderWorldEntities renders floor details, decals, corpses, props, and loot.
+// renderWorldEntities renders floor details, decals, corpses, sprites, and loot.
 func (g *Game) renderWorldEntities(screen *ebiten.Image, camX, camY float64) {
 	if g.floorDetailSystem != nil && (len(g.floorTiles) > 0 || len(g.floorDetails) > 0) {
 		g.renderFloorDetails(screen)
@@ -5365,16 +10629,11 @@ func (g *Game) renderWorldEntities(screen *ebiten.Image, camX, camY float64) {
 	if g.corpseSystem != nil && len(g.corpses) > 0 {
 		g.corpseSystem.RenderAllCorpses(screen, g.corpses, camX, camY)
 	}
-	if g.propsManager != nil {
-		g.renderProps(screen)
-	}
-	if len(g.loreItems) > 0 {
-		g.renderLoreItems(screen)
-	}
+	g.renderSprites(screen)
 	if g.lootVisualSystem != nil {
 		g.renderLootItems(screen)
 	}
-	if g.shadowSystem != nil && g.lightMap != nil {
+	if g.shadowSystem != nil && g.lightMap != nil && g.quality.Shadows {
 		g.renderShadows(screen)
 	}
 	// Render volumetric light shafts after shadows but before combat effects
@@ -5387,20 +10646,40 @@ func (g *Game) renderWorldEntities(screen *ebiten.Image, camX, camY float64) {
 	}
 }
 
-// renderCombatEffects renders particles, weather, hazards, and combat feedback.
+// renderSprites registers props, lore items, and hazards as billboards and
+// draws them depth-sorted and clipped against the walls of this frame.
+func (g *Game) renderSprites(screen *ebiten.Image) {
+	if g.propsManager != nil {
+		g.addPropSprites()
+	}
+	if len(g.loreItems) > 0 {
+		g.addLoreSprites()
+	}
+	if g.hazardECSSystem != nil {
+		g.addHazardSprites()
+	}
+	g.addEnemySprites()
+	g.addEscortSprite()
+	g.addShotSprites()
+	g.renderer.DrawSprites(screen)
+}
+
+// renderCombatEffects renders particles, weather, and combat feedback.
 func (g *Game) renderCombatEffects(screen *ebiten.Image, camX, camY float64) {
+	particlesStart := time.Now()
 	if g.particleSystem != nil {
 		g.renderParticles(screen)
 	}
+	if g.trailSystem != nil {
+		g.trailRenderer.Draw(screen, g.trailSystem, g.particleProjection().Project)
+	}
 	if g.weatherSystem != nil {
 		g.renderWeatherParticles(screen)
 	}
 	if g.dustMoteSystem != nil {
 		g.renderDustMotes(screen)
 	}
-	if g.hazardECSSystem != nil {
-		g.renderHazards(screen)
-	}
+	g.profiler.Since(profiler.StageParticles, particlesStart)
 	if g.feedbackSystem != nil {
 		g.renderFeedbackEffects(screen)
 	}
@@ -5413,7 +10692,9 @@ func (g *Game) renderCombatEffects(screen *ebiten.Image, camX, camY float64) {
 	if g.telegraphSystem != nil {
 		g.telegraphSystem.Render(screen, g.world, camX, camY)
 	}
+	uiStart := time.Now()
 	g.renderCombatUI(screen, camX, camY)
+	g.profiler.Since(profiler.StageUI, uiStart)
 }
 
 // renderCombatUI renders in-world UI elements with overlap prevention via layout manager.
@@ -5537,6 +10818,7 @@ func (g *Game) renderOverlaysAndHUD(screen *ebiten.Image, camX, camY float64) {
 
 	g.hud.Update()
 	ui.DrawHUD(screen, g.hud)
+	g.drawVoiceIndicators(screen)
 
 	// Render player status effect icons (buffs/debuffs)
 	if g.statusBarSystem != nil && g.playerEntity != 0 {
@@ -5565,6 +10847,9 @@ func (g *Game) renderOverlaysAndHUD(screen *ebiten.Image, camX, camY float64) {
 	if g.tutorialSystem.Active {
 		ui.DrawTutorial(screen, g.tutorialSystem.Current)
 	}
+	if g.scoreboard != nil {
+		g.scoreboard.Draw(screen)
+	}
 }
 
 // renderHitFlashOverlay applies screen flash effect when player is hit.
@@ -5598,26 +10883,53 @@ func (g *Game) renderHitFlashOverlay(screen *ebiten.Image) {
 	}
 }
 
+// particleProjection maps world positions to the screen for particle and
+// trail overlays centred on the camera.
+func (g *Game) particleProjection() particle.Projection {
+	return particle.Projection{
+		CameraX: g.camera.X,
+		CameraY: g.camera.Y,
+		Scale:   particleScreenZoom,
+		CenterX: float32(config.C.InternalWidth / 2),
+		CenterY: float32(config.C.InternalHeight / 2),
+	}
+}
+
+// batchedParticleThreshold is the visible particle count above which
+// particles are drawn as plain batched discs instead of individual shapes.
+const batchedParticleThreshold = 256
+
 // renderParticles draws particles with enhanced visual shapes and effects.
 func (g *Game) renderParticles(screen *ebiten.Image) {
 	// Use optimized visible particles query with frustum culling
 	const maxDistSq = 400.0
-	particles := g.particleSystem.GetVisibleParticles(
+	g.visibleParts = g.particleSystem.AppendVisibleParticles(
+		g.visibleParts[:0],
 		g.camera.X, g.camera.Y,
 		g.camera.DirX, g.camera.DirY,
 		maxDistSq,
 	)
+	particles := g.visibleParts
+	proj := g.particleProjection()
+
+	// Large counts go through a single batched draw
+	if len(particles) > batchedParticleThreshold && g.batchRenderer != nil {
+		g.particleBatch.Width = float32(config.C.InternalWidth)
+		g.particleBatch.Height = float32(config.C.InternalHeight)
+		drawn := g.particleBatch.Build(particles, proj)
+		g.batchRenderer.Draw(screen, g.particleBatch)
+		g.profiler.Count("particles", drawn)
+		return
+	}
 
 	renderer := g.particleRenderer.GetRenderer()
 
+	drawn := 0
 	for i := range particles {
 		p := &particles[i]
-		dx := p.X - g.camera.X
-		dy := p.Y - g.camera.Y
 
 		// Project to screen space
-		screenX := float32(config.C.InternalWidth/2) + float32(dx*10)
-		screenY := float32(config.C.InternalHeight/2) + float32(dy*10)
+		screenX, screenY := proj.Project(p.X, p.Y)
 
 		// Screen bounds check with margin for larger particles
 		const margin = 20.0
@@ -5625,8 +10937,10 @@ func (g *Game) renderParticles(screen *ebiten.Image) {
 			screenY >= -margin && screenY < float32(config.C.InternalHeight)+margin {
 			// Use enhanced particle renderer
 			renderer.RenderParticle(screen, p, screenX, screenY, g.genreID)
+			drawn++
 		}
 	}
+	g.profiler.Count("particles", drawn)
 }
 
 // renderWeatherParticles draws environmental weather particles with depth parallax.
@@ -5713,87 +11027,106 @@ func (g *Game) renderFogParticle(screen *ebiten.Image, x, y, size float32, c col
 	}
 }
 
-// renderProps draws decorative props as simple sprites in world space.
-func (g *Game) renderProps(screen *ebiten.Image) {
-	allProps := g.propsManager.GetProps()
-	planeX, planeY := g.calcCameraPlane()
+// addPropSprites registers props near the camera with the sprite pipeline.
+func (g *Game) addPropSprites() {
+	for _, prop := range g.propsManager.GetProps() {
+		dx := prop.X - g.camera.X
+		dy := prop.Y - g.camera.Y
+		dist := dx*dx + dy*dy
+		if dist > 400 {
+			continue
+		}
 
-	for _, prop := range allProps {
-		g.renderSingleProp(screen, prop, planeX, planeY)
+		propSubtype := mapPropTypeToSubtype(prop.SpriteType)
+		propSeed := int64(prop.X*1000 + prop.Y)
+		spriteImg := g.spriteGenerator.GetSprite(sprite.SpriteProp, propSubtype, propSeed, g.animationTicker/10, 32)
+		if spriteImg == nil {
+			continue
+		}
+
+		s := render.Sprite{X: prop.X, Y: prop.Y, Image: spriteImg, Aspect: 1}
+		applyDistanceFade(&s.ColorScale, dist)
+		g.applyColorTempScale(&s.ColorScale, prop.X, prop.Y, 0.35)
+		g.renderer.AddSprite(s)
 	}
 }
 
-// calcCameraPlane computes camera plane vectors from direction and FOV.
-func (g *Game) calcCameraPlane() (float64, float64) {
-	fov := g.camera.FOV
-	planeX := -g.camera.DirY * fov / 66.0
-	planeY := g.camera.DirX * fov / 66.0
-	return planeX, planeY
-}
+// addEnemySprites queues the sprites of living enemies near the camera,
+// drawn from each agent's archetype with a per-enemy seed variation.
+func (g *Game) addEnemySprites() {
+	for i, agent := range g.aiAgents {
+		if agent.Health <= 0 {
+			continue
+		}
+		dx := agent.X - g.camera.X
+		dy := agent.Y - g.camera.Y
+		dist := dx*dx + dy*dy
+		if dist > 400 {
+			continue
+		}
 
-// renderSingleProp renders a single prop with camera-space transform.
-func (g *Game) renderSingleProp(screen *ebiten.Image, prop *props.Prop, planeX, planeY float64) {
-	dx := prop.X - g.camera.X
-	dy := prop.Y - g.camera.Y
+		arch, ok := g.archetypes.Get(agent.ArchetypeID)
+		if !ok {
+			continue
+		}
+		spriteImg := g.spriteGenerator.GetSprite(sprite.SpriteEnemy, arch.Sprite, arch.SpriteSeed+int64(i), g.animationTicker/10, 32)
+		if spriteImg == nil {
+			continue
+		}
 
-	dist := dx*dx + dy*dy
-	if dist > 400 {
-		return
+		s := render.Sprite{X: agent.X, Y: agent.Y, Image: spriteImg, Aspect: 1}
+		applyDistanceFade(&s.ColorScale, dist)
+		g.applyColorTempScale(&s.ColorScale, agent.X, agent.Y, 0.35)
+		g.renderer.AddSprite(s)
 	}
+}
 
-	transformX, transformY := g.transformToCamera(dx, dy, planeX, planeY)
-	if transformY <= 0.1 {
+// addEscortSprite queues the sprite of the level's living escortee, tinted
+// apart from the enemies.
+func (g *Game) addEscortSprite() {
+	if g.escort == nil || g.escort.Dead() {
 		return
 	}
-
-	spriteScreenX, spriteWidth, spriteHeight, drawStartX, drawStartY, visible := g.calcPropScreenBounds(transformX, transformY)
-	if !visible {
+	a := g.escort.Agent
+	dx := a.X - g.camera.X
+	dy := a.Y - g.camera.Y
+	dist := dx*dx + dy*dy
+	if dist > 400 {
 		return
 	}
-	_ = spriteScreenX
-
-	propSubtype := mapPropTypeToSubtype(prop.SpriteType)
-	propSeed := int64(prop.X*1000 + prop.Y)
-	spriteImg := g.spriteGenerator.GetSprite(sprite.SpriteProp, propSubtype, propSeed, g.animationTicker/10, 32)
+	spriteImg := g.spriteGenerator.GetSprite(sprite.SpriteEnemy, "humanoid", escortSpriteSeed, g.animationTicker/10, 32)
 	if spriteImg == nil {
 		return
 	}
-
-	op := &ebiten.DrawImageOptions{}
-	scaleX := float64(spriteWidth) / float64(spriteImg.Bounds().Dx())
-	scaleY := float64(spriteHeight) / float64(spriteImg.Bounds().Dy())
-	op.GeoM.Scale(scaleX, scaleY)
-	op.GeoM.Translate(float64(drawStartX), float64(drawStartY))
-
-	applyDistanceFade(op, dist)
-	g.applyColorTempScale(op, prop.X, prop.Y, 0.35)
-	screen.DrawImage(spriteImg, op)
-}
-
-// transformToCamera transforms world-relative offset to camera space.
-func (g *Game) transformToCamera(dx, dy, planeX, planeY float64) (float64, float64) {
-	invDet := 1.0 / (planeX*g.camera.DirY - g.camera.DirX*planeY)
-	transformX := invDet * (g.camera.DirY*dx - g.camera.DirX*dy)
-	transformY := invDet * (-planeY*dx + planeX*dy)
-	return transformX, transformY
+	s := render.Sprite{X: a.X, Y: a.Y, Image: spriteImg, Aspect: 1}
+	s.ColorScale.Scale(0.7, 1, 0.8, 1)
+	applyDistanceFade(&s.ColorScale, dist)
+	g.applyColorTempScale(&s.ColorScale, a.X, a.Y, 0.35)
+	g.renderer.AddSprite(s)
 }
 
-// calcPropScreenBounds calculates screen bounds for a prop sprite.
-func (g *Game) calcPropScreenBounds(transformX, transformY float64) (int, int, int, int, int, bool) {
-	spriteScreenX := int((float64(config.C.InternalWidth) / 2.0) * (1.0 + transformX/transformY))
-	spriteHeight := int(float64(config.C.InternalHeight) / transformY)
-	spriteWidth := spriteHeight
-
-	drawStartX := spriteScreenX - spriteWidth/2
-	drawStartY := config.C.InternalHeight/2 - spriteHeight/2
-
-	if spriteScreenX+spriteWidth/2 < 0 || drawStartX >= config.C.InternalWidth {
-		return 0, 0, 0, 0, 0, false
-	}
-	if drawStartX < 0 {
-		drawStartX = 0
+// addShotSprites queues enemy shots as glowing sprites: a flare growing at
+// the shooter while it aims, then the shot itself in flight.
+func (g *Game) addShotSprites() {
+	shotType := reflect.TypeOf(&combat.ShotComponent{})
+	posType := reflect.TypeOf(&engine.Position{})
+	for _, e := range g.world.Query(shotType, posType) {
+		sc, _ := g.world.GetComponent(e, shotType)
+		pc, _ := g.world.GetComponent(e, posType)
+		shot, pos := sc.(*combat.ShotComponent), pc.(*engine.Position)
+
+		scale := shot.Weapon.Radius * 2
+		if !shot.Released() {
+			scale *= 1 - shot.Windup/shot.Weapon.WindupTime
+		}
+		g.renderer.AddSprite(render.Sprite{
+			X: pos.X, Y: pos.Y,
+			Color:      shot.Weapon.Color,
+			Scale:      scale,
+			Aspect:     1,
+			Fullbright: true,
+		})
 	}
-	return spriteScreenX, spriteWidth, spriteHeight, drawStartX, drawStartY, true
 }
 
 // mapPropTypeToSubtype converts prop type enum to sprite subtype string.
@@ -5825,18 +11158,18 @@ func mapPropTypeToSubtype(propType props.PropType) string {
 }
 
 // applyDistanceFade applies alpha fade based on distance.
-func applyDistanceFade(op *ebiten.DrawImageOptions, dist float64) {
+func applyDistanceFade(cs *ebiten.ColorScale, dist float64) {
 	if dist > 250 {
 		alpha := 1.0 - (dist-250)/150
 		if alpha < 0.3 {
 			alpha = 0.3
 		}
-		op.ColorScale.Scale(1, 1, 1, float32(alpha))
+		cs.ScaleAlpha(float32(alpha))
 	}
 }
 
 // applyColorTempScale applies color temperature tinting using ColorScale.
-func (g *Game) applyColorTempScale(op *ebiten.DrawImageOptions, worldX, worldY, blendFactor float64) {
+func (g *Game) applyColorTempScale(cs *ebiten.ColorScale, worldX, worldY, blendFactor float64) {
 	if g.colorTempSystem == nil {
 		return
 	}
@@ -5848,7 +11181,7 @@ func (g *Game) applyColorTempScale(op *ebiten.DrawImageOptions, worldX, worldY,
 	tintR := float32(1.0 + (float64(tint.R)/255.0-0.5)*blend)
 	tintG := float32(1.0 + (float64(tint.G)/255.0-0.5)*blend)
 	tintB := float32(1.0 + (float64(tint.B)/255.0-0.5)*blend)
-	op.ColorScale.Scale(tintR, tintG, tintB, 1.0)
+	cs.Scale(tintR, tintG, tintB, 1.0)
 }
 
 // renderFloorDetails draws procedural floor detail overlays for visual variety.
@@ -6494,7 +11827,9 @@ func (g *Game) isWallAt(worldX, worldY float64) bool {
 		return true // Out of bounds treated as wall
 	}
 
-	return g.currentMap[tileY][tileX] > 0
+	// Light passes through see-through walls such as windows and bars.
+	tile := g.currentMap[tileY][tileX]
+	return tile > 0 && !raycaster.IsTransparentTile(tile)
 }
 
 // renderLensDirtEffects renders cinematic lens dirt artifacts around bright lights.
@@ -6868,15 +12203,85 @@ func (g *Game) updateColorTempLights(lights []lighting.Light) {
 	}
 }
 
-// collectDynamicLights gathers lights from the lighting system.
+// collectDynamicLights gathers lights from the lighting system and hazards.
 func (g *Game) collectDynamicLights(lights []lighting.Light) []lighting.Light {
 	if g.lightingSystem != nil {
 		dynamicLights := g.lightingSystem.CollectLights(g.world)
 		lights = append(lights, dynamicLights...)
 	}
+	return g.collectHazardLights(lights)
+}
+
+// collectFrameLights gathers the lights that change from frame to frame:
+// animated and moving entity lights, hazard glows and impulse lights from
+// weapon fire and explosions.
+func (g *Game) collectFrameLights(lights []lighting.Light) []lighting.Light {
+	if g.lightingSystem != nil {
+		lights = append(lights, g.lightingSystem.CollectDynamicLights(g.world)...)
+	}
+	lights = g.collectHazardLights(lights)
+	if g.impulseLights != nil {
+		lights = g.impulseLights.AppendLights(lights)
+	}
+	return lights
+}
+
+// collectHazardLights adds animated glows for nearby energy and fire
+// hazards: sparking electric floors, strobing laser grids, pulsing plasma
+// jets and guttering fire grates. Charging hazards strobe as a warning.
+func (g *Game) collectHazardLights(lights []lighting.Light) []lighting.Light {
+	if g.hazardECSSystem == nil || g.world == nil {
+		return lights
+	}
+	for i, h := range g.hazardECSSystem.GetHazardsForRendering(g.world) {
+		if !shouldRenderHazard(h, g.camera) {
+			continue
+		}
+		kind, ok := hazardLightAnimation(h)
+		if !ok {
+			continue
+		}
+		light := lighting.Light{
+			X:         h.X,
+			Y:         h.Y,
+			Radius:    3.0,
+			Intensity: 0.6,
+			R:         float64((h.Color>>16)&0xFF) / 255.0,
+			G:         float64((h.Color>>8)&0xFF) / 255.0,
+			B:         float64(h.Color&0xFF) / 255.0,
+		}
+		anim := lighting.NewLightAnimator(kind, int64(g.seed)+int64(i))
+		lights = append(lights, anim.Apply(light, g.flickerTick))
+	}
 	return lights
 }
 
+// hazardLightAnimation returns the light animation of a glowing hazard, or
+// false for hazards that give off no light in their current state.
+func hazardLightAnimation(h hazard.HazardRenderData) (lighting.Animation, bool) {
+	var kind lighting.Animation
+	switch h.Type {
+	case hazard.TypeElectricFloor:
+		kind = lighting.AnimMalfunction
+	case hazard.TypeLaserGrid:
+		kind = lighting.AnimStrobe
+	case hazard.TypePlasmaJet:
+		kind = lighting.AnimPulse
+	case hazard.TypeFireGrate:
+		kind = lighting.AnimCandle
+	default:
+		return lighting.AnimNone, false
+	}
+	switch h.State {
+	case hazard.StateCharging:
+		return lighting.AnimStrobe, true
+	case hazard.StateActive:
+		return kind, true
+	default:
+		return lighting.AnimNone, false
+	}
+}
+
 // collectTorchLights adds torch props as light sources.
 func (g *Game) collectTorchLights(lights []lighting.Light) []lighting.Light {
 	if g.propsManager == nil {
@@ -6886,39 +12291,34 @@ func (g *Game) collectTorchLights(lights []lighting.Light) []lighting.Light {
 	allProps := g.propsManager.GetProps()
 	for i, prop := range allProps {
 		if prop.SpriteType == props.PropTorch {
-			// Use physics-based flicker system for realistic flame behavior
-			baseIntensity := 0.9
-			baseR, baseG, baseB := 1.0, 0.8, 0.4
+			light := baseTorchLight(prop)
 
 			if g.flickerBridge != nil {
-				// Use prop index as unique seed for this torch
-				flickerResult := g.flickerBridge.CalculateFlickerSimple(int64(i), g.flickerTick, baseIntensity, baseR, baseG, baseB)
-				lights = append(lights, lighting.Light{
-					X:         prop.X,
-					Y:         prop.Y,
-					Radius:    8.0,
-					Intensity: flickerResult.Intensity,
-					R:         flickerResult.R,
-					G:         flickerResult.G,
-					B:         flickerResult.B,
-				})
-			} else {
-				// Fallback if flicker system not initialized
-				lights = append(lights, lighting.Light{
-					X:         prop.X,
-					Y:         prop.Y,
-					Radius:    8.0,
-					Intensity: baseIntensity,
-					R:         baseR,
-					G:         baseG,
-					B:         baseB,
-				})
+				// Use physics-based flicker system for realistic flame behavior,
+				// with the prop index as unique seed for this torch
+				flickerResult := g.flickerBridge.CalculateFlickerSimple(int64(i), g.flickerTick, light.Intensity, light.R, light.G, light.B)
+				light.Intensity = flickerResult.Intensity
+				light.R, light.G, light.B = flickerResult.R, flickerResult.G, flickerResult.B
 			}
+			lights = append(lights, light)
 		}
 	}
 	return lights
 }
 
+// baseTorchLight returns the steady, unflickered light of a torch prop.
+func baseTorchLight(prop *props.Prop) lighting.Light {
+	return lighting.Light{
+		X:         prop.X,
+		Y:         prop.Y,
+		Radius:    8.0,
+		Intensity: 0.9,
+		R:         1.0,
+		G:         0.8,
+		B:         0.4,
+	}
+}
+
 // collectPlayerFlashlight adds the player's flashlight cone light.
 func (g *Game) collectPlayerFlashlight(coneLights []lighting.ConeLight) []lighting.ConeLight {
 	return append(coneLights, lighting.ConeLight{
@@ -6936,31 +12336,33 @@ func (g *Game) collectPlayerFlashlight(coneLights []lighting.ConeLight) []lighti
 	})
 }
 
-// renderHazards draws environmental hazards as floor sprites in world space.
-func (g *Game) renderHazards(screen *ebiten.Image) {
-	hazards := g.hazardECSSystem.GetHazardsForRendering(g.world)
-	planeX, planeY := calculateCameraPlane(g.camera)
-
-	for _, h := range hazards {
-		if !shouldRenderHazard(h, g.camera) {
-			continue
-		}
-
-		transformX, transformY := transformToCameraSpace(h.X, h.Y, g.camera, planeX, planeY)
-		if transformY <= 0.1 {
+// addHazardSprites registers environmental hazards as floor sprites with
+// the sprite pipeline. Charging hazards get a pulsing warning halo.
+func (g *Game) addHazardSprites() {
+	for _, h := range g.hazardECSSystem.GetHazardsForRendering(g.world) {
+		if !shouldRenderHazard(h, g.camera) || h.Height <= 0 {
 			continue
 		}
 
-		drawBounds := calculateHazardDrawBounds(transformX, transformY, h)
-		if !isVisibleOnScreen(drawBounds) {
-			continue
+		scale := h.Height / 2
+		aspect := 2 * h.Width / h.Height
+		if h.State == hazard.StateCharging {
+			ticker := (time.Now().UnixMilli() / 2) % 100
+			g.renderer.AddSprite(render.Sprite{
+				X: h.X, Y: h.Y,
+				Color:      color.RGBA{255, 255, 0, uint8(150 + ticker)},
+				Scale:      scale * 1.15,
+				Aspect:     aspect,
+				VOffset:    -scale * 0.075,
+				Fullbright: true,
+			})
 		}
-
-		clippedBounds := clipToScreenBounds(drawBounds)
-		hazardColor := determineHazardColor(h)
-
-		renderHazardSprite(screen, clippedBounds, hazardColor)
-		renderHazardWarning(screen, clippedBounds, h)
+		g.renderer.AddSprite(render.Sprite{
+			X: h.X, Y: h.Y,
+			Color:  determineHazardColor(h),
+			Scale:  scale,
+			Aspect: aspect,
+		})
 	}
 }
 
@@ -6972,57 +12374,6 @@ func shouldRenderHazard(h hazard.HazardRenderData, cam *camera.Camera) bool {
 	return distSq <= 400
 }
 
-// transformToCameraSpace converts world coordinates to camera space.
-func transformToCameraSpace(x, y float64, cam *camera.Camera, planeX, planeY float64) (float64, float64) {
-	dx := x - cam.X
-	dy := y - cam.Y
-	invDet := 1.0 / (planeX*cam.DirY - cam.DirX*planeY)
-	transformX := invDet * (cam.DirY*dx - cam.DirX*dy)
-	transformY := invDet * (-planeY*dx + planeX*dy)
-	return transformX, transformY
-}
-
-// hazardDrawBounds represents the screen-space rectangle for rendering.
-type hazardDrawBounds struct {
-	startX, endX, startY, endY int
-}
-
-// calculateHazardDrawBounds computes screen-space draw bounds for a hazard.
-func calculateHazardDrawBounds(transformX, transformY float64, h hazard.HazardRenderData) hazardDrawBounds {
-	spriteScreenX := int((float64(config.C.InternalWidth) / 2.0) * (1.0 + transformX/transformY))
-	spriteHeight := int(float64(config.C.InternalHeight) / transformY * h.Height)
-	spriteWidth := int(float64(config.C.InternalWidth) / transformY * h.Width)
-
-	return hazardDrawBounds{
-		startX: spriteScreenX - spriteWidth/2,
-		endX:   spriteScreenX + spriteWidth/2,
-		startY: config.C.InternalHeight/2 + spriteHeight/4,
-		endY:   config.C.InternalHeight/2 + spriteHeight/4 + spriteHeight/2,
-	}
-}
-
-// isVisibleOnScreen checks if draw bounds intersect the screen.
-func isVisibleOnScreen(bounds hazardDrawBounds) bool {
-	return !(bounds.endX < 0 || bounds.startX >= config.C.InternalWidth)
-}
-
-// clipToScreenBounds clamps draw bounds to screen dimensions.
-func clipToScreenBounds(bounds hazardDrawBounds) hazardDrawBounds {
-	if bounds.startX < 0 {
-		bounds.startX = 0
-	}
-	if bounds.endX >= config.C.InternalWidth {
-		bounds.endX = config.C.InternalWidth - 1
-	}
-	if bounds.startY < 0 {
-		bounds.startY = 0
-	}
-	if bounds.endY >= config.C.InternalHeight {
-		bounds.endY = config.C.InternalHeight - 1
-	}
-	return bounds
-}
-
 // determineHazardColor calculates color based on hazard state.
 func determineHazardColor(h hazard.HazardRenderData) color.RGBA {
 	r := uint8((h.Color >> 16) & 0xFF)
@@ -7044,28 +12395,6 @@ func determineHazardColor(h hazard.HazardRenderData) color.RGBA {
 	}
 }
 
-// renderHazardSprite draws the hazard sprite to the screen.
-func renderHazardSprite(screen *ebiten.Image, bounds hazardDrawBounds, col color.RGBA) {
-	vector.DrawFilledRect(screen,
-		float32(bounds.startX), float32(bounds.startY),
-		float32(bounds.endX-bounds.startX), float32(bounds.endY-bounds.startY),
-		col, false)
-}
-
-// renderHazardWarning draws warning indicator for charging hazards.
-func renderHazardWarning(screen *ebiten.Image, bounds hazardDrawBounds, h hazard.HazardRenderData) {
-	if h.State != hazard.StateCharging {
-		return
-	}
-
-	ticker := (time.Now().UnixMilli() / 2) % 100
-	warningColor := color.RGBA{255, 255, 0, uint8(150 + ticker)}
-	vector.StrokeRect(screen,
-		float32(bounds.startX), float32(bounds.startY),
-		float32(bounds.endX-bounds.startX), float32(bounds.endY-bounds.startY),
-		2, warningColor, false)
-}
-
 // renderFeedbackEffects renders damage numbers and impact effects.
 func (g *Game) renderFeedbackEffects(screen *ebiten.Image) {
 	planeX, planeY := calculateCameraPlane(g.camera)
@@ -7264,12 +12593,18 @@ func (g *Game) drawAutomap(screen *ebiten.Image) {
 	bounds := screen.Bounds()
 	w := float32(bounds.Dx())
 
-	walls := make([][]bool, len(g.currentMap))
-	for y := 0; y < len(g.currentMap); y++ {
-		walls[y] = make([]bool, len(g.currentMap[y]))
-		for x := 0; x < len(g.currentMap[y]); x++ {
-			tile := g.currentMap[y][x]
-			walls[y][x] = tile == bsp.TileWall || (tile >= 10 && tile <= 14)
+	var walls [][]bool
+	if g.chunkWorld != nil {
+		// Unloaded chunks are sealed in the tile map but unexplored on the map.
+		walls = g.chunkWorld.WallMask(0, 0, g.chunkWorld.Width, g.chunkWorld.Height)
+	} else {
+		walls = make([][]bool, len(g.currentMap))
+		for y := 0; y < len(g.currentMap); y++ {
+			walls[y] = make([]bool, len(g.currentMap[y]))
+			for x := 0; x < len(g.currentMap[y]); x++ {
+				tile := g.currentMap[y][x]
+				walls[y][x] = tile == bsp.TileWall || (tile >= 10 && tile <= 19) || raycaster.IsTransparentTile(tile)
+			}
 		}
 	}
 
@@ -7304,7 +12639,7 @@ func (g *Game) drawCollapsibleAutomap(screen *ebiten.Image) {
 		walls[y] = make([]bool, len(g.currentMap[y]))
 		for x := 0; x < len(g.currentMap[y]); x++ {
 			tile := g.currentMap[y][x]
-			walls[y][x] = tile == bsp.TileWall || (tile >= 10 && tile <= 14)
+			walls[y][x] = tile == bsp.TileWall || (tile >= 10 && tile <= 19) || raycaster.IsTransparentTile(tile)
 		}
 	}
 
@@ -7376,10 +12711,9 @@ func (g *Game) drawLoading(screen *ebiten.Image) {
 	ui.DrawLoadingScreen(screen, g.loadingScreen)
 }
 
-// renderLoreItems draws lore items as simple sprites in world space.
-func (g *Game) renderLoreItems(screen *ebiten.Image) {
-	planeX, planeY := calculateCameraPlane(g.camera)
-
+// addLoreSprites registers unactivated lore items near the camera with the
+// sprite pipeline.
+func (g *Game) addLoreSprites() {
 	for _, loreItem := range g.loreItems {
 		if loreItem.Activated {
 			continue
@@ -7389,7 +12723,7 @@ func (g *Game) renderLoreItems(screen *ebiten.Image) {
 			continue
 		}
 
-		g.drawLoreItemSprite(screen, loreItem, g.camera, planeX, planeY, g.animationTicker)
+		g.addLoreItemSprite(loreItem, g.animationTicker)
 	}
 }
 
@@ -7409,20 +12743,9 @@ func shouldRenderLoreItem(loreItem *lore.LoreItem, camera *camera.Camera) bool {
 	return dist <= 400
 }
 
-// drawLoreItemSprite renders a single lore item sprite on screen.
-func (g *Game) drawLoreItemSprite(screen *ebiten.Image, loreItem *lore.LoreItem, camera *camera.Camera, planeX, planeY float64, animationTicker int) {
-	transformX, transformY := calculateSpriteTransform(loreItem, camera, planeX, planeY)
-	if transformY <= 0.1 {
-		return
-	}
-
-	spriteScreenX, spriteHeight, spriteWidth := calculateSpriteDimensions(transformX, transformY)
-	drawStartX, _, drawStartY, _ := calculateSpriteDrawBounds(spriteScreenX, spriteHeight, spriteWidth)
-
-	if !isWithinScreenBounds(drawStartX, drawStartX+spriteWidth) {
-		return
-	}
-
+// addLoreItemSprite registers a single lore item sprite. Lore items float at
+// half height and pulse, so they are drawn fullbright.
+func (g *Game) addLoreItemSprite(loreItem *lore.LoreItem, animationTicker int) {
 	// Map lore item type to sprite subtype
 	var loreSubtype string
 	switch loreItem.Type {
@@ -7444,19 +12767,23 @@ func (g *Game) drawLoreItemSprite(screen *ebiten.Image, loreItem *lore.LoreItem,
 	// Get or generate sprite
 	spriteImg := g.spriteGenerator.GetSprite(sprite.SpriteLoreItem, loreSubtype, loreSeed, animationTicker/10, 32)
 
-	if spriteImg != nil {
-		op := &ebiten.DrawImageOptions{}
-		scaleX := float64(spriteWidth) / float64(spriteImg.Bounds().Dx())
-		scaleY := float64(spriteHeight) / float64(spriteImg.Bounds().Dy())
-		op.GeoM.Scale(scaleX, scaleY)
-		op.GeoM.Translate(float64(drawStartX), float64(drawStartY))
-
-		// Add pulsing glow effect
-		pulse := float32(0.7 + 0.3*math.Sin(float64(animationTicker)*0.05))
-		op.ColorScale.Scale(pulse, pulse, pulse, 1.0)
+	if spriteImg == nil {
+		return
+	}
 
-		screen.DrawImage(spriteImg, op)
+	s := render.Sprite{
+		X:          loreItem.PosX,
+		Y:          loreItem.PosY,
+		Image:      spriteImg,
+		Scale:      0.5,
+		Aspect:     1,
+		VOffset:    0.25,
+		Fullbright: true,
 	}
+	// Add pulsing glow effect
+	pulse := float32(0.7 + 0.3*math.Sin(float64(animationTicker)*0.05))
+	s.ColorScale.Scale(pulse, pulse, pulse, 1.0)
+	g.renderer.AddSprite(s)
 }
 
 // calculateSpriteTransform computes the sprite's transform coordinates.
@@ -7690,6 +13017,12 @@ func (g *Game) drawMinigame(screen *ebiten.Image) {
 		g.drawCircuitGame(screen, centerX, centerY)
 	case "code":
 		g.drawCodeGame(screen, centerX, centerY)
+	case "wire":
+		g.drawWireGame(screen, centerX, centerY)
+	case "frequency":
+		g.drawFrequencyGame(screen, centerX, centerY)
+	case "rhythm":
+		g.drawRhythmGame(screen, centerX, centerY)
 	}
 
 	// Draw progress bar
@@ -8059,6 +13392,165 @@ func (g *Game) drawCodeGame(screen *ebiten.Image, centerX, centerY float32) {
 	text.Draw(screen, backspaceText, basicfont.Face7x13, backspaceX, backspaceY, color.RGBA{150, 150, 150, 255})
 }
 
+// drawMinigameLabel draws text centered on x.
+func drawMinigameLabel(screen *ebiten.Image, label string, x float32, y int, clr color.RGBA) {
+	bounds := text.BoundString(basicfont.Face7x13, label)
+	text.Draw(screen, label, basicfont.Face7x13, int(x)-bounds.Dx()/2, y, clr)
+}
+
+// wireColors are the colors of the wire matching minigame's wires.
+var wireColors = []color.RGBA{
+	{230, 40, 40, 255},
+	{40, 110, 255, 255},
+	{250, 220, 30, 255},
+	{40, 210, 70, 255},
+	{220, 60, 220, 255},
+	{255, 140, 20, 255},
+}
+
+// drawWireGame renders wire matching interface.
+func (g *Game) drawWireGame(screen *ebiten.Image, centerX, centerY float32) {
+	wireGame, ok := g.activeMinigame.(*minigame.WireMatchGame)
+	if !ok {
+		return
+	}
+
+	drawMinigameLabel(screen, "WIRE SPLICE", centerX, int(centerY)-90, color.RGBA{255, 160, 40, 255})
+	instr := "UP/DOWN to choose, SPACE to pick up a wire"
+	if wireGame.Held >= 0 {
+		instr = "Pick the matching terminal, SPACE to connect"
+	}
+	drawMinigameLabel(screen, instr, centerX, int(centerY)-75, color.RGBA{200, 200, 200, 255})
+
+	rowHeight := float32(22)
+	leftX := centerX - 80
+	rightX := centerX + 80
+	topY := centerY - float32(len(wireGame.Wires)-1)*rowHeight/2
+
+	for i := range wireGame.Wires {
+		wireY := topY + float32(i)*rowHeight
+		wireColor := wireColors[wireGame.Wires[i]%len(wireColors)]
+		termColor := wireColors[wireGame.Terminals[i]%len(wireColors)]
+
+		// Wire stubs on the left, terminals on the right
+		vector.DrawFilledRect(screen, leftX-30, wireY-3, 30, 6, wireColor, false)
+		vector.DrawFilledCircle(screen, rightX+6, wireY, 6, termColor, false)
+		vector.StrokeCircle(screen, rightX+6, wireY, 6, 1, color.RGBA{200, 200, 200, 255}, false)
+
+		if wireGame.Connected[i] {
+			termY := topY + float32(wireGame.Terminal(i))*rowHeight
+			vector.StrokeLine(screen, leftX, wireY, rightX, termY, 3, wireColor, false)
+		}
+	}
+
+	// Held wire follows the cursor across the board
+	cursorY := topY + float32(wireGame.Cursor)*rowHeight
+	if wireGame.Held >= 0 {
+		heldY := topY + float32(wireGame.Held)*rowHeight
+		heldColor := wireColors[wireGame.Wires[wireGame.Held]%len(wireColors)]
+		heldColor.A = 160
+		vector.StrokeLine(screen, leftX, heldY, rightX, cursorY, 2, heldColor, false)
+		vector.StrokeRect(screen, rightX-4, cursorY-10, 20, 20, 2, color.RGBA{255, 255, 255, 255}, false)
+	} else {
+		vector.StrokeRect(screen, leftX-34, cursorY-10, 38, 20, 2, color.RGBA{255, 255, 255, 255}, false)
+	}
+}
+
+// drawFrequencyGame renders frequency tuning interface.
+func (g *Game) drawFrequencyGame(screen *ebiten.Image, centerX, centerY float32) {
+	freqGame, ok := g.activeMinigame.(*minigame.FrequencyTuneGame)
+	if !ok {
+		return
+	}
+
+	drawMinigameLabel(screen, "SIGNAL TUNER", centerX, int(centerY)-90, color.RGBA{120, 255, 120, 255})
+	drawMinigameLabel(screen, "Hold LEFT/RIGHT to tune, stay on the signal", centerX, int(centerY)-75, color.RGBA{200, 200, 200, 255})
+
+	band := freqGame.Band + 1
+	if band > len(freqGame.Targets) {
+		band = len(freqGame.Targets)
+	}
+	secondsLeft := (freqGame.TimeLimit - freqGame.Time + 59) / 60
+	status := fmt.Sprintf("Band %d/%d   Time: %ds", band, len(freqGame.Targets), secondsLeft)
+	drawMinigameLabel(screen, status, centerX, int(centerY)-60, color.RGBA{255, 255, 255, 255})
+
+	// Dial scale with the needle at the tuned frequency
+	dialWidth := float32(200)
+	dialX := centerX - dialWidth/2
+	dialY := centerY - 20
+	vector.DrawFilledRect(screen, dialX, dialY, dialWidth, 20, color.RGBA{30, 40, 30, 255}, false)
+	for i := 0; i <= 10; i++ {
+		tickX := dialX + dialWidth*float32(i)/10
+		tickHeight := float32(5)
+		if i%5 == 0 {
+			tickHeight = 10
+		}
+		vector.StrokeLine(screen, tickX, dialY+20-tickHeight, tickX, dialY+20, 1, color.RGBA{150, 200, 150, 255}, false)
+	}
+	vector.StrokeRect(screen, dialX, dialY, dialWidth, 20, 2, color.RGBA{200, 200, 200, 255}, false)
+	needleX := dialX + dialWidth*float32(freqGame.Dial)
+	vector.DrawFilledRect(screen, needleX-1, dialY-6, 3, 32, color.RGBA{255, 60, 60, 255}, false)
+
+	// Signal strength meter
+	signal := freqGame.Signal()
+	bars := 10
+	lit := int(signal*float64(bars) + 0.5)
+	for i := 0; i < bars; i++ {
+		barHeight := float32(4 + i*2)
+		barX := centerX - float32(bars*8)/2 + float32(i*8)
+		barY := centerY + 40 - barHeight
+		barColor := color.RGBA{50, 60, 50, 255}
+		if i < lit {
+			barColor = color.RGBA{60, uint8(120 + i*13), 60, 255}
+		}
+		vector.DrawFilledRect(screen, barX, barY, 6, barHeight, barColor, false)
+	}
+
+	// Lock building while the dial is held on the signal
+	lockWidth := float32(80)
+	lockFill := lockWidth * float32(freqGame.Hold) / float32(freqGame.HoldFrames)
+	vector.DrawFilledRect(screen, centerX-lockWidth/2, centerY+50, lockFill, 6, color.RGBA{0, 200, 255, 255}, false)
+	vector.StrokeRect(screen, centerX-lockWidth/2, centerY+50, lockWidth, 6, 1, color.RGBA{150, 150, 150, 255}, false)
+}
+
+// drawRhythmGame renders rhythm lock interface.
+func (g *Game) drawRhythmGame(screen *ebiten.Image, centerX, centerY float32) {
+	rhythmGame, ok := g.activeMinigame.(*minigame.RhythmLockGame)
+	if !ok {
+		return
+	}
+
+	drawMinigameLabel(screen, "TUMBLER RHYTHM", centerX, int(centerY)-90, color.RGBA{230, 190, 90, 255})
+	drawMinigameLabel(screen, "Press SPACE as the ring closes on the lock", centerX, int(centerY)-75, color.RGBA{200, 200, 200, 255})
+
+	// The ring closes on the lock face as the next beat comes
+	phase := rhythmGame.BeatPhase()
+	framesOff := phase * float64(rhythmGame.Interval)
+	if rest := float64(rhythmGame.Interval) - framesOff; rest < framesOff {
+		framesOff = rest
+	}
+	faceColor := color.RGBA{90, 70, 40, 255}
+	if framesOff <= float64(rhythmGame.Window) {
+		faceColor = color.RGBA{230, 190, 90, 255}
+	}
+	vector.DrawFilledCircle(screen, centerX, centerY-10, 20, faceColor, false)
+	vector.StrokeCircle(screen, centerX, centerY-10, 20, 2, color.RGBA{200, 200, 200, 255}, false)
+	ringRadius := 20 + 30*float32(1-phase)
+	vector.StrokeCircle(screen, centerX, centerY-10, ringRadius, 2, color.RGBA{255, 255, 255, 200}, false)
+
+	// Tumblers set by the current streak
+	for i := 0; i < rhythmGame.Required; i++ {
+		tumblerX := centerX - float32(rhythmGame.Required*14)/2 + float32(i*14)
+		tumblerY := centerY + 50
+		tumblerColor := color.RGBA{70, 70, 70, 255}
+		if i < rhythmGame.Streak {
+			tumblerColor = color.RGBA{0, 255, 0, 255}
+		}
+		vector.DrawFilledRect(screen, tumblerX, tumblerY, 10, 14, tumblerColor, false)
+		vector.StrokeRect(screen, tumblerX, tumblerY, 10, 14, 1, color.RGBA{200, 200, 200, 255}, false)
+	}
+}
+
 // cosf is a helper for float32 cosine.
 func cosf(angle float32) float32 {
 	return float32(math.Cos(float64(angle)))
@@ -8069,13 +13561,22 @@ func sinf(angle float32) float32 {
 	return float32(math.Sin(float64(angle)))
 }
 
-// findExitPosition finds the room furthest from player spawn as the exit location.
+// findExitPosition returns the tagged exit chamber, falling back to the room
+// furthest from player spawn.
 func (g *Game) findExitPosition(rooms []*bsp.Room, playerX, playerY float64) *quest.Position {
 	if len(rooms) == 0 {
 		// Fallback to center of map if no rooms available
 		return &quest.Position{X: 60, Y: 60}
 	}
 
+	// Prefer the exit chamber tagged by the generator
+	for _, room := range rooms {
+		if room.Tag == bsp.RoomTagExit {
+			cx, cy := room.Center()
+			return &quest.Position{X: float64(cx), Y: float64(cy)}
+		}
+	}
+
 	// Find the room furthest from player spawn
 	maxDist := 0.0
 	var exitRoom *bsp.Room
@@ -8300,6 +13801,8 @@ func main() {
 	if err := config.Load(); err != nil {
 		log.Fatal(err)
 	}
+	save.SetPassphrase(config.C.SavePassphrase)
+	save.SetDir(config.C.SaveDir)
 
 	initializeEbitenWindow()
 	stopWatch := setupConfigHotReload()
@@ -8357,4 +13860,10 @@ func applyConfigChanges(old, new config.Config) {
 			ebiten.SetTPS(60)
 		}
 	}
+	if new.SavePassphrase != old.SavePassphrase {
+		save.SetPassphrase(new.SavePassphrase)
+	}
+	if new.SaveDir != old.SaveDir {
+		save.SetDir(new.SaveDir)
+	}
 }
@@ -1,21 +1,31 @@
 package main
 
 import (
+	"math"
 	"testing"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/opd-ai/violence/pkg/ai"
 	"github.com/opd-ai/violence/pkg/ammo"
 	"github.com/opd-ai/violence/pkg/bsp"
 	"github.com/opd-ai/violence/pkg/camera"
+	"github.com/opd-ai/violence/pkg/combat"
 	"github.com/opd-ai/violence/pkg/config"
+	"github.com/opd-ai/violence/pkg/engine"
 	"github.com/opd-ai/violence/pkg/federation"
+	"github.com/opd-ai/violence/pkg/hazard"
 	"github.com/opd-ai/violence/pkg/inventory"
+	"github.com/opd-ai/violence/pkg/lighting"
 	"github.com/opd-ai/violence/pkg/lore"
 	"github.com/opd-ai/violence/pkg/minigame"
 	"github.com/opd-ai/violence/pkg/particle"
 	"github.com/opd-ai/violence/pkg/progression"
+	"github.com/opd-ai/violence/pkg/props"
 	"github.com/opd-ai/violence/pkg/quest"
+	"github.com/opd-ai/violence/pkg/squad"
 	"github.com/opd-ai/violence/pkg/ui"
+	"github.com/opd-ai/violence/pkg/weapon"
+	"github.com/opd-ai/violence/pkg/weather"
 )
 
 // TestNewGame verifies game initialization.
@@ -2581,6 +2591,41 @@ func TestMinigameGenreVariety(t *testing.T) {
 	}
 }
 
+// TestMinigameTypeOf tests each minigame gets its own input and rendering.
+func TestMinigameTypeOf(t *testing.T) {
+	tests := []struct {
+		game     minigame.MiniGame
+		expected string
+	}{
+		{minigame.NewLockpickGame(1, 1), "lockpick"},
+		{minigame.NewHackGame(1, 1), "hack"},
+		{minigame.NewCircuitTraceGame(1, 1), "circuit"},
+		{minigame.NewBypassCodeGame(1, 1), "code"},
+		{minigame.NewWireMatchGame(1, 1), "wire"},
+		{minigame.NewFrequencyTuneGame(1, 1), "frequency"},
+		{minigame.NewRhythmLockGame(1, 1), "rhythm"},
+	}
+
+	for _, tt := range tests {
+		if got := minigameTypeOf(tt.game); got != tt.expected {
+			t.Errorf("%T: expected minigame type %s, got %s", tt.game, tt.expected, got)
+		}
+	}
+}
+
+func TestAgentDamageType(t *testing.T) {
+	tests := map[string]combat.DamageType{
+		"staff":    combat.DamageFire,
+		"crossbow": combat.DamagePhysical,
+		"claws":    combat.DamagePhysical,
+	}
+	for weapon, want := range tests {
+		if got := agentDamageType(&ai.Agent{Weapon: weapon}); got != want {
+			t.Errorf("agentDamageType(%s) = %s, want %s", weapon, got, want)
+		}
+	}
+}
+
 // TestMinigameStateTransition tests state transitions with minigames.
 func TestMinigameStateTransition(t *testing.T) {
 	if err := config.Load(); err != nil {
@@ -3129,11 +3174,10 @@ func TestWeaponUpgradeShopItems(t *testing.T) {
 	// Apply shop upgrade item (damage)
 	game.applyShopItem("upgrade_damage")
 
-	// Verify upgrade was applied and tokens deducted
-	currentWeapon := game.arsenal.GetCurrentWeapon()
-	upgrades := game.upgradeManager.GetUpgrades(currentWeapon.Name)
-	if len(upgrades) == 0 {
-		t.Error("No upgrades applied after shop purchase")
+	// Verify the upgrade's attachment was fitted and tokens deducted
+	fitted := game.arsenal.Attached[game.arsenal.CurrentSlot]
+	if fitted[weapon.MountBarrel] != "barrel_heavy" {
+		t.Errorf("Mounts after damage upgrade = %v, want a heavy barrel", fitted)
 	}
 
 	if game.upgradeManager.GetTokens().GetCount() >= initialTokens {
@@ -3863,3 +3907,305 @@ func TestGame_SaveGame_ErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestIsOutdoorRoom(t *testing.T) {
+	tests := []struct {
+		name string
+		room bsp.Room
+		want bool
+	}{
+		{"large untagged", bsp.Room{W: 10, H: 12}, true},
+		{"small untagged", bsp.Room{W: 6, H: 8}, false},
+		{"large boss arena", bsp.Room{W: 12, H: 12, Tag: bsp.RoomTagBossArena}, false},
+		{"large start", bsp.Room{W: 12, H: 12, Tag: bsp.RoomTagStart}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOutdoorRoom(&tt.room); got != tt.want {
+				t.Errorf("isOutdoorRoom(%dx%d, tag %v) = %v, want %v", tt.room.W, tt.room.H, tt.room.Tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHazardLightAnimation(t *testing.T) {
+	tests := []struct {
+		name   string
+		hazard hazard.HazardRenderData
+		want   lighting.Animation
+		glows  bool
+	}{
+		{"active electric floor", hazard.HazardRenderData{Type: hazard.TypeElectricFloor, State: hazard.StateActive}, lighting.AnimMalfunction, true},
+		{"active laser grid", hazard.HazardRenderData{Type: hazard.TypeLaserGrid, State: hazard.StateActive}, lighting.AnimStrobe, true},
+		{"active plasma jet", hazard.HazardRenderData{Type: hazard.TypePlasmaJet, State: hazard.StateActive}, lighting.AnimPulse, true},
+		{"active fire grate", hazard.HazardRenderData{Type: hazard.TypeFireGrate, State: hazard.StateActive}, lighting.AnimCandle, true},
+		{"charging plasma jet", hazard.HazardRenderData{Type: hazard.TypePlasmaJet, State: hazard.StateCharging}, lighting.AnimStrobe, true},
+		{"cooling electric floor", hazard.HazardRenderData{Type: hazard.TypeElectricFloor, State: hazard.StateCooldown}, lighting.AnimNone, false},
+		{"active spike trap", hazard.HazardRenderData{Type: hazard.TypeSpikeTrap, State: hazard.StateActive}, lighting.AnimNone, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, glows := hazardLightAnimation(tt.hazard)
+			if got != tt.want || glows != tt.glows {
+				t.Errorf("hazardLightAnimation() = %v, %v, want %v, %v", got, glows, tt.want, tt.glows)
+			}
+		})
+	}
+}
+
+func TestBakeStaticLights(t *testing.T) {
+	pm := props.NewManager()
+	pm.AddProp(&props.Prop{ID: "torch", X: 4.5, Y: 4.5, SpriteType: props.PropTorch})
+	pm.AddProp(&props.Prop{ID: "barrel", X: 10.5, Y: 10.5, SpriteType: props.PropBarrel})
+	g := &Game{
+		lightMap:     lighting.NewSectorLightMap(16, 16, 0),
+		propsManager: pm,
+	}
+
+	g.bakeStaticLights()
+	if got := g.lightMap.StaticLightCount(); got != 1 {
+		t.Fatalf("StaticLightCount = %d, want 1 torch", got)
+	}
+
+	// The baked torch survives the per-frame clear of dynamic lights.
+	g.lightMap.Clear()
+	g.lightMap.Calculate()
+	if r, _, b := g.lightMap.GetLightColor(4, 4); r <= b || r == 0 {
+		t.Errorf("torch tile color = (%f, _, %f), want warm light", r, b)
+	}
+	if got := g.lightMap.GetLight(12, 12); got != 0 {
+		t.Errorf("GetLight far from torch = %f, want 0", got)
+	}
+}
+
+func TestBlocksLight(t *testing.T) {
+	tests := []struct {
+		name string
+		tile int
+		want bool
+	}{
+		{"empty", 0, false},
+		{"wall", bsp.TileWall, true},
+		{"floor", bsp.TileFloor, false},
+		{"door", bsp.TileDoor, true},
+		{"secret", bsp.TileSecret, true},
+		{"genre wall", 12, true},
+		{"genre floor", 22, false},
+		{"window", 31, false},
+		{"portal", 40, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blocksLight(tt.tile); got != tt.want {
+				t.Errorf("blocksLight(%d) = %v, want %v", tt.tile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClampToRadius(t *testing.T) {
+	tests := []struct {
+		name         string
+		x, y         float64
+		wantX, wantY float64
+	}{
+		{"inside", 12, 10, 12, 10},
+		{"on edge", 18, 10, 18, 10},
+		{"outside east", 30, 10, 18, 10},
+		{"outside diagonal", 20, 20, 10 + 8/math.Sqrt2, 10 + 8/math.Sqrt2},
+		{"at origin", 10, 10, 10, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := clampToRadius(10, 10, tt.x, tt.y, 8)
+			if math.Abs(x-tt.wantX) > 1e-9 || math.Abs(y-tt.wantY) > 1e-9 {
+				t.Errorf("clampToRadius(%v, %v) = (%v, %v), want (%v, %v)", tt.x, tt.y, x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestCollectFrameLightsIncludesImpulses(t *testing.T) {
+	g := &Game{impulseLights: lighting.NewImpulsePool(maxImpulseLights, maxImpulsesPerFrame)}
+	g.impulseLights.EmitLightImpulse(3, 4, barrelBlastLightRadius, barrelBlastLightIntensity, barrelBlastLightColor, barrelBlastLightDuration)
+
+	lights := g.collectFrameLights(nil)
+	if len(lights) != 1 || lights[0].X != 3 || lights[0].Y != 4 {
+		t.Fatalf("collectFrameLights() = %v, want the barrel flash", lights)
+	}
+
+	g.impulseLights.Update(barrelBlastLightDuration)
+	if lights = g.collectFrameLights(lights[:0]); len(lights) != 0 {
+		t.Errorf("collectFrameLights() after expiry = %v, want none", lights)
+	}
+}
+
+func TestSpawnMeleeArc(t *testing.T) {
+	cam := camera.NewCamera(66)
+	cam.X, cam.Y = 5, 5
+	g := &Game{camera: cam, trailSystem: particle.NewTrailSystem(maxTrails, maxTrailPoints)}
+
+	g.spawnMeleeArc()
+	if n := g.trailSystem.Len(); n != 1 {
+		t.Fatalf("trails after swing = %d, want 1", n)
+	}
+
+	g.trailSystem.Update(meleeArcTrail.Fade + meleeArcSweepTime)
+	if n := g.trailSystem.Len(); n != 0 {
+		t.Errorf("trails after fade = %d, want 0", n)
+	}
+}
+
+func TestUpdateWindFieldFollowsWeather(t *testing.T) {
+	g := &Game{
+		weatherSystem: weather.NewSystem(100, 1, "fantasy"),
+		windField:     particle.NewVectorField(16, 16, windCellSize),
+	}
+	g.weatherSystem.GetWeatherSystem().SetWind(3, 4)
+
+	g.updateWindField(0.1)
+
+	ax, ay := g.windField.At(8, 8)
+	if ax <= 0 || ay <= 0 || math.Abs(ax/ay-0.75) > 1e-9 {
+		t.Errorf("wind at (8, 8) = (%v, %v), want along (3, 4)", ax, ay)
+	}
+	if l := math.Hypot(ax, ay); l > windStrength*(1+windGustAmount)+1e-9 {
+		t.Errorf("wind strength = %v, want at most %v", l, windStrength*(1+windGustAmount))
+	}
+
+	g.weatherSystem.GetWeatherSystem().SetWind(0, 0)
+	g.updateWindField(0.1)
+	if ax, ay := g.windField.At(8, 8); ax != 0 || ay != 0 {
+		t.Errorf("calm wind = (%v, %v), want none", ax, ay)
+	}
+}
+
+func TestUpdateAIAgentsWalksAroundWalls(t *testing.T) {
+	tiles := [][]int{
+		{1, 1, 1, 1, 1, 1, 1},
+		{1, 2, 2, 1, 2, 2, 1},
+		{1, 2, 2, 1, 2, 2, 1},
+		{1, 2, 2, 2, 2, 2, 1},
+		{1, 1, 1, 1, 1, 1, 1},
+	}
+	agent := &ai.Agent{X: 1.5, Y: 1.5, DirX: -1, Speed: 0.05, AlertRadius: 10, HearRadius: 15, Health: 10, MaxHealth: 10, AttackRange: 1, Cooldown: 1 << 20}
+	pos := &engine.Position{X: agent.X, Y: agent.Y}
+	cam := camera.NewCamera(66)
+	cam.X, cam.Y = 5.5, 1.5
+	behaviors := ai.NewNodeRegistry()
+	g := &Game{
+		camera:     cam,
+		currentMap: tiles,
+		aiAgents:   []*ai.Agent{agent},
+		agentPos:   []*engine.Position{pos},
+		agentTrees: []*ai.BehaviorTree{behaviors.NewRoleTree(ai.RoleTank)},
+		behaviors:  behaviors,
+		navGrid:    ai.NewNavGrid(tiles, ai.DefaultNavCosts),
+		perception: ai.NewPerception(),
+	}
+
+	for i := 0; i < 400; i++ {
+		g.perception.EmitSound(cam.X, cam.Y, gunfireLoudness) // The player keeps firing
+		g.updateAIAgents()
+		if tiles[int(agent.Y)][int(agent.X)] == 1 {
+			t.Fatalf("agent walked into a wall at (%v, %v)", agent.X, agent.Y)
+		}
+	}
+	if d := math.Hypot(cam.X-agent.X, cam.Y-agent.Y); d > agent.AttackRange+agent.Speed {
+		t.Errorf("agent stopped %v from the player, want within attack range %v", d, agent.AttackRange)
+	}
+	if agent.State != ai.StateAttack {
+		t.Errorf("agent state = %v, want attacking", agent.State)
+	}
+	if pos.X != agent.X || pos.Y != agent.Y {
+		t.Errorf("entity at (%v, %v), want synced to agent (%v, %v)", pos.X, pos.Y, agent.X, agent.Y)
+	}
+}
+
+func TestIsSkyTile(t *testing.T) {
+	g := &Game{skyMask: [][]bool{
+		{false, true},
+		{false, false},
+	}}
+	tests := []struct {
+		x, y int
+		want bool
+	}{
+		{1, 0, true},
+		{0, 0, false},
+		{1, 1, false},
+		{-1, 0, false},
+		{2, 0, false},
+		{0, 5, false},
+	}
+	for _, tt := range tests {
+		if got := g.isSkyTile(tt.x, tt.y); got != tt.want {
+			t.Errorf("isSkyTile(%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+	if !g.hasSky() {
+		t.Error("hasSky() = false with an open tile")
+	}
+	if (&Game{skyMask: [][]bool{{false}}}).hasSky() {
+		t.Error("hasSky() = true for an all-indoor level")
+	}
+}
+
+func TestCompanionLanternsFollowMembers(t *testing.T) {
+	g := &Game{
+		world:           engine.NewWorld(),
+		lightingSystem:  lighting.NewLightingSystem("fantasy"),
+		squadCompanions: squad.NewSquad(3),
+	}
+	g.squadCompanions.AddMember("c1", "grunt", "pistol", 2, 3, 1)
+	g.squadCompanions.AddMember("c2", "medic", "pistol", 5, 5, 1)
+	g.attachCompanionLanterns()
+
+	if lights := g.lightingSystem.CollectLights(g.world); len(lights) != 2 {
+		t.Fatalf("CollectLights() = %d lights, want 2 lanterns", len(lights))
+	}
+
+	m := g.squadCompanions.GetMembers()[0]
+	m.X, m.Y = 7, 8
+	g.syncCompanionEntities()
+	g.lightingSystem.Update(g.world)
+	lantern := g.lightingSystem.AttachedLight(g.world, g.companionEntities["c1"])
+	if lantern == nil || lantern.X != 7 || lantern.Y != 8 {
+		t.Errorf("lantern = %+v, want at (7, 8)", lantern)
+	}
+
+	g.squadCompanions.RemoveMember("c2")
+	g.syncCompanionEntities()
+	if _, ok := g.companionEntities["c2"]; ok {
+		t.Error("entity of removed member was kept")
+	}
+	if lights := g.lightingSystem.CollectLights(g.world); len(lights) != 1 {
+		t.Errorf("CollectLights() = %d lights after removal, want 1", len(lights))
+	}
+}
+
+func TestBlocksParticles(t *testing.T) {
+	g := &Game{currentMap: [][]int{
+		{bsp.TileWall, bsp.TileFloor, 31},
+	}}
+	tests := []struct {
+		name string
+		x, y int
+		want bool
+	}{
+		{"wall", 0, 0, true},
+		{"floor", 1, 0, false},
+		{"window", 2, 0, true},
+		{"outside", 3, 0, true},
+		{"negative", -1, 0, true},
+	}
+	for _, tt := range tests {
+		if got := g.blocksParticles(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: blocksParticles(%d, %d) = %v, want %v", tt.name, tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	if particleCeiling("postapoc") != 0 || particleCeiling("fantasy") <= 0 {
+		t.Error("postapoc should be open sky and fantasy roofed")
+	}
+}
@@ -47,6 +47,33 @@ func TestMinigameVisualComponents(t *testing.T) {
 				g.minigameType = "code"
 			},
 		},
+		{
+			name:         "Wire visual rendering",
+			minigameType: "wire",
+			setupGame: func(g *Game) {
+				wireGame := minigame.NewWireMatchGame(3, 12345)
+				wireGame.Connect(0, wireGame.Terminal(0))
+				wireGame.Held = 1
+				g.activeMinigame = wireGame
+				g.minigameType = "wire"
+			},
+		},
+		{
+			name:         "Frequency visual rendering",
+			minigameType: "frequency",
+			setupGame: func(g *Game) {
+				g.activeMinigame = minigame.NewFrequencyTuneGame(1, 12345)
+				g.minigameType = "frequency"
+			},
+		},
+		{
+			name:         "Rhythm visual rendering",
+			minigameType: "rhythm",
+			setupGame: func(g *Game) {
+				g.activeMinigame = minigame.NewRhythmLockGame(1, 12345)
+				g.minigameType = "rhythm"
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -71,6 +98,12 @@ func TestMinigameVisualComponents(t *testing.T) {
 				g.drawCircuitGame(screen, centerX, centerY)
 			case "code":
 				g.drawCodeGame(screen, centerX, centerY)
+			case "wire":
+				g.drawWireGame(screen, centerX, centerY)
+			case "frequency":
+				g.drawFrequencyGame(screen, centerX, centerY)
+			case "rhythm":
+				g.drawRhythmGame(screen, centerX, centerY)
 			}
 
 			// Verify screen was modified (not empty)
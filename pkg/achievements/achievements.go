@@ -96,7 +96,9 @@ type AchievementManager struct {
 	mu           sync.RWMutex
 }
 
-// NewAchievementManager creates a new achievement manager with default achievements
+// NewAchievementManager creates a new achievement manager with default achievements.
+// An empty savePath keeps unlocks in memory, for callers persisting them
+// elsewhere through GetUnlocked and Restore
 func NewAchievementManager(savePath string) (*AchievementManager, error) {
 	am := &AchievementManager{
 		achievements: make(map[string]Achievement),
@@ -108,6 +110,9 @@ func NewAchievementManager(savePath string) (*AchievementManager, error) {
 	am.registerDefaultAchievements()
 
 	// Load existing unlocks
+	if savePath == "" {
+		return am, nil
+	}
 	if err := am.Load(); err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to load achievements: %w", err)
 	}
@@ -330,7 +335,7 @@ func (am *AchievementManager) CheckUnlocks(stats *PlayerStats) ([]Achievement, e
 	}
 
 	// Persist unlocks (unlock mutex before saving to avoid deadlock)
-	if len(newlyUnlocked) > 0 {
+	if len(newlyUnlocked) > 0 && am.savePath != "" {
 		am.mu.Unlock()
 		err := am.Save()
 		am.mu.Lock()
@@ -410,6 +415,18 @@ func (am *AchievementManager) GetUnlocked() []UnlockedAchievement {
 	return result
 }
 
+// Restore replaces the unlocked achievements with ones persisted
+// elsewhere, such as in the player's profile
+func (am *AchievementManager) Restore(unlocks []UnlockedAchievement) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	am.unlocked = make(map[string]UnlockedAchievement, len(unlocks))
+	for _, unlock := range unlocks {
+		am.unlocked[unlock.ID] = unlock
+	}
+}
+
 // GetAll returns all registered achievements
 func (am *AchievementManager) GetAll() []Achievement {
 	am.mu.RLock()
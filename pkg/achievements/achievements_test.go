@@ -495,6 +495,36 @@ func TestGetUnlocked(t *testing.T) {
 	}
 }
 
+func TestRestore(t *testing.T) {
+	am, err := NewAchievementManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := am.CheckUnlocks(&PlayerStats{Kills: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	am.Restore([]UnlockedAchievement{{ID: "centurion", UnlockedAt: at}})
+	if am.IsUnlocked("first_blood") {
+		t.Error("Restore() kept an achievement it wasn't given")
+	}
+	unlocked := am.GetUnlocked()
+	if len(unlocked) != 1 || unlocked[0].ID != "centurion" || !unlocked[0].UnlockedAt.Equal(at) {
+		t.Errorf("GetUnlocked() = %+v", unlocked)
+	}
+
+	newly, err := am.CheckUnlocks(&PlayerStats{Kills: 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range newly {
+		if a.ID == "centurion" {
+			t.Error("restored achievement unlocked again")
+		}
+	}
+}
+
 func TestGetAll(t *testing.T) {
 	am, err := NewAchievementManager(filepath.Join(t.TempDir(), "test.json"))
 	if err != nil {
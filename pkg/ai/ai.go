@@ -4,6 +4,7 @@ package ai
 import (
 	"math"
 
+	"github.com/opd-ai/violence/pkg/combat"
 	"github.com/opd-ai/violence/pkg/rng"
 )
 
@@ -135,6 +136,17 @@ const (
 	StateAttack
 )
 
+// stateNames are the names of the states, indexed by State.
+var stateNames = [...]string{"idle", "patrol", "alert", "chase", "strafe", "cover", "retreat", "attack"}
+
+// String returns the state name.
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return "unknown"
+	}
+	return stateNames[s]
+}
+
 // Agent represents an AI-controlled enemy entity.
 type Agent struct {
 	ID                 string
@@ -151,9 +163,36 @@ type Agent struct {
 	Cooldown           int
 	StrafeDirection    float64
 	ArchetypeID        string
+	Weapon             string
 	Damage             float64
 	AttackRange        float64
 	RetreatHealthRatio float64
+	Role               EnemyRole
+	Blackboard         Blackboard
+	Resistances        combat.Resistances // Damage each type does is scaled by
+	Mass               float64            // Body weight against knockback; 0 counts as 1
+
+	// ViewAngle is the half-angle of the vision cone in radians; zero
+	// means DefaultViewAngle.
+	ViewAngle float64
+	// SquadID groups agents that alert each other; empty for none.
+	SquadID string
+	// Awareness, Alert, SeesPlayer and the player's last known position
+	// are kept by Perception.
+	Awareness              float64
+	Alert                  AlertLevel
+	SeesPlayer             bool
+	LastKnownX, LastKnownY float64
+	alertTimer             int
+	heard                  bool
+	staggerTicks           int
+
+	// Path is the route being followed by MoveToward, from PathIndex on.
+	Path         []Waypoint
+	PathIndex    int
+	pathGoal     Coord
+	pathVersion  uint64
+	pathComplete bool
 }
 
 // Waypoint represents a patrol destination.
@@ -171,6 +210,13 @@ type Context struct {
 	LastShotTick int
 	CurrentTick  int
 	RNG          *rng.RNG
+	// Nav plans routes around walls and hazards; without it agents take
+	// single path steps or walk straight at their targets.
+	Nav *NavGrid
+	// Perception, once updated for the tick, decides what agents see and
+	// hear; without it agents see the player along any clear line and hear
+	// the last shot within HearRadius.
+	Perception *Perception
 	// Extension holds an optional specialized context (e.g. *TelegraphAttackContext)
 	// that embeds this Context, allowing type-safe access to extra fields in actions.
 	Extension interface{}
@@ -226,10 +272,16 @@ func (bt *BehaviorTree) Tick(agent *Agent, ctx *Context) {
 // Condition functions
 
 func checkLowHealth(agent *Agent, ctx *Context) bool {
+	if ctx.Perception != nil {
+		return agent.Alert == AlertFlee
+	}
 	return agent.Health < agent.MaxHealth*agent.RetreatHealthRatio
 }
 
 func checkCanSeePlayer(agent *Agent, ctx *Context) bool {
+	if ctx.Perception != nil {
+		return agent.SeesPlayer && agent.Alert == AlertCombat
+	}
 	return lineOfSight(agent.X, agent.Y, ctx.PlayerX, ctx.PlayerY, ctx.TileMap)
 }
 
@@ -241,6 +293,10 @@ func checkInAttackRange(agent *Agent, ctx *Context) bool {
 }
 
 func checkHeardGunshot(agent *Agent, ctx *Context) bool {
+	if ctx.Perception != nil {
+		investigating := agent.Alert == AlertSuspicious || agent.Alert == AlertSearching
+		return investigating && agent.Blackboard.Bool(KeyInvestigate)
+	}
 	if ctx.CurrentTick-ctx.LastShotTick > 60 {
 		return false
 	}
@@ -254,9 +310,13 @@ func checkHeardGunshot(agent *Agent, ctx *Context) bool {
 
 func actionRetreat(agent *Agent, ctx *Context) NodeStatus {
 	agent.State = StateRetreat
-	// Move away from player
-	dx := agent.X - ctx.PlayerX
-	dy := agent.Y - ctx.PlayerY
+	// Move away from player, or where they were last seen
+	fromX, fromY := ctx.PlayerX, ctx.PlayerY
+	if ctx.Perception != nil {
+		fromX, fromY = agent.LastKnownX, agent.LastKnownY
+	}
+	dx := agent.X - fromX
+	dy := agent.Y - fromY
 	dist := math.Sqrt(dx*dx + dy*dy)
 	if dist < 0.01 {
 		return StatusRunning
@@ -286,6 +346,7 @@ func actionAttack(agent *Agent, ctx *Context) NodeStatus {
 	}
 	// Fire weapon (cooldown 30 ticks)
 	agent.Cooldown = 30
+	agent.Blackboard.Set(KeyAttacked, true)
 	return StatusSuccess
 }
 
@@ -322,6 +383,10 @@ func actionStrafe(agent *Agent, ctx *Context) NodeStatus {
 
 func actionChase(agent *Agent, ctx *Context) NodeStatus {
 	agent.State = StateChase
+	if ctx.Nav != nil {
+		agent.MoveToward(ctx.Nav, ctx.PlayerX, ctx.PlayerY, agent.Speed)
+		return StatusRunning
+	}
 	// Use A* pathfinding to navigate toward player
 	path := FindPath(agent.X, agent.Y, ctx.PlayerX, ctx.PlayerY, ctx.TileMap)
 	if len(path) > 1 {
@@ -346,13 +411,22 @@ func actionChase(agent *Agent, ctx *Context) NodeStatus {
 
 func actionAlert(agent *Agent, ctx *Context) NodeStatus {
 	agent.State = StateAlert
-	// Move toward last gunshot position
-	dx := ctx.LastShotX - agent.X
-	dy := ctx.LastShotY - agent.Y
+	// Move toward what perception noticed, or the last gunshot position
+	targetX, targetY := ctx.LastShotX, ctx.LastShotY
+	if ctx.Perception != nil {
+		targetX, targetY = agent.Blackboard.Float(KeyInvestigateX), agent.Blackboard.Float(KeyInvestigateY)
+	}
+	dx := targetX - agent.X
+	dy := targetY - agent.Y
 	dist := math.Sqrt(dx*dx + dy*dy)
 	if dist < 0.5 {
+		agent.Blackboard.Delete(KeyInvestigate)
 		return StatusSuccess
 	}
+	if ctx.Nav != nil {
+		agent.MoveToward(ctx.Nav, targetX, targetY, agent.Speed)
+		return StatusRunning
+	}
 	if dist > 0.01 {
 		agent.DirX = dx / dist
 		agent.DirY = dy / dist
@@ -380,6 +454,10 @@ func actionPatrol(agent *Agent, ctx *Context) NodeStatus {
 		agent.PatrolIndex = (agent.PatrolIndex + 1) % len(agent.PatrolWaypoints)
 		return StatusRunning
 	}
+	if ctx.Nav != nil {
+		agent.MoveToward(ctx.Nav, wp.X, wp.Y, agent.Speed*0.5)
+		return StatusRunning
+	}
 	if dist > 0.01 {
 		agent.DirX = dx / dist
 		agent.DirY = dy / dist
@@ -417,13 +495,19 @@ func lineOfSight(x1, y1, x2, y2 float64, tileMap [][]int) bool {
 		if mapY < 0 || mapY >= len(tileMap) || mapX < 0 || mapX >= len(tileMap[0]) {
 			return false
 		}
-		if tileMap[mapY][mapX] == 1 {
+		if blocksSight(tileMap[mapY][mapX]) {
 			return false
 		}
 	}
 	return true
 }
 
+// blocksSight reports whether a tile blocks line of sight: walls, genre
+// walls, doors and secret walls.
+func blocksSight(tile int) bool {
+	return tile == 1 || tile == 3 || tile == 4 || (tile >= 10 && tile <= 19)
+}
+
 // isWalkable checks if a position is on a walkable tile.
 func isWalkable(x, y float64, tileMap [][]int) bool {
 	if tileMap == nil || len(tileMap) == 0 || len(tileMap[0]) == 0 {
@@ -435,7 +519,7 @@ func isWalkable(x, y float64, tileMap [][]int) bool {
 		return false
 	}
 	tile := tileMap[mapY][mapX]
-	return tile == 0 || tile == 2
+	return tile == 0 || tile == 2 || (tile >= 20 && tile <= 29)
 }
 
 // pathNode is used for A* pathfinding.
@@ -572,71 +656,6 @@ func reconstructPath(node *pathNode) []Waypoint {
 	return path
 }
 
-// Archetype defines enemy type characteristics.
-type Archetype struct {
-	ID                 string
-	MaxHealth          float64
-	Speed              float64
-	Damage             float64
-	AttackRange        float64
-	AlertRadius        float64
-	HearRadius         float64
-	RetreatHealthRatio float64
-}
-
-var archetypes = map[string]Archetype{
-	"fantasy_guard": {
-		ID:                 "fantasy_guard",
-		MaxHealth:          50,
-		Speed:              0.03,
-		Damage:             10,
-		AttackRange:        8,
-		AlertRadius:        10,
-		HearRadius:         15,
-		RetreatHealthRatio: 0.2,
-	},
-	"scifi_soldier": {
-		ID:                 "scifi_soldier",
-		MaxHealth:          60,
-		Speed:              0.035,
-		Damage:             12,
-		AttackRange:        10,
-		AlertRadius:        12,
-		HearRadius:         18,
-		RetreatHealthRatio: 0.25,
-	},
-	"horror_cultist": {
-		ID:                 "horror_cultist",
-		MaxHealth:          40,
-		Speed:              0.025,
-		Damage:             15,
-		AttackRange:        6,
-		AlertRadius:        8,
-		HearRadius:         20,
-		RetreatHealthRatio: 0.1,
-	},
-	"cyberpunk_drone": {
-		ID:                 "cyberpunk_drone",
-		MaxHealth:          45,
-		Speed:              0.04,
-		Damage:             10,
-		AttackRange:        12,
-		AlertRadius:        15,
-		HearRadius:         12,
-		RetreatHealthRatio: 0.3,
-	},
-	"postapoc_scavenger": {
-		ID:                 "postapoc_scavenger",
-		MaxHealth:          55,
-		Speed:              0.032,
-		Damage:             11,
-		AttackRange:        7,
-		AlertRadius:        9,
-		HearRadius:         16,
-		RetreatHealthRatio: 0.25,
-	},
-}
-
 var currentGenre = "fantasy"
 
 // SetGenre configures AI behaviors for a genre.
@@ -644,27 +663,18 @@ func SetGenre(genreID string) {
 	currentGenre = genreID
 }
 
-// GetArchetype returns the archetype for the current genre.
+// GetArchetype returns the default archetype of the current genre.
 func GetArchetype() Archetype {
-	switch currentGenre {
-	case "fantasy":
-		return archetypes["fantasy_guard"]
-	case "scifi":
-		return archetypes["scifi_soldier"]
-	case "horror":
-		return archetypes["horror_cultist"]
-	case "cyberpunk":
-		return archetypes["cyberpunk_drone"]
-	case "postapoc":
-		return archetypes["postapoc_scavenger"]
-	default:
-		return archetypes["fantasy_guard"]
-	}
-}
-
-// NewAgent creates an agent from archetype.
+	return builtinArchetypes.Default(currentGenre)
+}
+
+// NewAgent creates an agent from the current genre's default archetype.
 func NewAgent(id string, x, y float64) *Agent {
-	arch := GetArchetype()
+	return NewAgentFrom(id, x, y, GetArchetype())
+}
+
+// NewAgentFrom creates an agent with an archetype's stats, role and weapon.
+func NewAgentFrom(id string, x, y float64, arch Archetype) *Agent {
 	return &Agent{
 		ID:                 id,
 		X:                  x,
@@ -676,11 +686,16 @@ func NewAgent(id string, x, y float64) *Agent {
 		Speed:              arch.Speed,
 		AlertRadius:        arch.AlertRadius,
 		HearRadius:         arch.HearRadius,
+		ViewAngle:          arch.ViewAngle,
 		State:              StatePatrol,
 		Damage:             arch.Damage,
 		AttackRange:        arch.AttackRange,
 		RetreatHealthRatio: arch.RetreatHealthRatio,
 		ArchetypeID:        arch.ID,
+		Role:               arch.Role,
+		Weapon:             arch.Weapon,
+		Resistances:        arch.Resistances,
+		Mass:               arch.Mass,
 		StrafeDirection:    1,
 	}
 }
@@ -119,6 +119,7 @@ func TestIsWalkable(t *testing.T) {
 		{1, 1, 1},
 		{1, 0, 1},
 		{1, 2, 1},
+		{1, 21, 1},
 	}
 	tests := []struct {
 		name     string
@@ -127,6 +128,7 @@ func TestIsWalkable(t *testing.T) {
 	}{
 		{"empty tile", 1.5, 1.5, true},
 		{"floor tile", 1.5, 2.5, true},
+		{"genre floor tile", 1.5, 3.5, true},
 		{"wall tile", 0.5, 0.5, false},
 		{"out of bounds", -1, -1, false},
 		{"out of bounds high", 10, 10, false},
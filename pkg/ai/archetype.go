@@ -0,0 +1,283 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/opd-ai/violence/pkg/combat"
+)
+
+// Archetype errors.
+var (
+	ErrUnknownArchetype = errors.New("unknown enemy archetype")
+	ErrInvalidArchetype = errors.New("invalid enemy archetype")
+)
+
+// LootEntry is an item an archetype drops with an independent Chance [0-1].
+type LootEntry struct {
+	Item   string  `json:"item"`
+	Name   string  `json:"name"`
+	Chance float64 `json:"chance"`
+}
+
+// Archetype defines an enemy type: its stats, role, weapon, perception,
+// loot and the sprite it is drawn with. Archetypes are data, e.g. in a
+// mod's enemies.json.
+type Archetype struct {
+	ID                 string      `json:"id"`
+	Genre              string      `json:"genre"`
+	Role               EnemyRole   `json:"role"`
+	Weapon             string      `json:"weapon"`
+	MaxHealth          float64     `json:"max_health"`
+	Speed              float64     `json:"speed"` // Tiles per tick
+	Damage             float64     `json:"damage"`
+	AttackRange        float64     `json:"attack_range"`
+	AlertRadius        float64     `json:"alert_radius"`
+	HearRadius         float64     `json:"hear_radius"`
+	ViewAngle          float64     `json:"view_angle,omitempty"` // Vision half-angle in radians; 0 for DefaultViewAngle
+	RetreatHealthRatio float64     `json:"retreat_health_ratio"`
+	Loot               []LootEntry `json:"loot,omitempty"`
+	Sprite             string      `json:"sprite"`      // Enemy sprite subtype, e.g. "humanoid" or "flying"
+	SpriteSeed         int64       `json:"sprite_seed"` // Base seed of the archetype's sprite variations
+
+	// Resistances scale the damage each type does, e.g. {"fire": -0.5}
+	// for a weakness to fire.
+	Resistances combat.Resistances `json:"resistances,omitempty"`
+	// Mass resists knockback and staggering; 0 counts as 1.
+	Mass float64 `json:"mass,omitempty"`
+}
+
+// Validate checks that agents can be spawned from an archetype.
+func (a *Archetype) Validate() error {
+	switch {
+	case a.ID == "":
+		return fmt.Errorf("%w: missing id", ErrInvalidArchetype)
+	case a.Genre == "":
+		return fmt.Errorf("%w: %s: missing genre", ErrInvalidArchetype, a.ID)
+	case a.MaxHealth <= 0:
+		return fmt.Errorf("%w: %s: max_health must be positive", ErrInvalidArchetype, a.ID)
+	case a.Speed <= 0:
+		return fmt.Errorf("%w: %s: speed must be positive", ErrInvalidArchetype, a.ID)
+	case a.Damage < 0:
+		return fmt.Errorf("%w: %s: damage must not be negative", ErrInvalidArchetype, a.ID)
+	case a.AttackRange <= 0:
+		return fmt.Errorf("%w: %s: attack_range must be positive", ErrInvalidArchetype, a.ID)
+	case a.AlertRadius < 0 || a.HearRadius < 0:
+		return fmt.Errorf("%w: %s: perception ranges must not be negative", ErrInvalidArchetype, a.ID)
+	case a.ViewAngle < 0 || a.ViewAngle > math.Pi:
+		return fmt.Errorf("%w: %s: view_angle must be within [0, pi]", ErrInvalidArchetype, a.ID)
+	case a.RetreatHealthRatio < 0 || a.RetreatHealthRatio > 1:
+		return fmt.Errorf("%w: %s: retreat_health_ratio must be within [0, 1]", ErrInvalidArchetype, a.ID)
+	case a.Mass < 0:
+		return fmt.Errorf("%w: %s: mass must not be negative", ErrInvalidArchetype, a.ID)
+	}
+	for t, r := range a.Resistances {
+		if r < -1 || r > 1 {
+			return fmt.Errorf("%w: %s: %s resistance must be within [-1, 1]", ErrInvalidArchetype, a.ID, t)
+		}
+	}
+	for _, l := range a.Loot {
+		if l.Item == "" || l.Chance < 0 || l.Chance > 1 {
+			return fmt.Errorf("%w: %s: loot needs an item and a chance within [0, 1]", ErrInvalidArchetype, a.ID)
+		}
+	}
+	return nil
+}
+
+// genreDefaults are the archetypes of plain genre enemies, used by NewAgent.
+var genreDefaults = map[string]string{
+	"fantasy":   "fantasy_guard",
+	"scifi":     "scifi_soldier",
+	"horror":    "horror_cultist",
+	"cyberpunk": "cyberpunk_drone",
+	"postapoc":  "postapoc_scavenger",
+}
+
+// Shared loot tables of the built-in archetypes.
+var (
+	lootMedic   = []LootEntry{{Item: "medkit", Name: "Medkit", Chance: 0.5}}
+	lootHeavy   = []LootEntry{{Item: "grenade", Name: "Grenade", Chance: 0.2}, {Item: "medkit", Name: "Medkit", Chance: 0.1}}
+	lootShooter = []LootEntry{{Item: "grenade", Name: "Grenade", Chance: 0.15}}
+	lootTrapper = []LootEntry{{Item: "proximity_mine", Name: "Proximity Mine", Chance: 0.2}}
+	lootRunner  = []LootEntry{{Item: "medkit", Name: "Medkit", Chance: 0.15}}
+)
+
+// Shared resistances of the built-in archetypes.
+var (
+	resistArmored = combat.Resistances{combat.DamagePhysical: 0.25, combat.DamageEnergy: -0.25}
+	resistWarded  = combat.Resistances{combat.DamageArcane: 0.5}
+	resistFlesh   = combat.Resistances{combat.DamagePhysical: 0.2, combat.DamageFire: -0.5}
+	resistMachine = combat.Resistances{combat.DamageToxic: 1, combat.DamageEnergy: -0.5}
+	resistHardy   = combat.Resistances{combat.DamageToxic: 0.5}
+)
+
+// builtinArchetypeList returns the built-in archetypes: one per role for
+// each genre.
+func builtinArchetypeList() []Archetype {
+	return []Archetype{
+		{ID: "fantasy_guard", Genre: "fantasy", Role: RoleTank, Weapon: "sword", MaxHealth: 50, Speed: 0.03, Damage: 10, AttackRange: 8, AlertRadius: 10, HearRadius: 15, RetreatHealthRatio: 0.2, Loot: lootHeavy, Resistances: resistArmored, Mass: 2, Sprite: "tank", SpriteSeed: 1100},
+		{ID: "fantasy_archer", Genre: "fantasy", Role: RoleRanged, Weapon: "bow", MaxHealth: 35, Speed: 0.03, Damage: 9, AttackRange: 12, AlertRadius: 12, HearRadius: 15, RetreatHealthRatio: 0.3, Loot: lootShooter, Sprite: "ranged", SpriteSeed: 1200},
+		{ID: "fantasy_priest", Genre: "fantasy", Role: RoleHealer, Weapon: "staff", MaxHealth: 40, Speed: 0.028, Damage: 6, AttackRange: 8, AlertRadius: 10, HearRadius: 14, RetreatHealthRatio: 0.4, Loot: lootMedic, Resistances: resistWarded, Sprite: "healer", SpriteSeed: 1300},
+		{ID: "fantasy_rogue", Genre: "fantasy", Role: RoleAmbusher, Weapon: "dagger", MaxHealth: 30, Speed: 0.04, Damage: 16, AttackRange: 3, AlertRadius: 8, HearRadius: 18, RetreatHealthRatio: 0.2, Loot: lootTrapper, Sprite: "ambusher", SpriteSeed: 1400},
+		{ID: "fantasy_outrider", Genre: "fantasy", Role: RoleScout, Weapon: "spear", MaxHealth: 35, Speed: 0.045, Damage: 8, AttackRange: 6, AlertRadius: 14, HearRadius: 16, ViewAngle: math.Pi / 2.5, RetreatHealthRatio: 0.3, Loot: lootRunner, Sprite: "scout", SpriteSeed: 1500},
+
+		{ID: "scifi_soldier", Genre: "scifi", Role: RoleRanged, Weapon: "rifle", MaxHealth: 60, Speed: 0.035, Damage: 12, AttackRange: 10, AlertRadius: 12, HearRadius: 18, RetreatHealthRatio: 0.25, Loot: lootShooter, Sprite: "ranged", SpriteSeed: 2100},
+		{ID: "scifi_heavy", Genre: "scifi", Role: RoleTank, Weapon: "minigun", MaxHealth: 90, Speed: 0.028, Damage: 14, AttackRange: 8, AlertRadius: 10, HearRadius: 16, RetreatHealthRatio: 0.1, Loot: lootHeavy, Resistances: resistArmored, Mass: 2.5, Sprite: "tank", SpriteSeed: 2200},
+		{ID: "scifi_medic", Genre: "scifi", Role: RoleHealer, Weapon: "pistol", MaxHealth: 45, Speed: 0.035, Damage: 7, AttackRange: 9, AlertRadius: 12, HearRadius: 18, RetreatHealthRatio: 0.4, Loot: lootMedic, Sprite: "healer", SpriteSeed: 2300},
+		{ID: "scifi_stalker", Genre: "scifi", Role: RoleAmbusher, Weapon: "blade", MaxHealth: 40, Speed: 0.042, Damage: 18, AttackRange: 3, AlertRadius: 9, HearRadius: 20, RetreatHealthRatio: 0.2, Loot: lootTrapper, Sprite: "ambusher", SpriteSeed: 2400},
+		{ID: "scifi_recon", Genre: "scifi", Role: RoleScout, Weapon: "smg", MaxHealth: 40, Speed: 0.048, Damage: 9, AttackRange: 8, AlertRadius: 16, HearRadius: 20, ViewAngle: math.Pi / 2.5, RetreatHealthRatio: 0.3, Loot: lootRunner, Sprite: "scout", SpriteSeed: 2500},
+
+		{ID: "horror_cultist", Genre: "horror", Role: RoleAmbusher, Weapon: "ritual_knife", MaxHealth: 40, Speed: 0.025, Damage: 15, AttackRange: 6, AlertRadius: 8, HearRadius: 20, RetreatHealthRatio: 0.1, Loot: lootTrapper, Sprite: "ambusher", SpriteSeed: 3100},
+		{ID: "horror_brute", Genre: "horror", Role: RoleTank, Weapon: "cleaver", MaxHealth: 80, Speed: 0.022, Damage: 18, AttackRange: 4, AlertRadius: 7, HearRadius: 18, RetreatHealthRatio: 0.05, Loot: lootHeavy, Resistances: resistFlesh, Mass: 2.5, Sprite: "tank", SpriteSeed: 3200},
+		{ID: "horror_zealot", Genre: "horror", Role: RoleRanged, Weapon: "crossbow", MaxHealth: 35, Speed: 0.025, Damage: 10, AttackRange: 10, AlertRadius: 8, HearRadius: 18, RetreatHealthRatio: 0.2, Loot: lootShooter, Sprite: "ranged", SpriteSeed: 3300},
+		{ID: "horror_priest", Genre: "horror", Role: RoleHealer, Weapon: "censer", MaxHealth: 40, Speed: 0.024, Damage: 6, AttackRange: 7, AlertRadius: 8, HearRadius: 20, RetreatHealthRatio: 0.3, Loot: lootMedic, Resistances: resistWarded, Sprite: "healer", SpriteSeed: 3400},
+		{ID: "horror_crawler", Genre: "horror", Role: RoleScout, Weapon: "claws", MaxHealth: 25, Speed: 0.05, Damage: 8, AttackRange: 2, AlertRadius: 10, HearRadius: 24, RetreatHealthRatio: 0.1, Loot: lootRunner, Mass: 0.6, Sprite: "insect", SpriteSeed: 3500},
+
+		{ID: "cyberpunk_drone", Genre: "cyberpunk", Role: RoleScout, Weapon: "taser", MaxHealth: 45, Speed: 0.04, Damage: 10, AttackRange: 12, AlertRadius: 15, HearRadius: 12, ViewAngle: math.Pi / 2, RetreatHealthRatio: 0.3, Loot: lootRunner, Resistances: resistMachine, Mass: 0.7, Sprite: "flying", SpriteSeed: 4100},
+		{ID: "cyberpunk_enforcer", Genre: "cyberpunk", Role: RoleTank, Weapon: "shotgun", MaxHealth: 85, Speed: 0.03, Damage: 15, AttackRange: 6, AlertRadius: 12, HearRadius: 14, RetreatHealthRatio: 0.15, Loot: lootHeavy, Resistances: resistArmored, Mass: 2, Sprite: "tank", SpriteSeed: 4200},
+		{ID: "cyberpunk_gunner", Genre: "cyberpunk", Role: RoleRanged, Weapon: "smart_rifle", MaxHealth: 50, Speed: 0.034, Damage: 12, AttackRange: 13, AlertRadius: 14, HearRadius: 14, RetreatHealthRatio: 0.3, Loot: lootShooter, Sprite: "ranged", SpriteSeed: 4300},
+		{ID: "cyberpunk_ripperdoc", Genre: "cyberpunk", Role: RoleHealer, Weapon: "scalpel", MaxHealth: 45, Speed: 0.033, Damage: 7, AttackRange: 8, AlertRadius: 12, HearRadius: 14, RetreatHealthRatio: 0.4, Loot: lootMedic, Sprite: "healer", SpriteSeed: 4400},
+		{ID: "cyberpunk_netrunner", Genre: "cyberpunk", Role: RoleAmbusher, Weapon: "monowire", MaxHealth: 35, Speed: 0.04, Damage: 17, AttackRange: 4, AlertRadius: 10, HearRadius: 16, RetreatHealthRatio: 0.25, Loot: lootTrapper, Sprite: "ambusher", SpriteSeed: 4500},
+
+		{ID: "postapoc_scavenger", Genre: "postapoc", Role: RoleScout, Weapon: "pipe", MaxHealth: 55, Speed: 0.032, Damage: 11, AttackRange: 7, AlertRadius: 9, HearRadius: 16, RetreatHealthRatio: 0.25, Loot: lootRunner, Resistances: resistHardy, Sprite: "scout", SpriteSeed: 5100},
+		{ID: "postapoc_bruiser", Genre: "postapoc", Role: RoleTank, Weapon: "sledgehammer", MaxHealth: 85, Speed: 0.026, Damage: 16, AttackRange: 3, AlertRadius: 8, HearRadius: 14, RetreatHealthRatio: 0.1, Loot: lootHeavy, Resistances: resistHardy, Mass: 2.5, Sprite: "tank", SpriteSeed: 5200},
+		{ID: "postapoc_raider", Genre: "postapoc", Role: RoleRanged, Weapon: "hunting_rifle", MaxHealth: 45, Speed: 0.03, Damage: 11, AttackRange: 12, AlertRadius: 11, HearRadius: 16, RetreatHealthRatio: 0.3, Loot: lootShooter, Sprite: "ranged", SpriteSeed: 5300},
+		{ID: "postapoc_mender", Genre: "postapoc", Role: RoleHealer, Weapon: "revolver", MaxHealth: 45, Speed: 0.03, Damage: 7, AttackRange: 9, AlertRadius: 9, HearRadius: 16, RetreatHealthRatio: 0.4, Loot: lootMedic, Sprite: "healer", SpriteSeed: 5400},
+		{ID: "postapoc_lurker", Genre: "postapoc", Role: RoleAmbusher, Weapon: "machete", MaxHealth: 40, Speed: 0.036, Damage: 15, AttackRange: 3, AlertRadius: 7, HearRadius: 18, RetreatHealthRatio: 0.2, Loot: lootTrapper, Sprite: "ambusher", SpriteSeed: 5500},
+	}
+}
+
+// builtinArchetypes backs GetArchetype and NewAgent.
+var builtinArchetypes = NewArchetypeRegistry()
+
+// ArchetypeRegistry holds enemy archetypes by ID.
+type ArchetypeRegistry struct {
+	archetypes map[string]*Archetype
+}
+
+// NewArchetypeRegistry creates a registry holding the built-in archetypes.
+func NewArchetypeRegistry() *ArchetypeRegistry {
+	r := &ArchetypeRegistry{archetypes: make(map[string]*Archetype)}
+	for _, a := range builtinArchetypeList() {
+		a := a
+		r.archetypes[a.ID] = &a
+	}
+	return r
+}
+
+// Register adds an archetype, replacing any archetype with the same ID.
+func (r *ArchetypeRegistry) Register(a Archetype) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	r.archetypes[a.ID] = &a
+	return nil
+}
+
+// Get returns the archetype with the given ID.
+func (r *ArchetypeRegistry) Get(id string) (Archetype, bool) {
+	a, ok := r.archetypes[id]
+	if !ok {
+		return Archetype{}, false
+	}
+	return *a, true
+}
+
+// IDs returns the registered archetype IDs in sorted order.
+func (r *ArchetypeRegistry) IDs() []string {
+	ids := make([]string, 0, len(r.archetypes))
+	for id := range r.archetypes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Genre returns a genre's archetypes sorted by ID.
+func (r *ArchetypeRegistry) Genre(genreID string) []Archetype {
+	var out []Archetype
+	for _, id := range r.IDs() {
+		if a := r.archetypes[id]; a.Genre == genreID {
+			out = append(out, *a)
+		}
+	}
+	return out
+}
+
+// Default returns a genre's plain enemy: its built-in default, else its
+// first archetype, else the fantasy default.
+func (r *ArchetypeRegistry) Default(genreID string) Archetype {
+	if a, ok := r.archetypes[genreDefaults[genreID]]; ok {
+		return *a
+	}
+	if as := r.Genre(genreID); len(as) > 0 {
+		return as[0]
+	}
+	if a, ok := r.archetypes[genreDefaults["fantasy"]]; ok {
+		return *a
+	}
+	return builtinArchetypeList()[0]
+}
+
+// Pick returns a genre archetype for a role, choosing among several by
+// seed. Roles the genre has no archetype for get the genre default.
+func (r *ArchetypeRegistry) Pick(genreID string, role EnemyRole, seed uint64) Archetype {
+	var candidates []Archetype
+	for _, a := range r.Genre(genreID) {
+		if a.Role == role {
+			candidates = append(candidates, a)
+		}
+	}
+	if len(candidates) == 0 {
+		return r.Default(genreID)
+	}
+	return candidates[seed%uint64(len(candidates))]
+}
+
+// LoadJSON registers the archetypes of a JSON array, e.g. a mod's
+// enemies.json. An entry whose ID is already registered overrides only the
+// fields it sets. Nothing is registered if any archetype is invalid.
+func (r *ArchetypeRegistry) LoadJSON(data []byte) (int, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidArchetype, err)
+	}
+	loaded := make([]Archetype, len(raws))
+	for i, raw := range raws {
+		var head struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &head); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidArchetype, err)
+		}
+		if old, ok := r.archetypes[head.ID]; ok {
+			loaded[i] = *old
+			loaded[i].Loot = append([]LootEntry(nil), old.Loot...)
+			loaded[i].Resistances = maps.Clone(old.Resistances)
+		}
+		if err := json.Unmarshal(raw, &loaded[i]); err != nil {
+			return 0, fmt.Errorf("%w: %s: %v", ErrInvalidArchetype, head.ID, err)
+		}
+		if err := loaded[i].Validate(); err != nil {
+			return 0, err
+		}
+	}
+	for i := range loaded {
+		r.archetypes[loaded[i].ID] = &loaded[i]
+	}
+	return len(loaded), nil
+}
+
+// LoadFile registers the archetypes of a JSON file.
+func (r *ArchetypeRegistry) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return r.LoadJSON(data)
+}
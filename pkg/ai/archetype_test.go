@@ -0,0 +1,185 @@
+package ai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/combat"
+)
+
+func TestBuiltinArchetypes(t *testing.T) {
+	r := NewArchetypeRegistry()
+	for _, genre := range []string{"fantasy", "scifi", "horror", "cyberpunk", "postapoc"} {
+		roles := make(map[EnemyRole]bool)
+		for _, a := range r.Genre(genre) {
+			if err := a.Validate(); err != nil {
+				t.Errorf("builtin %s: %v", a.ID, err)
+			}
+			if a.Sprite == "" || a.Weapon == "" {
+				t.Errorf("builtin %s has no sprite or weapon", a.ID)
+			}
+			roles[a.Role] = true
+		}
+		if len(roles) != len(roleNames) {
+			t.Errorf("%s covers %d roles, want %d", genre, len(roles), len(roleNames))
+		}
+	}
+}
+
+func TestArchetypeRegistry_Default(t *testing.T) {
+	r := NewArchetypeRegistry()
+	tests := []struct {
+		genre string
+		want  string
+	}{
+		{"fantasy", "fantasy_guard"},
+		{"scifi", "scifi_soldier"},
+		{"horror", "horror_cultist"},
+		{"cyberpunk", "cyberpunk_drone"},
+		{"postapoc", "postapoc_scavenger"},
+		{"unknown", "fantasy_guard"},
+	}
+	for _, tt := range tests {
+		if got := r.Default(tt.genre); got.ID != tt.want {
+			t.Errorf("Default(%q) = %s, want %s", tt.genre, got.ID, tt.want)
+		}
+	}
+}
+
+func TestArchetypeRegistry_Pick(t *testing.T) {
+	r := NewArchetypeRegistry()
+	if got := r.Pick("scifi", RoleHealer, 3); got.ID != "scifi_medic" {
+		t.Errorf("Pick(scifi, healer) = %s, want scifi_medic", got.ID)
+	}
+
+	// A second healer is chosen between by seed.
+	if err := r.Register(Archetype{ID: "scifi_chaplain", Genre: "scifi", Role: RoleHealer, MaxHealth: 40, Speed: 0.03, AttackRange: 8}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if a, b := r.Pick("scifi", RoleHealer, 0), r.Pick("scifi", RoleHealer, 1); a.ID == b.ID {
+		t.Errorf("seeds 0 and 1 both picked %s", a.ID)
+	}
+
+	if got := r.Pick("unknown", RoleScout, 0); got.ID != "fantasy_guard" {
+		t.Errorf("Pick for an unknown genre = %s, want the fallback default", got.ID)
+	}
+}
+
+func TestArchetypeRegistry_LoadJSON(t *testing.T) {
+	r := NewArchetypeRegistry()
+	n, err := r.LoadJSON([]byte(`[
+		{"id": "fantasy_guard", "max_health": 120, "loot": [{"item": "grenade", "chance": 1}]},
+		{"id": "fantasy_troll", "genre": "fantasy", "role": "tank", "weapon": "club",
+		 "max_health": 200, "speed": 0.02, "damage": 25, "attack_range": 3, "sprite": "quadruped"}
+	]`))
+	if err != nil || n != 2 {
+		t.Fatalf("LoadJSON = %d, %v; want 2 archetypes", n, err)
+	}
+
+	guard, _ := r.Get("fantasy_guard")
+	if guard.MaxHealth != 120 || guard.Speed != 0.03 || guard.Weapon != "sword" {
+		t.Errorf("guard = %+v, want only max_health overridden", guard)
+	}
+	if len(guard.Loot) != 1 || guard.Loot[0].Item != "grenade" {
+		t.Errorf("guard loot = %+v, want the override", guard.Loot)
+	}
+	if builtin, _ := NewArchetypeRegistry().Get("fantasy_guard"); len(builtin.Loot) != 2 {
+		t.Error("override changed the shared built-in loot table")
+	}
+
+	if _, err := r.LoadJSON([]byte(`[{"id": "fantasy_guard", "resistances": {"fire": 0.5}}]`)); err != nil {
+		t.Fatalf("LoadJSON resistances: %v", err)
+	}
+	guard, _ = r.Get("fantasy_guard")
+	if guard.Resistances[combat.DamageFire] != 0.5 || guard.Resistances[combat.DamagePhysical] != 0.25 {
+		t.Errorf("guard resistances = %v, want fire added to the built-in ones", guard.Resistances)
+	}
+	if builtin, _ := NewArchetypeRegistry().Get("fantasy_guard"); builtin.Resistances[combat.DamageFire] != 0 {
+		t.Error("override changed the shared built-in resistances")
+	}
+
+	troll, ok := r.Get("fantasy_troll")
+	if !ok || troll.Role != RoleTank || troll.Sprite != "quadruped" {
+		t.Errorf("troll = %+v, %v; want a registered quadruped tank", troll, ok)
+	}
+}
+
+func TestArchetypeRegistry_LoadJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not an array", `{"id": "x"}`},
+		{"missing genre", `[{"id": "x", "max_health": 10, "speed": 0.1, "attack_range": 2}]`},
+		{"bad role", `[{"id": "fantasy_guard", "role": "berserker"}]`},
+		{"negative health", `[{"id": "fantasy_guard", "max_health": -5}]`},
+		{"bad loot chance", `[{"id": "fantasy_guard", "loot": [{"item": "medkit", "chance": 2}]}]`},
+		{"wide view", `[{"id": "fantasy_guard", "view_angle": 4}]`},
+		{"bad resistance", `[{"id": "fantasy_guard", "resistances": {"fire": 3}}]`},
+		{"negative mass", `[{"id": "fantasy_guard", "mass": -1}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewArchetypeRegistry()
+			data := `[{"id": "fantasy_guard", "max_health": 99}, ` + tt.data[1:]
+			if tt.data[0] != '[' {
+				data = tt.data
+			}
+			if _, err := r.LoadJSON([]byte(data)); !errors.Is(err, ErrInvalidArchetype) {
+				t.Errorf("err = %v, want ErrInvalidArchetype", err)
+			}
+			if guard, _ := r.Get("fantasy_guard"); guard.MaxHealth != 50 {
+				t.Error("a failed load registered part of the file")
+			}
+		})
+	}
+}
+
+func TestArchetypeRegistry_LoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "enemies.json")
+	data := `[{"id": "horror_ghoul", "genre": "horror", "role": "ambusher", "max_health": 30, "speed": 0.04, "attack_range": 2}]`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewArchetypeRegistry()
+	if n, err := r.LoadFile(path); err != nil || n != 1 {
+		t.Fatalf("LoadFile = %d, %v", n, err)
+	}
+	if _, ok := r.Get("horror_ghoul"); !ok {
+		t.Error("horror_ghoul not registered")
+	}
+	if _, err := r.LoadFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadFile of a missing file succeeded")
+	}
+}
+
+func TestNewAgentFrom(t *testing.T) {
+	arch, _ := NewArchetypeRegistry().Get("cyberpunk_gunner")
+	agent := NewAgentFrom("e1", 3, 4, arch)
+	if agent.MaxHealth != arch.MaxHealth || agent.Health != arch.MaxHealth || agent.Speed != arch.Speed {
+		t.Errorf("agent stats = %v/%v/%v, want the archetype's", agent.Health, agent.MaxHealth, agent.Speed)
+	}
+	if agent.Role != RoleRanged || agent.Weapon != "smart_rifle" || agent.ArchetypeID != "cyberpunk_gunner" {
+		t.Errorf("agent = role %v weapon %q archetype %q", agent.Role, agent.Weapon, agent.ArchetypeID)
+	}
+}
+
+func TestEnemyRoleText(t *testing.T) {
+	for i := range roleNames {
+		role := EnemyRole(i)
+		text, err := role.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%d): %v", i, err)
+		}
+		var got EnemyRole
+		if err := got.UnmarshalText(text); err != nil || got != role {
+			t.Errorf("round trip of %s = %v, %v", text, got, err)
+		}
+	}
+	var r EnemyRole
+	if err := r.UnmarshalText([]byte("berserker")); !errors.Is(err, ErrUnknownRole) {
+		t.Errorf("UnmarshalText(berserker) err = %v, want ErrUnknownRole", err)
+	}
+}
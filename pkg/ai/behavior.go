@@ -0,0 +1,570 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+// Behavior tree errors.
+var (
+	ErrUnknownNode = errors.New("unknown behavior tree node")
+	ErrInvalidTree = errors.New("invalid behavior tree")
+)
+
+// Blackboard is an agent's private memory, shared by the nodes of its tree,
+// e.g. where the player was last seen. The zero value is empty and ready.
+type Blackboard struct {
+	values map[string]interface{}
+}
+
+// Set stores a value under key.
+func (b *Blackboard) Set(key string, v interface{}) {
+	if b.values == nil {
+		b.values = make(map[string]interface{})
+	}
+	b.values[key] = v
+}
+
+// Get returns the value under key.
+func (b *Blackboard) Get(key string) (interface{}, bool) {
+	v, ok := b.values[key]
+	return v, ok
+}
+
+// Float returns the float64 under key, or 0.
+func (b *Blackboard) Float(key string) float64 {
+	v, _ := b.values[key].(float64)
+	return v
+}
+
+// Bool returns the bool under key, or false.
+func (b *Blackboard) Bool(key string) bool {
+	v, _ := b.values[key].(bool)
+	return v
+}
+
+// Delete removes key.
+func (b *Blackboard) Delete(key string) {
+	delete(b.values, key)
+}
+
+// Keys returns the keys set on the blackboard in sorted order.
+func (b *Blackboard) Keys() []string {
+	keys := make([]string, 0, len(b.values))
+	for k := range b.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Blackboard keys written by built-in nodes.
+const (
+	// KeyAttacked is set to true on the tick an agent attacks, for the game
+	// to apply the attack and clear.
+	KeyAttacked = "attacked"
+	// KeyLastSeenX and KeyLastSeenY hold where the player was last seen.
+	KeyLastSeenX = "last_seen_x"
+	KeyLastSeenY = "last_seen_y"
+	// KeySawPlayer is true once the player has been seen.
+	KeySawPlayer = "saw_player"
+)
+
+// ReactiveSelector runs children in order until one succeeds or is running,
+// like Selector, but starts from the first child every tick so higher
+// priority branches can interrupt running ones.
+type ReactiveSelector struct {
+	Children []Node
+}
+
+// NewReactiveSelector creates a reactive selector node.
+func NewReactiveSelector(children ...Node) *ReactiveSelector {
+	return &ReactiveSelector{Children: children}
+}
+
+// Tick executes children in order until one does not fail.
+func (s *ReactiveSelector) Tick(agent *Agent, ctx *Context) NodeStatus {
+	for _, child := range s.Children {
+		if status := child.Tick(agent, ctx); status != StatusFailure {
+			return status
+		}
+	}
+	return StatusFailure
+}
+
+// ReactiveSequence runs children in order until one fails or is running,
+// like Sequence, but starts from the first child every tick so its
+// conditions are checked again while a later action runs.
+type ReactiveSequence struct {
+	Children []Node
+}
+
+// NewReactiveSequence creates a reactive sequence node.
+func NewReactiveSequence(children ...Node) *ReactiveSequence {
+	return &ReactiveSequence{Children: children}
+}
+
+// Tick executes children in order until one does not succeed.
+func (s *ReactiveSequence) Tick(agent *Agent, ctx *Context) NodeStatus {
+	for _, child := range s.Children {
+		if status := child.Tick(agent, ctx); status != StatusSuccess {
+			return status
+		}
+	}
+	return StatusSuccess
+}
+
+// Parallel ticks every child each tick. It fails as soon as one child fails
+// and succeeds once all children have succeeded.
+type Parallel struct {
+	Children []Node
+}
+
+// NewParallel creates a parallel node.
+func NewParallel(children ...Node) *Parallel {
+	return &Parallel{Children: children}
+}
+
+// Tick executes all children.
+func (p *Parallel) Tick(agent *Agent, ctx *Context) NodeStatus {
+	result := StatusSuccess
+	for _, child := range p.Children {
+		switch child.Tick(agent, ctx) {
+		case StatusFailure:
+			return StatusFailure
+		case StatusRunning:
+			result = StatusRunning
+		}
+	}
+	return result
+}
+
+// Inverter swaps its child's success and failure.
+type Inverter struct {
+	Child Node
+}
+
+// NewInverter creates an inverter node.
+func NewInverter(child Node) *Inverter {
+	return &Inverter{Child: child}
+}
+
+// Tick executes the child and inverts its result.
+func (n *Inverter) Tick(agent *Agent, ctx *Context) NodeStatus {
+	switch n.Child.Tick(agent, ctx) {
+	case StatusSuccess:
+		return StatusFailure
+	case StatusFailure:
+		return StatusSuccess
+	default:
+		return StatusRunning
+	}
+}
+
+// Succeeder runs its child and reports success unless it is still running.
+type Succeeder struct {
+	Child Node
+}
+
+// NewSucceeder creates a succeeder node.
+func NewSucceeder(child Node) *Succeeder {
+	return &Succeeder{Child: child}
+}
+
+// Tick executes the child and ignores failure.
+func (n *Succeeder) Tick(agent *Agent, ctx *Context) NodeStatus {
+	if n.Child.Tick(agent, ctx) == StatusRunning {
+		return StatusRunning
+	}
+	return StatusSuccess
+}
+
+// Repeater reruns its child until it has succeeded Times times, failing
+// when the child fails. Times of 0 repeats forever.
+type Repeater struct {
+	Child Node
+	Times int
+	count int
+}
+
+// NewRepeater creates a repeater node.
+func NewRepeater(child Node, times int) *Repeater {
+	return &Repeater{Child: child, Times: times}
+}
+
+// Tick executes the child once.
+func (n *Repeater) Tick(agent *Agent, ctx *Context) NodeStatus {
+	switch n.Child.Tick(agent, ctx) {
+	case StatusFailure:
+		n.count = 0
+		return StatusFailure
+	case StatusSuccess:
+		n.count++
+		if n.Times > 0 && n.count >= n.Times {
+			n.count = 0
+			return StatusSuccess
+		}
+	}
+	return StatusRunning
+}
+
+// Cooldown fails without ticking its child for Ticks ticks after the child
+// succeeds, e.g. to rate-limit a special attack.
+type Cooldown struct {
+	Child Node
+	Ticks int
+	ready int // Tick at which the child may run again
+}
+
+// NewCooldown creates a cooldown node.
+func NewCooldown(child Node, ticks int) *Cooldown {
+	return &Cooldown{Child: child, Ticks: ticks}
+}
+
+// Tick executes the child unless it is cooling down.
+func (n *Cooldown) Tick(agent *Agent, ctx *Context) NodeStatus {
+	if ctx.CurrentTick < n.ready {
+		return StatusFailure
+	}
+	status := n.Child.Tick(agent, ctx)
+	if status == StatusSuccess {
+		n.ready = ctx.CurrentTick + n.Ticks
+	}
+	return status
+}
+
+// TreeSpec describes a behavior tree node by the registered name of its
+// type, with numeric parameters and children, so trees can be written as
+// data, e.g. in a mod's behaviors.json.
+type TreeSpec struct {
+	Node     string             `json:"node"`
+	Params   map[string]float64 `json:"params,omitempty"`
+	Children []TreeSpec         `json:"children,omitempty"`
+}
+
+// NodeFactory builds a node from its parameters and built children.
+type NodeFactory func(params map[string]float64, children []Node) (Node, error)
+
+// NodeRegistry maps node names to factories and tree names to specs.
+// Games and mods register custom leaves and trees; a registered tree can be
+// used as a node in other trees. Nodes keep running state, so every agent
+// needs its own tree built by NewTree.
+type NodeRegistry struct {
+	nodes map[string]NodeFactory
+	trees map[string]TreeSpec
+}
+
+// NewNodeRegistry creates a registry with the built-in nodes and role trees.
+func NewNodeRegistry() *NodeRegistry {
+	r := &NodeRegistry{
+		nodes: make(map[string]NodeFactory),
+		trees: make(map[string]TreeSpec),
+	}
+	registerBuiltinNodes(r)
+	for name, tree := range builtinTrees() {
+		r.trees[name] = tree
+	}
+	return r
+}
+
+// RegisterNode adds a node type, replacing any node of the same name.
+func (r *NodeRegistry) RegisterNode(name string, factory NodeFactory) {
+	r.nodes[name] = factory
+}
+
+// RegisterCondition adds a leaf that succeeds when check returns true.
+func (r *NodeRegistry) RegisterCondition(name string, check func(*Agent, *Context) bool) {
+	r.RegisterNode(name, func(map[string]float64, []Node) (Node, error) {
+		return NewCondition(check), nil
+	})
+}
+
+// RegisterAction adds a leaf that runs execute.
+func (r *NodeRegistry) RegisterAction(name string, execute func(*Agent, *Context) NodeStatus) {
+	r.RegisterNode(name, func(map[string]float64, []Node) (Node, error) {
+		return NewAction(execute), nil
+	})
+}
+
+// RegisterTree adds a named tree, replacing any tree of the same name. The
+// spec is checked by building it.
+func (r *NodeRegistry) RegisterTree(name string, spec TreeSpec) error {
+	if _, err := r.build(spec, map[string]bool{name: true}); err != nil {
+		return err
+	}
+	r.trees[name] = spec
+	return nil
+}
+
+// Nodes returns the registered node and tree names in sorted order.
+func (r *NodeRegistry) Nodes() []string {
+	names := make([]string, 0, len(r.nodes)+len(r.trees))
+	for name := range r.nodes {
+		names = append(names, name)
+	}
+	for name := range r.trees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build creates the node tree a spec describes.
+func (r *NodeRegistry) Build(spec TreeSpec) (Node, error) {
+	return r.build(spec, map[string]bool{})
+}
+
+// build creates a node, expanding tree references and rejecting cycles.
+func (r *NodeRegistry) build(spec TreeSpec, expanding map[string]bool) (Node, error) {
+	if tree, ok := r.trees[spec.Node]; ok {
+		if expanding[spec.Node] {
+			return nil, fmt.Errorf("%w: %s refers to itself", ErrInvalidTree, spec.Node)
+		}
+		expanding[spec.Node] = true
+		defer delete(expanding, spec.Node)
+		return r.build(tree, expanding)
+	}
+	factory, ok := r.nodes[spec.Node]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownNode, spec.Node)
+	}
+	children := make([]Node, 0, len(spec.Children))
+	for _, c := range spec.Children {
+		child, err := r.build(c, expanding)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return factory(spec.Params, children)
+}
+
+// NewTree builds a fresh instance of the named tree.
+func (r *NodeRegistry) NewTree(name string) (*BehaviorTree, error) {
+	if _, ok := r.trees[name]; !ok {
+		return nil, fmt.Errorf("%w: tree %q", ErrUnknownNode, name)
+	}
+	root, err := r.Build(TreeSpec{Node: name})
+	if err != nil {
+		return nil, err
+	}
+	return &BehaviorTree{Root: root}, nil
+}
+
+// NewRoleTree builds a fresh instance of a role's tree, which mods may have
+// replaced, falling back to the default FPS tree.
+func (r *NodeRegistry) NewRoleTree(role EnemyRole) *BehaviorTree {
+	if bt, err := r.NewTree(RoleTreeName(role)); err == nil {
+		return bt
+	}
+	return NewBehaviorTree()
+}
+
+// LoadJSON registers the trees of a JSON object mapping tree names to specs,
+// e.g. a mod's behaviors.json. Trees may refer to each other; nothing is
+// registered if any tree is invalid.
+func (r *NodeRegistry) LoadJSON(data []byte) (int, error) {
+	var specs map[string]TreeSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidTree, err)
+	}
+	previous := make(map[string]TreeSpec)
+	for name, spec := range specs {
+		if old, ok := r.trees[name]; ok {
+			previous[name] = old
+		}
+		r.trees[name] = spec
+	}
+	for name := range specs {
+		if _, err := r.build(TreeSpec{Node: name}, map[string]bool{}); err != nil {
+			for n := range specs {
+				delete(r.trees, n)
+			}
+			for n, old := range previous {
+				r.trees[n] = old
+			}
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return len(specs), nil
+}
+
+// LoadFile registers the trees of a JSON file.
+func (r *NodeRegistry) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return r.LoadJSON(data)
+}
+
+// RoleTreeName returns the registry name of a role's tree.
+func RoleTreeName(role EnemyRole) string {
+	switch role {
+	case RoleRanged:
+		return "role_ranged"
+	case RoleHealer:
+		return "role_healer"
+	case RoleAmbusher:
+		return "role_ambusher"
+	case RoleScout:
+		return "role_scout"
+	default:
+		return "role_tank"
+	}
+}
+
+// leafNode builds a childless node.
+func leafNode(build func(params map[string]float64) Node) NodeFactory {
+	return func(params map[string]float64, children []Node) (Node, error) {
+		if len(children) > 0 {
+			return nil, fmt.Errorf("%w: leaf node has children", ErrInvalidTree)
+		}
+		return build(params), nil
+	}
+}
+
+// decoratorNode builds a node with exactly one child.
+func decoratorNode(build func(child Node, params map[string]float64) Node) NodeFactory {
+	return func(params map[string]float64, children []Node) (Node, error) {
+		if len(children) != 1 {
+			return nil, fmt.Errorf("%w: decorator needs one child, has %d", ErrInvalidTree, len(children))
+		}
+		return build(children[0], params), nil
+	}
+}
+
+// registerBuiltinNodes adds the composites, decorators and leaves.
+func registerBuiltinNodes(r *NodeRegistry) {
+	r.RegisterNode("selector", func(_ map[string]float64, c []Node) (Node, error) { return NewSelector(c...), nil })
+	r.RegisterNode("sequence", func(_ map[string]float64, c []Node) (Node, error) { return NewSequence(c...), nil })
+	r.RegisterNode("reactive_selector", func(_ map[string]float64, c []Node) (Node, error) { return NewReactiveSelector(c...), nil })
+	r.RegisterNode("reactive_sequence", func(_ map[string]float64, c []Node) (Node, error) { return NewReactiveSequence(c...), nil })
+	r.RegisterNode("parallel", func(_ map[string]float64, c []Node) (Node, error) { return NewParallel(c...), nil })
+
+	r.RegisterNode("inverter", decoratorNode(func(c Node, _ map[string]float64) Node { return NewInverter(c) }))
+	r.RegisterNode("succeeder", decoratorNode(func(c Node, _ map[string]float64) Node { return NewSucceeder(c) }))
+	r.RegisterNode("repeater", decoratorNode(func(c Node, p map[string]float64) Node { return NewRepeater(c, int(p["times"])) }))
+	r.RegisterNode("cooldown", decoratorNode(func(c Node, p map[string]float64) Node { return NewCooldown(c, int(p["ticks"])) }))
+
+	r.RegisterCondition("low_health", checkLowHealth)
+	r.RegisterCondition("can_see_player", checkCanSeePlayer)
+	r.RegisterCondition("in_attack_range", checkInAttackRange)
+	r.RegisterCondition("heard_gunshot", checkHeardGunshot)
+	r.RegisterCondition("saw_player", func(agent *Agent, _ *Context) bool { return agent.Blackboard.Bool(KeySawPlayer) })
+	for _, level := range alertLevels {
+		r.RegisterCondition("alert_"+level.String(), func(agent *Agent, _ *Context) bool { return agent.Alert == level })
+	}
+	for _, order := range squadOrders {
+		r.RegisterCondition("order_"+order.String(), func(agent *Agent, _ *Context) bool { return agent.Order() == order })
+	}
+	r.RegisterNode("health_below", leafNode(func(p map[string]float64) Node {
+		ratio := p["ratio"]
+		return NewCondition(func(agent *Agent, _ *Context) bool { return agent.Health < agent.MaxHealth*ratio })
+	}))
+	r.RegisterNode("player_within", leafNode(func(p map[string]float64) Node {
+		dist := p["range"]
+		return NewCondition(func(agent *Agent, ctx *Context) bool {
+			return math.Hypot(ctx.PlayerX-agent.X, ctx.PlayerY-agent.Y) <= dist
+		})
+	}))
+
+	r.RegisterAction("retreat", actionRetreat)
+	r.RegisterAction("attack", actionAttack)
+	r.RegisterAction("strafe", actionStrafe)
+	r.RegisterAction("chase", actionChase)
+	r.RegisterAction("alert", actionAlert)
+	r.RegisterAction("patrol", actionPatrol)
+	r.RegisterAction("remember_player", actionRememberPlayer)
+	r.RegisterAction("search", actionSearch)
+	r.RegisterAction("search_area", actionSearchArea)
+	r.RegisterAction("flank", actionFlank)
+	r.RegisterAction("take_cover", actionTakeCover)
+	r.RegisterAction("suppress", actionSuppress)
+	r.RegisterAction("wait", func(agent *Agent, _ *Context) NodeStatus {
+		agent.State = StateIdle
+		return StatusRunning
+	})
+}
+
+// actionRememberPlayer notes where the player is on the blackboard.
+func actionRememberPlayer(agent *Agent, ctx *Context) NodeStatus {
+	agent.Blackboard.Set(KeySawPlayer, true)
+	agent.Blackboard.Set(KeyLastSeenX, ctx.PlayerX)
+	agent.Blackboard.Set(KeyLastSeenY, ctx.PlayerY)
+	return StatusSuccess
+}
+
+// actionSearch walks to where the player was last seen, then forgets them.
+func actionSearch(agent *Agent, ctx *Context) NodeStatus {
+	if !agent.Blackboard.Bool(KeySawPlayer) {
+		return StatusFailure
+	}
+	agent.State = StateAlert
+	x, y := agent.Blackboard.Float(KeyLastSeenX), agent.Blackboard.Float(KeyLastSeenY)
+	arrived := math.Hypot(x-agent.X, y-agent.Y) < 0.5
+	if !arrived && ctx.Nav != nil {
+		arrived = agent.MoveToward(ctx.Nav, x, y, agent.Speed)
+	}
+	if arrived || ctx.Nav == nil {
+		agent.Blackboard.Delete(KeySawPlayer)
+		return StatusSuccess
+	}
+	return StatusRunning
+}
+
+// spec is shorthand for building tree specs in Go.
+func spec(node string, children ...TreeSpec) TreeSpec {
+	return TreeSpec{Node: node, Children: children}
+}
+
+// specP is spec with parameters.
+func specP(node string, params map[string]float64, children ...TreeSpec) TreeSpec {
+	return TreeSpec{Node: node, Params: params, Children: children}
+}
+
+// builtinTrees returns the tree for each enemy role. The trees are
+// reactive, so every tick they re-check what the agent sees, and every role
+// notes where it saw the player to search there after losing sight. With
+// perception, agents investigate what they notice and sweep the area while
+// searching. Squad orders from a SquadPlanner come before a role's own
+// fighting: front-liners flank, ranged enemies and healers fight from
+// cover, and ranged enemies without cover suppress.
+func builtinTrees() map[string]TreeSpec {
+	when := func(children ...TreeSpec) TreeSpec { return spec("reactive_sequence", children...) }
+	first := func(children ...TreeSpec) TreeSpec { return spec("reactive_selector", children...) }
+	within := func(r float64) TreeSpec { return specP("player_within", map[string]float64{"range": r}) }
+
+	spotted := when(spec("can_see_player"), spec("remember_player"))
+	retreat := when(spec("low_health"), spec("retreat"))
+	attack := when(spec("in_attack_range"), spec("attack"))
+	heard := first(when(spec("heard_gunshot"), spec("alert")), when(spec("alert_searching"), spec("search_area")))
+	flank := when(spec("order_flank"), spec("flank"))
+	cover := when(spec("order_cover"), spec("take_cover"))
+	suppress := when(spec("order_suppress"), spotted, spec("suppress"))
+	return map[string]TreeSpec{
+		// Tanks close in and trade blows.
+		"role_tank": first(retreat, flank, when(spotted, first(attack, spec("chase"))), heard, spec("search"), spec("patrol")),
+		// Ranged enemies back off when rushed and strafe between shots.
+		"role_ranged": first(retreat,
+			when(spotted, within(3), spec("retreat")), cover, suppress,
+			when(spotted, first(attack, spec("strafe"))),
+			heard, spec("search"), spec("patrol")),
+		// Healers keep their distance and flee early.
+		"role_healer": first(
+			when(specP("health_below", map[string]float64{"ratio": 0.5}), spec("retreat")),
+			when(spotted, within(5), spec("retreat")), cover,
+			when(spotted, attack),
+			heard, spec("search"), spec("patrol")),
+		// Ambushers hold still until the player comes close, then pounce.
+		"role_ambusher": first(retreat, flank, when(within(5), spotted, first(attack, spec("chase"))), spec("search"), spec("wait")),
+		// Scouts hit and run, darting off whenever the player gets close.
+		"role_scout": first(retreat, when(spotted, within(2), spec("retreat")), flank,
+			when(spotted, first(attack, spec("chase"))),
+			heard, spec("search"), spec("patrol")),
+	}
+}
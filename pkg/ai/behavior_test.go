@@ -0,0 +1,228 @@
+package ai
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// statusNode returns a fixed status and counts its ticks.
+type statusNode struct {
+	status NodeStatus
+	ticks  int
+}
+
+func (n *statusNode) Tick(*Agent, *Context) NodeStatus {
+	n.ticks++
+	return n.status
+}
+
+func TestCompositesAndDecorators(t *testing.T) {
+	s, f, r := StatusSuccess, StatusFailure, StatusRunning
+	tests := []struct {
+		name  string
+		build func(children ...Node) Node
+		in    []NodeStatus
+		want  NodeStatus
+	}{
+		{"reactive selector first success", func(c ...Node) Node { return NewReactiveSelector(c...) }, []NodeStatus{f, s, r}, s},
+		{"reactive selector running", func(c ...Node) Node { return NewReactiveSelector(c...) }, []NodeStatus{f, r}, r},
+		{"reactive selector all fail", func(c ...Node) Node { return NewReactiveSelector(c...) }, []NodeStatus{f, f}, f},
+		{"reactive sequence all succeed", func(c ...Node) Node { return NewReactiveSequence(c...) }, []NodeStatus{s, s}, s},
+		{"reactive sequence running", func(c ...Node) Node { return NewReactiveSequence(c...) }, []NodeStatus{s, r, f}, r},
+		{"parallel running", func(c ...Node) Node { return NewParallel(c...) }, []NodeStatus{s, r}, r},
+		{"parallel failure", func(c ...Node) Node { return NewParallel(c...) }, []NodeStatus{r, f}, f},
+		{"inverter", func(c ...Node) Node { return NewInverter(c[0]) }, []NodeStatus{s}, f},
+		{"inverter running", func(c ...Node) Node { return NewInverter(c[0]) }, []NodeStatus{r}, r},
+		{"succeeder", func(c ...Node) Node { return NewSucceeder(c[0]) }, []NodeStatus{f}, s},
+		{"repeater keeps going", func(c ...Node) Node { return NewRepeater(c[0], 2) }, []NodeStatus{s}, r},
+		{"repeater failure", func(c ...Node) Node { return NewRepeater(c[0], 2) }, []NodeStatus{f}, f},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			children := make([]Node, len(tt.in))
+			for i, st := range tt.in {
+				children[i] = &statusNode{status: st}
+			}
+			if got := tt.build(children...).Tick(&Agent{}, &Context{}); got != tt.want {
+				t.Errorf("Tick() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReactiveSequenceRechecksConditions(t *testing.T) {
+	cond := &statusNode{status: StatusSuccess}
+	action := &statusNode{status: StatusRunning}
+	seq := NewReactiveSequence(cond, action)
+
+	seq.Tick(&Agent{}, &Context{})
+	cond.status = StatusFailure
+	if got := seq.Tick(&Agent{}, &Context{}); got != StatusFailure {
+		t.Errorf("Tick() = %v after the condition failed, want failure", got)
+	}
+	if action.ticks != 1 {
+		t.Errorf("action ticked %d times, want 1", action.ticks)
+	}
+}
+
+func TestRepeaterAndCooldown(t *testing.T) {
+	child := &statusNode{status: StatusSuccess}
+	rep := NewRepeater(child, 3)
+	var got []NodeStatus
+	for i := 0; i < 3; i++ {
+		got = append(got, rep.Tick(&Agent{}, &Context{}))
+	}
+	if got[0] != StatusRunning || got[1] != StatusRunning || got[2] != StatusSuccess {
+		t.Errorf("repeater statuses = %v, want running, running, success", got)
+	}
+
+	cd := NewCooldown(&statusNode{status: StatusSuccess}, 10)
+	tests := []struct {
+		tick int
+		want NodeStatus
+	}{
+		{0, StatusSuccess},
+		{5, StatusFailure},
+		{10, StatusSuccess},
+	}
+	for _, tt := range tests {
+		if st := cd.Tick(&Agent{}, &Context{CurrentTick: tt.tick}); st != tt.want {
+			t.Errorf("cooldown at tick %d = %v, want %v", tt.tick, st, tt.want)
+		}
+	}
+}
+
+func TestBlackboard(t *testing.T) {
+	var b Blackboard
+	if b.Bool("x") || b.Float("x") != 0 {
+		t.Error("empty blackboard returned values")
+	}
+	b.Set("x", 2.5)
+	b.Set("seen", true)
+	if b.Float("x") != 2.5 || !b.Bool("seen") || b.Bool("x") {
+		t.Error("blackboard did not return the stored values by type")
+	}
+	b.Delete("seen")
+	if _, ok := b.Get("seen"); ok {
+		t.Error("Delete() left the key")
+	}
+}
+
+func TestNodeRegistryBuildErrors(t *testing.T) {
+	r := NewNodeRegistry()
+	tests := []struct {
+		name string
+		spec TreeSpec
+		want error
+	}{
+		{"unknown node", TreeSpec{Node: "dance"}, ErrUnknownNode},
+		{"leaf with children", TreeSpec{Node: "player_within", Children: []TreeSpec{{Node: "wait"}}}, ErrInvalidTree},
+		{"decorator without child", TreeSpec{Node: "inverter"}, ErrInvalidTree},
+	}
+	for _, tt := range tests {
+		if _, err := r.Build(tt.spec); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Build() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if err := r.RegisterTree("loop", TreeSpec{Node: "sequence", Children: []TreeSpec{{Node: "loop"}}}); err == nil {
+		t.Error("RegisterTree() accepted an unknown self reference")
+	}
+}
+
+func TestNodeRegistryCustomNodes(t *testing.T) {
+	r := NewNodeRegistry()
+	danced := false
+	r.RegisterAction("dance", func(*Agent, *Context) NodeStatus {
+		danced = true
+		return StatusSuccess
+	})
+	n, err := r.LoadJSON([]byte(`{
+		"party": {"node": "sequence", "children": [{"node": "dance"}]},
+		"role_tank": {"node": "reactive_selector", "children": [{"node": "party"}]}
+	}`))
+	if err != nil || n != 2 {
+		t.Fatalf("LoadJSON() = %d, %v, want 2 trees", n, err)
+	}
+	r.NewRoleTree(RoleTank).Tick(&Agent{}, &Context{})
+	if !danced {
+		t.Error("mod tree replacing the tank tree did not run the custom node")
+	}
+
+	// A bad file leaves the registry as it was.
+	if _, err := r.LoadJSON([]byte(`{"role_tank": {"node": "nope"}}`)); !errors.Is(err, ErrUnknownNode) {
+		t.Errorf("LoadJSON() error = %v, want ErrUnknownNode", err)
+	}
+	danced = false
+	r.NewRoleTree(RoleTank).Tick(&Agent{}, &Context{})
+	if !danced {
+		t.Error("failed load replaced the tank tree")
+	}
+}
+
+func TestRoleTrees(t *testing.T) {
+	open := navMap(
+		"..........",
+		"..........",
+		"..........",
+	)
+	tests := []struct {
+		name      string
+		role      EnemyRole
+		playerX   float64
+		health    float64
+		wantState State
+	}{
+		{"tank chases", RoleTank, 8.5, 100, StateChase},
+		{"tank retreats when hurt", RoleTank, 8.5, 10, StateRetreat},
+		{"ranged kites when rushed", RoleRanged, 2.5, 100, StateRetreat},
+		{"healer keeps away", RoleHealer, 4.5, 100, StateRetreat},
+		{"ambusher waits", RoleAmbusher, 8.5, 100, StateIdle},
+		{"ambusher pounces", RoleAmbusher, 4.5, 100, StateChase},
+		{"scout chases", RoleScout, 8.5, 100, StateChase},
+	}
+	r := NewNodeRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &Agent{X: 0.5, Y: 1.5, Health: tt.health, MaxHealth: 100, Speed: 0.05, AttackRange: 1, RetreatHealthRatio: 0.25, Role: tt.role}
+			ctx := &Context{TileMap: open, PlayerX: tt.playerX, PlayerY: 1.5, LastShotTick: -1000, RNG: rng.NewRNG(1)}
+			r.NewRoleTree(tt.role).Tick(agent, ctx)
+			if agent.State != tt.wantState {
+				t.Errorf("State = %v, want %v", agent.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestRoleTreeSearchesLastSeen(t *testing.T) {
+	tiles := navMap(
+		".....",
+		".....",
+	)
+	agent := &Agent{X: 0.5, Y: 0.5, Health: 100, MaxHealth: 100, Speed: 0.5, AttackRange: 1}
+	ctx := &Context{TileMap: tiles, PlayerX: 4.5, PlayerY: 0.5, LastShotTick: -1000, Nav: NewNavGrid(tiles, DefaultNavCosts)}
+	bt := NewNodeRegistry().NewRoleTree(RoleTank)
+
+	bt.Tick(agent, ctx)
+	// The player vanishes off the map; the tank heads for where it saw them.
+	ctx.PlayerX, ctx.PlayerY = -50, -50
+	bt.Tick(agent, ctx)
+	if agent.State != StateAlert || agent.X <= 1 {
+		t.Errorf("State = %v at x %v, want searching toward the last sighting", agent.State, agent.X)
+	}
+	for i := 0; i < 20; i++ {
+		bt.Tick(agent, ctx)
+	}
+	if agent.Blackboard.Bool(KeySawPlayer) {
+		t.Error("agent still remembers the player after searching the spot")
+	}
+}
+
+func TestActionAttackMarksBlackboard(t *testing.T) {
+	agent := &Agent{}
+	actionAttack(agent, &Context{PlayerX: 1})
+	if !agent.Blackboard.Bool(KeyAttacked) {
+		t.Error("attack did not set KeyAttacked")
+	}
+}
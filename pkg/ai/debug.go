@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DebugInfo is a snapshot of what an agent is thinking, for the debug
+// overlay and JSON dumps.
+type DebugInfo struct {
+	ID         string                 `json:"id"`
+	Archetype  string                 `json:"archetype,omitempty"`
+	Role       string                 `json:"role"`
+	State      string                 `json:"state"`
+	Alert      string                 `json:"alert"`
+	X          float64                `json:"x"`
+	Y          float64                `json:"y"`
+	DirX       float64                `json:"dir_x"`
+	DirY       float64                `json:"dir_y"`
+	Health     float64                `json:"health"`
+	MaxHealth  float64                `json:"max_health"`
+	Awareness  float64                `json:"awareness"`
+	SeesPlayer bool                   `json:"sees_player"`
+	ViewAngle  float64                `json:"view_angle"`
+	ViewRange  float64                `json:"view_range"`
+	Path       []Waypoint             `json:"path,omitempty"`
+	HasTarget  bool                   `json:"has_target"`
+	TargetX    float64                `json:"target_x,omitempty"`
+	TargetY    float64                `json:"target_y,omitempty"`
+	Squad      string                 `json:"squad,omitempty"`
+	Blackboard map[string]interface{} `json:"blackboard,omitempty"`
+}
+
+// Debug returns a snapshot of the agent. Path holds only the part of the
+// route still to be walked; the target is whatever the agent is heading
+// for: the player in combat or flight, otherwise the point being searched
+// or investigated.
+func (agent *Agent) Debug() DebugInfo {
+	angle := agent.ViewAngle
+	if angle <= 0 {
+		angle = DefaultViewAngle
+	}
+	info := DebugInfo{
+		ID:         agent.ID,
+		Archetype:  agent.ArchetypeID,
+		Role:       agent.Role.String(),
+		State:      agent.State.String(),
+		Alert:      agent.Alert.String(),
+		X:          agent.X,
+		Y:          agent.Y,
+		DirX:       agent.DirX,
+		DirY:       agent.DirY,
+		Health:     agent.Health,
+		MaxHealth:  agent.MaxHealth,
+		Awareness:  agent.Awareness,
+		SeesPlayer: agent.SeesPlayer,
+		ViewAngle:  angle,
+		ViewRange:  agent.AlertRadius,
+		Squad:      agent.SquadID,
+	}
+	if agent.PathIndex < len(agent.Path) {
+		info.Path = append([]Waypoint(nil), agent.Path[agent.PathIndex:]...)
+	}
+	info.TargetX, info.TargetY, info.HasTarget = agent.debugTarget()
+	if keys := agent.Blackboard.Keys(); len(keys) > 0 {
+		info.Blackboard = make(map[string]interface{}, len(keys))
+		for _, k := range keys {
+			info.Blackboard[k], _ = agent.Blackboard.Get(k)
+		}
+	}
+	return info
+}
+
+// debugTarget returns the point the agent is heading for, if any.
+func (agent *Agent) debugTarget() (x, y float64, ok bool) {
+	switch {
+	case agent.SeesPlayer || agent.Alert == AlertCombat || agent.Alert == AlertFlee:
+		return agent.LastKnownX, agent.LastKnownY, true
+	case agent.Blackboard.Bool(KeySearchPoint):
+		return agent.Blackboard.Float(KeySearchX), agent.Blackboard.Float(KeySearchY), true
+	case agent.Blackboard.Bool(KeyInvestigate):
+		return agent.Blackboard.Float(KeyInvestigateX), agent.Blackboard.Float(KeyInvestigateY), true
+	}
+	return 0, 0, false
+}
+
+// DumpDebug writes each agent's snapshot as indented JSON to
+// dir/<agent ID>.json, creating dir if needed.
+func DumpDebug(dir string, agents []*Agent) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create AI dump directory: %w", err)
+	}
+	for i, agent := range agents {
+		data, err := json.MarshalIndent(agent.Debug(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode agent %q: %w", agent.ID, err)
+		}
+		name := agent.ID
+		if name == "" {
+			name = fmt.Sprintf("agent_%d", i)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(name)+".json"), data, 0o644); err != nil {
+			return fmt.Errorf("write agent %q: %w", agent.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package ai
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Debug overlay layout.
+const (
+	debugLineHeight = 13
+	debugConeSteps  = 12
+	debugAgentSize  = 3
+	debugTextOffset = 6
+)
+
+var (
+	debugConeColor   = color.RGBA{R: 255, G: 255, B: 120, A: 40}
+	debugEdgeColor   = color.RGBA{R: 255, G: 255, B: 120, A: 140}
+	debugPathColor   = color.RGBA{R: 80, G: 200, B: 255, A: 220}
+	debugTargetColor = color.RGBA{R: 255, G: 80, B: 80, A: 220}
+	debugTextColor   = color.RGBA{R: 220, G: 255, B: 220, A: 255}
+)
+
+// debugAlertColors tints each agent's marker by alert level.
+var debugAlertColors = map[AlertLevel]color.RGBA{
+	AlertIdle:       {R: 120, G: 220, B: 120, A: 255},
+	AlertSuspicious: {R: 230, G: 200, B: 60, A: 255},
+	AlertSearching:  {R: 240, G: 140, B: 40, A: 255},
+	AlertCombat:     {R: 230, G: 60, B: 60, A: 255},
+	AlertFlee:       {R: 180, G: 100, B: 230, A: 255},
+}
+
+// DebugOverlay draws each agent's state, path, vision cone, target line and
+// blackboard over a top-down view of the world. It draws nothing until
+// toggled on.
+type DebugOverlay struct {
+	enabled  bool
+	whiteImg *ebiten.Image
+	// ShowBlackboard also lists each agent's blackboard values under its state.
+	ShowBlackboard bool
+}
+
+// NewDebugOverlay creates a hidden overlay that shows blackboards.
+func NewDebugOverlay() *DebugOverlay {
+	return &DebugOverlay{ShowBlackboard: true}
+}
+
+// Toggle shows or hides the overlay.
+func (o *DebugOverlay) Toggle() {
+	o.enabled = !o.enabled
+}
+
+// Enabled reports whether the overlay is shown.
+func (o *DebugOverlay) Enabled() bool {
+	return o.enabled
+}
+
+// Lines returns the text shown beside an agent: its ID, state and alert
+// level, then its blackboard when ShowBlackboard is set.
+func (o *DebugOverlay) Lines(info DebugInfo) []string {
+	lines := []string{
+		fmt.Sprintf("%s %s/%s", info.ID, info.State, info.Alert),
+		fmt.Sprintf("hp %.0f/%.0f aware %.2f", info.Health, info.MaxHealth, info.Awareness),
+	}
+	if !o.ShowBlackboard {
+		return lines
+	}
+	keys := make([]string, 0, len(info.Blackboard))
+	for k := range info.Blackboard {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := info.Blackboard[k].(type) {
+		case float64:
+			lines = append(lines, fmt.Sprintf(" %s=%.2f", k, v))
+		default:
+			lines = append(lines, fmt.Sprintf(" %s=%v", k, v))
+		}
+	}
+	return lines
+}
+
+// Draw renders the overlay when enabled, placing world positions on screen
+// with project.
+func (o *DebugOverlay) Draw(screen *ebiten.Image, agents []*Agent, project func(x, y float64) (float32, float32)) {
+	if !o.enabled {
+		return
+	}
+	for _, agent := range agents {
+		if agent.Health <= 0 {
+			continue
+		}
+		info := agent.Debug()
+		o.drawCone(screen, info, project)
+		drawDebugPath(screen, info, project)
+
+		x, y := project(info.X, info.Y)
+		if info.HasTarget {
+			tx, ty := project(info.TargetX, info.TargetY)
+			vector.StrokeLine(screen, x, y, tx, ty, 1, debugTargetColor, false)
+		}
+		vector.DrawFilledCircle(screen, x, y, debugAgentSize, debugAlertColors[agent.Alert], false)
+
+		ty := int(y) - debugTextOffset
+		for _, line := range o.Lines(info) {
+			text.Draw(screen, line, basicfont.Face7x13, int(x)+debugTextOffset, ty, debugTextColor)
+			ty += debugLineHeight
+		}
+	}
+}
+
+// drawCone fills the agent's vision cone out to its sight range. Agents
+// that face nowhere look all around and get a full circle.
+func (o *DebugOverlay) drawCone(screen *ebiten.Image, info DebugInfo, project func(x, y float64) (float32, float32)) {
+	if info.ViewRange <= 0 {
+		return
+	}
+	facing := math.Atan2(info.DirY, info.DirX)
+	half := info.ViewAngle
+	if info.DirX == 0 && info.DirY == 0 {
+		half = math.Pi
+	}
+	half = math.Min(half, math.Pi)
+
+	var path vector.Path
+	cx, cy := project(info.X, info.Y)
+	path.MoveTo(cx, cy)
+	var edges [2][2]float32
+	for i := 0; i <= debugConeSteps; i++ {
+		a := facing - half + 2*half*float64(i)/debugConeSteps
+		px, py := project(info.X+math.Cos(a)*info.ViewRange, info.Y+math.Sin(a)*info.ViewRange)
+		path.LineTo(px, py)
+		if i == 0 {
+			edges[0] = [2]float32{px, py}
+		}
+		edges[1] = [2]float32{px, py}
+	}
+	path.Close()
+
+	if o.whiteImg == nil {
+		o.whiteImg = ebiten.NewImage(1, 1)
+		o.whiteImg.Fill(color.White)
+	}
+	vs, is := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	for i := range vs {
+		vs[i].ColorR = float32(debugConeColor.R) / 255.0
+		vs[i].ColorG = float32(debugConeColor.G) / 255.0
+		vs[i].ColorB = float32(debugConeColor.B) / 255.0
+		vs[i].ColorA = float32(debugConeColor.A) / 255.0
+	}
+	screen.DrawTriangles(vs, is, o.whiteImg, &ebiten.DrawTrianglesOptions{})
+
+	if half < math.Pi {
+		for _, e := range edges {
+			vector.StrokeLine(screen, cx, cy, e[0], e[1], 1, debugEdgeColor, false)
+		}
+	}
+}
+
+// drawDebugPath draws the remaining route from the agent through each
+// waypoint.
+func drawDebugPath(screen *ebiten.Image, info DebugInfo, project func(x, y float64) (float32, float32)) {
+	px, py := project(info.X, info.Y)
+	for _, wp := range info.Path {
+		x, y := project(wp.X, wp.Y)
+		vector.StrokeLine(screen, px, py, x, y, 1, debugPathColor, false)
+		vector.DrawFilledRect(screen, x-1, y-1, 2, 2, debugPathColor, false)
+		px, py = x, y
+	}
+}
@@ -0,0 +1,137 @@
+package ai
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStateString(t *testing.T) {
+	tests := []struct {
+		state State
+		want  string
+	}{
+		{StateIdle, "idle"},
+		{StateChase, "chase"},
+		{StateAttack, "attack"},
+		{State(99), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("State(%d).String() = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestBlackboardKeysSorted(t *testing.T) {
+	var b Blackboard
+	if keys := b.Keys(); len(keys) != 0 {
+		t.Errorf("empty Keys() = %v, want none", keys)
+	}
+	b.Set("b", 1)
+	b.Set("a", 2)
+	b.Set("c", 3)
+	if got, want := b.Keys(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestAgentDebug(t *testing.T) {
+	agent := NewAgent("grunt", 2, 3)
+	agent.State = StateChase
+	agent.Alert = AlertCombat
+	agent.LastKnownX, agent.LastKnownY = 7, 8
+	agent.Path = []Waypoint{{X: 2.5, Y: 3.5}, {X: 3.5, Y: 3.5}, {X: 4.5, Y: 3.5}}
+	agent.PathIndex = 1
+	agent.ViewAngle = 0
+	agent.Blackboard.Set("note", "hello")
+
+	info := agent.Debug()
+	if info.State != "chase" || info.Alert != "combat" {
+		t.Errorf("state/alert = %s/%s, want chase/combat", info.State, info.Alert)
+	}
+	if len(info.Path) != 2 || info.Path[0] != agent.Path[1] {
+		t.Errorf("Path = %v, want remaining route %v", info.Path, agent.Path[1:])
+	}
+	if !info.HasTarget || info.TargetX != 7 || info.TargetY != 8 {
+		t.Errorf("target = (%v, %v, %v), want (7, 8, true)", info.TargetX, info.TargetY, info.HasTarget)
+	}
+	if info.ViewAngle != DefaultViewAngle {
+		t.Errorf("ViewAngle = %v, want default %v", info.ViewAngle, DefaultViewAngle)
+	}
+	if info.Blackboard["note"] != "hello" {
+		t.Errorf("Blackboard = %v, want note=hello", info.Blackboard)
+	}
+
+	info.Path[0].X = -1
+	if agent.Path[1].X == -1 {
+		t.Error("Debug path aliases the agent's path")
+	}
+}
+
+func TestAgentDebugTarget(t *testing.T) {
+	agent := NewAgent("grunt", 0, 0)
+	if info := agent.Debug(); info.HasTarget {
+		t.Errorf("idle agent has target (%v, %v)", info.TargetX, info.TargetY)
+	}
+
+	agent.investigate(4, 5)
+	if info := agent.Debug(); !info.HasTarget || info.TargetX != 4 || info.TargetY != 5 {
+		t.Errorf("investigating target = (%v, %v, %v), want (4, 5, true)", info.TargetX, info.TargetY, info.HasTarget)
+	}
+
+	agent.Blackboard.Set(KeySearchPoint, true)
+	agent.Blackboard.Set(KeySearchX, 6.0)
+	agent.Blackboard.Set(KeySearchY, 1.0)
+	if info := agent.Debug(); info.TargetX != 6 || info.TargetY != 1 {
+		t.Errorf("searching target = (%v, %v), want (6, 1)", info.TargetX, info.TargetY)
+	}
+}
+
+func TestDumpDebug(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ai")
+	a := NewAgent("a1", 1, 1)
+	a.Blackboard.Set(KeySawPlayer, true)
+	b := NewAgent("b1", 2, 2)
+
+	if err := DumpDebug(dir, []*Agent{a, b}); err != nil {
+		t.Fatalf("DumpDebug: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "a1.json"))
+	if err != nil {
+		t.Fatalf("read dump: %v", err)
+	}
+	var got DebugInfo
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode dump: %v", err)
+	}
+	if got.ID != "a1" || got.X != 1 || got.Blackboard[KeySawPlayer] != true {
+		t.Errorf("dump = %+v, want a1 at x=1 with saw_player", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b1.json")); err != nil {
+		t.Errorf("second agent not dumped: %v", err)
+	}
+}
+
+func TestDebugOverlayLines(t *testing.T) {
+	o := NewDebugOverlay()
+	if o.Enabled() {
+		t.Error("overlay enabled before toggle")
+	}
+	o.Toggle()
+	if !o.Enabled() {
+		t.Error("overlay disabled after toggle")
+	}
+
+	info := DebugInfo{ID: "a1", State: "patrol", Alert: "idle", Blackboard: map[string]interface{}{"z": true, "x": 1.0}}
+	want := []string{"a1 patrol/idle", "hp 0/0 aware 0.00", " x=1.00", " z=true"}
+	if got := o.Lines(info); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+	o.ShowBlackboard = false
+	if got := o.Lines(info); len(got) != 2 {
+		t.Errorf("Lines() without blackboard = %q, want 2 lines", got)
+	}
+}
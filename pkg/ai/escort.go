@@ -0,0 +1,96 @@
+package ai
+
+import "math"
+
+// Escort defaults.
+const (
+	// EscortHealth is the health an escort starts with.
+	EscortHealth = 60.0
+	// EscortSpeed is how far an escort walks per update, a little quicker
+	// than most enemies so it keeps up with the player.
+	EscortSpeed = 0.04
+	// EscortJoinRadius is how close the player must come for an escort to
+	// start following.
+	EscortJoinRadius = 2.0
+	// EscortFollowDistance is how far behind the player an escort keeps.
+	EscortFollowDistance = 1.5
+)
+
+// Escort is a friendly agent the player leads through the level. It waits
+// where it stands until the player comes within EscortJoinRadius, then
+// follows, keeping EscortFollowDistance behind. Enemies attacking within
+// their range of it hurt it.
+type Escort struct {
+	Agent     *Agent
+	Following bool
+}
+
+// NewEscort creates an escort waiting at x, y.
+func NewEscort(id string, x, y float64) *Escort {
+	return &Escort{Agent: &Agent{
+		ID:        id,
+		X:         x,
+		Y:         y,
+		DirX:      1,
+		Health:    EscortHealth,
+		MaxHealth: EscortHealth,
+		Speed:     EscortSpeed,
+		State:     StateIdle,
+		Mass:      1,
+	}}
+}
+
+// Dead reports whether the escort has been killed.
+func (e *Escort) Dead() bool {
+	return e.Agent.Health <= 0
+}
+
+// Update moves the escort one tick towards the player once it follows.
+func (e *Escort) Update(ctx *Context) {
+	a := e.Agent
+	if e.Dead() {
+		return
+	}
+	dist := math.Hypot(ctx.PlayerX-a.X, ctx.PlayerY-a.Y)
+	if !e.Following {
+		if dist > EscortJoinRadius {
+			return
+		}
+		e.Following = true
+	}
+	if dist <= EscortFollowDistance {
+		a.State = StateIdle
+		return
+	}
+	a.State = StateChase
+	if ctx.Nav != nil {
+		a.MoveToward(ctx.Nav, ctx.PlayerX, ctx.PlayerY, math.Min(a.Speed, dist-EscortFollowDistance))
+		return
+	}
+	step := math.Min(a.Speed, dist-EscortFollowDistance)
+	nx := a.X + (ctx.PlayerX-a.X)/dist*step
+	ny := a.Y + (ctx.PlayerY-a.Y)/dist*step
+	if isWalkable(nx, ny, ctx.TileMap) {
+		a.X, a.Y = nx, ny
+	}
+}
+
+// Threats returns the living enemies attacking within their range of the
+// escort.
+func (e *Escort) Threats(enemies []*Agent) []*Agent {
+	var out []*Agent
+	for _, enemy := range enemies {
+		if enemy.Health <= 0 || enemy.State != StateAttack {
+			continue
+		}
+		if math.Hypot(enemy.X-e.Agent.X, enemy.Y-e.Agent.Y) <= enemy.AttackRange {
+			out = append(out, enemy)
+		}
+	}
+	return out
+}
+
+// Reached reports whether the escort stands within radius of x, y.
+func (e *Escort) Reached(x, y, radius float64) bool {
+	return !e.Dead() && math.Hypot(x-e.Agent.X, y-e.Agent.Y) <= radius
+}
@@ -0,0 +1,52 @@
+package ai
+
+import "testing"
+
+func TestEscortFollow(t *testing.T) {
+	tileMap := [][]int{
+		{1, 1, 1, 1, 1, 1, 1, 1},
+		{1, 0, 0, 0, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1, 1, 1, 1},
+	}
+	e := NewEscort("vip", 1.5, 1.5)
+	ctx := &Context{TileMap: tileMap, PlayerX: 6.5, PlayerY: 1.5}
+	e.Update(ctx)
+	if e.Following || e.Agent.X != 1.5 {
+		t.Fatal("escort left before the player came for it")
+	}
+
+	ctx.PlayerX = 3
+	e.Update(ctx)
+	if !e.Following {
+		t.Fatal("escort did not join the player")
+	}
+	ctx.PlayerX = 6.5
+	for range 200 {
+		e.Update(ctx)
+	}
+	if got := ctx.PlayerX - e.Agent.X; got < EscortFollowDistance-1e-9 || got > EscortFollowDistance+0.05 {
+		t.Errorf("escort %.2f behind the player, want %.2f", got, EscortFollowDistance)
+	}
+	if !e.Reached(5, 1.5, 0.1) {
+		t.Errorf("escort at %.2f did not reach x=5", e.Agent.X)
+	}
+}
+
+func TestEscortThreats(t *testing.T) {
+	e := NewEscort("vip", 5, 5)
+	near := NewAgent("near", 6, 5)
+	near.State, near.AttackRange = StateAttack, 2
+	far := NewAgent("far", 15, 5)
+	far.State, far.AttackRange = StateAttack, 2
+	idle := NewAgent("idle", 5, 6)
+	idle.AttackRange = 2
+
+	threats := e.Threats([]*Agent{near, far, idle})
+	if len(threats) != 1 || threats[0] != near {
+		t.Errorf("threats = %v, want only the attacker in range", threats)
+	}
+	e.Agent.Health = 0
+	if !e.Dead() || e.Reached(5, 5, 1) {
+		t.Error("a dead escort reached its destination")
+	}
+}
@@ -0,0 +1,447 @@
+package ai
+
+import "math"
+
+// NavCosts are the extra costs of entering special tiles, on top of the cost
+// of 1 for plain floor. Higher costs make agents prefer longer detours.
+type NavCosts struct {
+	// Door is the cost of opening a closed door on the way through. Zero
+	// means agents cannot open doors, so closed doors block until SetBlocked
+	// clears them.
+	Door   float64
+	Hazard float64 // Added for each hazard marked on a tile
+}
+
+// DefaultNavCosts avoid hazards unless the detour is long. Enemies cannot
+// open doors, so closed doors block.
+var DefaultNavCosts = NavCosts{Hazard: 8}
+
+// navCacheSize bounds the number of cached paths; the cache is emptied when
+// it fills up.
+const navCacheSize = 256
+
+// navKey identifies a cached path.
+type navKey struct {
+	start, goal Coord
+}
+
+// navPath is a cached search result.
+type navPath struct {
+	waypoints []Waypoint
+	complete  bool
+}
+
+// NavGrid plans paths for agents on a tile map with A*. Tiles have a cost to
+// enter, so agents route around hazards and slow doors; found paths are
+// smoothed into straight runs and cached until the costs change. Search
+// buffers are reused, so a NavGrid must not be shared between goroutines.
+type NavGrid struct {
+	width, height int
+	cost          []float64 // Cost to enter each tile; 0 blocks
+	hazards       []uint8   // Hazards marked on each tile
+	version       uint64
+	cache         map[navKey]navPath
+	costs         NavCosts
+
+	// MaxExpansions bounds the tiles a search may expand before giving up
+	// with a partial path.
+	MaxExpansions int
+
+	// Search buffers, valid where stamp matches the current search
+	g        []float64
+	from     []int32
+	stamp    []uint32
+	closed   []bool
+	searchID uint32
+	open     []navOpen
+}
+
+// navOpen is an entry of the open set.
+type navOpen struct {
+	tile int32
+	f    float64
+}
+
+// NewNavGrid creates a navigation grid for a tile map. Floor and empty tiles
+// cost 1 to enter and closed doors 1 plus costs.Door, or block when
+// costs.Door is zero; everything else blocks.
+func NewNavGrid(tiles [][]int, costs NavCosts) *NavGrid {
+	height := len(tiles)
+	width := 0
+	if height > 0 {
+		width = len(tiles[0])
+	}
+	n := width * height
+	nav := &NavGrid{
+		width:         width,
+		height:        height,
+		cost:          make([]float64, n),
+		hazards:       make([]uint8, n),
+		cache:         make(map[navKey]navPath),
+		costs:         costs,
+		MaxExpansions: n,
+		g:             make([]float64, n),
+		from:          make([]int32, n),
+		stamp:         make([]uint32, n),
+		closed:        make([]bool, n),
+	}
+	for y, row := range tiles {
+		for x := 0; x < width && x < len(row); x++ {
+			nav.cost[y*width+x] = tileNavCost(row[x], costs)
+		}
+	}
+	return nav
+}
+
+// tileNavCost returns the cost of entering a tile, or 0 if it blocks.
+func tileNavCost(tile int, costs NavCosts) float64 {
+	switch {
+	case tile == 0 || tile == 2 || (tile >= 20 && tile <= 29):
+		return 1
+	case tile == 3 && costs.Door > 0:
+		return 1 + costs.Door
+	default:
+		return 0
+	}
+}
+
+// Version returns a counter that changes whenever tile costs change, so
+// followers know to replan.
+func (nav *NavGrid) Version() uint64 {
+	return nav.version
+}
+
+// Walkable reports whether agents can enter the tile.
+func (nav *NavGrid) Walkable(x, y int) bool {
+	return nav.inBounds(x, y) && nav.cost[y*nav.width+x] > 0
+}
+
+// inBounds reports whether the tile lies on the grid.
+func (nav *NavGrid) inBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < nav.width && y < nav.height
+}
+
+// SetBlocked blocks a tile or, when blocked is false, makes it plain floor,
+// e.g. when a door is destroyed or a barricade raised.
+func (nav *NavGrid) SetBlocked(x, y int, blocked bool) {
+	if !nav.inBounds(x, y) {
+		return
+	}
+	c := 1.0
+	if blocked {
+		c = 0
+	}
+	nav.setCost(y*nav.width+x, c)
+}
+
+// MarkHazard raises the cost of a walkable tile by the grid's hazard cost.
+func (nav *NavGrid) MarkHazard(x, y int) {
+	if nav.Walkable(x, y) {
+		i := y*nav.width + x
+		if nav.hazards[i] < math.MaxUint8 {
+			nav.hazards[i]++
+		}
+		nav.setCost(i, nav.cost[i]+nav.costs.Hazard)
+	}
+}
+
+// TileChange is a navigation-change event: the map tile at (X, Y) became
+// Tile, e.g. a door opened, a secret wall slid away or a wall was destroyed.
+type TileChange struct {
+	X, Y int
+	Tile int
+}
+
+// Apply updates the grid for changed map tiles. Each tile's cost is worked
+// out again from its new type, keeping the hazards marked on it. Cached paths
+// are dropped only if a cost changed, and agents following a path replan on
+// their next step.
+func (nav *NavGrid) Apply(changes ...TileChange) {
+	for _, c := range changes {
+		if !nav.inBounds(c.X, c.Y) {
+			continue
+		}
+		i := c.Y*nav.width + c.X
+		cost := tileNavCost(c.Tile, nav.costs)
+		if cost > 0 {
+			cost += float64(nav.hazards[i]) * nav.costs.Hazard
+		}
+		nav.setCost(i, cost)
+	}
+}
+
+// setCost changes a tile's cost and invalidates cached paths.
+func (nav *NavGrid) setCost(i int, c float64) {
+	if nav.cost[i] == c {
+		return
+	}
+	nav.cost[i] = c
+	nav.version++
+	clear(nav.cache)
+}
+
+// FindPath returns a smoothed path of tile-centre waypoints from start to
+// goal, beginning at start. When the goal cannot be reached within
+// MaxExpansions, the path leads to the explored tile closest to it and
+// complete is false. The path is nil if start is not walkable. Paths are
+// cached and must not be modified.
+func (nav *NavGrid) FindPath(start, goal Coord) (path []Waypoint, complete bool) {
+	if !nav.Walkable(start.X, start.Y) {
+		return nil, false
+	}
+	key := navKey{start, goal}
+	if p, ok := nav.cache[key]; ok {
+		return p.waypoints, p.complete
+	}
+
+	end, complete := nav.search(start, goal)
+	path = nav.smooth(nav.trace(end))
+	if len(nav.cache) >= navCacheSize {
+		clear(nav.cache)
+	}
+	nav.cache[key] = navPath{waypoints: path, complete: complete}
+	return path, complete
+}
+
+// search runs A* from start toward goal and returns the goal tile, or the
+// explored tile closest to it when the goal was not reached.
+func (nav *NavGrid) search(start, goal Coord) (end int32, complete bool) {
+	nav.searchID++
+	nav.open = nav.open[:0]
+
+	s := int32(start.Y*nav.width + start.X)
+	target := int32(-1)
+	if nav.inBounds(goal.X, goal.Y) {
+		target = int32(goal.Y*nav.width + goal.X)
+	}
+	nav.visit(s, -1, 0)
+	nav.push(s, manhattanDistance(start, goal))
+
+	best, bestH := s, manhattanDistance(start, goal)
+	for expanded := 0; len(nav.open) > 0 && expanded < nav.MaxExpansions; expanded++ {
+		cur := nav.pop()
+		if nav.closed[cur] {
+			continue
+		}
+		nav.closed[cur] = true
+		if cur == target {
+			return cur, true
+		}
+		cx, cy := int(cur)%nav.width, int(cur)/nav.width
+		if h := manhattanDistance(Coord{cx, cy}, goal); h < bestH {
+			best, bestH = cur, h
+		}
+
+		for _, d := range [4]Coord{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+			nx, ny := cx+d.X, cy+d.Y
+			if !nav.Walkable(nx, ny) {
+				continue
+			}
+			n := int32(ny*nav.width + nx)
+			g := nav.g[cur] + nav.cost[n]
+			if nav.stamp[n] == nav.searchID && (nav.closed[n] || g >= nav.g[n]) {
+				continue
+			}
+			nav.visit(n, cur, g)
+			nav.push(n, g+manhattanDistance(Coord{nx, ny}, goal))
+		}
+	}
+	return best, false
+}
+
+// visit records the best known route to a tile for the current search.
+func (nav *NavGrid) visit(tile, from int32, g float64) {
+	if nav.stamp[tile] != nav.searchID {
+		nav.stamp[tile] = nav.searchID
+		nav.closed[tile] = false
+	}
+	nav.g[tile] = g
+	nav.from[tile] = from
+}
+
+// push adds a tile to the open set, a binary min-heap on f.
+func (nav *NavGrid) push(tile int32, f float64) {
+	nav.open = append(nav.open, navOpen{tile, f})
+	for i := len(nav.open) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if nav.open[parent].f <= nav.open[i].f {
+			break
+		}
+		nav.open[parent], nav.open[i] = nav.open[i], nav.open[parent]
+		i = parent
+	}
+}
+
+// pop removes and returns the open tile with the lowest f.
+func (nav *NavGrid) pop() int32 {
+	top := nav.open[0].tile
+	last := len(nav.open) - 1
+	nav.open[0] = nav.open[last]
+	nav.open = nav.open[:last]
+	for i := 0; ; {
+		small := i
+		for _, c := range [2]int{2*i + 1, 2*i + 2} {
+			if c < len(nav.open) && nav.open[c].f < nav.open[small].f {
+				small = c
+			}
+		}
+		if small == i {
+			return top
+		}
+		nav.open[small], nav.open[i] = nav.open[i], nav.open[small]
+		i = small
+	}
+}
+
+// trace returns the tiles of the search's route to end, from the start.
+func (nav *NavGrid) trace(end int32) []Coord {
+	n := 0
+	for t := end; t >= 0; t = nav.from[t] {
+		n++
+	}
+	tiles := make([]Coord, n)
+	for t := end; t >= 0; t = nav.from[t] {
+		n--
+		tiles[n] = Coord{int(t) % nav.width, int(t) / nav.width}
+	}
+	return tiles
+}
+
+// smooth drops the tiles of a route that a straight walk can skip and
+// returns the rest as tile-centre waypoints. Shortcuts may only cross plain
+// floor, so costly tiles the route deliberately crosses are kept.
+func (nav *NavGrid) smooth(tiles []Coord) []Waypoint {
+	if len(tiles) == 0 {
+		return nil
+	}
+	path := []Waypoint{tileCentre(tiles[0])}
+	anchor := 0
+	for i := 2; i < len(tiles); i++ {
+		if !nav.clearLine(tiles[anchor], tiles[i]) {
+			anchor = i - 1
+			path = append(path, tileCentre(tiles[anchor]))
+		}
+	}
+	if len(tiles) > 1 {
+		path = append(path, tileCentre(tiles[len(tiles)-1]))
+	}
+	return path
+}
+
+// clearLine reports whether the segment between two tile centres crosses
+// only plain floor, besides its end tiles.
+func (nav *NavGrid) clearLine(a, b Coord) bool {
+	ok := true
+	walkLine(tileCentre(a), tileCentre(b), func(x, y int) bool {
+		if (x != a.X || y != a.Y) && (x != b.X || y != b.Y) && (!nav.inBounds(x, y) || nav.cost[y*nav.width+x] != 1) {
+			ok = false
+		}
+		return ok
+	})
+	return ok
+}
+
+// walkLine calls visit for every tile the segment from a to b touches, in
+// order, until visit returns false.
+func walkLine(a, b Waypoint, visit func(x, y int) bool) {
+	x, y := int(math.Floor(a.X)), int(math.Floor(a.Y))
+	endX, endY := int(math.Floor(b.X)), int(math.Floor(b.Y))
+	dx, dy := b.X-a.X, b.Y-a.Y
+
+	stepX, stepY := 1, 1
+	if dx < 0 {
+		stepX = -1
+	}
+	if dy < 0 {
+		stepY = -1
+	}
+	// Distance along the segment, as a fraction, to the next tile edge on
+	// each axis and between tile edges.
+	nextX, deltaX := math.Inf(1), math.Inf(1)
+	if dx != 0 {
+		deltaX = math.Abs(1 / dx)
+		edge := math.Floor(a.X) + 1
+		if stepX < 0 {
+			edge = math.Floor(a.X)
+		}
+		nextX = (edge - a.X) / dx
+	}
+	nextY, deltaY := math.Inf(1), math.Inf(1)
+	if dy != 0 {
+		deltaY = math.Abs(1 / dy)
+		edge := math.Floor(a.Y) + 1
+		if stepY < 0 {
+			edge = math.Floor(a.Y)
+		}
+		nextY = (edge - a.Y) / dy
+	}
+
+	for visit(x, y) {
+		if x == endX && y == endY {
+			return
+		}
+		switch {
+		case nextX < nextY:
+			x += stepX
+			nextX += deltaX
+		case nextY < nextX:
+			y += stepY
+			nextY += deltaY
+		default:
+			// Passing exactly through a corner touches both side tiles.
+			if !visit(x+stepX, y) || !visit(x, y+stepY) {
+				return
+			}
+			x += stepX
+			y += stepY
+			nextX += deltaX
+			nextY += deltaY
+		}
+	}
+}
+
+// tileCentre returns the waypoint at the centre of a tile.
+func tileCentre(c Coord) Waypoint {
+	return Waypoint{X: float64(c.X) + 0.5, Y: float64(c.Y) + 0.5}
+}
+
+// MoveToward walks the agent up to step world units along a planned path to
+// (x, y), replanning when the goal tile or the grid changes. It returns true
+// once the agent stands at the goal. When the goal is unreachable the agent
+// walks as close as it can get.
+func (agent *Agent) MoveToward(nav *NavGrid, x, y, step float64) bool {
+	goal := Coord{int(math.Floor(x)), int(math.Floor(y))}
+	if agent.Path == nil || goal != agent.pathGoal || nav.Version() != agent.pathVersion {
+		path, complete := nav.FindPath(Coord{int(math.Floor(agent.X)), int(math.Floor(agent.Y))}, goal)
+		if path == nil {
+			return false
+		}
+		agent.Path, agent.PathIndex = path, min(1, len(path)-1)
+		agent.pathGoal, agent.pathVersion, agent.pathComplete = goal, nav.Version(), complete
+	}
+
+	for step > 0 && agent.PathIndex < len(agent.Path) {
+		target := agent.Path[agent.PathIndex]
+		if agent.PathIndex == len(agent.Path)-1 && agent.pathComplete {
+			target = Waypoint{X: x, Y: y} // Finish at the goal itself, not its tile centre
+		}
+		dx, dy := target.X-agent.X, target.Y-agent.Y
+		dist := math.Hypot(dx, dy)
+		if dist > 0.01 {
+			agent.DirX, agent.DirY = dx/dist, dy/dist
+		}
+		if dist <= step {
+			agent.X, agent.Y = target.X, target.Y
+			agent.PathIndex++
+			step -= dist
+			continue
+		}
+		agent.X += dx / dist * step
+		agent.Y += dy / dist * step
+		return false
+	}
+	if agent.PathIndex >= len(agent.Path) && !agent.pathComplete {
+		agent.Path = nil // Replan next time in case the way has opened up
+	}
+	return agent.PathIndex >= len(agent.Path) && agent.pathComplete
+}
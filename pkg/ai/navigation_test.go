@@ -0,0 +1,279 @@
+package ai
+
+import (
+	"math"
+	"testing"
+)
+
+// navMap builds a tile map from rows of '#' walls, '.' floor, 'D' doors.
+func navMap(rows ...string) [][]int {
+	tiles := make([][]int, len(rows))
+	for y, row := range rows {
+		tiles[y] = make([]int, len(row))
+		for x, c := range row {
+			switch c {
+			case '#':
+				tiles[y][x] = 1
+			case 'D':
+				tiles[y][x] = 3
+			default:
+				tiles[y][x] = 2
+			}
+		}
+	}
+	return tiles
+}
+
+func TestNavGridFindPath(t *testing.T) {
+	tests := []struct {
+		name         string
+		tiles        [][]int
+		start, goal  Coord
+		want         []Waypoint
+		wantComplete bool
+	}{
+		{
+			name:         "open room smooths to a straight line",
+			tiles:        navMap(".....", ".....", "....."),
+			start:        Coord{0, 0},
+			goal:         Coord{4, 2},
+			want:         []Waypoint{{0.5, 0.5}, {4.5, 2.5}},
+			wantComplete: true,
+		},
+		{
+			name:         "around a wall",
+			tiles:        navMap("..#..", "..#..", "....."),
+			start:        Coord{0, 0},
+			goal:         Coord{4, 0},
+			wantComplete: true,
+		},
+		{
+			name:         "same tile",
+			tiles:        navMap("..."),
+			start:        Coord{1, 0},
+			goal:         Coord{1, 0},
+			want:         []Waypoint{{1.5, 0.5}},
+			wantComplete: true,
+		},
+		{
+			name:  "walled off goal gives a partial path",
+			tiles: navMap("...#.", "...#.", "...#."),
+			start: Coord{0, 1},
+			goal:  Coord{4, 1},
+			want:  []Waypoint{{0.5, 1.5}, {2.5, 1.5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nav := NewNavGrid(tt.tiles, DefaultNavCosts)
+			path, complete := nav.FindPath(tt.start, tt.goal)
+			if complete != tt.wantComplete {
+				t.Errorf("complete = %v, want %v", complete, tt.wantComplete)
+			}
+			if tt.want != nil && !equalWaypoints(path, tt.want) {
+				t.Errorf("path = %v, want %v", path, tt.want)
+			}
+			assertWalkablePath(t, nav, path)
+		})
+	}
+}
+
+func TestNavGridFindPath_BlockedStart(t *testing.T) {
+	nav := NewNavGrid(navMap("#.."), DefaultNavCosts)
+	if path, complete := nav.FindPath(Coord{0, 0}, Coord{2, 0}); path != nil || complete {
+		t.Errorf("FindPath() from a wall = %v, %v, want nil, false", path, complete)
+	}
+}
+
+func TestNavGridCosts(t *testing.T) {
+	// Through the door is 2 steps plus the door cost; around is 12.
+	tiles := navMap(
+		"......",
+		"D####.",
+		"......",
+	)
+	tests := []struct {
+		name     string
+		costs    NavCosts
+		wantDoor bool
+	}{
+		{"cheap door", NavCosts{Door: 2}, true},
+		{"slow door", NavCosts{Door: 20}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nav := NewNavGrid(tiles, tt.costs)
+			path, complete := nav.FindPath(Coord{0, 0}, Coord{0, 2})
+			if !complete || pathCrosses(path, Coord{0, 1}) != tt.wantDoor {
+				t.Errorf("path = %v, want through the door %v", path, tt.wantDoor)
+			}
+		})
+	}
+
+	// Agents that cannot open doors go around until the door is opened.
+	nav := NewNavGrid(tiles, DefaultNavCosts)
+	if path, _ := nav.FindPath(Coord{0, 0}, Coord{0, 2}); pathCrosses(path, Coord{0, 1}) {
+		t.Errorf("path = %v, want around the closed door", path)
+	}
+	nav.SetBlocked(0, 1, false)
+	if path, _ := nav.FindPath(Coord{0, 0}, Coord{0, 2}); !pathCrosses(path, Coord{0, 1}) {
+		t.Errorf("path = %v, want through the opened door", path)
+	}
+
+	// With two ways across, a hazard pushes agents to the other one.
+	tiles = navMap(
+		"....",
+		".##.",
+		"....",
+	)
+	nav = NewNavGrid(tiles, DefaultNavCosts)
+	nav.MarkHazard(0, 1)
+	path, _ := nav.FindPath(Coord{0, 0}, Coord{0, 2})
+	if pathCrosses(path, Coord{0, 1}) {
+		t.Errorf("path = %v, want around the hazard", path)
+	}
+}
+
+func TestNavGridApply(t *testing.T) {
+	nav := NewNavGrid(navMap(
+		"..#..",
+		"..#..",
+		"..#..",
+	), DefaultNavCosts)
+	if _, complete := nav.FindPath(Coord{0, 1}, Coord{4, 1}); complete {
+		t.Fatal("path found through a solid wall")
+	}
+
+	v := nav.Version()
+	nav.Apply(TileChange{X: 2, Y: 1, Tile: 2})
+	if nav.Version() == v {
+		t.Error("Version() unchanged after a wall became floor")
+	}
+	path, complete := nav.FindPath(Coord{0, 1}, Coord{4, 1})
+	if !complete || !pathCrosses(path, Coord{2, 1}) {
+		t.Errorf("path = %v, want through the broken wall", path)
+	}
+
+	// Re-applying the same tile changes nothing.
+	v = nav.Version()
+	nav.Apply(TileChange{X: 2, Y: 1, Tile: 0}, TileChange{X: -1, Y: 9, Tile: 1})
+	if nav.Version() != v {
+		t.Error("Version() changed though no cost did")
+	}
+
+	// Hazards marked on a tile survive it changing type.
+	nav.MarkHazard(1, 0)
+	nav.Apply(TileChange{X: 1, Y: 0, Tile: 1})
+	if nav.Walkable(1, 0) {
+		t.Error("tile still walkable after becoming a wall")
+	}
+	nav.Apply(TileChange{X: 1, Y: 0, Tile: 2})
+	if got, want := nav.cost[1], 1+DefaultNavCosts.Hazard; got != want {
+		t.Errorf("reopened hazard tile cost = %v, want %v", got, want)
+	}
+}
+
+func TestNavGridCacheInvalidation(t *testing.T) {
+	nav := NewNavGrid(navMap(".....", "....."), DefaultNavCosts)
+	first, _ := nav.FindPath(Coord{0, 0}, Coord{4, 0})
+	again, _ := nav.FindPath(Coord{0, 0}, Coord{4, 0})
+	if &first[0] != &again[0] {
+		t.Error("repeated FindPath() did not use the cache")
+	}
+
+	v := nav.Version()
+	nav.SetBlocked(2, 0, true)
+	if nav.Version() == v {
+		t.Error("Version() unchanged after SetBlocked()")
+	}
+	path, complete := nav.FindPath(Coord{0, 0}, Coord{4, 0})
+	if !complete || pathCrosses(path, Coord{2, 0}) {
+		t.Errorf("path = %v after blocking (2, 0), want a detour", path)
+	}
+}
+
+func TestNavGridMaxExpansions(t *testing.T) {
+	nav := NewNavGrid(navMap("........................"), DefaultNavCosts)
+	nav.MaxExpansions = 5
+	path, complete := nav.FindPath(Coord{0, 0}, Coord{23, 0})
+	if complete {
+		t.Fatal("search with a tiny budget reached a far goal")
+	}
+	if end := path[len(path)-1]; end.X <= 0.5 || end.X >= 23.5 {
+		t.Errorf("partial path ends at %v, want between start and goal", end)
+	}
+}
+
+func TestAgentMoveToward(t *testing.T) {
+	nav := NewNavGrid(navMap(
+		".....",
+		"###..",
+		".....",
+	), DefaultNavCosts)
+	agent := &Agent{X: 0.5, Y: 0.5, Speed: 0.2}
+
+	arrived := false
+	for i := 0; i < 200 && !arrived; i++ {
+		arrived = agent.MoveToward(nav, 0.5, 2.5, agent.Speed)
+		if !nav.Walkable(int(agent.X), int(agent.Y)) {
+			t.Fatalf("agent walked into a wall at (%v, %v)", agent.X, agent.Y)
+		}
+	}
+	if !arrived || math.Hypot(agent.X-0.5, agent.Y-2.5) > 1e-9 {
+		t.Errorf("agent at (%v, %v), arrived %v, want at the goal", agent.X, agent.Y, arrived)
+	}
+}
+
+func TestActionChase_UsesNavGrid(t *testing.T) {
+	tiles := navMap(
+		"..#..",
+		"..#..",
+		".....",
+	)
+	agent := &Agent{X: 0.5, Y: 0.5, Speed: 0.1}
+	ctx := &Context{TileMap: tiles, PlayerX: 4.5, PlayerY: 0.5, Nav: NewNavGrid(tiles, DefaultNavCosts)}
+
+	for i := 0; i < 300; i++ {
+		actionChase(agent, ctx)
+	}
+	if math.Hypot(agent.X-4.5, agent.Y-0.5) > 1e-9 {
+		t.Errorf("agent at (%v, %v), want to have reached the player round the wall", agent.X, agent.Y)
+	}
+}
+
+func equalWaypoints(a, b []Waypoint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pathCrosses reports whether walking the path touches the tile.
+func pathCrosses(path []Waypoint, tile Coord) bool {
+	crosses := false
+	for i := 1; i < len(path); i++ {
+		walkLine(path[i-1], path[i], func(x, y int) bool {
+			crosses = crosses || (x == tile.X && y == tile.Y)
+			return true
+		})
+	}
+	return crosses
+}
+
+// assertWalkablePath fails if walking the path touches a blocked tile.
+func assertWalkablePath(t *testing.T, nav *NavGrid, path []Waypoint) {
+	t.Helper()
+	for i := 1; i < len(path); i++ {
+		walkLine(path[i-1], path[i], func(x, y int) bool {
+			if !nav.Walkable(x, y) {
+				t.Errorf("path %v crosses blocked tile (%d, %d)", path, x, y)
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package ai
+
+import (
+	"math"
+
+	"github.com/opd-ai/violence/pkg/engine"
+	"github.com/opd-ai/violence/pkg/spatial"
+)
+
+// AlertLevel is a state of an agent's alert state machine. Agents escalate
+// from Idle through Suspicious to Combat as they notice the player, search
+// the player's last known position after losing them, flee when badly hurt,
+// and calm down step by step when nothing more turns up.
+type AlertLevel int
+
+const (
+	// AlertIdle means the agent has noticed nothing.
+	AlertIdle AlertLevel = iota
+	// AlertSuspicious means the agent heard or glimpsed something and
+	// investigates it.
+	AlertSuspicious
+	// AlertSearching means the agent lost the player, or was told of them by
+	// its squad, and sweeps the area around their last known position.
+	AlertSearching
+	// AlertCombat means the agent has spotted the player.
+	AlertCombat
+	// AlertFlee means the agent is too hurt to fight and runs from the player.
+	AlertFlee
+)
+
+// String returns the alert level's name.
+func (l AlertLevel) String() string {
+	switch l {
+	case AlertSuspicious:
+		return "suspicious"
+	case AlertSearching:
+		return "searching"
+	case AlertCombat:
+		return "combat"
+	case AlertFlee:
+		return "flee"
+	default:
+		return "idle"
+	}
+}
+
+// alertLevels lists every alert level, for registering conditions.
+var alertLevels = []AlertLevel{AlertIdle, AlertSuspicious, AlertSearching, AlertCombat, AlertFlee}
+
+// DefaultViewAngle is the half-angle of an agent's vision cone, in radians,
+// used when the agent's ViewAngle is unset.
+const DefaultViewAngle = math.Pi / 3
+
+// Perception tuning. Awareness runs from 0 to 1 and is gained per tick;
+// durations are in ticks.
+const (
+	// nearSenseRadius is how close the player can be before an agent
+	// notices them whichever way it faces.
+	nearSenseRadius = 1.5
+	// hearingAwareness caps the awareness a sound gives, so hearing alone
+	// never starts combat.
+	hearingAwareness = 0.9
+	// sightGainNear and sightGainFar are the awareness gained per tick of
+	// seeing the player up close and at the edge of sight.
+	sightGainNear = 0.5
+	sightGainFar  = 0.05
+	// awarenessDecay is the awareness lost per tick without seeing the player.
+	awarenessDecay = 0.002
+	// suspiciousTicks is how long an agent stays suspicious after the last
+	// thing it noticed.
+	suspiciousTicks = 240
+	// combatLoseTicks is how long an agent fights on after losing sight of
+	// the player before it starts searching.
+	combatLoseTicks = 90
+	// searchTicks is how long an agent searches before giving up.
+	searchTicks = 600
+	// fleeTicks is how long a fleeing agent runs once out of sight.
+	fleeTicks = 180
+	// searchRadius is how far from the last known position searches range.
+	searchRadius = 4.0
+	// perceptionCellSize is the spatial grid cell size, near the typical
+	// sight range.
+	perceptionCellSize = 8
+	// tickSeconds is the duration of a perception tick.
+	tickSeconds = 1.0 / 60
+)
+
+// Blackboard keys written by perception and the search action.
+const (
+	// KeyInvestigate is true while the agent has something to investigate
+	// at KeyInvestigateX and KeyInvestigateY.
+	KeyInvestigate  = "investigate"
+	KeyInvestigateX = "investigate_x"
+	KeyInvestigateY = "investigate_y"
+	// KeySearchX and KeySearchY hold the point being searched while
+	// KeySearchPoint is true; KeySearchStep counts points searched.
+	KeySearchPoint = "search_point"
+	KeySearchX     = "search_x"
+	KeySearchY     = "search_y"
+	KeySearchStep  = "search_step"
+)
+
+// Sound is a noise agents may hear, such as gunfire or an explosion.
+type Sound struct {
+	X, Y float64
+	// Loudness scales how far the sound carries: at 1 it reaches an
+	// agent's HearRadius.
+	Loudness float64
+}
+
+// Perception decides what each agent sees and hears every tick and steps
+// agents through their alert states. Agents see the player inside a vision
+// cone with a clear line of sight, and hear sounds with linear falloff over
+// a range that halves through walls. An agent entering combat alerts the
+// rest of its squad, who search where it saw the player.
+type Perception struct {
+	// OnAlertChange, if set, is called when an agent's alert level changes.
+	OnAlertChange func(agent *Agent, from, to AlertLevel)
+
+	grid   *spatial.Grid
+	sounds []Sound
+	squads map[string]*SquadTactics
+}
+
+// NewPerception creates a perception system with no pending sounds.
+func NewPerception() *Perception {
+	return &Perception{
+		grid:   spatial.NewGrid(perceptionCellSize),
+		squads: make(map[string]*SquadTactics),
+	}
+}
+
+// EmitSound queues a sound to be heard on the next Update.
+func (p *Perception) EmitSound(x, y, loudness float64) {
+	if loudness <= 0 {
+		return
+	}
+	p.sounds = append(p.sounds, Sound{X: x, Y: y, Loudness: loudness})
+}
+
+// Pending returns the sounds queued for the next Update.
+func (p *Perception) Pending() []Sound {
+	return p.sounds
+}
+
+// Squad returns the tactics of the squad with the given ID, or nil if no
+// agent of the squad has been updated.
+func (p *Perception) Squad(id string) *SquadTactics {
+	return p.squads[id]
+}
+
+// Clear drops pending sounds and squads, e.g. when a level is generated.
+func (p *Perception) Clear() {
+	p.sounds = p.sounds[:0]
+	p.squads = make(map[string]*SquadTactics)
+}
+
+// Update lets every living agent look for the player at ctx.PlayerX and
+// ctx.PlayerY and listen to the pending sounds, then advances their alert
+// states by one tick. Sounds are consumed.
+func (p *Perception) Update(agents []*Agent, ctx *Context) {
+	p.grid.Clear()
+	var maxSight, maxHearing float64
+	for i, agent := range agents {
+		agent.SeesPlayer = false
+		agent.heard = false
+		if agent.Health <= 0 {
+			continue
+		}
+		p.grid.Insert(engine.Entity(i), agent.X, agent.Y)
+		maxSight = math.Max(maxSight, math.Max(agent.AlertRadius, nearSenseRadius))
+		maxHearing = math.Max(maxHearing, agent.HearRadius)
+		if agent.SquadID != "" {
+			p.squad(agent.SquadID).AddMember(agent.ID)
+		}
+	}
+	for _, st := range p.squads {
+		st.DecayAlert(tickSeconds)
+	}
+
+	for _, s := range p.sounds {
+		for _, e := range p.grid.QueryRadius(s.X, s.Y, maxHearing*s.Loudness) {
+			agent := agents[e]
+			if heard := Hearing(agent, s, ctx.TileMap); heard > 0 {
+				agent.hear(s, heard)
+			}
+		}
+	}
+	p.sounds = p.sounds[:0]
+
+	for _, e := range p.grid.QueryRadius(ctx.PlayerX, ctx.PlayerY, maxSight) {
+		agent := agents[e]
+		agent.SeesPlayer = CanSee(agent, ctx.PlayerX, ctx.PlayerY, ctx.TileMap)
+	}
+
+	for _, agent := range agents {
+		if agent.Health <= 0 {
+			continue
+		}
+		if agent.SeesPlayer {
+			agent.see(ctx.PlayerX, ctx.PlayerY)
+		} else {
+			agent.Awareness = math.Max(agent.Awareness-awarenessDecay, 0)
+		}
+		from := agent.Alert
+		p.setAlert(agent, agent.nextAlert())
+		if agent.Alert == AlertCombat && from != AlertCombat && from != AlertFlee {
+			p.alertSquad(agent, agents)
+		}
+	}
+}
+
+// Dispatch sends an agent to search around (x, y), as if alerted by its
+// squad, unless it is already fighting or fleeing.
+func (p *Perception) Dispatch(agent *Agent, x, y float64) {
+	if agent.Alert == AlertCombat || agent.Alert == AlertFlee {
+		return
+	}
+	agent.LastKnownX, agent.LastKnownY = x, y
+	agent.investigate(x, y)
+	agent.alertTimer = searchTicks
+	p.setAlert(agent, AlertSearching)
+}
+
+// squad returns the tactics of a squad, creating them on first use.
+func (p *Perception) squad(id string) *SquadTactics {
+	st, ok := p.squads[id]
+	if !ok {
+		st = NewSquadTactics(id)
+		p.squads[id] = st
+	}
+	return st
+}
+
+// setAlert moves the agent to a level, notifying OnAlertChange.
+func (p *Perception) setAlert(agent *Agent, to AlertLevel) {
+	from := agent.Alert
+	agent.Alert = to
+	if to == AlertIdle {
+		agent.Blackboard.Delete(KeyInvestigate)
+		agent.Blackboard.Delete(KeySearchPoint)
+	}
+	if to != from && p.OnAlertChange != nil {
+		p.OnAlertChange(agent, from, to)
+	}
+}
+
+// alertSquad sends the rest of the agent's squad to search where the agent
+// saw the player. Squad members already fighting or fleeing carry on.
+func (p *Perception) alertSquad(agent *Agent, agents []*Agent) {
+	if agent.SquadID == "" {
+		return
+	}
+	p.squad(agent.SquadID).RaiseAlert(1)
+	for _, mate := range agents {
+		if mate == agent || mate.SquadID != agent.SquadID || mate.Health <= 0 {
+			continue
+		}
+		p.Dispatch(mate, agent.LastKnownX, agent.LastKnownY)
+	}
+}
+
+// nextAlert advances the agent's alert timer and returns its next state.
+func (agent *Agent) nextAlert() AlertLevel {
+	if agent.alertTimer > 0 {
+		agent.alertTimer--
+	}
+	hurt := agent.MaxHealth > 0 && agent.Health < agent.MaxHealth*agent.RetreatHealthRatio
+
+	switch {
+	case agent.Alert == AlertFlee:
+		if agent.SeesPlayer {
+			agent.alertTimer = fleeTicks
+		} else if agent.alertTimer == 0 {
+			return agent.startSearch()
+		}
+		return AlertFlee
+	case agent.SeesPlayer && agent.Awareness >= 1, agent.Alert == AlertCombat && hurt:
+		if hurt {
+			agent.alertTimer = fleeTicks
+			return AlertFlee
+		}
+		agent.alertTimer = combatLoseTicks
+		return AlertCombat
+	case agent.Alert == AlertCombat:
+		if agent.alertTimer == 0 {
+			return agent.startSearch()
+		}
+		return AlertCombat
+	case agent.heard || agent.SeesPlayer:
+		if agent.Alert == AlertSearching {
+			agent.alertTimer = searchTicks
+			return AlertSearching
+		}
+		agent.alertTimer = suspiciousTicks
+		return AlertSuspicious
+	case agent.alertTimer > 0:
+		return agent.Alert
+	case agent.Alert == AlertSearching:
+		agent.alertTimer = suspiciousTicks
+		return AlertSuspicious
+	default:
+		return AlertIdle
+	}
+}
+
+// startSearch sends the agent to search the player's last known position.
+func (agent *Agent) startSearch() AlertLevel {
+	agent.investigate(agent.LastKnownX, agent.LastKnownY)
+	agent.Blackboard.Delete(KeySearchPoint)
+	agent.alertTimer = searchTicks
+	return AlertSearching
+}
+
+// CanSee reports whether the agent can see the point: within its
+// AlertRadius and vision cone with a clear line of sight, or close enough
+// to sense whichever way it faces.
+func CanSee(agent *Agent, x, y float64, tileMap [][]int) bool {
+	dx, dy := x-agent.X, y-agent.Y
+	dist := math.Hypot(dx, dy)
+	if dist > math.Max(agent.AlertRadius, nearSenseRadius) {
+		return false
+	}
+	if dist > nearSenseRadius && !inViewCone(agent, dx/dist, dy/dist) {
+		return false
+	}
+	return lineOfSight(agent.X, agent.Y, x, y, tileMap)
+}
+
+// inViewCone reports whether the unit direction lies in the agent's vision
+// cone. An agent with no facing looks all around.
+func inViewCone(agent *Agent, dx, dy float64) bool {
+	facing := math.Hypot(agent.DirX, agent.DirY)
+	if facing == 0 {
+		return true
+	}
+	angle := agent.ViewAngle
+	if angle <= 0 {
+		angle = DefaultViewAngle
+	}
+	return (dx*agent.DirX+dy*agent.DirY)/facing >= math.Cos(angle)
+}
+
+// Hearing returns how loudly the agent hears the sound, from 0 (out of
+// earshot) to the sound's loudness (at its source). The sound carries
+// HearRadius times its loudness, half as far without a line of sight.
+func Hearing(agent *Agent, s Sound, tileMap [][]int) float64 {
+	reach := agent.HearRadius * s.Loudness
+	if !lineOfSight(s.X, s.Y, agent.X, agent.Y, tileMap) {
+		reach /= 2
+	}
+	dist := math.Hypot(s.X-agent.X, s.Y-agent.Y)
+	if reach <= 0 || dist >= reach {
+		return 0
+	}
+	return s.Loudness * (1 - dist/reach)
+}
+
+// hear makes the agent notice a sound heard at the given volume and turns
+// it to face the sound.
+func (agent *Agent) hear(s Sound, volume float64) {
+	agent.heard = true
+	agent.Awareness = math.Max(agent.Awareness, hearingAwareness*math.Min(volume, 1))
+	agent.investigate(s.X, s.Y)
+	if dx, dy := s.X-agent.X, s.Y-agent.Y; dx != 0 || dy != 0 {
+		d := math.Hypot(dx, dy)
+		agent.DirX, agent.DirY = dx/d, dy/d
+	}
+}
+
+// see raises the agent's awareness of the player at (x, y), faster the
+// closer they are, and notes where they were. Until fully aware it
+// investigates what it glimpsed.
+func (agent *Agent) see(x, y float64) {
+	reach := math.Max(agent.AlertRadius, nearSenseRadius)
+	closeness := 1 - math.Hypot(x-agent.X, y-agent.Y)/reach
+	agent.Awareness = math.Min(agent.Awareness+sightGainFar+(sightGainNear-sightGainFar)*closeness, 1)
+	agent.LastKnownX, agent.LastKnownY = x, y
+	if agent.Awareness < 1 {
+		agent.investigate(x, y)
+	}
+}
+
+// investigate notes a point for the agent to check out.
+func (agent *Agent) investigate(x, y float64) {
+	agent.Blackboard.Set(KeyInvestigate, true)
+	agent.Blackboard.Set(KeyInvestigateX, x)
+	agent.Blackboard.Set(KeyInvestigateY, y)
+}
+
+// actionSearchArea sweeps points around the player's last known position
+// while the agent is searching, spiralling outward by the golden angle so
+// successive points cover the area evenly.
+func actionSearchArea(agent *Agent, ctx *Context) NodeStatus {
+	if agent.Alert != AlertSearching {
+		return StatusFailure
+	}
+	agent.State = StateAlert
+	if !agent.Blackboard.Bool(KeySearchPoint) {
+		agent.pickSearchPoint(ctx)
+	}
+	x, y := agent.Blackboard.Float(KeySearchX), agent.Blackboard.Float(KeySearchY)
+	arrived := math.Hypot(x-agent.X, y-agent.Y) < 0.5
+	if !arrived && ctx.Nav != nil {
+		arrived = agent.MoveToward(ctx.Nav, x, y, agent.Speed)
+	} else if !arrived {
+		dx, dy := x-agent.X, y-agent.Y
+		d := math.Hypot(dx, dy)
+		agent.DirX, agent.DirY = dx/d, dy/d
+		moveX, moveY := agent.X+agent.DirX*agent.Speed, agent.Y+agent.DirY*agent.Speed
+		if !isWalkable(moveX, moveY, ctx.TileMap) {
+			arrived = true // Blocked; try another point
+		} else {
+			agent.X, agent.Y = moveX, moveY
+		}
+	}
+	if arrived {
+		agent.Blackboard.Delete(KeySearchPoint)
+	}
+	return StatusRunning
+}
+
+// pickSearchPoint chooses the next walkable point to search, falling back to
+// the last known position itself.
+func (agent *Agent) pickSearchPoint(ctx *Context) {
+	const goldenAngle = 2.399963229728653
+	x, y := agent.LastKnownX, agent.LastKnownY
+	step := agent.Blackboard.Float(KeySearchStep)
+	for tries := 0; tries < 8; tries++ {
+		step++
+		r := searchRadius * math.Sqrt(math.Mod(step*0.618033988749895, 1))
+		px := agent.LastKnownX + math.Cos(step*goldenAngle)*r
+		py := agent.LastKnownY + math.Sin(step*goldenAngle)*r
+		walkable := isWalkable(px, py, ctx.TileMap)
+		if ctx.Nav != nil {
+			walkable = ctx.Nav.Walkable(int(px), int(py))
+		}
+		if walkable {
+			x, y = px, py
+			break
+		}
+	}
+	agent.Blackboard.Set(KeySearchStep, step)
+	agent.Blackboard.Set(KeySearchPoint, true)
+	agent.Blackboard.Set(KeySearchX, x)
+	agent.Blackboard.Set(KeySearchY, y)
+}
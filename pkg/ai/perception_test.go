@@ -0,0 +1,286 @@
+package ai
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCanSee(t *testing.T) {
+	open := navMap(
+		"...........",
+		"...........",
+		"...........",
+	)
+	walled := navMap(
+		"...........",
+		".....#.....",
+		"...........",
+	)
+	tests := []struct {
+		name   string
+		tiles  [][]int
+		dirX   float64
+		px, py float64
+		want   bool
+	}{
+		{"ahead", open, 1, 8.5, 1.5, true},
+		{"behind", open, -1, 8.5, 1.5, false},
+		{"beyond sight", open, 1, 10.9, 1.5, false},
+		{"behind a wall", walled, 1, 8.5, 1.5, false},
+		{"near sense from behind", open, -1, 3.5, 1.5, true},
+		{"edge of cone", open, 1, 5.5, 2.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &Agent{X: 2.5, Y: 1.5, DirX: tt.dirX, AlertRadius: 8}
+			if got := CanSee(agent, tt.px, tt.py, tt.tiles); got != tt.want {
+				t.Errorf("CanSee() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	narrow := &Agent{X: 2.5, Y: 1.5, DirX: 1, AlertRadius: 8, ViewAngle: 0.1}
+	if CanSee(narrow, 5.5, 2.5, open) {
+		t.Error("CanSee() outside a narrow ViewAngle = true, want false")
+	}
+}
+
+func TestHearing(t *testing.T) {
+	open := navMap("....................")
+	walled := navMap(".....#..............")
+	tests := []struct {
+		name     string
+		tiles    [][]int
+		sound    Sound
+		want     float64
+		wantZero bool
+	}{
+		{"at source", open, Sound{X: 0.5, Y: 0.5, Loudness: 1}, 1, false},
+		{"halfway", open, Sound{X: 5.5, Y: 0.5, Loudness: 1}, 0.5, false},
+		{"out of earshot", open, Sound{X: 15.5, Y: 0.5, Loudness: 1}, 0, true},
+		{"louder carries further", open, Sound{X: 15.5, Y: 0.5, Loudness: 2}, 0.5, false},
+		{"muffled by a wall", walled, Sound{X: 7.5, Y: 0.5, Loudness: 1}, 0, true},
+		{"near through a wall", walled, Sound{X: 3.5, Y: 0.5, Loudness: 1}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := &Agent{X: 0.5, Y: 0.5, HearRadius: 10}
+			got := Hearing(agent, tt.sound, tt.tiles)
+			if tt.wantZero != (got == 0) {
+				t.Fatalf("Hearing() = %v, want zero %v", got, tt.wantZero)
+			}
+			if tt.want != 0 && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Hearing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerceptionHearingAlerts(t *testing.T) {
+	tiles := navMap("....................")
+	near := &Agent{X: 2.5, Y: 0.5, DirX: -1, HearRadius: 10, Health: 1}
+	far := &Agent{X: 19.5, Y: 0.5, DirX: -1, HearRadius: 10, Health: 1}
+	p := NewPerception()
+	p.EmitSound(5.5, 0.5, 1)
+	p.Update([]*Agent{near, far}, &Context{TileMap: tiles, PlayerX: 5.5, PlayerY: 0.5})
+
+	if near.Alert != AlertSuspicious {
+		t.Errorf("near agent alert = %v, want suspicious", near.Alert)
+	}
+	if far.Alert != AlertIdle {
+		t.Errorf("far agent alert = %v, want idle", far.Alert)
+	}
+	if near.DirX != 1 {
+		t.Errorf("near agent facing (%v, %v), want toward the sound", near.DirX, near.DirY)
+	}
+	if !near.Blackboard.Bool(KeyInvestigate) || near.Blackboard.Float(KeyInvestigateX) != 5.5 {
+		t.Error("near agent is not investigating the sound")
+	}
+	if len(p.Pending()) != 0 {
+		t.Errorf("Pending() = %v after Update(), want none", p.Pending())
+	}
+}
+
+func TestPerceptionAlertTransitions(t *testing.T) {
+	tiles := navMap("....................")
+	agent := &Agent{X: 0.5, Y: 0.5, DirX: 1, AlertRadius: 10, Health: 1}
+	p := NewPerception()
+	var changes []AlertLevel
+	p.OnAlertChange = func(_ *Agent, _, to AlertLevel) { changes = append(changes, to) }
+	ctx := &Context{TileMap: tiles, PlayerX: 8.5, PlayerY: 0.5}
+
+	// A distant player takes a few ticks to notice.
+	p.Update([]*Agent{agent}, ctx)
+	if agent.Alert == AlertCombat {
+		t.Fatal("agent entered combat on the first glimpse")
+	}
+	for i := 0; i < 20 && agent.Alert != AlertCombat; i++ {
+		p.Update([]*Agent{agent}, ctx)
+	}
+	if agent.Alert != AlertCombat || !agent.SeesPlayer {
+		t.Fatalf("alert = %v, sees %v, want combat and seeing", agent.Alert, agent.SeesPlayer)
+	}
+	if !checkCanSeePlayer(agent, &Context{Perception: p}) {
+		t.Error("checkCanSeePlayer() = false in combat")
+	}
+
+	// Out of sight, the agent fights on a while, searches, then calms down.
+	agent.DirX = -1
+	ticks := 0
+	for ; agent.Alert != AlertIdle && ticks < 2000; ticks++ {
+		p.Update([]*Agent{agent}, ctx)
+	}
+	if want := combatLoseTicks + searchTicks + suspiciousTicks; ticks < want {
+		t.Errorf("agent calmed down after %d ticks, want at least %d", ticks, want)
+	}
+	want := []AlertLevel{AlertSuspicious, AlertCombat, AlertSearching, AlertSuspicious, AlertIdle}
+	if len(changes) != len(want) {
+		t.Fatalf("alert changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("alert changes = %v, want %v", changes, want)
+			break
+		}
+	}
+	if agent.Blackboard.Bool(KeyInvestigate) {
+		t.Error("idle agent still investigating")
+	}
+	if agent.LastKnownX != 8.5 {
+		t.Errorf("LastKnownX = %v, want 8.5", agent.LastKnownX)
+	}
+}
+
+func TestPerceptionSkipsDeadAgents(t *testing.T) {
+	dead := &Agent{X: 0.5, Y: 0.5, AlertRadius: 10, HearRadius: 10}
+	p := NewPerception()
+	p.EmitSound(1.5, 0.5, 1)
+	p.Update([]*Agent{dead}, &Context{TileMap: navMap("...."), PlayerX: 1.5, PlayerY: 0.5})
+	if dead.Alert != AlertIdle || dead.SeesPlayer {
+		t.Errorf("dead agent alert = %v, sees %v, want idle and blind", dead.Alert, dead.SeesPlayer)
+	}
+}
+
+func TestActionAlert_InvestigatesPerceivedPoint(t *testing.T) {
+	tiles := navMap(".....")
+	agent := &Agent{X: 0.5, Y: 0.5, Speed: 0.5, Alert: AlertSuspicious}
+	agent.investigate(3.5, 0.5)
+	ctx := &Context{TileMap: tiles, Perception: NewPerception(), Nav: NewNavGrid(tiles, DefaultNavCosts)}
+
+	status := StatusRunning
+	for i := 0; i < 20 && status == StatusRunning; i++ {
+		if !checkHeardGunshot(agent, ctx) {
+			t.Fatal("checkHeardGunshot() = false while investigating")
+		}
+		status = actionAlert(agent, ctx)
+	}
+	if status != StatusSuccess || math.Abs(agent.X-3.5) > 0.5 {
+		t.Errorf("status %v at x = %v, want success at the investigated point", status, agent.X)
+	}
+	if checkHeardGunshot(agent, ctx) {
+		t.Error("checkHeardGunshot() = true after investigating")
+	}
+}
+
+func TestPerceptionFlee(t *testing.T) {
+	tiles := navMap("....................")
+	agent := &Agent{X: 0.5, Y: 0.5, DirX: 1, AlertRadius: 10, Health: 10, MaxHealth: 10, RetreatHealthRatio: 0.25}
+	p := NewPerception()
+	ctx := &Context{TileMap: tiles, PlayerX: 2.5, PlayerY: 0.5, Perception: p}
+	for i := 0; i < 5; i++ {
+		p.Update([]*Agent{agent}, ctx)
+	}
+	if agent.Alert != AlertCombat || checkLowHealth(agent, ctx) {
+		t.Fatalf("healthy agent alert = %v, want combat without retreating", agent.Alert)
+	}
+
+	agent.Health = 1
+	p.Update([]*Agent{agent}, ctx)
+	if agent.Alert != AlertFlee || !checkLowHealth(agent, ctx) {
+		t.Errorf("hurt agent alert = %v, want flee", agent.Alert)
+	}
+
+	// Once the player is long out of sight, the agent searches again.
+	ctx.PlayerX = 19.5
+	agent.DirX = -1
+	for i := 0; i <= fleeTicks && agent.Alert == AlertFlee; i++ {
+		p.Update([]*Agent{agent}, ctx)
+	}
+	if agent.Alert != AlertSearching {
+		t.Errorf("alert = %v after fleeing out of sight, want searching", agent.Alert)
+	}
+}
+
+func TestPerceptionSquadAlert(t *testing.T) {
+	tiles := navMap(
+		"..........",
+		"#########.",
+		"..........",
+	)
+	spotter := &Agent{ID: "a", X: 0.5, Y: 0.5, DirX: 1, AlertRadius: 10, Health: 1, SquadID: "s"}
+	mate := &Agent{ID: "b", X: 0.5, Y: 2.5, DirX: -1, AlertRadius: 10, Health: 1, SquadID: "s"}
+	loner := &Agent{ID: "c", X: 1.5, Y: 2.5, DirX: -1, AlertRadius: 10, Health: 1, SquadID: "t"}
+	agents := []*Agent{spotter, mate, loner}
+	p := NewPerception()
+	ctx := &Context{TileMap: tiles, PlayerX: 3.5, PlayerY: 0.5}
+	for i := 0; i < 10 && spotter.Alert != AlertCombat; i++ {
+		p.Update(agents, ctx)
+	}
+
+	if spotter.Alert != AlertCombat {
+		t.Fatalf("spotter alert = %v, want combat", spotter.Alert)
+	}
+	if mate.Alert != AlertSearching || mate.LastKnownX != 3.5 || mate.LastKnownY != 0.5 {
+		t.Errorf("squad mate alert = %v searching (%v, %v), want searching (3.5, 0.5)", mate.Alert, mate.LastKnownX, mate.LastKnownY)
+	}
+	if loner.Alert != AlertIdle {
+		t.Errorf("other squad alert = %v, want idle", loner.Alert)
+	}
+	if st := p.Squad("s"); st == nil || st.AlertLevel == 0 || len(st.Members) != 2 {
+		t.Errorf("Squad(\"s\") = %+v, want an alerted squad of 2", st)
+	}
+}
+
+func TestActionSearchArea(t *testing.T) {
+	tiles := navMap(
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+		"..........",
+	)
+	agent := &Agent{X: 0.5, Y: 0.5, Speed: 0.2, Alert: AlertSearching, LastKnownX: 5, LastKnownY: 2.5}
+	ctx := &Context{TileMap: tiles, Perception: NewPerception(), Nav: NewNavGrid(tiles, DefaultNavCosts)}
+
+	visited := map[Coord]bool{}
+	for i := 0; i < 600; i++ {
+		if status := actionSearchArea(agent, ctx); status != StatusRunning {
+			t.Fatalf("actionSearchArea() = %v while searching, want running", status)
+		}
+		if math.Hypot(agent.X-agent.LastKnownX, agent.Y-agent.LastKnownY) <= searchRadius+0.5 {
+			visited[Coord{int(agent.X), int(agent.Y)}] = true
+		}
+	}
+	if len(visited) < 10 {
+		t.Errorf("searched %d tiles around the last known position, want the area swept", len(visited))
+	}
+
+	agent.Alert = AlertIdle
+	if status := actionSearchArea(agent, ctx); status != StatusFailure {
+		t.Errorf("actionSearchArea() = %v when idle, want failure", status)
+	}
+}
+
+func TestPerceptionDispatch(t *testing.T) {
+	p := NewPerception()
+	idle := &Agent{Health: 1}
+	fighting := &Agent{Health: 1, Alert: AlertCombat}
+	p.Dispatch(idle, 4, 5)
+	p.Dispatch(fighting, 4, 5)
+	if idle.Alert != AlertSearching || idle.LastKnownX != 4 || idle.LastKnownY != 5 {
+		t.Errorf("dispatched agent alert = %v at (%v, %v), want searching (4, 5)", idle.Alert, idle.LastKnownX, idle.LastKnownY)
+	}
+	if fighting.Alert != AlertCombat {
+		t.Errorf("fighting agent alert = %v after Dispatch(), want combat", fighting.Alert)
+	}
+}
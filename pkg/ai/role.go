@@ -2,6 +2,8 @@
 package ai
 
 import (
+	"errors"
+	"fmt"
 	"math"
 
 	"github.com/opd-ai/violence/pkg/rng"
@@ -23,6 +25,45 @@ const (
 	RoleScout
 )
 
+// ErrUnknownRole is returned when parsing a role name that does not exist.
+var ErrUnknownRole = errors.New("unknown enemy role")
+
+// roleNames are the names roles have in data files.
+var roleNames = [...]string{
+	RoleTank:     "tank",
+	RoleRanged:   "ranged",
+	RoleHealer:   "healer",
+	RoleAmbusher: "ambusher",
+	RoleScout:    "scout",
+}
+
+// String returns the role name.
+func (r EnemyRole) String() string {
+	if r >= 0 && int(r) < len(roleNames) {
+		return roleNames[r]
+	}
+	return fmt.Sprintf("EnemyRole(%d)", int(r))
+}
+
+// MarshalText encodes the role as its name.
+func (r EnemyRole) MarshalText() ([]byte, error) {
+	if r < 0 || int(r) >= len(roleNames) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownRole, int(r))
+	}
+	return []byte(roleNames[r]), nil
+}
+
+// UnmarshalText decodes a role name.
+func (r *EnemyRole) UnmarshalText(text []byte) error {
+	for role, name := range roleNames {
+		if name == string(text) {
+			*r = EnemyRole(role)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownRole, text)
+}
+
 // RoleConfig holds behavior parameters for an enemy role.
 type RoleConfig struct {
 	Role                EnemyRole
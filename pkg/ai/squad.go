@@ -0,0 +1,406 @@
+package ai
+
+import (
+	"math"
+	"sort"
+)
+
+// SquadOrder is a squad member's part in the squad's plan.
+type SquadOrder int
+
+const (
+	// OrderAssault leaves the member to its own tree.
+	OrderAssault SquadOrder = iota
+	// OrderFlank sends the member along a route to the player's side.
+	OrderFlank
+	// OrderSuppress has the member hold position and keep up fire.
+	OrderSuppress
+	// OrderCover sends the member behind a prop to fight from.
+	OrderCover
+)
+
+// squadOrders lists the orders in order.
+var squadOrders = []SquadOrder{OrderAssault, OrderFlank, OrderSuppress, OrderCover}
+
+// String returns the order name.
+func (o SquadOrder) String() string {
+	switch o {
+	case OrderFlank:
+		return "flank"
+	case OrderSuppress:
+		return "suppress"
+	case OrderCover:
+		return "cover"
+	default:
+		return "assault"
+	}
+}
+
+// Blackboard keys written by the squad planner.
+const (
+	// KeyOrder holds the agent's SquadOrder.
+	KeyOrder = "squad_order"
+	// KeyOrderX and KeyOrderY hold where the order sends the agent.
+	KeyOrderX = "squad_order_x"
+	KeyOrderY = "squad_order_y"
+)
+
+// Squad tactics tuning, in tiles and ticks.
+const (
+	// flankDistance is how far to the player's side flank routes lead.
+	flankDistance = 4.0
+	// flankBehind is how far past the player, away from the squad, they lead.
+	flankBehind = 1.5
+	// flankSearchRadius bounds the search for open floor near a flank point.
+	flankSearchRadius = 2
+	// coverMinDist is the closest to the player a cover spot may be.
+	coverMinDist = 2.5
+	// coverSearchRadius is how far members go to reach cover.
+	coverSearchRadius = 8.0
+	// replanDistance is how far the player may move before squads replan.
+	replanDistance = 3.0
+	// replanTicks is how long a plan lasts.
+	replanTicks = 180
+	// suppressCooldown is the ticks between suppressing shots.
+	suppressCooldown = 15
+	// suppressRangeScale stretches attack range for suppressing fire.
+	suppressRangeScale = 1.5
+)
+
+// SuppressDamageScale scales the damage of suppressing shots, which trade
+// accuracy for volume.
+const SuppressDamageScale = 0.4
+
+// Order returns the agent's current squad order.
+func (agent *Agent) Order() SquadOrder {
+	o, _ := agent.Blackboard.values[KeyOrder].(SquadOrder)
+	return o
+}
+
+// setOrder gives the agent an order leading to (x, y).
+func (agent *Agent) setOrder(o SquadOrder, x, y float64) {
+	agent.Blackboard.Set(KeyOrder, o)
+	agent.Blackboard.Set(KeyOrderX, x)
+	agent.Blackboard.Set(KeyOrderY, y)
+}
+
+// clearOrder returns the agent to its own tree.
+func (agent *Agent) clearOrder() {
+	agent.Blackboard.Delete(KeyOrder)
+	agent.Blackboard.Delete(KeyOrderX)
+	agent.Blackboard.Delete(KeyOrderY)
+}
+
+// squadPlan is what a squad was planned against.
+type squadPlan struct {
+	members          []string
+	targetX, targetY float64
+	tick             int
+}
+
+// SquadPlanner coordinates squads in combat. Ranged members and healers
+// take cover behind props that put an obstacle between them and the
+// player, ranged members without cover lay down suppressing fire, and the
+// squad's fastest members flank along routes to the player's side.
+// Squads replan when a member dies, when the player moves away from the
+// plan, and every few seconds. Plans are handed out as orders on the
+// members' blackboards, which the order_* conditions and the flank,
+// take_cover and suppress actions carry out.
+type SquadPlanner struct {
+	cover []Coord
+	plans map[string]*squadPlan
+}
+
+// NewSquadPlanner creates a planner with no cover.
+func NewSquadPlanner() *SquadPlanner {
+	return &SquadPlanner{plans: make(map[string]*squadPlan)}
+}
+
+// SetCover sets the tiles of props and decorations members can take cover
+// behind.
+func (sp *SquadPlanner) SetCover(tiles []Coord) {
+	sp.cover = append(sp.cover[:0], tiles...)
+}
+
+// Clear drops all plans and cover, e.g. when a level is generated.
+func (sp *SquadPlanner) Clear() {
+	sp.cover = sp.cover[:0]
+	sp.plans = make(map[string]*squadPlan)
+}
+
+// Update plans for every squad with a member in combat and clears the
+// orders of agents whose squads have stood down. Dead members leave their
+// squad's tactics, which triggers a replan.
+func (sp *SquadPlanner) Update(agents []*Agent, ctx *Context) {
+	var ids []string
+	squads := make(map[string][]*Agent)
+	fighting := make(map[string]bool)
+	for _, agent := range agents {
+		if agent.SquadID == "" {
+			continue
+		}
+		if agent.Health <= 0 {
+			agent.clearOrder()
+			if ctx.Perception != nil {
+				if st := ctx.Perception.Squad(agent.SquadID); st != nil {
+					st.RemoveMember(agent.ID)
+				}
+			}
+			continue
+		}
+		if _, ok := squads[agent.SquadID]; !ok {
+			ids = append(ids, agent.SquadID)
+		}
+		squads[agent.SquadID] = append(squads[agent.SquadID], agent)
+		fighting[agent.SquadID] = fighting[agent.SquadID] || agent.Alert == AlertCombat
+	}
+
+	for _, id := range ids {
+		members := squads[id]
+		if !fighting[id] {
+			delete(sp.plans, id)
+			for _, agent := range members {
+				agent.clearOrder()
+			}
+			continue
+		}
+		if sp.stale(id, members, ctx) {
+			sp.plan(id, members, ctx)
+		}
+	}
+	for id := range sp.plans {
+		if !fighting[id] {
+			delete(sp.plans, id)
+		}
+	}
+}
+
+// stale reports whether a squad's plan no longer fits its members or the
+// player's position.
+func (sp *SquadPlanner) stale(id string, members []*Agent, ctx *Context) bool {
+	plan, ok := sp.plans[id]
+	if !ok || len(plan.members) != len(members) {
+		return true
+	}
+	for i, agent := range members {
+		if plan.members[i] != agent.ID {
+			return true
+		}
+	}
+	return math.Hypot(ctx.PlayerX-plan.targetX, ctx.PlayerY-plan.targetY) > replanDistance ||
+		ctx.CurrentTick-plan.tick >= replanTicks
+}
+
+// plan hands out orders to a squad's living members.
+func (sp *SquadPlanner) plan(id string, members []*Agent, ctx *Context) {
+	plan := &squadPlan{targetX: ctx.PlayerX, targetY: ctx.PlayerY, tick: ctx.CurrentTick}
+	for _, agent := range members {
+		plan.members = append(plan.members, agent.ID)
+		agent.clearOrder()
+	}
+	sp.plans[id] = plan
+	// A lone survivor has nobody to coordinate with.
+	if len(members) < 2 {
+		return
+	}
+
+	var cx, cy float64
+	for _, agent := range members {
+		cx += agent.X
+		cy += agent.Y
+	}
+	cx /= float64(len(members))
+	cy /= float64(len(members))
+	var st *SquadTactics
+	if ctx.Perception != nil {
+		st = ctx.Perception.Squad(id)
+	}
+	if st != nil {
+		st.FormationCenter = [2]float64{cx, cy}
+		clear(st.FlankPositions)
+	}
+
+	taken := make(map[Coord]bool)
+	var rest []*Agent
+	for _, agent := range members {
+		if agent.Role != RoleRanged && agent.Role != RoleHealer {
+			rest = append(rest, agent)
+			continue
+		}
+		if spot, ok := sp.coverSpot(agent, ctx, taken); ok {
+			taken[spot] = true
+			c := tileCentre(spot)
+			agent.setOrder(OrderCover, c.X, c.Y)
+		} else if agent.Role == RoleRanged {
+			agent.setOrder(OrderSuppress, agent.X, agent.Y)
+		}
+	}
+
+	// The quickest members flank, from alternating sides.
+	sort.SliceStable(rest, func(i, j int) bool { return flankPreference(rest[i].Role) < flankPreference(rest[j].Role) })
+	flankers := max(1, len(members)*2/5)
+	side := 1.0
+	for _, agent := range rest {
+		if flankers == 0 {
+			break
+		}
+		spot, ok := flankSpot(agent, ctx, cx, cy, side)
+		if !ok {
+			spot, ok = flankSpot(agent, ctx, cx, cy, -side)
+		}
+		if !ok {
+			continue
+		}
+		c := tileCentre(spot)
+		agent.setOrder(OrderFlank, c.X, c.Y)
+		if st != nil {
+			st.FlankPositions[agent.ID] = true
+		}
+		flankers--
+		side = -side
+	}
+}
+
+// flankPreference ranks roles by how well they flank, lowest first.
+func flankPreference(role EnemyRole) int {
+	switch role {
+	case RoleScout:
+		return 0
+	case RoleAmbusher:
+		return 1
+	case RoleTank:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// flankSpot finds open floor to one side of the player, slightly past them
+// as seen from the squad's centre (cx, cy), that the agent has a route to.
+func flankSpot(agent *Agent, ctx *Context, cx, cy, side float64) (Coord, bool) {
+	if ctx.Nav == nil {
+		return Coord{}, false
+	}
+	dx, dy := cx-ctx.PlayerX, cy-ctx.PlayerY
+	d := math.Hypot(dx, dy)
+	if d < 0.01 {
+		dx, dy, d = agent.X-ctx.PlayerX, agent.Y-ctx.PlayerY, math.Hypot(agent.X-ctx.PlayerX, agent.Y-ctx.PlayerY)
+		if d < 0.01 {
+			return Coord{}, false
+		}
+	}
+	dx, dy = dx/d, dy/d
+	tx := ctx.PlayerX - dy*side*flankDistance - dx*flankBehind
+	ty := ctx.PlayerY + dx*side*flankDistance - dy*flankBehind
+	target := Coord{int(math.Floor(tx)), int(math.Floor(ty))}
+
+	var candidates []Coord
+	for y := target.Y - flankSearchRadius; y <= target.Y+flankSearchRadius; y++ {
+		for x := target.X - flankSearchRadius; x <= target.X+flankSearchRadius; x++ {
+			if ctx.Nav.Walkable(x, y) {
+				candidates = append(candidates, Coord{x, y})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return manhattanDistance(candidates[i], target) < manhattanDistance(candidates[j], target)
+	})
+	from := Coord{int(math.Floor(agent.X)), int(math.Floor(agent.Y))}
+	// Only the nearest few are worth a search.
+	for _, c := range candidates[:min(len(candidates), 3)] {
+		if _, complete := ctx.Nav.FindPath(from, c); complete {
+			return c, true
+		}
+	}
+	return Coord{}, false
+}
+
+// coverSpot picks the free open tile nearest the agent that lies directly
+// behind a cover prop as seen from the player, within the agent's attack
+// range.
+func (sp *SquadPlanner) coverSpot(agent *Agent, ctx *Context, taken map[Coord]bool) (Coord, bool) {
+	if ctx.Nav == nil {
+		return Coord{}, false
+	}
+	best, found := Coord{}, false
+	bestDist := coverSearchRadius
+	for _, prop := range sp.cover {
+		px, py := float64(prop.X)+0.5, float64(prop.Y)+0.5
+		dx, dy := px-ctx.PlayerX, py-ctx.PlayerY
+		d := math.Hypot(dx, dy)
+		if d < 0.01 {
+			continue
+		}
+		spot := Coord{int(math.Floor(px + dx/d)), int(math.Floor(py + dy/d))}
+		if spot == prop || taken[spot] || !ctx.Nav.Walkable(spot.X, spot.Y) {
+			continue
+		}
+		c := tileCentre(spot)
+		fromPlayer := math.Hypot(c.X-ctx.PlayerX, c.Y-ctx.PlayerY)
+		if fromPlayer < coverMinDist || fromPlayer > agent.AttackRange {
+			continue
+		}
+		if dist := math.Hypot(c.X-agent.X, c.Y-agent.Y); dist < bestDist {
+			best, bestDist, found = spot, dist, true
+		}
+	}
+	return best, found
+}
+
+// actionFlank follows the agent's flank route. Once there its order is done
+// and it fails, handing back to the rest of the tree.
+func actionFlank(agent *Agent, ctx *Context) NodeStatus {
+	if agent.Order() != OrderFlank || ctx.Nav == nil {
+		return StatusFailure
+	}
+	x, y := agent.Blackboard.Float(KeyOrderX), agent.Blackboard.Float(KeyOrderY)
+	agent.State = StateChase
+	if math.Hypot(x-agent.X, y-agent.Y) < 0.5 || agent.MoveToward(ctx.Nav, x, y, agent.Speed) {
+		agent.clearOrder()
+		return StatusFailure
+	}
+	return StatusRunning
+}
+
+// actionTakeCover moves the agent to its cover spot and fights from there,
+// firing when it can see the player in range and otherwise holding.
+func actionTakeCover(agent *Agent, ctx *Context) NodeStatus {
+	if agent.Order() != OrderCover || ctx.Nav == nil {
+		return StatusFailure
+	}
+	x, y := agent.Blackboard.Float(KeyOrderX), agent.Blackboard.Float(KeyOrderY)
+	if math.Hypot(x-agent.X, y-agent.Y) >= 0.5 {
+		agent.State = StateChase
+		agent.MoveToward(ctx.Nav, x, y, agent.Speed)
+		return StatusRunning
+	}
+	if checkCanSeePlayer(agent, ctx) && checkInAttackRange(agent, ctx) {
+		return actionAttack(agent, ctx)
+	}
+	agent.State = StateIdle
+	return StatusRunning
+}
+
+// actionSuppress holds position and keeps up rapid, weaker fire on a
+// visible player within the stretched suppression range.
+func actionSuppress(agent *Agent, ctx *Context) NodeStatus {
+	if agent.Order() != OrderSuppress || !checkCanSeePlayer(agent, ctx) {
+		return StatusFailure
+	}
+	dx, dy := ctx.PlayerX-agent.X, ctx.PlayerY-agent.Y
+	dist := math.Hypot(dx, dy)
+	if dist > agent.AttackRange*suppressRangeScale {
+		return StatusFailure
+	}
+	agent.State = StateAttack
+	if dist > 0.01 {
+		agent.DirX, agent.DirY = dx/dist, dy/dist
+	}
+	if agent.Cooldown > 0 {
+		agent.Cooldown--
+		return StatusRunning
+	}
+	agent.Cooldown = suppressCooldown
+	agent.Blackboard.Set(KeyAttacked, true)
+	return StatusSuccess
+}
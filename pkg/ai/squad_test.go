@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"math"
+	"testing"
+)
+
+// squadArena is an open walled room with the player near the top and a
+// squad near the bottom.
+func squadArena() (*Context, []*Agent) {
+	rows := []string{"############"}
+	for i := 0; i < 10; i++ {
+		rows = append(rows, "#..........#")
+	}
+	rows = append(rows, "############")
+	tiles := navMap(rows...)
+	ctx := &Context{TileMap: tiles, PlayerX: 6.5, PlayerY: 2.5, Nav: NewNavGrid(tiles, DefaultNavCosts), Perception: NewPerception()}
+	member := func(id string, role EnemyRole, x float64) *Agent {
+		return &Agent{ID: id, X: x, Y: 9.5, DirX: 0, DirY: -1, Health: 1, AlertRadius: 20, AttackRange: 10, Speed: 0.1, Role: role, SquadID: "s"}
+	}
+	agents := []*Agent{
+		member("ranged", RoleRanged, 3.5),
+		member("scout", RoleScout, 6.5),
+		member("tank", RoleTank, 8.5),
+		member("healer", RoleHealer, 9.5),
+	}
+	ctx.Perception.Update(agents, ctx)
+	for _, a := range agents {
+		a.Alert = AlertCombat
+	}
+	return ctx, agents
+}
+
+func TestSquadPlannerOrders(t *testing.T) {
+	ctx, agents := squadArena()
+	sp := NewSquadPlanner()
+	sp.SetCover([]Coord{{3, 6}})
+	sp.Update(agents, ctx)
+
+	want := []SquadOrder{OrderCover, OrderFlank, OrderAssault, OrderAssault}
+	for i, a := range agents {
+		if got := a.Order(); got != want[i] {
+			t.Errorf("%s order = %v, want %v", a.ID, got, want[i])
+		}
+	}
+	if x, y := agents[0].Blackboard.Float(KeyOrderX), agents[0].Blackboard.Float(KeyOrderY); x != 2.5 || y != 7.5 {
+		t.Errorf("cover spot = (%v, %v), want (2.5, 7.5) behind the prop", x, y)
+	}
+	fx, fy := agents[1].Blackboard.Float(KeyOrderX), agents[1].Blackboard.Float(KeyOrderY)
+	if !ctx.Nav.Walkable(int(fx), int(fy)) || math.Abs(fx-ctx.PlayerX) < 2 {
+		t.Errorf("flank spot = (%v, %v), want open floor to the player's side", fx, fy)
+	}
+	if st := ctx.Perception.Squad("s"); !st.ShouldFlank("scout") || st.ShouldFlank("tank") {
+		t.Errorf("squad flank positions = %v, want only the scout", st.FlankPositions)
+	}
+
+	// Without cover the ranged member suppresses instead.
+	ctx, agents = squadArena()
+	NewSquadPlanner().Update(agents, ctx)
+	if got := agents[0].Order(); got != OrderSuppress {
+		t.Errorf("ranged order without cover = %v, want suppress", got)
+	}
+}
+
+func TestSquadPlannerReplans(t *testing.T) {
+	ctx, agents := squadArena()
+	sp := NewSquadPlanner()
+	sp.Update(agents, ctx)
+
+	// The flanker dies and the tank takes over.
+	agents[1].Health = 0
+	ctx.CurrentTick++
+	sp.Update(agents, ctx)
+	if got := agents[2].Order(); got != OrderFlank {
+		t.Errorf("tank order after the scout died = %v, want flank", got)
+	}
+	if st := ctx.Perception.Squad("s"); len(st.Members) != 3 {
+		t.Errorf("squad members = %v, want the scout removed", st.Members)
+	}
+
+	// Plans hold while the player stays put.
+	agents[2].clearOrder()
+	ctx.CurrentTick++
+	sp.Update(agents, ctx)
+	if got := agents[2].Order(); got != OrderAssault {
+		t.Errorf("order = %v without a replan, want the cleared order kept", got)
+	}
+	ctx.PlayerX += replanDistance + 1
+	sp.Update(agents, ctx)
+	if got := agents[2].Order(); got != OrderFlank {
+		t.Errorf("order after the player moved = %v, want flank", got)
+	}
+
+	// Squads out of combat stand down.
+	for _, a := range agents {
+		a.Alert = AlertSearching
+	}
+	sp.Update(agents, ctx)
+	for _, a := range agents {
+		if a.Order() != OrderAssault {
+			t.Errorf("%s order after standing down = %v, want none", a.ID, a.Order())
+		}
+	}
+}
+
+func TestActionFlank(t *testing.T) {
+	ctx, agents := squadArena()
+	a := agents[1]
+	a.Speed = 0.5
+	a.setOrder(OrderFlank, 2.5, 4.5)
+	status := StatusRunning
+	for i := 0; i < 100 && status == StatusRunning; i++ {
+		status = actionFlank(a, ctx)
+	}
+	if status != StatusFailure || a.Order() != OrderAssault {
+		t.Fatalf("flank status = %v, order = %v; want failure with the order done", status, a.Order())
+	}
+	if math.Hypot(a.X-2.5, a.Y-4.5) > 0.5 {
+		t.Errorf("flanker at (%v, %v), want (2.5, 4.5)", a.X, a.Y)
+	}
+}
+
+func TestActionSuppress(t *testing.T) {
+	tests := []struct {
+		name    string
+		playerY float64
+		want    NodeStatus
+	}{
+		{"in range", 5.5, StatusSuccess},
+		{"beyond suppression range", 1.5, StatusFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, agents := squadArena()
+			ctx.Perception = nil
+			ctx.PlayerY = tt.playerY
+			a := agents[1]
+			a.AttackRange = 3
+			a.setOrder(OrderSuppress, a.X, a.Y)
+			if got := actionSuppress(a, ctx); got != tt.want {
+				t.Fatalf("actionSuppress = %v, want %v", got, tt.want)
+			}
+			if tt.want == StatusSuccess && (a.Cooldown != suppressCooldown || !a.Blackboard.Bool(KeyAttacked)) {
+				t.Errorf("cooldown = %d, attacked = %v; want a suppressing shot", a.Cooldown, a.Blackboard.Bool(KeyAttacked))
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package ai
+
+import "math"
+
+// knockbackStep is the longest move a shove makes before checking for a
+// wall, so hard hits can't push an agent through one.
+const knockbackStep = 0.1
+
+// Stagger knocks the agent off balance for ticks updates. It breaks off an
+// attack in progress and keeps its behavior tree from running until it
+// recovers; a shorter stagger never cuts a longer one short.
+func (a *Agent) Stagger(ticks int) {
+	if ticks > a.staggerTicks {
+		a.staggerTicks = ticks
+	}
+	a.Blackboard.Delete(KeyAttacked)
+	if a.State == StateAttack {
+		a.State = StateChase
+	}
+}
+
+// Staggered reports whether the agent is still off balance.
+func (a *Agent) Staggered() bool {
+	return a.staggerTicks > 0
+}
+
+// Recover counts down a stagger by one update and reports whether the
+// agent is still staggered afterwards.
+func (a *Agent) Recover() bool {
+	if a.staggerTicks > 0 {
+		a.staggerTicks--
+	}
+	return a.Staggered()
+}
+
+// Knockback shoves the agent by (dx, dy) tiles, stopping at the first wall
+// in the way.
+func (a *Agent) Knockback(dx, dy float64, tileMap [][]int) {
+	dist := math.Hypot(dx, dy)
+	if dist == 0 {
+		return
+	}
+	steps := int(math.Ceil(dist / knockbackStep))
+	stepX, stepY := dx/float64(steps), dy/float64(steps)
+	for i := 0; i < steps; i++ {
+		if !isWalkable(a.X+stepX, a.Y+stepY, tileMap) {
+			return
+		}
+		a.X += stepX
+		a.Y += stepY
+	}
+}
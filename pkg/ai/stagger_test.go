@@ -0,0 +1,55 @@
+package ai
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAgentStagger(t *testing.T) {
+	agent := NewAgent("a1", 2, 2)
+	agent.State = StateAttack
+	agent.Blackboard.Set(KeyAttacked, true)
+
+	agent.Stagger(3)
+	if !agent.Staggered() {
+		t.Fatal("agent not staggered")
+	}
+	if agent.State == StateAttack || agent.Blackboard.Bool(KeyAttacked) {
+		t.Errorf("stagger left attack in progress: state %v, attacked %v", agent.State, agent.Blackboard.Bool(KeyAttacked))
+	}
+
+	agent.Stagger(1)
+	for i := 0; i < 2; i++ {
+		if !agent.Recover() {
+			t.Fatalf("recovered after %d ticks, want 3", i+1)
+		}
+	}
+	if agent.Recover() {
+		t.Error("still staggered after 3 ticks")
+	}
+	if agent.Recover() {
+		t.Error("recovering past zero staggers the agent")
+	}
+}
+
+func TestAgentKnockback(t *testing.T) {
+	tileMap := [][]int{
+		{1, 1, 1, 1, 1},
+		{1, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1},
+	}
+	agent := NewAgent("a1", 2.5, 1.5)
+	agent.Knockback(0.5, 0, tileMap)
+	if math.Abs(agent.X-3) > 1e-9 || agent.Y != 1.5 {
+		t.Errorf("after shove agent at (%v, %v), want (3, 1.5)", agent.X, agent.Y)
+	}
+
+	agent.Knockback(5, 0, tileMap)
+	if agent.X >= 4 {
+		t.Errorf("shove pushed agent into the wall at x=%v", agent.X)
+	}
+	agent.Knockback(0, -2, tileMap)
+	if agent.Y < 1 {
+		t.Errorf("shove pushed agent into the wall at y=%v", agent.Y)
+	}
+}
@@ -38,6 +38,16 @@ func (p *Pool) Get(ammoType string) int {
 	return p.counts[ammoType]
 }
 
+// All returns the amount in every pool, standard and variant, that has
+// been stocked.
+func (p *Pool) All() map[string]int {
+	all := make(map[string]int, len(p.counts))
+	for ammoType, n := range p.counts {
+		all[ammoType] = n
+	}
+	return all
+}
+
 // Set sets the amount of the given ammo type directly (used for save/load).
 func (p *Pool) Set(ammoType string, amount int) {
 	p.counts[ammoType] = amount
@@ -0,0 +1,79 @@
+package ammo
+
+import "strings"
+
+// Variant is a special load of one or more ammo types. Its rounds are kept
+// in their own pool and change what a weapon loaded with them does on hit.
+// Multipliers of 0 leave the stat unchanged.
+type Variant struct {
+	ID           string
+	Name         string
+	Types        []string // Ammo types the variant is made in
+	DamageType   string   // Combat damage type its rounds deal; "" keeps the weapon's
+	Damage       float64  // Damage multiplier
+	Penetration  int      // Extra targets each round passes through
+	Status       string   // Status effect its rounds inflict on hit
+	StatusChance float64  // Chance per hit of inflicting Status
+}
+
+// Variants is the catalogue of special ammo loads.
+var Variants = []Variant{
+	{ID: "incendiary", Name: "Incendiary", Types: []string{Bullets, Shells}, DamageType: "fire", Damage: 0.9, Status: "burning", StatusChance: 0.3},
+	{ID: "ap", Name: "Armor-Piercing", Types: []string{Bullets, Shells}, Damage: 1.1, Penetration: 2},
+	{ID: "hollow_point", Name: "Hollow-Point", Types: []string{Bullets}, Damage: 1.35, Status: "bleeding", StatusChance: 0.4},
+	{ID: "emp", Name: "EMP", Types: []string{Cells}, DamageType: "energy", Damage: 0.8, Status: "shocked", StatusChance: 0.35},
+}
+
+// FindVariant looks up a variant in the catalogue by ID.
+func FindVariant(id string) (Variant, bool) {
+	for _, v := range Variants {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// Fits reports whether the variant is made in the ammo type.
+func (v Variant) Fits(ammoType string) bool {
+	for _, t := range v.Types {
+		if t == ammoType {
+			return true
+		}
+	}
+	return false
+}
+
+// VariantsFor returns the variants made in the ammo type.
+func VariantsFor(ammoType string) []Variant {
+	var fits []Variant
+	for _, v := range Variants {
+		if v.Fits(ammoType) {
+			fits = append(fits, v)
+		}
+	}
+	return fits
+}
+
+// PoolKey returns the pool holding rounds of the variant of an ammo type.
+// Standard rounds, the "" variant, are pooled under the ammo type itself.
+func PoolKey(ammoType, variant string) string {
+	if variant == "" {
+		return ammoType
+	}
+	return ammoType + "_" + variant
+}
+
+// ParsePoolKey splits a variant's pool key into its ammo type and variant.
+// It reports false for standard pools and unknown or misfitting variants.
+func ParsePoolKey(key string) (string, Variant, bool) {
+	ammoType, id, ok := strings.Cut(key, "_")
+	if !ok {
+		return "", Variant{}, false
+	}
+	v, ok := FindVariant(id)
+	if !ok || !v.Fits(ammoType) {
+		return "", Variant{}, false
+	}
+	return ammoType, v, true
+}
@@ -0,0 +1,44 @@
+package ammo
+
+import "testing"
+
+func TestPoolKey(t *testing.T) {
+	if PoolKey(Bullets, "") != Bullets {
+		t.Error("standard rounds are not pooled under their ammo type")
+	}
+	for _, v := range Variants {
+		for _, ammoType := range v.Types {
+			key := PoolKey(ammoType, v.ID)
+			gotType, got, ok := ParsePoolKey(key)
+			if !ok || gotType != ammoType || got.ID != v.ID {
+				t.Errorf("ParsePoolKey(%q) = %q, %q, %v", key, gotType, got.ID, ok)
+			}
+		}
+	}
+	for _, key := range []string{Bullets, "cells_incendiary", "bullets_unknown"} {
+		if _, _, ok := ParsePoolKey(key); ok {
+			t.Errorf("ParsePoolKey(%q) accepted it", key)
+		}
+	}
+}
+
+func TestVariantPools(t *testing.T) {
+	p := NewPool()
+	p.Add(Bullets, 10)
+	p.Add(PoolKey(Bullets, "incendiary"), 5)
+	if !p.Consume(PoolKey(Bullets, "incendiary"), 5) || p.Get(Bullets) != 10 {
+		t.Error("variant rounds were drawn from the standard pool")
+	}
+	if all := p.All(); len(all) != 2 || all[Bullets] != 10 {
+		t.Errorf("All() = %v", all)
+	}
+}
+
+func TestVariantsFor(t *testing.T) {
+	if got := VariantsFor(Cells); len(got) != 1 || got[0].ID != "emp" {
+		t.Errorf("cell variants = %+v, want EMP alone", got)
+	}
+	if got := VariantsFor(Rockets); len(got) != 0 {
+		t.Errorf("rocket variants = %+v, want none", got)
+	}
+}
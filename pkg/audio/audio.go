@@ -96,6 +96,7 @@ type Engine struct {
 	targetWet      float64
 	targetDry      float64
 	transitionStep float64
+	sfxOverrides   map[string][]byte // WAV data replacing generated sounds
 	mu             sync.RWMutex
 }
 
@@ -104,6 +105,7 @@ func NewEngine() *Engine {
 	reverb := NewReverbCalculator(20, 20)
 	return &Engine{
 		sfxPlayers:     make(map[string]*audio.Player),
+		sfxOverrides:   make(map[string][]byte),
 		intensity:      0.5,
 		reverb:         reverb,
 		targetDecay:    reverb.GetDecay(),
@@ -428,9 +430,16 @@ func generateMusicForEngine(name string, layer int, genreID string) []byte {
 	return generateMusic(seed, duration, genreID, layer)
 }
 
-// getSFXData generates procedural SFX data by name.
+// getSFXData returns the override of a sound, or else generates
+// procedural SFX data by name.
 // Returns deterministic audio based on name parameter.
 func (e *Engine) getSFXData(name string) []byte {
+	e.mu.RLock()
+	data, ok := e.sfxOverrides[name]
+	e.mu.RUnlock()
+	if ok {
+		return data
+	}
 	seed := hashString(name)
 	return generateSFX(seed, name)
 }
@@ -642,6 +651,12 @@ func generateSFX(seed uint64, name string) []byte {
 	if containsAny(name, "explosion", "boom", "blast") {
 		return generateExplosion(seed)
 	}
+	if containsAny(name, "thunder") {
+		return generateThunder(seed)
+	}
+	if containsAny(name, "rain", "wind", "gust") {
+		return generateWeatherSwell(seed)
+	}
 	if containsAny(name, "pickup", "item", "collect") {
 		return generatePickup(seed)
 	}
@@ -743,6 +758,56 @@ func generateExplosion(seed uint64) []byte {
 	return buf.Bytes()
 }
 
+// generateThunder creates a distant thunder roll: a sharp crack followed by
+// a long, low rumble.
+func generateThunder(seed uint64) []byte {
+	rng := newLocalRNG(seed)
+	samples := sampleRate * 3
+	buf := &bytes.Buffer{}
+	writeWAVHeader(buf, samples)
+
+	low := 0.0
+	for i := 0; i < samples; i++ {
+		t := float64(i) / float64(samples)
+		crack := math.Exp(-float64(i)/float64(sampleRate/20)) * 0.6
+		rumbleEnv := math.Min(t*8, 1) * math.Exp(-t*2.5)
+
+		// One-pole low-pass keeps only the deep part of the noise
+		noise := rng.Float64()*2.0 - 1.0
+		low += (noise - low) * 0.02
+
+		val := int16(clamp(noise*crack+low*rumbleEnv*6, -1.0, 1.0) * 20000.0)
+		writeInt16(buf, val)
+		writeInt16(buf, val)
+	}
+
+	return buf.Bytes()
+}
+
+// generateWeatherSwell creates a soft two second swell of rain or wind
+// noise, to mark weather picking up.
+func generateWeatherSwell(seed uint64) []byte {
+	rng := newLocalRNG(seed)
+	samples := sampleRate * 2
+	buf := &bytes.Buffer{}
+	writeWAVHeader(buf, samples)
+
+	smooth := 0.0
+	for i := 0; i < samples; i++ {
+		t := float64(i) / float64(samples)
+		env := math.Sin(math.Pi * t)
+
+		noise := rng.Float64()*2.0 - 1.0
+		smooth += (noise - smooth) * 0.3
+
+		val := int16(smooth * env * 6000.0)
+		writeInt16(buf, val)
+		writeInt16(buf, val)
+	}
+
+	return buf.Bytes()
+}
+
 // generatePickup creates an item pickup sound.
 func generatePickup(seed uint64) []byte {
 	samples := sampleRate / 6
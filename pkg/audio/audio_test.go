@@ -344,6 +344,28 @@ func TestGenerateBlip(t *testing.T) {
 	}
 }
 
+func TestGenerateWeatherSFX(t *testing.T) {
+	tests := []struct {
+		name    string
+		seconds int
+	}{
+		{"weather_thunder", 3},
+		{"weather_rain", 2},
+		{"wind_gust", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := generateSFX(hashString(tt.name), tt.name)
+			if want := 44 + sampleRate*tt.seconds*4; len(data) != want {
+				t.Errorf("len = %d, want %d for %d seconds of stereo audio", len(data), want, tt.seconds)
+			}
+			if string(data[0:4]) != "RIFF" {
+				t.Error("missing RIFF header")
+			}
+		})
+	}
+}
+
 func TestVolumeAttenuationFormula(t *testing.T) {
 	// Test that volume decreases with distance
 	engine := NewEngine()
@@ -39,6 +39,12 @@
 //
 // Layer generation uses consistent seeds to ensure synchronization.
 //
+// # Vocal Barks
+//
+// Companions acknowledge orders with short barks synthesized from vowel
+// formants. GenerateBark pitches the voice by speaker class and colours it by
+// genre; Engine.PlayBark positions the bark like any sound effect.
+//
 // # 3D Audio
 //
 // Sound effects use distance-based attenuation (inverse square law) and
@@ -0,0 +1,91 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+// ErrOGGUnsupported is returned for an OGG sound in a build without Ogg
+// Vorbis decoding. Build with -tags vorbis to enable it.
+var ErrOGGUnsupported = errors.New("OGG sounds need a build with the vorbis tag")
+
+// decodeOGG decodes Ogg Vorbis data to 16-bit stereo PCM at the engine's
+// sample rate. The vorbis build replaces it.
+var decodeOGG = func(data []byte) ([]byte, error) {
+	return nil, ErrOGGUnsupported
+}
+
+// SetSFXOverride replaces a generated sound effect with WAV data, such as
+// a mod's. The data may be mono or stereo at any sample rate; it is
+// converted when played.
+func (e *Engine) SetSFXOverride(name string, data []byte) error {
+	if _, err := wav.DecodeWithSampleRate(sampleRate, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("invalid WAV: %w", err)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sfxOverrides[name] = data
+	return nil
+}
+
+// ClearSFXOverrides drops every sound override, returning to the generated
+// sounds.
+func (e *Engine) ClearSFXOverrides() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sfxOverrides = make(map[string][]byte)
+}
+
+// LoadSFXOverrides overrides a sound effect with each WAV or OGG file in
+// dir, named by the file name without its extension, e.g. item_pickup.wav
+// replaces item_pickup. Files that fail to decode are skipped, leaving the
+// generated sound in place, and reported in the returned error.
+func (e *Engine) LoadSFXOverrides(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	loaded := 0
+	var failed []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".wav" && ext != ".ogg") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err == nil && ext == ".ogg" {
+			data, err = oggToWAV(data)
+		}
+		if err == nil {
+			err = e.SetSFXOverride(strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())), data)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", entry.Name(), err))
+			continue
+		}
+		loaded++
+	}
+	if len(failed) > 0 {
+		return loaded, fmt.Errorf("failed to load sounds: %s", strings.Join(failed, "; "))
+	}
+	return loaded, nil
+}
+
+// oggToWAV decodes Ogg Vorbis data once, up front, into WAV data so it
+// plays like any other sound.
+func oggToWAV(data []byte) ([]byte, error) {
+	pcm, err := decodeOGG(data)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	writeWAVHeader(buf, len(pcm)/4)
+	buf.Write(pcm[:len(pcm)/4*4])
+	return buf.Bytes(), nil
+}
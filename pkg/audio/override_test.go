@@ -0,0 +1,49 @@
+package audio
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEngine_LoadSFXOverrides(t *testing.T) {
+	dir := t.TempDir()
+	blip := generateBlip(480)
+	files := map[string][]byte{
+		"item_pickup.wav": blip,
+		"broken.wav":      []byte("RIFF nonsense"),
+		"garbage.ogg":     []byte("OggS nonsense"),
+		"readme.txt":      []byte("ignored"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := NewEngine()
+	generated := e.getSFXData("item_pickup")
+	n, err := e.LoadSFXOverrides(dir)
+	if n != 1 || err == nil {
+		t.Errorf("LoadSFXOverrides = %d, %v; want 1 and an error for the broken files", n, err)
+	}
+	if !bytes.Equal(e.getSFXData("item_pickup"), blip) {
+		t.Error("getSFXData did not return the override")
+	}
+	if e.getSFXData("broken") == nil {
+		t.Error("a broken override should fall back to the generated sound")
+	}
+
+	e.ClearSFXOverrides()
+	if !bytes.Equal(e.getSFXData("item_pickup"), generated) {
+		t.Error("ClearSFXOverrides did not restore the generated sound")
+	}
+}
+
+func TestEngine_SetSFXOverrideRejectsInvalidWAV(t *testing.T) {
+	e := NewEngine()
+	if err := e.SetSFXOverride("shot", []byte("not audio")); err == nil {
+		t.Error("SetSFXOverride accepted invalid data")
+	}
+}
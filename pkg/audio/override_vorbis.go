@@ -0,0 +1,20 @@
+//go:build vorbis
+
+package audio
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+)
+
+func init() {
+	decodeOGG = func(data []byte) ([]byte, error) {
+		stream, err := vorbis.DecodeWithSampleRate(sampleRate, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(stream)
+	}
+}
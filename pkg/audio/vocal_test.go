@@ -0,0 +1,76 @@
+package audio
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGenerateBark(t *testing.T) {
+	tests := []struct {
+		name      string
+		genreID   string
+		voice     string
+		syllables int
+		wantSyl   int
+	}{
+		{"fantasy grunt", "fantasy", "grunt", 3, 3},
+		{"scifi radio", "scifi", "medic", 4, 4},
+		{"horror breath", "horror", "demo", 2, 2},
+		{"cyberpunk radio", "cyberpunk", "mystic", 5, 5},
+		{"postapoc drive", "postapoc", "grunt", 1, 1},
+		{"unknown voice", "fantasy", "unknown", 2, 2},
+		{"too few syllables", "fantasy", "grunt", 0, 1},
+		{"too many syllables", "fantasy", "grunt", 40, barkMaxSyllable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := GenerateBark(tt.genreID, tt.voice, tt.syllables, 42)
+			if !bytes.Equal(data[0:4], []byte("RIFF")) || !bytes.Equal(data[8:12], []byte("WAVE")) {
+				t.Fatal("missing WAV header")
+			}
+			// 16-bit stereo frames after the 44-byte header.
+			wantLen := 44 + tt.wantSyl*(barkSyllableLen+barkGapLen)*4
+			if len(data) != wantLen {
+				t.Errorf("len = %d, want %d", len(data), wantLen)
+			}
+			nonZero := 0
+			for i := 44; i < len(data); i += 2 {
+				if data[i] != 0 || data[i+1] != 0 {
+					nonZero++
+				}
+			}
+			if nonZero == 0 {
+				t.Error("bark is silent")
+			}
+		})
+	}
+}
+
+func TestGenerateBark_Determinism(t *testing.T) {
+	a := GenerateBark("scifi", "medic", 3, 7)
+	b := GenerateBark("scifi", "medic", 3, 7)
+	if !bytes.Equal(a, b) {
+		t.Error("same seed produced different barks")
+	}
+	if bytes.Equal(a, GenerateBark("scifi", "grunt", 3, 7)) {
+		t.Error("different voices produced the same bark")
+	}
+}
+
+func TestCountSyllables(t *testing.T) {
+	tests := []struct {
+		line string
+		want int
+	}{
+		{"Holding position.", 5},
+		{"On it!", 2},
+		{"Dug in.", 2},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := countSyllables(tt.line); got != tt.want {
+			t.Errorf("countSyllables(%q) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
@@ -42,12 +42,47 @@ const (
 	// TileFloorDirt is post-apocalyptic dirt floor.
 	TileFloorDirt = 24
 
+	// TileBars is a see-through wall of bars, such as a prison cell front.
+	TileBars = 30
+	// TileWindow is a see-through glass window.
+	TileWindow = 31
+	// TileForceField is a translucent energy barrier.
+	TileForceField = 32
+
+	// TilePortal is the first portal tile. Portal tiles TilePortal through
+	// TilePortalLast come in pairs: the two tiles sharing an ID are linked,
+	// so looking or walking into one continues out of the other.
+	TilePortal = 40
+	// TilePortalLast is the last portal tile ID.
+	TilePortalLast = 49
+
 	// MinLevelSize is the minimum level dimension.
 	MinLevelSize = 16
 	// MaxLevelSize is the maximum level dimension.
 	MaxLevelSize = 1024
 )
 
+// CorridorStyle selects how corridors between rooms are carved.
+type CorridorStyle int
+
+const (
+	// CorridorL carves an L-shaped corridor with a single bend.
+	CorridorL CorridorStyle = iota
+	// CorridorStraight carves a straight corridor where rooms overlap on an
+	// axis, falling back to an L-shape otherwise.
+	CorridorStraight
+	// CorridorWinding carves a meandering corridor with random jogs.
+	CorridorWinding
+	// CorridorMixed picks one of the other styles per corridor.
+	CorridorMixed
+)
+
+// DefaultLoopRate is the chance of adding an extra passage between sibling
+// subtrees that GenerationConfig starts from, turning the tree-shaped layout
+// into one with loops. NewGenerator leaves loops off so existing seeds keep
+// their layouts.
+const DefaultLoopRate = 0.2
+
 // ErrInvalidWidth is returned when width is invalid.
 var ErrInvalidWidth = errors.New("width must be > 0 and <= 1024")
 
@@ -69,18 +104,38 @@ type Room struct {
 	X, Y, W, H int
 	Type       int
 	Index      int
+	Tag        RoomTag // Setpiece role assigned by the generator
 }
 
 // Generator produces levels using binary space partitioning.
 type Generator struct {
-	Width     int
-	Height    int
-	MinSize   int
-	MaxSize   int
-	rng       *rng.RNG
-	genre     string
-	wallTile  int
-	floorTile int
+	Width   int
+	Height  int
+	MinSize int
+	MaxSize int
+	// CorridorStyle controls the shape of carved corridors.
+	CorridorStyle CorridorStyle
+	// LoopRate is the probability [0,1] that sibling subtrees receive an
+	// additional connecting passage. Zero, the default, disables loop
+	// injection.
+	LoopRate float64
+	// MinRooms and MaxRooms bound the number of BSP leaves when MaxRooms is
+	// positive. Zero MaxRooms keeps the unbounded recursive split.
+	MinRooms int
+	MaxRooms int
+	// DetailDensity is the approximate share [0,1] of genre wall and floor
+	// tiles replaced with variants by the detailing pass. Zero disables it.
+	DetailDensity float64
+	// WindowRate is the probability [0,1] that an eligible thin wall becomes
+	// a genre see-through wall (bars, window, or force field).
+	WindowRate float64
+	// PortalPairs is the number of linked portal pairs placed in thin walls,
+	// at most TilePortalLast-TilePortal+1. Zero disables portals.
+	PortalPairs int
+	rng         *rng.RNG
+	genre       string
+	wallTile    int
+	floorTile   int
 }
 
 // GeneratorConfig holds BSP generation parameters.
@@ -103,14 +158,16 @@ func NewGenerator(width, height int, r *rng.RNG) (*Generator, error) {
 	}
 
 	return &Generator{
-		Width:     width,
-		Height:    height,
-		MinSize:   6,
-		MaxSize:   12,
-		rng:       r,
-		genre:     genre.Fantasy,
-		wallTile:  TileWall,
-		floorTile: TileFloor,
+		Width:         width,
+		Height:        height,
+		MinSize:       6,
+		MaxSize:       12,
+		DetailDensity: DefaultDetailDensity,
+		WindowRate:    DefaultWindowRate,
+		rng:           r,
+		genre:         genre.Fantasy,
+		wallTile:      TileWall,
+		floorTile:     TileFloor,
 	}, nil
 }
 
@@ -145,7 +202,11 @@ func (g *Generator) SetGenre(genreID string) {
 // Generate produces a BSP tree and tile map.
 func (g *Generator) Generate() (*Node, [][]int) {
 	root := &Node{X: 0, Y: 0, W: g.Width, H: g.Height}
-	g.split(root, 0)
+	if g.MaxRooms > 0 {
+		g.splitToCount(root)
+	} else {
+		g.split(root, 0)
+	}
 
 	tiles := make([][]int, g.Height)
 	for y := range tiles {
@@ -158,18 +219,23 @@ func (g *Generator) Generate() (*Node, [][]int) {
 	g.createRooms(root, tiles)
 	g.assignRoomIndices(root)
 	g.createCorridors(root, tiles)
+	g.injectLoops(root, tiles)
 	g.placeDoors(root, tiles)
 	g.placeSecrets(root, tiles)
+	g.placePortals(tiles)
+	g.placeWindows(tiles)
+	g.detailTiles(tiles)
 
 	return root, tiles
 }
 
-// assignRoomIndices assigns sequential indices to rooms.
+// assignRoomIndices assigns sequential indices and setpiece tags to rooms.
 func (g *Generator) assignRoomIndices(n *Node) {
 	rooms := GetRooms(n)
 	for i, room := range rooms {
 		room.Index = i
 	}
+	tagRooms(rooms)
 }
 
 // GetRooms returns all rooms from a BSP tree.
@@ -193,6 +259,18 @@ func (g *Generator) split(n *Node, depth int) bool {
 		return false
 	}
 
+	if !g.splitOnce(n) {
+		return false
+	}
+
+	g.split(n.Left, depth+1)
+	g.split(n.Right, depth+1)
+	return true
+}
+
+// splitOnce divides a node into two children. Returns false if the node is
+// too small to split.
+func (g *Generator) splitOnce(n *Node) bool {
 	// Stop splitting if too small
 	if n.W < g.MinSize*2 || n.H < g.MinSize*2 {
 		return false
@@ -225,9 +303,6 @@ func (g *Generator) split(n *Node, depth int) bool {
 		n.Left = &Node{X: n.X, Y: n.Y, W: splitPos, H: n.H}
 		n.Right = &Node{X: n.X + splitPos, Y: n.Y, W: n.W - splitPos, H: n.H}
 	}
-
-	g.split(n.Left, depth+1)
-	g.split(n.Right, depth+1)
 	return true
 }
 
@@ -318,7 +393,7 @@ func (g *Generator) fillRoomFloor(x, y, w, h int, tiles [][]int) {
 	}
 }
 
-// createCorridors connects sibling rooms with L-shaped or straight corridors.
+// createCorridors connects sibling rooms using the configured corridor style.
 func (g *Generator) createCorridors(n *Node, tiles [][]int) {
 	if n.Left == nil || n.Right == nil {
 		return
@@ -331,12 +406,36 @@ func (g *Generator) createCorridors(n *Node, tiles [][]int) {
 		return
 	}
 
+	g.connectRooms(r1, r2, tiles)
+
+	g.createCorridors(n.Left, tiles)
+	g.createCorridors(n.Right, tiles)
+}
+
+// connectRooms carves a corridor between the centers of two rooms using the
+// generator's corridor style.
+func (g *Generator) connectRooms(r1, r2 *Room, tiles [][]int) {
 	// Center points of rooms
 	x1 := r1.X + r1.W/2
 	y1 := r1.Y + r1.H/2
 	x2 := r2.X + r2.W/2
 	y2 := r2.Y + r2.H/2
 
+	style := g.CorridorStyle
+	if style == CorridorMixed {
+		style = CorridorStyle(g.rng.Intn(int(CorridorMixed)))
+	}
+
+	switch style {
+	case CorridorStraight:
+		if g.carveStraightCorridor(r1, r2, tiles) {
+			return
+		}
+	case CorridorWinding:
+		g.carveWindingCorridor(x1, y1, x2, y2, tiles)
+		return
+	}
+
 	// Carve L-shaped corridor
 	if g.rng.Intn(2) == 0 {
 		g.carveCorridor(x1, y1, x2, y1, tiles)
@@ -345,9 +444,110 @@ func (g *Generator) createCorridors(n *Node, tiles [][]int) {
 		g.carveCorridor(x1, y1, x1, y2, tiles)
 		g.carveCorridor(x1, y2, x2, y2, tiles)
 	}
+}
 
-	g.createCorridors(n.Left, tiles)
-	g.createCorridors(n.Right, tiles)
+// carveStraightCorridor carves a single straight corridor when two rooms
+// share a row or column range. Returns false if the rooms do not overlap.
+func (g *Generator) carveStraightCorridor(r1, r2 *Room, tiles [][]int) bool {
+	if lo, hi := max(r1.X, r2.X), min(r1.X+r1.W, r2.X+r2.W)-1; lo <= hi {
+		x := lo + g.rng.Intn(hi-lo+1)
+		g.carveCorridor(x, r1.Y+r1.H/2, x, r2.Y+r2.H/2, tiles)
+		return true
+	}
+	if lo, hi := max(r1.Y, r2.Y), min(r1.Y+r1.H, r2.Y+r2.H)-1; lo <= hi {
+		y := lo + g.rng.Intn(hi-lo+1)
+		g.carveCorridor(r1.X+r1.W/2, y, r2.X+r2.W/2, y, tiles)
+		return true
+	}
+	return false
+}
+
+// carveWindingCorridor walks from (x1,y1) to (x2,y2), choosing the step axis
+// at random weighted by the remaining distance so the path meanders.
+func (g *Generator) carveWindingCorridor(x1, y1, x2, y2 int, tiles [][]int) {
+	x, y := x1, y1
+	g.setFloor(x, y, tiles)
+	for x != x2 || y != y2 {
+		dx, dy := x2-x, y2-y
+		adx, ady := dx, dy
+		if adx < 0 {
+			adx = -adx
+		}
+		if ady < 0 {
+			ady = -ady
+		}
+		if g.rng.Intn(adx+ady) < adx {
+			if dx > 0 {
+				x++
+			} else {
+				x--
+			}
+		} else {
+			if dy > 0 {
+				y++
+			} else {
+				y--
+			}
+		}
+		g.setFloor(x, y, tiles)
+	}
+}
+
+// setFloor carves a single floor tile if it lies inside the map.
+func (g *Generator) setFloor(x, y int, tiles [][]int) {
+	if x >= 0 && x < g.Width && y >= 0 && y < g.Height {
+		tiles[y][x] = g.floorTile
+	}
+}
+
+// injectLoops adds extra passages between sibling subtrees at LoopRate,
+// joining the closest pair of rooms so loops stay local.
+func (g *Generator) injectLoops(n *Node, tiles [][]int) {
+	if g.LoopRate <= 0 || n == nil || n.Left == nil || n.Right == nil {
+		return
+	}
+
+	g.injectLoops(n.Left, tiles)
+	g.injectLoops(n.Right, tiles)
+
+	left := g.collectRooms(n.Left)
+	right := g.collectRooms(n.Right)
+	// A pair of single rooms is already directly connected.
+	if len(left)+len(right) < 3 {
+		return
+	}
+	if g.rng.Float64() >= g.LoopRate {
+		return
+	}
+
+	r1, r2 := closestRooms(left, right)
+	if r1 != nil && r2 != nil {
+		g.connectRooms(r1, r2, tiles)
+	}
+}
+
+// closestRooms returns the pair of rooms, one from each set, whose centers
+// are nearest by Manhattan distance.
+func closestRooms(a, b []*Room) (*Room, *Room) {
+	var bestA, bestB *Room
+	best := -1
+	for _, ra := range a {
+		for _, rb := range b {
+			dx := (ra.X + ra.W/2) - (rb.X + rb.W/2)
+			dy := (ra.Y + ra.H/2) - (rb.Y + rb.H/2)
+			if dx < 0 {
+				dx = -dx
+			}
+			if dy < 0 {
+				dy = -dy
+			}
+			if best < 0 || dx+dy < best {
+				best = dx + dy
+				bestA, bestB = ra, rb
+			}
+		}
+	}
+	return bestA, bestB
 }
 
 // getRandomRoom returns a random leaf room from a subtree.
@@ -500,3 +700,10 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
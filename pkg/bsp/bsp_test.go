@@ -630,3 +630,87 @@ func TestGetRoomsNilNode(t *testing.T) {
 		t.Errorf("GetRooms(nil) should return nil, got %v", rooms)
 	}
 }
+
+func TestCorridorStyles(t *testing.T) {
+	styles := []struct {
+		name  string
+		style CorridorStyle
+	}{
+		{"l_shaped", CorridorL},
+		{"straight", CorridorStraight},
+		{"winding", CorridorWinding},
+		{"mixed", CorridorMixed},
+	}
+
+	for _, tt := range styles {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGenerator(64, 64, rng.NewRNG(4242))
+			if err != nil {
+				t.Fatalf("NewGenerator failed: %v", err)
+			}
+			g.CorridorStyle = tt.style
+			_, tiles := g.Generate()
+
+			total := countFloorTiles(tiles)
+			visited := make([][]bool, len(tiles))
+			for i := range visited {
+				visited[i] = make([]bool, len(tiles[0]))
+			}
+			var reached int
+			for y := range tiles {
+				for x := range tiles[y] {
+					if tiles[y][x] == TileFloor {
+						reached = floodFill(tiles, visited, x, y)
+						break
+					}
+				}
+				if reached > 0 {
+					break
+				}
+			}
+			if float64(reached) < float64(total)*0.9 {
+				t.Errorf("only %d of %d floor tiles connected", reached, total)
+			}
+		})
+	}
+}
+
+func TestLoopsAreOptIn(t *testing.T) {
+	g, err := NewGenerator(64, 64, rng.NewRNG(1))
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	if g.LoopRate != 0 {
+		t.Errorf("default LoopRate = %v, want 0", g.LoopRate)
+	}
+}
+
+func TestInjectLoopsAddsPassages(t *testing.T) {
+	generate := func(rate float64) [][]int {
+		g, err := NewGenerator(96, 96, rng.NewRNG(9001))
+		if err != nil {
+			t.Fatalf("NewGenerator failed: %v", err)
+		}
+		g.LoopRate = rate
+		_, tiles := g.Generate()
+		return tiles
+	}
+
+	without := countFloorTiles(generate(0))
+	with := countFloorTiles(generate(1))
+	if with <= without {
+		t.Errorf("floor tiles with loops = %d, without = %d; expected more with loops", with, without)
+	}
+}
+
+func TestClosestRooms(t *testing.T) {
+	a := []*Room{{X: 0, Y: 0, W: 4, H: 4}, {X: 20, Y: 0, W: 4, H: 4}}
+	b := []*Room{{X: 26, Y: 0, W: 4, H: 4}, {X: 60, Y: 60, W: 4, H: 4}}
+	r1, r2 := closestRooms(a, b)
+	if r1 != a[1] || r2 != b[0] {
+		t.Errorf("closestRooms picked %+v and %+v", r1, r2)
+	}
+	if r1, r2 := closestRooms(nil, b); r1 != nil || r2 != nil {
+		t.Error("closestRooms with empty set should return nil")
+	}
+}
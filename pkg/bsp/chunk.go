@@ -0,0 +1,389 @@
+package bsp
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// DefaultChunkSize is the default edge length of a generated chunk in tiles.
+const DefaultChunkSize = 64
+
+// DefaultChunkLoadRadius is the default number of chunks kept loaded in each
+// direction around the player's chunk.
+const DefaultChunkLoadRadius = 1
+
+// ErrInvalidChunkSize is returned when the chunk size cannot hold a room.
+var ErrInvalidChunkSize = errors.New("chunk size must be >= 16 and <= 1024")
+
+// ErrChunkOutOfBounds is returned when a chunk coordinate lies outside the world.
+var ErrChunkOutOfBounds = errors.New("chunk coordinate out of bounds")
+
+// ChunkCoord identifies a chunk by its column and row in the chunk grid.
+type ChunkCoord struct {
+	CX, CY int
+}
+
+// Chunk is an independently generated square region of a chunked world.
+type Chunk struct {
+	Coord ChunkCoord
+	X, Y  int // World tile origin of the chunk
+	Size  int
+	Seed  uint64
+	Root  *Node   // Partition and rooms, in world coordinates
+	Tiles [][]int // Indexed from the chunk origin
+}
+
+// Bounds returns the chunk's world-space bounding box, suitable for
+// spatial.Grid.QueryBounds and RemoveBounds.
+func (c *Chunk) Bounds() (minX, minY, maxX, maxY float64) {
+	return float64(c.X), float64(c.Y), float64(c.X + c.Size), float64(c.Y + c.Size)
+}
+
+// ChunkedWorld generates a large seamless map lazily, one chunk at a time.
+// Each chunk is produced by its own BSP generator seeded from the world seed
+// and chunk coordinate, so any chunk can be regenerated identically after it
+// has been unloaded. Neighboring chunks agree on gate positions along their
+// shared edges, which are carved through the border walls.
+type ChunkedWorld struct {
+	Width      int // World width in tiles
+	Height     int // World height in tiles
+	ChunkSize  int
+	Seed       uint64
+	Genre      string
+	LoadRadius int
+
+	// OnLoad is called after a chunk is generated and added to the world.
+	OnLoad func(*Chunk)
+	// OnUnload is called before a chunk is removed from the world.
+	OnUnload func(*Chunk)
+
+	chunks map[ChunkCoord]*Chunk
+}
+
+// NewChunkedWorld creates a chunked world of the given tile dimensions.
+// Dimensions are rounded up to whole chunks.
+func NewChunkedWorld(width, height, chunkSize int, seed uint64, genreID string) (*ChunkedWorld, error) {
+	if chunkSize < MinLevelSize || chunkSize > MaxLevelSize {
+		return nil, ErrInvalidChunkSize
+	}
+	if width <= 0 {
+		return nil, ErrInvalidWidth
+	}
+	if height <= 0 {
+		return nil, ErrInvalidHeight
+	}
+
+	cols := (width + chunkSize - 1) / chunkSize
+	rows := (height + chunkSize - 1) / chunkSize
+	return &ChunkedWorld{
+		Width:      cols * chunkSize,
+		Height:     rows * chunkSize,
+		ChunkSize:  chunkSize,
+		Seed:       seed,
+		Genre:      genreID,
+		LoadRadius: DefaultChunkLoadRadius,
+		chunks:     make(map[ChunkCoord]*Chunk),
+	}, nil
+}
+
+// ChunkSeed derives a chunk's generation seed from the world seed and its
+// coordinate using a SplitMix64 finalizer.
+func ChunkSeed(worldSeed uint64, cx, cy int) uint64 {
+	return mix64(worldSeed ^ mix64(uint64(int64(cx))<<32^uint64(uint32(int32(cy)))))
+}
+
+// mix64 is the SplitMix64 output function.
+func mix64(z uint64) uint64 {
+	z += 0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Columns returns the number of chunk columns.
+func (w *ChunkedWorld) Columns() int { return w.Width / w.ChunkSize }
+
+// Rows returns the number of chunk rows.
+func (w *ChunkedWorld) Rows() int { return w.Height / w.ChunkSize }
+
+// ChunkAt returns the chunk coordinate containing a world position.
+func (w *ChunkedWorld) ChunkAt(x, y float64) ChunkCoord {
+	cx := int(x) / w.ChunkSize
+	cy := int(y) / w.ChunkSize
+	if x < 0 {
+		cx = -1
+	}
+	if y < 0 {
+		cy = -1
+	}
+	return ChunkCoord{CX: cx, CY: cy}
+}
+
+// inBounds reports whether a chunk coordinate lies inside the world.
+func (w *ChunkedWorld) inBounds(c ChunkCoord) bool {
+	return c.CX >= 0 && c.CY >= 0 && c.CX < w.Columns() && c.CY < w.Rows()
+}
+
+// Chunk returns a loaded chunk, or nil if it is not loaded.
+func (w *ChunkedWorld) Chunk(cx, cy int) *Chunk {
+	return w.chunks[ChunkCoord{CX: cx, CY: cy}]
+}
+
+// Loaded returns the coordinates of all loaded chunks in row-major order.
+func (w *ChunkedWorld) Loaded() []ChunkCoord {
+	coords := make([]ChunkCoord, 0, len(w.chunks))
+	for c := range w.chunks {
+		coords = append(coords, c)
+	}
+	sortChunkCoords(coords)
+	return coords
+}
+
+// GenerateChunk generates and loads the chunk at (cx, cy). Already loaded
+// chunks are returned unchanged.
+func (w *ChunkedWorld) GenerateChunk(cx, cy int) (*Chunk, error) {
+	coord := ChunkCoord{CX: cx, CY: cy}
+	if !w.inBounds(coord) {
+		return nil, ErrChunkOutOfBounds
+	}
+	if c, ok := w.chunks[coord]; ok {
+		return c, nil
+	}
+
+	seed := ChunkSeed(w.Seed, cx, cy)
+	g, err := NewGenerator(w.ChunkSize, w.ChunkSize, rng.NewRNG(seed))
+	if err != nil {
+		return nil, err
+	}
+	g.SetGenre(w.Genre)
+	root, tiles := g.Generate()
+	w.carveGates(g, coord, root, tiles)
+	x, y := cx*w.ChunkSize, cy*w.ChunkSize
+	offsetNode(root, x, y)
+
+	c := &Chunk{
+		Coord: coord,
+		X:     x,
+		Y:     y,
+		Size:  w.ChunkSize,
+		Seed:  seed,
+		Root:  root,
+		Tiles: tiles,
+	}
+	w.chunks[coord] = c
+	if w.OnLoad != nil {
+		w.OnLoad(c)
+	}
+	return c, nil
+}
+
+// UnloadChunk removes a chunk from memory. It can be regenerated later.
+func (w *ChunkedWorld) UnloadChunk(cx, cy int) {
+	coord := ChunkCoord{CX: cx, CY: cy}
+	c, ok := w.chunks[coord]
+	if !ok {
+		return
+	}
+	if w.OnUnload != nil {
+		w.OnUnload(c)
+	}
+	delete(w.chunks, coord)
+}
+
+// UpdateAround loads every chunk within LoadRadius of the chunk containing
+// (x, y) and unloads all others. It returns the coordinates that were loaded
+// and unloaded by this call, each in row-major order.
+func (w *ChunkedWorld) UpdateAround(x, y float64) (loaded, unloaded []ChunkCoord, err error) {
+	center := w.ChunkAt(x, y)
+	want := make(map[ChunkCoord]bool)
+	for dy := -w.LoadRadius; dy <= w.LoadRadius; dy++ {
+		for dx := -w.LoadRadius; dx <= w.LoadRadius; dx++ {
+			c := ChunkCoord{CX: center.CX + dx, CY: center.CY + dy}
+			if w.inBounds(c) {
+				want[c] = true
+			}
+		}
+	}
+
+	for _, c := range w.Loaded() {
+		if !want[c] {
+			w.UnloadChunk(c.CX, c.CY)
+			unloaded = append(unloaded, c)
+		}
+	}
+
+	targets := make([]ChunkCoord, 0, len(want))
+	for c := range want {
+		if _, ok := w.chunks[c]; !ok {
+			targets = append(targets, c)
+		}
+	}
+	sortChunkCoords(targets)
+	for _, c := range targets {
+		if _, err := w.GenerateChunk(c.CX, c.CY); err != nil {
+			return loaded, unloaded, err
+		}
+		loaded = append(loaded, c)
+	}
+	return loaded, unloaded, nil
+}
+
+// Root joins the partitions of all loaded chunks under one node, so GetRooms
+// and FindRoomByTag see every loaded room in world coordinates. It returns
+// nil when nothing is loaded.
+func (w *ChunkedWorld) Root() *Node {
+	var nodes []*Node
+	for _, c := range w.Loaded() {
+		nodes = append(nodes, w.chunks[c].Root)
+	}
+	for len(nodes) > 1 {
+		var joined []*Node
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				joined = append(joined, nodes[i])
+				continue
+			}
+			joined = append(joined, &Node{Left: nodes[i], Right: nodes[i+1]})
+		}
+		nodes = joined
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	return &Node{W: w.Width, H: w.Height, Left: nodes[0]}
+}
+
+// TileAt returns the tile at a world position. The second result is false
+// if the position is outside the world or its chunk is not loaded.
+func (w *ChunkedWorld) TileAt(x, y int) (int, bool) {
+	if x < 0 || y < 0 || x >= w.Width || y >= w.Height {
+		return TileEmpty, false
+	}
+	c := w.chunks[ChunkCoord{CX: x / w.ChunkSize, CY: y / w.ChunkSize}]
+	if c == nil {
+		return TileEmpty, false
+	}
+	return c.Tiles[y-c.Y][x-c.X], true
+}
+
+// Window copies a rectangular region of the world into a new tile grid.
+// Unloaded or out-of-bounds tiles are reported as TileEmpty, which the
+// raycaster and automap treat as open space.
+func (w *ChunkedWorld) Window(x, y, width, height int) [][]int {
+	out := make([][]int, height)
+	for dy := range out {
+		out[dy] = make([]int, width)
+		for dx := range out[dy] {
+			out[dy][dx], _ = w.TileAt(x+dx, y+dy)
+		}
+	}
+	return out
+}
+
+// WallMask returns a wall grid for a region, in the shape expected by
+// automap.RenderConfig.Walls. Unloaded tiles are not walls.
+func (w *ChunkedWorld) WallMask(x, y, width, height int) [][]bool {
+	out := make([][]bool, height)
+	for dy := range out {
+		out[dy] = make([]bool, width)
+		for dx := range out[dy] {
+			t, ok := w.TileAt(x+dx, y+dy)
+			out[dy][dx] = ok && (t == TileWall || (t >= TileWallStone && t <= TileWallPipes) ||
+				(t >= TileBars && t <= TileForceField) || (t >= TilePortal && t <= TilePortalLast))
+		}
+	}
+	return out
+}
+
+// gatePosition returns the offset along a shared chunk edge where the gate
+// between two chunks is carved. Both neighbors derive the same value.
+func (w *ChunkedWorld) gatePosition(a, b ChunkCoord) int {
+	if b.CX < a.CX || b.CY < a.CY {
+		a, b = b, a
+	}
+	h := mix64(ChunkSeed(w.Seed, a.CX, a.CY) ^ ChunkSeed(w.Seed, b.CX, b.CY) ^ 0x6761746573)
+	return 2 + int(h%uint64(w.ChunkSize-4))
+}
+
+// carveGates opens the chunk border toward each in-bounds neighbor and
+// connects each gate to the nearest room.
+func (w *ChunkedWorld) carveGates(g *Generator, coord ChunkCoord, root *Node, tiles [][]int) {
+	rooms := GetRooms(root)
+	if len(rooms) == 0 {
+		return
+	}
+	last := w.ChunkSize - 1
+
+	neighbors := []struct {
+		dx, dy int
+	}{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	for _, n := range neighbors {
+		other := ChunkCoord{CX: coord.CX + n.dx, CY: coord.CY + n.dy}
+		if !w.inBounds(other) {
+			continue
+		}
+		pos := w.gatePosition(coord, other)
+
+		var gx, gy int
+		switch {
+		case n.dx == 1:
+			gx, gy = last, pos
+		case n.dx == -1:
+			gx, gy = 0, pos
+		case n.dy == 1:
+			gx, gy = pos, last
+		default:
+			gx, gy = pos, 0
+		}
+
+		target := nearestRoom(rooms, gx, gy)
+		tx, ty := target.Center()
+		// carveCorridor runs along y1 first, then x2, so order the
+		// endpoints to leave the gate perpendicular to its edge.
+		if n.dx != 0 {
+			g.carveCorridor(gx, gy, tx, ty, tiles)
+		} else {
+			g.carveCorridor(tx, ty, gx, gy, tiles)
+		}
+	}
+}
+
+// offsetNode moves a generated partition and its rooms by (dx, dy).
+func offsetNode(n *Node, dx, dy int) {
+	if n == nil {
+		return
+	}
+	n.X += dx
+	n.Y += dy
+	if n.Room != nil {
+		n.Room.X += dx
+		n.Room.Y += dy
+	}
+	offsetNode(n.Left, dx, dy)
+	offsetNode(n.Right, dx, dy)
+}
+
+// nearestRoom returns the room whose center is closest to (x, y).
+func nearestRoom(rooms []*Room, x, y int) *Room {
+	best := rooms[0]
+	bestDist := -1
+	for _, r := range rooms {
+		cx, cy := r.Center()
+		d := (cx-x)*(cx-x) + (cy-y)*(cy-y)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = r, d
+		}
+	}
+	return best
+}
+
+func sortChunkCoords(coords []ChunkCoord) {
+	sort.Slice(coords, func(i, j int) bool {
+		if coords[i].CY != coords[j].CY {
+			return coords[i].CY < coords[j].CY
+		}
+		return coords[i].CX < coords[j].CX
+	})
+}
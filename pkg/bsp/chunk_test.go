@@ -0,0 +1,212 @@
+package bsp
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/procgen/genre"
+)
+
+func newTestWorld(t *testing.T) *ChunkedWorld {
+	t.Helper()
+	w, err := NewChunkedWorld(512, 512, DefaultChunkSize, 0xC0FFEE, genre.Fantasy)
+	if err != nil {
+		t.Fatalf("NewChunkedWorld failed: %v", err)
+	}
+	return w
+}
+
+func TestNewChunkedWorldValidation(t *testing.T) {
+	if _, err := NewChunkedWorld(512, 512, 8, 1, genre.Fantasy); err != ErrInvalidChunkSize {
+		t.Errorf("small chunk: err = %v, want %v", err, ErrInvalidChunkSize)
+	}
+	if _, err := NewChunkedWorld(0, 512, 64, 1, genre.Fantasy); err != ErrInvalidWidth {
+		t.Errorf("zero width: err = %v, want %v", err, ErrInvalidWidth)
+	}
+	if _, err := NewChunkedWorld(512, -1, 64, 1, genre.Fantasy); err != ErrInvalidHeight {
+		t.Errorf("negative height: err = %v, want %v", err, ErrInvalidHeight)
+	}
+
+	w, err := NewChunkedWorld(100, 70, 64, 1, genre.Fantasy)
+	if err != nil {
+		t.Fatalf("NewChunkedWorld failed: %v", err)
+	}
+	if w.Columns() != 2 || w.Rows() != 2 || w.Width != 128 || w.Height != 128 {
+		t.Errorf("world rounded to %dx%d (%dx%d chunks), want 128x128 (2x2)", w.Width, w.Height, w.Columns(), w.Rows())
+	}
+}
+
+func TestChunkRegenerationIsDeterministic(t *testing.T) {
+	w := newTestWorld(t)
+	c1, err := w.GenerateChunk(3, 4)
+	if err != nil {
+		t.Fatalf("GenerateChunk failed: %v", err)
+	}
+	first := c1.Tiles
+
+	w.UnloadChunk(3, 4)
+	if w.Chunk(3, 4) != nil {
+		t.Fatal("chunk still loaded after UnloadChunk")
+	}
+
+	c2, err := w.GenerateChunk(3, 4)
+	if err != nil {
+		t.Fatalf("GenerateChunk failed: %v", err)
+	}
+	if !tilesEqual(first, c2.Tiles) {
+		t.Error("regenerated chunk differs from original")
+	}
+	if c1.Seed != ChunkSeed(w.Seed, 3, 4) {
+		t.Error("chunk seed does not match ChunkSeed")
+	}
+	if ChunkSeed(w.Seed, 3, 4) == ChunkSeed(w.Seed, 4, 3) {
+		t.Error("transposed chunk coordinates share a seed")
+	}
+}
+
+func TestChunkGenerationOutOfBounds(t *testing.T) {
+	w := newTestWorld(t)
+	for _, c := range []ChunkCoord{{-1, 0}, {0, -1}, {8, 0}, {0, 8}} {
+		if _, err := w.GenerateChunk(c.CX, c.CY); err != ErrChunkOutOfBounds {
+			t.Errorf("GenerateChunk(%d,%d) err = %v, want %v", c.CX, c.CY, err, ErrChunkOutOfBounds)
+		}
+	}
+}
+
+func TestChunkGatesAreSeamless(t *testing.T) {
+	w := newTestWorld(t)
+	if _, err := w.GenerateChunk(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.GenerateChunk(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.GenerateChunk(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// East edge of (1,1) meets west edge of (2,1).
+	pos := w.gatePosition(ChunkCoord{1, 1}, ChunkCoord{2, 1})
+	y := w.ChunkSize + pos
+	left, _ := w.TileAt(2*w.ChunkSize-1, y)
+	right, _ := w.TileAt(2*w.ChunkSize, y)
+	if !isFloorLike(left) || !isFloorLike(right) {
+		t.Errorf("east/west gate tiles = %d, %d; want floor", left, right)
+	}
+
+	// South edge of (1,1) meets north edge of (1,2).
+	pos = w.gatePosition(ChunkCoord{1, 2}, ChunkCoord{1, 1})
+	x := w.ChunkSize + pos
+	top, _ := w.TileAt(x, 2*w.ChunkSize-1)
+	bottom, _ := w.TileAt(x, 2*w.ChunkSize)
+	if !isFloorLike(top) || !isFloorLike(bottom) {
+		t.Errorf("north/south gate tiles = %d, %d; want floor", top, bottom)
+	}
+}
+
+func TestUpdateAroundLoadsAndUnloads(t *testing.T) {
+	w := newTestWorld(t)
+	var loads, unloads int
+	w.OnLoad = func(*Chunk) { loads++ }
+	w.OnUnload = func(*Chunk) { unloads++ }
+
+	loaded, unloaded, err := w.UpdateAround(200, 200) // chunk (3,3)
+	if err != nil {
+		t.Fatalf("UpdateAround failed: %v", err)
+	}
+	if len(loaded) != 9 || len(unloaded) != 0 || loads != 9 {
+		t.Errorf("first update loaded %d, unloaded %d, callbacks %d; want 9, 0, 9", len(loaded), len(unloaded), loads)
+	}
+
+	loaded, unloaded, err = w.UpdateAround(264, 200) // chunk (4,3)
+	if err != nil {
+		t.Fatalf("UpdateAround failed: %v", err)
+	}
+	if len(loaded) != 3 || len(unloaded) != 3 || unloads != 3 {
+		t.Errorf("shift loaded %d, unloaded %d, callbacks %d; want 3, 3, 3", len(loaded), len(unloaded), unloads)
+	}
+	if len(w.Loaded()) != 9 {
+		t.Errorf("%d chunks loaded, want 9", len(w.Loaded()))
+	}
+
+	// Corner of the world only has 4 in-bounds chunks.
+	if _, _, err := w.UpdateAround(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(w.Loaded()) != 4 {
+		t.Errorf("corner: %d chunks loaded, want 4", len(w.Loaded()))
+	}
+}
+
+func TestChunkedWorldRoot(t *testing.T) {
+	w := newTestWorld(t)
+	if w.Root() != nil {
+		t.Error("Root() of an empty world is not nil")
+	}
+	if _, _, err := w.UpdateAround(200, 200); err != nil {
+		t.Fatalf("UpdateAround failed: %v", err)
+	}
+
+	want := 0
+	for _, coord := range w.Loaded() {
+		c := w.Chunk(coord.CX, coord.CY)
+		for _, r := range GetRooms(c.Root) {
+			want++
+			if r.X < c.X || r.Y < c.Y || r.X+r.W > c.X+c.Size || r.Y+r.H > c.Y+c.Size {
+				t.Errorf("chunk %v room %+v outside chunk at (%d, %d)", coord, *r, c.X, c.Y)
+			}
+			if tile, _ := w.TileAt(r.X+r.W/2, r.Y+r.H/2); tile == TileWall {
+				t.Errorf("chunk %v room center (%d, %d) is a wall", coord, r.X+r.W/2, r.Y+r.H/2)
+			}
+		}
+	}
+	if got := len(GetRooms(w.Root())); got != want || want == 0 {
+		t.Errorf("Root() has %d rooms, want %d from loaded chunks", got, want)
+	}
+}
+
+func TestChunkedWorldTileAccess(t *testing.T) {
+	w := newTestWorld(t)
+	if _, ok := w.TileAt(10, 10); ok {
+		t.Error("TileAt reported unloaded tile as present")
+	}
+	c, err := w.GenerateChunk(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tile, ok := w.TileAt(10, 10); !ok || tile != c.Tiles[10][10] {
+		t.Errorf("TileAt(10,10) = %d, %v; want %d, true", tile, ok, c.Tiles[10][10])
+	}
+
+	window := w.Window(60, 0, 8, 4)
+	if window[0][0] != c.Tiles[0][60] || window[0][7] != TileEmpty {
+		t.Error("Window did not copy loaded tiles and blank unloaded ones")
+	}
+
+	mask := w.WallMask(0, 0, 4, 4)
+	if !mask[0][0] {
+		t.Error("WallMask corner of a generated chunk should be a wall")
+	}
+
+	minX, minY, maxX, maxY := c.Bounds()
+	if minX != 0 || minY != 0 || maxX != 64 || maxY != 64 {
+		t.Errorf("Bounds = (%v,%v,%v,%v), want (0,0,64,64)", minX, minY, maxX, maxY)
+	}
+}
+
+func isFloorLike(tile int) bool {
+	return tile == TileFloor || tile == TileDoor || (tile >= TileFloorStone && tile <= TileFloorGrate)
+}
+
+func BenchmarkChunkGeneration(b *testing.B) {
+	w, err := NewChunkedWorld(512, 512, DefaultChunkSize, 1, genre.Fantasy)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.UnloadChunk(2, 2)
+		if _, err := w.GenerateChunk(2, 2); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
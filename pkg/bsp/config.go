@@ -0,0 +1,148 @@
+package bsp
+
+import (
+	"errors"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// Difficulty levels, matching the ordering of ui.DifficultyLevel.
+const (
+	DifficultyEasy      = iota // DifficultyEasy is the easiest difficulty.
+	DifficultyNormal           // DifficultyNormal is the standard difficulty.
+	DifficultyHard             // DifficultyHard is the challenging difficulty.
+	DifficultyNightmare        // DifficultyNightmare is the extreme difficulty.
+)
+
+const (
+	// maxConfigLevelSize caps difficulty/depth scaling of map dimensions.
+	maxConfigLevelSize = 128
+	// maxEnemyBudget caps the number of enemies a level may request.
+	maxEnemyBudget = 32
+	// maxHazardBudget caps the number of hazards a level may request.
+	maxHazardBudget = 30
+	// maxConfigPortalPairs caps the portal shortcuts a level may request.
+	maxConfigPortalPairs = 3
+)
+
+// ErrInvalidRoomRange is returned when room count or size limits are inconsistent.
+var ErrInvalidRoomRange = errors.New("invalid room range")
+
+// GenerationConfig holds level generation parameters derived from
+// difficulty and level depth.
+type GenerationConfig struct {
+	Width        int     // Map width in tiles
+	Height       int     // Map height in tiles
+	MinRoomSize  int     // Minimum room dimension
+	MaxRoomSize  int     // Maximum room dimension
+	MinRooms     int     // Minimum number of BSP leaves (rooms)
+	MaxRooms     int     // Maximum number of BSP leaves (rooms); 0 means unlimited
+	EnemyBudget  int     // Number of regular enemies to spawn
+	HazardBudget int     // Number of environmental hazards to place
+	LoopRate     float64 // Chance of extra passages between sibling subtrees
+	PortalPairs  int     // Linked portal pairs acting as shortcuts
+}
+
+// NewGenerationConfig derives generation parameters for a difficulty
+// (DifficultyEasy..DifficultyNightmare) and a 1-based level depth. Harder
+// and deeper levels are larger, denser, more looped, and more dangerous;
+// from depth 4 they also gain portal shortcuts.
+// Normal difficulty at depth 1 matches the classic 64x64 layout.
+func NewGenerationConfig(difficulty, depth int) GenerationConfig {
+	difficulty = clamp(difficulty, DifficultyEasy, DifficultyNightmare)
+	if depth < 1 {
+		depth = 1
+	}
+
+	size := clamp(56+8*difficulty+4*(depth-1), MinLevelSize, maxConfigLevelSize)
+	minRooms := 4 + difficulty + depth/2
+	maxRooms := minRooms + 6 + difficulty
+
+	return GenerationConfig{
+		Width:        size,
+		Height:       size,
+		MinRoomSize:  6,
+		MaxRoomSize:  12 - difficulty,
+		MinRooms:     minRooms,
+		MaxRooms:     maxRooms,
+		EnemyBudget:  min(2+difficulty+(depth-1)/2, maxEnemyBudget),
+		HazardBudget: min(4+2*difficulty+(depth-1), maxHazardBudget),
+		LoopRate:     DefaultLoopRate + 0.05*float64(difficulty),
+		PortalPairs:  min((depth-1)/3, maxConfigPortalPairs),
+	}
+}
+
+// Validate checks that the configuration describes a generatable level.
+func (c GenerationConfig) Validate() error {
+	if c.Width <= 0 || c.Width > MaxLevelSize {
+		return ErrInvalidWidth
+	}
+	if c.Height <= 0 || c.Height > MaxLevelSize {
+		return ErrInvalidHeight
+	}
+	if c.MinRoomSize < 3 || c.MaxRoomSize < c.MinRoomSize {
+		return ErrInvalidRoomRange
+	}
+	if c.MinRooms < 0 || (c.MaxRooms > 0 && c.MaxRooms < c.MinRooms) {
+		return ErrInvalidRoomRange
+	}
+	return nil
+}
+
+// NewGeneratorFromConfig creates a BSP generator using the given config.
+func NewGeneratorFromConfig(cfg GenerationConfig, r *rng.RNG) (*Generator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	g, err := NewGenerator(cfg.Width, cfg.Height, r)
+	if err != nil {
+		return nil, err
+	}
+	g.MinSize = cfg.MinRoomSize
+	g.MaxSize = cfg.MaxRoomSize
+	g.MinRooms = cfg.MinRooms
+	g.MaxRooms = cfg.MaxRooms
+	g.LoopRate = cfg.LoopRate
+	g.PortalPairs = cfg.PortalPairs
+	return g, nil
+}
+
+// splitToCount partitions the tree by repeatedly splitting the largest leaf
+// until the leaf count reaches a target drawn from [MinRooms, MaxRooms].
+// Unlike the recursive split, this keeps partitions balanced when the
+// room count is capped.
+func (g *Generator) splitToCount(root *Node) {
+	target := g.MinRooms
+	if g.MaxRooms > g.MinRooms {
+		target += g.rng.Intn(g.MaxRooms - g.MinRooms + 1)
+	}
+
+	// open holds leaves that may still be split; count tracks all leaves.
+	open := []*Node{root}
+	count := 1
+	for count < target && len(open) > 0 {
+		idx := 0
+		for i, n := range open {
+			if n.W*n.H > open[idx].W*open[idx].H {
+				idx = i
+			}
+		}
+
+		n := open[idx]
+		open = append(open[:idx], open[idx+1:]...)
+		if g.splitOnce(n) {
+			open = append(open, n.Left, n.Right)
+			count++
+		}
+	}
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
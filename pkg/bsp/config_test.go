@@ -0,0 +1,105 @@
+package bsp
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+func TestNewGenerationConfigScaling(t *testing.T) {
+	normal := NewGenerationConfig(DifficultyNormal, 1)
+	if normal.Width != 64 || normal.Height != 64 {
+		t.Errorf("normal depth 1 size = %dx%d, want 64x64", normal.Width, normal.Height)
+	}
+
+	easy := NewGenerationConfig(DifficultyEasy, 1)
+	nightmare := NewGenerationConfig(DifficultyNightmare, 1)
+	if easy.Width >= nightmare.Width {
+		t.Errorf("easy width %d should be smaller than nightmare width %d", easy.Width, nightmare.Width)
+	}
+	if easy.EnemyBudget >= nightmare.EnemyBudget {
+		t.Errorf("easy enemies %d should be fewer than nightmare %d", easy.EnemyBudget, nightmare.EnemyBudget)
+	}
+	if easy.HazardBudget >= nightmare.HazardBudget {
+		t.Errorf("easy hazards %d should be fewer than nightmare %d", easy.HazardBudget, nightmare.HazardBudget)
+	}
+
+	shallow := NewGenerationConfig(DifficultyNormal, 1)
+	deep := NewGenerationConfig(DifficultyNormal, 10)
+	if deep.Width <= shallow.Width || deep.MinRooms <= shallow.MinRooms {
+		t.Errorf("depth 10 config %+v should exceed depth 1 config %+v", deep, shallow)
+	}
+
+	huge := NewGenerationConfig(DifficultyNightmare, 1000)
+	if huge.Width > maxConfigLevelSize || huge.EnemyBudget > maxEnemyBudget || huge.HazardBudget > maxHazardBudget {
+		t.Errorf("config not capped: %+v", huge)
+	}
+	if err := huge.Validate(); err != nil {
+		t.Errorf("capped config invalid: %v", err)
+	}
+}
+
+func TestNewGenerationConfigClampsInputs(t *testing.T) {
+	if got, want := NewGenerationConfig(-5, -3), NewGenerationConfig(DifficultyEasy, 1); got != want {
+		t.Errorf("negative inputs = %+v, want %+v", got, want)
+	}
+	if got, want := NewGenerationConfig(99, 1), NewGenerationConfig(DifficultyNightmare, 1); got != want {
+		t.Errorf("oversized difficulty = %+v, want %+v", got, want)
+	}
+}
+
+func TestGenerationConfigValidate(t *testing.T) {
+	base := NewGenerationConfig(DifficultyNormal, 1)
+	tests := []struct {
+		name    string
+		mutate  func(*GenerationConfig)
+		wantErr error
+	}{
+		{"valid", func(c *GenerationConfig) {}, nil},
+		{"zero_width", func(c *GenerationConfig) { c.Width = 0 }, ErrInvalidWidth},
+		{"huge_height", func(c *GenerationConfig) { c.Height = MaxLevelSize + 1 }, ErrInvalidHeight},
+		{"tiny_rooms", func(c *GenerationConfig) { c.MinRoomSize = 2 }, ErrInvalidRoomRange},
+		{"inverted_sizes", func(c *GenerationConfig) { c.MaxRoomSize = c.MinRoomSize - 1 }, ErrInvalidRoomRange},
+		{"inverted_counts", func(c *GenerationConfig) { c.MaxRooms = c.MinRooms - 1 }, ErrInvalidRoomRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			tt.mutate(&cfg)
+			if err := cfg.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGeneratorFromConfigRoomCount(t *testing.T) {
+	for difficulty := DifficultyEasy; difficulty <= DifficultyNightmare; difficulty++ {
+		for _, depth := range []int{1, 5} {
+			cfg := NewGenerationConfig(difficulty, depth)
+			g, err := NewGeneratorFromConfig(cfg, rng.NewRNG(uint64(difficulty*100+depth)))
+			if err != nil {
+				t.Fatalf("NewGeneratorFromConfig failed: %v", err)
+			}
+			root, tiles := g.Generate()
+			if len(tiles) != cfg.Height || len(tiles[0]) != cfg.Width {
+				t.Errorf("tiles = %dx%d, want %dx%d", len(tiles[0]), len(tiles), cfg.Width, cfg.Height)
+			}
+			rooms := len(GetRooms(root))
+			if rooms < cfg.MinRooms || rooms > cfg.MaxRooms {
+				t.Errorf("difficulty %d depth %d: %d rooms, want [%d,%d]", difficulty, depth, rooms, cfg.MinRooms, cfg.MaxRooms)
+			}
+		}
+	}
+}
+
+func TestGeneratorFromConfigInvalid(t *testing.T) {
+	cfg := NewGenerationConfig(DifficultyNormal, 1)
+	cfg.Width = 0
+	if g, err := NewGeneratorFromConfig(cfg, rng.NewRNG(1)); err == nil || g != nil {
+		t.Error("expected error for invalid config")
+	}
+	if _, err := NewGeneratorFromConfig(NewGenerationConfig(DifficultyNormal, 1), nil); err != ErrNilRNG {
+		t.Errorf("nil rng: err = %v, want %v", err, ErrNilRNG)
+	}
+}
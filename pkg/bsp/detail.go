@@ -0,0 +1,184 @@
+package bsp
+
+import "github.com/opd-ai/violence/pkg/procgen/genre"
+
+// Detail variant tiles. Variants reuse the genre wall (10-19) and floor
+// (20-29) ranges so existing wall/walkability checks keep working; their
+// appearance is interpreted per genre by the renderer.
+const (
+	// TileWallWorn is a weathered wall variant.
+	TileWallWorn = 15
+	// TileWallCracked is a cracked wall variant.
+	TileWallCracked = 16
+	// TileWallStained is a stained or grimy wall variant.
+	TileWallStained = 17
+	// TileWallPatterned is a decorated or paneled wall variant.
+	TileWallPatterned = 18
+	// TileWallPipes is a wall variant with exposed pipes or supports.
+	TileWallPipes = 19
+
+	// TileFloorWorn is a worn floor variant.
+	TileFloorWorn = 25
+	// TileFloorCracked is a cracked floor variant.
+	TileFloorCracked = 26
+	// TileFloorStained is a stained floor variant.
+	TileFloorStained = 27
+	// TileFloorPatterned is a tiled or inlaid floor variant.
+	TileFloorPatterned = 28
+	// TileFloorGrate is a drain or grate floor variant.
+	TileFloorGrate = 29
+)
+
+// DefaultDetailDensity is the default share of eligible tiles that may
+// become variants rather than the genre base tile.
+const DefaultDetailDensity = 0.25
+
+// tileVariant is a candidate tile with a relative collapse weight.
+type tileVariant struct {
+	id     int
+	weight int
+}
+
+// genreFloorVariants lists the floor variants each genre may use.
+var genreFloorVariants = map[string][]tileVariant{
+	genre.Fantasy:   {{TileFloorWorn, 3}, {TileFloorCracked, 2}, {TileFloorPatterned, 1}},
+	genre.SciFi:     {{TileFloorWorn, 2}, {TileFloorPatterned, 3}, {TileFloorGrate, 2}},
+	genre.Horror:    {{TileFloorWorn, 2}, {TileFloorCracked, 2}, {TileFloorStained, 3}},
+	genre.Cyberpunk: {{TileFloorStained, 2}, {TileFloorPatterned, 3}, {TileFloorGrate, 2}},
+	genre.PostApoc:  {{TileFloorWorn, 3}, {TileFloorCracked, 3}, {TileFloorStained, 2}},
+}
+
+// genreWallVariants lists the wall variants each genre may use.
+var genreWallVariants = map[string][]tileVariant{
+	genre.Fantasy:   {{TileWallWorn, 3}, {TileWallCracked, 2}, {TileWallPatterned, 1}},
+	genre.SciFi:     {{TileWallPatterned, 3}, {TileWallPipes, 2}, {TileWallWorn, 1}},
+	genre.Horror:    {{TileWallCracked, 3}, {TileWallStained, 3}, {TileWallWorn, 1}},
+	genre.Cyberpunk: {{TileWallStained, 2}, {TileWallPipes, 3}, {TileWallPatterned, 2}},
+	genre.PostApoc:  {{TileWallWorn, 3}, {TileWallCracked, 2}, {TileWallPipes, 2}},
+}
+
+// coherenceBonus multiplies the weight of a variant already chosen by a
+// collapsed neighbor so variants grow into patches rather than noise.
+const coherenceBonus = 6
+
+// detailTiles runs a wave-function-collapse style pass that replaces base
+// genre wall and floor tiles with variants. Each eligible cell starts with
+// the base tile and all genre variants in its domain; cells collapse in
+// scanline order and propagate an adjacency rule that a variant may only
+// touch the base tile or the same variant, giving locally coherent patches.
+func (g *Generator) detailTiles(tiles [][]int) {
+	// Generic tiles have no genre variants.
+	if g.DetailDensity <= 0 || g.floorTile == TileFloor {
+		return
+	}
+	g.detailLayer(tiles, g.floorTile, genreFloorVariants[g.genre])
+	g.detailLayer(tiles, g.wallTile, genreWallVariants[g.genre])
+}
+
+// detailLayer collapses all cells equal to base using the given variants.
+func (g *Generator) detailLayer(tiles [][]int, base int, variants []tileVariant) {
+	if len(variants) == 0 {
+		return
+	}
+
+	// Domain bit 0 is the base tile; bit i+1 is variants[i].
+	full := uint32(1)<<uint(len(variants)+1) - 1
+	domains := make([][]uint32, g.Height)
+	for y := range domains {
+		domains[y] = make([]uint32, g.Width)
+		for x := range domains[y] {
+			if tiles[y][x] == base {
+				domains[y][x] = full
+			}
+		}
+	}
+
+	baseWeight := g.baseWeight(variants)
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			if domains[y][x] == 0 {
+				continue
+			}
+			choice := g.collapseCell(tiles, domains, x, y, baseWeight, variants)
+			domains[y][x] = 1 << uint(choice)
+			if choice > 0 {
+				tiles[y][x] = variants[choice-1].id
+			}
+			g.propagate(domains, x, y, choice)
+		}
+	}
+}
+
+// baseWeight derives the base tile weight so that variants make up roughly
+// DetailDensity of an unconstrained domain.
+func (g *Generator) baseWeight(variants []tileVariant) int {
+	total := 0
+	for _, v := range variants {
+		total += v.weight
+	}
+	density := g.DetailDensity
+	if density >= 1 {
+		return 0
+	}
+	return int(float64(total) * (1 - density) / density)
+}
+
+// collapseCell picks a weighted option from the cell's remaining domain,
+// boosting options that match already-collapsed neighbors.
+func (g *Generator) collapseCell(tiles [][]int, domains [][]uint32, x, y, baseWeight int, variants []tileVariant) int {
+	domain := domains[y][x]
+	weights := make([]int, len(variants)+1)
+	total := 0
+	for i := range weights {
+		if domain&(1<<uint(i)) == 0 {
+			continue
+		}
+		w := baseWeight
+		if i > 0 {
+			w = variants[i-1].weight
+			if g.neighborHas(tiles, x, y, variants[i-1].id) {
+				w *= coherenceBonus
+			}
+		}
+		weights[i] = w
+		total += w
+	}
+	if total == 0 {
+		return 0
+	}
+
+	pick := g.rng.Intn(total)
+	for i, w := range weights {
+		if pick < w {
+			return i
+		}
+		pick -= w
+	}
+	return 0
+}
+
+// neighborHas reports whether any 4-neighbor of (x,y) already holds id.
+func (g *Generator) neighborHas(tiles [][]int, x, y, id int) bool {
+	return (x > 0 && tiles[y][x-1] == id) ||
+		(y > 0 && tiles[y-1][x] == id) ||
+		(x < g.Width-1 && tiles[y][x+1] == id) ||
+		(y < g.Height-1 && tiles[y+1][x] == id)
+}
+
+// propagate restricts uncollapsed neighbors after (x,y) collapsed to choice.
+// The base tile is always compatible, so domains never become empty.
+func (g *Generator) propagate(domains [][]uint32, x, y, choice int) {
+	if choice == 0 {
+		return
+	}
+	allowed := uint32(1) | 1<<uint(choice)
+	for _, d := range [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		nx, ny := x+d[0], y+d[1]
+		if nx < 0 || ny < 0 || nx >= g.Width || ny >= g.Height {
+			continue
+		}
+		if dom := domains[ny][nx]; dom != 0 && dom&(dom-1) != 0 {
+			domains[ny][nx] = dom & allowed
+		}
+	}
+}
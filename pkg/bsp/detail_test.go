@@ -0,0 +1,116 @@
+package bsp
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/procgen/genre"
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+func generateDetailed(t *testing.T, genreID string, seed uint64, density float64) [][]int {
+	t.Helper()
+	g, err := NewGenerator(64, 64, rng.NewRNG(seed))
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	g.SetGenre(genreID)
+	g.DetailDensity = density
+	_, tiles := g.Generate()
+	return tiles
+}
+
+func TestDetailTilesProducesGenreVariants(t *testing.T) {
+	for _, genreID := range []string{genre.Fantasy, genre.SciFi, genre.Horror, genre.Cyberpunk, genre.PostApoc} {
+		t.Run(genreID, func(t *testing.T) {
+			tiles := generateDetailed(t, genreID, 2024, DefaultDetailDensity)
+
+			allowed := map[int]bool{}
+			for _, v := range genreFloorVariants[genreID] {
+				allowed[v.id] = true
+			}
+			for _, v := range genreWallVariants[genreID] {
+				allowed[v.id] = true
+			}
+
+			floorVariants, wallVariants := 0, 0
+			for y := range tiles {
+				for x := range tiles[y] {
+					id := tiles[y][x]
+					switch {
+					case id >= TileFloorWorn && id <= TileFloorGrate:
+						floorVariants++
+					case id >= TileWallWorn && id <= TileWallPipes:
+						wallVariants++
+					default:
+						continue
+					}
+					if !allowed[id] {
+						t.Fatalf("tile %d at (%d,%d) is not a %s variant", id, x, y, genreID)
+					}
+				}
+			}
+			if floorVariants == 0 || wallVariants == 0 {
+				t.Errorf("floor variants = %d, wall variants = %d; expected both > 0", floorVariants, wallVariants)
+			}
+		})
+	}
+}
+
+func TestDetailTilesAdjacencyRule(t *testing.T) {
+	tiles := generateDetailed(t, genre.Horror, 77, 0.6)
+	isVariant := func(id int) bool {
+		return (id >= TileWallWorn && id <= TileWallPipes) || (id >= TileFloorWorn && id <= TileFloorGrate)
+	}
+	for y := range tiles {
+		for x := range tiles[y] {
+			a := tiles[y][x]
+			if !isVariant(a) {
+				continue
+			}
+			if x+1 < len(tiles[y]) {
+				if b := tiles[y][x+1]; isVariant(b) && b != a && (a < 20) == (b < 20) {
+					t.Fatalf("variants %d and %d adjacent at (%d,%d)", a, b, x, y)
+				}
+			}
+			if y+1 < len(tiles) {
+				if b := tiles[y+1][x]; isVariant(b) && b != a && (a < 20) == (b < 20) {
+					t.Fatalf("variants %d and %d adjacent at (%d,%d)", a, b, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestDetailTilesDeterministic(t *testing.T) {
+	a := generateDetailed(t, genre.Cyberpunk, 555, DefaultDetailDensity)
+	b := generateDetailed(t, genre.Cyberpunk, 555, DefaultDetailDensity)
+	if !tilesEqual(a, b) {
+		t.Error("same seed produced different detailed maps")
+	}
+}
+
+func TestDetailTilesDisabled(t *testing.T) {
+	tiles := generateDetailed(t, genre.Fantasy, 31, 0)
+	for y := range tiles {
+		for x := range tiles[y] {
+			if id := tiles[y][x]; (id >= TileWallWorn && id <= TileWallPipes) || id >= TileFloorWorn {
+				t.Fatalf("unexpected variant %d at (%d,%d) with detailing disabled", id, x, y)
+			}
+		}
+	}
+}
+
+func TestDetailTilesGenericGenreUnchanged(t *testing.T) {
+	g, err := NewGenerator(32, 32, rng.NewRNG(8))
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	_, tiles := g.Generate()
+	for y := range tiles {
+		for x := range tiles[y] {
+			if tiles[y][x] >= TileWallWorn {
+				t.Fatalf("generic genre produced variant tile %d", tiles[y][x])
+			}
+		}
+	}
+}
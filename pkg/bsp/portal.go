@@ -0,0 +1,117 @@
+package bsp
+
+// maxPortalPairs is the number of distinct portal tile IDs.
+const maxPortalPairs = TilePortalLast - TilePortal + 1
+
+// minPortalDistance is the minimum Manhattan distance between the two ends
+// of a portal pair, so portals act as shortcuts rather than doorways.
+const minPortalDistance = 16
+
+// Directions a portal candidate opens toward, i.e. the side its floor is on.
+const (
+	openNorth = iota
+	openSouth
+	openWest
+	openEast
+	openNone
+)
+
+// oppositeOpening maps an opening to the one its partner portal needs: a
+// ray entering a north-facing wall heading south must leave a south-facing
+// wall still heading south.
+var oppositeOpening = [4]int{openSouth, openNorth, openEast, openWest}
+
+// placePortals turns pairs of straight room wall tiles into linked portals.
+// The two ends face opposite ways, so a ray or player passing through one
+// keeps its heading out of the other, and each end is as far from its
+// partner as the candidates allow.
+func (g *Generator) placePortals(tiles [][]int) {
+	pairs := min(g.PortalPairs, maxPortalPairs)
+	if pairs <= 0 {
+		return
+	}
+
+	var candidates [4][][2]int
+	for y := 1; y < g.Height-1; y++ {
+		for x := 1; x < g.Width-1; x++ {
+			if dir := g.wallOpening(tiles, x, y); dir != openNone {
+				candidates[dir] = append(candidates[dir], [2]int{x, y})
+			}
+		}
+	}
+
+	for id := TilePortal; id < TilePortal+pairs; id++ {
+		// Start from the most common opening that still has partners.
+		dir := -1
+		for d := range candidates {
+			if len(candidates[oppositeOpening[d]]) == 0 {
+				continue
+			}
+			if dir < 0 || len(candidates[d]) > len(candidates[dir]) {
+				dir = d
+			}
+		}
+		if dir < 0 || len(candidates[dir]) == 0 {
+			return
+		}
+		partner := oppositeOpening[dir]
+
+		a := candidates[dir][g.rng.Intn(len(candidates[dir]))]
+		b, dist := a, -1
+		for _, c := range candidates[partner] {
+			if d := absInt(c[0]-a[0]) + absInt(c[1]-a[1]); d > dist {
+				b, dist = c, d
+			}
+		}
+		if dist < minPortalDistance {
+			return
+		}
+
+		tiles[a[1]][a[0]] = id
+		tiles[b[1]][b[0]] = id
+		for d := range candidates {
+			candidates[d] = removeNear(candidates[d], a, b)
+		}
+	}
+}
+
+// wallOpening returns which side of a straight base wall tile has floor, or
+// openNone when the tile is not a single-sided wall segment between two
+// other wall tiles.
+func (g *Generator) wallOpening(tiles [][]int, x, y int) int {
+	if tiles[y][x] != g.wallTile {
+		return openNone
+	}
+	floor := func(t int) bool { return t == g.floorTile }
+	wall := func(t int) bool { return t == g.wallTile }
+
+	n, s := tiles[y-1][x], tiles[y+1][x]
+	w, e := tiles[y][x-1], tiles[y][x+1]
+	switch {
+	case wall(w) && wall(e) && floor(n) && wall(s):
+		return openNorth
+	case wall(w) && wall(e) && floor(s) && wall(n):
+		return openSouth
+	case wall(n) && wall(s) && floor(w) && wall(e):
+		return openWest
+	case wall(n) && wall(s) && floor(e) && wall(w):
+		return openEast
+	default:
+		return openNone
+	}
+}
+
+// removeNear drops candidates within one tile of either placed portal, so
+// portals never sit side by side.
+func removeNear(list [][2]int, a, b [2]int) [][2]int {
+	near := func(c, p [2]int) bool {
+		return absInt(c[0]-p[0]) <= 1 && absInt(c[1]-p[1]) <= 1
+	}
+	out := list[:0]
+	for _, c := range list {
+		if !near(c, a) && !near(c, b) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
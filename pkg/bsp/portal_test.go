@@ -0,0 +1,99 @@
+package bsp
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/procgen/genre"
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+func generateWithPortals(t *testing.T, seed uint64, pairs int) [][]int {
+	t.Helper()
+	g, err := NewGenerator(64, 64, rng.NewRNG(seed))
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	g.SetGenre(genre.Fantasy)
+	g.PortalPairs = pairs
+	_, tiles := g.Generate()
+	return tiles
+}
+
+func TestPlacePortalsPairs(t *testing.T) {
+	for _, seed := range []uint64{1, 42, 99} {
+		tiles := generateWithPortals(t, seed, 2)
+
+		ends := map[int][][2]int{}
+		for y := range tiles {
+			for x, id := range tiles[y] {
+				if id >= TilePortal && id <= TilePortalLast {
+					ends[id] = append(ends[id], [2]int{x, y})
+				}
+			}
+		}
+		if len(ends) == 0 {
+			t.Errorf("seed %d: no portals placed", seed)
+		}
+
+		for id, e := range ends {
+			if len(e) != 2 {
+				t.Fatalf("seed %d: portal %d has %d ends, want 2", seed, id, len(e))
+			}
+			a, b := e[0], e[1]
+			if d := absInt(a[0]-b[0]) + absInt(a[1]-b[1]); d < minPortalDistance {
+				t.Errorf("seed %d: portal %d ends %v and %v are %d apart, want >= %d", seed, id, a, b, d, minPortalDistance)
+			}
+			// The ends open on opposite sides, so passing through one
+			// keeps the heading out of the other.
+			da, db := floorSide(tiles, a), floorSide(tiles, b)
+			if da == [2]int{} || da != [2]int{-db[0], -db[1]} {
+				t.Errorf("seed %d: portal %d ends open toward %v and %v, want opposite", seed, id, da, db)
+			}
+		}
+	}
+}
+
+// floorSide returns the unit offset from a portal tile to its floor side.
+func floorSide(tiles [][]int, p [2]int) [2]int {
+	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		if isFloorLike(tiles[p[1]+d[1]][p[0]+d[0]]) {
+			return d
+		}
+	}
+	return [2]int{}
+}
+
+func TestPlacePortalsDisabled(t *testing.T) {
+	tiles := generateWithPortals(t, 42, 0)
+	for y := range tiles {
+		for x, id := range tiles[y] {
+			if id >= TilePortal && id <= TilePortalLast {
+				t.Fatalf("portal %d at (%d,%d) with PortalPairs = 0", id, x, y)
+			}
+		}
+	}
+
+	// Disabling portals leaves the rest of the level untouched.
+	g, _ := NewGenerator(64, 64, rng.NewRNG(42))
+	g.SetGenre(genre.Fantasy)
+	_, want := g.Generate()
+	for y := range tiles {
+		for x := range tiles[y] {
+			if tiles[y][x] != want[y][x] {
+				t.Fatalf("tile (%d,%d) = %d, want %d", x, y, tiles[y][x], want[y][x])
+			}
+		}
+	}
+}
+
+func TestGenerationConfigPortalPairs(t *testing.T) {
+	if got := NewGenerationConfig(DifficultyNormal, 1).PortalPairs; got != 0 {
+		t.Errorf("depth 1 PortalPairs = %d, want 0", got)
+	}
+	if got := NewGenerationConfig(DifficultyNormal, 4).PortalPairs; got != 1 {
+		t.Errorf("depth 4 PortalPairs = %d, want 1", got)
+	}
+	if got := NewGenerationConfig(DifficultyNormal, 1000).PortalPairs; got != maxConfigPortalPairs {
+		t.Errorf("deep PortalPairs = %d, want %d", got, maxConfigPortalPairs)
+	}
+}
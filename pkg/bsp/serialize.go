@@ -0,0 +1,416 @@
+package bsp
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// LevelMagic identifies a serialized Violence level.
+	LevelMagic = "VLVL"
+	// LevelFormatVersion is the current level format version. Version 1
+	// stored tiles run-length encoded; version 2 deflates them; version 3
+	// adds room tags.
+	LevelFormatVersion = uint16(3)
+)
+
+// decorationSeedSalt separates the decoration seed from the level seed.
+const decorationSeedSalt = 0x4445434F // "DECO"
+
+// maxRoomTag bounds decoded room tags. It is far above the tags defined so
+// files with tags added later still decode.
+const maxRoomTag = 1 << 16
+
+// maxPackedTiles bounds the compressed tile payload of a MaxLevelSize map.
+const maxPackedTiles = 2 * MaxLevelSize * MaxLevelSize
+
+// ErrInvalidLevelMagic is returned when decoded data is not a level file.
+var ErrInvalidLevelMagic = errors.New("invalid level magic bytes")
+
+// ErrUnsupportedLevelVersion is returned for level files from a newer format.
+var ErrUnsupportedLevelVersion = errors.New("unsupported level format version")
+
+// ErrTileOutOfRange is returned when a tile ID does not fit the encoding.
+var ErrTileOutOfRange = errors.New("tile id out of range")
+
+// Point is an integer tile coordinate.
+type Point struct {
+	X, Y int
+}
+
+// Lock describes a locked door and the keycard color that opens it.
+type Lock struct {
+	X, Y  int
+	Color string
+}
+
+// Level is a fully generated level that can be shared and reloaded exactly.
+type Level struct {
+	Seed           uint64
+	Genre          string
+	Width          int
+	Height         int
+	Tiles          [][]int
+	Rooms          []Room
+	Secrets        []Point
+	Locks          []Lock
+	DecorationSeed uint64
+}
+
+// LevelDecorationSeed returns the seed decorations are placed with for a
+// level generated from seed.
+func LevelDecorationSeed(seed uint64) uint64 {
+	return seed ^ decorationSeedSalt
+}
+
+// NewLevel captures a generated BSP tree and tile map as a Level.
+// Secrets are collected from TileSecret tiles; locks are left for the caller.
+// The decoration seed is derived from seed with LevelDecorationSeed.
+func NewLevel(seed uint64, genreID string, root *Node, tiles [][]int) *Level {
+	lvl := &Level{
+		Seed:           seed,
+		Genre:          genreID,
+		Tiles:          tiles,
+		DecorationSeed: LevelDecorationSeed(seed),
+	}
+	lvl.Height = len(tiles)
+	if lvl.Height > 0 {
+		lvl.Width = len(tiles[0])
+	}
+
+	for _, r := range GetRooms(root) {
+		lvl.Rooms = append(lvl.Rooms, *r)
+	}
+
+	for y := range tiles {
+		for x := range tiles[y] {
+			if tiles[y][x] == TileSecret {
+				lvl.Secrets = append(lvl.Secrets, Point{X: x, Y: y})
+			}
+		}
+	}
+	return lvl
+}
+
+// MarshalBinary encodes the level into the compact versioned binary format.
+func (l *Level) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := l.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a level previously produced by MarshalBinary.
+func (l *Level) UnmarshalBinary(data []byte) error {
+	decoded, err := DecodeLevel(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	*l = *decoded
+	return nil
+}
+
+// Encode writes the level to w. Tiles are stored as a deflate-compressed byte grid.
+func (l *Level) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := &levelEncoder{w: bw}
+
+	enc.raw([]byte(LevelMagic))
+	enc.u16(LevelFormatVersion)
+	enc.u64(l.Seed)
+	enc.str(l.Genre)
+	enc.uvarint(uint64(l.Width))
+	enc.uvarint(uint64(l.Height))
+	enc.u64(l.DecorationSeed)
+
+	if err := enc.tiles(l.Tiles, l.Width, l.Height); err != nil {
+		return err
+	}
+
+	enc.uvarint(uint64(len(l.Rooms)))
+	for _, r := range l.Rooms {
+		enc.uvarint(uint64(r.X))
+		enc.uvarint(uint64(r.Y))
+		enc.uvarint(uint64(r.W))
+		enc.uvarint(uint64(r.H))
+		enc.varint(int64(r.Type))
+		enc.uvarint(uint64(r.Index))
+		enc.uvarint(uint64(r.Tag))
+	}
+
+	enc.uvarint(uint64(len(l.Secrets)))
+	for _, p := range l.Secrets {
+		enc.uvarint(uint64(p.X))
+		enc.uvarint(uint64(p.Y))
+	}
+
+	enc.uvarint(uint64(len(l.Locks)))
+	for _, lk := range l.Locks {
+		enc.uvarint(uint64(lk.X))
+		enc.uvarint(uint64(lk.Y))
+		enc.str(lk.Color)
+	}
+
+	if enc.err != nil {
+		return fmt.Errorf("failed to encode level: %w", enc.err)
+	}
+	return bw.Flush()
+}
+
+// DecodeLevel reads a level written by Encode.
+func DecodeLevel(r io.Reader) (*Level, error) {
+	dec := &levelDecoder{r: bufio.NewReader(r)}
+
+	magic := dec.raw(len(LevelMagic))
+	if dec.err == nil && string(magic) != LevelMagic {
+		return nil, ErrInvalidLevelMagic
+	}
+	version := dec.u16()
+	if dec.err == nil && version > LevelFormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedLevelVersion, version)
+	}
+
+	lvl := &Level{}
+	lvl.Seed = dec.u64()
+	lvl.Genre = dec.str()
+	lvl.Width = dec.size(MaxLevelSize)
+	lvl.Height = dec.size(MaxLevelSize)
+	lvl.DecorationSeed = dec.u64()
+	if version < 2 {
+		lvl.Tiles = dec.tilesRLE(lvl.Width, lvl.Height)
+	} else {
+		lvl.Tiles = dec.tiles(lvl.Width, lvl.Height)
+	}
+
+	roomCount := dec.size(lvl.Width * lvl.Height)
+	for i := 0; i < roomCount && dec.err == nil; i++ {
+		room := Room{
+			X:     dec.size(lvl.Width),
+			Y:     dec.size(lvl.Height),
+			W:     dec.size(lvl.Width),
+			H:     dec.size(lvl.Height),
+			Type:  int(dec.varint()),
+			Index: dec.size(roomCount),
+		}
+		if version >= 3 {
+			room.Tag = RoomTag(dec.size(maxRoomTag))
+		}
+		lvl.Rooms = append(lvl.Rooms, room)
+	}
+
+	secretCount := dec.size(lvl.Width * lvl.Height)
+	for i := 0; i < secretCount && dec.err == nil; i++ {
+		lvl.Secrets = append(lvl.Secrets, Point{X: dec.size(lvl.Width), Y: dec.size(lvl.Height)})
+	}
+
+	lockCount := dec.size(lvl.Width * lvl.Height)
+	for i := 0; i < lockCount && dec.err == nil; i++ {
+		lvl.Locks = append(lvl.Locks, Lock{
+			X:     dec.size(lvl.Width),
+			Y:     dec.size(lvl.Height),
+			Color: dec.str(),
+		})
+	}
+
+	if dec.err != nil {
+		return nil, fmt.Errorf("failed to decode level: %w", dec.err)
+	}
+	return lvl, nil
+}
+
+// levelEncoder accumulates the first write error so callers can check once.
+type levelEncoder struct {
+	w   *bufio.Writer
+	err error
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (e *levelEncoder) raw(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *levelEncoder) u16(v uint16) {
+	binary.LittleEndian.PutUint16(e.buf[:2], v)
+	e.raw(e.buf[:2])
+}
+
+func (e *levelEncoder) u64(v uint64) {
+	binary.LittleEndian.PutUint64(e.buf[:8], v)
+	e.raw(e.buf[:8])
+}
+
+func (e *levelEncoder) uvarint(v uint64) {
+	n := binary.PutUvarint(e.buf[:], v)
+	e.raw(e.buf[:n])
+}
+
+func (e *levelEncoder) varint(v int64) {
+	n := binary.PutVarint(e.buf[:], v)
+	e.raw(e.buf[:n])
+}
+
+func (e *levelEncoder) str(s string) {
+	e.uvarint(uint64(len(s)))
+	e.raw([]byte(s))
+}
+
+// tiles writes the row-major tile bytes as a length-prefixed deflate stream.
+func (e *levelEncoder) tiles(tiles [][]int, width, height int) error {
+	if len(tiles) != height {
+		return fmt.Errorf("tile rows = %d, want %d", len(tiles), height)
+	}
+	raw := make([]byte, 0, width*height)
+	for y := 0; y < height; y++ {
+		if len(tiles[y]) != width {
+			return fmt.Errorf("tile row %d has %d columns, want %d", y, len(tiles[y]), width)
+		}
+		for x := 0; x < width; x++ {
+			t := tiles[y][x]
+			if t < 0 || t > 0xFF {
+				return fmt.Errorf("%w: %d at (%d,%d)", ErrTileOutOfRange, t, x, y)
+			}
+			raw = append(raw, byte(t))
+		}
+	}
+
+	var packed bytes.Buffer
+	fw, err := flate.NewWriter(&packed, flate.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(raw); err != nil {
+		return err
+	}
+	if err := fw.Close(); err != nil {
+		return err
+	}
+	e.uvarint(uint64(packed.Len()))
+	e.raw(packed.Bytes())
+	return nil
+}
+
+// levelDecoder mirrors levelEncoder and stops at the first read error.
+type levelDecoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *levelDecoder) raw(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return b
+}
+
+func (d *levelDecoder) u16() uint16 {
+	b := d.raw(2)
+	if d.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (d *levelDecoder) u64() uint64 {
+	b := d.raw(8)
+	if d.err != nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (d *levelDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	var v uint64
+	v, d.err = binary.ReadUvarint(d.r)
+	return v
+}
+
+func (d *levelDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	var v int64
+	v, d.err = binary.ReadVarint(d.r)
+	return v
+}
+
+// size reads an unsigned value and rejects anything larger than limit.
+func (d *levelDecoder) size(limit int) int {
+	v := d.uvarint()
+	if d.err == nil && v > uint64(limit) {
+		d.err = fmt.Errorf("value %d exceeds limit %d", v, limit)
+		return 0
+	}
+	return int(v)
+}
+
+func (d *levelDecoder) str() string {
+	n := d.size(1 << 16)
+	return string(d.raw(n))
+}
+
+func (d *levelDecoder) tiles(width, height int) [][]int {
+	packed := d.raw(d.size(maxPackedTiles))
+	if d.err != nil {
+		return nil
+	}
+
+	raw := make([]byte, width*height)
+	fr := flate.NewReader(bytes.NewReader(packed))
+	defer fr.Close()
+	if _, err := io.ReadFull(fr, raw); err != nil {
+		d.err = fmt.Errorf("failed to inflate tiles: %w", err)
+		return nil
+	}
+
+	tiles := make([][]int, height)
+	for y := range tiles {
+		tiles[y] = make([]int, width)
+		for x := range tiles[y] {
+			tiles[y][x] = int(raw[y*width+x])
+		}
+	}
+	return tiles
+}
+
+// tilesRLE reads the version 1 tile encoding: (runLength, tileID) pairs in
+// row-major order.
+func (d *levelDecoder) tilesRLE(width, height int) [][]int {
+	if d.err != nil {
+		return nil
+	}
+	tiles := make([][]int, height)
+	for y := range tiles {
+		tiles[y] = make([]int, width)
+	}
+
+	total := width * height
+	for pos := 0; pos < total && d.err == nil; {
+		run := d.size(total - pos)
+		id := d.raw(1)
+		if d.err != nil {
+			break
+		}
+		if run == 0 {
+			d.err = errors.New("zero-length tile run")
+			break
+		}
+		for i := 0; i < run; i++ {
+			tiles[(pos+i)/width][(pos+i)%width] = int(id[0])
+		}
+		pos += run
+	}
+	return tiles
+}
@@ -0,0 +1,230 @@
+package bsp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/procgen/genre"
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+func generateTestLevel(t *testing.T, seed uint64) *Level {
+	t.Helper()
+	g, err := NewGenerator(64, 64, rng.NewRNG(seed))
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	g.SetGenre(genre.SciFi)
+	root, tiles := g.Generate()
+	lvl := NewLevel(seed, genre.SciFi, root, tiles)
+	lvl.Locks = []Lock{{X: 3, Y: 4, Color: "red"}, {X: 10, Y: 12, Color: "blue"}}
+	return lvl
+}
+
+func TestLevelRoundTrip(t *testing.T) {
+	for _, seed := range []uint64{1, 12345, 67890} {
+		lvl := generateTestLevel(t, seed)
+
+		data, err := lvl.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary failed: %v", err)
+		}
+
+		var got Level
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary failed: %v", err)
+		}
+
+		if !reflect.DeepEqual(lvl, &got) {
+			t.Errorf("seed %d: round-trip mismatch", seed)
+		}
+	}
+}
+
+func TestDecorationSeedRoundTrip(t *testing.T) {
+	lvl := generateTestLevel(t, 4242)
+	if want := LevelDecorationSeed(4242); lvl.DecorationSeed != want || want == 0 {
+		t.Fatalf("DecorationSeed = %d, want derived %d", lvl.DecorationSeed, want)
+	}
+
+	data, err := lvl.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	var got Level
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if got.DecorationSeed != lvl.DecorationSeed {
+		t.Errorf("DecorationSeed = %d after round trip, want %d", got.DecorationSeed, lvl.DecorationSeed)
+	}
+}
+
+func TestLevelEncodingIsCompact(t *testing.T) {
+	lvl := generateTestLevel(t, 42)
+	data, err := lvl.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	if len(data) >= lvl.Width*lvl.Height {
+		t.Errorf("encoded size %d not smaller than raw tile count %d", len(data), lvl.Width*lvl.Height)
+	}
+}
+
+func TestLevelEncodingDeterministic(t *testing.T) {
+	a, err := generateTestLevel(t, 777).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	b, err := generateTestLevel(t, 777).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Error("same seed produced different encodings")
+	}
+}
+
+func TestNewLevelCollectsSecrets(t *testing.T) {
+	tiles := [][]int{
+		{TileWall, TileSecret, TileWall},
+		{TileWall, TileFloor, TileSecret},
+	}
+	lvl := NewLevel(1, genre.Fantasy, nil, tiles)
+	want := []Point{{X: 1, Y: 0}, {X: 2, Y: 1}}
+	if !reflect.DeepEqual(lvl.Secrets, want) {
+		t.Errorf("Secrets = %v, want %v", lvl.Secrets, want)
+	}
+	if lvl.Width != 3 || lvl.Height != 2 {
+		t.Errorf("size = %dx%d, want 3x2", lvl.Width, lvl.Height)
+	}
+}
+
+func TestDecodeLevelErrors(t *testing.T) {
+	valid, err := generateTestLevel(t, 5).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+
+	badMagic := append([]byte("XXXX"), valid[4:]...)
+	if _, err := DecodeLevel(bytes.NewReader(badMagic)); !errors.Is(err, ErrInvalidLevelMagic) {
+		t.Errorf("bad magic: err = %v, want %v", err, ErrInvalidLevelMagic)
+	}
+
+	future := append([]byte(nil), valid...)
+	future[4] = 0xFF
+	if _, err := DecodeLevel(bytes.NewReader(future)); !errors.Is(err, ErrUnsupportedLevelVersion) {
+		t.Errorf("future version: err = %v, want %v", err, ErrUnsupportedLevelVersion)
+	}
+
+	if _, err := DecodeLevel(bytes.NewReader(valid[:len(valid)/2])); err == nil {
+		t.Error("truncated data: expected error")
+	}
+}
+
+func TestEncodeRejectsOutOfRangeTiles(t *testing.T) {
+	lvl := &Level{Width: 1, Height: 1, Tiles: [][]int{{300}}}
+	if _, err := lvl.MarshalBinary(); !errors.Is(err, ErrTileOutOfRange) {
+		t.Errorf("err = %v, want %v", err, ErrTileOutOfRange)
+	}
+}
+
+// encodeLegacy writes lvl in an older format version: version 1 stores
+// run-length encoded tiles and both versions 1 and 2 omit room tags.
+func encodeLegacy(t *testing.T, lvl *Level, version uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	enc := &levelEncoder{w: bw}
+	enc.raw([]byte(LevelMagic))
+	enc.u16(version)
+	enc.u64(lvl.Seed)
+	enc.str(lvl.Genre)
+	enc.uvarint(uint64(lvl.Width))
+	enc.uvarint(uint64(lvl.Height))
+	enc.u64(lvl.DecorationSeed)
+	if version == 1 {
+		run, prev := 0, -1
+		for _, row := range lvl.Tiles {
+			for _, id := range row {
+				if id == prev {
+					run++
+					continue
+				}
+				if run > 0 {
+					enc.uvarint(uint64(run))
+					enc.raw([]byte{byte(prev)})
+				}
+				run, prev = 1, id
+			}
+		}
+		enc.uvarint(uint64(run))
+		enc.raw([]byte{byte(prev)})
+	} else if err := enc.tiles(lvl.Tiles, lvl.Width, lvl.Height); err != nil {
+		t.Fatalf("encode tiles: %v", err)
+	}
+	enc.uvarint(uint64(len(lvl.Rooms)))
+	for _, r := range lvl.Rooms {
+		enc.uvarint(uint64(r.X))
+		enc.uvarint(uint64(r.Y))
+		enc.uvarint(uint64(r.W))
+		enc.uvarint(uint64(r.H))
+		enc.varint(int64(r.Type))
+		enc.uvarint(uint64(r.Index))
+	}
+	enc.uvarint(uint64(len(lvl.Secrets)))
+	for _, p := range lvl.Secrets {
+		enc.uvarint(uint64(p.X))
+		enc.uvarint(uint64(p.Y))
+	}
+	enc.uvarint(uint64(len(lvl.Locks)))
+	for _, lk := range lvl.Locks {
+		enc.uvarint(uint64(lk.X))
+		enc.uvarint(uint64(lk.Y))
+		enc.str(lk.Color)
+	}
+	if enc.err != nil {
+		t.Fatalf("encode: %v", enc.err)
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeLegacyVersions(t *testing.T) {
+	for _, version := range []uint16{1, 2} {
+		lvl := generateTestLevel(t, 31337)
+		for i := range lvl.Rooms {
+			lvl.Rooms[i].Tag = RoomTagNone // Not stored before version 3
+		}
+
+		got, err := DecodeLevel(bytes.NewReader(encodeLegacy(t, lvl, version)))
+		if err != nil {
+			t.Fatalf("version %d: DecodeLevel failed: %v", version, err)
+		}
+		if !reflect.DeepEqual(lvl, got) {
+			t.Errorf("version %d: decoded level differs from original", version)
+		}
+	}
+}
+
+func TestDecodeKeepsUnknownRoomTags(t *testing.T) {
+	lvl := generateTestLevel(t, 8)
+	lvl.Rooms[0].Tag = RoomTagExit + 10
+
+	data, err := lvl.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary failed: %v", err)
+	}
+	got, err := DecodeLevel(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("DecodeLevel failed: %v", err)
+	}
+	if got.Rooms[0].Tag != lvl.Rooms[0].Tag {
+		t.Errorf("Tag = %v, want %v", got.Rooms[0].Tag, lvl.Rooms[0].Tag)
+	}
+}
@@ -0,0 +1,123 @@
+package bsp
+
+// RoomTag marks a room with a gameplay role chosen by the generator.
+type RoomTag int
+
+const (
+	// RoomTagNone is an ordinary room with no special role.
+	RoomTagNone RoomTag = iota
+	// RoomTagStart is the player spawn room.
+	RoomTagStart
+	// RoomTagBossArena is the largest remaining room, reserved for the boss.
+	RoomTagBossArena
+	// RoomTagShop is a room near the start for merchants.
+	RoomTagShop
+	// RoomTagShrine is a small side room for shrines and rest points.
+	RoomTagShrine
+	// RoomTagExit is the room farthest from the start.
+	RoomTagExit
+)
+
+// String returns the tag name.
+func (t RoomTag) String() string {
+	switch t {
+	case RoomTagStart:
+		return "start"
+	case RoomTagBossArena:
+		return "boss_arena"
+	case RoomTagShop:
+		return "shop"
+	case RoomTagShrine:
+		return "shrine"
+	case RoomTagExit:
+		return "exit"
+	default:
+		return "none"
+	}
+}
+
+// Center returns the tile at the center of the room.
+func (r *Room) Center() (int, int) {
+	return r.X + r.W/2, r.Y + r.H/2
+}
+
+// FindRoomByTag returns the first room in the tree with the given tag, or nil.
+func FindRoomByTag(n *Node, tag RoomTag) *Room {
+	for _, r := range GetRooms(n) {
+		if r.Tag == tag {
+			return r
+		}
+	}
+	return nil
+}
+
+// tagRooms assigns setpiece roles to rooms. Tagging is a pure function of
+// room geometry so it never consumes RNG state. Roles are assigned in
+// priority order and skipped when there are too few rooms.
+func tagRooms(rooms []*Room) {
+	for _, r := range rooms {
+		r.Tag = RoomTagNone
+	}
+	if len(rooms) == 0 {
+		return
+	}
+
+	start := rooms[0]
+	start.Tag = RoomTagStart
+	sx, sy := start.Center()
+
+	dist := func(r *Room) int {
+		x, y := r.Center()
+		return (x-sx)*(x-sx) + (y-sy)*(y-sy)
+	}
+
+	pick := func(tag RoomTag, better func(a, b *Room) bool) {
+		var best *Room
+		for _, r := range rooms {
+			if r.Tag != RoomTagNone {
+				continue
+			}
+			if best == nil || better(r, best) {
+				best = r
+			}
+		}
+		if best != nil {
+			best.Tag = tag
+		}
+	}
+
+	pick(RoomTagExit, func(a, b *Room) bool { return dist(a) > dist(b) })
+	pick(RoomTagBossArena, func(a, b *Room) bool {
+		if a.W*a.H != b.W*b.H {
+			return a.W*a.H > b.W*b.H
+		}
+		return dist(a) > dist(b)
+	})
+	pick(RoomTagShop, func(a, b *Room) bool { return dist(a) < dist(b) })
+	pick(RoomTagShrine, func(a, b *Room) bool {
+		if a.W*a.H != b.W*b.H {
+			return a.W*a.H < b.W*b.H
+		}
+		return dist(a) > dist(b)
+	})
+}
+
+// SkyMask returns a [y][x] mask of the tiles open to the sky: the floors of
+// the rooms for which open reports true. Corridors and walls stay covered.
+func SkyMask(n *Node, width, height int, open func(*Room) bool) [][]bool {
+	mask := make([][]bool, height)
+	for y := range mask {
+		mask[y] = make([]bool, width)
+	}
+	for _, r := range GetRooms(n) {
+		if !open(r) {
+			continue
+		}
+		for y := max(r.Y, 0); y < min(r.Y+r.H, height); y++ {
+			for x := max(r.X, 0); x < min(r.X+r.W, width); x++ {
+				mask[y][x] = true
+			}
+		}
+	}
+	return mask
+}
@@ -0,0 +1,95 @@
+package bsp
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+func TestTagRooms(t *testing.T) {
+	rooms := []*Room{
+		{X: 0, Y: 0, W: 4, H: 4},     // start
+		{X: 6, Y: 0, W: 5, H: 5},     // nearest: shop
+		{X: 20, Y: 20, W: 12, H: 12}, // largest: boss arena
+		{X: 50, Y: 50, W: 6, H: 6},   // farthest: exit
+		{X: 30, Y: 0, W: 3, H: 3},    // smallest: shrine
+		{X: 10, Y: 30, W: 7, H: 7},   // untagged
+	}
+	tagRooms(rooms)
+
+	want := []RoomTag{RoomTagStart, RoomTagShop, RoomTagBossArena, RoomTagExit, RoomTagShrine, RoomTagNone}
+	for i, r := range rooms {
+		if r.Tag != want[i] {
+			t.Errorf("room %d tag = %v, want %v", i, r.Tag, want[i])
+		}
+	}
+}
+
+func TestTagRoomsFewRooms(t *testing.T) {
+	rooms := []*Room{{X: 0, Y: 0, W: 4, H: 4}, {X: 10, Y: 10, W: 4, H: 4}}
+	tagRooms(rooms)
+	if rooms[0].Tag != RoomTagStart || rooms[1].Tag != RoomTagExit {
+		t.Errorf("tags = %v, %v; want start, exit", rooms[0].Tag, rooms[1].Tag)
+	}
+	tagRooms(nil)
+}
+
+func TestGenerateTagsRooms(t *testing.T) {
+	g, err := NewGenerator(64, 64, rng.NewRNG(31337))
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	root, _ := g.Generate()
+
+	for _, tag := range []RoomTag{RoomTagStart, RoomTagBossArena, RoomTagShop, RoomTagShrine, RoomTagExit} {
+		if FindRoomByTag(root, tag) == nil {
+			t.Errorf("no room tagged %v", tag)
+		}
+	}
+	if start := FindRoomByTag(root, RoomTagStart); start != GetRooms(root)[0] {
+		t.Error("start tag should be on the first room")
+	}
+}
+
+func TestRoomTagString(t *testing.T) {
+	tests := map[RoomTag]string{
+		RoomTagNone:      "none",
+		RoomTagStart:     "start",
+		RoomTagBossArena: "boss_arena",
+		RoomTagShop:      "shop",
+		RoomTagShrine:    "shrine",
+		RoomTagExit:      "exit",
+	}
+	for tag, want := range tests {
+		if got := tag.String(); got != want {
+			t.Errorf("RoomTag(%d).String() = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestSkyMask(t *testing.T) {
+	root := &Node{
+		Left:  &Node{Room: &Room{X: 1, Y: 1, W: 2, H: 2}},
+		Right: &Node{Room: &Room{X: 4, Y: 0, W: 3, H: 3, Tag: RoomTagBossArena}},
+	}
+	mask := SkyMask(root, 6, 4, func(r *Room) bool { return r.Tag == RoomTagNone })
+
+	tests := []struct {
+		x, y int
+		want bool
+	}{
+		{1, 1, true},
+		{2, 2, true},
+		{0, 0, false},
+		{3, 1, false}, // Between rooms
+		{4, 0, false}, // Boss arena stays covered
+	}
+	for _, tt := range tests {
+		if got := mask[tt.y][tt.x]; got != tt.want {
+			t.Errorf("mask[%d][%d] = %v, want %v", tt.y, tt.x, got, tt.want)
+		}
+	}
+	if len(mask) != 4 || len(mask[0]) != 6 {
+		t.Errorf("mask size = %dx%d, want 6x4", len(mask[0]), len(mask))
+	}
+}
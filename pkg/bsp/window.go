@@ -0,0 +1,55 @@
+package bsp
+
+import "github.com/opd-ai/violence/pkg/procgen/genre"
+
+// DefaultWindowRate is the default chance that an eligible thin wall is
+// replaced by a see-through wall.
+const DefaultWindowRate = 0.04
+
+// genreWindowTiles lists the see-through wall tiles each genre may place.
+var genreWindowTiles = map[string][]int{
+	genre.Fantasy:   {TileBars},
+	genre.SciFi:     {TileWindow, TileForceField},
+	genre.Horror:    {TileBars},
+	genre.Cyberpunk: {TileWindow, TileBars},
+	genre.PostApoc:  {TileBars, TileWindow},
+}
+
+// placeWindows replaces some one-tile-thick walls that separate two floor
+// areas with see-through walls, so adjacent rooms and corridors can be seen
+// into without connecting them. Walkability is unchanged.
+func (g *Generator) placeWindows(tiles [][]int) {
+	options := genreWindowTiles[g.genre]
+	if g.WindowRate <= 0 || len(options) == 0 || g.floorTile == TileFloor {
+		return
+	}
+
+	for y := 1; y < g.Height-1; y++ {
+		for x := 1; x < g.Width-1; x++ {
+			if !g.isThinWall(tiles, x, y) {
+				continue
+			}
+			if g.rng.Float64() >= g.WindowRate {
+				continue
+			}
+			tiles[y][x] = options[g.rng.Intn(len(options))]
+		}
+	}
+}
+
+// isThinWall reports whether (x,y) is a base wall with floor on both sides
+// along one axis and solid wall on both sides along the other, i.e. a
+// straight wall segment one tile thick.
+func (g *Generator) isThinWall(tiles [][]int, x, y int) bool {
+	if tiles[y][x] != g.wallTile {
+		return false
+	}
+	floor := func(t int) bool { return t == g.floorTile }
+	wall := func(t int) bool { return t == g.wallTile }
+
+	horizontal := floor(tiles[y-1][x]) && floor(tiles[y+1][x]) &&
+		wall(tiles[y][x-1]) && wall(tiles[y][x+1])
+	vertical := floor(tiles[y][x-1]) && floor(tiles[y][x+1]) &&
+		wall(tiles[y-1][x]) && wall(tiles[y+1][x])
+	return horizontal || vertical
+}
@@ -0,0 +1,91 @@
+package bsp
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/procgen/genre"
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+func generateWithWindows(t *testing.T, genreID string, seed uint64, rate float64) [][]int {
+	t.Helper()
+	g, err := NewGenerator(64, 64, rng.NewRNG(seed))
+	if err != nil {
+		t.Fatalf("NewGenerator failed: %v", err)
+	}
+	g.SetGenre(genreID)
+	g.WindowRate = rate
+	_, tiles := g.Generate()
+	return tiles
+}
+
+func TestPlaceWindowsUsesGenreTiles(t *testing.T) {
+	for _, genreID := range []string{genre.Fantasy, genre.SciFi, genre.Horror, genre.Cyberpunk, genre.PostApoc} {
+		t.Run(genreID, func(t *testing.T) {
+			tiles := generateWithWindows(t, genreID, 77, 1.0)
+
+			allowed := map[int]bool{}
+			for _, id := range genreWindowTiles[genreID] {
+				allowed[id] = true
+			}
+
+			count := 0
+			for y := range tiles {
+				for x := range tiles[y] {
+					id := tiles[y][x]
+					if id < TileBars || id > TileForceField {
+						continue
+					}
+					count++
+					if !allowed[id] {
+						t.Errorf("tile %d at (%d,%d) not allowed for %s", id, x, y, genreID)
+					}
+					// Each window sits between two walkable tiles.
+					ns := isFloorLike(tiles[y-1][x]) && isFloorLike(tiles[y+1][x])
+					ew := isFloorLike(tiles[y][x-1]) && isFloorLike(tiles[y][x+1])
+					if !ns && !ew {
+						t.Errorf("window at (%d,%d) does not separate two floor tiles", x, y)
+					}
+				}
+			}
+			if count == 0 {
+				t.Errorf("no see-through walls placed for %s at rate 1.0", genreID)
+			}
+		})
+	}
+}
+
+func TestPlaceWindowsDisabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		genreID string
+		rate    float64
+	}{
+		{"zero rate", genre.SciFi, 0},
+		{"generic genre", "", 1.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiles := generateWithWindows(t, tt.genreID, 77, tt.rate)
+			for y := range tiles {
+				for x := range tiles[y] {
+					if tiles[y][x] >= TileBars && tiles[y][x] <= TileForceField {
+						t.Fatalf("unexpected see-through wall at (%d,%d)", x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceWindowsDeterministic(t *testing.T) {
+	a := generateWithWindows(t, genre.Cyberpunk, 4242, DefaultWindowRate)
+	b := generateWithWindows(t, genre.Cyberpunk, 4242, DefaultWindowRate)
+	for y := range a {
+		for x := range a[y] {
+			if a[y][x] != b[y][x] {
+				t.Fatalf("tile (%d,%d) differs: %d vs %d", x, y, a[y][x], b[y][x])
+			}
+		}
+	}
+}
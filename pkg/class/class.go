@@ -14,10 +14,23 @@ type Class struct {
 	Name   string
 	Health float64
 	Speed  float64
+	Carry  float64 // Weight of items carried before the player is burdened
 }
 
-// GetClass returns the class definition for the given ID.
+// classes are the base stats of the playable classes.
+var classes = map[string]Class{
+	Grunt:  {ID: Grunt, Name: "Grunt", Health: 100, Speed: 1.0, Carry: 40},
+	Medic:  {ID: Medic, Name: "Medic", Health: 80, Speed: 1.2, Carry: 30},
+	Demo:   {ID: Demo, Name: "Demolition", Health: 120, Speed: 0.9, Carry: 50},
+	Mystic: {ID: Mystic, Name: "Mystic", Health: 70, Speed: 1.1, Carry: 25},
+}
+
+// GetClass returns the class definition for the given ID. Unknown IDs get
+// a class with no stats set.
 func GetClass(id string) Class {
+	if c, ok := classes[id]; ok {
+		return c
+	}
 	return Class{ID: id}
 }
 
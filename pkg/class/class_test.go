@@ -210,3 +210,17 @@ func TestClassNameAssignment(t *testing.T) {
 		})
 	}
 }
+
+func TestClassCarry(t *testing.T) {
+	for _, id := range []string{Grunt, Medic, Demo, Mystic} {
+		if c := GetClass(id); c.Carry <= 0 || c.Health <= 0 || c.Speed <= 0 {
+			t.Errorf("GetClass(%q) = %+v, want positive stats", id, c)
+		}
+	}
+	if GetClass(Demo).Carry <= GetClass(Mystic).Carry {
+		t.Error("Demolition should carry more than the Mystic")
+	}
+	if c := GetClass("unknown"); c.Carry != 0 {
+		t.Errorf("unknown class carries %v, want 0", c.Carry)
+	}
+}
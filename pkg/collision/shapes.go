@@ -2,6 +2,7 @@
 package collision
 
 import (
+	"math"
 	"sync"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -66,6 +67,38 @@ func (c *AttackShapeCache) CreateColliderFromShape(name string, x, y, rotation f
 	return NewPolygonCollider(x, y, rotatedVerts, layer, mask)
 }
 
+// Reach returns how far the shape extends from its origin.
+func (s *AttackShape) Reach() float64 {
+	reach := 0.0
+	for _, v := range s.Vertices {
+		reach = math.Max(reach, math.Hypot(v.X, v.Y))
+	}
+	return reach
+}
+
+// Place returns a polygon collider of the shape swung from (x, y) toward
+// (dirX, dirY), scaled so that it extends reach from its origin. Shapes are
+// generated facing along +X.
+func (s *AttackShape) Place(x, y, dirX, dirY, reach float64, layer, mask Layer) *Collider {
+	scale := 1.0
+	if r := s.Reach(); r > 0 {
+		scale = reach / r
+	}
+	cos, sin := 1.0, 0.0
+	if l := math.Hypot(dirX, dirY); l > 0 {
+		cos, sin = dirX/l, dirY/l
+	}
+
+	verts := make([]Point, len(s.Vertices))
+	for i, v := range s.Vertices {
+		verts[i] = Point{
+			X: (v.X*cos - v.Y*sin) * scale,
+			Y: (v.X*sin + v.Y*cos) * scale,
+		}
+	}
+	return NewPolygonCollider(x, y, verts, layer, mask)
+}
+
 // WeaponShapeGenerator creates standard weapon attack shapes.
 type WeaponShapeGenerator struct {
 	cache *AttackShapeCache
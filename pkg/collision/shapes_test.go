@@ -322,3 +322,28 @@ func BenchmarkAttackShapeCache_GetShape(b *testing.B) {
 		cache.GetShape("sword_slash_h")
 	}
 }
+
+func TestAttackShape_Place(t *testing.T) {
+	shape := &AttackShape{
+		Name:     "thrust",
+		Vertices: GenerateConeShape(0, 0, 20, 0, 0.2),
+	}
+	if r := shape.Reach(); r != 20 {
+		t.Errorf("Reach() = %v, want 20", r)
+	}
+
+	// Facing +Y and scaled to 2 units, the thrust reaches a target just
+	// short of 2 units north but misses one to the east.
+	c := shape.Place(5, 5, 0, 1, 2, LayerPlayer, LayerEnemy)
+	ahead := NewCircleCollider(5, 6.8, 0.1, LayerEnemy, LayerPlayer)
+	aside := NewCircleCollider(6.8, 5, 0.1, LayerEnemy, LayerPlayer)
+	if !TestCollision(c, ahead) {
+		t.Error("placed shape missed the target ahead")
+	}
+	if TestCollision(c, aside) {
+		t.Error("placed shape hit a target to the side")
+	}
+	if len(shape.Vertices) != 3 || shape.Vertices[1].X < 19 {
+		t.Error("Place modified the cached vertices")
+	}
+}
@@ -0,0 +1,324 @@
+// Package combat - Boss controller scripting phases, telegraphed attacks and arena mechanics
+package combat
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/opd-ai/violence/pkg/engine"
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// BossArchetype is a genre's kind of boss: how it moves and which arena
+// mechanics each of its phases brings.
+type BossArchetype struct {
+	Name  string
+	Speed float64 // Base movement speed in tiles per second
+	Reach float64 // Distance the boss closes to before holding position
+	// Adds and Hazards are the minions summoned and the arena hazards
+	// activated on entering each phase, indexed by phase.
+	Adds    []int
+	Hazards []int
+}
+
+// bossArchetypes are keyed by genre and match the phase counts of
+// CreateBossPhases.
+var bossArchetypes = map[string]BossArchetype{
+	"fantasy":   {Name: "warlord", Speed: 1.6, Reach: 1.5, Adds: []int{0, 2, 3}, Hazards: []int{0, 0, 2}},
+	"scifi":     {Name: "war_mech", Speed: 1.2, Reach: 5, Adds: []int{0, 0, 2}, Hazards: []int{0, 2, 3}},
+	"horror":    {Name: "abomination", Speed: 1.0, Reach: 1.5, Adds: []int{0, 1, 2, 3}, Hazards: []int{0, 1, 1, 2}},
+	"cyberpunk": {Name: "enforcer", Speed: 1.4, Reach: 3, Adds: []int{0, 1, 2}, Hazards: []int{0, 1, 2}},
+}
+
+// defaultArchetype is the boss of genres without their own archetype.
+var defaultArchetype = BossArchetype{Name: "champion", Speed: 1.4, Reach: 1.5, Adds: []int{0, 1, 2}, Hazards: []int{0, 1, 1}}
+
+// BossArchetypeFor returns the boss archetype of a genre.
+func BossArchetypeFor(genreID string) BossArchetype {
+	if a, ok := bossArchetypes[genreID]; ok {
+		return a
+	}
+	return defaultArchetype
+}
+
+// bossAbilities are the telegraphed attacks behind phase ability names.
+// Unlike DefaultPatterns, ranges are in tiles.
+var bossAbilities = map[string]AttackPattern{
+	"basic_attack":   {Name: "basic_attack", Shape: ShapeCone, Range: 2, Angle: math.Pi / 2, WindupTime: 0.5, ActiveTime: 0.15, CooldownTime: 0.8, Damage: 12, DamageType: DamagePhysical, KnockbackMul: 1},
+	"power_attack":   {Name: "power_attack", Shape: ShapeCone, Range: 2.5, Angle: math.Pi * 2 / 3, WindupTime: 0.9, ActiveTime: 0.2, CooldownTime: 1.2, Damage: 25, DamageType: DamagePhysical, KnockbackMul: 2},
+	"slow_strike":    {Name: "slow_strike", Shape: ShapeCone, Range: 2.5, Angle: math.Pi / 2, WindupTime: 1.2, ActiveTime: 0.25, CooldownTime: 1.0, Damage: 30, DamageType: DamagePhysical, KnockbackMul: 2.5},
+	"frenzy":         {Name: "frenzy", Shape: ShapeCone, Range: 2, Angle: math.Pi / 2, WindupTime: 0.3, ActiveTime: 0.1, CooldownTime: 0.4, Damage: 8, DamageType: DamagePhysical, KnockbackMul: 0.5},
+	"charge":         {Name: "charge", Shape: ShapeLine, Range: 6, Width: 1.2, WindupTime: 0.8, ActiveTime: 0.3, CooldownTime: 1.2, Damage: 20, DamageType: DamagePhysical, KnockbackMul: 3},
+	"whirlwind":      {Name: "whirlwind", Shape: ShapeCircle, Range: 2.5, WindupTime: 0.7, ActiveTime: 0.5, CooldownTime: 1.0, Damage: 18, DamageType: DamagePhysical, KnockbackMul: 1.5},
+	"ground_slam":    {Name: "ground_slam", Shape: ShapeRing, Range: 5, Width: 2, WindupTime: 1.0, ActiveTime: 0.2, CooldownTime: 1.5, Damage: 28, DamageType: DamagePhysical, KnockbackMul: 2},
+	"laser_burst":    {Name: "laser_burst", Shape: ShapeLine, Range: 10, Width: 0.6, WindupTime: 0.7, ActiveTime: 0.2, CooldownTime: 0.9, Damage: 16, DamageType: DamageEnergy, KnockbackMul: 0.5},
+	"plasma_missile": {Name: "plasma_missile", Shape: ShapeLine, Range: 12, Width: 1.5, WindupTime: 0.9, ActiveTime: 0.2, CooldownTime: 1.2, Damage: 24, DamageType: DamagePlasma, KnockbackMul: 1.5},
+	"orbital_strike": {Name: "orbital_strike", Shape: ShapeRing, Range: 6, Width: 3, WindupTime: 1.5, ActiveTime: 0.3, CooldownTime: 2.0, Damage: 35, DamageType: DamageEnergy, KnockbackMul: 2},
+	"smart_gun":      {Name: "smart_gun", Shape: ShapeLine, Range: 10, Width: 0.8, WindupTime: 0.4, ActiveTime: 0.15, CooldownTime: 0.6, Damage: 10, DamageType: DamagePhysical, KnockbackMul: 0.3},
+	"emp_burst":      {Name: "emp_burst", Shape: ShapeCircle, Range: 4, WindupTime: 0.9, ActiveTime: 0.2, CooldownTime: 1.5, Damage: 18, DamageType: DamageEnergy, KnockbackMul: 1},
+	"cyber_overload": {Name: "cyber_overload", Shape: ShapeRing, Range: 5, Width: 2.5, WindupTime: 1.2, ActiveTime: 0.3, CooldownTime: 1.8, Damage: 30, DamageType: DamageEnergy, KnockbackMul: 1.5},
+	"life_drain":     {Name: "life_drain", Shape: ShapeCone, Range: 4, Angle: math.Pi / 3, WindupTime: 0.8, ActiveTime: 0.4, CooldownTime: 1.2, Damage: 15, DamageType: DamageEnergy},
+	"death_curse":    {Name: "death_curse", Shape: ShapeRing, Range: 7, Width: 3, WindupTime: 1.5, ActiveTime: 0.3, CooldownTime: 2.0, Damage: 40, DamageType: DamageEnergy, KnockbackMul: 1},
+	"ultimate":       {Name: "ultimate", Shape: ShapeCircle, Range: 5, WindupTime: 1.8, ActiveTime: 0.4, CooldownTime: 2.5, Damage: 45, DamageType: DamageExplosive, KnockbackMul: 3},
+}
+
+// bossAddAbilities are the abilities that call in minions, and how many.
+var bossAddAbilities = map[string]int{
+	"spawn_minions": 2,
+	"deploy_turret": 1,
+}
+
+// Boss tuning.
+const (
+	// bossAbilityCooldown is the recovery, in seconds at an attack rate of
+	// 1, after an ability that isn't an attack.
+	bossAbilityCooldown = 1.5
+	// bossShieldTime is how long shield_up lasts, in seconds.
+	bossShieldTime = 3.0
+	// bossShieldFactor scales damage taken while shielded.
+	bossShieldFactor = 0.25
+	// bossTeleportDist is how far from the player teleport lands.
+	bossTeleportDist = 3.0
+	// bossTransitionCooldown is the minimum seconds between phase changes.
+	bossTransitionCooldown = 2.0
+)
+
+// BossEventKind is the kind of a boss event.
+type BossEventKind int
+
+const (
+	// BossEventPhase reports that the boss entered Phase.
+	BossEventPhase BossEventKind = iota
+	// BossEventTelegraph reports that the boss started winding up Pattern.
+	BossEventTelegraph
+	// BossEventHit reports that Pattern hit the player for Damage.
+	BossEventHit
+	// BossEventAdds asks the game to summon Count minions.
+	BossEventAdds
+	// BossEventHazards asks the game to activate Count arena hazards.
+	BossEventHazards
+	// BossEventAbility reports that the boss used the utility Ability.
+	BossEventAbility
+)
+
+// BossEvent is something the boss did or wants the game to do.
+type BossEvent struct {
+	Kind    BossEventKind
+	Phase   int
+	Pattern AttackPattern
+	Damage  float64
+	Count   int
+	Ability string
+}
+
+// BossController scripts a boss fight. The boss works through its current
+// phase's abilities in order, telegraphing each attack before it lands, and
+// moves on to the next phase as its health crosses the phase thresholds.
+// Entering a phase summons adds and activates arena hazards as its
+// archetype dictates. Given the same seed and updates, a boss fights the
+// same way.
+type BossController struct {
+	Archetype         BossArchetype
+	X, Y              float64
+	DirX, DirY        float64 // Facing, toward the player
+	Health, MaxHealth float64
+	Phases            *BossPhaseComponent
+	Telegraph         TelegraphComponent
+
+	rng     *rng.RNG
+	time    float64
+	ability int     // Index of the next ability in the phase's AbilitySet
+	shield  float64 // Seconds of shield left
+}
+
+// NewBossController creates a genre's boss at (x, y).
+func NewBossController(genreID string, x, y, maxHealth float64, seed uint64) *BossController {
+	return &BossController{
+		Archetype: BossArchetypeFor(genreID),
+		X:         x,
+		Y:         y,
+		DirX:      1,
+		Health:    maxHealth,
+		MaxHealth: maxHealth,
+		Phases: &BossPhaseComponent{
+			Phases:             CreateBossPhases(genreID, rand.New(rand.NewSource(int64(seed)))),
+			TransitionCooldown: bossTransitionCooldown,
+			InitialMaxHealth:   maxHealth,
+		},
+		rng: rng.NewRNG(seed),
+	}
+}
+
+// Dead reports whether the boss has been defeated.
+func (b *BossController) Dead() bool {
+	return b.Health <= 0
+}
+
+// Shielded reports whether the boss's shield is up.
+func (b *BossController) Shielded() bool {
+	return b.shield > 0
+}
+
+// TakeDamage reduces the boss's health, by less while its shield is up, and
+// returns the damage dealt.
+func (b *BossController) TakeDamage(amount float64) float64 {
+	if b.shield > 0 {
+		amount *= bossShieldFactor
+	}
+	amount = math.Min(amount, b.Health)
+	b.Health -= amount
+	return amount
+}
+
+// Update advances the boss by dt seconds against a player at (px, py) and
+// returns what happened. walkable reports whether the boss may stand at a
+// point, which keeps it in its arena; nil allows anywhere.
+func (b *BossController) Update(dt, px, py float64, walkable func(x, y float64) bool) []BossEvent {
+	if b.Dead() {
+		return nil
+	}
+	b.time += dt
+	b.shield = math.Max(b.shield-dt, 0)
+
+	if b.Phases.IsTransitioning {
+		if b.Phases.UpdateTransition(dt) {
+			return b.enterPhase(nil)
+		}
+		return nil
+	}
+	if b.Phases.ShouldTransition(b.Health, b.MaxHealth, b.time) {
+		// A phase change interrupts whatever the boss was doing.
+		b.Phases.StartTransition(b.time)
+		b.Telegraph.Phase = PhaseInactive
+		return nil
+	}
+	phase := b.Phases.GetCurrentPhaseData()
+	if phase == nil {
+		return nil
+	}
+
+	var events []BossEvent
+	t := &b.Telegraph
+	switch t.Phase {
+	case PhaseInactive:
+		b.face(px, py)
+		b.move(dt*phase.SpeedMultiplier, px, py, walkable)
+		events = b.useAbility(events, phase, px, py, walkable)
+	case PhaseWindup:
+		t.PhaseTimer -= dt
+		if t.PhaseTimer <= 0 {
+			t.Phase, t.PhaseTimer, t.HasHit = PhaseActive, t.Pattern.ActiveTime, false
+		}
+	case PhaseActive:
+		if !t.HasHit && InAttackArea(&engine.Position{X: b.X, Y: b.Y}, &engine.Position{X: px, Y: py}, t) {
+			t.HasHit = true
+			damage := t.Pattern.Damage * phase.DamageMultiplier
+			if t.Pattern.Name == "life_drain" {
+				b.Health = math.Min(b.Health+damage, b.MaxHealth)
+			}
+			events = append(events, BossEvent{Kind: BossEventHit, Phase: b.Phases.CurrentPhase, Pattern: t.Pattern, Damage: damage})
+		}
+		t.PhaseTimer -= dt
+		if t.PhaseTimer <= 0 {
+			t.Phase, t.PhaseTimer = PhaseCooldown, t.Pattern.CooldownTime/phase.AttackRate
+		}
+	case PhaseCooldown:
+		t.PhaseTimer -= dt
+		if t.PhaseTimer <= 0 {
+			t.Phase, t.PhaseTimer = PhaseInactive, 0
+		}
+	}
+	return events
+}
+
+// enterPhase resets the ability rotation for the new phase and calls in
+// its adds and hazards.
+func (b *BossController) enterPhase(events []BossEvent) []BossEvent {
+	p := b.Phases.CurrentPhase
+	b.ability = 0
+	events = append(events, BossEvent{Kind: BossEventPhase, Phase: p})
+	if p < len(b.Archetype.Adds) && b.Archetype.Adds[p] > 0 {
+		events = append(events, BossEvent{Kind: BossEventAdds, Phase: p, Count: b.Archetype.Adds[p]})
+	}
+	if p < len(b.Archetype.Hazards) && b.Archetype.Hazards[p] > 0 {
+		events = append(events, BossEvent{Kind: BossEventHazards, Phase: p, Count: b.Archetype.Hazards[p]})
+	}
+	return events
+}
+
+// useAbility starts the phase's next ability. Attacks wait until the
+// player is within their range and then wind up toward where the player
+// stands; other abilities take effect at once.
+func (b *BossController) useAbility(events []BossEvent, phase *PhaseTransition, px, py float64, walkable func(x, y float64) bool) []BossEvent {
+	if len(phase.AbilitySet) == 0 {
+		return events
+	}
+	name := phase.AbilitySet[b.ability%len(phase.AbilitySet)]
+	t := &b.Telegraph
+	if pattern, ok := bossAbilities[name]; ok {
+		if math.Hypot(px-b.X, py-b.Y) > pattern.Range {
+			return events
+		}
+		b.ability++
+		t.Pattern = pattern
+		t.Phase, t.PhaseTimer = PhaseWindup, pattern.WindupTime
+		t.TargetX, t.TargetY = px, py
+		t.DirectionX, t.DirectionY = b.DirX, b.DirY
+		return append(events, BossEvent{Kind: BossEventTelegraph, Phase: phase.PhaseID, Pattern: pattern})
+	}
+
+	b.ability++
+	t.Pattern = AttackPattern{Name: name}
+	t.Phase, t.PhaseTimer = PhaseCooldown, bossAbilityCooldown/phase.AttackRate
+	if n, ok := bossAddAbilities[name]; ok {
+		return append(events, BossEvent{Kind: BossEventAdds, Phase: phase.PhaseID, Count: n})
+	}
+	switch name {
+	case "shield_up":
+		b.shield = bossShieldTime
+	case "teleport":
+		b.teleport(px, py, walkable)
+	}
+	return append(events, BossEvent{Kind: BossEventAbility, Phase: phase.PhaseID, Ability: name})
+}
+
+// face turns the boss toward (px, py).
+func (b *BossController) face(px, py float64) {
+	dx, dy := px-b.X, py-b.Y
+	if d := math.Hypot(dx, dy); d > 0 {
+		b.DirX, b.DirY = dx/d, dy/d
+	}
+}
+
+// move closes on the player at the archetype's speed, scaled by speedDt,
+// until within its reach, sliding along whatever walkable rejects.
+func (b *BossController) move(speedDt, px, py float64, walkable func(x, y float64) bool) {
+	dx, dy := px-b.X, py-b.Y
+	dist := math.Hypot(dx, dy)
+	if dist <= b.Archetype.Reach {
+		return
+	}
+	step := math.Min(b.Archetype.Speed*speedDt, dist-b.Archetype.Reach)
+	nx, ny := b.X+dx/dist*step, b.Y+dy/dist*step
+	if walkable == nil || walkable(nx, b.Y) {
+		b.X = nx
+	}
+	if walkable == nil || walkable(b.X, ny) {
+		b.Y = ny
+	}
+}
+
+// teleport blinks the boss to a walkable spot bossTeleportDist from the
+// player, trying evenly spaced bearings from a random start.
+func (b *BossController) teleport(px, py float64, walkable func(x, y float64) bool) {
+	start := b.rng.Float64() * 2 * math.Pi
+	for i := 0; i < 8; i++ {
+		a := start + float64(i)*math.Pi/4
+		x, y := px+math.Cos(a)*bossTeleportDist, py+math.Sin(a)*bossTeleportDist
+		if walkable == nil || walkable(x, y) {
+			b.X, b.Y = x, y
+			b.face(px, py)
+			return
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package combat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+const bossDT = 1.0 / 60.0
+
+// runBoss updates a boss against a stationary player and collects its events.
+func runBoss(b *BossController, seconds, px, py float64) []BossEvent {
+	var events []BossEvent
+	for i := 0; i < int(seconds/bossDT); i++ {
+		events = append(events, b.Update(bossDT, px, py, nil)...)
+	}
+	return events
+}
+
+// kinds returns the events of a kind.
+func kinds(events []BossEvent, kind BossEventKind) []BossEvent {
+	var out []BossEvent
+	for _, e := range events {
+		if e.Kind == kind {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestBossArchetypesCoverPhases(t *testing.T) {
+	utilities := map[string]bool{"shield_up": true, "teleport": true, "intimidate": true, "hack_attempt": true}
+	for _, genre := range []string{"fantasy", "scifi", "horror", "cyberpunk", "unknown"} {
+		a := BossArchetypeFor(genre)
+		phases := CreateBossPhases(genre, rand.New(rand.NewSource(1)))
+		if len(a.Adds) != len(phases) || len(a.Hazards) != len(phases) {
+			t.Errorf("%s: archetype %s scripts %d/%d phases, want %d", genre, a.Name, len(a.Adds), len(a.Hazards), len(phases))
+		}
+		for _, p := range phases {
+			for _, name := range p.AbilitySet {
+				_, attack := bossAbilities[name]
+				_, adds := bossAddAbilities[name]
+				if !attack && !adds && !utilities[name] {
+					t.Errorf("%s: ability %q has no behavior", genre, name)
+				}
+			}
+		}
+	}
+}
+
+func TestBossTelegraphedAttack(t *testing.T) {
+	tests := []struct {
+		name    string
+		dodgeY  float64 // Where the player moves once the windup starts
+		wantHit bool
+	}{
+		{"stands still", 5, true},
+		{"dodges", 9, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBossController("fantasy", 5, 5, 1000, 1)
+			var telegraph []BossEvent
+			for i := 0; i < 120 && len(telegraph) == 0; i++ {
+				telegraph = kinds(b.Update(bossDT, 6.5, 5, nil), BossEventTelegraph)
+			}
+			if len(telegraph) != 1 || telegraph[0].Pattern.Name != "basic_attack" {
+				t.Fatalf("telegraph events = %v, want basic_attack", telegraph)
+			}
+			hits := kinds(runBoss(b, bossAbilities["basic_attack"].WindupTime+0.5, 6.5, tt.dodgeY), BossEventHit)
+			if got := len(hits) > 0; got != tt.wantHit {
+				t.Fatalf("hit = %v, want %v", got, tt.wantHit)
+			}
+			if tt.wantHit && (len(hits) != 1 || hits[0].Damage != bossAbilities["basic_attack"].Damage) {
+				t.Errorf("hits = %v, want one for %v", hits, bossAbilities["basic_attack"].Damage)
+			}
+		})
+	}
+}
+
+func TestBossPhaseMechanics(t *testing.T) {
+	b := NewBossController("fantasy", 5, 5, 1000, 1)
+	runBoss(b, bossTransitionCooldown, 20, 20)
+
+	b.TakeDamage(400)
+	events := runBoss(b, 1, 20, 20)
+	if phases := kinds(events, BossEventPhase); len(phases) != 1 || phases[0].Phase != 1 {
+		t.Fatalf("phase events = %v, want phase 1", phases)
+	}
+	if adds := kinds(events, BossEventAdds); len(adds) != 1 || adds[0].Count != b.Archetype.Adds[1] {
+		t.Errorf("adds events = %v, want %d adds", adds, b.Archetype.Adds[1])
+	}
+
+	b.TakeDamage(300)
+	events = runBoss(b, bossTransitionCooldown+1, 20, 20)
+	if hazards := kinds(events, BossEventHazards); len(hazards) != 1 || hazards[0].Count != b.Archetype.Hazards[2] {
+		t.Errorf("hazard events = %v, want %d hazards", hazards, b.Archetype.Hazards[2])
+	}
+	if b.Phases.CurrentPhase != 2 {
+		t.Errorf("CurrentPhase = %d, want 2", b.Phases.CurrentPhase)
+	}
+}
+
+func TestBossShield(t *testing.T) {
+	b := NewBossController("scifi", 5, 5, 1000, 1)
+	events := runBoss(b, 5, 8, 5)
+	if len(kinds(events, BossEventAbility)) == 0 {
+		t.Fatalf("no shield_up in %v", events)
+	}
+	b.shield = bossShieldTime
+	if got := b.TakeDamage(100); got != 100*bossShieldFactor {
+		t.Errorf("TakeDamage(100) while shielded = %v, want %v", got, 100*bossShieldFactor)
+	}
+}
+
+func TestBossStaysWalkable(t *testing.T) {
+	b := NewBossController("fantasy", 5, 5, 1000, 1)
+	arena := func(x, y float64) bool { return x < 7 }
+	for i := 0; i < 600; i++ {
+		b.Update(bossDT, 15, 5, arena)
+		if b.X >= 7 {
+			t.Fatalf("boss left the arena at x=%v", b.X)
+		}
+	}
+	if b.X < 6.5 {
+		t.Errorf("boss stopped at x=%v, want it pressed against the arena edge", b.X)
+	}
+}
+
+func TestBossDeterministic(t *testing.T) {
+	a, b := NewBossController("scifi", 5, 5, 500, 7), NewBossController("scifi", 5, 5, 500, 7)
+	for i := 0; i < 1200; i++ {
+		if i%100 == 0 {
+			a.TakeDamage(40)
+			b.TakeDamage(40)
+		}
+		a.Update(bossDT, 9, 6, nil)
+		b.Update(bossDT, 9, 6, nil)
+	}
+	if a.X != b.X || a.Y != b.Y || a.Phases.CurrentPhase != b.Phases.CurrentPhase {
+		t.Errorf("bosses with the same seed diverged: (%v,%v) phase %d vs (%v,%v) phase %d",
+			a.X, a.Y, a.Phases.CurrentPhase, b.X, b.Y, b.Phases.CurrentPhase)
+	}
+}
@@ -9,13 +9,18 @@ import (
 type DamageType string
 
 const (
-	DamagePhysical  DamageType = "physical"
+	DamagePhysical  DamageType = "physical" // Kinetic: blades, bullets and blunt force
 	DamageFire      DamageType = "fire"
 	DamagePlasma    DamageType = "plasma"
 	DamageEnergy    DamageType = "energy"
 	DamageExplosive DamageType = "explosive"
+	DamageToxic     DamageType = "toxic"
+	DamageArcane    DamageType = "arcane"
 )
 
+// DamageKinetic is another name for DamagePhysical.
+const DamageKinetic = DamagePhysical
+
 // DamageEvent represents a single damage instance.
 type DamageEvent struct {
 	Source  uint64
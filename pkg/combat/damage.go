@@ -0,0 +1,136 @@
+package combat
+
+import "math"
+
+// Resistances maps damage types to the fraction of that damage resisted:
+// 0.5 halves it, 1 blocks it and negative values are weaknesses. Types not
+// listed do full damage.
+type Resistances map[DamageType]float64
+
+// Resist returns damage after the resistance to its type.
+func (r Resistances) Resist(damage float64, dmgType DamageType) float64 {
+	return damage * (1 - r[dmgType])
+}
+
+// GenreResistances returns what the player's armor resists in a genre.
+func GenreResistances(genreID string) Resistances {
+	switch genreID {
+	case "fantasy":
+		return Resistances{DamagePhysical: 0.15, DamageArcane: -0.2}
+	case "scifi":
+		return Resistances{DamageEnergy: 0.25, DamagePlasma: 0.15}
+	case "horror":
+		return Resistances{DamageArcane: -0.25}
+	case "cyberpunk":
+		return Resistances{DamagePhysical: 0.1, DamageEnergy: 0.2}
+	case "postapoc":
+		return Resistances{DamageFire: 0.15, DamageToxic: 0.2}
+	default:
+		return Resistances{}
+	}
+}
+
+// armorEffect is the share of its usual soak armor manages against each
+// damage type. Armor is made to stop blows and shrapnel; heat and energy
+// get partly through, toxins seep past it and sorcery mostly ignores it.
+// Types not listed are soaked fully.
+var armorEffect = map[DamageType]float64{
+	DamageFire:   0.6,
+	DamagePlasma: 0.6,
+	DamageEnergy: 0.5,
+	DamageArcane: 0.25,
+	DamageToxic:  0,
+}
+
+// ArmorEffect returns the share of its usual soak armor manages against a
+// damage type, from 0 (ignored) to 1.
+func ArmorEffect(dmgType DamageType) float64 {
+	if e, ok := armorEffect[dmgType]; ok {
+		return e
+	}
+	return 1
+}
+
+// damageTypeNames are genre names for damage types, where a genre calls
+// them something other than the type itself.
+var damageTypeNames = map[string]map[DamageType]string{
+	"fantasy":   {DamageToxic: "poison", DamageEnergy: "lightning"},
+	"scifi":     {DamageToxic: "corrosive", DamageArcane: "psionic"},
+	"horror":    {DamageToxic: "blight", DamageArcane: "occult"},
+	"cyberpunk": {DamageToxic: "toxin", DamageArcane: "neural"},
+	"postapoc":  {DamageToxic: "radiation", DamageArcane: "mutagenic"},
+}
+
+// DamageTypeName returns what a genre calls a damage type, e.g. toxic damage
+// is "radiation" in postapoc.
+func DamageTypeName(genreID string, dmgType DamageType) string {
+	if name, ok := damageTypeNames[genreID][dmgType]; ok {
+		return name
+	}
+	return string(dmgType)
+}
+
+// weaponDamageTypes maps the player's ammo types to the damage they deal;
+// genres that use an ammo type differently override it.
+var weaponDamageTypes = map[string]DamageType{
+	"bullets": DamagePhysical,
+	"shells":  DamagePhysical,
+	"cells":   DamageEnergy,
+	"rockets": DamageExplosive,
+}
+
+var genreWeaponDamageTypes = map[string]map[string]DamageType{
+	"fantasy": {"cells": DamageArcane, "rockets": DamageFire},
+	"horror":  {"cells": DamageArcane},
+	"scifi":   {"cells": DamagePlasma},
+}
+
+// WeaponDamageType returns the damage type of the player's weapons that use
+// an ammo type in a genre. Melee weapons and unknown ammo deal physical
+// damage.
+func WeaponDamageType(genreID, ammoType string) DamageType {
+	if t, ok := genreWeaponDamageTypes[genreID][ammoType]; ok {
+		return t
+	}
+	if t, ok := weaponDamageTypes[ammoType]; ok {
+		return t
+	}
+	return DamagePhysical
+}
+
+// Hit is one blow, shot or blast about to land.
+type Hit struct {
+	Amount float64
+	Type   DamageType
+}
+
+// Target is the state of whatever a hit lands on.
+type Target struct {
+	Health      float64
+	Armor       float64
+	Resistances Resistances
+}
+
+// Resolve works out what a hit does to a target. The target's resistance to
+// the hit's type scales it first; armor then soaks the genre's absorption
+// share of what is left, weakened by ArmorEffect for the type and capped by
+// the armor remaining, and the rest comes off health. Damage is never
+// negative, so weaknesses cannot heal.
+func (s *System) Resolve(h Hit, t Target) DamageResult {
+	damage := math.Max(0, t.Resistances.Resist(h.Amount, h.Type))
+	soak := 0.0
+	if t.Armor > 0 {
+		soak = math.Min(t.Armor, damage*s.armorAbsorption*ArmorEffect(h.Type))
+	}
+	result := DamageResult{
+		HealthDamage: damage - soak,
+		ArmorDamage:  soak,
+	}
+	result.Killed = t.Health-result.HealthDamage <= 0
+	return result
+}
+
+// Resolve resolves a hit with the global system.
+func Resolve(h Hit, t Target) DamageResult {
+	return globalSystem.Resolve(h, t)
+}
@@ -0,0 +1,94 @@
+package combat
+
+import "testing"
+
+func TestResistances(t *testing.T) {
+	r := Resistances{DamageFire: 0.5, DamageEnergy: -0.25, DamagePlasma: 1}
+	tests := []struct {
+		dmgType DamageType
+		want    float64
+	}{
+		{DamageFire, 10},
+		{DamageEnergy, 25},
+		{DamagePlasma, 0},
+		{DamagePhysical, 20},
+		{"", 20},
+	}
+	for _, tt := range tests {
+		if got := r.Resist(20, tt.dmgType); got != tt.want {
+			t.Errorf("Resist(20, %q) = %v, want %v", tt.dmgType, got, tt.want)
+		}
+	}
+	var none Resistances
+	if got := none.Resist(20, DamageFire); got != 20 {
+		t.Errorf("nil Resistances.Resist(20) = %v, want 20", got)
+	}
+	if got := GenreResistances("scifi").Resist(20, DamageEnergy); got >= 20 {
+		t.Errorf("scifi armor takes %v from 20 energy, want less", got)
+	}
+	if got := NewDefenseComponent("postapoc").Resistances; got[DamageToxic] <= 0 {
+		t.Errorf("postapoc defense resistances = %v, want toxic resistance", got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s := NewSystem() // Armor soaks half
+	tests := []struct {
+		name       string
+		hit        Hit
+		target     Target
+		wantHealth float64
+		wantArmor  float64
+		wantKilled bool
+	}{
+		{"no armor", Hit{20, DamagePhysical}, Target{Health: 100}, 20, 0, false},
+		{"armor soaks half", Hit{20, DamagePhysical}, Target{Health: 100, Armor: 50}, 10, 10, false},
+		{"soak capped by armor", Hit{20, DamagePhysical}, Target{Health: 100, Armor: 4}, 16, 4, false},
+		{"energy half through armor", Hit{20, DamageEnergy}, Target{Health: 100, Armor: 50}, 15, 5, false},
+		{"toxic ignores armor", Hit{20, DamageToxic}, Target{Health: 100, Armor: 50}, 20, 0, false},
+		{"resisted first", Hit{20, DamageFire}, Target{Health: 100, Resistances: Resistances{DamageFire: 0.5}}, 10, 0, false},
+		{"weakness", Hit{20, DamageFire}, Target{Health: 100, Resistances: Resistances{DamageFire: -0.5}}, 30, 0, false},
+		{"immune", Hit{20, DamageFire}, Target{Health: 5, Resistances: Resistances{DamageFire: 1.5}}, 0, 0, false},
+		{"lethal", Hit{20, DamagePhysical}, Target{Health: 20}, 20, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := s.Resolve(tt.hit, tt.target)
+			if r.HealthDamage != tt.wantHealth || r.ArmorDamage != tt.wantArmor || r.Killed != tt.wantKilled {
+				t.Errorf("Resolve() = health %v, armor %v, killed %v; want %v, %v, %v",
+					r.HealthDamage, r.ArmorDamage, r.Killed, tt.wantHealth, tt.wantArmor, tt.wantKilled)
+			}
+		})
+	}
+}
+
+func TestWeaponDamageType(t *testing.T) {
+	tests := []struct {
+		genre, ammo string
+		want        DamageType
+	}{
+		{"scifi", "bullets", DamagePhysical},
+		{"cyberpunk", "cells", DamageEnergy},
+		{"scifi", "cells", DamagePlasma},
+		{"fantasy", "cells", DamageArcane},
+		{"postapoc", "rockets", DamageExplosive},
+		{"horror", "", DamagePhysical},
+	}
+	for _, tt := range tests {
+		if got := WeaponDamageType(tt.genre, tt.ammo); got != tt.want {
+			t.Errorf("WeaponDamageType(%q, %q) = %q, want %q", tt.genre, tt.ammo, got, tt.want)
+		}
+	}
+}
+
+func TestDamageTypeName(t *testing.T) {
+	if got := DamageTypeName("postapoc", DamageToxic); got != "radiation" {
+		t.Errorf("postapoc toxic = %q, want radiation", got)
+	}
+	if got := DamageTypeName("postapoc", DamageFire); got != "fire" {
+		t.Errorf("postapoc fire = %q, want fire", got)
+	}
+	if got := DamageTypeName("unknown", DamageArcane); got != "arcane" {
+		t.Errorf("unknown genre arcane = %q, want arcane", got)
+	}
+}
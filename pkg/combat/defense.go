@@ -59,6 +59,9 @@ type DefenseComponent struct {
 	BlockStaminaDrain    float64
 	BlockArc             float64
 
+	// Armor
+	Resistances Resistances // Fraction of each damage type the armor resists
+
 	// Cooldowns
 	DodgeCooldown float64
 	ParryCooldown float64
@@ -193,6 +196,7 @@ func NewDefenseComponent(genreID string) *DefenseComponent {
 		DodgeCooldown:        preset.DodgeCooldown,
 		ParryCooldown:        preset.ParryCooldown,
 		BlockCooldown:        preset.BlockCooldown,
+		Resistances:          GenreResistances(genreID),
 	}
 }
 
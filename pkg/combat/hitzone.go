@@ -0,0 +1,87 @@
+package combat
+
+import "math"
+
+// HitZone is the part of a body a shot lands on.
+type HitZone int
+
+const (
+	// ZoneTorso is the body's center mass.
+	ZoneTorso HitZone = iota
+	// ZoneHead is the top of the body.
+	ZoneHead
+	// ZoneLimb covers the legs below the torso and the arms beside it.
+	ZoneLimb
+)
+
+// String returns the zone's name.
+func (z HitZone) String() string {
+	switch z {
+	case ZoneTorso:
+		return "torso"
+	case ZoneHead:
+		return "head"
+	case ZoneLimb:
+		return "limb"
+	default:
+		return "unknown"
+	}
+}
+
+// EyeHeight is the shooter's eye level as a fraction of a body's height.
+const EyeHeight = 0.5
+
+// HitZones lays out the zones on an upright body and how hard each is hit.
+// Heights are fractions of the body's height from its feet; widths are in
+// tiles from its center line.
+type HitZones struct {
+	HeadLine       float64 // Shots at or above this height hit the head
+	LegLine        float64 // Shots below this height hit the legs
+	TorsoHalfWidth float64 // Shots passing further from center than this hit an arm
+	Head           float64 // Damage multiplier for the head
+	Torso          float64 // Damage multiplier for the torso
+	Limb           float64 // Damage multiplier for arms and legs
+}
+
+// DefaultHitZones is the layout used for enemies.
+var DefaultHitZones = HitZones{
+	HeadLine:       0.8,
+	LegLine:        0.35,
+	TorsoHalfWidth: 0.15,
+	Head:           2.0,
+	Torso:          1.0,
+	Limb:           0.6,
+}
+
+// Zone returns the zone a shot crossing the body at the given height and
+// lateral offset lands in. Heights above the head still count as the head
+// and heights below the feet as the legs, since the ray already hit.
+func (z HitZones) Zone(height, lateral float64) HitZone {
+	switch {
+	case height >= z.HeadLine:
+		return ZoneHead
+	case height < z.LegLine || math.Abs(lateral) > z.TorsoHalfWidth:
+		return ZoneLimb
+	default:
+		return ZoneTorso
+	}
+}
+
+// Multiplier returns the damage multiplier for a zone.
+func (z HitZones) Multiplier(zone HitZone) float64 {
+	switch zone {
+	case ZoneHead:
+		return z.Head
+	case ZoneLimb:
+		return z.Limb
+	default:
+		return z.Torso
+	}
+}
+
+// ShotHeight returns the height, as a fraction of a body's height, at which
+// a shot fired from eye level with the given pitch in degrees (up is
+// positive) crosses a body dist tiles away.
+func ShotHeight(pitch, dist float64) float64 {
+	return EyeHeight + dist*math.Tan(pitch*math.Pi/180)
+}
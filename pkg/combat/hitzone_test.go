@@ -0,0 +1,60 @@
+package combat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHitZonesZone(t *testing.T) {
+	z := DefaultHitZones
+	tests := []struct {
+		name            string
+		height, lateral float64
+		want            HitZone
+	}{
+		{"center mass", 0.5, 0, ZoneTorso},
+		{"head", 0.9, 0, ZoneHead},
+		{"over the head", 1.4, 0, ZoneHead},
+		{"head off center", 0.85, 0.3, ZoneHead},
+		{"legs", 0.2, 0, ZoneLimb},
+		{"below the feet", -0.3, 0, ZoneLimb},
+		{"arm", 0.5, 0.3, ZoneLimb},
+		{"other arm", 0.5, -0.3, ZoneLimb},
+	}
+	for _, tt := range tests {
+		if got := z.Zone(tt.height, tt.lateral); got != tt.want {
+			t.Errorf("%s: Zone(%v, %v) = %v, want %v", tt.name, tt.height, tt.lateral, got, tt.want)
+		}
+	}
+}
+
+func TestHitZonesMultiplier(t *testing.T) {
+	z := DefaultHitZones
+	if z.Multiplier(ZoneHead) <= z.Multiplier(ZoneTorso) {
+		t.Errorf("head multiplier %v not above torso %v", z.Multiplier(ZoneHead), z.Multiplier(ZoneTorso))
+	}
+	if z.Multiplier(ZoneLimb) >= z.Multiplier(ZoneTorso) {
+		t.Errorf("limb multiplier %v not below torso %v", z.Multiplier(ZoneLimb), z.Multiplier(ZoneTorso))
+	}
+	if got := z.Multiplier(HitZone(99)); got != z.Torso {
+		t.Errorf("unknown zone multiplier = %v, want torso %v", got, z.Torso)
+	}
+	if got := HitZone(99).String(); got != "unknown" {
+		t.Errorf("HitZone(99).String() = %q, want unknown", got)
+	}
+}
+
+func TestShotHeight(t *testing.T) {
+	if got := ShotHeight(0, 5); got != EyeHeight {
+		t.Errorf("level shot height = %v, want eye height %v", got, EyeHeight)
+	}
+	if got, want := ShotHeight(45, 0.3), EyeHeight+0.3; math.Abs(got-want) > 1e-9 {
+		t.Errorf("ShotHeight(45, 0.3) = %v, want %v", got, want)
+	}
+	if DefaultHitZones.Zone(ShotHeight(10, 3), 0) != ZoneHead {
+		t.Error("shot aimed up at a near enemy misses the head")
+	}
+	if DefaultHitZones.Zone(ShotHeight(-10, 3), 0) != ZoneLimb {
+		t.Error("shot aimed down at a near enemy misses the legs")
+	}
+}
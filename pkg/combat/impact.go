@@ -0,0 +1,91 @@
+package combat
+
+import "math"
+
+// ImpactConfig tunes how hard hits shove, stagger and stun their targets.
+// Impact strength is damage times the weapon's force over the target's
+// mass; the ratios compare it to the target's max health.
+type ImpactConfig struct {
+	KnockbackPerDamage float64 // Tiles of shove per point of impact strength
+	MaxKnockback       float64 // Longest shove one hit can give, in tiles
+	StaggerRatio       float64 // Share of max health one hit must reach to stagger
+	StaggerTime        float64 // Seconds a stagger lasts
+	StunRatio          float64 // Share of max health one hit must reach to stun
+}
+
+// GetImpactConfig returns genre-specific impact tuning. Horror and
+// post-apocalyptic fights are heavy and messy; sci-fi and cyberpunk enemies
+// are braced and shrug off more.
+func GetImpactConfig(genreID string) ImpactConfig {
+	configs := map[string]ImpactConfig{
+		"fantasy": {
+			KnockbackPerDamage: 0.01,
+			MaxKnockback:       0.6,
+			StaggerRatio:       0.35,
+			StaggerTime:        0.4,
+			StunRatio:          0.9,
+		},
+		"scifi": {
+			KnockbackPerDamage: 0.008,
+			MaxKnockback:       0.5,
+			StaggerRatio:       0.4,
+			StaggerTime:        0.3,
+			StunRatio:          1.0,
+		},
+		"horror": {
+			KnockbackPerDamage: 0.012,
+			MaxKnockback:       0.7,
+			StaggerRatio:       0.3,
+			StaggerTime:        0.5,
+			StunRatio:          0.8,
+		},
+		"cyberpunk": {
+			KnockbackPerDamage: 0.008,
+			MaxKnockback:       0.5,
+			StaggerRatio:       0.4,
+			StaggerTime:        0.3,
+			StunRatio:          1.0,
+		},
+		"postapoc": {
+			KnockbackPerDamage: 0.012,
+			MaxKnockback:       0.8,
+			StaggerRatio:       0.35,
+			StaggerTime:        0.45,
+			StunRatio:          0.9,
+		},
+	}
+
+	if cfg, ok := configs[genreID]; ok {
+		return cfg
+	}
+	return configs["fantasy"]
+}
+
+// Impact is the physical effect of a hit on its target.
+type Impact struct {
+	PushX, PushY float64 // Shove in tiles
+	Stagger      bool    // The hit breaks off the target's attack
+	Stun         bool    // The hit leaves the target stunned
+}
+
+// Resolve works out what a hit doing damage with the given weapon force
+// does to a target of mass and maxHealth, shoving it along (dirX, dirY).
+// A mass of zero counts as one.
+func (c ImpactConfig) Resolve(damage, force, mass, maxHealth, dirX, dirY float64) Impact {
+	if mass <= 0 {
+		mass = 1
+	}
+	strength := math.Max(damage, 0) * force / mass
+
+	var imp Impact
+	if l := math.Hypot(dirX, dirY); l > 0 {
+		push := math.Min(strength*c.KnockbackPerDamage, c.MaxKnockback)
+		imp.PushX, imp.PushY = dirX/l*push, dirY/l*push
+	}
+	if maxHealth > 0 {
+		ratio := strength / maxHealth
+		imp.Stagger = ratio >= c.StaggerRatio
+		imp.Stun = ratio >= c.StunRatio
+	}
+	return imp
+}
@@ -0,0 +1,55 @@
+package combat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGetImpactConfig(t *testing.T) {
+	for _, genre := range []string{"fantasy", "scifi", "horror", "cyberpunk", "postapoc", "unknown"} {
+		cfg := GetImpactConfig(genre)
+		if cfg.KnockbackPerDamage <= 0 || cfg.MaxKnockback <= 0 || cfg.StaggerTime <= 0 {
+			t.Errorf("%s: impact config %+v has unset tuning", genre, cfg)
+		}
+		if cfg.StunRatio <= cfg.StaggerRatio {
+			t.Errorf("%s: stun ratio %v not above stagger ratio %v", genre, cfg.StunRatio, cfg.StaggerRatio)
+		}
+	}
+	if GetImpactConfig("unknown") != GetImpactConfig("fantasy") {
+		t.Error("unknown genre does not fall back to fantasy")
+	}
+}
+
+func TestImpactResolve(t *testing.T) {
+	cfg := ImpactConfig{KnockbackPerDamage: 0.01, MaxKnockback: 0.5, StaggerRatio: 0.3, StunRatio: 0.8}
+	tests := []struct {
+		name                  string
+		damage, force, mass   float64
+		wantPush              float64
+		wantStagger, wantStun bool
+	}{
+		{"light hit", 10, 1, 1, 0.1, false, false},
+		{"staggering hit", 40, 1, 1, 0.4, true, false},
+		{"stunning hit", 100, 1, 1, 0.5, true, true},
+		{"heavy target", 40, 1, 2, 0.2, false, false},
+		{"forceful weapon", 20, 2, 1, 0.4, true, false},
+		{"massless target", 10, 1, 0, 0.1, false, false},
+		{"no damage", 0, 1, 1, 0, false, false},
+	}
+	for _, tt := range tests {
+		imp := cfg.Resolve(tt.damage, tt.force, tt.mass, 100, 0, 2)
+		if imp.PushX != 0 || math.Abs(imp.PushY-tt.wantPush) > 1e-9 {
+			t.Errorf("%s: push = (%v, %v), want (0, %v)", tt.name, imp.PushX, imp.PushY, tt.wantPush)
+		}
+		if imp.Stagger != tt.wantStagger || imp.Stun != tt.wantStun {
+			t.Errorf("%s: stagger/stun = %v/%v, want %v/%v", tt.name, imp.Stagger, imp.Stun, tt.wantStagger, tt.wantStun)
+		}
+	}
+
+	if imp := cfg.Resolve(50, 1, 1, 100, 0, 0); imp.PushX != 0 || imp.PushY != 0 || !imp.Stagger {
+		t.Errorf("hit with no direction = %+v, want no push but a stagger", imp)
+	}
+	if imp := cfg.Resolve(50, 1, 1, 0, 1, 0); imp.Stagger || imp.Stun {
+		t.Errorf("hit on target without max health = %+v, want no stagger", imp)
+	}
+}
@@ -0,0 +1,215 @@
+// Package combat - Ranged enemy attacks fired as projectile entities
+package combat
+
+import (
+	"image/color"
+	"math"
+	"reflect"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// RangedWeapon describes the shots an enemy weapon fires. Shots are aimed
+// when the windup starts and fly straight, so a player who reads the
+// windup can step out of the line of fire.
+type RangedWeapon struct {
+	Name       string
+	Speed      float64 // Tiles per second
+	Radius     float64 // Shot collision radius in tiles
+	WindupTime float64 // Seconds of aiming before the shot is released
+	Lifetime   float64 // Seconds of flight before the shot fizzles
+	DamageType DamageType
+	Color      color.RGBA
+}
+
+// rangedWeapons are keyed by the weapon names of enemy archetypes. Weapons
+// not listed here are melee weapons.
+var rangedWeapons = map[string]RangedWeapon{
+	"bow":           {Name: "bow", Speed: 9, Radius: 0.12, WindupTime: 0.6, Lifetime: 2, DamageType: DamagePhysical, Color: color.RGBA{200, 170, 110, 255}},
+	"crossbow":      {Name: "crossbow", Speed: 12, Radius: 0.12, WindupTime: 0.8, Lifetime: 1.5, DamageType: DamagePhysical, Color: color.RGBA{180, 160, 130, 255}},
+	"staff":         {Name: "staff", Speed: 6, Radius: 0.25, WindupTime: 0.7, Lifetime: 2.5, DamageType: DamageFire, Color: color.RGBA{255, 120, 40, 255}},
+	"rifle":         {Name: "rifle", Speed: 16, Radius: 0.1, WindupTime: 0.5, Lifetime: 1.2, DamageType: DamagePhysical, Color: color.RGBA{255, 230, 150, 255}},
+	"minigun":       {Name: "minigun", Speed: 14, Radius: 0.1, WindupTime: 0.9, Lifetime: 1.2, DamageType: DamagePhysical, Color: color.RGBA{255, 200, 100, 255}},
+	"pistol":        {Name: "pistol", Speed: 12, Radius: 0.1, WindupTime: 0.4, Lifetime: 1.2, DamageType: DamagePhysical, Color: color.RGBA{255, 230, 150, 255}},
+	"smg":           {Name: "smg", Speed: 13, Radius: 0.1, WindupTime: 0.3, Lifetime: 1, DamageType: DamagePhysical, Color: color.RGBA{255, 220, 140, 255}},
+	"taser":         {Name: "taser", Speed: 8, Radius: 0.2, WindupTime: 0.5, Lifetime: 1.5, DamageType: DamageEnergy, Color: color.RGBA{120, 200, 255, 255}},
+	"smart_rifle":   {Name: "smart_rifle", Speed: 15, Radius: 0.1, WindupTime: 0.6, Lifetime: 1.2, DamageType: DamagePlasma, Color: color.RGBA{255, 60, 200, 255}},
+	"shotgun":       {Name: "shotgun", Speed: 11, Radius: 0.3, WindupTime: 0.6, Lifetime: 0.6, DamageType: DamagePhysical, Color: color.RGBA{255, 210, 120, 255}},
+	"hunting_rifle": {Name: "hunting_rifle", Speed: 16, Radius: 0.1, WindupTime: 0.8, Lifetime: 1.2, DamageType: DamagePhysical, Color: color.RGBA{255, 230, 150, 255}},
+	"revolver":      {Name: "revolver", Speed: 12, Radius: 0.1, WindupTime: 0.5, Lifetime: 1.2, DamageType: DamagePhysical, Color: color.RGBA{255, 230, 150, 255}},
+}
+
+// RangedWeaponFor returns the shots of a weapon, or false for melee weapons.
+func RangedWeaponFor(name string) (RangedWeapon, bool) {
+	w, ok := rangedWeapons[name]
+	return w, ok
+}
+
+// shotTargetRadius is the collision radius of the shots' target.
+const shotTargetRadius = 0.25
+
+// ShotComponent is an enemy shot: aiming during its windup, then in flight.
+// Pure data - all logic in RangedSystem.
+type ShotComponent struct {
+	Weapon     RangedWeapon
+	Owner      string  // ID of the agent that fired the shot
+	Damage     float64 // Damage dealt on hitting the target
+	DirX, DirY float64 // Normalized flight direction, fixed when aimed
+	Windup     float64 // Seconds until release; 0 once in flight
+	Age        float64 // Seconds in flight
+}
+
+// Type returns the component type identifier.
+func (s *ShotComponent) Type() string {
+	return "ShotComponent"
+}
+
+// Released reports whether the shot has left the weapon.
+func (s *ShotComponent) Released() bool {
+	return s.Windup <= 0
+}
+
+// ShotEventKind is the kind of a shot event.
+type ShotEventKind int
+
+const (
+	// ShotEventFired reports that a shot left its weapon.
+	ShotEventFired ShotEventKind = iota
+	// ShotEventHit reports that a shot hit the target for Damage.
+	ShotEventHit
+	// ShotEventBlocked reports that a shot struck a wall.
+	ShotEventBlocked
+)
+
+// ShotEvent is something that happened to a shot during an update.
+type ShotEvent struct {
+	Kind       ShotEventKind
+	Entity     engine.Entity
+	Owner      string
+	X, Y       float64
+	Damage     float64
+	DamageType DamageType
+}
+
+// RangedSystem fires enemy shots as entities and flies them to the target,
+// resolving wall and target collisions along the way.
+type RangedSystem struct{}
+
+// NewRangedSystem creates a ranged attack system.
+func NewRangedSystem() *RangedSystem {
+	return &RangedSystem{}
+}
+
+// Aim starts a shot from (x, y) toward (targetX, targetY) and returns its
+// entity. The shot is released after the weapon's windup.
+func (s *RangedSystem) Aim(w *engine.World, owner string, weapon RangedWeapon, damage, x, y, targetX, targetY float64) engine.Entity {
+	dx, dy := targetX-x, targetY-y
+	dist := math.Hypot(dx, dy)
+	dirX, dirY := 1.0, 0.0
+	if dist > 0.001 {
+		dirX, dirY = dx/dist, dy/dist
+	}
+
+	e := w.AddEntity()
+	w.AddComponent(e, &engine.Position{X: x, Y: y})
+	w.AddComponent(e, &ShotComponent{
+		Weapon: weapon,
+		Owner:  owner,
+		Damage: damage,
+		DirX:   dirX,
+		DirY:   dirY,
+		Windup: weapon.WindupTime,
+	})
+	return e
+}
+
+// Update advances every shot by dt seconds against a target at
+// (targetX, targetY) and returns what happened. blocked reports whether a
+// point is inside a wall; nil lets shots fly through everything. Shots that
+// hit, strike a wall or outlive their weapon's lifetime are removed.
+func (s *RangedSystem) Update(w *engine.World, dt, targetX, targetY float64, blocked func(x, y float64) bool) []ShotEvent {
+	shotType := reflect.TypeOf(&ShotComponent{})
+	posType := reflect.TypeOf(&engine.Position{})
+
+	var events []ShotEvent
+	var toRemove []engine.Entity
+	for _, e := range w.Query(shotType, posType) {
+		sc, _ := w.GetComponent(e, shotType)
+		pc, _ := w.GetComponent(e, posType)
+		shot, pos := sc.(*ShotComponent), pc.(*engine.Position)
+
+		if !shot.Released() {
+			shot.Windup -= dt
+			if shot.Released() {
+				events = append(events, shot.event(ShotEventFired, e, pos))
+			}
+			continue
+		}
+
+		shot.Age += dt
+		if kind, collided := s.fly(shot, pos, dt, targetX, targetY, blocked); collided {
+			events = append(events, shot.event(kind, e, pos))
+			toRemove = append(toRemove, e)
+		} else if shot.Age >= shot.Weapon.Lifetime {
+			toRemove = append(toRemove, e)
+		}
+	}
+	for _, e := range toRemove {
+		w.RemoveEntity(e)
+	}
+	return events
+}
+
+// Clear removes every shot, e.g. when the level changes.
+func (s *RangedSystem) Clear(w *engine.World) {
+	for _, e := range w.Query(reflect.TypeOf(&ShotComponent{})) {
+		w.RemoveEntity(e)
+	}
+}
+
+// Cancel drops the shots owner is still aiming, e.g. when a hit staggers
+// it, and returns how many were dropped. Shots already in flight fly on.
+func (s *RangedSystem) Cancel(w *engine.World, owner string) int {
+	shotType := reflect.TypeOf(&ShotComponent{})
+	var aiming []engine.Entity
+	for _, e := range w.Query(shotType) {
+		sc, _ := w.GetComponent(e, shotType)
+		if shot := sc.(*ShotComponent); shot.Owner == owner && !shot.Released() {
+			aiming = append(aiming, e)
+		}
+	}
+	for _, e := range aiming {
+		w.RemoveEntity(e)
+	}
+	return len(aiming)
+}
+
+// fly moves a shot through its flight this update in steps no longer than
+// its radius, so fast shots can't skip past walls or the target, and
+// reports what it collided with.
+func (s *RangedSystem) fly(shot *ShotComponent, pos *engine.Position, dt, targetX, targetY float64, blocked func(x, y float64) bool) (ShotEventKind, bool) {
+	dist := shot.Weapon.Speed * dt
+	steps := int(math.Max(math.Ceil(dist/math.Max(shot.Weapon.Radius, 0.05)), 1))
+	step := dist / float64(steps)
+	hitDist := shot.Weapon.Radius + shotTargetRadius
+	for i := 0; i < steps; i++ {
+		pos.X += shot.DirX * step
+		pos.Y += shot.DirY * step
+		if blocked != nil && blocked(pos.X, pos.Y) {
+			return ShotEventBlocked, true
+		}
+		if math.Hypot(targetX-pos.X, targetY-pos.Y) < hitDist {
+			return ShotEventHit, true
+		}
+	}
+	return 0, false
+}
+
+// event reports a shot event at the shot's position.
+func (s *ShotComponent) event(kind ShotEventKind, e engine.Entity, pos *engine.Position) ShotEvent {
+	ev := ShotEvent{Kind: kind, Entity: e, Owner: s.Owner, X: pos.X, Y: pos.Y, DamageType: s.Weapon.DamageType}
+	if kind == ShotEventHit {
+		ev.Damage = s.Damage
+	}
+	return ev
+}
@@ -0,0 +1,142 @@
+package combat
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// runShots updates shots against a target and collects their events.
+func runShots(s *RangedSystem, w *engine.World, seconds, tx, ty float64, blocked func(x, y float64) bool) []ShotEvent {
+	var events []ShotEvent
+	for i := 0; i < int(seconds/bossDT); i++ {
+		events = append(events, s.Update(w, bossDT, tx, ty, blocked)...)
+	}
+	return events
+}
+
+// shotKinds returns the events of a kind.
+func shotKinds(events []ShotEvent, kind ShotEventKind) []ShotEvent {
+	var out []ShotEvent
+	for _, e := range events {
+		if e.Kind == kind {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestRangedWeaponFor(t *testing.T) {
+	if _, ok := RangedWeaponFor("rifle"); !ok {
+		t.Error("rifle is not ranged")
+	}
+	if _, ok := RangedWeaponFor("sword"); ok {
+		t.Error("sword is ranged")
+	}
+	for name, w := range rangedWeapons {
+		if w.Name != name || w.Speed <= 0 || w.Radius <= 0 || w.WindupTime <= 0 || w.Lifetime <= 0 {
+			t.Errorf("weapon %q = %+v, want a name and positive tuning", name, w)
+		}
+	}
+}
+
+func TestRangedShot(t *testing.T) {
+	bow, _ := RangedWeaponFor("bow")
+	tests := []struct {
+		name     string
+		targetY  float64 // Where the target stands once the shot is aimed
+		blocked  func(x, y float64) bool
+		wantKind ShotEventKind
+		wantHits int
+	}{
+		{"stands still", 5, nil, ShotEventHit, 1},
+		{"dodges", 8, nil, ShotEventFired, 0},
+		{"behind a wall", 5, func(x, y float64) bool { return x >= 4 && x < 5 }, ShotEventBlocked, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewRangedSystem()
+			w := engine.NewWorld()
+			s.Aim(w, "enemy_1", bow, 9, 1, 5, 8, 5)
+
+			// Nothing flies during the windup.
+			events := runShots(s, w, bow.WindupTime/2, 8, tt.targetY, tt.blocked)
+			if len(events) != 0 {
+				t.Fatalf("events during windup: %+v", events)
+			}
+
+			events = runShots(s, w, bow.Lifetime+bow.WindupTime, 8, tt.targetY, tt.blocked)
+			if fired := shotKinds(events, ShotEventFired); len(fired) != 1 || fired[0].Owner != "enemy_1" {
+				t.Errorf("fired = %+v, want one shot from enemy_1", fired)
+			}
+			if last := events[len(events)-1]; last.Kind != tt.wantKind {
+				t.Errorf("last event = %+v, want kind %v", last, tt.wantKind)
+			}
+			hits := shotKinds(events, ShotEventHit)
+			if len(hits) != tt.wantHits {
+				t.Errorf("hits = %+v, want %d", hits, tt.wantHits)
+			}
+			if len(hits) == 1 && hits[0].Damage != 9 {
+				t.Errorf("hit damage = %v, want 9", hits[0].Damage)
+			}
+			if left := w.Query(reflect.TypeOf(&ShotComponent{})); len(left) != 0 {
+				t.Errorf("%d shots left in the world", len(left))
+			}
+		})
+	}
+}
+
+func TestRangedShot_TravelTime(t *testing.T) {
+	rifle, _ := RangedWeaponFor("rifle")
+	s := NewRangedSystem()
+	w := engine.NewWorld()
+	s.Aim(w, "enemy_1", rifle, 12, 0, 0, 10, 0)
+
+	// The windup plus 10 tiles of flight must pass before the hit lands.
+	flight := rifle.WindupTime + (10-rifle.Radius-shotTargetRadius)/rifle.Speed
+	if hits := shotKinds(runShots(s, w, flight-0.05, 10, 0, nil), ShotEventHit); len(hits) != 0 {
+		t.Fatalf("hit after %.2fs, before the shot could arrive", flight-0.05)
+	}
+	if hits := shotKinds(runShots(s, w, 0.1, 10, 0, nil), ShotEventHit); len(hits) != 1 {
+		t.Error("shot did not arrive")
+	}
+}
+
+func TestRangedSystem_Clear(t *testing.T) {
+	pistol, _ := RangedWeaponFor("pistol")
+	s := NewRangedSystem()
+	w := engine.NewWorld()
+	s.Aim(w, "enemy_1", pistol, 5, 0, 0, 3, 0)
+	s.Aim(w, "enemy_2", pistol, 5, 0, 1, 3, 1)
+	s.Clear(w)
+	if events := runShots(s, w, 2, 3, 0, nil); len(events) != 0 {
+		t.Errorf("cleared shots produced %+v", events)
+	}
+}
+
+func TestRangedSystem_Cancel(t *testing.T) {
+	pistol, _ := RangedWeaponFor("pistol")
+	s := NewRangedSystem()
+	w := engine.NewWorld()
+	s.Aim(w, "enemy_1", pistol, 5, 0, 0, 3, 0)
+	s.Aim(w, "enemy_2", pistol, 5, 0, 1, 3, 1)
+	if n := s.Cancel(w, "enemy_1"); n != 1 {
+		t.Errorf("Cancel(enemy_1) dropped %d shots, want 1", n)
+	}
+	events := runShots(s, w, 2, 3, 0, nil)
+	for _, e := range events {
+		if e.Owner == "enemy_1" {
+			t.Errorf("cancelled shot produced %+v", e)
+		}
+	}
+	if len(shotKinds(events, ShotEventFired)) != 1 {
+		t.Errorf("events = %+v, want enemy_2's shot fired", events)
+	}
+
+	s.Aim(w, "enemy_1", pistol, 5, 0, 0, 30, 0)
+	runShots(s, w, pistol.WindupTime+0.05, 30, 0, nil)
+	if n := s.Cancel(w, "enemy_1"); n != 0 {
+		t.Errorf("Cancel dropped %d shots in flight, want 0", n)
+	}
+}
@@ -240,6 +240,13 @@ func (s *TelegraphSystem) logExecutionSummary(attacker engine.Entity, hitCount i
 }
 
 func (s *TelegraphSystem) isInAttackArea(attackerPos, targetPos *engine.Position, telegraph *TelegraphComponent) bool {
+	return InAttackArea(attackerPos, targetPos, telegraph)
+}
+
+// InAttackArea reports whether a target at targetPos is caught by the
+// telegraphed attack of an attacker at attackerPos, testing the pattern's
+// collision shape against a character-sized circle.
+func InAttackArea(attackerPos, targetPos *engine.Position, telegraph *TelegraphComponent) bool {
 	// Create precise collision shape for attack
 	var attackCollider *collision.Collider
 
@@ -0,0 +1,189 @@
+// Package combatlog records a structured stream of combat events and sums it
+// into per-level statistics, shown on the end-of-level summary screen and
+// exported as JSON for balancing analysis.
+package combatlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Kind is the kind of a combat event.
+type Kind string
+
+const (
+	// KindShot is the player firing a weapon.
+	KindShot Kind = "shot"
+	// KindHit is the player damaging an enemy.
+	KindHit Kind = "hit"
+	// KindHurt is the player taking damage.
+	KindHurt Kind = "hurt"
+	// KindKill is the player killing an enemy.
+	KindKill Kind = "kill"
+)
+
+// Event is one thing that happened in combat.
+type Event struct {
+	Time float64 `json:"time"` // Seconds since the log was reset
+	Kind Kind    `json:"kind"`
+	// Source is the player's weapon for shots, hits and kills, and the
+	// attacker (an archetype, "boss" or a hazard) for damage taken.
+	Source     string  `json:"source"`
+	Target     string  `json:"target,omitempty"`
+	Amount     float64 `json:"amount,omitempty"`      // Health damage done
+	DamageType string  `json:"damage_type,omitempty"` // combat.DamageType of the damage
+	Hits       int     `json:"hits,omitempty"`        // Targets a shot struck
+	Crit       bool    `json:"crit,omitempty"`
+	Headshot   bool    `json:"headshot,omitempty"`
+}
+
+// Log is a level's combat event stream.
+type Log struct {
+	events  []Event
+	elapsed float64
+}
+
+// New creates an empty log.
+func New() *Log {
+	return &Log{}
+}
+
+// Advance moves the log's clock on by dt seconds.
+func (l *Log) Advance(dt float64) {
+	l.elapsed += dt
+}
+
+// Record adds an event, stamped with the log's clock.
+func (l *Log) Record(e Event) {
+	e.Time = l.elapsed
+	l.events = append(l.events, e)
+}
+
+// Events returns a copy of the events recorded since the last reset.
+func (l *Log) Events() []Event {
+	return append([]Event(nil), l.events...)
+}
+
+// Reset clears the log and its clock for a new level.
+func (l *Log) Reset() {
+	l.events = l.events[:0]
+	l.elapsed = 0
+}
+
+// Summary is a level's combat statistics.
+type Summary struct {
+	Duration    float64            `json:"duration"` // Seconds
+	ShotsFired  int                `json:"shots_fired"`
+	ShotsHit    int                `json:"shots_hit"` // Shots that struck at least one target
+	Crits       int                `json:"crits"`
+	Headshots   int                `json:"headshots"`
+	Kills       int                `json:"kills"`
+	DamageDealt map[string]float64 `json:"damage_dealt"` // By weapon
+	DamageTaken map[string]float64 `json:"damage_taken"` // By attacker
+}
+
+// Summary sums the events recorded since the last reset.
+func (l *Log) Summary() Summary {
+	s := Summary{
+		Duration:    l.elapsed,
+		DamageDealt: make(map[string]float64),
+		DamageTaken: make(map[string]float64),
+	}
+	for _, e := range l.events {
+		switch e.Kind {
+		case KindShot:
+			s.ShotsFired++
+			if e.Hits > 0 {
+				s.ShotsHit++
+			}
+		case KindHit:
+			s.DamageDealt[e.Source] += e.Amount
+			if e.Crit {
+				s.Crits++
+			}
+			if e.Headshot {
+				s.Headshots++
+			}
+		case KindHurt:
+			s.DamageTaken[e.Source] += e.Amount
+		case KindKill:
+			s.Kills++
+		}
+	}
+	return s
+}
+
+// Accuracy returns the share of shots fired that hit, or 0 before any.
+func (s Summary) Accuracy() float64 {
+	if s.ShotsFired == 0 {
+		return 0
+	}
+	return float64(s.ShotsHit) / float64(s.ShotsFired)
+}
+
+// Lines returns the summary as text for the end-of-level screen: totals
+// first, then damage by weapon and by attacker, largest first.
+func (s Summary) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("Time %d:%02d", int(s.Duration)/60, int(s.Duration)%60),
+		fmt.Sprintf("Kills %d", s.Kills),
+		fmt.Sprintf("Accuracy %.0f%% (%d/%d)", s.Accuracy()*100, s.ShotsHit, s.ShotsFired),
+		fmt.Sprintf("Crits %d  Headshots %d", s.Crits, s.Headshots),
+		fmt.Sprintf("Damage dealt %.0f", total(s.DamageDealt)),
+	}
+	lines = append(lines, breakdown(s.DamageDealt)...)
+	lines = append(lines, fmt.Sprintf("Damage taken %.0f", total(s.DamageTaken)))
+	return append(lines, breakdown(s.DamageTaken)...)
+}
+
+// total sums a damage breakdown.
+func total(m map[string]float64) float64 {
+	var sum float64
+	for _, v := range m {
+		sum += v
+	}
+	return sum
+}
+
+// breakdown lists a damage breakdown as indented lines, largest first and
+// ties by name.
+func breakdown(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("  %s %.0f", k, m[k])
+	}
+	return lines
+}
+
+// Export writes the log's summary and events as JSON to path, creating its
+// directory.
+func (l *Log) Export(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	events := l.Events()
+	if events == nil {
+		events = []Event{}
+	}
+	data, err := json.MarshalIndent(struct {
+		Summary Summary `json:"summary"`
+		Events  []Event `json:"events"`
+	}{l.Summary(), events}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,116 @@
+package combatlog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fight records a short fight: two pistol shots, one of which hits with a
+// headshot and kills, and a hit taken from a guard.
+func fight(l *Log) {
+	l.Record(Event{Kind: KindShot, Source: "Pistol", Hits: 1})
+	l.Record(Event{Kind: KindHit, Source: "Pistol", Target: "fantasy_guard", Amount: 30, DamageType: "physical", Crit: true, Headshot: true})
+	l.Advance(1.5)
+	l.Record(Event{Kind: KindKill, Source: "Pistol", Target: "fantasy_guard"})
+	l.Record(Event{Kind: KindShot, Source: "Pistol"})
+	l.Record(Event{Kind: KindHurt, Source: "fantasy_archer", Amount: 9, DamageType: "physical"})
+	l.Advance(63)
+}
+
+func TestLogSummary(t *testing.T) {
+	l := New()
+	fight(l)
+
+	s := l.Summary()
+	want := Summary{
+		Duration:    64.5,
+		ShotsFired:  2,
+		ShotsHit:    1,
+		Crits:       1,
+		Headshots:   1,
+		Kills:       1,
+		DamageDealt: map[string]float64{"Pistol": 30},
+		DamageTaken: map[string]float64{"fantasy_archer": 9},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Summary() = %+v, want %+v", s, want)
+	}
+	if s.Accuracy() != 0.5 {
+		t.Errorf("Accuracy() = %v, want 0.5", s.Accuracy())
+	}
+	if got := (Summary{}).Accuracy(); got != 0 {
+		t.Errorf("Accuracy() with no shots = %v, want 0", got)
+	}
+
+	events := l.Events()
+	if len(events) != 5 || events[0].Time != 0 || events[2].Time != 1.5 {
+		t.Errorf("events = %+v, want 5 stamped with the log clock", events)
+	}
+	events[0].Source = "changed"
+	if l.Events()[0].Source != "Pistol" {
+		t.Error("Events aliases the log")
+	}
+
+	l.Reset()
+	if s := l.Summary(); s.Duration != 0 || s.ShotsFired != 0 || len(l.Events()) != 0 {
+		t.Errorf("after Reset summary = %+v, events = %v", s, l.Events())
+	}
+}
+
+func TestSummaryLines(t *testing.T) {
+	s := Summary{
+		Duration:    64.5,
+		ShotsFired:  4,
+		ShotsHit:    3,
+		Crits:       2,
+		Headshots:   1,
+		Kills:       2,
+		DamageDealt: map[string]float64{"Pistol": 30, "Shotgun": 70},
+		DamageTaken: map[string]float64{"boss": 12, "fantasy_archer": 12},
+	}
+	want := []string{
+		"Time 1:04",
+		"Kills 2",
+		"Accuracy 75% (3/4)",
+		"Crits 2  Headshots 1",
+		"Damage dealt 100",
+		"  Shotgun 70",
+		"  Pistol 30",
+		"Damage taken 24",
+		"  boss 12",
+		"  fantasy_archer 12",
+	}
+	if got := s.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestLogExport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "level_1.json")
+	l := New()
+	if err := l.Export(path); err != nil {
+		t.Fatalf("Export empty log: %v", err)
+	}
+	fight(l)
+	if err := l.Export(path); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	var got struct {
+		Summary Summary `json:"summary"`
+		Events  []Event `json:"events"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if !reflect.DeepEqual(got.Summary, l.Summary()) || !reflect.DeepEqual(got.Events, l.Events()) {
+		t.Errorf("export = %+v, want the log's summary and events", got)
+	}
+}
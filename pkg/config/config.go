@@ -10,22 +10,38 @@ import (
 
 // Config holds all game configuration values.
 type Config struct {
-	WindowWidth      int            `mapstructure:"WindowWidth"`
-	WindowHeight     int            `mapstructure:"WindowHeight"`
-	InternalWidth    int            `mapstructure:"InternalWidth"`
-	InternalHeight   int            `mapstructure:"InternalHeight"`
-	FOV              float64        `mapstructure:"FOV"`
-	MouseSensitivity float64        `mapstructure:"MouseSensitivity"`
-	MasterVolume     float64        `mapstructure:"MasterVolume"`
-	MusicVolume      float64        `mapstructure:"MusicVolume"`
-	SFXVolume        float64        `mapstructure:"SFXVolume"`
-	DefaultGenre     string         `mapstructure:"DefaultGenre"`
-	VSync            bool           `mapstructure:"VSync"`
-	FullScreen       bool           `mapstructure:"FullScreen"`
-	MaxTPS           int            `mapstructure:"MaxTPS"` // Maximum ticks per second (0 = unlimited)
-	KeyBindings      map[string]int `mapstructure:"KeyBindings"`
-	ProfanityFilter  bool           `mapstructure:"ProfanityFilter"`  // Client-side profanity filter toggle
-	FederationHubURL string         `mapstructure:"FederationHubURL"` // URL of the federation hub for server discovery (empty = local mode only)
+	WindowWidth       int            `mapstructure:"WindowWidth"`
+	WindowHeight      int            `mapstructure:"WindowHeight"`
+	InternalWidth     int            `mapstructure:"InternalWidth"`
+	InternalHeight    int            `mapstructure:"InternalHeight"`
+	FOV               float64        `mapstructure:"FOV"`
+	MouseSensitivity  float64        `mapstructure:"MouseSensitivity"`
+	MasterVolume      float64        `mapstructure:"MasterVolume"`
+	MusicVolume       float64        `mapstructure:"MusicVolume"`
+	SFXVolume         float64        `mapstructure:"SFXVolume"`
+	DefaultGenre      string         `mapstructure:"DefaultGenre"`
+	VSync             bool           `mapstructure:"VSync"`
+	FullScreen        bool           `mapstructure:"FullScreen"`
+	MaxTPS            int            `mapstructure:"MaxTPS"` // Maximum ticks per second (0 = unlimited)
+	KeyBindings       map[string]int `mapstructure:"KeyBindings"`
+	ProfanityFilter   bool           `mapstructure:"ProfanityFilter"`   // Client-side profanity filter toggle
+	FederationHubURL  string         `mapstructure:"FederationHubURL"`  // URL of the federation hub for server discovery (empty = local mode only)
+	QualityTier       string         `mapstructure:"QualityTier"`       // Render quality tier: low, medium, high, ultra
+	DynamicResolution bool           `mapstructure:"DynamicResolution"` // Lower internal resolution when frames exceed FrameBudgetMS
+	FrameBudgetMS     float64        `mapstructure:"FrameBudgetMS"`     // Render time budget per frame for dynamic resolution
+	ScreenshotDir     string         `mapstructure:"ScreenshotDir"`     // Directory screenshots are saved to
+	ScreenshotHideHUD bool           `mapstructure:"ScreenshotHideHUD"` // Capture screenshots without the HUD
+	AIDumpDir         string         `mapstructure:"AIDumpDir"`         // Directory AI debug JSON dumps are written to
+	WorldSize         int            `mapstructure:"WorldSize"`         // Overworld edge in tiles, streamed in chunks (0 = single BSP level)
+	CombatLogDir      string         `mapstructure:"CombatLogDir"`      // Directory per-level combat logs are exported to (empty = no export)
+	Encumbrance       bool           `mapstructure:"Encumbrance"`       // Slow the player when carrying more than their capacity (false = arcade-style)
+	SavePassphrase    string         `mapstructure:"SavePassphrase"`    // Encrypt saves, the stash and the profile with this passphrase (empty = unencrypted)
+	SaveDir           string         `mapstructure:"SaveDir"`           // Directory saves are kept in (empty = the platform's data directory)
+	FavoriteServers   []string       `mapstructure:"FavoriteServers"`   // Server addresses starred in the server browser
+	ReplayDir         string         `mapstructure:"ReplayDir"`         // Directory match replays are watched from
+	ServerRegion      string         `mapstructure:"ServerRegion"`      // Region reported to the hub for ranking servers by latency (empty = let the hub infer it)
+	ModRegistryURL    string         `mapstructure:"ModRegistryURL"`    // URL of the mod registry for author keys and mod downloads (empty = no registry)
+	ModHotReload      bool           `mapstructure:"ModHotReload"`      // Reload the content of enabled mods when their files change
 }
 
 // C is the global configuration instance.
@@ -60,6 +76,22 @@ func Load() error {
 	viper.SetDefault("KeyBindings", map[string]int{})
 	viper.SetDefault("ProfanityFilter", true)
 	viper.SetDefault("FederationHubURL", "")
+	viper.SetDefault("QualityTier", "high")
+	viper.SetDefault("DynamicResolution", true)
+	viper.SetDefault("FrameBudgetMS", 14.0)
+	viper.SetDefault("ScreenshotDir", "screenshots")
+	viper.SetDefault("ScreenshotHideHUD", false)
+	viper.SetDefault("AIDumpDir", "ai_dumps")
+	viper.SetDefault("WorldSize", 0)
+	viper.SetDefault("CombatLogDir", "")
+	viper.SetDefault("Encumbrance", true)
+	viper.SetDefault("SavePassphrase", "")
+	viper.SetDefault("SaveDir", "")
+	viper.SetDefault("FavoriteServers", []string{})
+	viper.SetDefault("ReplayDir", "replays")
+	viper.SetDefault("ServerRegion", "")
+	viper.SetDefault("ModRegistryURL", "")
+	viper.SetDefault("ModHotReload", false)
 
 	if err := viper.ReadInConfig(); err != nil {
 		var notFound viper.ConfigFileNotFoundError
@@ -91,6 +123,22 @@ func Save() error {
 	viper.Set("MaxTPS", C.MaxTPS)
 	viper.Set("KeyBindings", C.KeyBindings)
 	viper.Set("ProfanityFilter", C.ProfanityFilter)
+	viper.Set("QualityTier", C.QualityTier)
+	viper.Set("DynamicResolution", C.DynamicResolution)
+	viper.Set("FrameBudgetMS", C.FrameBudgetMS)
+	viper.Set("ScreenshotDir", C.ScreenshotDir)
+	viper.Set("ScreenshotHideHUD", C.ScreenshotHideHUD)
+	viper.Set("AIDumpDir", C.AIDumpDir)
+	viper.Set("WorldSize", C.WorldSize)
+	viper.Set("CombatLogDir", C.CombatLogDir)
+	viper.Set("Encumbrance", C.Encumbrance)
+	viper.Set("SavePassphrase", C.SavePassphrase)
+	viper.Set("SaveDir", C.SaveDir)
+	viper.Set("FavoriteServers", C.FavoriteServers)
+	viper.Set("ReplayDir", C.ReplayDir)
+	viper.Set("ServerRegion", C.ServerRegion)
+	viper.Set("ModRegistryURL", C.ModRegistryURL)
+	viper.Set("ModHotReload", C.ModHotReload)
 
 	return viper.WriteConfig()
 }
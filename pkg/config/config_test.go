@@ -32,6 +32,21 @@ func TestLoad_DefaultValues(t *testing.T) {
 		{"VSync", "VSync", true},
 		{"FullScreen", "FullScreen", false},
 		{"MaxTPS", "MaxTPS", 60},
+		{"QualityTier", "QualityTier", "high"},
+		{"DynamicResolution", "DynamicResolution", true},
+		{"FrameBudgetMS", "FrameBudgetMS", 14.0},
+		{"ScreenshotDir", "ScreenshotDir", "screenshots"},
+		{"ScreenshotHideHUD", "ScreenshotHideHUD", false},
+		{"AIDumpDir", "AIDumpDir", "ai_dumps"},
+		{"WorldSize", "WorldSize", 0},
+		{"CombatLogDir", "CombatLogDir", ""},
+		{"Encumbrance", "Encumbrance", true},
+		{"SavePassphrase", "SavePassphrase", ""},
+		{"SaveDir", "SaveDir", ""},
+		{"ReplayDir", "ReplayDir", "replays"},
+		{"ServerRegion", "ServerRegion", ""},
+		{"ModRegistryURL", "ModRegistryURL", ""},
+		{"ModHotReload", "ModHotReload", false},
 	}
 
 	if err := Load(); err != nil {
@@ -69,6 +84,36 @@ func TestLoad_DefaultValues(t *testing.T) {
 				actual = cfg.FullScreen
 			case "MaxTPS":
 				actual = cfg.MaxTPS
+			case "QualityTier":
+				actual = cfg.QualityTier
+			case "DynamicResolution":
+				actual = cfg.DynamicResolution
+			case "FrameBudgetMS":
+				actual = cfg.FrameBudgetMS
+			case "ScreenshotDir":
+				actual = cfg.ScreenshotDir
+			case "ScreenshotHideHUD":
+				actual = cfg.ScreenshotHideHUD
+			case "AIDumpDir":
+				actual = cfg.AIDumpDir
+			case "WorldSize":
+				actual = cfg.WorldSize
+			case "CombatLogDir":
+				actual = cfg.CombatLogDir
+			case "Encumbrance":
+				actual = cfg.Encumbrance
+			case "SavePassphrase":
+				actual = cfg.SavePassphrase
+			case "SaveDir":
+				actual = cfg.SaveDir
+			case "ReplayDir":
+				actual = cfg.ReplayDir
+			case "ServerRegion":
+				actual = cfg.ServerRegion
+			case "ModRegistryURL":
+				actual = cfg.ModRegistryURL
+			case "ModHotReload":
+				actual = cfg.ModHotReload
 			}
 			if actual != tt.expected {
 				t.Errorf("Config.%s = %v, want %v", tt.field, actual, tt.expected)
@@ -4,6 +4,8 @@ package crafting
 import (
 	"fmt"
 	"sync"
+
+	"github.com/opd-ai/violence/pkg/ammo"
 )
 
 // Scrap represents a crafting material resource.
@@ -244,7 +246,86 @@ func getDefaultRecipes() []Recipe {
 	}
 }
 
+// AttachmentPrefix starts the output IDs of recipes that craft weapon
+// attachments; the rest of the ID names the attachment.
+const AttachmentPrefix = "attach_"
+
+// attachmentParts are the weapon attachments that can be crafted, with
+// their scrap cost.
+var attachmentParts = []struct {
+	id, name string
+	cost     int
+}{
+	{"scope_reflex", "Reflex Sight", 20},
+	{"scope_long", "Long Scope", 35},
+	{"barrel_heavy", "Heavy Barrel", 30},
+	{"barrel_ported", "Ported Barrel", 25},
+	{"barrel_suppressor", "Suppressor", 30},
+	{"mag_extended", "Extended Magazine", 25},
+	{"mag_speed", "Speed Loader", 20},
+	{"under_grip", "Foregrip", 20},
+	{"under_bayonet", "Bayonet", 15},
+}
+
+// attachmentRecipes returns the recipes crafting weapon attachments from
+// a genre's scrap.
+func attachmentRecipes(scrap string) []Recipe {
+	recipes := make([]Recipe, 0, len(attachmentParts))
+	for _, p := range attachmentParts {
+		recipes = append(recipes, Recipe{
+			ID:        AttachmentPrefix + p.id,
+			Name:      "Craft " + p.name,
+			Inputs:    map[string]int{scrap: p.cost},
+			OutputID:  AttachmentPrefix + p.id,
+			OutputQty: 1,
+		})
+	}
+	return recipes
+}
+
+// ammoLoads are the ammo variants that can be crafted, with their scrap
+// cost and the rounds made.
+var ammoLoads = []struct {
+	ammoType, variant, name string
+	cost, qty               int
+}{
+	{ammo.Bullets, "incendiary", "Incendiary Bullets", 10, 10},
+	{ammo.Shells, "incendiary", "Incendiary Shells", 14, 5},
+	{ammo.Bullets, "ap", "Armor-Piercing Bullets", 12, 10},
+	{ammo.Shells, "ap", "Armor-Piercing Shells", 16, 5},
+	{ammo.Bullets, "hollow_point", "Hollow-Point Bullets", 8, 10},
+	{ammo.Cells, "emp", "EMP Cells", 15, 10},
+}
+
+// ammoRecipes returns the recipes crafting ammo variants from a genre's
+// scrap. Each outputs rounds into the variant's ammo pool.
+func ammoRecipes(scrap string) []Recipe {
+	recipes := make([]Recipe, 0, len(ammoLoads))
+	for _, l := range ammoLoads {
+		key := ammo.PoolKey(l.ammoType, l.variant)
+		recipes = append(recipes, Recipe{
+			ID:        key,
+			Name:      "Craft " + l.name,
+			Inputs:    map[string]int{scrap: l.cost},
+			OutputID:  key,
+			OutputQty: l.qty,
+		})
+	}
+	return recipes
+}
+
 func getGenreRecipes(genreID string) []Recipe {
+	switch genreID {
+	case "fantasy", "scifi", "horror", "cyberpunk", "postapoc":
+		scrap := GetScrapNameForGenre(genreID)
+		recipes := append(getGenreConsumables(genreID), ammoRecipes(scrap)...)
+		return append(recipes, attachmentRecipes(scrap)...)
+	default:
+		return getDefaultRecipes()
+	}
+}
+
+func getGenreConsumables(genreID string) []Recipe {
 	switch genreID {
 	case "fantasy":
 		return []Recipe{
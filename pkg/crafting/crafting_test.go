@@ -1,6 +1,11 @@
 package crafting
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/ammo"
+)
 
 func TestCraft(t *testing.T) {
 	tests := []struct {
@@ -725,3 +730,38 @@ func TestCraftingMenu_ConcurrentAccess(t *testing.T) {
 		<-done
 	}
 }
+
+func TestAttachmentRecipes(t *testing.T) {
+	SetGenre("horror")
+	found := 0
+	for _, r := range GetRecipes() {
+		if !strings.HasPrefix(r.ID, AttachmentPrefix) {
+			continue
+		}
+		found++
+		if r.OutputID != r.ID || r.OutputQty != 1 || r.Inputs["flesh"] <= 0 {
+			t.Errorf("attachment recipe %+v, want one part crafted from flesh", r)
+		}
+	}
+	if found != len(attachmentParts) {
+		t.Errorf("found %d attachment recipes, want %d", found, len(attachmentParts))
+	}
+}
+
+func TestAmmoRecipes(t *testing.T) {
+	SetGenre("cyberpunk")
+	found := 0
+	for _, r := range GetRecipes() {
+		ammoType, v, ok := ammo.ParsePoolKey(r.OutputID)
+		if !ok {
+			continue
+		}
+		found++
+		if !v.Fits(ammoType) || r.OutputQty <= 0 || r.Inputs["data_shards"] <= 0 {
+			t.Errorf("ammo recipe %+v, want %s rounds crafted from data shards", r, v.Name)
+		}
+	}
+	if found != len(ammoLoads) {
+		t.Errorf("found %d ammo variant recipes, want %d", found, len(ammoLoads))
+	}
+}
@@ -22,6 +22,10 @@ type Component struct {
 
 	// SwayOffsetX and SwayOffsetY apply weapon sway offset to crosshair position (pixels)
 	SwayOffsetX, SwayOffsetY float64
+
+	// Spread widens the ranged crosshair's gap by this many pixels to show
+	// the weapon's current spread bloom
+	Spread float64
 }
 
 // Type implements the engine.Component interface.
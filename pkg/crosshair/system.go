@@ -102,7 +102,7 @@ func (s *System) renderCrosshair(screen *ebiten.Image, entityX, entityY float64,
 
 	switch ch.WeaponType {
 	case "ranged":
-		s.renderRangedCrosshair(screen, screenX, screenY, float32(ch.Scale), crosshairColor)
+		s.renderRangedCrosshair(screen, screenX, screenY, float32(ch.Scale), float32(ch.Spread), crosshairColor)
 	case "magic":
 		s.renderMagicCrosshair(screen, screenX, screenY, float32(ch.Scale), crosshairColor)
 	case "melee":
@@ -124,10 +124,11 @@ func (s *System) renderMeleeCrosshair(screen *ebiten.Image, x, y, scale float32,
 	vector.StrokeCircle(screen, x, y, radius+1, 1, outerColor, false)
 }
 
-// renderRangedCrosshair draws a precision crosshair for ranged weapons.
-func (s *System) renderRangedCrosshair(screen *ebiten.Image, x, y, scale float32, col color.RGBA) {
+// renderRangedCrosshair draws a precision crosshair for ranged weapons, its
+// gap opened by spread pixels.
+func (s *System) renderRangedCrosshair(screen *ebiten.Image, x, y, scale, spread float32, col color.RGBA) {
 	size := 8.0 * scale
-	gap := 3.0 * scale
+	gap := 3.0*scale + max(spread, 0)
 	thickness := 1.5 * scale
 
 	// Draw four lines forming a crosshair with a gap in the center
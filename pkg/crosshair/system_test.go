@@ -122,7 +122,8 @@ func TestRenderRangedCrosshair(t *testing.T) {
 	screen := ebiten.NewImage(100, 100)
 
 	col := color.RGBA{R: 255, G: 255, B: 255, A: 255}
-	sys.renderRangedCrosshair(screen, 50, 50, 1.0, col)
+	sys.renderRangedCrosshair(screen, 50, 50, 1.0, 0, col)
+	sys.renderRangedCrosshair(screen, 50, 50, 1.0, 12, col)
 
 	// Should not panic
 }
@@ -46,8 +46,10 @@ type Decoration struct {
 
 // RoomDecor holds all decorations for a room.
 type RoomDecor struct {
-	RoomType    RoomType
-	Decorations []Decoration
+	RoomType        RoomType
+	Decorations     []Decoration
+	FloorMaterial   Material
+	CeilingMaterial Material
 }
 
 // System manages room decoration and environmental storytelling.
@@ -193,6 +195,7 @@ func (s *System) DecorateRoom(roomType RoomType, x, y, width, height int, tiles
 		RoomType:    roomType,
 		Decorations: make([]Decoration, 0, 16),
 	}
+	decor.FloorMaterial, decor.CeilingMaterial = s.SurfaceMaterials(roomType)
 
 	// Place landmark decoration first (center focal point)
 	if r.Float64() < s.genreCfg.LandmarkChance {
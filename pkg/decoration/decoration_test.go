@@ -378,3 +378,63 @@ func BenchmarkDecorateRoom(b *testing.B) {
 		sys.DecorateRoom(RoomArmory, 5, 5, 20, 20, tiles, r)
 	}
 }
+
+func TestSurfaceMaterials(t *testing.T) {
+	tests := []struct {
+		name        string
+		genreID     string
+		roomType    RoomType
+		wantFloor   Material
+		wantCeiling Material
+	}{
+		{"fantasy generic uses genre default", genre.Fantasy, RoomGeneric, MaterialStone, MaterialStone},
+		{"scifi generic uses genre default", genre.SciFi, RoomGeneric, MaterialMetal, MaterialMetal},
+		{"library falls back to shared override", genre.Horror, RoomLibrary, MaterialWood, MaterialWood},
+		{"scifi prison uses genre override", genre.SciFi, RoomPrison, MaterialGrate, MaterialMetal},
+		{"fantasy prison uses shared override", genre.Fantasy, RoomPrison, MaterialStone, MaterialStone},
+		{"boss room uses genre default", genre.PostApoc, RoomBoss, MaterialDirt, MaterialConcrete},
+		{"unknown genre falls back to fantasy", "unknown", RoomGeneric, MaterialStone, MaterialStone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sys := NewSystem()
+			sys.SetGenre(tt.genreID)
+			floor, ceiling := sys.SurfaceMaterials(tt.roomType)
+			if floor != tt.wantFloor || ceiling != tt.wantCeiling {
+				t.Errorf("SurfaceMaterials(%v) = (%v, %v), want (%v, %v)",
+					tt.roomType, floor, ceiling, tt.wantFloor, tt.wantCeiling)
+			}
+		})
+	}
+}
+
+func TestDecorateRoomSetsMaterials(t *testing.T) {
+	sys := NewSystem()
+	sys.SetGenre(genre.SciFi)
+	tiles := make([][]int, 20)
+	for y := range tiles {
+		tiles[y] = make([]int, 20)
+		for x := range tiles[y] {
+			tiles[y][x] = 2 // floor
+		}
+	}
+	decor := sys.DecorateRoom(RoomLaboratory, 2, 2, 10, 10, tiles, rng.NewRNG(1))
+	if decor.FloorMaterial != MaterialTile || decor.CeilingMaterial != MaterialMetal {
+		t.Errorf("laboratory materials = (%v, %v), want (tile, metal)", decor.FloorMaterial, decor.CeilingMaterial)
+	}
+}
+
+func TestMaterialString(t *testing.T) {
+	seen := map[string]bool{}
+	for _, m := range AllMaterials() {
+		name := m.String()
+		if seen[name] {
+			t.Errorf("duplicate material name %q", name)
+		}
+		seen[name] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("got %d material names, want 8", len(seen))
+	}
+}
@@ -0,0 +1,120 @@
+package decoration
+
+import "github.com/opd-ai/violence/pkg/procgen/genre"
+
+// Material identifies a floor or ceiling surface material.
+type Material int
+
+const (
+	MaterialStone    Material = iota // MaterialStone is flagstone or rock.
+	MaterialWood                     // MaterialWood is planks or beams.
+	MaterialMetal                    // MaterialMetal is riveted plating.
+	MaterialTile                     // MaterialTile is glazed or clean tiling.
+	MaterialCarpet                   // MaterialCarpet is woven carpet or rugs.
+	MaterialDirt                     // MaterialDirt is packed earth.
+	MaterialConcrete                 // MaterialConcrete is poured concrete.
+	MaterialGrate                    // MaterialGrate is open grating.
+)
+
+// String returns the material name used in texture names.
+func (m Material) String() string {
+	switch m {
+	case MaterialStone:
+		return "stone"
+	case MaterialWood:
+		return "wood"
+	case MaterialMetal:
+		return "metal"
+	case MaterialTile:
+		return "tile"
+	case MaterialCarpet:
+		return "carpet"
+	case MaterialDirt:
+		return "dirt"
+	case MaterialConcrete:
+		return "concrete"
+	case MaterialGrate:
+		return "grate"
+	default:
+		return "stone"
+	}
+}
+
+// AllMaterials lists every surface material, for pre-generating textures.
+func AllMaterials() []Material {
+	return []Material{
+		MaterialStone, MaterialWood, MaterialMetal, MaterialTile,
+		MaterialCarpet, MaterialDirt, MaterialConcrete, MaterialGrate,
+	}
+}
+
+// surfacePair is a floor and ceiling material combination.
+type surfacePair struct {
+	floor, ceiling Material
+}
+
+// genreSurfaces is the default surface pair for rooms of each genre.
+var genreSurfaces = map[string]surfacePair{
+	genre.Fantasy:   {MaterialStone, MaterialStone},
+	genre.SciFi:     {MaterialMetal, MaterialMetal},
+	genre.Horror:    {MaterialWood, MaterialWood},
+	genre.Cyberpunk: {MaterialConcrete, MaterialMetal},
+	genre.PostApoc:  {MaterialDirt, MaterialConcrete},
+}
+
+// roomSurfaces overrides the genre default for specific room types.
+// A genre-specific entry takes precedence over the "" fallback entry.
+var roomSurfaces = map[RoomType]map[string]surfacePair{
+	RoomLibrary: {
+		"": {MaterialWood, MaterialWood},
+	},
+	RoomShrine: {
+		"":           {MaterialTile, MaterialStone},
+		genre.Horror: {MaterialStone, MaterialStone},
+	},
+	RoomTreasure: {
+		"":          {MaterialCarpet, MaterialStone},
+		genre.SciFi: {MaterialTile, MaterialMetal},
+	},
+	RoomPrison: {
+		"":              {MaterialStone, MaterialStone},
+		genre.SciFi:     {MaterialGrate, MaterialMetal},
+		genre.Cyberpunk: {MaterialGrate, MaterialConcrete},
+	},
+	RoomBarracks: {
+		"":          {MaterialWood, MaterialWood},
+		genre.SciFi: {MaterialMetal, MaterialMetal},
+	},
+	RoomLaboratory: {
+		"": {MaterialTile, MaterialMetal},
+	},
+	RoomStorage: {
+		"":             {MaterialConcrete, MaterialConcrete},
+		genre.Fantasy:  {MaterialWood, MaterialStone},
+		genre.PostApoc: {MaterialDirt, MaterialMetal},
+	},
+	RoomArmory: {
+		"":             {MaterialStone, MaterialStone},
+		genre.SciFi:    {MaterialGrate, MaterialMetal},
+		genre.PostApoc: {MaterialConcrete, MaterialMetal},
+	},
+}
+
+// SurfaceMaterials returns the floor and ceiling materials for a room type
+// in the current genre. Selection depends only on genre and room type, so it
+// does not consume RNG state.
+func (s *System) SurfaceMaterials(roomType RoomType) (floor, ceiling Material) {
+	if overrides, ok := roomSurfaces[roomType]; ok {
+		if p, ok := overrides[s.genre]; ok {
+			return p.floor, p.ceiling
+		}
+		if p, ok := overrides[""]; ok {
+			return p.floor, p.ceiling
+		}
+	}
+	if p, ok := genreSurfaces[s.genre]; ok {
+		return p.floor, p.ceiling
+	}
+	p := genreSurfaces[genre.Fantasy]
+	return p.floor, p.ceiling
+}
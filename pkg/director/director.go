@@ -0,0 +1,274 @@
+// Package director paces encounters. It watches how the player is doing and
+// schedules reinforcement waves, lulls and, in horror, ambient scares.
+//
+// The director cycles through three phases. During build-up it sends waves
+// of reinforcements while the player's intensity (damage taken and kills
+// made) rises. Once intensity peaks it holds off while the fight plays out,
+// then gives the player a lull before building up again. Struggling players
+// get an early lull, and wave sizes follow the player's health, ammo and
+// kill rate.
+package director
+
+import (
+	"math"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// Phase is a stage of the director's pacing cycle.
+type Phase int
+
+const (
+	// PhaseBuildUp sends waves while tension rises.
+	PhaseBuildUp Phase = iota
+	// PhasePeak holds off new waves while the peak fight plays out.
+	PhasePeak
+	// PhaseLull gives the player breathing room.
+	PhaseLull
+)
+
+// String returns the phase name.
+func (p Phase) String() string {
+	switch p {
+	case PhasePeak:
+		return "peak"
+	case PhaseLull:
+		return "lull"
+	default:
+		return "build_up"
+	}
+}
+
+// EventKind is the kind of a director event.
+type EventKind int
+
+const (
+	// EventWave asks the game to spawn Count reinforcements.
+	EventWave EventKind = iota
+	// EventScare asks the game to play the ambient scare cue Scare.
+	EventScare
+)
+
+// Event is something the director wants the game to do.
+type Event struct {
+	Kind  EventKind
+	Count int
+	Scare string
+}
+
+// Status is the player's situation, reported every update.
+type Status struct {
+	Health, MaxHealth int
+	Ammo, MaxAmmo     int
+	// Enemies is the number of living enemies.
+	Enemies int
+}
+
+// Budget limits what the director may do in a level.
+type Budget struct {
+	Reinforcements int     // Enemies the director may add over the level
+	MaxWave        int     // Largest wave
+	MaxEnemies     int     // Living enemies at or above which no wave is sent
+	WaveInterval   float64 // Seconds between waves during build-up
+	PeakIntensity  float64 // Intensity that ends the build-up
+	PeakDuration   float64 // Seconds to hold off after the peak
+	LullDuration   float64 // Seconds of breathing room
+}
+
+// budgets are indexed by difficulty, matching bsp.DifficultyEasy through
+// bsp.DifficultyNightmare.
+var budgets = [...]Budget{
+	{Reinforcements: 4, MaxWave: 2, MaxEnemies: 4, WaveInterval: 45, PeakIntensity: 0.6, PeakDuration: 10, LullDuration: 40},
+	{Reinforcements: 8, MaxWave: 3, MaxEnemies: 6, WaveInterval: 35, PeakIntensity: 0.75, PeakDuration: 12, LullDuration: 30},
+	{Reinforcements: 12, MaxWave: 4, MaxEnemies: 8, WaveInterval: 25, PeakIntensity: 0.9, PeakDuration: 15, LullDuration: 22},
+	{Reinforcements: 18, MaxWave: 6, MaxEnemies: 10, WaveInterval: 18, PeakIntensity: 1, PeakDuration: 18, LullDuration: 15},
+}
+
+// BudgetFor returns the budget of a difficulty, clamped to the known range.
+func BudgetFor(difficulty int) Budget {
+	return budgets[max(0, min(difficulty, len(budgets)-1))]
+}
+
+// Pacing tuning.
+const (
+	// damageIntensity is the intensity gained from losing all health.
+	damageIntensity = 1.5
+	// killIntensity is the intensity gained per kill.
+	killIntensity = 0.08
+	// intensityDecay is the intensity lost per second.
+	intensityDecay = 0.02
+	// killWindow is the span, in seconds, the kill rate is measured over.
+	killWindow = 60.0
+	// targetKillRate is the kills per killWindow of a player keeping pace;
+	// faster players get bigger waves, slower ones smaller.
+	targetKillRate = 4.0
+	// mercyHealth is the health fraction below which the director backs off.
+	mercyHealth = 0.3
+	// scareMin and scareSpread bound the seconds between scares in a lull.
+	scareMin    = 8.0
+	scareSpread = 12.0
+)
+
+// scareCues are the sound cues of horror ambient scares.
+var scareCues = []string{"scare_whisper", "scare_footsteps", "scare_scream", "scare_knock"}
+
+// Director schedules waves, lulls and scares for one level. Its choices
+// depend only on its seed and the updates it is given, so replays and
+// clients with the same inputs pace the same way.
+type Director struct {
+	budget Budget
+	scares bool
+	rng    *rng.RNG
+
+	phase      Phase
+	phaseTime  float64
+	intensity  float64
+	sinceWave  float64
+	nextScare  float64
+	spent      int
+	lastHealth int
+	kills      []float64
+	time       float64
+}
+
+// New creates a director for a level at the given difficulty. Ambient
+// scares are only scheduled in the horror genre.
+func New(difficulty int, genreID string, seed uint64) *Director {
+	d := &Director{
+		budget:     BudgetFor(difficulty),
+		scares:     genreID == "horror",
+		rng:        rng.NewRNG(seed),
+		lastHealth: -1,
+	}
+	d.nextScare = d.scareDelay()
+	return d
+}
+
+// Phase returns the current pacing phase.
+func (d *Director) Phase() Phase {
+	return d.phase
+}
+
+// Intensity returns how hard the player has been pressed lately, from 0
+// upward; the build-up ends when it reaches the budget's PeakIntensity.
+func (d *Director) Intensity() float64 {
+	return d.intensity
+}
+
+// Remaining returns how many reinforcements the budget still allows.
+func (d *Director) Remaining() int {
+	return d.budget.Reinforcements - d.spent
+}
+
+// KillRate returns the player's kills over the last killWindow seconds.
+func (d *Director) KillRate() int {
+	return len(d.kills)
+}
+
+// RNG returns the director's seeded generator. The game draws from it when
+// placing the waves the director schedules, so they replay from the seed.
+func (d *Director) RNG() *rng.RNG {
+	return d.rng
+}
+
+// RecordKill notes that the player killed an enemy.
+func (d *Director) RecordKill() {
+	d.kills = append(d.kills, d.time)
+	d.intensity += killIntensity
+}
+
+// Update advances the director by dt seconds given the player's status and
+// returns what it wants the game to do.
+func (d *Director) Update(dt float64, s Status) []Event {
+	d.time += dt
+	d.phaseTime += dt
+	d.sinceWave += dt
+	d.observe(dt, s)
+
+	var events []Event
+	switch d.phase {
+	case PhaseBuildUp:
+		switch {
+		case d.intensity >= d.budget.PeakIntensity:
+			d.setPhase(PhasePeak)
+		case fraction(s.Health, s.MaxHealth) < mercyHealth:
+			d.setPhase(PhaseLull)
+		case d.sinceWave >= d.budget.WaveInterval:
+			if n := d.waveSize(s); n > 0 {
+				d.spent += n
+				d.sinceWave = 0
+				events = append(events, Event{Kind: EventWave, Count: n})
+			}
+		}
+	case PhasePeak:
+		if d.phaseTime >= d.budget.PeakDuration {
+			d.setPhase(PhaseLull)
+		}
+	case PhaseLull:
+		if d.phaseTime >= d.budget.LullDuration && fraction(s.Health, s.MaxHealth) >= mercyHealth {
+			d.setPhase(PhaseBuildUp)
+			break
+		}
+		if d.scares {
+			d.nextScare -= dt
+			if d.nextScare <= 0 {
+				d.nextScare = d.scareDelay()
+				events = append(events, Event{Kind: EventScare, Scare: scareCues[d.rng.Intn(len(scareCues))]})
+			}
+		}
+	}
+	return events
+}
+
+// observe folds damage taken into the intensity, lets it decay and drops
+// kills that left the kill rate window.
+func (d *Director) observe(dt float64, s Status) {
+	if d.lastHealth >= 0 && s.Health < d.lastHealth && s.MaxHealth > 0 {
+		d.intensity += damageIntensity * float64(d.lastHealth-s.Health) / float64(s.MaxHealth)
+	}
+	d.lastHealth = s.Health
+	d.intensity = math.Max(d.intensity-intensityDecay*dt, 0)
+
+	old := 0
+	for old < len(d.kills) && d.time-d.kills[old] > killWindow {
+		old++
+	}
+	d.kills = d.kills[old:]
+}
+
+// waveSize returns how many reinforcements to send: more for players
+// killing quickly, fewer for those short of health or ammo, within the
+// budget and the cap on living enemies.
+func (d *Director) waveSize(s Status) int {
+	pace := math.Max(0.5, math.Min(float64(len(d.kills))/targetKillRate, 1.5))
+	supplies := math.Min(fraction(s.Health, s.MaxHealth), 0.5+0.5*fraction(s.Ammo, s.MaxAmmo))
+	n := int(math.Round(float64(d.budget.MaxWave) * pace * supplies))
+	n = min(n, d.budget.MaxWave, d.Remaining(), d.budget.MaxEnemies-s.Enemies)
+	// Jitter waves by one so they don't feel metered.
+	if n > 1 && d.rng.Intn(3) == 0 {
+		n--
+	}
+	return max(n, 0)
+}
+
+// setPhase moves to a phase, restarting the phase clock.
+func (d *Director) setPhase(p Phase) {
+	d.phase = p
+	d.phaseTime = 0
+	if p == PhaseBuildUp {
+		d.sinceWave = 0
+	}
+}
+
+// scareDelay returns the seconds until the next scare.
+func (d *Director) scareDelay() float64 {
+	return scareMin + d.rng.Float64()*scareSpread
+}
+
+// fraction returns n/total clamped to [0, 1], or 1 when total is unknown.
+func fraction(n, total int) float64 {
+	if total <= 0 {
+		return 1
+	}
+	return math.Max(0, math.Min(float64(n)/float64(total), 1))
+}
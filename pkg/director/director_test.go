@@ -0,0 +1,178 @@
+package director
+
+import (
+	"reflect"
+	"testing"
+)
+
+// healthy is the status of a player at full health and ammo.
+var healthy = Status{Health: 100, MaxHealth: 100, Ammo: 50, MaxAmmo: 50}
+
+// run updates the director once a second and collects its events.
+func run(d *Director, seconds int, s Status) []Event {
+	var events []Event
+	for i := 0; i < seconds; i++ {
+		events = append(events, d.Update(1, s)...)
+	}
+	return events
+}
+
+func TestBudgetFor(t *testing.T) {
+	tests := []struct {
+		difficulty int
+		want       Budget
+	}{
+		{-1, budgets[0]},
+		{1, budgets[1]},
+		{99, budgets[len(budgets)-1]},
+	}
+	for _, tt := range tests {
+		if got := BudgetFor(tt.difficulty); got != tt.want {
+			t.Errorf("BudgetFor(%d) = %+v, want %+v", tt.difficulty, got, tt.want)
+		}
+	}
+	for i := 1; i < len(budgets); i++ {
+		if budgets[i].Reinforcements <= budgets[i-1].Reinforcements || budgets[i].WaveInterval >= budgets[i-1].WaveInterval {
+			t.Errorf("difficulty %d budget is not harder than difficulty %d", i, i-1)
+		}
+	}
+}
+
+func TestWavesDuringBuildUp(t *testing.T) {
+	d := New(1, "fantasy", 1)
+	events := run(d, int(budgets[1].WaveInterval)-1, healthy)
+	if len(events) != 0 {
+		t.Fatalf("events before the first wave interval = %v, want none", events)
+	}
+	events = run(d, 1, healthy)
+	if len(events) != 1 || events[0].Kind != EventWave || events[0].Count < 1 {
+		t.Fatalf("events = %v, want one wave", events)
+	}
+	if d.Remaining() != budgets[1].Reinforcements-events[0].Count {
+		t.Errorf("Remaining() = %d after a wave of %d", d.Remaining(), events[0].Count)
+	}
+}
+
+func TestWaveLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  Status
+		maxWave int
+	}{
+		{"crowded", Status{Health: 100, MaxHealth: 100, Enemies: budgets[3].MaxEnemies}, 0},
+		{"out of ammo", Status{Health: 100, MaxHealth: 100, Ammo: 0, MaxAmmo: 50}, budgets[3].MaxWave / 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := New(3, "scifi", 5)
+			total := 0
+			for _, e := range run(d, 120, tt.status) {
+				if e.Count > tt.maxWave {
+					t.Errorf("wave of %d, want at most %d", e.Count, tt.maxWave)
+				}
+				total += e.Count
+			}
+			if total > budgets[3].Reinforcements {
+				t.Errorf("sent %d reinforcements, budget %d", total, budgets[3].Reinforcements)
+			}
+		})
+	}
+
+	d := New(0, "fantasy", 2)
+	total := 0
+	for _, e := range run(d, 3600, healthy) {
+		total += e.Count
+	}
+	if total != budgets[0].Reinforcements || d.Remaining() != 0 {
+		t.Errorf("sent %d of a budget of %d over an hour, want all of it", total, budgets[0].Reinforcements)
+	}
+}
+
+func TestPhaseCycle(t *testing.T) {
+	d := New(1, "fantasy", 1)
+	d.Update(1, healthy)
+	// Heavy damage peaks the intensity.
+	d.Update(1, Status{Health: 40, MaxHealth: 100, Ammo: 50, MaxAmmo: 50})
+	if d.Phase() != PhasePeak {
+		t.Fatalf("Phase() = %v after heavy damage, want peak", d.Phase())
+	}
+	run(d, int(budgets[1].PeakDuration), healthy)
+	if d.Phase() != PhaseLull {
+		t.Fatalf("Phase() = %v after the peak, want lull", d.Phase())
+	}
+	if events := run(d, int(budgets[1].LullDuration), healthy); len(events) != 0 {
+		t.Errorf("events during a lull = %v, want none", events)
+	}
+	if d.Phase() != PhaseBuildUp {
+		t.Errorf("Phase() = %v after the lull, want build_up", d.Phase())
+	}
+}
+
+func TestMercyLull(t *testing.T) {
+	d := New(2, "fantasy", 1)
+	hurt := Status{Health: 20, MaxHealth: 100, Ammo: 50, MaxAmmo: 50}
+	d.Update(1, hurt)
+	if d.Phase() != PhaseLull {
+		t.Fatalf("Phase() = %v for a struggling player, want lull", d.Phase())
+	}
+	run(d, int(budgets[2].LullDuration)*2, hurt)
+	if d.Phase() != PhaseLull {
+		t.Errorf("Phase() = %v while the player is still hurt, want lull", d.Phase())
+	}
+}
+
+func TestKillRate(t *testing.T) {
+	d := New(1, "fantasy", 1)
+	for i := 0; i < 3; i++ {
+		d.RecordKill()
+	}
+	if d.KillRate() != 3 || d.Intensity() == 0 {
+		t.Errorf("KillRate(), Intensity() = %d, %v after 3 kills", d.KillRate(), d.Intensity())
+	}
+	run(d, int(killWindow)+1, healthy)
+	if d.KillRate() != 0 {
+		t.Errorf("KillRate() = %d after the window passed, want 0", d.KillRate())
+	}
+}
+
+func TestScares(t *testing.T) {
+	tests := []struct {
+		genre string
+		want  bool
+	}{
+		{"horror", true},
+		{"fantasy", false},
+	}
+	for _, tt := range tests {
+		d := New(1, tt.genre, 3)
+		d.setPhase(PhaseLull)
+		d.budget.LullDuration = 1000
+		scares := 0
+		for _, e := range run(d, 100, healthy) {
+			if e.Kind == EventScare {
+				scares++
+			}
+		}
+		if got := scares > 0; got != tt.want {
+			t.Errorf("%s: %d scares, want any %v", tt.genre, scares, tt.want)
+		}
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	a, b := New(3, "horror", 42), New(3, "horror", 42)
+	statuses := []Status{healthy, {Health: 70, MaxHealth: 100, Ammo: 10, MaxAmmo: 50}, {Health: 90, MaxHealth: 100, Ammo: 40, MaxAmmo: 50}}
+	var gotA, gotB []Event
+	for i := 0; i < 600; i++ {
+		s := statuses[(i/40)%len(statuses)]
+		if i%50 == 0 {
+			a.RecordKill()
+			b.RecordKill()
+		}
+		gotA = append(gotA, a.Update(0.5, s)...)
+		gotB = append(gotB, b.Update(0.5, s)...)
+	}
+	if len(gotA) == 0 || !reflect.DeepEqual(gotA, gotB) {
+		t.Errorf("directors with the same seed diverged: %v vs %v", gotA, gotB)
+	}
+}
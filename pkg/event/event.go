@@ -199,6 +199,152 @@ func (b *BossArenaEvent) GetSpawnDelay() float64 {
 	return b.SpawnDelay
 }
 
+// DefendEvent sends waves of enemies at a position once the player comes
+// to hold it.
+type DefendEvent struct {
+	ID         string
+	X, Y       float64
+	Radius     float64
+	WaveCount  int
+	SpawnDelay float64 // seconds between waves
+	Wave       int     // waves sent so far
+	Triggered  bool
+	timer      float64
+	mu         sync.RWMutex
+}
+
+// NewDefendEvent creates a defend event at x, y, triggered within radius.
+func NewDefendEvent(id string, x, y, radius float64, waveCount int, spawnDelay float64) *DefendEvent {
+	return &DefendEvent{
+		ID:         id,
+		X:          x,
+		Y:          y,
+		Radius:     radius,
+		WaveCount:  waveCount,
+		SpawnDelay: spawnDelay,
+	}
+}
+
+// Update advances the event with the player at px, py and returns true
+// when the next wave is due: at once on trigger, then every SpawnDelay.
+func (d *DefendEvent) Update(deltaTime, px, py float64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Wave >= d.WaveCount {
+		return false
+	}
+	if !d.Triggered {
+		if (px-d.X)*(px-d.X)+(py-d.Y)*(py-d.Y) > d.Radius*d.Radius {
+			return false
+		}
+		d.Triggered = true
+	} else if d.timer -= deltaTime; d.timer > 0 {
+		return false
+	}
+	d.Wave++
+	d.timer = d.SpawnDelay
+	return true
+}
+
+// IsTriggered returns whether the player has come to hold the position.
+func (d *DefendEvent) IsTriggered() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.Triggered
+}
+
+// AllSent returns whether every wave has been sent.
+func (d *DefendEvent) AllSent() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.Wave >= d.WaveCount
+}
+
+// ExtractionEvent is a countdown to reach an extraction point once
+// activated, e.g. from a beacon.
+type ExtractionEvent struct {
+	ID           string
+	X, Y         float64 // Extraction point
+	Radius       float64
+	CountdownS   float64 // seconds
+	Remaining    float64
+	Active       bool
+	Extracted    bool
+	wasActivated bool
+	mu           sync.RWMutex
+}
+
+// NewExtractionEvent creates an extraction to x, y, reached within radius.
+func NewExtractionEvent(id string, x, y, radius, countdownS float64) *ExtractionEvent {
+	return &ExtractionEvent{
+		ID:         id,
+		X:          x,
+		Y:          y,
+		Radius:     radius,
+		CountdownS: countdownS,
+	}
+}
+
+// Activate starts the countdown. An extraction only runs once.
+func (e *ExtractionEvent) Activate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.wasActivated {
+		return
+	}
+	e.Active = true
+	e.wasActivated = true
+	e.Remaining = e.CountdownS
+}
+
+// Update decrements the countdown, ending it when the player at px, py
+// reaches the extraction point or the time runs out.
+func (e *ExtractionEvent) Update(deltaTime, px, py float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.Active {
+		return
+	}
+	if (px-e.X)*(px-e.X)+(py-e.Y)*(py-e.Y) <= e.Radius*e.Radius {
+		e.Active = false
+		e.Extracted = true
+		return
+	}
+	e.Remaining -= deltaTime
+	if e.Remaining <= 0 {
+		e.Active = false
+		e.Remaining = 0
+	}
+}
+
+// IsActive returns whether the countdown is running.
+func (e *ExtractionEvent) IsActive() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.Active
+}
+
+// GetRemaining returns remaining time in seconds.
+func (e *ExtractionEvent) GetRemaining() float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.Remaining
+}
+
+// IsExtracted returns true if the player reached the extraction point in time.
+func (e *ExtractionEvent) IsExtracted() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.Extracted
+}
+
+// IsExpired returns true if the countdown ran out before the player got out.
+func (e *ExtractionEvent) IsExpired() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.wasActivated && !e.Active && !e.Extracted
+}
+
 // Fire dispatches an event by ID.
 func Fire(eventID string) {}
 
@@ -184,6 +184,65 @@ func TestBossArenaEvent_Trigger(t *testing.T) {
 	}
 }
 
+// TestDefendEvent_Waves verifies waves start on arrival and keep coming.
+func TestDefendEvent_Waves(t *testing.T) {
+	d := NewDefendEvent("defend1", 10, 10, 2, 3, 5)
+
+	if d.Update(1, 20, 20) || d.IsTriggered() {
+		t.Fatal("Defend event should wait for the player")
+	}
+	if !d.Update(1, 11, 10) || d.Wave != 1 {
+		t.Fatalf("First wave should come on arrival, wave = %d", d.Wave)
+	}
+	if d.Update(4, 11, 10) {
+		t.Error("Second wave came before the spawn delay")
+	}
+	if !d.Update(1, 30, 30) || d.Wave != 2 {
+		t.Errorf("Second wave should come after the delay, wave = %d", d.Wave)
+	}
+	d.Update(5, 30, 30)
+	if !d.AllSent() || d.Update(5, 30, 30) {
+		t.Error("Defend event sent more waves than its count")
+	}
+}
+
+// TestExtractionEvent_Lifecycle verifies reaching the point in time.
+func TestExtractionEvent_Lifecycle(t *testing.T) {
+	e := NewExtractionEvent("extract1", 10, 10, 1, 30)
+	e.Update(1, 10, 10)
+	if e.IsExtracted() || e.IsExpired() {
+		t.Fatal("Extraction should not end before activation")
+	}
+
+	e.Activate()
+	e.Update(10, 0, 0)
+	if !e.IsActive() || e.GetRemaining() != 20 {
+		t.Errorf("Remaining should be 20, got %f", e.GetRemaining())
+	}
+	e.Update(1, 10.5, 10)
+	if !e.IsExtracted() || e.IsActive() || e.IsExpired() {
+		t.Error("Reaching the point should extract the player")
+	}
+	e.Activate()
+	if e.IsActive() {
+		t.Error("Extraction ran a second time")
+	}
+}
+
+// TestExtractionEvent_Expired verifies the countdown running out.
+func TestExtractionEvent_Expired(t *testing.T) {
+	e := NewExtractionEvent("extract1", 10, 10, 1, 5)
+	e.Activate()
+	e.Update(6, 0, 0)
+	if !e.IsExpired() || e.IsExtracted() || e.GetRemaining() != 0 {
+		t.Error("Extraction should expire when the countdown runs out")
+	}
+	e.Update(1, 10, 10)
+	if e.IsExtracted() {
+		t.Error("Reaching the point after the countdown should not extract")
+	}
+}
+
 // TestBossArenaEvent_Concurrent verifies thread-safe operations.
 func TestBossArenaEvent_Concurrent(t *testing.T) {
 	boss := NewBossArenaEvent("boss1", "room_5", 3, 2.5)
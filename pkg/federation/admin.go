@@ -0,0 +1,233 @@
+package federation
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrKeyBanned is returned for an announcement signed by a banned key.
+	ErrKeyBanned = errors.New("announcement key is banned")
+
+	// ErrAddressBanned is returned for an announcement whose address is in
+	// a banned network.
+	ErrAddressBanned = errors.New("server address is banned")
+)
+
+// BanList is the set of keys and networks a hub refuses announcements
+// from.
+type BanList struct {
+	Keys     []string `json:"keys"`     // Base64 server public keys
+	Networks []string `json:"networks"` // CIDR prefixes
+}
+
+// Delist removes a server from the registry straight away, as if it had
+// expired. It reports whether the server was registered. A delisted server
+// that keeps announcing comes back unless it is also banned.
+func (h *FederationHub) Delist(name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	server, ok := h.servers[name]
+	if !ok {
+		return false
+	}
+	h.removeServer(server)
+	return true
+}
+
+// removeServer drops a server and its player mappings from the registry
+// and the store, and tells subscribers it expired (must be called with
+// lock held).
+func (h *FederationHub) removeServer(server *ServerAnnouncement) {
+	removePlayerMappings(h.playerIndex, server.PlayerList)
+	delete(h.servers, server.Name)
+	h.unpersist(server.Name)
+	h.publishExpired(server)
+}
+
+// BanKey refuses further announcements signed by a base64 public key and
+// delists the servers using it. It returns how many were delisted.
+func (h *FederationHub) BanKey(key string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return 0, fmt.Errorf("invalid public key %q", key)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bannedKeys[key] = true
+	return h.delistBanned(), nil
+}
+
+// UnbanKey lifts a key ban, reporting whether the key was banned.
+func (h *FederationHub) UnbanKey(key string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	banned := h.bannedKeys[key]
+	delete(h.bannedKeys, key)
+	return banned
+}
+
+// BanNetwork refuses further announcements for addresses in a CIDR prefix,
+// or a single IP, and delists the servers there. It returns how many were
+// delisted.
+func (h *FederationHub) BanNetwork(cidr string) (int, error) {
+	network, err := parseNetwork(cidr)
+	if err != nil {
+		return 0, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, banned := range h.bannedNetworks {
+		if banned.String() == network.String() {
+			return h.delistBanned(), nil
+		}
+	}
+	h.bannedNetworks = append(h.bannedNetworks, network)
+	return h.delistBanned(), nil
+}
+
+// UnbanNetwork lifts a network ban, reporting whether it was banned.
+func (h *FederationHub) UnbanNetwork(cidr string) bool {
+	network, err := parseNetwork(cidr)
+	if err != nil {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, banned := range h.bannedNetworks {
+		if banned.String() == network.String() {
+			h.bannedNetworks = append(h.bannedNetworks[:i], h.bannedNetworks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// IsBannedIP reports whether an IP is in a banned network, so the hub's
+// HTTP front end can turn away clients from it.
+func (h *FederationHub) IsBannedIP(ip net.IP) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.bannedIP(ip)
+}
+
+// bannedIP reports whether an IP is in a banned network (must be called
+// with lock held).
+func (h *FederationHub) bannedIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range h.bannedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bans returns the hub's current bans.
+func (h *FederationHub) Bans() BanList {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	bans := BanList{Keys: []string{}, Networks: []string{}}
+	for key := range h.bannedKeys {
+		bans.Keys = append(bans.Keys, key)
+	}
+	sort.Strings(bans.Keys)
+	for _, network := range h.bannedNetworks {
+		bans.Networks = append(bans.Networks, network.String())
+	}
+	return bans
+}
+
+// SetBans replaces the hub's bans, such as with a list saved by an earlier
+// run, and delists servers that are now banned.
+func (h *FederationHub) SetBans(bans BanList) error {
+	keys := make(map[string]bool, len(bans.Keys))
+	for _, key := range bans.Keys {
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid public key %q", key)
+		}
+		keys[key] = true
+	}
+	networks := make([]*net.IPNet, 0, len(bans.Networks))
+	for _, cidr := range bans.Networks {
+		network, err := parseNetwork(cidr)
+		if err != nil {
+			return err
+		}
+		networks = append(networks, network)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.bannedKeys = keys
+	h.bannedNetworks = networks
+	h.delistBanned()
+	return nil
+}
+
+// checkBans rejects an announcement from a banned key or address (must be
+// called with lock held).
+func (h *FederationHub) checkBans(a *ServerAnnouncement) error {
+	if len(a.PublicKey) > 0 && h.bannedKeys[base64.StdEncoding.EncodeToString(a.PublicKey)] {
+		return ErrKeyBanned
+	}
+	if h.bannedIP(addressIP(a.Address)) || h.bannedIP(net.ParseIP(a.SourceIP)) {
+		return ErrAddressBanned
+	}
+	return nil
+}
+
+// delistBanned removes every registered server that is now banned and
+// returns how many it removed (must be called with lock held).
+func (h *FederationHub) delistBanned() int {
+	removed := 0
+	for _, server := range h.servers {
+		if err := h.checkBans(server); err != nil {
+			logrus.WithError(err).WithField("server_name", server.Name).Info("delisted banned server")
+			h.removeServer(server)
+			removed++
+		}
+	}
+	return removed
+}
+
+// parseNetwork parses a CIDR prefix, or a single IP as a prefix of one
+// address.
+func parseNetwork(cidr string) (*net.IPNet, error) {
+	cidr = strings.TrimSpace(cidr)
+	if ip := net.ParseIP(cidr); ip != nil {
+		bits := 128
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network %q", cidr)
+	}
+	return network, nil
+}
+
+// addressIP returns the IP in a server's host:port address, or nil if the
+// host is a name rather than an IP.
+func addressIP(address string) net.IP {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	return net.ParseIP(host)
+}
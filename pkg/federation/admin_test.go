@@ -0,0 +1,138 @@
+package federation
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFederationHub_Delist(t *testing.T) {
+	hub := NewFederationHub()
+	hub.RegisterServer(&ServerAnnouncement{Name: "s1", PlayerList: []string{"p1"}, Timestamp: time.Now()})
+	events, cancel := hub.Subscribe(&ServerQuery{})
+	defer cancel()
+	<-events // Snapshot
+
+	if !hub.Delist("s1") {
+		t.Fatal("Delist(s1) = false")
+	}
+	if hub.Delist("s1") {
+		t.Error("second Delist(s1) = true")
+	}
+	if hub.GetServerCount() != 0 || hub.lookupPlayer("p1", "").Online {
+		t.Error("delisted server is still registered")
+	}
+	if event := <-events; event.Type != EventExpired || event.Server.Name != "s1" {
+		t.Errorf("event = %+v, want s1 expired", event)
+	}
+}
+
+func TestFederationHub_BanKey(t *testing.T) {
+	hub := NewFederationHub()
+	id, _ := NewServerIdentity()
+	if err := hub.Announce(signedAnnouncement(t, id, "s1")); err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+
+	if _, err := hub.BanKey("not-a-key"); err == nil {
+		t.Error("BanKey accepted a malformed key")
+	}
+	removed, err := hub.BanKey(id.PublicKey())
+	if err != nil || removed != 1 {
+		t.Fatalf("BanKey = %d, %v; want 1 delisted", removed, err)
+	}
+	if err := hub.Announce(signedAnnouncement(t, id, "s2")); !errors.Is(err, ErrKeyBanned) {
+		t.Errorf("banned key: err = %v", err)
+	}
+	if bans := hub.Bans(); len(bans.Keys) != 1 || bans.Keys[0] != id.PublicKey() {
+		t.Errorf("bans = %+v", bans)
+	}
+
+	if !hub.UnbanKey(id.PublicKey()) {
+		t.Error("UnbanKey = false")
+	}
+	if err := hub.Announce(signedAnnouncement(t, id, "s2")); err != nil {
+		t.Errorf("after unban: %v", err)
+	}
+}
+
+func TestFederationHub_BanNetwork(t *testing.T) {
+	hub := NewFederationHub()
+	for _, a := range []*ServerAnnouncement{
+		{Name: "inside", Address: "203.0.113.9:7777"},
+		{Name: "outside", Address: "198.51.100.1:7777"},
+		{Name: "hostname", Address: "game.example.com:7777"},
+	} {
+		if err := hub.Announce(a); err != nil {
+			t.Fatalf("Announce(%s) failed: %v", a.Name, err)
+		}
+	}
+
+	if _, err := hub.BanNetwork("nonsense"); err == nil {
+		t.Error("BanNetwork accepted a malformed network")
+	}
+	removed, err := hub.BanNetwork("203.0.113.0/24")
+	if err != nil || removed != 1 {
+		t.Fatalf("BanNetwork = %d, %v; want 1 delisted", removed, err)
+	}
+	if err := hub.Announce(&ServerAnnouncement{Name: "again", Address: "203.0.113.10:7777"}); !errors.Is(err, ErrAddressBanned) {
+		t.Errorf("banned address: err = %v", err)
+	}
+	if !hub.IsBannedIP(net.ParseIP("203.0.113.200")) || hub.IsBannedIP(net.ParseIP("198.51.100.1")) {
+		t.Error("IsBannedIP disagrees with the ban")
+	}
+
+	// A bare IP bans just that address
+	if _, err := hub.BanNetwork("198.51.100.1"); err != nil {
+		t.Fatalf("BanNetwork(ip) failed: %v", err)
+	}
+	if bans := hub.Bans(); len(bans.Networks) != 2 || bans.Networks[1] != "198.51.100.1/32" {
+		t.Errorf("networks = %v", bans.Networks)
+	}
+	if hub.GetServerCount() != 1 {
+		t.Errorf("servers = %d, want only the hostname server", hub.GetServerCount())
+	}
+
+	if !hub.UnbanNetwork("203.0.113.0/24") || hub.UnbanNetwork("203.0.113.0/24") {
+		t.Error("UnbanNetwork should succeed once")
+	}
+}
+
+func TestFederationHub_BanSourceIP(t *testing.T) {
+	hub := NewFederationHub()
+	forged := &ServerAnnouncement{Name: "forged", Address: "game.example.com:7777", SourceIP: "192.0.2.1"}
+	if err := hub.Announce(forged); err != nil || forged.SourceIP != "" {
+		t.Fatalf("Announce kept a client-supplied source IP %q (err %v)", forged.SourceIP, err)
+	}
+	if err := hub.AnnounceFrom(&ServerAnnouncement{Name: "named", Address: "game.example.com:7777"}, net.ParseIP("203.0.113.9")); err != nil {
+		t.Fatalf("AnnounceFrom failed: %v", err)
+	}
+
+	removed, err := hub.BanNetwork("203.0.113.9")
+	if err != nil || removed != 1 {
+		t.Fatalf("BanNetwork = %d, %v; want the hostname server delisted", removed, err)
+	}
+	again := &ServerAnnouncement{Name: "again", Address: "other.example.com:7777"}
+	if err := hub.AnnounceFrom(again, net.ParseIP("203.0.113.9")); !errors.Is(err, ErrAddressBanned) {
+		t.Errorf("banned source: err = %v", err)
+	}
+	if redacted := (&ServerAnnouncement{SourceIP: "203.0.113.9"}).Redacted(); redacted.SourceIP != "" {
+		t.Error("Redacted() kept the source IP")
+	}
+}
+
+func TestFederationHub_SetBans(t *testing.T) {
+	hub := NewFederationHub()
+	hub.RegisterServer(&ServerAnnouncement{Name: "s1", Address: "10.0.0.5:7777", Timestamp: time.Now()})
+
+	if err := hub.SetBans(BanList{Networks: []string{"10.0.0.0/8"}}); err != nil {
+		t.Fatalf("SetBans failed: %v", err)
+	}
+	if hub.GetServerCount() != 0 {
+		t.Error("SetBans left a banned server listed")
+	}
+	if err := hub.SetBans(BanList{Keys: []string{"bad"}}); err == nil {
+		t.Error("SetBans accepted a malformed key")
+	}
+}
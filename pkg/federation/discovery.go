@@ -30,22 +30,37 @@ const (
 
 // ServerAnnouncement is sent from game servers to the federation hub.
 type ServerAnnouncement struct {
-	Name       string    `json:"name"`
-	Address    string    `json:"address"`
-	Region     Region    `json:"region"`
-	Genre      string    `json:"genre"`
-	Players    int       `json:"players"`
-	MaxPlayers int       `json:"maxPlayers"`
-	PlayerList []string  `json:"playerList,omitempty"` // List of player IDs currently on this server
-	Timestamp  time.Time `json:"timestamp"`
+	Name           string                    `json:"name"`
+	Address        string                    `json:"address"`
+	Mode           string                    `json:"mode,omitempty"` // Game mode ID, such as "coop" or "ffa"
+	Region         Region                    `json:"region"`
+	RegionInferred bool                      `json:"regionInferred,omitempty"` // Set by the hub when it inferred Region from Address
+	Genre          string                    `json:"genre"`
+	Players        int                       `json:"players"`
+	MaxPlayers     int                       `json:"maxPlayers"`
+	PlayerList     []string                  `json:"playerList,omitempty"` // List of player IDs currently on this server
+	Presence       map[string]PlayerPresence `json:"presence,omitempty"`   // Privacy settings by player ID; players without one are public
+	PublicKey      []byte                    `json:"publicKey,omitempty"`  // Ed25519 key the announcement is signed with
+	SignedAt       int64                     `json:"signedAt,omitempty"`   // Unix time the server signed the announcement
+	Signature      []byte                    `json:"signature,omitempty"`
+	Verified       bool                      `json:"verified,omitempty"` // Set by the hub once the signature checks out
+	SourceIP       string                    `json:"sourceIP,omitempty"` // Set by the hub to the IP the announcement came from
+	Timestamp      time.Time                 `json:"timestamp"`
 }
 
 // ServerQuery specifies filtering criteria for server discovery.
 type ServerQuery struct {
 	Region     *Region `json:"region,omitempty"`
+	Mode       *string `json:"mode,omitempty"`
 	Genre      *string `json:"genre,omitempty"`
 	MinPlayers *int    `json:"minPlayers,omitempty"`
 	MaxPlayers *int    `json:"maxPlayers,omitempty"`
+
+	// Latency-aware fields. MaxPing (milliseconds) only filters when the
+	// client's region is known, reported here or inferred by the hub.
+	ClientRegion  *Region  `json:"clientRegion,omitempty"`
+	MaxPing       *int     `json:"maxPing,omitempty"`
+	PreferRegions []Region `json:"preferRegions,omitempty"` // Ranked first by NearestServers
 }
 
 // FederationHub manages server announcements and client queries.
@@ -59,6 +74,15 @@ type FederationHub struct {
 	ctx             context.Context
 	cancel          context.CancelFunc
 	httpServer      *http.Server
+	rendezvous      *rendezvous
+	store           RegistryStore
+	requireSigned   bool
+	allowedKeys     map[string]bool // Base64 public keys; empty allows any
+	resolver        RegionResolver
+	subscribers     map[*subscriber]bool
+	gossipToken     string          // Bearer token peers gossip with; empty trusts no caller
+	bannedKeys      map[string]bool // Base64 public keys
+	bannedNetworks  []*net.IPNet
 }
 
 // NewFederationHub creates a new federation hub.
@@ -67,6 +91,8 @@ func NewFederationHub() *FederationHub {
 	return &FederationHub{
 		servers:         make(map[string]*ServerAnnouncement),
 		playerIndex:     make(map[string]string),
+		subscribers:     make(map[*subscriber]bool),
+		bannedKeys:      make(map[string]bool),
 		upgrader:        websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
 		staleTimeout:    30 * time.Second,
 		cleanupInterval: 10 * time.Second,
@@ -81,6 +107,9 @@ func (h *FederationHub) Start(addr string) error {
 	mux.HandleFunc("/announce", h.handleAnnounce)
 	mux.HandleFunc("/query", h.handleQuery)
 	mux.HandleFunc("/lookup", h.handleLookup)
+	mux.HandleFunc("/nearest", h.handleNearest)
+	mux.HandleFunc("/subscribe", h.ServeSubscribe)
+	mux.HandleFunc("/gossip", h.ServeGossip)
 
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -114,6 +143,15 @@ func (h *FederationHub) GetAddr() string {
 // Stop gracefully shuts down the federation hub.
 func (h *FederationHub) Stop() error {
 	h.cancel()
+	h.dropAllSubscribers()
+	h.mu.Lock()
+	if h.store != nil {
+		if err := h.store.Close(); err != nil {
+			logrus.WithError(err).Warn("failed to close registry store")
+		}
+		h.store = nil
+	}
+	h.mu.Unlock()
 	if h.httpServer != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -144,14 +182,17 @@ func (h *FederationHub) handleAnnounce(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		announcement.Timestamp = time.Now()
-		h.registerServer(&announcement)
+		if err := h.AnnounceFrom(&announcement, remoteIP(r)); err != nil {
+			logrus.WithError(err).WithField("server_name", announcement.Name).Warn("rejected announcement")
+			continue
+		}
 
 		logrus.WithFields(logrus.Fields{
 			"server_name": announcement.Name,
 			"region":      announcement.Region,
 			"genre":       announcement.Genre,
 			"players":     fmt.Sprintf("%d/%d", announcement.Players, announcement.MaxPlayers),
+			"verified":    announcement.Verified,
 		}).Debug("server announced")
 	}
 }
@@ -170,14 +211,19 @@ func (h *FederationHub) handleQuery(w http.ResponseWriter, r *http.Request) {
 	}
 
 	servers := h.queryServers(&query)
+	redacted := make([]*ServerAnnouncement, len(servers))
+	for i, server := range servers {
+		redacted[i] = server.Redacted()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(servers)
+	json.NewEncoder(w).Encode(redacted)
 }
 
 // PlayerLookupRequest represents a player lookup query.
 type PlayerLookupRequest struct {
-	PlayerID string `json:"playerID"`
+	PlayerID    string `json:"playerID"`
+	FriendToken string `json:"friendToken,omitempty"` // Needed to find friends-only players
 }
 
 // PlayerLookupResponse contains the server address if player is online.
@@ -205,14 +251,15 @@ func (h *FederationHub) handleLookup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := h.lookupPlayer(req.PlayerID)
+	response := h.FindPlayer(&req)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// lookupPlayer finds a player across all federated servers.
-func (h *FederationHub) lookupPlayer(playerID string) PlayerLookupResponse {
+// lookupPlayer finds a player across all federated servers, if their
+// presence settings let a caller holding friendToken see them.
+func (h *FederationHub) lookupPlayer(playerID, friendToken string) PlayerLookupResponse {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
@@ -222,7 +269,7 @@ func (h *FederationHub) lookupPlayer(playerID string) PlayerLookupResponse {
 	}
 
 	server, exists := h.servers[serverName]
-	if !exists {
+	if !exists || !server.Presence[playerID].visibleTo(friendToken) {
 		return PlayerLookupResponse{Online: false}
 	}
 
@@ -237,9 +284,16 @@ func (h *FederationHub) lookupPlayer(playerID string) PlayerLookupResponse {
 func (h *FederationHub) registerServer(announcement *ServerAnnouncement) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.addServer(announcement)
+	h.persist(announcement)
+}
 
+// addServer indexes an announcement in memory (must be called with lock
+// held).
+func (h *FederationHub) addServer(announcement *ServerAnnouncement) {
 	// Remove old player mappings for this server
-	if oldAnnouncement, exists := h.servers[announcement.Name]; exists {
+	oldAnnouncement, exists := h.servers[announcement.Name]
+	if exists {
 		for _, playerID := range oldAnnouncement.PlayerList {
 			delete(h.playerIndex, playerID)
 		}
@@ -251,9 +305,12 @@ func (h *FederationHub) registerServer(announcement *ServerAnnouncement) {
 	}
 
 	h.servers[announcement.Name] = announcement
+	h.publishRegistered(oldAnnouncement, announcement)
 }
 
-// RegisterServer adds or updates a server announcement (public API).
+// RegisterServer adds or updates a server announcement (public API). It
+// trusts the announcement as given; use Announce for announcements
+// straight from game servers.
 func (h *FederationHub) RegisterServer(announcement *ServerAnnouncement) {
 	h.registerServer(announcement)
 }
@@ -277,6 +334,9 @@ func (h *FederationHub) matchesQuery(server *ServerAnnouncement, query *ServerQu
 	if query.Region != nil && server.Region != *query.Region {
 		return false
 	}
+	if query.Mode != nil && server.Mode != *query.Mode {
+		return false
+	}
 	if query.Genre != nil && server.Genre != *query.Genre {
 		return false
 	}
@@ -286,6 +346,10 @@ func (h *FederationHub) matchesQuery(server *ServerAnnouncement, query *ServerQu
 	if query.MaxPlayers != nil && server.Players > *query.MaxPlayers {
 		return false
 	}
+	if query.MaxPing != nil && query.ClientRegion != nil && *query.ClientRegion != RegionUnknown && *query.ClientRegion != "" &&
+		EstimateLatency(*query.ClientRegion, server.Region) > time.Duration(*query.MaxPing)*time.Millisecond {
+		return false
+	}
 	return true
 }
 
@@ -312,8 +376,7 @@ func (h *FederationHub) removeStaleServerEntries() {
 	now := time.Now()
 	for name, server := range h.servers {
 		if now.Sub(server.Timestamp) > h.staleTimeout {
-			removePlayerMappings(h.playerIndex, server.PlayerList)
-			delete(h.servers, name)
+			h.removeServer(server)
 			logrus.WithField("server_name", name).Debug("removed stale server")
 		}
 	}
@@ -357,6 +420,7 @@ type ServerAnnouncer struct {
 	hubURL       string
 	conn         *websocket.Conn
 	announcement ServerAnnouncement
+	identity     *ServerIdentity
 	interval     time.Duration
 	ctx          context.Context
 	cancel       context.CancelFunc
@@ -382,6 +446,14 @@ func (a *ServerAnnouncer) SetInterval(interval time.Duration) {
 	a.interval = interval
 }
 
+// SetIdentity signs every announcement with the server's key, so hubs
+// can verify it.
+func (a *ServerAnnouncer) SetIdentity(id *ServerIdentity) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.identity = id
+}
+
 // Start begins announcing to the federation hub.
 func (a *ServerAnnouncer) Start() error {
 	conn, _, err := websocket.DefaultDialer.Dial(a.hubURL, nil)
@@ -420,6 +492,14 @@ func (a *ServerAnnouncer) UpdatePlayerList(playerIDs []string) {
 	a.announcement.Players = len(playerIDs)
 }
 
+// UpdatePlayerPresence updates the privacy settings of the players on this
+// server, keyed by player ID. Players left out are public.
+func (a *ServerAnnouncer) UpdatePlayerPresence(presence map[string]PlayerPresence) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.announcement.Presence = presence
+}
+
 // announceLoop sends periodic announcements.
 func (a *ServerAnnouncer) announceLoop() {
 	ticker := time.NewTicker(a.interval)
@@ -447,7 +527,15 @@ func (a *ServerAnnouncer) sendAnnouncement() {
 		return
 	}
 
-	data, err := json.Marshal(a.announcement)
+	announcement := a.announcement
+	if a.identity != nil {
+		if err := a.identity.Sign(&announcement); err != nil {
+			logrus.WithError(err).Error("failed to sign announcement")
+			return
+		}
+	}
+
+	data, err := json.Marshal(announcement)
 	if err != nil {
 		logrus.WithError(err).Error("failed to marshal announcement")
 		return
@@ -492,13 +580,20 @@ func DiscoverServers(hubURL string, query *ServerQuery, timeout time.Duration) (
 
 // LookupPlayer queries a remote federation hub for player presence.
 func LookupPlayer(hubURL, playerID string, timeout time.Duration) (*PlayerLookupResponse, error) {
+	return LookupFriend(hubURL, playerID, "", timeout)
+}
+
+// LookupFriend queries a remote federation hub for the presence of a
+// player who may be visible to friends only, using the friend token they
+// gave the caller.
+func LookupFriend(hubURL, playerID, friendToken string, timeout time.Duration) (*PlayerLookupResponse, error) {
 	if timeout == 0 {
 		timeout = 5 * time.Second
 	}
 
 	client := &http.Client{Timeout: timeout}
 
-	req := PlayerLookupRequest{PlayerID: playerID}
+	req := PlayerLookupRequest{PlayerID: playerID, FriendToken: friendToken}
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
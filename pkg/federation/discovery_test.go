@@ -183,6 +183,7 @@ func TestFederationHub_MatchesQuery(t *testing.T) {
 
 	server := &ServerAnnouncement{
 		Name:       "test",
+		Mode:       "ffa",
 		Region:     RegionUSEast,
 		Genre:      "scifi",
 		Players:    8,
@@ -213,6 +214,20 @@ func TestFederationHub_MatchesQuery(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "matching mode",
+			query: ServerQuery{
+				Mode: ptrString("ffa"),
+			},
+			want: true,
+		},
+		{
+			name: "non-matching mode",
+			query: ServerQuery{
+				Mode: ptrString("coop"),
+			},
+			want: false,
+		},
 		{
 			name: "matching genre",
 			query: ServerQuery{
@@ -599,7 +614,7 @@ func TestFederationHub_PlayerLookup(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			response := hub.lookupPlayer(tt.playerID)
+			response := hub.lookupPlayer(tt.playerID, "")
 			if response.Online != tt.wantOnline {
 				t.Errorf("Online = %v, want %v", response.Online, tt.wantOnline)
 			}
@@ -0,0 +1,283 @@
+package federation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRegionLatency is the estimated round trip to or from a server in
+// an unknown region.
+const DefaultRegionLatency = 150 * time.Millisecond
+
+// regionLatency is the typical round trip in milliseconds between regions,
+// listed once per pair. Within a region it is sameRegionLatency.
+var regionLatency = map[[2]Region]int{
+	{RegionUSEast, RegionUSWest}:   70,
+	{RegionUSEast, RegionEUWest}:   80,
+	{RegionUSEast, RegionEUEast}:   110,
+	{RegionUSEast, RegionAsiaPac}:  200,
+	{RegionUSEast, RegionSouthAm}:  120,
+	{RegionUSWest, RegionEUWest}:   140,
+	{RegionUSWest, RegionEUEast}:   170,
+	{RegionUSWest, RegionAsiaPac}:  120,
+	{RegionUSWest, RegionSouthAm}:  170,
+	{RegionEUWest, RegionEUEast}:   40,
+	{RegionEUWest, RegionAsiaPac}:  220,
+	{RegionEUWest, RegionSouthAm}:  190,
+	{RegionEUEast, RegionAsiaPac}:  180,
+	{RegionEUEast, RegionSouthAm}:  220,
+	{RegionAsiaPac, RegionSouthAm}: 300,
+}
+
+const sameRegionLatency = 20
+
+// EstimateLatency returns the typical round trip between two regions, or
+// DefaultRegionLatency if either is unknown.
+func EstimateLatency(from, to Region) time.Duration {
+	if from == to && from != RegionUnknown && from != "" {
+		return sameRegionLatency * time.Millisecond
+	}
+	if ms, ok := regionLatency[[2]Region{from, to}]; ok {
+		return time.Duration(ms) * time.Millisecond
+	}
+	if ms, ok := regionLatency[[2]Region{to, from}]; ok {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return DefaultRegionLatency
+}
+
+// RegionResolver infers a region from an IP address, returning
+// RegionUnknown when it can't.
+type RegionResolver interface {
+	Resolve(ip net.IP) Region
+}
+
+// PrefixResolver is a RegionResolver backed by a table of network
+// prefixes, such as one exported from a GeoIP database. The longest
+// matching prefix wins. It is safe for concurrent use.
+type PrefixResolver struct {
+	mu       sync.RWMutex
+	prefixes []regionPrefix // Longest first
+}
+
+// regionPrefix maps one network prefix to a region.
+type regionPrefix struct {
+	network *net.IPNet
+	region  Region
+}
+
+// NewPrefixResolver creates an empty prefix resolver.
+func NewPrefixResolver() *PrefixResolver {
+	return &PrefixResolver{}
+}
+
+// LoadPrefixResolver reads a prefix table from a file of "cidr,region"
+// lines. Blank lines and lines starting with # are skipped.
+func LoadPrefixResolver(path string) (*PrefixResolver, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := NewPrefixResolver()
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		cidr, region, ok := strings.Cut(text, ",")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: want cidr,region", path, line)
+		}
+		if err := r.Add(strings.TrimSpace(cidr), Region(strings.TrimSpace(region))); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Add maps a network prefix to a region.
+func (r *PrefixResolver) Add(cidr string, region Region) error {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("invalid prefix: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prefixes = append(r.prefixes, regionPrefix{network: network, region: region})
+	sort.SliceStable(r.prefixes, func(i, j int) bool {
+		a, _ := r.prefixes[i].network.Mask.Size()
+		b, _ := r.prefixes[j].network.Mask.Size()
+		return a > b
+	})
+	return nil
+}
+
+// Resolve returns the region of the longest prefix containing ip.
+func (r *PrefixResolver) Resolve(ip net.IP) Region {
+	if ip == nil {
+		return RegionUnknown
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.prefixes {
+		if p.network.Contains(ip) {
+			return p.region
+		}
+	}
+	return RegionUnknown
+}
+
+// RankedServer is a server with its estimated round trip from the client
+// that asked.
+type RankedServer struct {
+	ServerAnnouncement
+	EstimatedPing int `json:"estimatedPing"` // Milliseconds
+}
+
+// SetRegionResolver lets the hub infer the regions of clients, and of
+// servers that announce without one, from their IP addresses.
+func (h *FederationHub) SetRegionResolver(r RegionResolver) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resolver = r
+}
+
+// ResolveRegion infers the region of an IP address, or returns
+// RegionUnknown if the hub has no resolver.
+func (h *FederationHub) ResolveRegion(ip net.IP) Region {
+	h.mu.RLock()
+	resolver := h.resolver
+	h.mu.RUnlock()
+	if resolver == nil {
+		return RegionUnknown
+	}
+	return resolver.Resolve(ip)
+}
+
+// inferRegion fills in the region of a server that announced without one
+// from the IP in its address (must be called with lock held).
+func (h *FederationHub) inferRegion(a *ServerAnnouncement) {
+	if h.resolver == nil || (a.Region != "" && a.Region != RegionUnknown) {
+		return
+	}
+	if ip := addressIP(a.Address); ip != nil {
+		a.Region = h.resolver.Resolve(ip)
+		a.RegionInferred = true
+	}
+}
+
+// NearestServers returns the servers matching the query, redacted for
+// clients and sorted by their estimated round trip from the client:
+// servers in one of the query's preferred regions first, then lowest ping,
+// then by name. The client's region is the query's ClientRegion if it
+// reports one, or else inferred from clientIP.
+func (h *FederationHub) NearestServers(query *ServerQuery, clientIP net.IP) []RankedServer {
+	var client Region
+	if query.ClientRegion != nil && *query.ClientRegion != "" {
+		client = *query.ClientRegion
+	} else {
+		client = h.ResolveRegion(clientIP)
+	}
+	located := *query
+	located.ClientRegion = &client
+
+	servers := h.queryServers(&located)
+	ranked := make([]RankedServer, len(servers))
+	for i, s := range servers {
+		ranked[i] = RankedServer{
+			ServerAnnouncement: *s.Redacted(),
+			EstimatedPing:      int(EstimateLatency(client, s.Region).Milliseconds()),
+		}
+	}
+
+	preferred := make(map[Region]bool, len(query.PreferRegions))
+	for _, r := range query.PreferRegions {
+		preferred[r] = true
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := &ranked[i], &ranked[j]
+		if pa, pb := preferred[a.Region], preferred[b.Region]; pa != pb {
+			return pa
+		}
+		if a.EstimatedPing != b.EstimatedPing {
+			return a.EstimatedPing < b.EstimatedPing
+		}
+		return a.Name < b.Name
+	})
+	return ranked
+}
+
+// handleNearest answers a query with servers sorted by estimated latency
+// from the client.
+func (h *FederationHub) handleNearest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var query ServerQuery
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		http.Error(w, "invalid query", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.NearestServers(&query, remoteIP(r)))
+}
+
+// remoteIP returns the IP a request came from, or nil if RemoteAddr isn't
+// an IP address.
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// FindNearestServers asks a remote federation hub for servers sorted by
+// estimated latency from this client.
+func FindNearestServers(hubURL string, query *ServerQuery, timeout time.Duration) ([]RankedServer, error) {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	client := &http.Client{Timeout: timeout}
+
+	data, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal query: %w", err)
+	}
+
+	resp, err := client.Post(hubURL+"/nearest", "application/json", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query federation hub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("federation hub returned status %d", resp.StatusCode)
+	}
+
+	var results []RankedServer
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,163 @@
+package federation
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEstimateLatency(t *testing.T) {
+	tests := []struct {
+		from, to Region
+		want     time.Duration
+	}{
+		{RegionEUWest, RegionEUWest, 20 * time.Millisecond},
+		{RegionEUWest, RegionEUEast, 40 * time.Millisecond},
+		{RegionEUEast, RegionEUWest, 40 * time.Millisecond},
+		{RegionUSWest, RegionAsiaPac, 120 * time.Millisecond},
+		{RegionUnknown, RegionUnknown, DefaultRegionLatency},
+		{RegionUSEast, "", DefaultRegionLatency},
+	}
+	for _, tt := range tests {
+		if got := EstimateLatency(tt.from, tt.to); got != tt.want {
+			t.Errorf("EstimateLatency(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPrefixResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefixes")
+	os.WriteFile(path, []byte("# test table\n10.0.0.0/8,us-east\n10.1.0.0/16, eu-west\n2001:db8::/32,asia-pac\n"), 0o600)
+	r, err := LoadPrefixResolver(path)
+	if err != nil {
+		t.Fatalf("LoadPrefixResolver failed: %v", err)
+	}
+
+	tests := []struct {
+		ip   string
+		want Region
+	}{
+		{"10.2.3.4", RegionUSEast},
+		{"10.1.3.4", RegionEUWest},
+		{"2001:db8::1", RegionAsiaPac},
+		{"192.0.2.1", RegionUnknown},
+	}
+	for _, tt := range tests {
+		if got := r.Resolve(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("Resolve(%s) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+	if r.Resolve(nil) != RegionUnknown {
+		t.Error("Resolve(nil) should be unknown")
+	}
+
+	os.WriteFile(path, []byte("10.0.0.0/8\n"), 0o600)
+	if _, err := LoadPrefixResolver(path); err == nil {
+		t.Error("LoadPrefixResolver accepted a line without a region")
+	}
+}
+
+// rankingHub has one server in each of three regions, and a resolver
+// that puts 10.0.0.0/8 in eu-west.
+func rankingHub(t *testing.T) *FederationHub {
+	t.Helper()
+	hub := NewFederationHub()
+	r := NewPrefixResolver()
+	r.Add("10.0.0.0/8", RegionEUWest)
+	r.Add("192.0.2.0/24", RegionAsiaPac)
+	hub.SetRegionResolver(r)
+	for name, region := range map[string]Region{"us": RegionUSEast, "eu": RegionEUEast, "asia": RegionAsiaPac} {
+		hub.RegisterServer(&ServerAnnouncement{Name: name, Region: region, Timestamp: time.Now()})
+	}
+	return hub
+}
+
+func names(servers []RankedServer) []string {
+	var out []string
+	for _, s := range servers {
+		out = append(out, s.Name)
+	}
+	return out
+}
+
+func TestFederationHub_NearestServers(t *testing.T) {
+	hub := rankingHub(t)
+
+	// Inferred from the client's IP
+	ranked := hub.NearestServers(&ServerQuery{}, net.ParseIP("10.0.0.1"))
+	if got := names(ranked); len(got) != 3 || got[0] != "eu" || got[1] != "us" || got[2] != "asia" {
+		t.Errorf("ranking from eu-west = %v", got)
+	}
+	if ranked[0].EstimatedPing != 40 {
+		t.Errorf("EstimatedPing = %d, want 40", ranked[0].EstimatedPing)
+	}
+
+	// A reported region wins over the IP
+	region := RegionAsiaPac
+	if got := names(hub.NearestServers(&ServerQuery{ClientRegion: &region}, net.ParseIP("10.0.0.1"))); got[0] != "asia" {
+		t.Errorf("ranking from asia-pac = %v", got)
+	}
+
+	// Max ping filters, and preferred regions come first
+	maxPing := 100
+	ranked = hub.NearestServers(&ServerQuery{MaxPing: &maxPing, PreferRegions: []Region{RegionUSEast}}, net.ParseIP("10.0.0.1"))
+	if got := names(ranked); len(got) != 2 || got[0] != "us" || got[1] != "eu" {
+		t.Errorf("ranking with max ping and preference = %v", got)
+	}
+
+	// Without a known client region, max ping can't filter
+	if got := hub.NearestServers(&ServerQuery{MaxPing: &maxPing}, nil); len(got) != 3 {
+		t.Errorf("%d servers with an unknown client region, want 3", len(got))
+	}
+}
+
+func TestFederationHub_InferServerRegion(t *testing.T) {
+	hub := rankingHub(t)
+	a := &ServerAnnouncement{Name: "inferred", Address: "192.0.2.7:7777"}
+	if err := hub.Announce(a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Region != RegionAsiaPac {
+		t.Errorf("region = %q, want inferred from the address", a.Region)
+	}
+	b := &ServerAnnouncement{Name: "declared", Address: "192.0.2.8:7777", Region: RegionUSWest}
+	hub.Announce(b)
+	if b.Region != RegionUSWest {
+		t.Errorf("declared region overwritten with %q", b.Region)
+	}
+}
+
+func TestFindNearestServers(t *testing.T) {
+	hub := rankingHub(t)
+	srv := httptest.NewServer(http.HandlerFunc(hub.handleNearest))
+	defer srv.Close()
+
+	region := RegionUSEast
+	ranked, err := FindNearestServers(srv.URL, &ServerQuery{ClientRegion: &region}, time.Second)
+	if err != nil {
+		t.Fatalf("FindNearestServers failed: %v", err)
+	}
+	if got := names(ranked); len(got) != 3 || got[0] != "us" || ranked[0].EstimatedPing != 20 {
+		t.Errorf("ranking = %v", ranked)
+	}
+
+	resp, _ := http.Post(srv.URL, "application/json", bytes.NewReader([]byte("{")))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad query: status %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	// The ranked JSON keeps the announcement's fields at the top level
+	data, _ := json.Marshal(ranked[0])
+	var flat map[string]interface{}
+	json.Unmarshal(data, &flat)
+	if flat["name"] != "us" || flat["estimatedPing"] != float64(20) {
+		t.Errorf("ranked JSON = %s", data)
+	}
+}
@@ -0,0 +1,257 @@
+package federation
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxClockSkew is how far in the future a replicated announcement's
+// timestamp may be before the hub rejects it, so that a peer with a fast
+// clock can't keep a server alive past its expiry.
+const maxClockSkew = time.Minute
+
+// GossipDigest summarises a hub's registry as each server's origin
+// timestamp in Unix nanoseconds, keyed by server name.
+type GossipDigest map[string]int64
+
+// GossipMessage is sent from one hub to a peer. A round opens with the
+// sender's digest; the sender then pushes the announcements the peer asked
+// for with a nil digest, which the peer doesn't answer.
+type GossipMessage struct {
+	Digest  GossipDigest          `json:"digest"`
+	Servers []*ServerAnnouncement `json:"servers,omitempty"`
+}
+
+// GossipReply answers a GossipMessage with the announcements the peer
+// holds newer copies of than the sender's digest, and the names the sender
+// holds newer copies of.
+type GossipReply struct {
+	Servers []*ServerAnnouncement `json:"servers,omitempty"`
+	Want    []string              `json:"want,omitempty"`
+}
+
+// GossipResult counts what one gossip round exchanged with a peer.
+type GossipResult struct {
+	Received int // Announcements from the peer that were newer here
+	Sent     int // Announcements the peer asked for
+}
+
+// Digest returns the origin timestamp of every registered server.
+func (h *FederationHub) Digest() GossipDigest {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	digest := make(GossipDigest, len(h.servers))
+	for name, server := range h.servers {
+		digest[name] = server.Timestamp.UnixNano()
+	}
+	return digest
+}
+
+// Merge registers replicated announcements that are newer than the hub's
+// own copies, keeping the timestamps the origin hub gave them so that a
+// server that stops announcing expires everywhere at once. Announcements
+// that are stale, from too far in the future, carry a bad signature, or
+// fail the hub's signing policy are skipped. It returns how many were
+// applied.
+func (h *FederationHub) Merge(servers []*ServerAnnouncement) int {
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	applied := 0
+	for _, server := range servers {
+		if server == nil || server.Name == "" {
+			continue
+		}
+		a := *server
+		if now.Sub(a.Timestamp) > h.staleTimeout || a.Timestamp.Sub(now) > maxClockSkew {
+			continue
+		}
+		if prev, ok := h.servers[a.Name]; ok && !prev.Timestamp.Before(a.Timestamp) {
+			continue
+		}
+		if !verifyReplicated(&a) {
+			logrus.WithField("server_name", a.Name).Warn("dropped replicated announcement with bad signature")
+			continue
+		}
+		if err := h.admit(&a); err != nil {
+			logrus.WithError(err).WithField("server_name", a.Name).Debug("replicated announcement rejected")
+			continue
+		}
+		h.addServer(&a)
+		h.persist(&a)
+		applied++
+	}
+	return applied
+}
+
+// verifyReplicated recomputes Verified for an announcement from a peer
+// rather than trusting the peer's word for it. The signature's age isn't
+// checked: the origin hub did that when the server announced. It reports
+// false if the announcement is signed but the signature doesn't match.
+func verifyReplicated(a *ServerAnnouncement) bool {
+	a.Verified = false
+	if len(a.Signature) == 0 {
+		a.PublicKey = nil
+		return true
+	}
+	if err := VerifyAnnouncement(a, time.Unix(a.SignedAt, 0)); err != nil {
+		return false
+	}
+	a.Verified = true
+	return true
+}
+
+// Gossip handles one message from a peer hub: it merges any announcements
+// the peer pushed, then compares the peer's digest with the registry.
+func (h *FederationHub) Gossip(msg *GossipMessage) *GossipReply {
+	h.Merge(msg.Servers)
+
+	reply := &GossipReply{}
+	if msg.Digest == nil {
+		return reply
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for name, server := range h.servers {
+		if ts, ok := msg.Digest[name]; !ok || ts < server.Timestamp.UnixNano() {
+			copied := *server
+			reply.Servers = append(reply.Servers, &copied)
+		}
+	}
+	for name, ts := range msg.Digest {
+		if server, ok := h.servers[name]; !ok || server.Timestamp.UnixNano() < ts {
+			reply.Want = append(reply.Want, name)
+		}
+	}
+	return reply
+}
+
+// SetGossipToken sets the bearer token peer hubs must gossip with. With a
+// token, callers without it are refused. Without one, every caller is a
+// stranger, so ServeGossip redacts the announcements it replies with and
+// presence settings never leave the hub.
+func (h *FederationHub) SetGossipToken(token string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.gossipToken = token
+}
+
+// ServeGossip answers a GossipMessage posted by a peer hub. Only peers
+// presenting the gossip token get full announcements; anyone else gets
+// them redacted as for /query. A signed announcement that lists players
+// who aren't public no longer matches its signature once redacted, so
+// untrusted peers drop it.
+func (h *FederationHub) ServeGossip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.mu.RLock()
+	token := h.gossipToken
+	h.mu.RUnlock()
+	trusted := false
+	if token != "" {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		trusted = true
+	}
+
+	var msg GossipMessage
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, "invalid gossip", http.StatusBadRequest)
+		return
+	}
+
+	reply := h.Gossip(&msg)
+	if !trusted {
+		for i, server := range reply.Servers {
+			reply.Servers[i] = server.Redacted()
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
+
+// GossipWith runs one push-pull round with a peer hub, given its HTTP URL:
+// it sends the hub's digest, merges the newer announcements the peer
+// returns, and pushes back the ones the peer asked for. Token, if set, is
+// sent as a bearer token.
+func (h *FederationHub) GossipWith(peerURL, token string, timeout time.Duration) (GossipResult, error) {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	var result GossipResult
+	reply, err := postGossip(client, peerURL, token, &GossipMessage{Digest: h.Digest()})
+	if err != nil {
+		return result, err
+	}
+	result.Received = h.Merge(reply.Servers)
+
+	if len(reply.Want) == 0 {
+		return result, nil
+	}
+	push := &GossipMessage{}
+	h.mu.RLock()
+	for _, name := range reply.Want {
+		if server, ok := h.servers[name]; ok {
+			copied := *server
+			push.Servers = append(push.Servers, &copied)
+		}
+	}
+	h.mu.RUnlock()
+	if len(push.Servers) == 0 {
+		return result, nil
+	}
+	if _, err := postGossip(client, peerURL, token, push); err != nil {
+		return result, err
+	}
+	result.Sent = len(push.Servers)
+	return result, nil
+}
+
+// postGossip sends one gossip message to a peer hub.
+func postGossip(client *http.Client, peerURL, token string, msg *GossipMessage) (*GossipReply, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal gossip: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, peerURL+"/gossip", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to gossip with peer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer hub returned status %d", resp.StatusCode)
+	}
+
+	var reply GossipReply
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &reply, nil
+}
@@ -0,0 +1,226 @@
+package federation
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestFederationHub_Merge(t *testing.T) {
+	hub := NewFederationHub()
+	hub.SetStaleTimeout(time.Minute)
+	now := time.Now()
+	hub.RegisterServer(&ServerAnnouncement{Name: "local", Players: 1, Timestamp: now})
+
+	id, _ := NewServerIdentity()
+	signed := signedAnnouncement(t, id, "signed")
+	signed.Timestamp = now.Add(-10 * time.Second)
+	forged := signedAnnouncement(t, id, "forged")
+	forged.Players = 7
+	forged.Timestamp = now
+
+	applied := hub.Merge([]*ServerAnnouncement{
+		{Name: "local", Players: 5, Timestamp: now.Add(-time.Second)}, // Older than ours
+		{Name: "stale", Timestamp: now.Add(-2 * time.Minute)},
+		{Name: "future", Timestamp: now.Add(2 * maxClockSkew)},
+		{Name: "remote", Verified: true, Timestamp: now.Add(-20 * time.Second)},
+		signed,
+		forged,
+	})
+	if applied != 2 {
+		t.Errorf("applied = %d, want 2", applied)
+	}
+
+	servers := map[string]*ServerAnnouncement{}
+	for _, s := range hub.QueryServers(&ServerQuery{}) {
+		servers[s.Name] = s
+	}
+	if servers["local"].Players != 1 {
+		t.Error("older replica overwrote the local announcement")
+	}
+	for _, name := range []string{"stale", "future", "forged"} {
+		if servers[name] != nil {
+			t.Errorf("%s was merged", name)
+		}
+	}
+	if remote := servers["remote"]; remote == nil || remote.Verified || !remote.Timestamp.Equal(now.Add(-20*time.Second)) {
+		t.Errorf("remote = %+v, want unverified with its origin timestamp", remote)
+	}
+	if s := servers["signed"]; s == nil || !s.Verified {
+		t.Errorf("signed = %+v, want verified", s)
+	}
+
+	// A newer replica replaces ours
+	if hub.Merge([]*ServerAnnouncement{{Name: "local", Players: 3, Timestamp: now.Add(time.Second)}}) != 1 {
+		t.Error("newer replica was not applied")
+	}
+}
+
+func TestFederationHub_Gossip(t *testing.T) {
+	hub := NewFederationHub()
+	now := time.Now()
+	hub.RegisterServer(&ServerAnnouncement{Name: "same", Timestamp: now})
+	hub.RegisterServer(&ServerAnnouncement{Name: "newer-here", Timestamp: now})
+	hub.RegisterServer(&ServerAnnouncement{Name: "only-here", Timestamp: now})
+	hub.RegisterServer(&ServerAnnouncement{Name: "newer-there", Timestamp: now.Add(-time.Second)})
+
+	reply := hub.Gossip(&GossipMessage{Digest: GossipDigest{
+		"same":        now.UnixNano(),
+		"newer-here":  now.Add(-time.Second).UnixNano(),
+		"newer-there": now.UnixNano(),
+		"only-there":  now.UnixNano(),
+	}})
+
+	var sent []string
+	for _, s := range reply.Servers {
+		sent = append(sent, s.Name)
+	}
+	sort.Strings(sent)
+	sort.Strings(reply.Want)
+	if len(sent) != 2 || sent[0] != "newer-here" || sent[1] != "only-here" {
+		t.Errorf("servers = %v, want [newer-here only-here]", sent)
+	}
+	if len(reply.Want) != 2 || reply.Want[0] != "newer-there" || reply.Want[1] != "only-there" {
+		t.Errorf("want = %v, want [newer-there only-there]", reply.Want)
+	}
+}
+
+func TestFederationHub_GossipWith(t *testing.T) {
+	a := NewFederationHub()
+	b := NewFederationHub()
+	b.SetStaleTimeout(time.Minute)
+	if err := b.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start hub: %v", err)
+	}
+	defer b.Stop()
+
+	origin := time.Now().Add(-15 * time.Second)
+	a.RegisterServer(&ServerAnnouncement{Name: "from-a", Timestamp: origin})
+	b.RegisterServer(&ServerAnnouncement{Name: "from-b", Timestamp: origin})
+
+	result, err := a.GossipWith("http://"+b.GetAddr(), "", time.Second)
+	if err != nil {
+		t.Fatalf("GossipWith failed: %v", err)
+	}
+	if result.Received != 1 || result.Sent != 1 {
+		t.Errorf("result = %+v, want one each way", result)
+	}
+	for _, hub := range []*FederationHub{a, b} {
+		digest := hub.Digest()
+		if len(digest) != 2 || digest["from-a"] != origin.UnixNano() || digest["from-b"] != origin.UnixNano() {
+			t.Errorf("digest = %v, want both servers at their origin timestamp", digest)
+		}
+	}
+
+	// Once in step, a round exchanges nothing
+	result, err = a.GossipWith("http://"+b.GetAddr(), "", time.Second)
+	if err != nil {
+		t.Fatalf("GossipWith failed: %v", err)
+	}
+	if result.Received != 0 || result.Sent != 0 {
+		t.Errorf("result = %+v, want nothing exchanged", result)
+	}
+}
+
+func TestFederationHub_GossipEmptyDigest(t *testing.T) {
+	hub := NewFederationHub()
+	hub.RegisterServer(&ServerAnnouncement{Name: "s1", Timestamp: time.Now()})
+
+	// An empty registry still asks for everything, even over JSON
+	var msg GossipMessage
+	data, _ := json.Marshal(&GossipMessage{Digest: GossipDigest{}})
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if reply := hub.Gossip(&msg); len(reply.Servers) != 1 {
+		t.Errorf("servers = %d, want 1", len(reply.Servers))
+	}
+}
+
+func TestFederationHub_GossipHidesPresence(t *testing.T) {
+	b := NewFederationHub()
+	b.SetStaleTimeout(time.Minute)
+	if err := b.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start hub: %v", err)
+	}
+	defer b.Stop()
+	b.RegisterServer(&ServerAnnouncement{
+		Name:       "s1",
+		Players:    2,
+		PlayerList: []string{"alice", "ghost"},
+		Presence:   map[string]PlayerPresence{"ghost": {Visibility: PresenceInvisible}},
+		SourceIP:   "203.0.113.9",
+		Timestamp:  time.Now(),
+	})
+	url := "http://" + b.GetAddr()
+
+	// Without a gossip token every caller is a stranger
+	stranger := NewFederationHub()
+	stranger.SetStaleTimeout(time.Minute)
+	if _, err := stranger.GossipWith(url, "", time.Second); err != nil {
+		t.Fatalf("GossipWith failed: %v", err)
+	}
+	servers := stranger.QueryServers(&ServerQuery{})
+	if len(servers) != 1 || len(servers[0].PlayerList) != 1 || servers[0].PlayerList[0] != "alice" || servers[0].Presence != nil || servers[0].SourceIP != "" {
+		t.Errorf("stranger got %+v, want only the public player", servers)
+	}
+	if stranger.lookupPlayer("ghost", "").Online {
+		t.Error("invisible player was gossiped to a stranger")
+	}
+
+	// With one, strangers are refused and peers get the full announcement
+	b.SetGossipToken("peer-secret")
+	if _, err := NewFederationHub().GossipWith(url, "wrong", time.Second); err == nil {
+		t.Error("GossipWith succeeded with the wrong token")
+	}
+	peer := NewFederationHub()
+	peer.SetStaleTimeout(time.Minute)
+	if _, err := peer.GossipWith(url, "peer-secret", time.Second); err != nil {
+		t.Fatalf("GossipWith failed: %v", err)
+	}
+	servers = peer.QueryServers(&ServerQuery{})
+	if len(servers) != 1 || len(servers[0].PlayerList) != 2 || servers[0].Presence["ghost"].Visibility != PresenceInvisible {
+		t.Errorf("peer got %+v, want the full announcement", servers)
+	}
+	if peer.lookupPlayer("ghost", "").Online {
+		t.Error("peer revealed the invisible player")
+	}
+}
+
+func TestFederationHub_GossipInferredRegion(t *testing.T) {
+	a := NewFederationHub()
+	resolver := NewPrefixResolver()
+	resolver.Add("192.0.2.0/24", RegionAsiaPac)
+	a.SetRegionResolver(resolver)
+	b := NewFederationHub()
+	b.SetStaleTimeout(time.Minute)
+	if err := b.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start hub: %v", err)
+	}
+	defer b.Stop()
+
+	id, _ := NewServerIdentity()
+	announcement := &ServerAnnouncement{Name: "s1", Address: "192.0.2.7:7777", Region: RegionUnknown}
+	if err := id.Sign(announcement); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := a.Announce(announcement); err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+	if announcement.Region != RegionAsiaPac || !announcement.Verified {
+		t.Fatalf("announcement = %+v, want verified with an inferred region", announcement)
+	}
+
+	result, err := a.GossipWith("http://"+b.GetAddr(), "", time.Second)
+	if err != nil {
+		t.Fatalf("GossipWith failed: %v", err)
+	}
+	if result.Sent != 1 {
+		t.Errorf("result = %+v, want the server sent", result)
+	}
+	servers := b.QueryServers(&ServerQuery{})
+	if len(servers) != 1 || !servers[0].Verified || servers[0].Region != RegionAsiaPac {
+		t.Errorf("peer has %+v, want the verified server with its inferred region", servers)
+	}
+}
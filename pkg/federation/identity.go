@@ -0,0 +1,229 @@
+package federation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxSignatureAge is how far an announcement's signing time may be from
+// the hub's clock before the hub treats it as a replay.
+const MaxSignatureAge = 5 * time.Minute
+
+var (
+	// ErrUnsignedAnnouncement is returned when a hub that requires signed
+	// announcements receives an unsigned one.
+	ErrUnsignedAnnouncement = errors.New("announcement is not signed")
+
+	// ErrBadSignature is returned for an announcement whose signature
+	// doesn't match its key and contents.
+	ErrBadSignature = errors.New("announcement signature is invalid")
+
+	// ErrStaleSignature is returned for an announcement signed more than
+	// MaxSignatureAge away from the hub's clock.
+	ErrStaleSignature = errors.New("announcement signature is stale")
+
+	// ErrKeyNotAllowed is returned when a hub with an allowlist receives an
+	// announcement signed by a key not on it.
+	ErrKeyNotAllowed = errors.New("announcement key is not allowed")
+
+	// ErrNameClaimed is returned when a server name is held by a verified
+	// server with a different key.
+	ErrNameClaimed = errors.New("server name is claimed by another key")
+)
+
+// ServerIdentity is a game server's signing key. The public half identifies
+// the server to hubs across restarts.
+type ServerIdentity struct {
+	key ed25519.PrivateKey
+}
+
+// NewServerIdentity generates a fresh server identity.
+func NewServerIdentity() (*ServerIdentity, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return &ServerIdentity{key: key}, nil
+}
+
+// LoadServerIdentity reads the identity saved at path, generating and
+// saving a new one if the file doesn't exist yet.
+func LoadServerIdentity(path string) (*ServerIdentity, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		id, err := NewServerIdentity()
+		if err != nil {
+			return nil, err
+		}
+		seed := base64.StdEncoding.EncodeToString(id.key.Seed())
+		if err := os.WriteFile(path, []byte(seed+"\n"), 0o600); err != nil {
+			return nil, fmt.Errorf("failed to save server key: %w", err)
+		}
+		logrus.WithFields(logrus.Fields{
+			"path":       path,
+			"public_key": id.PublicKey(),
+		}).Info("generated server key")
+		return id, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server key: %w", err)
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid server key in %s", path)
+	}
+	return &ServerIdentity{key: ed25519.NewKeyFromSeed(seed)}, nil
+}
+
+// PublicKey returns the identity's public key in base64, the form hub
+// allowlists use.
+func (id *ServerIdentity) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(id.key.Public().(ed25519.PublicKey))
+}
+
+// Sign stamps the announcement with the identity's key and the current
+// time and signs it.
+func (id *ServerIdentity) Sign(a *ServerAnnouncement) error {
+	a.PublicKey = id.key.Public().(ed25519.PublicKey)
+	a.SignedAt = time.Now().Unix()
+	payload, err := a.signingPayload()
+	if err != nil {
+		return err
+	}
+	a.Signature = ed25519.Sign(id.key, payload)
+	return nil
+}
+
+// VerifyAnnouncement checks a signed announcement's signature, and that it
+// was signed within MaxSignatureAge of now.
+func VerifyAnnouncement(a *ServerAnnouncement, now time.Time) error {
+	if len(a.Signature) == 0 {
+		return ErrUnsignedAnnouncement
+	}
+	if len(a.PublicKey) != ed25519.PublicKeySize || len(a.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: malformed key or signature", ErrBadSignature)
+	}
+	age := now.Sub(time.Unix(a.SignedAt, 0))
+	if age > MaxSignatureAge || age < -MaxSignatureAge {
+		return fmt.Errorf("%w: signed %v ago", ErrStaleSignature, age.Round(time.Second))
+	}
+	payload, err := a.signingPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(a.PublicKey, payload, a.Signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// signingPayload is what an announcement's signature covers: everything
+// the server says about itself, leaving out the signature and the fields
+// the hub fills in. A region the hub inferred is left out too, so peers
+// can still verify the announcement once it carries one; an unknown
+// region is signed as none, since that is what inference replaces.
+func (a *ServerAnnouncement) signingPayload() ([]byte, error) {
+	signed := *a
+	signed.Signature = nil
+	signed.Verified = false
+	signed.SourceIP = ""
+	if signed.RegionInferred || signed.Region == RegionUnknown {
+		signed.Region = ""
+	}
+	signed.RegionInferred = false
+	signed.Timestamp = time.Time{}
+	payload, err := json.Marshal(&signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal announcement: %w", err)
+	}
+	return payload, nil
+}
+
+// Announce verifies an announcement from a game server and registers it.
+// Signed announcements are marked Verified once their signature checks
+// out. The hub rejects banned keys and addresses, unsigned announcements
+// if it requires signatures or has an allowlist, keys not on its
+// allowlist, and announcements for a name a verified server holds under a
+// different key.
+func (h *FederationHub) Announce(a *ServerAnnouncement) error {
+	return h.AnnounceFrom(a, nil)
+}
+
+// AnnounceFrom is Announce for an announcement received from source. The
+// hub records the source IP, which unlike the address a server announces
+// is not the server's to choose, and rejects announcements from banned
+// networks.
+func (h *FederationHub) AnnounceFrom(a *ServerAnnouncement, source net.IP) error {
+	a.Timestamp = time.Now()
+	a.SourceIP = ""
+	a.RegionInferred = false
+	if source != nil {
+		a.SourceIP = source.String()
+	}
+	a.Verified = false
+	if len(a.Signature) == 0 {
+		a.PublicKey = nil
+	} else {
+		if err := VerifyAnnouncement(a, a.Timestamp); err != nil {
+			return err
+		}
+		a.Verified = true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.admit(a); err != nil {
+		return err
+	}
+	h.inferRegion(a)
+	h.addServer(a)
+	h.persist(a)
+	return nil
+}
+
+// admit applies the hub's bans and signing policy to an announcement (must
+// be called with lock held).
+func (h *FederationHub) admit(a *ServerAnnouncement) error {
+	if err := h.checkBans(a); err != nil {
+		return err
+	}
+	if !a.Verified && (h.requireSigned || len(h.allowedKeys) > 0) {
+		return ErrUnsignedAnnouncement
+	}
+	if a.Verified && len(h.allowedKeys) > 0 && !h.allowedKeys[base64.StdEncoding.EncodeToString(a.PublicKey)] {
+		return ErrKeyNotAllowed
+	}
+	if prev, ok := h.servers[a.Name]; ok && prev.Verified && (!a.Verified || string(prev.PublicKey) != string(a.PublicKey)) {
+		return ErrNameClaimed
+	}
+	return nil
+}
+
+// SetRequireSigned makes the hub reject unsigned announcements.
+func (h *FederationHub) SetRequireSigned(require bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requireSigned = require
+}
+
+// SetAllowedKeys restricts announcements to servers signing with one of
+// the given base64 public keys. An empty list lets any key announce.
+func (h *FederationHub) SetAllowedKeys(keys []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.allowedKeys = make(map[string]bool, len(keys))
+	for _, key := range keys {
+		h.allowedKeys[key] = true
+	}
+}
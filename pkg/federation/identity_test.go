@@ -0,0 +1,117 @@
+package federation
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// signedAnnouncement returns an announcement for name signed by id.
+func signedAnnouncement(t *testing.T, id *ServerIdentity, name string) *ServerAnnouncement {
+	t.Helper()
+	a := &ServerAnnouncement{Name: name, Address: name + ":7777", Players: 2, MaxPlayers: 8}
+	if err := id.Sign(a); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	return a
+}
+
+func TestVerifyAnnouncement(t *testing.T) {
+	id, _ := NewServerIdentity()
+	now := time.Now()
+
+	if err := VerifyAnnouncement(signedAnnouncement(t, id, "s1"), now); err != nil {
+		t.Errorf("valid announcement: %v", err)
+	}
+
+	tampered := signedAnnouncement(t, id, "s1")
+	tampered.Players = 1
+	if err := VerifyAnnouncement(tampered, now); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("tampered announcement: err = %v", err)
+	}
+
+	other, _ := NewServerIdentity()
+	swapped := signedAnnouncement(t, id, "s1")
+	swapped.PublicKey = other.key.Public().(ed25519.PublicKey)
+	if err := VerifyAnnouncement(swapped, now); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("swapped key: err = %v", err)
+	}
+
+	if err := VerifyAnnouncement(signedAnnouncement(t, id, "s1"), now.Add(MaxSignatureAge+time.Minute)); !errors.Is(err, ErrStaleSignature) {
+		t.Errorf("replayed announcement: err = %v", err)
+	}
+	if err := VerifyAnnouncement(&ServerAnnouncement{Name: "s1"}, now); !errors.Is(err, ErrUnsignedAnnouncement) {
+		t.Errorf("unsigned announcement: err = %v", err)
+	}
+}
+
+func TestLoadServerIdentity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.key")
+	first, err := LoadServerIdentity(path)
+	if err != nil {
+		t.Fatalf("generating: %v", err)
+	}
+	second, err := LoadServerIdentity(path)
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+	if first.PublicKey() != second.PublicKey() {
+		t.Error("reloaded identity has a different key")
+	}
+}
+
+func TestFederationHub_Announce(t *testing.T) {
+	hub := NewFederationHub()
+	id, _ := NewServerIdentity()
+
+	a := signedAnnouncement(t, id, "real")
+	if err := hub.Announce(a); err != nil || !a.Verified {
+		t.Fatalf("Announce() = %v, verified %v", err, a.Verified)
+	}
+
+	// Neither an unsigned announcement nor another key can take the name
+	if err := hub.Announce(&ServerAnnouncement{Name: "real", Address: "evil:7777"}); !errors.Is(err, ErrNameClaimed) {
+		t.Errorf("unsigned spoof: err = %v", err)
+	}
+	impostor, _ := NewServerIdentity()
+	if err := hub.Announce(signedAnnouncement(t, impostor, "real")); !errors.Is(err, ErrNameClaimed) {
+		t.Errorf("signed spoof: err = %v", err)
+	}
+	if servers := hub.QueryServers(&ServerQuery{}); len(servers) != 1 || servers[0].Address != "real:7777" {
+		t.Errorf("servers = %+v", servers)
+	}
+
+	// Unsigned servers are listed, just not verified
+	unsigned := &ServerAnnouncement{Name: "plain", PublicKey: []byte("claimed")}
+	if err := hub.Announce(unsigned); err != nil || unsigned.Verified || unsigned.PublicKey != nil {
+		t.Errorf("unsigned Announce() = %v, %+v", err, unsigned)
+	}
+}
+
+func TestFederationHub_AnnouncePolicy(t *testing.T) {
+	hub := NewFederationHub()
+	allowed, _ := NewServerIdentity()
+	other, _ := NewServerIdentity()
+
+	hub.SetRequireSigned(true)
+	if err := hub.Announce(&ServerAnnouncement{Name: "plain"}); !errors.Is(err, ErrUnsignedAnnouncement) {
+		t.Errorf("unsigned with signatures required: err = %v", err)
+	}
+	if err := hub.Announce(signedAnnouncement(t, other, "other")); err != nil {
+		t.Errorf("signed with signatures required: %v", err)
+	}
+
+	hub.SetRequireSigned(false)
+	hub.SetAllowedKeys([]string{allowed.PublicKey()})
+	if err := hub.Announce(&ServerAnnouncement{Name: "plain"}); !errors.Is(err, ErrUnsignedAnnouncement) {
+		t.Errorf("unsigned with an allowlist: err = %v", err)
+	}
+	if err := hub.Announce(signedAnnouncement(t, other, "other2")); !errors.Is(err, ErrKeyNotAllowed) {
+		t.Errorf("key off the allowlist: err = %v", err)
+	}
+	if err := hub.Announce(signedAnnouncement(t, allowed, "ok")); err != nil {
+		t.Errorf("key on the allowlist: %v", err)
+	}
+}
@@ -0,0 +1,630 @@
+package federation
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Packet types on the wire. The first byte of every UDP packet says what it
+// is; peers talk to the hub's rendezvous with the first two, to each other
+// with sealed packets, and through the relay with the last two.
+const (
+	packetRegister byte = iota + 1 // peer to hub: punchRegistration JSON
+	packetPeerInfo                 // hub to peer: punchPeerInfo JSON
+	packetSealed                   // peer to peer: sealed message
+	packetRelay                    // peer to hub: relay header and sealed message
+	packetRelayed                  // hub to peer: sealed message
+)
+
+// Sealed message kinds, the first byte of the decrypted message.
+const (
+	sealedProbe byte = iota + 1
+	sealedProbeAck
+	sealedPing
+	sealedPong
+	sealedData
+)
+
+const (
+	// MaxPeerPayload is the most data one Send can carry, leaving room for
+	// headers and encryption within a typical MTU.
+	MaxPeerPayload = 1200
+
+	// DefaultRendezvousTimeout is how long ConnectPeer waits for the other
+	// peer to register with the hub.
+	DefaultRendezvousTimeout = 10 * time.Second
+
+	// DefaultPunchTimeout is how long ConnectPeer tries to punch through
+	// before falling back to the relay.
+	DefaultPunchTimeout = 3 * time.Second
+
+	// DefaultPingInterval is how often a peer link measures its quality.
+	DefaultPingInterval = time.Second
+
+	maxPeerPacket    = 1500
+	registerInterval = 250 * time.Millisecond
+	probeInterval    = 100 * time.Millisecond
+	pingTimeout      = 2 * time.Second
+	qualityWindow    = 20 // Pings the loss figure covers
+	peerInbox        = 64 // Received payloads buffered for Receive
+)
+
+var (
+	// ErrRendezvousTimeout is returned when the other peer never registers
+	// with the hub.
+	ErrRendezvousTimeout = errors.New("peer never reached the rendezvous")
+
+	// ErrPeerClosed is returned by a closed peer link.
+	ErrPeerClosed = errors.New("peer link closed")
+
+	// ErrPeerPayloadSize is returned for a payload over MaxPeerPayload.
+	ErrPeerPayloadSize = errors.New("peer payload too large")
+
+	// ErrPeerUnverified is returned when the other peer never answers a
+	// probe under the link key, as happens when the peers' secrets differ
+	// or the hub swapped their public keys.
+	ErrPeerUnverified = errors.New("peer never proved the link key")
+)
+
+// punchRegistration is what a peer tells the hub's rendezvous.
+type punchRegistration struct {
+	Session   string `json:"session"`
+	PeerID    string `json:"peerID"`
+	PublicKey []byte `json:"publicKey"`
+}
+
+// punchPeerInfo is what the rendezvous tells a peer about the other one.
+type punchPeerInfo struct {
+	PeerID    string `json:"peerID"`
+	Addr      string `json:"addr"`
+	PublicKey []byte `json:"publicKey"`
+}
+
+// PeerPath is the route a peer link's packets take.
+type PeerPath int
+
+const (
+	PathDirect  PeerPath = iota // Straight to the peer through a punched hole
+	PathRelayed                 // Through the hub's relay
+)
+
+// String returns the path's display name.
+func (p PeerPath) String() string {
+	switch p {
+	case PathDirect:
+		return "direct"
+	case PathRelayed:
+		return "relayed"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnectionQuality describes how well a peer link is doing, measured over
+// the last few pings.
+type ConnectionQuality struct {
+	Path    PeerPath
+	RTT     time.Duration // Smoothed round trip time
+	Jitter  time.Duration // Smoothed variation in round trip time
+	Loss    float64       // Fraction of pings that went unanswered, from 0 to 1
+	Samples int           // Pings the loss figure covers
+}
+
+// Grade sums the quality up as "good", "fair" or "poor", or "unknown"
+// before the first ping has been answered or lost.
+func (q ConnectionQuality) Grade() string {
+	switch {
+	case q.Samples == 0:
+		return "unknown"
+	case q.RTT < 100*time.Millisecond && q.Loss < 0.02:
+		return "good"
+	case q.RTT < 200*time.Millisecond && q.Loss < 0.1:
+		return "fair"
+	default:
+		return "poor"
+	}
+}
+
+// PeerConfig says how to reach a peer through a federation hub.
+type PeerConfig struct {
+	HubAddr string // The hub's rendezvous UDP address
+	Session string // Shared by both peers, such as the host's and joiner's IDs together
+	PeerID  string // This peer's ID within the session
+
+	// Secret is mixed into the link key. The hub hands each peer the
+	// other's public key, so it could hand out its own instead and read the
+	// link; it can't if both peers set Secret to something they share over
+	// a channel the hub doesn't see, such as the joiner's session token
+	// from the game connection. Without one the link is only as private as
+	// the hub is honest.
+	Secret string
+
+	// ForceRelay skips hole punching, for NATs known not to allow it.
+	ForceRelay bool
+
+	RendezvousTimeout time.Duration // Defaults to DefaultRendezvousTimeout
+	PunchTimeout      time.Duration // Defaults to DefaultPunchTimeout
+	PingInterval      time.Duration // Defaults to DefaultPingInterval
+}
+
+// PeerConn is an encrypted UDP link to another peer, either direct through
+// a punched hole or relayed by the hub. The key comes from an X25519
+// exchange between the peers, bound to their PeerConfig.Secret, so the hub
+// only sees sealed packets.
+type PeerConn struct {
+	conn   *net.UDPConn
+	hub    *net.UDPAddr
+	cfg    PeerConfig
+	header []byte // Relay header naming the session and this peer
+
+	mu       sync.Mutex
+	aead     cipher.AEAD
+	peerAddr *net.UDPAddr
+	path     PeerPath
+	pingSeq  uint32
+	pending  map[uint32]time.Time
+	outcomes []bool
+	rtt      time.Duration
+	jitter   time.Duration
+
+	peerInfo  chan punchPeerInfo
+	acked     chan struct{}
+	ackOnce   sync.Once
+	incoming  chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// ConnectPeer links up with the other peer of cfg.Session. Both peers
+// register their public endpoints with the hub, then send probes straight
+// at each other so each NAT opens a hole for the other. If no probe gets
+// through by the punch timeout, the link falls back to the hub's relay,
+// and probes through it instead. ConnectPeer only returns a link once the
+// peer has answered a probe, which proves it derived the same key.
+//
+// ConnectPeer takes over conn, which should be unconnected; Close closes it.
+func ConnectPeer(ctx context.Context, conn *net.UDPConn, cfg PeerConfig) (*PeerConn, error) {
+	if cfg.Session == "" || cfg.PeerID == "" || len(cfg.Session) > 255 || len(cfg.PeerID) > 255 {
+		return nil, fmt.Errorf("session and peer ID must be 1 to 255 bytes")
+	}
+	if cfg.RendezvousTimeout <= 0 {
+		cfg.RendezvousTimeout = DefaultRendezvousTimeout
+	}
+	if cfg.PunchTimeout <= 0 {
+		cfg.PunchTimeout = DefaultPunchTimeout
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = DefaultPingInterval
+	}
+	hub, err := net.ResolveUDPAddr("udp", cfg.HubAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve hub: %w", err)
+	}
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	c := &PeerConn{
+		conn:     conn,
+		hub:      hub,
+		cfg:      cfg,
+		header:   relayHeader(cfg.Session, cfg.PeerID),
+		pending:  make(map[uint32]time.Time),
+		peerInfo: make(chan punchPeerInfo, 1),
+		acked:    make(chan struct{}),
+		incoming: make(chan []byte, peerInbox),
+		done:     make(chan struct{}),
+	}
+	go c.readLoop()
+
+	info, err := c.register(ctx, key)
+	if err == nil {
+		err = c.setPeer(key, info)
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	if cfg.ForceRelay || !c.punch(ctx, cfg.PunchTimeout) {
+		c.mu.Lock()
+		c.path = PathRelayed
+		c.mu.Unlock()
+		logrus.WithFields(logrus.Fields{
+			"session": cfg.Session,
+			"peer":    info.PeerID,
+			"forced":  cfg.ForceRelay,
+		}).Info("hole punching failed, relaying through the hub")
+		if !c.punch(ctx, cfg.RendezvousTimeout) {
+			c.Close()
+			return nil, ErrPeerUnverified
+		}
+	}
+	go c.pingLoop()
+	return c, nil
+}
+
+// register announces this peer to the hub until it hears about the other.
+func (c *PeerConn) register(ctx context.Context, key *ecdh.PrivateKey) (punchPeerInfo, error) {
+	msg, err := json.Marshal(punchRegistration{Session: c.cfg.Session, PeerID: c.cfg.PeerID, PublicKey: key.PublicKey().Bytes()})
+	if err != nil {
+		return punchPeerInfo{}, err
+	}
+	packet := append([]byte{packetRegister}, msg...)
+
+	ticker := time.NewTicker(registerInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(c.cfg.RendezvousTimeout)
+	defer timeout.Stop()
+	for {
+		if _, err := c.conn.WriteToUDP(packet, c.hub); err != nil {
+			return punchPeerInfo{}, fmt.Errorf("failed to register with hub: %w", err)
+		}
+		select {
+		case info := <-c.peerInfo:
+			return info, nil
+		case <-ticker.C:
+		case <-timeout.C:
+			return punchPeerInfo{}, ErrRendezvousTimeout
+		case <-ctx.Done():
+			return punchPeerInfo{}, ctx.Err()
+		case <-c.done:
+			return punchPeerInfo{}, ErrPeerClosed
+		}
+	}
+}
+
+// setPeer derives the link key and records where the peer is.
+func (c *PeerConn) setPeer(key *ecdh.PrivateKey, info punchPeerInfo) error {
+	peerKey, err := ecdh.X25519().NewPublicKey(info.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid peer key: %w", err)
+	}
+	shared, err := key.ECDH(peerKey)
+	if err != nil {
+		return fmt.Errorf("key exchange failed: %w", err)
+	}
+	aead, err := linkCipher(shared, c.cfg.Session, c.cfg.Secret)
+	if err != nil {
+		return err
+	}
+	addr, err := net.ResolveUDPAddr("udp", info.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid peer address: %w", err)
+	}
+	c.mu.Lock()
+	c.aead, c.peerAddr = aead, addr
+	c.mu.Unlock()
+	return nil
+}
+
+// linkCipher derives an AES-256-GCM cipher from the X25519 shared secret
+// and the peers' own secret, salted with the session so each session gets
+// its own key.
+func linkCipher(shared []byte, session, secret string) (cipher.AEAD, error) {
+	key := make([]byte, 32)
+	ikm := append(append([]byte{}, shared...), secret...)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, []byte(session), []byte("violence peer link")), key); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// punch probes the peer until it acknowledges a probe or the timeout
+// passes, and reports whether it did. Probes go straight to the peer, or
+// through the relay once the link has fallen back to it.
+func (c *PeerConn) punch(ctx context.Context, limit time.Duration) bool {
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(limit)
+	defer timeout.Stop()
+	for {
+		c.sendSealed(sealedProbe, nil, c.route())
+		select {
+		case <-c.acked:
+			return true
+		case <-ticker.C:
+		case <-timeout.C:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-c.done:
+			return false
+		}
+	}
+}
+
+// sendSealed encrypts a message and sends it straight to addr, or through
+// the relay when addr is nil.
+func (c *PeerConn) sendSealed(kind byte, body []byte, addr *net.UDPAddr) error {
+	c.mu.Lock()
+	aead := c.aead
+	c.mu.Unlock()
+	if aead == nil {
+		return ErrPeerClosed
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, append([]byte{kind}, body...), nil)
+
+	var err error
+	if addr != nil {
+		_, err = c.conn.WriteToUDP(append([]byte{packetSealed}, sealed...), addr)
+	} else {
+		packet := append(append([]byte{packetRelay}, c.header...), sealed...)
+		_, err = c.conn.WriteToUDP(packet, c.hub)
+	}
+	return err
+}
+
+// route returns where to send to: the peer on a direct link, or nil for
+// the relay.
+func (c *PeerConn) route() *net.UDPAddr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.path == PathRelayed {
+		return nil
+	}
+	return c.peerAddr
+}
+
+// readLoop reads packets until the connection closes.
+func (c *PeerConn) readLoop() {
+	buf := make([]byte, maxPeerPacket)
+	for {
+		n, from, err := c.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				c.Close()
+				return
+			}
+			continue
+		}
+		if n == 0 {
+			continue
+		}
+		switch buf[0] {
+		case packetPeerInfo:
+			var info punchPeerInfo
+			if !sameAddr(from, c.hub) || json.Unmarshal(buf[1:n], &info) != nil {
+				continue
+			}
+			select {
+			case c.peerInfo <- info:
+			default:
+			}
+		case packetSealed:
+			c.handleSealed(buf[1:n], from)
+		case packetRelayed:
+			if sameAddr(from, c.hub) {
+				c.handleSealed(buf[1:n], nil)
+			}
+		}
+	}
+}
+
+// handleSealed opens a message from the peer, which came straight from
+// addr or through the relay when addr is nil.
+func (c *PeerConn) handleSealed(data []byte, from *net.UDPAddr) {
+	c.mu.Lock()
+	aead := c.aead
+	c.mu.Unlock()
+	if aead == nil || len(data) < aead.NonceSize() {
+		return
+	}
+	msg, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil || len(msg) == 0 {
+		return
+	}
+
+	switch kind, body := msg[0], msg[1:]; kind {
+	case sealedProbe:
+		// The peer's NAT may have mapped it to a different port than the
+		// hub saw, so answer wherever the probe came from. Relayed probes
+		// are answered through the relay.
+		if from != nil {
+			c.mu.Lock()
+			c.peerAddr = from
+			c.mu.Unlock()
+		}
+		c.sendSealed(sealedProbeAck, nil, from)
+	case sealedProbeAck:
+		c.ackOnce.Do(func() { close(c.acked) })
+	case sealedPing:
+		c.sendSealed(sealedPong, body, from)
+	case sealedPong:
+		c.recordPong(body, time.Now())
+	case sealedData:
+		select {
+		case c.incoming <- body:
+		default:
+			logrus.WithField("session", c.cfg.Session).Debug("peer inbox full, dropping packet")
+		}
+	}
+}
+
+// pingLoop measures the link until it closes.
+func (c *PeerConn) pingLoop() {
+	ticker := time.NewTicker(c.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-ticker.C:
+			c.expirePings(now)
+			c.mu.Lock()
+			c.pingSeq++
+			seq := c.pingSeq
+			c.pending[seq] = now
+			c.mu.Unlock()
+			c.sendSealed(sealedPing, binary.BigEndian.AppendUint32(nil, seq), c.route())
+		}
+	}
+}
+
+// recordPong folds an answered ping into the quality figures.
+func (c *PeerConn) recordPong(body []byte, now time.Time) {
+	if len(body) != 4 {
+		return
+	}
+	seq := binary.BigEndian.Uint32(body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sent, ok := c.pending[seq]
+	if !ok {
+		return
+	}
+	delete(c.pending, seq)
+	c.addOutcome(true)
+
+	// Smoothed as in RFC 6298 and RFC 3550
+	sample := now.Sub(sent)
+	if c.rtt == 0 {
+		c.rtt = sample
+		return
+	}
+	diff := sample - c.rtt
+	if diff < 0 {
+		diff = -diff
+	}
+	c.jitter += (diff - c.jitter) / 4
+	c.rtt += (sample - c.rtt) / 8
+}
+
+// expirePings counts pings unanswered for too long as lost.
+func (c *PeerConn) expirePings(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for seq, sent := range c.pending {
+		if now.Sub(sent) > pingTimeout {
+			delete(c.pending, seq)
+			c.addOutcome(false)
+		}
+	}
+}
+
+// addOutcome records whether a ping was answered. Callers hold c.mu.
+func (c *PeerConn) addOutcome(answered bool) {
+	c.outcomes = append(c.outcomes, answered)
+	if len(c.outcomes) > qualityWindow {
+		c.outcomes = c.outcomes[len(c.outcomes)-qualityWindow:]
+	}
+}
+
+// Send sends a payload to the peer. Like any UDP packet it may be lost.
+func (c *PeerConn) Send(payload []byte) error {
+	if len(payload) > MaxPeerPayload {
+		return ErrPeerPayloadSize
+	}
+	select {
+	case <-c.done:
+		return ErrPeerClosed
+	default:
+	}
+	return c.sendSealed(sealedData, payload, c.route())
+}
+
+// Receive waits for the next payload from the peer.
+func (c *PeerConn) Receive(ctx context.Context) ([]byte, error) {
+	select {
+	case data := <-c.incoming:
+		return data, nil
+	case <-c.done:
+		return nil, ErrPeerClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Path returns the route packets to the peer take.
+func (c *PeerConn) Path() PeerPath {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.path
+}
+
+// RemoteAddr returns the peer's public endpoint.
+func (c *PeerConn) RemoteAddr() *net.UDPAddr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.peerAddr
+}
+
+// Quality returns the link's path, round trip time and loss.
+func (c *PeerConn) Quality() ConnectionQuality {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	q := ConnectionQuality{Path: c.path, RTT: c.rtt, Jitter: c.jitter, Samples: len(c.outcomes)}
+	lost := 0
+	for _, answered := range c.outcomes {
+		if !answered {
+			lost++
+		}
+	}
+	if q.Samples > 0 {
+		q.Loss = float64(lost) / float64(q.Samples)
+	}
+	return q
+}
+
+// Close shuts the link down and closes its connection.
+func (c *PeerConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// relayHeader builds the length-prefixed session and peer ID that start a
+// relay packet.
+func relayHeader(session, peerID string) []byte {
+	header := make([]byte, 0, 2+len(session)+len(peerID))
+	header = append(header, byte(len(session)))
+	header = append(header, session...)
+	header = append(header, byte(len(peerID)))
+	return append(header, peerID...)
+}
+
+// parseRelayHeader splits a relay packet into its session, peer ID and
+// sealed message.
+func parseRelayHeader(data []byte) (session, peerID string, sealed []byte, ok bool) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return "", "", nil, false
+	}
+	session, data = string(data[1:1+int(data[0])]), data[1+int(data[0]):]
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return "", "", nil, false
+	}
+	peerID, data = string(data[1:1+int(data[0])]), data[1+int(data[0]):]
+	return session, peerID, data, session != "" && peerID != "" && len(data) > 0
+}
+
+// sameAddr reports whether two UDP addresses are the same endpoint.
+func sameAddr(a, b *net.UDPAddr) bool {
+	return a != nil && b != nil && a.Port == b.Port && a.IP.Equal(b.IP)
+}
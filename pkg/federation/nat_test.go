@@ -0,0 +1,272 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// startRendezvous runs a hub with only its rendezvous listening.
+func startRendezvous(t *testing.T) *FederationHub {
+	t.Helper()
+	hub := NewFederationHub()
+	if err := hub.StartRendezvous("127.0.0.1:0"); err != nil {
+		t.Fatalf("StartRendezvous failed: %v", err)
+	}
+	t.Cleanup(func() { hub.Stop() })
+	return hub
+}
+
+// listenUDP opens a local socket for a peer.
+func listenUDP(t *testing.T) *net.UDPConn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// connectPair links two peers through the hub at the same time.
+func connectPair(t *testing.T, hub *FederationHub, a, b PeerConfig) (*PeerConn, *PeerConn) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type result struct {
+		conn *PeerConn
+		err  error
+	}
+	results := make(chan result, 1)
+	b.HubAddr = hub.RendezvousAddr()
+	go func() {
+		conn, err := ConnectPeer(ctx, listenUDP(t), b)
+		results <- result{conn, err}
+	}()
+	a.HubAddr = hub.RendezvousAddr()
+	connA, err := ConnectPeer(ctx, listenUDP(t), a)
+	if err != nil {
+		t.Fatalf("peer %s: %v", a.PeerID, err)
+	}
+	t.Cleanup(func() { connA.Close() })
+	r := <-results
+	if r.err != nil {
+		t.Fatalf("peer %s: %v", b.PeerID, r.err)
+	}
+	t.Cleanup(func() { r.conn.Close() })
+	return connA, r.conn
+}
+
+// exchange sends a payload each way and checks it arrives.
+func exchange(t *testing.T, a, b *PeerConn) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for _, pair := range [][2]*PeerConn{{a, b}, {b, a}} {
+		if err := pair[0].Send([]byte("hello")); err != nil {
+			t.Fatalf("Send() failed: %v", err)
+		}
+		data, err := pair[1].Receive(ctx)
+		if err != nil || string(data) != "hello" {
+			t.Fatalf("Receive() = %q, %v", data, err)
+		}
+	}
+}
+
+func TestConnectPeer_Direct(t *testing.T) {
+	hub := startRendezvous(t)
+	a, b := connectPair(t, hub,
+		PeerConfig{Session: "host+joiner", PeerID: "host", Secret: "token", PingInterval: 20 * time.Millisecond},
+		PeerConfig{Session: "host+joiner", PeerID: "joiner", Secret: "token"})
+
+	if a.Path() != PathDirect || b.Path() != PathDirect {
+		t.Fatalf("paths %v and %v, want direct", a.Path(), b.Path())
+	}
+	if got, want := a.RemoteAddr().String(), b.conn.LocalAddr().String(); got != want {
+		t.Errorf("RemoteAddr() = %s, want %s", got, want)
+	}
+	exchange(t, a, b)
+	if stats := hub.RelayStats(); stats.Packets != 0 || stats.Sessions != 1 {
+		t.Errorf("RelayStats() = %+v, want one session and nothing relayed", stats)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for a.Quality().Samples < 3 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	q := a.Quality()
+	if q.Samples < 3 || q.Loss != 0 || q.RTT <= 0 || q.Path != PathDirect {
+		t.Errorf("Quality() = %+v", q)
+	}
+	if q.Grade() != "good" {
+		t.Errorf("Grade() = %q, want good on loopback", q.Grade())
+	}
+}
+
+func TestConnectPeer_RelayFallback(t *testing.T) {
+	hub := startRendezvous(t)
+	a, b := connectPair(t, hub,
+		PeerConfig{Session: "cgnat", PeerID: "a", ForceRelay: true, PingInterval: 20 * time.Millisecond},
+		PeerConfig{Session: "cgnat", PeerID: "b", ForceRelay: true})
+
+	if a.Path() != PathRelayed || b.Path() != PathRelayed {
+		t.Fatalf("paths %v and %v, want relayed", a.Path(), b.Path())
+	}
+	exchange(t, a, b)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for a.Quality().Samples == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if q := a.Quality(); q.Samples == 0 || q.Path != PathRelayed {
+		t.Errorf("Quality() = %+v", q)
+	}
+	stats := hub.RelayStats()
+	if stats.Packets < 2 || stats.Bytes == 0 {
+		t.Errorf("RelayStats() = %+v, want the exchange relayed", stats)
+	}
+}
+
+func TestConnectPeer_Errors(t *testing.T) {
+	hub := startRendezvous(t)
+	if _, err := ConnectPeer(context.Background(), listenUDP(t), PeerConfig{HubAddr: hub.RendezvousAddr()}); err == nil {
+		t.Error("ConnectPeer() without a session succeeded")
+	}
+
+	conn := listenUDP(t)
+	_, err := ConnectPeer(context.Background(), conn, PeerConfig{
+		HubAddr:           hub.RendezvousAddr(),
+		Session:           "alone",
+		PeerID:            "a",
+		RendezvousTimeout: 300 * time.Millisecond,
+	})
+	if !errors.Is(err, ErrRendezvousTimeout) {
+		t.Errorf("ConnectPeer() with no other peer: err = %v, want ErrRendezvousTimeout", err)
+	}
+	if _, err := conn.Write([]byte{0}); err == nil {
+		t.Error("failed ConnectPeer() should close its connection")
+	}
+}
+
+func TestConnectPeer_SecretMismatch(t *testing.T) {
+	hub := startRendezvous(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Peers whose keys differ, as when the hub swaps them, never link
+	errs := make(chan error, 2)
+	for _, peer := range []PeerConfig{
+		{Session: "s", PeerID: "a", Secret: "one"},
+		{Session: "s", PeerID: "b", Secret: "two"},
+	} {
+		peer.HubAddr = hub.RendezvousAddr()
+		peer.PunchTimeout = 200 * time.Millisecond
+		peer.RendezvousTimeout = 300 * time.Millisecond
+		go func() {
+			conn, err := ConnectPeer(ctx, listenUDP(t), peer)
+			if err == nil {
+				conn.Close()
+			}
+			errs <- err
+		}()
+	}
+	for range 2 {
+		if err := <-errs; !errors.Is(err, ErrPeerUnverified) {
+			t.Errorf("ConnectPeer() with another secret: err = %v, want ErrPeerUnverified", err)
+		}
+	}
+}
+
+func TestPeerConn_Closed(t *testing.T) {
+	hub := startRendezvous(t)
+	a, b := connectPair(t, hub, PeerConfig{Session: "s", PeerID: "a"}, PeerConfig{Session: "s", PeerID: "b"})
+
+	if err := a.Send(make([]byte, MaxPeerPayload+1)); !errors.Is(err, ErrPeerPayloadSize) {
+		t.Errorf("oversized Send(): err = %v", err)
+	}
+	a.Close()
+	if err := a.Send([]byte("x")); !errors.Is(err, ErrPeerClosed) {
+		t.Errorf("Send() after Close(): err = %v", err)
+	}
+	if _, err := a.Receive(context.Background()); !errors.Is(err, ErrPeerClosed) {
+		t.Errorf("Receive() after Close(): err = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := b.Receive(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Receive() with nothing sent: err = %v", err)
+	}
+}
+
+func TestRendezvous_RejectsStrangers(t *testing.T) {
+	hub := startRendezvous(t)
+	connectPair(t, hub,
+		PeerConfig{Session: "s", PeerID: "a", ForceRelay: true, PingInterval: time.Hour},
+		PeerConfig{Session: "s", PeerID: "b", ForceRelay: true, PingInterval: time.Hour})
+	hubAddr, _ := net.ResolveUDPAddr("udp", hub.RendezvousAddr())
+	relayed := hub.RelayStats().Packets
+	hub.rendezvous.mu.Lock()
+	addrA := hub.rendezvous.sessions["s"].peers["a"].addr.String()
+	hub.rendezvous.mu.Unlock()
+
+	// A third peer can't join, a stranger can't take a member's place, and
+	// it can't relay as one
+	stranger := listenUDP(t)
+	defer stranger.Close()
+	stranger.WriteToUDP(append([]byte{packetRegister}, `{"session":"s","peerID":"c","publicKey":"AAAA"}`...), hubAddr)
+	stranger.WriteToUDP(append([]byte{packetRegister}, `{"session":"s","peerID":"a","publicKey":"AAAA"}`...), hubAddr)
+	packet := append(append([]byte{packetRelay}, relayHeader("s", "a")...), "spoofed"...)
+	stranger.WriteToUDP(packet, hubAddr)
+
+	stranger.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if n, _, err := stranger.ReadFromUDP(make([]byte, maxPeerPacket)); err == nil {
+		t.Errorf("stranger got a %d byte reply", n)
+	}
+	if stats := hub.RelayStats(); stats.Packets != relayed {
+		t.Errorf("relayed %d packets from a stranger", stats.Packets-relayed)
+	}
+	hub.rendezvous.mu.Lock()
+	got := hub.rendezvous.sessions["s"].peers["a"].addr.String()
+	hub.rendezvous.mu.Unlock()
+	if got != addrA {
+		t.Errorf("re-registration moved peer a from %s to %s", addrA, got)
+	}
+}
+
+func TestConnectionQuality_Grade(t *testing.T) {
+	tests := []struct {
+		q    ConnectionQuality
+		want string
+	}{
+		{ConnectionQuality{}, "unknown"},
+		{ConnectionQuality{RTT: 30 * time.Millisecond, Samples: 10}, "good"},
+		{ConnectionQuality{RTT: 30 * time.Millisecond, Loss: 0.05, Samples: 10}, "fair"},
+		{ConnectionQuality{RTT: 150 * time.Millisecond, Samples: 10}, "fair"},
+		{ConnectionQuality{RTT: 400 * time.Millisecond, Samples: 10}, "poor"},
+		{ConnectionQuality{RTT: 30 * time.Millisecond, Loss: 0.5, Samples: 10}, "poor"},
+	}
+	for _, tt := range tests {
+		if got := tt.q.Grade(); got != tt.want {
+			t.Errorf("%+v.Grade() = %q, want %q", tt.q, got, tt.want)
+		}
+	}
+	if PathDirect.String() != "direct" || PathRelayed.String() != "relayed" {
+		t.Error("PeerPath.String() names")
+	}
+}
+
+func TestParseRelayHeader(t *testing.T) {
+	data := append(relayHeader("session", "peer"), 1, 2, 3)
+	session, peer, sealed, ok := parseRelayHeader(data)
+	if !ok || session != "session" || peer != "peer" || len(sealed) != 3 {
+		t.Errorf("parseRelayHeader() = %q, %q, %v, %v", session, peer, sealed, ok)
+	}
+	for _, bad := range [][]byte{nil, {5, 'a'}, {1, 'a', 9, 'b'}, relayHeader("s", "p")} {
+		if _, _, _, ok := parseRelayHeader(bad); ok {
+			t.Errorf("parseRelayHeader(%v) succeeded", bad)
+		}
+	}
+}
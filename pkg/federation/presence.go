@@ -0,0 +1,102 @@
+package federation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// PresenceVisibility says who may see which server a player is on.
+type PresenceVisibility string
+
+const (
+	// PresencePublic lets anyone look the player up. Players without
+	// presence settings are public.
+	PresencePublic PresenceVisibility = "public"
+
+	// PresenceFriends lets only lookups carrying one of the player's
+	// friend tokens find the player.
+	PresenceFriends PresenceVisibility = "friends"
+
+	// PresenceInvisible hides the player from every lookup.
+	PresenceInvisible PresenceVisibility = "invisible"
+)
+
+// PlayerPresence is a player's privacy settings, relayed by their game
+// server in its announcements.
+type PlayerPresence struct {
+	Visibility PresenceVisibility `json:"visibility,omitempty"`
+
+	// FriendTokens are hashes, from HashFriendToken, of the tokens the
+	// player has handed out. Each friend gets their own token, so one
+	// friend can be dropped without reissuing the rest.
+	FriendTokens []string `json:"friendTokens,omitempty"`
+}
+
+// NewFriendToken generates a token a player can give a friend to let them
+// look the player up while presence is friends-only.
+func NewFriendToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate friend token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashFriendToken returns the form of a friend token that goes in a
+// player's presence settings, so announcements never carry the tokens
+// themselves.
+func HashFriendToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// visibleTo reports whether a lookup with the given friend token may see
+// the player.
+func (p PlayerPresence) visibleTo(friendToken string) bool {
+	switch p.Visibility {
+	case "", PresencePublic:
+		return true
+	case PresenceFriends:
+		if friendToken == "" {
+			return false
+		}
+		hash := HashFriendToken(friendToken)
+		for _, allowed := range p.FriendTokens {
+			if subtle.ConstantTimeCompare([]byte(hash), []byte(allowed)) == 1 {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// Redacted returns a copy of the announcement safe to hand to any client:
+// players who aren't public are left out of its player list, and presence
+// settings and the source IP are dropped. The player count is kept.
+func (a *ServerAnnouncement) Redacted() *ServerAnnouncement {
+	redacted := *a
+	redacted.Presence = nil
+	redacted.SourceIP = ""
+	if len(a.Presence) > 0 {
+		redacted.PlayerList = make([]string, 0, len(a.PlayerList))
+		for _, id := range a.PlayerList {
+			if a.Presence[id].visibleTo("") {
+				redacted.PlayerList = append(redacted.PlayerList, id)
+			}
+		}
+	}
+	return &redacted
+}
+
+// FindPlayer answers a presence lookup, honouring the player's privacy
+// settings. A player hidden from the caller looks offline, so a lookup
+// can't tell a private player from an absent one.
+func (h *FederationHub) FindPlayer(req *PlayerLookupRequest) PlayerLookupResponse {
+	return h.lookupPlayer(req.PlayerID, req.FriendToken)
+}
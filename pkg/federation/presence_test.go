@@ -0,0 +1,105 @@
+package federation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// presenceHub returns a hub with one server hosting a public, a
+// friends-only and an invisible player, and the friends-only player's
+// friend token.
+func presenceHub(t *testing.T) (*FederationHub, string) {
+	t.Helper()
+	token, err := NewFriendToken()
+	if err != nil {
+		t.Fatalf("NewFriendToken failed: %v", err)
+	}
+	hub := NewFederationHub()
+	hub.RegisterServer(&ServerAnnouncement{
+		Name:       "s1",
+		Address:    "s1:7777",
+		Players:    3,
+		PlayerList: []string{"open", "shy", "ghost"},
+		Presence: map[string]PlayerPresence{
+			"shy":   {Visibility: PresenceFriends, FriendTokens: []string{HashFriendToken(token)}},
+			"ghost": {Visibility: PresenceInvisible},
+		},
+		Timestamp: time.Now(),
+	})
+	return hub, token
+}
+
+func TestFederationHub_FindPlayerPrivacy(t *testing.T) {
+	hub, token := presenceHub(t)
+	other, _ := NewFriendToken()
+
+	tests := []struct {
+		player, token string
+		online        bool
+	}{
+		{"open", "", true},
+		{"shy", "", false},
+		{"shy", other, false},
+		{"shy", token, true},
+		{"ghost", "", false},
+		{"ghost", token, false},
+	}
+	for _, tt := range tests {
+		resp := hub.FindPlayer(&PlayerLookupRequest{PlayerID: tt.player, FriendToken: tt.token})
+		if resp.Online != tt.online {
+			t.Errorf("FindPlayer(%s, token=%t) online = %v, want %v", tt.player, tt.token != "", resp.Online, tt.online)
+		}
+		if !resp.Online && resp.ServerName != "" {
+			t.Errorf("FindPlayer(%s) leaked server %q", tt.player, resp.ServerName)
+		}
+	}
+}
+
+func TestServerAnnouncement_Redacted(t *testing.T) {
+	hub, _ := presenceHub(t)
+	server := hub.QueryServers(&ServerQuery{})[0]
+
+	redacted := server.Redacted()
+	if len(redacted.PlayerList) != 1 || redacted.PlayerList[0] != "open" {
+		t.Errorf("player list = %v, want [open]", redacted.PlayerList)
+	}
+	if redacted.Presence != nil || redacted.Players != 3 {
+		t.Errorf("redacted = %+v, want no presence and the full count", redacted)
+	}
+	if len(server.PlayerList) != 3 {
+		t.Error("Redacted modified the registered announcement")
+	}
+}
+
+func TestFederationHub_QueryHidesPrivatePlayers(t *testing.T) {
+	hub, token := presenceHub(t)
+	if err := hub.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start hub: %v", err)
+	}
+	defer hub.Stop()
+	hubURL := fmt.Sprintf("http://%s", hub.GetAddr())
+
+	resp, err := http.Post(hubURL+"/query", "application/json", bytes.NewReader([]byte("{}")))
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var servers []ServerAnnouncement
+	if err := json.NewDecoder(resp.Body).Decode(&servers); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(servers) != 1 || len(servers[0].PlayerList) != 1 || servers[0].Presence != nil {
+		t.Errorf("servers = %+v, want only the public player", servers)
+	}
+
+	if result, err := LookupPlayer(hubURL, "shy", time.Second); err != nil || result.Online {
+		t.Errorf("LookupPlayer(shy) = %+v, %v; want offline", result, err)
+	}
+	if result, err := LookupFriend(hubURL, "shy", token, time.Second); err != nil || !result.Online {
+		t.Errorf("LookupFriend(shy) = %+v, %v; want online", result, err)
+	}
+}
@@ -0,0 +1,239 @@
+package federation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// punchSessionTimeout is how long a punch session lives without traffic.
+	punchSessionTimeout = 2 * time.Minute
+
+	// maxPunchSessions caps the sessions a hub tracks at once.
+	maxPunchSessions = 10000
+)
+
+// RelayStats counts a hub's punch sessions and relayed traffic.
+type RelayStats struct {
+	Sessions int    `json:"sessions"`
+	Packets  uint64 `json:"packets"`
+	Bytes    uint64 `json:"bytes"`
+}
+
+// punchPeer is a peer's endpoint as the hub saw it.
+type punchPeer struct {
+	addr      *net.UDPAddr
+	publicKey []byte
+}
+
+// punchSession pairs two peers trying to reach each other.
+type punchSession struct {
+	peers    map[string]*punchPeer
+	lastSeen time.Time
+}
+
+// other returns the session's peer that isn't peerID.
+func (s *punchSession) other(peerID string) (string, *punchPeer) {
+	for id, p := range s.peers {
+		if id != peerID {
+			return id, p
+		}
+	}
+	return "", nil
+}
+
+// rendezvous answers punch registrations over UDP and relays sealed
+// packets between the peers of a session when they can't reach each other
+// directly. It passes each peer the other's public key, so it is trusted
+// not to swap them; peers that share a PeerConfig.Secret don't need to
+// trust it, since their link key depends on a secret it never sees.
+type rendezvous struct {
+	conn     *net.UDPConn
+	sessions map[string]*punchSession
+	stats    RelayStats
+	mu       sync.Mutex
+}
+
+// StartRendezvous listens for UDP hole punching and relay traffic on addr.
+// Peers register here to learn each other's public endpoints, and fall back
+// to relaying through here when punching fails.
+func (h *FederationHub) StartRendezvous(addr string) error {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to resolve rendezvous address: %w", err)
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for rendezvous: %w", err)
+	}
+
+	r := &rendezvous{conn: conn, sessions: make(map[string]*punchSession)}
+	h.mu.Lock()
+	h.rendezvous = r
+	h.mu.Unlock()
+
+	go r.serve()
+	go func() {
+		ticker := time.NewTicker(h.cleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.ctx.Done():
+				conn.Close()
+				return
+			case now := <-ticker.C:
+				r.expire(now)
+			}
+		}
+	}()
+	return nil
+}
+
+// RendezvousAddr returns the UDP address the rendezvous listens on, or ""
+// if it isn't running.
+func (h *FederationHub) RendezvousAddr() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.rendezvous == nil {
+		return ""
+	}
+	return h.rendezvous.conn.LocalAddr().String()
+}
+
+// RelayStats returns the rendezvous' session count and relayed traffic.
+func (h *FederationHub) RelayStats() RelayStats {
+	h.mu.RLock()
+	r := h.rendezvous
+	h.mu.RUnlock()
+	if r == nil {
+		return RelayStats{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.stats
+	stats.Sessions = len(r.sessions)
+	return stats
+}
+
+// serve reads packets until the socket is closed.
+func (r *rendezvous) serve() {
+	buf := make([]byte, maxPeerPacket)
+	for {
+		n, from, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n == 0 {
+			continue
+		}
+		switch buf[0] {
+		case packetRegister:
+			r.handleRegister(buf[1:n], from)
+		case packetRelay:
+			r.handleRelay(buf[1:n], from)
+		}
+	}
+}
+
+// handleRegister records the peer's endpoint and, once the other peer has
+// registered too, tells it where to punch. Peers keep registering until
+// they hear back, so a lost reply just means answering the next one. A
+// peer's first registration holds: registering its ID again from another
+// endpoint or with another key is ignored, so nobody else can take its
+// place in the session.
+func (r *rendezvous) handleRegister(data []byte, from *net.UDPAddr) {
+	var reg punchRegistration
+	if err := json.Unmarshal(data, &reg); err != nil || reg.Session == "" || reg.PeerID == "" || len(reg.PublicKey) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	session, ok := r.sessions[reg.Session]
+	if !ok {
+		if len(r.sessions) >= maxPunchSessions {
+			r.mu.Unlock()
+			logrus.WithField("session", reg.Session).Warn("too many punch sessions")
+			return
+		}
+		session = &punchSession{peers: make(map[string]*punchPeer)}
+		r.sessions[reg.Session] = session
+	}
+	if peer, known := session.peers[reg.PeerID]; known {
+		if !sameAddr(peer.addr, from) || !bytes.Equal(peer.publicKey, reg.PublicKey) {
+			r.mu.Unlock()
+			logrus.WithFields(logrus.Fields{
+				"session": reg.Session,
+				"peer":    reg.PeerID,
+				"from":    from.String(),
+			}).Warn("ignored re-registration from another endpoint")
+			return
+		}
+	} else if len(session.peers) >= 2 {
+		r.mu.Unlock()
+		return
+	}
+	session.peers[reg.PeerID] = &punchPeer{addr: from, publicKey: reg.PublicKey}
+	session.lastSeen = time.Now()
+	otherID, other := session.other(reg.PeerID)
+	r.mu.Unlock()
+
+	if other == nil {
+		return
+	}
+	reply, err := json.Marshal(punchPeerInfo{PeerID: otherID, Addr: other.addr.String(), PublicKey: other.publicKey})
+	if err != nil {
+		return
+	}
+	r.conn.WriteToUDP(append([]byte{packetPeerInfo}, reply...), from)
+}
+
+// handleRelay forwards a sealed packet to the other peer of its session.
+// Only a registered peer sending from its registered endpoint can relay.
+func (r *rendezvous) handleRelay(data []byte, from *net.UDPAddr) {
+	sessionID, peerID, sealed, ok := parseRelayHeader(data)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	session, ok := r.sessions[sessionID]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	sender, ok := session.peers[peerID]
+	if !ok || !sameAddr(sender.addr, from) {
+		r.mu.Unlock()
+		return
+	}
+	_, other := session.other(peerID)
+	if other == nil {
+		r.mu.Unlock()
+		return
+	}
+	session.lastSeen = time.Now()
+	r.stats.Packets++
+	r.stats.Bytes += uint64(len(sealed))
+	to := other.addr
+	r.mu.Unlock()
+
+	r.conn.WriteToUDP(append([]byte{packetRelayed}, sealed...), to)
+}
+
+// expire drops sessions that have gone quiet.
+func (r *rendezvous) expire(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, s := range r.sessions {
+		if now.Sub(s.lastSeen) > punchSessionTimeout {
+			delete(r.sessions, id)
+			logrus.WithField("session", id).Debug("punch session expired")
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package sqlstore provides a SQLite-backed registry store for the
+// federation hub.
+//
+// The database runs in write-ahead-log mode with full synchronous commits,
+// so an announcement the hub has acknowledged survives a crash or power
+// loss, and a torn write rolls back rather than corrupting the registry.
+// Rows that still fail to decode are dropped on load instead of stopping
+// the hub from starting.
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/opd-ai/violence/pkg/federation"
+	"github.com/sirupsen/logrus"
+)
+
+// Store persists server announcements in a SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens or creates the registry database at path.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_synchronous=FULL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// One connection serialises writers and keeps the pragmas in force
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db}
+	if err := s.createTables(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"db_path": path,
+	}).Info("registry store opened")
+
+	return s, nil
+}
+
+// createTables initializes the database schema.
+func (s *Store) createTables() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS servers (
+		name TEXT PRIMARY KEY,
+		announcement TEXT NOT NULL,
+		announced_at INTEGER NOT NULL
+	);
+	`
+
+	if _, err := s.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create tables: %w", err)
+	}
+
+	return nil
+}
+
+// Save inserts or replaces the announcement for a server name.
+func (s *Store) Save(announcement *federation.ServerAnnouncement) error {
+	data, err := json.Marshal(announcement)
+	if err != nil {
+		return fmt.Errorf("failed to marshal announcement: %w", err)
+	}
+
+	query := `
+	INSERT INTO servers (name, announcement, announced_at)
+	VALUES (?, ?, ?)
+	ON CONFLICT(name) DO UPDATE SET
+		announcement = excluded.announcement,
+		announced_at = excluded.announced_at
+	`
+
+	if _, err := s.db.Exec(query, announcement.Name, string(data), announcement.Timestamp.UnixNano()); err != nil {
+		return fmt.Errorf("failed to save announcement: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a server's announcement, if there is one.
+func (s *Store) Delete(name string) error {
+	if _, err := s.db.Exec(`DELETE FROM servers WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete announcement: %w", err)
+	}
+	return nil
+}
+
+// Load returns every stored announcement. Rows that don't decode are
+// deleted and skipped.
+func (s *Store) Load() ([]*federation.ServerAnnouncement, error) {
+	rows, err := s.db.Query(`SELECT name, announcement FROM servers ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query announcements: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*federation.ServerAnnouncement
+	var corrupt []string
+	for rows.Next() {
+		var name, data string
+		if err := rows.Scan(&name, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan announcement: %w", err)
+		}
+		var announcement federation.ServerAnnouncement
+		if err := json.Unmarshal([]byte(data), &announcement); err != nil || announcement.Name != name {
+			corrupt = append(corrupt, name)
+			continue
+		}
+		results = append(results, &announcement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read announcements: %w", err)
+	}
+	rows.Close()
+
+	for _, name := range corrupt {
+		logrus.WithField("server_name", name).Warn("dropping unreadable stored server")
+		if err := s.Delete(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
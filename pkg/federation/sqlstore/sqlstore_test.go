@@ -0,0 +1,90 @@
+package sqlstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/federation"
+)
+
+func TestStore_SaveLoadDelete(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "registry.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now().Round(0)
+	store.Save(&federation.ServerAnnouncement{Name: "b", Players: 1, Timestamp: now})
+	store.Save(&federation.ServerAnnouncement{Name: "a", Players: 2, Timestamp: now})
+	store.Save(&federation.ServerAnnouncement{Name: "b", Players: 3, PlayerList: []string{"p"}, Timestamp: now})
+
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(saved) != 2 || saved[0].Name != "a" || saved[1].Players != 3 || len(saved[1].PlayerList) != 1 {
+		t.Fatalf("Load() = %+v", saved)
+	}
+	if !saved[1].Timestamp.Equal(now) {
+		t.Errorf("timestamp = %v, want %v", saved[1].Timestamp, now)
+	}
+
+	if err := store.Delete("a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if saved, _ := store.Load(); len(saved) != 1 {
+		t.Errorf("%d announcements after Delete(), want 1", len(saved))
+	}
+}
+
+func TestStore_DropsCorruptRows(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "registry.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer store.Close()
+
+	store.Save(&federation.ServerAnnouncement{Name: "good", Timestamp: time.Now()})
+	store.db.Exec(`INSERT INTO servers (name, announcement, announced_at) VALUES ('bad', '{not json', 0)`)
+
+	saved, err := store.Load()
+	if err != nil || len(saved) != 1 || saved[0].Name != "good" {
+		t.Fatalf("Load() = %+v, %v", saved, err)
+	}
+	var n int
+	store.db.QueryRow(`SELECT COUNT(*) FROM servers`).Scan(&n)
+	if n != 1 {
+		t.Errorf("%d rows after load, want the corrupt one deleted", n)
+	}
+}
+
+func TestStore_HubRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.db")
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	hub := federation.NewFederationHub()
+	if err := hub.SetStore(store); err != nil {
+		t.Fatalf("SetStore failed: %v", err)
+	}
+	hub.RegisterServer(&federation.ServerAnnouncement{Name: "s1", Address: "a:1", Timestamp: time.Now()})
+	hub.Stop()
+
+	store, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	restarted := federation.NewFederationHub()
+	if err := restarted.SetStore(store); err != nil {
+		t.Fatalf("SetStore failed: %v", err)
+	}
+	defer restarted.Stop()
+	servers := restarted.QueryServers(&federation.ServerQuery{})
+	if len(servers) != 1 || servers[0].Address != "a:1" {
+		t.Errorf("servers after restart = %+v", servers)
+	}
+}
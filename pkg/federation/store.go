@@ -0,0 +1,78 @@
+package federation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RegistryStore persists the hub's server registrations so that a restart
+// doesn't empty the browser. Implementations must be safe for concurrent
+// use; the hub writes through on every announcement and expiry.
+type RegistryStore interface {
+	// Save inserts or replaces the announcement for a server name.
+	Save(announcement *ServerAnnouncement) error
+	// Delete removes a server's announcement, if there is one.
+	Delete(name string) error
+	// Load returns every stored announcement, dropping any it can't read.
+	Load() ([]*ServerAnnouncement, error)
+	// Close releases the store.
+	Close() error
+}
+
+// SetStore attaches a persistent store to the hub and recovers the
+// registrations it holds. Announcements older than the stale timeout are
+// expired from the store rather than loaded. The hub closes the store when
+// it stops.
+func (h *FederationHub) SetStore(store RegistryStore) error {
+	saved, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load registry: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	recovered := 0
+	for _, announcement := range saved {
+		if now.Sub(announcement.Timestamp) > h.staleTimeout {
+			if err := store.Delete(announcement.Name); err != nil {
+				logrus.WithError(err).WithField("server_name", announcement.Name).Warn("failed to expire stored server")
+			}
+			continue
+		}
+		h.addServer(announcement)
+		recovered++
+	}
+	h.store = store
+
+	logrus.WithFields(logrus.Fields{
+		"recovered": recovered,
+		"expired":   len(saved) - recovered,
+	}).Info("server registry loaded")
+	return nil
+}
+
+// persist writes an announcement through to the store, if there is one
+// (must be called with lock held).
+func (h *FederationHub) persist(announcement *ServerAnnouncement) {
+	if h.store == nil {
+		return
+	}
+	if err := h.store.Save(announcement); err != nil {
+		logrus.WithError(err).WithField("server_name", announcement.Name).Warn("failed to persist server")
+	}
+}
+
+// unpersist removes a server from the store, if there is one (must be
+// called with lock held).
+func (h *FederationHub) unpersist(name string) {
+	if h.store == nil {
+		return
+	}
+	if err := h.store.Delete(name); err != nil {
+		logrus.WithError(err).WithField("server_name", name).Warn("failed to remove stored server")
+	}
+}
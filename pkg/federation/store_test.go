@@ -0,0 +1,122 @@
+package federation
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore is a RegistryStore that remembers announcements in a map.
+type memoryStore struct {
+	mu      sync.Mutex
+	servers map[string]ServerAnnouncement
+	closed  bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{servers: make(map[string]ServerAnnouncement)}
+}
+
+func (m *memoryStore) Save(a *ServerAnnouncement) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers[a.Name] = *a
+	return nil
+}
+
+func (m *memoryStore) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.servers, name)
+	return nil
+}
+
+func (m *memoryStore) Load() ([]*ServerAnnouncement, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var results []*ServerAnnouncement
+	for _, a := range m.servers {
+		a := a
+		results = append(results, &a)
+	}
+	return results, nil
+}
+
+func (m *memoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *memoryStore) has(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.servers[name]
+	return ok
+}
+
+func TestFederationHub_SetStoreRecovers(t *testing.T) {
+	store := newMemoryStore()
+	store.Save(&ServerAnnouncement{Name: "fresh", PlayerList: []string{"p1"}, Timestamp: time.Now()})
+	store.Save(&ServerAnnouncement{Name: "stale", Timestamp: time.Now().Add(-time.Hour)})
+
+	hub := NewFederationHub()
+	if err := hub.SetStore(store); err != nil {
+		t.Fatalf("SetStore failed: %v", err)
+	}
+	if hub.GetServerCount() != 1 {
+		t.Errorf("recovered %d servers, want 1", hub.GetServerCount())
+	}
+	if store.has("stale") {
+		t.Error("expired server left in the store")
+	}
+	if resp := hub.lookupPlayer("p1", ""); !resp.Online || resp.ServerName != "fresh" {
+		t.Errorf("lookupPlayer() after recovery = %+v", resp)
+	}
+
+	hub.Stop()
+	if !store.closed {
+		t.Error("Stop() didn't close the store")
+	}
+}
+
+func TestFederationHub_StoreWriteThrough(t *testing.T) {
+	store := newMemoryStore()
+	hub := NewFederationHub()
+	hub.SetStaleTimeout(50 * time.Millisecond)
+	if err := hub.SetStore(store); err != nil {
+		t.Fatalf("SetStore failed: %v", err)
+	}
+	defer hub.Stop()
+
+	hub.RegisterServer(&ServerAnnouncement{Name: "s1", Timestamp: time.Now()})
+	if !store.has("s1") {
+		t.Fatal("registration not written to the store")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	hub.removeStaleServerEntries()
+	if store.has("s1") {
+		t.Error("expired server not removed from the store")
+	}
+}
+
+// failingStore is a RegistryStore whose Load always fails.
+type failingStore struct{ memoryStore }
+
+func (f *failingStore) Load() ([]*ServerAnnouncement, error) {
+	return nil, errors.New("disk on fire")
+}
+
+func TestFederationHub_SetStoreLoadError(t *testing.T) {
+	hub := NewFederationHub()
+	if err := hub.SetStore(&failingStore{}); err == nil {
+		t.Error("SetStore() with a failing store succeeded")
+	}
+	hub.RegisterServer(&ServerAnnouncement{Name: "s1", Timestamp: time.Now()})
+	if hub.GetServerCount() != 1 {
+		t.Error("hub stopped registering after a store error")
+	}
+}
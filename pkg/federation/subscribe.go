@@ -0,0 +1,283 @@
+package federation
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// subscriberBuffer is how many events a subscriber can fall behind
+	// before the hub drops it.
+	subscriberBuffer = 64
+
+	subscribeQueryTimeout = 10 * time.Second
+	subscribeWriteTimeout = 5 * time.Second
+	subscribePingInterval = 30 * time.Second
+)
+
+// RegistryEventType says what happened to the registry.
+type RegistryEventType string
+
+const (
+	// EventSnapshot is the first event on a subscription, listing every
+	// server matching its query.
+	EventSnapshot RegistryEventType = "snapshot"
+
+	// EventRegistered is sent when a matching server registers or its
+	// announcement changes. Heartbeats that change nothing aren't sent.
+	EventRegistered RegistryEventType = "registered"
+
+	// EventExpired is sent when a server expires, or changes so that it no
+	// longer matches the subscription's query.
+	EventExpired RegistryEventType = "expired"
+)
+
+// RegistryEvent is a change to the hub's registry pushed to subscribers.
+type RegistryEvent struct {
+	Type    RegistryEventType     `json:"type"`
+	Server  *ServerAnnouncement   `json:"server,omitempty"`  // Registered and expired
+	Servers []*ServerAnnouncement `json:"servers,omitempty"` // Snapshot
+}
+
+// subscriber is one live subscription to registry events.
+type subscriber struct {
+	query  ServerQuery
+	events chan RegistryEvent
+}
+
+// Subscribe starts pushing events for servers matching the query. The
+// first event is a snapshot of the current matches. The channel is closed
+// when the subscription is cancelled, the hub stops, or the subscriber
+// falls more than subscriberBuffer events behind.
+func (h *FederationHub) Subscribe(query *ServerQuery) (<-chan RegistryEvent, func()) {
+	sub := &subscriber{query: *query, events: make(chan RegistryEvent, subscriberBuffer)}
+
+	h.mu.Lock()
+	snapshot := RegistryEvent{Type: EventSnapshot, Servers: []*ServerAnnouncement{}}
+	for _, server := range h.servers {
+		if h.matchesQuery(server, query) {
+			snapshot.Servers = append(snapshot.Servers, server.Redacted())
+		}
+	}
+	sub.events <- snapshot
+	if h.ctx.Err() != nil {
+		close(sub.events)
+	} else {
+		h.subscribers[sub] = true
+	}
+	h.mu.Unlock()
+
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.dropSubscriber(sub)
+	}
+	return sub.events, cancel
+}
+
+// SubscriberCount returns the number of live registry subscriptions.
+func (h *FederationHub) SubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers)
+}
+
+// dropSubscriber ends a subscription (must be called with lock held).
+func (h *FederationHub) dropSubscriber(sub *subscriber) {
+	if h.subscribers[sub] {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// dropAllSubscribers ends every subscription.
+func (h *FederationHub) dropAllSubscribers() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		h.dropSubscriber(sub)
+	}
+}
+
+// publishRegistered tells subscribers about a new or changed announcement
+// (must be called with lock held). Subscribers that matched the old
+// announcement but not the new one see it expire.
+func (h *FederationHub) publishRegistered(old, server *ServerAnnouncement) {
+	if old != nil && !announcementChanged(old, server) {
+		return
+	}
+	for sub := range h.subscribers {
+		switch {
+		case h.matchesQuery(server, &sub.query):
+			h.publish(sub, RegistryEvent{Type: EventRegistered, Server: server.Redacted()})
+		case old != nil && h.matchesQuery(old, &sub.query):
+			h.publish(sub, RegistryEvent{Type: EventExpired, Server: old.Redacted()})
+		}
+	}
+}
+
+// publishExpired tells subscribers a server has expired (must be called
+// with lock held).
+func (h *FederationHub) publishExpired(server *ServerAnnouncement) {
+	for sub := range h.subscribers {
+		if h.matchesQuery(server, &sub.query) {
+			h.publish(sub, RegistryEvent{Type: EventExpired, Server: server.Redacted()})
+		}
+	}
+}
+
+// publish queues an event for a subscriber, dropping the subscriber if
+// it has fallen too far behind (must be called with lock held).
+func (h *FederationHub) publish(sub *subscriber, event RegistryEvent) {
+	select {
+	case sub.events <- event:
+	default:
+		logrus.Debug("dropped slow registry subscriber")
+		h.dropSubscriber(sub)
+	}
+}
+
+// announcementChanged reports whether an announcement says anything new,
+// ignoring the timestamps and signature that change with every heartbeat.
+func announcementChanged(old, server *ServerAnnouncement) bool {
+	a, b := *old, *server
+	a.Timestamp, b.Timestamp = time.Time{}, time.Time{}
+	a.SignedAt, b.SignedAt = 0, 0
+	a.Signature, b.Signature = nil, nil
+	return !reflect.DeepEqual(a, b)
+}
+
+// ServeSubscribe upgrades a request to a WebSocket that pushes registry
+// events. The client sends a ServerQuery as its first message, or {} for
+// every server, and then only reads.
+func (h *FederationHub) ServeSubscribe(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logrus.WithError(err).Error("failed to upgrade websocket")
+		return
+	}
+	defer conn.Close()
+
+	var query ServerQuery
+	conn.SetReadDeadline(time.Now().Add(subscribeQueryTimeout))
+	if err := conn.ReadJSON(&query); err != nil {
+		logrus.WithError(err).Debug("subscriber sent no query")
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	events, cancel := h.Subscribe(&query)
+	defer cancel()
+
+	// The client only reads, so a read error means it has gone
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(subscribePingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(subscribeWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				logrus.WithError(err).Debug("subscriber write error")
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(subscribeWriteTimeout)); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
+
+// ServerSubscription is a live feed of registry events from a remote
+// federation hub.
+type ServerSubscription struct {
+	conn   *websocket.Conn
+	events chan RegistryEvent
+	done   chan struct{}
+	once   sync.Once
+}
+
+// SubscribeServers opens a live feed of events for servers matching the
+// query from a remote federation hub, given its HTTP URL.
+func SubscribeServers(hubURL string, query *ServerQuery, timeout time.Duration) (*ServerSubscription, error) {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	url := hubURL + "/subscribe"
+	if strings.HasPrefix(url, "http") {
+		url = "ws" + strings.TrimPrefix(url, "http")
+	}
+	dialer := websocket.Dialer{HandshakeTimeout: timeout}
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to federation hub: %w", err)
+	}
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	if err := conn.WriteJSON(query); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send query: %w", err)
+	}
+	conn.SetWriteDeadline(time.Time{})
+
+	s := &ServerSubscription{
+		conn:   conn,
+		events: make(chan RegistryEvent, subscriberBuffer),
+		done:   make(chan struct{}),
+	}
+	go s.readLoop()
+	return s, nil
+}
+
+// readLoop decodes events until the connection ends.
+func (s *ServerSubscription) readLoop() {
+	defer close(s.events)
+	for {
+		var event RegistryEvent
+		if err := s.conn.ReadJSON(&event); err != nil {
+			return
+		}
+		select {
+		case s.events <- event:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Events returns the subscription's events, closed when it ends.
+func (s *ServerSubscription) Events() <-chan RegistryEvent {
+	return s.events
+}
+
+// Close ends the subscription.
+func (s *ServerSubscription) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.conn.Close()
+	})
+	return err
+}
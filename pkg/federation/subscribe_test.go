@@ -0,0 +1,135 @@
+package federation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// nextEvent waits for a registry event.
+func nextEvent(t *testing.T, events <-chan RegistryEvent) RegistryEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("subscription ended")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event")
+	}
+	return RegistryEvent{}
+}
+
+// noEvent checks nothing more arrives.
+func noEvent(t *testing.T, events <-chan RegistryEvent) {
+	t.Helper()
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected %s event: %+v", event.Type, event.Server)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestFederationHub_Subscribe(t *testing.T) {
+	hub := NewFederationHub()
+	hub.RegisterServer(&ServerAnnouncement{Name: "coop1", Mode: "coop", Timestamp: time.Now()})
+	hub.RegisterServer(&ServerAnnouncement{Name: "ffa1", Mode: "ffa", Timestamp: time.Now()})
+
+	mode := "coop"
+	events, cancel := hub.Subscribe(&ServerQuery{Mode: &mode})
+	defer cancel()
+
+	snapshot := nextEvent(t, events)
+	if snapshot.Type != EventSnapshot || len(snapshot.Servers) != 1 || snapshot.Servers[0].Name != "coop1" {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+
+	hub.RegisterServer(&ServerAnnouncement{Name: "coop2", Mode: "coop", Timestamp: time.Now()})
+	if e := nextEvent(t, events); e.Type != EventRegistered || e.Server.Name != "coop2" {
+		t.Errorf("event = %s %+v", e.Type, e.Server)
+	}
+
+	// Heartbeats that change nothing, and other modes, aren't pushed
+	hub.RegisterServer(&ServerAnnouncement{Name: "coop2", Mode: "coop", Timestamp: time.Now().Add(time.Second)})
+	hub.RegisterServer(&ServerAnnouncement{Name: "ffa2", Mode: "ffa", Timestamp: time.Now()})
+	noEvent(t, events)
+
+	hub.RegisterServer(&ServerAnnouncement{Name: "coop2", Mode: "coop", Players: 3, Timestamp: time.Now()})
+	if e := nextEvent(t, events); e.Type != EventRegistered || e.Server.Players != 3 {
+		t.Errorf("event = %s %+v", e.Type, e.Server)
+	}
+
+	// Switching modes leaves the query
+	hub.RegisterServer(&ServerAnnouncement{Name: "coop1", Mode: "ffa", Timestamp: time.Now()})
+	if e := nextEvent(t, events); e.Type != EventExpired || e.Server.Name != "coop1" {
+		t.Errorf("event = %s %+v", e.Type, e.Server)
+	}
+
+	hub.SetStaleTimeout(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	hub.removeStaleServerEntries()
+	if e := nextEvent(t, events); e.Type != EventExpired || e.Server.Name != "coop2" {
+		t.Errorf("event = %s %+v", e.Type, e.Server)
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("events still open after cancel")
+	}
+}
+
+func TestFederationHub_SubscribeDropsSlowSubscribers(t *testing.T) {
+	hub := NewFederationHub()
+	events, cancel := hub.Subscribe(&ServerQuery{})
+	defer cancel()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		hub.RegisterServer(&ServerAnnouncement{Name: "s", Players: i, Timestamp: time.Now()})
+	}
+	n := 0
+	for range events {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("got %d events before being dropped, want %d", n, subscriberBuffer)
+	}
+
+	hub.Stop()
+	if events, _ := hub.Subscribe(&ServerQuery{}); len(events) != 1 {
+		t.Error("Subscribe() on a stopped hub should only return the snapshot")
+	}
+}
+
+func TestSubscribeServers(t *testing.T) {
+	hub := NewFederationHub()
+	hub.RegisterServer(&ServerAnnouncement{Name: "s1", Address: "a:1", Timestamp: time.Now()})
+	srv := httptest.NewServer(http.HandlerFunc(hub.ServeSubscribe))
+	defer srv.Close()
+
+	feed, err := SubscribeServers(srv.URL, &ServerQuery{}, time.Second)
+	if err != nil {
+		t.Fatalf("SubscribeServers failed: %v", err)
+	}
+	defer feed.Close()
+
+	if e := nextEvent(t, feed.Events()); e.Type != EventSnapshot || len(e.Servers) != 1 {
+		t.Fatalf("snapshot = %+v", e)
+	}
+	hub.RegisterServer(&ServerAnnouncement{Name: "s2", Address: "b:1", Timestamp: time.Now()})
+	if e := nextEvent(t, feed.Events()); e.Type != EventRegistered || e.Server.Address != "b:1" {
+		t.Errorf("event = %s %+v", e.Type, e.Server)
+	}
+
+	// Stopping the hub ends the feed
+	hub.Stop()
+	select {
+	case _, ok := <-feed.Events():
+		if ok {
+			t.Error("event after the hub stopped")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("feed still open after the hub stopped")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 	"math/rand"
 	"reflect"
+	"slices"
 
 	"github.com/opd-ai/violence/pkg/engine"
 	"github.com/sirupsen/logrus"
@@ -11,8 +12,9 @@ import (
 
 // ECSSystem manages environmental hazards using the ECS architecture.
 type ECSSystem struct {
-	rng   *rand.Rand
-	genre string
+	rng    *rand.Rand
+	genre  string
+	budget int
 }
 
 // NewECSSystem creates a new ECS-based hazard system.
@@ -28,6 +30,12 @@ func (s *ECSSystem) SetGenre(genre string) {
 	s.genre = genre
 }
 
+// SetBudget fixes the number of hazards GenerateHazards tries to place.
+// A budget of zero or less restores the default randomized count.
+func (s *ECSSystem) SetBudget(budget int) {
+	s.budget = budget
+}
+
 // Update advances hazard states and timers (implements System interface).
 func (s *ECSSystem) Update(w *engine.World) {
 	// Query all entities with HazardComponent
@@ -80,6 +88,9 @@ func (s *ECSSystem) GenerateHazards(w *engine.World, worldMap [][]int, seed int6
 	attempts := 0
 	maxAttempts := 100
 	targetCount := 5 + localRNG.Intn(10)
+	if s.budget > 0 {
+		targetCount = s.budget
+	}
 	placedCount := 0
 
 	for placedCount < targetCount && attempts < maxAttempts {
@@ -117,6 +128,63 @@ func (s *ECSSystem) GenerateHazards(w *engine.World, worldMap [][]int, seed int6
 	}).Debug("Generated environmental hazards")
 }
 
+// SpawnHazard places a random hazard of the current genre at (x, y), such
+// as one a boss activates in its arena, and returns its entity.
+func (s *ECSSystem) SpawnHazard(w *engine.World, x, y float64) engine.Entity {
+	hazardTypes := s.getGenreHazards()
+	hType := hazardTypes[s.rng.Intn(len(hazardTypes))]
+	entity := w.AddEntity()
+	w.AddComponent(entity, &PositionComponent{X: x, Y: y})
+	w.AddComponent(entity, s.createHazardComponent(hType, s.rng))
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "hazard_ecs",
+		"type":        hType,
+		"x":           x,
+		"y":           y,
+	}).Debug("Spawned hazard")
+	return entity
+}
+
+// Placed is a hazard and its position, as saved.
+type Placed struct {
+	X, Y   float64
+	Hazard HazardComponent
+}
+
+// Snapshot returns every hazard entity in the world, oldest first, to save.
+func (s *ECSSystem) Snapshot(w *engine.World) []Placed {
+	hazardType := reflect.TypeOf((*HazardComponent)(nil))
+	posType := reflect.TypeOf((*PositionComponent)(nil))
+
+	entities := w.Query(hazardType, posType)
+	slices.Sort(entities)
+	var out []Placed
+	for _, entity := range entities {
+		hazardComp, _ := w.GetComponent(entity, hazardType)
+		posComp, _ := w.GetComponent(entity, posType)
+		hazard, ok := hazardComp.(*HazardComponent)
+		pos, ok2 := posComp.(*PositionComponent)
+		if ok && ok2 {
+			out = append(out, Placed{X: pos.X, Y: pos.Y, Hazard: *hazard})
+		}
+	}
+	return out
+}
+
+// Restore replaces every hazard entity in the world with saved ones.
+func (s *ECSSystem) Restore(w *engine.World, hazards []Placed) {
+	for _, entity := range w.Query(reflect.TypeOf((*HazardComponent)(nil))) {
+		w.RemoveEntity(entity)
+	}
+	for _, p := range hazards {
+		entity := w.AddEntity()
+		w.AddComponent(entity, &PositionComponent{X: p.X, Y: p.Y})
+		hazard := p.Hazard
+		w.AddComponent(entity, &hazard)
+	}
+}
+
 // getGenreHazards returns hazard types appropriate for the current genre.
 func (s *ECSSystem) getGenreHazards() []Type {
 	return getGenreHazardTypes(s.genre)
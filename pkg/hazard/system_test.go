@@ -1,6 +1,7 @@
 package hazard
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/opd-ai/violence/pkg/engine"
@@ -49,6 +50,28 @@ func TestECSGenerateHazards(t *testing.T) {
 	}
 }
 
+func TestECSGenerateHazardsBudget(t *testing.T) {
+	testMap := make([][]int, 30)
+	for i := range testMap {
+		testMap[i] = make([]int, 30)
+		for j := range testMap[i] {
+			if i == 0 || i == 29 || j == 0 || j == 29 {
+				testMap[i][j] = 1
+			}
+		}
+	}
+
+	world := engine.NewWorld()
+	s := NewECSSystem(12345)
+	s.SetBudget(3)
+	s.GenerateHazards(world, testMap, 67890)
+
+	hazards := world.Query(reflect.TypeOf((*HazardComponent)(nil)))
+	if len(hazards) != 3 {
+		t.Errorf("placed %d hazards, want budget of 3", len(hazards))
+	}
+}
+
 func TestECSGenreHazards(t *testing.T) {
 	tests := []struct {
 		genre         string
@@ -192,6 +215,30 @@ func TestECSGetHazardsForRendering(t *testing.T) {
 	}
 }
 
+func TestECSSpawnHazard(t *testing.T) {
+	world := engine.NewWorld()
+	s := NewECSSystem(12345)
+	s.SetGenre("scifi")
+
+	s.SpawnHazard(world, 3.5, 4.5)
+
+	renderData := s.GetHazardsForRendering(world)
+	if len(renderData) != 1 {
+		t.Fatalf("Expected 1 hazard, got %d", len(renderData))
+	}
+	h := renderData[0]
+	if h.X != 3.5 || h.Y != 4.5 {
+		t.Errorf("Hazard at (%v, %v), want (3.5, 4.5)", h.X, h.Y)
+	}
+	valid := false
+	for _, typ := range getGenreHazardTypes("scifi") {
+		valid = valid || typ == h.Type
+	}
+	if !valid {
+		t.Errorf("Hazard type %v is not a scifi hazard", h.Type)
+	}
+}
+
 func TestHazardComponentTypes(t *testing.T) {
 	types := []Type{
 		TypeSpikeTrap,
@@ -255,3 +302,22 @@ func TestHazardStatusEffects(t *testing.T) {
 		}
 	}
 }
+
+func TestECSSnapshotRestore(t *testing.T) {
+	s := NewECSSystem(12345)
+	w := engine.NewWorld()
+	s.SpawnHazard(w, 3.5, 4.5)
+	s.SpawnHazard(w, 7.5, 2.5)
+	s.Update(w)
+	saved := s.Snapshot(w)
+	if len(saved) != 2 {
+		t.Fatalf("Snapshot returned %d hazards, want 2", len(saved))
+	}
+
+	loaded := engine.NewWorld()
+	NewECSSystem(1).SpawnHazard(loaded, 1, 1)
+	s.Restore(loaded, saved)
+	if got := s.Snapshot(loaded); !reflect.DeepEqual(got, saved) {
+		t.Errorf("restored hazards = %+v, want %+v", got, saved)
+	}
+}
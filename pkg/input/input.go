@@ -46,6 +46,12 @@ const (
 	ActionTurnLeft     Action = "turn_left"
 	ActionTurnRight    Action = "turn_right"
 	ActionFire         Action = "fire"
+	ActionAltFire      Action = "alt_fire"
+	ActionReload       Action = "reload"
+	ActionCycleAmmo    Action = "cycle_ammo"
+	ActionStash        Action = "stash"
+	ActionUnstash      Action = "unstash"
+	ActionMelee        Action = "melee"
 	ActionInteract     Action = "interact"
 	ActionAutomap      Action = "automap"
 	ActionPause        Action = "pause"
@@ -65,6 +71,17 @@ const (
 	ActionDodge        Action = "dodge"
 	ActionParry        Action = "parry"
 	ActionBlock        Action = "block"
+	ActionProfiler     Action = "profiler"
+	ActionScreenshot   Action = "screenshot"
+	ActionPhotoMode    Action = "photo_mode"
+	ActionSquadHold    Action = "squad_hold"
+	ActionSquadRegroup Action = "squad_regroup"
+	ActionSquadAttack  Action = "squad_attack"
+	ActionSquadAbility Action = "squad_ability"
+	ActionAIDebug      Action = "ai_debug"
+	ActionAIDump       Action = "ai_dump"
+	ActionPushToTalk   Action = "push_to_talk"
+	ActionScoreboard   Action = "scoreboard"
 )
 
 // Manager tracks input state and key bindings.
@@ -101,6 +118,12 @@ func (m *Manager) setDefaultBindings() {
 	m.bindings[ActionTurnLeft] = ebiten.KeyLeft
 	m.bindings[ActionTurnRight] = ebiten.KeyRight
 	m.bindings[ActionFire] = ebiten.KeySpace
+	m.bindings[ActionAltFire] = ebiten.KeyAltLeft
+	m.bindings[ActionReload] = ebiten.KeyT
+	m.bindings[ActionCycleAmmo] = ebiten.KeyJ
+	m.bindings[ActionStash] = ebiten.KeyO
+	m.bindings[ActionUnstash] = ebiten.KeyU
+	m.bindings[ActionMelee] = ebiten.KeyY
 	m.bindings[ActionInteract] = ebiten.KeyE
 	m.bindings[ActionAutomap] = ebiten.KeyTab
 	m.bindings[ActionPause] = ebiten.KeyEscape
@@ -120,6 +143,17 @@ func (m *Manager) setDefaultBindings() {
 	m.bindings[ActionDodge] = ebiten.KeyShift
 	m.bindings[ActionParry] = ebiten.KeyR
 	m.bindings[ActionBlock] = ebiten.KeyControl
+	m.bindings[ActionProfiler] = ebiten.KeyF3
+	m.bindings[ActionScreenshot] = ebiten.KeyF12
+	m.bindings[ActionPhotoMode] = ebiten.KeyP
+	m.bindings[ActionSquadHold] = ebiten.KeyG
+	m.bindings[ActionSquadRegroup] = ebiten.KeyH
+	m.bindings[ActionSquadAttack] = ebiten.KeyX
+	m.bindings[ActionSquadAbility] = ebiten.KeyV
+	m.bindings[ActionAIDebug] = ebiten.KeyF4
+	m.bindings[ActionAIDump] = ebiten.KeyF5
+	m.bindings[ActionPushToTalk] = ebiten.KeyCapsLock
+	m.bindings[ActionScoreboard] = ebiten.KeyBackquote
 
 	// Gamepad button bindings
 	m.gamepadButtons[ActionFire] = ebiten.GamepadButton0       // A/Cross
@@ -167,13 +201,18 @@ func (m *Manager) Update() {
 	}
 }
 
+// fireMouseButtons are the mouse buttons bound to the fire actions.
+var fireMouseButtons = map[Action]ebiten.MouseButton{
+	ActionFire:    ebiten.MouseButtonLeft,
+	ActionAltFire: ebiten.MouseButtonRight,
+	ActionMelee:   ebiten.MouseButtonMiddle,
+}
+
 // IsPressed returns true if the named action is currently pressed.
 func (m *Manager) IsPressed(action Action) bool {
-	// Check mouse button for fire action (standard FPS left-click)
-	if action == ActionFire {
-		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-			return true
-		}
+	// Check mouse buttons for fire actions (standard FPS left/right-click)
+	if btn, ok := fireMouseButtons[action]; ok && ebiten.IsMouseButtonPressed(btn) {
+		return true
 	}
 
 	// Check keyboard
@@ -197,11 +236,9 @@ func (m *Manager) IsPressed(action Action) bool {
 
 // IsJustPressed returns true if the action was pressed this frame.
 func (m *Manager) IsJustPressed(action Action) bool {
-	// Check mouse button for fire action (standard FPS left-click)
-	if action == ActionFire {
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			return true
-		}
+	// Check mouse buttons for fire actions (standard FPS left/right-click)
+	if btn, ok := fireMouseButtons[action]; ok && inpututil.IsMouseButtonJustPressed(btn) {
+		return true
 	}
 
 	// Check keyboard
@@ -32,6 +32,9 @@ func TestDefaultBindings(t *testing.T) {
 		{"turn left", ActionTurnLeft, ebiten.KeyLeft},
 		{"turn right", ActionTurnRight, ebiten.KeyRight},
 		{"fire", ActionFire, ebiten.KeySpace},
+		{"alt fire", ActionAltFire, ebiten.KeyAltLeft},
+		{"reload", ActionReload, ebiten.KeyT},
+		{"melee", ActionMelee, ebiten.KeyY},
 		{"interact", ActionInteract, ebiten.KeyE},
 		{"automap", ActionAutomap, ebiten.KeyTab},
 		{"pause", ActionPause, ebiten.KeyEscape},
@@ -42,6 +45,17 @@ func TestDefaultBindings(t *testing.T) {
 		{"weapon 5", ActionWeapon5, ebiten.Key5},
 		{"next weapon", ActionNextWeapon, ebiten.KeyQ},
 		{"prev weapon", ActionPrevWeapon, ebiten.KeyZ},
+		{"cycle ammo", ActionCycleAmmo, ebiten.KeyJ},
+		{"stash", ActionStash, ebiten.KeyO},
+		{"unstash", ActionUnstash, ebiten.KeyU},
+		{"squad hold", ActionSquadHold, ebiten.KeyG},
+		{"squad regroup", ActionSquadRegroup, ebiten.KeyH},
+		{"squad attack", ActionSquadAttack, ebiten.KeyX},
+		{"squad ability", ActionSquadAbility, ebiten.KeyV},
+		{"ai debug", ActionAIDebug, ebiten.KeyF4},
+		{"ai dump", ActionAIDump, ebiten.KeyF5},
+		{"push to talk", ActionPushToTalk, ebiten.KeyCapsLock},
+		{"scoreboard", ActionScoreboard, ebiten.KeyBackquote},
 	}
 
 	m := NewManager()
@@ -0,0 +1,272 @@
+package inventory
+
+import "fmt"
+
+// ArmorSlot is the part of the body an armor piece is worn on.
+type ArmorSlot int
+
+const (
+	SlotHead       ArmorSlot = iota // SlotHead holds helmets, hoods and masks.
+	SlotChest                       // SlotChest holds vests, coats and plate.
+	SlotLegs                        // SlotLegs holds greaves and leggings.
+	ArmorSlotCount                  // ArmorSlotCount is the number of armor slots.
+)
+
+// slotShare is each slot's share of its set's resistances, durability,
+// weight and value.
+var slotShare = [ArmorSlotCount]float64{0.3, 0.45, 0.25}
+
+// ArmorSet is a suit of armor made of one piece per slot. Its stats are
+// those of the whole suit, shared out between the pieces by slot.
+type ArmorSet struct {
+	ID          string
+	Name        string
+	Pieces      [ArmorSlotCount]string // Piece names by slot
+	Resistances map[string]float64     // Fraction of each combat damage type resisted
+	Bonus       map[string]float64     // Extra resistances for wearing every piece intact
+	Durability  float64                // Damage the suit takes the wear of before breaking
+	Weight      float64
+	Value       int // Shop price of the whole suit
+}
+
+// Armor is one wearable piece of an armor set. Pieces are carried as
+// inventory items under their ID until worn.
+type Armor struct {
+	ID          string
+	Name        string
+	Slot        ArmorSlot
+	Set         string             // ID of the set the piece belongs to
+	Resistances map[string]float64 // Fraction of each combat damage type resisted
+	Durability  float64            // Durability when new
+	Weight      float64
+	Value       int
+	Rarity      Rarity
+	Seed        uint64 // Seed its rarity and affixes were rolled from; 0 for plain pieces
+}
+
+// genreArmorSets are the armor sets found in each genre.
+var genreArmorSets = map[string][]ArmorSet{
+	"fantasy": {
+		{ID: "chainmail", Name: "Chainmail", Pieces: [ArmorSlotCount]string{"Chain Coif", "Chain Hauberk", "Chain Leggings"}, Resistances: map[string]float64{"physical": 0.25, "fire": -0.1}, Bonus: map[string]float64{"physical": 0.1}, Durability: 300, Weight: 24, Value: 450},
+		{ID: "warded", Name: "Warded Vestments", Pieces: [ArmorSlotCount]string{"Warded Hood", "Warded Robe", "Warded Breeches"}, Resistances: map[string]float64{"arcane": 0.3, "fire": 0.1}, Bonus: map[string]float64{"arcane": 0.15}, Durability: 180, Weight: 8, Value: 500},
+	},
+	"scifi": {
+		{ID: "exo", Name: "Exo Plating", Pieces: [ArmorSlotCount]string{"Exo Helm", "Exo Carapace", "Exo Greaves"}, Resistances: map[string]float64{"energy": 0.2, "plasma": 0.15, "physical": 0.1}, Bonus: map[string]float64{"energy": 0.1}, Durability: 320, Weight: 22, Value: 520},
+		{ID: "hazard", Name: "Hazard Suit", Pieces: [ArmorSlotCount]string{"Hazard Hood", "Hazard Suit", "Hazard Leggings"}, Resistances: map[string]float64{"toxic": 0.35, "fire": 0.1}, Bonus: map[string]float64{"toxic": 0.15}, Durability: 200, Weight: 10, Value: 420},
+	},
+	"horror": {
+		{ID: "riot", Name: "Riot Gear", Pieces: [ArmorSlotCount]string{"Riot Helmet", "Riot Vest", "Riot Pads"}, Resistances: map[string]float64{"physical": 0.3}, Bonus: map[string]float64{"physical": 0.1}, Durability: 280, Weight: 20, Value: 480},
+		{ID: "blessed", Name: "Blessed Vestments", Pieces: [ArmorSlotCount]string{"Blessed Cowl", "Blessed Cassock", "Blessed Wraps"}, Resistances: map[string]float64{"arcane": 0.35}, Bonus: map[string]float64{"arcane": 0.15, "toxic": 0.1}, Durability: 160, Weight: 6, Value: 520},
+	},
+	"cyberpunk": {
+		{ID: "weave", Name: "Ballistic Weave", Pieces: [ArmorSlotCount]string{"Weave Hood", "Weave Jacket", "Weave Pants"}, Resistances: map[string]float64{"physical": 0.25, "explosive": 0.1}, Bonus: map[string]float64{"physical": 0.1}, Durability: 260, Weight: 12, Value: 480},
+		{ID: "faraday", Name: "Faraday Mesh", Pieces: [ArmorSlotCount]string{"Faraday Cap", "Faraday Vest", "Faraday Leggings"}, Resistances: map[string]float64{"energy": 0.35, "arcane": 0.1}, Bonus: map[string]float64{"energy": 0.15}, Durability: 200, Weight: 9, Value: 500},
+	},
+	"postapoc": {
+		{ID: "scrap", Name: "Scrap Plate", Pieces: [ArmorSlotCount]string{"Scrap Helm", "Scrap Plate", "Scrap Greaves"}, Resistances: map[string]float64{"physical": 0.25, "explosive": 0.15}, Bonus: map[string]float64{"physical": 0.1}, Durability: 300, Weight: 26, Value: 400},
+		{ID: "rad", Name: "Rad Suit", Pieces: [ArmorSlotCount]string{"Gas Mask", "Rad Suit", "Rad Leggings"}, Resistances: map[string]float64{"toxic": 0.35, "fire": 0.15}, Bonus: map[string]float64{"toxic": 0.15}, Durability: 220, Weight: 11, Value: 450},
+	},
+}
+
+// ArmorSets returns the armor sets of the current genre.
+func ArmorSets() []ArmorSet {
+	return genreArmorSets[currentGenre]
+}
+
+// Piece returns the set's piece for a slot, with its share of the set's
+// stats.
+func (s ArmorSet) Piece(slot ArmorSlot) Armor {
+	share := slotShare[slot]
+	a := Armor{
+		ID:          fmt.Sprintf("%s_%s", s.ID, slotNames[slot]),
+		Name:        s.Pieces[slot],
+		Slot:        slot,
+		Set:         s.ID,
+		Resistances: make(map[string]float64, len(s.Resistances)),
+		Durability:  s.Durability * share,
+		Weight:      s.Weight * share,
+		Value:       int(float64(s.Value) * share),
+	}
+	for t, v := range s.Resistances {
+		a.Resistances[t] = v * share
+	}
+	return a
+}
+
+// slotNames name the slots in armor piece IDs.
+var slotNames = [ArmorSlotCount]string{"head", "chest", "legs"}
+
+// FindArmor looks up an armor piece of the current genre by item ID.
+func FindArmor(id string) (Armor, bool) {
+	for _, s := range ArmorSets() {
+		for slot := range ArmorSlotCount {
+			if a := s.Piece(slot); a.ID == id {
+				return a, true
+			}
+		}
+	}
+	return Armor{}, false
+}
+
+// FindRolledArmor looks up an armor piece of the current genre by item
+// ID, with the rarity and affixes rolled from seed.
+func FindRolledArmor(id string, seed uint64) (Armor, bool) {
+	a, ok := FindArmor(id)
+	if !ok || seed == 0 {
+		return a, ok
+	}
+	a = RollAffixes(seed, true).apply(a)
+	a.Seed = seed
+	return a, true
+}
+
+// findArmorSet looks up an armor set of the current genre by ID.
+func findArmorSet(id string) (ArmorSet, bool) {
+	for _, s := range ArmorSets() {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return ArmorSet{}, false
+}
+
+// Worn is an armor piece being worn, with the durability it has left.
+type Worn struct {
+	Armor
+	Condition float64 // Durability left; 0 when broken
+}
+
+// Broken reports whether the piece is worn out and protects no more.
+func (w *Worn) Broken() bool {
+	return w.Condition <= 0
+}
+
+// Loadout is the armor the player wears, one piece per slot.
+type Loadout struct {
+	Slots [ArmorSlotCount]*Worn
+
+	// wear is the durability lost by pieces taken off, so putting a piece
+	// back in the inventory does not mend it.
+	wear map[itemKey]float64
+}
+
+// itemKey identifies one kind of rolled item.
+type itemKey struct {
+	id   string
+	seed uint64
+}
+
+// NewLoadout creates a loadout with nothing worn.
+func NewLoadout() *Loadout {
+	return &Loadout{wear: make(map[itemKey]float64)}
+}
+
+// Equip takes the armor piece with the ID rolled from seed out of the
+// inventory and wears it, putting the piece it replaces back in the
+// inventory. A broken piece replaced is thrown away.
+func (l *Loadout) Equip(inv *Inventory, id string, seed uint64) (Armor, error) {
+	a, ok := FindRolledArmor(id, seed)
+	if !ok {
+		return Armor{}, fmt.Errorf("%s is not armor", id)
+	}
+	if !inv.Take(id, seed) {
+		return Armor{}, fmt.Errorf("no %s in inventory", id)
+	}
+	l.Unequip(inv, a.Slot)
+	key := itemKey{id, seed}
+	l.Slots[a.Slot] = &Worn{Armor: a, Condition: a.Durability - l.wear[key]}
+	delete(l.wear, key)
+	return a, nil
+}
+
+// Unequip takes off the piece worn in the slot and puts it back in the
+// inventory, unless it is broken. It reports whether a piece was worn.
+func (l *Loadout) Unequip(inv *Inventory, slot ArmorSlot) bool {
+	w := l.Slots[slot]
+	if w == nil {
+		return false
+	}
+	l.Slots[slot] = nil
+	if w.Broken() {
+		return true
+	}
+	if l.wear == nil {
+		l.wear = make(map[itemKey]float64)
+	}
+	l.wear[itemKey{w.ID, w.Seed}] = w.Durability - w.Condition
+	inv.Add(Item{ID: w.ID, Name: w.Name, Qty: 1, Seed: w.Seed})
+	return true
+}
+
+// Restore wears the armor piece with the ID rolled from seed in the
+// condition given, as when loading a save. It reports false for unknown
+// pieces.
+func (l *Loadout) Restore(id string, seed uint64, condition float64) bool {
+	a, ok := FindRolledArmor(id, seed)
+	if !ok {
+		return false
+	}
+	l.Slots[a.Slot] = &Worn{Armor: a, Condition: min(condition, a.Durability)}
+	return true
+}
+
+// FullSet returns the set every piece worn belongs to, when all of them
+// are intact.
+func (l *Loadout) FullSet() (ArmorSet, bool) {
+	set := ""
+	for _, w := range l.Slots {
+		if w == nil || w.Broken() || (set != "" && w.Set != set) {
+			return ArmorSet{}, false
+		}
+		set = w.Set
+	}
+	return findArmorSet(set)
+}
+
+// Resistances returns what the intact pieces worn resist between them,
+// with the set bonus of a full set.
+func (l *Loadout) Resistances() map[string]float64 {
+	res := make(map[string]float64)
+	for _, w := range l.Slots {
+		if w == nil || w.Broken() {
+			continue
+		}
+		for t, v := range w.Resistances {
+			res[t] += v
+		}
+	}
+	if s, ok := l.FullSet(); ok {
+		for t, v := range s.Bonus {
+			res[t] += v
+		}
+	}
+	return res
+}
+
+// Wear shares the wear of a hit of the amount out between the intact
+// pieces worn, by their slot's share, and returns those it broke.
+func (l *Loadout) Wear(amount float64) []Armor {
+	var broke []Armor
+	for slot, w := range l.Slots {
+		if w == nil || w.Broken() {
+			continue
+		}
+		w.Condition -= amount * slotShare[slot]
+		if w.Broken() {
+			w.Condition = 0
+			broke = append(broke, w.Armor)
+		}
+	}
+	return broke
+}
+
+// Weight returns the total weight of the pieces worn.
+func (l *Loadout) Weight() float64 {
+	total := 0.0
+	for _, w := range l.Slots {
+		if w != nil {
+			total += w.Weight
+		}
+	}
+	return total
+}
@@ -0,0 +1,124 @@
+package inventory
+
+import (
+	"math"
+	"testing"
+)
+
+// wearSet puts every piece of the set in the inventory and equips them.
+func wearSet(t *testing.T, l *Loadout, inv *Inventory, s ArmorSet) {
+	t.Helper()
+	for slot := range ArmorSlotCount {
+		a := s.Piece(slot)
+		inv.Add(Item{ID: a.ID, Name: a.Name, Qty: 1})
+		if _, err := l.Equip(inv, a.ID, 0); err != nil {
+			t.Fatalf("Equip(%s): %v", a.ID, err)
+		}
+	}
+}
+
+func TestArmorSets(t *testing.T) {
+	defer SetGenre("fantasy")
+	for genre, sets := range genreArmorSets {
+		SetGenre(genre)
+		if len(ArmorSets()) != len(sets) {
+			t.Errorf("%s has %d sets, want %d", genre, len(ArmorSets()), len(sets))
+		}
+		for _, s := range sets {
+			for slot := range ArmorSlotCount {
+				a := s.Piece(slot)
+				found, ok := FindArmor(a.ID)
+				if !ok || found.Name != s.Pieces[slot] || found.Slot != slot || found.Durability <= 0 {
+					t.Errorf("%s piece %s = %+v, %v", genre, a.ID, found, ok)
+				}
+			}
+		}
+	}
+}
+
+func TestLoadoutEquip(t *testing.T) {
+	SetGenre("fantasy")
+	inv := NewInventory()
+	l := NewLoadout()
+
+	if _, err := l.Equip(inv, "chainmail_head", 0); err == nil {
+		t.Error("equipped a piece not in the inventory")
+	}
+	if _, err := l.Equip(inv, "medkit", 0); err == nil {
+		t.Error("equipped a medkit")
+	}
+
+	inv.Add(Item{ID: "chainmail_head", Name: "Chain Coif", Qty: 1})
+	inv.Add(Item{ID: "warded_head", Name: "Warded Hood", Qty: 1})
+	if _, err := l.Equip(inv, "chainmail_head", 0); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Has("chainmail_head") || l.Slots[SlotHead].ID != "chainmail_head" {
+		t.Fatal("Equip did not move the piece from the inventory to the head")
+	}
+
+	// Swapping puts the worn piece back, and it keeps its wear.
+	l.Wear(20)
+	worn := l.Slots[SlotHead].Condition
+	if _, err := l.Equip(inv, "warded_head", 0); err != nil {
+		t.Fatal(err)
+	}
+	if !inv.Has("chainmail_head") {
+		t.Fatal("the replaced piece did not go back in the inventory")
+	}
+	l.Equip(inv, "chainmail_head", 0)
+	if got := l.Slots[SlotHead].Condition; got != worn {
+		t.Errorf("re-equipped piece has condition %v, want %v", got, worn)
+	}
+}
+
+func TestLoadoutResistances(t *testing.T) {
+	SetGenre("fantasy")
+	inv := NewInventory()
+	l := NewLoadout()
+	chainmail := genreArmorSets["fantasy"][0]
+
+	inv.Add(Item{ID: "chainmail_chest", Qty: 1})
+	l.Equip(inv, "chainmail_chest", 0)
+	if got := l.Resistances()["physical"]; math.Abs(got-0.25*0.45) > 1e-9 {
+		t.Errorf("chest alone resists %v physical, want %v", got, 0.25*0.45)
+	}
+	if _, ok := l.FullSet(); ok {
+		t.Error("one piece counted as a full set")
+	}
+
+	wearSet(t, l, inv, chainmail)
+	if s, ok := l.FullSet(); !ok || s.ID != "chainmail" {
+		t.Fatalf("FullSet() = %s, %v, want chainmail", s.ID, ok)
+	}
+	if got := l.Resistances()["physical"]; math.Abs(got-0.35) > 1e-9 {
+		t.Errorf("full chainmail resists %v physical, want 0.35 with the set bonus", got)
+	}
+
+	// A broken piece protects no more and breaks up the set.
+	broke := l.Wear(chainmail.Durability * 2)
+	if len(broke) != int(ArmorSlotCount) {
+		t.Fatalf("heavy wear broke %d pieces, want %d", len(broke), ArmorSlotCount)
+	}
+	if len(l.Resistances()) != 0 {
+		t.Errorf("broken armor resists %v", l.Resistances())
+	}
+	if l.Wear(10) != nil {
+		t.Error("broken pieces broke again")
+	}
+}
+
+func TestLoadoutRestore(t *testing.T) {
+	SetGenre("scifi")
+	defer SetGenre("fantasy")
+	l := NewLoadout()
+	if !l.Restore("exo_chest", 0, 40) || l.Slots[SlotChest].Condition != 40 {
+		t.Error("Restore did not wear the piece in its condition")
+	}
+	if l.Restore("chainmail_chest", 0, 40) {
+		t.Error("restored a piece from another genre")
+	}
+	if l.Weight() != l.Slots[SlotChest].Weight || ItemWeight("exo_chest") != l.Weight() {
+		t.Errorf("loadout weighs %v, want the chest's %v", l.Weight(), l.Slots[SlotChest].Weight)
+	}
+}
@@ -0,0 +1,64 @@
+package inventory
+
+import "sort"
+
+// StatDiff is one stat of an item set against the same stat of the gear it
+// would replace, for tooltips.
+type StatDiff struct {
+	Stat     string
+	Equipped float64
+	Item     float64
+}
+
+// Delta returns how much the item changes the stat; positive means more.
+func (d StatDiff) Delta() float64 {
+	return d.Item - d.Equipped
+}
+
+// Stats returns the armor piece's tooltip stats: its durability, weight
+// and a "resist_<type>" entry per damage type it resists.
+func (a Armor) Stats() map[string]float64 {
+	stats := map[string]float64{"durability": a.Durability, "weight": a.Weight}
+	for t, v := range a.Resistances {
+		stats["resist_"+t] = v
+	}
+	return stats
+}
+
+// Stats returns the item's tooltip stats. Armor has those of the rolled
+// piece; anything else has its potency and weight.
+func (it Item) Stats() map[string]float64 {
+	if a, ok := FindRolledArmor(it.ID, it.Seed); ok {
+		return a.Stats()
+	}
+	return map[string]float64{"potency": it.Potency(), "weight": it.Weight()}
+}
+
+// Compare diffs the item's stats against the gear it would replace, sorted
+// by stat. Armor is compared with the piece worn in its slot, at the
+// condition it is in, or with nothing when the slot is empty; anything
+// else is compared with a plain item of its kind.
+func (l *Loadout) Compare(item Item) []StatDiff {
+	candidate := item.Stats()
+	var equipped map[string]float64
+	if a, ok := FindArmor(item.ID); ok {
+		if w := l.Slots[a.Slot]; w != nil {
+			equipped = w.Stats()
+			equipped["durability"] = w.Condition
+		}
+	} else {
+		equipped = Item{ID: item.ID}.Stats()
+	}
+
+	diffs := make([]StatDiff, 0, len(candidate))
+	for stat, v := range candidate {
+		diffs = append(diffs, StatDiff{Stat: stat, Equipped: equipped[stat], Item: v})
+	}
+	for stat, v := range equipped {
+		if _, ok := candidate[stat]; !ok {
+			diffs = append(diffs, StatDiff{Stat: stat, Equipped: v})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Stat < diffs[j].Stat })
+	return diffs
+}
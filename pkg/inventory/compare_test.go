@@ -0,0 +1,59 @@
+package inventory
+
+import "testing"
+
+// diffOf returns the diff of the stat, failing when it is missing.
+func diffOf(t *testing.T, diffs []StatDiff, stat string) StatDiff {
+	t.Helper()
+	for _, d := range diffs {
+		if d.Stat == stat {
+			return d
+		}
+	}
+	t.Fatalf("no %s in %+v", stat, diffs)
+	return StatDiff{}
+}
+
+func TestCompareArmor(t *testing.T) {
+	SetGenre("fantasy")
+	inv := NewInventory()
+	l := NewLoadout()
+	coif, _ := FindArmor("chainmail_head")
+	hood, _ := FindArmor("warded_head")
+
+	// Against an empty slot every stat is a gain.
+	diffs := l.Compare(Item{ID: "chainmail_head"})
+	if d := diffOf(t, diffs, "resist_physical"); d.Equipped != 0 || d.Item != coif.Resistances["physical"] {
+		t.Errorf("physical against an empty head = %+v", d)
+	}
+
+	inv.Add(Item{ID: "chainmail_head", Qty: 1})
+	l.Equip(inv, "chainmail_head", 0)
+	l.Wear(10)
+	diffs = l.Compare(Item{ID: "warded_head"})
+	for i := 1; i < len(diffs); i++ {
+		if diffs[i-1].Stat >= diffs[i].Stat {
+			t.Fatalf("diffs not sorted: %+v", diffs)
+		}
+	}
+	if d := diffOf(t, diffs, "resist_physical"); d.Item != 0 || d.Delta() >= 0 {
+		t.Errorf("the hood should lose the coif's physical resistance: %+v", d)
+	}
+	if d := diffOf(t, diffs, "resist_arcane"); d.Delta() != hood.Resistances["arcane"] {
+		t.Errorf("arcane diff = %+v", d)
+	}
+	if d := diffOf(t, diffs, "durability"); d.Equipped != l.Slots[SlotHead].Condition {
+		t.Errorf("durability compares against %v, want the worn condition %v", d.Equipped, l.Slots[SlotHead].Condition)
+	}
+}
+
+func TestCompareConsumable(t *testing.T) {
+	seed := uint64(1)
+	for (Item{ID: "medkit", Seed: seed}).Potency() <= 1 {
+		seed++
+	}
+	d := diffOf(t, (&Loadout{}).Compare(Item{ID: "medkit", Seed: seed}), "potency")
+	if d.Equipped != 1 || d.Delta() <= 0 {
+		t.Errorf("potent medkit diff = %+v", d)
+	}
+}
@@ -0,0 +1,131 @@
+package inventory
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrContainerFull is returned when a deposit needs a stack the container
+// has no room for.
+var ErrContainerFull = errors.New("container is full")
+
+// ErrNotStored is returned when moving more of an item than its container
+// or inventory holds.
+var ErrNotStored = errors.New("not enough of the item stored")
+
+// Container is player-owned storage. Persistent containers are kept
+// across levels and runs; the rest belong to the level they are on.
+type Container struct {
+	ID         string
+	Name       string
+	Capacity   int  // Stacks it holds; 0 for no limit
+	Persistent bool // Kept across levels and runs
+	Items      []Item
+	mu         sync.RWMutex
+}
+
+// NewContainer creates an empty container.
+func NewContainer(id, name string, capacity int, persistent bool) *Container {
+	return &Container{ID: id, Name: name, Capacity: capacity, Persistent: persistent}
+}
+
+// Deposit stores the item, stacking it with items of the same roll.
+// Returns ErrContainerFull if it needs a new stack and none is free.
+func (c *Container) Deposit(item Item) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if item.Qty <= 0 {
+		return nil
+	}
+	for i := range c.Items {
+		if c.Items[i].ID == item.ID && c.Items[i].Seed == item.Seed {
+			c.Items[i].Qty += item.Qty
+			return nil
+		}
+	}
+	if c.Capacity > 0 && len(c.Items) >= c.Capacity {
+		return ErrContainerFull
+	}
+	c.Items = append(c.Items, item)
+	return nil
+}
+
+// Withdraw takes qty of the item with the ID rolled from seed out of the
+// container and returns them. Returns ErrNotStored if it holds fewer.
+func (c *Container) Withdraw(id string, seed uint64, qty int) (Item, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.Items {
+		it := &c.Items[i]
+		if it.ID != id || it.Seed != seed {
+			continue
+		}
+		if it.Qty < qty || qty <= 0 {
+			break
+		}
+		out := *it
+		out.Qty = qty
+		it.Qty -= qty
+		if it.Qty == 0 {
+			c.Items = append(c.Items[:i], c.Items[i+1:]...)
+		}
+		return out, nil
+	}
+	return Item{}, ErrNotStored
+}
+
+// Contents returns a copy of the items stored.
+func (c *Container) Contents() []Item {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Item(nil), c.Items...)
+}
+
+// Free returns the stacks left free, or -1 for a container with no limit.
+func (c *Container) Free() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.Capacity <= 0 {
+		return -1
+	}
+	return c.Capacity - len(c.Items)
+}
+
+// DepositFrom moves qty of the item with the ID rolled from seed from the
+// inventory into the container. Nothing moves if either side refuses.
+func (c *Container) DepositFrom(inv *Inventory, id string, seed uint64, qty int) error {
+	item := Item{ID: id, Seed: seed, Qty: qty}
+	held := 0
+	inv.mu.RLock()
+	for _, it := range inv.Items {
+		if it.ID == id && it.Seed == seed {
+			item.Name, held = it.Name, it.Qty
+		}
+	}
+	inv.mu.RUnlock()
+	if qty <= 0 || held < qty {
+		return ErrNotStored
+	}
+
+	if err := c.Deposit(item); err != nil {
+		return err
+	}
+	for range qty {
+		inv.Take(id, seed)
+	}
+	return nil
+}
+
+// WithdrawTo moves qty of the item with the ID rolled from seed from the
+// container into the inventory.
+func (c *Container) WithdrawTo(inv *Inventory, id string, seed uint64, qty int) error {
+	item, err := c.Withdraw(id, seed, qty)
+	if err != nil {
+		return err
+	}
+	inv.Add(item)
+	return nil
+}
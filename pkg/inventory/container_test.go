@@ -0,0 +1,69 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestContainerCapacity(t *testing.T) {
+	c := NewContainer("cache", "Cache", 2, false)
+	if err := c.Deposit(Item{ID: "scrap_salvage", Qty: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Deposit(Item{ID: "weapon_roll_postapoc", Qty: 1, Seed: 7}); err != nil {
+		t.Fatal(err)
+	}
+	// Stacking needs no free stack; a new kind does.
+	if err := c.Deposit(Item{ID: "scrap_salvage", Qty: 5}); err != nil {
+		t.Errorf("stacking into a full container: %v", err)
+	}
+	if err := c.Deposit(Item{ID: "medkit", Qty: 1}); !errors.Is(err, ErrContainerFull) {
+		t.Errorf("new stack in a full container: %v, want ErrContainerFull", err)
+	}
+	if c.Free() != 0 {
+		t.Errorf("Free() = %d, want 0", c.Free())
+	}
+
+	it, err := c.Withdraw("scrap_salvage", 0, 15)
+	if err != nil || it.Qty != 15 {
+		t.Fatalf("Withdraw = %+v, %v", it, err)
+	}
+	if _, err := c.Withdraw("weapon_roll_postapoc", 0, 1); !errors.Is(err, ErrNotStored) {
+		t.Errorf("withdrew a weapon under the wrong seed: %v", err)
+	}
+	if c.Free() != 1 || len(c.Contents()) != 1 {
+		t.Errorf("after withdrawing the scrap: %d free, contents %+v", c.Free(), c.Contents())
+	}
+	if NewContainer("stash", "Stash", 0, true).Free() != -1 {
+		t.Error("unlimited container reports a limit")
+	}
+}
+
+func TestContainerTransfer(t *testing.T) {
+	inv := NewInventory()
+	inv.Add(Item{ID: "medkit", Name: "Medkit", Qty: 3})
+	c := NewContainer("stash", "Stash", 1, true)
+
+	if err := c.DepositFrom(inv, "medkit", 0, 4); !errors.Is(err, ErrNotStored) {
+		t.Errorf("deposited more medkits than carried: %v", err)
+	}
+	if err := c.DepositFrom(inv, "medkit", 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Get("medkit").Qty != 1 || c.Contents()[0].Qty != 2 || c.Contents()[0].Name != "Medkit" {
+		t.Errorf("after depositing: inventory %+v, container %+v", inv.Items, c.Contents())
+	}
+
+	// A refused deposit leaves the inventory alone.
+	inv.Add(Item{ID: "grenade", Name: "Grenade", Qty: 1})
+	if err := c.DepositFrom(inv, "grenade", 0, 1); !errors.Is(err, ErrContainerFull) || !inv.Has("grenade") {
+		t.Errorf("deposit into a full container: %v", err)
+	}
+
+	if err := c.WithdrawTo(inv, "medkit", 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Get("medkit").Qty != 3 || len(c.Contents()) != 0 {
+		t.Errorf("after withdrawing: inventory %+v, container %+v", inv.Items, c.Contents())
+	}
+}
@@ -107,6 +107,7 @@ type Item struct {
 	ID   string
 	Name string
 	Qty  int
+	Seed uint64 // Seed its rarity and affixes were rolled from; 0 for plain items
 }
 
 // Inventory holds the player's items.
@@ -164,6 +165,7 @@ func NewInventory() *Inventory {
 
 // Add places an item into the inventory.
 // If item already exists, increases quantity instead of adding duplicate.
+// Rolled items only stack with items of the same roll.
 func (inv *Inventory) Add(item Item) {
 	inv.mu.Lock()
 	defer inv.mu.Unlock()
@@ -172,7 +174,7 @@ func (inv *Inventory) Add(item Item) {
 		inv.Items = []Item{}
 	}
 	for i := range inv.Items {
-		if inv.Items[i].ID == item.ID {
+		if inv.Items[i].ID == item.ID && inv.Items[i].Seed == item.Seed {
 			inv.Items[i].Qty += item.Qty
 			return
 		}
@@ -255,6 +257,25 @@ func (inv *Inventory) Consume(id string, amount int) bool {
 	return false
 }
 
+// Take removes one of the item with the ID rolled from seed.
+// Returns false if there is none.
+func (inv *Inventory) Take(id string, seed uint64) bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+
+	for i := range inv.Items {
+		if inv.Items[i].ID != id || inv.Items[i].Seed != seed {
+			continue
+		}
+		inv.Items[i].Qty--
+		if inv.Items[i].Qty <= 0 {
+			inv.Items = append(inv.Items[:i], inv.Items[i+1:]...)
+		}
+		return true
+	}
+	return false
+}
+
 // Use consumes or activates an item by ID.
 // Returns true if item was used successfully.
 func (inv *Inventory) Use(id string) bool {
@@ -0,0 +1,213 @@
+package inventory
+
+import (
+	"strings"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// Rarity is the quality tier an item was rolled at.
+type Rarity int
+
+const (
+	RarityCommon    Rarity = iota // RarityCommon items roll no affixes.
+	RarityUncommon                // RarityUncommon items roll one affix.
+	RarityRare                    // RarityRare items roll a prefix and a suffix.
+	RarityEpic                    // RarityEpic items roll stronger affixes.
+	RarityLegendary               // RarityLegendary items roll the strongest affixes.
+)
+
+// rarityNames are the display names of each rarity.
+var rarityNames = [...]string{"Common", "Uncommon", "Rare", "Epic", "Legendary"}
+
+// Label returns the rarity's display name.
+func (r Rarity) Label() string {
+	if r < 0 || int(r) >= len(rarityNames) {
+		return rarityNames[RarityCommon]
+	}
+	return rarityNames[r]
+}
+
+// Rolled item tables by rarity: the odds out of 100 of rolling each tier
+// and the strength of its affixes.
+var (
+	rarityWeights  = [...]int{52, 27, 13, 6, 2}
+	rarityStrength = [...]float64{1, 1, 1, 1.25, 1.5}
+)
+
+// Affix is a named modifier rolled onto armor or a consumable.
+// Multipliers of 0 leave the stat unchanged.
+type Affix struct {
+	Name        string
+	Resistances map[string]float64 // Extra resistances; armor only
+	Durability  float64            // Durability multiplier; armor only
+	Potency     float64            // Healing or damage multiplier; consumables only
+	Weight      float64            // Weight multiplier
+}
+
+// Affix tables, by whether they go before or after the item's name.
+var (
+	armorPrefixes = []Affix{
+		{Name: "Sturdy", Durability: 1.4},
+		{Name: "Light", Weight: 0.6},
+		{Name: "Reinforced", Durability: 1.2, Resistances: map[string]float64{"physical": 0.04}},
+		{Name: "Padded", Resistances: map[string]float64{"explosive": 0.06}},
+	}
+	armorSuffixes = []Affix{
+		{Name: "of Warding", Resistances: map[string]float64{"arcane": 0.06}},
+		{Name: "of Insulation", Resistances: map[string]float64{"energy": 0.06}},
+		{Name: "of the Salamander", Resistances: map[string]float64{"fire": 0.06}},
+		{Name: "of Purity", Resistances: map[string]float64{"toxic": 0.06}},
+	}
+	consumablePrefixes = []Affix{
+		{Name: "Potent", Potency: 1.3},
+		{Name: "Compact", Weight: 0.5},
+		{Name: "Concentrated", Potency: 1.5, Weight: 1.2},
+	}
+	consumableSuffixes = []Affix{
+		{Name: "of Vigor", Potency: 1.2},
+		{Name: "of Lightness", Weight: 0.7},
+	}
+)
+
+// Roll is the rarity and affixes an item rolled from its seed.
+type Roll struct {
+	Rarity   Rarity
+	Prefix   *Affix
+	Suffix   *Affix
+	Strength float64 // Strength of the affixes, by rarity
+}
+
+// RollAffixes rolls a rarity and affixes from seed for armor or a
+// consumable: none for common items, one for uncommon ones and a prefix
+// and a suffix, growing stronger, for the rest. Seed 0 is a plain item
+// and always rolls common. The same seed always rolls the same.
+func RollAffixes(seed uint64, armor bool) Roll {
+	if seed == 0 {
+		return Roll{Strength: 1}
+	}
+	r := rng.NewRNG(seed)
+	rarity := RarityCommon
+	for roll := r.Intn(100); roll >= rarityWeights[rarity]; rarity++ {
+		roll -= rarityWeights[rarity]
+	}
+	out := Roll{Rarity: rarity, Strength: rarityStrength[rarity]}
+
+	prefixes, suffixes := consumablePrefixes, consumableSuffixes
+	if armor {
+		prefixes, suffixes = armorPrefixes, armorSuffixes
+	}
+	switch rarity {
+	case RarityUncommon:
+		if r.Intn(2) == 0 {
+			out.Prefix = &prefixes[r.Intn(len(prefixes))]
+		} else {
+			out.Suffix = &suffixes[r.Intn(len(suffixes))]
+		}
+	case RarityRare, RarityEpic, RarityLegendary:
+		out.Prefix = &prefixes[r.Intn(len(prefixes))]
+		out.Suffix = &suffixes[r.Intn(len(suffixes))]
+	}
+	return out
+}
+
+// affixes returns the affixes rolled.
+func (r Roll) affixes() []*Affix {
+	var out []*Affix
+	for _, f := range []*Affix{r.Prefix, r.Suffix} {
+		if f != nil {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Name returns the item name with the rolled affixes around it.
+func (r Roll) Name(base string) string {
+	parts := []string{base}
+	if r.Prefix != nil {
+		parts = append([]string{r.Prefix.Name}, parts...)
+	}
+	if r.Suffix != nil {
+		parts = append(parts, r.Suffix.Name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// multiplier returns the product of the affixes' multipliers picked by
+// stat, at the roll's strength.
+func (r Roll) multiplier(stat func(Affix) float64) float64 {
+	m := 1.0
+	for _, f := range r.affixes() {
+		if v := stat(*f); v != 0 {
+			m *= 1 + (v-1)*r.Strength
+		}
+	}
+	return m
+}
+
+// apply returns the armor piece with the roll's rarity and affixes.
+func (r Roll) apply(a Armor) Armor {
+	a.Rarity = r.Rarity
+	a.Name = r.Name(a.Name)
+	a.Durability *= r.multiplier(func(f Affix) float64 { return f.Durability })
+	a.Weight *= r.multiplier(func(f Affix) float64 { return f.Weight })
+	a.Value <<= r.Rarity
+
+	res := make(map[string]float64, len(a.Resistances))
+	for t, v := range a.Resistances {
+		res[t] = v
+	}
+	for _, f := range r.affixes() {
+		for t, v := range f.Resistances {
+			res[t] += v * r.Strength
+		}
+	}
+	a.Resistances = res
+	return a
+}
+
+// RollItem returns one of the item with the ID, named name, rolled from
+// seed. Common rolls come back plain, with seed 0, so they stack with the
+// rest of their kind.
+func RollItem(id, name string, seed uint64) Item {
+	_, armor := FindArmor(id)
+	roll := RollAffixes(seed, armor)
+	if roll.Rarity == RarityCommon {
+		return Item{ID: id, Name: name, Qty: 1}
+	}
+	return Item{ID: id, Name: roll.Name(name), Qty: 1, Seed: seed}
+}
+
+// RollItemAtLeast is RollItem for the first seed from seed on that rolls
+// at least the rarity, for loot that guarantees a tier.
+func RollItemAtLeast(id, name string, seed uint64, rarity Rarity) Item {
+	_, armor := FindArmor(id)
+	if seed == 0 {
+		seed = 1
+	}
+	for RollAffixes(seed, armor).Rarity < rarity && rarity <= RarityLegendary {
+		seed++
+	}
+	return RollItem(id, name, seed)
+}
+
+// Rarity returns the rarity the item rolled.
+func (it Item) Rarity() Rarity {
+	_, armor := FindArmor(it.ID)
+	return RollAffixes(it.Seed, armor).Rarity
+}
+
+// Potency returns the multiplier the item's affixes put on the healing or
+// damage of a consumable; 1 for plain items.
+func (it Item) Potency() float64 {
+	return RollAffixes(it.Seed, false).multiplier(func(f Affix) float64 { return f.Potency })
+}
+
+// Weight returns the carry weight of one of the item, with its affixes.
+func (it Item) Weight() float64 {
+	if a, ok := FindRolledArmor(it.ID, it.Seed); ok {
+		return a.Weight
+	}
+	return ItemWeight(it.ID) * RollAffixes(it.Seed, false).multiplier(func(f Affix) float64 { return f.Weight })
+}
@@ -0,0 +1,107 @@
+package inventory
+
+import (
+	"math"
+	"testing"
+)
+
+// seedFor returns the first seed from 1 that rolls at least the rarity.
+func seedFor(rarity Rarity, armor bool) uint64 {
+	seed := uint64(1)
+	for RollAffixes(seed, armor).Rarity < rarity {
+		seed++
+	}
+	return seed
+}
+
+func TestRollAffixes(t *testing.T) {
+	if r := RollAffixes(0, true); r.Rarity != RarityCommon || r.Prefix != nil || r.Suffix != nil {
+		t.Errorf("seed 0 rolled %+v, want a plain item", r)
+	}
+
+	counts := make(map[Rarity]int)
+	for seed := uint64(1); seed <= 2000; seed++ {
+		r := RollAffixes(seed, seed%2 == 0)
+		if again := RollAffixes(seed, seed%2 == 0); again.Rarity != r.Rarity || again.Name("x") != r.Name("x") {
+			t.Fatalf("seed %d rolled differently twice", seed)
+		}
+		affixes := len(r.affixes())
+		switch {
+		case r.Rarity == RarityCommon && affixes != 0,
+			r.Rarity == RarityUncommon && affixes != 1,
+			r.Rarity >= RarityRare && affixes != 2:
+			t.Errorf("seed %d rolled %s with %d affixes", seed, r.Rarity.Label(), affixes)
+		}
+		counts[r.Rarity]++
+	}
+	if counts[RarityCommon] <= counts[RarityRare] || counts[RarityLegendary] == 0 {
+		t.Errorf("rarity spread %v", counts)
+	}
+}
+
+func TestRollItem(t *testing.T) {
+	SetGenre("fantasy")
+	plain := RollItem("medkit", "Medkit", seedFor(RarityCommon, false))
+	if plain.Seed != 0 || plain.Name != "Medkit" {
+		t.Errorf("common roll = %+v, want a plain medkit", plain)
+	}
+
+	seed := seedFor(RarityRare, true)
+	item := RollItem("chainmail_chest", "Chain Hauberk", seed)
+	if item.Seed != seed || item.Rarity() < RarityRare || item.Name == "Chain Hauberk" {
+		t.Fatalf("rare roll = %+v", item)
+	}
+	a, _ := FindRolledArmor(item.ID, item.Seed)
+	base, _ := FindArmor(item.ID)
+	if a.Name != item.Name || a.Value <= base.Value || a.Seed != seed {
+		t.Errorf("rolled armor %+v does not match its item %+v", a, item)
+	}
+
+	// Rolled items stack only with their own roll.
+	inv := NewInventory()
+	inv.Add(Item{ID: "chainmail_chest", Name: "Chain Hauberk", Qty: 1})
+	inv.Add(item)
+	inv.Add(item)
+	if inv.Count() != 2 {
+		t.Errorf("inventory holds %d kinds, want 2", inv.Count())
+	}
+	if !inv.Take("chainmail_chest", seed) || !inv.Take("chainmail_chest", seed) || inv.Take("chainmail_chest", seed) {
+		t.Error("Take did not remove exactly the two rolled pieces")
+	}
+	if !inv.Has("chainmail_chest") {
+		t.Error("Take removed the plain piece")
+	}
+}
+
+func TestItemPotency(t *testing.T) {
+	if p := (Item{ID: "medkit"}).Potency(); p != 1 {
+		t.Errorf("plain medkit potency = %v, want 1", p)
+	}
+	for seed := uint64(1); seed <= 500; seed++ {
+		r := RollAffixes(seed, false)
+		want := 1.0
+		for _, f := range r.affixes() {
+			if f.Potency != 0 {
+				want *= 1 + (f.Potency-1)*r.Strength
+			}
+		}
+		if got := (Item{ID: "medkit", Seed: seed}).Potency(); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("seed %d potency = %v, want %v", seed, got, want)
+		}
+	}
+}
+
+func TestRollItemAtLeast(t *testing.T) {
+	SetGenre("fantasy")
+	for _, want := range []Rarity{RarityCommon, RarityRare, RarityLegendary} {
+		for _, id := range []string{"medkit", "chainmail_legs"} {
+			item := RollItemAtLeast(id, id, 7, want)
+			if item.Rarity() < want {
+				t.Errorf("%s at least %s rolled %s", id, want.Label(), item.Rarity().Label())
+			}
+			if again := RollItemAtLeast(id, id, 7, want); again != item {
+				t.Errorf("%s at least %s rolled differently twice", id, want.Label())
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package inventory
+
+// DefaultWeight is the carry weight of an item not listed in Weights and
+// not armor.
+const DefaultWeight = 1.0
+
+// Weights are the carry weights of one of each item, by item ID.
+var Weights = map[string]float64{
+	"medkit":         2,
+	"potion":         1,
+	"grenade":        3,
+	"proximity_mine": 4,
+}
+
+// overloadRatio is how far past its capacity a load must go to overload
+// the carrier.
+const overloadRatio = 1.5
+
+// Encumbrance is how heavily loaded a carrier is against its capacity.
+type Encumbrance int
+
+const (
+	Unburdened Encumbrance = iota // Unburdened carries no more than its capacity.
+	Burdened                      // Burdened carries more than its capacity.
+	Overloaded                    // Overloaded carries well past its capacity.
+)
+
+// ItemWeight returns the carry weight of one item with the ID. Armor
+// pieces weigh their share of their set.
+func ItemWeight(id string) float64 {
+	if w, ok := Weights[id]; ok {
+		return w
+	}
+	if a, ok := FindArmor(id); ok {
+		return a.Weight
+	}
+	return DefaultWeight
+}
+
+// Weight returns the total carry weight of the items in the inventory.
+func (inv *Inventory) Weight() float64 {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+
+	total := 0.0
+	for _, item := range inv.Items {
+		total += item.Weight() * float64(item.Qty)
+	}
+	return total
+}
+
+// Encumber returns the encumbrance of a load of the weight against the
+// capacity. A capacity of 0 or less carries anything unburdened.
+func Encumber(weight, capacity float64) Encumbrance {
+	switch {
+	case capacity <= 0 || weight <= capacity:
+		return Unburdened
+	case weight <= capacity*overloadRatio:
+		return Burdened
+	default:
+		return Overloaded
+	}
+}
+
+// SpeedMultiplier returns the factor the encumbrance scales movement by.
+func (e Encumbrance) SpeedMultiplier() float64 {
+	switch e {
+	case Burdened:
+		return 0.75
+	case Overloaded:
+		return 0.45
+	default:
+		return 1
+	}
+}
+
+// String returns the encumbrance's display name, "" when unburdened.
+func (e Encumbrance) String() string {
+	switch e {
+	case Burdened:
+		return "Burdened"
+	case Overloaded:
+		return "Overloaded"
+	default:
+		return ""
+	}
+}
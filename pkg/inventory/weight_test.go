@@ -0,0 +1,45 @@
+package inventory
+
+import "testing"
+
+func TestInventoryWeight(t *testing.T) {
+	inv := NewInventory()
+	if inv.Weight() != 0 {
+		t.Errorf("empty inventory weighs %v", inv.Weight())
+	}
+	inv.Add(Item{ID: "grenade", Qty: 2})
+	inv.Add(Item{ID: "medkit", Qty: 1})
+	inv.Add(Item{ID: "relic", Qty: 3})
+	want := 2*Weights["grenade"] + Weights["medkit"] + 3*DefaultWeight
+	if inv.Weight() != want {
+		t.Errorf("Weight() = %v, want %v", inv.Weight(), want)
+	}
+	inv.Consume("grenade", 2)
+	if inv.Weight() != want-2*Weights["grenade"] {
+		t.Errorf("Weight() after consuming = %v", inv.Weight())
+	}
+}
+
+func TestEncumber(t *testing.T) {
+	tests := []struct {
+		weight, capacity float64
+		want             Encumbrance
+		speed            float64
+	}{
+		{10, 40, Unburdened, 1},
+		{40, 40, Unburdened, 1},
+		{50, 40, Burdened, 0.75},
+		{60, 40, Burdened, 0.75},
+		{61, 40, Overloaded, 0.45},
+		{500, 0, Unburdened, 1},
+	}
+	for _, tt := range tests {
+		got := Encumber(tt.weight, tt.capacity)
+		if got != tt.want || got.SpeedMultiplier() != tt.speed {
+			t.Errorf("Encumber(%v, %v) = %v at %v speed, want %v at %v", tt.weight, tt.capacity, got, got.SpeedMultiplier(), tt.want, tt.speed)
+		}
+	}
+	if Unburdened.String() != "" || Overloaded.String() != "Overloaded" {
+		t.Error("unexpected encumbrance names")
+	}
+}
@@ -0,0 +1,188 @@
+package lighting
+
+import (
+	"math"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// Animation selects how a LightAnimator modulates a light over time.
+type Animation int
+
+const (
+	AnimNone        Animation = iota // AnimNone leaves the light steady.
+	AnimCandle                       // AnimCandle is a soft, smoothly wandering flicker.
+	AnimStrobe                       // AnimStrobe switches the light on and off at a fixed rate.
+	AnimPulse                        // AnimPulse slowly breathes between dim and bright.
+	AnimLightning                    // AnimLightning stays dark with rare double flashes.
+	AnimMalfunction                  // AnimMalfunction is mostly on with buzzing dropouts.
+)
+
+// String returns the animation name.
+func (a Animation) String() string {
+	switch a {
+	case AnimNone:
+		return "none"
+	case AnimCandle:
+		return "candle"
+	case AnimStrobe:
+		return "strobe"
+	case AnimPulse:
+		return "pulse"
+	case AnimLightning:
+		return "lightning"
+	case AnimMalfunction:
+		return "malfunction"
+	default:
+		return "unknown"
+	}
+}
+
+// Lightning timing in ticks.
+const (
+	lightningWindow = 180 // One possible strike per window
+	lightningChance = 0.35
+	lightningFlash  = 24 // Length of a strike's double flash
+)
+
+// LightAnimator drives an animated light behavior. It is a pure function of
+// the game tick and its seed, so every client animates a light identically.
+type LightAnimator struct {
+	Kind   Animation
+	Seed   int64
+	Period int     // Ticks per cycle; noise step for candle and malfunction
+	Depth  float64 // Modulation amount [0.0-1.0]; the light dims to 1-Depth at most
+}
+
+// NewLightAnimator creates an animator with the default timing for a kind.
+func NewLightAnimator(kind Animation, seed int64) LightAnimator {
+	a := LightAnimator{Kind: kind, Seed: seed}
+	switch kind {
+	case AnimCandle:
+		a.Period, a.Depth = 8, 0.35
+	case AnimStrobe:
+		a.Period, a.Depth = 20, 1.0
+	case AnimPulse:
+		a.Period, a.Depth = 240, 0.6
+	case AnimLightning:
+		a.Period, a.Depth = lightningWindow, 1.0
+	case AnimMalfunction:
+		a.Period, a.Depth = 3, 0.9
+	}
+	return a
+}
+
+// Multiplier returns the intensity scale at a tick, in [1-Depth, 1].
+func (a LightAnimator) Multiplier(tick int) float64 {
+	depth := clamp(a.Depth, 0.0, 1.0)
+	period := a.Period
+	if period < 1 {
+		period = 1
+	}
+
+	var level float64 // 0 = dimmest, 1 = full intensity
+	switch a.Kind {
+	case AnimCandle:
+		level = a.candle(tick, period)
+	case AnimStrobe:
+		if floorMod(tick, period) < (period+1)/2 {
+			level = 1
+		}
+	case AnimPulse:
+		phase := float64(floorMod(tick, period)) / float64(period)
+		level = 0.5 - 0.5*math.Cos(2*math.Pi*phase)
+	case AnimLightning:
+		level = a.lightning(tick, period)
+	case AnimMalfunction:
+		level = a.malfunction(tick, period)
+	default:
+		return 1.0
+	}
+	return 1.0 - depth*(1.0-level)
+}
+
+// Apply returns a copy of light with its intensity animated for tick.
+func (a LightAnimator) Apply(light Light, tick int) Light {
+	light.Intensity *= a.Multiplier(tick)
+	return light
+}
+
+// ApplyCone returns a copy of cone with its intensity animated for tick.
+func (a LightAnimator) ApplyCone(cone ConeLight, tick int) ConeLight {
+	cone.Intensity *= a.Multiplier(tick)
+	return cone
+}
+
+// candle interpolates smoothly between random levels one period apart.
+func (a LightAnimator) candle(tick, period int) float64 {
+	step := floorDiv(tick, period)
+	t := float64(floorMod(tick, period)) / float64(period)
+	t = t * t * (3 - 2*t)
+	from := a.noise(step)
+	to := a.noise(step + 1)
+	return from + (to-from)*t
+}
+
+// lightning picks, for each window, whether and when a strike happens. A
+// strike is a bright flash, a short gap and a second, fading flash.
+func (a LightAnimator) lightning(tick, period int) float64 {
+	window := floorDiv(tick, period)
+	r := rng.NewRNG(a.noiseSeed(window))
+	if r.Float64() >= lightningChance {
+		return 0
+	}
+	start := r.Intn(max(1, period-lightningFlash))
+	t := floorMod(tick, period) - start
+	switch {
+	case t < 0 || t >= lightningFlash:
+		return 0
+	case t < 4:
+		return 1
+	case t < 8:
+		return 0.1
+	default:
+		return 1 - float64(t-8)/float64(lightningFlash-8)
+	}
+}
+
+// malfunction holds full power most steps and drops out or buzzes on a
+// few.
+func (a LightAnimator) malfunction(tick, period int) float64 {
+	n := a.noise(floorDiv(tick, period))
+	switch {
+	case n < 0.12:
+		return 0
+	case n < 0.25:
+		return 0.5
+	default:
+		return 1
+	}
+}
+
+// noise returns a deterministic value in [0, 1) for a step.
+func (a LightAnimator) noise(step int) float64 {
+	return rng.NewRNG(a.noiseSeed(step)).Float64()
+}
+
+// noiseSeed mixes the animator seed with a step index.
+func (a LightAnimator) noiseSeed(step int) uint64 {
+	return uint64(a.Seed)*0x9e3779b97f4a7c15 + uint64(int64(step))
+}
+
+// floorDiv divides rounding toward negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
+
+// floorMod returns a modulo b in [0, b).
+func floorMod(a, b int) int {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
@@ -0,0 +1,151 @@
+package lighting
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnimationString(t *testing.T) {
+	tests := []struct {
+		kind Animation
+		want string
+	}{
+		{AnimNone, "none"},
+		{AnimCandle, "candle"},
+		{AnimStrobe, "strobe"},
+		{AnimPulse, "pulse"},
+		{AnimLightning, "lightning"},
+		{AnimMalfunction, "malfunction"},
+		{Animation(99), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("Animation(%d).String() = %q, want %q", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestMultiplierRange(t *testing.T) {
+	kinds := []Animation{AnimNone, AnimCandle, AnimStrobe, AnimPulse, AnimLightning, AnimMalfunction}
+	for _, kind := range kinds {
+		t.Run(kind.String(), func(t *testing.T) {
+			a := NewLightAnimator(kind, 7)
+			lo := 1 - a.Depth
+			for tick := -100; tick < 2000; tick++ {
+				m := a.Multiplier(tick)
+				if m < lo-1e-9 || m > 1+1e-9 {
+					t.Fatalf("Multiplier(%d) = %f, want within [%f, 1]", tick, m, lo)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiplierDeterministic(t *testing.T) {
+	for _, kind := range []Animation{AnimCandle, AnimLightning, AnimMalfunction} {
+		a := NewLightAnimator(kind, 1234)
+		b := NewLightAnimator(kind, 1234)
+		for tick := 0; tick < 1000; tick++ {
+			if a.Multiplier(tick) != b.Multiplier(tick) {
+				t.Fatalf("%s: Multiplier(%d) differs between identical animators", kind, tick)
+			}
+		}
+	}
+}
+
+func TestMultiplierSeedsDiffer(t *testing.T) {
+	a := NewLightAnimator(AnimCandle, 1)
+	b := NewLightAnimator(AnimCandle, 2)
+	for tick := 0; tick < 200; tick++ {
+		if a.Multiplier(tick) != b.Multiplier(tick) {
+			return
+		}
+	}
+	t.Error("candle animators with different seeds flicker identically")
+}
+
+func TestStrobe(t *testing.T) {
+	a := LightAnimator{Kind: AnimStrobe, Period: 10, Depth: 1}
+	tests := []struct {
+		tick int
+		want float64
+	}{
+		{0, 1}, {4, 1}, {5, 0}, {9, 0}, {10, 1}, {-1, 0},
+	}
+	for _, tt := range tests {
+		if got := a.Multiplier(tt.tick); got != tt.want {
+			t.Errorf("Multiplier(%d) = %f, want %f", tt.tick, got, tt.want)
+		}
+	}
+}
+
+func TestPulse(t *testing.T) {
+	a := LightAnimator{Kind: AnimPulse, Period: 100, Depth: 0.5}
+	if got := a.Multiplier(0); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("pulse start = %f, want dimmest 0.5", got)
+	}
+	if got := a.Multiplier(50); math.Abs(got-1) > 1e-9 {
+		t.Errorf("pulse midpoint = %f, want full 1.0", got)
+	}
+	if a.Multiplier(25) <= a.Multiplier(10) {
+		t.Error("pulse should brighten during the first half of the cycle")
+	}
+}
+
+func TestCandleIsSmooth(t *testing.T) {
+	a := NewLightAnimator(AnimCandle, 99)
+	for tick := 0; tick < 1000; tick++ {
+		if d := math.Abs(a.Multiplier(tick+1) - a.Multiplier(tick)); d > a.Depth/2 {
+			t.Fatalf("candle jumps %f between ticks %d and %d", d, tick, tick+1)
+		}
+	}
+}
+
+func TestLightningStrikes(t *testing.T) {
+	a := NewLightAnimator(AnimLightning, 5)
+	dark, flashes := 0, 0
+	for tick := 0; tick < lightningWindow*50; tick++ {
+		switch m := a.Multiplier(tick); {
+		case m == 0:
+			dark++
+		case m == 1:
+			flashes++
+		}
+	}
+	if flashes == 0 {
+		t.Error("lightning never flashed in 50 windows")
+	}
+	if dark < lightningWindow*25 {
+		t.Errorf("lightning dark for %d ticks, want mostly dark", dark)
+	}
+}
+
+func TestApply(t *testing.T) {
+	a := LightAnimator{Kind: AnimStrobe, Period: 10, Depth: 1}
+	light := Light{X: 1, Y: 2, Radius: 3, Intensity: 0.8, R: 1}
+	if got := a.Apply(light, 0); got != light {
+		t.Errorf("Apply on bright tick = %+v, want unchanged", got)
+	}
+	if got := a.Apply(light, 5); got.Intensity != 0 || got.X != 1 || got.R != 1 {
+		t.Errorf("Apply on dark tick = %+v, want zero intensity only", got)
+	}
+
+	cone := ConeLight{Intensity: 0.5, IsActive: true}
+	if got := a.ApplyCone(cone, 5); got.Intensity != 0 || !got.IsActive {
+		t.Errorf("ApplyCone on dark tick = %+v, want zero intensity", got)
+	}
+}
+
+func TestFloorDivMod(t *testing.T) {
+	tests := []struct{ a, b, div, mod int }{
+		{7, 3, 2, 1},
+		{-1, 3, -1, 2},
+		{-3, 3, -1, 0},
+		{0, 5, 0, 0},
+	}
+	for _, tt := range tests {
+		if d, m := floorDiv(tt.a, tt.b), floorMod(tt.a, tt.b); d != tt.div || m != tt.mod {
+			t.Errorf("floorDiv/Mod(%d, %d) = %d, %d, want %d, %d", tt.a, tt.b, d, m, tt.div, tt.mod)
+		}
+	}
+}
@@ -3,17 +3,18 @@ package lighting
 // LightComponent represents a dynamic light source attached to an entity.
 // Pure data component following ECS architecture.
 type LightComponent struct {
-	PointLight               // Embedded point light
-	AttachedToEntity bool    // Whether light follows entity position
-	OffsetX, OffsetY float64 // Offset from entity position
-	Pulsing          bool    // Whether light pulses with entity actions
-	PulsePhase       float64 // Current pulse phase [0.0-1.0]
-	PulseSpeed       float64 // Pulse speed multiplier
-	FadeInDuration   float64 // Time to fade in (seconds)
-	FadeOutDuration  float64 // Time to fade out (seconds)
-	CurrentAge       float64 // Time since creation (seconds)
-	Lifetime         float64 // Total lifetime (0 = infinite)
-	Enabled          bool    // Whether light is currently active
+	PointLight                     // Embedded point light
+	AttachedToEntity bool          // Whether light follows entity position
+	OffsetX, OffsetY float64       // Offset from entity position
+	Pulsing          bool          // Whether light pulses with entity actions
+	PulsePhase       float64       // Current pulse phase [0.0-1.0]
+	PulseSpeed       float64       // Pulse speed multiplier
+	FadeInDuration   float64       // Time to fade in (seconds)
+	FadeOutDuration  float64       // Time to fade out (seconds)
+	CurrentAge       float64       // Time since creation (seconds)
+	Lifetime         float64       // Total lifetime (0 = infinite)
+	Enabled          bool          // Whether light is currently active
+	Animator         LightAnimator // Animated behavior (AnimNone = steady)
 }
 
 // Type returns the component type identifier.
@@ -57,3 +58,19 @@ func NewPulsingLight(preset LightPreset, seed int64, pulseSpeed float64) *LightC
 	lc.PulseSpeed = pulseSpeed
 	return lc
 }
+
+// NewAnimatedLight creates a light driven by an animation controller, e.g. a
+// strobing alarm or a malfunctioning lamp.
+func NewAnimatedLight(preset LightPreset, seed int64, kind Animation) *LightComponent {
+	lc := NewLightComponent(preset, seed)
+	lc.Animator = NewLightAnimator(kind, seed)
+	return lc
+}
+
+// IsStatic reports whether the light never changes once placed: it does not
+// flicker, pulse, animate, fade, expire or follow an entity. Static lights
+// can be baked into a SectorLightMap.
+func (l *LightComponent) IsStatic() bool {
+	return !l.IsFlickering && !l.Pulsing && l.Animator.Kind == AnimNone &&
+		!l.AttachedToEntity && l.Lifetime <= 0
+}
@@ -131,3 +131,29 @@ func TestLightComponentType(t *testing.T) {
 		t.Errorf("expected Type() = 'Light', got %s", lc.Type())
 	}
 }
+
+func TestLightComponentIsStatic(t *testing.T) {
+	preset := LightPreset{Name: "lamp", Radius: 5.0, Intensity: 0.8, R: 1, G: 1, B: 1}
+	flicker := preset
+	flicker.Flicker = true
+
+	tests := []struct {
+		name  string
+		light *LightComponent
+		want  bool
+	}{
+		{"steady", NewLightComponent(preset, 1), true},
+		{"flickering", NewLightComponent(flicker, 1), false},
+		{"pulsing", NewPulsingLight(preset, 1, 2.0), false},
+		{"animated", NewAnimatedLight(preset, 1, AnimCandle), false},
+		{"attached", NewAttachedLight(preset, 1, 0, 0), false},
+		{"temporary", NewTemporaryLight(preset, 1, 2.0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.light.IsStatic(); got != tt.want {
+				t.Errorf("IsStatic() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package lighting
+
+import "math"
+
+// HoursPerDay is the length of a DayCycle in game hours.
+const HoursPerDay = 24.0
+
+// DayPhase names a part of the day.
+type DayPhase int
+
+const (
+	PhaseNight DayPhase = iota // PhaseNight is 21:00 to 5:00.
+	PhaseDawn                  // PhaseDawn is 5:00 to 8:00.
+	PhaseDay                   // PhaseDay is 8:00 to 17:00.
+	PhaseDusk                  // PhaseDusk is 17:00 to 21:00.
+)
+
+// String returns the phase name.
+func (p DayPhase) String() string {
+	switch p {
+	case PhaseNight:
+		return "night"
+	case PhaseDawn:
+		return "dawn"
+	case PhaseDay:
+		return "day"
+	case PhaseDusk:
+		return "dusk"
+	default:
+		return "unknown"
+	}
+}
+
+// AmbientKey is one point of a day cycle's ambient curve. Between keys the
+// curve is interpolated linearly, wrapping from the last key to the first.
+type AmbientKey struct {
+	Hour    float64 // Time of day [0-24)
+	Level   float64 // Ambient light as a fraction of the genre's daylight level
+	R, G, B float64 // Ambient tint
+	Weather float64 // Weather emission rate multiplier
+}
+
+// surfaceCurve is the ambient curve of open-air maps.
+var surfaceCurve = []AmbientKey{
+	{Hour: 0, Level: 0.25, R: 0.55, G: 0.6, B: 0.9, Weather: 1.3},
+	{Hour: 5, Level: 0.3, R: 0.6, G: 0.6, B: 0.85, Weather: 1.2},
+	{Hour: 7, Level: 0.8, R: 1.0, G: 0.8, B: 0.65, Weather: 0.8},
+	{Hour: 12, Level: 1.0, R: 1.0, G: 1.0, B: 1.0, Weather: 0.6},
+	{Hour: 17, Level: 0.9, R: 1.0, G: 0.9, B: 0.8, Weather: 0.8},
+	{Hour: 19, Level: 0.55, R: 1.0, G: 0.6, B: 0.4, Weather: 1.1},
+	{Hour: 21, Level: 0.3, R: 0.6, G: 0.55, B: 0.85, Weather: 1.3},
+}
+
+// DayCycle is the global lighting controller. It drives the ambient level,
+// ambient tint and weather intensity from a time of day, which either
+// advances on its own or is moved by scripted transitions.
+type DayCycle struct {
+	Hour  float64 // Current time of day [0-24)
+	Speed float64 // Game hours per real second; 0 freezes the clock
+
+	daylight float64 // Genre ambient level at full daylight
+	curve    []AmbientKey
+
+	targetHour     float64 // Hour a scripted transition ends at
+	transitionLeft float64 // Seconds left in the scripted transition
+}
+
+// NewDayCycle creates a day cycle for a genre. Post-apocalyptic surface
+// maps start before dusk and run a full day in 20 minutes; the indoor genres
+// hold at noon so their ambient level stays the genre preset's.
+func NewDayCycle(genreID string) *DayCycle {
+	d := &DayCycle{}
+	d.SetGenre(genreID)
+	return d
+}
+
+// SetGenre resets the cycle to a genre's preset.
+func (d *DayCycle) SetGenre(genreID string) {
+	d.daylight = genreAmbientLevel(genreID)
+	d.curve = surfaceCurve
+	d.transitionLeft = 0
+	if genreID == "postapoc" {
+		d.Hour = 16.0
+		d.Speed = HoursPerDay / (20 * 60)
+	} else {
+		d.Hour = 12.0
+		d.Speed = 0
+	}
+}
+
+// Update advances the clock by deltaTime seconds, or a scripted transition
+// when one is running.
+func (d *DayCycle) Update(deltaTime float64) {
+	if d.transitionLeft > 0 {
+		step := math.Min(deltaTime/d.transitionLeft, 1.0)
+		d.Hour = wrapHour(d.Hour + hourDelta(d.Hour, d.targetHour)*step)
+		d.transitionLeft -= deltaTime
+		if d.transitionLeft <= 0 {
+			d.Hour = d.targetHour
+			d.transitionLeft = 0
+		}
+		return
+	}
+	d.Hour = wrapHour(d.Hour + d.Speed*deltaTime)
+}
+
+// TransitionTo moves the clock forward to hour over duration seconds, for
+// scripted lighting changes such as a sudden nightfall. The regular clock
+// pauses during the transition. A duration of 0 jumps immediately.
+func (d *DayCycle) TransitionTo(hour, duration float64) {
+	hour = wrapHour(hour)
+	if duration <= 0 {
+		d.Hour = hour
+		d.transitionLeft = 0
+		return
+	}
+	d.targetHour = hour
+	d.transitionLeft = duration
+}
+
+// InTransition reports whether a scripted transition is running.
+func (d *DayCycle) InTransition() bool {
+	return d.transitionLeft > 0
+}
+
+// Phase returns the part of the day of the current hour.
+func (d *DayCycle) Phase() DayPhase {
+	switch h := d.Hour; {
+	case h >= 5 && h < 8:
+		return PhaseDawn
+	case h >= 8 && h < 17:
+		return PhaseDay
+	case h >= 17 && h < 21:
+		return PhaseDusk
+	default:
+		return PhaseNight
+	}
+}
+
+// Ambient returns the ambient light level for the current hour.
+func (d *DayCycle) Ambient() float64 {
+	return clamp(d.sample().Level*d.daylight/d.noon().Level, 0.0, 1.0)
+}
+
+// Tint returns the ambient light color for the current hour.
+func (d *DayCycle) Tint() (r, g, b float64) {
+	k := d.sample()
+	return k.R, k.G, k.B
+}
+
+// WeatherScale returns the weather emission multiplier for the current
+// hour; wind and dust pick up after dark.
+func (d *DayCycle) WeatherScale() float64 {
+	return d.sample().Weather
+}
+
+// State returns the clock and any running scripted transition, for saving.
+func (d *DayCycle) State() (hour, speed, targetHour, transitionLeft float64) {
+	return d.Hour, d.Speed, d.targetHour, d.transitionLeft
+}
+
+// Restore sets the clock and scripted transition from saved values.
+func (d *DayCycle) Restore(hour, speed, targetHour, transitionLeft float64) {
+	d.Hour = wrapHour(hour)
+	d.Speed = math.Max(speed, 0)
+	d.targetHour = wrapHour(targetHour)
+	d.transitionLeft = math.Max(transitionLeft, 0)
+}
+
+// noon returns the curve value at 12:00, the reference for full daylight.
+func (d *DayCycle) noon() AmbientKey {
+	return sampleCurve(d.curve, 12)
+}
+
+// sample returns the curve value at the current hour.
+func (d *DayCycle) sample() AmbientKey {
+	return sampleCurve(d.curve, d.Hour)
+}
+
+// sampleCurve interpolates an ambient curve at an hour.
+func sampleCurve(curve []AmbientKey, hour float64) AmbientKey {
+	n := len(curve)
+	if n == 0 {
+		return AmbientKey{Hour: hour, Level: 1, R: 1, G: 1, B: 1, Weather: 1}
+	}
+	hour = wrapHour(hour)
+
+	// Find the last key at or before hour, wrapping to the final key.
+	i := n - 1
+	for j, k := range curve {
+		if k.Hour > hour {
+			break
+		}
+		i = j
+	}
+	a, b := curve[i], curve[(i+1)%n]
+
+	span := b.Hour - a.Hour
+	if span <= 0 {
+		span += HoursPerDay
+	}
+	t := hour - a.Hour
+	if t < 0 {
+		t += HoursPerDay
+	}
+	t /= span
+
+	lerp := func(x, y float64) float64 { return x + (y-x)*t }
+	return AmbientKey{
+		Hour:    hour,
+		Level:   lerp(a.Level, b.Level),
+		R:       lerp(a.R, b.R),
+		G:       lerp(a.G, b.G),
+		B:       lerp(a.B, b.B),
+		Weather: lerp(a.Weather, b.Weather),
+	}
+}
+
+// hourDelta returns how far the clock runs forward from one hour to another.
+func hourDelta(from, to float64) float64 {
+	return wrapHour(to - from)
+}
+
+// wrapHour wraps an hour into [0, 24).
+func wrapHour(h float64) float64 {
+	h = math.Mod(h, HoursPerDay)
+	if h < 0 {
+		h += HoursPerDay
+	}
+	return h
+}
@@ -0,0 +1,198 @@
+package lighting
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDayPhaseString(t *testing.T) {
+	tests := []struct {
+		phase DayPhase
+		want  string
+	}{
+		{PhaseNight, "night"},
+		{PhaseDawn, "dawn"},
+		{PhaseDay, "day"},
+		{PhaseDusk, "dusk"},
+		{DayPhase(99), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.phase.String(); got != tt.want {
+			t.Errorf("DayPhase(%d).String() = %q, want %q", tt.phase, got, tt.want)
+		}
+	}
+}
+
+func TestNewDayCycle(t *testing.T) {
+	tests := []struct {
+		genre    string
+		wantHour float64
+		cycling  bool
+		wantNoon float64
+	}{
+		{"fantasy", 12, false, 0.3},
+		{"scifi", 12, false, genreAmbientLevel("scifi")},
+		{"postapoc", 16, true, genreAmbientLevel("postapoc")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.genre, func(t *testing.T) {
+			d := NewDayCycle(tt.genre)
+			if d.Hour != tt.wantHour {
+				t.Errorf("Hour = %f, want %f", d.Hour, tt.wantHour)
+			}
+			if (d.Speed > 0) != tt.cycling {
+				t.Errorf("Speed = %f, cycling want %v", d.Speed, tt.cycling)
+			}
+			d.Hour = 12
+			if got := d.Ambient(); math.Abs(got-tt.wantNoon) > 1e-9 {
+				t.Errorf("Ambient() at noon = %f, want genre level %f", got, tt.wantNoon)
+			}
+		})
+	}
+}
+
+func TestDayCyclePhase(t *testing.T) {
+	tests := []struct {
+		hour float64
+		want DayPhase
+	}{
+		{0, PhaseNight},
+		{4.9, PhaseNight},
+		{5, PhaseDawn},
+		{8, PhaseDay},
+		{16.9, PhaseDay},
+		{17, PhaseDusk},
+		{21, PhaseNight},
+		{23.5, PhaseNight},
+	}
+	d := NewDayCycle("postapoc")
+	for _, tt := range tests {
+		d.Hour = tt.hour
+		if got := d.Phase(); got != tt.want {
+			t.Errorf("Phase() at %.1f = %v, want %v", tt.hour, got, tt.want)
+		}
+	}
+}
+
+func TestDayCycleNightDarkerThanDay(t *testing.T) {
+	d := NewDayCycle("postapoc")
+	d.Hour = 12
+	day := d.Ambient()
+	_, _, dayB := d.Tint()
+	d.Hour = 1
+	night := d.Ambient()
+	r, _, b := d.Tint()
+	if night >= day {
+		t.Errorf("night ambient %f not below day %f", night, day)
+	}
+	if b <= r {
+		t.Errorf("night tint r=%f b=%f, want blue-shifted", r, b)
+	}
+	if dayB != 1 {
+		t.Errorf("noon tint blue = %f, want 1", dayB)
+	}
+	if d.WeatherScale() <= 1 {
+		t.Errorf("night WeatherScale() = %f, want above 1", d.WeatherScale())
+	}
+}
+
+func TestSampleCurve(t *testing.T) {
+	curve := []AmbientKey{
+		{Hour: 6, Level: 0.2, R: 1, G: 1, B: 1, Weather: 1},
+		{Hour: 18, Level: 0.8, R: 1, G: 1, B: 1, Weather: 1},
+	}
+	tests := []struct {
+		hour float64
+		want float64
+	}{
+		{6, 0.2},
+		{12, 0.5},
+		{18, 0.8},
+		{0, 0.5},  // Midway through the wrap from 18 to 6
+		{24, 0.5}, // Wraps to 0
+		{-6, 0.8}, // Wraps to 18
+	}
+	for _, tt := range tests {
+		if got := sampleCurve(curve, tt.hour).Level; math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("sampleCurve(%f).Level = %f, want %f", tt.hour, got, tt.want)
+		}
+	}
+
+	if got := sampleCurve(nil, 3).Level; got != 1 {
+		t.Errorf("empty curve Level = %f, want 1", got)
+	}
+}
+
+func TestDayCycleUpdateWraps(t *testing.T) {
+	d := NewDayCycle("postapoc")
+	d.Hour = 23.5
+	d.Speed = 1
+	d.Update(1)
+	if math.Abs(d.Hour-0.5) > 1e-9 {
+		t.Errorf("Hour = %f, want 0.5", d.Hour)
+	}
+
+	d = NewDayCycle("fantasy")
+	d.Update(100)
+	if d.Hour != 12 {
+		t.Errorf("frozen clock moved to %f", d.Hour)
+	}
+}
+
+func TestDayCycleTransitionTo(t *testing.T) {
+	d := NewDayCycle("postapoc")
+	d.TransitionTo(22, 2)
+	if !d.InTransition() {
+		t.Fatal("InTransition() = false after TransitionTo")
+	}
+
+	d.Update(1)
+	if math.Abs(d.Hour-19) > 1e-9 {
+		t.Errorf("Hour halfway = %f, want 19", d.Hour)
+	}
+	d.Update(1)
+	if d.Hour != 22 || d.InTransition() {
+		t.Errorf("Hour = %f, InTransition = %v; want 22, false", d.Hour, d.InTransition())
+	}
+
+	// The clock resumes after the transition.
+	d.Update(1)
+	if d.Hour <= 22 {
+		t.Errorf("Hour = %f, want clock running after transition", d.Hour)
+	}
+
+	// Transitions run forward through midnight.
+	d.Hour = 22
+	d.TransitionTo(2, 4)
+	d.Update(2)
+	if math.Abs(d.Hour-0) > 1e-9 {
+		t.Errorf("Hour = %f, want 0 halfway through 22->2", d.Hour)
+	}
+
+	d.TransitionTo(6, 0)
+	if d.Hour != 6 || d.InTransition() {
+		t.Errorf("instant TransitionTo: Hour = %f, InTransition = %v", d.Hour, d.InTransition())
+	}
+}
+
+func TestDayCycleStateRestore(t *testing.T) {
+	d := NewDayCycle("postapoc")
+	d.Hour = 18
+	d.TransitionTo(22, 8)
+	d.Update(2)
+
+	hour, speed, target, left := d.State()
+	r := NewDayCycle("postapoc")
+	r.Restore(hour, speed, target, left)
+
+	d.Update(3)
+	r.Update(3)
+	if d.Hour != r.Hour || d.InTransition() != r.InTransition() {
+		t.Errorf("restored cycle diverged: %f/%v vs %f/%v", r.Hour, r.InTransition(), d.Hour, d.InTransition())
+	}
+
+	r.Restore(30, -1, -2, -5)
+	if r.Hour != 6 || r.Speed != 0 || r.InTransition() {
+		t.Errorf("Restore did not sanitize: hour %f speed %f transition %v", r.Hour, r.Speed, r.InTransition())
+	}
+}
@@ -0,0 +1,141 @@
+package lighting
+
+import "image/color"
+
+// Impulse is a short-lived light such as a muzzle flash or an explosion. It
+// starts at full intensity and fades out quadratically over its duration.
+type Impulse struct {
+	Light    Light
+	Age      float64 // Seconds since emission
+	Duration float64 // Seconds until the light is gone
+}
+
+// fade returns the intensity scale at the impulse's current age.
+func (i *Impulse) fade() float64 {
+	if i.Duration <= 0 || i.Age >= i.Duration {
+		return 0
+	}
+	p := i.Age / i.Duration
+	return 1.0 - p*p
+}
+
+// current returns the impulse's light at its current age.
+func (i *Impulse) current() Light {
+	l := i.Light
+	l.Intensity *= i.fade()
+	return l
+}
+
+// ImpulsePool holds impulse lights in fixed storage so that emitting them
+// never allocates. At most perFrame impulses are accepted between two
+// Update calls; when the pool is full, a new impulse replaces the dimmest
+// live one if it is brighter.
+type ImpulsePool struct {
+	impulses []Impulse
+	active   int
+	perFrame int
+	emitted  int
+}
+
+// NewImpulsePool creates a pool of capacity impulses accepting at most
+// perFrame new impulses per frame. perFrame <= 0 means no per-frame cap.
+func NewImpulsePool(capacity, perFrame int) *ImpulsePool {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if perFrame <= 0 {
+		perFrame = capacity
+	}
+	return &ImpulsePool{
+		impulses: make([]Impulse, capacity),
+		perFrame: perFrame,
+	}
+}
+
+// Emit adds an impulse light lasting duration seconds. It returns false
+// when the light was dropped by the per-frame cap or a full pool.
+func (p *ImpulsePool) Emit(light Light, duration float64) bool {
+	if duration <= 0 || light.Intensity <= 0 || light.Radius <= 0 {
+		return false
+	}
+	if p.emitted >= p.perFrame {
+		return false
+	}
+
+	slot := p.active
+	if p.active == len(p.impulses) {
+		slot = p.dimmest()
+		if p.impulses[slot].current().Intensity >= light.Intensity {
+			return false
+		}
+	} else {
+		p.active++
+	}
+	p.impulses[slot] = Impulse{Light: light, Duration: duration}
+	p.emitted++
+	return true
+}
+
+// EmitLightImpulse adds an impulse light from a color, for systems that
+// describe their effects in RGBA.
+func (p *ImpulsePool) EmitLightImpulse(x, y, radius, intensity float64, col color.RGBA, duration float64) {
+	p.Emit(Light{
+		X:         x,
+		Y:         y,
+		Radius:    radius,
+		Intensity: intensity,
+		R:         float64(col.R) / 255.0,
+		G:         float64(col.G) / 255.0,
+		B:         float64(col.B) / 255.0,
+	}, duration)
+}
+
+// Update ages impulses by deltaTime seconds, drops expired ones and resets
+// the per-frame cap.
+func (p *ImpulsePool) Update(deltaTime float64) {
+	n := 0
+	for i := 0; i < p.active; i++ {
+		imp := p.impulses[i]
+		imp.Age += deltaTime
+		if imp.Age < imp.Duration {
+			p.impulses[n] = imp
+			n++
+		}
+	}
+	p.active = n
+	p.emitted = 0
+}
+
+// AppendLights appends the live impulses at their faded intensity to dst.
+func (p *ImpulsePool) AppendLights(dst []Light) []Light {
+	for i := 0; i < p.active; i++ {
+		if l := p.impulses[i].current(); l.Intensity > 0 {
+			dst = append(dst, l)
+		}
+	}
+	return dst
+}
+
+// Len returns the number of live impulses.
+func (p *ImpulsePool) Len() int {
+	return p.active
+}
+
+// Clear removes all impulses, e.g. on level change.
+func (p *ImpulsePool) Clear() {
+	p.active = 0
+	p.emitted = 0
+}
+
+// dimmest returns the index of the live impulse with the lowest current
+// intensity.
+func (p *ImpulsePool) dimmest() int {
+	best := 0
+	bestIntensity := p.impulses[0].current().Intensity
+	for i := 1; i < p.active; i++ {
+		if v := p.impulses[i].current().Intensity; v < bestIntensity {
+			best, bestIntensity = i, v
+		}
+	}
+	return best
+}
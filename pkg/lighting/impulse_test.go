@@ -0,0 +1,149 @@
+package lighting
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestImpulseFade(t *testing.T) {
+	tests := []struct {
+		age  float64
+		want float64
+	}{
+		{0, 1.0},
+		{0.5, 0.75},
+		{1.0, 0},
+		{2.0, 0},
+	}
+	for _, tt := range tests {
+		imp := Impulse{Light: Light{Intensity: 2}, Age: tt.age, Duration: 1}
+		if got := imp.current().Intensity; math.Abs(got-2*tt.want) > 1e-9 {
+			t.Errorf("intensity at age %.1f = %f, want %f", tt.age, got, 2*tt.want)
+		}
+	}
+}
+
+func TestImpulsePoolLifetime(t *testing.T) {
+	p := NewImpulsePool(4, 0)
+	if !p.Emit(Light{X: 1, Y: 1, Radius: 3, Intensity: 1}, 0.2) {
+		t.Fatal("Emit() = false on an empty pool")
+	}
+
+	lights := p.AppendLights(nil)
+	if len(lights) != 1 || lights[0].Intensity != 1 {
+		t.Fatalf("AppendLights() = %v, want one full-intensity light", lights)
+	}
+
+	p.Update(0.1)
+	lights = p.AppendLights(lights[:0])
+	if len(lights) != 1 || math.Abs(lights[0].Intensity-0.75) > 1e-9 {
+		t.Errorf("halfway intensity = %v, want 0.75", lights)
+	}
+
+	p.Update(0.1)
+	if p.Len() != 0 {
+		t.Errorf("Len() = %d after expiry, want 0", p.Len())
+	}
+}
+
+func TestImpulsePoolRejectsInvalid(t *testing.T) {
+	p := NewImpulsePool(4, 0)
+	tests := []struct {
+		name     string
+		light    Light
+		duration float64
+	}{
+		{"zero duration", Light{Radius: 1, Intensity: 1}, 0},
+		{"zero intensity", Light{Radius: 1}, 1},
+		{"zero radius", Light{Intensity: 1}, 1},
+	}
+	for _, tt := range tests {
+		if p.Emit(tt.light, tt.duration) {
+			t.Errorf("%s: Emit() = true, want false", tt.name)
+		}
+	}
+}
+
+func TestImpulsePoolPerFrameCap(t *testing.T) {
+	p := NewImpulsePool(8, 2)
+	accepted := 0
+	for i := 0; i < 5; i++ {
+		if p.Emit(Light{Radius: 1, Intensity: 1}, 1) {
+			accepted++
+		}
+	}
+	if accepted != 2 {
+		t.Errorf("accepted %d impulses in one frame, want 2", accepted)
+	}
+
+	p.Update(0.01)
+	if !p.Emit(Light{Radius: 1, Intensity: 1}, 1) {
+		t.Error("Emit() = false after Update reset the frame cap")
+	}
+}
+
+func TestImpulsePoolFullReplacesDimmest(t *testing.T) {
+	p := NewImpulsePool(2, 0)
+	p.Emit(Light{X: 1, Radius: 1, Intensity: 0.5}, 1)
+	p.Emit(Light{X: 2, Radius: 1, Intensity: 2.0}, 1)
+	p.Update(0)
+
+	if p.Emit(Light{X: 3, Radius: 1, Intensity: 0.2}, 1) {
+		t.Error("dimmer impulse replaced a live one")
+	}
+	if !p.Emit(Light{X: 4, Radius: 1, Intensity: 1.0}, 1) {
+		t.Fatal("brighter impulse was dropped from a full pool")
+	}
+
+	lights := p.AppendLights(nil)
+	if len(lights) != 2 {
+		t.Fatalf("len = %d, want 2", len(lights))
+	}
+	for _, l := range lights {
+		if l.X == 1 {
+			t.Error("dimmest impulse was not replaced")
+		}
+	}
+}
+
+func TestImpulsePoolNoAllocs(t *testing.T) {
+	p := NewImpulsePool(16, 0)
+	buf := make([]Light, 0, 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		p.EmitLightImpulse(1, 1, 3, 1, color.RGBA{R: 255, G: 128, A: 255}, 0.1)
+		buf = p.AppendLights(buf[:0])
+		p.Update(0.016)
+	})
+	if allocs != 0 {
+		t.Errorf("allocs per frame = %f, want 0", allocs)
+	}
+}
+
+func TestEmitLightImpulseColor(t *testing.T) {
+	p := NewImpulsePool(1, 0)
+	p.EmitLightImpulse(2, 3, 4, 1.5, color.RGBA{R: 255, G: 51, B: 0, A: 255}, 0.5)
+	lights := p.AppendLights(nil)
+	if len(lights) != 1 {
+		t.Fatalf("len = %d, want 1", len(lights))
+	}
+	l := lights[0]
+	if l.X != 2 || l.Y != 3 || l.Radius != 4 || l.Intensity != 1.5 {
+		t.Errorf("light = %+v", l)
+	}
+	if l.R != 1 || math.Abs(l.G-0.2) > 1e-9 || l.B != 0 {
+		t.Errorf("color = (%f, %f, %f), want (1, 0.2, 0)", l.R, l.G, l.B)
+	}
+}
+
+func TestImpulsePoolClear(t *testing.T) {
+	p := NewImpulsePool(4, 1)
+	p.Emit(Light{Radius: 1, Intensity: 1}, 1)
+	p.Clear()
+	if p.Len() != 0 {
+		t.Errorf("Len() = %d after Clear, want 0", p.Len())
+	}
+	if !p.Emit(Light{Radius: 1, Intensity: 1}, 1) {
+		t.Error("Emit() = false after Clear")
+	}
+}
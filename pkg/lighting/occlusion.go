@@ -0,0 +1,133 @@
+package lighting
+
+import "math"
+
+// SetOccluder sets the test for tiles that block light, typically walls and
+// closed doors. Lights then stop at walls instead of bleeding through them,
+// while the wall faces they reach are still lit. Call it again after the
+// map changes (a door opens) to re-bake static lights. nil disables
+// occlusion.
+func (s *SectorLightMap) SetOccluder(opaque func(x, y int) bool) {
+	s.opaque = opaque
+	s.staticDirty = true
+	s.dirty = true
+}
+
+// isOpaque reports whether a tile blocks light.
+func (s *SectorLightMap) isOpaque(x, y int) bool {
+	return s.opaque != nil && s.opaque(x, y)
+}
+
+// computeVisibility fills s.visible for the tiles of a bounding box with
+// whether light from (lx, ly) reaches them. Open tiles need a clear line of
+// sight; opaque tiles are lit when an open neighbor on the light's side is.
+// Without an occluder every tile is visible.
+func (s *SectorLightMap) computeVisibility(lx, ly float64, minX, minY, maxX, maxY int) {
+	w := maxX - minX + 1
+	h := maxY - minY + 1
+	if w <= 0 || h <= 0 {
+		return
+	}
+	if cap(s.visible) < w*h {
+		s.visible = make([]bool, w*h)
+	}
+	s.visible = s.visible[:w*h]
+
+	if s.opaque == nil {
+		for i := range s.visible {
+			s.visible[i] = true
+		}
+		return
+	}
+
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			s.visible[(y-minY)*w+x-minX] = !s.isOpaque(x, y) && s.lineOfSight(lx, ly, x, y)
+		}
+	}
+
+	// Wall faces: lit from a visible open neighbor that lies toward the light.
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			if !s.isOpaque(x, y) {
+				continue
+			}
+			lit := false
+			for _, d := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				nx, ny := x+d[0], y+d[1]
+				if nx < minX || nx > maxX || ny < minY || ny > maxY {
+					continue
+				}
+				// The neighbor must be on the same side of the wall as the light.
+				toLightX := lx - (float64(x) + 0.5)
+				toLightY := ly - (float64(y) + 0.5)
+				if float64(d[0])*toLightX+float64(d[1])*toLightY <= 0 {
+					continue
+				}
+				if !s.isOpaque(nx, ny) && s.visible[(ny-minY)*w+nx-minX] {
+					lit = true
+					break
+				}
+			}
+			s.visible[(y-minY)*w+x-minX] = lit
+		}
+	}
+}
+
+// lineOfSight walks the grid from (lx, ly) to the center of tile (tx, ty)
+// and reports whether no opaque tile lies strictly between them. The tile
+// holding the light never blocks, so wall-mounted lights still shine.
+func (s *SectorLightMap) lineOfSight(lx, ly float64, tx, ty int) bool {
+	x, y := int(math.Floor(lx)), int(math.Floor(ly))
+	dx := float64(tx) + 0.5 - lx
+	dy := float64(ty) + 0.5 - ly
+
+	stepX, stepY := 1, 1
+	if dx < 0 {
+		stepX = -1
+	}
+	if dy < 0 {
+		stepY = -1
+	}
+
+	// Ray parameter t runs from 0 at the light to 1 at the tile center.
+	deltaX, deltaY := math.Inf(1), math.Inf(1)
+	nextX, nextY := math.Inf(1), math.Inf(1)
+	if dx != 0 {
+		deltaX = math.Abs(1 / dx)
+		if stepX > 0 {
+			nextX = (float64(x+1) - lx) / dx
+		} else {
+			nextX = (float64(x) - lx) / dx
+		}
+	}
+	if dy != 0 {
+		deltaY = math.Abs(1 / dy)
+		if stepY > 0 {
+			nextY = (float64(y+1) - ly) / dy
+		} else {
+			nextY = (float64(y) - ly) / dy
+		}
+	}
+
+	for x != tx || y != ty {
+		if nextX < nextY {
+			x += stepX
+			nextX += deltaX
+		} else {
+			y += stepY
+			nextY += deltaY
+		}
+		if x == tx && y == ty {
+			return true
+		}
+		if s.isOpaque(x, y) {
+			return false
+		}
+		if math.Min(nextX, nextY) > 1 {
+			// Rounding stepped past the target; it is reached.
+			return true
+		}
+	}
+	return true
+}
@@ -0,0 +1,162 @@
+package lighting
+
+import "testing"
+
+// occlusionMap is a test map: '#' blocks light, anything else is open.
+func occlusionMap(rows ...string) func(x, y int) bool {
+	return func(x, y int) bool {
+		if y < 0 || y >= len(rows) || x < 0 || x >= len(rows[y]) {
+			return true
+		}
+		return rows[y][x] == '#'
+	}
+}
+
+func TestLightStopsAtWalls(t *testing.T) {
+	// Two rooms split by a wall at x=5 with a doorway at y=1.
+	rows := []string{
+		"##########",
+		"#........#",
+		"#....#...#",
+		"#....#...#",
+		"#....#...#",
+		"##########",
+	}
+	slm := NewSectorLightMap(10, 6, 0.0)
+	slm.SetOccluder(occlusionMap(rows...))
+	slm.AddLight(Light{X: 2.5, Y: 2.5, Radius: 6, Intensity: 1.0})
+	slm.Calculate()
+
+	tests := []struct {
+		name  string
+		x, y  int
+		isLit bool
+	}{
+		{"light tile", 2, 2, true},
+		{"same room", 1, 1, true},
+		{"wall face toward light", 5, 3, true},
+		{"behind wall", 7, 4, false},
+		{"behind wall near corner", 6, 4, false},
+		{"doorway spill", 6, 1, true},
+		{"outer wall face", 3, 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if lit := slm.GetLight(tt.x, tt.y) > 0; lit != tt.isLit {
+				t.Errorf("GetLight(%d, %d) = %f, want lit=%v", tt.x, tt.y, slm.GetLight(tt.x, tt.y), tt.isLit)
+			}
+		})
+	}
+}
+
+func TestWallBackFaceStaysDark(t *testing.T) {
+	// A wall between the light and an open tile is only lit on its near side:
+	// the wall tile itself is lit, but its back face gets nothing through it.
+	slm := NewSectorLightMap(7, 3, 0.0)
+	slm.SetOccluder(occlusionMap(
+		".......",
+		"...#...",
+		".......",
+	))
+	slm.AddLight(Light{X: 1.5, Y: 1.5, Radius: 6, Intensity: 1.0})
+	slm.Calculate()
+
+	if slm.GetLight(3, 1) == 0 {
+		t.Error("wall facing the light is dark")
+	}
+	if got := slm.GetLight(4, 1); got != 0 {
+		t.Errorf("tile directly behind the wall = %f, want 0", got)
+	}
+}
+
+func TestSetOccluderRebakesStaticLights(t *testing.T) {
+	rows := []string{
+		"#######",
+		"#..#..#",
+		"#######",
+	}
+	slm := NewSectorLightMap(7, 3, 0.0)
+	slm.SetOccluder(occlusionMap(rows...))
+	slm.AddStaticLight(Light{X: 1.5, Y: 1.5, Radius: 5, Intensity: 1.0})
+	slm.Calculate()
+	if got := slm.GetLight(4, 1); got != 0 {
+		t.Fatalf("tile behind closed door = %f, want 0", got)
+	}
+
+	// Opening the door re-bakes the static layer.
+	rows[1] = "#.....#"
+	slm.SetOccluder(occlusionMap(rows...))
+	slm.Calculate()
+	if got := slm.GetLight(4, 1); got == 0 {
+		t.Error("tile behind opened door still dark")
+	}
+}
+
+func TestConeLightStopsAtWalls(t *testing.T) {
+	slm := NewSectorLightMap(10, 3, 0.0)
+	slm.SetOccluder(occlusionMap(
+		"..........",
+		".....#....",
+		"..........",
+	))
+	slm.AddFlashlight(1.5, 1.5, 1, 0, 0.3, 9, 1.0)
+	slm.Calculate()
+
+	if slm.GetLight(3, 1) == 0 {
+		t.Error("tile in front of the wall is dark")
+	}
+	if got := slm.GetLight(7, 1); got != 0 {
+		t.Errorf("tile behind the wall = %f, want 0", got)
+	}
+}
+
+func TestNoOccluderLightsEverything(t *testing.T) {
+	slm := NewSectorLightMap(7, 3, 0.0)
+	slm.AddLight(Light{X: 1.5, Y: 1.5, Radius: 6, Intensity: 1.0})
+	slm.SetOccluder(nil)
+	slm.Calculate()
+	if slm.GetLight(5, 1) == 0 {
+		t.Error("light without an occluder did not reach tile (5, 1)")
+	}
+}
+
+func TestLightOutsideMap(t *testing.T) {
+	slm := NewSectorLightMap(4, 4, 0.1)
+	slm.SetOccluder(occlusionMap("....", "....", "....", "...."))
+	slm.AddLight(Light{X: -20, Y: -20, Radius: 2, Intensity: 1.0})
+	slm.Calculate()
+	if got := slm.GetLight(0, 0); got != 0.1 {
+		t.Errorf("GetLight = %f, want ambient 0.1", got)
+	}
+}
+
+func TestLineOfSight(t *testing.T) {
+	slm := NewSectorLightMap(5, 5, 0.0)
+	slm.SetOccluder(occlusionMap(
+		".....",
+		".....",
+		"..#..",
+		".....",
+		".....",
+	))
+	tests := []struct {
+		name   string
+		lx, ly float64
+		tx, ty int
+		want   bool
+	}{
+		{"same tile", 0.5, 0.5, 0, 0, true},
+		{"clear row", 0.5, 0.5, 4, 0, true},
+		{"blocked diagonal", 0.5, 0.5, 4, 4, false},
+		{"blocked column", 2.5, 0.5, 2, 4, false},
+		{"target is the wall", 2.5, 0.5, 2, 2, true},
+		{"light in a wall", 2.5, 2.5, 2, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slm.lineOfSight(tt.lx, tt.ly, tt.tx, tt.ty); got != tt.want {
+				t.Errorf("lineOfSight(%v, %v -> %d, %d) = %v, want %v", tt.lx, tt.ly, tt.tx, tt.ty, got, tt.want)
+			}
+		})
+	}
+}
@@ -6,14 +6,30 @@ import (
 
 // SectorLightMap manages per-tile lighting for a level sector.
 // It maintains an ambient light level and combines contributions from multiple point lights.
+//
+// Lights are split into two layers. Static lights (wall torches, fixed lamps)
+// are baked once into a cached layer when the level loads; dynamic lights
+// (flashlight, muzzle flashes, animated lights) are re-added every frame and
+// composited on top by Calculate.
 type SectorLightMap struct {
-	Width      int         // Map width in tiles
-	Height     int         // Map height in tiles
-	Ambient    float64     // Base ambient light level [0.0-1.0]
-	lights     []Light     // Active point light sources
-	coneLights []ConeLight // Active cone light sources (flashlights)
-	lightGrid  []float64   // Cached per-tile illumination [0.0-1.0]
-	dirty      bool        // True when lights changed, requires recalculation
+	Width        int         // Map width in tiles
+	Height       int         // Map height in tiles
+	Ambient      float64     // Base ambient light level [0.0-1.0]
+	lights       []Light     // Active point light sources
+	coneLights   []ConeLight // Active cone light sources (flashlights)
+	lightGrid    []float64   // Cached per-tile illumination [0.0-1.0]
+	colorGrid    []float64   // Cached per-tile RGB illumination, 3 values per tile [0.0-1.0]
+	dirty        bool        // True when lights changed, requires recalculation
+	staticLights []Light     // Light sources of the baked layer
+	staticGrid   []float64   // Baked per-tile illumination of staticLights, unclamped
+	staticColor  []float64   // Baked per-tile RGB illumination of staticLights, unclamped
+	staticScale  float64     // Brightness of the baked layer, e.g. dimmed during an alarm
+	staticDirty  bool        // True when staticLights changed, requires a re-bake
+
+	ambientTint [3]float64 // Color of the ambient light, white by default
+
+	opaque  func(x, y int) bool // Tiles that block light; nil lets light pass everywhere
+	visible []bool              // Scratch visibility of one light's bounding box
 }
 
 // NewSectorLightMap creates a lighting map for the given dimensions.
@@ -26,7 +42,13 @@ func NewSectorLightMap(width, height int, ambient float64) *SectorLightMap {
 		lights:     make([]Light, 0, 16),
 		coneLights: make([]ConeLight, 0, 4),
 		lightGrid:  make([]float64, width*height),
+		colorGrid:  make([]float64, width*height*3),
 		dirty:      true,
+
+		staticGrid:  make([]float64, width*height),
+		staticColor: make([]float64, width*height*3),
+		staticScale: 1.0,
+		ambientTint: [3]float64{1, 1, 1},
 	}
 }
 
@@ -99,22 +121,85 @@ func (s *SectorLightMap) SetAmbient(ambient float64) {
 	s.dirty = true
 }
 
+// SetAmbientTint sets the color of the ambient light, e.g. orange at dusk
+// or blue at night. It affects GetLightColor only; GetLight stays the
+// uncolored level.
+func (s *SectorLightMap) SetAmbientTint(r, g, b float64) {
+	tint := [3]float64{clamp(r, 0.0, 1.0), clamp(g, 0.0, 1.0), clamp(b, 0.0, 1.0)}
+	if tint != s.ambientTint {
+		s.ambientTint = tint
+		s.dirty = true
+	}
+}
+
+// AddStaticLight registers a light source of the baked layer. It takes
+// effect at the next Bake or Calculate.
+// Returns the index of the added light.
+func (s *SectorLightMap) AddStaticLight(light Light) int {
+	s.staticLights = append(s.staticLights, light)
+	s.staticDirty = true
+	s.dirty = true
+	return len(s.staticLights) - 1
+}
+
+// ClearStatic removes all static light sources.
+func (s *SectorLightMap) ClearStatic() {
+	s.staticLights = s.staticLights[:0]
+	s.staticDirty = true
+	s.dirty = true
+}
+
+// StaticLightCount returns the number of static light sources.
+func (s *SectorLightMap) StaticLightCount() int {
+	return len(s.staticLights)
+}
+
+// SetStaticScale sets the brightness of the baked layer without re-baking it.
+// 1.0 is normal; 0.0 turns all static lights off.
+func (s *SectorLightMap) SetStaticScale(scale float64) {
+	scale = math.Max(scale, 0.0)
+	if scale != s.staticScale {
+		s.staticScale = scale
+		s.dirty = true
+	}
+}
+
+// Bake recomputes the baked layer from the static lights if they changed.
+// Calculate bakes on demand, so calling Bake is only needed to move the cost
+// to load time.
+func (s *SectorLightMap) Bake() {
+	if !s.staticDirty {
+		return
+	}
+	clear(s.staticGrid)
+	clear(s.staticColor)
+	for _, light := range s.staticLights {
+		s.addLightContribution(light, s.staticGrid, s.staticColor)
+	}
+	s.staticDirty = false
+}
+
 // Calculate computes combined illumination for all tiles.
-// Each tile receives ambient light plus contributions from all point lights and cone lights.
+// Each tile receives ambient light, the baked static layer and contributions
+// from all dynamic point lights and cone lights.
 // Light intensity falls off as 1 / (1 + distance²) for quadratic attenuation.
 func (s *SectorLightMap) Calculate() {
 	if !s.dirty {
 		return
 	}
+	s.Bake()
 
-	// Reset grid to ambient level
+	// Reset grid to ambient level plus the baked layer
 	for i := range s.lightGrid {
-		s.lightGrid[i] = s.Ambient
+		s.lightGrid[i] = s.Ambient + s.staticGrid[i]*s.staticScale
+	}
+	for i := range s.colorGrid {
+		s.colorGrid[i] = s.Ambient*s.ambientTint[i%3] + s.staticColor[i]*s.staticScale
 	}
 
 	// Add point light contributions
 	for _, light := range s.lights {
-		s.addLightContribution(light)
+		s.addLightContribution(light, s.lightGrid, s.colorGrid)
 	}
 
 	// Add cone light contributions
@@ -122,6 +207,13 @@ func (s *SectorLightMap) Calculate() {
 		s.addConeLightContribution(cone)
 	}
 
+	for i, v := range s.lightGrid {
+		s.lightGrid[i] = clamp(v, 0.0, 1.0)
+	}
+	for i, v := range s.colorGrid {
+		s.colorGrid[i] = clamp(v, 0.0, 1.0)
+	}
+
 	s.dirty = false
 }
 
@@ -135,6 +227,18 @@ func (s *SectorLightMap) GetLight(x, y int) float64 {
 	return s.lightGrid[y*s.Width+x]
 }
 
+// GetLightColor returns the computed RGB illumination at the given tile.
+// Each light tints its contribution by its color, so a torch lights nearby
+// tiles orange while ambient light stays white.
+// Returns black for out-of-bounds coordinates.
+func (s *SectorLightMap) GetLightColor(x, y int) (r, g, b float64) {
+	if x < 0 || x >= s.Width || y < 0 || y >= s.Height {
+		return 0, 0, 0
+	}
+	i := (y*s.Width + x) * 3
+	return s.colorGrid[i], s.colorGrid[i+1], s.colorGrid[i+2]
+}
+
 // LightCount returns the number of active light sources.
 func (s *SectorLightMap) LightCount() int {
 	return len(s.lights)
@@ -145,16 +249,17 @@ func (s *SectorLightMap) ConeLightCount() int {
 	return len(s.coneLights)
 }
 
-// Clear removes all light sources and resets to ambient.
+// Clear removes all dynamic light sources. Baked static lights remain.
 func (s *SectorLightMap) Clear() {
 	s.lights = s.lights[:0]
 	s.coneLights = s.coneLights[:0]
 	s.dirty = true
 }
 
-// addLightContribution adds a point light's contribution to the light grid.
+// addLightContribution adds a point light's contribution to a brightness
+// grid and its RGB counterpart.
 // Uses quadratic attenuation: intensity = baseIntensity / (1 + distance²)
-func (s *SectorLightMap) addLightContribution(light Light) {
+func (s *SectorLightMap) addLightContribution(light Light, grid, colors []float64) {
 	// Calculate bounding box to avoid processing entire grid
 	radiusTiles := int(math.Ceil(light.Radius))
 	minX := max(0, int(light.X)-radiusTiles)
@@ -163,9 +268,16 @@ func (s *SectorLightMap) addLightContribution(light Light) {
 	maxY := min(s.Height-1, int(light.Y)+radiusTiles)
 
 	radiusSq := light.Radius * light.Radius
+	s.computeVisibility(light.X, light.Y, minX, minY, maxX, maxY)
+	boxW := maxX - minX + 1
 
 	for y := minY; y <= maxY; y++ {
 		for x := minX; x <= maxX; x++ {
+			// Skip tiles hidden behind walls
+			if !s.visible[(y-minY)*boxW+x-minX] {
+				continue
+			}
+
 			dx := float64(x) + 0.5 - light.X
 			dy := float64(y) + 0.5 - light.Y
 			distSq := dx*dx + dy*dy
@@ -177,8 +289,7 @@ func (s *SectorLightMap) addLightContribution(light Light) {
 
 			// Quadratic attenuation: intensity / (1 + distance²)
 			contribution := light.Intensity / (1.0 + distSq)
-			idx := y*s.Width + x
-			s.lightGrid[idx] = clamp(s.lightGrid[idx]+contribution, 0.0, 1.0)
+			addTileContribution(grid, colors, y*s.Width+x, contribution, light.R, light.G, light.B)
 		}
 	}
 }
@@ -199,9 +310,16 @@ func (s *SectorLightMap) addConeLightContribution(cone ConeLight) {
 
 	radiusSq := cone.Radius * cone.Radius
 	cosHalfAngle := math.Cos(cone.Angle)
+	s.computeVisibility(cone.X, cone.Y, minX, minY, maxX, maxY)
+	boxW := maxX - minX + 1
 
 	for y := minY; y <= maxY; y++ {
 		for x := minX; x <= maxX; x++ {
+			// Skip tiles hidden behind walls
+			if !s.visible[(y-minY)*boxW+x-minX] {
+				continue
+			}
+
 			// Vector from light to tile center
 			tileX := float64(x) + 0.5
 			tileY := float64(y) + 0.5
@@ -231,12 +349,26 @@ func (s *SectorLightMap) addConeLightContribution(cone ConeLight) {
 			angleAttenuation := (dotProduct - cosHalfAngle) / (1.0 - cosHalfAngle)
 			contribution := distAttenuation * angleAttenuation
 
-			idx := y*s.Width + x
-			s.lightGrid[idx] = clamp(s.lightGrid[idx]+contribution, 0.0, 1.0)
+			addTileContribution(s.lightGrid, s.colorGrid, y*s.Width+x, contribution, cone.R, cone.G, cone.B)
 		}
 	}
 }
 
+// addTileContribution adds a light's contribution to a tile's brightness
+// and, tinted by the light's color, to its RGB illumination. A light with no
+// color set counts as white. Values are clamped once all lights are added.
+func addTileContribution(grid, colors []float64, idx int, contribution, r, g, b float64) {
+	grid[idx] += contribution
+
+	if r == 0 && g == 0 && b == 0 {
+		r, g, b = 1, 1, 1
+	}
+	c := colors[idx*3 : idx*3+3]
+	c[0] += contribution * r
+	c[1] += contribution * g
+	c[2] += contribution * b
+}
+
 // clamp restricts value to [min, max] range.
 func clamp(value, min, max float64) float64 {
 	if value < min {
@@ -815,6 +815,138 @@ func TestFlashlightInactiveDoesNotContribute(t *testing.T) {
 	}
 }
 
+func TestGetLightColor(t *testing.T) {
+	tests := []struct {
+		name                string
+		light               Light
+		wantR, wantG, wantB float64
+	}{
+		{
+			name:  "torch tints orange",
+			light: Light{X: 5.5, Y: 5.5, Radius: 3, Intensity: 0.5, R: 1.0, G: 0.6, B: 0.2},
+			wantR: 0.1 + 0.5, wantG: 0.1 + 0.3, wantB: 0.1 + 0.1,
+		},
+		{
+			name:  "uncolored light is white",
+			light: Light{X: 5.5, Y: 5.5, Radius: 3, Intensity: 0.5},
+			wantR: 0.6, wantG: 0.6, wantB: 0.6,
+		},
+		{
+			name:  "channels clamp at full brightness",
+			light: Light{X: 5.5, Y: 5.5, Radius: 3, Intensity: 2.0, R: 1.0, G: 0.2, B: 1.0},
+			wantR: 1.0, wantG: 0.5, wantB: 1.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slm := NewSectorLightMap(10, 10, 0.1)
+			slm.AddLight(tt.light)
+			slm.Calculate()
+
+			r, g, b := slm.GetLightColor(5, 5)
+			for _, c := range []struct {
+				name      string
+				got, want float64
+			}{{"R", r, tt.wantR}, {"G", g, tt.wantG}, {"B", b, tt.wantB}} {
+				if math.Abs(c.got-c.want) > 1e-9 {
+					t.Errorf("%s = %f, want %f", c.name, c.got, c.want)
+				}
+			}
+
+			// Outside the light only white ambient remains.
+			r, g, b = slm.GetLightColor(0, 9)
+			if r != 0.1 || g != 0.1 || b != 0.1 {
+				t.Errorf("unlit tile = (%f, %f, %f), want ambient 0.1", r, g, b)
+			}
+		})
+	}
+}
+
+func TestGetLightColor_OutOfBounds(t *testing.T) {
+	slm := NewSectorLightMap(4, 4, 0.5)
+	slm.Calculate()
+	if r, g, b := slm.GetLightColor(-1, 4); r != 0 || g != 0 || b != 0 {
+		t.Errorf("GetLightColor out of bounds = (%f, %f, %f), want black", r, g, b)
+	}
+}
+
+func TestGetLightColor_FlashlightColor(t *testing.T) {
+	slm := NewSectorLightMap(20, 20, 0.0)
+	slm.AddFlashlight(10, 10, 1, 0, math.Pi/4, 8, 1.0)
+	slm.coneLights[0].R, slm.coneLights[0].G, slm.coneLights[0].B = 0.2, 0.4, 1.0
+	slm.Calculate()
+
+	r, g, b := slm.GetLightColor(12, 10)
+	if !(b > g && g > r && r > 0) {
+		t.Errorf("cone light color = (%f, %f, %f), want blue-tinted", r, g, b)
+	}
+}
+
+func TestStaticLightBaking(t *testing.T) {
+	torch := Light{X: 5.5, Y: 5.5, Radius: 3, Intensity: 0.4, R: 1.0, G: 0.5, B: 0.2}
+
+	baked := NewSectorLightMap(10, 10, 0.2)
+	if idx := baked.AddStaticLight(torch); idx != 0 || baked.StaticLightCount() != 1 {
+		t.Fatalf("AddStaticLight index = %d, count = %d", idx, baked.StaticLightCount())
+	}
+	baked.Bake()
+	baked.Calculate()
+
+	direct := NewSectorLightMap(10, 10, 0.2)
+	direct.AddLight(torch)
+	direct.Calculate()
+
+	// A baked light lights tiles exactly as the same dynamic light would.
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			if got, want := baked.GetLight(x, y), direct.GetLight(x, y); math.Abs(got-want) > 1e-9 {
+				t.Fatalf("GetLight(%d, %d) = %f, want %f", x, y, got, want)
+			}
+		}
+	}
+
+	// Clearing dynamic lights keeps the baked layer; dynamic lights add on top.
+	baked.Clear()
+	baked.AddLight(Light{X: 1.5, Y: 1.5, Radius: 2, Intensity: 0.5})
+	baked.Calculate()
+	if got := baked.GetLight(5, 5); math.Abs(got-0.6) > 1e-9 {
+		t.Errorf("baked tile after Clear = %f, want 0.6", got)
+	}
+	if got := baked.GetLight(1, 1); math.Abs(got-0.7) > 1e-9 {
+		t.Errorf("dynamic tile = %f, want 0.7", got)
+	}
+
+	// The static scale dims baked lights without touching ambient.
+	baked.SetStaticScale(0.5)
+	baked.Calculate()
+	if got := baked.GetLight(5, 5); math.Abs(got-0.4) > 1e-9 {
+		t.Errorf("half-scaled baked tile = %f, want 0.4", got)
+	}
+	if r, g, b := baked.GetLightColor(5, 5); math.Abs(r-0.4) > 1e-9 || math.Abs(g-0.3) > 1e-9 || math.Abs(b-0.24) > 1e-9 {
+		t.Errorf("half-scaled baked color = (%f, %f, %f), want (0.4, 0.3, 0.24)", r, g, b)
+	}
+
+	baked.ClearStatic()
+	baked.Calculate()
+	if got := baked.GetLight(5, 5); math.Abs(got-0.2) > 1e-9 {
+		t.Errorf("tile after ClearStatic = %f, want ambient 0.2", got)
+	}
+}
+
+func TestCalculate_ClampsCombinedLayers(t *testing.T) {
+	slm := NewSectorLightMap(5, 5, 0.5)
+	slm.AddStaticLight(Light{X: 2.5, Y: 2.5, Radius: 2, Intensity: 0.4})
+	slm.AddLight(Light{X: 2.5, Y: 2.5, Radius: 2, Intensity: 0.4})
+	slm.Calculate()
+	if got := slm.GetLight(2, 2); got != 1.0 {
+		t.Errorf("GetLight = %f, want clamped 1.0", got)
+	}
+	if r, _, _ := slm.GetLightColor(2, 2); r != 1.0 {
+		t.Errorf("GetLightColor R = %f, want clamped 1.0", r)
+	}
+}
+
 // BenchmarkCalculate measures lighting calculation performance
 func BenchmarkCalculate(b *testing.B) {
 	slm := NewSectorLightMap(100, 100, 0.2)
@@ -833,3 +965,20 @@ func BenchmarkCalculate(b *testing.B) {
 		slm.Calculate()
 	}
 }
+
+func TestSetAmbientTint(t *testing.T) {
+	sm := NewSectorLightMap(4, 4, 0.5)
+	sm.SetAmbientTint(0.4, 0.6, 2.0)
+	sm.Calculate()
+
+	r, g, b := sm.GetLightColor(1, 1)
+	want := [3]float64{0.2, 0.3, 0.5}
+	for i, got := range []float64{r, g, b} {
+		if math.Abs(got-want[i]) > 1e-9 {
+			t.Errorf("channel %d = %f, want %f", i, got, want[i])
+		}
+	}
+	if got := sm.GetLight(1, 1); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("GetLight() = %f, want uncolored 0.5", got)
+	}
+}
@@ -18,6 +18,7 @@ type LightingSystem struct {
 	ambientG         float64
 	ambientB         float64
 	ambientIntensity float64
+	override         LightAnimator // Applied to every light, e.g. during an alarm
 	logger           *logrus.Entry
 }
 
@@ -131,11 +132,22 @@ func (s *LightingSystem) updateLightLifetime(light *LightComponent, deltaTime fl
 	}
 }
 
-// updateLightPosition synchronizes light position with attached entity.
+// enginePositionType is the position component of game entities.
+var enginePositionType = reflect.TypeOf(&engine.Position{})
+
+// updateLightPosition synchronizes light position with attached entity. The
+// entity's engine.Position is used when present, else its PositionComponent.
 func (s *LightingSystem) updateLightPosition(w *engine.World, entity engine.Entity, light *LightComponent, positionType reflect.Type) {
 	if !light.AttachedToEntity {
 		return
 	}
+	if posComp, found := w.GetComponent(entity, enginePositionType); found {
+		if pos, ok := posComp.(*engine.Position); ok {
+			light.X = pos.X + light.OffsetX
+			light.Y = pos.Y + light.OffsetY
+			return
+		}
+	}
 	if posComp, found := w.GetComponent(entity, positionType); found {
 		if pos, ok := posComp.(*PositionComponent); ok {
 			light.X = pos.X + light.OffsetX
@@ -174,7 +186,27 @@ func (s *LightingSystem) applyLightFade(light *LightComponent) {
 	light.Intensity = light.PointLight.Intensity * fadeMultiplier
 }
 
-// GetEffectiveIntensity returns the current intensity including flicker and pulse.
+// SetOverride animates every collected light with a, on top of each light's
+// own animation. Used for level-wide events such as alarms or power
+// failures. Pass an animator of kind AnimNone to clear it.
+func (s *LightingSystem) SetOverride(a LightAnimator) {
+	s.override = a
+}
+
+// Override returns the level-wide animation set with SetOverride.
+func (s *LightingSystem) Override() LightAnimator {
+	return s.override
+}
+
+// OverrideMultiplier returns the current intensity scale of the level-wide
+// animation, for applying it to lights outside the system such as a baked
+// light layer.
+func (s *LightingSystem) OverrideMultiplier() float64 {
+	return s.override.Multiplier(s.tick)
+}
+
+// GetEffectiveIntensity returns the current intensity including flicker, pulse
+// and animation.
 func GetEffectiveIntensity(light *LightComponent, tick int) float64 {
 	intensity := light.Intensity
 
@@ -189,11 +221,41 @@ func GetEffectiveIntensity(light *LightComponent, tick int) float64 {
 		intensity *= (1.0 + pulseAmount)
 	}
 
+	intensity *= light.Animator.Multiplier(tick)
+
 	return clampF(intensity, 0.0, 1.0)
 }
 
 // CollectLights extracts all active lights from the world for rendering.
 func (s *LightingSystem) CollectLights(w *engine.World) []Light {
+	return s.applyOverride(s.collect(w, func(*LightComponent) bool { return true }))
+}
+
+// CollectStaticLights extracts the enabled lights that never change, for
+// baking into a SectorLightMap at level load. The override animation is not
+// applied; scale the baked layer by OverrideMultiplier instead.
+func (s *LightingSystem) CollectStaticLights(w *engine.World) []Light {
+	return s.collect(w, (*LightComponent).IsStatic)
+}
+
+// CollectDynamicLights extracts the enabled lights that flicker, move,
+// animate or expire; the complement of CollectStaticLights.
+func (s *LightingSystem) CollectDynamicLights(w *engine.World) []Light {
+	return s.applyOverride(s.collect(w, func(lc *LightComponent) bool { return !lc.IsStatic() }))
+}
+
+// applyOverride scales lights by the level-wide animation.
+func (s *LightingSystem) applyOverride(lights []Light) []Light {
+	m := s.OverrideMultiplier()
+	for i := range lights {
+		lights[i].Intensity *= m
+	}
+	return lights
+}
+
+// collect extracts the enabled lights accepted by keep, with their own
+// flicker, pulse and animation applied.
+func (s *LightingSystem) collect(w *engine.World, keep func(*LightComponent) bool) []Light {
 	lightType := reflect.TypeOf(&LightComponent{})
 	entities := w.Query(lightType)
 
@@ -206,11 +268,11 @@ func (s *LightingSystem) CollectLights(w *engine.World) []Light {
 		}
 
 		lc, ok := lightComp.(*LightComponent)
-		if !ok || !lc.Enabled {
+		if !ok || !lc.Enabled || !keep(lc) {
 			continue
 		}
 
-		// Get effective intensity with flicker and pulse
+		// Get effective intensity with flicker, pulse and animation
 		effectiveIntensity := GetEffectiveIntensity(lc, s.tick)
 
 		// Add to render list
@@ -233,6 +295,28 @@ func (s *LightingSystem) AddLightToEntity(w *engine.World, entity engine.Entity,
 	w.AddComponent(entity, light)
 }
 
+// AttachLight adds light to entity so that it follows the entity's position
+// at an offset, e.g. a glowing projectile or a companion's lantern. The
+// light is placed at once, so it is correct before the next Update.
+func (s *LightingSystem) AttachLight(w *engine.World, entity engine.Entity, light *LightComponent, offsetX, offsetY float64) {
+	light.AttachedToEntity = true
+	light.OffsetX = offsetX
+	light.OffsetY = offsetY
+	s.updateLightPosition(w, entity, light, reflect.TypeOf(&PositionComponent{}))
+	w.AddComponent(entity, light)
+}
+
+// AttachedLight returns the light of entity, or nil if it has none.
+func (s *LightingSystem) AttachedLight(w *engine.World, entity engine.Entity) *LightComponent {
+	return s.getLightComponent(w, entity, reflect.TypeOf(&LightComponent{}))
+}
+
+// DetachLight removes the light of entity, e.g. when a burning enemy is
+// extinguished.
+func (s *LightingSystem) DetachLight(w *engine.World, entity engine.Entity) {
+	w.RemoveComponent(entity, reflect.TypeOf(&LightComponent{}))
+}
+
 // PositionComponent represents entity position (defined for reference).
 type PositionComponent struct {
 	X, Y float64
@@ -175,6 +175,43 @@ func TestUpdateAttachedLight(t *testing.T) {
 	}
 }
 
+func TestAttachLightFollowsEnginePosition(t *testing.T) {
+	sys := NewLightingSystem("fantasy")
+	world := NewMockWorld()
+
+	entity := world.AddEntity()
+	pos := &engine.Position{X: 3.0, Y: 4.0}
+	world.AddComponent(entity, pos)
+
+	preset := LightPreset{Name: "lantern", Radius: 3.0, Intensity: 0.6, R: 1.0, G: 0.8, B: 0.5}
+	light := NewLightComponent(preset, 7)
+	sys.AttachLight(world, entity, light, 0.5, 0)
+
+	// Placed immediately, before any Update
+	if light.X != 3.5 || light.Y != 4.0 {
+		t.Errorf("attached light at (%f, %f), want (3.5, 4.0)", light.X, light.Y)
+	}
+	if got := sys.AttachedLight(world, entity); got != light {
+		t.Errorf("AttachedLight() = %p, want %p", got, light)
+	}
+
+	// Follows the entity as it moves
+	pos.X, pos.Y = 8.0, 1.0
+	sys.Update(world)
+	lights := sys.CollectLights(world)
+	if len(lights) != 1 || lights[0].X != 8.5 || lights[0].Y != 1.0 {
+		t.Errorf("CollectLights() = %v, want one light at (8.5, 1.0)", lights)
+	}
+
+	sys.DetachLight(world, entity)
+	if got := sys.AttachedLight(world, entity); got != nil {
+		t.Error("AttachedLight() after DetachLight should be nil")
+	}
+	if lights := sys.CollectLights(world); len(lights) != 0 {
+		t.Errorf("CollectLights() after DetachLight = %v, want none", lights)
+	}
+}
+
 func TestUpdatePulsingLight(t *testing.T) {
 	sys := NewLightingSystem("fantasy")
 	world := NewMockWorld()
@@ -312,6 +349,83 @@ func TestGetEffectiveIntensity(t *testing.T) {
 	}
 }
 
+func TestGetEffectiveIntensityAnimated(t *testing.T) {
+	preset := LightPreset{Name: "alarm", Radius: 4.0, Intensity: 0.8, R: 1.0, G: 0.2, B: 0.2}
+	light := NewAnimatedLight(preset, 3, AnimStrobe)
+	light.Animator.Period = 10
+
+	if got := GetEffectiveIntensity(light, 0); math.Abs(got-0.8) > 1e-9 {
+		t.Errorf("strobe on = %f, want 0.8", got)
+	}
+	if got := GetEffectiveIntensity(light, 5); got != 0 {
+		t.Errorf("strobe off = %f, want 0", got)
+	}
+}
+
+func TestCollectLightsOverride(t *testing.T) {
+	sys := NewLightingSystem("scifi")
+	world := NewMockWorld()
+	preset := LightPreset{Name: "ceiling_lamp", Radius: 8.0, Intensity: 1.0, R: 1, G: 1, B: 1}
+	world.AddComponent(world.AddEntity(), NewLightComponent(preset, 1))
+
+	sys.SetOverride(LightAnimator{Kind: AnimStrobe, Period: 2, Depth: 1})
+	if sys.Override().Kind != AnimStrobe {
+		t.Fatalf("Override().Kind = %v, want strobe", sys.Override().Kind)
+	}
+
+	// tick 0: on
+	if lights := sys.CollectLights(world); lights[0].Intensity != 1.0 {
+		t.Errorf("override on-phase intensity = %f, want 1.0", lights[0].Intensity)
+	}
+	sys.tick = 1
+	if lights := sys.CollectLights(world); lights[0].Intensity != 0 {
+		t.Errorf("override off-phase intensity = %f, want 0", lights[0].Intensity)
+	}
+
+	sys.SetOverride(LightAnimator{})
+	if lights := sys.CollectLights(world); lights[0].Intensity != 1.0 {
+		t.Errorf("cleared override intensity = %f, want 1.0", lights[0].Intensity)
+	}
+}
+
+func TestCollectStaticAndDynamicLights(t *testing.T) {
+	sys := NewLightingSystem("fantasy")
+	world := NewMockWorld()
+	steady := LightPreset{Name: "magic_crystal", Radius: 6.0, Intensity: 0.9, R: 0.4, G: 0.6, B: 1.0}
+	flicker := LightPreset{Name: "torch", Radius: 5.0, Intensity: 0.8, R: 1.0, G: 0.6, B: 0.2, Flicker: true}
+
+	world.AddComponent(world.AddEntity(), NewLightComponent(steady, 1))
+	world.AddComponent(world.AddEntity(), NewLightComponent(flicker, 2))
+	world.AddComponent(world.AddEntity(), NewAnimatedLight(steady, 3, AnimPulse))
+
+	static := sys.CollectStaticLights(world)
+	dynamic := sys.CollectDynamicLights(world)
+	if len(static) != 1 || len(dynamic) != 2 {
+		t.Fatalf("got %d static and %d dynamic lights, want 1 and 2", len(static), len(dynamic))
+	}
+	if static[0].Intensity != 0.9 {
+		t.Errorf("static light intensity = %f, want 0.9", static[0].Intensity)
+	}
+	if all := sys.CollectLights(world); len(all) != 3 {
+		t.Errorf("CollectLights returned %d lights, want 3", len(all))
+	}
+
+	// Static lights ignore the override; the baked layer is scaled instead.
+	sys.SetOverride(LightAnimator{Kind: AnimStrobe, Period: 2, Depth: 1})
+	sys.tick = 1
+	if got := sys.OverrideMultiplier(); got != 0 {
+		t.Errorf("OverrideMultiplier = %f, want 0 on the strobe's off phase", got)
+	}
+	if static := sys.CollectStaticLights(world); static[0].Intensity != 0.9 {
+		t.Errorf("static light with override = %f, want 0.9", static[0].Intensity)
+	}
+	for _, l := range sys.CollectDynamicLights(world) {
+		if l.Intensity != 0 {
+			t.Errorf("dynamic light with override = %f, want 0", l.Intensity)
+		}
+	}
+}
+
 func TestPositionComponentType(t *testing.T) {
 	pos := &PositionComponent{}
 	if pos.Type() != "Position" {
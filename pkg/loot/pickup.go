@@ -0,0 +1,204 @@
+package loot
+
+import (
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// PickupKind is what collecting a pickup gives the player.
+type PickupKind int
+
+const (
+	PickupItem    PickupKind = iota // PickupItem is an inventory item.
+	PickupAmmo                      // PickupAmmo is rounds for an ammo pool.
+	PickupCredits                   // PickupCredits is shop credits.
+	PickupWeapon                    // PickupWeapon is a rolled weapon.
+)
+
+// Pickup defaults.
+const (
+	// DefaultMagnetRadius is how close, in tiles, pickups start drifting
+	// towards the player.
+	DefaultMagnetRadius = 2.5
+	// DefaultCollectRadius is how close, in tiles, pickups are collected.
+	DefaultCollectRadius = 0.5
+	// DefaultMagnetSpeed is how fast, in tiles per second, pickups drift.
+	DefaultMagnetSpeed = 6.0
+	// DefaultPickupLifetime is how long, in seconds, pickups lie before
+	// they despawn.
+	DefaultPickupLifetime = 45.0
+)
+
+// Pickup is the component of a loot entity lying in the world. Item is
+// the item ID, or the pool of ammo; Amount is the quantity, rounds or
+// credits; Seed is the roll of rolled items and weapons.
+type Pickup struct {
+	ID        uint64 // Same on every peer of a multiplayer session
+	Kind      PickupKind
+	Item      string
+	Name      string
+	Amount    int
+	Seed      uint64
+	Rarity    Rarity
+	Remaining float64 // Seconds until it despawns; 0 when spawned for the system's lifetime
+}
+
+// Type implements Component interface.
+func (p *Pickup) Type() string {
+	return "LootPickup"
+}
+
+// PickupState is a pickup and where it lies, as peers exchange them.
+type PickupState struct {
+	Pickup
+	X, Y float64
+}
+
+// PickupSystem spawns pickups into the world, drifts them towards the
+// player in magnet range, collects them and despawns them when their time
+// runs out.
+type PickupSystem struct {
+	MagnetRadius  float64
+	CollectRadius float64
+	MagnetSpeed   float64
+	Lifetime      float64 // Lifetime of pickups spawned without one; 0 lies forever
+	nextID        uint64
+}
+
+// NewPickupSystem creates a pickup system with the default radii.
+func NewPickupSystem() *PickupSystem {
+	return &PickupSystem{
+		MagnetRadius:  DefaultMagnetRadius,
+		CollectRadius: DefaultCollectRadius,
+		MagnetSpeed:   DefaultMagnetSpeed,
+		Lifetime:      DefaultPickupLifetime,
+	}
+}
+
+// pickupCategory returns the sprite category a pickup is drawn with.
+func pickupCategory(p *Pickup) ItemCategory {
+	switch p.Kind {
+	case PickupCredits:
+		return CategoryGold
+	case PickupWeapon:
+		return CategoryWeapon
+	case PickupAmmo:
+		return CategoryGear
+	}
+	return CategorizeItem(p.Item)
+}
+
+// Spawn lays the pickup at x, y, with its sprite, and returns its entity.
+// Pickups without an ID get the next one, and those without a lifetime
+// the system's.
+func (s *PickupSystem) Spawn(w *engine.World, p Pickup, x, y float64) engine.Entity {
+	if p.ID == 0 {
+		s.nextID++
+		p.ID = s.nextID
+	} else if p.ID > s.nextID {
+		s.nextID = p.ID
+	}
+	if p.Remaining == 0 {
+		p.Remaining = s.Lifetime
+	}
+	ent := SpawnLootVisual(w, p.Item, p.Rarity, x, y, int64(p.Seed^p.ID))
+	if comp, ok := w.GetComponent(ent, reflect.TypeOf((*VisualComponent)(nil))); ok {
+		comp.(*VisualComponent).Category = pickupCategory(&p)
+	}
+	w.AddComponent(ent, &p)
+	return ent
+}
+
+// Component types of a pickup entity.
+var (
+	pickupType   = reflect.TypeOf((*Pickup)(nil))
+	positionType = reflect.TypeOf((*PositionComponent)(nil))
+)
+
+// each calls fn with every pickup in the world and its position.
+func each(w *engine.World, fn func(ent engine.Entity, p *Pickup, pos *PositionComponent)) {
+	for _, ent := range w.Query(pickupType, positionType) {
+		pc, _ := w.GetComponent(ent, pickupType)
+		posc, _ := w.GetComponent(ent, positionType)
+		p, ok := pc.(*Pickup)
+		pos, ok2 := posc.(*PositionComponent)
+		if ok && ok2 {
+			fn(ent, p, pos)
+		}
+	}
+}
+
+// Update advances the pickups by dt seconds towards the player at px, py:
+// it despawns the expired ones, drifts those in magnet range closer and
+// removes and returns those the player collects, sorted by ID.
+func (s *PickupSystem) Update(w *engine.World, px, py, dt float64) []Pickup {
+	var collected []Pickup
+	each(w, func(ent engine.Entity, p *Pickup, pos *PositionComponent) {
+		if p.Remaining > 0 {
+			if p.Remaining -= dt; p.Remaining <= 0 {
+				w.RemoveEntity(ent)
+				return
+			}
+		}
+		dx, dy := px-pos.X, py-pos.Y
+		dist := math.Hypot(dx, dy)
+		if dist > s.CollectRadius && dist <= s.MagnetRadius {
+			step := math.Min(s.MagnetSpeed*dt, dist)
+			pos.X += dx / dist * step
+			pos.Y += dy / dist * step
+			dist -= step
+		}
+		if dist <= s.CollectRadius {
+			collected = append(collected, *p)
+			w.RemoveEntity(ent)
+		}
+	})
+	sort.Slice(collected, func(i, j int) bool { return collected[i].ID < collected[j].ID })
+	return collected
+}
+
+// Clear removes every pickup from the world.
+func (s *PickupSystem) Clear(w *engine.World) {
+	each(w, func(ent engine.Entity, _ *Pickup, _ *PositionComponent) {
+		w.RemoveEntity(ent)
+	})
+}
+
+// Snapshot returns the state of every pickup in the world, by ID, for a
+// host to send its peers.
+func (s *PickupSystem) Snapshot(w *engine.World) []PickupState {
+	var out []PickupState
+	each(w, func(_ engine.Entity, p *Pickup, pos *PositionComponent) {
+		out = append(out, PickupState{Pickup: *p, X: pos.X, Y: pos.Y})
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Sync makes the world's pickups those of a host's snapshot: it spawns
+// the missing ones, despawns those the host no longer has, collected by
+// another player or expired, and takes the host's despawn timers.
+func (s *PickupSystem) Sync(w *engine.World, states []PickupState) {
+	byID := make(map[uint64]PickupState, len(states))
+	for _, st := range states {
+		byID[st.ID] = st
+	}
+	each(w, func(ent engine.Entity, p *Pickup, pos *PositionComponent) {
+		st, ok := byID[p.ID]
+		if !ok {
+			w.RemoveEntity(ent)
+			return
+		}
+		p.Remaining = st.Remaining
+		pos.X, pos.Y = st.X, st.Y
+		delete(byID, p.ID)
+	})
+	for _, st := range states {
+		if _, missing := byID[st.ID]; missing {
+			s.Spawn(w, st.Pickup, st.X, st.Y)
+		}
+	}
+}
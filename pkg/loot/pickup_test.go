@@ -0,0 +1,64 @@
+package loot
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+func TestPickupSystem_Collect(t *testing.T) {
+	w := engine.NewWorld()
+	s := NewPickupSystem()
+	near := s.Spawn(w, Pickup{Kind: PickupCredits, Item: "credits", Amount: 25}, 2, 0)
+	s.Spawn(w, Pickup{Kind: PickupAmmo, Item: "bullets", Amount: 10}, 10, 0)
+
+	// In magnet range the credits drift in until they are collected.
+	var got []Pickup
+	for i := 0; i < 60 && len(got) == 0; i++ {
+		got = s.Update(w, 0, 0, 1.0/60)
+	}
+	if len(got) != 1 || got[0].Kind != PickupCredits || got[0].Amount != 25 {
+		t.Fatalf("collected %+v, want the credits", got)
+	}
+	if _, ok := w.GetComponent(near, pickupType); ok {
+		t.Error("collected pickup still in the world")
+	}
+	if n := len(s.Snapshot(w)); n != 1 {
+		t.Errorf("%d pickups left, want the ammo out of range", n)
+	}
+}
+
+func TestPickupSystem_Despawn(t *testing.T) {
+	w := engine.NewWorld()
+	s := NewPickupSystem()
+	s.Spawn(w, Pickup{Item: "medkit", Remaining: 1}, 10, 10)
+	s.Spawn(w, Pickup{Item: "potion"}, 10, 10)
+	s.Update(w, 0, 0, 1.5)
+	snap := s.Snapshot(w)
+	if len(snap) != 1 || snap[0].Item != "potion" || snap[0].Remaining != DefaultPickupLifetime-1.5 {
+		t.Errorf("after 1.5s the world holds %+v, want the potion with its timer running", snap)
+	}
+}
+
+func TestPickupSystem_Sync(t *testing.T) {
+	host, peer := engine.NewWorld(), engine.NewWorld()
+	hs, ps := NewPickupSystem(), NewPickupSystem()
+	hs.Spawn(host, Pickup{Kind: PickupWeapon, Item: "weapon", Seed: 42}, 3, 3)
+	hs.Spawn(host, Pickup{Item: "medkit"}, 5, 5)
+	ps.Sync(peer, hs.Snapshot(host))
+	if got := ps.Snapshot(peer); len(got) != 2 || got[0].Seed != 42 {
+		t.Fatalf("peer holds %+v after the first sync", got)
+	}
+
+	// The host's player takes the weapon and time passes on the host.
+	hs.Update(host, 3, 3, 2)
+	ps.Sync(peer, hs.Snapshot(host))
+	got := ps.Snapshot(peer)
+	if len(got) != 1 || got[0].Item != "medkit" || got[0].Remaining != DefaultPickupLifetime-2 {
+		t.Errorf("peer holds %+v, want the medkit on the host's timer", got)
+	}
+	ps.Spawn(peer, Pickup{Item: "potion"}, 0, 0)
+	if snap := ps.Snapshot(peer); snap[len(snap)-1].ID <= got[0].ID {
+		t.Errorf("a local spawn reused synced ID %d", snap[len(snap)-1].ID)
+	}
+}
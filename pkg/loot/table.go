@@ -0,0 +1,270 @@
+package loot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// ErrInvalidTable is returned when a loot table cannot be rolled.
+var ErrInvalidTable = errors.New("invalid loot table")
+
+// Loot table defaults, used for fields a table leaves at zero.
+var (
+	// DefaultWeights are the odds of each rarity tier, common to legendary.
+	DefaultWeights = [...]float64{70, 20, 8, 2}
+	// DefaultScaling is how much more likely each tier above common gets
+	// per level of depth, per tier.
+	DefaultScaling = 0.1
+	// DefaultPity is how many rolls in a row may miss a rare drop before
+	// the next one is guaranteed.
+	DefaultPity = 12
+)
+
+// Entry is an item a Table drops with an independent Chance [0-1] from
+// MinDepth on.
+type Entry struct {
+	Item     string  `json:"item"`
+	Name     string  `json:"name"`
+	Chance   float64 `json:"chance"`
+	MinDepth int     `json:"min_depth,omitempty"`
+}
+
+// Table is the loot of an enemy archetype or a container. Every entry
+// rolls on its own; each item that drops then rolls the rarity it is at
+// least, by Weights scaled up towards the rarer tiers with depth. After
+// Pity rolls in a row without a rare or better drop the next roll drops
+// one. Zero fields take the package defaults; a negative Pity turns the
+// pity timer off. Tables are data, e.g. in a mod's loot.json.
+type Table struct {
+	ID      string     `json:"id"`
+	Entries []Entry    `json:"entries"`
+	Weights [4]float64 `json:"weights"`
+	Scaling float64    `json:"scaling,omitempty"`
+	Pity    int        `json:"pity,omitempty"`
+}
+
+// Result is an item a table dropped and the rarity it is at least.
+type Result struct {
+	Item   string
+	Name   string
+	Rarity Rarity
+}
+
+// Validate checks that a table can be rolled.
+func (t *Table) Validate() error {
+	if t.ID == "" {
+		return fmt.Errorf("%w: missing id", ErrInvalidTable)
+	}
+	for _, e := range t.Entries {
+		if e.Item == "" || e.Chance < 0 || e.Chance > 1 {
+			return fmt.Errorf("%w: %s: entries need an item and a chance within [0, 1]", ErrInvalidTable, t.ID)
+		}
+	}
+	for _, w := range t.Weights {
+		if w < 0 {
+			return fmt.Errorf("%w: %s: weights must not be negative", ErrInvalidTable, t.ID)
+		}
+	}
+	if t.Scaling < 0 {
+		return fmt.Errorf("%w: %s: scaling must not be negative", ErrInvalidTable, t.ID)
+	}
+	return nil
+}
+
+// weights returns the odds of each tier at depth.
+func (t *Table) weights(depth int) [4]float64 {
+	w := t.Weights
+	if w == [4]float64{} {
+		w = DefaultWeights
+	}
+	scaling := t.Scaling
+	if scaling == 0 {
+		scaling = DefaultScaling
+	}
+	levels := float64(max(depth-1, 0))
+	for r := RarityUncommon; r <= RarityLegendary; r++ {
+		w[r] *= 1 + scaling*levels*float64(r)
+	}
+	return w
+}
+
+// pity returns how many misses the table allows, or 0 for no limit.
+func (t *Table) pity() int {
+	switch {
+	case t.Pity < 0:
+		return 0
+	case t.Pity == 0:
+		return DefaultPity
+	}
+	return t.Pity
+}
+
+// rollRarity picks a tier of at least lowest by the weights.
+func rollRarity(r *rng.RNG, w [4]float64, lowest Rarity) Rarity {
+	total := 0.0
+	for tier := lowest; tier <= RarityLegendary; tier++ {
+		total += w[tier]
+	}
+	if total <= 0 {
+		return lowest
+	}
+	roll := r.Float64() * total
+	for tier := lowest; tier < RarityLegendary; tier++ {
+		if roll < w[tier] {
+			return tier
+		}
+		roll -= w[tier]
+	}
+	return RarityLegendary
+}
+
+// Roll rolls the table at depth from seed. The same seed, depth and pity
+// state always roll the same. pity tracks the misses of the table between
+// rolls and may be nil to roll without it.
+func (t *Table) Roll(depth int, seed uint64, pity *Pity) []Result {
+	r := rng.NewRNG(seed)
+	w := t.weights(depth)
+
+	var out []Result
+	likeliest := -1
+	for i, e := range t.Entries {
+		if e.MinDepth > depth {
+			continue
+		}
+		if likeliest < 0 || e.Chance > t.Entries[likeliest].Chance {
+			likeliest = i
+		}
+		if r.Float64() < e.Chance {
+			out = append(out, Result{Item: e.Item, Name: e.Name, Rarity: rollRarity(r, w, RarityCommon)})
+		}
+	}
+
+	limit := t.pity()
+	if pity == nil || limit == 0 || likeliest < 0 {
+		return out
+	}
+	for _, res := range out {
+		if res.Rarity >= RarityRare {
+			pity.misses[t.ID] = 0
+			return out
+		}
+	}
+	if pity.misses[t.ID] < limit {
+		pity.misses[t.ID]++
+		return out
+	}
+	if len(out) == 0 {
+		e := t.Entries[likeliest]
+		out = append(out, Result{Item: e.Item, Name: e.Name})
+	}
+	out[0].Rarity = rollRarity(r, w, RarityRare)
+	pity.misses[t.ID] = 0
+	return out
+}
+
+// Pity counts, per table, the rolls in a row that missed a rare drop.
+type Pity struct {
+	misses map[string]int
+}
+
+// NewPity creates a pity tracker with no misses.
+func NewPity() *Pity {
+	return &Pity{misses: make(map[string]int)}
+}
+
+// Misses returns the rolls in a row of the table that missed a rare drop.
+func (p *Pity) Misses(tableID string) int {
+	return p.misses[tableID]
+}
+
+// Tables is a registry of loot tables by ID: the built-in container
+// tables and any loaded from files.
+type Tables struct {
+	tables map[string]*Table
+}
+
+// builtinTables are the loot of the level's containers.
+var builtinTables = []Table{
+	{ID: "crate", Entries: []Entry{
+		{Item: "medkit", Name: "Medkit", Chance: 0.4},
+		{Item: "potion", Name: "Potion", Chance: 0.2},
+		{Item: "grenade", Name: "Grenade", Chance: 0.15, MinDepth: 2},
+	}},
+	{ID: "barrel", Entries: []Entry{
+		{Item: "grenade", Name: "Grenade", Chance: 0.2},
+		{Item: "proximity_mine", Name: "Proximity Mine", Chance: 0.1, MinDepth: 3},
+	}, Pity: -1},
+}
+
+// NewTables creates a registry holding the built-in tables.
+func NewTables() *Tables {
+	ts := &Tables{tables: make(map[string]*Table, len(builtinTables))}
+	for i := range builtinTables {
+		t := builtinTables[i]
+		t.Entries = append([]Entry(nil), t.Entries...)
+		ts.tables[t.ID] = &t
+	}
+	return ts
+}
+
+// Get returns the table with the ID.
+func (ts *Tables) Get(id string) (*Table, bool) {
+	t, ok := ts.tables[id]
+	return t, ok
+}
+
+// Register adds the table, replacing any with its ID.
+func (ts *Tables) Register(t Table) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	ts.tables[t.ID] = &t
+	return nil
+}
+
+// LoadJSON registers the tables of a JSON array, e.g. a mod's loot.json.
+// A table whose ID is already registered overrides only the fields it
+// sets; a table with an enemy archetype's ID replaces that archetype's
+// loot. Nothing is registered if any table is invalid.
+func (ts *Tables) LoadJSON(data []byte) (int, error) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidTable, err)
+	}
+	loaded := make([]Table, len(raws))
+	for i, raw := range raws {
+		var head struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &head); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrInvalidTable, err)
+		}
+		if old, ok := ts.tables[head.ID]; ok {
+			loaded[i] = *old
+			loaded[i].Entries = append([]Entry(nil), old.Entries...)
+		}
+		if err := json.Unmarshal(raw, &loaded[i]); err != nil {
+			return 0, fmt.Errorf("%w: %s: %v", ErrInvalidTable, head.ID, err)
+		}
+		if err := loaded[i].Validate(); err != nil {
+			return 0, err
+		}
+	}
+	for i := range loaded {
+		ts.tables[loaded[i].ID] = &loaded[i]
+	}
+	return len(loaded), nil
+}
+
+// LoadFile registers the tables of a JSON file.
+func (ts *Tables) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return ts.LoadJSON(data)
+}
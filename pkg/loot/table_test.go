@@ -0,0 +1,126 @@
+package loot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTableRollDeterministic(t *testing.T) {
+	table := &Table{ID: "t", Entries: []Entry{
+		{Item: "medkit", Chance: 0.5},
+		{Item: "grenade", Chance: 0.5},
+	}}
+	for seed := uint64(0); seed < 50; seed++ {
+		a := table.Roll(3, seed, nil)
+		b := table.Roll(3, seed, nil)
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("seed %d rolled %v then %v", seed, a, b)
+		}
+	}
+}
+
+func TestTableMinDepth(t *testing.T) {
+	table := &Table{ID: "t", Entries: []Entry{{Item: "mine", Chance: 1, MinDepth: 3}}}
+	if got := table.Roll(2, 1, nil); len(got) != 0 {
+		t.Errorf("depth 2 dropped %v before the min depth", got)
+	}
+	if got := table.Roll(3, 1, nil); len(got) != 1 {
+		t.Errorf("depth 3 dropped %v, want the mine", got)
+	}
+}
+
+func TestTableDepthScaling(t *testing.T) {
+	table := &Table{ID: "t", Entries: []Entry{{Item: "medkit", Chance: 1}}}
+	rare := func(depth int) int {
+		n := 0
+		for seed := uint64(0); seed < 2000; seed++ {
+			if table.Roll(depth, seed, nil)[0].Rarity >= RarityRare {
+				n++
+			}
+		}
+		return n
+	}
+	if shallow, deep := rare(1), rare(10); deep <= shallow {
+		t.Errorf("depth 10 rolled %d rare drops, depth 1 %d; want more deeper", deep, shallow)
+	}
+}
+
+func TestTablePity(t *testing.T) {
+	table := &Table{ID: "t", Weights: [4]float64{1}, Pity: 3, Entries: []Entry{{Item: "medkit", Chance: 0}}}
+	p := NewPity()
+	for i := range 3 {
+		if got := table.Roll(1, uint64(i), p); len(got) != 0 {
+			t.Fatalf("roll %d dropped %v", i, got)
+		}
+	}
+	if p.Misses("t") != 3 {
+		t.Fatalf("misses = %d, want 3", p.Misses("t"))
+	}
+	got := table.Roll(1, 9, p)
+	if len(got) != 1 || got[0].Item != "medkit" || got[0].Rarity < RarityRare {
+		t.Fatalf("pity roll = %v, want a rare medkit", got)
+	}
+	if p.Misses("t") != 0 {
+		t.Errorf("misses after the pity drop = %d, want 0", p.Misses("t"))
+	}
+
+	table.Pity = -1
+	for i := range 10 {
+		if got := table.Roll(1, uint64(i), p); len(got) != 0 {
+			t.Fatalf("roll %d with pity off dropped %v", i, got)
+		}
+	}
+}
+
+func TestTables_LoadJSON(t *testing.T) {
+	ts := NewTables()
+	if _, ok := ts.Get("crate"); !ok {
+		t.Fatal("no built-in crate table")
+	}
+	data := `[
+		{"id": "crate", "pity": 4},
+		{"id": "fantasy_guard", "entries": [{"item": "potion", "chance": 0.5}], "weights": [0, 0, 1, 1]}
+	]`
+	if n, err := ts.LoadJSON([]byte(data)); err != nil || n != 2 {
+		t.Fatalf("LoadJSON = %d, %v", n, err)
+	}
+	crate, _ := ts.Get("crate")
+	if crate.Pity != 4 || len(crate.Entries) != len(builtinTables[0].Entries) {
+		t.Errorf("crate override = %+v, want the built-in entries with pity 4", crate)
+	}
+	if builtinTables[0].Pity != 0 {
+		t.Error("override changed the built-in crate table")
+	}
+	guard, ok := ts.Get("fantasy_guard")
+	if !ok || len(guard.Entries) != 1 || guard.Entries[0].Item != "potion" || guard.Weights[RarityRare] != 1 {
+		t.Errorf("guard table = %+v", guard)
+	}
+
+	for _, bad := range []string{
+		`{"id": "crate"}`,
+		`[{"entries": []}]`,
+		`[{"id": "x", "entries": [{"item": "medkit", "chance": 2}]}]`,
+		`[{"id": "x", "weights": [1, -1, 0, 0]}]`,
+	} {
+		if _, err := NewTables().LoadJSON([]byte(bad)); !errors.Is(err, ErrInvalidTable) {
+			t.Errorf("LoadJSON(%s) err = %v, want ErrInvalidTable", bad, err)
+		}
+	}
+}
+
+func TestTables_LoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loot.json")
+	if err := os.WriteFile(path, []byte(`[{"id": "locker", "entries": [{"item": "medkit", "chance": 1}]}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ts := NewTables()
+	if n, err := ts.LoadFile(path); err != nil || n != 1 {
+		t.Fatalf("LoadFile = %d, %v", n, err)
+	}
+	if _, ok := ts.Get("locker"); !ok {
+		t.Error("locker not registered")
+	}
+}
@@ -2,6 +2,7 @@
 package minigame
 
 import (
+	"math"
 	"math/rand"
 )
 
@@ -477,18 +478,396 @@ func (b *BypassCodeGame) GetAttempts() int {
 	return b.MaxAttempts - b.Attempts
 }
 
+// WireMatchGame is a wiring mini-game for cyberpunk, scifi and postapoc.
+// Player must connect each wire to the terminal of the same color.
+type WireMatchGame struct {
+	Complete    bool
+	Progress    float64
+	Wires       []int  // Color of each wire, top to bottom
+	Terminals   []int  // Color of each terminal, top to bottom
+	Connected   []bool // Whether each wire is connected
+	Cursor      int    // Highlighted wire, or terminal while a wire is held
+	Held        int    // Wire being connected, or -1
+	Attempts    int
+	MaxAttempts int
+	Difficulty  int
+}
+
+// NewWireMatchGame creates a new wire matching game.
+func NewWireMatchGame(difficulty int, seed int64) *WireMatchGame {
+	rng := rand.New(rand.NewSource(seed))
+	wires := 3 + difficulty
+
+	return &WireMatchGame{
+		Wires:       rng.Perm(wires),
+		Terminals:   rng.Perm(wires),
+		Connected:   make([]bool, wires),
+		Held:        -1,
+		MaxAttempts: 3,
+		Difficulty:  difficulty,
+	}
+}
+
+// Start begins the wire matching game.
+func (w *WireMatchGame) Start() {
+	w.Connected = make([]bool, len(w.Wires))
+	w.Cursor = 0
+	w.Held = -1
+	w.Attempts = 0
+	w.Complete = false
+	w.Progress = 0
+}
+
+// MoveCursor moves the cursor up (negative) or down the current column,
+// wrapping at the ends.
+func (w *WireMatchGame) MoveCursor(delta int) {
+	if w.Complete {
+		return
+	}
+	n := len(w.Wires)
+	w.Cursor = ((w.Cursor+delta)%n + n) % n
+}
+
+// Select picks up the wire under the cursor or, while holding one, connects
+// it to the terminal under the cursor.
+func (w *WireMatchGame) Select() bool {
+	if w.Complete {
+		return false
+	}
+	if w.Held < 0 {
+		if w.Connected[w.Cursor] {
+			return false
+		}
+		w.Held = w.Cursor
+		return true
+	}
+	wire := w.Held
+	w.Held = -1
+	return w.Connect(wire, w.Cursor)
+}
+
+// Connect joins a wire to a terminal. A terminal of another color sparks
+// and costs an attempt.
+func (w *WireMatchGame) Connect(wire, terminal int) bool {
+	if w.Complete || wire < 0 || wire >= len(w.Wires) || terminal < 0 || terminal >= len(w.Terminals) {
+		return false
+	}
+	if w.Connected[wire] {
+		return false
+	}
+
+	if w.Wires[wire] != w.Terminals[terminal] {
+		w.Attempts++
+		if w.Attempts >= w.MaxAttempts {
+			w.Complete = true // Failed
+		}
+		return false
+	}
+
+	w.Connected[wire] = true
+	connected := 0
+	for _, c := range w.Connected {
+		if c {
+			connected++
+		}
+	}
+	w.Progress = float64(connected) / float64(len(w.Wires))
+	if connected == len(w.Wires) {
+		w.Complete = true
+		w.Progress = 1.0
+	}
+	return true
+}
+
+// Terminal returns the terminal of the same color as a wire.
+func (w *WireMatchGame) Terminal(wire int) int {
+	for i, c := range w.Terminals {
+		if c == w.Wires[wire] {
+			return i
+		}
+	}
+	return -1
+}
+
+// Update advances the wire matching game; returns true when finished.
+func (w *WireMatchGame) Update() bool {
+	return w.Complete
+}
+
+// GetProgress returns the fraction of wires connected (0.0 to 1.0).
+func (w *WireMatchGame) GetProgress() float64 {
+	return w.Progress
+}
+
+// GetAttempts returns remaining attempts.
+func (w *WireMatchGame) GetAttempts() int {
+	return w.MaxAttempts - w.Attempts
+}
+
+// FrequencyTuneGame is a radio tuning mini-game for cyberpunk, scifi and
+// horror. Player must hold the dial on each band's hidden frequency until
+// the signal locks, before the band's time runs out.
+type FrequencyTuneGame struct {
+	Complete    bool
+	Progress    float64
+	Dial        float64   // Tuned frequency, 0.0 to 1.0
+	Targets     []float64 // Hidden frequency of each band
+	Band        int       // Band being tuned
+	Drift       float64   // How far the target moves each frame; it turns back at the ends
+	Tolerance   float64   // Distance from the target that holds the signal
+	Hold        int       // Frames held on the target
+	HoldFrames  int       // Frames on the target that lock a band
+	Time        int       // Frames spent on the current band
+	TimeLimit   int       // Frames per band before an attempt is lost
+	Attempts    int
+	MaxAttempts int
+	Difficulty  int
+}
+
+// NewFrequencyTuneGame creates a new frequency tuning game.
+func NewFrequencyTuneGame(difficulty int, seed int64) *FrequencyTuneGame {
+	rng := rand.New(rand.NewSource(seed))
+	bands := 1 + (difficulty+1)/2
+	targets := make([]float64, bands)
+	for i := range targets {
+		targets[i] = 0.1 + rng.Float64()*0.8 // 0.1-0.9
+	}
+	drift := float64(difficulty) * 0.0008
+	if rng.Intn(2) == 0 {
+		drift = -drift
+	}
+
+	return &FrequencyTuneGame{
+		Targets:     targets,
+		Drift:       drift,
+		Tolerance:   0.05 - float64(difficulty)*0.01,
+		HoldFrames:  45,
+		TimeLimit:   600,
+		MaxAttempts: 3,
+		Difficulty:  difficulty,
+	}
+}
+
+// Start begins the frequency tuning game.
+func (f *FrequencyTuneGame) Start() {
+	f.Dial = 0
+	f.Band = 0
+	f.Hold = 0
+	f.Time = 0
+	f.Attempts = 0
+	f.Complete = false
+	f.Progress = 0
+}
+
+// Tune turns the dial by delta, within 0.0 to 1.0.
+func (f *FrequencyTuneGame) Tune(delta float64) {
+	if f.Complete {
+		return
+	}
+	f.Dial = math.Max(0, math.Min(1, f.Dial+delta))
+}
+
+// Signal returns the strength of the signal at the dial (0.0 to 1.0), which
+// rises as the dial nears the current band's frequency.
+func (f *FrequencyTuneGame) Signal() float64 {
+	if f.Band >= len(f.Targets) {
+		return 1.0
+	}
+	return math.Max(0, 1-math.Abs(f.Dial-f.Targets[f.Band])*4)
+}
+
+// Update moves the target and the clock on by a frame, locking the band
+// once the dial has been held on it; returns true when finished.
+func (f *FrequencyTuneGame) Update() bool {
+	if f.Complete {
+		return true
+	}
+
+	target := f.Targets[f.Band] + f.Drift
+	if target < 0.05 || target > 0.95 {
+		f.Drift = -f.Drift
+		target = f.Targets[f.Band] + f.Drift
+	}
+	f.Targets[f.Band] = target
+
+	f.Time++
+	if math.Abs(f.Dial-target) <= f.Tolerance {
+		f.Hold++
+	} else {
+		f.Hold = 0
+	}
+
+	if f.Hold >= f.HoldFrames {
+		f.Band++
+		f.Hold = 0
+		f.Time = 0
+		if f.Band >= len(f.Targets) {
+			f.Complete = true
+			f.Progress = 1.0
+			return true
+		}
+	} else if f.Time >= f.TimeLimit {
+		f.Attempts++
+		f.Hold = 0
+		f.Time = 0
+		if f.Attempts >= f.MaxAttempts {
+			f.Complete = true // Failed
+			return true
+		}
+	}
+
+	f.Progress = (float64(f.Band) + float64(f.Hold)/float64(f.HoldFrames)) / float64(len(f.Targets))
+	return false
+}
+
+// GetProgress returns the locked bands, counting the lock building on the
+// current one (0.0 to 1.0).
+func (f *FrequencyTuneGame) GetProgress() float64 {
+	return f.Progress
+}
+
+// GetAttempts returns remaining attempts.
+func (f *FrequencyTuneGame) GetAttempts() int {
+	return f.MaxAttempts - f.Attempts
+}
+
+// RhythmLockGame is a tumbler mini-game for fantasy, postapoc and horror.
+// Player must strike on the beat enough times in a row to turn the lock;
+// an off-beat strike resets the tumblers.
+type RhythmLockGame struct {
+	Complete    bool
+	Progress    float64
+	Frame       int // Frames since the game started
+	Interval    int // Frames between beats
+	Window      int // Frames either side of a beat that count as on it
+	Streak      int // Strikes on the beat in a row
+	Required    int // Strikes in a row that turn the lock
+	LastBeat    int // Beat last struck, so each beat counts once
+	Attempts    int
+	MaxAttempts int
+	Difficulty  int
+}
+
+// NewRhythmLockGame creates a new rhythm lock game.
+func NewRhythmLockGame(difficulty int, seed int64) *RhythmLockGame {
+	rng := rand.New(rand.NewSource(seed))
+
+	return &RhythmLockGame{
+		Interval:    40 - difficulty*4 + rng.Intn(8),
+		Window:      6 - difficulty,
+		Required:    4 + difficulty,
+		MaxAttempts: 4,
+		Difficulty:  difficulty,
+	}
+}
+
+// Start begins the rhythm lock game.
+func (r *RhythmLockGame) Start() {
+	r.Frame = 0
+	r.Streak = 0
+	r.LastBeat = 0 // The beat the game starts on can't be struck
+	r.Attempts = 0
+	r.Complete = false
+	r.Progress = 0
+}
+
+// Strike hits the lock, setting a tumbler when it lands on a beat.
+func (r *RhythmLockGame) Strike() bool {
+	if r.Complete {
+		return false
+	}
+
+	beat := (r.Frame + r.Interval/2) / r.Interval
+	offset := r.Frame - beat*r.Interval
+	if offset < 0 {
+		offset = -offset
+	}
+
+	if offset <= r.Window && beat != r.LastBeat {
+		r.LastBeat = beat
+		r.Streak++
+		r.Progress = float64(r.Streak) / float64(r.Required)
+		if r.Streak >= r.Required {
+			r.Complete = true
+			r.Progress = 1.0
+		}
+		return true
+	}
+
+	// Off the beat
+	r.Attempts++
+	r.Streak = 0
+	r.Progress = 0
+	if r.Attempts >= r.MaxAttempts {
+		r.Complete = true // Failed
+	}
+	return false
+}
+
+// BeatPhase returns how far the game is between beats, 0.0 on a beat.
+func (r *RhythmLockGame) BeatPhase() float64 {
+	return float64(r.Frame%r.Interval) / float64(r.Interval)
+}
+
+// Update moves the rhythm on by a frame; returns true when finished.
+func (r *RhythmLockGame) Update() bool {
+	if !r.Complete {
+		r.Frame++
+	}
+	return r.Complete
+}
+
+// GetProgress returns the tumblers set in the current streak (0.0 to 1.0).
+func (r *RhythmLockGame) GetProgress() float64 {
+	return r.Progress
+}
+
+// GetAttempts returns remaining attempts.
+func (r *RhythmLockGame) GetAttempts() int {
+	return r.MaxAttempts - r.Attempts
+}
+
+// genreMiniGames lists the mini-games each genre's locked doors use. The
+// first is the genre's own; the others join it from difficulty 1, so doors
+// vary more the further a campaign goes.
+var genreMiniGames = map[string][]func(difficulty int, seed int64) MiniGame{
+	"fantasy": {
+		func(d int, s int64) MiniGame { return NewLockpickGame(d, s) },
+		func(d int, s int64) MiniGame { return NewRhythmLockGame(d, s) },
+	},
+	"cyberpunk": {
+		func(d int, s int64) MiniGame { return NewCircuitTraceGame(d, s) },
+		func(d int, s int64) MiniGame { return NewWireMatchGame(d, s) },
+		func(d int, s int64) MiniGame { return NewFrequencyTuneGame(d, s) },
+	},
+	"scifi": {
+		func(d int, s int64) MiniGame { return NewBypassCodeGame(d, s) },
+		func(d int, s int64) MiniGame { return NewFrequencyTuneGame(d, s) },
+		func(d int, s int64) MiniGame { return NewWireMatchGame(d, s) },
+	},
+	"postapoc": {
+		func(d int, s int64) MiniGame { return NewBypassCodeGame(d, s) },
+		func(d int, s int64) MiniGame { return NewWireMatchGame(d, s) },
+		func(d int, s int64) MiniGame { return NewRhythmLockGame(d, s) },
+	},
+	"horror": {
+		func(d int, s int64) MiniGame { return NewHackGame(d, s) },
+		func(d int, s int64) MiniGame { return NewFrequencyTuneGame(d, s) },
+		func(d int, s int64) MiniGame { return NewRhythmLockGame(d, s) },
+	},
+}
+
 // GetGenreMiniGame returns the appropriate mini-game type for a genre.
+// At difficulty 0 it is the genre's own game; above that the seed picks
+// one of the genre's games.
 func GetGenreMiniGame(genre string, difficulty int, seed int64) MiniGame {
-	switch genre {
-	case "fantasy":
-		return NewLockpickGame(difficulty, seed)
-	case "cyberpunk":
-		return NewCircuitTraceGame(difficulty, seed)
-	case "scifi", "postapoc":
-		return NewBypassCodeGame(difficulty, seed)
-	case "horror":
-		return NewHackGame(difficulty, seed)
-	default:
+	games, ok := genreMiniGames[genre]
+	if !ok {
 		return NewHackGame(difficulty, seed)
 	}
+	if difficulty <= 0 {
+		return games[0](difficulty, seed)
+	}
+	pick := rand.New(rand.NewSource(seed)).Intn(len(games))
+	return games[pick](difficulty, seed)
 }
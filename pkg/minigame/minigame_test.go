@@ -1,6 +1,8 @@
 package minigame
 
 import (
+	"fmt"
+	"math"
 	"testing"
 )
 
@@ -533,12 +535,40 @@ func TestGetGenreMiniGame(t *testing.T) {
 	}
 }
 
+func TestGetGenreMiniGameDifficulty(t *testing.T) {
+	// At difficulty 0 each genre keeps its own game
+	if _, ok := GetGenreMiniGame("fantasy", 0, 7).(*LockpickGame); !ok {
+		t.Error("fantasy at difficulty 0 should be lockpicking")
+	}
+
+	// Later doors draw on the genre's other games too
+	seen := make(map[string]bool)
+	for seed := int64(0); seed < 50; seed++ {
+		seen[fmt.Sprintf("%T", GetGenreMiniGame("cyberpunk", 2, seed))] = true
+	}
+	for _, name := range []string{"*minigame.CircuitTraceGame", "*minigame.WireMatchGame", "*minigame.FrequencyTuneGame"} {
+		if !seen[name] {
+			t.Errorf("cyberpunk never used %s", name)
+		}
+	}
+
+	// The same door always gets the same game
+	a := fmt.Sprintf("%T", GetGenreMiniGame("horror", 3, 99))
+	b := fmt.Sprintf("%T", GetGenreMiniGame("horror", 3, 99))
+	if a != b {
+		t.Errorf("same seed gave %s and %s", a, b)
+	}
+}
+
 func TestAllMiniGamesImplementInterface(t *testing.T) {
 	games := []MiniGame{
 		NewHackGame(1, 12345),
 		NewLockpickGame(1, 12345),
 		NewCircuitTraceGame(1, 12345),
 		NewBypassCodeGame(1, 12345),
+		NewWireMatchGame(1, 12345),
+		NewFrequencyTuneGame(1, 12345),
+		NewRhythmLockGame(1, 12345),
 	}
 
 	for i, game := range games {
@@ -585,3 +615,206 @@ func TestLockpickGame_PositionWrapping(t *testing.T) {
 		t.Errorf("second advance: position = %f, want ~0.2 (wrapped)", game.Position)
 	}
 }
+
+func TestWireMatchGame_Connect(t *testing.T) {
+	game := NewWireMatchGame(1, 12345)
+	game.Start()
+
+	if len(game.Wires) != 4 || len(game.Terminals) != 4 {
+		t.Fatalf("wires = %v, terminals = %v", game.Wires, game.Terminals)
+	}
+
+	// A terminal of the wrong color costs an attempt
+	wrong := (game.Terminal(0) + 1) % len(game.Terminals)
+	if game.Connect(0, wrong) {
+		t.Error("connected a wire to the wrong terminal")
+	}
+	if game.GetAttempts() != 2 {
+		t.Errorf("attempts = %d, want 2", game.GetAttempts())
+	}
+
+	for wire := range game.Wires {
+		if !game.Connect(wire, game.Terminal(wire)) {
+			t.Fatalf("failed to connect wire %d", wire)
+		}
+		if game.Connect(wire, game.Terminal(wire)) {
+			t.Fatalf("connected wire %d twice", wire)
+		}
+	}
+	if !game.Update() || game.GetProgress() != 1.0 {
+		t.Errorf("complete = %v, progress = %f", game.Complete, game.GetProgress())
+	}
+}
+
+func TestWireMatchGame_Select(t *testing.T) {
+	game := NewWireMatchGame(0, 12345)
+	game.Start()
+
+	game.MoveCursor(-1)
+	if game.Cursor != 2 {
+		t.Fatalf("cursor = %d, want wrap to 2", game.Cursor)
+	}
+	if !game.Select() || game.Held != 2 {
+		t.Fatalf("held = %d, want 2", game.Held)
+	}
+	game.MoveCursor(game.Terminal(2) - game.Cursor)
+	if !game.Select() || !game.Connected[2] || game.Held != -1 {
+		t.Errorf("connected = %v, held = %d", game.Connected, game.Held)
+	}
+
+	// A connected wire can't be picked up again
+	game.MoveCursor(2 - game.Cursor)
+	if game.Select() {
+		t.Error("picked up a connected wire")
+	}
+}
+
+func TestWireMatchGame_MaxAttempts(t *testing.T) {
+	game := NewWireMatchGame(0, 12345)
+	game.Start()
+
+	wrong := (game.Terminal(0) + 1) % len(game.Terminals)
+	for i := 0; i < game.MaxAttempts; i++ {
+		game.Connect(0, wrong)
+	}
+	if !game.Complete || game.GetProgress() >= 1.0 {
+		t.Errorf("complete = %v, progress = %f; want failed", game.Complete, game.GetProgress())
+	}
+}
+
+func TestFrequencyTuneGame_LocksBands(t *testing.T) {
+	game := NewFrequencyTuneGame(2, 12345)
+	game.Start()
+
+	if len(game.Targets) != 2 {
+		t.Fatalf("bands = %d, want 2", len(game.Targets))
+	}
+
+	for frame := 0; frame < 1000 && !game.Complete; frame++ {
+		if game.Band < len(game.Targets) {
+			game.Tune(game.Targets[game.Band] - game.Dial)
+		}
+		game.Update()
+	}
+	if game.GetProgress() != 1.0 || game.GetAttempts() != game.MaxAttempts {
+		t.Errorf("progress = %f, attempts = %d", game.GetProgress(), game.GetAttempts())
+	}
+}
+
+func TestFrequencyTuneGame_Progress(t *testing.T) {
+	game := NewFrequencyTuneGame(0, 12345)
+	game.Start()
+
+	game.Tune(game.Targets[0] - game.Dial)
+	if game.Signal() != 1.0 {
+		t.Errorf("signal on target = %f", game.Signal())
+	}
+	for i := 0; i < game.HoldFrames/2; i++ {
+		game.Update()
+	}
+	if p := game.GetProgress(); p < 0.4 || p > 0.6 {
+		t.Errorf("progress half way through the hold = %f", p)
+	}
+
+	// Leaving the frequency loses the lock
+	game.Dial = math.Mod(game.Targets[0]+0.5, 1)
+	game.Update()
+	if game.Hold != 0 || game.GetProgress() != 0 {
+		t.Errorf("hold = %d, progress = %f after detuning", game.Hold, game.GetProgress())
+	}
+}
+
+func TestFrequencyTuneGame_TimeLimit(t *testing.T) {
+	game := NewFrequencyTuneGame(0, 12345)
+	game.Start()
+	game.Dial = math.Mod(game.Targets[0]+0.5, 1)
+
+	for i := 0; i < game.TimeLimit*game.MaxAttempts; i++ {
+		game.Update()
+	}
+	if !game.Complete || game.GetAttempts() != 0 || game.GetProgress() >= 1.0 {
+		t.Errorf("complete = %v, attempts = %d; want failed", game.Complete, game.GetAttempts())
+	}
+}
+
+func TestFrequencyTuneGame_DialClamped(t *testing.T) {
+	game := NewFrequencyTuneGame(3, 12345)
+	game.Start()
+
+	game.Tune(-1)
+	if game.Dial != 0 {
+		t.Errorf("dial = %f, want 0", game.Dial)
+	}
+	game.Tune(5)
+	if game.Dial != 1 {
+		t.Errorf("dial = %f, want 1", game.Dial)
+	}
+
+	// The drifting target stays on the dial
+	for i := 0; i < 2000; i++ {
+		game.Update()
+		if target := game.Targets[game.Band]; target < 0 || target > 1 {
+			t.Fatalf("target drifted to %f", target)
+		}
+	}
+}
+
+// beat runs a rhythm lock game to the next beat.
+func beat(game *RhythmLockGame) {
+	for {
+		game.Update()
+		if game.Frame%game.Interval == 0 {
+			return
+		}
+	}
+}
+
+func TestRhythmLockGame_OnBeat(t *testing.T) {
+	game := NewRhythmLockGame(1, 12345)
+	game.Start()
+
+	for i := 0; i < game.Required; i++ {
+		beat(game)
+		if !game.Strike() {
+			t.Fatalf("strike %d on the beat missed", i+1)
+		}
+	}
+	if !game.Update() || game.GetProgress() != 1.0 {
+		t.Errorf("complete = %v, progress = %f", game.Complete, game.GetProgress())
+	}
+}
+
+func TestRhythmLockGame_OffBeat(t *testing.T) {
+	game := NewRhythmLockGame(1, 12345)
+	game.Start()
+
+	beat(game)
+	game.Strike()
+	if game.Strike() {
+		t.Error("a beat counted twice")
+	}
+	if game.Streak != 0 || game.GetProgress() != 0 || game.GetAttempts() != game.MaxAttempts-1 {
+		t.Errorf("streak = %d, progress = %f, attempts = %d", game.Streak, game.GetProgress(), game.GetAttempts())
+	}
+
+	beat(game)
+	for i := 0; i < game.Interval/2; i++ {
+		game.Update()
+	}
+	if game.BeatPhase() < 0.4 || game.Strike() {
+		t.Errorf("strike between beats (phase %f) landed", game.BeatPhase())
+	}
+}
+
+func TestRhythmLockGame_MaxAttempts(t *testing.T) {
+	game := NewRhythmLockGame(0, 12345)
+	game.Start()
+
+	// The beat the game starts on doesn't count
+	for i := 0; i < game.MaxAttempts; i++ {
+		game.Strike()
+	}
+	if !game.Update() || game.GetAttempts() != 0 || game.GetProgress() != 0 {
+		t.Errorf("complete = %v, attempts = %d; want failed", game.Complete, game.GetAttempts())
+	}
+}
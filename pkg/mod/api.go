@@ -255,4 +255,5 @@ const (
 	EventTypeGenreSet      = "genre.set"
 	EventTypeModLoad       = "mod.load"
 	EventTypeModUnload     = "mod.unload"
+	EventTypeModReload     = "mod.reload"
 )
@@ -0,0 +1,184 @@
+package mod
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// APIVersion is the version of the mod-facing API: the manifest schema,
+// the content files the game reads, the Lua game table, script events and
+// plugin hooks. Minor versions only add to it. A major version may rename
+// or remove things; mods written for the major version before it still
+// load, through deprecation shims, until the next major version drops
+// them.
+const APIVersion = "2.0.0"
+
+// LegacyAPIVersion is the API version of mods whose manifest names none,
+// which were written before the API was versioned.
+const LegacyAPIVersion = "1.0.0"
+
+var (
+	// ErrIncompatibleAPI is returned for a mod written for a mod API
+	// version the game doesn't provide.
+	ErrIncompatibleAPI = errors.New("incompatible mod API")
+
+	// ErrIncompatibleGame is returned for a mod whose game version range
+	// leaves out the running game.
+	ErrIncompatibleGame = errors.New("incompatible game version")
+
+	// ErrMissingCapability is returned for a mod that needs a capability
+	// the game doesn't have.
+	ErrMissingCapability = errors.New("missing capability")
+)
+
+// Capability names an optional part of the mod API. Mods list the ones
+// they need in their manifest; the loader reports the ones the game has,
+// which can depend on the platform.
+type Capability string
+
+const (
+	// CapabilityScripts runs Lua scripts.
+	CapabilityScripts Capability = "scripts"
+
+	// CapabilityAssets replaces textures, sounds and sprite sheets.
+	CapabilityAssets Capability = "assets"
+
+	// CapabilityParticles loads particle presets from particles.json.
+	CapabilityParticles Capability = "particles"
+
+	// CapabilityBehaviors loads behavior trees from behaviors.json.
+	CapabilityBehaviors Capability = "behaviors"
+
+	// CapabilityArchetypes loads enemy archetypes from enemies.json.
+	CapabilityArchetypes Capability = "archetypes"
+
+	// CapabilityLootTables loads loot tables from loot.json.
+	CapabilityLootTables Capability = "loot_tables"
+
+	// CapabilitySignatures checks mod signatures against registry keys.
+	CapabilitySignatures Capability = "signatures"
+
+	// CapabilityHotReload reloads mod content when its files change.
+	CapabilityHotReload Capability = "hot_reload"
+)
+
+// Capabilities returns the capabilities the game has on this platform,
+// sorted by name.
+func Capabilities() []Capability {
+	caps := []Capability{
+		CapabilityScripts, CapabilityAssets, CapabilityParticles, CapabilityBehaviors,
+		CapabilityArchetypes, CapabilityLootTables, CapabilitySignatures,
+	}
+	if watchSupported {
+		caps = append(caps, CapabilityHotReload)
+	}
+	sort.Slice(caps, func(i, j int) bool { return caps[i] < caps[j] })
+	return caps
+}
+
+// HasCapability reports whether the game has a capability on this
+// platform.
+func HasCapability(c Capability) bool {
+	for _, have := range Capabilities() {
+		if have == c {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetAPIVersion returns the mod API version a manifest is written for:
+// the version its api_version constraint names, or LegacyAPIVersion.
+func (m *Manifest) TargetAPIVersion() string {
+	if m.APIVersion == "" {
+		return LegacyAPIVersion
+	}
+	return strings.TrimSpace(strings.TrimLeft(m.APIVersion, "^~>=<!"))
+}
+
+// apiMajor returns the major mod API version a manifest is written for.
+func apiMajor(m *Manifest) int {
+	return getMajor(m.TargetAPIVersion())
+}
+
+// IsDeprecatedAPI reports whether a manifest is written for the previous
+// major mod API version, which only loads through deprecation shims.
+func (m *Manifest) IsDeprecatedAPI() bool {
+	return apiMajor(m) == getMajor(APIVersion)-1
+}
+
+// CheckCompatibility returns why a mod can't load in this game: an API
+// version it doesn't provide, a game version outside the mod's range, or
+// a missing capability. An empty gameVersion skips the game version
+// check.
+func CheckCompatibility(m *Manifest, gameVersion string) error {
+	major := apiMajor(m)
+	switch current := getMajor(APIVersion); {
+	case major == current && m.APIVersion != "" && !satisfies(APIVersion, m.APIVersion):
+		return fmt.Errorf("%w: needs mod API %s, the game has %s", ErrIncompatibleAPI, m.APIVersion, APIVersion)
+	case major > current:
+		return fmt.Errorf("%w: written for mod API %s, the game has %s", ErrIncompatibleAPI, m.TargetAPIVersion(), APIVersion)
+	case major < current-1:
+		return fmt.Errorf("%w: mod API %s is no longer supported, the game has %s", ErrIncompatibleAPI, m.TargetAPIVersion(), APIVersion)
+	}
+
+	if gameVersion != "" {
+		if m.MinGameVersion != "" && compareVersions(gameVersion, m.MinGameVersion) < 0 {
+			return fmt.Errorf("%w: needs game %s or later, this is %s", ErrIncompatibleGame, m.MinGameVersion, gameVersion)
+		}
+		if m.MaxGameVersion != "" && compareVersions(gameVersion, m.MaxGameVersion) > 0 {
+			return fmt.Errorf("%w: supports game up to %s, this is %s", ErrIncompatibleGame, m.MaxGameVersion, gameVersion)
+		}
+	}
+
+	for _, c := range m.Capabilities {
+		if !HasCapability(Capability(c)) {
+			return fmt.Errorf("%w: needs %s", ErrMissingCapability, c)
+		}
+	}
+	return nil
+}
+
+// eventShim keeps a script event parameter under the name it had before
+// a major API version renamed it.
+type eventShim struct {
+	major    int // API version that renamed the parameter
+	event    string
+	old, new string
+}
+
+// eventShims are the script event parameters renamed by each major API
+// version. Scripts written for the major version before one see the old
+// names as well; shims are removed a major version after they are added.
+var eventShims = []eventShim{
+	// API 2 named the pickup count as the item.pickup hook does
+	{major: 2, event: EventTypeItemPickup, old: "qty", new: "quantity"},
+}
+
+// shimEventParams returns an event's parameters as a script written for
+// an API major version expects them. The params are copied only when a
+// shim applies.
+func shimEventParams(major int, eventType string, params map[string]interface{}) map[string]interface{} {
+	shimmed := params
+	copied := false
+	for _, shim := range eventShims {
+		if shim.event != eventType || shim.major <= major {
+			continue
+		}
+		v, ok := params[shim.new]
+		if !ok {
+			continue
+		}
+		if !copied {
+			shimmed = make(map[string]interface{}, len(params)+1)
+			for k, v := range params {
+				shimmed[k] = v
+			}
+			copied = true
+		}
+		shimmed[shim.old] = v
+	}
+	return shimmed
+}
@@ -0,0 +1,91 @@
+package mod
+
+import (
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest Manifest
+		game     string
+		want     error
+	}{
+		{"legacy", Manifest{}, "6.1.0", nil},
+		{"current", Manifest{APIVersion: "^2.0.0"}, "6.1.0", nil},
+		{"exact", Manifest{APIVersion: "2.0.0"}, "6.1.0", nil},
+		{"newer minor", Manifest{APIVersion: "^2.1.0"}, "6.1.0", ErrIncompatibleAPI},
+		{"next major", Manifest{APIVersion: "^3.0.0"}, "6.1.0", ErrIncompatibleAPI},
+		{"dropped major", Manifest{APIVersion: "^0.9.0"}, "6.1.0", ErrIncompatibleAPI},
+		{"in game range", Manifest{MinGameVersion: "6.0.0", MaxGameVersion: "6.9.0"}, "6.1.0", nil},
+		{"game too old", Manifest{MinGameVersion: "6.2.0"}, "6.1.0", ErrIncompatibleGame},
+		{"game too new", Manifest{MaxGameVersion: "5.0.0"}, "6.1.0", ErrIncompatibleGame},
+		{"no game version", Manifest{MaxGameVersion: "5.0.0"}, "", nil},
+		{"capability", Manifest{Capabilities: []string{"scripts", "particles"}}, "6.1.0", nil},
+		{"unknown capability", Manifest{Capabilities: []string{"wasm"}}, "6.1.0", ErrMissingCapability},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCompatibility(&tt.manifest, tt.game)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CheckCompatibility = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	caps := Capabilities()
+	if !sort.SliceIsSorted(caps, func(i, j int) bool { return caps[i] < caps[j] }) {
+		t.Errorf("capabilities not sorted: %v", caps)
+	}
+	if !HasCapability(CapabilityScripts) || HasCapability("wasm") {
+		t.Errorf("capabilities = %v", caps)
+	}
+	if HasCapability(CapabilityHotReload) != watchSupported {
+		t.Errorf("hot_reload reported as %v", HasCapability(CapabilityHotReload))
+	}
+}
+
+func TestManifest_TargetAPIVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       string
+		deprecated bool
+	}{
+		{"", LegacyAPIVersion, true},
+		{"^2.0.0", "2.0.0", false},
+		{">= 2.1.0", "2.1.0", false},
+		{"~1.2.0", "1.2.0", true},
+	}
+	for _, tt := range tests {
+		m := Manifest{APIVersion: tt.constraint}
+		if got := m.TargetAPIVersion(); got != tt.want {
+			t.Errorf("TargetAPIVersion(%q) = %q, want %q", tt.constraint, got, tt.want)
+		}
+		if got := m.IsDeprecatedAPI(); got != tt.deprecated {
+			t.Errorf("IsDeprecatedAPI(%q) = %v", tt.constraint, got)
+		}
+	}
+}
+
+func TestShimEventParams(t *testing.T) {
+	params := map[string]interface{}{"item": "medkit", "quantity": 2}
+
+	legacy := shimEventParams(1, EventTypeItemPickup, params)
+	if legacy["qty"] != 2 || legacy["quantity"] != 2 {
+		t.Errorf("API 1 params = %v", legacy)
+	}
+	if _, ok := params["qty"]; ok {
+		t.Error("shim changed the original params")
+	}
+
+	if current := shimEventParams(2, EventTypeItemPickup, params); len(current) != 2 {
+		t.Errorf("API 2 params = %v", current)
+	}
+	if other := shimEventParams(1, EventTypeEnemyKilled, params); len(other) != 2 {
+		t.Errorf("params of an unshimmed event = %v", other)
+	}
+}
@@ -50,9 +50,24 @@ type Manifest struct {
 	// MaxGameVersion is the maximum compatible game version (semver)
 	MaxGameVersion string `json:"max_game_version,omitempty"`
 
+	// APIVersion is the mod API version the mod is written for, as a
+	// constraint such as "^2.0.0". Mods without one are written for API 1
+	APIVersion string `json:"api_version,omitempty"`
+
+	// Capabilities lists the optional parts of the mod API the mod needs,
+	// such as "scripts"; it doesn't load in games without them
+	Capabilities []string `json:"capabilities,omitempty"`
+
 	// EntryPoint is the path to the mod's WASM binary (relative to mod directory)
 	EntryPoint string `json:"entry_point,omitempty"`
 
+	// Script is the path to the mod's Lua script (relative to mod directory)
+	Script string `json:"script,omitempty"`
+
+	// Assets is the mod's asset directory (relative to mod directory),
+	// holding textures/, sounds/ and sprites/ that replace generated assets
+	Assets string `json:"assets,omitempty"`
+
 	// Permissions specify what capabilities the mod requires
 	Permissions PermissionSet `json:"permissions,omitempty"`
 
@@ -119,6 +134,9 @@ func (m *Manifest) Validate() error {
 	if err := m.validateTags(); err != nil {
 		return err
 	}
+	if err := m.validateCapabilities(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -150,6 +168,9 @@ func (m *Manifest) validateVersionFields() error {
 	if m.MaxGameVersion != "" && !IsValidSemver(m.MaxGameVersion) {
 		return fmt.Errorf("max_game_version must be valid semver (got %q)", m.MaxGameVersion)
 	}
+	if m.APIVersion != "" && !hasValidSemverCore(m.APIVersion) {
+		return fmt.Errorf("api_version must contain valid semver (got %q)", m.APIVersion)
+	}
 	return nil
 }
 
@@ -193,6 +214,22 @@ func (m *Manifest) validateTags() error {
 	return nil
 }
 
+// validateCapabilities validates capability count and names.
+func (m *Manifest) validateCapabilities() error {
+	if len(m.Capabilities) > 16 {
+		return fmt.Errorf("too many capabilities (max 16)")
+	}
+	for i, c := range m.Capabilities {
+		if c == "" {
+			return fmt.Errorf("capability %d is empty", i)
+		}
+		if len(c) > 32 {
+			return fmt.Errorf("capability %d too long (max 32 characters)", i)
+		}
+	}
+	return nil
+}
+
 // Validate checks that a dependency has valid fields.
 func (d *Dependency) Validate() error {
 	if d.Name == "" {
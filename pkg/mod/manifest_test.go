@@ -39,6 +39,8 @@ func TestManifest_Validate(t *testing.T) {
 				Conflicts:      []string{"old-mod"},
 				MinGameVersion: "1.0.0",
 				MaxGameVersion: "2.0.0",
+				APIVersion:     "^2.0.0",
+				Capabilities:   []string{"scripts"},
 				EntryPoint:     "mod.wasm",
 				Permissions: PermissionSet{
 					FileRead:  true,
@@ -182,6 +184,26 @@ func TestManifest_Validate(t *testing.T) {
 			},
 			wantError: true,
 		},
+		{
+			name: "invalid api version",
+			manifest: Manifest{
+				Name:       "test-mod",
+				Version:    "1.0.0",
+				Author:     "Test",
+				APIVersion: "^two",
+			},
+			wantError: true,
+		},
+		{
+			name: "empty capability",
+			manifest: Manifest{
+				Name:         "test-mod",
+				Version:      "1.0.0",
+				Author:       "Test",
+				Capabilities: []string{"scripts", ""},
+			},
+			wantError: true,
+		},
 		{
 			name: "too many tags",
 			manifest: Manifest{
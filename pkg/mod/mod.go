@@ -24,6 +24,16 @@ type Mod struct {
 	// Enabled indicates if the mod is active
 	Enabled bool `json:"-"`
 
+	// ResolveError is why an enabled mod can't load: a missing or
+	// incompatible dependency, a conflict, a dependency cycle or a block
+	ResolveError error `json:"-"`
+
+	// Trust is how far the mod's signature can be trusted, and TrustError
+	// why it isn't signed or is blocked
+	Trust      TrustLevel        `json:"-"`
+	TrustError error             `json:"-"`
+	Signature  *PackageSignature `json:"-"`
+
 	// Legacy fields for backward compatibility (deprecated)
 	Name        string            `json:"name,omitempty"`
 	Version     string            `json:"version,omitempty"`
@@ -35,12 +45,16 @@ type Mod struct {
 // Loader manages loading and listing of mods.
 type Loader struct {
 	mods          []Mod
+	loadOrder     []string // Names of the mods that can load, dependencies first
 	modsDir       string
 	mu            sync.RWMutex
 	conflicts     map[string][]string // mod name -> conflicting mods
 	warnings      []string
 	pluginManager *PluginManager
 	wasmLoader    *WASMLoader
+	scripts       *ScriptRuntime
+	keyRing       *KeyRing
+	gameVersion   string // Checked against mods' game version ranges, if set
 
 	// EnableUnsafePlugins allows loading Go plugins (DEPRECATED).
 	// This is unsafe for untrusted mods. Use WASM mods instead.
@@ -56,6 +70,7 @@ func NewLoader() *Loader {
 		warnings:            make([]string, 0),
 		pluginManager:       NewPluginManager(),
 		wasmLoader:          NewWASMLoader(),
+		scripts:             NewScriptRuntime(DefaultScriptLimits()),
 		EnableUnsafePlugins: false,
 	}
 }
@@ -69,12 +84,17 @@ func NewLoaderWithDir(dir string) *Loader {
 		warnings:            make([]string, 0),
 		pluginManager:       NewPluginManager(),
 		wasmLoader:          NewWASMLoader(),
+		scripts:             NewScriptRuntime(DefaultScriptLimits()),
 		EnableUnsafePlugins: false,
 	}
 }
 
 // LoadMod loads a mod from the given path.
 // The path should point to a directory containing a mod.json manifest file.
+// Dependencies and conflicts declared in the manifest don't stop the mod
+// loading; they are checked against the other enabled mods each time the
+// set changes, and a mod that can't load is left out of ActiveMods with
+// its ResolveError set.
 func (l *Loader) LoadMod(path string) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -92,11 +112,14 @@ func (l *Loader) LoadMod(path string) error {
 		return err
 	}
 
-	if err := l.checkModConflicts(&mod); err != nil {
+	if err := l.checkLegacyConflicts(getModName(&mod)); err != nil {
 		return err
 	}
 
+	l.verify(&mod)
+	l.warnDeprecatedAPI(&mod)
 	l.mods = append(l.mods, mod)
+	l.resolve()
 	return nil
 }
 
@@ -157,21 +180,6 @@ func validateModFields(mod *Mod) error {
 	return nil
 }
 
-// checkModConflicts verifies that the mod does not conflict with any enabled mods.
-func (l *Loader) checkModConflicts(mod *Mod) error {
-	modName := getModName(mod)
-
-	if err := l.checkLegacyConflicts(modName); err != nil {
-		return err
-	}
-
-	if err := l.checkManifestConflicts(mod, modName); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // getModName retrieves the effective name of a mod.
 func getModName(mod *Mod) string {
 	if mod.Manifest != nil {
@@ -180,6 +188,14 @@ func getModName(mod *Mod) string {
 	return mod.Name
 }
 
+// getModAuthor extracts the author from a mod (manifest or legacy).
+func getModAuthor(mod *Mod) string {
+	if mod.Manifest != nil {
+		return mod.Manifest.Author
+	}
+	return mod.Author
+}
+
 // checkLegacyConflicts verifies against the legacy conflicts registry.
 func (l *Loader) checkLegacyConflicts(modName string) error {
 	conflicts, ok := l.conflicts[modName]
@@ -188,21 +204,7 @@ func (l *Loader) checkLegacyConflicts(modName string) error {
 	}
 
 	for _, conflict := range conflicts {
-		if err := l.checkConflictWithEnabled(modName, conflict, ""); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-// checkManifestConflicts verifies conflicts declared in the mod manifest.
-func (l *Loader) checkManifestConflicts(mod *Mod, modName string) error {
-	if mod.Manifest == nil {
-		return nil
-	}
-
-	for _, conflict := range mod.Manifest.Conflicts {
-		if err := l.checkConflictWithEnabled(modName, conflict, " (declared in manifest)"); err != nil {
+		if err := l.checkConflictWithEnabled(modName, conflict); err != nil {
 			return err
 		}
 	}
@@ -210,14 +212,14 @@ func (l *Loader) checkManifestConflicts(mod *Mod, modName string) error {
 }
 
 // checkConflictWithEnabled checks if a conflicting mod is enabled.
-func (l *Loader) checkConflictWithEnabled(modName, conflict, suffix string) error {
+func (l *Loader) checkConflictWithEnabled(modName, conflict string) error {
 	for _, existing := range l.mods {
 		if !existing.Enabled {
 			continue
 		}
 		existingName := getModName(&existing)
 		if existingName == conflict {
-			return fmt.Errorf("mod %s conflicts with %s%s", modName, conflict, suffix)
+			return fmt.Errorf("mod %s conflicts with %s", modName, conflict)
 		}
 	}
 	return nil
@@ -231,12 +233,51 @@ func (l *Loader) UnloadMod(name string) error {
 	for i, mod := range l.mods {
 		if mod.Name == name {
 			l.mods = append(l.mods[:i], l.mods[i+1:]...)
+			l.scripts.Unload(name)
+			l.resolve()
 			return nil
 		}
 	}
 	return fmt.Errorf("mod not found: %s", name)
 }
 
+// ReloadMod re-reads a loaded mod's manifest and signature from its
+// directory after its files changed. The mod keeps its place in load
+// order and whether it is enabled.
+func (l *Loader) ReloadMod(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range l.mods {
+		if l.mods[i].Name != name {
+			continue
+		}
+		mod, err := l.readAndParseManifest(l.mods[i].Path)
+		if err != nil {
+			return err
+		}
+		if err := validateModFields(&mod); err != nil {
+			return err
+		}
+		if mod.Name != name {
+			return fmt.Errorf("mod %s was renamed to %s", name, mod.Name)
+		}
+		mod.Enabled = l.mods[i].Enabled
+		l.verify(&mod)
+		l.warnDeprecatedAPI(&mod)
+		l.mods[i] = mod
+		l.resolve()
+		return nil
+	}
+	return fmt.Errorf("mod not found: %s", name)
+}
+
+// ReloadEvent is the data of HookTypeModReload: the mods whose changed
+// files caused a reload of mod content.
+type ReloadEvent struct {
+	Mods []string
+}
+
 // ListMods returns all loaded mods.
 func (l *Loader) ListMods() []Mod {
 	l.mu.RLock()
@@ -247,6 +288,120 @@ func (l *Loader) ListMods() []Mod {
 	return result
 }
 
+// ActiveMods returns the enabled mods that can load, in load order: each
+// after the mods it depends on.
+func (l *Loader) ActiveMods() []Mod {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	result := make([]Mod, 0, len(l.loadOrder))
+	for _, name := range l.loadOrder {
+		for i := range l.mods {
+			if getModName(&l.mods[i]) == name {
+				result = append(result, l.mods[i])
+				break
+			}
+		}
+	}
+	return result
+}
+
+// resolve works out the load order of the enabled mods and records why
+// the others can't load (must be called with lock held). It runs whenever
+// the set of loaded or enabled mods changes.
+func (l *Loader) resolve() {
+	manifests := make([]*Manifest, 0, len(l.mods))
+	blocked := make(map[string]error)
+	for i := range l.mods {
+		if !l.mods[i].Enabled {
+			continue
+		}
+		manifest := modManifest(&l.mods[i])
+		manifests = append(manifests, manifest)
+		if err := CheckCompatibility(manifest, l.gameVersion); err != nil {
+			blocked[manifest.Name] = err
+		} else if l.mods[i].Trust == TrustBlocked {
+			blocked[manifest.Name] = l.mods[i].TrustError
+		}
+	}
+	order, failed := resolveLoadOrder(manifests, blocked)
+
+	l.loadOrder = make([]string, len(order))
+	for i, m := range order {
+		l.loadOrder[i] = m.Name
+	}
+	for i := range l.mods {
+		l.mods[i].ResolveError = failed[getModName(&l.mods[i])]
+	}
+}
+
+// SetGameVersion sets the game version mods' min_game_version and
+// max_game_version are checked against. Until it is set, they aren't.
+func (l *Loader) SetGameVersion(version string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.gameVersion = version
+	l.resolve()
+}
+
+// Capabilities returns the parts of the mod API mods can rely on in this
+// game, for mods to name in their manifest's capabilities.
+func (l *Loader) Capabilities() []Capability {
+	return Capabilities()
+}
+
+// warnDeprecatedAPI logs a warning for a mod written for the previous
+// major mod API version.
+func (l *Loader) warnDeprecatedAPI(m *Mod) {
+	if m.Manifest == nil || !m.Manifest.IsDeprecatedAPI() {
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"system_name": "mod_loader",
+		"mod":         m.Manifest.Name,
+		"api_version": m.Manifest.TargetAPIVersion(),
+	}).Warnf("Mod is written for a deprecated mod API; the game has %s", APIVersion)
+}
+
+// SetKeyRing sets the author keys and blocks mod signatures are checked
+// against, such as those fetched from a mod registry, and checks every
+// loaded mod again. A nil key ring trusts no keys.
+func (l *Loader) SetKeyRing(ring *KeyRing) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.keyRing = ring
+	for i := range l.mods {
+		l.verify(&l.mods[i])
+	}
+	l.resolve()
+}
+
+// verify checks a mod's signature against the key ring (must be called
+// with lock held). A mod whose files can't be read is blocked.
+func (l *Loader) verify(m *Mod) {
+	sig, err := ReadSignature(m.Path)
+	if err != nil {
+		m.Trust, m.TrustError, m.Signature = TrustBlocked, fmt.Errorf("%w: %v", ErrModBlocked, err), nil
+		return
+	}
+	digest, err := DirDigest(m.Path)
+	if err != nil {
+		m.Trust, m.TrustError, m.Signature = TrustBlocked, fmt.Errorf("%w: %v", ErrModBlocked, err), sig
+		return
+	}
+	m.Signature = sig
+	m.Trust, m.TrustError = l.keyRing.Verify(getModName(m), getModAuthor(m), digest, sig)
+}
+
+// modManifest returns a mod's manifest, or one made from its legacy
+// fields.
+func modManifest(m *Mod) *Manifest {
+	if m.Manifest != nil {
+		return m.Manifest
+	}
+	return &Manifest{Name: m.Name, Version: m.Version}
+}
+
 // GetMod returns a mod by name.
 func (l *Loader) GetMod(name string) (*Mod, error) {
 	l.mu.RLock()
@@ -268,6 +423,7 @@ func (l *Loader) EnableMod(name string) error {
 	for i := range l.mods {
 		if l.mods[i].Name == name {
 			l.mods[i].Enabled = true
+			l.resolve()
 			return nil
 		}
 	}
@@ -282,6 +438,7 @@ func (l *Loader) DisableMod(name string) error {
 	for i := range l.mods {
 		if l.mods[i].Name == name {
 			l.mods[i].Enabled = false
+			l.resolve()
 			return nil
 		}
 	}
@@ -329,6 +486,13 @@ func (l *Loader) WASMLoader() *WASMLoader {
 	return l.wasmLoader
 }
 
+// Scripts returns the Lua script runtime for this loader's mods.
+func (l *Loader) Scripts() *ScriptRuntime {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.scripts
+}
+
 // RegisterPlugin registers a plugin with the loader's plugin manager.
 // The plugin is loaded immediately and its lifecycle managed by the loader.
 // Requires EnableUnsafePlugins flag to be set.
@@ -1,6 +1,7 @@
 package mod
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -200,6 +201,50 @@ func TestLoader_UnloadModNotFound(t *testing.T) {
 	}
 }
 
+func TestLoader_ReloadMod(t *testing.T) {
+	modDir := filepath.Join(t.TempDir(), "testmod")
+	if err := os.Mkdir(modDir, 0o755); err != nil {
+		t.Fatalf("failed to create mod dir: %v", err)
+	}
+	writeManifest := func(modJSON string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(modDir, "mod.json"), []byte(modJSON), 0o644); err != nil {
+			t.Fatalf("failed to write mod.json: %v", err)
+		}
+	}
+	writeManifest(`{"name": "test-mod", "version": "1.0.0", "author": "Test"}`)
+
+	loader := NewLoader()
+	if err := loader.LoadMod(modDir); err != nil {
+		t.Fatalf("LoadMod failed: %v", err)
+	}
+	loader.DisableMod("test-mod")
+
+	writeManifest(`{"name": "test-mod", "version": "1.1.0", "author": "Test"}`)
+	if err := loader.ReloadMod("test-mod"); err != nil {
+		t.Fatalf("ReloadMod failed: %v", err)
+	}
+	m, _ := loader.GetMod("test-mod")
+	if m.Version != "1.1.0" || m.Enabled {
+		t.Errorf("reloaded mod: version %s, enabled %v", m.Version, m.Enabled)
+	}
+
+	writeManifest(`{"name": "renamed", "version": "1.1.0", "author": "Test"}`)
+	if err := loader.ReloadMod("test-mod"); err == nil {
+		t.Error("ReloadMod accepted a renamed mod")
+	}
+	writeManifest(`{`)
+	if err := loader.ReloadMod("test-mod"); err == nil {
+		t.Error("ReloadMod accepted an invalid manifest")
+	}
+	if m, _ := loader.GetMod("test-mod"); m.Version != "1.1.0" {
+		t.Errorf("failed reload changed the mod to version %s", m.Version)
+	}
+	if err := loader.ReloadMod("missing"); err == nil {
+		t.Error("ReloadMod of a mod that isn't loaded succeeded")
+	}
+}
+
 func TestLoader_GetMod(t *testing.T) {
 	tmpDir := t.TempDir()
 	modDir := filepath.Join(tmpDir, "testmod")
@@ -327,6 +372,96 @@ func TestLoader_AddConflict(t *testing.T) {
 	}
 }
 
+func TestLoader_ResolvesDependencies(t *testing.T) {
+	tmpDir := t.TempDir()
+	for dir, manifest := range map[string]string{
+		"a-addon": `{"name": "addon", "version": "1.0.0", "author": "Test",
+			"dependencies": [{"name": "base", "version": "^1.0.0"}]}`,
+		"b-base":  `{"name": "base", "version": "1.4.0", "author": "Test"}`,
+		"c-rival": `{"name": "rival", "version": "1.0.0", "author": "Test", "conflicts": ["base"]}`,
+	} {
+		if err := os.Mkdir(filepath.Join(tmpDir, dir), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dir, "mod.json"), []byte(manifest), 0o644); err != nil {
+			t.Fatalf("failed to write %s/mod.json: %v", dir, err)
+		}
+	}
+
+	loader := NewLoaderWithDir(tmpDir)
+	if n, err := loader.LoadAllMods(); err != nil || n != 3 {
+		t.Fatalf("LoadAllMods = %d, %v; want 3 loaded", n, err)
+	}
+
+	// The conflict holds back rival and base and, through base, addon
+	if active := loader.ActiveMods(); len(active) != 0 {
+		t.Errorf("active = %v, want none", active)
+	}
+	rival, _ := loader.GetMod("rival")
+	addon, _ := loader.GetMod("addon")
+	if !errors.Is(rival.ResolveError, ErrModConflict) || !errors.Is(addon.ResolveError, ErrMissingDependency) {
+		t.Errorf("rival: %v; addon: %v", rival.ResolveError, addon.ResolveError)
+	}
+
+	if err := loader.DisableMod("rival"); err != nil {
+		t.Fatal(err)
+	}
+	active := loader.ActiveMods()
+	if len(active) != 2 || active[0].Name != "base" || active[1].Name != "addon" {
+		t.Errorf("active = %v, want base then addon", active)
+	}
+	for _, m := range loader.ListMods() {
+		if m.ResolveError != nil {
+			t.Errorf("%s: ResolveError = %v", m.Name, m.ResolveError)
+		}
+	}
+}
+
+func TestLoader_ChecksCompatibility(t *testing.T) {
+	tmpDir := t.TempDir()
+	for dir, manifest := range map[string]string{
+		"a-legacy": `{"name": "legacy", "version": "1.0.0", "author": "Test"}`,
+		"b-future": `{"name": "future", "version": "1.0.0", "author": "Test", "api_version": "^3.0.0"}`,
+		"c-old":    `{"name": "old", "version": "1.0.0", "author": "Test", "api_version": "^2.0.0", "max_game_version": "5.0.0"}`,
+		"d-addon": `{"name": "addon", "version": "1.0.0", "author": "Test", "api_version": "^2.0.0",
+			"dependencies": [{"name": "future", "version": "^1.0.0"}]}`,
+	} {
+		if err := os.Mkdir(filepath.Join(tmpDir, dir), 0o755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dir, "mod.json"), []byte(manifest), 0o644); err != nil {
+			t.Fatalf("failed to write %s/mod.json: %v", dir, err)
+		}
+	}
+
+	loader := NewLoaderWithDir(tmpDir)
+	if n, err := loader.LoadAllMods(); err != nil || n != 4 {
+		t.Fatalf("LoadAllMods = %d, %v; want 4 loaded", n, err)
+	}
+	if active := loader.ActiveMods(); len(active) != 2 {
+		t.Errorf("active = %v, want legacy and old", active)
+	}
+
+	loader.SetGameVersion("6.1.0")
+	future, _ := loader.GetMod("future")
+	old, _ := loader.GetMod("old")
+	addon, _ := loader.GetMod("addon")
+	if !errors.Is(future.ResolveError, ErrIncompatibleAPI) || !errors.Is(old.ResolveError, ErrIncompatibleGame) {
+		t.Errorf("future: %v; old: %v", future.ResolveError, old.ResolveError)
+	}
+	if !errors.Is(addon.ResolveError, ErrMissingDependency) {
+		t.Errorf("addon: %v", addon.ResolveError)
+	}
+	if active := loader.ActiveMods(); len(active) != 1 || active[0].Name != "legacy" {
+		t.Errorf("active = %v, want legacy", active)
+	}
+
+	legacy, _ := loader.GetMod("legacy")
+	if !legacy.Manifest.IsDeprecatedAPI() || addon.Manifest.IsDeprecatedAPI() {
+		t.Error("only the mod without an api_version should use the deprecated API")
+	}
+}
+
 func TestLoader_SetGetModsDir(t *testing.T) {
 	loader := NewLoader()
 	loader.SetModsDir("/custom/mods")
@@ -85,6 +85,10 @@ const (
 
 	// HookTypeGenreSet is called when genre is changed.
 	HookTypeGenreSet HookType = "genre.set"
+
+	// HookTypeModReload is called after mod content is reloaded because a
+	// mod's files changed. The data is a ReloadEvent.
+	HookTypeModReload HookType = "mod.reload"
 )
 
 // HookFunc is a callback function for game events.
@@ -0,0 +1,324 @@
+package registry
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxAuthorLength matches the longest author a manifest may name.
+const maxAuthorLength = 128
+
+var (
+	// ErrInvalidAuthor is returned for an author name an account can't
+	// have.
+	ErrInvalidAuthor = errors.New("invalid author name")
+
+	// ErrAccountExists is returned when creating an account for a taken
+	// author name.
+	ErrAccountExists = errors.New("account already exists")
+
+	// ErrAuthorReserved is returned when creating an account for an author
+	// who published mods or keys before the registry had accounts. Only an
+	// admin can issue a token for one.
+	ErrAuthorReserved = errors.New("author name reserved")
+
+	// ErrAccountNotFound is returned for an author without an account.
+	ErrAccountNotFound = errors.New("account not found")
+
+	// ErrInvalidToken is returned for a missing or unknown account token.
+	ErrInvalidToken = errors.New("invalid account token")
+
+	// ErrAccountSuspended is returned when a suspended account tries to
+	// publish.
+	ErrAccountSuspended = errors.New("account suspended")
+)
+
+// AccountRequest creates an author account.
+type AccountRequest struct {
+	Author string `json:"author"`
+}
+
+// AccountResponse returns a new account's token. The registry keeps only
+// its hash, so it is shown once.
+type AccountResponse struct {
+	Author string `json:"author"`
+	Token  string `json:"token"`
+}
+
+// RatingRequest rates a mod from 1 to 5 stars.
+type RatingRequest struct {
+	Stars int `json:"stars"`
+}
+
+// initAccountSchema creates the account and rating tables.
+func (r *Registry) initAccountSchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS accounts (
+		author TEXT PRIMARY KEY,
+		token_hash TEXT NOT NULL UNIQUE,
+		suspended INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS ratings (
+		name TEXT NOT NULL,
+		rater TEXT NOT NULL,
+		stars INTEGER NOT NULL,
+		rated_at DATETIME NOT NULL,
+		PRIMARY KEY (name, rater)
+	);
+	`
+	_, err := r.db.Exec(schema)
+	return err
+}
+
+// CreateAccount creates an account for an author and returns its token.
+// Authors whose mods or keys the registry already holds are reserved, so
+// nobody can take over their mods by claiming the name first.
+func (r *Registry) CreateAccount(author string) (string, error) {
+	if err := validateAuthor(author); err != nil {
+		return "", err
+	}
+	reserved, err := r.authorReserved(author)
+	if err != nil {
+		return "", err
+	}
+	if reserved {
+		return "", ErrAuthorReserved
+	}
+	return r.insertAccount(author)
+}
+
+// IssueToken returns a new token for an author's account, creating the
+// account if it has none. It is how an admin hands a reserved author name
+// to the author who owns it, once they have proved who they are.
+func (r *Registry) IssueToken(author string) (string, error) {
+	if err := validateAuthor(author); err != nil {
+		return "", err
+	}
+	token, err := r.ResetToken(author)
+	if errors.Is(err, ErrAccountNotFound) {
+		return r.insertAccount(author)
+	}
+	return token, err
+}
+
+// validateAuthor checks that an author name can have an account.
+func validateAuthor(author string) error {
+	if author == "" || len(author) > maxAuthorLength || strings.TrimSpace(author) != author {
+		return fmt.Errorf("%w %q", ErrInvalidAuthor, author)
+	}
+	return nil
+}
+
+// authorReserved reports whether an author without an account published
+// mods or keys, which happens for everything uploaded before accounts.
+func (r *Registry) authorReserved(author string) (bool, error) {
+	var reserved bool
+	err := r.db.QueryRow(`SELECT NOT EXISTS (SELECT 1 FROM accounts WHERE author = ?)
+		AND (EXISTS (SELECT 1 FROM mods WHERE author = ?) OR EXISTS (SELECT 1 FROM author_keys WHERE author = ?))`,
+		author, author, author).Scan(&reserved)
+	return reserved, err
+}
+
+// insertAccount creates an account and returns its token.
+func (r *Registry) insertAccount(author string) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	result, err := r.db.Exec("INSERT OR IGNORE INTO accounts (author, token_hash, created_at) VALUES (?, ?, ?)",
+		author, hashToken(token), time.Now())
+	if err != nil {
+		return "", err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return "", ErrAccountExists
+	}
+	return token, nil
+}
+
+// ResetToken replaces an account's token, for authors who lost theirs,
+// and returns the new one.
+func (r *Registry) ResetToken(author string) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	result, err := r.db.Exec("UPDATE accounts SET token_hash = ? WHERE author = ?", hashToken(token), author)
+	if err != nil {
+		return "", err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return "", ErrAccountNotFound
+	}
+	return token, nil
+}
+
+// SetSuspended suspends or reinstates an account. Suspended authors can't
+// upload, publish keys or rate mods.
+func (r *Registry) SetSuspended(author string, suspended bool) error {
+	result, err := r.db.Exec("UPDATE accounts SET suspended = ? WHERE author = ?", suspended, author)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrAccountNotFound
+	}
+	return nil
+}
+
+// Authenticate returns the author whose token the request carries as
+// "Authorization: Bearer <token>".
+func (r *Registry) Authenticate(req *http.Request) (string, error) {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", ErrInvalidToken
+	}
+
+	var author string
+	var suspended bool
+	err := r.db.QueryRow("SELECT author, suspended FROM accounts WHERE token_hash = ?", hashToken(token)).Scan(&author, &suspended)
+	if err == sql.ErrNoRows {
+		return "", ErrInvalidToken
+	} else if err != nil {
+		return "", err
+	}
+	if suspended {
+		return author, ErrAccountSuspended
+	}
+	return author, nil
+}
+
+// authenticate authenticates a request, answering 401 or 403 if it can't.
+func (r *Registry) authenticate(w http.ResponseWriter, req *http.Request) (string, bool) {
+	author, err := r.Authenticate(req)
+	switch {
+	case err == nil:
+		return author, true
+	case errors.Is(err, ErrInvalidToken):
+		http.Error(w, "Account token required", http.StatusUnauthorized)
+	case errors.Is(err, ErrAccountSuspended):
+		http.Error(w, "Account suspended", http.StatusForbidden)
+	default:
+		logrus.WithError(err).Error("Account lookup failed")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+	}
+	return "", false
+}
+
+// HandleAccounts creates an author account on POST. An author name can
+// only be claimed once, and names that published before accounts existed
+// can only be claimed through an admin.
+func (r *Registry) HandleAccounts(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var ar AccountRequest
+	if err := json.NewDecoder(req.Body).Decode(&ar); err != nil {
+		http.Error(w, "Invalid account request", http.StatusBadRequest)
+		return
+	}
+	token, err := r.CreateAccount(ar.Author)
+	if errors.Is(err, ErrAccountExists) {
+		http.Error(w, "Author name is taken", http.StatusConflict)
+		return
+	} else if errors.Is(err, ErrAuthorReserved) {
+		http.Error(w, "Author name has published mods; ask a registry admin for its token", http.StatusConflict)
+		return
+	} else if errors.Is(err, ErrInvalidAuthor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("Failed to create account")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "mod_registry",
+		"author":      ar.Author,
+	}).Info("Account created")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(AccountResponse{Author: ar.Author, Token: token})
+}
+
+// HandleRate records the requesting account's rating of a mod at
+// /rate/{name}, replacing any earlier one. Authors can't rate their own
+// mods.
+func (r *Registry) HandleRate(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	rater, ok := r.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	name := strings.TrimPrefix(req.URL.Path, "/rate/")
+	var rr RatingRequest
+	if err := json.NewDecoder(req.Body).Decode(&rr); err != nil || rr.Stars < 1 || rr.Stars > 5 {
+		http.Error(w, "Stars must be 1 to 5", http.StatusBadRequest)
+		return
+	}
+
+	owner, err := r.modOwner(name)
+	if err == sql.ErrNoRows {
+		http.Error(w, "Mod not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("Database query failed")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if owner == rater {
+		http.Error(w, "Authors can't rate their own mods", http.StatusForbidden)
+		return
+	}
+
+	_, err = r.db.Exec("INSERT OR REPLACE INTO ratings (name, rater, stars, rated_at) VALUES (?, ?, ?, ?)",
+		name, rater, rr.Stars, time.Now())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save rating")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// modOwner returns the author who first uploaded a mod, who alone may
+// upload new versions of it.
+func (r *Registry) modOwner(name string) (string, error) {
+	var author string
+	err := r.db.QueryRow("SELECT author FROM mods WHERE name = ? ORDER BY uploaded_at LIMIT 1", name).Scan(&author)
+	return author, err
+}
+
+// newToken returns a random account token.
+func newToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// hashToken returns the hash an account token is stored as.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,294 @@
+package registry
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rate posts a rating by rater and returns the response status.
+func rate(t *testing.T, reg *Registry, name, rater string, stars int) int {
+	t.Helper()
+	body, _ := json.Marshal(RatingRequest{Stars: stars})
+	req := httptest.NewRequest(http.MethodPost, "/rate/"+name, bytes.NewReader(body))
+	authorize(t, reg, req, rater)
+	w := httptest.NewRecorder()
+	reg.HandleRate(w, req)
+	return w.Code
+}
+
+func TestHandleAccounts(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"create", `{"author": "alice"}`, http.StatusCreated},
+		{"taken", `{"author": "alice"}`, http.StatusConflict},
+		{"empty author", `{"author": ""}`, http.StatusBadRequest},
+		{"padded author", `{"author": " bob"}`, http.StatusBadRequest},
+		{"invalid JSON", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			reg.HandleAccounts(w, req)
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+	w := httptest.NewRecorder()
+	reg.HandleAccounts(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d", w.Code)
+	}
+}
+
+func TestAccountsMigration(t *testing.T) {
+	// A registry from before accounts: mods and keys, but no account table
+	tmpDir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(tmpDir, "old.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+	CREATE TABLE mods (
+		name TEXT NOT NULL,
+		version TEXT NOT NULL,
+		author TEXT NOT NULL,
+		description TEXT,
+		tags TEXT,
+		sha256 TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		uploaded_at DATETIME NOT NULL,
+		downloads INTEGER DEFAULT 0,
+		PRIMARY KEY (name, version)
+	);
+	CREATE TABLE author_keys (
+		author TEXT NOT NULL,
+		key TEXT NOT NULL,
+		added_at DATETIME NOT NULL,
+		PRIMARY KEY (author, key)
+	);`)
+	if err != nil {
+		t.Fatalf("Failed to create old schema: %v", err)
+	}
+	db.Exec("INSERT INTO mods (name, version, author, sha256, size, uploaded_at) VALUES ('old-mod', '1.0.0', 'old-author', 'abc', 1, ?)", time.Now())
+	db.Exec("INSERT INTO author_keys (author, key, added_at) VALUES ('key-author', 'a2V5', ?)", time.Now())
+
+	reg, err := NewRegistry(db, filepath.Join(tmpDir, "storage"))
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	defer reg.Close()
+
+	// Nobody can claim the old authors' names and with them their mods
+	for _, author := range []string{"old-author", "key-author"} {
+		if _, err := reg.CreateAccount(author); !errors.Is(err, ErrAuthorReserved) {
+			t.Errorf("CreateAccount(%s) err = %v, want %v", author, err, ErrAuthorReserved)
+		}
+	}
+	req := httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{"author": "old-author"}`))
+	w := httptest.NewRecorder()
+	reg.HandleAccounts(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("claiming a reserved name: status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	if _, err := reg.CreateAccount("new-author"); err != nil {
+		t.Errorf("CreateAccount(new-author) failed: %v", err)
+	}
+
+	// An admin issues the owner's token
+	w = admin(adminMux(reg), http.MethodPost, "/admin/accounts/old-author/token", "")
+	var account AccountResponse
+	if w.Code != http.StatusOK || json.NewDecoder(w.Body).Decode(&account) != nil {
+		t.Fatalf("issuing a token: status = %d", w.Code)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/upload", nil)
+	req.Header.Set("Authorization", "Bearer "+account.Token)
+	if author, err := reg.Authenticate(req); err != nil || author != "old-author" {
+		t.Errorf("Authenticate = %q, %v", author, err)
+	}
+	if _, err := reg.CreateAccount("old-author"); !errors.Is(err, ErrAccountExists) {
+		t.Errorf("CreateAccount after claiming: err = %v, want %v", err, ErrAccountExists)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	token, err := reg.CreateAccount("alice")
+	if err != nil {
+		t.Fatalf("CreateAccount failed: %v", err)
+	}
+	request := func(header string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		return req
+	}
+
+	if author, err := reg.Authenticate(request("Bearer " + token)); err != nil || author != "alice" {
+		t.Errorf("Authenticate = %q, %v", author, err)
+	}
+	for _, header := range []string{"", token, "Bearer ", "Bearer wrong"} {
+		if _, err := reg.Authenticate(request(header)); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Authenticate(%q) err = %v", header, err)
+		}
+	}
+
+	// A reset token replaces the old one
+	newToken, err := reg.ResetToken("alice")
+	if err != nil {
+		t.Fatalf("ResetToken failed: %v", err)
+	}
+	if _, err := reg.Authenticate(request("Bearer " + token)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("old token still accepted: %v", err)
+	}
+	if _, err := reg.ResetToken("nobody"); !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("ResetToken of unknown account err = %v", err)
+	}
+
+	if err := reg.SetSuspended("alice", true); err != nil {
+		t.Fatalf("SetSuspended failed: %v", err)
+	}
+	if _, err := reg.Authenticate(request("Bearer " + newToken)); !errors.Is(err, ErrAccountSuspended) {
+		t.Errorf("suspended account err = %v", err)
+	}
+}
+
+func TestHandleUploadOwnership(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	manifest := createValidManifest()
+	uploadTestMod(t, reg, manifest)
+
+	// Nobody else can publish the name, even naming the owner as author
+	next := manifest
+	next.Version = "1.1.0"
+	if code := uploadAs(t, reg, next, nil, "mallory"); code != http.StatusForbidden {
+		t.Errorf("upload as another account: status %d", code)
+	}
+
+	hijack := manifest
+	hijack.Version = "1.1.0"
+	hijack.Author = "mallory"
+	if code := uploadSigned(t, reg, hijack, nil); code != http.StatusForbidden {
+		t.Errorf("upload of another author's mod: status %d", code)
+	}
+
+	if code := uploadSigned(t, reg, next, nil); code != http.StatusCreated {
+		t.Errorf("owner's new version: status %d", code)
+	}
+
+	reg.SetSuspended(manifest.Author, true)
+	next.Version = "1.2.0"
+	if code := uploadSigned(t, reg, next, nil); code != http.StatusForbidden {
+		t.Errorf("upload by a suspended account: status %d", code)
+	}
+}
+
+func TestHandleRate(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	manifest := createValidManifest()
+	uploadTestMod(t, reg, manifest)
+	other := createValidManifest()
+	other.Name = "other-mod"
+	uploadTestMod(t, reg, other)
+
+	if code := rate(t, reg, "test-mod", "alice", 5); code != http.StatusNoContent {
+		t.Errorf("rating: status %d", code)
+	}
+	if code := rate(t, reg, "test-mod", "bob", 3); code != http.StatusNoContent {
+		t.Errorf("rating: status %d", code)
+	}
+	if code := rate(t, reg, "other-mod", "alice", 2); code != http.StatusNoContent {
+		t.Errorf("rating: status %d", code)
+	}
+	if code := rate(t, reg, "test-mod", manifest.Author, 5); code != http.StatusForbidden {
+		t.Errorf("rating your own mod: status %d", code)
+	}
+	if code := rate(t, reg, "test-mod", "alice", 6); code != http.StatusBadRequest {
+		t.Errorf("6 stars: status %d", code)
+	}
+	if code := rate(t, reg, "missing", "alice", 4); code != http.StatusNotFound {
+		t.Errorf("rating a missing mod: status %d", code)
+	}
+
+	// Rating again replaces the earlier rating
+	rate(t, reg, "test-mod", "bob", 4)
+
+	req := httptest.NewRequest(http.MethodGet, "/search?sort=rating", nil)
+	w := httptest.NewRecorder()
+	reg.HandleSearch(w, req)
+	var response struct {
+		Results []ModRecord `json:"results"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode search results: %v", err)
+	}
+	if len(response.Results) != 2 {
+		t.Fatalf("search found %d mods", len(response.Results))
+	}
+	top := response.Results[0]
+	if top.Name != "test-mod" || top.Rating != 4.5 || top.Ratings != 2 {
+		t.Errorf("top rated = %s, %.2f from %d ratings", top.Name, top.Rating, top.Ratings)
+	}
+}
+
+func TestHandleSearchSortDownloads(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	manifest := createValidManifest()
+	uploadTestMod(t, reg, manifest)
+	manifest.Version = "1.1.0"
+	uploadTestMod(t, reg, manifest)
+	other := createValidManifest()
+	other.Name = "other-mod"
+	uploadTestMod(t, reg, other)
+
+	downloads := []string{"/download/test-mod/1.0.0", "/download/test-mod/1.1.0", "/download/other-mod/1.0.0"}
+	for _, path := range downloads {
+		w := httptest.NewRecorder()
+		reg.HandleDownload(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("download %s: status %d", path, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/search?sort=downloads", nil)
+	w := httptest.NewRecorder()
+	reg.HandleSearch(w, req)
+	var response struct {
+		Results []ModRecord `json:"results"`
+	}
+	json.NewDecoder(w.Body).Decode(&response)
+	if len(response.Results) != 3 {
+		t.Fatalf("search found %d versions", len(response.Results))
+	}
+	for _, rec := range response.Results[:2] {
+		if rec.Name != "test-mod" || rec.TotalDownloads != 2 {
+			t.Errorf("result %s %s: total downloads %d", rec.Name, rec.Version, rec.TotalDownloads)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/mod"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultEntryPoint is where a package's WASM module goes when its manifest
+// names no entry point.
+const DefaultEntryPoint = "mod.wasm"
+
+// KeyRequest publishes a signing key for an author.
+type KeyRequest struct {
+	Author string `json:"author"`
+	Key    string `json:"key"` // Base64 Ed25519 public key
+
+	// Proof is a base64 signature over KeyProofMessage by one of the
+	// author's existing keys. An author's first key needs no proof.
+	Proof string `json:"proof,omitempty"`
+}
+
+// KeyProofMessage returns the message an author signs with an existing key
+// to publish another.
+func KeyProofMessage(author, key string) []byte {
+	return []byte("violence-mod-key\x00" + author + "\x00" + key)
+}
+
+// PackageFiles returns the files of a mod package as they are laid out in
+// a mod directory, which is what its signature covers.
+func PackageFiles(manifestData, wasmData []byte, entryPoint string) map[string][]byte {
+	if entryPoint == "" {
+		entryPoint = DefaultEntryPoint
+	}
+	// Rooting the path first keeps it inside the mod directory
+	entryPoint = path.Clean("/" + entryPoint)[1:]
+	return map[string][]byte{
+		"mod.json": manifestData,
+		entryPoint: wasmData,
+	}
+}
+
+// initKeySchema creates the signing key tables and adds the signature
+// column to registries created before it existed.
+func (r *Registry) initKeySchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS author_keys (
+		author TEXT NOT NULL,
+		key TEXT NOT NULL,
+		added_at DATETIME NOT NULL,
+		PRIMARY KEY (author, key)
+	);
+	CREATE TABLE IF NOT EXISTS blocked_keys (
+		key TEXT PRIMARY KEY
+	);
+	CREATE TABLE IF NOT EXISTS blocked_mods (
+		name TEXT PRIMARY KEY
+	);
+	`
+	if _, err := r.db.Exec(schema); err != nil {
+		return err
+	}
+	_, err := r.db.Exec("ALTER TABLE mods ADD COLUMN signature TEXT")
+	if err != nil && !strings.Contains(err.Error(), "duplicate column") {
+		return err
+	}
+	return nil
+}
+
+// HandleKeys serves the key ring on GET and publishes an author key on
+// POST.
+func (r *Registry) HandleKeys(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		ring, err := r.KeyRing()
+		if err != nil {
+			logrus.WithError(err).Error("Failed to read key ring")
+			http.Error(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ring)
+	case http.MethodPost:
+		r.handleAddKey(w, req)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAddKey publishes a key for the requesting account's author. The
+// first key an author publishes is trusted on first use; later keys must
+// be vouched for by an existing one, so a stolen account token alone can't
+// add keys.
+func (r *Registry) handleAddKey(w http.ResponseWriter, req *http.Request) {
+	account, ok := r.authenticate(w, req)
+	if !ok {
+		return
+	}
+	var kr KeyRequest
+	if err := json.NewDecoder(req.Body).Decode(&kr); err != nil || kr.Author == "" {
+		http.Error(w, "Invalid key request", http.StatusBadRequest)
+		return
+	}
+	if kr.Author != account {
+		http.Error(w, "Keys can only be published for your own account", http.StatusForbidden)
+		return
+	}
+	ring, err := r.KeyRing()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to read key ring")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	existing := ring.Authors[kr.Author]
+	if containsKey(existing, kr.Key) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err := mod.NewKeyRing().AddAuthorKey(kr.Author, kr.Key); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if containsKey(ring.BlockedKeys, kr.Key) {
+		http.Error(w, "Key is blocked", http.StatusForbidden)
+		return
+	}
+	if len(existing) > 0 && !provesKey(existing, ring.BlockedKeys, kr) {
+		http.Error(w, "Proof by an existing author key required", http.StatusForbidden)
+		return
+	}
+
+	_, err = r.db.Exec("INSERT OR IGNORE INTO author_keys (author, key, added_at) VALUES (?, ?, ?)", kr.Author, kr.Key, time.Now())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to insert author key")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "mod_registry",
+		"author":      kr.Author,
+		"keys":        len(existing) + 1,
+	}).Info("Author key published")
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// provesKey reports whether the request's proof is a signature by one of
+// the author's unblocked keys.
+func provesKey(keys, blocked []string, kr KeyRequest) bool {
+	proof, err := base64.StdEncoding.DecodeString(kr.Proof)
+	if err != nil {
+		return false
+	}
+	message := KeyProofMessage(kr.Author, kr.Key)
+	for _, key := range keys {
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil || len(raw) != ed25519.PublicKeySize || containsKey(blocked, key) {
+			continue
+		}
+		if ed25519.Verify(ed25519.PublicKey(raw), message, proof) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyRing returns the author keys the registry publishes, and the keys and
+// mods it has blocked.
+func (r *Registry) KeyRing() (*mod.KeyRing, error) {
+	ring := mod.NewKeyRing()
+
+	rows, err := r.db.Query("SELECT author, key FROM author_keys ORDER BY added_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var author, key string
+		if err := rows.Scan(&author, &key); err != nil {
+			return nil, err
+		}
+		ring.Authors[author] = append(ring.Authors[author], key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if ring.BlockedKeys, err = r.queryStrings("SELECT key FROM blocked_keys ORDER BY key"); err != nil {
+		return nil, err
+	}
+	if ring.BlockedMods, err = r.queryStrings("SELECT name FROM blocked_mods ORDER BY name"); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+// BlockKey revokes a signing key, blocking every mod signed with it.
+func (r *Registry) BlockKey(key string) error {
+	_, err := r.db.Exec("INSERT OR IGNORE INTO blocked_keys (key) VALUES (?)", key)
+	return err
+}
+
+// BlockMod takes a mod down: clients stop loading it and it can't be
+// uploaded again.
+func (r *Registry) BlockMod(name string) error {
+	_, err := r.db.Exec("INSERT OR IGNORE INTO blocked_mods (name) VALUES (?)", name)
+	return err
+}
+
+// UnblockMod lifts a mod's takedown, reporting whether it was taken down.
+func (r *Registry) UnblockMod(name string) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM blocked_mods WHERE name = ?", name)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// isBlockedMod reports whether a mod has been taken down.
+func (r *Registry) isBlockedMod(name string) (bool, error) {
+	var blocked string
+	err := r.db.QueryRow("SELECT name FROM blocked_mods WHERE name = ?", name).Scan(&blocked)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// verifyUploadSignature rejects uploads of blocked mods and, when the
+// upload is signed, checks the signature against the author's published
+// keys.
+func (r *Registry) verifyUploadSignature(w http.ResponseWriter, upload *modUpload) error {
+	ring, err := r.KeyRing()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to read key ring")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return err
+	}
+
+	sig := upload.signature
+	if sig != nil && sig.Author != upload.manifest.Author {
+		err := fmt.Errorf("signed by %s, but the manifest author is %s", sig.Author, upload.manifest.Author)
+		http.Error(w, fmt.Sprintf("Invalid signature: %v", err), http.StatusBadRequest)
+		return err
+	}
+
+	files := PackageFiles(upload.manifestData, upload.wasmData, upload.manifest.EntryPoint)
+	_, err = ring.Verify(upload.manifest.Name, upload.manifest.Author, mod.PackageDigest(files), sig)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, mod.ErrModBlocked):
+		logrus.WithError(err).WithField("mod_name", upload.manifest.Name).Warn("Mod upload rejected")
+		http.Error(w, fmt.Sprintf("Upload rejected: %v", err), http.StatusForbidden)
+		return err
+	case sig == nil:
+		// Unsigned mods are still accepted; clients warn about them
+		return nil
+	default:
+		http.Error(w, fmt.Sprintf("Invalid signature: %v", err), http.StatusForbidden)
+		return err
+	}
+}
+
+// queryStrings returns the single string column a query selects.
+func (r *Registry) queryStrings(query string) ([]string, error) {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+	}
+	return values, rows.Err()
+}
+
+// containsKey reports whether a list of keys holds key.
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
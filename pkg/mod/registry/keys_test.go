@@ -0,0 +1,182 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/mod"
+)
+
+// postKey publishes a key and returns the response status.
+func postKey(t *testing.T, reg *Registry, kr KeyRequest) int {
+	t.Helper()
+	body, _ := json.Marshal(kr)
+	req := httptest.NewRequest(http.MethodPost, "/keys", bytes.NewReader(body))
+	authorize(t, reg, req, kr.Author)
+	w := httptest.NewRecorder()
+	reg.HandleKeys(w, req)
+	return w.Code
+}
+
+// uploadSigned uploads the test mod with a signature, which may be nil, and
+// returns the response status.
+func uploadSigned(t *testing.T, reg *Registry, manifest mod.Manifest, sig *mod.PackageSignature) int {
+	t.Helper()
+	return uploadAs(t, reg, manifest, sig, manifest.Author)
+}
+
+// uploadAs uploads the test mod from account's author and returns the
+// response status.
+func uploadAs(t *testing.T, reg *Registry, manifest mod.Manifest, sig *mod.PackageSignature, account string) int {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	wasmPart, _ := writer.CreateFormFile("wasm", "test.wasm")
+	wasmPart.Write(createValidWASM())
+	manifestPart, _ := writer.CreateFormFile("manifest", "mod.json")
+	manifestJSON, _ := json.Marshal(manifest)
+	manifestPart.Write(manifestJSON)
+	if sig != nil {
+		sigPart, _ := writer.CreateFormFile("signature", mod.SignatureFile)
+		json.NewEncoder(sigPart).Encode(sig)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	authorize(t, reg, req, account)
+	w := httptest.NewRecorder()
+	reg.HandleUpload(w, req)
+	return w.Code
+}
+
+// signManifest signs the test mod's package as laid out for manifest.
+func signManifest(manifest mod.Manifest, author string, key ed25519.PrivateKey) *mod.PackageSignature {
+	manifestJSON, _ := json.Marshal(manifest)
+	files := PackageFiles(manifestJSON, createValidWASM(), manifest.EntryPoint)
+	return mod.SignPackage(mod.PackageDigest(files), author, key)
+}
+
+func TestHandleKeys(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	second, _, _ := ed25519.GenerateKey(nil)
+	key := base64.StdEncoding.EncodeToString(pub)
+	secondKey := base64.StdEncoding.EncodeToString(second)
+
+	if code := postKey(t, reg, KeyRequest{Author: "alice", Key: key}); code != http.StatusCreated {
+		t.Fatalf("first key: status %d", code)
+	}
+	if code := postKey(t, reg, KeyRequest{Author: "alice", Key: secondKey}); code != http.StatusForbidden {
+		t.Errorf("second key without proof: status %d", code)
+	}
+	proof := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, KeyProofMessage("alice", secondKey)))
+	if code := postKey(t, reg, KeyRequest{Author: "alice", Key: secondKey, Proof: proof}); code != http.StatusCreated {
+		t.Errorf("second key with proof: status %d", code)
+	}
+	if code := postKey(t, reg, KeyRequest{Author: "bob", Key: "bogus"}); code != http.StatusBadRequest {
+		t.Errorf("malformed key: status %d", code)
+	}
+
+	reg.BlockKey(secondKey)
+	reg.BlockMod("malware")
+	req := httptest.NewRequest(http.MethodGet, "/keys", nil)
+	w := httptest.NewRecorder()
+	reg.HandleKeys(w, req)
+	var ring mod.KeyRing
+	if err := json.NewDecoder(w.Body).Decode(&ring); err != nil {
+		t.Fatalf("Failed to decode key ring: %v", err)
+	}
+	if len(ring.Authors["alice"]) != 2 || len(ring.BlockedKeys) != 1 || len(ring.BlockedMods) != 1 {
+		t.Errorf("key ring = %+v", ring)
+	}
+}
+
+func TestHandleUploadSigned(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+	postKey(t, reg, KeyRequest{Author: "test-author", Key: base64.StdEncoding.EncodeToString(pub)})
+	manifest := createValidManifest()
+
+	tampered := signManifest(manifest, "test-author", priv)
+	changed := manifest
+	changed.Description = "Changed after signing"
+
+	tests := []struct {
+		name     string
+		manifest mod.Manifest
+		sig      *mod.PackageSignature
+		want     int
+	}{
+		{"signed", manifest, signManifest(manifest, "test-author", priv), http.StatusCreated},
+		{"unsigned", manifest, nil, http.StatusCreated},
+		{"unpublished key", manifest, signManifest(manifest, "test-author", otherPriv), http.StatusForbidden},
+		{"other author", manifest, signManifest(manifest, "someone-else", priv), http.StatusBadRequest},
+		{"tampered", changed, tampered, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := uploadSigned(t, reg, tt.manifest, tt.sig); code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+
+	// Search results carry the signature so clients can verify installs
+	uploadSigned(t, reg, manifest, signManifest(manifest, "test-author", priv))
+	req := httptest.NewRequest(http.MethodGet, "/search?name=test-mod", nil)
+	w := httptest.NewRecorder()
+	reg.HandleSearch(w, req)
+	var response struct {
+		Results []ModRecord `json:"results"`
+	}
+	json.NewDecoder(w.Body).Decode(&response)
+	if len(response.Results) != 1 || response.Results[0].Signature == nil {
+		t.Fatalf("search results = %+v", response.Results)
+	}
+}
+
+func TestBlockMod(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	manifest := createValidManifest()
+	uploadTestMod(t, reg, manifest)
+	if err := reg.BlockMod(manifest.Name); err != nil {
+		t.Fatalf("BlockMod failed: %v", err)
+	}
+
+	if code := uploadSigned(t, reg, manifest, nil); code != http.StatusForbidden {
+		t.Errorf("upload of a blocked mod: status %d", code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/search", nil)
+	w := httptest.NewRecorder()
+	reg.HandleSearch(w, req)
+	var response struct {
+		Count int `json:"count"`
+	}
+	json.NewDecoder(w.Body).Decode(&response)
+	if response.Count != 0 {
+		t.Errorf("search found %d blocked mods", response.Count)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/download/test-mod/1.0.0", nil)
+	w = httptest.NewRecorder()
+	reg.HandleDownload(w, req)
+	if w.Code != http.StatusGone {
+		t.Errorf("download of a blocked mod: status %d", w.Code)
+	}
+}
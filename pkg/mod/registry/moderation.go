@@ -0,0 +1,364 @@
+package registry
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Report statuses.
+const (
+	ReportOpen      = "open"       // Waiting in the moderation queue
+	ReportDismissed = "dismissed"  // Reviewed and left up
+	ReportTakenDown = "taken_down" // The mod was taken down
+)
+
+// maxReportReason caps the length of a report's reason.
+const maxReportReason = 500
+
+// ReportRequest reports a mod for moderation.
+type ReportRequest struct {
+	Version string `json:"version,omitempty"` // Version reported, empty for all
+	Reason  string `json:"reason"`
+}
+
+// Report is a report in the moderation queue.
+type Report struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Version   string    `json:"version,omitempty"`
+	Reporter  string    `json:"reporter"` // Account author, or "anonymous"
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TakedownRequest names a mod to take down.
+type TakedownRequest struct {
+	Name string `json:"name"`
+}
+
+// SuspendRequest names an account to suspend.
+type SuspendRequest struct {
+	Author string `json:"author"`
+}
+
+// BlockKeyRequest names a signing key to revoke.
+type BlockKeyRequest struct {
+	Key string `json:"key"`
+}
+
+// initModerationSchema creates the report table.
+func (r *Registry) initModerationSchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		version TEXT NOT NULL DEFAULT '',
+		reporter TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_reports_status ON reports(status, created_at);
+	`
+	_, err := r.db.Exec(schema)
+	return err
+}
+
+// SetAdminToken sets the bearer token for the /admin endpoints. They are
+// only registered when it is set.
+func (r *Registry) SetAdminToken(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adminToken = token
+}
+
+// RegisterAdmin adds the moderation endpoints to mux, if an admin token
+// is set.
+func (r *Registry) RegisterAdmin(mux *http.ServeMux) {
+	r.mu.RLock()
+	token := r.adminToken
+	r.mu.RUnlock()
+	if token == "" {
+		return
+	}
+	mux.HandleFunc("GET /admin/reports", r.withAdmin(token, r.handleAdminReports))
+	mux.HandleFunc("POST /admin/reports/{id}/dismiss", r.withAdmin(token, r.handleAdminDismiss))
+	mux.HandleFunc("POST /admin/takedowns", r.withAdmin(token, r.handleAdminTakedown))
+	mux.HandleFunc("DELETE /admin/takedowns/{name}", r.withAdmin(token, r.handleAdminRestore))
+	mux.HandleFunc("POST /admin/blocked-keys", r.withAdmin(token, r.handleAdminBlockKey))
+	mux.HandleFunc("POST /admin/suspensions", r.withAdmin(token, r.handleAdminSuspend))
+	mux.HandleFunc("DELETE /admin/suspensions/{author}", r.withAdmin(token, r.handleAdminReinstate))
+	mux.HandleFunc("POST /admin/accounts/{author}/token", r.withAdmin(token, r.handleAdminResetToken))
+}
+
+// withAdmin wraps a handler so it requires the admin bearer token.
+func (r *Registry) withAdmin(token string, handler http.HandlerFunc) http.HandlerFunc {
+	want := []byte("Bearer " + token)
+	return func(w http.ResponseWriter, req *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), want) != 1 {
+			logrus.WithField("remote_addr", req.RemoteAddr).Warn("Unauthorized admin request")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, req)
+	}
+}
+
+// HandleReport files a report against a mod at /report/{name}. Anyone may
+// report; reports with an account token name its author as the reporter.
+func (r *Registry) HandleReport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reporter := "anonymous"
+	if req.Header.Get("Authorization") != "" {
+		author, ok := r.authenticate(w, req)
+		if !ok {
+			return
+		}
+		reporter = author
+	}
+
+	name := strings.TrimPrefix(req.URL.Path, "/report/")
+	var rr ReportRequest
+	if err := json.NewDecoder(req.Body).Decode(&rr); err != nil {
+		http.Error(w, "Invalid report", http.StatusBadRequest)
+		return
+	}
+	rr.Reason = strings.TrimSpace(rr.Reason)
+	if rr.Reason == "" || len(rr.Reason) > maxReportReason {
+		http.Error(w, "A reason of up to 500 characters is required", http.StatusBadRequest)
+		return
+	}
+	if _, err := r.modOwner(name); err == sql.ErrNoRows {
+		http.Error(w, "Mod not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("Database query failed")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := r.db.Exec("INSERT INTO reports (name, version, reporter, reason, created_at) VALUES (?, ?, ?, ?, ?)",
+		name, rr.Version, reporter, rr.Reason, time.Now())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to save report")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	id, _ := result.LastInsertId()
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "mod_registry",
+		"mod_name":    name,
+		"report_id":   id,
+		"reporter":    reporter,
+	}).Info("Mod reported")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "status": ReportOpen})
+}
+
+// Reports returns the reports with a status, or all reports if status is
+// empty, oldest first.
+func (r *Registry) Reports(status string) ([]Report, error) {
+	query := "SELECT id, name, version, reporter, reason, status, created_at FROM reports"
+	var args []interface{}
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	rows, err := r.db.Query(query+" ORDER BY created_at, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []Report{}
+	for rows.Next() {
+		var rep Report
+		if err := rows.Scan(&rep.ID, &rep.Name, &rep.Version, &rep.Reporter, &rep.Reason, &rep.Status, &rep.CreatedAt); err != nil {
+			return nil, err
+		}
+		reports = append(reports, rep)
+	}
+	return reports, rows.Err()
+}
+
+// TakeDown blocks a mod and closes its open reports, returning how many
+// it closed.
+func (r *Registry) TakeDown(name string) (int64, error) {
+	if err := r.BlockMod(name); err != nil {
+		return 0, err
+	}
+	result, err := r.db.Exec("UPDATE reports SET status = ? WHERE name = ? AND status = ?", ReportTakenDown, name, ReportOpen)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// handleAdminReports lists the moderation queue: open reports, or those
+// with the ?status given ("all" for every report).
+func (r *Registry) handleAdminReports(w http.ResponseWriter, req *http.Request) {
+	status := req.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = ReportOpen
+	case "all":
+		status = ""
+	}
+	reports, err := r.Reports(status)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list reports")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	writeAdminJSON(w, reports)
+}
+
+// handleAdminDismiss closes a report without action.
+func (r *Registry) handleAdminDismiss(w http.ResponseWriter, req *http.Request) {
+	id, err := strconv.ParseInt(req.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid report ID", http.StatusBadRequest)
+		return
+	}
+	result, err := r.db.Exec("UPDATE reports SET status = ? WHERE id = ? AND status = ?", ReportDismissed, id, ReportOpen)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to dismiss report")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		http.Error(w, "No open report with that ID", http.StatusNotFound)
+		return
+	}
+	logrus.WithField("report_id", id).Info("Admin dismissed report")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminTakedown takes a mod down: it disappears from search and
+// downloads, can't be uploaded again, and clients stop loading it.
+func (r *Registry) handleAdminTakedown(w http.ResponseWriter, req *http.Request) {
+	var tr TakedownRequest
+	if err := json.NewDecoder(req.Body).Decode(&tr); err != nil || tr.Name == "" {
+		http.Error(w, "Invalid takedown request", http.StatusBadRequest)
+		return
+	}
+	closed, err := r.TakeDown(tr.Name)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to take down mod")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"mod_name":       tr.Name,
+		"reports_closed": closed,
+	}).Info("Admin took down mod")
+	writeAdminJSON(w, map[string]interface{}{"name": tr.Name, "reports_closed": closed})
+}
+
+// handleAdminRestore lifts a takedown.
+func (r *Registry) handleAdminRestore(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+	restored, err := r.UnblockMod(name)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to restore mod")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	if !restored {
+		http.Error(w, "Mod is not taken down", http.StatusNotFound)
+		return
+	}
+	logrus.WithField("mod_name", name).Info("Admin restored mod")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminBlockKey revokes a signing key.
+func (r *Registry) handleAdminBlockKey(w http.ResponseWriter, req *http.Request) {
+	var br BlockKeyRequest
+	if err := json.NewDecoder(req.Body).Decode(&br); err != nil || br.Key == "" {
+		http.Error(w, "Invalid key", http.StatusBadRequest)
+		return
+	}
+	if err := r.BlockKey(br.Key); err != nil {
+		logrus.WithError(err).Error("Failed to block key")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	logrus.WithField("key", br.Key).Info("Admin blocked signing key")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminSuspend suspends an account.
+func (r *Registry) handleAdminSuspend(w http.ResponseWriter, req *http.Request) {
+	var sr SuspendRequest
+	if err := json.NewDecoder(req.Body).Decode(&sr); err != nil || sr.Author == "" {
+		http.Error(w, "Invalid suspension request", http.StatusBadRequest)
+		return
+	}
+	r.setSuspended(w, sr.Author, true)
+}
+
+// handleAdminReinstate lifts an account's suspension.
+func (r *Registry) handleAdminReinstate(w http.ResponseWriter, req *http.Request) {
+	r.setSuspended(w, req.PathValue("author"), false)
+}
+
+// setSuspended suspends or reinstates an account for an admin request.
+func (r *Registry) setSuspended(w http.ResponseWriter, author string, suspended bool) {
+	err := r.SetSuspended(author, suspended)
+	if errors.Is(err, ErrAccountNotFound) {
+		http.Error(w, "Account not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("Failed to update account")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"author":    author,
+		"suspended": suspended,
+	}).Info("Admin changed account suspension")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAdminResetToken issues a new token for an account whose author
+// lost theirs, or creates the account of an author who published before
+// accounts existed.
+func (r *Registry) handleAdminResetToken(w http.ResponseWriter, req *http.Request) {
+	author := req.PathValue("author")
+	token, err := r.IssueToken(author)
+	if errors.Is(err, ErrInvalidAuthor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("Failed to reset token")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	logrus.WithField("author", author).Info("Admin reset account token")
+	writeAdminJSON(w, AccountResponse{Author: author, Token: token})
+}
+
+// writeAdminJSON encodes an admin response.
+func writeAdminJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.WithError(err).Error("Failed to encode admin response")
+	}
+}
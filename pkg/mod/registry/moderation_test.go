@@ -0,0 +1,215 @@
+package registry
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testAdminToken = "admin-secret"
+
+// adminMux returns a mux serving the registry's admin endpoints.
+func adminMux(reg *Registry) *http.ServeMux {
+	mux := http.NewServeMux()
+	reg.SetAdminToken(testAdminToken)
+	reg.RegisterAdmin(mux)
+	return mux
+}
+
+// admin sends an admin request and returns the response.
+func admin(mux *http.ServeMux, method, path, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+// report files a report, with an account token unless reporter is empty,
+// and returns the response status.
+func report(t *testing.T, reg *Registry, name, reporter, body string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/report/"+name, strings.NewReader(body))
+	if reporter != "" {
+		authorize(t, reg, req, reporter)
+	}
+	w := httptest.NewRecorder()
+	reg.HandleReport(w, req)
+	return w.Code
+}
+
+func TestHandleReport(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+	uploadTestMod(t, reg, createValidManifest())
+
+	tests := []struct {
+		name     string
+		mod      string
+		reporter string
+		body     string
+		status   int
+	}{
+		{"anonymous", "test-mod", "", `{"reason": "Crashes on load"}`, http.StatusCreated},
+		{"account", "test-mod", "alice", `{"version": "1.0.0", "reason": "Steals saves"}`, http.StatusCreated},
+		{"no reason", "test-mod", "", `{"reason": "  "}`, http.StatusBadRequest},
+		{"long reason", "test-mod", "", `{"reason": "` + strings.Repeat("x", maxReportReason+1) + `"}`, http.StatusBadRequest},
+		{"missing mod", "missing", "", `{"reason": "Broken"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := report(t, reg, tt.mod, tt.reporter, tt.body); code != tt.status {
+				t.Errorf("status = %d, want %d", code, tt.status)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/report/test-mod", strings.NewReader(`{"reason": "Spam"}`))
+	req.Header.Set("Authorization", "Bearer wrong")
+	w := httptest.NewRecorder()
+	reg.HandleReport(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("bad token: status %d", w.Code)
+	}
+
+	reports, err := reg.Reports(ReportOpen)
+	if err != nil {
+		t.Fatalf("Reports failed: %v", err)
+	}
+	if len(reports) != 2 || reports[0].Reporter != "anonymous" || reports[1].Reporter != "alice" {
+		t.Errorf("reports = %+v", reports)
+	}
+}
+
+func TestAdminUnauthorized(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+	mux := adminMux(reg)
+
+	for _, header := range []string{"", "Bearer wrong", testAdminToken} {
+		req := httptest.NewRequest(http.MethodGet, "/admin/reports", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status %d", header, w.Code)
+		}
+	}
+
+	// Without a token the admin endpoints aren't served at all
+	disabled, cleanupDisabled := setupTestRegistry(t)
+	defer cleanupDisabled()
+	plain := http.NewServeMux()
+	disabled.RegisterAdmin(plain)
+	if w := admin(plain, http.MethodGet, "/admin/reports", ""); w.Code != http.StatusNotFound {
+		t.Errorf("admin endpoints without a token: status %d", w.Code)
+	}
+}
+
+func TestAdminModerationQueue(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+	mux := adminMux(reg)
+	manifest := createValidManifest()
+	uploadTestMod(t, reg, manifest)
+
+	report(t, reg, "test-mod", "", `{"reason": "Crashes on load"}`)
+	report(t, reg, "test-mod", "alice", `{"reason": "Steals saves"}`)
+
+	var queue []Report
+	w := admin(mux, http.MethodGet, "/admin/reports", "")
+	if err := json.NewDecoder(w.Body).Decode(&queue); err != nil || len(queue) != 2 {
+		t.Fatalf("queue = %+v, %v", queue, err)
+	}
+
+	if w := admin(mux, http.MethodPost, "/admin/reports/1/dismiss", ""); w.Code != http.StatusNoContent {
+		t.Errorf("dismiss: status %d", w.Code)
+	}
+	if w := admin(mux, http.MethodPost, "/admin/reports/1/dismiss", ""); w.Code != http.StatusNotFound {
+		t.Errorf("dismissing a closed report: status %d", w.Code)
+	}
+
+	w = admin(mux, http.MethodPost, "/admin/takedowns", `{"name": "test-mod"}`)
+	var takedown struct {
+		ReportsClosed int `json:"reports_closed"`
+	}
+	json.NewDecoder(w.Body).Decode(&takedown)
+	if w.Code != http.StatusOK || takedown.ReportsClosed != 1 {
+		t.Errorf("takedown: status %d, closed %d", w.Code, takedown.ReportsClosed)
+	}
+	if open, _ := reg.Reports(ReportOpen); len(open) != 0 {
+		t.Errorf("%d reports still open", len(open))
+	}
+	if all, _ := reg.Reports(""); len(all) != 2 || all[0].Status != ReportDismissed || all[1].Status != ReportTakenDown {
+		t.Errorf("reports = %+v", all)
+	}
+
+	download := httptest.NewRecorder()
+	reg.HandleDownload(download, httptest.NewRequest(http.MethodGet, "/download/test-mod/1.0.0", nil))
+	if download.Code != http.StatusGone {
+		t.Errorf("download after takedown: status %d", download.Code)
+	}
+
+	if w := admin(mux, http.MethodDelete, "/admin/takedowns/test-mod", ""); w.Code != http.StatusNoContent {
+		t.Errorf("restore: status %d", w.Code)
+	}
+	if w := admin(mux, http.MethodDelete, "/admin/takedowns/test-mod", ""); w.Code != http.StatusNotFound {
+		t.Errorf("restoring a listed mod: status %d", w.Code)
+	}
+	download = httptest.NewRecorder()
+	reg.HandleDownload(download, httptest.NewRequest(http.MethodGet, "/download/test-mod/1.0.0", nil))
+	if download.Code != http.StatusOK {
+		t.Errorf("download after restore: status %d", download.Code)
+	}
+}
+
+func TestAdminAccounts(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+	mux := adminMux(reg)
+	manifest := createValidManifest()
+	uploadTestMod(t, reg, manifest)
+
+	if w := admin(mux, http.MethodPost, "/admin/suspensions", `{"author": "test-author"}`); w.Code != http.StatusNoContent {
+		t.Fatalf("suspend: status %d", w.Code)
+	}
+	manifest.Version = "1.1.0"
+	if code := uploadSigned(t, reg, manifest, nil); code != http.StatusForbidden {
+		t.Errorf("upload while suspended: status %d", code)
+	}
+	if w := admin(mux, http.MethodPost, "/admin/suspensions", `{"author": "nobody"}`); w.Code != http.StatusNotFound {
+		t.Errorf("suspending an unknown account: status %d", w.Code)
+	}
+
+	if w := admin(mux, http.MethodDelete, "/admin/suspensions/test-author", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("reinstate: status %d", w.Code)
+	}
+	if code := uploadSigned(t, reg, manifest, nil); code != http.StatusCreated {
+		t.Errorf("upload after reinstating: status %d", code)
+	}
+
+	w := admin(mux, http.MethodPost, "/admin/accounts/test-author/token", "")
+	var account AccountResponse
+	json.NewDecoder(w.Body).Decode(&account)
+	req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	req.Header.Set("Authorization", "Bearer "+account.Token)
+	if author, err := reg.Authenticate(req); err != nil || author != "test-author" {
+		t.Errorf("reset token: Authenticate = %q, %v", author, err)
+	}
+
+	if w := admin(mux, http.MethodPost, "/admin/blocked-keys", `{"key": "revoked"}`); w.Code != http.StatusNoContent {
+		t.Errorf("block key: status %d", w.Code)
+	}
+	if ring, _ := reg.KeyRing(); len(ring.BlockedKeys) != 1 {
+		t.Errorf("blocked keys = %v", ring.BlockedKeys)
+	}
+}
@@ -25,6 +25,7 @@ type Registry struct {
 	db          *sql.DB
 	storagePath string
 	maxModSize  int64
+	adminToken  string
 	mu          sync.RWMutex
 }
 
@@ -39,6 +40,22 @@ type ModRecord struct {
 	Size        int64     `json:"size"`
 	UploadedAt  time.Time `json:"uploaded_at"`
 	Downloads   int       `json:"downloads"`
+
+	// Totals across all versions of the mod
+	TotalDownloads int     `json:"total_downloads"`
+	Rating         float64 `json:"rating"`  // Average stars, 0 if unrated
+	Ratings        int     `json:"ratings"` // Number of ratings
+
+	// Signature is the author's signature over the package, if signed
+	Signature *mod.PackageSignature `json:"signature,omitempty"`
+}
+
+// modUpload is a mod package received by HandleUpload.
+type modUpload struct {
+	manifest     *mod.Manifest
+	manifestData []byte // The manifest as uploaded, which the signature covers
+	wasmData     []byte
+	signature    *mod.PackageSignature
 }
 
 // NewRegistry creates a new mod registry with database and storage.
@@ -92,137 +109,210 @@ func (r *Registry) initSchema() error {
 	CREATE INDEX IF NOT EXISTS idx_mods_author ON mods(author);
 	CREATE INDEX IF NOT EXISTS idx_mods_uploaded ON mods(uploaded_at DESC);
 	`
-	_, err := r.db.Exec(schema)
-	return err
+	if _, err := r.db.Exec(schema); err != nil {
+		return err
+	}
+	if err := r.initKeySchema(); err != nil {
+		return err
+	}
+	if err := r.initAccountSchema(); err != nil {
+		return err
+	}
+	return r.initModerationSchema()
 }
 
-// HandleUpload processes mod upload requests with validation and virus scanning.
+// HandleUpload processes mod upload requests with validation and virus
+// scanning. Uploads need an account token, and the manifest's author must
+// be the account's.
 func (r *Registry) HandleUpload(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	manifest, wasmData, err := r.parseUploadRequest(w, req)
+	account, ok := r.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	upload, err := r.parseUploadRequest(w, req)
 	if err != nil {
 		return
 	}
 
-	checksum, err := r.validateAndStoreFiles(w, manifest, wasmData)
+	if err := r.checkUploadOwner(w, account, upload.manifest); err != nil {
+		return
+	}
+
+	if err := r.verifyUploadSignature(w, upload); err != nil {
+		return
+	}
+
+	checksum, err := r.validateAndStoreFiles(w, upload)
 	if err != nil {
 		return
 	}
 
-	if err := r.saveModMetadata(w, manifest, wasmData, checksum); err != nil {
+	if err := r.saveModMetadata(w, upload, checksum); err != nil {
 		return
 	}
 
-	r.sendUploadSuccess(w, manifest, checksum)
+	r.sendUploadSuccess(w, upload.manifest, checksum)
 }
 
-// parseUploadRequest extracts and validates manifest and WASM files from the upload request.
-func (r *Registry) parseUploadRequest(w http.ResponseWriter, req *http.Request) (*mod.Manifest, []byte, error) {
+// checkUploadOwner rejects uploads whose manifest names another author,
+// or of a mod first uploaded by another author.
+func (r *Registry) checkUploadOwner(w http.ResponseWriter, account string, manifest *mod.Manifest) error {
+	if manifest.Author != account {
+		err := fmt.Errorf("manifest author %q is not account %q", manifest.Author, account)
+		http.Error(w, fmt.Sprintf("Upload rejected: %v", err), http.StatusForbidden)
+		return err
+	}
+	owner, err := r.modOwner(manifest.Name)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		logrus.WithError(err).Error("Database query failed")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return err
+	}
+	if owner != account {
+		err := fmt.Errorf("%s belongs to %s", manifest.Name, owner)
+		http.Error(w, fmt.Sprintf("Upload rejected: %v", err), http.StatusForbidden)
+		return err
+	}
+	return nil
+}
+
+// parseUploadRequest extracts and validates the manifest, WASM and optional
+// signature files from the upload request.
+func (r *Registry) parseUploadRequest(w http.ResponseWriter, req *http.Request) (*modUpload, error) {
 	if err := req.ParseMultipartForm(r.maxModSize); err != nil {
 		logrus.WithError(err).Warn("Failed to parse multipart form")
 		http.Error(w, "Invalid request", http.StatusBadRequest)
-		return nil, nil, err
+		return nil, err
 	}
 
 	file, header, err := req.FormFile("wasm")
 	if err != nil {
 		http.Error(w, "Missing wasm file", http.StatusBadRequest)
-		return nil, nil, err
+		return nil, err
 	}
 	defer file.Close()
 
 	if header.Size > r.maxModSize {
 		http.Error(w, fmt.Sprintf("File too large (max %d bytes)", r.maxModSize), http.StatusRequestEntityTooLarge)
-		return nil, nil, fmt.Errorf("file too large")
+		return nil, fmt.Errorf("file too large")
 	}
 
 	manifestFile, _, err := req.FormFile("manifest")
 	if err != nil {
 		http.Error(w, "Missing manifest file", http.StatusBadRequest)
-		return nil, nil, err
+		return nil, err
 	}
 	defer manifestFile.Close()
 
-	manifest, err := r.parseManifest(w, manifestFile)
+	manifest, manifestData, err := r.parseManifest(w, manifestFile)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	wasmData, err := io.ReadAll(file)
 	if err != nil {
 		http.Error(w, "Failed to read WASM file", http.StatusInternalServerError)
-		return nil, nil, err
+		return nil, err
 	}
 
-	return manifest, wasmData, nil
+	upload := &modUpload{manifest: manifest, manifestData: manifestData, wasmData: wasmData}
+	if sigFile, _, err := req.FormFile("signature"); err == nil {
+		defer sigFile.Close()
+		var sig mod.PackageSignature
+		if err := json.NewDecoder(sigFile).Decode(&sig); err != nil {
+			http.Error(w, "Invalid signature JSON", http.StatusBadRequest)
+			return nil, err
+		}
+		upload.signature = &sig
+	}
+
+	return upload, nil
 }
 
 // parseManifest reads and validates a manifest JSON file.
-func (r *Registry) parseManifest(w http.ResponseWriter, manifestFile multipart.File) (*mod.Manifest, error) {
+func (r *Registry) parseManifest(w http.ResponseWriter, manifestFile multipart.File) (*mod.Manifest, []byte, error) {
 	manifestData, err := io.ReadAll(manifestFile)
 	if err != nil {
 		http.Error(w, "Failed to read manifest", http.StatusBadRequest)
-		return nil, err
+		return nil, nil, err
 	}
 
 	var manifest mod.Manifest
 	if err := json.Unmarshal(manifestData, &manifest); err != nil {
 		logrus.WithError(err).Warn("Invalid manifest JSON")
 		http.Error(w, "Invalid manifest JSON", http.StatusBadRequest)
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := manifest.Validate(); err != nil {
 		logrus.WithError(err).Warn("Manifest validation failed")
 		http.Error(w, fmt.Sprintf("Invalid manifest: %v", err), http.StatusBadRequest)
-		return nil, err
+		return nil, nil, err
 	}
 
-	return &manifest, nil
+	return &manifest, manifestData, nil
 }
 
-// validateAndStoreFiles validates WASM data and stores it to disk.
-func (r *Registry) validateAndStoreFiles(w http.ResponseWriter, manifest *mod.Manifest, wasmData []byte) (string, error) {
-	if err := validateWASM(wasmData); err != nil {
+// validateAndStoreFiles validates WASM data and stores it to disk, with the
+// manifest as uploaded beside it.
+func (r *Registry) validateAndStoreFiles(w http.ResponseWriter, upload *modUpload) (string, error) {
+	if err := validateWASM(upload.wasmData); err != nil {
 		logrus.WithError(err).Warn("WASM validation failed")
 		http.Error(w, fmt.Sprintf("Invalid WASM file: %v", err), http.StatusBadRequest)
 		return "", err
 	}
 
-	if err := virusScanStub(wasmData); err != nil {
+	if err := virusScanStub(upload.wasmData); err != nil {
 		logrus.WithError(err).Warn("Virus scan failed")
 		http.Error(w, "Security scan failed", http.StatusForbidden)
 		return "", err
 	}
 
-	hash := sha256.Sum256(wasmData)
+	hash := sha256.Sum256(upload.wasmData)
 	checksum := hex.EncodeToString(hash[:])
 
-	modPath := filepath.Join(r.storagePath, fmt.Sprintf("%s-%s.wasm", manifest.Name, manifest.Version))
-	if err := os.WriteFile(modPath, wasmData, 0o644); err != nil {
+	modPath := r.modFilePath(upload.manifest.Name, upload.manifest.Version, ".wasm")
+	if err := os.WriteFile(modPath, upload.wasmData, 0o644); err != nil {
 		logrus.WithError(err).Error("Failed to write WASM file")
 		http.Error(w, "Storage error", http.StatusInternalServerError)
 		return "", err
 	}
+	manifestPath := r.modFilePath(upload.manifest.Name, upload.manifest.Version, ".json")
+	if err := os.WriteFile(manifestPath, upload.manifestData, 0o644); err != nil {
+		logrus.WithError(err).Error("Failed to write manifest file")
+		os.Remove(modPath)
+		http.Error(w, "Storage error", http.StatusInternalServerError)
+		return "", err
+	}
 
 	return checksum, nil
 }
 
 // saveModMetadata inserts mod metadata into the database.
-func (r *Registry) saveModMetadata(w http.ResponseWriter, manifest *mod.Manifest, wasmData []byte, checksum string) error {
+func (r *Registry) saveModMetadata(w http.ResponseWriter, upload *modUpload, checksum string) error {
+	manifest := upload.manifest
 	tagsJSON, _ := json.Marshal(manifest.Tags)
+	var signature sql.NullString
+	if upload.signature != nil {
+		sigJSON, _ := json.Marshal(upload.signature)
+		signature = sql.NullString{String: string(sigJSON), Valid: true}
+	}
 	_, err := r.db.Exec(`
-		INSERT OR REPLACE INTO mods (name, version, author, description, tags, sha256, size, uploaded_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, manifest.Name, manifest.Version, manifest.Author, manifest.Description, string(tagsJSON), checksum, len(wasmData), time.Now())
+		INSERT OR REPLACE INTO mods (name, version, author, description, tags, sha256, size, uploaded_at, signature)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, manifest.Name, manifest.Version, manifest.Author, manifest.Description, string(tagsJSON), checksum, len(upload.wasmData), time.Now(), signature)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to insert mod record")
-		modPath := filepath.Join(r.storagePath, fmt.Sprintf("%s-%s.wasm", manifest.Name, manifest.Version))
-		os.Remove(modPath)
+		os.Remove(r.modFilePath(manifest.Name, manifest.Version, ".wasm"))
+		os.Remove(r.modFilePath(manifest.Name, manifest.Version, ".json"))
 		http.Error(w, "Database error", http.StatusInternalServerError)
 		return err
 	}
@@ -232,13 +322,19 @@ func (r *Registry) saveModMetadata(w http.ResponseWriter, manifest *mod.Manifest
 		"mod_name":    manifest.Name,
 		"version":     manifest.Version,
 		"author":      manifest.Author,
-		"size":        len(wasmData),
+		"size":        len(upload.wasmData),
 		"sha256":      checksum,
+		"signed":      upload.signature != nil,
 	}).Info("Mod uploaded successfully")
 
 	return nil
 }
 
+// modFilePath returns where a stored file of a mod version is kept.
+func (r *Registry) modFilePath(name, version, ext string) string {
+	return filepath.Join(r.storagePath, fmt.Sprintf("%s-%s%s", name, version, ext))
+}
+
 // sendUploadSuccess sends a successful upload response.
 func (r *Registry) sendUploadSuccess(w http.ResponseWriter, manifest *mod.Manifest, checksum string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -251,7 +347,9 @@ func (r *Registry) sendUploadSuccess(w http.ResponseWriter, manifest *mod.Manife
 	})
 }
 
-// HandleSearch processes mod search requests with filtering.
+// HandleSearch processes mod search requests with filtering. Results are
+// the newest uploads, or with ?sort=rating or ?sort=downloads the best
+// rated or most downloaded mods.
 func (r *Registry) HandleSearch(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -263,34 +361,43 @@ func (r *Registry) HandleSearch(w http.ResponseWriter, req *http.Request) {
 	author := query.Get("author")
 	tag := query.Get("tag")
 
-	// Build SQL query dynamically
-	var conditions []string
+	// Build SQL query dynamically, leaving out mods that were taken down
+	conditions := []string{"m.name NOT IN (SELECT name FROM blocked_mods)"}
 	var args []interface{}
 
 	if name != "" {
-		conditions = append(conditions, "name LIKE ?")
+		conditions = append(conditions, "m.name LIKE ?")
 		args = append(args, "%"+name+"%")
 	}
 	if author != "" {
-		conditions = append(conditions, "author = ?")
+		conditions = append(conditions, "m.author = ?")
 		args = append(args, author)
 	}
 	if tag != "" {
-		conditions = append(conditions, "tags LIKE ?")
+		conditions = append(conditions, "m.tags LIKE ?")
 		args = append(args, "%"+tag+"%")
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
+	orderBy := "m.uploaded_at DESC"
+	switch query.Get("sort") {
+	case "rating":
+		orderBy = "rating DESC, ratings DESC, m.uploaded_at DESC"
+	case "downloads":
+		orderBy = "total_downloads DESC, m.uploaded_at DESC"
 	}
 
 	sqlQuery := fmt.Sprintf(`
-		SELECT name, version, author, description, tags, sha256, size, uploaded_at, downloads
-		FROM mods %s
-		ORDER BY uploaded_at DESC
+		SELECT m.name, m.version, m.author, m.description, m.tags, m.sha256, m.size, m.uploaded_at, m.downloads, m.signature,
+			t.total AS total_downloads, COALESCE(rt.average, 0) AS rating, COALESCE(rt.count, 0) AS ratings
+		FROM mods m
+		JOIN (SELECT name, SUM(downloads) AS total FROM mods GROUP BY name) t ON t.name = m.name
+		LEFT JOIN (SELECT name, AVG(stars) AS average, COUNT(*) AS count FROM ratings GROUP BY name) rt ON rt.name = m.name
+		%s
+		ORDER BY %s
 		LIMIT 50
-	`, whereClause)
+	`, whereClause, orderBy)
 
 	rows, err := r.db.Query(sqlQuery, args...)
 	if err != nil {
@@ -304,11 +411,16 @@ func (r *Registry) HandleSearch(w http.ResponseWriter, req *http.Request) {
 	for rows.Next() {
 		var rec ModRecord
 		var tagsJSON string
-		err := rows.Scan(&rec.Name, &rec.Version, &rec.Author, &rec.Description, &tagsJSON, &rec.SHA256, &rec.Size, &rec.UploadedAt, &rec.Downloads)
+		var signature sql.NullString
+		err := rows.Scan(&rec.Name, &rec.Version, &rec.Author, &rec.Description, &tagsJSON, &rec.SHA256, &rec.Size, &rec.UploadedAt, &rec.Downloads, &signature,
+			&rec.TotalDownloads, &rec.Rating, &rec.Ratings)
 		if err != nil {
 			continue
 		}
 		json.Unmarshal([]byte(tagsJSON), &rec.Tags)
+		if signature.Valid {
+			json.Unmarshal([]byte(signature.String), &rec.Signature)
+		}
 		results = append(results, rec)
 	}
 
@@ -336,6 +448,15 @@ func (r *Registry) HandleDownload(w http.ResponseWriter, req *http.Request) {
 
 	name, version := parts[0], parts[1]
 
+	if blocked, err := r.isBlockedMod(name); err != nil {
+		logrus.WithError(err).Error("Database query failed")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if blocked {
+		http.Error(w, "Mod was taken down", http.StatusGone)
+		return
+	}
+
 	// Verify mod exists in database
 	var sha256 string
 	err := r.db.QueryRow("SELECT sha256 FROM mods WHERE name = ? AND version = ?", name, version).Scan(&sha256)
@@ -355,7 +476,7 @@ func (r *Registry) HandleDownload(w http.ResponseWriter, req *http.Request) {
 	}
 
 	// Serve WASM file
-	modPath := filepath.Join(r.storagePath, fmt.Sprintf("%s-%s.wasm", name, version))
+	modPath := r.modFilePath(name, version, ".wasm")
 	if _, err := os.Stat(modPath); os.IsNotExist(err) {
 		http.Error(w, "WASM file not found", http.StatusNotFound)
 		return
@@ -372,6 +493,46 @@ func (r *Registry) HandleDownload(w http.ResponseWriter, req *http.Request) {
 	}).Debug("Mod downloaded")
 }
 
+// HandleManifest serves the manifest a mod version was uploaded with, which
+// clients need alongside the WASM module to install it.
+func (r *Registry) HandleManifest(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/manifest/")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 {
+		http.Error(w, "Invalid manifest path (expected /manifest/{name}/{version})", http.StatusBadRequest)
+		return
+	}
+	name, version := parts[0], parts[1]
+
+	if blocked, err := r.isBlockedMod(name); err != nil {
+		logrus.WithError(err).Error("Database query failed")
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	} else if blocked {
+		http.Error(w, "Mod was taken down", http.StatusGone)
+		return
+	}
+
+	// Mods uploaded before manifests were kept have none to serve
+	data, err := os.ReadFile(r.modFilePath(name, version, ".json"))
+	if os.IsNotExist(err) {
+		http.Error(w, "Manifest not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("Failed to read manifest file")
+		http.Error(w, "Storage error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
 // validateWASM performs basic WASM magic number validation.
 func validateWASM(data []byte) error {
 	if len(data) < 8 {
@@ -41,6 +41,17 @@ func setupTestRegistry(t *testing.T) (*Registry, func()) {
 	return reg, cleanup
 }
 
+// authorize adds a token for author's account to req, creating the
+// account if needed.
+func authorize(t *testing.T, reg *Registry, req *http.Request, author string) {
+	t.Helper()
+	token, err := reg.IssueToken(author)
+	if err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+}
+
 func createValidWASM() []byte {
 	// WASM magic number (0x00 0x61 0x73 0x6D) + version 1 (0x01 0x00 0x00 0x00)
 	wasm := []byte{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00}
@@ -337,6 +348,7 @@ func TestHandleUpload(t *testing.T) {
 			defer cleanup()
 
 			req := tt.setupRequest(t)
+			authorize(t, reg, req, "test-author")
 			w := httptest.NewRecorder()
 
 			reg.HandleUpload(w, req)
@@ -568,6 +580,7 @@ func TestSetMaxModSize(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/upload", body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	authorize(t, reg, req, "test-author")
 	w := httptest.NewRecorder()
 
 	reg.HandleUpload(w, req)
@@ -594,6 +607,7 @@ func uploadTestMod(t *testing.T, reg *Registry, manifest mod.Manifest) {
 
 	req := httptest.NewRequest(http.MethodPost, "/upload", body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	authorize(t, reg, req, manifest.Author)
 	w := httptest.NewRecorder()
 
 	reg.HandleUpload(w, req)
@@ -671,8 +685,10 @@ func TestHandleUploadCleanupOnError(t *testing.T) {
 	reg, cleanup := setupTestRegistry(t)
 	defer cleanup()
 
-	// Close database to force error during INSERT
-	reg.db.Close()
+	// Make the INSERT fail after the files are stored
+	if _, err := reg.db.Exec(`CREATE TRIGGER fail_insert BEFORE INSERT ON mods BEGIN SELECT RAISE(ABORT, 'forced failure'); END`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
 
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
@@ -689,6 +705,7 @@ func TestHandleUploadCleanupOnError(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/upload", body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	authorize(t, reg, req, "test-author")
 	w := httptest.NewRecorder()
 
 	reg.HandleUpload(w, req)
@@ -703,6 +720,10 @@ func TestHandleUploadCleanupOnError(t *testing.T) {
 	if _, err := os.Stat(modPath); !os.IsNotExist(err) {
 		t.Errorf("Expected WASM file to be cleaned up on error")
 	}
+	manifestPath := filepath.Join(reg.storagePath, "test-mod-1.0.0.json")
+	if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+		t.Errorf("Expected manifest file to be cleaned up on error")
+	}
 }
 
 func TestHandleUploadInvalidJSON(t *testing.T) {
@@ -722,6 +743,7 @@ func TestHandleUploadInvalidJSON(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/upload", body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	authorize(t, reg, req, "test-author")
 	w := httptest.NewRecorder()
 
 	reg.HandleUpload(w, req)
@@ -751,6 +773,7 @@ func TestHandleUploadReadError(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/upload", body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	authorize(t, reg, req, "test-author")
 	w := httptest.NewRecorder()
 
 	reg.HandleUpload(w, req)
@@ -760,3 +783,32 @@ func TestHandleUploadReadError(t *testing.T) {
 		t.Errorf("Expected status %d for invalid WASM, got %d", http.StatusBadRequest, w.Code)
 	}
 }
+
+func TestHandleManifest(t *testing.T) {
+	reg, cleanup := setupTestRegistry(t)
+	defer cleanup()
+
+	manifest := createValidManifest()
+	uploadTestMod(t, reg, manifest)
+
+	req := httptest.NewRequest(http.MethodGet, "/manifest/test-mod/1.0.0", nil)
+	w := httptest.NewRecorder()
+	reg.HandleManifest(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var served mod.Manifest
+	if err := json.NewDecoder(w.Body).Decode(&served); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	if served.Name != manifest.Name || served.Author != manifest.Author {
+		t.Errorf("Served manifest = %+v", served)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/manifest/test-mod/9.9.9", nil)
+	w = httptest.NewRecorder()
+	reg.HandleManifest(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown version, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -1,12 +1,31 @@
 package mod
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
 )
 
+var (
+	// ErrMissingDependency is returned for a mod whose required dependency
+	// isn't installed and enabled, or can't load itself.
+	ErrMissingDependency = errors.New("missing dependency")
+
+	// ErrIncompatibleDependency is returned for a mod whose required
+	// dependency has a version outside its constraint.
+	ErrIncompatibleDependency = errors.New("incompatible dependency")
+
+	// ErrModConflict is returned for a mod that conflicts with another
+	// enabled mod.
+	ErrModConflict = errors.New("mod conflict")
+
+	// ErrDependencyCycle is returned for mods that depend on each other in
+	// a cycle.
+	ErrDependencyCycle = errors.New("dependency cycle")
+)
+
 // Resolver handles dependency resolution and version constraints.
 type Resolver struct {
 	available map[string][]string // map[modName][]versions
@@ -25,28 +44,18 @@ func (r *Resolver) AddAvailable(name string, versions []string) {
 }
 
 // Resolve computes installation order for a mod and its dependencies.
-// Returns ordered list of (name, version) tuples or error if unresolvable.
+// Returns ordered list of (name, version) tuples, dependencies before
+// dependents, or error if unresolvable.
 func (r *Resolver) Resolve(root *Manifest) ([]ModVersion, error) {
 	resolved := make(map[string]string) // map[modName]selectedVersion
 	visiting := make(map[string]bool)   // cycle detection
 	visited := make(map[string]bool)    // completion tracking
+	var order []ModVersion              // post-order, so dependencies first
 
-	if err := r.visit(root.Name, root.Version, root, resolved, visiting, visited); err != nil {
+	if err := r.visit(root.Name, root.Version, root, resolved, visiting, visited, &order); err != nil {
 		return nil, err
 	}
-
-	// Convert to ordered list
-	result := make([]ModVersion, 0, len(resolved))
-	for name, version := range resolved {
-		result = append(result, ModVersion{Name: name, Version: version})
-	}
-
-	// Topological sort (dependencies before dependents)
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Name < result[j].Name
-	})
-
-	return result, nil
+	return order, nil
 }
 
 // ModVersion represents a resolved mod with specific version.
@@ -56,7 +65,7 @@ type ModVersion struct {
 }
 
 // visit performs DFS to resolve dependencies.
-func (r *Resolver) visit(name, version string, manifest *Manifest, resolved map[string]string, visiting, visited map[string]bool) error {
+func (r *Resolver) visit(name, version string, manifest *Manifest, resolved map[string]string, visiting, visited map[string]bool, order *[]ModVersion) error {
 	if visiting[name] {
 		return fmt.Errorf("circular dependency detected: %s", name)
 	}
@@ -90,13 +99,14 @@ func (r *Resolver) visit(name, version string, manifest *Manifest, resolved map[
 			Dependencies: nil, // Would load actual deps from registry
 		}
 
-		if err := r.visit(dep.Name, selectedVersion, depManifest, resolved, visiting, visited); err != nil {
+		if err := r.visit(dep.Name, selectedVersion, depManifest, resolved, visiting, visited, order); err != nil {
 			return err
 		}
 	}
 
 	resolved[name] = version
 	visited[name] = true
+	*order = append(*order, ModVersion{Name: name, Version: version})
 	return nil
 }
 
@@ -289,71 +299,168 @@ func CheckConflicts(manifest *Manifest, installed []string) error {
 	return nil
 }
 
-// SortTopological orders mods by dependency (dependencies before dependents).
+// SortTopological orders mods by dependency (dependencies before
+// dependents), breaking ties by name so the order is stable. Optional
+// dependencies order the mods too when both are present; dependencies on
+// mods that aren't in the list are ignored.
 func SortTopological(manifests []*Manifest) ([]*Manifest, error) {
-	graph, inDegree, modMap := buildDependencyGraph(manifests)
-	queue := initializeQueue(inDegree)
-	result := processTopologicalSort(queue, graph, inDegree, modMap)
-
-	if len(result) != len(manifests) {
-		return nil, fmt.Errorf("circular dependency detected")
+	ordered, cyclic := orderByDependencies(manifests)
+	if len(cyclic) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(manifestNames(cyclic), ", "))
 	}
+	return ordered, nil
+}
 
-	return result, nil
+// ResolveLoadOrder works out which of a set of enabled mods can load and
+// in what order. A mod can't load if a dependency it requires is missing,
+// has a version outside its constraint or can't load itself, if it
+// conflicts with another mod in the set, or if it is part of or depends on
+// a dependency cycle. The mods that can load are returned in dependency
+// order; the rest are mapped by name to the reason they can't.
+func ResolveLoadOrder(manifests []*Manifest) ([]*Manifest, map[string]error) {
+	return resolveLoadOrder(manifests, make(map[string]error))
 }
 
-// buildDependencyGraph constructs the dependency graph and calculates in-degrees.
-func buildDependencyGraph(manifests []*Manifest) (map[string][]string, map[string]int, map[string]*Manifest) {
-	graph := make(map[string][]string)
-	inDegree := make(map[string]int)
-	modMap := make(map[string]*Manifest)
+// resolveLoadOrder is ResolveLoadOrder for a set of mods some of which
+// already can't load, for the reasons in failed.
+func resolveLoadOrder(manifests []*Manifest, failed map[string]error) ([]*Manifest, map[string]error) {
+	sorted := make([]*Manifest, len(manifests))
+	copy(sorted, manifests)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
 
-	for _, m := range manifests {
-		modMap[m.Name] = m
-		if _, exists := inDegree[m.Name]; !exists {
-			inDegree[m.Name] = 0
+	byName := make(map[string]*Manifest, len(sorted))
+	for _, m := range sorted {
+		byName[m.Name] = m
+	}
+	fail := func(name string, err error) {
+		if failed[name] == nil {
+			failed[name] = err
 		}
-		for _, dep := range m.Dependencies {
-			if !dep.Optional {
-				graph[dep.Name] = append(graph[dep.Name], m.Name)
-				inDegree[m.Name]++
+	}
+
+	// Both sides of a conflict are held back, whichever declared it, so
+	// neither depends on which was installed first
+	for _, m := range sorted {
+		for _, conflict := range m.Conflicts {
+			if _, ok := byName[conflict]; ok && conflict != m.Name {
+				fail(m.Name, fmt.Errorf("%w: conflicts with %s", ErrModConflict, conflict))
+				fail(conflict, fmt.Errorf("%w: conflicts with %s", ErrModConflict, m.Name))
 			}
 		}
 	}
 
-	return graph, inDegree, modMap
-}
+	// A failed mod fails the mods that require it, so repeat until settled
+	for changed := true; changed; {
+		changed = false
+		for _, m := range sorted {
+			if failed[m.Name] != nil {
+				continue
+			}
+			if err := checkDependencies(m, byName, failed); err != nil {
+				failed[m.Name] = err
+				changed = true
+			}
+		}
+	}
 
-// initializeQueue creates the initial queue with zero-dependency nodes.
-func initializeQueue(inDegree map[string]int) []string {
-	var queue []string
-	for name, degree := range inDegree {
-		if degree == 0 {
-			queue = append(queue, name)
+	loadable := make([]*Manifest, 0, len(sorted))
+	for _, m := range sorted {
+		if failed[m.Name] == nil {
+			loadable = append(loadable, m)
 		}
 	}
-	return queue
+	ordered, cyclic := orderByDependencies(loadable)
+	names := strings.Join(manifestNames(cyclic), ", ")
+	for _, m := range cyclic {
+		failed[m.Name] = fmt.Errorf("%w: %s", ErrDependencyCycle, names)
+	}
+	return ordered, failed
 }
 
-// processTopologicalSort performs Kahn's algorithm to topologically sort the graph.
-func processTopologicalSort(queue []string, graph map[string][]string, inDegree map[string]int, modMap map[string]*Manifest) []*Manifest {
-	var result []*Manifest
+// checkDependencies returns why a mod's required dependencies can't be
+// met, or nil if they can.
+func checkDependencies(m *Manifest, byName map[string]*Manifest, failed map[string]error) error {
+	for _, dep := range m.Dependencies {
+		if dep.Optional {
+			continue
+		}
+		found, ok := byName[dep.Name]
+		if !ok {
+			return fmt.Errorf("%w: requires %s %s, which is not installed or not enabled", ErrMissingDependency, dep.Name, dep.Version)
+		}
+		if !satisfies(found.Version, dep.Version) {
+			return fmt.Errorf("%w: requires %s %s, found %s", ErrIncompatibleDependency, dep.Name, dep.Version, found.Version)
+		}
+		if failed[dep.Name] != nil {
+			return fmt.Errorf("%w: requires %s, which can't load", ErrMissingDependency, dep.Name)
+		}
+	}
+	return nil
+}
 
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
+// orderByDependencies sorts mods so each comes after the mods in the list
+// it depends on, taking the first by name whenever several are ready. It
+// also returns the mods left over because they are in or depend on a
+// cycle.
+func orderByDependencies(manifests []*Manifest) (ordered, cyclic []*Manifest) {
+	byName := make(map[string]*Manifest, len(manifests))
+	for _, m := range manifests {
+		byName[m.Name] = m
+	}
+	dependents := make(map[string][]string)
+	pending := make(map[string]int)
+	for _, m := range manifests {
+		pending[m.Name] = 0
+	}
+	for _, m := range manifests {
+		for _, dep := range m.Dependencies {
+			if _, ok := byName[dep.Name]; ok && dep.Name != m.Name {
+				dependents[dep.Name] = append(dependents[dep.Name], m.Name)
+				pending[m.Name]++
+			}
+		}
+	}
 
-		if manifest, ok := modMap[current]; ok {
-			result = append(result, manifest)
+	var ready []string
+	for name, n := range pending {
+		if n == 0 {
+			ready = append(ready, name)
 		}
+	}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		current := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, byName[current])
+		for _, dependent := range dependents[current] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
 
-		for _, dependent := range graph[current] {
-			inDegree[dependent]--
-			if inDegree[dependent] == 0 {
-				queue = append(queue, dependent)
+	if len(ordered) < len(byName) {
+		done := make(map[string]bool, len(ordered))
+		for _, m := range ordered {
+			done[m.Name] = true
+		}
+		for _, m := range manifests {
+			if !done[m.Name] {
+				cyclic = append(cyclic, m)
+				done[m.Name] = true
 			}
 		}
+		sort.Slice(cyclic, func(i, j int) bool { return cyclic[i].Name < cyclic[j].Name })
 	}
+	return ordered, cyclic
+}
 
-	return result
+// manifestNames returns the names of manifests.
+func manifestNames(manifests []*Manifest) []string {
+	names := make([]string, len(manifests))
+	for i, m := range manifests {
+		names[i] = m.Name
+	}
+	return names
 }
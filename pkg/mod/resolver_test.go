@@ -1,6 +1,7 @@
 package mod
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -449,6 +450,74 @@ func TestSortTopological(t *testing.T) {
 	}
 }
 
+func TestResolveLoadOrder(t *testing.T) {
+	dep := func(name, version string) Dependency { return Dependency{Name: name, Version: version} }
+	manifests := []*Manifest{
+		{Name: "weapons-plus", Version: "1.2.0", Dependencies: []Dependency{dep("core-lib", "^2.0.0")}},
+		{Name: "core-lib", Version: "2.1.0"},
+		{Name: "addon", Version: "1.0.0", Dependencies: []Dependency{dep("weapons-plus", ">=1.0.0"), {Name: "extras", Version: "1.0.0", Optional: true}}},
+		{Name: "old-addon", Version: "1.0.0", Dependencies: []Dependency{dep("core-lib", "^1.0.0")}},
+		{Name: "needs-old", Version: "1.0.0", Dependencies: []Dependency{dep("old-addon", "1.0.0")}},
+		{Name: "orphan", Version: "1.0.0", Dependencies: []Dependency{dep("missing", "1.0.0")}},
+		{Name: "rival-a", Version: "1.0.0", Conflicts: []string{"rival-b"}},
+		{Name: "rival-b", Version: "1.0.0"},
+		{Name: "loop-a", Version: "1.0.0", Dependencies: []Dependency{dep("loop-b", "1.0.0")}},
+		{Name: "loop-b", Version: "1.0.0", Dependencies: []Dependency{dep("loop-a", "1.0.0")}},
+	}
+
+	order, failed := ResolveLoadOrder(manifests)
+
+	got := manifestNames(order)
+	want := []string{"core-lib", "weapons-plus", "addon"}
+	if len(got) != len(want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+
+	for name, want := range map[string]error{
+		"old-addon": ErrIncompatibleDependency,
+		"needs-old": ErrMissingDependency,
+		"orphan":    ErrMissingDependency,
+		"rival-a":   ErrModConflict,
+		"rival-b":   ErrModConflict,
+		"loop-a":    ErrDependencyCycle,
+		"loop-b":    ErrDependencyCycle,
+	} {
+		if !errors.Is(failed[name], want) {
+			t.Errorf("%s: err = %v, want %v", name, failed[name], want)
+		}
+	}
+	if len(failed) != 7 {
+		t.Errorf("failed = %v, want 7 mods", failed)
+	}
+}
+
+func TestSortTopological_StableOrder(t *testing.T) {
+	manifests := []*Manifest{
+		{Name: "zeta", Version: "1.0.0"},
+		{Name: "beta", Version: "1.0.0", Dependencies: []Dependency{{Name: "zeta", Version: "1.0.0"}}},
+		{Name: "alpha", Version: "1.0.0", Dependencies: []Dependency{{Name: "gone", Version: "1.0.0"}}},
+		{Name: "mid", Version: "1.0.0", Dependencies: []Dependency{{Name: "beta", Version: "1.0.0", Optional: true}}},
+	}
+	for i := 0; i < 10; i++ {
+		result, err := SortTopological(manifests)
+		if err != nil {
+			t.Fatalf("SortTopological failed: %v", err)
+		}
+		got := manifestNames(result)
+		want := []string{"alpha", "zeta", "beta", "mid"}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Fatalf("order = %v, want %v", got, want)
+			}
+		}
+	}
+}
+
 func TestResolverAddAvailable(t *testing.T) {
 	r := NewResolver()
 
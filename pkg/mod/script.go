@@ -0,0 +1,661 @@
+package mod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ErrScriptStopped is returned for a script that failed too often and
+// no longer runs.
+var ErrScriptStopped = errors.New("mod script stopped")
+
+// unsafeGlobals are the base library functions a sandboxed script may not
+// use: they load code or files, or reach outside the script's own state.
+var unsafeGlobals = []string{
+	"collectgarbage", "dofile", "getfenv", "load", "loadfile", "loadstring",
+	"module", "newproxy", "print", "require", "setfenv", "_printregs",
+}
+
+// ScriptLimits bounds the time and memory one mod's script may use. Each
+// mod gets its own Lua state, so one mod hitting its limits doesn't take
+// from another's.
+type ScriptLimits struct {
+	// InitTimeout is how long the script's main chunk may run when loaded.
+	InitTimeout time.Duration
+
+	// CallTimeout is how long each event handler call may run.
+	CallTimeout time.Duration
+
+	// CallStackSize caps nested Lua calls.
+	CallStackSize int
+
+	// RegistrySize and RegistryMaxSize are the initial and largest sizes
+	// of the Lua value stack, in slots.
+	RegistrySize    int
+	RegistryMaxSize int
+
+	// MaxStringLength caps the strings string.rep, string.gsub,
+	// string.format and table.concat may build.
+	MaxStringLength int
+
+	// MaxAllocBytes caps the memory one call, or the main chunk, may
+	// allocate; it is what bounds strings built with the .. operator.
+	MaxAllocBytes uint64
+
+	// MaxSpawnsPerCall caps the entities one call may spawn.
+	MaxSpawnsPerCall int
+
+	// MaxRegistrations caps the items, weapons and quests a mod registers.
+	MaxRegistrations int
+
+	// MaxFailures is how many errors, timeouts included, a script may
+	// raise before it is stopped.
+	MaxFailures int
+}
+
+// DefaultScriptLimits returns limits that leave a frame's worth of time
+// for the whole game with a handful of mods running.
+func DefaultScriptLimits() ScriptLimits {
+	return ScriptLimits{
+		InitTimeout:      250 * time.Millisecond,
+		CallTimeout:      5 * time.Millisecond,
+		CallStackSize:    200,
+		RegistrySize:     1024,
+		RegistryMaxSize:  64 * 1024,
+		MaxStringLength:  1 << 20,
+		MaxAllocBytes:    64 << 20,
+		MaxSpawnsPerCall: 16,
+		MaxRegistrations: 256,
+		MaxFailures:      5,
+	}
+}
+
+// ScriptItem is an item a mod script registered. It is added to the loot
+// table it names.
+type ScriptItem struct {
+	Mod      string
+	ID       string
+	Name     string
+	Table    string // Loot table ID, e.g. "crate" or an archetype ID
+	Chance   float64
+	MinDepth int
+}
+
+// ScriptWeapon is a weapon a mod script registered to replace the stock
+// weapon in a slot.
+type ScriptWeapon struct {
+	Mod         string
+	Slot        int
+	Name        string
+	Damage      float64
+	FireRate    float64 // Frames between shots (60 TPS)
+	AmmoType    string
+	ClipSize    int
+	Range       float64
+	ReloadTime  float64 // Frames to refill the magazine (60 TPS)
+	SpreadAngle float64
+	RayCount    int
+	Knockback   float64
+}
+
+// ScriptQuest is a bonus objective a mod script registered. Its ID is
+// prefixed with the mod name so it can't collide with built-in
+// objectives; the script advances it with game.progress_quest.
+type ScriptQuest struct {
+	Mod    string
+	ID     string
+	Desc   string
+	Target string
+	Count  int
+}
+
+// ScriptRuntime runs the Lua scripts of mods. Scripts see a sandbox with
+// only the base, table, string and math libraries and a game table:
+//
+//	game.mod                      the mod's name
+//	game.api_version              the mod API version, APIVersion
+//	game.has(capability)          whether the game has a capability
+//	game.log(msg)                 log a message
+//	game.notify(msg)              show a HUD message (ui_modify)
+//	game.spawn(kind, x, y)        spawn an entity, returning its ID (entity_spawn)
+//	game.on(event, fn)            call fn(params) on each game event
+//	game.register_item{...}       add an item to a loot table
+//	game.register_weapon{...}     replace the stock weapon in a slot
+//	game.register_quest{...}      add a bonus objective to each level
+//	game.progress_quest(id, n)    advance a registered objective
+//
+// Game calls into scripts happen on the caller's goroutine, under the
+// script's ScriptLimits.
+type ScriptRuntime struct {
+	limits  ScriptLimits
+	mu      sync.Mutex
+	scripts []*modScript // In load order
+
+	// OnQuestProgress is called when a script advances one of its
+	// objectives, with the objective's full ID. It runs inside the script
+	// call, so it must not call back into the runtime.
+	OnQuestProgress func(id string, amount int)
+}
+
+// modScript is the Lua state of one mod.
+type modScript struct {
+	name     string
+	major    int // Major mod API version the mod is written for
+	api      *ModAPI
+	state    *lua.LState
+	handlers map[string][]*lua.LFunction
+	failures int
+	stopped  bool
+	spawns   int // Entities spawned by the current call
+
+	items   []ScriptItem
+	weapons []ScriptWeapon
+	quests  []ScriptQuest
+}
+
+// NewScriptRuntime creates a script runtime that holds each mod's script
+// to the given limits.
+func NewScriptRuntime(limits ScriptLimits) *ScriptRuntime {
+	return &ScriptRuntime{limits: limits}
+}
+
+// Limits returns the limits scripts run under.
+func (r *ScriptRuntime) Limits() ScriptLimits {
+	return r.limits
+}
+
+// LoadScript runs the script named by the mod manifest's script field,
+// replacing any script the mod already had loaded. The api carries the
+// mod's permissions and game bindings.
+func (r *ScriptRuntime) LoadScript(m *Mod, api *ModAPI) error {
+	if m.Manifest == nil || m.Manifest.Script == "" {
+		return fmt.Errorf("mod %s has no script", getModName(m))
+	}
+	name := getModName(m)
+	path := filepath.Join(m.Path, filepath.Clean("/"+m.Manifest.Script))
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read script: %w", err)
+	}
+
+	s := &modScript{name: name, major: apiMajor(m.Manifest), api: api, handlers: make(map[string][]*lua.LFunction)}
+	s.state = r.newSandbox(s)
+	fn, err := s.state.Load(strings.NewReader(string(source)), m.Manifest.Script)
+	if err != nil {
+		s.state.Close()
+		return fmt.Errorf("failed to compile script: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call(s, fn, r.limits.InitTimeout); err != nil {
+		s.state.Close()
+		return fmt.Errorf("script %s failed: %w", m.Manifest.Script, err)
+	}
+	r.unload(name)
+	r.scripts = append(r.scripts, s)
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "mod_script",
+		"mod":         name,
+		"handlers":    len(s.handlers),
+		"content":     len(s.items) + len(s.weapons) + len(s.quests),
+	}).Info("Mod script loaded")
+	return nil
+}
+
+// Unload stops a mod's script and drops what it registered. It reports
+// whether the mod had a script loaded.
+func (r *ScriptRuntime) Unload(modName string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.unload(modName)
+}
+
+// unload closes a mod's script (must be called with lock held).
+func (r *ScriptRuntime) unload(modName string) bool {
+	for i, s := range r.scripts {
+		if s.name == modName {
+			s.state.Close()
+			r.scripts = append(r.scripts[:i], r.scripts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops every script.
+func (r *ScriptRuntime) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.scripts {
+		s.state.Close()
+	}
+	r.scripts = nil
+}
+
+// Loaded returns the names of the mods with a script loaded, in load
+// order.
+func (r *ScriptRuntime) Loaded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, len(r.scripts))
+	for i, s := range r.scripts {
+		names[i] = s.name
+	}
+	return names
+}
+
+// Dispatch calls every script's handlers for an event, in load order. A
+// failing script doesn't stop the others; the errors are joined.
+func (r *ScriptRuntime) Dispatch(event EventData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for _, s := range r.scripts {
+		if s.stopped || len(s.handlers[event.Type]) == 0 {
+			continue
+		}
+		params := toLuaTable(s.state, shimEventParams(s.major, event.Type, event.Params))
+		for _, fn := range s.handlers[event.Type] {
+			if err := r.call(s, fn, r.limits.CallTimeout, params); err != nil {
+				errs = append(errs, fmt.Errorf("mod %s: %s handler: %w", s.name, event.Type, err))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Items returns the items registered by every script, in load order.
+func (r *ScriptRuntime) Items() []ScriptItem {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []ScriptItem
+	for _, s := range r.scripts {
+		items = append(items, s.items...)
+	}
+	return items
+}
+
+// Weapons returns the weapons registered by every script, in load order,
+// so a later mod's weapon for a slot wins.
+func (r *ScriptRuntime) Weapons() []ScriptWeapon {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var weapons []ScriptWeapon
+	for _, s := range r.scripts {
+		weapons = append(weapons, s.weapons...)
+	}
+	return weapons
+}
+
+// Quests returns the objectives registered by every script, in load order.
+func (r *ScriptRuntime) Quests() []ScriptQuest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var quests []ScriptQuest
+	for _, s := range r.scripts {
+		quests = append(quests, s.quests...)
+	}
+	return quests
+}
+
+// call runs a Lua function under a time budget, counting failures against
+// the script and stopping it once it has failed too often (must be called
+// with lock held).
+func (r *ScriptRuntime) call(s *modScript, fn *lua.LFunction, timeout time.Duration, args ...lua.LValue) error {
+	if s.stopped {
+		return ErrScriptStopped
+	}
+	ctx := newDeadlineContext(timeout, r.limits.MaxAllocBytes)
+	s.state.SetContext(ctx)
+	defer s.state.RemoveContext()
+	s.spawns = 0
+
+	err := s.state.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}, args...)
+	if err == nil {
+		return nil
+	}
+	var apiErr *lua.ApiError
+	if errors.As(err, &apiErr) {
+		err = errors.New(apiErr.Object.String())
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		err = fmt.Errorf("exceeded %v time limit", timeout)
+	case errMemoryLimit:
+		err = fmt.Errorf("exceeded %d byte memory limit", r.limits.MaxAllocBytes)
+	}
+
+	s.failures++
+	if s.failures >= r.limits.MaxFailures {
+		s.stopped = true
+	}
+	logrus.WithFields(logrus.Fields{
+		"system_name": "mod_script",
+		"mod":         s.name,
+		"failures":    s.failures,
+		"stopped":     s.stopped,
+	}).WithError(err).Warn("Mod script error")
+	return err
+}
+
+// deadlineContext is a context that is done once its deadline passes or
+// more than its allocation limit has been allocated. It checks when asked
+// rather than waiting on a timer: the Lua VM polls Done between
+// instructions, and on single-threaded targets such as js/wasm a timer
+// goroutine never gets to run while a script spins. Only the goroutine
+// running the script may use it.
+type deadlineContext struct {
+	context.Context
+	deadline   time.Time
+	allocStart uint64
+	allocLimit uint64 // 0 for no limit
+	allocPoll  time.Time
+	err        error
+	done       chan struct{}
+}
+
+// newDeadlineContext creates a context that is done after timeout, or
+// once allocLimit bytes have been allocated if allocLimit isn't 0.
+func newDeadlineContext(timeout time.Duration, allocLimit uint64) *deadlineContext {
+	c := &deadlineContext{
+		Context:    context.Background(),
+		deadline:   time.Now().Add(timeout),
+		allocLimit: allocLimit,
+		done:       make(chan struct{}),
+	}
+	if allocLimit > 0 {
+		c.allocStart = allocatedBytes()
+		c.allocPoll = time.Now()
+	}
+	return c
+}
+
+// Deadline returns the context's deadline.
+func (c *deadlineContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+// Done returns a channel that is closed once the deadline has passed or
+// the allocation limit has been exceeded.
+func (c *deadlineContext) Done() <-chan struct{} {
+	if c.err != nil {
+		return c.done
+	}
+	now := time.Now()
+	switch {
+	case !now.Before(c.deadline):
+		c.err = context.DeadlineExceeded
+	case c.allocLimit > 0 && now.Sub(c.allocPoll) >= allocPollPeriod:
+		c.allocPoll = now
+		if allocatedBytes()-c.allocStart > c.allocLimit {
+			c.err = errMemoryLimit
+		}
+	}
+	if c.err != nil {
+		close(c.done)
+	}
+	return c.done
+}
+
+// Err returns context.DeadlineExceeded once the deadline has passed, or
+// errMemoryLimit once the allocation limit has been exceeded.
+func (c *deadlineContext) Err() error {
+	c.Done()
+	return c.err
+}
+
+// newSandbox creates a Lua state with only the safe libraries and the
+// game API open.
+func (r *ScriptRuntime) newSandbox(s *modScript) *lua.LState {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   r.limits.CallStackSize,
+		RegistrySize:    r.limits.RegistrySize,
+		RegistryMaxSize: r.limits.RegistryMaxSize,
+	})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range unsafeGlobals {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	r.limitStringLib(L)
+
+	game := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"log":             s.luaLog,
+		"notify":          s.luaNotify,
+		"spawn":           func(L *lua.LState) int { return s.luaSpawn(L, r.limits.MaxSpawnsPerCall) },
+		"on":              s.luaOn,
+		"register_item":   func(L *lua.LState) int { return s.luaRegisterItem(L, r.limits.MaxRegistrations) },
+		"register_weapon": func(L *lua.LState) int { return s.luaRegisterWeapon(L, r.limits.MaxRegistrations) },
+		"register_quest":  func(L *lua.LState) int { return s.luaRegisterQuest(L, r.limits.MaxRegistrations) },
+		"progress_quest":  func(L *lua.LState) int { return s.luaProgressQuest(L, r.OnQuestProgress) },
+	})
+	game.RawSetString("mod", lua.LString(s.name))
+	game.RawSetString("api_version", lua.LString(APIVersion))
+	game.RawSetString("has", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LBool(HasCapability(Capability(L.CheckString(1)))))
+		return 1
+	}))
+	L.SetGlobal("game", game)
+	return L
+}
+
+// luaLog implements game.log(msg).
+func (s *modScript) luaLog(L *lua.LState) int {
+	logrus.WithFields(logrus.Fields{
+		"system_name": "mod_script",
+		"mod":         s.name,
+	}).Info(L.CheckString(1))
+	return 0
+}
+
+// luaNotify implements game.notify(msg).
+func (s *modScript) luaNotify(L *lua.LState) int {
+	if err := s.api.ShowNotification(L.CheckString(1)); err != nil {
+		L.RaiseError("%v", err)
+	}
+	return 0
+}
+
+// luaSpawn implements game.spawn(kind, x, y).
+func (s *modScript) luaSpawn(L *lua.LState, limit int) int {
+	kind := L.CheckString(1)
+	x := float64(L.CheckNumber(2))
+	y := float64(L.CheckNumber(3))
+	if s.spawns >= limit {
+		L.RaiseError("spawn limit of %d per call reached", limit)
+	}
+	id, err := s.api.SpawnEntity(kind, x, y)
+	if err != nil {
+		L.RaiseError("%v", err)
+	}
+	s.spawns++
+	L.Push(lua.LNumber(id))
+	return 1
+}
+
+// luaOn implements game.on(event, fn).
+func (s *modScript) luaOn(L *lua.LState) int {
+	event := L.CheckString(1)
+	fn := L.CheckFunction(2)
+	s.handlers[event] = append(s.handlers[event], fn)
+	return 0
+}
+
+// luaRegisterItem implements game.register_item{id, name, table, chance,
+// min_depth}.
+func (s *modScript) luaRegisterItem(L *lua.LState, limit int) int {
+	t := L.CheckTable(1)
+	s.checkRegistrations(L, limit)
+	item := ScriptItem{
+		Mod:      s.name,
+		ID:       requiredField(L, t, "id"),
+		Name:     requiredField(L, t, "name"),
+		Table:    stringField(t, "table", "crate"),
+		Chance:   numberField(t, "chance", 0.1),
+		MinDepth: int(numberField(t, "min_depth", 0)),
+	}
+	if item.Chance <= 0 || item.Chance > 1 {
+		L.ArgError(1, "chance must be in (0, 1]")
+	}
+	s.items = append(s.items, item)
+	return 0
+}
+
+// luaRegisterWeapon implements game.register_weapon{slot, name, damage,
+// ...}.
+func (s *modScript) luaRegisterWeapon(L *lua.LState, limit int) int {
+	t := L.CheckTable(1)
+	s.checkRegistrations(L, limit)
+	w := ScriptWeapon{
+		Mod:         s.name,
+		Slot:        int(numberField(t, "slot", -1)),
+		Name:        requiredField(L, t, "name"),
+		Damage:      numberField(t, "damage", 10),
+		FireRate:    numberField(t, "fire_rate", 15),
+		AmmoType:    stringField(t, "ammo", ""),
+		ClipSize:    int(numberField(t, "clip", 0)),
+		Range:       numberField(t, "range", 100),
+		ReloadTime:  numberField(t, "reload", 60),
+		SpreadAngle: numberField(t, "spread", 0),
+		RayCount:    int(numberField(t, "rays", 1)),
+		Knockback:   numberField(t, "knockback", 1),
+	}
+	if w.Slot < 0 {
+		L.ArgError(1, "slot is required")
+	}
+	if w.Damage < 0 || w.FireRate <= 0 || w.RayCount < 1 {
+		L.ArgError(1, "damage, fire_rate and rays must be positive")
+	}
+	s.weapons = append(s.weapons, w)
+	return 0
+}
+
+// luaRegisterQuest implements game.register_quest{id, desc, target,
+// count}.
+func (s *modScript) luaRegisterQuest(L *lua.LState, limit int) int {
+	t := L.CheckTable(1)
+	s.checkRegistrations(L, limit)
+	q := ScriptQuest{
+		Mod:    s.name,
+		ID:     s.name + ":" + requiredField(L, t, "id"),
+		Desc:   requiredField(L, t, "desc"),
+		Target: stringField(t, "target", ""),
+		Count:  int(numberField(t, "count", 1)),
+	}
+	if q.Count < 1 {
+		L.ArgError(1, "count must be positive")
+	}
+	s.quests = append(s.quests, q)
+	return 0
+}
+
+// luaProgressQuest implements game.progress_quest(id, n). Scripts may only
+// advance their own objectives.
+func (s *modScript) luaProgressQuest(L *lua.LState, progress func(id string, amount int)) int {
+	id := s.name + ":" + L.CheckString(1)
+	amount := L.OptInt(2, 1)
+	for _, q := range s.quests {
+		if q.ID == id {
+			if progress != nil {
+				progress(id, amount)
+			}
+			return 0
+		}
+	}
+	L.ArgError(1, "unknown quest")
+	return 0
+}
+
+// checkRegistrations raises an error once the script has registered as
+// much content as it may.
+func (s *modScript) checkRegistrations(L *lua.LState, limit int) {
+	if len(s.items)+len(s.weapons)+len(s.quests) >= limit {
+		L.RaiseError("registration limit of %d reached", limit)
+	}
+}
+
+// requiredField returns a non-empty string field of a table, raising an
+// error if it is missing.
+func requiredField(L *lua.LState, t *lua.LTable, key string) string {
+	v, ok := t.RawGetString(key).(lua.LString)
+	if !ok || v == "" {
+		L.ArgError(1, key+" is required")
+	}
+	if len(v) > 128 {
+		L.ArgError(1, key+" is too long")
+	}
+	return string(v)
+}
+
+// stringField returns a string field of a table, or def if it isn't set.
+func stringField(t *lua.LTable, key, def string) string {
+	if v, ok := t.RawGetString(key).(lua.LString); ok {
+		return string(v)
+	}
+	return def
+}
+
+// numberField returns a number field of a table, or def if it isn't set.
+func numberField(t *lua.LTable, key string, def float64) float64 {
+	if v, ok := t.RawGetString(key).(lua.LNumber); ok {
+		return float64(v)
+	}
+	return def
+}
+
+// toLuaTable converts event parameters to a Lua table. Values that aren't
+// strings, numbers or booleans are passed as their string form.
+func toLuaTable(L *lua.LState, params map[string]interface{}) *lua.LTable {
+	t := L.NewTable()
+	for k, v := range params {
+		var lv lua.LValue
+		switch v := v.(type) {
+		case string:
+			lv = lua.LString(v)
+		case bool:
+			lv = lua.LBool(v)
+		case int:
+			lv = lua.LNumber(v)
+		case int64:
+			lv = lua.LNumber(v)
+		case uint64:
+			lv = lua.LNumber(v)
+		case float32:
+			lv = lua.LNumber(v)
+		case float64:
+			lv = lua.LNumber(v)
+		case nil:
+			continue
+		default:
+			lv = lua.LString(fmt.Sprint(v))
+		}
+		t.RawSetString(k, lv)
+	}
+	return t
+}
@@ -0,0 +1,285 @@
+package mod
+
+import (
+	"errors"
+	"fmt"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/pm"
+)
+
+// errMemoryLimit is the error of a script call that allocated more than
+// MaxAllocBytes.
+var errMemoryLimit = errors.New("memory limit exceeded")
+
+// allocPollPeriod is how often a call's allocations are checked. Reading
+// them costs several times the clock check made on each VM instruction;
+// checking by time rather than by instruction count still catches a
+// string that doubles with every .. long before it gets large.
+const allocPollPeriod = 50 * time.Microsecond
+
+// allocatedBytes returns the bytes the process has allocated so far.
+// Script calls run on the game loop, which waits for them, so what is
+// allocated during a call is nearly all the script's.
+func allocatedBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// limitStringLib replaces the string and table functions that build a
+// string in one Go call with versions held to MaxStringLength. The VM only
+// checks a call's deadline and allocations between instructions, so
+// without this one string.gsub or table.concat could run for seconds or
+// exhaust memory.
+func (r *ScriptRuntime) limitStringLib(L *lua.LState) {
+	str := L.GetGlobal(lua.StringLibName).(*lua.LTable)
+	format := str.RawGetString("format").(*lua.LFunction).GFunction
+	str.RawSetString("dump", lua.LNil)
+	str.RawSetString("rep", L.NewFunction(r.luaRep))
+	str.RawSetString("gsub", L.NewFunction(r.luaGsub))
+	str.RawSetString("format", L.NewFunction(func(L *lua.LState) int {
+		r.checkFormat(L)
+		return format(L)
+	}))
+
+	tbl := L.GetGlobal(lua.TabLibName).(*lua.LTable)
+	tbl.RawSetString("concat", L.NewFunction(r.luaConcat))
+}
+
+// raiseTooLong raises the error for a string longer than MaxStringLength.
+func (r *ScriptRuntime) raiseTooLong(L *lua.LState, fn string) {
+	L.RaiseError("%s result longer than %d bytes", fn, r.limits.MaxStringLength)
+}
+
+// luaRep implements string.rep(s, n).
+func (r *ScriptRuntime) luaRep(L *lua.LState) int {
+	piece := L.CheckString(1)
+	n := L.CheckInt(2)
+	if n <= 0 || piece == "" {
+		L.Push(lua.LString(""))
+		return 1
+	}
+	if n > r.limits.MaxStringLength/len(piece) {
+		r.raiseTooLong(L, "string.rep")
+	}
+	L.Push(lua.LString(strings.Repeat(piece, n)))
+	return 1
+}
+
+// luaConcat implements table.concat(t, sep, i, j).
+func (r *ScriptRuntime) luaConcat(L *lua.LState) int {
+	tbl := L.CheckTable(1)
+	sep := L.OptString(2, "")
+	i := L.OptInt(3, 1)
+	j := L.OptInt(4, tbl.Len())
+
+	var b strings.Builder
+	for k := i; k <= j; k++ {
+		v := tbl.RawGetInt(k)
+		if !lua.LVCanConvToString(v) {
+			L.RaiseError("invalid value (%s) at index %d in table for concat", v.Type().String(), k)
+		}
+		if k > i {
+			b.WriteString(sep)
+		}
+		b.WriteString(lua.LVAsString(v))
+		if b.Len() > r.limits.MaxStringLength {
+			r.raiseTooLong(L, "table.concat")
+		}
+	}
+	L.Push(lua.LString(b.String()))
+	return 1
+}
+
+// checkFormat raises an error before string.format builds a result longer
+// than MaxStringLength. As in Lua, widths and precisions are at most two
+// digits, which bounds what each directive adds to its argument.
+func (r *ScriptRuntime) checkFormat(L *lua.LState) {
+	format := L.CheckString(1)
+	size := len(format)
+	arg := 2
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("-+ #0", format[i]) >= 0 {
+			i++
+		}
+		if digits := countDigits(format[i:]); digits > 2 {
+			L.RaiseError("invalid format (width or precision too long)")
+		} else {
+			i += digits
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			if digits := countDigits(format[i:]); digits > 2 {
+				L.RaiseError("invalid format (width or precision too long)")
+			} else {
+				i += digits
+			}
+		}
+
+		// Numbers take at most a few hundred bytes; quoting a string can
+		// escape each byte as \xNN
+		size += 512
+		if s, ok := L.Get(arg).(lua.LString); ok {
+			if i < len(format) && format[i] == 'q' {
+				size += 4 * len(s)
+			} else {
+				size += len(s)
+			}
+		}
+		arg++
+	}
+	if size > r.limits.MaxStringLength {
+		r.raiseTooLong(L, "string.format")
+	}
+}
+
+// countDigits returns how many decimal digits s starts with.
+func countDigits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// luaGsub implements string.gsub(s, pattern, repl, n). It finds one match
+// at a time, so it stops at the call's deadline and once its result grows
+// past MaxStringLength rather than building the whole result first.
+func (r *ScriptRuntime) luaGsub(L *lua.LState) int {
+	src := L.CheckString(1)
+	pattern := L.CheckString(2)
+	L.CheckTypes(3, lua.LTString, lua.LTTable, lua.LTFunction)
+	repl := L.Get(3)
+	limit := L.OptInt(4, -1)
+
+	srcBytes := []byte(src)
+	var b strings.Builder
+	count, last := 0, 0
+	for pos := 0; pos <= len(src) && count != limit; {
+		if ctx := L.Context(); ctx != nil && ctx.Err() != nil {
+			L.RaiseError("%v", ctx.Err())
+		}
+		matches, err := pm.Find(pattern, srcBytes, pos, 1)
+		if err != nil {
+			L.RaiseError("%s", err.Error())
+		}
+		if len(matches) == 0 {
+			break
+		}
+		m := matches[0]
+		start, end := m.Capture(0), m.Capture(1)
+		b.WriteString(src[last:start])
+		r.gsubReplace(L, &b, src, m, repl)
+		last = end
+		count++
+		if b.Len() > r.limits.MaxStringLength {
+			r.raiseTooLong(L, "string.gsub")
+		}
+
+		// Only the first position is tried for an anchored pattern
+		if strings.HasPrefix(pattern, "^") {
+			break
+		}
+		pos = max(start+1, end)
+	}
+	b.WriteString(src[last:])
+	if b.Len() > r.limits.MaxStringLength {
+		r.raiseTooLong(L, "string.gsub")
+	}
+	L.Push(lua.LString(b.String()))
+	L.Push(lua.LNumber(count))
+	return 2
+}
+
+// gsubReplace writes the replacement for one gsub match.
+func (r *ScriptRuntime) gsubReplace(L *lua.LState, b *strings.Builder, src string, m *pm.MatchData, repl lua.LValue) {
+	start, end := m.Capture(0), m.Capture(1)
+	switch repl := repl.(type) {
+	case lua.LString:
+		for rest := string(repl); rest != ""; {
+			i := strings.IndexByte(rest, '%')
+			if i < 0 || i+1 == len(rest) {
+				b.WriteString(rest)
+				break
+			}
+			b.WriteString(rest[:i])
+			if c := rest[i+1]; c >= '0' && c <= '9' {
+				b.WriteString(gsubCapture(L, src, m, 2*int(c-'0')))
+			} else {
+				b.WriteByte(c)
+			}
+			if b.Len() > r.limits.MaxStringLength {
+				r.raiseTooLong(L, "string.gsub")
+			}
+			rest = rest[i+2:]
+		}
+		return
+	case *lua.LTable:
+		idx := 0
+		if m.CaptureLength() > 2 {
+			idx = 2
+		}
+		var value lua.LValue
+		if m.IsPosCapture(idx) {
+			value = L.GetTable(repl, lua.LNumber(m.Capture(idx)))
+		} else {
+			value = L.GetField(repl, src[m.Capture(idx):m.Capture(idx+1)])
+		}
+		if !lua.LVIsFalse(value) {
+			b.WriteString(lua.LVAsString(value))
+			return
+		}
+	case *lua.LFunction:
+		L.Push(repl)
+		nargs := 0
+		for i := 2; i < m.CaptureLength(); i += 2 {
+			if m.IsPosCapture(i) {
+				L.Push(lua.LNumber(m.Capture(i)))
+			} else {
+				L.Push(lua.LString(gsubCapture(L, src, m, i)))
+			}
+			nargs++
+		}
+		if nargs == 0 {
+			L.Push(lua.LString(src[start:end]))
+			nargs++
+		}
+		L.Call(nargs, 1)
+		value := L.Get(-1)
+		L.Pop(1)
+		if !lua.LVIsFalse(value) {
+			b.WriteString(lua.LVAsString(value))
+			return
+		}
+	}
+	b.WriteString(src[start:end])
+}
+
+// gsubCapture returns a match's capture at index idx of its capture
+// positions; %1 of a pattern without captures is the whole match.
+func gsubCapture(L *lua.LState, src string, m *pm.MatchData, idx int) string {
+	if idx >= m.CaptureLength() {
+		if idx != 2 {
+			L.RaiseError("invalid capture index")
+		}
+		idx = 0
+	}
+	if m.IsPosCapture(idx) {
+		return fmt.Sprint(m.Capture(idx))
+	}
+	return src[m.Capture(idx):m.Capture(idx+1)]
+}
@@ -0,0 +1,258 @@
+package mod
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// scriptMod writes a mod with the Lua source as its script and returns it.
+func scriptMod(t *testing.T, name, source string) *Mod {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.lua"), []byte(source), 0o644); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	return &Mod{
+		Manifest: &Manifest{Name: name, Version: "1.0.0", Author: "test", Script: "main.lua"},
+		Path:     dir,
+		Name:     name,
+	}
+}
+
+func TestScriptRuntime_RegistersContent(t *testing.T) {
+	r := NewScriptRuntime(DefaultScriptLimits())
+	defer r.Close()
+	m := scriptMod(t, "arms", `
+		game.register_item{id = "elixir", name = "Elixir", table = "barrel", chance = 0.25}
+		game.register_weapon{slot = 1, name = "Hand Cannon", damage = 40, clip = 6, ammo = "bullets"}
+		game.register_quest{id = "hunt", desc = "Hunt 3 rats", target = "rat", count = 3}
+	`)
+	if err := r.LoadScript(m, NewModAPI("arms", DefaultPermissions())); err != nil {
+		t.Fatalf("LoadScript failed: %v", err)
+	}
+
+	items := r.Items()
+	if len(items) != 1 || items[0].ID != "elixir" || items[0].Table != "barrel" || items[0].Chance != 0.25 {
+		t.Errorf("items = %+v", items)
+	}
+	weapons := r.Weapons()
+	if len(weapons) != 1 || weapons[0].Slot != 1 || weapons[0].Damage != 40 || weapons[0].RayCount != 1 {
+		t.Errorf("weapons = %+v", weapons)
+	}
+	quests := r.Quests()
+	if len(quests) != 1 || quests[0].ID != "arms:hunt" || quests[0].Count != 3 {
+		t.Errorf("quests = %+v", quests)
+	}
+
+	if !r.Unload("arms") || len(r.Items()) != 0 {
+		t.Error("Unload left the mod's content registered")
+	}
+}
+
+func TestScriptRuntime_Events(t *testing.T) {
+	r := NewScriptRuntime(DefaultScriptLimits())
+	defer r.Close()
+	var progressed []string
+	r.OnQuestProgress = func(id string, amount int) {
+		progressed = append(progressed, id)
+	}
+
+	world := engine.NewWorld()
+	api := NewModAPI("ambush", ModPermissions{AllowEntitySpawn: true})
+	api.BindGameSystems(world, nil, nil, nil, nil)
+	m := scriptMod(t, "ambush", `
+		game.register_quest{id = "kills", desc = "Kill 2", count = 2}
+		game.on("enemy.killed", function(e)
+			game.spawn("enemy", e.x + 1, e.y)
+			game.progress_quest("kills")
+		end)
+	`)
+	if err := r.LoadScript(m, api); err != nil {
+		t.Fatalf("LoadScript failed: %v", err)
+	}
+
+	before := len(world.Query())
+	err := r.Dispatch(EventData{Type: EventTypeEnemyKilled, Params: map[string]interface{}{"x": 2.0, "y": 3.0}})
+	if err != nil {
+		t.Fatalf("Dispatch failed: %v", err)
+	}
+	if got := len(world.Query()); got != before+1 {
+		t.Errorf("entities = %d, want %d", got, before+1)
+	}
+	if len(progressed) != 1 || progressed[0] != "ambush:kills" {
+		t.Errorf("progressed = %v", progressed)
+	}
+	if err := r.Dispatch(EventData{Type: EventTypeDoorOpen}); err != nil {
+		t.Errorf("event with no handlers: %v", err)
+	}
+}
+
+func TestScriptRuntime_APIVersion(t *testing.T) {
+	r := NewScriptRuntime(DefaultScriptLimits())
+	defer r.Close()
+
+	// Scripts of API 1 mods still see item.pickup's count as qty
+	legacy := scriptMod(t, "legacy", `
+		assert(game.api_version == "2.0.0")
+		assert(game.has("scripts") and not game.has("wasm"))
+		game.on("item.pickup", function(e) assert(e.qty == 3 and e.quantity == 3) end)
+	`)
+	current := scriptMod(t, "current", `
+		game.on("item.pickup", function(e) assert(e.qty == nil and e.quantity == 3) end)
+	`)
+	current.Manifest.APIVersion = "^2.0.0"
+	for _, m := range []*Mod{legacy, current} {
+		if err := r.LoadScript(m, NewModAPI(m.Name, DefaultPermissions())); err != nil {
+			t.Fatalf("LoadScript(%s) failed: %v", m.Name, err)
+		}
+	}
+
+	err := r.Dispatch(EventData{Type: EventTypeItemPickup, Params: map[string]interface{}{"item": "medkit", "quantity": 3}})
+	if err != nil {
+		t.Errorf("Dispatch failed: %v", err)
+	}
+}
+
+func TestScriptRuntime_Sandbox(t *testing.T) {
+	for _, source := range []string{
+		`os.exit(1)`,
+		`io.open("/etc/passwd")`,
+		`require("os")`,
+		`dofile("/etc/passwd")`,
+		`load("return 1")()`,
+		`loadstring("return 1")()`,
+		`string.rep("x", 1e9)`,
+	} {
+		r := NewScriptRuntime(DefaultScriptLimits())
+		if err := r.LoadScript(scriptMod(t, "probe", source), NewModAPI("probe", DefaultPermissions())); err == nil {
+			t.Errorf("%s: expected an error", source)
+		}
+		r.Close()
+	}
+}
+
+func TestScriptRuntime_Permissions(t *testing.T) {
+	r := NewScriptRuntime(DefaultScriptLimits())
+	defer r.Close()
+	api := NewModAPI("sneaky", DefaultPermissions())
+	api.BindGameSystems(engine.NewWorld(), nil, nil, nil, nil)
+	err := r.LoadScript(scriptMod(t, "sneaky", `game.spawn("enemy", 1, 1)`), api)
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("spawn without permission: err = %v", err)
+	}
+}
+
+func TestScriptRuntime_Limits(t *testing.T) {
+	limits := DefaultScriptLimits()
+	limits.InitTimeout = 50 * time.Millisecond
+	limits.CallTimeout = 10 * time.Millisecond
+	limits.MaxRegistrations = 2
+	limits.MaxFailures = 2
+	limits.MaxStringLength = 64 << 10
+	limits.MaxAllocBytes = 8 << 20
+
+	tests := []struct {
+		name, source, want string
+	}{
+		{"init timeout", `while true do end`, "time limit"},
+		{"gsub", `string.gsub(string.rep("y", 64), "", string.rep("x", 4096))`, "string.gsub result longer"},
+		{"gsub captures", `string.gsub(string.rep("y", 1024), "(.+)", string.rep("%1", 100))`, "string.gsub result longer"},
+		{"concat", `local t = {} for i = 1, 8 do t[i] = string.rep("x", 16 * 1024) end table.concat(t)`, "table.concat result longer"},
+		{"format width", `string.format("%999999d", 1)`, "width or precision too long"},
+		{"format args", `local s = string.rep("x", 40 * 1024) string.format("%s%s", s, s)`, "string.format result longer"},
+		{"concat operator", `local s = "x" for i = 1, 40 do s = s .. s end`, "memory limit"},
+		{"recursion", `local function f() return 1 + f() end f()`, "overflow"},
+		{"registrations", `for i = 1, 3 do game.register_item{id = "i" .. i, name = "I"} end`, "registration limit"},
+		{"bad field", `game.register_weapon{name = "No Slot"}`, "slot is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewScriptRuntime(limits)
+			defer r.Close()
+			err := r.LoadScript(scriptMod(t, "greedy", tt.source), NewModAPI("greedy", DefaultPermissions()))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+			if len(r.Loaded()) != 0 {
+				t.Error("failed script stayed loaded")
+			}
+		})
+	}
+}
+
+func TestScriptRuntime_StringLibrary(t *testing.T) {
+	r := NewScriptRuntime(DefaultScriptLimits())
+	defer r.Close()
+
+	// The size-checked functions behave as Lua's own
+	m := scriptMod(t, "strings", `
+		local function check(got, want) assert(got == want, tostring(got) .. " ~= " .. want) end
+		check(string.gsub("hello world", "o", "0"), "hell0 w0rld")
+		check(select(2, string.gsub("hello world", "o", "0")), 2)
+		check(string.gsub("hello world", "(%w+)", "<%1>"), "<hello> <world>")
+		check(string.gsub("hello", "", "-"), "-h-e-l-l-o-")
+		check(string.gsub("abc", "%w", "%0%0", 2), "aabbc")
+		check(string.gsub("abc", "^a", "x"), "xbc")
+		check(string.gsub("$name is $age", "%$(%w+)", {name = "Ada", age = 36}), "Ada is 36")
+		check(string.gsub("a b", "%w", function(c) return c:upper() end), "A B")
+		check(string.gsub("a b", "%w", function(c) return nil end), "a b")
+		check(string.gsub("100%", "%%", "%%%%"), "100%%")
+		check(string.format("%5.2f|%-3s|%q", 3.14159, "ab", "x"), " 3.14|ab |\"x\"")
+		check(table.concat({1, "b", 3}, ", "), "1, b, 3")
+		check(table.concat({"a", "b", "c"}, "", 2, 3), "bc")
+		check(table.concat({}), "")
+		check(string.rep("ab", 3), "ababab")
+		check(string.rep("ab", 0), "")
+	`)
+	if err := r.LoadScript(m, NewModAPI("strings", DefaultPermissions())); err != nil {
+		t.Fatalf("LoadScript failed: %v", err)
+	}
+}
+
+func TestScriptRuntime_StopsFailingScript(t *testing.T) {
+	limits := DefaultScriptLimits()
+	limits.CallTimeout = 10 * time.Millisecond
+	limits.MaxFailures = 2
+	r := NewScriptRuntime(limits)
+	defer r.Close()
+
+	m := scriptMod(t, "spin", `game.on("tick", function() while true do end end)`)
+	if err := r.LoadScript(m, NewModAPI("spin", DefaultPermissions())); err != nil {
+		t.Fatalf("LoadScript failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.Dispatch(EventData{Type: "tick"}); err == nil || !strings.Contains(err.Error(), "time limit") {
+			t.Fatalf("dispatch %d: err = %v", i, err)
+		}
+	}
+	if err := r.Dispatch(EventData{Type: "tick"}); err != nil {
+		t.Errorf("stopped script still ran: %v", err)
+	}
+
+	// Reloading gives the mod a fresh start
+	if err := r.LoadScript(m, NewModAPI("spin", DefaultPermissions())); err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if err := r.Dispatch(EventData{Type: "tick"}); errors.Is(err, ErrScriptStopped) || err == nil {
+		t.Errorf("reloaded script: err = %v", err)
+	}
+	if got := r.Loaded(); len(got) != 1 {
+		t.Errorf("loaded = %v, want one script", got)
+	}
+}
+
+func TestScriptRuntime_ScriptPathStaysInMod(t *testing.T) {
+	r := NewScriptRuntime(DefaultScriptLimits())
+	defer r.Close()
+	m := scriptMod(t, "escape", "")
+	m.Manifest.Script = "../../../../etc/passwd"
+	if err := r.LoadScript(m, NewModAPI("escape", DefaultPermissions())); err == nil {
+		t.Error("loaded a script outside the mod directory")
+	}
+}
@@ -0,0 +1,317 @@
+package mod
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SignatureFile is the file in a mod directory holding its signature.
+const SignatureFile = "mod.sig"
+
+// maxKeyRingSize caps the key ring downloaded from a registry.
+const maxKeyRingSize = 1 << 20
+
+var (
+	// ErrModUnsigned is returned for a mod that isn't signed by a key its
+	// author published.
+	ErrModUnsigned = errors.New("mod is unsigned")
+
+	// ErrModBlocked is returned for a mod that must not load: it was
+	// blocked by the registry, signed with a revoked key, or changed after
+	// it was signed.
+	ErrModBlocked = errors.New("mod is blocked")
+)
+
+// TrustLevel is how far a mod package can be trusted.
+type TrustLevel int
+
+const (
+	// TrustUnsigned mods load, with a warning.
+	TrustUnsigned TrustLevel = iota
+	// TrustSigned mods are signed by a key their author published.
+	TrustSigned
+	// TrustBlocked mods don't load.
+	TrustBlocked
+)
+
+// String returns the trust level's name.
+func (t TrustLevel) String() string {
+	switch t {
+	case TrustSigned:
+		return "signed"
+	case TrustBlocked:
+		return "blocked"
+	default:
+		return "unsigned"
+	}
+}
+
+// PackageSignature is an author's Ed25519 signature over the digest of a
+// mod package.
+type PackageSignature struct {
+	Author    string `json:"author"`
+	Key       string `json:"key"`       // Base64 Ed25519 public key
+	Signature string `json:"signature"` // Base64 signature over the PackageDigest
+}
+
+// PackageDigest returns the digest a mod package is signed over: SHA-256
+// over each file's slash-separated path and SHA-256, in path order, so it
+// depends only on the files' names and contents.
+func PackageDigest(files map[string][]byte) []byte {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	h := sha256.New()
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s\x00%x\n", path, sha256.Sum256(files[path]))
+	}
+	return h.Sum(nil)
+}
+
+// DirDigest returns the PackageDigest of the regular files in a mod
+// directory and its subdirectories, leaving out the signature file.
+func DirDigest(dir string) ([]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == SignatureFile {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[rel] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mod files: %w", err)
+	}
+	return PackageDigest(files), nil
+}
+
+// SignPackage signs a package digest as author.
+func SignPackage(digest []byte, author string, key ed25519.PrivateKey) *PackageSignature {
+	return &PackageSignature{
+		Author:    author,
+		Key:       base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest)),
+	}
+}
+
+// Verify checks that the signature is its key's signature over digest.
+func (s *PackageSignature) Verify(digest []byte) error {
+	key, err := decodePublicKey(s.Key)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(s.Signature)
+	if err != nil || !ed25519.Verify(key, digest, sig) {
+		return errors.New("signature does not match the package")
+	}
+	return nil
+}
+
+// ReadSignature reads a mod directory's signature file. It returns nil
+// and no error if the mod isn't signed.
+func ReadSignature(dir string) (*PackageSignature, error) {
+	data, err := os.ReadFile(filepath.Join(dir, SignatureFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	var sig PackageSignature
+	if err := json.Unmarshal(data, &sig); err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	return &sig, nil
+}
+
+// WriteSignature writes a mod directory's signature file.
+func WriteSignature(dir string, sig *PackageSignature) error {
+	data, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, SignatureFile), append(data, '\n'), 0o644)
+}
+
+// KeyRing holds the author keys a mod registry publishes, and the keys
+// and mods it has blocked.
+type KeyRing struct {
+	Authors     map[string][]string `json:"authors"`      // Author name -> base64 public keys
+	BlockedKeys []string            `json:"blocked_keys"` // Revoked or compromised keys
+	BlockedMods []string            `json:"blocked_mods"` // Mod names taken down
+}
+
+// NewKeyRing creates an empty key ring.
+func NewKeyRing() *KeyRing {
+	return &KeyRing{
+		Authors:     make(map[string][]string),
+		BlockedKeys: []string{},
+		BlockedMods: []string{},
+	}
+}
+
+// AddAuthorKey publishes a base64 Ed25519 public key for an author.
+func (k *KeyRing) AddAuthorKey(author, key string) error {
+	if _, err := decodePublicKey(key); err != nil {
+		return err
+	}
+	for _, existing := range k.Authors[author] {
+		if existing == key {
+			return nil
+		}
+	}
+	k.Authors[author] = append(k.Authors[author], key)
+	return nil
+}
+
+// BlockKey blocks every mod signed with a key.
+func (k *KeyRing) BlockKey(key string) {
+	if !contains(k.BlockedKeys, key) {
+		k.BlockedKeys = append(k.BlockedKeys, key)
+	}
+}
+
+// BlockMod blocks a mod by name, signed or not.
+func (k *KeyRing) BlockMod(name string) {
+	if !contains(k.BlockedMods, name) {
+		k.BlockedMods = append(k.BlockedMods, name)
+	}
+}
+
+// Verify decides how far to trust a mod package from its name, the author
+// its manifest names, its digest and its signature, which may be nil. The
+// error explains any level below signed. A signature by anyone but the
+// manifest's author doesn't vouch for the mod, and a nil key ring knows no
+// authors, so in both cases signed mods are only as trusted as unsigned
+// ones.
+func (k *KeyRing) Verify(name, author string, digest []byte, sig *PackageSignature) (TrustLevel, error) {
+	if k == nil {
+		k = NewKeyRing()
+	}
+	if contains(k.BlockedMods, name) {
+		return TrustBlocked, fmt.Errorf("%w: taken down by the registry", ErrModBlocked)
+	}
+	if sig == nil {
+		return TrustUnsigned, fmt.Errorf("%w: no %s", ErrModUnsigned, SignatureFile)
+	}
+	if contains(k.BlockedKeys, sig.Key) {
+		return TrustBlocked, fmt.Errorf("%w: signed with a revoked key", ErrModBlocked)
+	}
+	if err := sig.Verify(digest); err != nil {
+		return TrustBlocked, fmt.Errorf("%w: files changed after signing", ErrModBlocked)
+	}
+	if sig.Author != author {
+		return TrustUnsigned, fmt.Errorf("%w: signed by %s, but the manifest author is %s", ErrModUnsigned, sig.Author, author)
+	}
+	if !contains(k.Authors[sig.Author], sig.Key) {
+		return TrustUnsigned, fmt.Errorf("%w: key not published for %s", ErrModUnsigned, sig.Author)
+	}
+	return TrustSigned, nil
+}
+
+// LoadKeyRing reads a key ring saved with Save.
+func LoadKeyRing(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key ring: %w", err)
+	}
+	return parseKeyRing(data)
+}
+
+// Save writes the key ring to a file as JSON.
+func (k *KeyRing) Save(path string) error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal key ring: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// FetchKeyRing downloads the key ring a mod registry publishes at /keys.
+func FetchKeyRing(registryURL string, timeout time.Duration) (*KeyRing, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(strings.TrimSuffix(registryURL, "/") + "/keys")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key ring: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch key ring: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeyRingSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key ring: %w", err)
+	}
+	return parseKeyRing(data)
+}
+
+// parseKeyRing parses a key ring from JSON.
+func parseKeyRing(data []byte) (*KeyRing, error) {
+	var ring KeyRing
+	if err := json.Unmarshal(data, &ring); err != nil {
+		return nil, fmt.Errorf("failed to parse key ring: %w", err)
+	}
+	return ring.normalize()
+}
+
+// normalize fills in missing fields and checks the author keys.
+func (k *KeyRing) normalize() (*KeyRing, error) {
+	ring := NewKeyRing()
+	for author, keys := range k.Authors {
+		for _, key := range keys {
+			if err := ring.AddAuthorKey(author, key); err != nil {
+				return nil, fmt.Errorf("author %s: %w", author, err)
+			}
+		}
+	}
+	ring.BlockedKeys = append(ring.BlockedKeys, k.BlockedKeys...)
+	ring.BlockedMods = append(ring.BlockedMods, k.BlockedMods...)
+	return ring, nil
+}
+
+// decodePublicKey decodes a base64 Ed25519 public key.
+func decodePublicKey(key string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key %q", key)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// contains reports whether a list holds a string.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
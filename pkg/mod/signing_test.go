@@ -0,0 +1,195 @@
+package mod
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// signedModDir writes a mod signed by author with key and returns its
+// directory.
+func signedModDir(t *testing.T, name, author string, key ed25519.PrivateKey) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{"name": "` + name + `", "version": "1.0.0", "author": "` + author + `"}`
+	if err := os.WriteFile(filepath.Join(dir, "mod.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "assets", "wall_1.png"), []byte("pixels"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := DirDigest(dir)
+	if err != nil {
+		t.Fatalf("DirDigest failed: %v", err)
+	}
+	if err := WriteSignature(dir, SignPackage(digest, author, key)); err != nil {
+		t.Fatalf("WriteSignature failed: %v", err)
+	}
+	return dir
+}
+
+func TestPackageDigest(t *testing.T) {
+	a := PackageDigest(map[string][]byte{"mod.json": []byte("{}"), "mod.wasm": []byte("code")})
+	b := PackageDigest(map[string][]byte{"mod.wasm": []byte("code"), "mod.json": []byte("{}")})
+	if string(a) != string(b) {
+		t.Error("digest depends on map order")
+	}
+	if c := PackageDigest(map[string][]byte{"mod.json": []byte("{}"), "other.wasm": []byte("code")}); string(a) == string(c) {
+		t.Error("digest ignores file names")
+	}
+}
+
+func TestKeyRing_Verify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+	key := base64.StdEncoding.EncodeToString(pub)
+	digest := PackageDigest(map[string][]byte{"mod.json": []byte("{}")})
+
+	ring := NewKeyRing()
+	if err := ring.AddAuthorKey("alice", key); err != nil {
+		t.Fatalf("AddAuthorKey failed: %v", err)
+	}
+	if err := ring.AddAuthorKey("alice", "short"); err == nil {
+		t.Error("AddAuthorKey accepted a malformed key")
+	}
+
+	revoked := NewKeyRing()
+	revoked.Authors["alice"] = []string{key}
+	revoked.BlockKey(key)
+	takenDown := NewKeyRing()
+	takenDown.BlockMod("arms")
+	bobPub, bobPriv, _ := ed25519.GenerateKey(nil)
+	impostor := NewKeyRing()
+	impostor.Authors["alice"] = []string{key}
+	impostor.Authors["bob"] = []string{base64.StdEncoding.EncodeToString(bobPub)}
+
+	tests := []struct {
+		name    string
+		ring    *KeyRing
+		sig     *PackageSignature
+		want    TrustLevel
+		wantErr error
+	}{
+		{"signed", ring, SignPackage(digest, "alice", priv), TrustSigned, nil},
+		{"no signature", ring, nil, TrustUnsigned, ErrModUnsigned},
+		{"unpublished key", ring, SignPackage(digest, "alice", otherPriv), TrustUnsigned, ErrModUnsigned},
+		{"other author", ring, SignPackage(digest, "bob", priv), TrustUnsigned, ErrModUnsigned},
+		{"not the manifest author", impostor, SignPackage(digest, "bob", bobPriv), TrustUnsigned, ErrModUnsigned},
+		{"no key ring", nil, SignPackage(digest, "alice", priv), TrustUnsigned, ErrModUnsigned},
+		{"tampered", ring, SignPackage([]byte("other files"), "alice", priv), TrustBlocked, ErrModBlocked},
+		{"revoked key", revoked, SignPackage(digest, "alice", priv), TrustBlocked, ErrModBlocked},
+		{"taken down", takenDown, nil, TrustBlocked, ErrModBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ring.Verify("arms", "alice", digest, tt.sig)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify = %v, %v; want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyRing_SaveLoad(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	ring := NewKeyRing()
+	ring.AddAuthorKey("alice", base64.StdEncoding.EncodeToString(pub))
+	ring.BlockMod("malware")
+
+	path := filepath.Join(t.TempDir(), "keyring.json")
+	if err := ring.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := LoadKeyRing(path)
+	if err != nil {
+		t.Fatalf("LoadKeyRing failed: %v", err)
+	}
+	if len(loaded.Authors["alice"]) != 1 || len(loaded.BlockedMods) != 1 {
+		t.Errorf("loaded = %+v", loaded)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"authors": {"eve": ["bogus"]}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeyRing(path); err == nil {
+		t.Error("LoadKeyRing accepted a malformed key")
+	}
+}
+
+func TestLoader_TrustLevels(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	signed := signedModDir(t, "core-lib", "alice", priv)
+	addonDir := filepath.Join(t.TempDir(), "addon")
+	os.Mkdir(addonDir, 0o755)
+	os.WriteFile(filepath.Join(addonDir, "mod.json"), []byte(`{"name": "addon", "version": "1.0.0", "author": "bob",
+		"dependencies": [{"name": "core-lib", "version": "^1.0.0"}]}`), 0o644)
+
+	loader := NewLoader()
+	if err := loader.LoadMod(signed); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.LoadMod(addonDir); err != nil {
+		t.Fatal(err)
+	}
+	core, _ := loader.GetMod("core-lib")
+	if core.Trust != TrustUnsigned || core.Signature == nil {
+		t.Errorf("before the key ring: trust = %v, signature = %v", core.Trust, core.Signature)
+	}
+
+	ring := NewKeyRing()
+	ring.AddAuthorKey("alice", base64.StdEncoding.EncodeToString(pub))
+	loader.SetKeyRing(ring)
+	core, _ = loader.GetMod("core-lib")
+	addon, _ := loader.GetMod("addon")
+	if core.Trust != TrustSigned || addon.Trust != TrustUnsigned || len(loader.ActiveMods()) != 2 {
+		t.Errorf("core = %v, addon = %v, active = %d", core.Trust, addon.Trust, len(loader.ActiveMods()))
+	}
+
+	// Changing a signed file blocks the mod, and with it the addon
+	if err := os.WriteFile(filepath.Join(signed, "assets", "wall_1.png"), []byte("swapped"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loader.SetKeyRing(ring)
+	core, _ = loader.GetMod("core-lib")
+	addon, _ = loader.GetMod("addon")
+	if core.Trust != TrustBlocked || !errors.Is(core.ResolveError, ErrModBlocked) {
+		t.Errorf("tampered mod: trust = %v, err = %v", core.Trust, core.ResolveError)
+	}
+	if !errors.Is(addon.ResolveError, ErrMissingDependency) || len(loader.ActiveMods()) != 0 {
+		t.Errorf("addon err = %v, active = %d", addon.ResolveError, len(loader.ActiveMods()))
+	}
+}
+
+func TestLoader_SignatureAuthor(t *testing.T) {
+	alicePub, _, _ := ed25519.GenerateKey(nil)
+	bobPub, bobPriv, _ := ed25519.GenerateKey(nil)
+	ring := NewKeyRing()
+	ring.AddAuthorKey("alice", base64.StdEncoding.EncodeToString(alicePub))
+	ring.AddAuthorKey("bob", base64.StdEncoding.EncodeToString(bobPub))
+
+	// Bob signs, with his own published key, a mod claiming to be Alice's
+	dir := signedModDir(t, "impostor", "alice", bobPriv)
+	digest, err := DirDigest(dir)
+	if err != nil {
+		t.Fatalf("DirDigest failed: %v", err)
+	}
+	if err := WriteSignature(dir, SignPackage(digest, "bob", bobPriv)); err != nil {
+		t.Fatalf("WriteSignature failed: %v", err)
+	}
+
+	loader := NewLoader()
+	loader.SetKeyRing(ring)
+	if err := loader.LoadMod(dir); err != nil {
+		t.Fatal(err)
+	}
+	m, _ := loader.GetMod("impostor")
+	if m.Trust != TrustUnsigned || !errors.Is(m.TrustError, ErrModUnsigned) {
+		t.Errorf("trust = %v, err = %v; want unsigned", m.Trust, m.TrustError)
+	}
+}
@@ -0,0 +1,182 @@
+//go:build !js
+
+package mod
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	logrus "github.com/sirupsen/logrus"
+)
+
+// watchSupported reports whether mod files can be watched on this
+// platform.
+const watchSupported = true
+
+// DefaultWatchDebounce is how long a mod's files must be left alone after a
+// change before the mod is reported, so the burst of writes an editor makes
+// on save reloads it once.
+const DefaultWatchDebounce = 250 * time.Millisecond
+
+// Watcher reports changes to the files of mods, so their content can be
+// reloaded while they are being worked on.
+type Watcher struct {
+	fs       *fsnotify.Watcher
+	debounce time.Duration
+
+	mu      sync.Mutex
+	roots   map[string]string    // Mod directory -> mod name
+	pending map[string]time.Time // Mod name -> last change
+	done    chan struct{}
+}
+
+// NewWatcher starts a watcher that reports mods once their files have been
+// left alone for debounce.
+func NewWatcher(debounce time.Duration) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		fs:       fsw,
+		debounce: debounce,
+		roots:    make(map[string]string),
+		pending:  make(map[string]time.Time),
+		done:     make(chan struct{}),
+	}
+	go w.loop()
+	return w, nil
+}
+
+// Watch replaces the watched mods with mods, watching each mod's directory
+// and everything under it.
+func (w *Watcher) Watch(mods []Mod) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, path := range w.fs.WatchList() {
+		_ = w.fs.Remove(path)
+	}
+	w.roots = make(map[string]string, len(mods))
+	w.pending = make(map[string]time.Time)
+
+	var firstErr error
+	for _, m := range mods {
+		root := filepath.Clean(m.Path)
+		w.roots[root] = m.Name
+		if err := w.addTree(root); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// addTree watches a directory and its subdirectories, since fsnotify only
+// reports changes to a directory's direct entries.
+func (w *Watcher) addTree(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && ignoredName(d.Name()) {
+			return filepath.SkipDir
+		}
+		return w.fs.Add(path)
+	})
+}
+
+// Changed returns the mods whose files changed and have since been left
+// alone for the debounce time, sorted by name. Each change is reported
+// once. It doesn't block, so the game can poll it every frame.
+func (w *Watcher) Changed() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var names []string
+	now := time.Now()
+	for name, last := range w.pending {
+		if now.Sub(last) >= w.debounce {
+			names = append(names, name)
+			delete(w.pending, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	err := w.fs.Close()
+	<-w.done
+	return err
+}
+
+// loop records file events until the watcher is closed.
+func (w *Watcher) loop() {
+	defer close(w.done)
+	for {
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			w.record(event)
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			logrus.WithError(err).Warn("Mod watcher error")
+		}
+	}
+}
+
+// record marks the mod a file event belongs to as changed, and watches
+// directories created inside a mod.
+func (w *Watcher) record(event fsnotify.Event) {
+	if event.Op == fsnotify.Chmod || ignoredName(filepath.Base(event.Name)) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	name, ok := w.modFor(event.Name)
+	if !ok {
+		return
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := w.addTree(event.Name); err != nil {
+				logrus.WithError(err).WithField("mod", name).Warn("Failed to watch new mod directory")
+			}
+		}
+	}
+	w.pending[name] = time.Now()
+}
+
+// modFor returns the name of the mod a path is in (must be called with
+// lock held).
+func (w *Watcher) modFor(path string) (string, bool) {
+	path = filepath.Clean(path)
+	for root, name := range w.roots {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ignoredName reports whether a file is hidden or an editor's backup,
+// whose changes don't touch mod content.
+func ignoredName(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || strings.HasSuffix(name, ".swp")
+}
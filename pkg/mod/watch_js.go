@@ -0,0 +1,37 @@
+//go:build js
+
+package mod
+
+import (
+	"errors"
+	"time"
+)
+
+// watchSupported reports whether mod files can be watched on this
+// platform.
+const watchSupported = false
+
+// DefaultWatchDebounce is how long a mod's files must be left alone after a
+// change before the mod is reported.
+const DefaultWatchDebounce = 250 * time.Millisecond
+
+// ErrWatchNotSupported is returned by NewWatcher on the js/wasm target,
+// where the browser has no file system to watch.
+var ErrWatchNotSupported = errors.New("mod watching is not available in browser environments")
+
+// Watcher is the js/wasm stub of the mod file watcher.
+type Watcher struct{}
+
+// NewWatcher returns ErrWatchNotSupported.
+func NewWatcher(debounce time.Duration) (*Watcher, error) {
+	return nil, ErrWatchNotSupported
+}
+
+// Watch does nothing.
+func (w *Watcher) Watch(mods []Mod) error { return ErrWatchNotSupported }
+
+// Changed reports no changes.
+func (w *Watcher) Changed() []string { return nil }
+
+// Close does nothing.
+func (w *Watcher) Close() error { return nil }
@@ -0,0 +1,87 @@
+//go:build !js
+
+package mod
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// waitChanged polls the watcher until it reports changes or a second
+// passes.
+func waitChanged(w *Watcher) []string {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if names := w.Changed(); len(names) > 0 {
+			return names
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil
+}
+
+func TestWatcher_Changed(t *testing.T) {
+	root := t.TempDir()
+	arms := filepath.Join(root, "arms")
+	bestiary := filepath.Join(root, "bestiary")
+	for _, dir := range []string{filepath.Join(arms, "assets"), bestiary, filepath.Join(root, "other")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewWatcher(20 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewWatcher failed: %v", err)
+	}
+	defer w.Close()
+	if err := w.Watch([]Mod{{Name: "arms", Path: arms}, {Name: "bestiary", Path: bestiary}}); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	// Several writes to a mod report it once
+	for i := 0; i < 3; i++ {
+		os.WriteFile(filepath.Join(bestiary, "enemies.json"), []byte("{}"), 0o644)
+	}
+	if got := waitChanged(w); !reflect.DeepEqual(got, []string{"bestiary"}) {
+		t.Errorf("after editing bestiary: changed = %v", got)
+	}
+	if got := w.Changed(); len(got) != 0 {
+		t.Errorf("change reported twice: %v", got)
+	}
+
+	// Subdirectories are watched, including ones created later
+	os.WriteFile(filepath.Join(arms, "assets", "wall_1.png"), []byte("pixels"), 0o644)
+	if got := waitChanged(w); !reflect.DeepEqual(got, []string{"arms"}) {
+		t.Errorf("after editing an asset: changed = %v", got)
+	}
+	sounds := filepath.Join(arms, "assets", "sounds")
+	os.Mkdir(sounds, 0o755)
+	waitChanged(w)
+	os.WriteFile(filepath.Join(sounds, "shot.wav"), []byte("wave"), 0o644)
+	if got := waitChanged(w); !reflect.DeepEqual(got, []string{"arms"}) {
+		t.Errorf("after adding a sound: changed = %v", got)
+	}
+
+	// Editor backups and other directories are ignored
+	os.WriteFile(filepath.Join(arms, ".mod.json.swp"), []byte("swap"), 0o644)
+	os.WriteFile(filepath.Join(arms, "mod.json~"), []byte("backup"), 0o644)
+	os.WriteFile(filepath.Join(root, "other", "mod.json"), []byte("{}"), 0o644)
+	time.Sleep(100 * time.Millisecond)
+	if got := w.Changed(); len(got) != 0 {
+		t.Errorf("ignored files reported as changes: %v", got)
+	}
+
+	// Watch replaces the watched mods
+	if err := w.Watch([]Mod{{Name: "arms", Path: arms}}); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	os.WriteFile(filepath.Join(bestiary, "enemies.json"), []byte("[]"), 0o644)
+	time.Sleep(100 * time.Millisecond)
+	if got := w.Changed(); len(got) != 0 {
+		t.Errorf("unwatched mod reported: %v", got)
+	}
+}
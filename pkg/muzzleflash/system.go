@@ -11,11 +11,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// LightEmitter receives the light of each spawned flash, so the lighting
+// system can illuminate the surroundings.
+type LightEmitter interface {
+	EmitLightImpulse(x, y, radius, intensity float64, col color.RGBA, duration float64)
+}
+
 // System manages muzzle flash spawning, updating, and cleanup.
 type System struct {
-	genreID string
-	rng     *rand.Rand
-	logger  *logrus.Entry
+	genreID      string
+	rng          *rand.Rand
+	logger       *logrus.Entry
+	lightEmitter LightEmitter
 
 	// Genre-specific flash style modifiers
 	colorTint     color.RGBA
@@ -95,6 +102,11 @@ func (s *System) applyGenreStyle(genreID string) {
 	}
 }
 
+// SetLightEmitter connects the lighting system for flash illumination.
+func (s *System) SetLightEmitter(emitter LightEmitter) {
+	s.lightEmitter = emitter
+}
+
 // Update processes all muzzle flash components, aging and removing expired flashes.
 func (s *System) Update(w *engine.World) {
 	deltaTime := common.DeltaTime
@@ -169,6 +181,10 @@ func (s *System) SpawnFlash(w *engine.World, entity engine.Entity, x, y, angle f
 
 	comp.ActiveFlashes = append(comp.ActiveFlashes, flash)
 
+	if flash.EmitsLight && s.lightEmitter != nil {
+		s.lightEmitter.EmitLightImpulse(x, y, flash.LightRadius, flash.LightIntensity, primaryColor, duration)
+	}
+
 	s.logger.WithFields(logrus.Fields{
 		"entity":     entity,
 		"flash_type": flashType,
@@ -83,6 +83,35 @@ func TestSpawnFlash(t *testing.T) {
 	}
 }
 
+type mockLightEmitter struct {
+	radius, intensity, duration float64
+	count                       int
+}
+
+func (m *mockLightEmitter) EmitLightImpulse(x, y, radius, intensity float64, col color.RGBA, duration float64) {
+	m.radius, m.intensity, m.duration = radius, intensity, duration
+	m.count++
+}
+
+func TestSpawnFlashEmitsLight(t *testing.T) {
+	sys := NewSystem("fantasy", 12345)
+	lights := &mockLightEmitter{}
+	sys.SetLightEmitter(lights)
+	world := engine.NewWorld()
+	entity := world.AddEntity()
+
+	sys.SpawnFlash(world, entity, 1.0, 2.0, 0, "bullet", 1.0)
+
+	flash := sys.GetActiveFlashes(world, entity)[0]
+	if lights.count != 1 {
+		t.Fatalf("emitted %d lights, want 1", lights.count)
+	}
+	if lights.radius != flash.LightRadius || lights.intensity != flash.LightIntensity || lights.duration != flash.Duration {
+		t.Errorf("light = %+v, want flash radius %f intensity %f duration %f",
+			lights, flash.LightRadius, flash.LightIntensity, flash.Duration)
+	}
+}
+
 func TestSpawnFlashMaxLimit(t *testing.T) {
 	sys := NewSystem("fantasy", 12345)
 	world := engine.NewWorld()
@@ -0,0 +1,63 @@
+package network
+
+import "sync"
+
+// BandwidthStats summarises the world state sent to a client, or to all
+// of them.
+type BandwidthStats struct {
+	BytesSent      uint64  // Total bytes sent
+	PacketsSent    uint64  // Total packets sent
+	BytesPerSecond float64 // Bytes sent over the last TickRate ticks
+	AvgPacketSize  float64 // Mean packet size over the last TickRate ticks
+}
+
+// BandwidthMeter counts the bytes sent each tick.
+type BandwidthMeter struct {
+	mu      sync.Mutex
+	bytes   uint64
+	packets uint64
+	window  [TickRate]int // Bytes sent in each of the last TickRate ticks
+	count   [TickRate]int // Packets sent in each of them
+	slot    int
+}
+
+// NewBandwidthMeter creates an empty bandwidth meter.
+func NewBandwidthMeter() *BandwidthMeter {
+	return &BandwidthMeter{}
+}
+
+// Record counts a packet of n bytes sent this tick.
+func (m *BandwidthMeter) Record(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bytes += uint64(n)
+	m.packets++
+	m.window[m.slot] += n
+	m.count[m.slot]++
+}
+
+// Tick closes the current tick, dropping the oldest from the rate window.
+func (m *BandwidthMeter) Tick() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slot = (m.slot + 1) % TickRate
+	m.window[m.slot] = 0
+	m.count[m.slot] = 0
+}
+
+// Stats returns the totals and the rate over the last second of ticks.
+func (m *BandwidthMeter) Stats() BandwidthStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := BandwidthStats{BytesSent: m.bytes, PacketsSent: m.packets}
+	bytes, packets := 0, 0
+	for i := range m.window {
+		bytes += m.window[i]
+		packets += m.count[i]
+	}
+	stats.BytesPerSecond = float64(bytes)
+	if packets > 0 {
+		stats.AvgPacketSize = float64(bytes) / float64(packets)
+	}
+	return stats
+}
@@ -0,0 +1,30 @@
+package network
+
+import "testing"
+
+func TestBandwidthMeter(t *testing.T) {
+	m := NewBandwidthMeter()
+	if stats := m.Stats(); stats != (BandwidthStats{}) {
+		t.Errorf("new meter stats = %+v", stats)
+	}
+
+	for i := 0; i < TickRate; i++ {
+		m.Record(100)
+		m.Tick()
+	}
+	stats := m.Stats()
+	if stats.BytesSent != 100*TickRate || stats.PacketsSent != TickRate {
+		t.Errorf("totals = %+v", stats)
+	}
+	// The current tick is empty, so the window holds TickRate-1 packets
+	if stats.BytesPerSecond != 100*(TickRate-1) || stats.AvgPacketSize != 100 {
+		t.Errorf("rate = %+v", stats)
+	}
+
+	for i := 0; i < TickRate; i++ {
+		m.Tick()
+	}
+	if stats := m.Stats(); stats.BytesPerSecond != 0 || stats.BytesSent != 100*TickRate {
+		t.Errorf("after a quiet second = %+v", stats)
+	}
+}
@@ -0,0 +1,373 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/federation"
+)
+
+const (
+	// DefaultServerPort is the port assumed for addresses given without one.
+	DefaultServerPort = 7777
+
+	// DefaultPingTimeout bounds how long a server ping waits for a reply.
+	DefaultPingTimeout = 2 * time.Second
+)
+
+// ErrInvalidAddress is returned by ParseServerAddress for addresses that
+// can't be dialled.
+var ErrInvalidAddress = errors.New("invalid server address")
+
+// ParseServerAddress normalises an address typed by the player into
+// host:port, adding DefaultServerPort when the port is left out.
+func ParseServerAddress(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", fmt.Errorf("%w: empty", ErrInvalidAddress)
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		// No port, or a bare IPv6 address
+		host, port = strings.Trim(s, "[]"), strconv.Itoa(DefaultServerPort)
+	}
+	if host == "" || strings.ContainsAny(host, " /") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("%w: bad port in %q", ErrInvalidAddress, s)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// PingFunc measures the round trip to a server.
+type PingFunc func(addr string, timeout time.Duration) (time.Duration, error)
+
+// PingServer measures the time to open a TCP connection to the server,
+// which is one round trip.
+func PingServer(addr string, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+	conn.Close()
+	return rtt, nil
+}
+
+// ServerListing is a server as shown in the browser.
+type ServerListing struct {
+	federation.ServerAnnouncement
+	Pinged    bool          // A ping has completed
+	Reachable bool          // The last ping got through
+	Ping      time.Duration // Round trip of the last ping that got through
+	Favorite  bool
+}
+
+// BrowserFilter selects the servers the browser lists. Zero values match
+// every server.
+type BrowserFilter struct {
+	Mode          string
+	Genre         string
+	Region        federation.Region
+	MinPlayers    int
+	MaxPlayers    int  // 0 = no limit
+	HideFull      bool // Leave out servers with no free slot
+	FavoritesOnly bool
+}
+
+// Matches reports whether the server passes the filter.
+func (f BrowserFilter) Matches(s *ServerListing) bool {
+	switch {
+	case f.Mode != "" && s.Mode != f.Mode,
+		f.Genre != "" && s.Genre != f.Genre,
+		f.Region != "" && s.Region != f.Region,
+		s.Players < f.MinPlayers,
+		f.MaxPlayers > 0 && s.Players > f.MaxPlayers,
+		f.HideFull && s.MaxPlayers > 0 && s.Players >= s.MaxPlayers,
+		f.FavoritesOnly && !s.Favorite:
+		return false
+	}
+	return true
+}
+
+// SortKey orders the server list.
+type SortKey int
+
+const (
+	SortByPing    SortKey = iota // Lowest ping first, unreachable last
+	SortByPlayers                // Most players first
+	SortByName                   // Alphabetical
+	numSortKeys
+)
+
+// String returns the sort key's display name.
+func (k SortKey) String() string {
+	switch k {
+	case SortByPing:
+		return "ping"
+	case SortByPlayers:
+		return "players"
+	case SortByName:
+		return "name"
+	default:
+		return "unknown"
+	}
+}
+
+// Next returns the sort key after k, wrapping around.
+func (k SortKey) Next() SortKey {
+	return (k + 1) % numSortKeys
+}
+
+// ServerBrowser keeps the discovered servers with their pings and the
+// player's favorites, and lists them filtered and sorted. Favorites that
+// no hub announces, such as servers joined by address, are listed by
+// address. It is safe for concurrent use, so pings can run in the
+// background.
+type ServerBrowser struct {
+	mu        sync.RWMutex
+	servers   map[string]*ServerListing // By address
+	favorites map[string]bool
+	filter    BrowserFilter
+	sortKey   SortKey
+	ping      PingFunc
+}
+
+// NewServerBrowser creates a browser with the given favorite addresses.
+func NewServerBrowser(favorites []string) *ServerBrowser {
+	b := &ServerBrowser{
+		servers:   make(map[string]*ServerListing),
+		favorites: make(map[string]bool),
+		ping:      PingServer,
+	}
+	for _, addr := range favorites {
+		b.favorites[addr] = true
+	}
+	b.SetServers(nil)
+	return b
+}
+
+// SetPinger replaces the function servers are pinged with.
+func (b *ServerBrowser) SetPinger(ping PingFunc) {
+	b.mu.Lock()
+	b.ping = ping
+	b.mu.Unlock()
+}
+
+// SetServers replaces the server list with fresh announcements. Servers
+// already listed keep their last ping until they're pinged again.
+func (b *ServerBrowser) SetServers(announcements []federation.ServerAnnouncement) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	servers := make(map[string]*ServerListing, len(announcements)+len(b.favorites))
+	for _, a := range announcements {
+		l := &ServerListing{ServerAnnouncement: a}
+		if old, ok := b.servers[a.Address]; ok {
+			l.Pinged, l.Reachable, l.Ping = old.Pinged, old.Reachable, old.Ping
+		}
+		servers[a.Address] = l
+	}
+	for addr := range b.favorites {
+		if _, ok := servers[addr]; !ok {
+			l := &ServerListing{ServerAnnouncement: federation.ServerAnnouncement{
+				Name: addr, Address: addr, Region: federation.RegionUnknown,
+			}}
+			if old, ok := b.servers[addr]; ok {
+				l.Pinged, l.Reachable, l.Ping = old.Pinged, old.Reachable, old.Ping
+			}
+			servers[addr] = l
+		}
+	}
+	for addr, l := range servers {
+		l.Favorite = b.favorites[addr]
+	}
+	b.servers = servers
+}
+
+// UpdateServer adds a server from a live hub feed, or updates it, and
+// reports whether it's new to the list.
+func (b *ServerBrowser) UpdateServer(a federation.ServerAnnouncement) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l := &ServerListing{ServerAnnouncement: a, Favorite: b.favorites[a.Address]}
+	old, ok := b.servers[a.Address]
+	if ok {
+		l.Pinged, l.Reachable, l.Ping = old.Pinged, old.Reachable, old.Ping
+	}
+	b.servers[a.Address] = l
+	return !ok
+}
+
+// RemoveServer drops a server the hub no longer lists. Favorites stay,
+// listed by address.
+func (b *ServerBrowser) RemoveServer(addr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l, ok := b.servers[addr]
+	if !ok {
+		return
+	}
+	if !b.favorites[addr] {
+		delete(b.servers, addr)
+		return
+	}
+	b.servers[addr] = &ServerListing{
+		ServerAnnouncement: federation.ServerAnnouncement{Name: addr, Address: addr, Region: federation.RegionUnknown},
+		Pinged:             l.Pinged,
+		Reachable:          l.Reachable,
+		Ping:               l.Ping,
+		Favorite:           true,
+	}
+}
+
+// PingAll pings every listed server at once and waits for the results.
+func (b *ServerBrowser) PingAll(timeout time.Duration) {
+	b.mu.RLock()
+	addrs := make([]string, 0, len(b.servers))
+	for addr := range b.servers {
+		addrs = append(addrs, addr)
+	}
+	b.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Ping(addr, timeout)
+		}()
+	}
+	wg.Wait()
+}
+
+// Ping pings one listed server and waits for the result.
+func (b *ServerBrowser) Ping(addr string, timeout time.Duration) {
+	b.mu.RLock()
+	ping := b.ping
+	b.mu.RUnlock()
+
+	rtt, err := ping(addr, timeout)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if l, ok := b.servers[addr]; ok {
+		l.Pinged, l.Reachable = true, err == nil
+		if err == nil {
+			l.Ping = rtt
+		}
+	}
+}
+
+// Filter returns the current filter.
+func (b *ServerBrowser) Filter() BrowserFilter {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.filter
+}
+
+// SetFilter replaces the filter.
+func (b *ServerBrowser) SetFilter(f BrowserFilter) {
+	b.mu.Lock()
+	b.filter = f
+	b.mu.Unlock()
+}
+
+// SortKey returns the current sort order.
+func (b *ServerBrowser) SortKey() SortKey {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.sortKey
+}
+
+// SetSortKey sets the sort order.
+func (b *ServerBrowser) SetSortKey(k SortKey) {
+	b.mu.Lock()
+	b.sortKey = k
+	b.mu.Unlock()
+}
+
+// ToggleFavorite adds the address to the favorites, or removes it, and
+// returns whether it's now a favorite.
+func (b *ServerBrowser) ToggleFavorite(addr string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	fav := !b.favorites[addr]
+	if fav {
+		b.favorites[addr] = true
+		if _, ok := b.servers[addr]; !ok {
+			b.servers[addr] = &ServerListing{ServerAnnouncement: federation.ServerAnnouncement{
+				Name: addr, Address: addr, Region: federation.RegionUnknown,
+			}}
+		}
+	} else {
+		delete(b.favorites, addr)
+	}
+	if l, ok := b.servers[addr]; ok {
+		l.Favorite = fav
+	}
+	return fav
+}
+
+// Favorites returns the favorite addresses in order.
+func (b *ServerBrowser) Favorites() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	favs := make([]string, 0, len(b.favorites))
+	for addr := range b.favorites {
+		favs = append(favs, addr)
+	}
+	sort.Strings(favs)
+	return favs
+}
+
+// Listings returns copies of the servers passing the filter, favorites
+// first and then in the sort order.
+func (b *ServerBrowser) Listings() []ServerListing {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	list := make([]ServerListing, 0, len(b.servers))
+	for _, l := range b.servers {
+		if b.filter.Matches(l) {
+			list = append(list, *l)
+		}
+	}
+	key := b.sortKey
+	sort.Slice(list, func(i, j int) bool {
+		a, c := &list[i], &list[j]
+		if a.Favorite != c.Favorite {
+			return a.Favorite
+		}
+		switch key {
+		case SortByPing:
+			if a.Reachable != c.Reachable {
+				return a.Reachable
+			}
+			if a.Reachable && a.Ping != c.Ping {
+				return a.Ping < c.Ping
+			}
+		case SortByPlayers:
+			if a.Players != c.Players {
+				return a.Players > c.Players
+			}
+		}
+		if a.Name != c.Name {
+			return a.Name < c.Name
+		}
+		return a.Address < c.Address
+	})
+	return list
+}
@@ -0,0 +1,165 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/federation"
+)
+
+func TestParseServerAddress(t *testing.T) {
+	tests := map[string]string{
+		"example.com":        "example.com:7777",
+		" 10.0.0.5:9000 ":    "10.0.0.5:9000",
+		"[::1]":              "[::1]:7777",
+		"::1":                "[::1]:7777",
+		"[2001:db8::1]:8000": "[2001:db8::1]:8000",
+	}
+	for in, want := range tests {
+		got, err := ParseServerAddress(in)
+		if err != nil || got != want {
+			t.Errorf("ParseServerAddress(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "   ", "host:", "host:abc", "host:70000", ":7777", "bad host"} {
+		if _, err := ParseServerAddress(in); !errors.Is(err, ErrInvalidAddress) {
+			t.Errorf("ParseServerAddress(%q) error = %v, want ErrInvalidAddress", in, err)
+		}
+	}
+}
+
+func TestPingServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	if _, err := PingServer(addr, 100*time.Millisecond); err == nil {
+		t.Error("ping of a closed port succeeded")
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skip("port was reused:", err)
+	}
+	defer ln.Close()
+	if _, err := PingServer(addr, time.Second); err != nil {
+		t.Errorf("PingServer() error = %v", err)
+	}
+}
+
+func TestServerBrowser(t *testing.T) {
+	b := NewServerBrowser([]string{"private:7777"})
+	b.SetPinger(func(addr string, timeout time.Duration) (time.Duration, error) {
+		switch addr {
+		case "a:7777":
+			return 80 * time.Millisecond, nil
+		case "b:7777":
+			return 20 * time.Millisecond, nil
+		case "c:7777":
+			return 50 * time.Millisecond, nil
+		}
+		return 0, errors.New("unreachable")
+	})
+	b.SetServers([]federation.ServerAnnouncement{
+		{Name: "Alpha", Address: "a:7777", Mode: "coop", Genre: "fantasy", Region: federation.RegionEUWest, Players: 2, MaxPlayers: 4},
+		{Name: "Bravo", Address: "b:7777", Mode: "ffa", Genre: "scifi", Region: federation.RegionUSEast, Players: 8, MaxPlayers: 8},
+		{Name: "Charlie", Address: "c:7777", Mode: "ffa", Genre: "fantasy", Region: federation.RegionUSEast, Players: 5, MaxPlayers: 16},
+		{Name: "Delta", Address: "d:7777", Mode: "team", Genre: "fantasy", Region: federation.RegionUSEast, Players: 0, MaxPlayers: 16},
+	})
+	b.PingAll(time.Second)
+
+	names := func() string {
+		var out []string
+		for _, l := range b.Listings() {
+			out = append(out, l.Name)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// Favorites first, then by ping with unreachable servers last
+	if got := names(); got != "private:7777,Bravo,Charlie,Alpha,Delta" {
+		t.Errorf("by ping: %s", got)
+	}
+	b.SetSortKey(SortByPlayers)
+	if got := names(); got != "private:7777,Bravo,Charlie,Alpha,Delta" {
+		t.Errorf("by players: %s", got)
+	}
+	b.SetSortKey(SortByName)
+	if got := names(); got != "private:7777,Alpha,Bravo,Charlie,Delta" {
+		t.Errorf("by name: %s", got)
+	}
+	if SortByName.Next() != SortByPing {
+		t.Error("sort keys should wrap around")
+	}
+
+	filters := map[string]BrowserFilter{
+		"Bravo,Charlie":       {Mode: "ffa"},
+		"Alpha,Charlie,Delta": {Genre: "fantasy"},
+		"Bravo,Charlie,Delta": {Region: federation.RegionUSEast},
+		"Alpha,Charlie":       {MinPlayers: 1, HideFull: true},
+		"Alpha,Delta":         {MaxPlayers: 2, Genre: "fantasy"},
+		"private:7777":        {FavoritesOnly: true},
+	}
+	for want, f := range filters {
+		b.SetFilter(f)
+		if got := names(); got != want {
+			t.Errorf("filter %+v: %s, want %s", f, got, want)
+		}
+	}
+	b.SetFilter(BrowserFilter{})
+
+	if !b.ToggleFavorite("c:7777") {
+		t.Error("ToggleFavorite() should add a new favorite")
+	}
+	if b.ToggleFavorite("private:7777") {
+		t.Error("ToggleFavorite() should remove an existing favorite")
+	}
+	if got := strings.Join(b.Favorites(), ","); got != "c:7777" {
+		t.Errorf("Favorites() = %s", got)
+	}
+	if got := names(); got != "Charlie,Alpha,Bravo,Delta,private:7777" {
+		t.Errorf("after toggling favorites: %s", got)
+	}
+
+	// A refresh drops unannounced non-favorites and keeps known pings
+	b.SetServers([]federation.ServerAnnouncement{{Name: "Charlie", Address: "c:7777"}})
+	list := b.Listings()
+	if len(list) != 1 || !list[0].Reachable || list[0].Ping != 50*time.Millisecond || !list[0].Favorite {
+		t.Errorf("after refresh: %+v", list)
+	}
+}
+
+func TestServerBrowser_LiveUpdates(t *testing.T) {
+	b := NewServerBrowser([]string{"fav:7777"})
+	b.SetPinger(func(addr string, timeout time.Duration) (time.Duration, error) {
+		return 30 * time.Millisecond, nil
+	})
+	b.SetServers([]federation.ServerAnnouncement{{Name: "Fav", Address: "fav:7777"}})
+
+	if !b.UpdateServer(federation.ServerAnnouncement{Name: "New", Address: "new:7777", Players: 1}) {
+		t.Error("UpdateServer() of an unlisted server should report it new")
+	}
+	b.Ping("new:7777", time.Second)
+	if b.UpdateServer(federation.ServerAnnouncement{Name: "New", Address: "new:7777", Players: 2}) {
+		t.Error("UpdateServer() of a listed server reported it new")
+	}
+	b.SetSortKey(SortByName)
+	list := b.Listings()
+	if len(list) != 2 || list[1].Players != 2 || list[1].Ping != 30*time.Millisecond {
+		t.Fatalf("after updates: %+v", list)
+	}
+
+	// An expired favorite stays, listed by address
+	b.Ping("fav:7777", time.Second)
+	b.RemoveServer("new:7777")
+	b.RemoveServer("fav:7777")
+	list = b.Listings()
+	if len(list) != 1 || list[0].Name != "fav:7777" || !list[0].Favorite || !list[0].Reachable {
+		t.Errorf("after removals: %+v", list)
+	}
+}
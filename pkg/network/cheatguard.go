@@ -0,0 +1,318 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+	"github.com/sirupsen/logrus"
+)
+
+// CommandShoot fires the player's weapon; its data is a ShootCommand.
+const CommandShoot = "shoot"
+
+// KickSuspicionScore is the suspicion score at which a player whose
+// commands keep being corrected is kicked instead, so small violations
+// add up.
+const KickSuspicionScore = 20
+
+// ShootCommand is the data of a shoot command: the weapon fired and what
+// the client says the shot did.
+type ShootCommand struct {
+	WeaponID int     `json:"weapon_id"`
+	Hit      bool    `json:"hit"`
+	Damage   int     `json:"damage,omitempty"`
+	Distance float64 `json:"distance,omitempty"`
+	Headshot bool    `json:"headshot,omitempty"`
+}
+
+// CheatCheck names one of the anti-cheat checks.
+type CheatCheck string
+
+// The anti-cheat checks.
+const (
+	CheckMovement CheatCheck = "movement" // Moving too fast
+	CheckTeleport CheatCheck = "teleport" // Moving impossibly fast
+	CheckFireRate CheatCheck = "fire_rate"
+	CheckAmmo     CheatCheck = "ammo"
+	CheckDamage   CheatCheck = "damage" // Impossible damage and range
+	CheckAccuracy CheatCheck = "accuracy"
+)
+
+// CheatResponse is what the server does about a failed check.
+type CheatResponse int
+
+const (
+	// ResponseCorrect drops the command, so the authoritative state
+	// stands and the client is corrected by its next update.
+	ResponseCorrect CheatResponse = iota
+	// ResponseWarn logs the violation and applies the command anyway.
+	ResponseWarn
+	// ResponseKick disconnects the player without letting them resume.
+	ResponseKick
+)
+
+// cheatResponseNames are the responses by name, for configuration.
+var cheatResponseNames = map[string]CheatResponse{
+	"correct": ResponseCorrect,
+	"warn":    ResponseWarn,
+	"kick":    ResponseKick,
+}
+
+// String returns the response's name.
+func (r CheatResponse) String() string {
+	for name, resp := range cheatResponseNames {
+		if resp == r {
+			return name
+		}
+	}
+	return fmt.Sprintf("CheatResponse(%d)", int(r))
+}
+
+// DefaultCheatResponses correct what can be corrected, kick for
+// teleports and impossible damage, and only warn about accuracy, which
+// needs review.
+func DefaultCheatResponses() map[CheatCheck]CheatResponse {
+	return map[CheatCheck]CheatResponse{
+		CheckMovement: ResponseCorrect,
+		CheckTeleport: ResponseKick,
+		CheckFireRate: ResponseCorrect,
+		CheckAmmo:     ResponseCorrect,
+		CheckDamage:   ResponseKick,
+		CheckAccuracy: ResponseWarn,
+	}
+}
+
+// ParseCheatResponses parses responses written as "check=response" pairs
+// separated by commas, such as "movement=kick,damage=warn", over the
+// defaults.
+func ParseCheatResponses(s string) (map[CheatCheck]CheatResponse, error) {
+	responses := DefaultCheatResponses()
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		check, name, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid anti-cheat response %q, want check=response", pair)
+		}
+		if _, known := responses[CheatCheck(check)]; !known {
+			return nil, fmt.Errorf("unknown anti-cheat check %q", check)
+		}
+		resp, known := cheatResponseNames[name]
+		if !known {
+			return nil, fmt.Errorf("unknown anti-cheat response %q, want correct, warn or kick", name)
+		}
+		responses[CheatCheck(check)] = resp
+	}
+	return responses, nil
+}
+
+// CheatError is returned by AntiCheat for a command that failed a check.
+type CheatError struct {
+	Check    CheatCheck
+	Result   ValidationResult
+	Response CheatResponse
+}
+
+func (e *CheatError) Error() string {
+	return fmt.Sprintf("anti-cheat %s check failed: %s", e.Check, e.Result.Violation)
+}
+
+// cheatPlayer is what AntiCheat knows about one player.
+type cheatPlayer struct {
+	stats    AntiCheatStats
+	lastMove time.Time
+	ammo     map[int]int // Rounds by weapon ID, for weapons that use ammo
+}
+
+// AntiCheat is a CommandValidator that checks moves against the speed
+// limits, and shots against the weapon's fire rate, the player's ammo
+// and what the weapon can do. Failed checks are handled by the response
+// configured for them.
+type AntiCheat struct {
+	mu        sync.Mutex
+	weapons   map[int]WeaponDefinition
+	players   map[uint64]*cheatPlayer
+	responses map[CheatCheck]CheatResponse
+}
+
+// NewAntiCheat creates an anti-cheat validator with the given responses;
+// nil means DefaultCheatResponses.
+func NewAntiCheat(responses map[CheatCheck]CheatResponse) *AntiCheat {
+	if responses == nil {
+		responses = DefaultCheatResponses()
+	}
+	return &AntiCheat{
+		weapons:   make(map[int]WeaponDefinition),
+		players:   make(map[uint64]*cheatPlayer),
+		responses: responses,
+	}
+}
+
+// RegisterWeapon adds a weapon shots can be checked against. Once any
+// are registered, shots with unregistered weapons fail the damage check;
+// until then only the overall fire rate and ammo are checked.
+func (a *AntiCheat) RegisterWeapon(def WeaponDefinition) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.weapons[def.ID] = def
+}
+
+// SetAmmo sets how many rounds a player has for a weapon, as the server
+// hands them out. Weapons whose ammo was never set aren't checked.
+func (a *AntiCheat) SetAmmo(playerID uint64, weaponID, rounds int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.player(playerID).ammo[weaponID] = rounds
+}
+
+// Ammo returns how many rounds a player has left for a weapon, and
+// whether it's tracked.
+func (a *AntiCheat) Ammo(playerID uint64, weaponID int) (int, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rounds, ok := a.player(playerID).ammo[weaponID]
+	return rounds, ok
+}
+
+// Stats returns a copy of a player's anti-cheat statistics.
+func (a *AntiCheat) Stats(playerID uint64) AntiCheatStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := a.player(playerID).stats
+	stats.RecentShotTimes = append([]time.Time(nil), stats.RecentShotTimes...)
+	return stats
+}
+
+// player returns a player's state, creating it. Caller must hold a.mu.
+func (a *AntiCheat) player(playerID uint64) *cheatPlayer {
+	p, ok := a.players[playerID]
+	if !ok {
+		p = &cheatPlayer{ammo: make(map[int]int)}
+		a.players[playerID] = p
+	}
+	return p
+}
+
+// Validate checks move and shoot commands. A failed check returns a
+// *CheatError with the response to it.
+func (a *AntiCheat) Validate(cmd *PlayerCommand, w *engine.World) error {
+	if err := (&DefaultValidator{}).Validate(cmd, w); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p := a.player(cmd.PlayerID)
+
+	switch cmd.Type {
+	case CommandMove:
+		var move MoveCommand
+		if err := json.Unmarshal(cmd.Data, &move); err != nil {
+			return fmt.Errorf("invalid move command: %w", err)
+		}
+		return a.checkMove(cmd, p, move)
+	case CommandShoot:
+		var shot ShootCommand
+		if err := json.Unmarshal(cmd.Data, &shot); err != nil {
+			return fmt.Errorf("invalid shoot command: %w", err)
+		}
+		return a.checkShot(cmd, p, shot)
+	}
+	return nil
+}
+
+// checkMove checks a step against the time since the player's last one.
+// Steps closer together than a tick count as a tick apart, so commands
+// that arrive bunched up after a stall aren't taken for speed hacks.
+func (a *AntiCheat) checkMove(cmd *PlayerCommand, p *cheatPlayer, move MoveCommand) error {
+	elapsed := TickDuration
+	if !p.lastMove.IsZero() && cmd.Timestamp.Sub(p.lastMove) > elapsed {
+		elapsed = cmd.Timestamp.Sub(p.lastMove)
+	}
+	result := ValidateMovement(Vec2{}, Vec2{X: move.DX, Y: move.DY}, elapsed.Seconds(), move.Sprint)
+	check := CheckMovement
+	if result.Severity >= SeverityKick {
+		check = CheckTeleport
+	}
+	if err := a.violation(cmd, p, check, result); err != nil && err.Response != ResponseWarn {
+		return err
+	}
+	p.lastMove = cmd.Timestamp
+	return nil
+}
+
+// checkShot checks a shot's fire rate, ammo and damage, and the player's
+// accuracy with it counted.
+func (a *AntiCheat) checkShot(cmd *PlayerCommand, p *cheatPlayer, shot ShootCommand) error {
+	weapon, ok := a.weapons[shot.WeaponID]
+	if !ok && len(a.weapons) > 0 {
+		result := ValidationResult{Violation: fmt.Sprintf("unknown weapon %d", shot.WeaponID), Severity: SeverityWarning}
+		if err := a.violation(cmd, p, CheckDamage, result); err != nil && err.Response != ResponseWarn {
+			return err
+		}
+	}
+
+	if err := a.violation(cmd, p, CheckFireRate, ValidateFireRate(&p.stats, weapon, cmd.Timestamp)); err != nil && err.Response != ResponseWarn {
+		return err
+	}
+	if rounds, tracked := p.ammo[shot.WeaponID]; tracked {
+		if rounds <= 0 {
+			result := ValidationResult{Violation: "fired with no ammo", Severity: SeverityWarning}
+			if err := a.violation(cmd, p, CheckAmmo, result); err != nil && err.Response != ResponseWarn {
+				return err
+			}
+		} else {
+			p.ammo[shot.WeaponID] = rounds - 1
+		}
+	}
+	if shot.Hit && ok {
+		result := ValidateDamage(weapon, shot.Damage, shot.Distance, shot.Headshot)
+		if err := a.violation(cmd, p, CheckDamage, result); err != nil && err.Response != ResponseWarn {
+			return err
+		}
+	}
+
+	RecordShot(&p.stats, shot.Hit, shot.Headshot, cmd.Timestamp)
+	if err := a.violation(cmd, p, CheckAccuracy, CheckStatisticalAnomaly(&p.stats)); err != nil && err.Response != ResponseWarn {
+		return err
+	}
+	return nil
+}
+
+// violation records and logs a failed check and returns its error, or
+// nil if the check passed. A correction for a player whose suspicion
+// score has built up becomes a kick.
+func (a *AntiCheat) violation(cmd *PlayerCommand, p *cheatPlayer, check CheatCheck, result ValidationResult) *CheatError {
+	if result.Valid {
+		return nil
+	}
+	RecordViolation(&p.stats, result.Severity)
+	response := a.responses[check]
+	if response == ResponseCorrect && p.stats.SuspicionScore >= KickSuspicionScore {
+		response = ResponseKick
+	}
+
+	entry := logrus.WithFields(logrus.Fields{
+		"system_name": "anticheat",
+		"player_id":   cmd.PlayerID,
+		"command":     cmd.Type,
+		"sequence":    cmd.Sequence,
+		"check":       string(check),
+		"violation":   result.Violation,
+		"severity":    result.Severity,
+		"suspicion":   p.stats.SuspicionScore,
+		"response":    response.String(),
+	})
+	if response == ResponseKick {
+		entry.Error("Anti-cheat violation")
+	} else {
+		entry.Warn("Anti-cheat violation")
+	}
+	return &CheatError{Check: check, Result: result, Response: response}
+}
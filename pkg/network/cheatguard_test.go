@@ -0,0 +1,150 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// cheatCmd returns a command from player 1 at the given time.
+func cheatCmd(t *testing.T, typ string, data interface{}, at time.Time) *PlayerCommand {
+	t.Helper()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &PlayerCommand{PlayerID: 1, Type: typ, Data: raw, Timestamp: at}
+}
+
+// cheatResponse returns the response to a failed check, or -1 if the
+// command passed.
+func cheatResponse(t *testing.T, err error) CheatResponse {
+	t.Helper()
+	if err == nil {
+		return -1
+	}
+	var cheat *CheatError
+	if !errors.As(err, &cheat) {
+		t.Fatalf("error %v is not a CheatError", err)
+	}
+	return cheat.Response
+}
+
+func TestAntiCheatMovement(t *testing.T) {
+	ac := NewAntiCheat(nil)
+	world := engine.NewWorld()
+	now := time.Now()
+
+	// A sprint step a tick is fine, even when steps arrive bunched up
+	for i := 0; i < 3; i++ {
+		cmd := cheatCmd(t, CommandMove, MoveCommand{DX: 0.6, Sprint: true}, now)
+		if err := ac.Validate(cmd, world); err != nil {
+			t.Fatalf("sprint step %d: %v", i, err)
+		}
+	}
+	// Too fast is corrected, a teleport kicks
+	if got := cheatResponse(t, ac.Validate(cheatCmd(t, CommandMove, MoveCommand{DX: 0.7}, now), world)); got != ResponseCorrect {
+		t.Errorf("fast step response = %v, want correct", got)
+	}
+	if got := cheatResponse(t, ac.Validate(cheatCmd(t, CommandMove, MoveCommand{DX: 5}, now), world)); got != ResponseKick {
+		t.Errorf("teleport response = %v, want kick", got)
+	}
+	if ac.Stats(1).SuspicionScore != SeverityWarning+SeverityKick {
+		t.Errorf("suspicion = %d", ac.Stats(1).SuspicionScore)
+	}
+}
+
+func TestAntiCheatShots(t *testing.T) {
+	responses, err := ParseCheatResponses("fire_rate=warn, accuracy=correct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ac := NewAntiCheat(responses)
+	ac.RegisterWeapon(WeaponDefinition{ID: 2, BaseDamage: 10, HeadshotMult: 2, IsHitscan: true, MaxFireRate: 2})
+	ac.SetAmmo(1, 2, 1)
+	world := engine.NewWorld()
+	now := time.Now()
+
+	if err := ac.Validate(cheatCmd(t, CommandShoot, ShootCommand{WeaponID: 2, Hit: true, Damage: 10}, now), world); err != nil {
+		t.Fatalf("honest shot: %v", err)
+	}
+	if rounds, ok := ac.Ammo(1, 2); !ok || rounds != 0 {
+		t.Errorf("ammo = %d, %v; want 0 left", rounds, ok)
+	}
+	if got := cheatResponse(t, ac.Validate(cheatCmd(t, CommandShoot, ShootCommand{WeaponID: 2}, now.Add(time.Second)), world)); got != ResponseCorrect {
+		t.Errorf("shot without ammo response = %v, want correct", got)
+	}
+
+	ac.SetAmmo(1, 2, 10)
+	// Three shots in a second is over the rate, but only warned about
+	for i := 0; i < 3; i++ {
+		ac.Validate(cheatCmd(t, CommandShoot, ShootCommand{WeaponID: 2}, now.Add(2*time.Second+time.Duration(i)*time.Millisecond)), world)
+	}
+	if rounds, _ := ac.Ammo(1, 2); rounds != 7 {
+		t.Errorf("ammo = %d, want 7 after warned shots went through", rounds)
+	}
+
+	if got := cheatResponse(t, ac.Validate(cheatCmd(t, CommandShoot, ShootCommand{WeaponID: 2, Hit: true, Damage: 500}, now.Add(5*time.Second)), world)); got != ResponseKick {
+		t.Errorf("impossible damage response = %v, want kick", got)
+	}
+	if got := cheatResponse(t, ac.Validate(cheatCmd(t, CommandShoot, ShootCommand{WeaponID: 9}, now.Add(6*time.Second)), world)); got != ResponseKick {
+		t.Errorf("unknown weapon response = %v, want kick", got)
+	}
+}
+
+func TestParseCheatResponses(t *testing.T) {
+	responses, err := ParseCheatResponses("movement=kick")
+	if err != nil || responses[CheckMovement] != ResponseKick || responses[CheckDamage] != ResponseKick || responses[CheckAmmo] != ResponseCorrect {
+		t.Errorf("ParseCheatResponses() = %v, %v", responses, err)
+	}
+	for _, bad := range []string{"movement", "wallhack=kick", "movement=ban"} {
+		if _, err := ParseCheatResponses(bad); err == nil {
+			t.Errorf("ParseCheatResponses(%q) should fail", bad)
+		}
+	}
+	if ResponseWarn.String() != "warn" {
+		t.Errorf("ResponseWarn.String() = %q", ResponseWarn.String())
+	}
+}
+
+func TestGameServer_AntiCheatKick(t *testing.T) {
+	world := engine.NewWorld()
+	server, err := NewGameServer(0, world)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+	server.SetValidator(NewAntiCheat(nil))
+	server.SetPlayerSpawner(func(w *engine.World, clientID uint64) engine.Entity {
+		return w.NewPlayerEntity(3, 3)
+	})
+
+	conn, deltas := pipeClient(t, server)
+	defer conn.Close()
+	nextDelta(t, server, deltas)
+	server.mu.RLock()
+	player := server.clients[0].entity
+	server.mu.RUnlock()
+
+	data, _ := json.Marshal(MoveCommand{DX: 50})
+	json.NewEncoder(conn).Encode(&PlayerCommand{Type: CommandMove, Data: data})
+	for i := 0; i < 100 && server.GetClientCount() > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+		server.tick()
+	}
+	if server.GetClientCount() != 0 {
+		t.Fatal("teleporting player was not kicked")
+	}
+	if _, ok := world.GetComponent(player, reflect.TypeOf(&engine.Position{})); ok {
+		t.Error("kicked player's entity is still in the world")
+	}
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+	if len(server.sessions) != 0 {
+		t.Error("kicked player can resume their session")
+	}
+}
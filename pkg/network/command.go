@@ -0,0 +1,90 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+const (
+	// CommandMove moves and turns the player; its data is a MoveCommand.
+	CommandMove = "move"
+
+	// DefaultMaxMoveStep is the furthest, in tiles, DefaultApplier moves a
+	// player for one command: 8 tiles a second at one command a tick.
+	DefaultMaxMoveStep = 8.0 / TickRate
+)
+
+// CommandApplier applies validated player commands to the world, as the
+// player's entity.
+type CommandApplier interface {
+	Apply(cmd *PlayerCommand, player engine.Entity, w *engine.World) error
+}
+
+// MoveCommand is the data of a move command: how far the player moved,
+// in tiles, the direction they face, and whether they're sprinting. A
+// zero direction keeps the current one.
+type MoveCommand struct {
+	DX     float64 `json:"dx"`
+	DY     float64 `json:"dy"`
+	DirX   float64 `json:"dir_x"`
+	DirY   float64 `json:"dir_y"`
+	Sprint bool    `json:"sprint,omitempty"`
+}
+
+// DefaultApplier applies move commands, capping each step and stopping
+// players at walls. Other commands are left to the game's systems.
+type DefaultApplier struct {
+	// MaxStep caps the distance one command moves a player; zero means
+	// DefaultMaxMoveStep.
+	MaxStep float64
+	// Blocked reports whether a player can't stand at a position. Nil
+	// means nothing blocks.
+	Blocked func(x, y float64) bool
+}
+
+// Apply moves and turns the player for a move command.
+func (a *DefaultApplier) Apply(cmd *PlayerCommand, player engine.Entity, w *engine.World) error {
+	if cmd.Type != CommandMove {
+		return nil
+	}
+	var move MoveCommand
+	if err := json.Unmarshal(cmd.Data, &move); err != nil {
+		return fmt.Errorf("invalid move command: %w", err)
+	}
+
+	comp, ok := w.GetComponent(player, reflect.TypeOf(&engine.Position{}))
+	if !ok {
+		return fmt.Errorf("player entity %d has no position", player)
+	}
+	pos := comp.(*engine.Position)
+
+	maxStep := a.MaxStep
+	if maxStep <= 0 {
+		maxStep = DefaultMaxMoveStep
+	}
+	if dist := math.Hypot(move.DX, move.DY); dist > maxStep {
+		move.DX *= maxStep / dist
+		move.DY *= maxStep / dist
+	}
+	// Each axis on its own, so players slide along walls
+	if a.Blocked == nil || !a.Blocked(pos.X+move.DX, pos.Y) {
+		pos.X += move.DX
+	}
+	if a.Blocked == nil || !a.Blocked(pos.X, pos.Y+move.DY) {
+		pos.Y += move.DY
+	}
+
+	if comp, ok := w.GetComponent(player, reflect.TypeOf(&engine.Camera{})); ok {
+		if length := math.Hypot(move.DirX, move.DirY); length > 0 {
+			cam := comp.(*engine.Camera)
+			planeLen := math.Hypot(cam.PlaneX, cam.PlaneY)
+			cam.DirX, cam.DirY = move.DirX/length, move.DirY/length
+			cam.PlaneX, cam.PlaneY = -cam.DirY*planeLen, cam.DirX*planeLen
+		}
+	}
+	return nil
+}
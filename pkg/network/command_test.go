@@ -0,0 +1,66 @@
+package network
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// moveCmd returns a move command with the given data.
+func moveCmd(t *testing.T, move MoveCommand) *PlayerCommand {
+	t.Helper()
+	data, err := json.Marshal(move)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &PlayerCommand{Type: CommandMove, Data: data}
+}
+
+func TestDefaultApplier(t *testing.T) {
+	world := engine.NewWorld()
+	player := world.NewPlayerEntity(5, 5)
+	applier := &DefaultApplier{
+		MaxStep: 1,
+		Blocked: func(x, y float64) bool { return x >= 7 },
+	}
+	position := func() engine.Position {
+		comp, _ := world.GetComponent(player, reflect.TypeOf(&engine.Position{}))
+		return *comp.(*engine.Position)
+	}
+
+	if err := applier.Apply(moveCmd(t, MoveCommand{DX: 0.5, DY: -0.25}), player, world); err != nil {
+		t.Fatal(err)
+	}
+	if pos := position(); pos.X != 5.5 || pos.Y != 4.75 {
+		t.Errorf("position = %+v, want (5.5, 4.75)", pos)
+	}
+
+	// Capped at MaxStep
+	applier.Apply(moveCmd(t, MoveCommand{DY: 10}), player, world)
+	if pos := position(); pos.Y != 5.75 {
+		t.Errorf("Y = %v, want a step of 1", pos.Y)
+	}
+
+	// Blocked on X, still slides on Y
+	applier.Apply(moveCmd(t, MoveCommand{DX: 0.6, DY: 0.6}), player, world)
+	applier.Apply(moveCmd(t, MoveCommand{DX: 0.6, DY: 0.6}), player, world)
+	if pos := position(); pos.X >= 7 || math.Abs(pos.Y-(5.75+1.2)) > 1e-9 {
+		t.Errorf("position = %+v, want stopped short of x=7", pos)
+	}
+
+	applier.Apply(moveCmd(t, MoveCommand{DirX: 2}), player, world)
+	comp, _ := world.GetComponent(player, reflect.TypeOf(&engine.Camera{}))
+	if cam := comp.(*engine.Camera); cam.DirX != 1 || cam.DirY != 0 || cam.PlaneX != 0 || cam.PlaneY <= 0 {
+		t.Errorf("camera = %+v, want facing east", cam)
+	}
+
+	if err := applier.Apply(&PlayerCommand{Type: CommandMove, Data: []byte("{")}, player, world); err == nil {
+		t.Error("malformed move should fail")
+	}
+	if err := applier.Apply(&PlayerCommand{Type: "shoot"}, player, world); err != nil {
+		t.Errorf("other commands should pass through: %v", err)
+	}
+}
@@ -5,6 +5,12 @@
 // ProcessBleedouts checks for expired timers and returns players ready to respawn.
 // RespawnPlayer places them at the nearest living teammate's position with full health.
 // If all players die (party wipe), RestartLevel resets the level with regenerated objectives.
+//
+// Drop-in/drop-out:
+// Players can Join a session that's already under way. The session keeps the level's
+// changes since it was generated (tile mutations, enemies, objectives), and a joining
+// player gets them as a LevelSnapshot and spawns next to the teammate furthest from
+// any enemy. Enemy budgets grow with the number of players.
 package network
 
 import (
@@ -54,6 +60,9 @@ type CoopSession struct {
 	Started        bool
 	LevelCompleted bool
 	CreatedAt      time.Time
+	genre          string
+	tiles          map[[2]int]int        // Map changes since generation, by position
+	enemies        map[uint64]*CoopEnemy // Living enemies, by ID
 }
 
 // NewCoopSession creates a new co-op session with specified max players (2-4).
@@ -70,6 +79,9 @@ func NewCoopSession(sessionID string, maxPlayers int, levelSeed uint64) (*CoopSe
 		LevelSeed:    levelSeed,
 		MaxPlayers:   maxPlayers,
 		CreatedAt:    time.Now(),
+		genre:        "fantasy",
+		tiles:        make(map[[2]int]int),
+		enemies:      make(map[uint64]*CoopEnemy),
 	}, nil
 }
 
@@ -87,11 +99,25 @@ func (s *CoopSession) AddPlayer(playerID uint64) error {
 		return fmt.Errorf("player %d already in session", playerID)
 	}
 
+	s.Players[playerID] = s.newPlayer(playerID)
+
+	logrus.WithFields(logrus.Fields{
+		"system_name":  "coop_session",
+		"session_id":   s.SessionID,
+		"player_id":    playerID,
+		"player_count": len(s.Players),
+	}).Info("Player joined co-op session")
+
+	return nil
+}
+
+// newPlayer creates a player's state and entity (must be called with lock held).
+func (s *CoopSession) newPlayer(playerID uint64) *CoopPlayerState {
 	// Create player entity in world
 	entityID := s.World.AddEntity()
 
 	// Initialize player state with independent inventory
-	playerState := &CoopPlayerState{
+	return &CoopPlayerState{
 		PlayerID:  playerID,
 		EntityID:  entityID,
 		Inventory: inventory.NewInventory(),
@@ -102,17 +128,6 @@ func (s *CoopSession) AddPlayer(playerID uint64) error {
 		PosX:      0.0,
 		PosY:      0.0,
 	}
-
-	s.Players[playerID] = playerState
-
-	logrus.WithFields(logrus.Fields{
-		"system_name":  "coop_session",
-		"session_id":   s.SessionID,
-		"player_id":    playerID,
-		"player_count": len(s.Players),
-	}).Info("Player joined co-op session")
-
-	return nil
 }
 
 // RemovePlayer removes a player from the session and marks them as inactive.
@@ -313,6 +328,7 @@ func (s *CoopSession) SetGenre(genreID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	s.genre = genreID
 	s.World.SetGenre(genreID)
 	s.QuestTracker.SetGenre(genreID)
 }
@@ -482,10 +498,13 @@ func (s *CoopSession) RestartLevel() error {
 		p.mu.Unlock()
 	}
 
-	// Reset quest progress
+	// Reset quest progress and the level's changes
 	s.QuestTracker = quest.NewTracker()
+	s.QuestTracker.SetGenre(s.genre)
 	s.QuestTracker.Generate(s.LevelSeed, 3)
 	s.LevelCompleted = false
+	s.tiles = make(map[[2]int]int)
+	s.enemies = make(map[uint64]*CoopEnemy)
 
 	logrus.WithFields(logrus.Fields{
 		"system_name": "coop_session",
@@ -0,0 +1,265 @@
+package network
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/quest"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// CoopEnemyScale is how much the enemy budget grows for each player
+	// after the first.
+	CoopEnemyScale = 0.5
+
+	// SafeSpawnRadius is how close an enemy can be to a teammate before
+	// spawning a joining player next to them stops counting as safe.
+	SafeSpawnRadius = 8.0
+)
+
+// TileMutation is a change to the level's map since it was generated,
+// such as a door opened or a wall destroyed.
+type TileMutation struct {
+	X    int `json:"x"`
+	Y    int `json:"y"`
+	Tile int `json:"tile"`
+}
+
+// CoopEnemy is a living enemy as the session tracks it for players who
+// join mid-level.
+type CoopEnemy struct {
+	ID        uint64  `json:"id"`
+	Kind      string  `json:"kind"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Health    float64 `json:"health"`
+	MaxHealth float64 `json:"maxHealth"`
+}
+
+// CoopPlayerInfo is a teammate as a joining player first sees them.
+type CoopPlayerInfo struct {
+	PlayerID uint64  `json:"playerID"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Health   float64 `json:"health"`
+	Dead     bool    `json:"dead"`
+}
+
+// LevelSnapshot is the level as it stands when a player joins mid-level:
+// enough to regenerate it from the seed and then catch up on what's
+// happened since.
+type LevelSnapshot struct {
+	SessionID  string            `json:"sessionID"`
+	LevelSeed  uint64            `json:"levelSeed"`
+	Genre      string            `json:"genre"`
+	Tiles      []TileMutation    `json:"tiles"`   // By row, then column
+	Enemies    []CoopEnemy       `json:"enemies"` // By ID
+	Objectives []quest.Objective `json:"objectives"`
+	Players    []CoopPlayerInfo  `json:"players"` // The joining player's teammates, by ID
+	SpawnX     float64           `json:"spawnX"`  // Where the joining player spawns
+	SpawnY     float64           `json:"spawnY"`
+}
+
+// ScaleEnemyBudget returns a level's enemy budget for a party of players:
+// CoopEnemyScale more of the base for each player after the first.
+func ScaleEnemyBudget(base, players int) int {
+	if players < 1 {
+		players = 1
+	}
+	return int(math.Round(float64(base) * (1 + CoopEnemyScale*float64(players-1))))
+}
+
+// EnemyBudget scales a level's enemy budget to the session's active players.
+func (s *CoopSession) EnemyBudget(base int) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return ScaleEnemyBudget(base, s.getActivePlayerCount())
+}
+
+// SetTile records a change to the level's map, for players who join later.
+func (s *CoopSession) SetTile(x, y, tile int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tiles[[2]int{x, y}] = tile
+}
+
+// UpdateEnemy records an enemy's current state, adding it if it's new.
+func (s *CoopSession) UpdateEnemy(e CoopEnemy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enemy := e
+	s.enemies[e.ID] = &enemy
+}
+
+// RemoveEnemy forgets an enemy once it's dead.
+func (s *CoopSession) RemoveEnemy(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.enemies, id)
+}
+
+// Join adds a player to the session, or brings back one who dropped out.
+// Before the session starts they join like anyone else and Join returns
+// nil. Once it's under way they spawn at a safe point with full health,
+// and Join returns the level so far for the server to send them. A new
+// player takes a dropped-out player's place if the session is otherwise
+// full.
+func (s *CoopSession) Join(playerID uint64) (*LevelSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	player, exists := s.Players[playerID]
+	if exists {
+		player.mu.RLock()
+		active := player.Active
+		player.mu.RUnlock()
+		if active {
+			return nil, fmt.Errorf("player %d already in session", playerID)
+		}
+	} else {
+		if s.getActivePlayerCount() >= s.MaxPlayers {
+			return nil, fmt.Errorf("session full: %d/%d players", s.getActivePlayerCount(), s.MaxPlayers)
+		}
+		if len(s.Players) >= s.MaxPlayers {
+			s.evictInactive()
+		}
+		player = s.newPlayer(playerID)
+		s.Players[playerID] = player
+	}
+
+	if !s.Started {
+		player.mu.Lock()
+		player.Active = true
+		player.mu.Unlock()
+		logrus.WithFields(logrus.Fields{
+			"system_name":  "coop_session",
+			"session_id":   s.SessionID,
+			"player_id":    playerID,
+			"player_count": len(s.Players),
+		}).Info("Player joined co-op session")
+		return nil, nil
+	}
+
+	x, y := s.safeSpawnPoint(playerID)
+	player.mu.Lock()
+	player.Active = true
+	player.Dead = false
+	player.Health = player.MaxHealth
+	player.PosX, player.PosY = x, y
+	player.BleedoutEndTime = time.Time{}
+	player.mu.Unlock()
+
+	snapshot := s.snapshot(playerID, x, y)
+	logrus.WithFields(logrus.Fields{
+		"system_name":  "coop_session",
+		"session_id":   s.SessionID,
+		"player_id":    playerID,
+		"spawn_x":      x,
+		"spawn_y":      y,
+		"enemies":      len(snapshot.Enemies),
+		"tiles":        len(snapshot.Tiles),
+		"active_count": s.getActivePlayerCount(),
+	}).Info("Player joined co-op session mid-level")
+	return snapshot, nil
+}
+
+// evictInactive frees the slot of the dropped-out player with the lowest
+// ID (must be called with lock held).
+func (s *CoopSession) evictInactive() {
+	var evict uint64
+	found := false
+	for id, p := range s.Players {
+		p.mu.RLock()
+		active := p.Active
+		p.mu.RUnlock()
+		if !active && (!found || id < evict) {
+			evict, found = id, true
+		}
+	}
+	if found {
+		s.World.RemoveEntity(s.Players[evict].EntityID)
+		delete(s.Players, evict)
+	}
+}
+
+// safeSpawnPoint picks where a joining player spawns: next to the living
+// teammate furthest from any enemy, preferring the lowest ID among those
+// out of SafeSpawnRadius, or the level start if nobody is alive (must be
+// called with lock held).
+func (s *CoopSession) safeSpawnPoint(joiner uint64) (x, y float64) {
+	ids := make([]uint64, 0, len(s.Players))
+	for id := range s.Players {
+		if id != joiner {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	best := -1.0
+	for _, id := range ids {
+		p := s.Players[id]
+		p.mu.RLock()
+		alive, px, py := p.Active && !p.Dead, p.PosX, p.PosY
+		p.mu.RUnlock()
+		if !alive {
+			continue
+		}
+		clearance := math.Min(s.nearestEnemy(px, py), SafeSpawnRadius)
+		if clearance > best {
+			best, x, y = clearance, px, py
+		}
+	}
+	return x, y
+}
+
+// nearestEnemy returns the distance from (x, y) to the nearest living
+// enemy (must be called with lock held).
+func (s *CoopSession) nearestEnemy(x, y float64) float64 {
+	nearest := math.MaxFloat64
+	for _, e := range s.enemies {
+		nearest = math.Min(nearest, math.Hypot(e.X-x, e.Y-y))
+	}
+	return nearest
+}
+
+// snapshot captures the level for a joining player (must be called with
+// lock held).
+func (s *CoopSession) snapshot(joiner uint64, spawnX, spawnY float64) *LevelSnapshot {
+	snap := &LevelSnapshot{
+		SessionID:  s.SessionID,
+		LevelSeed:  s.LevelSeed,
+		Genre:      s.genre,
+		Tiles:      make([]TileMutation, 0, len(s.tiles)),
+		Enemies:    make([]CoopEnemy, 0, len(s.enemies)),
+		Objectives: append([]quest.Objective(nil), s.QuestTracker.Objectives...),
+		Players:    make([]CoopPlayerInfo, 0, len(s.Players)),
+		SpawnX:     spawnX,
+		SpawnY:     spawnY,
+	}
+	for pos, tile := range s.tiles {
+		snap.Tiles = append(snap.Tiles, TileMutation{X: pos[0], Y: pos[1], Tile: tile})
+	}
+	sort.Slice(snap.Tiles, func(i, j int) bool {
+		a, b := snap.Tiles[i], snap.Tiles[j]
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X < b.X
+	})
+	for _, e := range s.enemies {
+		snap.Enemies = append(snap.Enemies, *e)
+	}
+	sort.Slice(snap.Enemies, func(i, j int) bool { return snap.Enemies[i].ID < snap.Enemies[j].ID })
+	for id, p := range s.Players {
+		p.mu.RLock()
+		if id != joiner && p.Active {
+			snap.Players = append(snap.Players, CoopPlayerInfo{PlayerID: id, X: p.PosX, Y: p.PosY, Health: p.Health, Dead: p.Dead})
+		}
+		p.mu.RUnlock()
+	}
+	sort.Slice(snap.Players, func(i, j int) bool { return snap.Players[i].PlayerID < snap.Players[j].PlayerID })
+	return snap
+}
@@ -0,0 +1,169 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// startedCoopSession starts a session with players 1 and 2, player 1 at
+// (10, 10) near an enemy and player 2 at (50, 50) in the clear.
+func startedCoopSession(t *testing.T, maxPlayers int) *CoopSession {
+	t.Helper()
+	s, _ := NewCoopSession("join", maxPlayers, 7)
+	s.AddPlayer(1)
+	s.AddPlayer(2)
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	s.UpdatePlayerPosition(1, 10, 10)
+	s.UpdatePlayerPosition(2, 50, 50)
+	s.UpdateEnemy(CoopEnemy{ID: 9, Kind: "grunt", X: 12, Y: 10, Health: 20, MaxHealth: 30})
+	return s
+}
+
+func TestCoopSession_JoinBeforeStart(t *testing.T) {
+	s, _ := NewCoopSession("join", 4, 7)
+	snapshot, err := s.Join(1)
+	if err != nil || snapshot != nil {
+		t.Fatalf("Join() = %+v, %v; want nil, nil", snapshot, err)
+	}
+	if _, err := s.Join(1); err == nil {
+		t.Error("joining twice succeeded")
+	}
+}
+
+func TestCoopSession_JoinMidLevel(t *testing.T) {
+	s := startedCoopSession(t, 4)
+	s.SetTile(3, 4, 0)
+	s.SetTile(1, 4, 2)
+	s.UpdateEnemy(CoopEnemy{ID: 5, Kind: "archer", X: 80, Y: 80, Health: 10, MaxHealth: 10})
+	s.UpdateEnemy(CoopEnemy{ID: 6, Kind: "archer", X: 90, Y: 90})
+	s.RemoveEnemy(6)
+	s.UpdateObjectiveProgress(s.QuestTracker.Objectives[0].ID, 1)
+
+	snapshot, err := s.Join(3)
+	if err != nil || snapshot == nil {
+		t.Fatalf("Join() = %+v, %v", snapshot, err)
+	}
+	if snapshot.SpawnX != 50 || snapshot.SpawnY != 50 {
+		t.Errorf("spawned at (%v, %v), want beside the teammate away from the enemy", snapshot.SpawnX, snapshot.SpawnY)
+	}
+	if want := []TileMutation{{X: 1, Y: 4, Tile: 2}, {X: 3, Y: 4, Tile: 0}}; !reflect.DeepEqual(snapshot.Tiles, want) {
+		t.Errorf("tiles = %+v, want %+v", snapshot.Tiles, want)
+	}
+	if len(snapshot.Enemies) != 2 || snapshot.Enemies[0].ID != 5 || snapshot.Enemies[1].Health != 20 {
+		t.Errorf("enemies = %+v", snapshot.Enemies)
+	}
+	if len(snapshot.Objectives) != 3 || snapshot.Objectives[0].Progress != 1 {
+		t.Errorf("objectives = %+v", snapshot.Objectives)
+	}
+	if len(snapshot.Players) != 2 || snapshot.Players[0].PlayerID != 1 || snapshot.LevelSeed != 7 {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+	if p, _ := s.GetPlayer(3); p.PosX != 50 || p.Health != p.MaxHealth {
+		t.Errorf("joined player at (%v, %v) with %v health", p.PosX, p.PosY, p.Health)
+	}
+}
+
+func TestCoopSession_DropInDropOut(t *testing.T) {
+	s := startedCoopSession(t, 2)
+	if _, err := s.Join(3); err == nil {
+		t.Fatal("joined a full session")
+	}
+
+	// Player 1 drops out, and player 3 takes their place
+	s.RemovePlayer(1)
+	if _, err := s.Join(3); err != nil {
+		t.Fatalf("Join() into a dropped-out slot: %v", err)
+	}
+	if _, err := s.GetPlayer(1); err == nil {
+		t.Error("dropped-out player kept their slot")
+	}
+
+	// Player 2 drops out and comes back
+	s.RemovePlayer(2)
+	if s.EnemyBudget(4) != 4 {
+		t.Errorf("EnemyBudget(4) with one player = %d", s.EnemyBudget(4))
+	}
+	snapshot, err := s.Join(2)
+	if err != nil || snapshot == nil {
+		t.Fatalf("rejoin: %+v, %v", snapshot, err)
+	}
+	if s.EnemyBudget(4) != 6 {
+		t.Errorf("EnemyBudget(4) with two players = %d, want 6", s.EnemyBudget(4))
+	}
+}
+
+func TestScaleEnemyBudget(t *testing.T) {
+	tests := []struct{ base, players, want int }{
+		{4, 0, 4},
+		{4, 1, 4},
+		{4, 2, 6},
+		{4, 4, 10},
+		{3, 2, 5},
+	}
+	for _, tt := range tests {
+		if got := ScaleEnemyBudget(tt.base, tt.players); got != tt.want {
+			t.Errorf("ScaleEnemyBudget(%d, %d) = %d, want %d", tt.base, tt.players, got, tt.want)
+		}
+	}
+}
+
+func TestGameServer_CoopJoin(t *testing.T) {
+	world := engine.NewWorld()
+	server, err := NewGameServer(0, world)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+
+	coop := startedCoopSession(t, 4)
+	server.SetCoopSession(coop)
+	server.SetPlayerSpawner(func(w *engine.World, clientID uint64) engine.Entity {
+		e := w.AddEntity()
+		w.AddComponent(e, &engine.Position{})
+		return e
+	})
+
+	conn, deltas := pipeClient(t, server)
+	session := NewSession()
+	delta := nextDelta(t, server, deltas)
+	session.Observe(&delta)
+	if delta.Level == nil || delta.Level.SpawnX != 50 || len(delta.Level.Enemies) != 1 {
+		t.Fatalf("joining delta's level = %+v", delta.Level)
+	}
+	if session.Level() == nil {
+		t.Error("Session.Level() = nil after joining")
+	}
+	if delta = nextDelta(t, server, deltas); delta.Level != nil {
+		t.Error("level sent twice")
+	}
+
+	server.mu.RLock()
+	var e engine.Entity
+	var clientID uint64
+	for id, c := range server.clients {
+		e, clientID = c.entity, id
+	}
+	server.mu.RUnlock()
+	comp, _ := world.GetComponent(e, reflect.TypeOf(&engine.Position{}))
+	if pos := comp.(*engine.Position); pos.X != 50 || pos.Y != 50 {
+		t.Errorf("player entity at (%v, %v), want the safe spawn point", pos.X, pos.Y)
+	}
+
+	conn.Close()
+	p, _ := coop.GetPlayer(clientID)
+	for i := 0; i < 100; i++ {
+		p.mu.RLock()
+		active := p.Active
+		p.mu.RUnlock()
+		if !active {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("disconnected player still active in the co-op session")
+}
@@ -26,11 +26,36 @@ type WorldSnapshot struct {
 
 // DeltaPacket represents the difference between two world states.
 type DeltaPacket struct {
-	BaseTick   uint64                            `json:"base_tick"`   // Baseline tick this delta is relative to
-	TargetTick uint64                            `json:"target_tick"` // Target tick this delta produces
-	Added      map[engine.Entity]*EntitySnapshot `json:"added"`       // Newly created entities
-	Modified   map[engine.Entity]*EntitySnapshot `json:"modified"`    // Modified entities (only changed fields)
-	Removed    []engine.Entity                   `json:"removed"`     // Removed entities
+	BaseTick   uint64                            `json:"base_tick"`         // Baseline tick this delta is relative to
+	TargetTick uint64                            `json:"target_tick"`       // Target tick this delta produces
+	Added      map[engine.Entity]*EntitySnapshot `json:"added"`             // Newly created entities
+	Modified   map[engine.Entity]*EntitySnapshot `json:"modified"`          // Modified entities (only changed fields)
+	Removed    []engine.Entity                   `json:"removed"`           // Removed entities
+	Session    *SessionInfo                      `json:"session,omitempty"` // The client's session, on initial deltas
+	Peers      []PeerInfo                        `json:"peers,omitempty"`   // The roster, when it has changed
+	Voice      *VoiceUpdate                      `json:"voice,omitempty"`   // Who's talking and their frames, when there's news
+	Match      *MatchStatus                      `json:"match,omitempty"`   // The team match's state, when it has changed
+	Level      *LevelSnapshot                    `json:"level,omitempty"`   // The co-op level so far, when the client joins mid-level
+}
+
+// replicatedComponents are the component types snapshots carry, by the
+// name they're sent under.
+var replicatedComponents = map[string]reflect.Type{}
+
+// RegisterReplicatedComponent adds a component type to the entity state
+// sent to clients, under the given name. Pass the component as the world
+// stores it; snapshots hold a copy of its value. Register components
+// before any server starts.
+func RegisterReplicatedComponent(name string, component interface{}) {
+	replicatedComponents[name] = reflect.TypeOf(component)
+}
+
+func init() {
+	RegisterReplicatedComponent("position", &engine.Position{})
+	RegisterReplicatedComponent("velocity", &engine.Velocity{})
+	RegisterReplicatedComponent("health", &engine.Health{})
+	RegisterReplicatedComponent("armor", &engine.Armor{})
+	RegisterReplicatedComponent("camera", &engine.Camera{})
 }
 
 // DeltaEncoder compresses world state into delta packets.
@@ -40,6 +65,7 @@ type DeltaEncoder struct {
 	lastSnapshot   *WorldSnapshot
 	snapshotBuffer []*WorldSnapshot // Ring buffer for lag compensation
 	bufferSize     int
+	chained        bool // Each delta is against the last one encoded
 }
 
 // NewDeltaEncoder creates a new delta encoder with a snapshot buffer.
@@ -69,10 +95,7 @@ func (e *DeltaEncoder) CaptureSnapshot(world *engine.World, tickNum uint64) *Wor
 	allEntities := world.Query() // Query with no component types returns all
 
 	for _, entityID := range allEntities {
-		entitySnap := e.captureEntity(world, entityID)
-		if entitySnap != nil {
-			snapshot.Entities[entityID] = entitySnap
-		}
+		snapshot.Entities[entityID] = e.captureEntity(world, entityID)
 	}
 
 	// Store in circular buffer
@@ -99,22 +122,39 @@ func (e *DeltaEncoder) captureEntity(world *engine.World, entityID engine.Entity
 		FieldMask:  make(map[string]bool),
 	}
 
-	// We need to iterate through all component types
-	// Since the World doesn't expose all components directly, we'll use a workaround
-	// In a production system, we'd extend World to expose component iteration
-	// For now, we'll capture known component types via reflection
-
-	// This is a simplified approach - in production, we'd have a registry
-	// of all component types that can be serialized
+	for name, compType := range replicatedComponents {
+		comp, ok := world.GetComponent(entityID, compType)
+		if !ok {
+			continue
+		}
+		value := reflect.ValueOf(comp)
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				continue
+			}
+			value = value.Elem()
+		}
+		snapshot.Components[name] = value.Interface()
+		snapshot.FieldMask[name] = true
+	}
 	return snapshot
 }
 
 // EncodeDelta creates a delta packet from baseline to current state.
 func (e *DeltaEncoder) EncodeDelta(world *engine.World, tickNum uint64) (*DeltaPacket, error) {
+	return e.EncodeDeltaFiltered(world, tickNum, nil)
+}
+
+// EncodeDeltaFiltered creates a delta packet from baseline to the current
+// state of the entities relevant reports true for; a nil relevant keeps
+// them all. Entities that stop being relevant are sent as removed and
+// ones that become relevant as added, so each client only tracks what
+// it can see.
+func (e *DeltaEncoder) EncodeDeltaFiltered(world *engine.World, tickNum uint64, relevant func(engine.Entity) bool) (*DeltaPacket, error) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	currentSnapshot := e.captureSnapshotInternal(world, tickNum)
+	currentSnapshot := e.captureSnapshotInternal(world, tickNum, relevant)
 
 	if e.baseline == nil {
 		return e.createInitialDelta(currentSnapshot, tickNum), nil
@@ -185,6 +225,9 @@ func (e *DeltaEncoder) findRemovedEntities(delta *DeltaPacket, currentSnapshot *
 // updateEncoderState updates snapshot buffer and last snapshot.
 func (e *DeltaEncoder) updateEncoderState(currentSnapshot *WorldSnapshot) {
 	e.lastSnapshot = currentSnapshot
+	if e.chained {
+		e.baseline = currentSnapshot
+	}
 
 	if len(e.snapshotBuffer) >= e.bufferSize {
 		e.snapshotBuffer = e.snapshotBuffer[1:]
@@ -203,22 +246,19 @@ func (e *DeltaEncoder) logDelta(delta *DeltaPacket, tickNum uint64) {
 	}).Debug("Delta encoded")
 }
 
-// captureSnapshotInternal creates a snapshot without locking (caller must hold lock).
-func (e *DeltaEncoder) captureSnapshotInternal(world *engine.World, tickNum uint64) *WorldSnapshot {
+// captureSnapshotInternal creates a snapshot of the relevant entities
+// without locking (caller must hold lock). A nil relevant keeps them all.
+func (e *DeltaEncoder) captureSnapshotInternal(world *engine.World, tickNum uint64, relevant func(engine.Entity) bool) *WorldSnapshot {
 	snapshot := &WorldSnapshot{
 		TickNumber: tickNum,
 		Entities:   make(map[engine.Entity]*EntitySnapshot),
 	}
 
-	// For simplicity, we'll use an empty query to get all entities
-	allEntities := world.Query()
-
-	for _, entityID := range allEntities {
-		snapshot.Entities[entityID] = &EntitySnapshot{
-			EntityID:   entityID,
-			Components: make(map[string]interface{}),
-			FieldMask:  make(map[string]bool),
+	for _, entityID := range world.Query() {
+		if relevant != nil && !relevant(entityID) {
+			continue
 		}
+		snapshot.Entities[entityID] = e.captureEntity(world, entityID)
 	}
 
 	return snapshot
@@ -290,10 +330,30 @@ func (e *DeltaEncoder) SetBaseline(snapshot *WorldSnapshot) {
 	e.baseline = snapshot
 }
 
+// Reset drops the baseline, so the next delta carries the full state,
+// as when a client reconnects.
+func (e *DeltaEncoder) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.baseline = nil
+	e.lastSnapshot = nil
+}
+
+// SetChained makes each delta relative to the one encoded before it
+// rather than to the first snapshot, so deltas stay small as the world
+// drifts from where it started. Only use it when every delta reaches the
+// decoder in order, as over a stream connection, with a chained decoder.
+func (e *DeltaEncoder) SetChained(chained bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.chained = chained
+}
+
 // DeltaDecoder reconstructs world state from delta packets.
 type DeltaDecoder struct {
 	mu       sync.RWMutex
 	baseline *WorldSnapshot
+	chained  bool // Each applied delta becomes the baseline
 }
 
 // NewDeltaDecoder creates a new delta decoder.
@@ -308,11 +368,23 @@ func (d *DeltaDecoder) SetBaseline(baseline *WorldSnapshot) {
 	d.baseline = baseline
 }
 
+// SetChained makes each applied delta the baseline for the next, to
+// decode the output of a chained DeltaEncoder. A chained decoder starts
+// over from each initial delta, with base tick 0, the encoder sends.
+func (d *DeltaDecoder) SetChained(chained bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.chained = chained
+}
+
 // ApplyDelta reconstructs a full world snapshot by applying a delta to the baseline.
 func (d *DeltaDecoder) ApplyDelta(delta *DeltaPacket) (*WorldSnapshot, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
+	if d.chained && delta.BaseTick == 0 {
+		d.baseline = &WorldSnapshot{Entities: make(map[engine.Entity]*EntitySnapshot)}
+	}
 	if d.baseline == nil {
 		return nil, fmt.Errorf("no baseline snapshot available")
 	}
@@ -363,6 +435,9 @@ func (d *DeltaDecoder) ApplyDelta(delta *DeltaPacket) (*WorldSnapshot, error) {
 		"entities":    len(result.Entities),
 	}).Debug("Delta applied")
 
+	if d.chained {
+		d.baseline = result
+	}
 	return result, nil
 }
 
@@ -414,8 +489,12 @@ func (d *DeltaDecoder) mergeEntitySnapshot(dst, src *EntitySnapshot) {
 		if present, exists := src.FieldMask[compName]; exists && present {
 			dst.Components[compName] = d.deepCopyValue(compValue)
 			dst.FieldMask[compName] = true
-		} else if exists && !present {
-			// Component marked for removal
+		}
+	}
+
+	// Removed components are only in the mask
+	for compName, present := range src.FieldMask {
+		if !present {
 			delete(dst.Components, compName)
 			delete(dst.FieldMask, compName)
 		}
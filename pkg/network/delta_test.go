@@ -1,6 +1,7 @@
 package network
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/opd-ai/violence/pkg/engine"
@@ -949,3 +950,53 @@ func TestDeltaEncodingWithComplexComponents(t *testing.T) {
 	// Use snapshot2 to verify it's not nil
 	_ = snapshot2
 }
+
+// TestDeltaComponents verifies component values are captured and diffed.
+func TestDeltaComponents(t *testing.T) {
+	world := engine.NewWorld()
+	e := world.AddEntity()
+	pos := &engine.Position{X: 1, Y: 2}
+	world.AddComponent(e, pos)
+	world.AddComponent(e, &engine.Health{Current: 10, Max: 10})
+
+	encoder := NewDeltaEncoder(10)
+	encoder.SetChained(true)
+	decoder := NewDeltaDecoder()
+	decoder.SetChained(true)
+
+	delta, _ := encoder.EncodeDelta(world, 1)
+	if got := delta.Added[e].Components["position"]; got != (engine.Position{X: 1, Y: 2}) {
+		t.Errorf("captured position = %v", got)
+	}
+	if _, err := decoder.ApplyDelta(delta); err != nil {
+		t.Fatal(err)
+	}
+
+	pos.X = 3
+	delta, _ = encoder.EncodeDelta(world, 2)
+	mod := delta.Modified[e]
+	if delta.BaseTick != 1 || mod == nil || len(mod.Components) != 1 || mod.Components["position"] != (engine.Position{X: 3, Y: 2}) {
+		t.Errorf("delta after move = %+v", delta)
+	}
+	if _, err := decoder.ApplyDelta(delta); err != nil {
+		t.Fatal(err)
+	}
+
+	world.RemoveComponent(e, reflect.TypeOf(&engine.Health{}))
+	delta, _ = encoder.EncodeDelta(world, 3)
+	state, err := decoder.ApplyDelta(delta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.Entities[e].Components["health"]; ok {
+		t.Error("removed component still in decoded state")
+	}
+	if state.Entities[e].Components["position"] != (engine.Position{X: 3, Y: 2}) {
+		t.Errorf("decoded position = %v", state.Entities[e].Components["position"])
+	}
+
+	delta, _ = encoder.EncodeDelta(world, 4)
+	if len(delta.Added)+len(delta.Modified)+len(delta.Removed) != 0 {
+		t.Errorf("delta of unchanged world = %+v", delta)
+	}
+}
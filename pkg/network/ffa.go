@@ -27,6 +27,7 @@ type FFAPlayerState struct {
 	Active      bool
 	Dead        bool
 	RespawnTime time.Time
+	KillerID    uint64 // Who made the last kill of the player; 0 after a suicide
 	PosX        float64
 	PosY        float64
 	Health      float64
@@ -243,6 +244,7 @@ func (m *FFAMatch) OnPlayerKill(killerID, victimID uint64) error {
 	victim.Deaths++
 	victim.Dead = true
 	victim.RespawnTime = time.Now().Add(RespawnDelay)
+	victim.KillerID = killerID
 	victim.mu.Unlock()
 
 	logrus.WithFields(logrus.Fields{
@@ -285,6 +287,7 @@ func (m *FFAMatch) OnPlayerSuicide(playerID uint64) error {
 	player.Deaths++
 	player.Dead = true
 	player.RespawnTime = time.Now().Add(RespawnDelay)
+	player.KillerID = 0
 	player.mu.Unlock()
 
 	logrus.WithFields(logrus.Fields{
@@ -347,6 +350,35 @@ func (m *FFAMatch) RespawnPlayer(playerID uint64) error {
 	return nil
 }
 
+// SpectateTargets returns the players a dead player can spectate until
+// they respawn: every other living player, their killer first.
+func (m *FFAMatch) SpectateTargets(playerID uint64) ([]uint64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	player, exists := m.Players[playerID]
+	if !exists {
+		return nil, fmt.Errorf("player %d not in match", playerID)
+	}
+	player.mu.RLock()
+	killerID := player.KillerID
+	player.mu.RUnlock()
+
+	targets := make([]uint64, 0, len(m.Players))
+	for id, other := range m.Players {
+		if id == playerID {
+			continue
+		}
+		other.mu.RLock()
+		alive := other.Active && !other.Dead
+		other.mu.RUnlock()
+		if alive {
+			targets = append(targets, id)
+		}
+	}
+	return orderSpectateTargets(targets, killerID), nil
+}
+
 // CheckTimeLimit checks if the time limit has been reached.
 func (m *FFAMatch) CheckTimeLimit() bool {
 	m.mu.RLock()
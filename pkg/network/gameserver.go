@@ -3,12 +3,15 @@ package network
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"reflect"
 	"sync"
 	"time"
 
+	"github.com/opd-ai/violence/pkg/common"
 	"github.com/opd-ai/violence/pkg/engine"
 	"github.com/sirupsen/logrus"
 )
@@ -18,6 +21,12 @@ const (
 	TickRate = 20
 	// TickDuration is the time between ticks.
 	TickDuration = time.Second / TickRate
+	// SimulationSteps is how many times a tick steps the world. Systems
+	// advance by common.DeltaTime a step, so this keeps them in real time.
+	SimulationSteps = common.TargetFPS / TickRate
+	// MaxCatchUpTicks is how many late ticks the loop runs back to back
+	// before it drops the rest and carries on from now.
+	MaxCatchUpTicks = 5
 )
 
 // PlayerCommand represents a client input command.
@@ -53,9 +62,22 @@ type GameServer struct {
 	listener     net.Listener
 	world        *engine.World
 	validator    CommandValidator
-	deltaEncoder *DeltaEncoder
+	applier      CommandApplier
+	spawner      PlayerSpawner
+	recorder     *MatchRecorder
+	voice        *VoiceRelay
+	match        MatchMode
+	coop         *CoopSession
+	matchStatus  *MatchStatus  // The match as last sent
+	deltaEncoder *DeltaEncoder // Full-world snapshots for lag compensation
+	interest     *InterestManager
+	bandwidth    *BandwidthMeter
 	mu           sync.RWMutex
 	clients      map[uint64]*playerClient
+	sessions     map[string]*suspendedSession // Dropped players by token hash
+	grace        time.Duration
+	rosterVer    uint64 // Bumped whenever the roster changes
+	matchVer     uint64 // Bumped whenever the match status changes
 	nextID       uint64
 	running      bool
 	tickNum      uint64
@@ -70,6 +92,16 @@ type playerClient struct {
 	conn           net.Conn
 	cmdQueue       chan *PlayerCommand
 	latencyMonitor *LatencyMonitor
+	encoder        *DeltaEncoder // Deltas of what this client can see
+	bandwidth      *BandwidthMeter
+	token          string         // Changed under both the server's and this mu
+	rosterVer      uint64         // Roster version last sent, guarded by mu
+	voiceVer       uint64         // Voice version last sent, guarded by mu
+	matchVer       uint64         // Match version last sent, guarded by mu
+	levelSync      *LevelSnapshot // Co-op level to send with the next delta, guarded by mu
+	entity         engine.Entity  // The client's player, guarded by the server's mu
+	hasEntity      bool
+	hostAddr       string // Where the client could host, guarded by the server's mu
 	mu             sync.Mutex
 	closeOnce      sync.Once
 	closedChan     chan struct{}
@@ -88,8 +120,14 @@ func NewGameServer(port int, world *engine.World) (*GameServer, error) {
 		listener:     listener,
 		world:        world,
 		validator:    &DefaultValidator{},
+		applier:      &DefaultApplier{},
+		voice:        NewVoiceRelay(),
 		deltaEncoder: NewDeltaEncoder(60), // 3 second buffer at 20 ticks/sec
+		interest:     NewInterestManager(DefaultInterestRadius),
+		bandwidth:    NewBandwidthMeter(),
 		clients:      make(map[uint64]*playerClient),
+		sessions:     make(map[string]*suspendedSession),
+		grace:        DefaultReconnectGrace,
 		ctx:          ctx,
 		cancel:       cancel,
 	}, nil
@@ -112,6 +150,168 @@ func (s *GameServer) SetValidator(v CommandValidator) {
 	s.validator = v
 }
 
+// NewMigratedGameServer creates the server that takes over a game whose
+// host went away, on the player ElectHost chose. peers is the last
+// roster the old host sent; every player in it, this host's included,
+// can resume their session within the reconnect grace window. world is
+// the new host's copy of the game.
+func NewMigratedGameServer(port int, world *engine.World, peers []PeerInfo) (*GameServer, error) {
+	s, err := NewGameServer(port, world)
+	if err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(s.grace)
+	for _, p := range peers {
+		s.sessions[p.TokenHash] = &suspendedSession{
+			id:        p.PlayerID,
+			entity:    p.Entity,
+			hasEntity: p.HasEntity,
+			hostAddr:  p.Addr,
+			expires:   expires,
+		}
+		if p.PlayerID >= s.nextID {
+			s.nextID = p.PlayerID + 1
+		}
+	}
+	s.rosterVer++
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "gameserver",
+		"players":     len(peers),
+	}).Info("Took over as host")
+	return s, nil
+}
+
+// PlayerSpawner creates the entity a newly connected client plays as.
+type PlayerSpawner func(w *engine.World, clientID uint64) engine.Entity
+
+// SetCommandApplier sets how validated commands change the world.
+func (s *GameServer) SetCommandApplier(a CommandApplier) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applier = a
+}
+
+// SetRecorder records the match into r from the next tick on: the world
+// every tick and every command applied. A nil r stops recording.
+func (s *GameServer) SetRecorder(r *MatchRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recorder = r
+}
+
+// SetMatch has the server run a game mode's rules, ticking them every
+// server tick and sending clients the match status whenever it changes.
+// A nil m stops running them.
+func (s *GameServer) SetMatch(m MatchMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.match = m
+	s.matchStatus = nil
+	s.matchVer++
+}
+
+// SetCoopSession has players join a co-op session as the spawner gives
+// them their entity, and drop out of it when they disconnect. Players who
+// join, or come back, once it's under way are moved to a safe spawn point
+// and sent the level so far with their next delta.
+func (s *GameServer) SetCoopSession(c *CoopSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.coop = c
+}
+
+// joinCoop puts a client's player into the co-op session, if there is one.
+func (s *GameServer) joinCoop(client *playerClient, clientID uint64, e engine.Entity) {
+	s.mu.RLock()
+	coop := s.coop
+	s.mu.RUnlock()
+	if coop == nil {
+		return
+	}
+
+	snapshot, err := coop.Join(clientID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"system_name": "gameserver",
+			"player_id":   clientID,
+		}).WithError(err).Warn("Player could not join co-op session")
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+	if comp, ok := s.world.GetComponent(e, reflect.TypeOf(&engine.Position{})); ok {
+		pos := comp.(*engine.Position)
+		pos.X, pos.Y = snapshot.SpawnX, snapshot.SpawnY
+	}
+	client.mu.Lock()
+	client.levelSync = snapshot
+	client.mu.Unlock()
+}
+
+// SetPlayerSpawner has the server give each client a player entity,
+// created by spawn on the tick after they connect. A player's entity is
+// removed from the world when their session ends.
+func (s *GameServer) SetPlayerSpawner(spawn PlayerSpawner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.spawner = spawn
+}
+
+// SetReconnectGrace sets how long a dropped player's session is kept
+// for them to reconnect to. Zero or less ends sessions on disconnect.
+func (s *GameServer) SetReconnectGrace(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grace = d
+}
+
+// SetInterestRadius sets how far from their player, in tiles, entities
+// are sent to each client.
+func (s *GameServer) SetInterestRadius(radius float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.interest = NewInterestManager(radius)
+}
+
+// SetPlayerEntity ties a client to their player entity, so they're only
+// sent the entities near it. Until it's set the client is sent everything.
+func (s *GameServer) SetPlayerEntity(clientID uint64, e engine.Entity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client, exists := s.clients[clientID]
+	if !exists {
+		return fmt.Errorf("client %d not connected", clientID)
+	}
+	client.entity = e
+	client.hasEntity = true
+	return nil
+}
+
+// GetBandwidth returns the world state traffic sent to a client.
+func (s *GameServer) GetBandwidth(clientID uint64) (BandwidthStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	client, exists := s.clients[clientID]
+	if !exists {
+		return BandwidthStats{}, false
+	}
+	return client.bandwidth.Stats(), true
+}
+
+// TotalBandwidth returns the world state traffic sent to all clients,
+// including ones since disconnected.
+func (s *GameServer) TotalBandwidth() BandwidthStats {
+	return s.bandwidth.Stats()
+}
+
+// GetSnapshot returns the full world snapshot of a recent tick, or nil
+// once it has left the lag compensation buffer.
+func (s *GameServer) GetSnapshot(tickNum uint64) *WorldSnapshot {
+	return s.deltaEncoder.GetSnapshot(tickNum)
+}
+
 // Start begins the server game loop and accepts client connections.
 func (s *GameServer) Start() error {
 	s.mu.Lock()
@@ -220,14 +420,20 @@ func (s *GameServer) addClient(conn net.Conn) {
 	clientID := s.nextID
 	s.nextID++
 
+	encoder := NewDeltaEncoder(1)
+	encoder.SetChained(true) // The connection delivers every delta in order
 	client := &playerClient{
 		id:             clientID,
 		conn:           conn,
 		cmdQueue:       make(chan *PlayerCommand, 100),
 		latencyMonitor: NewLatencyMonitor(clientID),
+		encoder:        encoder,
+		bandwidth:      NewBandwidthMeter(),
+		token:          newSessionToken(),
 		closedChan:     make(chan struct{}),
 	}
 	s.clients[clientID] = client
+	s.rosterVer++
 	s.mu.Unlock()
 
 	logrus.WithFields(logrus.Fields{
@@ -243,7 +449,7 @@ func (s *GameServer) addClient(conn net.Conn) {
 func (s *GameServer) handleClient(client *playerClient) {
 	defer s.wg.Done()
 	defer func() {
-		s.removeClient(client.id)
+		s.dropClient(client)
 		client.conn.Close()
 	}()
 
@@ -262,7 +468,35 @@ func (s *GameServer) handleClient(client *playerClient) {
 			return
 		}
 
-		s.enqueuePlayerCommand(client, cmd)
+		switch cmd.Type {
+		case CommandResume:
+			if err := s.resumeSession(client, string(cmd.Data)); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"system_name": "gameserver",
+					"player_id":   client.id,
+				}).WithError(err).Warn("Failed to resume session")
+			}
+		case CommandHostAddr:
+			if err := s.setHostAddr(client, string(cmd.Data)); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"system_name": "gameserver",
+					"player_id":   client.id,
+				}).WithError(err).Warn("Invalid host address")
+			}
+		default:
+			// Voice skips the tick queue, to reach listeners sooner
+			if handled, err := s.voice.handleVoiceCommand(cmd); handled {
+				if err != nil {
+					logrus.WithFields(logrus.Fields{
+						"system_name": "gameserver",
+						"player_id":   cmd.PlayerID,
+						"command":     cmd.Type,
+					}).WithError(err).Debug("Voice command dropped")
+				}
+				continue
+			}
+			s.enqueuePlayerCommand(client, cmd)
+		}
 	}
 }
 
@@ -299,40 +533,271 @@ func (s *GameServer) enqueuePlayerCommand(client *playerClient, cmd *PlayerComma
 
 // removeClient removes a disconnected client.
 func (s *GameServer) removeClient(clientID uint64) {
-	s.mu.Lock()
+	s.mu.RLock()
 	client, exists := s.clients[clientID]
-	if !exists {
-		s.mu.Unlock()
-		return
+	s.mu.RUnlock()
+	if exists {
+		s.dropClient(client)
 	}
-	delete(s.clients, clientID)
+}
+
+// dropClient removes a disconnected client, keeping their session for
+// the reconnect grace window. A client whose session was taken over by a
+// new connection is only closed.
+func (s *GameServer) dropClient(client *playerClient) {
+	s.mu.Lock()
+	clientID := client.id
+	current := s.clients[clientID] == client
+	if current {
+		delete(s.clients, clientID)
+		if s.grace > 0 {
+			s.sessions[hashToken(client.token)] = &suspendedSession{
+				id:        clientID,
+				entity:    client.entity,
+				hasEntity: client.hasEntity,
+				hostAddr:  client.hostAddr,
+				expires:   time.Now().Add(s.grace),
+			}
+			s.voice.Leave(clientID)
+		} else {
+			s.voice.Forget(clientID)
+		}
+		s.rosterVer++
+	}
+	coop := s.coop
 	s.mu.Unlock()
 
+	if current && coop != nil {
+		coop.RemovePlayer(clientID)
+	}
+
 	// Close channel safely using sync.Once
 	client.closeOnce.Do(func() {
 		close(client.cmdQueue)
 		close(client.closedChan)
-		logrus.WithFields(logrus.Fields{
-			"system_name": "gameserver",
-			"player_id":   clientID,
-		}).Info("Player disconnected")
+		if current {
+			logrus.WithFields(logrus.Fields{
+				"system_name": "gameserver",
+				"player_id":   clientID,
+			}).Info("Player disconnected")
+		}
 	})
 }
 
-// gameLoop runs the authoritative game simulation at 20 ticks/second.
+// kickClient disconnects a player for good: their session can't be
+// resumed and their entity is removed.
+func (s *GameServer) kickClient(clientID uint64, reason string) {
+	s.mu.Lock()
+	client, exists := s.clients[clientID]
+	if !exists {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.clients, clientID)
+	if client.hasEntity {
+		s.world.RemoveEntity(client.entity)
+	}
+	s.voice.Forget(clientID)
+	s.rosterVer++
+	s.mu.Unlock()
+
+	// Its handler sees the connection close and finishes the cleanup
+	client.conn.Close()
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "gameserver",
+		"player_id":   clientID,
+		"reason":      reason,
+	}).Warn("Player kicked")
+}
+
+// resumeSession gives a reconnecting client back the player of the
+// session whose token they sent: a dropped one still in its grace window,
+// or a live one whose connection has gone quiet, which is closed. The
+// client's next delta resyncs the full state.
+func (s *GameServer) resumeSession(client *playerClient, token string) error {
+	hash := hashToken(token)
+	s.mu.Lock()
+	var prev suspendedSession
+	var replaced *playerClient
+	if sess, ok := s.sessions[hash]; ok && time.Now().Before(sess.expires) {
+		prev = *sess
+		delete(s.sessions, hash)
+	} else if old := s.clientWithToken(hash); old != nil && old != client {
+		replaced = old
+		prev = suspendedSession{id: old.id, entity: old.entity, hasEntity: old.hasEntity, hostAddr: old.hostAddr}
+		delete(s.clients, old.id)
+	} else {
+		s.mu.Unlock()
+		return fmt.Errorf("unknown or expired session token")
+	}
+
+	delete(s.clients, client.id)
+	client.mu.Lock()
+	client.id = prev.id
+	client.token = token
+	client.rosterVer = 0
+	client.voiceVer = 0
+	client.matchVer = 0
+	client.mu.Unlock()
+	client.entity, client.hasEntity = prev.entity, prev.hasEntity
+	if client.hostAddr == "" {
+		client.hostAddr = prev.hostAddr
+	}
+	s.clients[prev.id] = client
+	s.rosterVer++
+	s.mu.Unlock()
+
+	client.encoder.Reset()
+	if prev.hasEntity && replaced == nil {
+		s.joinCoop(client, prev.id, prev.entity)
+	}
+	if replaced != nil {
+		replaced.conn.Close()
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "gameserver",
+		"player_id":   prev.id,
+	}).Info("Player resumed session")
+	return nil
+}
+
+// clientWithToken returns the connected client holding the session with
+// the token hash. Caller must hold s.mu.
+func (s *GameServer) clientWithToken(hash string) *playerClient {
+	for _, c := range s.clients {
+		if hashToken(c.token) == hash {
+			return c
+		}
+	}
+	return nil
+}
+
+// setHostAddr records where a client could host the game.
+func (s *GameServer) setHostAddr(client *playerClient, addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		if remote, _, err := net.SplitHostPort(client.conn.RemoteAddr().String()); err == nil {
+			host = remote
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client.hostAddr = net.JoinHostPort(host, port)
+	s.rosterVer++
+	return nil
+}
+
+// spawnPlayers creates entities for clients without one, if the server
+// has a spawner.
+func (s *GameServer) spawnPlayers(clients []*playerClient) {
+	s.mu.RLock()
+	spawn := s.spawner
+	s.mu.RUnlock()
+	if spawn == nil {
+		return
+	}
+
+	for _, client := range clients {
+		s.mu.RLock()
+		clientID, hasEntity := client.id, client.hasEntity
+		s.mu.RUnlock()
+		if hasEntity {
+			continue
+		}
+		e := spawn(s.world, clientID)
+		s.mu.Lock()
+		client.entity, client.hasEntity = e, true
+		s.rosterVer++
+		s.mu.Unlock()
+		s.joinCoop(client, clientID, e)
+	}
+}
+
+// expireSessions ends the sessions of players who didn't reconnect in
+// time, removing their entities.
+func (s *GameServer) expireSessions(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, sess := range s.sessions {
+		if now.After(sess.expires) {
+			delete(s.sessions, hash)
+			if sess.hasEntity {
+				s.world.RemoveEntity(sess.entity)
+			}
+			s.voice.Forget(sess.id)
+			s.rosterVer++
+			logrus.WithFields(logrus.Fields{
+				"system_name": "gameserver",
+				"player_id":   sess.id,
+			}).Info("Player session expired")
+		}
+	}
+}
+
+// roster lists the connected players and those who may yet reconnect.
+// Caller must hold s.mu.
+func (s *GameServer) roster() []PeerInfo {
+	peers := make([]PeerInfo, 0, len(s.clients)+len(s.sessions))
+	for _, c := range s.clients {
+		peers = append(peers, PeerInfo{
+			PlayerID:  c.id,
+			Addr:      c.hostAddr,
+			TokenHash: hashToken(c.token),
+			Entity:    c.entity,
+			HasEntity: c.hasEntity,
+		})
+	}
+	for hash, sess := range s.sessions {
+		peers = append(peers, PeerInfo{
+			PlayerID:  sess.id,
+			Addr:      sess.hostAddr,
+			TokenHash: hash,
+			Entity:    sess.entity,
+			HasEntity: sess.hasEntity,
+		})
+	}
+	sortPeers(peers)
+	return peers
+}
+
+// gameLoop runs the authoritative game simulation at a fixed 20
+// ticks/second. Ticks that come due while one runs long are caught up
+// back to back, so the simulation keeps real time.
 func (s *GameServer) gameLoop() {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(TickDuration)
-	defer ticker.Stop()
+	next := time.Now().Add(TickDuration)
+	timer := time.NewTimer(TickDuration)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-s.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-timer.C:
+		}
+
+		ran := 0
+		for !time.Now().Before(next) && ran < MaxCatchUpTicks {
 			s.tick()
+			next = next.Add(TickDuration)
+			ran++
+		}
+		if now := time.Now(); !now.Before(next) {
+			dropped := now.Sub(next)/TickDuration + 1
+			logrus.WithFields(logrus.Fields{
+				"system_name": "gameserver",
+				"dropped":     int64(dropped),
+			}).Warn("Server falling behind, dropping ticks")
+			next = next.Add(dropped * TickDuration)
 		}
+		timer.Reset(time.Until(next))
 	}
 }
 
@@ -351,12 +816,30 @@ func (s *GameServer) tick() {
 	}
 	s.mu.RUnlock()
 
+	s.spawnPlayers(clients)
 	for _, client := range clients {
 		s.processClientCommands(client)
 	}
+	s.expireSessions(time.Now())
+
+	// Step the game world in fixed steps
+	for i := 0; i < SimulationSteps; i++ {
+		s.world.Update()
+	}
+
+	s.mu.RLock()
+	recorder := s.recorder
+	s.mu.RUnlock()
+	if recorder != nil {
+		if err := recorder.RecordTick(s.world, tickNum); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"system_name": "gameserver",
+				"tick":        tickNum,
+			}).WithError(err).Warn("Failed to record tick")
+		}
+	}
 
-	// Update game world
-	s.world.Update()
+	s.tickMatch(time.Now())
 
 	// Broadcast world state to all clients
 	s.broadcastWorldState(tickNum, clients)
@@ -368,31 +851,125 @@ func (s *GameServer) tick() {
 	}).Debug("Server tick completed")
 }
 
-// broadcastWorldState sends the current world state to all connected clients.
-func (s *GameServer) broadcastWorldState(tickNum uint64, clients []*playerClient) {
-	delta, err := s.deltaEncoder.EncodeDelta(s.world, tickNum)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to encode world state delta")
+// tickMatch advances the match to now and bumps the match version if its
+// status has changed.
+func (s *GameServer) tickMatch(now time.Time) {
+	s.mu.RLock()
+	match := s.match
+	s.mu.RUnlock()
+	if match == nil {
 		return
 	}
 
-	data, err := json.Marshal(delta)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to marshal delta packet")
-		return
+	if result := match.Tick(now); result != nil {
+		logrus.WithFields(logrus.Fields{
+			"system_name": "gameserver",
+			"match_id":    result.MatchID,
+			"mode":        result.Mode,
+			"winner_team": result.WinnerTeam,
+			"reason":      result.Reason,
+		}).Info("Match finished")
 	}
+	status := match.Status(now)
 
-	// Add newline delimiter for JSON streaming
-	data = append(data, '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.match == match && (s.matchStatus == nil || !reflect.DeepEqual(*s.matchStatus, status)) {
+		s.matchStatus = &status
+		s.matchVer++
+	}
+}
+
+// tickNews is what a tick has for clients besides their delta, with the
+// versions that tell who has seen it.
+type tickNews struct {
+	roster    []PeerInfo
+	rosterVer uint64
+	voice     *voiceTick
+	match     *MatchStatus
+	matchVer  uint64
+}
+
+// broadcastWorldState sends each connected client the changes since
+// their last update to the entities near their player.
+func (s *GameServer) broadcastWorldState(tickNum uint64, clients []*playerClient) {
+	s.deltaEncoder.CaptureSnapshot(s.world, tickNum)
+
+	s.mu.RLock()
+	interest := s.interest
+	news := tickNews{
+		roster:    s.roster(),
+		rosterVer: s.rosterVer,
+		match:     s.matchStatus,
+		matchVer:  s.matchVer,
+	}
+	s.mu.RUnlock()
+	interest.Update(s.world)
+	news.voice = s.voice.take()
 
 	for _, client := range clients {
-		if err := s.sendToClient(client, data); err != nil {
+		s.mu.RLock()
+		clientID, focus, hasFocus := client.id, client.entity, client.hasEntity
+		s.mu.RUnlock()
+
+		var relevant func(engine.Entity) bool
+		if hasFocus {
+			relevant = interest.Relevant(s.world, focus)
+		}
+		if err := s.sendWorldState(client, tickNum, relevant, news); err != nil {
 			logrus.WithFields(logrus.Fields{
 				"system_name": "gameserver",
-				"player_id":   client.id,
+				"player_id":   clientID,
 			}).WithError(err).Debug("Failed to send state to client")
 		}
+		client.bandwidth.Tick()
+	}
+	s.bandwidth.Tick()
+}
+
+// sendWorldState encodes and sends one client's delta, with their session
+// if it's an initial delta, the roster and match status if they haven't
+// seen these ones and any voice traffic for them.
+func (s *GameServer) sendWorldState(client *playerClient, tickNum uint64, relevant func(engine.Entity) bool, news tickNews) error {
+	client.mu.Lock()
+	delta, err := client.encoder.EncodeDeltaFiltered(s.world, tickNum, relevant)
+	if err != nil {
+		client.mu.Unlock()
+		return fmt.Errorf("encode delta: %w", err)
+	}
+	if delta.BaseTick == 0 {
+		delta.Session = &SessionInfo{PlayerID: client.id, Token: client.token}
+	}
+	if client.rosterVer != news.rosterVer {
+		delta.Peers = news.roster
+		client.rosterVer = news.rosterVer
+	}
+	if client.levelSync != nil {
+		delta.Level = client.levelSync
+		client.levelSync = nil
+	}
+	if client.matchVer != news.matchVer {
+		delta.Match = news.match
+		client.matchVer = news.matchVer
 	}
+	delta.Voice = news.voice.update(client.id, client.voiceVer)
+	client.voiceVer = news.voice.version
+	client.mu.Unlock()
+
+	data, err := json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("marshal delta: %w", err)
+	}
+
+	// Add newline delimiter for JSON streaming
+	data = append(data, '\n')
+
+	if err := s.sendToClient(client, data); err != nil {
+		return err
+	}
+	client.bandwidth.Record(len(data))
+	s.bandwidth.Record(len(data))
+	return nil
 }
 
 // sendToClient writes data to a client connection with error handling.
@@ -435,12 +1012,44 @@ func (s *GameServer) validateAndApplyCommand(cmd *PlayerCommand) {
 	}
 
 	if err := s.validator.Validate(cmd, s.world); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"system_name": "gameserver",
-			"player_id":   cmd.PlayerID,
-			"command":     cmd.Type,
-		}).WithError(err).Warn("Command validation failed")
-		return
+		var cheat *CheatError
+		if !errors.As(err, &cheat) {
+			logrus.WithFields(logrus.Fields{
+				"system_name": "gameserver",
+				"player_id":   cmd.PlayerID,
+				"command":     cmd.Type,
+			}).WithError(err).Warn("Command validation failed")
+			return
+		}
+		switch cheat.Response {
+		case ResponseKick:
+			s.kickClient(cmd.PlayerID, cheat.Error())
+			return
+		case ResponseCorrect:
+			return
+		}
+	}
+
+	s.mu.RLock()
+	applier, recorder, tickNum := s.applier, s.recorder, s.tickNum
+	var player engine.Entity
+	hasPlayer := false
+	if client, ok := s.clients[cmd.PlayerID]; ok {
+		player, hasPlayer = client.entity, client.hasEntity
+	}
+	s.mu.RUnlock()
+	if applier != nil && hasPlayer {
+		if err := applier.Apply(cmd, player, s.world); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"system_name": "gameserver",
+				"player_id":   cmd.PlayerID,
+				"command":     cmd.Type,
+			}).WithError(err).Warn("Command could not be applied")
+			return
+		}
+	}
+	if recorder != nil {
+		recorder.RecordCommand(tickNum, cmd)
 	}
 
 	logrus.WithFields(logrus.Fields{
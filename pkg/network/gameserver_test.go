@@ -1038,3 +1038,292 @@ func TestGameServer_DeltaEncoderInitialized(t *testing.T) {
 		t.Fatal("delta encoder should be initialized in NewGameServer")
 	}
 }
+
+func TestGameServer_InterestManagement(t *testing.T) {
+	world := engine.NewWorld()
+	player := world.NewPlayerEntity(10, 10)
+	near := world.AddEntity()
+	world.AddComponent(near, &engine.Position{X: 15, Y: 10})
+	far := world.AddEntity()
+	world.AddComponent(far, &engine.Position{X: 200, Y: 10})
+
+	server, err := NewGameServer(0, world)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	server.addClient(serverConn)
+	if err := server.SetPlayerEntity(0, player); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.SetPlayerEntity(7, player); err == nil {
+		t.Error("SetPlayerEntity() of unknown client should fail")
+	}
+
+	deltas := make(chan DeltaPacket, 1)
+	go func() {
+		var delta DeltaPacket
+		if err := json.NewDecoder(clientConn).Decode(&delta); err == nil {
+			deltas <- delta
+		}
+	}()
+	server.tick()
+
+	select {
+	case delta := <-deltas:
+		if delta.Added[player] == nil || delta.Added[near] == nil {
+			t.Errorf("added %v, want the player and the entity near them", delta.Added)
+		}
+		if delta.Added[far] != nil {
+			t.Error("entity out of interest range was sent")
+		}
+		if _, ok := delta.Added[player].Components["position"]; !ok {
+			t.Error("player position not replicated")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no state received")
+	}
+
+	stats, ok := server.GetBandwidth(0)
+	if !ok || stats.PacketsSent != 1 || stats.BytesSent == 0 {
+		t.Errorf("client bandwidth = %+v, %v", stats, ok)
+	}
+	if total := server.TotalBandwidth(); total.BytesSent != stats.BytesSent {
+		t.Errorf("total bytes = %d, want %d", total.BytesSent, stats.BytesSent)
+	}
+	if server.GetSnapshot(1) == nil {
+		t.Error("full snapshot of tick 1 not kept")
+	}
+}
+
+// pipeClient connects a client to the server over a pipe and decodes the
+// deltas it's sent.
+func pipeClient(t *testing.T, server *GameServer) (net.Conn, <-chan DeltaPacket) {
+	t.Helper()
+	serverConn, clientConn := net.Pipe()
+	server.addClient(serverConn)
+	deltas := make(chan DeltaPacket, 16)
+	go func() {
+		decoder := json.NewDecoder(clientConn)
+		for {
+			var delta DeltaPacket
+			if err := decoder.Decode(&delta); err != nil {
+				return
+			}
+			deltas <- delta
+		}
+	}()
+	return clientConn, deltas
+}
+
+// nextDelta ticks the server and returns the delta a client was sent.
+func nextDelta(t *testing.T, server *GameServer, deltas <-chan DeltaPacket) DeltaPacket {
+	t.Helper()
+	server.tick()
+	select {
+	case delta := <-deltas:
+		return delta
+	case <-time.After(2 * time.Second):
+		t.Fatal("no state received")
+		return DeltaPacket{}
+	}
+}
+
+// waitForSession waits for the server to hold n dropped sessions.
+func waitForSession(t *testing.T, server *GameServer, n int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		server.mu.RLock()
+		held := len(server.sessions)
+		server.mu.RUnlock()
+		if held == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server never held %d dropped sessions", n)
+}
+
+func TestGameServer_Reconnect(t *testing.T) {
+	world := engine.NewWorld()
+	player := world.NewPlayerEntity(10, 10)
+	server, err := NewGameServer(0, world)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+
+	conn, deltas := pipeClient(t, server)
+	server.SetPlayerEntity(0, player)
+	session := NewSession()
+	first := nextDelta(t, server, deltas)
+	session.Observe(&first)
+	if first.Session == nil || session.Token() == "" {
+		t.Fatalf("initial delta carries no session: %+v", first)
+	}
+	if len(first.Peers) != 1 || first.Peers[0].TokenHash != hashToken(session.Token()) {
+		t.Errorf("roster = %+v", first.Peers)
+	}
+	if later := nextDelta(t, server, deltas); later.Session != nil || later.Peers != nil {
+		t.Error("session and unchanged roster should only be sent once")
+	}
+
+	conn.Close()
+	waitForSession(t, server, 1)
+
+	conn, deltas = pipeClient(t, server)
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(session.ResumeCommand()); err != nil {
+		t.Fatal(err)
+	}
+	waitForSession(t, server, 0)
+
+	// The first delta may predate the resume; the resync follows it
+	for i := 0; i < 3; i++ {
+		delta := nextDelta(t, server, deltas)
+		if delta.Session == nil || delta.Session.PlayerID != 0 {
+			continue
+		}
+		if delta.BaseTick != 0 || delta.Added[player] == nil {
+			t.Errorf("resumed delta doesn't resync the player: %+v", delta)
+		}
+		server.mu.RLock()
+		c := server.clients[0]
+		server.mu.RUnlock()
+		if c == nil || !c.hasEntity || c.entity != player || len(server.clients) != 1 {
+			t.Error("resumed client doesn't have the player back")
+		}
+		return
+	}
+	t.Fatal("session not resumed")
+}
+
+func TestGameServer_ReconnectGrace(t *testing.T) {
+	server, err := NewGameServer(0, engine.NewWorld())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+	server.SetReconnectGrace(time.Minute)
+
+	conn, deltas := pipeClient(t, server)
+	first := nextDelta(t, server, deltas)
+	conn.Close()
+	waitForSession(t, server, 1)
+
+	server.expireSessions(time.Now())
+	waitForSession(t, server, 1)
+	server.expireSessions(time.Now().Add(2 * time.Minute))
+	waitForSession(t, server, 0)
+
+	conn, _ = pipeClient(t, server)
+	defer conn.Close()
+	server.mu.RLock()
+	newest := server.clients[1]
+	server.mu.RUnlock()
+	if err := server.resumeSession(newest, first.Session.Token); err == nil {
+		t.Error("expired session was resumed")
+	}
+}
+
+func TestGameServer_HostMigration(t *testing.T) {
+	server, err := NewGameServer(0, engine.NewWorld())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+
+	sessions := []*Session{NewSession(), NewSession()}
+	for i, s := range sessions {
+		conn, deltas := pipeClient(t, server)
+		defer conn.Close()
+		if i == 1 {
+			json.NewEncoder(conn).Encode(&PlayerCommand{Type: CommandHostAddr, Data: []byte("10.0.0.2:7777")})
+			time.Sleep(50 * time.Millisecond)
+		}
+		for j := 0; j < 3; j++ {
+			delta := nextDelta(t, server, deltas)
+			s.Observe(&delta)
+		}
+	}
+
+	// Player 1's roster is current; player 0 is the old host
+	host, ok := sessions[1].NextHost("")
+	if !ok || host.PlayerID != 1 || host.Addr != "10.0.0.2:7777" {
+		t.Fatalf("NextHost() = %+v, %v", host, ok)
+	}
+	sessions[1].mu.RLock()
+	roster := sessions[1].peers
+	sessions[1].mu.RUnlock()
+
+	migrated, err := NewMigratedGameServer(0, engine.NewWorld(), roster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer migrated.listener.Close()
+
+	conn, deltas := pipeClient(t, migrated)
+	defer conn.Close()
+	json.NewEncoder(conn).Encode(sessions[0].ResumeCommand())
+	waitForSession(t, migrated, 1)
+	for i := 0; i < 3; i++ {
+		if delta := nextDelta(t, migrated, deltas); delta.Session != nil && delta.Session.PlayerID == 0 {
+			if migrated.nextID != 3 {
+				t.Errorf("nextID = %d, want past the roster", migrated.nextID)
+			}
+			return
+		}
+	}
+	t.Fatal("session not resumed on the new host")
+}
+
+// countingSystem counts the steps the world takes.
+type countingSystem struct{ steps int }
+
+func (c *countingSystem) Update(w *engine.World) { c.steps++ }
+
+func TestGameServer_HeadlessSimulation(t *testing.T) {
+	world := engine.NewWorld()
+	steps := &countingSystem{}
+	world.AddSystem(steps)
+	server, err := NewGameServer(0, world)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+	server.SetPlayerSpawner(func(w *engine.World, clientID uint64) engine.Entity {
+		return w.NewPlayerEntity(3, 3)
+	})
+
+	conn, deltas := pipeClient(t, server)
+	defer conn.Close()
+	delta := nextDelta(t, server, deltas)
+	if steps.steps != SimulationSteps {
+		t.Errorf("world stepped %d times a tick, want %d", steps.steps, SimulationSteps)
+	}
+
+	server.mu.RLock()
+	client := server.clients[0]
+	player, spawned := client.entity, client.hasEntity
+	server.mu.RUnlock()
+	if !spawned || delta.Added[player] == nil {
+		t.Fatal("connected client was not given a player entity")
+	}
+
+	data, _ := json.Marshal(MoveCommand{DX: 0.25})
+	json.NewEncoder(conn).Encode(&PlayerCommand{Type: CommandMove, Data: data})
+	for i := 0; i < 10; i++ {
+		delta = nextDelta(t, server, deltas)
+		if mod := delta.Modified[player]; mod != nil {
+			pos, _ := mod.Components["position"].(map[string]interface{})
+			if pos["X"] != 3.25 {
+				t.Errorf("moved position = %v, want X 3.25", pos)
+			}
+			return
+		}
+	}
+	t.Fatal("move command never reached the world")
+}
@@ -0,0 +1,66 @@
+package network
+
+import (
+	"reflect"
+
+	"github.com/opd-ai/violence/pkg/engine"
+	"github.com/opd-ai/violence/pkg/spatial"
+)
+
+// DefaultInterestRadius is how far from a player, in tiles, entities are
+// sent to them.
+const DefaultInterestRadius = 24.0
+
+// InterestManager decides which entities each client is sent, from a
+// spatial index of the world rebuilt once a tick.
+type InterestManager struct {
+	radius float64
+	index  *spatial.System
+}
+
+// NewInterestManager creates an interest manager that sends each client
+// the entities within radius of their player.
+func NewInterestManager(radius float64) *InterestManager {
+	if radius <= 0 {
+		radius = DefaultInterestRadius
+	}
+	return &InterestManager{
+		radius: radius,
+		index:  spatial.NewSystem(radius / 2),
+	}
+}
+
+// Radius returns the interest radius.
+func (m *InterestManager) Radius() float64 {
+	return m.radius
+}
+
+// Update reindexes entity positions. Call it once a tick before Relevant.
+func (m *InterestManager) Update(w *engine.World) {
+	m.index.Update(w)
+}
+
+// Relevant returns the filter for the entities a client whose player is
+// focus should be sent: those within the radius, the player itself, and
+// entities without a position, which aren't anywhere in particular. A
+// focus without a position sees everything.
+func (m *InterestManager) Relevant(w *engine.World, focus engine.Entity) func(engine.Entity) bool {
+	posType := reflect.TypeOf(&engine.Position{})
+	comp, ok := w.GetComponent(focus, posType)
+	if !ok {
+		return nil
+	}
+	pos := comp.(*engine.Position)
+
+	near := make(map[engine.Entity]bool)
+	for _, e := range m.index.QueryRadiusExact(w, pos.X, pos.Y, m.radius) {
+		near[e] = true
+	}
+	return func(e engine.Entity) bool {
+		if e == focus || near[e] {
+			return true
+		}
+		_, positioned := w.GetComponent(e, posType)
+		return !positioned
+	}
+}
@@ -0,0 +1,80 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+func TestInterestManagerRelevant(t *testing.T) {
+	world := engine.NewWorld()
+	focus := world.AddEntity()
+	world.AddComponent(focus, &engine.Position{X: 50, Y: 50})
+	near := world.AddEntity()
+	world.AddComponent(near, &engine.Position{X: 55, Y: 48})
+	far := world.AddEntity()
+	world.AddComponent(far, &engine.Position{X: 50, Y: 90})
+	global := world.AddEntity()
+	world.AddComponent(global, &engine.Health{Current: 1, Max: 1})
+
+	m := NewInterestManager(10)
+	if m.Radius() != 10 {
+		t.Errorf("Radius() = %v, want 10", m.Radius())
+	}
+	m.Update(world)
+	relevant := m.Relevant(world, focus)
+	if relevant == nil {
+		t.Fatal("Relevant() = nil for a positioned focus")
+	}
+	for e, want := range map[engine.Entity]bool{focus: true, near: true, far: false, global: true} {
+		if got := relevant(e); got != want {
+			t.Errorf("relevant(%d) = %v, want %v", e, got, want)
+		}
+	}
+
+	if m.Relevant(world, global) != nil {
+		t.Error("focus without a position should see everything")
+	}
+	if NewInterestManager(0).Radius() != DefaultInterestRadius {
+		t.Error("non-positive radius should use the default")
+	}
+}
+
+func TestInterestEnterAndLeave(t *testing.T) {
+	world := engine.NewWorld()
+	focus := world.AddEntity()
+	world.AddComponent(focus, &engine.Position{X: 0, Y: 0})
+	other := world.AddEntity()
+	world.AddComponent(other, &engine.Position{X: 50, Y: 0})
+
+	m := NewInterestManager(10)
+	encoder := NewDeltaEncoder(1)
+	encoder.SetChained(true)
+	decoder := NewDeltaDecoder()
+	decoder.SetChained(true)
+
+	step := func(tick uint64, x float64) (*DeltaPacket, *WorldSnapshot) {
+		t.Helper()
+		world.AddComponent(other, &engine.Position{X: x, Y: 0})
+		m.Update(world)
+		delta, err := encoder.EncodeDeltaFiltered(world, tick, m.Relevant(world, focus))
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := decoder.ApplyDelta(delta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return delta, state
+	}
+
+	if _, state := step(1, 50); state.Entities[other] != nil {
+		t.Error("distant entity sent")
+	}
+	if delta, state := step(2, 5); delta.Added[other] == nil || state.Entities[other] == nil {
+		t.Error("entity entering interest not added")
+	}
+	if delta, state := step(3, 50); len(delta.Removed) != 1 || state.Entities[other] != nil {
+		t.Errorf("entity leaving interest not removed: %v", delta.Removed)
+	}
+}
@@ -132,6 +132,21 @@ func (b *InterpolationBuffer) GetInterpolatedState(currentTick uint64) (*WorldSn
 	return prev, nil
 }
 
+// History returns the buffered snapshots from fromTick to toTick
+// inclusive, oldest first, such as the lead-up to a kill for the kill-cam.
+func (b *InterpolationBuffer) History(fromTick, toTick uint64) []*WorldSnapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var history []*WorldSnapshot
+	for _, snapshot := range b.snapshots {
+		if snapshot.TickNumber >= fromTick && snapshot.TickNumber <= toTick {
+			history = append(history, snapshot)
+		}
+	}
+	return history
+}
+
 // LatencyMonitor tracks client latency and manages degradation modes.
 type LatencyMonitor struct {
 	mu             sync.RWMutex
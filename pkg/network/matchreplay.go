@@ -0,0 +1,449 @@
+package network
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MatchReplayMagic identifies a match replay file.
+	MatchReplayMagic = "VMRP"
+
+	// MatchReplayVersion is the match replay file format version.
+	MatchReplayVersion = uint16(1)
+
+	// KeyframeInterval is how many ticks apart loaded replays keep full
+	// snapshots to seek from.
+	KeyframeInterval = 5 * TickRate
+)
+
+// ErrEmptyReplay is returned when saving or loading a replay with no ticks.
+var ErrEmptyReplay = errors.New("replay has no recorded ticks")
+
+// Match replay files start with a matchReplayHeader, then the gzipped JSON
+// of a matchReplayBody. The body holds every tick as a delta against the
+// tick before, the first against nothing, and the commands the server
+// applied, so a match can be watched from the snapshots or re-simulated
+// from its seed and inputs.
+type matchReplayHeader struct {
+	Magic    [4]byte
+	Version  uint16
+	TickRate uint16
+	Ticks    uint32
+	Reserved [6]byte
+}
+
+type matchReplayBody struct {
+	Info     MatchInfo         `json:"info"`
+	Commands []RecordedCommand `json:"commands"`
+	Deltas   []*DeltaPacket    `json:"deltas"`
+}
+
+// MatchInfo describes the match a replay was recorded from.
+type MatchInfo struct {
+	Seed  uint64 `json:"seed"`
+	Genre string `json:"genre"`
+	Mode  string `json:"mode,omitempty"`
+}
+
+// RecordedCommand is a player command and the tick the server applied it
+// on, before stepping the world.
+type RecordedCommand struct {
+	Tick    uint64        `json:"tick"`
+	Command PlayerCommand `json:"command"`
+}
+
+// MatchRecorder records a match on the server, tick by tick, for saving
+// as a replay. It is safe for concurrent use.
+type MatchRecorder struct {
+	mu       sync.Mutex
+	info     MatchInfo
+	encoder  *DeltaEncoder
+	deltas   []*DeltaPacket
+	commands []RecordedCommand
+}
+
+// NewMatchRecorder creates a recorder for the described match.
+func NewMatchRecorder(info MatchInfo) *MatchRecorder {
+	encoder := NewDeltaEncoder(1)
+	encoder.SetChained(true)
+	return &MatchRecorder{info: info, encoder: encoder}
+}
+
+// RecordCommand records a command applied on the given tick.
+func (r *MatchRecorder) RecordCommand(tick uint64, cmd *PlayerCommand) {
+	r.mu.Lock()
+	r.commands = append(r.commands, RecordedCommand{Tick: tick, Command: *cmd})
+	r.mu.Unlock()
+}
+
+// RecordTick records the world's state at the end of the given tick.
+func (r *MatchRecorder) RecordTick(w *engine.World, tick uint64) error {
+	delta, err := r.encoder.EncodeDelta(w, tick)
+	if err != nil {
+		return fmt.Errorf("failed to record tick %d: %w", tick, err)
+	}
+	r.mu.Lock()
+	r.deltas = append(r.deltas, delta)
+	r.mu.Unlock()
+	return nil
+}
+
+// Ticks returns how many ticks have been recorded.
+func (r *MatchRecorder) Ticks() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.deltas)
+}
+
+// WriteTo writes the replay recorded so far in the match replay format.
+func (r *MatchRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	body := matchReplayBody{Info: r.info, Commands: r.commands, Deltas: r.deltas}
+	r.mu.Unlock()
+	if len(body.Deltas) == 0 {
+		return 0, ErrEmptyReplay
+	}
+
+	header := matchReplayHeader{Version: MatchReplayVersion, TickRate: TickRate, Ticks: uint32(len(body.Deltas))}
+	copy(header.Magic[:], MatchReplayMagic)
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, header); err != nil {
+		return 0, fmt.Errorf("failed to write header: %w", err)
+	}
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(body); err != nil {
+		return 0, fmt.Errorf("failed to encode replay: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return 0, fmt.Errorf("failed to compress replay: %w", err)
+	}
+	return buf.WriteTo(w)
+}
+
+// Save writes the replay recorded so far to a file.
+func (r *MatchRecorder) Save(path string) error {
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	// Write beside the replay and rename over it, so readers never see
+	// half a file
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create replay file: %w", err)
+	}
+	_, err = file.Write(buf.Bytes())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to write replay file: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"system_name": "match_recorder",
+		"path":        path,
+		"ticks":       r.Ticks(),
+	}).Info("Match replay saved")
+	return nil
+}
+
+// MatchReplay is a loaded match replay.
+type MatchReplay struct {
+	Info      MatchInfo
+	TickRate  int
+	Commands  []RecordedCommand
+	deltas    []*DeltaPacket
+	keyframes []*WorldSnapshot // Snapshot of every KeyframeInterval-th delta
+}
+
+// LoadMatchReplay loads a match replay from a file.
+func LoadMatchReplay(path string) (*MatchReplay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+	defer file.Close()
+	return ReadMatchReplay(file)
+}
+
+// ReadMatchReplay reads a match replay written by MatchRecorder.WriteTo.
+func ReadMatchReplay(r io.Reader) (*MatchReplay, error) {
+	var header matchReplayHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	if !bytes.Equal(header.Magic[:], []byte(MatchReplayMagic)) {
+		return nil, fmt.Errorf("invalid magic bytes: expected %q, got %q", MatchReplayMagic, header.Magic)
+	}
+	if header.Version != MatchReplayVersion {
+		return nil, fmt.Errorf("unsupported version: %d (expected %d)", header.Version, MatchReplayVersion)
+	}
+	if header.TickRate == 0 {
+		return nil, fmt.Errorf("invalid tick rate: 0")
+	}
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress replay: %w", err)
+	}
+	var body matchReplayBody
+	if err := json.NewDecoder(zr).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode replay: %w", err)
+	}
+	// Read to the end of the stream so gzip checks its checksum
+	if _, err := io.Copy(io.Discard, zr); err != nil {
+		return nil, fmt.Errorf("failed to decompress replay: %w", err)
+	}
+	if len(body.Deltas) == 0 {
+		return nil, ErrEmptyReplay
+	}
+	if len(body.Deltas) != int(header.Ticks) {
+		return nil, fmt.Errorf("replay has %d ticks, header says %d", len(body.Deltas), header.Ticks)
+	}
+
+	replay := &MatchReplay{
+		Info:     body.Info,
+		TickRate: int(header.TickRate),
+		Commands: body.Commands,
+		deltas:   body.Deltas,
+	}
+	if err := replay.buildKeyframes(); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// buildKeyframes decodes the whole replay once, keeping a snapshot every
+// KeyframeInterval ticks to seek from.
+func (m *MatchReplay) buildKeyframes() error {
+	decoder := NewDeltaDecoder()
+	decoder.SetChained(true)
+	for i, delta := range m.deltas {
+		snapshot, err := decoder.ApplyDelta(delta)
+		if err != nil {
+			return fmt.Errorf("replay tick %d: %w", delta.TargetTick, err)
+		}
+		if i%KeyframeInterval == 0 {
+			m.keyframes = append(m.keyframes, snapshot)
+		}
+	}
+	return nil
+}
+
+// FirstTick returns the first recorded tick.
+func (m *MatchReplay) FirstTick() uint64 {
+	return m.deltas[0].TargetTick
+}
+
+// LastTick returns the last recorded tick.
+func (m *MatchReplay) LastTick() uint64 {
+	return m.deltas[len(m.deltas)-1].TargetTick
+}
+
+// Duration returns how long the recorded match ran.
+func (m *MatchReplay) Duration() time.Duration {
+	return time.Duration(len(m.deltas)) * time.Second / time.Duration(m.TickRate)
+}
+
+// index returns the position of the last recorded tick at or before tick.
+func (m *MatchReplay) index(tick uint64) int {
+	i := sort.Search(len(m.deltas), func(i int) bool { return m.deltas[i].TargetTick > tick })
+	if i > 0 {
+		i--
+	}
+	return i
+}
+
+// SnapshotAt returns the world as it was at the end of the given tick, or
+// of the nearest recorded tick before it.
+func (m *MatchReplay) SnapshotAt(tick uint64) (*WorldSnapshot, error) {
+	i := m.index(tick)
+	k := i / KeyframeInterval
+	snapshot := m.keyframes[k]
+	if i == k*KeyframeInterval {
+		return snapshot, nil
+	}
+
+	decoder := NewDeltaDecoder()
+	decoder.SetChained(true)
+	decoder.SetBaseline(snapshot)
+	for _, delta := range m.deltas[k*KeyframeInterval+1 : i+1] {
+		var err error
+		if snapshot, err = decoder.ApplyDelta(delta); err != nil {
+			return nil, fmt.Errorf("replay tick %d: %w", delta.TargetTick, err)
+		}
+	}
+	return snapshot, nil
+}
+
+// CommandsAt returns the commands applied on the given tick.
+func (m *MatchReplay) CommandsAt(tick uint64) []RecordedCommand {
+	var cmds []RecordedCommand
+	for _, c := range m.Commands {
+		if c.Tick == tick {
+			cmds = append(cmds, c)
+		}
+	}
+	return cmds
+}
+
+// ReplayPlayback plays a match replay on the client at a chosen speed,
+// with pausing, seeking and a free camera to watch from.
+type ReplayPlayback struct {
+	replay   *MatchReplay
+	index    int
+	snapshot *WorldSnapshot
+	decoder  *DeltaDecoder
+	paused   bool
+	speed    float64
+	elapsed  time.Duration // Play time owed to the next tick
+	camera   *Spectator
+}
+
+// NewReplayPlayback starts playback at the first tick with a free camera
+// at the given view.
+func NewReplayPlayback(replay *MatchReplay, from CameraView) *ReplayPlayback {
+	p := &ReplayPlayback{replay: replay, speed: 1, camera: NewSpectator(from)}
+	p.Seek(replay.FirstTick())
+	return p
+}
+
+// Replay returns the replay being played.
+func (p *ReplayPlayback) Replay() *MatchReplay {
+	return p.replay
+}
+
+// Tick returns the tick being shown.
+func (p *ReplayPlayback) Tick() uint64 {
+	return p.snapshot.TickNumber
+}
+
+// Snapshot returns the world at the tick being shown.
+func (p *ReplayPlayback) Snapshot() *WorldSnapshot {
+	return p.snapshot
+}
+
+// Camera returns the free camera the replay is watched from.
+func (p *ReplayPlayback) Camera() *Spectator {
+	return p.camera
+}
+
+// Paused reports whether playback is paused.
+func (p *ReplayPlayback) Paused() bool {
+	return p.paused
+}
+
+// SetPaused pauses or resumes playback.
+func (p *ReplayPlayback) SetPaused(paused bool) {
+	p.paused = paused
+	p.elapsed = 0
+}
+
+// Speed returns the playback speed, 1 being real time.
+func (p *ReplayPlayback) Speed() float64 {
+	return p.speed
+}
+
+// SetSpeed sets the playback speed, 1 being real time.
+func (p *ReplayPlayback) SetSpeed(speed float64) {
+	if speed > 0 {
+		p.speed = speed
+	}
+}
+
+// Done reports whether playback has reached the last tick.
+func (p *ReplayPlayback) Done() bool {
+	return p.index >= len(p.replay.deltas)-1
+}
+
+// Progress returns how far through the replay playback is, from 0 to 1.
+func (p *ReplayPlayback) Progress() float64 {
+	if len(p.replay.deltas) < 2 {
+		return 1
+	}
+	return float64(p.index) / float64(len(p.replay.deltas)-1)
+}
+
+// Elapsed returns the match time at the tick being shown.
+func (p *ReplayPlayback) Elapsed() time.Duration {
+	return time.Duration(p.index+1) * time.Second / time.Duration(p.replay.TickRate)
+}
+
+// Seek jumps to the given tick, clamped to the recorded ones.
+func (p *ReplayPlayback) Seek(tick uint64) error {
+	snapshot, err := p.replay.SnapshotAt(tick)
+	if err != nil {
+		return err
+	}
+	p.index = p.replay.index(tick)
+	p.snapshot = snapshot
+	p.decoder = NewDeltaDecoder()
+	p.decoder.SetChained(true)
+	p.decoder.SetBaseline(snapshot)
+	p.elapsed = 0
+	return nil
+}
+
+// SeekBy jumps forward by d of match time, or back when d is negative.
+func (p *ReplayPlayback) SeekBy(d time.Duration) error {
+	tick := int64(p.Tick()) + int64(d*time.Duration(p.replay.TickRate)/time.Second)
+	return p.Seek(uint64(max(tick, 0)))
+}
+
+// Step advances one tick, for stepping through a paused replay. It reports
+// false at the end.
+func (p *ReplayPlayback) Step() (bool, error) {
+	if p.Done() {
+		return false, nil
+	}
+	snapshot, err := p.decoder.ApplyDelta(p.replay.deltas[p.index+1])
+	if err != nil {
+		return false, err
+	}
+	p.index++
+	p.snapshot = snapshot
+	return true, nil
+}
+
+// Update advances playback by dt of real time at the playback speed. It
+// pauses at the end of the replay.
+func (p *ReplayPlayback) Update(dt time.Duration) error {
+	if p.paused {
+		return nil
+	}
+	tickDuration := time.Second / time.Duration(p.replay.TickRate)
+	p.elapsed += time.Duration(float64(dt) * p.speed)
+	for p.elapsed >= tickDuration {
+		p.elapsed -= tickDuration
+		more, err := p.Step()
+		if err != nil {
+			return err
+		}
+		if !more {
+			p.SetPaused(true)
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// recordWalk records a player walking a tenth of a tile along x each tick,
+// for the given number of ticks starting at tick 1.
+func recordWalk(t *testing.T, ticks int) (*MatchRecorder, engine.Entity) {
+	t.Helper()
+	w := engine.NewWorld()
+	player := w.NewPlayerEntity(0, 0)
+	r := NewMatchRecorder(MatchInfo{Seed: 42, Genre: "scifi", Mode: "ffa"})
+	for tick := 1; tick <= ticks; tick++ {
+		pos, _ := w.GetComponent(player, reflect.TypeOf(&engine.Position{}))
+		pos.(*engine.Position).X = float64(tick) / 10
+		data, _ := json.Marshal(MoveCommand{DX: 0.1})
+		r.RecordCommand(uint64(tick), &PlayerCommand{Type: CommandMove, Data: data})
+		if err := r.RecordTick(w, uint64(tick)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r, player
+}
+
+// replayX returns the player's x in the snapshot.
+func replayX(t *testing.T, snapshot *WorldSnapshot, player engine.Entity) float64 {
+	t.Helper()
+	es, ok := snapshot.Entities[player]
+	if !ok {
+		t.Fatalf("tick %d has no player", snapshot.TickNumber)
+	}
+	x, _, ok := snapshotPosition(es)
+	if !ok {
+		t.Fatalf("tick %d has no player position", snapshot.TickNumber)
+	}
+	return x
+}
+
+func TestMatchReplayRoundTrip(t *testing.T) {
+	ticks := KeyframeInterval*2 + 30
+	r, player := recordWalk(t, ticks)
+	if r.Ticks() != ticks {
+		t.Fatalf("Ticks() = %d, want %d", r.Ticks(), ticks)
+	}
+
+	path := filepath.Join(t.TempDir(), "match.vmr")
+	if err := r.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := LoadMatchReplay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Info.Seed != 42 || replay.Info.Genre != "scifi" || replay.TickRate != TickRate {
+		t.Errorf("loaded %+v at %d ticks/s", replay.Info, replay.TickRate)
+	}
+	if replay.FirstTick() != 1 || replay.LastTick() != uint64(ticks) {
+		t.Errorf("ticks %d to %d, want 1 to %d", replay.FirstTick(), replay.LastTick(), ticks)
+	}
+	if want := time.Duration(ticks) * TickDuration; replay.Duration() != want {
+		t.Errorf("Duration() = %v, want %v", replay.Duration(), want)
+	}
+
+	// Ticks on, just after and between keyframes
+	for _, tick := range []uint64{1, 2, KeyframeInterval + 1, KeyframeInterval + 2, uint64(ticks)} {
+		snapshot, err := replay.SnapshotAt(tick)
+		if err != nil {
+			t.Fatalf("SnapshotAt(%d): %v", tick, err)
+		}
+		if snapshot.TickNumber != tick {
+			t.Errorf("SnapshotAt(%d) is tick %d", tick, snapshot.TickNumber)
+		}
+		if x := replayX(t, snapshot, player); x != float64(tick)/10 {
+			t.Errorf("tick %d: x = %v, want %v", tick, x, float64(tick)/10)
+		}
+	}
+	if snapshot, _ := replay.SnapshotAt(uint64(ticks) + 50); snapshot.TickNumber != uint64(ticks) {
+		t.Errorf("SnapshotAt past the end is tick %d, want the last", snapshot.TickNumber)
+	}
+
+	cmds := replay.CommandsAt(7)
+	if len(cmds) != 1 || cmds[0].Command.Type != CommandMove {
+		t.Errorf("CommandsAt(7) = %+v", cmds)
+	}
+	if len(replay.CommandsAt(uint64(ticks)+1)) != 0 {
+		t.Error("CommandsAt() after the match should be empty")
+	}
+}
+
+func TestMatchReplayErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := NewMatchRecorder(MatchInfo{}).WriteTo(&buf); !errors.Is(err, ErrEmptyReplay) {
+		t.Errorf("WriteTo() of an empty recording: err = %v, want ErrEmptyReplay", err)
+	}
+
+	r, _ := recordWalk(t, 3)
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.Bytes()
+
+	corrupt := map[string]func(data []byte){
+		"magic":     func(data []byte) { data[0] = 'X' },
+		"version":   func(data []byte) { data[4] = 9 },
+		"tick rate": func(data []byte) { data[6], data[7] = 0, 0 },
+		"ticks":     func(data []byte) { data[8] = 7 },
+		"body":      func(data []byte) { data[len(data)-12] ^= 0xff },
+	}
+	for name, fn := range corrupt {
+		data := append([]byte(nil), valid...)
+		fn(data)
+		if _, err := ReadMatchReplay(bytes.NewReader(data)); err == nil {
+			t.Errorf("bad %s: ReadMatchReplay() succeeded", name)
+		}
+	}
+	if _, err := ReadMatchReplay(bytes.NewReader(valid[:10])); err == nil {
+		t.Error("truncated header: ReadMatchReplay() succeeded")
+	}
+}
+
+func TestReplayPlayback(t *testing.T) {
+	r, player := recordWalk(t, KeyframeInterval+20)
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadMatchReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewReplayPlayback(replay, CameraView{X: 2, Y: 2})
+	if p.Tick() != 1 || p.Paused() || p.Progress() != 0 || p.Elapsed() != TickDuration {
+		t.Fatalf("started at tick %d, paused %v", p.Tick(), p.Paused())
+	}
+
+	// A tick and a half of play shows the next tick and keeps the rest
+	if err := p.Update(TickDuration * 3 / 2); err != nil {
+		t.Fatal(err)
+	}
+	if p.Tick() != 2 || replayX(t, p.Snapshot(), player) != 0.2 {
+		t.Errorf("after 1.5 ticks: tick %d", p.Tick())
+	}
+	p.SetSpeed(2)
+	p.SetSpeed(-1)
+	if err := p.Update(TickDuration); err != nil {
+		t.Fatal(err)
+	}
+	if p.Tick() != 4 || p.Speed() != 2 {
+		t.Errorf("at double speed: tick %d, speed %v", p.Tick(), p.Speed())
+	}
+
+	p.SetPaused(true)
+	p.Update(time.Second)
+	if p.Tick() != 4 {
+		t.Errorf("paused playback moved to tick %d", p.Tick())
+	}
+	if more, err := p.Step(); !more || err != nil || p.Tick() != 5 {
+		t.Errorf("Step() = %v, %v; at tick %d", more, err, p.Tick())
+	}
+
+	if err := p.Seek(KeyframeInterval + 5); err != nil {
+		t.Fatal(err)
+	}
+	if p.Tick() != KeyframeInterval+5 || replayX(t, p.Snapshot(), player) != float64(KeyframeInterval+5)/10 {
+		t.Errorf("after seeking: tick %d", p.Tick())
+	}
+	if _, err := p.Step(); err != nil {
+		t.Fatalf("Step() after seeking: %v", err)
+	}
+	if err := p.SeekBy(-time.Second); err != nil || p.Tick() != KeyframeInterval+6-TickRate {
+		t.Errorf("SeekBy(-1s) = %v, at tick %d", err, p.Tick())
+	}
+	if err := p.SeekBy(-time.Hour); err != nil || p.Tick() != replay.FirstTick() {
+		t.Errorf("SeekBy(-1h) = %v, at tick %d", err, p.Tick())
+	}
+	if x, _, ok := p.Snapshot().Entities[player].Position(); !ok || x != 0.1 {
+		t.Errorf("Position() = %v, %v", x, ok)
+	}
+
+	// Playing past the end stops on the last tick
+	p.SetPaused(false)
+	if err := p.Update(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Done() || !p.Paused() || p.Tick() != replay.LastTick() || p.Progress() != 1 {
+		t.Errorf("at the end: tick %d, done %v, paused %v", p.Tick(), p.Done(), p.Paused())
+	}
+	if more, _ := p.Step(); more {
+		t.Error("Step() at the end should report false")
+	}
+
+	cam := p.Camera()
+	cam.Move(1, 0, 1)
+	if cam.Mode() != SpectateFree || cam.View().X != 2+SpectatorSpeed {
+		t.Errorf("free camera at %+v", cam.View())
+	}
+}
+
+func TestGameServer_Recording(t *testing.T) {
+	server, err := NewGameServer(0, engine.NewWorld())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+	server.SetPlayerSpawner(func(w *engine.World, clientID uint64) engine.Entity {
+		return w.NewPlayerEntity(3, 3)
+	})
+	recorder := NewMatchRecorder(MatchInfo{Seed: 9})
+	server.SetRecorder(recorder)
+
+	conn, deltas := pipeClient(t, server)
+	defer conn.Close()
+	nextDelta(t, server, deltas)
+
+	data, _ := json.Marshal(MoveCommand{DX: 0.25})
+	json.NewEncoder(conn).Encode(&PlayerCommand{Type: CommandMove, Data: data})
+	recorded := func() int {
+		recorder.mu.Lock()
+		defer recorder.mu.Unlock()
+		return len(recorder.commands)
+	}
+	for i := 0; i < 10 && recorded() == 0; i++ {
+		nextDelta(t, server, deltas)
+	}
+
+	var buf bytes.Buffer
+	if _, err := recorder.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	replay, err := ReadMatchReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replay.Commands) != 1 || replay.Commands[0].Command.Type != CommandMove {
+		t.Fatalf("recorded commands %+v, want the move", replay.Commands)
+	}
+	if replay.LastTick() != server.GetTickNumber() {
+		t.Errorf("recorded up to tick %d, server is at %d", replay.LastTick(), server.GetTickNumber())
+	}
+}
@@ -0,0 +1,386 @@
+package network
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// DefaultIntermission is the pause between rounds when MatchRules doesn't
+// set one.
+const DefaultIntermission = 10 * time.Second
+
+// Reasons a round, and so a match, ends.
+const (
+	EndScoreLimit = "score limit" // A team reached the frag or score limit
+	EndTimeLimit  = "time limit"  // Time ran out with a team ahead
+	EndOvertime   = "overtime"    // Overtime settled the round or ran out
+)
+
+// MatchPhase is where a team match stands.
+type MatchPhase int
+
+const (
+	PhaseWaiting      MatchPhase = iota // Not started yet
+	PhasePlaying                        // A round is on
+	PhaseOvertime                       // Time ran out with the round undecided
+	PhaseIntermission                   // Between rounds
+	PhaseFinished                       // The match is over
+)
+
+// String returns the phase's display name.
+func (p MatchPhase) String() string {
+	switch p {
+	case PhaseWaiting:
+		return "waiting"
+	case PhasePlaying:
+		return "playing"
+	case PhaseOvertime:
+		return "overtime"
+	case PhaseIntermission:
+		return "intermission"
+	case PhaseFinished:
+		return "finished"
+	default:
+		return "unknown"
+	}
+}
+
+// MatchRules are a team match's round, overtime and respawn rules. The zero
+// value plays one round with no overtime and respawns each player
+// RespawnDelay after they die.
+type MatchRules struct {
+	Rounds       int           // Rounds to play, won by the first team to take most of them; 0 plays one
+	Overtime     time.Duration // Most overtime an undecided round gets; 0 ends it when time runs out
+	RespawnWave  time.Duration // Respawn the dead together on this interval; 0 respawns each on their own
+	Intermission time.Duration // Pause between rounds; 0 means DefaultIntermission
+}
+
+// TeamResult is a team's showing in a match.
+type TeamResult struct {
+	Team      int `json:"team"`
+	Score     int `json:"score"` // Frags or points over all rounds
+	RoundsWon int `json:"roundsWon"`
+}
+
+// PlayerResult is a player's showing in a match.
+type PlayerResult struct {
+	PlayerID uint64 `json:"playerID"`
+	Team     int    `json:"team"`
+	Frags    int    `json:"frags"`
+	Deaths   int    `json:"deaths"`
+}
+
+// MatchResult is how a team match ended.
+type MatchResult struct {
+	MatchID    string         `json:"matchID"`
+	Mode       string         `json:"mode"`
+	WinnerTeam int            `json:"winnerTeam"` // -1 for a draw
+	Reason     string         `json:"reason"`     // Why the deciding round ended
+	Rounds     []int          `json:"rounds"`     // Each round's winning team, -1 for a draw
+	Teams      []TeamResult   `json:"teams"`
+	Players    []PlayerResult `json:"players"` // By team, best first
+	Duration   time.Duration  `json:"duration"`
+}
+
+// ControlPointStatus is a control point as clients see it.
+type ControlPointStatus struct {
+	ID        string                `json:"id"`
+	Owner     ControlPointOwnership `json:"owner"`
+	Progress  float64               `json:"progress"`
+	Contested bool                  `json:"contested"`
+}
+
+// MatchStatus is a team match's live state. Servers send it to clients
+// whenever it changes, with the result once the match is over.
+type MatchStatus struct {
+	Mode          string               `json:"mode"`
+	Phase         MatchPhase           `json:"phase"`
+	Round         int                  `json:"round"`
+	Rounds        int                  `json:"rounds"`
+	TimeLeft      time.Duration        `json:"timeLeft"` // Of the round, overtime or intermission, to the second
+	Teams         []TeamResult         `json:"teams"`    // Scores in this round
+	Players       []PlayerResult       `json:"players"`
+	ControlPoints []ControlPointStatus `json:"controlPoints,omitempty"`
+	Result        *MatchResult         `json:"result,omitempty"`
+}
+
+// MatchMode is a game mode's rules engine, as a GameServer runs it.
+type MatchMode interface {
+	// Tick advances the match to now. It returns the result on the
+	// tick the match ends and nil on every other.
+	Tick(now time.Time) *MatchResult
+
+	// Status returns the match's live state.
+	Status(now time.Time) MatchStatus
+}
+
+// rounds returns how many rounds the match plays at most.
+func (r MatchRules) rounds() int {
+	if r.Rounds < 1 {
+		return 1
+	}
+	return r.Rounds
+}
+
+// intermission returns the pause between rounds.
+func (r MatchRules) intermission() time.Duration {
+	if r.Intermission <= 0 {
+		return DefaultIntermission
+	}
+	return r.Intermission
+}
+
+// roundState tracks the rounds of a team match. The match's lock guards it.
+type roundState struct {
+	rules      MatchRules
+	phase      MatchPhase
+	round      int
+	matchStart time.Time
+	roundStart time.Time
+	phaseStart time.Time // When the round, overtime or intermission began
+	winners    []int     // Each finished round's winner
+	totals     [2]int    // Scores over finished rounds
+	result     *MatchResult
+	delivered  bool // The result has been returned from Tick
+}
+
+// start begins the first round.
+func (r *roundState) start(rules MatchRules, now time.Time) {
+	r.rules = rules
+	r.phase = PhasePlaying
+	r.round = 1
+	r.matchStart, r.roundStart, r.phaseStart = now, now, now
+}
+
+// inPlay reports whether kills and captures count.
+func (r *roundState) inPlay() bool {
+	return r.phase == PhaseWaiting || r.phase == PhasePlaying || r.phase == PhaseOvertime
+}
+
+// wins counts the rounds a team has taken.
+func (r *roundState) wins(team int) int {
+	n := 0
+	for _, w := range r.winners {
+		if w == team {
+			n++
+		}
+	}
+	return n
+}
+
+// endRound records a round's winner and scores, and reports whether that
+// decides the match: a team has taken most of the rounds, or they've all
+// been played.
+func (r *roundState) endRound(winner int, scores [2]int, now time.Time) bool {
+	r.winners = append(r.winners, winner)
+	r.totals[TeamRed] += scores[TeamRed]
+	r.totals[TeamBlue] += scores[TeamBlue]
+	need := r.rules.rounds()/2 + 1
+	if r.wins(TeamRed) >= need || r.wins(TeamBlue) >= need || len(r.winners) >= r.rules.rounds() {
+		r.phase = PhaseFinished
+		return true
+	}
+	r.phase = PhaseIntermission
+	r.phaseStart = now
+	return false
+}
+
+// nextRound starts the round after an intermission.
+func (r *roundState) nextRound(now time.Time) {
+	r.round++
+	r.phase = PhasePlaying
+	r.roundStart, r.phaseStart = now, now
+}
+
+// timeUp reports whether the round's time limit or its overtime has run out.
+func (r *roundState) timeUp(limit time.Duration, now time.Time) bool {
+	switch r.phase {
+	case PhasePlaying:
+		return now.Sub(r.roundStart) >= limit
+	case PhaseOvertime:
+		return now.Sub(r.phaseStart) >= r.rules.Overtime
+	default:
+		return false
+	}
+}
+
+// enterOvertime moves an undecided round into overtime, and reports false
+// if the rules allow none or it's already had it.
+func (r *roundState) enterOvertime(now time.Time) bool {
+	if r.phase != PhasePlaying || r.rules.Overtime <= 0 {
+		return false
+	}
+	r.phase = PhaseOvertime
+	r.phaseStart = now
+	return true
+}
+
+// winner returns the team with the most rounds, or -1 if they're level.
+func (r *roundState) winner() int {
+	red, blue := r.wins(TeamRed), r.wins(TeamBlue)
+	switch {
+	case red > blue:
+		return TeamRed
+	case blue > red:
+		return TeamBlue
+	default:
+		return -1
+	}
+}
+
+// respawnAt returns when a player who died at died comes back: after
+// RespawnDelay, held to the next wave when the rules respawn in waves.
+// Waves run from the start of the round.
+func (r *roundState) respawnAt(died time.Time) time.Time {
+	earliest := died.Add(RespawnDelay)
+	wave := r.rules.RespawnWave
+	if wave <= 0 || r.roundStart.IsZero() || earliest.Before(r.roundStart) {
+		return earliest
+	}
+	waves := (earliest.Sub(r.roundStart) + wave - 1) / wave
+	return r.roundStart.Add(waves * wave)
+}
+
+// timeLeft returns what's left of the current phase, to the second.
+func (r *roundState) timeLeft(limit time.Duration, now time.Time) time.Duration {
+	var left time.Duration
+	switch r.phase {
+	case PhasePlaying:
+		left = limit - now.Sub(r.roundStart)
+	case PhaseOvertime:
+		left = r.rules.Overtime - now.Sub(r.phaseStart)
+	case PhaseIntermission:
+		left = r.rules.intermission() - now.Sub(r.phaseStart)
+	}
+	if left < 0 {
+		return 0
+	}
+	return left.Round(time.Second)
+}
+
+// takeResult returns the result the first time it's asked for after the
+// match ends.
+func (r *roundState) takeResult() *MatchResult {
+	if r.result == nil || r.delivered {
+		return nil
+	}
+	r.delivered = true
+	return r.result
+}
+
+// teamResults returns both teams' scores and round wins.
+func (r *roundState) teamResults(scores [2]int) []TeamResult {
+	return []TeamResult{
+		{Team: TeamRed, Score: scores[TeamRed], RoundsWon: r.wins(TeamRed)},
+		{Team: TeamBlue, Score: scores[TeamBlue], RoundsWon: r.wins(TeamBlue)},
+	}
+}
+
+// buildResult records the match's result once it's finished.
+func (r *roundState) buildResult(matchID, mode, reason string, players map[uint64]*TeamPlayerState, now time.Time) *MatchResult {
+	r.result = &MatchResult{
+		MatchID:    matchID,
+		Mode:       mode,
+		WinnerTeam: r.winner(),
+		Reason:     reason,
+		Rounds:     append([]int(nil), r.winners...),
+		Teams:      r.teamResults(r.totals),
+		Players:    teamPlayerResults(players),
+	}
+	if !r.matchStart.IsZero() {
+		r.result.Duration = now.Sub(r.matchStart)
+	}
+	return r.result
+}
+
+// teamScores reads both teams' scores in this round.
+func teamScores(teams map[int]*TeamScore) [2]int {
+	var scores [2]int
+	for _, team := range []int{TeamRed, TeamBlue} {
+		ts := teams[team]
+		ts.mu.RLock()
+		scores[team] = ts.Frags
+		ts.mu.RUnlock()
+	}
+	return scores
+}
+
+// resetTeamScores zeroes both teams' scores for a new round.
+func resetTeamScores(teams map[int]*TeamScore) {
+	for _, ts := range teams {
+		ts.mu.Lock()
+		ts.Frags, ts.Deaths = 0, 0
+		ts.mu.Unlock()
+	}
+}
+
+// leadingTeam returns the team ahead on score, or -1 if they're level.
+func leadingTeam(scores [2]int) int {
+	switch {
+	case scores[TeamRed] > scores[TeamBlue]:
+		return TeamRed
+	case scores[TeamBlue] > scores[TeamRed]:
+		return TeamBlue
+	default:
+		return -1
+	}
+}
+
+// teamPlayerResults lists the players by team, then most frags, then ID.
+func teamPlayerResults(players map[uint64]*TeamPlayerState) []PlayerResult {
+	results := make([]PlayerResult, 0, len(players))
+	for id, p := range players {
+		p.mu.RLock()
+		results = append(results, PlayerResult{PlayerID: id, Team: p.Team, Frags: p.Frags, Deaths: p.Deaths})
+		p.mu.RUnlock()
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Team != b.Team {
+			return a.Team < b.Team
+		}
+		if a.Frags != b.Frags {
+			return a.Frags > b.Frags
+		}
+		return a.PlayerID < b.PlayerID
+	})
+	return results
+}
+
+// reviveAll brings every player back for a new round, at their team's
+// spawn points when there are any.
+func reviveAll(players map[uint64]*TeamPlayerState, spawns map[int][]SpawnPoint) {
+	for id, p := range players {
+		p.mu.RLock()
+		team, spawn := p.Team, SpawnPoint{X: p.PosX, Y: p.PosY}
+		p.mu.RUnlock()
+		if points := spawns[team]; len(points) > 0 {
+			spawn = points[int(id)%len(points)]
+		}
+		applyRespawn(&teamPlayerAdapter{p}, spawn)
+	}
+}
+
+// respawnDuePlayers brings back the dead whose respawn time has come, at a
+// random spawn point of their team or where they fell when it has none.
+func respawnDuePlayers(players map[uint64]*TeamPlayerState, spawns map[int][]SpawnPoint, seed uint64, now time.Time) []uint64 {
+	var respawned []uint64
+	for id, p := range players {
+		adapter := &teamPlayerAdapter{p}
+		if !respawnDue(adapter, now) {
+			continue
+		}
+		p.mu.RLock()
+		team, spawn := p.Team, SpawnPoint{X: p.PosX, Y: p.PosY}
+		p.mu.RUnlock()
+		if points := spawns[team]; len(points) > 0 {
+			rng := rand.New(rand.NewSource(int64(seed + id + uint64(now.UnixNano()))))
+			spawn = points[rng.Intn(len(points))]
+		}
+		applyRespawn(adapter, spawn)
+		respawned = append(respawned, id)
+	}
+	sort.Slice(respawned, func(i, j int) bool { return respawned[i] < respawned[j] })
+	return respawned
+}
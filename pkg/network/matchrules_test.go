@@ -0,0 +1,245 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// newTestTeamMatch starts a one-on-one team match with the given rules.
+func newTestTeamMatch(t *testing.T, fragLimit int, rules MatchRules) *TeamMatch {
+	t.Helper()
+	m, _ := NewTeamMatch("rules", fragLimit, time.Minute, 1)
+	m.Rules = rules
+	m.AddPlayer(1, TeamRed)
+	m.AddPlayer(2, TeamBlue)
+	m.GenerateSpawnPoints(2, 100, 100)
+	if err := m.StartMatch(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestTeamMatch_Rounds(t *testing.T) {
+	m := newTestTeamMatch(t, 1, MatchRules{Rounds: 3})
+
+	m.OnPlayerKill(1, 2)
+	if m.IsFinished() || m.state.phase != PhaseIntermission {
+		t.Fatalf("after round 1: finished %v, phase %v", m.IsFinished(), m.state.phase)
+	}
+	if err := m.OnPlayerKill(2, 1); err == nil {
+		t.Error("kill in the intermission counted")
+	}
+
+	if result := m.Tick(time.Now().Add(DefaultIntermission)); result != nil {
+		t.Fatalf("Tick() starting round 2 returned %+v", result)
+	}
+	status := m.Status(time.Now())
+	if status.Round != 2 || status.Phase != PhasePlaying || status.Teams[TeamRed].Score != 0 || status.Teams[TeamRed].RoundsWon != 1 {
+		t.Fatalf("round 2 status = %+v", status)
+	}
+	m.mu.RLock()
+	dead := m.Players[2].Dead
+	m.mu.RUnlock()
+	if dead {
+		t.Error("players should be revived for the next round")
+	}
+
+	// Red taking the second round of three decides the match
+	m.OnPlayerKill(1, 2)
+	if !m.IsFinished() || m.GetWinner() != TeamRed {
+		t.Fatalf("finished %v, winner %d", m.IsFinished(), m.GetWinner())
+	}
+	result := m.Tick(time.Now())
+	if result == nil || !reflect.DeepEqual(result.Rounds, []int{TeamRed, TeamRed}) || result.Reason != EndScoreLimit {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.Teams[TeamRed].Score != 2 || len(result.Players) != 2 || result.Players[0].PlayerID != 1 {
+		t.Errorf("result totals = %+v, players %+v", result.Teams, result.Players)
+	}
+	if m.Tick(time.Now()) != nil {
+		t.Error("Tick() returned the result twice")
+	}
+}
+
+func TestTeamMatch_Overtime(t *testing.T) {
+	t.Run("first kill wins", func(t *testing.T) {
+		m := newTestTeamMatch(t, 50, MatchRules{Overtime: 30 * time.Second})
+		m.Tick(m.StartTime.Add(time.Minute))
+		if m.state.phase != PhaseOvertime || m.IsFinished() {
+			t.Fatalf("level at the time limit: phase %v", m.state.phase)
+		}
+		m.OnPlayerKill(2, 1)
+		if !m.IsFinished() || m.GetWinner() != TeamBlue || m.state.result.Reason != EndOvertime {
+			t.Errorf("winner %d, result %+v", m.GetWinner(), m.state.result)
+		}
+	})
+
+	t.Run("runs out as a draw", func(t *testing.T) {
+		m := newTestTeamMatch(t, 50, MatchRules{Overtime: 30 * time.Second})
+		m.Tick(m.StartTime.Add(time.Minute))
+		if result := m.Tick(m.StartTime.Add(time.Minute + 30*time.Second)); result == nil || result.WinnerTeam != -1 {
+			t.Errorf("result = %+v, want a draw", result)
+		}
+	})
+
+	t.Run("no overtime is a draw", func(t *testing.T) {
+		m := newTestTeamMatch(t, 50, MatchRules{})
+		if result := m.Tick(m.StartTime.Add(time.Minute)); result == nil || result.WinnerTeam != -1 || result.Reason != EndTimeLimit {
+			t.Errorf("result = %+v, want a draw on time", result)
+		}
+	})
+}
+
+func TestRoundState_RespawnWaves(t *testing.T) {
+	start := time.Now()
+	var r roundState
+	r.start(MatchRules{RespawnWave: 10 * time.Second}, start)
+
+	tests := []struct {
+		died, want time.Duration
+	}{
+		{0, 10 * time.Second},
+		{7 * time.Second, 10 * time.Second},
+		{8 * time.Second, 20 * time.Second},
+		{17 * time.Second, 20 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := r.respawnAt(start.Add(tt.died)).Sub(start); got != tt.want {
+			t.Errorf("died at %v: respawn at %v, want %v", tt.died, got, tt.want)
+		}
+	}
+
+	r.rules.RespawnWave = 0
+	if got := r.respawnAt(start).Sub(start); got != RespawnDelay {
+		t.Errorf("without waves: respawn after %v, want %v", got, RespawnDelay)
+	}
+}
+
+func TestControlPoint_ContestAndDecay(t *testing.T) {
+	cp := NewControlPoint("cp", 1, 0, 0)
+	cp.UpdateCapture(1, 1)
+	if !cp.Contested || cp.GetCaptureProgress() != 0 {
+		t.Errorf("1v1: contested %v, progress %f", cp.Contested, cp.GetCaptureProgress())
+	}
+	cp.UpdateCapture(2, 1)
+	if !cp.Contested || cp.GetCaptureProgress() >= 0 {
+		t.Errorf("2v1: contested %v, progress %f", cp.Contested, cp.GetCaptureProgress())
+	}
+
+	// An abandoned capture drifts back to neutral
+	cp.CaptureProgress = -0.5
+	cp.UpdateCapture(0, 0)
+	if cp.Contested || cp.GetCaptureProgress() != -0.5+CaptureDecayPerTick {
+		t.Errorf("abandoned: contested %v, progress %f", cp.Contested, cp.GetCaptureProgress())
+	}
+
+	// An owned point drifts back to its owner
+	cp.Owner, cp.CaptureProgress = OwnershipRed, -0.95
+	for i := 0; i < 5; i++ {
+		cp.UpdateCapture(0, 0)
+	}
+	if cp.GetOwner() != OwnershipRed || cp.GetCaptureProgress() != -1 {
+		t.Errorf("owned: owner %v, progress %f", cp.GetOwner(), cp.GetCaptureProgress())
+	}
+}
+
+func TestTerritoryMatch_Overtime(t *testing.T) {
+	m, _ := NewTerritoryMatch("overtime", 100, time.Minute, 1)
+	m.Rules = MatchRules{Overtime: time.Minute}
+	m.ScoreTickRate = time.Hour
+	m.AddPlayer(1, TeamRed)
+	m.AddPlayer(2, TeamBlue)
+	m.AddControlPoint("cp", 0, 0)
+	m.Start()
+	m.ControlPoints["cp"].Owner = OwnershipRed
+	m.ControlPoints["cp"].CaptureProgress = -1
+	m.SetPlayerPosition(1, 50, 50)
+	m.SetPlayerPosition(2, 0, 0)
+
+	m.Tick(m.StartTime.Add(time.Minute))
+	if m.state.phase != PhaseOvertime {
+		t.Fatalf("level at the time limit: phase %v", m.state.phase)
+	}
+
+	// Red pulls ahead, but blue is still on red's point
+	m.Teams[TeamRed].Frags = 1
+	m.Tick(m.StartTime.Add(time.Minute + time.Second))
+	if m.Finished {
+		t.Fatal("overtime ended while blue was contesting")
+	}
+	status := m.Status(time.Now())
+	if len(status.ControlPoints) != 1 || status.ControlPoints[0].ID != "cp" || status.Mode != "territory" {
+		t.Errorf("status = %+v", status)
+	}
+
+	m.SetPlayerPosition(2, 50, 50)
+	result := m.Tick(m.StartTime.Add(time.Minute + 2*time.Second))
+	if result == nil || result.WinnerTeam != TeamRed || result.Reason != EndOvertime {
+		t.Errorf("result = %+v, want red in overtime", result)
+	}
+}
+
+func TestTerritoryMatch_RoundsResetPoints(t *testing.T) {
+	m, _ := NewTerritoryMatch("rounds", 5, time.Minute, 1)
+	m.Rules = MatchRules{Rounds: 3}
+	m.AddPlayer(1, TeamRed)
+	m.AddPlayer(2, TeamBlue)
+	m.AddControlPoint("cp", 0, 0)
+	m.Start()
+
+	m.ControlPoints["cp"].Owner = OwnershipBlue
+	m.Teams[TeamBlue].Frags = 5
+	if m.CheckWinCondition() || m.state.phase != PhaseIntermission {
+		t.Fatalf("after round 1: phase %v", m.state.phase)
+	}
+	if err := m.OnPlayerKill(1, 2); err == nil {
+		t.Error("kill in the intermission counted")
+	}
+
+	m.Tick(time.Now().Add(DefaultIntermission))
+	if owner := m.ControlPoints["cp"].GetOwner(); owner != OwnershipNeutral {
+		t.Errorf("point owner in round 2 = %v, want neutral", owner)
+	}
+	if score, _ := m.GetTeamScore(TeamBlue); score != 0 || m.state.round != 2 {
+		t.Errorf("round %d blue score %d", m.state.round, score)
+	}
+}
+
+func TestGameServer_MatchStatus(t *testing.T) {
+	server, err := NewGameServer(0, engine.NewWorld())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+
+	match, _ := NewTeamMatch("broadcast", 50, time.Minute, 1)
+	match.AddPlayer(1, TeamRed)
+	match.AddPlayer(2, TeamBlue)
+	server.SetMatch(match)
+
+	conn, deltas := pipeClient(t, server)
+	defer conn.Close()
+	session := NewSession()
+
+	delta := nextDelta(t, server, deltas)
+	session.Observe(&delta)
+	if delta.Match == nil || delta.Match.Mode != "team" || len(delta.Match.Players) != 2 {
+		t.Fatalf("first delta's match = %+v", delta.Match)
+	}
+	if delta = nextDelta(t, server, deltas); delta.Match != nil {
+		t.Errorf("unchanged match resent: %+v", delta.Match)
+	}
+
+	match.OnPlayerKill(1, 2)
+	delta = nextDelta(t, server, deltas)
+	session.Observe(&delta)
+	if delta.Match == nil || delta.Match.Teams[TeamRed].Score != 1 {
+		t.Fatalf("match after a kill = %+v", delta.Match)
+	}
+	if status, ok := session.Match(); !ok || status.Teams[TeamRed].Score != 1 {
+		t.Errorf("Session.Match() = %+v, %v", status, ok)
+	}
+}
@@ -2,6 +2,7 @@
 package network
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
@@ -81,13 +82,18 @@ func (p *teamPlayerAdapter) ClearRespawnTime() { p.RespawnTime = time.Time{} }
 // This shared helper consolidates duplicate logic from FFAMatch.ProcessRespawns
 // and TeamMatch.ProcessRespawns.
 func canRespawn(player PlayerState) bool {
+	return respawnDue(player, time.Now())
+}
+
+// respawnDue checks if a player is ready to respawn at the given time.
+func respawnDue(player PlayerState, now time.Time) bool {
 	mu := player.GetMutex()
 	mu.RLock()
 	isDead := player.IsDead()
 	respawnTime := player.GetRespawnTime()
 	mu.RUnlock()
 
-	return isDead && !respawnTime.IsZero() && time.Now().After(respawnTime)
+	return isDead && !respawnTime.IsZero() && now.After(respawnTime)
 }
 
 // applyRespawn applies respawn state to a player at the given spawn point.
@@ -102,3 +108,17 @@ func applyRespawn(player PlayerState, spawn SpawnPoint) {
 	player.ClearRespawnTime()
 	mu.Unlock()
 }
+
+// orderSpectateTargets sorts the players a dead player can spectate by ID,
+// putting first (usually their killer) at the front if it's among them.
+// This shared helper backs FFAMatch.SpectateTargets and
+// TeamMatch.SpectateTargets.
+func orderSpectateTargets(ids []uint64, first uint64) []uint64 {
+	sort.Slice(ids, func(i, j int) bool {
+		if (ids[i] == first) != (ids[j] == first) {
+			return ids[i] == first
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
@@ -0,0 +1,179 @@
+package network
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+const (
+	// CommandResume is sent by a reconnecting client, with their session
+	// token as its data, to take back their player.
+	CommandResume = "resume"
+	// CommandHostAddr is sent by a client that can host the game if the
+	// server goes away, with the address it would listen on as its data.
+	// A missing host, as in ":7777", is filled in from the connection.
+	CommandHostAddr = "host_addr"
+
+	// DefaultReconnectGrace is how long a dropped player's session is
+	// kept for them to reconnect to.
+	DefaultReconnectGrace = 30 * time.Second
+)
+
+// SessionInfo tells a client who they are on the server. It's sent with
+// each initial delta: when they connect and when they resume.
+type SessionInfo struct {
+	PlayerID uint64 `json:"player_id"`
+	Token    string `json:"token"`
+}
+
+// PeerInfo is a player in the roster the server sends out for host
+// migration. It carries a hash of the player's token, so a new host can
+// check a token without the roster giving them away.
+type PeerInfo struct {
+	PlayerID  uint64        `json:"player_id"`
+	Addr      string        `json:"addr,omitempty"` // Where they'd host, if they can
+	TokenHash string        `json:"token_hash"`
+	Entity    engine.Entity `json:"entity"`
+	HasEntity bool          `json:"has_entity"`
+}
+
+// suspendedSession is a dropped player waiting to reconnect.
+type suspendedSession struct {
+	id        uint64
+	entity    engine.Entity
+	hasEntity bool
+	hostAddr  string
+	expires   time.Time
+}
+
+// newSessionToken returns a random session token.
+func newSessionToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// hashToken returns the hash sessions are looked up by.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ElectHost picks who hosts once lostHost, the address of the server
+// that went away, is gone: the player with the lowest ID that can host
+// somewhere else. Every client runs it on the same roster, so they all
+// agree without talking to each other.
+func ElectHost(peers []PeerInfo, lostHost string) (PeerInfo, bool) {
+	var best PeerInfo
+	found := false
+	for _, p := range peers {
+		if p.Addr == "" || p.Addr == lostHost {
+			continue
+		}
+		if !found || p.PlayerID < best.PlayerID {
+			best, found = p, true
+		}
+	}
+	return best, found
+}
+
+// Session is a client's hold on their place in a game: the ID and token
+// the server gave them, the latest roster for host migration, the latest
+// match status and the co-op level they joined.
+type Session struct {
+	mu       sync.RWMutex
+	playerID uint64
+	token    string
+	peers    []PeerInfo
+	match    *MatchStatus
+	level    *LevelSnapshot
+}
+
+// NewSession creates an empty client session.
+func NewSession() *Session {
+	return &Session{}
+}
+
+// Observe records the session, roster, match status and co-op level
+// carried by a delta from the server. Call it on every delta received.
+func (s *Session) Observe(delta *DeltaPacket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if delta.Session != nil {
+		s.playerID = delta.Session.PlayerID
+		s.token = delta.Session.Token
+	}
+	if delta.Peers != nil {
+		s.peers = append([]PeerInfo(nil), delta.Peers...)
+	}
+	if delta.Match != nil {
+		s.match = delta.Match
+	}
+	if delta.Level != nil {
+		s.level = delta.Level
+	}
+}
+
+// Level returns the co-op level as it stood when the client last joined
+// mid-level, or nil if they haven't.
+func (s *Session) Level() *LevelSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.level
+}
+
+// Match returns the latest match status, and false if the server hasn't
+// sent one.
+func (s *Session) Match() (MatchStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.match == nil {
+		return MatchStatus{}, false
+	}
+	return *s.match, true
+}
+
+// PlayerID returns the client's player ID on the server.
+func (s *Session) PlayerID() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.playerID
+}
+
+// Token returns the session token, or "" before the server has sent one.
+func (s *Session) Token() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.token
+}
+
+// ResumeCommand returns the command to send first on a new connection to
+// take the session back, or nil if there's no session to resume.
+func (s *Session) ResumeCommand() *PlayerCommand {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.token == "" {
+		return nil
+	}
+	return &PlayerCommand{Type: CommandResume, Data: []byte(s.token)}
+}
+
+// NextHost returns the player to reconnect to once the server at
+// lostHost has gone away. If it's this client, it should start a server
+// with NewMigratedGameServer and reconnect to it like everyone else.
+func (s *Session) NextHost(lostHost string) (PeerInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return ElectHost(s.peers, lostHost)
+}
+
+// sortPeers orders a roster by player ID.
+func sortPeers(peers []PeerInfo) {
+	sort.Slice(peers, func(i, j int) bool { return peers[i].PlayerID < peers[j].PlayerID })
+}
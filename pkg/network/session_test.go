@@ -0,0 +1,47 @@
+package network
+
+import "testing"
+
+func TestElectHost(t *testing.T) {
+	peers := []PeerInfo{
+		{PlayerID: 3, Addr: "10.0.0.3:7777"},
+		{PlayerID: 0, Addr: "10.0.0.1:7777"},
+		{PlayerID: 1},
+		{PlayerID: 2, Addr: "10.0.0.2:7777"},
+	}
+
+	host, ok := ElectHost(peers, "10.0.0.1:7777")
+	if !ok || host.PlayerID != 2 {
+		t.Errorf("ElectHost() = %+v, %v; want player 2", host, ok)
+	}
+	if host, _ := ElectHost(peers, "10.0.0.9:7777"); host.PlayerID != 0 {
+		t.Errorf("ElectHost() = %+v, want player 0", host)
+	}
+	if _, ok := ElectHost([]PeerInfo{{PlayerID: 1}}, ""); ok {
+		t.Error("ElectHost() found a host among players who can't host")
+	}
+}
+
+func TestSessionObserve(t *testing.T) {
+	s := NewSession()
+	if s.ResumeCommand() != nil {
+		t.Error("ResumeCommand() without a session should be nil")
+	}
+
+	s.Observe(&DeltaPacket{
+		Session: &SessionInfo{PlayerID: 4, Token: "abc"},
+		Peers:   []PeerInfo{{PlayerID: 4, Addr: "10.0.0.4:7777"}},
+	})
+	s.Observe(&DeltaPacket{BaseTick: 1, TargetTick: 2})
+
+	if s.PlayerID() != 4 || s.Token() != "abc" {
+		t.Errorf("session = %d, %q", s.PlayerID(), s.Token())
+	}
+	cmd := s.ResumeCommand()
+	if cmd == nil || cmd.Type != CommandResume || string(cmd.Data) != "abc" {
+		t.Errorf("ResumeCommand() = %+v", cmd)
+	}
+	if host, ok := s.NextHost("10.0.0.1:7777"); !ok || host.PlayerID != 4 {
+		t.Errorf("NextHost() = %+v, %v", host, ok)
+	}
+}
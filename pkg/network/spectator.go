@@ -0,0 +1,338 @@
+package network
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+const (
+	// KillCamDuration is how much of the lead-up to a kill the kill-cam
+	// replays. It is shorter than RespawnDelay so the replay ends before
+	// the player is back in.
+	KillCamDuration = 2 * time.Second
+
+	// KillCamTicks is KillCamDuration in snapshots.
+	KillCamTicks = int(KillCamDuration / TickDuration)
+
+	// SpectatorSpeed is the free camera's speed in tiles per second.
+	SpectatorSpeed = 6.0
+)
+
+// SpectatorMode is what a dead player's camera shows.
+type SpectatorMode int
+
+const (
+	SpectateKillCam SpectatorMode = iota // Replaying the fatal shot from the killer's view
+	SpectateFollow                       // Following a living player
+	SpectateFree                         // Flying freely around the map
+)
+
+// String returns the mode's display name.
+func (m SpectatorMode) String() string {
+	switch m {
+	case SpectateKillCam:
+		return "kill-cam"
+	case SpectateFollow:
+		return "follow"
+	case SpectateFree:
+		return "free"
+	default:
+		return "unknown"
+	}
+}
+
+// CameraView is where a camera is and the way it faces.
+type CameraView struct {
+	X, Y       float64
+	DirX, DirY float64
+}
+
+// snapshotPosition reads an entity's position from a snapshot, whether it
+// holds the component itself or, after a trip through JSON, a map of it.
+func snapshotPosition(es *EntitySnapshot) (x, y float64, ok bool) {
+	switch p := es.Components["position"].(type) {
+	case engine.Position:
+		return p.X, p.Y, true
+	case *engine.Position:
+		return p.X, p.Y, p != nil
+	case map[string]interface{}:
+		x, okX := p["X"].(float64)
+		y, okY := p["Y"].(float64)
+		return x, y, okX && okY
+	}
+	return 0, 0, false
+}
+
+// Position returns the entity's position, if the snapshot holds one.
+func (es *EntitySnapshot) Position() (x, y float64, ok bool) {
+	return snapshotPosition(es)
+}
+
+// KillCam replays the snapshots leading up to a kill, seen from where the
+// killer stood and facing the victim.
+type KillCam struct {
+	frames []*WorldSnapshot
+	frame  int
+	killer engine.Entity
+	victim engine.Entity
+}
+
+// NewKillCam builds a kill-cam from buffered snapshot history, oldest
+// first, keeping the last KillCamTicks snapshots.
+func NewKillCam(history []*WorldSnapshot, killer, victim engine.Entity) *KillCam {
+	if len(history) > KillCamTicks {
+		history = history[len(history)-KillCamTicks:]
+	}
+	frames := make([]*WorldSnapshot, len(history))
+	copy(frames, history)
+	return &KillCam{frames: frames, killer: killer, victim: victim}
+}
+
+// Killer returns the killer's entity.
+func (k *KillCam) Killer() engine.Entity {
+	return k.killer
+}
+
+// Done reports whether the replay has ended.
+func (k *KillCam) Done() bool {
+	return k.frame >= len(k.frames)
+}
+
+// Advance moves the replay on one snapshot and reports whether there is
+// still a snapshot to show.
+func (k *KillCam) Advance() bool {
+	if !k.Done() {
+		k.frame++
+	}
+	return !k.Done()
+}
+
+// Frame returns the snapshot being shown, or nil once the replay is done.
+func (k *KillCam) Frame() *WorldSnapshot {
+	if k.Done() {
+		return nil
+	}
+	return k.frames[k.frame]
+}
+
+// Progress returns how far through the replay it is, from 0 to 1.
+func (k *KillCam) Progress() float64 {
+	if len(k.frames) == 0 {
+		return 1
+	}
+	return float64(k.frame) / float64(len(k.frames))
+}
+
+// View returns the camera for the current snapshot: at the killer, facing
+// the victim. It reports false if the snapshot doesn't place the killer.
+func (k *KillCam) View() (CameraView, bool) {
+	frame := k.Frame()
+	if frame == nil {
+		return CameraView{}, false
+	}
+	killer, ok := frame.Entities[k.killer]
+	if !ok {
+		return CameraView{}, false
+	}
+	x, y, ok := snapshotPosition(killer)
+	if !ok {
+		return CameraView{}, false
+	}
+	view := CameraView{X: x, Y: y, DirX: 1}
+	if victim, ok := frame.Entities[k.victim]; ok {
+		if vx, vy, ok := snapshotPosition(victim); ok {
+			if d := math.Hypot(vx-x, vy-y); d > 1e-9 {
+				view.DirX, view.DirY = (vx-x)/d, (vy-y)/d
+			}
+		}
+	}
+	return view, true
+}
+
+// Spectator drives a dead player's camera: a kill-cam of their death, then
+// following living players or a free camera, until they respawn.
+type Spectator struct {
+	mode      SpectatorMode
+	view      CameraView
+	target    engine.Entity
+	hasTarget bool
+	killCam   *KillCam
+}
+
+// NewSpectator creates a spectator with a free camera at the given view,
+// usually where the player died.
+func NewSpectator(from CameraView) *Spectator {
+	if from.DirX == 0 && from.DirY == 0 {
+		from.DirX = 1
+	}
+	return &Spectator{mode: SpectateFree, view: from}
+}
+
+// StartKillCam replays the kill from the snapshot history. Once the replay
+// ends, the spectator follows the killer.
+func (s *Spectator) StartKillCam(history []*WorldSnapshot, killer, victim engine.Entity) {
+	s.target, s.hasTarget = killer, true
+	s.killCam = NewKillCam(history, killer, victim)
+	if s.killCam.Done() {
+		s.killCam = nil
+		s.mode = SpectateFollow
+		return
+	}
+	s.mode = SpectateKillCam
+	if view, ok := s.killCam.View(); ok {
+		s.view = view
+	}
+}
+
+// Mode returns what the camera is showing.
+func (s *Spectator) Mode() SpectatorMode {
+	return s.mode
+}
+
+// View returns the spectator camera.
+func (s *Spectator) View() CameraView {
+	return s.view
+}
+
+// Target returns the player being followed.
+func (s *Spectator) Target() (engine.Entity, bool) {
+	return s.target, s.hasTarget
+}
+
+// KillCam returns the kill-cam being replayed, or nil outside of one.
+func (s *Spectator) KillCam() *KillCam {
+	if s.mode != SpectateKillCam {
+		return nil
+	}
+	return s.killCam
+}
+
+// ToggleFree switches between following a player and the free camera.
+// During the kill-cam it skips the rest of the replay.
+func (s *Spectator) ToggleFree() {
+	switch {
+	case s.mode == SpectateKillCam:
+		s.endKillCam()
+	case s.mode == SpectateFree && s.hasTarget:
+		s.mode = SpectateFollow
+	default:
+		s.mode = SpectateFree
+	}
+}
+
+// Cycle follows the next of the candidates, or the previous one when step
+// is negative, skipping any without a position or with no health left.
+func (s *Spectator) Cycle(w *engine.World, candidates []engine.Entity, step int) bool {
+	alive := make([]engine.Entity, 0, len(candidates))
+	current := -1
+	for _, e := range candidates {
+		if !followable(w, e) {
+			continue
+		}
+		if s.hasTarget && e == s.target {
+			current = len(alive)
+		}
+		alive = append(alive, e)
+	}
+	if len(alive) == 0 {
+		return false
+	}
+
+	next := 0
+	if current >= 0 {
+		if step < 0 {
+			next = (current - 1 + len(alive)) % len(alive)
+		} else {
+			next = (current + 1) % len(alive)
+		}
+	}
+	s.target, s.hasTarget = alive[next], true
+	if s.mode == SpectateKillCam {
+		s.killCam = nil
+	}
+	s.mode = SpectateFollow
+	s.follow(w)
+	return true
+}
+
+// Move flies the free camera forward and sideways by the given fractions
+// of SpectatorSpeed over dt seconds. It does nothing in the other modes.
+func (s *Spectator) Move(forward, strafe, dt float64) {
+	if s.mode != SpectateFree {
+		return
+	}
+	step := SpectatorSpeed * dt
+	s.view.X += (s.view.DirX*forward - s.view.DirY*strafe) * step
+	s.view.Y += (s.view.DirY*forward + s.view.DirX*strafe) * step
+}
+
+// Turn rotates the free camera by angle radians.
+func (s *Spectator) Turn(angle float64) {
+	if s.mode != SpectateFree {
+		return
+	}
+	sin, cos := math.Sincos(angle)
+	s.view.DirX, s.view.DirY = s.view.DirX*cos-s.view.DirY*sin, s.view.DirX*sin+s.view.DirY*cos
+}
+
+// Update steps the kill-cam a snapshot or moves the camera with the
+// followed player. Call it once per snapshot.
+func (s *Spectator) Update(w *engine.World) {
+	switch s.mode {
+	case SpectateKillCam:
+		if !s.killCam.Advance() {
+			s.endKillCam()
+			s.follow(w)
+			return
+		}
+		if view, ok := s.killCam.View(); ok {
+			s.view = view
+		}
+	case SpectateFollow:
+		s.follow(w)
+	}
+}
+
+// endKillCam leaves the kill-cam for the killer.
+func (s *Spectator) endKillCam() {
+	s.killCam = nil
+	s.mode = SpectateFollow
+}
+
+// follow moves the camera to the followed player, falling back to the free
+// camera where it is if they're gone.
+func (s *Spectator) follow(w *engine.World) {
+	if s.mode != SpectateFollow {
+		return
+	}
+	if !s.hasTarget || !followable(w, s.target) {
+		s.mode = SpectateFree
+		return
+	}
+	pos, _ := w.GetComponent(s.target, reflect.TypeOf(&engine.Position{}))
+	p := pos.(*engine.Position)
+	s.view.X, s.view.Y = p.X, p.Y
+	if c, ok := w.GetComponent(s.target, reflect.TypeOf(&engine.Camera{})); ok {
+		if cam := c.(*engine.Camera); cam.DirX != 0 || cam.DirY != 0 {
+			s.view.DirX, s.view.DirY = cam.DirX, cam.DirY
+		}
+	}
+}
+
+// followable reports whether the entity can be followed: it has a position
+// and, if it has health, some left.
+func followable(w *engine.World, e engine.Entity) bool {
+	if w == nil {
+		return false
+	}
+	if _, ok := w.GetComponent(e, reflect.TypeOf(&engine.Position{})); !ok {
+		return false
+	}
+	if h, ok := w.GetComponent(e, reflect.TypeOf(&engine.Health{})); ok && h.(*engine.Health).Current <= 0 {
+		return false
+	}
+	return true
+}
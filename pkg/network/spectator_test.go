@@ -0,0 +1,201 @@
+package network
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// killHistory returns snapshots of a killer at (0, 0) and a victim walking
+// along the x axis, one per tick.
+func killHistory(ticks int, killer, victim engine.Entity) []*WorldSnapshot {
+	history := make([]*WorldSnapshot, ticks)
+	for i := range history {
+		history[i] = &WorldSnapshot{TickNumber: uint64(i), Entities: map[engine.Entity]*EntitySnapshot{
+			killer: {EntityID: killer, Components: map[string]interface{}{"position": engine.Position{X: 0, Y: 0}}},
+			victim: {EntityID: victim, Components: map[string]interface{}{"position": engine.Position{X: 5, Y: float64(i)}}},
+		}}
+	}
+	return history
+}
+
+func TestKillCam(t *testing.T) {
+	history := killHistory(KillCamTicks+10, 1, 2)
+	k := NewKillCam(history, 1, 2)
+	if k.Frame().TickNumber != 10 {
+		t.Errorf("first frame is tick %d, want the last %d ticks", k.Frame().TickNumber, KillCamTicks)
+	}
+
+	view, ok := k.View()
+	if !ok || view.X != 0 || view.Y != 0 {
+		t.Fatalf("View() = %+v, %v; want at the killer", view, ok)
+	}
+	if want := math.Atan2(10, 5); math.Abs(math.Atan2(view.DirY, view.DirX)-want) > 1e-9 {
+		t.Errorf("view faces %v, want the victim at %v", math.Atan2(view.DirY, view.DirX), want)
+	}
+
+	frames := 1
+	for k.Advance() {
+		frames++
+	}
+	if frames != KillCamTicks || !k.Done() || k.Frame() != nil || k.Progress() != 1 {
+		t.Errorf("replayed %d frames, done %v", frames, k.Done())
+	}
+	if _, ok := k.View(); ok {
+		t.Error("View() after the replay should report false")
+	}
+}
+
+func TestKillCamDecodedSnapshots(t *testing.T) {
+	data, err := json.Marshal(killHistory(3, 1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []*WorldSnapshot
+	if err := json.Unmarshal(data, &history); err != nil {
+		t.Fatal(err)
+	}
+	view, ok := NewKillCam(history, 1, 2).View()
+	if !ok || view.DirX != 1 || view.DirY != 0 {
+		t.Errorf("View() of decoded snapshots = %+v, %v", view, ok)
+	}
+}
+
+func TestSpectator(t *testing.T) {
+	world := engine.NewWorld()
+	killer := world.AddEntity()
+	world.AddComponent(killer, &engine.Position{X: 3, Y: 4})
+	world.AddComponent(killer, &engine.Camera{DirX: 0, DirY: 1})
+	ally := world.AddEntity()
+	world.AddComponent(ally, &engine.Position{X: 10, Y: 10})
+	world.AddComponent(ally, &engine.Health{Current: 50, Max: 100})
+	dead := world.AddEntity()
+	world.AddComponent(dead, &engine.Position{X: 20, Y: 20})
+	world.AddComponent(dead, &engine.Health{Current: 0, Max: 100})
+	victim := world.AddEntity()
+
+	s := NewSpectator(CameraView{X: 1, Y: 1})
+	if s.Mode() != SpectateFree || s.View().DirX != 1 {
+		t.Fatalf("new spectator: mode %v, view %+v", s.Mode(), s.View())
+	}
+
+	s.StartKillCam(killHistory(3, killer, victim), killer, victim)
+	if s.Mode() != SpectateKillCam || s.KillCam() == nil {
+		t.Fatalf("mode %v after StartKillCam", s.Mode())
+	}
+	for i := 0; i < 3; i++ {
+		s.Update(world)
+	}
+	if s.Mode() != SpectateFollow || s.KillCam() != nil {
+		t.Fatalf("mode %v after the kill-cam, want follow", s.Mode())
+	}
+	if v := s.View(); v.X != 3 || v.Y != 4 || v.DirY != 1 {
+		t.Errorf("following the killer: view %+v", v)
+	}
+
+	candidates := []engine.Entity{killer, ally, dead}
+	if !s.Cycle(world, candidates, 1) {
+		t.Fatal("Cycle() found nobody to follow")
+	}
+	if target, _ := s.Target(); target != ally {
+		t.Errorf("Cycle(+1) followed %d, want %d", target, ally)
+	}
+	s.Cycle(world, candidates, 1)
+	if target, _ := s.Target(); target != killer {
+		t.Errorf("Cycle(+1) should skip the dead and wrap, followed %d", target)
+	}
+	s.Cycle(world, candidates, -1)
+	if target, _ := s.Target(); target != ally {
+		t.Errorf("Cycle(-1) followed %d, want %d", target, ally)
+	}
+
+	s.Move(1, 0, 1)
+	if v := s.View(); v.X != 10 || v.Y != 10 {
+		t.Error("Move() should do nothing while following")
+	}
+	s.ToggleFree()
+	if s.Mode() != SpectateFree {
+		t.Fatalf("mode %v after ToggleFree", s.Mode())
+	}
+	// Still facing the way the killer did, +y; turn to face +x
+	s.Turn(-math.Pi / 2)
+	s.Move(1, 0, 0.5)
+	if v := s.View(); math.Abs(v.X-(10+SpectatorSpeed/2)) > 1e-9 || math.Abs(v.Y-10) > 1e-9 {
+		t.Errorf("free camera moved to (%v, %v)", v.X, v.Y)
+	}
+	s.ToggleFree()
+	if s.Mode() != SpectateFollow {
+		t.Errorf("mode %v after toggling back", s.Mode())
+	}
+
+	// The followed player dying leaves the camera free where it was
+	world.RemoveEntity(ally)
+	s.Update(world)
+	if s.Mode() != SpectateFree {
+		t.Errorf("mode %v with the target gone, want free", s.Mode())
+	}
+	if s.Cycle(world, []engine.Entity{dead}, 1) {
+		t.Error("Cycle() should find nobody among the dead")
+	}
+
+	// Without history the kill-cam goes straight to the killer
+	s.StartKillCam(nil, killer, victim)
+	if s.Mode() != SpectateFollow {
+		t.Errorf("mode %v after a kill-cam with no history", s.Mode())
+	}
+}
+
+func TestInterpolationBufferHistory(t *testing.T) {
+	b := NewInterpolationBuffer(KillCamTicks)
+	for i := 0; i < KillCamTicks+5; i++ {
+		b.AddSnapshot(&WorldSnapshot{TickNumber: uint64(i)})
+	}
+	history := b.History(0, 100)
+	if len(history) != KillCamTicks || history[0].TickNumber != 5 {
+		t.Errorf("History() kept %d snapshots from tick %d", len(history), history[0].TickNumber)
+	}
+	if got := b.History(10, 12); len(got) != 3 || got[2].TickNumber != 12 {
+		t.Errorf("History(10, 12) = %d snapshots", len(got))
+	}
+}
+
+func TestSpectateTargets(t *testing.T) {
+	ffa, _ := NewFFAMatch("ffa", 10, time.Minute, 1)
+	for id := uint64(1); id <= 4; id++ {
+		ffa.AddPlayer(id)
+	}
+	ffa.StartMatch()
+	ffa.OnPlayerKill(3, 1)
+	ffa.OnPlayerKill(2, 4)
+
+	targets, err := ffa.SpectateTargets(1)
+	if err != nil || len(targets) != 2 || targets[0] != 3 || targets[1] != 2 {
+		t.Errorf("FFA SpectateTargets(1) = %v, %v; want the killer first, then 2", targets, err)
+	}
+	if ffa.Players[1].KillerID != 3 {
+		t.Errorf("KillerID = %d, want 3", ffa.Players[1].KillerID)
+	}
+	if _, err := ffa.SpectateTargets(9); err == nil {
+		t.Error("SpectateTargets() of an unknown player should fail")
+	}
+
+	team, _ := NewTeamMatch("team", 10, time.Minute, 1)
+	team.AddPlayer(1, TeamRed)
+	team.AddPlayer(2, TeamBlue)
+	team.AddPlayer(3, TeamRed)
+	team.AddPlayer(4, TeamRed)
+	team.StartMatch()
+	team.OnPlayerKill(2, 1)
+	team.OnPlayerSuicide(4)
+
+	targets, err = team.SpectateTargets(1)
+	if err != nil || len(targets) != 1 || targets[0] != 3 {
+		t.Errorf("team SpectateTargets(1) = %v, %v; want the living teammate only", targets, err)
+	}
+	if team.Players[1].KillerID != 2 || team.Players[4].KillerID != 0 {
+		t.Errorf("KillerIDs = %d, %d", team.Players[1].KillerID, team.Players[4].KillerID)
+	}
+}
@@ -28,6 +28,7 @@ type TeamPlayerState struct {
 	Active      bool
 	Dead        bool
 	RespawnTime time.Time
+	KillerID    uint64 // Who made the last kill of the player; 0 after a suicide
 	PosX        float64
 	PosY        float64
 	Health      float64
@@ -57,6 +58,8 @@ type TeamMatch struct {
 	StartTime   time.Time
 	WinnerTeam  int
 	Seed        uint64
+	Rules       MatchRules // Set before StartMatch
+	state       roundState
 	mu          sync.RWMutex
 }
 
@@ -235,6 +238,7 @@ func (m *TeamMatch) StartMatch() error {
 
 	m.Started = true
 	m.StartTime = time.Now()
+	m.state.start(m.Rules, m.StartTime)
 
 	// Spawn all players at their team's spawn points
 	for playerID, player := range m.Players {
@@ -254,6 +258,7 @@ func (m *TeamMatch) StartMatch() error {
 		"player_count": len(m.Players),
 		"frag_limit":   m.FragLimit,
 		"time_limit":   m.TimeLimit,
+		"rounds":       m.Rules.rounds(),
 	}).Info("Team match started")
 
 	return nil
@@ -267,6 +272,10 @@ func (m *TeamMatch) OnPlayerKill(killerID, victimID uint64) error {
 	if m.Finished {
 		return fmt.Errorf("match already finished")
 	}
+	if !m.state.inPlay() {
+		return fmt.Errorf("round %d is over", m.state.round)
+	}
+	now := time.Now()
 
 	killer, killerExists := m.Players[killerID]
 	victim, victimExists := m.Players[victimID]
@@ -287,7 +296,8 @@ func (m *TeamMatch) OnPlayerKill(killerID, victimID uint64) error {
 	victimTeam := victim.Team
 	victim.Deaths++
 	victim.Dead = true
-	victim.RespawnTime = time.Now().Add(RespawnDelay)
+	victim.RespawnTime = m.state.respawnAt(now)
+	victim.KillerID = killerID
 	victim.mu.Unlock()
 
 	// Update team scores
@@ -312,15 +322,11 @@ func (m *TeamMatch) OnPlayerKill(killerID, victimID uint64) error {
 		"team_frags":  currentTeamFrags,
 	}).Info("Player kill registered")
 
-	// Check win condition
+	// The frag limit ends the round, and so does the first kill in overtime
 	if currentTeamFrags >= m.FragLimit {
-		m.Finished = true
-		m.WinnerTeam = killerTeam
-		logrus.WithFields(logrus.Fields{
-			"match_id":    m.MatchID,
-			"winner_team": killerTeam,
-			"frags":       currentTeamFrags,
-		}).Info("Team match finished - frag limit reached")
+		m.finishRound(killerTeam, EndScoreLimit, now)
+	} else if m.state.phase == PhaseOvertime {
+		m.finishRound(killerTeam, EndOvertime, now)
 	}
 
 	return nil
@@ -334,6 +340,9 @@ func (m *TeamMatch) OnPlayerSuicide(playerID uint64) error {
 	if m.Finished {
 		return fmt.Errorf("match already finished")
 	}
+	if !m.state.inPlay() {
+		return fmt.Errorf("round %d is over", m.state.round)
+	}
 
 	player, exists := m.Players[playerID]
 	if !exists {
@@ -345,7 +354,8 @@ func (m *TeamMatch) OnPlayerSuicide(playerID uint64) error {
 	player.Frags--
 	player.Deaths++
 	player.Dead = true
-	player.RespawnTime = time.Now().Add(RespawnDelay)
+	player.RespawnTime = m.state.respawnAt(time.Now())
+	player.KillerID = 0
 	player.mu.Unlock()
 
 	// Update team deaths
@@ -415,53 +425,139 @@ func (m *TeamMatch) RespawnPlayer(playerID uint64) error {
 	return nil
 }
 
-// CheckTimeLimit checks if the time limit has been reached.
-func (m *TeamMatch) CheckTimeLimit() bool {
+// SpectateTargets returns the players a dead player can spectate until
+// they respawn: their living teammates, so enemy positions stay hidden.
+func (m *TeamMatch) SpectateTargets(playerID uint64) ([]uint64, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if !m.Started || m.Finished {
+	player, exists := m.Players[playerID]
+	if !exists {
+		return nil, fmt.Errorf("player %d not in match", playerID)
+	}
+	player.mu.RLock()
+	team := player.Team
+	player.mu.RUnlock()
+
+	targets := make([]uint64, 0, len(m.Players))
+	for id, other := range m.Players {
+		if id == playerID {
+			continue
+		}
+		other.mu.RLock()
+		eligible := other.Active && !other.Dead && other.Team == team
+		other.mu.RUnlock()
+		if eligible {
+			targets = append(targets, id)
+		}
+	}
+	return orderSpectateTargets(targets, 0), nil
+}
+
+// CheckTimeLimit checks if the round's time limit, or its overtime, has
+// run out, and reports whether that ended the round. A round that's level
+// when time runs out goes to overtime if the rules allow it, and is a draw
+// otherwise.
+func (m *TeamMatch) CheckTimeLimit() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkTime(time.Now())
+}
+
+// checkTime is CheckTimeLimit at now. The caller holds m.mu.
+func (m *TeamMatch) checkTime(now time.Time) bool {
+	if !m.Started || m.Finished || !m.state.timeUp(m.TimeLimit, now) {
 		return false
 	}
+	if m.state.phase == PhaseOvertime {
+		m.finishRound(-1, EndOvertime, now)
+		return true
+	}
 
-	elapsed := time.Since(m.StartTime)
-	if elapsed >= m.TimeLimit {
-		m.mu.RUnlock()
-		m.mu.Lock()
-		m.Finished = true
-		m.WinnerTeam = m.getLeadingTeam()
-		m.mu.Unlock()
-		m.mu.RLock()
+	leader := leadingTeam(teamScores(m.Teams))
+	if leader < 0 && m.state.enterOvertime(now) {
+		logrus.WithFields(logrus.Fields{
+			"match_id": m.MatchID,
+			"round":    m.state.round,
+			"overtime": m.state.rules.Overtime,
+		}).Info("Team match round tied - overtime")
+		return false
+	}
+	m.finishRound(leader, EndTimeLimit, now)
+	return true
+}
 
+// finishRound ends the round with winner taking it, or -1 for a draw, and
+// the match if that decides it. The caller holds m.mu.
+func (m *TeamMatch) finishRound(winner int, reason string, now time.Time) {
+	scores := teamScores(m.Teams)
+	if !m.state.endRound(winner, scores, now) {
 		logrus.WithFields(logrus.Fields{
 			"match_id":    m.MatchID,
-			"winner_team": m.WinnerTeam,
-			"elapsed":     elapsed,
-		}).Info("Team match finished - time limit reached")
-
-		return true
+			"round":       m.state.round,
+			"winner_team": winner,
+			"reason":      reason,
+		}).Info("Team match round finished")
+		return
 	}
 
-	return false
+	m.Finished = true
+	m.WinnerTeam = m.state.winner()
+	result := m.state.buildResult(m.MatchID, "team", reason, m.Players, now)
+	logrus.WithFields(logrus.Fields{
+		"match_id":    m.MatchID,
+		"winner_team": m.WinnerTeam,
+		"reason":      reason,
+		"rounds":      result.Rounds,
+		"duration":    result.Duration,
+	}).Info("Team match finished")
 }
 
-// getLeadingTeam returns the team with the most frags (must be called with lock held).
-func (m *TeamMatch) getLeadingTeam() int {
-	redScore := m.Teams[TeamRed]
-	blueScore := m.Teams[TeamBlue]
+// startNextRound clears the scores and brings everyone back for the next
+// round. The caller holds m.mu.
+func (m *TeamMatch) startNextRound(now time.Time) {
+	resetTeamScores(m.Teams)
+	reviveAll(m.Players, m.SpawnPoints)
+	m.state.nextRound(now)
 
-	redScore.mu.RLock()
-	redFrags := redScore.Frags
-	redScore.mu.RUnlock()
+	logrus.WithFields(logrus.Fields{
+		"match_id": m.MatchID,
+		"round":    m.state.round,
+	}).Info("Team match round started")
+}
+
+// Tick advances the match to now: it starts the next round once the
+// intermission is up, ends the round when time runs out and respawns
+// players who are due. It returns the result on the tick the match ends.
+func (m *TeamMatch) Tick(now time.Time) *MatchResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state.phase == PhaseIntermission && now.Sub(m.state.phaseStart) >= m.state.rules.intermission() {
+		m.startNextRound(now)
+	}
+	m.checkTime(now)
+	if m.Started && m.state.inPlay() {
+		respawnDuePlayers(m.Players, m.SpawnPoints, m.Seed, now)
+	}
+	return m.state.takeResult()
+}
 
-	blueScore.mu.RLock()
-	blueFrags := blueScore.Frags
-	blueScore.mu.RUnlock()
+// Status returns the match's live state.
+func (m *TeamMatch) Status(now time.Time) MatchStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	if redFrags > blueFrags {
-		return TeamRed
+	return MatchStatus{
+		Mode:     "team",
+		Phase:    m.state.phase,
+		Round:    m.state.round,
+		Rounds:   m.Rules.rounds(),
+		TimeLeft: m.state.timeLeft(m.TimeLimit, now),
+		Teams:    m.state.teamResults(teamScores(m.Teams)),
+		Players:  teamPlayerResults(m.Players),
+		Result:   m.state.result,
 	}
-	return TeamBlue
 }
 
 // GetPlayerStats returns the current stats for a player.
@@ -3,6 +3,7 @@ package network
 import (
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -17,6 +18,10 @@ const (
 	// CaptureRatePerTick is how much capture progress changes per tick when contested
 	CaptureRatePerTick = 0.05 // 5% per tick = 1 second at 20 ticks/sec
 
+	// CaptureDecayPerTick is how fast an abandoned point's progress drifts
+	// back to its owner, or to neutral if nobody owns it
+	CaptureDecayPerTick = CaptureRatePerTick / 2
+
 	// DefaultScoreTickRate is how often teams score points for held control points
 	DefaultScoreTickRate = time.Second
 
@@ -41,8 +46,10 @@ type ControlPoint struct {
 	PosY            float64
 	Owner           ControlPointOwnership
 	CaptureProgress float64 // -1.0 (full red) to +1.0 (full blue), 0.0 is neutral
+	Contested       bool    // Both teams are on the point
 	LastTickTime    time.Time
 	VisualStyle     string // Genre-specific visual style (altar/terminal/etc)
+	red, blue       int    // Players of each team on the point at the last update
 	mu              sync.RWMutex
 }
 
@@ -81,13 +88,18 @@ func (cp *ControlPoint) GetCaptureProgress() float64 {
 	return cp.CaptureProgress
 }
 
-// UpdateCapture processes capture logic based on nearby players.
-// Returns true if ownership changed.
+// UpdateCapture processes capture logic based on nearby players. The team
+// with more players on the point moves it their way, faster the more they
+// outnumber the other; a point with both teams on it is contested and only
+// moves if one side outnumbers the other. An empty point drifts back to its
+// owner. Returns true if ownership changed.
 func (cp *ControlPoint) UpdateCapture(redCount, blueCount int) bool {
 	cp.mu.Lock()
 	defer cp.mu.Unlock()
 
 	oldOwner := cp.Owner
+	cp.red, cp.blue = redCount, blueCount
+	cp.Contested = redCount > 0 && blueCount > 0
 
 	// Determine capture direction based on player counts
 	if redCount > blueCount {
@@ -96,6 +108,9 @@ func (cp *ControlPoint) UpdateCapture(redCount, blueCount int) bool {
 	} else if blueCount > redCount {
 		// Blue team is capturing
 		cp.CaptureProgress += CaptureRatePerTick * float64(blueCount-redCount)
+	} else if redCount == 0 {
+		// Nobody is on the point
+		cp.decay()
 	}
 	// If counts are equal (contested), progress doesn't change
 
@@ -131,6 +146,51 @@ func (cp *ControlPoint) UpdateCapture(redCount, blueCount int) bool {
 	return false
 }
 
+// decay drifts an empty point's progress toward its owner's side, or
+// toward neutral if nobody owns it (must be called with lock held).
+func (cp *ControlPoint) decay() {
+	target := 0.0
+	switch cp.Owner {
+	case OwnershipRed:
+		target = -1.0
+	case OwnershipBlue:
+		target = 1.0
+	}
+	if cp.CaptureProgress < target {
+		cp.CaptureProgress = math.Min(cp.CaptureProgress+CaptureDecayPerTick, target)
+	} else if cp.CaptureProgress > target {
+		cp.CaptureProgress = math.Max(cp.CaptureProgress-CaptureDecayPerTick, target)
+	}
+}
+
+// contestedBy reports whether team has players on the point and doesn't own it.
+func (cp *ControlPoint) contestedBy(team int) bool {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+	present := cp.red
+	if team == TeamBlue {
+		present = cp.blue
+	}
+	return present > 0 && cp.Owner != ControlPointOwnership(team)
+}
+
+// reset returns the point to neutral for a new round.
+func (cp *ControlPoint) reset() {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.Owner = OwnershipNeutral
+	cp.CaptureProgress = 0
+	cp.Contested = false
+	cp.red, cp.blue = 0, 0
+}
+
+// status returns the point as clients see it.
+func (cp *ControlPoint) status() ControlPointStatus {
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+	return ControlPointStatus{ID: cp.ID, Owner: cp.Owner, Progress: cp.CaptureProgress, Contested: cp.Contested}
+}
+
 // IsPlayerInRange checks if a player at (x, y) is within capture range.
 func (cp *ControlPoint) IsPlayerInRange(x, y float64) bool {
 	cp.mu.RLock()
@@ -183,7 +243,9 @@ type TerritoryMatch struct {
 	LastScoreTick time.Time
 	WinnerTeam    int
 	Seed          uint64
-	Genre         string // Current genre for control point visuals
+	Genre         string     // Current genre for control point visuals
+	Rules         MatchRules // Set before Start
+	state         roundState
 	mu            sync.RWMutex
 }
 
@@ -310,18 +372,28 @@ func (m *TerritoryMatch) Start() error {
 	m.Started = true
 	m.StartTime = time.Now()
 	m.LastScoreTick = m.StartTime
+	m.state.start(m.Rules, m.StartTime)
 
 	logrus.WithFields(logrus.Fields{
 		"match_id":       m.MatchID,
 		"player_count":   len(m.Players),
 		"control_points": len(m.ControlPoints),
+		"rounds":         m.Rules.rounds(),
 	}).Info("Territory match started")
 
 	return nil
 }
 
-// ProcessCapture updates capture progress for all control points.
+// ProcessCapture updates capture progress for all control points. Nothing
+// is captured between rounds or after the match.
 func (m *TerritoryMatch) ProcessCapture() {
+	m.mu.RLock()
+	inPlay := m.state.inPlay()
+	m.mu.RUnlock()
+	if !inPlay {
+		return
+	}
+
 	controlPoints, players := m.getMatchSnapshot()
 
 	for _, cp := range controlPoints {
@@ -378,11 +450,15 @@ func (m *TerritoryMatch) countPlayersNearPoint(cp *ControlPoint, players []*Team
 
 // ProcessScoring awards points to teams based on controlled points.
 func (m *TerritoryMatch) ProcessScoring() {
+	m.processScoring(time.Now())
+}
+
+// processScoring is ProcessScoring at now.
+func (m *TerritoryMatch) processScoring(now time.Time) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	now := time.Now()
-	if now.Sub(m.LastScoreTick) < m.ScoreTickRate {
+	if !m.state.inPlay() || now.Sub(m.LastScoreTick) < m.ScoreTickRate {
 		return
 	}
 
@@ -433,66 +509,225 @@ func (m *TerritoryMatch) ProcessScoring() {
 	}
 }
 
-// CheckWinCondition checks if a team has won.
+// CheckWinCondition ends the round when a team reaches the score limit or
+// time runs out, and reports whether the match is over. A round that's level
+// when time runs out goes to overtime if the rules allow it, and overtime
+// lasts while the trailing team is still fighting for a point it doesn't own.
 func (m *TerritoryMatch) CheckWinCondition() bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	return m.checkWin(time.Now())
+}
 
+// checkWin is CheckWinCondition at now. The caller holds m.mu.
+func (m *TerritoryMatch) checkWin(now time.Time) bool {
 	if m.Finished {
 		return true
 	}
+	if !m.state.inPlay() {
+		return false
+	}
 
-	// Check score limit
-	m.Teams[TeamRed].mu.RLock()
-	redScore := m.Teams[TeamRed].Frags
-	m.Teams[TeamRed].mu.RUnlock()
-
-	m.Teams[TeamBlue].mu.RLock()
-	blueScore := m.Teams[TeamBlue].Frags
-	m.Teams[TeamBlue].mu.RUnlock()
-
-	if redScore >= m.ScoreLimit {
-		m.Finished = true
-		m.WinnerTeam = TeamRed
-		logrus.WithFields(logrus.Fields{
-			"match_id":    m.MatchID,
-			"winner":      "red",
-			"final_score": redScore,
-		}).Info("Territory match ended - red team wins by score limit")
-		return true
+	scores := teamScores(m.Teams)
+	for _, team := range []int{TeamRed, TeamBlue} {
+		if scores[team] >= m.ScoreLimit {
+			m.finishRound(team, EndScoreLimit, now)
+			return m.Finished
+		}
 	}
 
-	if blueScore >= m.ScoreLimit {
-		m.Finished = true
-		m.WinnerTeam = TeamBlue
-		logrus.WithFields(logrus.Fields{
-			"match_id":    m.MatchID,
-			"winner":      "blue",
-			"final_score": blueScore,
-		}).Info("Territory match ended - blue team wins by score limit")
-		return true
+	if !m.Started {
+		return false
+	}
+	leader := leadingTeam(scores)
+	switch m.state.phase {
+	case PhasePlaying:
+		if !m.state.timeUp(m.TimeLimit, now) {
+			return false
+		}
+		if leader < 0 && m.state.enterOvertime(now) {
+			logrus.WithFields(logrus.Fields{
+				"match_id": m.MatchID,
+				"round":    m.state.round,
+				"overtime": m.state.rules.Overtime,
+			}).Info("Territory match round tied - overtime")
+			return false
+		}
+		m.finishRound(leader, EndTimeLimit, now)
+	case PhaseOvertime:
+		if !m.state.timeUp(m.TimeLimit, now) && (leader < 0 || m.contesting(1-leader)) {
+			return false
+		}
+		m.finishRound(leader, EndOvertime, now)
 	}
+	return m.Finished
+}
 
-	// Check time limit
-	if m.TimeLimit > 0 && time.Since(m.StartTime) >= m.TimeLimit {
-		m.Finished = true
-		if redScore > blueScore {
-			m.WinnerTeam = TeamRed
-		} else if blueScore > redScore {
-			m.WinnerTeam = TeamBlue
-		} else {
-			m.WinnerTeam = -1 // Tie
+// contesting reports whether team has players on a point it doesn't own.
+// The caller holds m.mu.
+func (m *TerritoryMatch) contesting(team int) bool {
+	for _, cp := range m.ControlPoints {
+		if cp.contestedBy(team) {
+			return true
 		}
+	}
+	return false
+}
+
+// finishRound ends the round with winner taking it, or -1 for a draw, and
+// the match if that decides it. The caller holds m.mu.
+func (m *TerritoryMatch) finishRound(winner int, reason string, now time.Time) {
+	scores := teamScores(m.Teams)
+	if !m.state.endRound(winner, scores, now) {
 		logrus.WithFields(logrus.Fields{
 			"match_id":   m.MatchID,
-			"winner":     m.WinnerTeam,
-			"red_score":  redScore,
-			"blue_score": blueScore,
-		}).Info("Territory match ended - time limit reached")
-		return true
+			"round":      m.state.round,
+			"winner":     winner,
+			"reason":     reason,
+			"red_score":  scores[TeamRed],
+			"blue_score": scores[TeamBlue],
+		}).Info("Territory match round finished")
+		return
 	}
 
-	return false
+	m.Finished = true
+	m.WinnerTeam = m.state.winner()
+	result := m.state.buildResult(m.MatchID, "territory", reason, m.Players, now)
+	logrus.WithFields(logrus.Fields{
+		"match_id":   m.MatchID,
+		"winner":     m.WinnerTeam,
+		"reason":     reason,
+		"rounds":     result.Rounds,
+		"red_score":  scores[TeamRed],
+		"blue_score": scores[TeamBlue],
+	}).Info("Territory match ended")
+}
+
+// startNextRound clears the scores, neutralises the points and brings
+// everyone back for the next round. The caller holds m.mu.
+func (m *TerritoryMatch) startNextRound(now time.Time) {
+	resetTeamScores(m.Teams)
+	reviveAll(m.Players, m.SpawnPoints)
+	for _, cp := range m.ControlPoints {
+		cp.reset()
+	}
+	m.LastScoreTick = now
+	m.state.nextRound(now)
+
+	logrus.WithFields(logrus.Fields{
+		"match_id": m.MatchID,
+		"round":    m.state.round,
+	}).Info("Territory match round started")
+}
+
+// Tick advances the match to now: it moves capture progress, scores held
+// points, ends the round on the score or time limit, starts the next round
+// once the intermission is up and respawns players who are due. It returns
+// the result on the tick the match ends.
+func (m *TerritoryMatch) Tick(now time.Time) *MatchResult {
+	m.ProcessCapture()
+	m.processScoring(now)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state.phase == PhaseIntermission && now.Sub(m.state.phaseStart) >= m.state.rules.intermission() {
+		m.startNextRound(now)
+	}
+	m.checkWin(now)
+	if m.Started && m.state.inPlay() {
+		respawnDuePlayers(m.Players, m.SpawnPoints, m.Seed, now)
+	}
+	return m.state.takeResult()
+}
+
+// OnPlayerKill registers a kill by one player of another. Kills don't
+// score in territory control, but the victim is off the points until they
+// respawn.
+func (m *TerritoryMatch) OnPlayerKill(killerID, victimID uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Finished {
+		return fmt.Errorf("match already finished")
+	}
+	if !m.state.inPlay() {
+		return fmt.Errorf("round %d is over", m.state.round)
+	}
+
+	killer, killerExists := m.Players[killerID]
+	victim, victimExists := m.Players[victimID]
+	if !killerExists {
+		return fmt.Errorf("killer %d not in match", killerID)
+	}
+	if !victimExists {
+		return fmt.Errorf("victim %d not in match", victimID)
+	}
+
+	killer.mu.Lock()
+	killer.Frags++
+	killer.mu.Unlock()
+
+	victim.mu.Lock()
+	victimTeam := victim.Team
+	victim.Deaths++
+	victim.Dead = true
+	victim.RespawnTime = m.state.respawnAt(time.Now())
+	victim.KillerID = killerID
+	victim.mu.Unlock()
+
+	ts := m.Teams[victimTeam]
+	ts.mu.Lock()
+	ts.Deaths++
+	ts.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"match_id":  m.MatchID,
+		"killer_id": killerID,
+		"victim_id": victimID,
+	}).Debug("Territory match kill registered")
+
+	return nil
+}
+
+// SetPlayerPosition moves a player, for working out who's on which point.
+func (m *TerritoryMatch) SetPlayerPosition(playerID uint64, x, y float64) error {
+	m.mu.RLock()
+	player, exists := m.Players[playerID]
+	m.mu.RUnlock()
+
+	if !exists {
+		return fmt.Errorf("player %d not in match", playerID)
+	}
+
+	player.mu.Lock()
+	player.PosX, player.PosY = x, y
+	player.mu.Unlock()
+	return nil
+}
+
+// Status returns the match's live state.
+func (m *TerritoryMatch) Status(now time.Time) MatchStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	points := make([]ControlPointStatus, 0, len(m.ControlPoints))
+	for _, cp := range m.ControlPoints {
+		points = append(points, cp.status())
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].ID < points[j].ID })
+
+	return MatchStatus{
+		Mode:          "territory",
+		Phase:         m.state.phase,
+		Round:         m.state.round,
+		Rounds:        m.Rules.rounds(),
+		TimeLeft:      m.state.timeLeft(m.TimeLimit, now),
+		Teams:         m.state.teamResults(teamScores(m.Teams)),
+		Players:       teamPlayerResults(m.Players),
+		ControlPoints: points,
+		Result:        m.state.result,
+	}
 }
 
 // GetTeamScore returns the score for a team.
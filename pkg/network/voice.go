@@ -0,0 +1,408 @@
+package network
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// CommandSpeaking is sent when a player starts or stops talking, with
+	// a JSON bool as its data. It's the only voice traffic players send
+	// when there's no voice integration to send frames.
+	CommandSpeaking = "speaking"
+	// CommandVoice carries one encoded voice frame: a big-endian uint32
+	// sequence number and then the frame.
+	CommandVoice = "voice"
+	// CommandMute tells the server to stop, or start again, relaying a
+	// player's voice to the sender; its data is a VoiceMute.
+	CommandMute = "mute"
+
+	// VoiceFrameDuration is the audio each frame holds, the usual opus
+	// frame.
+	VoiceFrameDuration = 20 * time.Millisecond
+	// MaxVoiceFrameSize is the largest frame relayed, the largest opus
+	// packet.
+	MaxVoiceFrameSize = 1275
+	// MaxVoiceFramesPerTick caps the frames relayed for a player each
+	// tick: a tick's worth of audio with room for jitter.
+	MaxVoiceFramesPerTick = 2 * int((TickDuration+VoiceFrameDuration-1)/VoiceFrameDuration)
+	// VoiceHangover is how long push-to-talk keeps a player talking after
+	// the key is released, so the end of a word isn't cut off.
+	VoiceHangover = 200 * time.Millisecond
+)
+
+var (
+	// ErrVoiceFrameSize is returned for empty frames or frames over
+	// MaxVoiceFrameSize.
+	ErrVoiceFrameSize = errors.New("voice frame size out of range")
+	// ErrNotSpeaking is returned for frames from players who haven't said
+	// they're talking.
+	ErrNotSpeaking = errors.New("player is not speaking")
+	// ErrVoiceRateLimited is returned for frames over a player's
+	// MaxVoiceFramesPerTick.
+	ErrVoiceRateLimited = errors.New("too many voice frames this tick")
+)
+
+// VoiceFrame is an encoded voice frame from a player. The relay doesn't
+// look inside frames; decoding them is up to the voice integration.
+type VoiceFrame struct {
+	From uint64 `json:"from"`
+	Seq  uint32 `json:"seq"`
+	Data []byte `json:"data"`
+}
+
+// VoiceMute is the data of a mute command.
+type VoiceMute struct {
+	PlayerID uint64 `json:"player_id"`
+	Muted    bool   `json:"muted"`
+}
+
+// VoiceUpdate is the voice traffic sent to a client with a delta: who's
+// talking and the frames since the last update. It's only sent when
+// someone starts or stops talking or there are frames.
+type VoiceUpdate struct {
+	Speaking []uint64     `json:"speaking"`
+	Frames   []VoiceFrame `json:"frames,omitempty"`
+}
+
+// EncodeVoiceFrame builds the data of a voice command.
+func EncodeVoiceFrame(seq uint32, frame []byte) ([]byte, error) {
+	if len(frame) == 0 || len(frame) > MaxVoiceFrameSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrVoiceFrameSize, len(frame))
+	}
+	data := make([]byte, 4+len(frame))
+	binary.BigEndian.PutUint32(data, seq)
+	copy(data[4:], frame)
+	return data, nil
+}
+
+// DecodeVoiceFrame reads the data of a voice command sent by a player.
+func DecodeVoiceFrame(from uint64, data []byte) (VoiceFrame, error) {
+	if len(data) <= 4 || len(data) > 4+MaxVoiceFrameSize {
+		return VoiceFrame{}, fmt.Errorf("%w: %d bytes", ErrVoiceFrameSize, len(data)-4)
+	}
+	return VoiceFrame{From: from, Seq: binary.BigEndian.Uint32(data), Data: data[4:]}, nil
+}
+
+// VoiceRelay is the server's side of voice: who's talking, who has muted
+// whom, and the frames waiting to go out. It is safe for concurrent use.
+type VoiceRelay struct {
+	mu       sync.Mutex
+	speaking map[uint64]bool
+	version  uint64                     // Bumped when someone starts or stops talking
+	muted    map[uint64]map[uint64]bool // By listener, the speakers they've muted
+	frames   []VoiceFrame               // Frames this tick
+	sent     map[uint64]int             // Frames each speaker sent this tick
+}
+
+// NewVoiceRelay creates a voice relay with nobody talking.
+func NewVoiceRelay() *VoiceRelay {
+	return &VoiceRelay{
+		speaking: make(map[uint64]bool),
+		muted:    make(map[uint64]map[uint64]bool),
+		sent:     make(map[uint64]int),
+	}
+}
+
+// SetSpeaking records a player starting or stopping talking.
+func (r *VoiceRelay) SetSpeaking(player uint64, speaking bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.speaking[player] == speaking {
+		return
+	}
+	if speaking {
+		r.speaking[player] = true
+	} else {
+		delete(r.speaking, player)
+	}
+	r.version++
+}
+
+// SetMuted records a listener muting, or unmuting, a speaker. Frames from
+// a muted speaker aren't sent to the listener.
+func (r *VoiceRelay) SetMuted(listener, speaker uint64, muted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if muted {
+		if r.muted[listener] == nil {
+			r.muted[listener] = make(map[uint64]bool)
+		}
+		r.muted[listener][speaker] = true
+	} else if m := r.muted[listener]; m != nil {
+		delete(m, speaker)
+		if len(m) == 0 {
+			delete(r.muted, listener)
+		}
+	}
+}
+
+// Submit queues a frame from a talking player for the next update.
+func (r *VoiceRelay) Submit(frame VoiceFrame) error {
+	if len(frame.Data) == 0 || len(frame.Data) > MaxVoiceFrameSize {
+		return fmt.Errorf("%w: %d bytes", ErrVoiceFrameSize, len(frame.Data))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.speaking[frame.From] {
+		return ErrNotSpeaking
+	}
+	if r.sent[frame.From] >= MaxVoiceFramesPerTick {
+		return ErrVoiceRateLimited
+	}
+	r.sent[frame.From]++
+	r.frames = append(r.frames, frame)
+	return nil
+}
+
+// Leave stops a player talking, as when they disconnect. Their mutes are
+// kept for if they come back.
+func (r *VoiceRelay) Leave(player uint64) {
+	r.SetSpeaking(player, false)
+}
+
+// Forget removes everything the relay knows of a player.
+func (r *VoiceRelay) Forget(player uint64) {
+	r.SetSpeaking(player, false)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.muted, player)
+	for listener, m := range r.muted {
+		delete(m, player)
+		if len(m) == 0 {
+			delete(r.muted, listener)
+		}
+	}
+}
+
+// voiceTick is one tick's voice traffic, taken from the relay to send out.
+type voiceTick struct {
+	version  uint64
+	speaking []uint64
+	frames   []VoiceFrame
+	muted    map[uint64]map[uint64]bool
+}
+
+// take returns the tick's traffic and starts the next tick.
+func (r *VoiceRelay) take() *voiceTick {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := &voiceTick{
+		version:  r.version,
+		speaking: make([]uint64, 0, len(r.speaking)),
+		frames:   r.frames,
+		muted:    make(map[uint64]map[uint64]bool, len(r.muted)),
+	}
+	for id := range r.speaking {
+		t.speaking = append(t.speaking, id)
+	}
+	sort.Slice(t.speaking, func(i, j int) bool { return t.speaking[i] < t.speaking[j] })
+	for listener, m := range r.muted {
+		t.muted[listener] = make(map[uint64]bool, len(m))
+		for speaker := range m {
+			t.muted[listener][speaker] = true
+		}
+	}
+	r.frames = nil
+	clear(r.sent)
+	return t
+}
+
+// update returns the listener's voice update, or nil if there's nothing
+// new since the version they last saw.
+func (t *voiceTick) update(listener, seenVersion uint64) *VoiceUpdate {
+	var frames []VoiceFrame
+	for _, f := range t.frames {
+		if f.From != listener && !t.muted[listener][f.From] {
+			frames = append(frames, f)
+		}
+	}
+	if len(frames) == 0 && seenVersion == t.version {
+		return nil
+	}
+	return &VoiceUpdate{Speaking: t.speaking, Frames: frames}
+}
+
+// handleVoiceCommand applies a voice command from a player. It reports
+// false for commands that aren't about voice.
+func (r *VoiceRelay) handleVoiceCommand(cmd *PlayerCommand) (bool, error) {
+	switch cmd.Type {
+	case CommandSpeaking:
+		var speaking bool
+		if err := json.Unmarshal(cmd.Data, &speaking); err != nil {
+			return true, fmt.Errorf("invalid speaking command: %w", err)
+		}
+		r.SetSpeaking(cmd.PlayerID, speaking)
+	case CommandVoice:
+		frame, err := DecodeVoiceFrame(cmd.PlayerID, cmd.Data)
+		if err != nil {
+			return true, err
+		}
+		return true, r.Submit(frame)
+	case CommandMute:
+		var mute VoiceMute
+		if err := json.Unmarshal(cmd.Data, &mute); err != nil {
+			return true, fmt.Errorf("invalid mute command: %w", err)
+		}
+		r.SetMuted(cmd.PlayerID, mute.PlayerID, mute.Muted)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// PushToTalk turns a talk key into a player starting and stopping
+// talking, keeping them talking for VoiceHangover after the key is let go.
+type PushToTalk struct {
+	speaking bool
+	released time.Time // When the key was let go, while in the hangover
+}
+
+// Update takes whether the talk key is held and reports whether the
+// player is talking, and whether that has just changed.
+func (p *PushToTalk) Update(held bool, now time.Time) (speaking, changed bool) {
+	was := p.speaking
+	switch {
+	case held:
+		p.speaking, p.released = true, time.Time{}
+	case p.speaking && p.released.IsZero():
+		p.released = now
+	case p.speaking && now.Sub(p.released) >= VoiceHangover:
+		p.speaking, p.released = false, time.Time{}
+	}
+	return p.speaking, p.speaking != was
+}
+
+// Speaking reports whether the player is talking.
+func (p *PushToTalk) Speaking() bool {
+	return p.speaking
+}
+
+// VoiceChannel is the client's side of voice: who's talking, who the
+// player has muted, and the commands that send their own voice. Frames
+// from players who aren't muted go to the frame handler. It is safe for
+// concurrent use.
+type VoiceChannel struct {
+	mu       sync.Mutex
+	speaking map[uint64]bool
+	muted    map[uint64]bool
+	seq      uint32
+	onFrame  func(VoiceFrame)
+}
+
+// NewVoiceChannel creates a voice channel with nobody talking or muted.
+func NewVoiceChannel() *VoiceChannel {
+	return &VoiceChannel{speaking: make(map[uint64]bool), muted: make(map[uint64]bool)}
+}
+
+// SetFrameHandler sets the function frames are played through. Until one
+// is set, frames are dropped.
+func (c *VoiceChannel) SetFrameHandler(fn func(VoiceFrame)) {
+	c.mu.Lock()
+	c.onFrame = fn
+	c.mu.Unlock()
+}
+
+// Apply takes a voice update from the server.
+func (c *VoiceChannel) Apply(u *VoiceUpdate) {
+	if u == nil {
+		return
+	}
+	c.mu.Lock()
+	c.speaking = make(map[uint64]bool, len(u.Speaking))
+	for _, id := range u.Speaking {
+		c.speaking[id] = true
+	}
+	onFrame := c.onFrame
+	var frames []VoiceFrame
+	for _, f := range u.Frames {
+		if !c.muted[f.From] {
+			frames = append(frames, f)
+		}
+	}
+	c.mu.Unlock()
+
+	if onFrame != nil {
+		for _, f := range frames {
+			onFrame(f)
+		}
+	}
+}
+
+// IsSpeaking reports whether the player is talking.
+func (c *VoiceChannel) IsSpeaking(player uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.speaking[player]
+}
+
+// Speaking returns the players talking, in order.
+func (c *VoiceChannel) Speaking() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sortedIDs(c.speaking)
+}
+
+// IsMuted reports whether the player has been muted.
+func (c *VoiceChannel) IsMuted(player uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.muted[player]
+}
+
+// Muted returns the muted players, in order.
+func (c *VoiceChannel) Muted() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sortedIDs(c.muted)
+}
+
+// SetMuted mutes or unmutes a player and returns the command that tells
+// the server, so it stops sending their frames.
+func (c *VoiceChannel) SetMuted(player uint64, muted bool) *PlayerCommand {
+	c.mu.Lock()
+	if muted {
+		c.muted[player] = true
+	} else {
+		delete(c.muted, player)
+	}
+	c.mu.Unlock()
+	data, _ := json.Marshal(VoiceMute{PlayerID: player, Muted: muted})
+	return &PlayerCommand{Type: CommandMute, Data: data}
+}
+
+// SpeakingCommand returns the command saying the player started or
+// stopped talking.
+func (c *VoiceChannel) SpeakingCommand(speaking bool) *PlayerCommand {
+	data, _ := json.Marshal(speaking)
+	return &PlayerCommand{Type: CommandSpeaking, Data: data}
+}
+
+// FrameCommand returns the command sending one of the player's encoded
+// voice frames.
+func (c *VoiceChannel) FrameCommand(frame []byte) (*PlayerCommand, error) {
+	c.mu.Lock()
+	seq := c.seq
+	c.seq++
+	c.mu.Unlock()
+	data, err := EncodeVoiceFrame(seq, frame)
+	if err != nil {
+		return nil, err
+	}
+	return &PlayerCommand{Type: CommandVoice, Data: data}, nil
+}
+
+// sortedIDs returns the set's players in order.
+func sortedIDs(set map[uint64]bool) []uint64 {
+	ids := make([]uint64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,237 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+func TestVoiceFrameEncoding(t *testing.T) {
+	data, err := EncodeVoiceFrame(7, []byte{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := DecodeVoiceFrame(4, data)
+	if err != nil || frame.From != 4 || frame.Seq != 7 || !bytes.Equal(frame.Data, []byte{1, 2, 3}) {
+		t.Errorf("DecodeVoiceFrame() = %+v, %v", frame, err)
+	}
+
+	if _, err := EncodeVoiceFrame(0, nil); !errors.Is(err, ErrVoiceFrameSize) {
+		t.Errorf("empty frame: err = %v", err)
+	}
+	if _, err := EncodeVoiceFrame(0, make([]byte, MaxVoiceFrameSize+1)); !errors.Is(err, ErrVoiceFrameSize) {
+		t.Errorf("oversized frame: err = %v", err)
+	}
+	if _, err := DecodeVoiceFrame(4, []byte{0, 0, 0, 1}); !errors.Is(err, ErrVoiceFrameSize) {
+		t.Errorf("header only: err = %v", err)
+	}
+}
+
+func TestVoiceRelay(t *testing.T) {
+	r := NewVoiceRelay()
+	frame := VoiceFrame{From: 1, Data: []byte{9}}
+	if err := r.Submit(frame); !errors.Is(err, ErrNotSpeaking) {
+		t.Errorf("frame before speaking: err = %v, want ErrNotSpeaking", err)
+	}
+
+	r.SetSpeaking(1, true)
+	for i := 0; i < MaxVoiceFramesPerTick; i++ {
+		if err := r.Submit(frame); err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+	}
+	if err := r.Submit(frame); !errors.Is(err, ErrVoiceRateLimited) {
+		t.Errorf("frame over the limit: err = %v, want ErrVoiceRateLimited", err)
+	}
+
+	r.SetMuted(3, 1, true)
+	tick := r.take()
+	if u := tick.update(2, 0); u == nil || len(u.Frames) != MaxVoiceFramesPerTick || !reflect.DeepEqual(u.Speaking, []uint64{1}) {
+		t.Errorf("listener's update = %+v", u)
+	}
+	if u := tick.update(1, 0); u == nil || len(u.Frames) != 0 {
+		t.Errorf("speaker should get the speaking list but not their own frames: %+v", u)
+	}
+	if u := tick.update(3, 0); u == nil || len(u.Frames) != 0 {
+		t.Errorf("muting listener got frames: %+v", u)
+	}
+
+	// The next tick starts with a fresh rate limit and nothing to send
+	if err := r.Submit(frame); err != nil {
+		t.Errorf("frame in the next tick: %v", err)
+	}
+	r.SetMuted(3, 1, false)
+	tick = r.take()
+	if u := tick.update(3, tick.version); u == nil || len(u.Frames) != 1 {
+		t.Errorf("unmuted listener's update = %+v", u)
+	}
+	if u := r.take().update(2, tick.version); u != nil {
+		t.Errorf("update with no news = %+v, want nil", u)
+	}
+
+	r.SetMuted(3, 1, true)
+	r.Leave(1)
+	tick = r.take()
+	if u := tick.update(2, 0); u == nil || len(u.Speaking) != 0 {
+		t.Errorf("after leaving: %+v", u)
+	}
+	if !tick.muted[3][1] {
+		t.Error("Leave() should keep mutes")
+	}
+	r.Forget(1)
+	if tick = r.take(); len(tick.muted) != 0 {
+		t.Errorf("Forget() left mutes %v", tick.muted)
+	}
+}
+
+func TestPushToTalk(t *testing.T) {
+	var p PushToTalk
+	now := time.Now()
+	step := func(held bool, at time.Duration, wantSpeaking, wantChanged bool) {
+		t.Helper()
+		speaking, changed := p.Update(held, now.Add(at))
+		if speaking != wantSpeaking || changed != wantChanged {
+			t.Errorf("Update(%v) at %v = %v, %v; want %v, %v", held, at, speaking, changed, wantSpeaking, wantChanged)
+		}
+	}
+	step(false, 0, false, false)
+	step(true, 0, true, true)
+	step(true, 100*time.Millisecond, true, false)
+	step(false, 200*time.Millisecond, true, false)
+	step(false, 200*time.Millisecond+VoiceHangover/2, true, false)
+	// Pressing again in the hangover carries on talking
+	step(true, 200*time.Millisecond+VoiceHangover*3/4, true, false)
+	step(false, time.Second, true, false)
+	step(false, time.Second+VoiceHangover, false, true)
+	if p.Speaking() {
+		t.Error("Speaking() after the hangover")
+	}
+}
+
+func TestVoiceChannel(t *testing.T) {
+	c := NewVoiceChannel()
+	var heard []uint64
+	c.Apply(&VoiceUpdate{Speaking: []uint64{2}, Frames: []VoiceFrame{{From: 2, Data: []byte{1}}}})
+	c.SetFrameHandler(func(f VoiceFrame) { heard = append(heard, f.From) })
+
+	cmd := c.SetMuted(3, true)
+	var mute VoiceMute
+	if err := json.Unmarshal(cmd.Data, &mute); cmd.Type != CommandMute || err != nil || mute != (VoiceMute{PlayerID: 3, Muted: true}) {
+		t.Errorf("SetMuted() command = %s %s", cmd.Type, cmd.Data)
+	}
+	c.Apply(&VoiceUpdate{
+		Speaking: []uint64{3, 2},
+		Frames:   []VoiceFrame{{From: 2, Data: []byte{1}}, {From: 3, Data: []byte{1}}},
+	})
+	if !reflect.DeepEqual(heard, []uint64{2}) {
+		t.Errorf("heard frames from %v, want only the unmuted player", heard)
+	}
+	if !c.IsSpeaking(3) || !reflect.DeepEqual(c.Speaking(), []uint64{2, 3}) {
+		t.Errorf("Speaking() = %v", c.Speaking())
+	}
+	if !c.IsMuted(3) || !reflect.DeepEqual(c.Muted(), []uint64{3}) {
+		t.Errorf("Muted() = %v", c.Muted())
+	}
+	c.SetMuted(3, false)
+	if c.IsMuted(3) {
+		t.Error("player still muted")
+	}
+
+	c.Apply(&VoiceUpdate{Speaking: []uint64{}})
+	if len(c.Speaking()) != 0 {
+		t.Errorf("Speaking() after everyone stopped = %v", c.Speaking())
+	}
+
+	if cmd := c.SpeakingCommand(true); cmd.Type != CommandSpeaking || string(cmd.Data) != "true" {
+		t.Errorf("SpeakingCommand() = %s %s", cmd.Type, cmd.Data)
+	}
+	for seq := uint32(0); seq < 2; seq++ {
+		cmd, err := c.FrameCommand([]byte{5})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if frame, _ := DecodeVoiceFrame(1, cmd.Data); frame.Seq != seq {
+			t.Errorf("frame sequence %d, want %d", frame.Seq, seq)
+		}
+	}
+}
+
+func TestGameServer_VoiceRelay(t *testing.T) {
+	server, err := NewGameServer(0, engine.NewWorld())
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	defer server.listener.Close()
+
+	speaker, speakerDeltas := pipeClient(t, server)
+	defer speaker.Close()
+	listener, listenerDeltas := pipeClient(t, server)
+	defer listener.Close()
+	server.tick()
+	<-speakerDeltas
+	<-listenerDeltas
+
+	voice := NewVoiceChannel()
+	send := func(conn interface{ Write([]byte) (int, error) }, cmd *PlayerCommand) {
+		t.Helper()
+		if err := json.NewEncoder(conn).Encode(cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Commands are handled as they arrive, so wait for the relay to see them
+	waitFor := func(check func(*VoiceRelay) bool) {
+		t.Helper()
+		for i := 0; i < 100; i++ {
+			server.voice.mu.Lock()
+			ok := check(server.voice)
+			server.voice.mu.Unlock()
+			if ok {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("relay never saw the voice command")
+	}
+
+	send(speaker, voice.SpeakingCommand(true))
+	frame, _ := voice.FrameCommand([]byte("opus"))
+	send(speaker, frame)
+	waitFor(func(r *VoiceRelay) bool { return len(r.frames) == 1 })
+
+	delta := nextDelta(t, server, listenerDeltas)
+	if delta.Voice == nil || len(delta.Voice.Frames) != 1 || string(delta.Voice.Frames[0].Data) != "opus" {
+		t.Fatalf("listener's voice update = %+v", delta.Voice)
+	}
+	if got := delta.Voice.Speaking; len(got) != 1 || got[0] != delta.Voice.Frames[0].From {
+		t.Errorf("speaking = %v, want the speaker", got)
+	}
+	if delta = <-speakerDeltas; delta.Voice == nil || len(delta.Voice.Frames) != 0 {
+		t.Errorf("speaker's own voice update = %+v", delta.Voice)
+	}
+
+	// Nothing new, nothing sent
+	if delta = nextDelta(t, server, listenerDeltas); delta.Voice != nil {
+		t.Errorf("voice update with no news = %+v", delta.Voice)
+	}
+	<-speakerDeltas
+
+	send(listener, voice.SetMuted(0, true))
+	waitFor(func(r *VoiceRelay) bool { return r.muted[1][0] })
+	send(speaker, frame)
+	waitFor(func(r *VoiceRelay) bool { return len(r.frames) == 1 })
+	if delta = nextDelta(t, server, listenerDeltas); delta.Voice != nil {
+		t.Errorf("muted speaker's frames reached the listener: %+v", delta.Voice)
+	}
+	<-speakerDeltas
+
+	speaker.Close()
+	waitFor(func(r *VoiceRelay) bool { return len(r.speaking) == 0 })
+	if delta = nextDelta(t, server, listenerDeltas); delta.Voice == nil || len(delta.Voice.Speaking) != 0 {
+		t.Errorf("after the speaker left: %+v", delta.Voice)
+	}
+}
@@ -0,0 +1,105 @@
+package particle
+
+// Vertex is one vertex of particle or trail geometry in screen space. U and
+// V are texture coordinates in [0, 1]; colors are straight alpha in [0, 1].
+type Vertex struct {
+	X, Y       float32
+	U, V       float32
+	R, G, B, A float32
+}
+
+// Projection maps world positions to the screen for a top-down view
+// centred on the camera.
+type Projection struct {
+	CameraX, CameraY float64 // World position at the screen center
+	Scale            float64 // Screen pixels per world unit
+	CenterX, CenterY float32 // Screen center in pixels
+}
+
+// Project returns the screen position of world position (x, y).
+func (pr Projection) Project(x, y float64) (float32, float32) {
+	return pr.CenterX + float32((x-pr.CameraX)*pr.Scale), pr.CenterY + float32((y-pr.CameraY)*pr.Scale)
+}
+
+// MaxBatchQuads is the number of quads in one chunk of a batch, the most
+// that uint16 indices within a single draw call allow.
+const MaxBatchQuads = 65535 / 6
+
+// batchQuadIndices is the index pattern shared by every chunk.
+var batchQuadIndices = func() []uint16 {
+	idx := make([]uint16, 0, MaxBatchQuads*6)
+	for q := 0; q < MaxBatchQuads; q++ {
+		v := uint16(q * 4)
+		idx = append(idx, v, v+1, v+2, v+1, v+3, v+2)
+	}
+	return idx
+}()
+
+// ParticleBatch turns particles into textured screen-space quads in a
+// reusable vertex buffer, so thousands of particles can be drawn with a few
+// draw calls instead of one vector call each.
+type ParticleBatch struct {
+	vertices []Vertex
+
+	// Screen bounds for culling, with a margin for particle size.
+	Width, Height float32
+	Margin        float32
+}
+
+// NewParticleBatch creates a batch for a screen of the given size.
+func NewParticleBatch(width, height float32) *ParticleBatch {
+	return &ParticleBatch{Width: width, Height: height, Margin: 20}
+}
+
+// Build replaces the batch contents with one quad per on-screen particle,
+// sized by the particle's Size in pixels and faded by its remaining life.
+// It returns the number of particles batched.
+func (b *ParticleBatch) Build(particles []Particle, proj Projection) int {
+	b.vertices = b.vertices[:0]
+	for i := range particles {
+		b.add(&particles[i], proj)
+	}
+	return len(b.vertices) / 4
+}
+
+// add appends a particle's quad if it is alive and on screen.
+func (b *ParticleBatch) add(p *Particle, proj Projection) {
+	if !p.Active || p.Life <= 0 {
+		return
+	}
+	x, y := proj.Project(p.X, p.Y)
+	if x < -b.Margin || x >= b.Width+b.Margin || y < -b.Margin || y >= b.Height+b.Margin {
+		return
+	}
+
+	s := float32(p.Size)
+	alpha := float32(p.A) / 255 * float32(p.Life/p.MaxLife)
+	r, g, bl := float32(p.R)/255, float32(p.G)/255, float32(p.B)/255
+	b.vertices = append(b.vertices,
+		Vertex{X: x - s, Y: y - s, U: 0, V: 0, R: r, G: g, B: bl, A: alpha},
+		Vertex{X: x + s, Y: y - s, U: 1, V: 0, R: r, G: g, B: bl, A: alpha},
+		Vertex{X: x - s, Y: y + s, U: 0, V: 1, R: r, G: g, B: bl, A: alpha},
+		Vertex{X: x + s, Y: y + s, U: 1, V: 1, R: r, G: g, B: bl, A: alpha},
+	)
+}
+
+// Len returns the number of batched particles.
+func (b *ParticleBatch) Len() int {
+	return len(b.vertices) / 4
+}
+
+// Chunks returns how many draw calls the batch needs.
+func (b *ParticleBatch) Chunks() int {
+	return (b.Len() + MaxBatchQuads - 1) / MaxBatchQuads
+}
+
+// Chunk returns the vertices and indices of draw call i. Indices are
+// relative to the chunk's vertices.
+func (b *ParticleBatch) Chunk(i int) ([]Vertex, []uint16) {
+	start := i * MaxBatchQuads
+	end := min(start+MaxBatchQuads, b.Len())
+	if start >= end {
+		return nil, nil
+	}
+	return b.vertices[start*4 : end*4], batchQuadIndices[:(end-start)*6]
+}
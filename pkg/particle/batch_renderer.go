@@ -0,0 +1,65 @@
+package particle
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// batchSpriteSize is the pixel size of the disc texture batched particles
+// are drawn with.
+const batchSpriteSize = 16
+
+// BatchRenderer draws a ParticleBatch with one DrawTriangles call per chunk,
+// reusing its vertex buffer between frames.
+type BatchRenderer struct {
+	sprite   *ebiten.Image
+	vertices []ebiten.Vertex
+}
+
+// NewBatchRenderer creates a batch renderer.
+func NewBatchRenderer() *BatchRenderer {
+	return &BatchRenderer{sprite: newDiscSprite(batchSpriteSize)}
+}
+
+// newDiscSprite creates a white disc with a one pixel soft edge.
+func newDiscSprite(size int) *ebiten.Image {
+	img := ebiten.NewImage(size, size)
+	radius := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx := float64(x) + 0.5 - radius
+			dy := float64(y) + 0.5 - radius
+			coverage := clamp01(radius - math.Sqrt(dx*dx+dy*dy))
+			if coverage > 0 {
+				a := uint8(coverage * 255)
+				img.Set(x, y, color.RGBA{R: a, G: a, B: a, A: a})
+			}
+		}
+	}
+	return img
+}
+
+// Draw renders the batch onto screen.
+func (br *BatchRenderer) Draw(screen *ebiten.Image, b *ParticleBatch) {
+	size := float32(batchSpriteSize)
+	opts := &ebiten.DrawTrianglesOptions{Filter: ebiten.FilterLinear}
+	for c := 0; c < b.Chunks(); c++ {
+		verts, indices := b.Chunk(c)
+		br.vertices = br.vertices[:0]
+		for _, v := range verts {
+			br.vertices = append(br.vertices, ebiten.Vertex{
+				DstX:   v.X,
+				DstY:   v.Y,
+				SrcX:   v.U * size,
+				SrcY:   v.V * size,
+				ColorR: v.R,
+				ColorG: v.G,
+				ColorB: v.B,
+				ColorA: v.A,
+			})
+		}
+		screen.DrawTriangles(br.vertices, indices, br.sprite, opts)
+	}
+}
@@ -0,0 +1,120 @@
+package particle
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+)
+
+var testProjection = Projection{CameraX: 10, CameraY: 10, Scale: 10, CenterX: 160, CenterY: 100}
+
+func TestProjectionProject(t *testing.T) {
+	tests := []struct {
+		x, y         float64
+		wantX, wantY float32
+	}{
+		{10, 10, 160, 100},
+		{11, 10, 170, 100},
+		{10, 8, 160, 80},
+	}
+	for _, tt := range tests {
+		if x, y := testProjection.Project(tt.x, tt.y); x != tt.wantX || y != tt.wantY {
+			t.Errorf("Project(%v, %v) = (%v, %v), want (%v, %v)", tt.x, tt.y, x, y, tt.wantX, tt.wantY)
+		}
+	}
+}
+
+func TestParticleBatchBuild(t *testing.T) {
+	particles := []Particle{
+		{X: 10, Y: 10, Size: 2, Life: 1, MaxLife: 2, R: 255, A: 255, Active: true},
+		{X: 10, Y: 10, Size: 2, Life: 1, MaxLife: 1, A: 255, Active: false}, // Inactive
+		{X: 100, Y: 10, Size: 2, Life: 1, MaxLife: 1, A: 255, Active: true}, // Off screen
+		{X: 10, Y: 10, Size: 2, Life: 0, MaxLife: 1, A: 255, Active: true},  // Dead
+	}
+	b := NewParticleBatch(320, 200)
+	if n := b.Build(particles, testProjection); n != 1 {
+		t.Fatalf("Build() = %d, want 1", n)
+	}
+	if b.Chunks() != 1 {
+		t.Fatalf("Chunks() = %d, want 1", b.Chunks())
+	}
+
+	verts, indices := b.Chunk(0)
+	if len(verts) != 4 || len(indices) != 6 {
+		t.Fatalf("chunk has %d vertices and %d indices, want 4 and 6", len(verts), len(indices))
+	}
+	if verts[0].X != 158 || verts[0].Y != 98 || verts[3].X != 162 || verts[3].Y != 102 {
+		t.Errorf("quad corners = (%v, %v)-(%v, %v), want (158, 98)-(162, 102)", verts[0].X, verts[0].Y, verts[3].X, verts[3].Y)
+	}
+	if verts[0].R != 1 || verts[0].A != 0.5 {
+		t.Errorf("color R %v A %v, want 1 and 0.5 at half life", verts[0].R, verts[0].A)
+	}
+}
+
+func TestParticleBatchChunks(t *testing.T) {
+	particles := make([]Particle, MaxBatchQuads+10)
+	for i := range particles {
+		particles[i] = Particle{X: 10, Y: 10, Size: 1, Life: 1, MaxLife: 1, A: 255, Active: true}
+	}
+	b := NewParticleBatch(320, 200)
+	b.Build(particles, testProjection)
+
+	if b.Chunks() != 2 {
+		t.Fatalf("Chunks() = %d, want 2", b.Chunks())
+	}
+	verts, indices := b.Chunk(1)
+	if len(verts) != 40 || len(indices) != 60 {
+		t.Errorf("last chunk has %d vertices and %d indices, want 40 and 60", len(verts), len(indices))
+	}
+	for _, i := range indices {
+		if int(i) >= len(verts) {
+			t.Fatalf("index %d out of range of %d chunk vertices", i, len(verts))
+		}
+	}
+	if verts, _ := b.Chunk(2); verts != nil {
+		t.Error("Chunk() past the end should be empty")
+	}
+}
+
+func TestParticleBatchReusesBuffer(t *testing.T) {
+	ps := NewParticleSystem(2048, 1)
+	ps.SpawnBurst(10, 10, 0, 2048, 5, 1, 2, 1, color.RGBA{A: 255})
+	b := NewParticleBatch(320, 200)
+	visible := ps.AppendVisibleParticles(nil, 10, 10, 1, 0, 400)
+	b.Build(visible, testProjection)
+
+	allocs := testing.AllocsPerRun(50, func() {
+		visible = ps.AppendVisibleParticles(visible[:0], 10, 10, 1, 0, 400)
+		b.Build(visible, testProjection)
+	})
+	if allocs != 0 {
+		t.Errorf("batching allocated %v times per frame, want 0", allocs)
+	}
+}
+
+func BenchmarkParticleBatchBuild(b *testing.B) {
+	for _, n := range []int{500, 2000, 10000} {
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			ps := NewParticleSystem(n, 1)
+			ps.SpawnBurst(10, 10, 0, n, 5, 1, 2, 1, color.RGBA{R: 200, A: 255})
+			batch := NewParticleBatch(320, 200)
+			visible := ps.AppendVisibleParticles(nil, 10, 10, 1, 0, 400)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				visible = ps.AppendVisibleParticles(visible[:0], 10, 10, 1, 0, 400)
+				batch.Build(visible, testProjection)
+			}
+		})
+	}
+}
+
+func BenchmarkGetVisibleParticles(b *testing.B) {
+	ps := NewParticleSystem(2000, 1)
+	ps.SpawnBurst(10, 10, 0, 2000, 5, 1, 2, 1, color.RGBA{A: 255})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = ps.GetVisibleParticles(10, 10, 1, 0, 400)
+	}
+}
@@ -0,0 +1,143 @@
+package particle
+
+import (
+	"fmt"
+	"math"
+)
+
+// CollisionMode selects how a particle reacts when it hits the map.
+type CollisionMode uint8
+
+const (
+	CollideNone   CollisionMode = iota // CollideNone passes through walls, floor and ceiling.
+	CollideBounce                      // CollideBounce reflects off surfaces, losing speed.
+	CollideStick                       // CollideStick stops at the surface until its life ends.
+	CollideDie                         // CollideDie removes the particle on contact.
+)
+
+// String returns the collision mode name.
+func (m CollisionMode) String() string {
+	switch m {
+	case CollideNone:
+		return "none"
+	case CollideBounce:
+		return "bounce"
+	case CollideStick:
+		return "stick"
+	case CollideDie:
+		return "die"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the mode by name, for data-driven presets.
+func (m CollisionMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes a mode name.
+func (m *CollisionMode) UnmarshalText(text []byte) error {
+	for mode := CollideNone; mode <= CollideDie; mode++ {
+		if mode.String() == string(text) {
+			*m = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown collision mode %q", text)
+}
+
+// Collision configures how a particle collides with the map.
+type Collision struct {
+	Mode        CollisionMode `json:"mode"`
+	Restitution float64       `json:"restitution"` // Fraction of speed kept per bounce [0.0-1.0]
+}
+
+// settleSpeed is the speed below which a bouncing particle comes to rest.
+const settleSpeed = 0.05
+
+// SetCollisionMap sets the test for solid tiles that colliding particles
+// cannot enter. nil disables wall collision; the floor and ceiling still
+// apply.
+func (ps *ParticleSystem) SetCollisionMap(solid func(x, y int) bool) {
+	ps.solid = solid
+}
+
+// SetCeiling sets the height of the ceiling that colliding particles cannot
+// rise through, for interior maps. 0 means open sky.
+func (ps *ParticleSystem) SetCeiling(height float64) {
+	ps.ceiling = math.Max(height, 0)
+}
+
+// isSolid reports whether the tile holding a world position is solid.
+func (ps *ParticleSystem) isSolid(x, y float64) bool {
+	return ps.solid != nil && ps.solid(int(math.Floor(x)), int(math.Floor(y)))
+}
+
+// collide resolves a particle's contact with walls, floor and ceiling after
+// it moved from (prevX, prevY). It returns false when the particle is
+// destroyed.
+func (ps *ParticleSystem) collide(p *Particle, prevX, prevY float64) bool {
+	var hitX, hitY, hitZ bool
+	if ps.isSolid(p.X, p.Y) {
+		// The axes whose movement entered the wall are the ones that hit.
+		hitX = !ps.isSolid(prevX, p.Y)
+		hitY = !ps.isSolid(p.X, prevY)
+		if !hitX && !hitY {
+			hitX, hitY = true, true // Corner or spawned inside a wall
+		}
+	}
+	floorHit := p.Z < 0 && p.VZ < 0
+	ceilingHit := ps.ceiling > 0 && p.Z > ps.ceiling && p.VZ > 0
+	hitZ = floorHit || ceilingHit
+	if !hitX && !hitY && !hitZ {
+		return true
+	}
+
+	if p.Collision.Mode == CollideDie {
+		return false
+	}
+
+	// Move back out of the surface on the axes that hit.
+	if hitX {
+		p.X = prevX
+	}
+	if hitY {
+		p.Y = prevY
+	}
+	switch {
+	case floorHit:
+		p.Z = 0
+	case ceilingHit:
+		p.Z = ps.ceiling
+	}
+
+	if p.Collision.Mode == CollideStick {
+		p.VX, p.VY, p.VZ = 0, 0, 0
+		return true
+	}
+
+	// Bounce: reflect the velocity across the surfaces hit and lose speed.
+	if hitX {
+		p.VX = -p.VX
+	}
+	if hitY {
+		p.VY = -p.VY
+	}
+	if hitZ {
+		p.VZ = -p.VZ
+	}
+	e := clamp01(p.Collision.Restitution)
+	p.VX *= e
+	p.VY *= e
+	p.VZ *= e
+	if p.VX*p.VX+p.VY*p.VY+p.VZ*p.VZ < settleSpeed*settleSpeed {
+		p.VX, p.VY, p.VZ = 0, 0, 0
+	}
+	return true
+}
+
+// clamp01 clamps v to [0, 1].
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,142 @@
+package particle
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// wallAtX returns a collision map with a solid column at x = wall.
+func wallAtX(wall int) func(x, y int) bool {
+	return func(x, y int) bool { return x == wall }
+}
+
+func TestCollisionModeString(t *testing.T) {
+	tests := []struct {
+		mode CollisionMode
+		want string
+	}{
+		{CollideNone, "none"},
+		{CollideBounce, "bounce"},
+		{CollideStick, "stick"},
+		{CollideDie, "die"},
+		{CollisionMode(99), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.String(); got != tt.want {
+			t.Errorf("CollisionMode(%d).String() = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestParticleWallCollision(t *testing.T) {
+	tests := []struct {
+		name       string
+		collision  Collision
+		wantActive bool
+		wantMaxX   float64 // Particle must stay left of this
+		wantVX     float64
+	}{
+		{"none passes through", Collision{}, true, 100, 10},
+		{"die", Collision{Mode: CollideDie}, false, 5, 0},
+		{"stick", Collision{Mode: CollideStick}, true, 5, 0},
+		{"bounce", Collision{Mode: CollideBounce, Restitution: 0.5}, true, 5, -5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := NewParticleSystem(4, 1)
+			ps.SetCollisionMap(wallAtX(5))
+			p := ps.Spawn(4.5, 1.5, 1, 10, 0, 0, 5, 1, color.RGBA{A: 255})
+			p.Collision = tt.collision
+
+			ps.Update(0.1)
+
+			if p.Active != tt.wantActive {
+				t.Fatalf("Active = %v, want %v", p.Active, tt.wantActive)
+			}
+			if !p.Active {
+				return
+			}
+			if p.X >= tt.wantMaxX {
+				t.Errorf("X = %f, want < %f", p.X, tt.wantMaxX)
+			}
+			if math.Abs(p.VX-tt.wantVX) > 1e-9 {
+				t.Errorf("VX = %f, want %f", p.VX, tt.wantVX)
+			}
+		})
+	}
+}
+
+func TestParticleBounceSlidesAlongWall(t *testing.T) {
+	ps := NewParticleSystem(4, 1)
+	ps.SetCollisionMap(wallAtX(5))
+	p := ps.Spawn(4.5, 1.5, 1, 10, 4, 0, 5, 1, color.RGBA{A: 255})
+	p.Collision = Collision{Mode: CollideBounce, Restitution: 1}
+
+	ps.Update(0.1)
+
+	// Only the X axis hit; Y movement is kept.
+	if p.X != 4.5 || math.Abs(p.Y-1.9) > 1e-9 {
+		t.Errorf("position = (%f, %f), want (4.5, 1.9)", p.X, p.Y)
+	}
+	if p.VX != -10 || p.VY != 4 {
+		t.Errorf("velocity = (%f, %f), want (-10, 4)", p.VX, p.VY)
+	}
+}
+
+func TestParticleFloorAndCeiling(t *testing.T) {
+	ps := NewParticleSystem(4, 1)
+	ps.SetCeiling(3)
+
+	falling := ps.Spawn(1, 1, 0.5, 0, 0, -10, 5, 1, color.RGBA{A: 255})
+	falling.Collision = Collision{Mode: CollideBounce, Restitution: 0.5}
+	rising := ps.Spawn(2, 2, 2.5, 0, 0, 10, 5, 1, color.RGBA{A: 255})
+	rising.Collision = Collision{Mode: CollideDie}
+	free := ps.Spawn(3, 3, 2.5, 0, 0, 10, 5, 1, color.RGBA{A: 255})
+
+	ps.Update(0.1)
+
+	if falling.Z != 0 || falling.VZ != 5 {
+		t.Errorf("floor bounce: Z = %f, VZ = %f; want 0, 5", falling.Z, falling.VZ)
+	}
+	if rising.Active {
+		t.Error("particle rising through the ceiling should die")
+	}
+	if !free.Active || free.Z <= 3 {
+		t.Errorf("non-colliding particle stopped at the ceiling: Z = %f", free.Z)
+	}
+}
+
+func TestParticleBounceSettles(t *testing.T) {
+	ps := NewParticleSystem(4, 1)
+	ps.SetCollisionMap(func(x, y int) bool { return x < 0 || x > 1 })
+	p := ps.Spawn(0.5, 0.5, 0, 3, 0, 0, 100, 1, color.RGBA{A: 255})
+	p.Collision = Collision{Mode: CollideBounce, Restitution: 0.5}
+
+	for i := 0; i < 2000; i++ {
+		ps.Update(0.05)
+	}
+	if p.VX != 0 {
+		t.Errorf("VX = %f after many bounces, want settled at 0", p.VX)
+	}
+	if p.X < 0 || p.X >= 2 {
+		t.Errorf("X = %f escaped the room", p.X)
+	}
+}
+
+func TestSpawnCollidingBurst(t *testing.T) {
+	ps := NewParticleSystem(16, 1)
+	ps.SpawnCollidingBurst(1, 1, 0, 5, 2, 0, 1, 1, color.RGBA{A: 255}, Collision{Mode: CollideStick})
+	for _, p := range ps.GetActiveParticles() {
+		if p.Collision.Mode != CollideStick {
+			t.Errorf("burst particle collision = %v, want stick", p.Collision.Mode)
+		}
+	}
+
+	// Reused pool slots start without collision.
+	ps.Clear()
+	p := ps.Spawn(0, 0, 0, 0, 0, 0, 1, 1, color.RGBA{})
+	if p.Collision.Mode != CollideNone {
+		t.Errorf("Spawn() collision = %v, want none", p.Collision.Mode)
+	}
+}
@@ -0,0 +1,123 @@
+package particle
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// ErrSnapshotMismatch is returned when restoring a snapshot taken from a
+// system with a different pool size.
+var ErrSnapshotMismatch = errors.New("particle snapshot does not match pool size")
+
+// frameSource is a splitmix64 random source whose whole state is one word,
+// so it can be reseeded per frame and captured in snapshots cheaply.
+type frameSource struct {
+	state uint64
+}
+
+// Uint64 returns the next value of the sequence.
+func (s *frameSource) Uint64() uint64 {
+	s.state += 0x9E3779B97F4A7C15
+	z := s.state
+	z = (z ^ z>>30) * 0xBF58476D1CE4E5B9
+	z = (z ^ z>>27) * 0x94D049BB133111EB
+	return z ^ z>>31
+}
+
+// Int63 returns a non-negative value of the sequence.
+func (s *frameSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Seed resets the sequence.
+func (s *frameSource) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// frameSeed mixes a system seed and frame number into the seed of the
+// frame's random sequence.
+func frameSeed(seed int64, frame uint64) int64 {
+	s := frameSource{state: uint64(seed) ^ frame*0xD1B54A32D192ED03}
+	return int64(s.Uint64())
+}
+
+// newFrameRand creates the random generator of a particle system.
+func newFrameRand(seed int64) (*rand.Rand, *frameSource) {
+	src := &frameSource{}
+	src.Seed(frameSeed(seed, 0))
+	return rand.New(src), src
+}
+
+// Seed returns the seed the system's random sequences derive from.
+func (ps *ParticleSystem) Seed() int64 {
+	return ps.seed
+}
+
+// Frame returns the number of the current frame.
+func (ps *ParticleSystem) Frame() uint64 {
+	return ps.frame
+}
+
+// Reseed restarts the system's random sequences from seed at frame 0, e.g.
+// with the level seed when a level is generated, so every client and replay
+// of the level spawns the same effects.
+func (ps *ParticleSystem) Reseed(seed int64) {
+	ps.seed = seed
+	ps.SetFrame(0)
+}
+
+// SetFrame moves the system to frame, e.g. to follow the server tick. The
+// random values drawn by spawns during a frame depend only on the seed, the
+// frame and the order of the spawns, never on earlier frames.
+func (ps *ParticleSystem) SetFrame(frame uint64) {
+	ps.frame = frame
+	ps.src.Seed(frameSeed(ps.seed, frame))
+}
+
+// Snapshot is the complete state of a particle system at one moment, for
+// replays to rewind and reproduce effects exactly. The vector field and map
+// geometry are inputs, not state, and are not captured.
+type Snapshot struct {
+	particles       []Particle
+	activeIndices   []int
+	nextIndex       int
+	seed            int64
+	frame           uint64
+	rngState        uint64
+	attractors      []attractorSlot
+	nextAttractorID int
+}
+
+// Snapshot captures the system's state. Pass a previous snapshot to reuse
+// its storage, or nil to allocate a new one.
+func (ps *ParticleSystem) Snapshot(dst *Snapshot) *Snapshot {
+	if dst == nil {
+		dst = &Snapshot{}
+	}
+	dst.particles = append(dst.particles[:0], ps.particles...)
+	dst.activeIndices = append(dst.activeIndices[:0], ps.activeIndices...)
+	dst.attractors = append(dst.attractors[:0], ps.attractors...)
+	dst.nextIndex = ps.nextIndex
+	dst.seed = ps.seed
+	dst.frame = ps.frame
+	dst.rngState = ps.src.state
+	dst.nextAttractorID = ps.nextAttractorID
+	return dst
+}
+
+// Restore returns the system to the state captured in s. The snapshot stays
+// valid and can be restored again.
+func (ps *ParticleSystem) Restore(s *Snapshot) error {
+	if s == nil || len(s.particles) != len(ps.particles) {
+		return ErrSnapshotMismatch
+	}
+	copy(ps.particles, s.particles)
+	ps.activeIndices = append(ps.activeIndices[:0], s.activeIndices...)
+	ps.attractors = append(ps.attractors[:0], s.attractors...)
+	ps.nextIndex = s.nextIndex
+	ps.seed = s.seed
+	ps.frame = s.frame
+	ps.src.state = s.rngState
+	ps.nextAttractorID = s.nextAttractorID
+	return nil
+}
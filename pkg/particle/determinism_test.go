@@ -0,0 +1,117 @@
+package particle
+
+import (
+	"errors"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// runFrames spawns a burst and updates the system once per frame.
+func runFrames(ps *ParticleSystem, frames int) {
+	for i := 0; i < frames; i++ {
+		ps.SpawnBurst(5, 5, 0, 4, 3, 1, 2, 1, color.RGBA{R: 200, A: 255})
+		ps.Update(1.0 / 60)
+	}
+}
+
+func TestDeterministicAcrossSystems(t *testing.T) {
+	a := NewParticleSystem(256, 42)
+	b := NewParticleSystem(256, 42)
+	runFrames(a, 10)
+	runFrames(b, 10)
+
+	if !reflect.DeepEqual(a.GetActiveParticles(), b.GetActiveParticles()) {
+		t.Error("systems with the same seed diverged")
+	}
+	if a.Frame() != 10 {
+		t.Errorf("Frame() = %d, want 10", a.Frame())
+	}
+}
+
+func TestSpawnDependsOnlyOnSeedAndFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		history int
+	}{
+		{"fresh", 0},
+		{"after other frames", 7},
+	}
+	var want []Particle
+	for _, tt := range tests {
+		ps := NewParticleSystem(256, 7)
+		runFrames(ps, tt.history)
+		ps.Clear()
+		ps.SetFrame(100)
+		ps.SpawnBurst(0, 0, 0, 3, 5, 1, 1, 1, color.RGBA{A: 255})
+
+		got := ps.GetActiveParticles()
+		if want == nil {
+			want = got
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: frame 100 spawned differently", tt.name)
+		}
+	}
+}
+
+func TestReseedRestartsSequence(t *testing.T) {
+	ps := NewParticleSystem(64, 1)
+	runFrames(ps, 3)
+	ps.Clear()
+	ps.Reseed(9)
+
+	fresh := NewParticleSystem(64, 9)
+	ps.SpawnBurst(0, 0, 0, 3, 5, 1, 1, 1, color.RGBA{A: 255})
+	fresh.SpawnBurst(0, 0, 0, 3, 5, 1, 1, 1, color.RGBA{A: 255})
+	if ps.Seed() != 9 || ps.Frame() != 0 {
+		t.Errorf("Seed(), Frame() = %d, %d, want 9, 0", ps.Seed(), ps.Frame())
+	}
+	if !reflect.DeepEqual(ps.GetActiveParticles(), fresh.GetActiveParticles()) {
+		t.Error("reseeded system differs from a new system with the same seed")
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	ps := NewParticleSystem(128, 3)
+	ps.AddAttractor(Attractor{X: 5, Y: 5, Strength: 2, Radius: 3})
+	runFrames(ps, 5)
+	// Leave the frame's sequence part-way through.
+	ps.SpawnBurst(1, 1, 0, 2, 1, 1, 1, 1, color.RGBA{A: 255})
+
+	snap := ps.Snapshot(nil)
+	runFrames(ps, 20)
+	want := ps.GetActiveParticles()
+
+	if err := ps.Restore(snap); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if ps.Frame() != 5 || ps.AttractorCount() != 1 {
+		t.Errorf("restored frame %d with %d attractors, want 5 and 1", ps.Frame(), ps.AttractorCount())
+	}
+	runFrames(ps, 20)
+	if !reflect.DeepEqual(ps.GetActiveParticles(), want) {
+		t.Error("replay from snapshot diverged")
+	}
+
+	if again := ps.Snapshot(snap); again != snap {
+		t.Error("Snapshot() did not reuse the given snapshot")
+	}
+}
+
+func TestRestoreMismatch(t *testing.T) {
+	snap := NewParticleSystem(16, 1).Snapshot(nil)
+	tests := []struct {
+		name string
+		snap *Snapshot
+	}{
+		{"nil", nil},
+		{"pool size", snap},
+	}
+	for _, tt := range tests {
+		if err := NewParticleSystem(32, 1).Restore(tt.snap); !errors.Is(err, ErrSnapshotMismatch) {
+			t.Errorf("%s: Restore() error = %v, want ErrSnapshotMismatch", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package particle
+
+import "math"
+
+// ForceMask selects which external forces move a particle. Particles ignore
+// forces unless their emitter opts in.
+type ForceMask uint8
+
+const (
+	ForceAttractors ForceMask = 1 << iota // ForceAttractors applies point attractors and repulsors.
+	ForceField                            // ForceField applies the vector-field grid.
+
+	ForceAll = ForceAttractors | ForceField // ForceAll applies every force.
+)
+
+// Attractor pulls particles toward a point, or pushes them away when
+// Strength is negative. Swirl adds a tangential push for vortexes.
+// Both fall off linearly to zero at Radius.
+type Attractor struct {
+	X, Y     float64
+	Strength float64 // Acceleration at the center, tiles per second squared
+	Swirl    float64 // Counter-clockwise tangential acceleration at the center
+	Radius   float64 // Range of influence in tiles
+	Life     float64 // Seconds until removal; 0 means until removed
+}
+
+// attractorSlot is an attractor with its stable ID.
+type attractorSlot struct {
+	Attractor
+	id int
+}
+
+// AddAttractor adds a point force and returns its ID.
+func (ps *ParticleSystem) AddAttractor(a Attractor) int {
+	ps.nextAttractorID++
+	ps.attractors = append(ps.attractors, attractorSlot{Attractor: a, id: ps.nextAttractorID})
+	return ps.nextAttractorID
+}
+
+// MoveAttractor moves an attractor, e.g. to follow a projectile. It returns
+// false when the attractor no longer exists.
+func (ps *ParticleSystem) MoveAttractor(id int, x, y float64) bool {
+	for i := range ps.attractors {
+		if ps.attractors[i].id == id {
+			ps.attractors[i].X, ps.attractors[i].Y = x, y
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveAttractor removes an attractor.
+func (ps *ParticleSystem) RemoveAttractor(id int) {
+	for i := range ps.attractors {
+		if ps.attractors[i].id == id {
+			ps.attractors = append(ps.attractors[:i], ps.attractors[i+1:]...)
+			return
+		}
+	}
+}
+
+// ClearAttractors removes all attractors, e.g. on level change.
+func (ps *ParticleSystem) ClearAttractors() {
+	ps.attractors = ps.attractors[:0]
+}
+
+// AttractorCount returns the number of live attractors.
+func (ps *ParticleSystem) AttractorCount() int {
+	return len(ps.attractors)
+}
+
+// SetVectorField sets the vector field applied to particles that opt into
+// ForceField. nil removes it.
+func (ps *ParticleSystem) SetVectorField(f *VectorField) {
+	ps.field = f
+}
+
+// ageAttractors expires timed attractors.
+func (ps *ParticleSystem) ageAttractors(deltaTime float64) {
+	n := 0
+	for _, a := range ps.attractors {
+		if a.Life > 0 {
+			a.Life -= deltaTime
+			if a.Life <= 0 {
+				continue
+			}
+		}
+		ps.attractors[n] = a
+		n++
+	}
+	ps.attractors = ps.attractors[:n]
+}
+
+// applyForces accelerates a particle by the forces it opted into.
+func (ps *ParticleSystem) applyForces(p *Particle, deltaTime float64) {
+	var ax, ay float64
+	if p.Forces&ForceAttractors != 0 {
+		for i := range ps.attractors {
+			fx, fy := ps.attractors[i].accel(p.X, p.Y)
+			ax += fx
+			ay += fy
+		}
+	}
+	if p.Forces&ForceField != 0 && ps.field != nil {
+		fx, fy := ps.field.At(p.X, p.Y)
+		ax += fx
+		ay += fy
+	}
+	p.VX += ax * deltaTime
+	p.VY += ay * deltaTime
+}
+
+// accel returns the attractor's acceleration at (x, y).
+func (a *Attractor) accel(x, y float64) (float64, float64) {
+	dx, dy := a.X-x, a.Y-y
+	d := math.Hypot(dx, dy)
+	if d >= a.Radius || d == 0 {
+		return 0, 0
+	}
+	falloff := 1 - d/a.Radius
+	nx, ny := dx/d, dy/d
+	// The tangent (ny, -nx) turns counter-clockwise around the center.
+	return (nx*a.Strength + ny*a.Swirl) * falloff, (ny*a.Strength - nx*a.Swirl) * falloff
+}
+
+// VectorField is a coarse grid of accelerations covering a map, sampled
+// with bilinear interpolation, for wind and drafts.
+type VectorField struct {
+	width, height int     // Cells
+	cellSize      float64 // Tiles per cell
+	fx, fy        []float64
+}
+
+// NewVectorField creates a zero field covering mapWidth x mapHeight tiles
+// with cells of cellSize tiles.
+func NewVectorField(mapWidth, mapHeight int, cellSize float64) *VectorField {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	w := int(math.Ceil(float64(mapWidth)/cellSize)) + 1
+	h := int(math.Ceil(float64(mapHeight)/cellSize)) + 1
+	return &VectorField{
+		width:    w,
+		height:   h,
+		cellSize: cellSize,
+		fx:       make([]float64, w*h),
+		fy:       make([]float64, w*h),
+	}
+}
+
+// Size returns the field's dimensions in cells.
+func (f *VectorField) Size() (int, int) {
+	return f.width, f.height
+}
+
+// CellSize returns the width of a cell in tiles.
+func (f *VectorField) CellSize() float64 {
+	return f.cellSize
+}
+
+// Set sets the acceleration at cell (cx, cy). Out-of-range cells are ignored.
+func (f *VectorField) Set(cx, cy int, ax, ay float64) {
+	if cx < 0 || cy < 0 || cx >= f.width || cy >= f.height {
+		return
+	}
+	f.fx[cy*f.width+cx] = ax
+	f.fy[cy*f.width+cx] = ay
+}
+
+// Fill sets every cell to the same acceleration, e.g. a steady wind.
+func (f *VectorField) Fill(ax, ay float64) {
+	for i := range f.fx {
+		f.fx[i] = ax
+		f.fy[i] = ay
+	}
+}
+
+// At samples the field at world position (x, y). Positions outside the map
+// take the nearest edge value.
+func (f *VectorField) At(x, y float64) (float64, float64) {
+	gx := clampF(x/f.cellSize, 0, float64(f.width-1))
+	gy := clampF(y/f.cellSize, 0, float64(f.height-1))
+	x0, y0 := int(gx), int(gy)
+	x1, y1 := min(x0+1, f.width-1), min(y0+1, f.height-1)
+	tx, ty := gx-float64(x0), gy-float64(y0)
+
+	i00, i10 := y0*f.width+x0, y0*f.width+x1
+	i01, i11 := y1*f.width+x0, y1*f.width+x1
+	lerp := func(v []float64) float64 {
+		top := v[i00] + (v[i10]-v[i00])*tx
+		bottom := v[i01] + (v[i11]-v[i01])*tx
+		return top + (bottom-top)*ty
+	}
+	return lerp(f.fx), lerp(f.fy)
+}
+
+// clampF clamps v to [lo, hi].
+func clampF(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
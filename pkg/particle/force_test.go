@@ -0,0 +1,131 @@
+package particle
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestAttractorAccel(t *testing.T) {
+	tests := []struct {
+		name   string
+		a      Attractor
+		x, y   float64
+		wantAX float64
+		wantAY float64
+	}{
+		{"pull", Attractor{Strength: 4, Radius: 2}, 1, 0, -2, 0},
+		{"push", Attractor{Strength: -4, Radius: 2}, 1, 0, 2, 0},
+		{"swirl", Attractor{Swirl: 4, Radius: 2}, 1, 0, 0, 2},
+		{"out of range", Attractor{Strength: 4, Radius: 2}, 3, 0, 0, 0},
+		{"at center", Attractor{Strength: 4, Radius: 2}, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ax, ay := tt.a.accel(tt.x, tt.y)
+			if math.Abs(ax-tt.wantAX) > 1e-9 || math.Abs(ay-tt.wantAY) > 1e-9 {
+				t.Errorf("accel(%v, %v) = (%v, %v), want (%v, %v)", tt.x, tt.y, ax, ay, tt.wantAX, tt.wantAY)
+			}
+		})
+	}
+}
+
+func TestAttractorOptIn(t *testing.T) {
+	tests := []struct {
+		name   string
+		forces ForceMask
+		wantVX bool // Whether the particle gains velocity toward the attractor
+	}{
+		{"no forces", 0, false},
+		{"attractors", ForceAttractors, true},
+		{"field only", ForceField, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := NewParticleSystem(4, 1)
+			ps.AddAttractor(Attractor{X: 5, Y: 0, Strength: 10, Radius: 10})
+			p := ps.Spawn(0, 0, 1, 0, 0, 0, 5, 1, color.RGBA{A: 255})
+			p.Forces = tt.forces
+
+			ps.Update(0.1)
+
+			if got := p.VX > 0; got != tt.wantVX {
+				t.Errorf("VX = %v, want pulled %v", p.VX, tt.wantVX)
+			}
+		})
+	}
+}
+
+func TestAttractorLifecycle(t *testing.T) {
+	ps := NewParticleSystem(4, 1)
+	timed := ps.AddAttractor(Attractor{Strength: 1, Radius: 1, Life: 0.5})
+	permanent := ps.AddAttractor(Attractor{Strength: 1, Radius: 1})
+
+	if !ps.MoveAttractor(permanent, 3, 4) {
+		t.Error("MoveAttractor() on a live attractor failed")
+	}
+	ps.Update(0.5)
+	if ps.AttractorCount() != 1 {
+		t.Fatalf("AttractorCount() = %d after expiry, want 1", ps.AttractorCount())
+	}
+	if ps.MoveAttractor(timed, 0, 0) {
+		t.Error("MoveAttractor() on an expired attractor succeeded")
+	}
+
+	ps.RemoveAttractor(permanent)
+	if ps.AttractorCount() != 0 {
+		t.Errorf("AttractorCount() = %d after removal, want 0", ps.AttractorCount())
+	}
+}
+
+func TestVectorFieldSampling(t *testing.T) {
+	f := NewVectorField(8, 8, 4)
+	if w, h := f.Size(); w != 3 || h != 3 {
+		t.Fatalf("Size() = %d x %d, want 3 x 3", w, h)
+	}
+	f.Set(1, 0, 2, -2)
+
+	tests := []struct {
+		name   string
+		x, y   float64
+		wantAX float64
+		wantAY float64
+	}{
+		{"on cell", 4, 0, 2, -2},
+		{"halfway", 2, 0, 1, -1},
+		{"between rows", 4, 2, 1, -1},
+		{"empty cell", 8, 8, 0, 0},
+		{"clamped outside", 4, -10, 2, -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ax, ay := f.At(tt.x, tt.y)
+			if math.Abs(ax-tt.wantAX) > 1e-9 || math.Abs(ay-tt.wantAY) > 1e-9 {
+				t.Errorf("At(%v, %v) = (%v, %v), want (%v, %v)", tt.x, tt.y, ax, ay, tt.wantAX, tt.wantAY)
+			}
+		})
+	}
+}
+
+func TestVectorFieldMovesWeather(t *testing.T) {
+	ps := NewParticleSystem(64, 1)
+	f := NewVectorField(16, 16, 4)
+	f.Fill(20, 0)
+	ps.SetVectorField(f)
+
+	w := NewWeatherEmitter(ps, "horror", 8, 8, 4, 4)
+	w.SetForces(ForceField)
+	w.Update(1)
+	ps.Update(0.1)
+
+	active := ps.GetActiveParticles()
+	if len(active) == 0 {
+		t.Fatal("no weather particles emitted")
+	}
+	for _, p := range active {
+		// Horror dust drifts at most 0.8 on its own; the wind adds 2.
+		if p.VX < 1 {
+			t.Errorf("VX = %v, want pushed by the wind", p.VX)
+		}
+	}
+}
@@ -9,15 +9,20 @@ import (
 
 // Particle represents a single particle with position, velocity, lifetime, color, and size.
 type Particle struct {
-	X, Y       float64 // World position
-	Z          float64 // Height (for 3D-like effects)
-	VX, VY     float64 // Velocity
-	VZ         float64 // Vertical velocity
-	Life       float64 // Current life remaining (seconds)
-	MaxLife    float64 // Initial life (for fade calculations)
-	R, G, B, A uint8   // Color components
-	Size       float64 // Particle size
-	Active     bool    // Whether particle is alive
+	X, Y       float64   // World position
+	Z          float64   // Height (for 3D-like effects)
+	VX, VY     float64   // Velocity
+	VZ         float64   // Vertical velocity
+	Life       float64   // Current life remaining (seconds)
+	MaxLife    float64   // Initial life (for fade calculations)
+	R, G, B, A uint8     // Color components
+	Size       float64   // Particle size
+	Active     bool      // Whether particle is alive
+	Collision  Collision // Reaction to walls, floor and ceiling
+	Forces     ForceMask // External forces moving the particle
+
+	preset   *Preset // Curves driving the particle, if spawned from a preset
+	velocity float64 // Last value of the preset velocity curve
 }
 
 // ParticleSystem manages a pool of particles with spawn/update/cull lifecycle.
@@ -26,12 +31,26 @@ type ParticleSystem struct {
 	poolSize      int
 	nextIndex     int
 	rng           *rand.Rand
+	src           *frameSource // Source of rng, reseeded every frame
+	seed          int64
+	frame         uint64
 	genreID       string
 	activeIndices []int // Indices of active particles for efficient iteration
 
 	// Spatial culling bounds
 	minX, maxX float64
 	minY, maxY float64
+
+	// Map geometry for colliding particles
+	solid   func(x, y int) bool
+	ceiling float64
+
+	presets *PresetRegistry
+
+	// External forces for particles that opt in
+	attractors      []attractorSlot
+	nextAttractorID int
+	field           *VectorField
 }
 
 // NewParticleSystem creates a particle system with a pre-allocated pool.
@@ -40,15 +59,19 @@ func NewParticleSystem(poolSize int, seed int64) *ParticleSystem {
 		poolSize = 1024 // default pool size
 	}
 
+	rng, src := newFrameRand(seed)
 	return &ParticleSystem{
 		particles:     make([]Particle, poolSize),
 		poolSize:      poolSize,
 		activeIndices: make([]int, 0, poolSize),
-		rng:           rand.New(rand.NewSource(seed)),
+		rng:           rng,
+		src:           src,
+		seed:          seed,
 		minX:          -1000,
 		maxX:          1000,
 		minY:          -1000,
 		maxY:          1000,
+		presets:       NewPresetRegistry(),
 	}
 }
 
@@ -90,6 +113,9 @@ func (ps *ParticleSystem) Spawn(x, y, z, vx, vy, vz, life, size float64, c color
 			p.A = c.A
 			p.Size = size
 			p.Active = true
+			p.Collision = Collision{}
+			p.Forces = 0
+			p.preset = nil
 			// Add to active indices list
 			ps.activeIndices = append(ps.activeIndices, particleIndex)
 			return p
@@ -104,6 +130,18 @@ func (ps *ParticleSystem) Spawn(x, y, z, vx, vy, vz, life, size float64, c color
 
 // SpawnBurst creates multiple particles at once with randomized velocities.
 func (ps *ParticleSystem) SpawnBurst(x, y, z float64, count int, speed, spread, life, size float64, c color.RGBA) {
+	ps.SpawnCollidingBurst(x, y, z, count, speed, spread, life, size, c, Collision{})
+}
+
+// SpawnCollidingBurst is SpawnBurst for particles that collide with the map,
+// e.g. debris that bounces off walls and settles.
+func (ps *ParticleSystem) SpawnCollidingBurst(x, y, z float64, count int, speed, spread, life, size float64, c color.RGBA, col Collision) {
+	ps.SpawnForcedBurst(x, y, z, count, speed, spread, life, size, c, col, 0)
+}
+
+// SpawnForcedBurst is SpawnCollidingBurst for particles that are also moved
+// by the given external forces, e.g. debris flung by a blast.
+func (ps *ParticleSystem) SpawnForcedBurst(x, y, z float64, count int, speed, spread, life, size float64, c color.RGBA, col Collision, forces ForceMask) {
 	for i := 0; i < count; i++ {
 		angle := ps.rng.Float64() * 2 * math.Pi
 		velocity := speed * (0.5 + ps.rng.Float64()*0.5)
@@ -112,23 +150,39 @@ func (ps *ParticleSystem) SpawnBurst(x, y, z float64, count int, speed, spread,
 		vy := math.Sin(angle) * velocity
 		vz := (ps.rng.Float64()*2 - 1) * spread
 
-		ps.Spawn(x, y, z, vx, vy, vz, life, size, c)
+		if p := ps.Spawn(x, y, z, vx, vy, vz, life, size, c); p != nil {
+			p.Collision = col
+			p.Forces = forces
+		}
 	}
 }
 
-// Update advances all active particles by deltaTime seconds.
+// Update advances all active particles by deltaTime seconds and moves the
+// system to the next frame.
 func (ps *ParticleSystem) Update(deltaTime float64) {
+	ps.ageAttractors(deltaTime)
+
 	// Iterate only over active particles and remove deactivated ones
 	writeIdx := 0
 	for readIdx := 0; readIdx < len(ps.activeIndices); readIdx++ {
 		particleIndex := ps.activeIndices[readIdx]
 		p := &ps.particles[particleIndex]
 
+		if p.Forces != 0 {
+			ps.applyForces(p, deltaTime)
+		}
+
 		// Update position
+		prevX, prevY := p.X, p.Y
 		p.X += p.VX * deltaTime
 		p.Y += p.VY * deltaTime
 		p.Z += p.VZ * deltaTime
 
+		if p.Collision.Mode != CollideNone && !ps.collide(p, prevX, prevY) {
+			p.Active = false
+			continue
+		}
+
 		// Update life
 		p.Life -= deltaTime
 		if p.Life <= 0 {
@@ -136,15 +190,21 @@ func (ps *ParticleSystem) Update(deltaTime float64) {
 			continue
 		}
 
+		if p.preset != nil {
+			p.applyPreset(deltaTime)
+		}
+
 		// Spatial culling
 		if p.X < ps.minX || p.X > ps.maxX || p.Y < ps.minY || p.Y > ps.maxY {
 			p.Active = false
 			continue
 		}
 
-		// Fade alpha based on remaining life
-		lifeFraction := p.Life / p.MaxLife
-		p.A = uint8(float64(color.RGBA{p.R, p.G, p.B, 255}.A) * lifeFraction)
+		// Fade alpha based on remaining life; presets fade by their color curve
+		if p.preset == nil {
+			lifeFraction := p.Life / p.MaxLife
+			p.A = uint8(float64(color.RGBA{p.R, p.G, p.B, 255}.A) * lifeFraction)
+		}
 
 		// Keep this particle in active list
 		ps.activeIndices[writeIdx] = particleIndex
@@ -152,6 +212,7 @@ func (ps *ParticleSystem) Update(deltaTime float64) {
 	}
 	// Trim activeIndices to only include still-active particles
 	ps.activeIndices = ps.activeIndices[:writeIdx]
+	ps.SetFrame(ps.frame + 1)
 }
 
 // GetActiveParticles returns a slice of all currently active particles.
@@ -170,7 +231,13 @@ func (ps *ParticleSystem) GetActiveParticles() []Particle {
 // Returns slice of particles sorted by distance (far to near for proper alpha blending).
 func (ps *ParticleSystem) GetVisibleParticles(cameraX, cameraY, dirX, dirY, maxDistSq float64) []Particle {
 	visible := make([]Particle, 0, len(ps.activeIndices)/2)
+	return ps.AppendVisibleParticles(visible, cameraX, cameraY, dirX, dirY, maxDistSq)
+}
 
+// AppendVisibleParticles is GetVisibleParticles appending to dst, so a
+// renderer can reuse one slice across frames.
+func (ps *ParticleSystem) AppendVisibleParticles(dst []Particle, cameraX, cameraY, dirX, dirY, maxDistSq float64) []Particle {
+	visible := dst
 	for _, idx := range ps.activeIndices {
 		p := &ps.particles[idx]
 
@@ -0,0 +1,317 @@
+package particle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"os"
+	"sort"
+)
+
+// Preset errors.
+var (
+	ErrUnknownPreset = errors.New("unknown particle preset")
+	ErrInvalidPreset = errors.New("invalid particle preset")
+)
+
+// CurveKey is one point of a Curve: value V at lifetime fraction T [0-1].
+type CurveKey struct {
+	T float64 `json:"t"`
+	V float64 `json:"v"`
+}
+
+// Curve is a value over a particle's lifetime, interpolated linearly
+// between keys sorted by T. An empty curve evaluates to 1.
+type Curve []CurveKey
+
+// Eval returns the curve value at lifetime fraction t.
+func (c Curve) Eval(t float64) float64 {
+	if len(c) == 0 {
+		return 1
+	}
+	if t <= c[0].T {
+		return c[0].V
+	}
+	if t >= c[len(c)-1].T {
+		return c[len(c)-1].V
+	}
+	for i := 1; i < len(c); i++ {
+		if t <= c[i].T {
+			a, b := c[i-1], c[i]
+			if b.T <= a.T {
+				return b.V
+			}
+			return a.V + (b.V-a.V)*(t-a.T)/(b.T-a.T)
+		}
+	}
+	return c[len(c)-1].V
+}
+
+// ColorKey is one point of a ColorCurve.
+type ColorKey struct {
+	T float64 `json:"t"`
+	R uint8   `json:"r"`
+	G uint8   `json:"g"`
+	B uint8   `json:"b"`
+	A uint8   `json:"a"`
+}
+
+// ColorCurve is a color over a particle's lifetime, interpolated linearly
+// between keys sorted by T.
+type ColorCurve []ColorKey
+
+// Eval returns the curve color at lifetime fraction t.
+func (c ColorCurve) Eval(t float64) color.RGBA {
+	if len(c) == 0 {
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+	if t <= c[0].T {
+		return c[0].rgba()
+	}
+	if t >= c[len(c)-1].T {
+		return c[len(c)-1].rgba()
+	}
+	for i := 1; i < len(c); i++ {
+		if t <= c[i].T {
+			a, b := c[i-1], c[i]
+			if b.T <= a.T {
+				return b.rgba()
+			}
+			f := (t - a.T) / (b.T - a.T)
+			lerp := func(x, y uint8) uint8 {
+				return uint8(math.Round(float64(x) + (float64(y)-float64(x))*f))
+			}
+			return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: lerp(a.A, b.A)}
+		}
+	}
+	return c[len(c)-1].rgba()
+}
+
+func (k ColorKey) rgba() color.RGBA {
+	return color.RGBA{R: k.R, G: k.G, B: k.B, A: k.A}
+}
+
+// Preset is a data-driven particle effect. Particles leave in a cone of
+// half-angle Spread around the spawn direction; their size, color and speed
+// then follow the curves over their lifetime.
+type Preset struct {
+	Name        string     `json:"name"`
+	Count       int        `json:"count"`
+	Life        float64    `json:"life"`         // Seconds
+	LifeJitter  float64    `json:"life_jitter"`  // Random extra life fraction [0-1]
+	Speed       float64    `json:"speed"`        // Initial speed, tiles per second
+	SpeedJitter float64    `json:"speed_jitter"` // Random speed reduction fraction [0-1]
+	Spread      float64    `json:"spread"`       // Cone half-angle in radians; Pi for all directions
+	Z           float64    `json:"z"`            // Spawn height
+	Rise        float64    `json:"rise"`         // Initial vertical speed
+	Gravity     float64    `json:"gravity"`      // Vertical acceleration, negative pulls down
+	Size        Curve      `json:"size"`         // Absolute size over lifetime
+	Color       ColorCurve `json:"color"`        // Color over lifetime
+	Velocity    Curve      `json:"velocity"`     // Speed multiplier over lifetime
+	Collision   Collision  `json:"collision"`
+}
+
+// Validate checks that a preset can be spawned.
+func (p *Preset) Validate() error {
+	switch {
+	case p.Name == "":
+		return fmt.Errorf("%w: missing name", ErrInvalidPreset)
+	case p.Count <= 0:
+		return fmt.Errorf("%w: %s: count must be positive", ErrInvalidPreset, p.Name)
+	case p.Life <= 0:
+		return fmt.Errorf("%w: %s: life must be positive", ErrInvalidPreset, p.Name)
+	}
+	for i := 1; i < len(p.Size); i++ {
+		if p.Size[i].T < p.Size[i-1].T {
+			return fmt.Errorf("%w: %s: size keys out of order", ErrInvalidPreset, p.Name)
+		}
+	}
+	for i := 1; i < len(p.Velocity); i++ {
+		if p.Velocity[i].T < p.Velocity[i-1].T {
+			return fmt.Errorf("%w: %s: velocity keys out of order", ErrInvalidPreset, p.Name)
+		}
+	}
+	for i := 1; i < len(p.Color); i++ {
+		if p.Color[i].T < p.Color[i-1].T {
+			return fmt.Errorf("%w: %s: color keys out of order", ErrInvalidPreset, p.Name)
+		}
+	}
+	return nil
+}
+
+// PresetRegistry holds particle presets by name.
+type PresetRegistry struct {
+	presets map[string]*Preset
+}
+
+// NewPresetRegistry creates a registry holding the built-in presets.
+func NewPresetRegistry() *PresetRegistry {
+	r := &PresetRegistry{presets: make(map[string]*Preset)}
+	for _, p := range builtinPresets() {
+		r.presets[p.Name] = p
+	}
+	return r
+}
+
+// Register adds a preset, replacing any preset of the same name.
+func (r *PresetRegistry) Register(p Preset) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	r.presets[p.Name] = &p
+	return nil
+}
+
+// Get returns the preset with the given name.
+func (r *PresetRegistry) Get(name string) (*Preset, bool) {
+	p, ok := r.presets[name]
+	return p, ok
+}
+
+// Names returns the registered preset names in sorted order.
+func (r *PresetRegistry) Names() []string {
+	names := make([]string, 0, len(r.presets))
+	for name := range r.presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadJSON registers the presets of a JSON array, e.g. a mod's
+// particles.json. Nothing is registered if any preset is invalid.
+func (r *PresetRegistry) LoadJSON(data []byte) (int, error) {
+	var presets []Preset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+	}
+	for i := range presets {
+		if err := presets[i].Validate(); err != nil {
+			return 0, err
+		}
+	}
+	for _, p := range presets {
+		r.presets[p.Name] = &p
+	}
+	return len(presets), nil
+}
+
+// LoadFile registers the presets of a JSON file.
+func (r *PresetRegistry) LoadFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return r.LoadJSON(data)
+}
+
+// SetPresets replaces the system's preset registry.
+func (ps *ParticleSystem) SetPresets(r *PresetRegistry) {
+	ps.presets = r
+}
+
+// Presets returns the system's preset registry.
+func (ps *ParticleSystem) Presets() *PresetRegistry {
+	return ps.presets
+}
+
+// SpawnPreset spawns the named preset at (x, y), aimed at angle dir in
+// radians. It returns the number of particles spawned, which is lower than
+// the preset count when the pool is full.
+func (ps *ParticleSystem) SpawnPreset(name string, x, y, dir float64) (int, error) {
+	preset, ok := ps.presets.Get(name)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownPreset, name)
+	}
+
+	c := preset.Color.Eval(0)
+	size := preset.Size.Eval(0)
+	velocity := preset.Velocity.Eval(0)
+	spawned := 0
+	for i := 0; i < preset.Count; i++ {
+		angle := dir + (ps.rng.Float64()*2-1)*preset.Spread
+		speed := preset.Speed * velocity * (1 - ps.rng.Float64()*clamp01(preset.SpeedJitter))
+		life := preset.Life * (1 + ps.rng.Float64()*clamp01(preset.LifeJitter))
+
+		p := ps.Spawn(x, y, preset.Z, math.Cos(angle)*speed, math.Sin(angle)*speed, preset.Rise, life, size, c)
+		if p == nil {
+			break
+		}
+		p.Collision = preset.Collision
+		p.preset = preset
+		p.velocity = velocity
+		spawned++
+	}
+	return spawned, nil
+}
+
+// applyPreset updates a preset particle's velocity, size and color for its
+// new age after a step.
+func (p *Particle) applyPreset(deltaTime float64) {
+	pr := p.preset
+	t := 1 - p.Life/p.MaxLife
+
+	p.VZ += pr.Gravity * deltaTime
+	if len(pr.Velocity) > 0 {
+		// Scale by the curve's change, so speed lost in bounces stays lost.
+		v := pr.Velocity.Eval(t)
+		if p.velocity > 0 {
+			p.VX *= v / p.velocity
+			p.VY *= v / p.velocity
+		}
+		p.velocity = v
+	}
+	if len(pr.Size) > 0 {
+		p.Size = pr.Size.Eval(t)
+	}
+	if len(pr.Color) > 0 {
+		c := pr.Color.Eval(t)
+		p.R, p.G, p.B, p.A = c.R, c.G, c.B, c.A
+	}
+}
+
+// builtinPresets returns the presets every registry starts with.
+func builtinPresets() []*Preset {
+	return []*Preset{
+		{
+			Name: "blood_spray", Count: 14, Life: 0.6, LifeJitter: 0.5,
+			Speed: 6, SpeedJitter: 0.6, Spread: 0.5, Z: 0.5, Rise: 1.5, Gravity: -9,
+			Size:      Curve{{0, 0.9}, {1, 0.5}},
+			Color:     ColorCurve{{T: 0, R: 190, G: 10, B: 10, A: 255}, {T: 1, R: 90, G: 0, B: 0, A: 200}},
+			Velocity:  Curve{{0, 1}, {1, 0.4}},
+			Collision: Collision{Mode: CollideStick},
+		},
+		{
+			Name: "sparks", Count: 12, Life: 0.35, LifeJitter: 0.6,
+			Speed: 12, SpeedJitter: 0.5, Spread: 0.9, Z: 0.5, Rise: 2, Gravity: -12,
+			Size:      Curve{{0, 0.6}, {1, 0.2}},
+			Color:     ColorCurve{{T: 0, R: 255, G: 255, B: 200, A: 255}, {T: 0.4, R: 255, G: 190, B: 60, A: 255}, {T: 1, R: 200, G: 60, B: 0, A: 0}},
+			Velocity:  Curve{{0, 1}, {1, 0.3}},
+			Collision: Collision{Mode: CollideBounce, Restitution: 0.5},
+		},
+		{
+			Name: "smoke_plume", Count: 10, Life: 2.5, LifeJitter: 0.4,
+			Speed: 0.6, SpeedJitter: 0.8, Spread: math.Pi, Z: 0.3, Rise: 1.2,
+			Size:      Curve{{0, 0.8}, {1, 3.0}},
+			Color:     ColorCurve{{T: 0, R: 90, G: 90, B: 90, A: 170}, {T: 1, R: 140, G: 140, B: 140, A: 0}},
+			Velocity:  Curve{{0, 1}, {1, 0.2}},
+			Collision: Collision{Mode: CollideDie},
+		},
+		{
+			Name: "muzzle_smoke", Count: 5, Life: 0.8, LifeJitter: 0.3,
+			Speed: 1.5, SpeedJitter: 0.5, Spread: 0.3, Z: 0.5, Rise: 0.4,
+			Size:     Curve{{0, 0.4}, {1, 1.4}},
+			Color:    ColorCurve{{T: 0, R: 200, G: 200, B: 190, A: 120}, {T: 1, R: 160, G: 160, B: 160, A: 0}},
+			Velocity: Curve{{0, 1}, {0.3, 0.3}, {1, 0.1}},
+		},
+		{
+			Name: "toxic_drip", Count: 3, Life: 1.2, LifeJitter: 0.3,
+			Speed: 0.2, SpeedJitter: 1, Spread: math.Pi, Z: 3, Gravity: -6,
+			Size:      Curve{{0, 0.3}, {1, 0.4}},
+			Color:     ColorCurve{{T: 0, R: 120, G: 220, B: 40, A: 220}, {T: 1, R: 80, G: 160, B: 20, A: 160}},
+			Collision: Collision{Mode: CollideDie},
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package particle
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCurveEval(t *testing.T) {
+	c := Curve{{0, 1}, {0.5, 3}, {1, 0}}
+	tests := []struct {
+		t    float64
+		want float64
+	}{
+		{-1, 1},
+		{0, 1},
+		{0.25, 2},
+		{0.5, 3},
+		{0.75, 1.5},
+		{1, 0},
+		{2, 0},
+	}
+	for _, tt := range tests {
+		if got := c.Eval(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Eval(%f) = %f, want %f", tt.t, got, tt.want)
+		}
+	}
+	if got := (Curve{}).Eval(0.5); got != 1 {
+		t.Errorf("empty curve Eval = %f, want 1", got)
+	}
+}
+
+func TestColorCurveEval(t *testing.T) {
+	c := ColorCurve{{T: 0, R: 0, G: 100, B: 200, A: 255}, {T: 1, R: 200, G: 100, B: 0, A: 55}}
+	got := c.Eval(0.5)
+	want := color.RGBA{R: 100, G: 100, B: 100, A: 155}
+	if got != want {
+		t.Errorf("Eval(0.5) = %v, want %v", got, want)
+	}
+	if got := (ColorCurve{}).Eval(0.5); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("empty curve Eval = %v, want white", got)
+	}
+}
+
+func TestBuiltinPresets(t *testing.T) {
+	r := NewPresetRegistry()
+	for _, name := range []string{"blood_spray", "sparks", "smoke_plume", "muzzle_smoke", "toxic_drip"} {
+		p, ok := r.Get(name)
+		if !ok {
+			t.Errorf("built-in preset %q missing", name)
+			continue
+		}
+		if err := p.Validate(); err != nil {
+			t.Errorf("built-in preset %q invalid: %v", name, err)
+		}
+	}
+}
+
+func TestPresetValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset Preset
+	}{
+		{"no name", Preset{Count: 1, Life: 1}},
+		{"no count", Preset{Name: "x", Life: 1}},
+		{"no life", Preset{Name: "x", Count: 1}},
+		{"unsorted size", Preset{Name: "x", Count: 1, Life: 1, Size: Curve{{1, 1}, {0, 1}}}},
+		{"unsorted color", Preset{Name: "x", Count: 1, Life: 1, Color: ColorCurve{{T: 1}, {T: 0}}}},
+	}
+	r := NewPresetRegistry()
+	for _, tt := range tests {
+		if err := r.Register(tt.preset); !errors.Is(err, ErrInvalidPreset) {
+			t.Errorf("%s: Register() error = %v, want ErrInvalidPreset", tt.name, err)
+		}
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	data := []byte(`[
+		{"name": "embers", "count": 4, "life": 1.5, "speed": 2, "spread": 3.14,
+		 "size": [{"t": 0, "v": 0.5}, {"t": 1, "v": 0.1}],
+		 "color": [{"t": 0, "r": 255, "g": 120, "a": 255}],
+		 "collision": {"mode": "bounce", "restitution": 0.3}},
+		{"name": "sparks", "count": 1, "life": 0.1}
+	]`)
+	r := NewPresetRegistry()
+	n, err := r.LoadJSON(data)
+	if err != nil || n != 2 {
+		t.Fatalf("LoadJSON() = %d, %v; want 2, nil", n, err)
+	}
+
+	embers, ok := r.Get("embers")
+	if !ok {
+		t.Fatal("embers not registered")
+	}
+	if embers.Collision.Mode != CollideBounce || embers.Collision.Restitution != 0.3 {
+		t.Errorf("collision = %+v, want bounce 0.3", embers.Collision)
+	}
+	if embers.Color[0].R != 255 || embers.Size.Eval(1) != 0.1 {
+		t.Errorf("curves not decoded: %+v", embers)
+	}
+	if sparks, _ := r.Get("sparks"); sparks.Count != 1 {
+		t.Error("mod preset did not replace the built-in")
+	}
+
+	bad := []string{
+		`not json`,
+		`[{"name": "x", "count": 1, "life": 1, "collision": {"mode": "explode"}}]`,
+		`[{"name": "ok", "count": 1, "life": 1}, {"name": "", "count": 1, "life": 1}]`,
+	}
+	for _, b := range bad {
+		if _, err := NewPresetRegistry().LoadJSON([]byte(b)); !errors.Is(err, ErrInvalidPreset) && err == nil {
+			t.Errorf("LoadJSON(%q) error = %v, want failure", b, err)
+		}
+	}
+	r = NewPresetRegistry()
+	r.LoadJSON([]byte(bad[2]))
+	if _, ok := r.Get("ok"); ok {
+		t.Error("valid preset registered from a file with an invalid one")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "particles.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "mist", "count": 2, "life": 1}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewPresetRegistry()
+	if n, err := r.LoadFile(path); err != nil || n != 1 {
+		t.Fatalf("LoadFile() = %d, %v; want 1, nil", n, err)
+	}
+	if _, err := r.LoadFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadFile() of a missing file should fail")
+	}
+}
+
+func TestSpawnPreset(t *testing.T) {
+	ps := NewParticleSystem(64, 1)
+	if _, err := ps.SpawnPreset("nope", 0, 0, 0); !errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("unknown preset error = %v, want ErrUnknownPreset", err)
+	}
+
+	ps.Presets().Register(Preset{
+		Name: "jet", Count: 8, Life: 1, Speed: 4, Spread: 0,
+		Size:      Curve{{0, 1}, {1, 3}},
+		Color:     ColorCurve{{T: 0, R: 255, A: 255}, {T: 1, B: 255, A: 0}},
+		Velocity:  Curve{{0, 1}, {1, 0.5}},
+		Collision: Collision{Mode: CollideDie},
+	})
+	n, err := ps.SpawnPreset("jet", 5, 5, math.Pi/2)
+	if err != nil || n != 8 {
+		t.Fatalf("SpawnPreset() = %d, %v; want 8, nil", n, err)
+	}
+
+	for _, p := range ps.GetActiveParticles() {
+		if math.Abs(p.VX) > 1e-9 || math.Abs(p.VY-4) > 1e-9 {
+			t.Fatalf("velocity = (%f, %f), want (0, 4) along dir", p.VX, p.VY)
+		}
+		if p.Collision.Mode != CollideDie {
+			t.Fatalf("collision = %v, want die", p.Collision.Mode)
+		}
+	}
+
+	ps.Update(0.5)
+	p := ps.GetActiveParticles()[0]
+	if math.Abs(p.Size-2) > 0.01 {
+		t.Errorf("size at half life = %f, want 2", p.Size)
+	}
+	if math.Abs(p.VY-3) > 0.01 {
+		t.Errorf("speed at half life = %f, want 3", p.VY)
+	}
+	if p.R != 128 || p.B != 128 || p.A != 128 {
+		t.Errorf("color at half life = (%d, %d, %d, %d), want halfway", p.R, p.G, p.B, p.A)
+	}
+}
+
+func TestSpawnPresetGravity(t *testing.T) {
+	ps := NewParticleSystem(8, 1)
+	ps.Presets().Register(Preset{Name: "drop", Count: 1, Life: 2, Z: 3, Gravity: -10})
+	ps.SpawnPreset("drop", 0, 0, 0)
+	ps.Update(0.1)
+	ps.Update(0.1)
+	p := ps.GetActiveParticles()[0]
+	if math.Abs(p.VZ+2) > 1e-9 || p.Z >= 3 {
+		t.Errorf("VZ = %f, Z = %f; want falling at 2", p.VZ, p.Z)
+	}
+}
@@ -0,0 +1,279 @@
+package particle
+
+import (
+	"image/color"
+	"math"
+)
+
+// TrailConfig describes a ribbon trail: a strip drawn through the recent
+// positions of a moving object, such as a rocket's smoke or a sword's arc.
+type TrailConfig struct {
+	Length    int        // Maximum number of recorded positions
+	Spacing   float64    // Minimum distance between recorded positions
+	Fade      float64    // Seconds a recorded position stays visible
+	Width     float64    // Ribbon width at the head, in world units
+	TailWidth float64    // Ribbon width at the tail, as a fraction of Width [0-1]
+	Color     color.RGBA // Color at the head; alpha fades to zero at the tail
+}
+
+// trailPoint is one recorded position of a trail.
+type trailPoint struct {
+	X, Y float64
+	Age  float64
+}
+
+// trail is a pooled ribbon. Its points live in a ring buffer slice of the
+// system's shared point storage.
+type trail struct {
+	cfg    TrailConfig
+	points []trailPoint // Ring buffer of cfg.Length points
+	head   int          // Index of the oldest point
+	count  int
+	gen    int
+	active bool // Slot in use
+	ended  bool // No longer recording; freed once all points faded
+}
+
+// at returns the i-th point counting from the oldest.
+func (t *trail) at(i int) *trailPoint {
+	return &t.points[(t.head+i)%len(t.points)]
+}
+
+// TrailSystem records and ages ribbon trails in fixed storage, so starting
+// and extending trails never allocates. Trails are addressed by IDs that go
+// stale once their slot is reused; 0 is never a valid ID.
+type TrailSystem struct {
+	trails    []trail
+	maxPoints int
+}
+
+// trailIDBits is the number of ID bits holding the slot index.
+const trailIDBits = 16
+
+// NewTrailSystem creates a system of capacity trails of up to maxPoints
+// recorded positions each.
+func NewTrailSystem(capacity, maxPoints int) *TrailSystem {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if capacity > 1<<trailIDBits {
+		capacity = 1 << trailIDBits
+	}
+	if maxPoints < 2 {
+		maxPoints = 2
+	}
+	ts := &TrailSystem{trails: make([]trail, capacity), maxPoints: maxPoints}
+	storage := make([]trailPoint, capacity*maxPoints)
+	for i := range ts.trails {
+		ts.trails[i].points = storage[i*maxPoints : (i+1)*maxPoints]
+	}
+	return ts
+}
+
+// Start begins a trail and returns its ID, or 0 when every slot is in use.
+func (ts *TrailSystem) Start(cfg TrailConfig) int {
+	if cfg.Length < 2 || cfg.Length > ts.maxPoints {
+		cfg.Length = ts.maxPoints
+	}
+	if cfg.Fade <= 0 || cfg.Width <= 0 {
+		return 0
+	}
+	cfg.TailWidth = clamp01(cfg.TailWidth)
+	for i := range ts.trails {
+		t := &ts.trails[i]
+		if t.active {
+			continue
+		}
+		t.cfg = cfg
+		t.head, t.count = 0, 0
+		t.gen++
+		t.active, t.ended = true, false
+		return t.gen<<trailIDBits | i
+	}
+	return 0
+}
+
+// StartTrail begins a trail of the given width, fade time and color with the
+// system's full length, for systems that only describe a trail's look.
+func (ts *TrailSystem) StartTrail(width, fade float64, col color.RGBA) int {
+	return ts.Start(TrailConfig{Length: ts.maxPoints, Spacing: width, Fade: fade, Width: width, Color: col})
+}
+
+// get returns the live, recording trail for an ID.
+func (ts *TrailSystem) get(id int) *trail {
+	i := id & (1<<trailIDBits - 1)
+	if id <= 0 || i >= len(ts.trails) {
+		return nil
+	}
+	t := &ts.trails[i]
+	if !t.active || t.ended || t.gen != id>>trailIDBits {
+		return nil
+	}
+	return t
+}
+
+// Extend records the trail's head at (x, y). Positions closer than the
+// trail's spacing to the last one move the head instead of adding a point,
+// and the oldest point is dropped when the trail is full. It returns false
+// for stale or ended IDs.
+func (ts *TrailSystem) Extend(id int, x, y float64) bool {
+	t := ts.get(id)
+	if t == nil {
+		return false
+	}
+	if t.count > 1 {
+		last := t.at(t.count - 1)
+		prev := t.at(t.count - 2)
+		if math.Hypot(x-prev.X, y-prev.Y) < t.cfg.Spacing {
+			last.X, last.Y, last.Age = x, y, 0
+			return true
+		}
+	}
+	if t.count == t.cfg.Length {
+		t.head = (t.head + 1) % len(t.points)
+		t.count--
+	}
+	*t.at(t.count) = trailPoint{X: x, Y: y}
+	t.count++
+	return true
+}
+
+// End stops recording a trail. It stays visible until its points fade.
+func (ts *TrailSystem) End(id int) {
+	if t := ts.get(id); t != nil {
+		t.ended = true
+	}
+}
+
+// Arc adds an ended trail sweeping around (cx, cy) at radius from angle from
+// to angle to in radians, as if recorded over duration seconds, for melee
+// swings. It returns false when no slot is free.
+func (ts *TrailSystem) Arc(cx, cy, radius, from, to, duration float64, cfg TrailConfig) bool {
+	id := ts.Start(cfg)
+	t := ts.get(id)
+	if t == nil {
+		return false
+	}
+	n := t.cfg.Length
+	for i := 0; i < n; i++ {
+		f := float64(i) / float64(n-1)
+		a := from + (to-from)*f
+		*t.at(i) = trailPoint{
+			X:   cx + math.Cos(a)*radius,
+			Y:   cy + math.Sin(a)*radius,
+			Age: duration * (1 - f),
+		}
+	}
+	t.count = n
+	t.ended = true
+	return true
+}
+
+// Update ages trail points by deltaTime seconds, drops faded points and
+// frees ended trails with nothing left to draw.
+func (ts *TrailSystem) Update(deltaTime float64) {
+	for i := range ts.trails {
+		t := &ts.trails[i]
+		if !t.active {
+			continue
+		}
+		for j := 0; j < t.count; j++ {
+			t.at(j).Age += deltaTime
+		}
+		// Points are oldest first, so faded ones form a prefix.
+		for t.count > 0 && t.at(0).Age >= t.cfg.Fade {
+			t.head = (t.head + 1) % len(t.points)
+			t.count--
+		}
+		if t.ended && t.count == 0 {
+			t.active = false
+		}
+	}
+}
+
+// Len returns the number of trails in use, including ended trails that are
+// still fading.
+func (ts *TrailSystem) Len() int {
+	n := 0
+	for i := range ts.trails {
+		if ts.trails[i].active {
+			n++
+		}
+	}
+	return n
+}
+
+// Clear removes all trails, e.g. on level change.
+func (ts *TrailSystem) Clear() {
+	for i := range ts.trails {
+		ts.trails[i].active = false
+		ts.trails[i].count = 0
+	}
+}
+
+// AppendGeometry appends every visible trail as a triangle strip of quads to
+// vertices and indices, projecting world positions to the screen with
+// project. Each recorded point becomes a pair of vertices offset across the
+// ribbon; width tapers and alpha fades from head to tail and with age. Pass
+// the previous frame's slices resliced to zero length to reuse their storage.
+func (ts *TrailSystem) AppendGeometry(vertices []Vertex, indices []uint16, project func(x, y float64) (float32, float32)) ([]Vertex, []uint16) {
+	for i := range ts.trails {
+		t := &ts.trails[i]
+		if !t.active || t.count < 2 {
+			continue
+		}
+		// Keep indices addressable by uint16.
+		if len(vertices)+2*t.count > math.MaxUint16 {
+			break
+		}
+		base := len(vertices)
+		vertices = t.appendVertices(vertices, project)
+		for j := 0; j < t.count-1; j++ {
+			v := uint16(base + 2*j)
+			indices = append(indices, v, v+1, v+2, v+1, v+3, v+2)
+		}
+	}
+	return vertices, indices
+}
+
+// appendVertices appends the trail's vertex pairs from tail to head.
+func (t *trail) appendVertices(vertices []Vertex, project func(x, y float64) (float32, float32)) []Vertex {
+	cfg := &t.cfg
+	r := float32(cfg.Color.R) / 255
+	g := float32(cfg.Color.G) / 255
+	b := float32(cfg.Color.B) / 255
+	a := float64(cfg.Color.A) / 255
+
+	for j := 0; j < t.count; j++ {
+		p := t.at(j)
+		// Direction along the ribbon from the neighbouring points.
+		prev, next := p, p
+		if j > 0 {
+			prev = t.at(j - 1)
+		}
+		if j < t.count-1 {
+			next = t.at(j + 1)
+		}
+		dx, dy := next.X-prev.X, next.Y-prev.Y
+		l := math.Hypot(dx, dy)
+		if l > 0 {
+			dx, dy = dx/l, dy/l
+		}
+
+		f := float64(j) / float64(t.count-1) // 0 at the tail, 1 at the head
+		half := cfg.Width * (cfg.TailWidth + (1-cfg.TailWidth)*f) / 2
+		alpha := float32(a * f * (1 - p.Age/cfg.Fade))
+		if alpha < 0 {
+			alpha = 0
+		}
+
+		nx, ny := -dy*half, dx*half
+		x0, y0 := project(p.X+nx, p.Y+ny)
+		x1, y1 := project(p.X-nx, p.Y-ny)
+		vertices = append(vertices,
+			Vertex{X: x0, Y: y0, U: 0.5, V: 0.5, R: r, G: g, B: b, A: alpha},
+			Vertex{X: x1, Y: y1, U: 0.5, V: 0.5, R: r, G: g, B: b, A: alpha},
+		)
+	}
+	return vertices
+}
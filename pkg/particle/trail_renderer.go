@@ -0,0 +1,47 @@
+package particle
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TrailRenderer draws ribbon trails as textured triangles, reusing its
+// vertex and index buffers between frames.
+type TrailRenderer struct {
+	geometry []Vertex
+	vertices []ebiten.Vertex
+	indices  []uint16
+	white    *ebiten.Image
+}
+
+// NewTrailRenderer creates a trail renderer.
+func NewTrailRenderer() *TrailRenderer {
+	white := ebiten.NewImage(1, 1)
+	white.Fill(color.White)
+	return &TrailRenderer{white: white}
+}
+
+// Draw renders every visible trail of ts onto screen, projecting world
+// positions with project.
+func (tr *TrailRenderer) Draw(screen *ebiten.Image, ts *TrailSystem, project func(x, y float64) (float32, float32)) {
+	tr.geometry, tr.indices = ts.AppendGeometry(tr.geometry[:0], tr.indices[:0], project)
+	if len(tr.indices) == 0 {
+		return
+	}
+
+	tr.vertices = tr.vertices[:0]
+	for _, v := range tr.geometry {
+		tr.vertices = append(tr.vertices, ebiten.Vertex{
+			DstX:   v.X,
+			DstY:   v.Y,
+			SrcX:   v.U,
+			SrcY:   v.V,
+			ColorR: v.R,
+			ColorG: v.G,
+			ColorB: v.B,
+			ColorA: v.A,
+		})
+	}
+	screen.DrawTriangles(tr.vertices, tr.indices, tr.white, &ebiten.DrawTrianglesOptions{})
+}
@@ -0,0 +1,185 @@
+package particle
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+// identityProjection maps world units straight to screen units.
+func identityProjection(x, y float64) (float32, float32) {
+	return float32(x), float32(y)
+}
+
+var testTrailConfig = TrailConfig{
+	Length:    4,
+	Spacing:   0.5,
+	Fade:      1,
+	Width:     0.2,
+	TailWidth: 0.5,
+	Color:     color.RGBA{R: 255, G: 128, B: 0, A: 255},
+}
+
+func TestTrailStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    TrailConfig
+		wantOK bool
+	}{
+		{"valid", testTrailConfig, true},
+		{"no fade", TrailConfig{Width: 1}, false},
+		{"no width", TrailConfig{Fade: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewTrailSystem(2, 8)
+			id := ts.Start(tt.cfg)
+			if (id != 0) != tt.wantOK {
+				t.Errorf("Start() = %d, want ok %v", id, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTrailCapacityAndStaleIDs(t *testing.T) {
+	ts := NewTrailSystem(1, 4)
+	id := ts.Start(testTrailConfig)
+	if id == 0 {
+		t.Fatal("Start() returned 0")
+	}
+	if ts.Start(testTrailConfig) != 0 {
+		t.Error("Start() on a full system should return 0")
+	}
+
+	ts.Clear()
+	newID := ts.Start(testTrailConfig)
+	if newID == 0 || newID == id {
+		t.Fatalf("Start() after Clear = %d, want fresh ID (old %d)", newID, id)
+	}
+	if ts.Extend(id, 1, 1) {
+		t.Error("Extend() with stale ID should fail")
+	}
+	if !ts.Extend(newID, 1, 1) {
+		t.Error("Extend() with live ID should succeed")
+	}
+}
+
+func TestTrailExtendSpacingAndLength(t *testing.T) {
+	ts := NewTrailSystem(1, 8)
+	id := ts.Start(testTrailConfig)
+
+	ts.Extend(id, 0, 0)
+	ts.Extend(id, 0.2, 0)
+	ts.Extend(id, 0.4, 0) // Within spacing of the previous point: moves the head
+	tr := &ts.trails[0]
+	if tr.count != 2 {
+		t.Fatalf("count = %d, want 2", tr.count)
+	}
+	if head := tr.at(1); head.X != 0.4 {
+		t.Errorf("head X = %v, want 0.4", head.X)
+	}
+
+	for x := 2.0; x <= 6; x++ {
+		ts.Extend(id, x, 0)
+	}
+	if tr.count != testTrailConfig.Length {
+		t.Fatalf("count = %d, want %d", tr.count, testTrailConfig.Length)
+	}
+	if tail := tr.at(0); tail.X != 3 {
+		t.Errorf("tail X = %v, want 3 after dropping oldest points", tail.X)
+	}
+}
+
+func TestTrailEndFadesAndFrees(t *testing.T) {
+	ts := NewTrailSystem(1, 4)
+	id := ts.Start(testTrailConfig)
+	ts.Extend(id, 0, 0)
+	ts.Extend(id, 1, 0)
+
+	ts.End(id)
+	if ts.Extend(id, 2, 0) {
+		t.Error("Extend() after End should fail")
+	}
+	if ts.Len() != 1 {
+		t.Fatalf("Len() = %d while fading, want 1", ts.Len())
+	}
+
+	ts.Update(testTrailConfig.Fade)
+	if ts.Len() != 0 {
+		t.Errorf("Len() = %d after fade, want 0", ts.Len())
+	}
+}
+
+func TestTrailGeometry(t *testing.T) {
+	ts := NewTrailSystem(2, 4)
+	id := ts.Start(testTrailConfig)
+	for x := 0.0; x < 3; x++ {
+		ts.Extend(id, x, 0)
+	}
+
+	vertices, indices := ts.AppendGeometry(nil, nil, identityProjection)
+	if len(vertices) != 6 {
+		t.Fatalf("vertices = %d, want 6", len(vertices))
+	}
+	if len(indices) != 12 {
+		t.Fatalf("indices = %d, want 12", len(indices))
+	}
+
+	// The head is full width and opaque, the tail tapered and transparent.
+	headWidth := vertices[4].Y - vertices[5].Y
+	tailWidth := vertices[0].Y - vertices[1].Y
+	if math.Abs(float64(headWidth)-0.2) > 1e-6 {
+		t.Errorf("head width = %v, want 0.2", headWidth)
+	}
+	if math.Abs(float64(tailWidth)-0.1) > 1e-6 {
+		t.Errorf("tail width = %v, want 0.1", tailWidth)
+	}
+	if vertices[4].A != 1 || vertices[0].A != 0 {
+		t.Errorf("alpha head %v tail %v, want 1 and 0", vertices[4].A, vertices[0].A)
+	}
+
+	ts.Update(0.5)
+	vertices, _ = ts.AppendGeometry(vertices[:0], indices[:0], identityProjection)
+	if math.Abs(float64(vertices[4].A)-0.5) > 1e-6 {
+		t.Errorf("head alpha after half fade = %v, want 0.5", vertices[4].A)
+	}
+}
+
+func TestTrailArc(t *testing.T) {
+	ts := NewTrailSystem(1, 8)
+	if !ts.Arc(0, 0, 1, 0, math.Pi/2, 0.2, testTrailConfig) {
+		t.Fatal("Arc() failed")
+	}
+	tr := &ts.trails[0]
+	if tr.count != testTrailConfig.Length || !tr.ended {
+		t.Fatalf("count %d ended %v, want %d true", tr.count, tr.ended, testTrailConfig.Length)
+	}
+	head := tr.at(tr.count - 1)
+	if math.Abs(head.X) > 1e-9 || math.Abs(head.Y-1) > 1e-9 || head.Age != 0 {
+		t.Errorf("head = %+v, want (0, 1) age 0", *head)
+	}
+	if tail := tr.at(0); tail.Age != 0.2 {
+		t.Errorf("tail age = %v, want 0.2", tail.Age)
+	}
+	if ts.Arc(0, 0, 1, 0, 1, 0.2, testTrailConfig) {
+		t.Error("Arc() on a full system should fail")
+	}
+}
+
+func TestTrailGeometryReusesBuffers(t *testing.T) {
+	ts := NewTrailSystem(4, 16)
+	for i := 0; i < 4; i++ {
+		id := ts.Start(testTrailConfig)
+		for x := 0.0; x < 4; x++ {
+			ts.Extend(id, x, float64(i))
+		}
+	}
+	vertices, indices := ts.AppendGeometry(nil, nil, identityProjection)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		vertices, indices = ts.AppendGeometry(vertices[:0], indices[:0], identityProjection)
+	})
+	if allocs != 0 {
+		t.Errorf("AppendGeometry allocated %v times per frame, want 0", allocs)
+	}
+}
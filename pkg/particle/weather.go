@@ -15,22 +15,49 @@ type WeatherEmitter struct {
 	height       float64 // Effect area height
 	emitTimer    float64 // Time accumulator for emission
 	emitInterval float64 // Seconds between particle spawns
+	rate         float64 // Emission rate multiplier, e.g. from the time of day
+	collision    Collision
+	forces       ForceMask
+
+	// Intensity ramps toward target at rampRate per second
+	intensity float64
+	target    float64
+	rampRate  float64
+
+	// Scripted intensity stages
+	stages     []WeatherStage
+	stage      int
+	stageTime  float64
+	loopStages bool
+	onStage    func(WeatherStage)
+
+	open func(x, y int) bool // Tiles open to the sky; nil means everywhere
 }
 
 // NewWeatherEmitter creates a weather emitter for a specific area.
 func NewWeatherEmitter(system *ParticleSystem, genreID string, x, y, width, height float64) *WeatherEmitter {
 	interval := 0.2 // Default: 5 particles per second
+	var collision Collision
 	switch genreID {
 	case "fantasy":
-		interval = 0.3 // Slower dripping
+		// Slower dripping; drops vanish on the floor
+		interval = 0.3
+		collision = Collision{Mode: CollideDie}
 	case "scifi":
-		interval = 0.15 // Faster steam bursts
+		// Faster steam bursts; steam disperses at the ceiling
+		interval = 0.15
+		collision = Collision{Mode: CollideDie}
 	case "horror":
-		interval = 0.5 // Slow, ominous
+		// Slow, ominous; dust settles where it lands
+		interval = 0.5
+		collision = Collision{Mode: CollideStick}
 	case "cyberpunk":
-		interval = 0.1 // Rapid glitch/static
+		// Rapid glitch/static; holograms ignore the map
+		interval = 0.1
 	case "postapoc":
-		interval = 0.25 // Dust settling
+		// Dust settling
+		interval = 0.25
+		collision = Collision{Mode: CollideStick}
 	}
 
 	return &WeatherEmitter{
@@ -41,12 +68,65 @@ func NewWeatherEmitter(system *ParticleSystem, genreID string, x, y, width, heig
 		width:        width,
 		height:       height,
 		emitInterval: interval,
+		rate:         1.0,
+		collision:    collision,
+		intensity:    1.0,
+		target:       1.0,
 	}
 }
 
+// SetCollision sets how the emitted particles collide with the map.
+func (w *WeatherEmitter) SetCollision(c Collision) {
+	w.collision = c
+}
+
+// SetForces opts the emitted particles into external forces, e.g. the wind
+// field.
+func (w *WeatherEmitter) SetForces(forces ForceMask) {
+	w.forces = forces
+}
+
+// SetRate scales how often particles are emitted: 2 doubles the rate, 0
+// stops emission. Negative values count as 0.
+func (w *WeatherEmitter) SetRate(rate float64) {
+	w.rate = math.Max(rate, 0)
+}
+
+// Rate returns the emission rate multiplier.
+func (w *WeatherEmitter) Rate() float64 {
+	return w.rate
+}
+
+// SetMask limits emission to the tiles for which open reports true, e.g.
+// outdoor courtyards. nil emits everywhere.
+func (w *WeatherEmitter) SetMask(open func(x, y int) bool) {
+	w.open = open
+}
+
+// SetIntensity ramps the intensity to target over rampSeconds; 0 seconds
+// jumps immediately. Intensity scales emission like the rate, so 2 is twice
+// as heavy. Negative targets count as 0.
+func (w *WeatherEmitter) SetIntensity(target, rampSeconds float64) {
+	w.target = math.Max(target, 0)
+	if rampSeconds <= 0 {
+		w.intensity = w.target
+		w.rampRate = 0
+		return
+	}
+	w.rampRate = math.Abs(w.target-w.intensity) / rampSeconds
+}
+
+// Intensity returns the current intensity.
+func (w *WeatherEmitter) Intensity() float64 {
+	return w.intensity
+}
+
 // Update advances the weather emitter by deltaTime and spawns particles.
 func (w *WeatherEmitter) Update(deltaTime float64) {
-	w.emitTimer += deltaTime
+	w.updateIntensity(deltaTime)
+	w.updateStages(deltaTime)
+
+	w.emitTimer += deltaTime * w.rate * w.intensity
 
 	for w.emitTimer >= w.emitInterval {
 		w.emitTimer -= w.emitInterval
@@ -54,6 +134,38 @@ func (w *WeatherEmitter) Update(deltaTime float64) {
 	}
 }
 
+// updateIntensity moves the intensity toward its target.
+func (w *WeatherEmitter) updateIntensity(deltaTime float64) {
+	if w.intensity == w.target {
+		return
+	}
+	step := w.rampRate * deltaTime
+	if math.Abs(w.target-w.intensity) <= step {
+		w.intensity = w.target
+	} else if w.target > w.intensity {
+		w.intensity += step
+	} else {
+		w.intensity -= step
+	}
+}
+
+// weatherMaskAttempts is how many random positions emission tries before
+// giving up on a particle when a mask is set.
+const weatherMaskAttempts = 4
+
+// position returns a random emission position in the area, or false when
+// none of the tried positions is open to the sky.
+func (w *WeatherEmitter) position() (float64, float64, bool) {
+	for i := 0; i < weatherMaskAttempts; i++ {
+		x := w.x + (w.system.rng.Float64()-0.5)*w.width
+		y := w.y + (w.system.rng.Float64()-0.5)*w.height
+		if w.open == nil || w.open(int(math.Floor(x)), int(math.Floor(y))) {
+			return x, y, true
+		}
+	}
+	return 0, 0, false
+}
+
 // emit spawns genre-specific weather particles.
 func (w *WeatherEmitter) emit() {
 	switch w.genreID {
@@ -72,9 +184,10 @@ func (w *WeatherEmitter) emit() {
 
 // emitDrippingWater spawns water droplets for fantasy dungeons.
 func (w *WeatherEmitter) emitDrippingWater() {
-	// Random position along ceiling
-	x := w.x + (w.system.rng.Float64()-0.5)*w.width
-	y := w.y + (w.system.rng.Float64()-0.5)*w.height
+	x, y, ok := w.position()
+	if !ok {
+		return
+	}
 
 	// Water drops fall straight down with slight randomness
 	vx := (w.system.rng.Float64()*2 - 1) * 0.5
@@ -82,14 +195,15 @@ func (w *WeatherEmitter) emitDrippingWater() {
 	vz := -8.0 - w.system.rng.Float64()*2.0 // Fall speed
 
 	c := color.RGBA{R: 100, G: 150, B: 200, A: 180}
-	w.system.Spawn(x, y, 3.0, vx, vy, vz, 1.5, 0.3, c)
+	w.spawn(x, y, 3.0, vx, vy, vz, 1.5, 0.3, c)
 }
 
 // emitVentSteam spawns steam particles for sci-fi facilities.
 func (w *WeatherEmitter) emitVentSteam() {
-	// Random vent position
-	x := w.x + (w.system.rng.Float64()-0.5)*w.width
-	y := w.y + (w.system.rng.Float64()-0.5)*w.height
+	x, y, ok := w.position()
+	if !ok {
+		return
+	}
 
 	// Steam rises and spreads
 	angle := w.system.rng.Float64() * 2 * math.Pi
@@ -100,14 +214,15 @@ func (w *WeatherEmitter) emitVentSteam() {
 	vz := 3.0 + w.system.rng.Float64()*2.0 // Rise speed
 
 	c := color.RGBA{R: 200, G: 210, B: 220, A: 120}
-	w.system.Spawn(x, y, 0.5, vx, vy, vz, 2.5, 1.5, c)
+	w.spawn(x, y, 0.5, vx, vy, vz, 2.5, 1.5, c)
 }
 
 // emitFlickeringDust spawns ominous particles for horror settings.
 func (w *WeatherEmitter) emitFlickeringDust() {
-	// Random position
-	x := w.x + (w.system.rng.Float64()-0.5)*w.width
-	y := w.y + (w.system.rng.Float64()-0.5)*w.height
+	x, y, ok := w.position()
+	if !ok {
+		return
+	}
 
 	// Dust drifts slowly
 	vx := (w.system.rng.Float64()*2 - 1) * 0.8
@@ -115,14 +230,15 @@ func (w *WeatherEmitter) emitFlickeringDust() {
 	vz := -0.5 - w.system.rng.Float64()*0.5 // Slow fall
 
 	c := color.RGBA{R: 80, G: 80, B: 70, A: 100}
-	w.system.Spawn(x, y, 2.0, vx, vy, vz, 3.0, 0.6, c)
+	w.spawn(x, y, 2.0, vx, vy, vz, 3.0, 0.6, c)
 }
 
 // emitHolographicStatic spawns glitchy particles for cyberpunk environments.
 func (w *WeatherEmitter) emitHolographicStatic() {
-	// Random position
-	x := w.x + (w.system.rng.Float64()-0.5)*w.width
-	y := w.y + (w.system.rng.Float64()-0.5)*w.height
+	x, y, ok := w.position()
+	if !ok {
+		return
+	}
 
 	// Static particles have erratic movement
 	vx := (w.system.rng.Float64()*2 - 1) * 5.0
@@ -137,14 +253,15 @@ func (w *WeatherEmitter) emitHolographicStatic() {
 		c = color.RGBA{R: 0, G: 255, B: 255, A: 150}
 	}
 
-	w.system.Spawn(x, y, 1.0, vx, vy, vz, 0.3, 0.5, c)
+	w.spawn(x, y, 1.0, vx, vy, vz, 0.3, 0.5, c)
 }
 
 // emitFallingDust spawns dust particles for post-apocalyptic ruins.
 func (w *WeatherEmitter) emitFallingDust() {
-	// Random position
-	x := w.x + (w.system.rng.Float64()-0.5)*w.width
-	y := w.y + (w.system.rng.Float64()-0.5)*w.height
+	x, y, ok := w.position()
+	if !ok {
+		return
+	}
 
 	// Dust falls slowly and drifts
 	vx := (w.system.rng.Float64()*2 - 1) * 1.0
@@ -152,7 +269,15 @@ func (w *WeatherEmitter) emitFallingDust() {
 	vz := -1.5 - w.system.rng.Float64()*1.0
 
 	c := color.RGBA{R: 120, G: 100, B: 70, A: 130}
-	w.system.Spawn(x, y, 2.5, vx, vy, vz, 4.0, 0.8, c)
+	w.spawn(x, y, 2.5, vx, vy, vz, 4.0, 0.8, c)
+}
+
+// spawn emits one particle with the emitter's collision.
+func (w *WeatherEmitter) spawn(x, y, z, vx, vy, vz, life, size float64, c color.RGBA) {
+	if p := w.system.Spawn(x, y, z, vx, vy, vz, life, size, c); p != nil {
+		p.Collision = w.collision
+		p.Forces = w.forces
+	}
 }
 
 // FlickeringLightController manages light-flickering particle effects for horror ambience.
@@ -0,0 +1,78 @@
+package particle
+
+// WeatherStage is one step of a scripted weather progression, such as a
+// drizzle building into a storm.
+type WeatherStage struct {
+	Name      string
+	Intensity float64 // Target emission intensity
+	Ramp      float64 // Seconds to reach Intensity from the previous stage
+	Duration  float64 // Seconds spent in the stage, including the ramp
+}
+
+// DefaultWeatherStages is a drizzle that builds into a storm and clears.
+var DefaultWeatherStages = []WeatherStage{
+	{Name: "drizzle", Intensity: 0.4, Ramp: 10, Duration: 90},
+	{Name: "rain", Intensity: 1.0, Ramp: 20, Duration: 120},
+	{Name: "storm", Intensity: 2.0, Ramp: 15, Duration: 75},
+	{Name: "clearing", Intensity: 0.2, Ramp: 30, Duration: 90},
+}
+
+// SetStages starts a scripted progression through stages, beginning with the
+// first. With loop the progression restarts after the last stage; otherwise
+// it holds there. An empty list stops scripting and keeps the intensity.
+func (w *WeatherEmitter) SetStages(stages []WeatherStage, loop bool) {
+	w.stages = stages
+	w.loopStages = loop
+	w.stage = 0
+	w.stageTime = 0
+	if len(stages) > 0 {
+		w.enterStage()
+	}
+}
+
+// OnStageChange registers fn to be called whenever a stage begins, e.g. to
+// cue thunder or ambience for the new stage.
+func (w *WeatherEmitter) OnStageChange(fn func(WeatherStage)) {
+	w.onStage = fn
+}
+
+// Stage returns the current stage, or false without a progression.
+func (w *WeatherEmitter) Stage() (WeatherStage, bool) {
+	if len(w.stages) == 0 {
+		return WeatherStage{}, false
+	}
+	return w.stages[w.stage], true
+}
+
+// updateStages advances the progression by deltaTime.
+func (w *WeatherEmitter) updateStages(deltaTime float64) {
+	if len(w.stages) == 0 {
+		return
+	}
+	w.stageTime += deltaTime
+	for w.stageTime >= w.stages[w.stage].Duration {
+		next := w.stage + 1
+		if next == len(w.stages) {
+			if !w.loopStages {
+				w.stageTime = w.stages[w.stage].Duration
+				return
+			}
+			next = 0
+		}
+		w.stageTime -= w.stages[w.stage].Duration
+		w.stage = next
+		w.enterStage()
+		if w.stages[w.stage].Duration <= 0 {
+			return // Guard against a progression that never spends time
+		}
+	}
+}
+
+// enterStage starts the ramp to the current stage and reports it.
+func (w *WeatherEmitter) enterStage() {
+	s := w.stages[w.stage]
+	w.SetIntensity(s.Intensity, s.Ramp)
+	if w.onStage != nil {
+		w.onStage(s)
+	}
+}
@@ -1,6 +1,8 @@
 package particle
 
 import (
+	"fmt"
+	"math"
 	"testing"
 )
 
@@ -96,6 +98,35 @@ func TestWeatherEmitter_MultipleEmissions(t *testing.T) {
 	}
 }
 
+func TestWeatherEmitter_SetRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		wantRate float64
+		emits    bool
+	}{
+		{"stopped", 0, 0, false},
+		{"negative clamps to stopped", -1, 0, false},
+		{"doubled", 2, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := NewParticleSystem(200, 12345)
+			we := NewWeatherEmitter(ps, "scifi", 10, 10, 20, 20)
+			we.SetRate(tt.rate)
+			if we.Rate() != tt.wantRate {
+				t.Errorf("Rate() = %f, want %f", we.Rate(), tt.wantRate)
+			}
+
+			// 0.1s is below the 0.15s scifi interval at the normal rate.
+			we.Update(0.1)
+			if emitted := ps.GetActiveCount() > 0; emitted != tt.emits {
+				t.Errorf("emitted = %v, want %v", emitted, tt.emits)
+			}
+		})
+	}
+}
+
 func TestNewFlickeringLightController(t *testing.T) {
 	ps := NewParticleSystem(100, 12345)
 	flc := NewFlickeringLightController(ps, 25, 25, 5.0)
@@ -486,3 +517,141 @@ func BenchmarkDustParticleEmitter(b *testing.B) {
 		dpe.Update(0.001)
 	}
 }
+
+func TestWeatherEmitter_Collision(t *testing.T) {
+	ps := NewParticleSystem(64, 1)
+	ps.SetCollisionMap(func(x, y int) bool { return true })
+	w := NewWeatherEmitter(ps, "fantasy", 5, 5, 4, 4)
+	w.Update(1)
+	ps.Update(0.01)
+	if ps.GetActiveCount() != 0 {
+		t.Errorf("fantasy drips inside solid tiles: %d active, want 0", ps.GetActiveCount())
+	}
+
+	w.SetCollision(Collision{})
+	w.Update(1)
+	ps.Update(0.01)
+	if ps.GetActiveCount() == 0 {
+		t.Error("non-colliding drips were removed")
+	}
+}
+
+func TestWeatherEmitter_SetIntensity(t *testing.T) {
+	tests := []struct {
+		name   string
+		target float64
+		ramp   float64
+		step   float64
+		want   float64
+	}{
+		{"immediate", 2, 0, 0, 2},
+		{"halfway up", 2, 4, 2, 1.5},
+		{"reaches target", 2, 4, 10, 2},
+		{"halfway down", 0, 2, 1, 0.5},
+		{"negative clamps", -1, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			we := NewWeatherEmitter(NewParticleSystem(10, 1), "fantasy", 5, 5, 10, 10)
+			we.SetIntensity(tt.target, tt.ramp)
+			we.Update(tt.step)
+			if math.Abs(we.Intensity()-tt.want) > 1e-9 {
+				t.Errorf("Intensity() = %f, want %f", we.Intensity(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWeatherEmitter_IntensityScalesEmission(t *testing.T) {
+	count := func(intensity float64) int {
+		ps := NewParticleSystem(1000, 1)
+		we := NewWeatherEmitter(ps, "cyberpunk", 5, 5, 10, 10)
+		we.SetIntensity(intensity, 0)
+		we.Update(1.0)
+		return ps.GetActiveCount()
+	}
+	if calm, storm := count(0.5), count(2); storm < 3*calm {
+		t.Errorf("particles at intensity 2 = %d, want about 4x the %d at 0.5", storm, calm)
+	}
+	if n := count(0); n != 0 {
+		t.Errorf("particles at intensity 0 = %d, want 0", n)
+	}
+}
+
+func TestWeatherEmitter_Mask(t *testing.T) {
+	ps := NewParticleSystem(1000, 1)
+	we := NewWeatherEmitter(ps, "cyberpunk", 5, 5, 10, 10)
+	we.SetMask(func(x, y int) bool { return x >= 5 })
+	we.Update(2.0)
+
+	active := ps.GetActiveParticles()
+	if len(active) == 0 {
+		t.Fatal("no particles emitted over the open half")
+	}
+	for _, p := range active {
+		// Cyberpunk static moves at most 5 tiles/s and has not been updated.
+		if p.X < 5 {
+			t.Fatalf("particle spawned indoors at x = %f", p.X)
+		}
+	}
+
+	ps.Clear()
+	we.SetMask(func(x, y int) bool { return false })
+	we.Update(2.0)
+	if n := ps.GetActiveCount(); n != 0 {
+		t.Errorf("particles with everything indoors = %d, want 0", n)
+	}
+}
+
+func TestWeatherEmitter_Stages(t *testing.T) {
+	stages := []WeatherStage{
+		{Name: "drizzle", Intensity: 0.5, Ramp: 0, Duration: 2},
+		{Name: "storm", Intensity: 2, Ramp: 1, Duration: 3},
+	}
+	tests := []struct {
+		name      string
+		loop      bool
+		elapsed   float64
+		wantStage string
+		wantCues  []string
+	}{
+		{"first stage", false, 1, "drizzle", []string{"drizzle"}},
+		{"second stage", false, 2.5, "storm", []string{"drizzle", "storm"}},
+		{"holds last", false, 10, "storm", []string{"drizzle", "storm"}},
+		{"loops", true, 5.5, "drizzle", []string{"drizzle", "storm", "drizzle"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			we := NewWeatherEmitter(NewParticleSystem(10, 1), "fantasy", 5, 5, 10, 10)
+			var cues []string
+			we.OnStageChange(func(s WeatherStage) { cues = append(cues, s.Name) })
+			we.SetStages(stages, tt.loop)
+
+			for e := 0.0; e < tt.elapsed-1e-9; e += 0.5 {
+				we.Update(0.5)
+			}
+
+			stage, ok := we.Stage()
+			if !ok || stage.Name != tt.wantStage {
+				t.Errorf("Stage() = %q, %v; want %q", stage.Name, ok, tt.wantStage)
+			}
+			if fmt.Sprint(cues) != fmt.Sprint(tt.wantCues) {
+				t.Errorf("stage cues = %v, want %v", cues, tt.wantCues)
+			}
+		})
+	}
+}
+
+func TestWeatherEmitter_StageRamp(t *testing.T) {
+	we := NewWeatherEmitter(NewParticleSystem(10, 1), "fantasy", 5, 5, 10, 10)
+	we.SetStages([]WeatherStage{
+		{Name: "calm", Intensity: 0, Duration: 1},
+		{Name: "storm", Intensity: 2, Ramp: 2, Duration: 10},
+	}, false)
+
+	we.Update(1) // Enter the storm
+	we.Update(1) // Halfway through its ramp
+	if math.Abs(we.Intensity()-1) > 1e-9 {
+		t.Errorf("Intensity() halfway through the ramp = %f, want 1", we.Intensity())
+	}
+}
@@ -0,0 +1,22 @@
+// Package profiler provides a runtime frame-time profiler and its debug
+// overlay.
+//
+// The game records how long each render stage takes and how many things it
+// drew, then closes the frame with the total frame time. The profiler keeps
+// smoothed per-stage averages and a rolling window of frame times, and draws
+// them as text and a bar graph when the overlay is toggled on. Stage timing is
+// skipped while the overlay is hidden.
+//
+// Example usage:
+//
+//	prof := profiler.New()
+//	prof.Toggle()
+//
+//	start := time.Now()
+//	renderWorld(screen)
+//	prof.Since(profiler.StageRaycast, start)
+//	prof.Count("sprites", spritesDrawn)
+//	prof.EndFrame(time.Since(frameStart))
+//
+//	prof.Draw(screen)
+package profiler
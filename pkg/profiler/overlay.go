@@ -0,0 +1,83 @@
+package profiler
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Overlay layout.
+const (
+	overlayMargin = 4
+	lineHeight    = 13
+	graphHeight   = 40
+	barWidth      = 1
+
+	// graphScaleMS is the frame time at the top of the graph; slower frames
+	// are clipped.
+	graphScaleMS = 50.0
+	// targetFrameMS is the 60 FPS frame time, drawn as a reference line.
+	targetFrameMS = 1000.0 / 60
+	// slowFrameMS is the 30 FPS frame time; slower frames are drawn red.
+	slowFrameMS = 1000.0 / 30
+)
+
+var (
+	panelColor  = color.RGBA{R: 0, G: 0, B: 0, A: 170}
+	textColor   = color.RGBA{R: 220, G: 255, B: 220, A: 255}
+	fastColor   = color.RGBA{R: 80, G: 220, B: 80, A: 255}
+	okColor     = color.RGBA{R: 230, G: 200, B: 60, A: 255}
+	slowColor   = color.RGBA{R: 230, G: 60, B: 60, A: 255}
+	targetColor = color.RGBA{R: 255, G: 255, B: 255, A: 120}
+)
+
+// Draw renders the overlay in the top-left corner of screen when enabled.
+func (p *Profiler) Draw(screen *ebiten.Image) {
+	if !p.Enabled() {
+		return
+	}
+
+	lines := p.Lines()
+	width := float32(HistorySize*barWidth + 2*overlayMargin)
+	height := float32(len(lines)*lineHeight + graphHeight + 3*overlayMargin)
+	vector.DrawFilledRect(screen, 0, 0, width, height, panelColor, false)
+
+	y := overlayMargin + lineHeight - 3
+	for _, line := range lines {
+		text.Draw(screen, line, basicfont.Face7x13, overlayMargin, y, textColor)
+		y += lineHeight
+	}
+
+	p.drawGraph(screen, overlayMargin, float32(y))
+}
+
+// drawGraph draws the frame-time history as bars, newest on the right, with
+// a line at the 60 FPS frame time.
+func (p *Profiler) drawGraph(screen *ebiten.Image, x, top float32) {
+	bottom := top + graphHeight
+	history := p.History()
+	offset := float32((HistorySize - len(history)) * barWidth)
+	for i, ms := range history {
+		h := float32(min(ms/graphScaleMS, 1) * graphHeight)
+		bx := x + offset + float32(i*barWidth)
+		vector.DrawFilledRect(screen, bx, bottom-h, barWidth, h, barColor(ms), false)
+	}
+
+	ty := bottom - float32(targetFrameMS/graphScaleMS*graphHeight)
+	vector.StrokeLine(screen, x, ty, x+float32(HistorySize*barWidth), ty, 1, targetColor, false)
+}
+
+// barColor colors a frame by whether it met 60 FPS, 30 FPS or neither.
+func barColor(ms float64) color.RGBA {
+	switch {
+	case ms <= targetFrameMS:
+		return fastColor
+	case ms <= slowFrameMS:
+		return okColor
+	default:
+		return slowColor
+	}
+}
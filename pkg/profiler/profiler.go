@@ -0,0 +1,198 @@
+package profiler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Stage is a part of the frame the profiler times separately.
+type Stage int
+
+const (
+	StageRaycast     Stage = iota // StageRaycast is ray casting and the software framebuffer fill.
+	StageSprites                  // StageSprites is world sprites, decals and other entity overlays.
+	StageParticles                // StageParticles is particle, weather and dust mote drawing.
+	StagePostProcess              // StagePostProcess is framebuffer post-processing.
+	StageUI                       // StageUI is the HUD and screen overlays.
+	stageCount
+)
+
+// String returns the stage label shown in the overlay.
+func (s Stage) String() string {
+	switch s {
+	case StageRaycast:
+		return "raycast"
+	case StageSprites:
+		return "sprites"
+	case StageParticles:
+		return "particles"
+	case StagePostProcess:
+		return "postfx"
+	case StageUI:
+		return "ui"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// HistorySize is the number of frames in the rolling frame-time window.
+	HistorySize = 120
+
+	// averageSmoothing is the weight of the newest frame in stage averages.
+	averageSmoothing = 0.1
+)
+
+// Profiler accumulates per-stage timings and draw counts for the current
+// frame and keeps a rolling history of frame times.
+type Profiler struct {
+	enabled bool
+
+	stages  [stageCount]time.Duration // Current frame
+	average [stageCount]float64       // Smoothed milliseconds per stage
+	counts  map[string]int            // Current frame
+	shown   map[string]int            // Last completed frame
+
+	history [HistorySize]float64 // Frame times in milliseconds, ring buffer
+	next    int                  // Ring index of the next frame
+	frames  int                  // Frames recorded, up to HistorySize
+}
+
+// New creates a disabled profiler.
+func New() *Profiler {
+	return &Profiler{
+		counts: make(map[string]int),
+		shown:  make(map[string]int),
+	}
+}
+
+// Enabled reports whether the profiler is recording and its overlay shown.
+// A nil profiler is disabled, so callers need not check for one.
+func (p *Profiler) Enabled() bool {
+	return p != nil && p.enabled
+}
+
+// Toggle shows or hides the overlay. Turning it on starts from an empty
+// history so stale frames are not shown.
+func (p *Profiler) Toggle() {
+	p.enabled = !p.enabled
+	if p.enabled {
+		p.Reset()
+	}
+}
+
+// Reset clears all recorded timings, counts and history.
+func (p *Profiler) Reset() {
+	p.stages = [stageCount]time.Duration{}
+	p.average = [stageCount]float64{}
+	clear(p.counts)
+	clear(p.shown)
+	p.next, p.frames = 0, 0
+}
+
+// Add adds time spent in a stage during the current frame. A stage may be
+// added several times per frame.
+func (p *Profiler) Add(stage Stage, d time.Duration) {
+	if !p.Enabled() || stage < 0 || stage >= stageCount {
+		return
+	}
+	p.stages[stage] += d
+}
+
+// Since adds the time elapsed since start to a stage.
+func (p *Profiler) Since(stage Stage, start time.Time) {
+	if p.Enabled() {
+		p.Add(stage, time.Since(start))
+	}
+}
+
+// Count adds n to a named draw counter for the current frame.
+func (p *Profiler) Count(name string, n int) {
+	if p.Enabled() {
+		p.counts[name] += n
+	}
+}
+
+// EndFrame closes the current frame with its total time, folding the stage
+// timings into the averages and the frame time into the history.
+func (p *Profiler) EndFrame(frame time.Duration) {
+	if !p.Enabled() {
+		return
+	}
+	for i, d := range p.stages {
+		ms := float64(d) / float64(time.Millisecond)
+		if p.frames == 0 {
+			p.average[i] = ms
+		} else {
+			p.average[i] += (ms - p.average[i]) * averageSmoothing
+		}
+	}
+	p.stages = [stageCount]time.Duration{}
+
+	clear(p.shown)
+	for name, n := range p.counts {
+		p.shown[name] = n
+	}
+	clear(p.counts)
+
+	p.history[p.next] = float64(frame) / float64(time.Millisecond)
+	p.next = (p.next + 1) % HistorySize
+	p.frames = min(p.frames+1, HistorySize)
+}
+
+// StageAverage returns the smoothed time of a stage in milliseconds.
+func (p *Profiler) StageAverage(stage Stage) float64 {
+	if stage < 0 || stage >= stageCount {
+		return 0
+	}
+	return p.average[stage]
+}
+
+// History returns the recorded frame times in milliseconds, oldest first.
+func (p *Profiler) History() []float64 {
+	out := make([]float64, 0, p.frames)
+	start := (p.next - p.frames + HistorySize) % HistorySize
+	for i := 0; i < p.frames; i++ {
+		out = append(out, p.history[(start+i)%HistorySize])
+	}
+	return out
+}
+
+// FrameTimes returns the mean and worst frame time in the history, in
+// milliseconds.
+func (p *Profiler) FrameTimes() (mean, worst float64) {
+	h := p.History()
+	if len(h) == 0 {
+		return 0, 0
+	}
+	for _, ms := range h {
+		mean += ms
+		worst = max(worst, ms)
+	}
+	return mean / float64(len(h)), worst
+}
+
+// Lines returns the overlay text: frame times, one line per stage and the
+// draw counters of the last frame in name order.
+func (p *Profiler) Lines() []string {
+	mean, worst := p.FrameTimes()
+	fps := 0.0
+	if mean > 0 {
+		fps = 1000 / mean
+	}
+	lines := []string{fmt.Sprintf("frame %5.2fms  max %5.2fms  %3.0f fps", mean, worst, fps)}
+	for s := Stage(0); s < stageCount; s++ {
+		lines = append(lines, fmt.Sprintf("%-9s %5.2fms", s, p.average[s]))
+	}
+
+	names := make([]string, 0, len(p.shown))
+	for name := range p.shown {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%-9s %5d", name, p.shown[name]))
+	}
+	return lines
+}
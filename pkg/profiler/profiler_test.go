@@ -0,0 +1,163 @@
+package profiler
+
+import (
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStageString(t *testing.T) {
+	tests := []struct {
+		stage Stage
+		want  string
+	}{
+		{StageRaycast, "raycast"},
+		{StageSprites, "sprites"},
+		{StageParticles, "particles"},
+		{StagePostProcess, "postfx"},
+		{StageUI, "ui"},
+		{Stage(99), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.stage.String(); got != tt.want {
+			t.Errorf("Stage(%d).String() = %q, want %q", tt.stage, got, tt.want)
+		}
+	}
+}
+
+func TestDisabledProfilerRecordsNothing(t *testing.T) {
+	p := New()
+	p.Add(StageRaycast, 5*time.Millisecond)
+	p.Count("sprites", 3)
+	p.EndFrame(16 * time.Millisecond)
+
+	if got := p.StageAverage(StageRaycast); got != 0 {
+		t.Errorf("StageAverage = %v, want 0 while disabled", got)
+	}
+	if h := p.History(); len(h) != 0 {
+		t.Errorf("History has %d frames, want 0 while disabled", len(h))
+	}
+}
+
+func TestStageAverages(t *testing.T) {
+	p := New()
+	p.Toggle()
+
+	// Stage times added several times in a frame are summed.
+	p.Add(StageRaycast, 2*time.Millisecond)
+	p.Add(StageRaycast, 3*time.Millisecond)
+	p.EndFrame(10 * time.Millisecond)
+	if got := p.StageAverage(StageRaycast); math.Abs(got-5) > 1e-9 {
+		t.Fatalf("first frame average = %v, want 5", got)
+	}
+
+	// Later frames move the average toward the new value.
+	p.Add(StageRaycast, 15*time.Millisecond)
+	p.EndFrame(20 * time.Millisecond)
+	if got := p.StageAverage(StageRaycast); math.Abs(got-6) > 1e-9 {
+		t.Errorf("second frame average = %v, want 6", got)
+	}
+	if got := p.StageAverage(StageUI); got != 0 {
+		t.Errorf("unused stage average = %v, want 0", got)
+	}
+}
+
+func TestHistoryWraps(t *testing.T) {
+	p := New()
+	p.Toggle()
+	for i := 1; i <= HistorySize+5; i++ {
+		p.EndFrame(time.Duration(i) * time.Millisecond)
+	}
+
+	h := p.History()
+	if len(h) != HistorySize {
+		t.Fatalf("History has %d frames, want %d", len(h), HistorySize)
+	}
+	if h[0] != 6 || h[len(h)-1] != HistorySize+5 {
+		t.Errorf("History spans %v..%v, want 6..%d oldest first", h[0], h[len(h)-1], HistorySize+5)
+	}
+
+	mean, worst := p.FrameTimes()
+	if worst != HistorySize+5 {
+		t.Errorf("worst = %v, want %d", worst, HistorySize+5)
+	}
+	if want := float64(6+HistorySize+5) / 2; math.Abs(mean-want) > 1e-9 {
+		t.Errorf("mean = %v, want %v", mean, want)
+	}
+}
+
+func TestToggleResets(t *testing.T) {
+	p := New()
+	p.Toggle()
+	p.Add(StageUI, time.Millisecond)
+	p.EndFrame(time.Millisecond)
+	p.Toggle()
+	if p.Enabled() {
+		t.Fatal("profiler still enabled after second toggle")
+	}
+	p.Toggle()
+	if len(p.History()) != 0 || p.StageAverage(StageUI) != 0 {
+		t.Error("re-enabling did not clear previous recordings")
+	}
+}
+
+func TestLines(t *testing.T) {
+	p := New()
+	p.Toggle()
+	p.Add(StageSprites, 4*time.Millisecond)
+	p.Count("sprites", 7)
+	p.Count("particles", 30)
+	p.Count("sprites", 1)
+	p.EndFrame(10 * time.Millisecond)
+
+	lines := p.Lines()
+	if want := 1 + int(stageCount) + 2; len(lines) != want {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), want, lines)
+	}
+	if !strings.Contains(lines[0], "100 fps") {
+		t.Errorf("summary line %q does not report 100 fps", lines[0])
+	}
+	if !strings.HasPrefix(lines[1+int(StageSprites)], "sprites") || !strings.Contains(lines[1+int(StageSprites)], "4.00ms") {
+		t.Errorf("sprites stage line = %q", lines[1+int(StageSprites)])
+	}
+
+	// Counters follow the stages in name order and show the last frame.
+	counters := lines[1+int(stageCount):]
+	if !strings.HasPrefix(counters[0], "particles") || !strings.HasSuffix(counters[0], " 30") {
+		t.Errorf("first counter line = %q, want particles 30", counters[0])
+	}
+	if !strings.HasPrefix(counters[1], "sprites") || !strings.HasSuffix(counters[1], " 8") {
+		t.Errorf("second counter line = %q, want sprites 8", counters[1])
+	}
+}
+
+func TestBarColor(t *testing.T) {
+	tests := []struct {
+		ms   float64
+		want string
+	}{
+		{10, "fast"},
+		{targetFrameMS, "fast"},
+		{25, "ok"},
+		{40, "slow"},
+	}
+	colors := map[string]color.RGBA{"fast": fastColor, "ok": okColor, "slow": slowColor}
+	for _, tt := range tests {
+		if got := barColor(tt.ms); got != colors[tt.want] {
+			t.Errorf("barColor(%v) = %v, want %s", tt.ms, got, tt.want)
+		}
+	}
+}
+
+func TestNilProfilerIsDisabled(t *testing.T) {
+	var p *Profiler
+	p.Add(StageUI, time.Millisecond)
+	p.Since(StageUI, time.Now())
+	p.Count("sprites", 1)
+	p.EndFrame(time.Millisecond)
+	if p.Enabled() {
+		t.Error("nil profiler reports enabled")
+	}
+}
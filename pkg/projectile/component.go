@@ -28,6 +28,7 @@ type ProjectileComponent struct {
 	PierceCount     int          // How many entities it can pierce through (-1 = infinite)
 	Color           color.RGBA   // Visual color
 	TrailParticles  bool         // Whether to spawn trail particles
+	TrailID         int          // Ribbon trail handle, 0 when none
 	ExplodeOnDeath  bool         // Whether to create AoE explosion on impact
 	ExplosionRadius float64      // Radius of explosion if ExplodeOnDeath is true
 	HitEntities     map[int]bool // Track hit entities for pierce mechanics
@@ -0,0 +1,226 @@
+package projectile
+
+import (
+	"image/color"
+	"math"
+)
+
+// sweepStep is the longest stretch of flight swept at once, so fast shots
+// can't skip through a wall corner or past a target.
+const sweepStep = 0.1
+
+// Flight is a player projectile in the air. Pure data - Manager flies it.
+type Flight struct {
+	Slot       int     // Arsenal slot of the weapon that fired it
+	X, Y, Z    float64 // Z is height above the floor in tiles; the ceiling is at 1
+	VelX, VelY float64 // Tiles per second
+	VelZ       float64
+	Gravity    float64 // Downward pull in tiles per second squared; 0 flies straight
+	Radius     float64 // Collision radius in tiles
+	Damage     float64
+	Splash     float64 // Explosion radius in tiles; 0 damages only what it hits
+	Range      float64 // Tiles left to fly before it fizzles
+	Color      color.RGBA
+	trailID    int
+}
+
+// Target is something a flight can hit, identified by the caller.
+type Target struct {
+	ID     uint64
+	X, Y   float64
+	Radius float64
+}
+
+// Strike is the damage an impact dealt to one target.
+type Strike struct {
+	Target uint64
+	Damage float64
+	Direct bool // Struck by the flight itself rather than its explosion
+}
+
+// Impact is a flight ending against a target, a wall, the floor or the
+// ceiling.
+type Impact struct {
+	Flight  Flight // The flight where it ended
+	Target  uint64 // Target struck directly; 0 for level geometry
+	Strikes []Strike
+}
+
+// Manager flies player projectiles along straight or ballistic paths and
+// resolves where they land. Unlike System it works outside the ECS world,
+// against whatever targets the caller passes each update.
+type Manager struct {
+	flights      []Flight
+	trailEmitter TrailEmitter
+	lightEmitter LightEmitter
+}
+
+// NewManager creates an empty projectile manager.
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// SetTrailEmitter configures ribbon trails behind flights.
+func (m *Manager) SetTrailEmitter(emitter TrailEmitter) {
+	m.trailEmitter = emitter
+}
+
+// SetLightEmitter configures explosion light impulses.
+func (m *Manager) SetLightEmitter(emitter LightEmitter) {
+	m.lightEmitter = emitter
+}
+
+// Spawn puts a flight in the air.
+func (m *Manager) Spawn(f Flight) {
+	f.trailID = 0
+	m.flights = append(m.flights, f)
+}
+
+// Flights returns a copy of the flights in the air.
+func (m *Manager) Flights() []Flight {
+	return append([]Flight(nil), m.flights...)
+}
+
+// Clear removes every flight, e.g. when the level changes.
+func (m *Manager) Clear() {
+	for i := range m.flights {
+		m.endTrail(&m.flights[i])
+	}
+	m.flights = m.flights[:0]
+}
+
+// Update advances every flight by dt seconds and returns the impacts.
+// blocked reports whether a point is inside a wall; nil lets flights pass
+// through walls. Flights that land or run out of range are removed.
+func (m *Manager) Update(dt float64, blocked func(x, y float64) bool, targets []Target) []Impact {
+	var impacts []Impact
+	kept := m.flights[:0]
+	for _, f := range m.flights {
+		if imp, landed := f.fly(dt, blocked, targets); landed {
+			m.endTrail(&f)
+			m.explode(f)
+			impacts = append(impacts, imp)
+			continue
+		}
+		if f.Range <= 0 {
+			m.endTrail(&f)
+			continue
+		}
+		m.extendTrail(&f)
+		kept = append(kept, f)
+	}
+	m.flights = kept
+	return impacts
+}
+
+// fly moves a flight through dt seconds in steps of at most sweepStep,
+// sweeping each step's path against the targets before testing the level,
+// and reports the impact it ended in.
+func (f *Flight) fly(dt float64, blocked func(x, y float64) bool, targets []Target) (Impact, bool) {
+	dist := math.Hypot(f.VelX, f.VelY) * dt
+	steps := max(int(math.Ceil(dist/sweepStep)), 1)
+	h := dt / float64(steps)
+	for i := 0; i < steps; i++ {
+		x0, y0 := f.X, f.Y
+		f.X += f.VelX * h
+		f.Y += f.VelY * h
+		f.Z += f.VelZ*h - 0.5*f.Gravity*h*h
+		f.VelZ -= f.Gravity * h
+		f.Range -= math.Hypot(f.X-x0, f.Y-y0)
+
+		if t, ok := f.sweep(x0, y0, targets); ok {
+			f.X, f.Y = t.X, t.Y
+			return f.impact(t.ID, targets), true
+		}
+		if blocked != nil && blocked(f.X, f.Y) {
+			// Burst in front of the wall, not inside it.
+			f.X, f.Y = x0, y0
+			return f.impact(0, targets), true
+		}
+		if f.Z <= 0 || f.Z >= 1 {
+			f.Z = math.Max(0, math.Min(f.Z, 1))
+			return f.impact(0, targets), true
+		}
+		if f.Range <= 0 {
+			break
+		}
+	}
+	return Impact{}, false
+}
+
+// sweep returns the first target the flight's path from (x0, y0) to its
+// position passes within reach of, with X and Y set to the point of
+// contact on the path.
+func (f *Flight) sweep(x0, y0 float64, targets []Target) (Target, bool) {
+	dx, dy := f.X-x0, f.Y-y0
+	lenSq := dx*dx + dy*dy
+	best, bestT, found := Target{}, math.Inf(1), false
+	for _, t := range targets {
+		along := 0.0
+		if lenSq > 0 {
+			along = math.Max(0, math.Min(((t.X-x0)*dx+(t.Y-y0)*dy)/lenSq, 1))
+		}
+		px, py := x0+dx*along, y0+dy*along
+		if math.Hypot(t.X-px, t.Y-py) > t.Radius+f.Radius || along >= bestT {
+			continue
+		}
+		best, bestT, found = t, along, true
+		best.X, best.Y = px, py
+	}
+	return best, found
+}
+
+// impact ends a flight where it is. The target struck directly takes full
+// damage; an explosive flight also strikes every other target within its
+// splash radius, with damage falling off linearly to nothing at the edge.
+func (f *Flight) impact(direct uint64, targets []Target) Impact {
+	imp := Impact{Flight: *f, Target: direct}
+	if direct != 0 {
+		imp.Strikes = append(imp.Strikes, Strike{Target: direct, Damage: f.Damage, Direct: true})
+	}
+	if f.Splash <= 0 {
+		return imp
+	}
+	for _, t := range targets {
+		if t.ID == direct {
+			continue
+		}
+		d := math.Max(math.Hypot(t.X-f.X, t.Y-f.Y)-t.Radius, 0)
+		if d >= f.Splash {
+			continue
+		}
+		imp.Strikes = append(imp.Strikes, Strike{Target: t.ID, Damage: f.Damage * (1 - d/f.Splash)})
+	}
+	return imp
+}
+
+// explode flashes a light where an explosive flight landed.
+func (m *Manager) explode(f Flight) {
+	if m.lightEmitter == nil || f.Splash <= 0 {
+		return
+	}
+	m.lightEmitter.EmitLightImpulse(f.X, f.Y, math.Max(f.Splash*explosionLightRange, 2.0),
+		explosionLightIntensity, f.Color, explosionLightDuration)
+}
+
+// extendTrail records the flight's position in its ribbon trail, starting
+// the trail on first use. Explosive flights leave smoke.
+func (m *Manager) extendTrail(f *Flight) {
+	if m.trailEmitter == nil || m.trailEmitter.Extend(f.trailID, f.X, f.Y) {
+		return
+	}
+	if f.Splash > 0 {
+		f.trailID = m.trailEmitter.StartTrail(smokeTrailWidth, smokeTrailFade, smokeTrailColor)
+	} else {
+		f.trailID = m.trailEmitter.StartTrail(streakTrailWidth, streakTrailFade, f.Color)
+	}
+	m.trailEmitter.Extend(f.trailID, f.X, f.Y)
+}
+
+// endTrail leaves the flight's trail to fade out.
+func (m *Manager) endTrail(f *Flight) {
+	if m.trailEmitter != nil && f.trailID != 0 {
+		m.trailEmitter.End(f.trailID)
+		f.trailID = 0
+	}
+}
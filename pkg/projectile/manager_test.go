@@ -0,0 +1,130 @@
+package projectile
+
+import (
+	"math"
+	"testing"
+)
+
+// wallAt blocks every point at or beyond x.
+func wallAt(x float64) func(float64, float64) bool {
+	return func(px, _ float64) bool { return px >= x }
+}
+
+func TestManagerLinearFlight(t *testing.T) {
+	m := NewManager()
+	m.Spawn(Flight{X: 1, Y: 1, Z: 0.5, VelX: 10, Radius: 0.1, Damage: 40, Range: 20})
+
+	if impacts := m.Update(0.5, nil, nil); len(impacts) != 0 {
+		t.Fatalf("impacts in open air = %+v", impacts)
+	}
+	f := m.Flights()[0]
+	if math.Abs(f.X-6) > 1e-9 || f.Y != 1 || f.Z != 0.5 || math.Abs(f.Range-15) > 1e-9 {
+		t.Errorf("flight after 0.5s = %+v, want at (6, 1, 0.5) with 15 range left", f)
+	}
+
+	m.Update(2, nil, nil)
+	if len(m.Flights()) != 0 {
+		t.Error("flight outlived its range")
+	}
+}
+
+func TestManagerBallisticFlight(t *testing.T) {
+	m := NewManager()
+	m.Spawn(Flight{X: 1, Y: 1, Z: 0.5, VelX: 10, Gravity: 4, Radius: 0.1, Damage: 40, Range: 100})
+
+	// Dropping 0.5 tiles under a pull of 4 takes 0.5s.
+	var impacts []Impact
+	for i := 0; i < 60 && len(impacts) == 0; i++ {
+		impacts = m.Update(1.0/60, nil, nil)
+	}
+	if len(impacts) != 1 {
+		t.Fatal("lobbed flight never landed")
+	}
+	imp := impacts[0]
+	if imp.Target != 0 || imp.Flight.Z != 0 || math.Abs(imp.Flight.X-6) > 0.2 {
+		t.Errorf("landed at %+v, want on the floor near x=6", imp.Flight)
+	}
+}
+
+func TestManagerSweptTargetHit(t *testing.T) {
+	m := NewManager()
+	m.Spawn(Flight{X: 1, Y: 1, Z: 0.5, VelX: 60, Radius: 0.1, Damage: 40, Range: 100})
+	targets := []Target{
+		{ID: 2, X: 8, Y: 1.2, Radius: 0.3},
+		{ID: 1, X: 4, Y: 1.1, Radius: 0.3},
+		{ID: 3, X: 3, Y: 3, Radius: 0.3},
+	}
+
+	// One update flies 30 tiles, far past both targets in the path.
+	impacts := m.Update(0.5, wallAt(20), targets)
+	if len(impacts) != 1 {
+		t.Fatalf("impacts = %+v, want 1", impacts)
+	}
+	imp := impacts[0]
+	if imp.Target != 1 || len(imp.Strikes) != 1 || imp.Strikes[0] != (Strike{Target: 1, Damage: 40, Direct: true}) {
+		t.Errorf("impact = %+v, want a direct strike on the nearest target in the path", imp)
+	}
+	if x := imp.Flight.X; x > 4 || x < 4-0.4-sweepStep {
+		t.Errorf("impact at x=%v, want on the target's near edge", x)
+	}
+	if len(m.Flights()) != 0 {
+		t.Error("flight survived its impact")
+	}
+}
+
+func TestManagerWallImpact(t *testing.T) {
+	m := NewManager()
+	m.Spawn(Flight{X: 1, Y: 1, Z: 0.5, VelX: 50, Radius: 0.1, Damage: 40, Range: 100})
+
+	impacts := m.Update(1, wallAt(5), nil)
+	if len(impacts) != 1 || impacts[0].Target != 0 || len(impacts[0].Strikes) != 0 {
+		t.Fatalf("impacts = %+v, want one against the wall", impacts)
+	}
+	if x := impacts[0].Flight.X; x >= 5 || x < 5-sweepStep-1e-9 {
+		t.Errorf("wall impact at x=%v, want just in front of the wall", x)
+	}
+}
+
+func TestManagerExplosionFalloff(t *testing.T) {
+	lights := &mockLightEmitter{}
+	trails := &mockTrailEmitter{}
+	m := NewManager()
+	m.SetLightEmitter(lights)
+	m.SetTrailEmitter(trails)
+	m.Spawn(Flight{X: 1, Y: 1, Z: 0.5, VelX: 10, Radius: 0.1, Damage: 100, Splash: 2, Range: 100})
+	targets := []Target{
+		{ID: 1, X: 3.5, Y: 3, Radius: 0.5}, // 1 tile from the blast past its radius
+		{ID: 2, X: 3.5, Y: 6, Radius: 0.5}, // Out of reach
+	}
+
+	m.Update(0.05, wallAt(4), targets)
+	impacts := m.Update(0.5, wallAt(4), targets)
+	if len(impacts) != 1 {
+		t.Fatalf("impacts = %+v, want 1", impacts)
+	}
+	imp := impacts[0]
+	d := math.Hypot(3.5-imp.Flight.X, 2) - 0.5
+	want := Strike{Target: 1, Damage: 100 * (1 - d/2)}
+	if len(imp.Strikes) != 1 || imp.Strikes[0].Target != 1 || math.Abs(imp.Strikes[0].Damage-want.Damage) > 1e-9 {
+		t.Errorf("strikes = %+v, want %+v", imp.Strikes, want)
+	}
+	if lights.count != 1 || lights.radius != 2*explosionLightRange {
+		t.Errorf("explosion lights = %d of radius %v, want 1 of %v", lights.count, lights.radius, 2*explosionLightRange)
+	}
+	if trails.width != smokeTrailWidth || trails.points == 0 || len(trails.ended) != 1 {
+		t.Errorf("trail = %+v, want a smoke trail ended on impact", trails)
+	}
+}
+
+func TestManagerClear(t *testing.T) {
+	trails := &mockTrailEmitter{}
+	m := NewManager()
+	m.SetTrailEmitter(trails)
+	m.Spawn(Flight{X: 1, Y: 1, Z: 0.5, VelX: 10, Range: 100})
+	m.Update(0.1, nil, nil)
+
+	m.Clear()
+	if len(m.Flights()) != 0 || len(trails.ended) != 1 {
+		t.Errorf("after Clear flights = %d, ended trails = %v", len(m.Flights()), trails.ended)
+	}
+}
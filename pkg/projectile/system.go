@@ -31,12 +31,46 @@ type DamageVisualProvider interface {
 	ApplyDamageVisual(w *engine.World, entity engine.Entity, damageTypeName string, damage, x, y float64)
 }
 
+// LightEmitter interface for short-lived explosion lights.
+type LightEmitter interface {
+	EmitLightImpulse(x, y, radius, intensity float64, col color.RGBA, duration float64)
+}
+
+// TrailEmitter interface for ribbon trails behind projectiles. IDs are
+// opaque handles; 0 means no trail.
+type TrailEmitter interface {
+	StartTrail(width, fade float64, col color.RGBA) int
+	Extend(id int, x, y float64) bool
+	End(id int)
+}
+
+// Ribbon trail tuning. Explosive projectiles leave lingering smoke; the rest
+// leave a thin streak of their damage color.
+const (
+	smokeTrailWidth  = 0.25
+	smokeTrailFade   = 0.9
+	streakTrailWidth = 0.1
+	streakTrailFade  = 0.25
+)
+
+// smokeTrailColor is the color of explosive projectile trails.
+var smokeTrailColor = color.RGBA{R: 150, G: 150, B: 150, A: 160}
+
+// Explosion light tuning.
+const (
+	explosionLightIntensity = 2.0
+	explosionLightDuration  = 0.35
+	explosionLightRange     = 2.5 // Light radius per unit of explosion radius
+)
+
 // System handles projectile movement, collision, and damage application.
 type System struct {
 	spatialGrid          SpatialGrid
 	particleSpawner      ParticleSpawner
 	feedbackProvider     FeedbackProvider
 	damageVisualProvider DamageVisualProvider
+	lightEmitter         LightEmitter
+	trailEmitter         TrailEmitter
 	logger               *logrus.Entry
 }
 
@@ -69,6 +103,17 @@ func (s *System) SetDamageVisualProvider(provider DamageVisualProvider) {
 	s.damageVisualProvider = provider
 }
 
+// SetLightEmitter connects the lighting system for explosion flashes.
+func (s *System) SetLightEmitter(emitter LightEmitter) {
+	s.lightEmitter = emitter
+}
+
+// SetTrailEmitter connects the ribbon trail system. While set, projectiles
+// leave ribbon trails instead of trail particles.
+func (s *System) SetTrailEmitter(emitter TrailEmitter) {
+	s.trailEmitter = emitter
+}
+
 // Update processes all projectile entities.
 func (s *System) Update(w *engine.World) {
 	deltaTime := common.DeltaTime
@@ -109,7 +154,7 @@ func (s *System) Update(w *engine.World) {
 		}
 
 		// Spawn trail particles
-		if proj.TrailParticles && s.particleSpawner != nil {
+		if proj.TrailParticles && s.trailEmitter == nil && s.particleSpawner != nil {
 			s.spawnTrailParticles(pos.X, pos.Y, proj)
 		}
 
@@ -117,6 +162,10 @@ func (s *System) Update(w *engine.World) {
 		pos.X += proj.VelX * deltaTime
 		pos.Y += proj.VelY * deltaTime
 
+		if proj.TrailParticles && s.trailEmitter != nil {
+			s.extendTrail(pos.X, pos.Y, proj)
+		}
+
 		// Check collisions
 		s.checkCollisions(w, entity, proj, pos.X, pos.Y, &toRemove)
 	}
@@ -138,6 +187,20 @@ func (s *System) spawnTrailParticles(x, y float64, proj *ProjectileComponent) {
 	s.particleSpawner.SpawnBurst(x, y, 0, 1, 0.5, 0.3, 0.2, 0.0, proj.Color)
 }
 
+// extendTrail records the projectile's position in its ribbon trail,
+// starting the trail on first use.
+func (s *System) extendTrail(x, y float64, proj *ProjectileComponent) {
+	if s.trailEmitter.Extend(proj.TrailID, x, y) {
+		return
+	}
+	if proj.ExplodeOnDeath {
+		proj.TrailID = s.trailEmitter.StartTrail(smokeTrailWidth, smokeTrailFade, smokeTrailColor)
+	} else {
+		proj.TrailID = s.trailEmitter.StartTrail(streakTrailWidth, streakTrailFade, proj.Color)
+	}
+	s.trailEmitter.Extend(proj.TrailID, x, y)
+}
+
 // checkCollisions performs collision detection and damage application.
 func (s *System) checkCollisions(w *engine.World, entity engine.Entity, proj *ProjectileComponent, x, y float64, toRemove *[]engine.Entity) {
 	if s.spatialGrid == nil {
@@ -264,6 +327,12 @@ func (s *System) applyDamage(w *engine.World, target engine.Entity, proj *Projec
 
 // handleProjectileDeath handles projectile expiration or destruction.
 func (s *System) handleProjectileDeath(w *engine.World, entity engine.Entity, proj *ProjectileComponent, x, y float64) {
+	// Leave the trail to fade out
+	if s.trailEmitter != nil && proj.TrailID != 0 {
+		s.trailEmitter.End(proj.TrailID)
+		proj.TrailID = 0
+	}
+
 	// Handle explosion
 	if proj.ExplodeOnDeath && proj.ExplosionRadius > 0 {
 		s.createExplosion(w, entity, proj, x, y)
@@ -281,16 +350,20 @@ func (s *System) createExplosion(w *engine.World, entity engine.Entity, proj *Pr
 		return
 	}
 
-	s.spawnExplosionEffects(x, y, proj.Color)
+	s.spawnExplosionEffects(x, y, proj.ExplosionRadius, proj.Color)
 	nearby := s.spatialGrid.QueryRadius(x, y, proj.ExplosionRadius)
 	s.applyExplosionDamageToTargets(w, entity, proj, x, y, nearby)
 }
 
 // spawnExplosionEffects creates visual and feedback effects for an explosion.
-func (s *System) spawnExplosionEffects(x, y float64, color color.RGBA) {
+func (s *System) spawnExplosionEffects(x, y, radius float64, color color.RGBA) {
 	if s.particleSpawner != nil {
 		s.particleSpawner.SpawnBurst(x, y, 0, 30, 6.0, 1.0, 0.5, 0.2, color)
 	}
+	if s.lightEmitter != nil {
+		s.lightEmitter.EmitLightImpulse(x, y, math.Max(radius*explosionLightRange, 2.0),
+			explosionLightIntensity, color, explosionLightDuration)
+	}
 	if s.feedbackProvider != nil {
 		s.feedbackProvider.AddScreenShake(3.0)
 	}
@@ -40,6 +40,40 @@ func (m *mockFeedbackProvider) AddHitFlash(intensity float64) {
 	m.flashCount++
 }
 
+// Mock light emitter
+type mockLightEmitter struct {
+	radius float64
+	count  int
+}
+
+func (m *mockLightEmitter) EmitLightImpulse(x, y, radius, intensity float64, col color.RGBA, duration float64) {
+	m.radius = radius
+	m.count++
+}
+
+type mockTrailEmitter struct {
+	width  float64
+	points int
+	ended  []int
+}
+
+func (m *mockTrailEmitter) StartTrail(width, fade float64, col color.RGBA) int {
+	m.width = width
+	return 1
+}
+
+func (m *mockTrailEmitter) Extend(id int, x, y float64) bool {
+	if id != 1 {
+		return false
+	}
+	m.points++
+	return true
+}
+
+func (m *mockTrailEmitter) End(id int) {
+	m.ended = append(m.ended, id)
+}
+
 func TestSystem_Update_Movement(t *testing.T) {
 	sys := NewSystem()
 	w := engine.NewWorld()
@@ -187,6 +221,9 @@ func TestSystem_Update_Explosion(t *testing.T) {
 	feedback := &mockFeedbackProvider{}
 	sys.SetFeedbackProvider(feedback)
 
+	lights := &mockLightEmitter{}
+	sys.SetLightEmitter(lights)
+
 	projectileEntity := w.AddEntity()
 	proj := NewProjectileComponent(5.0, 0.0, 50.0, DamageFire, 99)
 	proj.Lifetime = 0.01 // Short lifetime to trigger death
@@ -214,6 +251,57 @@ func TestSystem_Update_Explosion(t *testing.T) {
 	if feedback.shakeCount == 0 {
 		t.Error("Explosion should trigger screen shake")
 	}
+
+	// Explosion should light the area
+	if lights.count != 1 || lights.radius < proj.ExplosionRadius {
+		t.Errorf("explosion lights = %d with radius %f, want 1 covering the blast", lights.count, lights.radius)
+	}
+}
+
+func TestSystem_Update_RibbonTrail(t *testing.T) {
+	tests := []struct {
+		name      string
+		explosive bool
+		wantWidth float64
+	}{
+		{"streak", false, streakTrailWidth},
+		{"smoke", true, smokeTrailWidth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sys := NewSystem()
+			w := engine.NewWorld()
+			particles := &mockParticleSpawner{}
+			sys.SetParticleSpawner(particles)
+			trails := &mockTrailEmitter{}
+			sys.SetTrailEmitter(trails)
+
+			entity := w.AddEntity()
+			proj := NewProjectileComponent(5.0, 0.0, 10.0, DamageFire, 99)
+			proj.ExplodeOnDeath = tt.explosive
+			w.AddComponent(entity, proj)
+			w.AddComponent(entity, &engine.Position{})
+
+			sys.Update(w)
+			sys.Update(w)
+
+			if proj.TrailID != 1 || trails.points != 2 {
+				t.Fatalf("TrailID = %d with %d points, want 1 and 2", proj.TrailID, trails.points)
+			}
+			if trails.width != tt.wantWidth {
+				t.Errorf("trail width = %f, want %f", trails.width, tt.wantWidth)
+			}
+			if particles.spawnCount != 0 {
+				t.Errorf("trail particles spawned = %d, want 0 with ribbon trails", particles.spawnCount)
+			}
+
+			proj.Lifetime = 0
+			sys.Update(w)
+			if len(trails.ended) != 1 || trails.ended[0] != 1 {
+				t.Errorf("ended trails = %v, want [1]", trails.ended)
+			}
+		})
+	}
 }
 
 func TestSystem_Update_Resistance(t *testing.T) {
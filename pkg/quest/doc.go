@@ -30,4 +30,23 @@
 //	if tracker.AllComplete() {
 //	    fmt.Println("All objectives done!")
 //	}
+//
+// # Campaign Graphs
+//
+// A Graph chains quests across levels, with prerequisites, exclusive
+// branches and failure states:
+//
+//	campaign := quest.GenerateCampaign(seed, 8, "scifi")
+//	campaign.Evaluate(1)          // unlock the first level's quests
+//	campaign.Record("enemy", 1)   // a kill; returns the transitions
+//	campaign.Evaluate(2)          // descend, failing bounties left behind
+//
+// # Meta-Quests
+//
+// A MetaBook holds quests spanning runs, kept in the player's profile.
+// Saved progress is folded in by each quest's MergeRule:
+//
+//	book := quest.NewMetaBook([]quest.MetaQuest{quest.KillMetaQuest("cult", "cultists", 100)})
+//	book.Merge(saved)                      // progress from the profile
+//	done := book.Record("enemy:cult", "", 1)
 package quest
@@ -0,0 +1,407 @@
+package quest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// ErrInvalidGraph is returned when a quest graph cannot be evaluated.
+var ErrInvalidGraph = errors.New("invalid quest graph")
+
+// NodeState is where a quest node stands.
+type NodeState int
+
+const (
+	NodeLocked    NodeState = iota // NodeLocked waits on its prerequisites or level.
+	NodeActive                     // NodeActive has objectives to make progress on.
+	NodeCompleted                  // NodeCompleted had all its objectives done.
+	NodeFailed                     // NodeFailed hit one of its failure states.
+	NodeClosed                     // NodeClosed can no longer be reached, e.g. a branch not taken.
+)
+
+// nodeStateNames are the display names of each node state.
+var nodeStateNames = [...]string{"Locked", "Active", "Completed", "Failed", "Closed"}
+
+// String returns the state's display name.
+func (s NodeState) String() string {
+	if s < 0 || int(s) >= len(nodeStateNames) {
+		return fmt.Sprintf("NodeState(%d)", int(s))
+	}
+	return nodeStateNames[s]
+}
+
+// done reports whether the state is final.
+func (s NodeState) done() bool {
+	return s >= NodeCompleted
+}
+
+// Node is one quest of a graph. It unlocks once every ID in Requires is
+// complete, where an ID is a node or a branch, complete when any of its
+// nodes is, and the level reached is at least MinLevel. Nodes sharing a
+// Branch are alternatives: completing one closes the rest. A node fails
+// when a node in FailOn completes or when the player leaves level
+// Deadline without finishing it; nodes that required it close.
+type Node struct {
+	ID         string
+	Title      string
+	Branch     string
+	Requires   []string
+	FailOn     []string
+	MinLevel   int
+	Deadline   int // Last level it can be finished on; 0 for none
+	Optional   bool
+	Objectives []Objective
+	State      NodeState
+	Reward     Reward // Paid out once the completed node is claimed
+	Claimed    bool
+}
+
+// complete reports whether all the node's objectives are done.
+func (n *Node) complete() bool {
+	for _, o := range n.Objectives {
+		if !o.Complete {
+			return false
+		}
+	}
+	return true
+}
+
+// Transition is a node changing state during an evaluation.
+type Transition struct {
+	Node *Node
+	From NodeState
+	To   NodeState
+}
+
+// Graph is a structured campaign of quest nodes.
+type Graph struct {
+	Nodes    []*Node
+	index    map[string]*Node
+	branches map[string][]*Node
+	level    int
+}
+
+// NewGraph builds a graph of the nodes, checking that every reference
+// names a node or branch of the graph and that prerequisites have no
+// cycles. Nodes start locked.
+func NewGraph(nodes []*Node) (*Graph, error) {
+	seen := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		if n.ID == "" {
+			return nil, fmt.Errorf("%w: node missing id", ErrInvalidGraph)
+		}
+		if seen[n.ID] {
+			return nil, fmt.Errorf("%w: duplicate node %s", ErrInvalidGraph, n.ID)
+		}
+		seen[n.ID] = true
+	}
+	g := newGraph(nodes)
+	for _, n := range nodes {
+		for _, ref := range append(append([]string(nil), n.Requires...), n.FailOn...) {
+			if len(g.resolve(ref)) == 0 {
+				return nil, fmt.Errorf("%w: %s refers to unknown %s", ErrInvalidGraph, n.ID, ref)
+			}
+		}
+	}
+	if err := g.checkCycles(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// newGraph indexes the nodes by ID and branch without checking them.
+func newGraph(nodes []*Node) *Graph {
+	g := &Graph{Nodes: nodes, index: make(map[string]*Node, len(nodes)), branches: make(map[string][]*Node), level: 1}
+	for _, n := range nodes {
+		g.index[n.ID] = n
+		if n.Branch != "" {
+			g.branches[n.Branch] = append(g.branches[n.Branch], n)
+		}
+	}
+	return g
+}
+
+// resolve returns the nodes a reference names: the node with the ID, or
+// the nodes of the branch.
+func (g *Graph) resolve(ref string) []*Node {
+	if n, ok := g.index[ref]; ok {
+		return []*Node{n}
+	}
+	return g.branches[ref]
+}
+
+// checkCycles fails if a node requires itself through its prerequisites.
+func (g *Graph) checkCycles() error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	marks := make(map[*Node]int, len(g.Nodes))
+	var visit func(n *Node) error
+	visit = func(n *Node) error {
+		switch marks[n] {
+		case visiting:
+			return fmt.Errorf("%w: %s requires itself", ErrInvalidGraph, n.ID)
+		case visited:
+			return nil
+		}
+		marks[n] = visiting
+		for _, ref := range n.Requires {
+			for _, dep := range g.resolve(ref) {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		marks[n] = visited
+		return nil
+	}
+	for _, n := range g.Nodes {
+		if err := visit(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the node with the ID.
+func (g *Graph) Get(id string) (*Node, bool) {
+	n, ok := g.index[id]
+	return n, ok
+}
+
+// refState returns whether a reference is complete, and whether it can no
+// longer complete.
+func (g *Graph) refState(ref string) (complete, closed bool) {
+	closed = true
+	for _, n := range g.resolve(ref) {
+		if n.State == NodeCompleted {
+			return true, false
+		}
+		if !n.State.done() {
+			closed = false
+		}
+	}
+	return false, closed
+}
+
+// Evaluate advances the graph to the level reached and returns the nodes
+// that changed state, in the order they did. It repeats until nothing
+// changes, so a node completed this pass unlocks its dependents at once.
+func (g *Graph) Evaluate(level int) []Transition {
+	g.level = max(g.level, level)
+	var out []Transition
+	set := func(n *Node, to NodeState) {
+		out = append(out, Transition{Node: n, From: n.State, To: to})
+		n.State = to
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, n := range g.Nodes {
+			if n.State.done() {
+				continue
+			}
+			if to, ok := g.next(n); ok {
+				set(n, to)
+				changed = true
+				if to == NodeCompleted && n.Branch != "" {
+					for _, alt := range g.branches[n.Branch] {
+						if alt != n && !alt.State.done() {
+							set(alt, NodeClosed)
+						}
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// next returns the state an unfinished node moves to, if any.
+func (g *Graph) next(n *Node) (NodeState, bool) {
+	for _, ref := range n.FailOn {
+		if complete, _ := g.refState(ref); complete {
+			return NodeFailed, true
+		}
+	}
+	if n.Deadline > 0 && g.level > n.Deadline {
+		return NodeFailed, true
+	}
+	unlocked := g.level >= n.MinLevel
+	for _, ref := range n.Requires {
+		complete, closed := g.refState(ref)
+		if closed {
+			return NodeClosed, true
+		}
+		unlocked = unlocked && complete
+	}
+	switch {
+	case n.State == NodeLocked && unlocked:
+		return NodeActive, true
+	case n.State == NodeActive && n.complete():
+		return NodeCompleted, true
+	}
+	return n.State, false
+}
+
+// Record adds progress to the objectives of every active node that target
+// the event, e.g. "enemy" for a kill, and re-evaluates the graph.
+func (g *Graph) Record(target string, amount int) []Transition {
+	for _, n := range g.Nodes {
+		if n.State != NodeActive {
+			continue
+		}
+		for i := range n.Objectives {
+			o := &n.Objectives[i]
+			if o.Target != target || o.Complete {
+				continue
+			}
+			o.Progress += int64(amount)
+			o.Complete = o.Progress >= int64(o.Count)
+		}
+	}
+	return g.Evaluate(g.level)
+}
+
+// Fail fails an unfinished node, e.g. on an escort dying, and re-evaluates
+// the graph.
+func (g *Graph) Fail(id string) []Transition {
+	n, ok := g.index[id]
+	if !ok || n.State.done() {
+		return nil
+	}
+	out := []Transition{{Node: n, From: n.State, To: NodeFailed}}
+	n.State = NodeFailed
+	return append(out, g.Evaluate(g.level)...)
+}
+
+// NodeProgress is the state of a node and the progress of its objectives,
+// as saved.
+type NodeProgress struct {
+	ID       string
+	State    NodeState
+	Progress []int64
+	Claimed  bool
+}
+
+// Snapshot returns the progress of every node, to save.
+func (g *Graph) Snapshot() []NodeProgress {
+	out := make([]NodeProgress, len(g.Nodes))
+	for i, n := range g.Nodes {
+		out[i] = NodeProgress{ID: n.ID, State: n.State, Progress: make([]int64, len(n.Objectives)), Claimed: n.Claimed}
+		for j, o := range n.Objectives {
+			out[i].Progress[j] = o.Progress
+		}
+	}
+	return out
+}
+
+// Restore puts back saved progress at the level reached. Nodes missing
+// from it keep their state.
+func (g *Graph) Restore(level int, saved []NodeProgress) {
+	g.level = max(level, 1)
+	for _, p := range saved {
+		n, ok := g.index[p.ID]
+		if !ok {
+			continue
+		}
+		n.State, n.Claimed = p.State, p.Claimed
+		for j := range min(len(p.Progress), len(n.Objectives)) {
+			o := &n.Objectives[j]
+			o.Progress = p.Progress[j]
+			o.Complete = o.Progress >= int64(o.Count)
+		}
+	}
+}
+
+// Active returns the nodes with objectives to make progress on.
+func (g *Graph) Active() []*Node {
+	var out []*Node
+	for _, n := range g.Nodes {
+		if n.State == NodeActive {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Done reports whether every node reached a final state.
+func (g *Graph) Done() bool {
+	for _, n := range g.Nodes {
+		if !n.State.done() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed reports whether a node that is not optional failed or closed
+// without a branch alternative completing in its place.
+func (g *Graph) Failed() bool {
+	for _, n := range g.Nodes {
+		if n.Optional {
+			continue
+		}
+		switch n.State {
+		case NodeFailed:
+			return true
+		case NodeClosed:
+			if n.Branch == "" {
+				return true
+			}
+			if complete, _ := g.refState(n.Branch); !complete {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// campaignObjectives are the objective types campaign nodes draw from.
+var campaignObjectives = []ObjectiveType{ObjKillAll, ObjFindItem, ObjDestroyTarget, ObjRetrieveItem}
+
+// GenerateCampaign builds a campaign graph from seed spanning the levels:
+// a main quest per level, each needing the last; on some levels a choice
+// of two exclusive side quests, which the next level's main quest may
+// wait on; and on some an optional bounty that fails if the player leaves
+// the level without it. The same seed always builds the same campaign.
+func GenerateCampaign(seed uint64, levels int, genreID string) *Graph {
+	r := rng.NewRNG(seed)
+	t := &Tracker{genreID: genreID}
+	node := func(id string, level int) *Node {
+		o := t.generateObjective(r, campaignObjectives[r.Intn(len(campaignObjectives))], 0)
+		o.ID = id + "_obj"
+		return &Node{ID: id, Title: o.Desc, MinLevel: level, Objectives: []Objective{o}}
+	}
+
+	var nodes []*Node
+	var needs []string
+	for level := 1; level <= levels; level++ {
+		main := node(fmt.Sprintf("main_%d", level), level)
+		main.Requires, needs = needs, []string{main.ID}
+		main.Reward = Reward{XP: 100 * level, Credits: 50 * level, SkillPoints: 1}
+		nodes = append(nodes, main)
+
+		if level < levels && r.Intn(2) == 0 {
+			branch := fmt.Sprintf("choice_%d", level)
+			for _, side := range []string{"a", "b"} {
+				n := node(branch+"_"+side, level)
+				n.Branch, n.Requires, n.Optional = branch, []string{main.ID}, true
+				n.Reward = Reward{XP: 75 * level, Codex: []string{"quest_" + n.ID}}
+				nodes = append(nodes, n)
+			}
+			if r.Intn(2) == 0 {
+				needs = append(needs, branch)
+			}
+		}
+		if r.Intn(3) == 0 {
+			n := node(fmt.Sprintf("bounty_%d", level), level)
+			n.Deadline, n.Optional = level, true
+			n.Reward = Reward{Credits: 100 * level, Items: []ItemReward{{ID: "grenade", Name: "Grenade", Qty: 2}}}
+			nodes = append(nodes, n)
+		}
+	}
+	return newGraph(nodes)
+}
@@ -0,0 +1,189 @@
+package quest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// kill returns an objective to kill count enemies.
+func kill(count int) []Objective {
+	return []Objective{{ID: "kill", Target: "enemy", Count: count}}
+}
+
+// states returns the state of each node by ID.
+func states(g *Graph) map[string]NodeState {
+	out := make(map[string]NodeState, len(g.Nodes))
+	for _, n := range g.Nodes {
+		out[n.ID] = n.State
+	}
+	return out
+}
+
+func TestNewGraph_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []*Node
+	}{
+		{"missing id", []*Node{{}}},
+		{"duplicate", []*Node{{ID: "a"}, {ID: "a"}}},
+		{"unknown prerequisite", []*Node{{ID: "a", Requires: []string{"b"}}}},
+		{"unknown failure", []*Node{{ID: "a", FailOn: []string{"b"}}}},
+		{"cycle", []*Node{{ID: "a", Requires: []string{"b"}}, {ID: "b", Requires: []string{"a"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGraph(tt.nodes); !errors.Is(err, ErrInvalidGraph) {
+				t.Errorf("err = %v, want ErrInvalidGraph", err)
+			}
+		})
+	}
+}
+
+func TestGraph_Prerequisites(t *testing.T) {
+	g, err := NewGraph([]*Node{
+		{ID: "first", Objectives: kill(2)},
+		{ID: "second", Requires: []string{"first"}, MinLevel: 2, Objectives: kill(1)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Evaluate(1)
+	if s := states(g); s["first"] != NodeActive || s["second"] != NodeLocked {
+		t.Fatalf("start = %v", s)
+	}
+	g.Record("enemy", 1)
+	if g.Active()[0].Objectives[0].Progress != 1 {
+		t.Fatal("kill not recorded")
+	}
+	g.Record("enemy", 1)
+	if s := states(g); s["first"] != NodeCompleted || s["second"] != NodeLocked {
+		t.Fatalf("after two kills = %v, want second waiting on level 2", s)
+	}
+	if ts := g.Evaluate(2); len(ts) != 1 || ts[0].Node.ID != "second" || ts[0].To != NodeActive {
+		t.Fatalf("level 2 transitions = %+v", ts)
+	}
+	g.Record("enemy", 1)
+	if !g.Done() || g.Failed() {
+		t.Errorf("done = %v, failed = %v, want a finished campaign", g.Done(), g.Failed())
+	}
+}
+
+func TestGraph_Branches(t *testing.T) {
+	g, _ := NewGraph([]*Node{
+		{ID: "spare", Branch: "fate", Objectives: []Objective{{Target: "hostage", Count: 1}}},
+		{ID: "slay", Branch: "fate", Objectives: kill(1)},
+		{ID: "reward", Requires: []string{"fate"}},
+		{ID: "revenge", Requires: []string{"slay"}, Optional: true},
+		{ID: "grudge", FailOn: []string{"spare"}, Optional: true, Objectives: kill(5)},
+	})
+	g.Evaluate(1)
+	ts := g.Record("hostage", 1)
+	want := map[string]NodeState{"spare": NodeCompleted, "slay": NodeClosed, "reward": NodeCompleted, "revenge": NodeClosed, "grudge": NodeFailed}
+	if got := states(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("after sparing = %v, want %v", got, want)
+	}
+	// The reward unlocks and completes in the same evaluation.
+	if len(ts) != 6 {
+		t.Errorf("%d transitions, want 6: %+v", len(ts), ts)
+	}
+	if g.Failed() {
+		t.Error("the branch not taken and optional nodes failed the campaign")
+	}
+}
+
+func TestGraph_Failure(t *testing.T) {
+	g, _ := NewGraph([]*Node{
+		{ID: "escort", Objectives: []Objective{{Target: "exit", Count: 1}}},
+		{ID: "after", Requires: []string{"escort"}},
+		{ID: "bounty", Deadline: 1, Optional: true, Objectives: kill(3)},
+	})
+	g.Evaluate(1)
+	g.Evaluate(2)
+	if s := states(g); s["bounty"] != NodeFailed {
+		t.Errorf("bounty left behind is %v, want failed", s["bounty"])
+	}
+	if g.Failed() {
+		t.Error("an optional bounty failed the campaign")
+	}
+	g.Fail("escort")
+	if s := states(g); s["escort"] != NodeFailed || s["after"] != NodeClosed {
+		t.Errorf("after the escort died = %v", s)
+	}
+	if !g.Failed() || !g.Done() {
+		t.Error("a failed main quest did not fail the campaign")
+	}
+	if g.Fail("escort") != nil {
+		t.Error("failed a node twice")
+	}
+}
+
+func TestGraph_SnapshotRestore(t *testing.T) {
+	build := func() *Graph {
+		g, _ := NewGraph([]*Node{{ID: "a", Objectives: kill(3)}, {ID: "b", Requires: []string{"a"}}})
+		return g
+	}
+	g := build()
+	g.Evaluate(1)
+	g.Record("enemy", 2)
+
+	loaded := build()
+	loaded.Restore(1, g.Snapshot())
+	if !reflect.DeepEqual(states(loaded), states(g)) {
+		t.Fatalf("restored states %v, want %v", states(loaded), states(g))
+	}
+	loaded.Record("enemy", 1)
+	if s := states(loaded); s["a"] != NodeCompleted || s["b"] != NodeCompleted {
+		t.Errorf("restored progress did not carry over: %v", s)
+	}
+}
+
+func TestGenerateCampaign(t *testing.T) {
+	a := GenerateCampaign(42, 6, "scifi")
+	b := GenerateCampaign(42, 6, "scifi")
+	if !reflect.DeepEqual(a.Snapshot(), b.Snapshot()) || len(a.Nodes) != len(b.Nodes) {
+		t.Fatal("the same seed built different campaigns")
+	}
+	if _, err := NewGraph(a.Nodes); err != nil {
+		t.Fatalf("generated campaign is invalid: %v", err)
+	}
+
+	branches := 0
+	for seed := uint64(1); seed <= 20; seed++ {
+		g := GenerateCampaign(seed, 5, "fantasy")
+		for level := 1; level <= 5; level++ {
+			if _, ok := g.Get(mainID(level)); !ok {
+				t.Fatalf("seed %d has no main quest on level %d", seed, level)
+			}
+		}
+		branches += len(g.branches)
+
+		// Playing through every level, taking the first choice offered,
+		// finishes the main chain.
+		g.Evaluate(1)
+		for level := 1; level <= 5; level++ {
+			g.Evaluate(level)
+			for i := 0; i < 10; i++ {
+				for _, n := range g.Active() {
+					if n.Branch != "" && n.ID[len(n.ID)-1] == 'b' {
+						continue
+					}
+					for _, o := range n.Objectives {
+						g.Record(o.Target, o.Count)
+					}
+				}
+			}
+		}
+		if last, _ := g.Get(mainID(5)); last.State != NodeCompleted {
+			t.Errorf("seed %d: the last main quest ended %v", seed, last.State)
+		}
+	}
+	if branches == 0 {
+		t.Error("no campaign offered a choice")
+	}
+}
+
+// mainID returns the ID of a campaign's main quest on the level.
+func mainID(level int) string {
+	return "main_" + string(rune('0'+level))
+}
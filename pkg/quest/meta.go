@@ -0,0 +1,173 @@
+package quest
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MergeRule decides how a meta-quest folds in progress saved elsewhere.
+type MergeRule int
+
+const (
+	MergeMax   MergeRule = iota // MergeMax keeps the higher progress.
+	MergeSum                    // MergeSum adds progress made separately.
+	MergeUnion                  // MergeUnion unions the distinct keys found.
+)
+
+// MetaQuest is a quest spanning levels and runs, e.g. killing 100 enemies
+// of a faction. Its progress lives in the player's profile, not a save
+// slot. Quests merged with MergeUnion count distinct keys, e.g. lore entry
+// IDs, so finding one twice counts once.
+type MetaQuest struct {
+	ID       string
+	Title    string
+	Target   string // Event counted, e.g. "enemy:cult" or "lore:horror"
+	Count    int
+	Rule     MergeRule
+	Progress int64
+	Keys     []string
+	Complete bool
+	Reward   Reward
+	Claimed  bool
+}
+
+// KillMetaQuest returns a meta-quest to kill count enemies of a faction.
+func KillMetaQuest(factionID, factionName string, count int) MetaQuest {
+	return MetaQuest{
+		ID:     "meta_kill_" + factionID,
+		Title:  fmt.Sprintf("Kill %d %s", count, factionName),
+		Target: "enemy:" + factionID,
+		Count:  count,
+		Rule:   MergeSum,
+		Reward: Reward{XP: 1000, Credits: 500, SkillPoints: 2},
+	}
+}
+
+// LoreMetaQuest returns a meta-quest to find count distinct lore entries
+// of a genre.
+func LoreMetaQuest(genreID string, count int) MetaQuest {
+	return MetaQuest{
+		ID:     "meta_lore_" + genreID,
+		Title:  fmt.Sprintf("Find all %d %s lore entries", count, genreID),
+		Target: "lore:" + genreID,
+		Count:  count,
+		Rule:   MergeUnion,
+		Reward: Reward{XP: 750, SkillPoints: 1, Codex: []string{"meta_lore_" + genreID}},
+	}
+}
+
+// MetaProgress is a meta-quest's progress as kept in the profile.
+type MetaProgress struct {
+	ID       string
+	Progress int64
+	Keys     []string
+	Claimed  bool
+}
+
+// MetaBook holds the player's meta-quests.
+type MetaBook struct {
+	Quests []*MetaQuest
+	index  map[string]*MetaQuest
+}
+
+// NewMetaBook creates a book of the quests with no progress.
+func NewMetaBook(quests []MetaQuest) *MetaBook {
+	b := &MetaBook{index: make(map[string]*MetaQuest, len(quests))}
+	for _, q := range quests {
+		q.Progress, q.Keys, q.Complete, q.Claimed = 0, nil, false, false
+		b.Quests = append(b.Quests, &q)
+		b.index[q.ID] = &q
+	}
+	return b
+}
+
+// Get returns the quest with the ID.
+func (b *MetaBook) Get(id string) (*MetaQuest, bool) {
+	q, ok := b.index[id]
+	return q, ok
+}
+
+// Record counts an event towards the unfinished quests targeting it and
+// returns those it completed. key names what the event found, for quests
+// counting distinct keys.
+func (b *MetaBook) Record(target, key string, amount int) []*MetaQuest {
+	var done []*MetaQuest
+	for _, q := range b.Quests {
+		if q.Target != target || q.Complete {
+			continue
+		}
+		if q.Rule == MergeUnion {
+			if key == "" || slices.Contains(q.Keys, key) {
+				continue
+			}
+			q.Keys = append(q.Keys, key)
+			q.Progress = int64(len(q.Keys))
+		} else {
+			q.Progress += int64(amount)
+		}
+		if q.Progress >= int64(q.Count) {
+			q.Complete = true
+			done = append(done, q)
+		}
+	}
+	return done
+}
+
+// Merge folds saved progress into the book by each quest's rule. Progress
+// of quests the book doesn't hold is dropped.
+func (b *MetaBook) Merge(saved []MetaProgress) {
+	for _, p := range saved {
+		q, ok := b.index[p.ID]
+		if !ok {
+			continue
+		}
+		switch q.Rule {
+		case MergeMax:
+			q.Progress = max(q.Progress, p.Progress)
+		case MergeSum:
+			q.Progress += p.Progress
+		case MergeUnion:
+			for _, k := range p.Keys {
+				if !slices.Contains(q.Keys, k) {
+					q.Keys = append(q.Keys, k)
+				}
+			}
+			q.Progress = int64(len(q.Keys))
+		}
+		q.Claimed = q.Claimed || p.Claimed
+		q.Complete = q.Progress >= int64(q.Count)
+	}
+}
+
+// Snapshot returns the progress of every quest, to keep in the profile.
+func (b *MetaBook) Snapshot() []MetaProgress {
+	out := make([]MetaProgress, len(b.Quests))
+	for i, q := range b.Quests {
+		out[i] = MetaProgress{ID: q.ID, Progress: q.Progress, Keys: slices.Clone(q.Keys), Claimed: q.Claimed}
+	}
+	return out
+}
+
+// Claimable returns the completed quests whose rewards are unclaimed.
+func (b *MetaBook) Claimable() []*MetaQuest {
+	var out []*MetaQuest
+	for _, q := range b.Quests {
+		if q.Complete && !q.Claimed && !q.Reward.Empty() {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// Claim marks a completed quest's reward claimed and returns it.
+func (b *MetaBook) Claim(id string) (Reward, error) {
+	q, ok := b.index[id]
+	if !ok {
+		return Reward{}, fmt.Errorf("%w: unknown meta-quest %s", ErrNotClaimable, id)
+	}
+	if !q.Complete || q.Claimed {
+		return Reward{}, fmt.Errorf("%w: %s", ErrNotClaimable, id)
+	}
+	q.Claimed = true
+	return q.Reward, nil
+}
@@ -0,0 +1,75 @@
+package quest
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMetaBook_Record(t *testing.T) {
+	b := NewMetaBook([]MetaQuest{KillMetaQuest("cult", "cultists", 3), LoreMetaQuest("horror", 2)})
+	b.Record("enemy:rebels", "", 1)
+	if done := b.Record("enemy:cult", "", 2); len(done) != 0 {
+		t.Fatalf("completed %v after two kills", done)
+	}
+	done := b.Record("enemy:cult", "", 1)
+	if len(done) != 1 || done[0].ID != "meta_kill_cult" {
+		t.Fatalf("third kill completed %v", done)
+	}
+	if b.Record("enemy:cult", "", 1) != nil {
+		t.Error("a completed quest completed again")
+	}
+
+	b.Record("lore:horror", "lore_horror_0", 1)
+	b.Record("lore:horror", "lore_horror_0", 1)
+	lore, _ := b.Get("meta_lore_horror")
+	if lore.Progress != 1 || lore.Complete {
+		t.Fatalf("lore progress %d after finding one entry twice", lore.Progress)
+	}
+	b.Record("lore:horror", "lore_horror_1", 1)
+	if !lore.Complete {
+		t.Error("finding both entries did not complete the lore quest")
+	}
+}
+
+func TestMetaBook_Merge(t *testing.T) {
+	quests := []MetaQuest{
+		KillMetaQuest("cult", "cultists", 10),
+		LoreMetaQuest("horror", 3),
+		{ID: "best", Target: "depth", Count: 5, Rule: MergeMax},
+	}
+	b := NewMetaBook(quests)
+	b.Record("enemy:cult", "", 4)
+	b.Record("lore:horror", "a", 1)
+	b.Record("depth", "", 2)
+
+	b.Merge([]MetaProgress{
+		{ID: "meta_kill_cult", Progress: 6},
+		{ID: "meta_lore_horror", Keys: []string{"a", "b"}},
+		{ID: "best", Progress: 1},
+		{ID: "retired", Progress: 99},
+	})
+	kills, _ := b.Get("meta_kill_cult")
+	lore, _ := b.Get("meta_lore_horror")
+	best, _ := b.Get("best")
+	if kills.Progress != 10 || !kills.Complete {
+		t.Errorf("summed kills = %d, complete %v; want 10 and complete", kills.Progress, kills.Complete)
+	}
+	if lore.Progress != 2 || lore.Complete {
+		t.Errorf("unioned lore = %d, want 2", lore.Progress)
+	}
+	if best.Progress != 2 {
+		t.Errorf("max depth = %d, want 2", best.Progress)
+	}
+
+	if _, err := b.Claim("meta_kill_cult"); err != nil {
+		t.Fatal(err)
+	}
+	fresh := NewMetaBook(quests)
+	fresh.Merge(b.Snapshot())
+	if len(fresh.Claimable()) != 0 {
+		t.Error("a claimed reward is claimable again from the profile")
+	}
+	if _, err := fresh.Claim("meta_lore_horror"); !errors.Is(err, ErrNotClaimable) {
+		t.Errorf("claiming an unfinished meta-quest: err = %v", err)
+	}
+}
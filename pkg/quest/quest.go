@@ -3,6 +3,7 @@ package quest
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/opd-ai/violence/pkg/rng"
 )
@@ -18,6 +19,10 @@ const (
 	ObjSurvive                            // ObjSurvive is a survival objective.
 	ObjRetrieveItem                       // ObjRetrieveItem is a retrieve item objective.
 	ObjRescueHostage                      // ObjRescueHostage is a rescue hostage objective.
+	ObjEscort                             // ObjEscort is an escort objective.
+	ObjDefend                             // ObjDefend is a defend position objective.
+	ObjExtraction                         // ObjExtraction is a timed extraction objective.
+	ObjSabotage                           // ObjSabotage is a sabotage objective.
 )
 
 // ObjectiveCategory indicates if objective is main or bonus.
@@ -40,6 +45,13 @@ type Objective struct {
 	Complete bool
 	PosX     float64 // Objective position in level
 	PosY     float64
+	Failed   bool
+	// Sites are further positions the objective uses: an escort's or an
+	// extraction's destination, or the sabotage targets.
+	Sites []Position
+	// Reward is paid out once the completed objective is claimed.
+	Reward  Reward
+	Claimed bool
 }
 
 // Tracker tracks active objectives.
@@ -79,6 +91,7 @@ func (t *Tracker) GenerateWithLayout(seed uint64, layout LevelLayout) {
 
 	// Generate bonus objectives
 	t.generateBonusObjectives(r, layout)
+	t.generateEventObjective(r, layout)
 }
 
 func (t *Tracker) generateBonusObjectives(r *rng.RNG, layout LevelLayout) {
@@ -93,6 +106,7 @@ func (t *Tracker) generateBonusObjectives(r *rng.RNG, layout LevelLayout) {
 			Target:   "secret",
 			Count:    threshold,
 		}
+		obj.Reward = objectiveReward(obj)
 		t.Objectives = append(t.Objectives, obj)
 	}
 
@@ -106,6 +120,7 @@ func (t *Tracker) generateBonusObjectives(r *rng.RNG, layout LevelLayout) {
 		Target:   "enemy",
 		Count:    killTarget,
 	}
+	killObj.Reward = objectiveReward(killObj)
 	t.Objectives = append(t.Objectives, killObj)
 
 	// Speed run bonus (in seconds)
@@ -118,9 +133,48 @@ func (t *Tracker) generateBonusObjectives(r *rng.RNG, layout LevelLayout) {
 		Target:   "speedrun",
 		Count:    timeTarget,
 	}
+	speedObj.Reward = objectiveReward(speedObj)
 	t.Objectives = append(t.Objectives, speedObj)
 }
 
+// eventObjectives are the objective types placed by generateEventObjective.
+var eventObjectives = []ObjectiveType{ObjEscort, ObjDefend, ObjExtraction, ObjSabotage}
+
+// generateEventObjective adds a bonus objective played out in the level's
+// rooms away from the exit: an escort from one room to another, a position
+// to defend, a beacon starting an extraction countdown or targets to
+// sabotage. Levels with fewer than three such rooms get none.
+func (t *Tracker) generateEventObjective(r *rng.RNG, layout LevelLayout) {
+	var rooms []Position
+	for _, room := range layout.Rooms {
+		if layout.ExitPos != nil && room.contains(*layout.ExitPos) {
+			continue
+		}
+		rooms = append(rooms, room.center())
+	}
+	if len(rooms) < 3 {
+		return
+	}
+	for i := len(rooms) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		rooms[i], rooms[j] = rooms[j], rooms[i]
+	}
+
+	obj := t.generateObjective(r, eventObjectives[r.Intn(len(eventObjectives))], 0)
+	obj.ID = "bonus_event"
+	obj.Category = CategoryBonus
+	obj.PosX, obj.PosY = rooms[0].X, rooms[0].Y
+	switch obj.Type {
+	case ObjEscort, ObjExtraction:
+		obj.Sites = []Position{rooms[len(rooms)-1]}
+	case ObjSabotage:
+		obj.Count = min(obj.Count, len(rooms))
+		obj.Sites = rooms[:obj.Count]
+	}
+	obj.Reward = objectiveReward(obj)
+	t.Objectives = append(t.Objectives, obj)
+}
+
 // LevelLayout represents level structure for objective placement.
 // It is used by GenerateWithLayout to position objectives in specific
 // level regions. ExitPos is used for "find exit" objectives, and Rooms
@@ -150,6 +204,16 @@ type Room struct {
 	Height int
 }
 
+// center returns the position at the middle of the room.
+func (r Room) center() Position {
+	return Position{X: float64(r.X) + float64(r.Width)/2, Y: float64(r.Y) + float64(r.Height)/2}
+}
+
+// contains reports whether the position lies within the room.
+func (r Room) contains(p Position) bool {
+	return p.X >= float64(r.X) && p.X < float64(r.X+r.Width) && p.Y >= float64(r.Y) && p.Y < float64(r.Y+r.Height)
+}
+
 func (t *Tracker) generateObjective(r *rng.RNG, objType ObjectiveType, idx int) Objective {
 	obj := Objective{
 		ID:       fmt.Sprintf("obj_%d", idx),
@@ -213,7 +277,45 @@ func (t *Tracker) generateObjective(r *rng.RNG, objType ObjectiveType, idx int)
 		)
 		obj.Target = "hostage"
 		obj.Count = count
+	case ObjEscort:
+		obj.Desc = t.genreText("Escort the pilgrim to safety", "Escort the scientist to the shuttle", "Lead the survivor out", "Extract the informant", "Guide the wanderer to camp")
+		obj.Target = "escort"
+		obj.Count = 1
+	case ObjDefend:
+		waves := 2 + r.Intn(3)
+		obj.Desc = t.genreText(
+			fmt.Sprintf("Defend the shrine from %d waves", waves),
+			fmt.Sprintf("Hold the relay against %d waves", waves),
+			fmt.Sprintf("Guard the sanctum through %d waves", waves),
+			fmt.Sprintf("Protect the server through %d waves", waves),
+			fmt.Sprintf("Hold the water pump against %d raids", waves),
+		)
+		obj.Target = "wave"
+		obj.Count = waves
+	case ObjExtraction:
+		time := 45 + r.Intn(46)
+		obj.Desc = t.genreText(
+			fmt.Sprintf("Light the beacon, then flee in %d seconds", time),
+			fmt.Sprintf("Call extraction and reach it in %d seconds", time),
+			fmt.Sprintf("Ring the bell, then escape in %d seconds", time),
+			fmt.Sprintf("Ping the dropship and reach it in %d seconds", time),
+			fmt.Sprintf("Fire the flare and reach the convoy in %d seconds", time),
+		)
+		obj.Target = "extract"
+		obj.Count = time
+	case ObjSabotage:
+		count := 2 + r.Intn(2)
+		obj.Desc = t.genreText(
+			fmt.Sprintf("Smash %d siege engines", count),
+			fmt.Sprintf("Sabotage %d reactors", count),
+			fmt.Sprintf("Break %d ritual seals", count),
+			fmt.Sprintf("Crash %d ICE servers", count),
+			fmt.Sprintf("Wreck %d raider rigs", count),
+		)
+		obj.Target = "sabotage"
+		obj.Count = count
 	}
+	obj.Reward = objectiveReward(obj)
 	return obj
 }
 
@@ -249,6 +351,16 @@ func (t *Tracker) UpdateProgress(id string, amount int) {
 	}
 }
 
+// Fail marks an unfinished objective as failed by ID, e.g. an escort who
+// died. Failed objectives are no longer active.
+func (t *Tracker) Fail(id string) {
+	for i := range t.Objectives {
+		if t.Objectives[i].ID == id && !t.Objectives[i].Complete {
+			t.Objectives[i].Failed = true
+		}
+	}
+}
+
 // Complete marks an objective as completed by ID.
 func (t *Tracker) Complete(id string) {
 	for i := range t.Objectives {
@@ -262,7 +374,7 @@ func (t *Tracker) Complete(id string) {
 func (t *Tracker) GetActive() []Objective {
 	active := []Objective{}
 	for _, obj := range t.Objectives {
-		if !obj.Complete {
+		if !obj.Complete && !obj.Failed {
 			active = append(active, obj)
 		}
 	}
@@ -273,7 +385,7 @@ func (t *Tracker) GetActive() []Objective {
 func (t *Tracker) GetMainObjectives() []Objective {
 	main := []Objective{}
 	for _, obj := range t.Objectives {
-		if !obj.Complete && obj.Category == CategoryMain {
+		if !obj.Complete && !obj.Failed && obj.Category == CategoryMain {
 			main = append(main, obj)
 		}
 	}
@@ -284,13 +396,51 @@ func (t *Tracker) GetMainObjectives() []Objective {
 func (t *Tracker) GetBonusObjectives() []Objective {
 	bonus := []Objective{}
 	for _, obj := range t.Objectives {
-		if !obj.Complete && obj.Category == CategoryBonus {
+		if !obj.Complete && !obj.Failed && obj.Category == CategoryBonus {
 			bonus = append(bonus, obj)
 		}
 	}
 	return bonus
 }
 
+// ObjectiveProgress is the state of a level objective and the items its
+// reward gained on completion, as saved.
+type ObjectiveProgress struct {
+	ID       string
+	Progress int64
+	Complete bool
+	Failed   bool
+	Claimed  bool
+	Items    []ItemReward
+}
+
+// Snapshot returns the progress of every objective, to save.
+func (t *Tracker) Snapshot() []ObjectiveProgress {
+	out := make([]ObjectiveProgress, len(t.Objectives))
+	for i, obj := range t.Objectives {
+		out[i] = ObjectiveProgress{
+			ID: obj.ID, Progress: obj.Progress, Complete: obj.Complete, Failed: obj.Failed,
+			Claimed: obj.Claimed, Items: slices.Clone(obj.Reward.Items),
+		}
+	}
+	return out
+}
+
+// Restore puts back saved progress onto the regenerated objectives.
+// Objectives missing from it keep their state.
+func (t *Tracker) Restore(saved []ObjectiveProgress) {
+	for _, p := range saved {
+		for i := range t.Objectives {
+			obj := &t.Objectives[i]
+			if obj.ID != p.ID {
+				continue
+			}
+			obj.Progress, obj.Complete, obj.Failed, obj.Claimed = p.Progress, p.Complete, p.Failed, p.Claimed
+			obj.Reward.Items = slices.Clone(p.Items)
+		}
+	}
+}
+
 // AllComplete returns true if all objectives are done.
 func (t *Tracker) AllComplete() bool {
 	for _, obj := range t.Objectives {
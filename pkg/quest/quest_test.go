@@ -1,6 +1,7 @@
 package quest
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -262,6 +263,68 @@ func TestTracker_GenerateWithLayout(t *testing.T) {
 	}
 }
 
+func TestTracker_GenerateEventObjective(t *testing.T) {
+	exit := &Position{X: 85, Y: 85}
+	layout := LevelLayout{
+		ExitPos: exit,
+		Rooms: []Room{
+			{X: 0, Y: 0, Width: 10, Height: 10},
+			{X: 20, Y: 0, Width: 10, Height: 10},
+			{X: 40, Y: 0, Width: 10, Height: 10},
+			{X: 60, Y: 0, Width: 10, Height: 10},
+			{X: 80, Y: 80, Width: 10, Height: 10},
+		},
+	}
+	types := make(map[ObjectiveType]bool)
+	for seed := uint64(1); seed <= 50; seed++ {
+		tracker := NewTracker()
+		tracker.GenerateWithLayout(seed, layout)
+		obj := tracker.Objectives[len(tracker.Objectives)-1]
+		if obj.ID != "bonus_event" || obj.Category != CategoryBonus {
+			t.Fatalf("seed %d: last objective %+v, want the event bonus", seed, obj)
+		}
+		types[obj.Type] = true
+		if (Room{X: 80, Y: 80, Width: 10, Height: 10}).contains(Position{X: obj.PosX, Y: obj.PosY}) {
+			t.Errorf("seed %d: %v placed in the exit room", seed, obj.Type)
+		}
+		switch obj.Type {
+		case ObjEscort, ObjExtraction:
+			if len(obj.Sites) != 1 || obj.Sites[0] == (Position{X: obj.PosX, Y: obj.PosY}) {
+				t.Errorf("seed %d: %v destination %v", seed, obj.Type, obj.Sites)
+			}
+		case ObjSabotage:
+			if len(obj.Sites) != obj.Count {
+				t.Errorf("seed %d: %d sabotage targets for a count of %d", seed, len(obj.Sites), obj.Count)
+			}
+		}
+	}
+	if len(types) != len(eventObjectives) {
+		t.Errorf("generated %d event objective types, want %d", len(types), len(eventObjectives))
+	}
+
+	tracker := NewTracker()
+	tracker.GenerateWithLayout(1, LevelLayout{ExitPos: exit, Rooms: layout.Rooms[3:]})
+	for _, obj := range tracker.Objectives {
+		if obj.ID == "bonus_event" {
+			t.Error("a level with too few rooms got an event objective")
+		}
+	}
+}
+
+func TestTracker_Fail(t *testing.T) {
+	tracker := NewTracker()
+	tracker.Add(Objective{ID: "escort", Category: CategoryBonus, Count: 1})
+	tracker.Add(Objective{ID: "done", Category: CategoryBonus, Count: 1, Complete: true})
+	tracker.Fail("escort")
+	tracker.Fail("done")
+	if !tracker.Objectives[0].Failed || tracker.Objectives[1].Failed {
+		t.Errorf("failed = %v, %v; want only the unfinished objective failed", tracker.Objectives[0].Failed, tracker.Objectives[1].Failed)
+	}
+	if len(tracker.GetActive()) != 0 || len(tracker.GetBonusObjectives()) != 0 {
+		t.Error("a failed objective is still active")
+	}
+}
+
 func TestTracker_GetMainObjectives(t *testing.T) {
 	tracker := NewTracker()
 	tracker.Add(Objective{ID: "main1", Category: CategoryMain})
@@ -467,3 +530,18 @@ func TestUpdateProgressLargeValue(t *testing.T) {
 		t.Error("Objective should be complete")
 	}
 }
+
+func TestTracker_SnapshotRestore(t *testing.T) {
+	tracker := NewTracker()
+	tracker.GenerateWithLayout(11, LevelLayout{SecretCount: 2})
+	tracker.UpdateProgress("bonus_secrets", 1)
+	tracker.Complete("obj_0")
+	tracker.AddReward("obj_0", Reward{Items: []ItemReward{{ID: "ring", Name: "Ring", Qty: 1}}})
+
+	loaded := NewTracker()
+	loaded.GenerateWithLayout(11, LevelLayout{SecretCount: 2})
+	loaded.Restore(tracker.Snapshot())
+	if !reflect.DeepEqual(loaded.Objectives, tracker.Objectives) {
+		t.Errorf("restored objectives = %+v, want %+v", loaded.Objectives, tracker.Objectives)
+	}
+}
@@ -0,0 +1,177 @@
+package quest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotClaimable is returned when claiming the reward of a quest that is
+// unfinished, failed or already claimed.
+var ErrNotClaimable = errors.New("quest reward not claimable")
+
+// ItemReward is a stack of an item a reward hands out.
+type ItemReward struct {
+	ID   string
+	Name string
+	Qty  int
+}
+
+// Reward is the bundle finishing a quest pays out once claimed.
+type Reward struct {
+	XP          int
+	Credits     int
+	Items       []ItemReward
+	SkillPoints int
+	Codex       []string // Codex entries unlocked, by ID
+}
+
+// Empty reports whether the reward pays out nothing.
+func (r Reward) Empty() bool {
+	return r.XP == 0 && r.Credits == 0 && len(r.Items) == 0 && r.SkillPoints == 0 && len(r.Codex) == 0
+}
+
+// Add adds another reward to this one.
+func (r *Reward) Add(o Reward) {
+	r.XP += o.XP
+	r.Credits += o.Credits
+	r.Items = append(r.Items, o.Items...)
+	r.SkillPoints += o.SkillPoints
+	r.Codex = append(r.Codex, o.Codex...)
+}
+
+// String returns a one-line summary, e.g. "+150 XP, +60 credits, 1 skill
+// point, Medkit x2".
+func (r Reward) String() string {
+	var parts []string
+	if r.XP != 0 {
+		parts = append(parts, fmt.Sprintf("+%d XP", r.XP))
+	}
+	if r.Credits != 0 {
+		parts = append(parts, fmt.Sprintf("+%d credits", r.Credits))
+	}
+	switch {
+	case r.SkillPoints == 1:
+		parts = append(parts, "1 skill point")
+	case r.SkillPoints > 1:
+		parts = append(parts, fmt.Sprintf("%d skill points", r.SkillPoints))
+	}
+	for _, it := range r.Items {
+		if it.Qty > 1 {
+			parts = append(parts, fmt.Sprintf("%s x%d", it.Name, it.Qty))
+		} else {
+			parts = append(parts, it.Name)
+		}
+	}
+	switch {
+	case len(r.Codex) == 1:
+		parts = append(parts, "1 codex entry")
+	case len(r.Codex) > 1:
+		parts = append(parts, fmt.Sprintf("%d codex entries", len(r.Codex)))
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// objectiveReward returns the reward for finishing a level objective. Main
+// objectives pay more than bonus ones, objectives played out as level
+// events add a skill point, and finding items a medkit.
+func objectiveReward(o Objective) Reward {
+	r := Reward{XP: 50, Credits: 25}
+	if o.Category == CategoryMain {
+		r = Reward{XP: 100, Credits: 50}
+	}
+	switch o.Type {
+	case ObjKillAll, ObjDestroyTarget:
+		r.XP += 5 * o.Count
+	case ObjFindItem, ObjRetrieveItem:
+		r.Items = []ItemReward{{ID: "medkit", Name: "Medkit", Qty: 1}}
+	case ObjEscort, ObjDefend, ObjExtraction, ObjSabotage:
+		r.XP += 50
+		r.SkillPoints = 1
+	}
+	return r
+}
+
+// AddReward adds to the reward of an unclaimed objective, e.g. loot rolled
+// when it completed.
+func (t *Tracker) AddReward(id string, r Reward) {
+	for i := range t.Objectives {
+		if t.Objectives[i].ID == id && !t.Objectives[i].Claimed {
+			t.Objectives[i].Reward.Add(r)
+		}
+	}
+}
+
+// Claimable returns the completed objectives whose rewards are unclaimed.
+func (t *Tracker) Claimable() []Objective {
+	var out []Objective
+	for _, obj := range t.Objectives {
+		if obj.Complete && !obj.Claimed && !obj.Reward.Empty() {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// Claim marks a completed objective's reward claimed and returns it.
+func (t *Tracker) Claim(id string) (Reward, error) {
+	for i := range t.Objectives {
+		obj := &t.Objectives[i]
+		if obj.ID != id {
+			continue
+		}
+		if !obj.Complete || obj.Claimed {
+			return Reward{}, fmt.Errorf("%w: %s", ErrNotClaimable, id)
+		}
+		obj.Claimed = true
+		return obj.Reward, nil
+	}
+	return Reward{}, fmt.Errorf("%w: unknown objective %s", ErrNotClaimable, id)
+}
+
+// ClaimAll claims every claimable objective reward and returns their sum.
+func (t *Tracker) ClaimAll() Reward {
+	var total Reward
+	for _, obj := range t.Claimable() {
+		r, _ := t.Claim(obj.ID)
+		total.Add(r)
+	}
+	return total
+}
+
+// Claimable returns the completed nodes whose rewards are unclaimed.
+func (g *Graph) Claimable() []*Node {
+	var out []*Node
+	for _, n := range g.Nodes {
+		if n.State == NodeCompleted && !n.Claimed && !n.Reward.Empty() {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Claim marks a completed node's reward claimed and returns it.
+func (g *Graph) Claim(id string) (Reward, error) {
+	n, ok := g.index[id]
+	if !ok {
+		return Reward{}, fmt.Errorf("%w: unknown node %s", ErrNotClaimable, id)
+	}
+	if n.State != NodeCompleted || n.Claimed {
+		return Reward{}, fmt.Errorf("%w: %s", ErrNotClaimable, id)
+	}
+	n.Claimed = true
+	return n.Reward, nil
+}
+
+// ClaimAll claims every claimable node reward and returns their sum.
+func (g *Graph) ClaimAll() Reward {
+	var total Reward
+	for _, n := range g.Claimable() {
+		r, _ := g.Claim(n.ID)
+		total.Add(r)
+	}
+	return total
+}
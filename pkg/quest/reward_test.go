@@ -0,0 +1,85 @@
+package quest
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReward_String(t *testing.T) {
+	tests := []struct {
+		reward Reward
+		want   string
+	}{
+		{Reward{}, "nothing"},
+		{Reward{XP: 150, Credits: 60, SkillPoints: 1}, "+150 XP, +60 credits, 1 skill point"},
+		{Reward{Items: []ItemReward{{Name: "Medkit", Qty: 2}, {Name: "Grenade", Qty: 1}}, Codex: []string{"a", "b"}}, "Medkit x2, Grenade, 2 codex entries"},
+	}
+	for _, tt := range tests {
+		if got := tt.reward.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTracker_Claim(t *testing.T) {
+	tracker := NewTracker()
+	tracker.GenerateWithLayout(7, LevelLayout{SecretCount: 2})
+	for _, obj := range tracker.Objectives {
+		if obj.Reward.Empty() {
+			t.Errorf("objective %s has no reward", obj.ID)
+		}
+	}
+	if len(tracker.Claimable()) != 0 {
+		t.Fatal("unfinished objectives are claimable")
+	}
+	if _, err := tracker.Claim("obj_0"); !errors.Is(err, ErrNotClaimable) {
+		t.Errorf("claiming an unfinished objective: err = %v", err)
+	}
+
+	tracker.Complete("obj_0")
+	tracker.AddReward("obj_0", Reward{Items: []ItemReward{{ID: "ring", Name: "Ring", Qty: 1}}})
+	r, err := tracker.Claim("obj_0")
+	if err != nil || r.XP != 100 || len(r.Items) != 1 {
+		t.Fatalf("Claim = %+v, %v; want the main reward with the ring", r, err)
+	}
+	if _, err := tracker.Claim("obj_0"); !errors.Is(err, ErrNotClaimable) {
+		t.Error("claimed a reward twice")
+	}
+
+	tracker.UpdateProgress("bonus_secrets", 1)
+	tracker.Complete("bonus_kills")
+	total := tracker.ClaimAll()
+	if total.XP < 100 || total.Credits != 50 {
+		t.Errorf("ClaimAll = %+v, want two bonus rewards", total)
+	}
+	if len(tracker.Claimable()) != 0 {
+		t.Error("rewards left to claim after ClaimAll")
+	}
+}
+
+func TestGraph_Claim(t *testing.T) {
+	build := func() *Graph {
+		g, _ := NewGraph([]*Node{
+			{ID: "a", Objectives: kill(1), Reward: Reward{XP: 10, SkillPoints: 1}},
+			{ID: "b", Requires: []string{"a"}, Objectives: kill(1), Reward: Reward{Codex: []string{"quest_b"}}},
+		})
+		return g
+	}
+	g := build()
+	g.Evaluate(1)
+	if _, err := g.Claim("a"); !errors.Is(err, ErrNotClaimable) {
+		t.Errorf("claiming an active node: err = %v", err)
+	}
+	g.Record("enemy", 1)
+	g.Record("enemy", 1)
+	total := g.ClaimAll()
+	if total.XP != 10 || total.SkillPoints != 1 || len(total.Codex) != 1 {
+		t.Errorf("ClaimAll = %+v", total)
+	}
+
+	loaded := build()
+	loaded.Restore(1, g.Snapshot())
+	if len(loaded.Claimable()) != 0 {
+		t.Error("claimed rewards claimable again after a restore")
+	}
+}
@@ -6,14 +6,31 @@ import (
 	"sort"
 )
 
+// Standard wall heights in wall units, for use in height maps.
+const (
+	HeightLowCover = 0.4 // HeightLowCover is waist-high cover the player can see over.
+	HeightPlatform = 0.2 // HeightPlatform is a raised floor platform edge.
+	HeightStandard = 1.0 // HeightStandard is a regular floor-to-ceiling wall.
+	HeightTallHall = 2.0 // HeightTallHall is a wall rising above the standard ceiling.
+)
+
+// maxLayers bounds how many see-through walls a ray passes through.
+const maxLayers = 8
+
+// MaxPortalDepth bounds how many portals a single ray continues through.
+// Rays reaching the limit stop at the portal tile itself.
+const MaxPortalDepth = 4
+
 // Raycaster performs raycasting against a 2D map.
 type Raycaster struct {
 	FOV        float64
 	Width      int
 	Height     int
-	Map        [][]int    // 2D tile grid; 0 = empty, >0 = wall type
-	FogColor   [3]float64 // RGB fog color (0.0-1.0)
-	FogDensity float64    // Fog density for exponential falloff
+	Map        [][]int     // 2D tile grid; 0 = empty, >0 = wall type
+	Heights    [][]float64 // Optional per-tile wall heights; 0 = HeightStandard
+	FogColor   [3]float64  // RGB fog color (0.0-1.0)
+	FogDensity float64     // Fog density for exponential falloff
+	portals    map[[2]int][2]int
 }
 
 // NewRaycaster creates a raycaster with the given field of view and resolution.
@@ -27,19 +44,112 @@ func NewRaycaster(fov float64, width, height int) *Raycaster {
 	}
 }
 
-// SetMap assigns the tile grid for raycasting.
+// SetMap assigns the tile grid for raycasting and links its portal pairs.
 func (r *Raycaster) SetMap(tileMap [][]int) {
 	r.Map = tileMap
+	r.portals = linkPortals(tileMap)
+}
+
+// linkPortals pairs up portal tiles by ID. Each portal maps to the tile of
+// its partner; IDs that do not appear exactly twice stay unlinked.
+func linkPortals(tileMap [][]int) map[[2]int][2]int {
+	ends := map[int][][2]int{}
+	for y := range tileMap {
+		for x, t := range tileMap[y] {
+			if IsPortalTile(t) {
+				ends[t] = append(ends[t], [2]int{x, y})
+			}
+		}
+	}
+	if len(ends) == 0 {
+		return nil
+	}
+
+	links := make(map[[2]int][2]int, len(ends)*2)
+	for _, e := range ends {
+		if len(e) == 2 {
+			links[e[0]] = e[1]
+			links[e[1]] = e[0]
+		}
+	}
+	return links
+}
+
+// PortalExit returns the tile linked to the portal at (x, y). ok is false
+// when the tile is not a linked portal.
+func (r *Raycaster) PortalExit(x, y int) (exitX, exitY int, ok bool) {
+	exit, ok := r.portals[[2]int{x, y}]
+	return exit[0], exit[1], ok
+}
+
+// SetHeightMap assigns per-tile wall heights. Tiles with height below
+// HeightStandard let rays continue so walls behind them stay visible.
+func (r *Raycaster) SetHeightMap(heights [][]float64) {
+	r.Heights = heights
+}
+
+// TileHeight returns the wall height of a tile in wall units.
+func (r *Raycaster) TileHeight(x, y int) float64 {
+	if y < 0 || y >= len(r.Heights) || x < 0 || x >= len(r.Heights[y]) {
+		return HeightStandard
+	}
+	if h := r.Heights[y][x]; h > 0 {
+		return h
+	}
+	return HeightStandard
 }
 
 // RayHit contains information about a ray-wall intersection.
 type RayHit struct {
-	Distance float64 // Perpendicular distance to wall
-	WallType int     // Tile value from map
-	Side     int     // 0 = horizontal, 1 = vertical wall face
-	HitX     float64 // Exact X coordinate of wall hit
-	HitY     float64 // Exact Y coordinate of wall hit
-	TextureX float64 // Texture coordinate along wall (0.0-1.0)
+	Distance float64  // Perpendicular distance to wall
+	WallType int      // Tile value from map
+	Side     int      // 0 = horizontal, 1 = vertical wall face
+	HitX     float64  // Exact X coordinate of wall hit
+	HitY     float64  // Exact Y coordinate of wall hit
+	TextureX float64  // Texture coordinate along wall (0.0-1.0)
+	Height   float64  // Wall height in wall units; 0 is treated as HeightStandard
+	Layers   []RayHit // Nearer see-through walls the ray passed, nearest first
+	Portals  int      // Number of portals the ray passed through
+}
+
+// WallSpan returns the top and bottom screen rows covered by a wall hit on a
+// screen of the given height. Walls stand on the floor, so shorter walls
+// keep their base and lose their top.
+func WallSpan(hit RayHit, screenHeight int) (top, bottom int) {
+	height := hit.Height
+	if height <= 0 {
+		height = HeightStandard
+	}
+	lineHeight := int(float64(screenHeight) / hit.Distance)
+	bottom = lineHeight/2 + screenHeight/2
+	top = -lineHeight/2 + screenHeight/2 - int(float64(lineHeight)*(height-HeightStandard))
+	return top, bottom
+}
+
+// IsOccluded reports whether a point at the given depth drawn on screen row y
+// is hidden by the column's walls: either the full-height wall is nearer, or
+// a nearer partial-height wall covers that row. Transparent walls never
+// occlude; callers composite through their cutouts instead.
+func IsOccluded(hit RayHit, y int, depth float64, screenHeight int) bool {
+	if hit.WallType != 0 && hit.Distance < depth {
+		top, bottom := WallSpan(hit, screenHeight)
+		if y >= top && y <= bottom {
+			return true
+		}
+	}
+	for _, p := range hit.Layers {
+		if p.Distance >= depth {
+			break
+		}
+		if IsTransparentTile(p.WallType) {
+			continue
+		}
+		top, bottom := WallSpan(p, screenHeight)
+		if y >= top && y <= bottom {
+			return true
+		}
+	}
+	return false
 }
 
 // CastRays casts all rays for a single frame using DDA algorithm.
@@ -77,21 +187,48 @@ func (r *Raycaster) castRay(posX, posY, rayDirX, rayDirY float64) RayHit {
 	deltaDistX, deltaDistY := calculateDeltaDistances(rayDirX, rayDirY)
 	stepX, stepY, sideDistX, sideDistY := initializeDDA(posX, posY, rayDirX, rayDirY, mapX, mapY, deltaDistX, deltaDistY)
 
-	side, hit := performDDA(&mapX, &mapY, &sideDistX, &sideDistY, deltaDistX, deltaDistY, stepX, stepY, r.Map)
-	if !hit {
-		return RayHit{Distance: 1e30, WallType: 0, Side: side}
-	}
+	var layers []RayHit
+	portals := 0
+	for {
+		side, hit := performDDA(&mapX, &mapY, &sideDistX, &sideDistY, deltaDistX, deltaDistY, stepX, stepY, r.Map)
+		if !hit {
+			return RayHit{Distance: 1e30, WallType: 0, Side: side, Layers: layers, Portals: portals}
+		}
 
-	perpWallDist, hitX, hitY := calculateWallDistance(side, mapX, mapY, posX, posY, rayDirX, rayDirY, stepX, stepY)
-	textureX := calculateTextureCoordinate(side, hitX, hitY)
+		// A linked portal continues the ray out of its partner. Portals are
+		// whole tiles apart, so shifting the origin by the same offset keeps
+		// the DDA state and distances valid on the far side.
+		if exit, ok := r.portals[[2]int{mapX, mapY}]; ok && portals < MaxPortalDepth {
+			posX += float64(exit[0] - mapX)
+			posY += float64(exit[1] - mapY)
+			mapX, mapY = exit[0], exit[1]
+			portals++
+			continue
+		}
+
+		perpWallDist, hitX, hitY := calculateWallDistance(side, mapX, mapY, posX, posY, rayDirX, rayDirY, stepX, stepY)
+		textureX := calculateTextureCoordinate(side, hitX, hitY)
+
+		rh := RayHit{
+			Distance: math.Abs(perpWallDist),
+			WallType: r.Map[mapY][mapX],
+			Side:     side,
+			HitX:     hitX,
+			HitY:     hitY,
+			TextureX: textureX,
+			Height:   r.TileHeight(mapX, mapY),
+			Portals:  portals,
+		}
 
-	return RayHit{
-		Distance: math.Abs(perpWallDist),
-		WallType: r.Map[mapY][mapX],
-		Side:     side,
-		HitX:     hitX,
-		HitY:     hitY,
-		TextureX: textureX,
+		// Partial-height and transparent walls are recorded and the ray
+		// keeps going so anything behind them is still visible.
+		seeThrough := rh.Height < HeightStandard || IsTransparentTile(rh.WallType)
+		if seeThrough && len(layers) < maxLayers {
+			layers = append(layers, rh)
+			continue
+		}
+		rh.Layers = layers
+		return rh
 	}
 }
 
@@ -165,7 +302,8 @@ func performDDA(mapX, mapY *int, sideDistX, sideDistY *float64, deltaDistX, delt
 
 // IsWallTile returns true if a tile value represents a solid wall that should
 // stop rays and block line of sight. Floor tiles (0, 2, 20-29) are not walls.
-// Wall tiles (1, 3=door, 4=secret, 10-14=genre walls) are solid.
+// Wall tiles (1, 3=door, 4=secret, 10-19=genre walls and variants) are solid.
+// Transparent tiles (30-39) also stop DDA steps; CastRays looks past them.
 func IsWallTile(tile int) bool {
 	if tile == 0 || tile == 2 {
 		return false
@@ -176,6 +314,19 @@ func IsWallTile(tile int) bool {
 	return tile > 0
 }
 
+// IsPortalTile returns true for portal tiles (40-49). Two tiles sharing a
+// portal ID are linked; rays and players entering one leave the other.
+func IsPortalTile(tile int) bool {
+	return tile >= 40 && tile <= 49
+}
+
+// IsTransparentTile returns true for see-through wall tiles (30-39: bars,
+// windows, force fields) whose textures have cutouts. They block movement
+// like walls, but rays continue past them to the walls behind.
+func IsTransparentTile(tile int) bool {
+	return tile >= 30 && tile <= 39
+}
+
 // calculateWallDistance computes the perpendicular distance to the wall and hit coordinates.
 func calculateWallDistance(side, mapX, mapY int, posX, posY, rayDirX, rayDirY float64, stepX, stepY int) (float64, float64, float64) {
 	var perpWallDist, hitX, hitY float64
@@ -1297,3 +1297,233 @@ func TestRayHit_TextureX_DifferentPositions(t *testing.T) {
 		})
 	}
 }
+
+func TestTileHeight(t *testing.T) {
+	r := NewRaycaster(66.0, 320, 200)
+	if h := r.TileHeight(1, 1); h != HeightStandard {
+		t.Errorf("TileHeight without height map = %v, want %v", h, HeightStandard)
+	}
+
+	r.SetHeightMap([][]float64{
+		{0, HeightLowCover},
+		{HeightTallHall, 0},
+	})
+	tests := []struct {
+		x, y int
+		want float64
+	}{
+		{0, 0, HeightStandard},
+		{1, 0, HeightLowCover},
+		{0, 1, HeightTallHall},
+		{5, 5, HeightStandard},
+		{-1, 0, HeightStandard},
+	}
+	for _, tt := range tests {
+		if got := r.TileHeight(tt.x, tt.y); got != tt.want {
+			t.Errorf("TileHeight(%d,%d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestCastRayThroughPartialWall(t *testing.T) {
+	r := NewRaycaster(66.0, 320, 200)
+	r.SetMap([][]int{
+		{1, 1, 1, 1, 1, 1},
+		{1, 0, 1, 0, 0, 1},
+		{1, 1, 1, 1, 1, 1},
+	})
+	heights := [][]float64{
+		{0, 0, 0, 0, 0, 0},
+		{0, 0, HeightLowCover, 0, 0, 0},
+		{0, 0, 0, 0, 0, 0},
+	}
+	r.SetHeightMap(heights)
+
+	hit := r.castRay(1.5, 1.5, 1.0, 0.0)
+	if hit.Distance < 3.4 || hit.Distance > 3.6 {
+		t.Errorf("full wall distance = %v, want ~3.5", hit.Distance)
+	}
+	if len(hit.Layers) != 1 {
+		t.Fatalf("partial hits = %d, want 1", len(hit.Layers))
+	}
+	if p := hit.Layers[0]; p.Height != HeightLowCover || p.Distance < 0.4 || p.Distance > 0.6 {
+		t.Errorf("partial hit = %+v, want low cover at ~0.5", p)
+	}
+
+	// Without a height map the same tile stops the ray.
+	r.SetHeightMap(nil)
+	hit = r.castRay(1.5, 1.5, 1.0, 0.0)
+	if len(hit.Layers) != 0 || hit.Distance > 0.6 {
+		t.Errorf("standard wall: distance %v partial %d, want ~0.5 and 0", hit.Distance, len(hit.Layers))
+	}
+}
+
+func TestWallSpan(t *testing.T) {
+	full := RayHit{Distance: 2.0, WallType: 1}
+	top, bottom := WallSpan(full, 200)
+	if top != 50 || bottom != 150 {
+		t.Errorf("standard span = (%d,%d), want (50,150)", top, bottom)
+	}
+
+	low := RayHit{Distance: 2.0, WallType: 1, Height: 0.5}
+	top, bottom = WallSpan(low, 200)
+	if top != 100 || bottom != 150 {
+		t.Errorf("half span = (%d,%d), want (100,150)", top, bottom)
+	}
+
+	tall := RayHit{Distance: 2.0, WallType: 1, Height: HeightTallHall}
+	top, bottom = WallSpan(tall, 200)
+	if top != -50 || bottom != 150 {
+		t.Errorf("tall span = (%d,%d), want (-50,150)", top, bottom)
+	}
+}
+
+func TestIsOccluded(t *testing.T) {
+	hit := RayHit{
+		Distance: 8.0,
+		WallType: 1,
+		Layers:   []RayHit{{Distance: 2.0, WallType: 1, Height: 0.5}},
+	}
+
+	// Sprite behind the half wall: lower rows hidden, upper rows visible.
+	if !IsOccluded(hit, 140, 4.0, 200) {
+		t.Error("row below half-wall top should be occluded")
+	}
+	if IsOccluded(hit, 60, 4.0, 200) {
+		t.Error("row above half-wall top should be visible")
+	}
+	// Sprite in front of the half wall is never occluded by it.
+	if IsOccluded(hit, 140, 1.0, 200) {
+		t.Error("sprite nearer than half wall should be visible")
+	}
+	// Sprite behind the full wall is hidden where the wall is drawn.
+	if !IsOccluded(hit, 100, 10.0, 200) {
+		t.Error("sprite behind full wall should be occluded")
+	}
+}
+
+func TestCastRayThroughTransparentWall(t *testing.T) {
+	r := NewRaycaster(66.0, 320, 200)
+	r.SetMap([][]int{
+		{1, 1, 1, 1, 1, 1},
+		{1, 0, 30, 0, 31, 1},
+		{1, 1, 1, 1, 1, 1},
+	})
+
+	hit := r.castRay(1.5, 1.5, 1.0, 0.0)
+	if hit.WallType != 1 || hit.Distance < 3.4 || hit.Distance > 3.6 {
+		t.Errorf("back wall = type %d at %v, want type 1 at ~3.5", hit.WallType, hit.Distance)
+	}
+	if len(hit.Layers) != 2 {
+		t.Fatalf("layers = %d, want 2", len(hit.Layers))
+	}
+	if hit.Layers[0].WallType != 30 || hit.Layers[1].WallType != 31 {
+		t.Errorf("layer types = %d,%d, want 30,31 nearest first", hit.Layers[0].WallType, hit.Layers[1].WallType)
+	}
+	if hit.Layers[0].Distance >= hit.Layers[1].Distance {
+		t.Error("layers should be ordered nearest first")
+	}
+}
+
+func TestCastRayThroughPortal(t *testing.T) {
+	r := NewRaycaster(66.0, 320, 200)
+	r.SetMap([][]int{
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		{1, 0, 0, 40, 1, 1, 40, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	})
+
+	hit := r.castRay(1.5, 1.5, 1.0, 0.0)
+	if hit.Portals != 1 {
+		t.Errorf("Portals = %d, want 1", hit.Portals)
+	}
+	if hit.WallType != 1 || math.Abs(hit.Distance-5.5) > 1e-9 {
+		t.Errorf("hit = type %d at %v, want type 1 at 5.5", hit.WallType, hit.Distance)
+	}
+	if math.Abs(hit.HitX-10) > 1e-9 {
+		t.Errorf("HitX = %v, want 10 on the far side of the portal", hit.HitX)
+	}
+}
+
+func TestCastRayPortalDepthLimit(t *testing.T) {
+	r := NewRaycaster(66.0, 320, 200)
+	// Two portals facing each other form an endless hall.
+	r.SetMap([][]int{
+		{1, 1, 1, 1, 1, 1},
+		{1, 40, 0, 0, 40, 1},
+		{1, 1, 1, 1, 1, 1},
+	})
+
+	hit := r.castRay(2.5, 1.5, 1.0, 0.0)
+	if hit.Portals != MaxPortalDepth {
+		t.Errorf("Portals = %d, want %d", hit.Portals, MaxPortalDepth)
+	}
+	if hit.WallType != 40 {
+		t.Errorf("WallType = %d, want the portal tile at the depth limit", hit.WallType)
+	}
+	want := 1.5 + 3*float64(MaxPortalDepth)
+	if math.Abs(hit.Distance-want) > 1e-9 {
+		t.Errorf("Distance = %v, want %v", hit.Distance, want)
+	}
+}
+
+func TestPortalExit(t *testing.T) {
+	r := NewRaycaster(66.0, 320, 200)
+	r.SetMap([][]int{
+		{1, 1, 1, 1, 1},
+		{40, 0, 41, 0, 40},
+		{1, 1, 1, 1, 1},
+	})
+
+	tests := []struct {
+		name         string
+		x, y         int
+		wantX, wantY int
+		wantOK       bool
+	}{
+		{"Linked west end", 0, 1, 4, 1, true},
+		{"Linked east end", 4, 1, 0, 1, true},
+		{"Unpaired portal", 2, 1, 0, 0, false},
+		{"Plain floor", 1, 1, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, ok := r.PortalExit(tt.x, tt.y)
+			if ok != tt.wantOK || (ok && (x != tt.wantX || y != tt.wantY)) {
+				t.Errorf("PortalExit(%d,%d) = (%d,%d,%v), want (%d,%d,%v)", tt.x, tt.y, x, y, ok, tt.wantX, tt.wantY, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsTransparentTile(t *testing.T) {
+	tests := []struct {
+		tile int
+		want bool
+	}{
+		{0, false},
+		{1, false},
+		{19, false},
+		{25, false},
+		{30, true},
+		{32, true},
+		{39, true},
+		{40, false},
+	}
+	for _, tt := range tests {
+		if got := IsTransparentTile(tt.tile); got != tt.want {
+			t.Errorf("IsTransparentTile(%d) = %v, want %v", tt.tile, got, tt.want)
+		}
+	}
+}
+
+func TestIsOccludedIgnoresTransparentLayers(t *testing.T) {
+	hit := RayHit{
+		Distance: 8.0,
+		WallType: 1,
+		Layers:   []RayHit{{Distance: 2.0, WallType: 30}},
+	}
+	if IsOccluded(hit, 100, 4.0, 200) {
+		t.Error("sprite behind bars should not be occluded")
+	}
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// screenshotAttempts bounds the suffixes tried when several screenshots are
+// taken within the same second.
+const screenshotAttempts = 100
+
+// ErrScreenshotExists is returned when no free screenshot file name is left
+// for the current second.
+var ErrScreenshotExists = errors.New("screenshot file already exists")
+
+// CaptureImage copies an ebiten image into CPU memory. It must be called
+// while the game loop is running, e.g. from Draw.
+func CaptureImage(src *ebiten.Image) *image.RGBA {
+	b := src.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	src.ReadPixels(img.Pix)
+	return img
+}
+
+// ScreenshotPath returns a free file name for a screenshot taken at now in
+// dir, of the form violence-20060102-150405.png. Later captures within the
+// same second get a -1, -2, ... suffix.
+func ScreenshotPath(dir string, now time.Time) (string, error) {
+	base := "violence-" + now.Format("20060102-150405")
+	for i := 0; i < screenshotAttempts; i++ {
+		name := base + ".png"
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d.png", base, i)
+		}
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return "", ErrScreenshotExists
+}
+
+// SavePNG encodes img as a PNG file at path, creating parent directories.
+func SavePNG(img image.Image, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SaveScreenshot captures src and saves it as a new PNG in dir, returning
+// the file path.
+func SaveScreenshot(src *ebiten.Image, dir string) (string, error) {
+	path, err := ScreenshotPath(dir, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if err := SavePNG(CaptureImage(src), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,79 @@
+package render
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScreenshotPath(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	first, err := ScreenshotPath(dir, now)
+	if err != nil {
+		t.Fatalf("ScreenshotPath: %v", err)
+	}
+	if want := filepath.Join(dir, "violence-20260304-050607.png"); first != want {
+		t.Errorf("first path = %q, want %q", first, want)
+	}
+
+	if err := os.WriteFile(first, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	second, err := ScreenshotPath(dir, now)
+	if err != nil {
+		t.Fatalf("ScreenshotPath: %v", err)
+	}
+	if want := filepath.Join(dir, "violence-20260304-050607-1.png"); second != want {
+		t.Errorf("second path = %q, want %q", second, want)
+	}
+}
+
+func TestScreenshotPathExhausted(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	for i := 0; i < screenshotAttempts; i++ {
+		path, err := ScreenshotPath(dir, now)
+		if err != nil {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ScreenshotPath(dir, now); !errors.Is(err, ErrScreenshotExists) {
+		t.Errorf("err = %v, want ErrScreenshotExists", err)
+	}
+}
+
+func TestSavePNG(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(1, 1, color.RGBA{R: 10, G: 20, B: 30, A: 255})
+
+	path := filepath.Join(t.TempDir(), "nested", "shot.png")
+	if err := SavePNG(img, path); err != nil {
+		t.Fatalf("SavePNG: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Bounds() != img.Bounds() {
+		t.Errorf("bounds = %v, want %v", got.Bounds(), img.Bounds())
+	}
+	if r, g, b, _ := got.At(1, 1).RGBA(); r>>8 != 10 || g>>8 != 20 || b>>8 != 30 {
+		t.Errorf("pixel = (%d,%d,%d), want (10,20,30)", r>>8, g>>8, b>>8)
+	}
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"image/color"
+	"math"
+)
+
+// blendOver composites src over an opaque dst using src's straight alpha.
+func blendOver(dst, src color.RGBA) color.RGBA {
+	switch src.A {
+	case 0:
+		return dst
+	case 255:
+		return src
+	}
+	a := uint16(src.A)
+	inv := 255 - a
+	return color.RGBA{
+		R: uint8((uint16(src.R)*a + uint16(dst.R)*inv) / 255),
+		G: uint8((uint16(src.G)*a + uint16(dst.G)*inv) / 255),
+		B: uint8((uint16(src.B)*a + uint16(dst.B)*inv) / 255),
+		A: 255,
+	}
+}
+
+// unpremultiply converts a color sampled via image.Image.At, which is
+// alpha-premultiplied, back to straight alpha so shading can apply to it.
+func unpremultiply(c color.RGBA) color.RGBA {
+	if c.A == 0 || c.A == 255 {
+		return c
+	}
+	a := uint16(c.A)
+	return color.RGBA{
+		R: uint8(min(uint16(c.R)*255/a, 255)),
+		G: uint8(min(uint16(c.G)*255/a, 255)),
+		B: uint8(min(uint16(c.B)*255/a, 255)),
+		A: c.A,
+	}
+}
+
+// cutoutFallback returns an untextured see-through wall color when no atlas
+// texture is available. Bars keep their stripes so the cutout still reads.
+func cutoutFallback(wallType int, textureX float64) color.RGBA {
+	switch wallType {
+	case 30: // Bars
+		if math.Mod(textureX*6, 1) < 0.3 {
+			return color.RGBA{70, 70, 75, 255}
+		}
+		return color.RGBA{0, 0, 0, 0}
+	case 31: // Window
+		return color.RGBA{140, 170, 190, 70}
+	default: // Force field
+		return color.RGBA{80, 200, 255, 110}
+	}
+}
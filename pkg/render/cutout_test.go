@@ -0,0 +1,69 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/raycaster"
+)
+
+func TestBlendOver(t *testing.T) {
+	dst := color.RGBA{200, 100, 0, 255}
+	tests := []struct {
+		name string
+		src  color.RGBA
+		want color.RGBA
+	}{
+		{"transparent keeps dst", color.RGBA{0, 0, 0, 0}, dst},
+		{"opaque replaces dst", color.RGBA{10, 20, 30, 255}, color.RGBA{10, 20, 30, 255}},
+		{"half blends", color.RGBA{0, 200, 255, 128}, color.RGBA{99, 150, 128, 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blendOver(dst, tt.src); got != tt.want {
+				t.Errorf("blendOver = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnpremultiply(t *testing.T) {
+	got := unpremultiply(color.RGBA{50, 25, 0, 100})
+	want := color.RGBA{127, 63, 0, 100}
+	if got != want {
+		t.Errorf("unpremultiply = %v, want %v", got, want)
+	}
+}
+
+func TestRenderTransparentWallComposites(t *testing.T) {
+	rc := raycaster.NewRaycaster(66.0, 320, 200)
+	r := NewRenderer(320, 200, rc)
+
+	back := raycaster.RayHit{Distance: 4.0, WallType: 1}
+	window := raycaster.RayHit{Distance: 2.0, WallType: 31}
+
+	// Glass is translucent: the composite differs from both layers alone.
+	solid := r.renderWall(160, 100, back)
+	glass := r.renderWall(160, 100, window)
+	if glass.A == 0 || glass.A == 255 {
+		t.Fatalf("window alpha = %d, want translucent", glass.A)
+	}
+	hits := []raycaster.RayHit{{Distance: 4.0, WallType: 1, Layers: []raycaster.RayHit{window}}}
+	hits = append(make([]raycaster.RayHit, 160), hits...)
+	got := r.computePixelColor(160, 100, hits, nil)
+	if got == solid || got.A != 255 {
+		t.Errorf("composited pixel = %v, want blend over %v", got, solid)
+	}
+
+	// Bars leave gaps: some columns show the wall behind unchanged.
+	open := 0
+	for i := 0; i < 20; i++ {
+		bars := raycaster.RayHit{Distance: 2.0, WallType: 30, TextureX: float64(i) / 20}
+		if r.renderWall(160, 100, bars).A == 0 {
+			open++
+		}
+	}
+	if open == 0 || open == 20 {
+		t.Errorf("bars open columns = %d of 20, want some but not all", open)
+	}
+}
@@ -0,0 +1,134 @@
+package render
+
+import (
+	"image/color"
+	"math"
+)
+
+// PhotoTone is a color treatment applied by photo mode.
+type PhotoTone int
+
+const (
+	PhotoToneNatural PhotoTone = iota // PhotoToneNatural keeps the scene's colors.
+	PhotoToneMono                     // PhotoToneMono renders in grayscale.
+	PhotoToneSepia                    // PhotoToneSepia renders in warm brown monochrome.
+	PhotoToneCool                     // PhotoToneCool shifts colors toward blue.
+	photoToneCount
+)
+
+// String returns the tone name shown in the photo mode hint.
+func (t PhotoTone) String() string {
+	switch t {
+	case PhotoToneNatural:
+		return "natural"
+	case PhotoToneMono:
+		return "mono"
+	case PhotoToneSepia:
+		return "sepia"
+	case PhotoToneCool:
+		return "cool"
+	default:
+		return "unknown"
+	}
+}
+
+// Next returns the tone after t, wrapping around.
+func (t PhotoTone) Next() PhotoTone {
+	return (t + 1) % photoToneCount
+}
+
+// Photo filter limits.
+const (
+	PhotoExposureMax   = 2.0 // Stops above or below the scene's exposure
+	PhotoContrastMin   = 0.5
+	PhotoContrastMax   = 2.0
+	PhotoSaturationMax = 2.0
+)
+
+// photoNoiseEffects are the effects a clean photo skips: grain, lines and
+// flashes that read as noise in a still image.
+const photoNoiseEffects = EffectFilmGrain | EffectScanlines | EffectFilmScratches | EffectStaticBurst
+
+// PhotoFilter holds the adjustable post-process filters of photo mode. They
+// run after the genre effects.
+type PhotoFilter struct {
+	Tone       PhotoTone
+	Exposure   float64 // Brightness change in stops, -PhotoExposureMax to PhotoExposureMax
+	Contrast   float64 // 1 is neutral
+	Saturation float64 // 1 is neutral, 0 is grayscale
+	Vignette   float64 // Extra edge darkening, 0 to 1
+	Clean      bool    // Skip noise effects of the genre preset
+}
+
+// NewPhotoFilter returns a filter that leaves the frame unchanged.
+func NewPhotoFilter() PhotoFilter {
+	return PhotoFilter{Contrast: 1, Saturation: 1}
+}
+
+// Clamp limits every adjustment to its valid range.
+func (f *PhotoFilter) Clamp() {
+	f.Tone = ((f.Tone % photoToneCount) + photoToneCount) % photoToneCount
+	f.Exposure = math.Min(math.Max(f.Exposure, -PhotoExposureMax), PhotoExposureMax)
+	f.Contrast = math.Min(math.Max(f.Contrast, PhotoContrastMin), PhotoContrastMax)
+	f.Saturation = math.Min(math.Max(f.Saturation, 0), PhotoSaturationMax)
+	f.Vignette = math.Min(math.Max(f.Vignette, 0), 1)
+}
+
+// SetPhotoFilter applies a photo filter to every following frame. Pass nil
+// to return to normal rendering.
+func (p *PostProcessor) SetPhotoFilter(f *PhotoFilter) {
+	p.photo = f
+}
+
+// ApplyPhotoFilter applies exposure, contrast, saturation, tone and vignette
+// to a framebuffer.
+func (p *PostProcessor) ApplyPhotoFilter(framebuffer []byte, f PhotoFilter) {
+	gain := math.Exp2(f.Exposure)
+	for i := 0; i+3 < len(framebuffer) && i < p.width*p.height*4; i += 4 {
+		r := float64(framebuffer[i]) / 255.0 * gain
+		g := float64(framebuffer[i+1]) / 255.0 * gain
+		b := float64(framebuffer[i+2]) / 255.0 * gain
+
+		r = (r-0.5)*f.Contrast + 0.5
+		g = (g-0.5)*f.Contrast + 0.5
+		b = (b-0.5)*f.Contrast + 0.5
+
+		luma := r*0.299 + g*0.587 + b*0.114
+		r = luma + (r-luma)*f.Saturation
+		g = luma + (g-luma)*f.Saturation
+		b = luma + (b-luma)*f.Saturation
+
+		r, g, b = photoTone(f.Tone, r, g, b)
+
+		// Round rather than truncate so a neutral filter is lossless.
+		framebuffer[i] = uint8(math.Round(clamp(r * 255.0)))
+		framebuffer[i+1] = uint8(math.Round(clamp(g * 255.0)))
+		framebuffer[i+2] = uint8(math.Round(clamp(b * 255.0)))
+	}
+
+	if f.Vignette > 0 {
+		p.ApplyVignette(framebuffer, VignetteConfig{
+			Enabled:   true,
+			Intensity: f.Vignette,
+			Power:     2.0,
+			Tint:      color.RGBA{A: 255},
+		})
+	}
+}
+
+// photoTone maps a color through a photo tone.
+func photoTone(t PhotoTone, r, g, b float64) (float64, float64, float64) {
+	switch t {
+	case PhotoToneMono:
+		l := r*0.299 + g*0.587 + b*0.114
+		return l, l, l
+	case PhotoToneSepia:
+		return r*0.393 + g*0.769 + b*0.189,
+			r*0.349 + g*0.686 + b*0.168,
+			r*0.272 + g*0.534 + b*0.131
+	case PhotoToneCool:
+		return r * 0.9, g * 0.97, b*1.1 + 0.03
+	default:
+		return r, g, b
+	}
+}
@@ -0,0 +1,110 @@
+package render
+
+import (
+	"testing"
+)
+
+func solidFramebuffer(w, h int, r, g, b byte) []byte {
+	fb := make([]byte, w*h*4)
+	for i := 0; i < len(fb); i += 4 {
+		fb[i], fb[i+1], fb[i+2], fb[i+3] = r, g, b, 255
+	}
+	return fb
+}
+
+func TestPhotoToneNext(t *testing.T) {
+	tone := PhotoToneNatural
+	seen := map[PhotoTone]bool{}
+	for i := 0; i < int(photoToneCount); i++ {
+		seen[tone] = true
+		tone = tone.Next()
+	}
+	if tone != PhotoToneNatural {
+		t.Errorf("tone after a full cycle = %v, want natural", tone)
+	}
+	if len(seen) != int(photoToneCount) {
+		t.Errorf("cycle visited %d tones, want %d", len(seen), photoToneCount)
+	}
+}
+
+func TestPhotoFilterClamp(t *testing.T) {
+	f := PhotoFilter{Tone: -1, Exposure: 9, Contrast: 0, Saturation: -1, Vignette: 3}
+	f.Clamp()
+	want := PhotoFilter{Tone: PhotoToneCool, Exposure: PhotoExposureMax, Contrast: PhotoContrastMin, Saturation: 0, Vignette: 1}
+	if f != want {
+		t.Errorf("Clamp() = %+v, want %+v", f, want)
+	}
+}
+
+func TestApplyPhotoFilter(t *testing.T) {
+	pp := NewPostProcessor(4, 4, 1)
+
+	tests := []struct {
+		name   string
+		filter PhotoFilter
+		check  func(r, g, b byte) bool
+	}{
+		{"neutral keeps color", NewPhotoFilter(), func(r, g, b byte) bool {
+			return r == 200 && g == 100 && b == 50
+		}},
+		{"mono is gray", PhotoFilter{Tone: PhotoToneMono, Contrast: 1, Saturation: 1}, func(r, g, b byte) bool {
+			return r == g && g == b
+		}},
+		{"zero saturation is gray", PhotoFilter{Contrast: 1}, func(r, g, b byte) bool {
+			return r == g && g == b
+		}},
+		{"sepia is warm", PhotoFilter{Tone: PhotoToneSepia, Contrast: 1, Saturation: 1}, func(r, g, b byte) bool {
+			return r > g && g > b
+		}},
+		{"exposure brightens", PhotoFilter{Exposure: 1, Contrast: 1, Saturation: 1}, func(r, g, b byte) bool {
+			return r == 255 && g == 200 && b == 100
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := solidFramebuffer(4, 4, 200, 100, 50)
+			pp.ApplyPhotoFilter(fb, tt.filter)
+			if !tt.check(fb[0], fb[1], fb[2]) {
+				t.Errorf("pixel = (%d,%d,%d)", fb[0], fb[1], fb[2])
+			}
+		})
+	}
+}
+
+func TestPhotoFilterVignetteDarkensEdges(t *testing.T) {
+	pp := NewPostProcessor(9, 9, 1)
+	fb := solidFramebuffer(9, 9, 200, 200, 200)
+	f := NewPhotoFilter()
+	f.Vignette = 1
+	pp.ApplyPhotoFilter(fb, f)
+
+	center := fb[(4*9+4)*4]
+	corner := fb[0]
+	if corner >= center {
+		t.Errorf("corner %d not darker than center %d", corner, center)
+	}
+}
+
+func TestCleanPhotoSkipsNoiseEffects(t *testing.T) {
+	pp := NewPostProcessor(4, 4, 1)
+	if !pp.allowed(EffectFilmGrain) {
+		t.Fatal("film grain should be allowed by default")
+	}
+
+	f := NewPhotoFilter()
+	f.Clean = true
+	pp.SetPhotoFilter(&f)
+	for _, e := range []PostEffect{EffectFilmGrain, EffectScanlines, EffectFilmScratches, EffectStaticBurst} {
+		if pp.allowed(e) {
+			t.Errorf("effect %b allowed in a clean photo", e)
+		}
+	}
+	if !pp.allowed(EffectColorGrade) || !pp.allowed(EffectVignette) {
+		t.Error("clean photo should keep color grading and vignette")
+	}
+
+	pp.SetPhotoFilter(nil)
+	if !pp.allowed(EffectFilmGrain) {
+		t.Error("film grain still blocked after leaving photo mode")
+	}
+}
@@ -14,7 +14,9 @@ type PostProcessor struct {
 	seed             int64
 	rng              *rand.Rand
 	genreID          string
-	staticBurstTimer int // Frame counter for static burst timing
+	staticBurstTimer int          // Frame counter for static burst timing
+	effects          PostEffect   // Effects allowed by the quality tier
+	photo            *PhotoFilter // Photo mode filter; nil outside photo mode
 }
 
 // NewPostProcessor creates a post-processor for the given dimensions.
@@ -25,9 +27,32 @@ func NewPostProcessor(width, height int, seed int64) *PostProcessor {
 		seed:    seed,
 		rng:     rand.New(rand.NewSource(seed)),
 		genreID: "fantasy",
+		effects: EffectsAll,
 	}
 }
 
+// SetEffects restricts which post effects may run, e.g. for a quality tier.
+// Effects also need to be enabled by the genre preset.
+func (p *PostProcessor) SetEffects(effects PostEffect) {
+	p.effects = effects
+}
+
+// Resize updates the framebuffer dimensions the effects assume.
+func (p *PostProcessor) Resize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// allowed reports whether the quality tier permits an effect. A clean photo
+// filter also turns off noise effects.
+func (p *PostProcessor) allowed(effect PostEffect) bool {
+	effects := p.effects
+	if p.photo != nil && p.photo.Clean {
+		effects &^= photoNoiseEffects
+	}
+	return effects&effect != 0
+}
+
 // SetGenre configures the post-processor for a genre.
 func (p *PostProcessor) SetGenre(genreID string) {
 	p.genreID = genreID
@@ -40,6 +65,9 @@ func (p *PostProcessor) Apply(framebuffer []byte) {
 	p.rng = rand.New(rand.NewSource(p.seed))
 
 	applyAllEffects(p, framebuffer, preset)
+	if p.photo != nil {
+		p.ApplyPhotoFilter(framebuffer, *p.photo)
+	}
 }
 
 // applyAllEffects applies all enabled post-processing effects in sequence.
@@ -51,36 +79,36 @@ func applyAllEffects(p *PostProcessor, framebuffer []byte, preset GenrePreset) {
 
 // applyColorEffects applies color-based post-processing effects.
 func applyColorEffects(p *PostProcessor, framebuffer []byte, preset GenrePreset) {
-	if preset.ColorGrade.Enabled {
+	if preset.ColorGrade.Enabled && p.allowed(EffectColorGrade) {
 		p.ApplyColorGrade(framebuffer, preset.ColorGrade)
 	}
-	if preset.Vignette.Enabled {
+	if preset.Vignette.Enabled && p.allowed(EffectVignette) {
 		p.ApplyVignette(framebuffer, preset.Vignette)
 	}
-	if preset.Bloom.Enabled {
+	if preset.Bloom.Enabled && p.allowed(EffectBloom) {
 		p.ApplyBloom(framebuffer, preset.Bloom)
 	}
 }
 
 // applyTextureEffects applies texture-based post-processing effects.
 func applyTextureEffects(p *PostProcessor, framebuffer []byte, preset GenrePreset) {
-	if preset.FilmGrain.Enabled {
+	if preset.FilmGrain.Enabled && p.allowed(EffectFilmGrain) {
 		p.ApplyFilmGrain(framebuffer, preset.FilmGrain)
 	}
-	if preset.Scanlines.Enabled {
+	if preset.Scanlines.Enabled && p.allowed(EffectScanlines) {
 		p.ApplyScanlines(framebuffer, preset.Scanlines)
 	}
-	if preset.FilmScratches.Enabled {
+	if preset.FilmScratches.Enabled && p.allowed(EffectFilmScratches) {
 		p.ApplyFilmScratches(framebuffer, preset.FilmScratches)
 	}
 }
 
 // applyDistortionEffects applies distortion-based post-processing effects.
 func applyDistortionEffects(p *PostProcessor, framebuffer []byte, preset GenrePreset) {
-	if preset.ChromaticAberration.Enabled {
+	if preset.ChromaticAberration.Enabled && p.allowed(EffectChromaticAberration) {
 		p.ApplyChromaticAberration(framebuffer, preset.ChromaticAberration)
 	}
-	if preset.StaticBurst.Enabled {
+	if preset.StaticBurst.Enabled && p.allowed(EffectStaticBurst) {
 		p.ApplyStaticBurst(framebuffer, preset.StaticBurst)
 	}
 }
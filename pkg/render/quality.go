@@ -0,0 +1,109 @@
+package render
+
+import (
+	"errors"
+	"strings"
+)
+
+// QualityTier selects a preset trade-off between visual fidelity and frame
+// time.
+type QualityTier int
+
+const (
+	QualityLow    QualityTier = iota // QualityLow favors frame rate on weak hardware.
+	QualityMedium                    // QualityMedium keeps shadows and cheap post effects.
+	QualityHigh                      // QualityHigh enables every effect.
+	QualityUltra                     // QualityUltra also pins the full internal resolution.
+)
+
+// ErrUnknownQualityTier is returned when a quality tier name is not recognized.
+var ErrUnknownQualityTier = errors.New("unknown quality tier")
+
+// String returns the tier name used in the config file.
+func (q QualityTier) String() string {
+	switch q {
+	case QualityLow:
+		return "low"
+	case QualityMedium:
+		return "medium"
+	case QualityHigh:
+		return "high"
+	case QualityUltra:
+		return "ultra"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseQualityTier converts a config name ("low", "medium", "high",
+// "ultra") to a QualityTier. Matching ignores case and surrounding space.
+func ParseQualityTier(name string) (QualityTier, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "low":
+		return QualityLow, nil
+	case "medium":
+		return QualityMedium, nil
+	case "high":
+		return QualityHigh, nil
+	case "ultra":
+		return QualityUltra, nil
+	default:
+		return QualityHigh, ErrUnknownQualityTier
+	}
+}
+
+// PostEffect is a bit set of post-processing effects.
+type PostEffect uint16
+
+const (
+	EffectColorGrade          PostEffect = 1 << iota // EffectColorGrade is genre color grading.
+	EffectVignette                                   // EffectVignette darkens screen edges.
+	EffectBloom                                      // EffectBloom blurs bright pixels; the most expensive effect.
+	EffectFilmGrain                                  // EffectFilmGrain adds per-pixel noise.
+	EffectScanlines                                  // EffectScanlines darkens alternate rows.
+	EffectFilmScratches                              // EffectFilmScratches draws vertical scratches.
+	EffectChromaticAberration                        // EffectChromaticAberration splits color channels.
+	EffectStaticBurst                                // EffectStaticBurst flashes horror static.
+
+	// EffectsAll enables every post-processing effect.
+	EffectsAll = EffectColorGrade | EffectVignette | EffectBloom | EffectFilmGrain |
+		EffectScanlines | EffectFilmScratches | EffectChromaticAberration | EffectStaticBurst
+)
+
+// QualitySettings are the concrete switches a quality tier controls.
+type QualitySettings struct {
+	Shadows        bool       // Dynamic shadow casting
+	PostEffects    PostEffect // Post-processing effects allowed to run
+	MinRenderScale float64    // Lowest internal resolution scale dynamic scaling may use
+}
+
+// Settings returns the switches for a quality tier. Genre presets still
+// decide which of the allowed post effects actually run.
+func (q QualityTier) Settings() QualitySettings {
+	switch q {
+	case QualityLow:
+		return QualitySettings{
+			Shadows:        false,
+			PostEffects:    EffectColorGrade,
+			MinRenderScale: 0.5,
+		}
+	case QualityMedium:
+		return QualitySettings{
+			Shadows:        true,
+			PostEffects:    EffectColorGrade | EffectVignette | EffectScanlines | EffectStaticBurst,
+			MinRenderScale: 0.6,
+		}
+	case QualityUltra:
+		return QualitySettings{
+			Shadows:        true,
+			PostEffects:    EffectsAll,
+			MinRenderScale: 1.0,
+		}
+	default:
+		return QualitySettings{
+			Shadows:        true,
+			PostEffects:    EffectsAll,
+			MinRenderScale: 0.75,
+		}
+	}
+}
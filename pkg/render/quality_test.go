@@ -0,0 +1,79 @@
+package render
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQualityTier(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    QualityTier
+		wantErr error
+	}{
+		{"low", QualityLow, nil},
+		{"Medium", QualityMedium, nil},
+		{" high ", QualityHigh, nil},
+		{"ULTRA", QualityUltra, nil},
+		{"extreme", QualityHigh, ErrUnknownQualityTier},
+		{"", QualityHigh, ErrUnknownQualityTier},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQualityTier(tt.name)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseQualityTier(%q) = %v, %v; want %v, %v", tt.name, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQualityTierStringRoundTrip(t *testing.T) {
+	for _, q := range []QualityTier{QualityLow, QualityMedium, QualityHigh, QualityUltra} {
+		got, err := ParseQualityTier(q.String())
+		if err != nil || got != q {
+			t.Errorf("ParseQualityTier(%q) = %v, %v; want %v", q.String(), got, err, q)
+		}
+	}
+}
+
+func TestQualitySettingsOrdering(t *testing.T) {
+	low := QualityLow.Settings()
+	high := QualityHigh.Settings()
+	ultra := QualityUltra.Settings()
+
+	if low.Shadows {
+		t.Error("low tier should disable shadows")
+	}
+	if low.PostEffects&EffectBloom != 0 {
+		t.Error("low tier should disable bloom")
+	}
+	if high.PostEffects != EffectsAll {
+		t.Errorf("high tier effects = %b, want all", high.PostEffects)
+	}
+	if !(low.MinRenderScale < high.MinRenderScale && high.MinRenderScale < ultra.MinRenderScale) {
+		t.Errorf("min render scales not increasing: %v, %v, %v", low.MinRenderScale, high.MinRenderScale, ultra.MinRenderScale)
+	}
+	if ultra.MinRenderScale != 1 {
+		t.Errorf("ultra min render scale = %v, want 1", ultra.MinRenderScale)
+	}
+}
+
+func TestPostProcessorSetEffects(t *testing.T) {
+	fb := make([]byte, 16*16*4)
+	for i := range fb {
+		fb[i] = 128
+	}
+	want := append([]byte(nil), fb...)
+
+	p := NewPostProcessor(16, 16, 1)
+	p.SetGenre("horror")
+	p.SetEffects(0)
+	p.Apply(fb)
+
+	for i := range fb {
+		if fb[i] != want[i] {
+			t.Fatalf("byte %d changed to %d with all effects disabled", i, fb[i])
+		}
+	}
+}
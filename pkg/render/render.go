@@ -4,6 +4,8 @@ package render
 import (
 	"image"
 	"image/color"
+	"math"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/opd-ai/violence/pkg/raycaster"
@@ -24,6 +26,14 @@ type LightMap interface {
 	Calculate()
 }
 
+// ColorLightMap is a LightMap that also tracks the color of its lights.
+// When the assigned light map implements it, surfaces and sprites are tinted
+// by the light falling on them instead of only darkened.
+type ColorLightMap interface {
+	LightMap
+	GetLightColor(x, y int) (r, g, b float64)
+}
+
 // EdgeAOProvider is an interface for edge ambient occlusion data.
 // Allows the renderer to darken floor/ceiling near walls and corners.
 type EdgeAOProvider interface {
@@ -32,8 +42,10 @@ type EdgeAOProvider interface {
 
 // Renderer manages the rendering pipeline.
 type Renderer struct {
-	Width         int
-	Height        int
+	Width         int // Internal render width; OutputWidth scaled by the render scale
+	Height        int // Internal render height; OutputHeight scaled by the render scale
+	OutputWidth   int // Size the frame is displayed at
+	OutputHeight  int
 	framebuffer   []byte
 	fbImage       *ebiten.Image // Pre-allocated image reused every frame to avoid WebGL texture leaks
 	raycaster     *raycaster.Raycaster
@@ -44,6 +56,29 @@ type Renderer struct {
 	edgeAO        EdgeAOProvider
 	postProcessor *PostProcessor
 	tick          int
+	materials     *MaterialMap
+	floorTex      image.Image   // Default floor texture resolved for this frame
+	ceilingTex    image.Image   // Default ceiling texture resolved for this frame
+	surfaceTex    []image.Image // MaterialMap textures resolved for this frame
+	skyFar        image.Image   // Sky panorama resolved for this frame
+	skyNear       image.Image   // Translucent sky layer drawn over skyFar
+	skyIndex      uint8         // MaterialMap index of SkyMaterial; 0 when no sky
+	skyU          []float64     // Per-column horizontal sky coordinate
+	sprites       []Sprite      // Billboards registered for the next DrawSprites
+	spriteCam     spriteCamera  // Camera of the last Render, for the sprite pass
+	zbuffer       []float64     // Per-column wall distance of the last Render
+	whitePixel    *ebiten.Image // Source image for untextured sprites
+	stats         FrameStats    // Timings and counts of the last frame
+}
+
+// FrameStats are the timings and draw counts of the last Render and
+// DrawSprites calls, for profiling.
+type FrameStats struct {
+	World        time.Duration // Ray casting and framebuffer fill
+	PostProcess  time.Duration // Post-processing of the framebuffer
+	Sprites      time.Duration // Sprite projection and drawing
+	SpritesDrawn int           // Sprites with at least one visible column
+	SpriteDraws  int           // DrawImage calls issued for sprites
 }
 
 // NewRenderer creates a renderer with the given internal resolution.
@@ -51,6 +86,8 @@ func NewRenderer(width, height int, rc *raycaster.Raycaster) *Renderer {
 	return &Renderer{
 		Width:         width,
 		Height:        height,
+		OutputWidth:   width,
+		OutputHeight:  height,
 		framebuffer:   make([]byte, width*height*4),
 		fbImage:       ebiten.NewImage(width, height),
 		raycaster:     rc,
@@ -77,9 +114,41 @@ func (r *Renderer) SetEdgeAO(edgeAO EdgeAOProvider) {
 	r.edgeAO = edgeAO
 }
 
-// SetPostProcessor assigns a post-processor for visual effects.
+// SetPostProcessor assigns a post-processor for visual effects. It is sized
+// to the current internal resolution.
 func (r *Renderer) SetPostProcessor(pp *PostProcessor) {
 	r.postProcessor = pp
+	if pp != nil {
+		pp.Resize(r.Width, r.Height)
+	}
+}
+
+// SetRenderScale sets the internal resolution as a fraction (0, 1] of the
+// output size. Frames are rendered at the reduced size and stretched on
+// display, trading sharpness for frame time. The raycaster is resized too.
+func (r *Renderer) SetRenderScale(scale float64) {
+	scale = math.Min(math.Max(scale, 0.1), 1)
+	width := max(1, int(math.Round(float64(r.OutputWidth)*scale)))
+	height := max(1, int(math.Round(float64(r.OutputHeight)*scale)))
+	if width == r.Width && height == r.Height {
+		return
+	}
+
+	r.Width, r.Height = width, height
+	r.framebuffer = make([]byte, width*height*4)
+	if r.fbImage != nil {
+		r.fbImage.Deallocate()
+	}
+	r.fbImage = ebiten.NewImage(width, height)
+	r.raycaster.Width, r.raycaster.Height = width, height
+	if r.postProcessor != nil {
+		r.postProcessor.Resize(width, height)
+	}
+}
+
+// RenderScale returns the current internal resolution scale.
+func (r *Renderer) RenderScale() float64 {
+	return float64(r.Width) / float64(r.OutputWidth)
 }
 
 // Tick increments the frame counter for animated textures.
@@ -89,40 +158,66 @@ func (r *Renderer) Tick() {
 
 // Render draws a frame to the given screen image.
 // Calls raycaster, writes column data to framebuffer, blits to screen.
+// The camera and wall depths are kept for a following DrawSprites call.
 func (r *Renderer) Render(screen *ebiten.Image, posX, posY, dirX, dirY, pitch float64) {
+	start := time.Now()
 	hits := r.raycaster.CastRays(posX, posY, dirX, dirY)
+	r.captureSpriteCamera(hits, posX, posY, dirX, dirY)
 	r.renderFrame(hits, posX, posY, dirX, dirY, pitch)
+	post := time.Now()
+	r.stats.World = post.Sub(start)
 	r.applyPostProcessing()
+	r.stats.PostProcess = time.Since(post)
 	r.displayFramebuffer(screen)
 }
 
+// Stats returns the timings and draw counts of the last frame.
+func (r *Renderer) Stats() FrameStats {
+	return r.stats
+}
+
 // renderFrame renders all pixels in the framebuffer using raycasting results.
+// Surface textures are resolved once per frame and floor/ceiling world
+// coordinates once per row, so the per-pixel cost is one texture sample plus
+// lighting and fog.
 func (r *Renderer) renderFrame(hits []raycaster.RayHit, posX, posY, dirX, dirY, pitch float64) {
+	r.resolveSurfaces()
+	r.resolveSky()
+	r.prepareSky(dirX, dirY)
 	for y := 0; y < r.Height; y++ {
+		surface := r.castSurfaceRow(y, posX, posY, dirX, dirY, pitch)
 		for x := 0; x < r.Width; x++ {
 			idx := (y*r.Width + x) * 4
-			c := r.computePixelColor(x, y, hits, posX, posY, dirX, dirY, pitch)
+			c := r.computePixelColor(x, y, hits, surface)
 			r.setFramebufferPixel(idx, c)
 		}
 	}
 }
 
 // computePixelColor determines the color for a single pixel based on position.
-func (r *Renderer) computePixelColor(x, y int, hits []raycaster.RayHit, posX, posY, dirX, dirY, pitch float64) color.RGBA {
+// surface is the row's floor/ceiling casting from castSurfaceRow.
+func (r *Renderer) computePixelColor(x, y int, hits []raycaster.RayHit, surface []raycaster.FloorCeilPixel) color.RGBA {
 	var c color.RGBA
 
 	if y < r.Height/2 {
-		c = r.renderCeiling(x, y, posX, posY, dirX, dirY, pitch)
+		if x < len(surface) && r.isSky(surface[x]) {
+			c = r.renderSky(x, y)
+		} else {
+			c = r.renderSurface(x, surface, false)
+		}
 	} else if y > r.Height/2 {
-		c = r.renderFloor(x, y, posX, posY, dirX, dirY, pitch)
+		c = r.renderSurface(x, surface, true)
 	} else {
 		c = r.palette[0]
 	}
 
 	if x < len(hits) {
-		wallColor := r.renderWall(x, y, hits[x])
-		if wallColor.A > 0 {
-			c = wallColor
+		c = blendOver(c, r.renderWall(x, y, hits[x]))
+		// Partial-height and transparent walls are nearer than the main
+		// hit; composite them farthest first so the closest ends on top.
+		layers := hits[x].Layers
+		for i := len(layers) - 1; i >= 0; i-- {
+			c = blendOver(c, r.renderWall(x, y, layers[i]))
 		}
 	}
 
@@ -147,26 +242,32 @@ func (r *Renderer) applyPostProcessing() {
 // displayFramebuffer writes the framebuffer to the pre-allocated fbImage and draws it to screen.
 // Using WritePixels on a persistent image avoids allocating a new WebGL texture every frame,
 // which would exhaust GPU memory in browsers and cause an all-black rendering output.
+// A reduced render scale is stretched back to the output size.
 func (r *Renderer) displayFramebuffer(screen *ebiten.Image) {
 	r.fbImage.WritePixels(r.framebuffer)
-	screen.DrawImage(r.fbImage, nil)
+	op := &ebiten.DrawImageOptions{}
+	if r.Width != r.OutputWidth || r.Height != r.OutputHeight {
+		op.GeoM.Scale(float64(r.OutputWidth)/float64(r.Width), float64(r.OutputHeight)/float64(r.Height))
+	}
+	screen.DrawImage(r.fbImage, op)
 }
 
 // renderWall computes wall color for a given column and row.
+// Opaque walls return A=255; transparent walls return their cutout
+// coverage in A with straight (non-premultiplied) color.
 func (r *Renderer) renderWall(x, y int, hit raycaster.RayHit) color.RGBA {
 	if hit.Distance >= 1e30 || hit.WallType == 0 {
 		return color.RGBA{0, 0, 0, 0}
 	}
 
-	lineHeight := int(float64(r.Height) / hit.Distance)
-	drawStart := -lineHeight/2 + r.Height/2
-	drawEnd := lineHeight/2 + r.Height/2
+	drawStart, drawEnd := raycaster.WallSpan(hit, r.Height)
 
 	if y < drawStart || y > drawEnd {
 		return color.RGBA{0, 0, 0, 0}
 	}
 
 	var baseColor color.RGBA
+	transparent := raycaster.IsTransparentTile(hit.WallType)
 
 	// Try to sample from texture atlas if available
 	if r.atlas != nil {
@@ -177,18 +278,38 @@ func (r *Renderer) renderWall(x, y int, hit raycaster.RayHit) color.RGBA {
 		} else {
 			// Fall back to static texture
 			textureName := getWallTextureName(hit.WallType)
-			if texture, ok := r.atlas.Get(textureName); ok {
+			texture, ok := r.atlas.Get(textureName)
+			if !ok && isWallDetail(hit.WallType) {
+				// Detail variants fall back to the plain genre wall
+				texture, ok = r.atlas.Get("wall_1")
+			}
+			if ok {
 				baseColor = sampleWallTexture(texture, hit.TextureX, y, drawStart, drawEnd)
+				if transparent {
+					baseColor = unpremultiply(baseColor)
+				}
+			} else if transparent {
+				baseColor = cutoutFallback(hit.WallType, hit.TextureX)
 			} else {
 				// Fallback to palette if texture not found
 				baseColor = r.palette[hit.WallType]
 			}
 		}
+	} else if transparent {
+		baseColor = cutoutFallback(hit.WallType, hit.TextureX)
 	} else {
 		// No atlas: use palette color
 		baseColor = r.palette[hit.WallType]
 	}
 
+	alpha := uint8(255)
+	if transparent {
+		if baseColor.A == 0 {
+			return color.RGBA{0, 0, 0, 0}
+		}
+		alpha = baseColor.A
+	}
+
 	// Darken horizontal walls for visual distinction
 	if hit.Side == 1 {
 		baseColor.R = baseColor.R / 2
@@ -197,13 +318,13 @@ func (r *Renderer) renderWall(x, y int, hit raycaster.RayHit) color.RGBA {
 	}
 
 	// Apply lighting if available
-	lightMult := r.getLightMultiplier(hit.HitX, hit.HitY)
+	light := r.getLightColor(hit.HitX, hit.HitY)
 
 	foggedColor := r.raycaster.ApplyFog(
 		[3]float64{
-			float64(baseColor.R) / 255.0 * lightMult,
-			float64(baseColor.G) / 255.0 * lightMult,
-			float64(baseColor.B) / 255.0 * lightMult,
+			float64(baseColor.R) / 255.0 * light[0],
+			float64(baseColor.G) / 255.0 * light[1],
+			float64(baseColor.B) / 255.0 * light[2],
 		},
 		hit.Distance,
 	)
@@ -212,7 +333,7 @@ func (r *Renderer) renderWall(x, y int, hit raycaster.RayHit) color.RGBA {
 		R: uint8(foggedColor[0] * 255),
 		G: uint8(foggedColor[1] * 255),
 		B: uint8(foggedColor[2] * 255),
-		A: 255,
+		A: alpha,
 	}
 }
 
@@ -235,11 +356,32 @@ func getWallTextureName(wallType int) string {
 		return "wall_4"
 	case 14: // PostApoc rust
 		return "wall_1"
+	case 15: // Worn variant
+		return "wall_worn"
+	case 16: // Cracked variant
+		return "wall_cracked"
+	case 17: // Stained variant
+		return "wall_stained"
+	case 18: // Patterned variant
+		return "wall_patterned"
+	case 19: // Pipes variant
+		return "wall_pipes"
+	case 30: // Bars
+		return "wall_bars"
+	case 31: // Window
+		return "wall_window"
+	case 32: // Force field
+		return "wall_forcefield"
 	default:
 		return "wall_1"
 	}
 }
 
+// isWallDetail reports whether a wall type is a detailing-pass variant.
+func isWallDetail(wallType int) bool {
+	return wallType >= 15 && wallType <= 19
+}
+
 // sampleWallTexture samples a texture at the given coordinates.
 // textureX is the horizontal position along the wall (0.0-1.0).
 // y is the screen row, drawStart/drawEnd define the visible wall segment.
@@ -278,29 +420,25 @@ func sampleWallTexture(texture image.Image, textureX float64, y, drawStart, draw
 }
 
 // renderSurface computes a floor or ceiling color for a given pixel.
-// textureName specifies which texture to sample (e.g., "floor_main", "ceiling_main").
-// paletteIdx specifies the fallback palette color index if no texture is found.
-func (r *Renderer) renderSurface(x int, pixels []raycaster.FloorCeilPixel, textureName string, paletteIdx int) color.RGBA {
+// The texture comes from the tile's MaterialMap entry, falling back to the
+// default surface texture and then to the floor (2) or ceiling (3) palette
+// color. Textures must have been resolved with resolveSurfaces.
+func (r *Renderer) renderSurface(x int, pixels []raycaster.FloorCeilPixel, floor bool) color.RGBA {
 	if x >= len(pixels) {
 		return r.palette[0]
 	}
 
 	var baseColor color.RGBA
-
-	// Try to sample from texture atlas if available
-	if r.atlas != nil {
-		tex, hasTexture := r.atlas.Get(textureName)
-		if hasTexture {
-			baseColor = r.sampleTexture(tex, pixels[x].WorldX, pixels[x].WorldY)
-		} else {
-			baseColor = r.palette[paletteIdx]
-		}
+	if tex := r.surfaceTexture(pixels[x], floor); tex != nil {
+		baseColor = r.sampleTexture(tex, pixels[x].WorldX, pixels[x].WorldY)
+	} else if floor {
+		baseColor = r.palette[2]
 	} else {
-		baseColor = r.palette[paletteIdx]
+		baseColor = r.palette[3]
 	}
 
 	// Apply lighting if available
-	lightMult := r.getLightMultiplier(pixels[x].WorldX, pixels[x].WorldY)
+	light := r.getLightColor(pixels[x].WorldX, pixels[x].WorldY)
 
 	// Apply edge ambient occlusion for environment depth
 	aoMult := 1.0
@@ -311,9 +449,9 @@ func (r *Renderer) renderSurface(x int, pixels []raycaster.FloorCeilPixel, textu
 
 	foggedColor := r.raycaster.ApplyFog(
 		[3]float64{
-			float64(baseColor.R) / 255.0 * lightMult * aoMult,
-			float64(baseColor.G) / 255.0 * lightMult * aoMult,
-			float64(baseColor.B) / 255.0 * lightMult * aoMult,
+			float64(baseColor.R) / 255.0 * light[0] * aoMult,
+			float64(baseColor.G) / 255.0 * light[1] * aoMult,
+			float64(baseColor.B) / 255.0 * light[2] * aoMult,
 		},
 		pixels[x].Distance,
 	)
@@ -328,16 +466,20 @@ func (r *Renderer) renderSurface(x int, pixels []raycaster.FloorCeilPixel, textu
 
 // renderFloor computes floor color for a given pixel.
 // If atlas is set, samples floor texture with perspective-correct coordinates.
+// It casts the whole row, so renderFrame shares rows via castSurfaceRow instead.
 func (r *Renderer) renderFloor(x, y int, posX, posY, dirX, dirY, pitch float64) color.RGBA {
+	r.resolveSurfaces()
 	pixels := r.raycaster.CastFloorCeiling(y, posX, posY, dirX, dirY, pitch)
-	return r.renderSurface(x, pixels, "floor_main", 2)
+	return r.renderSurface(x, pixels, true)
 }
 
 // renderCeiling computes ceiling color for a given pixel.
 // If atlas is set, samples ceiling texture with perspective-correct coordinates.
+// It casts the whole row, so renderFrame shares rows via castSurfaceRow instead.
 func (r *Renderer) renderCeiling(x, y int, posX, posY, dirX, dirY, pitch float64) color.RGBA {
+	r.resolveSurfaces()
 	pixels := r.raycaster.CastFloorCeiling(r.Height-1-y, posX, posY, dirX, dirY, pitch)
-	return r.renderSurface(x, pixels, "ceiling_main", 3)
+	return r.renderSurface(x, pixels, false)
 }
 
 // sampleTexture samples a texture at world coordinates with wrapping.
@@ -399,6 +541,18 @@ func (r *Renderer) getLightMultiplier(worldX, worldY float64) float64 {
 	return r.lightMap.GetLight(tileX, tileY)
 }
 
+// getLightColor returns the RGB lighting multiplier at world coordinates.
+// Light maps without color information light every channel equally.
+// Returns full brightness if no light map is set.
+func (r *Renderer) getLightColor(worldX, worldY float64) [3]float64 {
+	if cm, ok := r.lightMap.(ColorLightMap); ok {
+		lr, lg, lb := cm.GetLightColor(int(worldX), int(worldY))
+		return [3]float64{lr, lg, lb}
+	}
+	l := r.getLightMultiplier(worldX, worldY)
+	return [3]float64{l, l, l}
+}
+
 // SetGenre configures the renderer for a genre.
 func (r *Renderer) SetGenre(genreID string) {
 	r.genreID = genreID
@@ -805,6 +805,93 @@ func TestRenderCeilingWithLighting(t *testing.T) {
 	}
 }
 
+// mockColorLightMap is a test light map with uniform colored light
+type mockColorLightMap struct {
+	mockLightMap
+	r, g, b float64
+}
+
+func (m *mockColorLightMap) GetLightColor(x, y int) (r, g, b float64) {
+	if x < 0 || x >= m.width || y < 0 || y >= m.height {
+		return 0, 0, 0
+	}
+	return m.r, m.g, m.b
+}
+
+func TestGetLightColor(t *testing.T) {
+	rc := raycaster.NewRaycaster(66.0, 320, 200)
+	r := NewRenderer(320, 200, rc)
+
+	tests := []struct {
+		name     string
+		lightMap LightMap
+		expected [3]float64
+	}{
+		{
+			name:     "No light map - full brightness",
+			lightMap: nil,
+			expected: [3]float64{1, 1, 1},
+		},
+		{
+			name:     "Intensity-only light map - gray",
+			lightMap: &mockLightMap{width: 5, height: 5, uniform: 0.4},
+			expected: [3]float64{0.4, 0.4, 0.4},
+		},
+		{
+			name: "Color light map - tinted",
+			lightMap: &mockColorLightMap{
+				mockLightMap: mockLightMap{width: 5, height: 5, uniform: 0.4},
+				r:            0.9, g: 0.5, b: 0.1,
+			},
+			expected: [3]float64{0.9, 0.5, 0.1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.SetLightMap(tt.lightMap)
+			if got := r.getLightColor(2.5, 2.5); got != tt.expected {
+				t.Errorf("getLightColor() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderWallWithColoredLight(t *testing.T) {
+	rc := raycaster.NewRaycaster(66.0, 320, 200)
+	rc.SetMap([][]int{
+		{1, 1, 1, 1, 1},
+		{1, 0, 0, 0, 1},
+		{1, 0, 0, 0, 1},
+		{1, 0, 0, 0, 1},
+		{1, 1, 1, 1, 1},
+	})
+
+	r := NewRenderer(320, 200, rc)
+	r.palette[1] = color.RGBA{R: 200, G: 200, B: 200, A: 255}
+	hit := raycaster.RayHit{Distance: 2.0, WallType: 1, HitX: 2.5, HitY: 2.5}
+
+	r.SetLightMap(&mockColorLightMap{
+		mockLightMap: mockLightMap{width: 5, height: 5, uniform: 1.0},
+		r:            1.0, g: 1.0, b: 1.0,
+	})
+	white := r.renderWall(160, 100, hit)
+
+	r.SetLightMap(&mockColorLightMap{
+		mockLightMap: mockLightMap{width: 5, height: 5, uniform: 1.0},
+		r:            1.0, g: 0.6, b: 0.2,
+	})
+	torch := r.renderWall(160, 100, hit)
+
+	// A torch-colored light keeps red and cuts blue, tinting the wall orange.
+	if torch.R != white.R {
+		t.Errorf("torch-lit R = %d, want %d", torch.R, white.R)
+	}
+	if !(torch.B < torch.G && torch.G < torch.R) {
+		t.Errorf("torch-lit wall = %v, want orange (R > G > B)", torch)
+	}
+}
+
 func TestRenderWithTextureAndLighting(t *testing.T) {
 	rc := raycaster.NewRaycaster(66.0, 320, 200)
 	rc.SetMap([][]int{
@@ -1152,8 +1239,16 @@ func TestGetWallTextureName(t *testing.T) {
 		{12, "wall_3"}, // Horror plaster
 		{13, "wall_4"}, // Cyberpunk concrete
 		{14, "wall_1"}, // PostApoc rust
-		{0, "wall_1"},  // default
-		{5, "wall_1"},  // default
+		{15, "wall_worn"},
+		{16, "wall_cracked"},
+		{17, "wall_stained"},
+		{18, "wall_patterned"},
+		{19, "wall_pipes"},
+		{30, "wall_bars"},
+		{31, "wall_window"},
+		{32, "wall_forcefield"},
+		{0, "wall_1"}, // default
+		{5, "wall_1"}, // default
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,97 @@
+package render
+
+import (
+	"math"
+	"time"
+)
+
+// Dynamic resolution tuning. Render cost is roughly proportional to pixel
+// count, so the scaler predicts the cost of a higher scale from the square of
+// the scale ratio before stepping up.
+const (
+	resolutionStep         = 0.1  // Scale change per adjustment
+	resolutionSmoothing    = 0.1  // Weight of the newest sample in the moving average
+	resolutionDownMargin   = 1.05 // Step down when the average exceeds budget by this factor
+	resolutionUpMargin     = 0.85 // Step up when the predicted cost fits this share of budget
+	resolutionDownCooldown = 15   // Frames to wait after stepping down
+	resolutionUpCooldown   = 90   // Frames to wait after stepping up
+)
+
+// ResolutionScaler adjusts the internal render scale to keep frame time
+// within a budget. Feed it one render time per frame via Observe.
+type ResolutionScaler struct {
+	Budget   time.Duration // Target render time per frame
+	MinScale float64       // Lowest scale to drop to
+	MaxScale float64       // Highest scale to climb to
+
+	scale    float64
+	avgMS    float64
+	cooldown int
+}
+
+// NewResolutionScaler creates a scaler starting at full resolution. minScale
+// is clamped to (0, 1].
+func NewResolutionScaler(budget time.Duration, minScale float64) *ResolutionScaler {
+	if minScale <= 0 || minScale > 1 {
+		minScale = 1
+	}
+	return &ResolutionScaler{
+		Budget:   budget,
+		MinScale: minScale,
+		MaxScale: 1,
+		scale:    1,
+	}
+}
+
+// Scale returns the current render scale.
+func (s *ResolutionScaler) Scale() float64 {
+	return s.scale
+}
+
+// Observe records the render time of a frame and returns the scale to use
+// next, reporting whether it changed.
+func (s *ResolutionScaler) Observe(frame time.Duration) (scale float64, changed bool) {
+	ms := float64(frame) / float64(time.Millisecond)
+	if s.avgMS == 0 {
+		s.avgMS = ms
+	} else {
+		s.avgMS += (ms - s.avgMS) * resolutionSmoothing
+	}
+
+	// Limits may be changed between frames (e.g. a quality tier switch).
+	if clamped := math.Min(math.Max(s.scale, s.MinScale), s.MaxScale); clamped != s.scale {
+		s.scale = clamped
+		return s.scale, true
+	}
+
+	if s.cooldown > 0 {
+		s.cooldown--
+		return s.scale, false
+	}
+
+	budget := float64(s.Budget) / float64(time.Millisecond)
+	switch {
+	case s.avgMS > budget*resolutionDownMargin && s.scale > s.MinScale:
+		next := math.Max(s.scale-resolutionStep, s.MinScale)
+		s.rescale(next)
+		s.cooldown = resolutionDownCooldown
+		return s.scale, true
+	case s.scale < s.MaxScale:
+		next := math.Min(s.scale+resolutionStep, s.MaxScale)
+		ratio := next / s.scale
+		if s.avgMS*ratio*ratio < budget*resolutionUpMargin {
+			s.rescale(next)
+			s.cooldown = resolutionUpCooldown
+			return s.scale, true
+		}
+	}
+	return s.scale, false
+}
+
+// rescale switches to a new scale and adjusts the average to the cost
+// expected there, so the next decision does not act on stale samples.
+func (s *ResolutionScaler) rescale(next float64) {
+	ratio := next / s.scale
+	s.avgMS *= ratio * ratio
+	s.scale = next
+}
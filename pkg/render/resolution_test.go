@@ -0,0 +1,87 @@
+package render
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/violence/pkg/raycaster"
+)
+
+func TestResolutionScalerStepsDownOverBudget(t *testing.T) {
+	s := NewResolutionScaler(10*time.Millisecond, 0.5)
+
+	for i := 0; i < 200; i++ {
+		s.Observe(30 * time.Millisecond)
+	}
+	if s.Scale() != 0.5 {
+		t.Errorf("scale = %v, want min 0.5 under sustained overload", s.Scale())
+	}
+}
+
+func TestResolutionScalerStepsUpWithHeadroom(t *testing.T) {
+	s := NewResolutionScaler(10*time.Millisecond, 0.5)
+	for i := 0; i < 200; i++ {
+		s.Observe(30 * time.Millisecond)
+	}
+
+	for i := 0; i < 2000; i++ {
+		s.Observe(time.Millisecond)
+	}
+	if math.Abs(s.Scale()-1) > 1e-9 {
+		t.Errorf("scale = %v, want 1 with ample headroom", s.Scale())
+	}
+}
+
+func TestResolutionScalerHoldsWithinBudget(t *testing.T) {
+	s := NewResolutionScaler(10*time.Millisecond, 0.5)
+	changes := 0
+	for i := 0; i < 500; i++ {
+		if _, changed := s.Observe(9 * time.Millisecond); changed {
+			changes++
+		}
+	}
+	if changes != 0 || s.Scale() != 1 {
+		t.Errorf("scale = %v after %d changes, want steady 1", s.Scale(), changes)
+	}
+}
+
+func TestResolutionScalerRespectsNewMinimum(t *testing.T) {
+	s := NewResolutionScaler(10*time.Millisecond, 0.5)
+	for i := 0; i < 200; i++ {
+		s.Observe(30 * time.Millisecond)
+	}
+
+	s.MinScale = 0.8
+	scale, changed := s.Observe(30 * time.Millisecond)
+	if !changed || scale != 0.8 {
+		t.Errorf("Observe() = %v, %v; want 0.8, true after raising MinScale", scale, changed)
+	}
+}
+
+func TestSetRenderScale(t *testing.T) {
+	rc := raycaster.NewRaycaster(66.0, 320, 200)
+	r := NewRenderer(320, 200, rc)
+
+	r.SetRenderScale(0.5)
+	if r.Width != 160 || r.Height != 100 {
+		t.Errorf("internal size = %dx%d, want 160x100", r.Width, r.Height)
+	}
+	if r.OutputWidth != 320 || r.OutputHeight != 200 {
+		t.Errorf("output size = %dx%d, want 320x200", r.OutputWidth, r.OutputHeight)
+	}
+	if rc.Width != 160 || rc.Height != 100 {
+		t.Errorf("raycaster size = %dx%d, want 160x100", rc.Width, rc.Height)
+	}
+	if len(r.framebuffer) != 160*100*4 {
+		t.Errorf("framebuffer size = %d, want %d", len(r.framebuffer), 160*100*4)
+	}
+	if r.RenderScale() != 0.5 {
+		t.Errorf("RenderScale() = %v, want 0.5", r.RenderScale())
+	}
+
+	r.SetRenderScale(2)
+	if r.Width != 320 || r.Height != 200 {
+		t.Errorf("scale above 1 gave %dx%d, want 320x200", r.Width, r.Height)
+	}
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/opd-ai/violence/pkg/raycaster"
+)
+
+// SkyMaterial is the MaterialMap ceiling name that opens tiles to the sky.
+// Ceiling pixels over such tiles show the genre skybox instead of a texture.
+const SkyMaterial = "sky"
+
+// Sky texture names, matching texture.GenerateSkySet.
+const (
+	skyFarTexture  = "sky_far"
+	skyNearTexture = "sky_near"
+)
+
+// skyNearRepeat is how many times the near sky layer repeats per full turn.
+// Being a whole number keeps the layer seamless, and being greater than one
+// makes it slide past the far layer as the camera rotates.
+const skyNearRepeat = 2
+
+// skyCloudDrift is how far the near layer drifts per frame, in turns.
+const skyCloudDrift = 1.0 / 7200
+
+// resolveSky fetches the sky layers from the atlas and records which
+// material index, if any, is open to the sky.
+func (r *Renderer) resolveSky() {
+	r.skyFar, r.skyNear, r.skyIndex = nil, nil, 0
+	if r.atlas == nil || r.materials == nil {
+		return
+	}
+	i, ok := r.materials.index[SkyMaterial]
+	if !ok {
+		return
+	}
+	if tex, ok := r.atlas.Get(skyFarTexture); ok {
+		r.skyFar = tex
+		r.skyIndex = i
+	}
+	if tex, ok := r.atlas.Get(skyNearTexture); ok {
+		r.skyNear = tex
+	}
+}
+
+// prepareSky computes the horizontal sky coordinate of each column from its
+// ray angle, so the sky turns with the camera but not with movement.
+func (r *Renderer) prepareSky(dirX, dirY float64) {
+	if r.skyFar == nil {
+		return
+	}
+	if cap(r.skyU) < r.Width {
+		r.skyU = make([]float64, r.Width)
+	}
+	r.skyU = r.skyU[:r.Width]
+
+	tanHalf := raycaster.Tan(r.raycaster.FOV * math.Pi / 360.0)
+	planeX, planeY := -dirY*tanHalf, dirX*tanHalf
+	for x := range r.skyU {
+		cameraX := 2*float64(x)/float64(r.Width) - 1
+		angle := math.Atan2(dirY+planeY*cameraX, dirX+planeX*cameraX)
+		r.skyU[x] = wrapUnit(angle / (2 * math.Pi))
+	}
+}
+
+// isSky reports whether a ceiling pixel is open to the sky.
+func (r *Renderer) isSky(px raycaster.FloorCeilPixel) bool {
+	return r.skyIndex > 0 && r.materials.lookup(px.WorldX, px.WorldY, false) == r.skyIndex
+}
+
+// renderSky returns the sky color for ceiling pixel (x, y). The near layer
+// scrolls faster than the far one and drifts over time; the sky is neither
+// lit nor fogged.
+func (r *Renderer) renderSky(x, y int) color.RGBA {
+	if x >= len(r.skyU) {
+		return r.palette[3]
+	}
+	u := r.skyU[x]
+	v := float64(y) / float64(max(r.Height/2, 1))
+
+	c := sampleSky(r.skyFar, u, v)
+	if r.skyNear != nil {
+		nearU := wrapUnit(u*skyNearRepeat + float64(r.tick)*skyCloudDrift)
+		c = blendOver(c, unpremultiply(sampleSky(r.skyNear, nearU, v)))
+	}
+	c.A = 255
+	return c
+}
+
+// sampleSky samples a sky layer at horizontal position u in [0, 1) and
+// height v, where 0 is the zenith and 1 the horizon.
+func sampleSky(tex image.Image, u, v float64) color.RGBA {
+	b := tex.Bounds()
+	tx := min(int(u*float64(b.Dx())), b.Dx()-1)
+	ty := min(max(int(v*float64(b.Dy())), 0), b.Dy()-1)
+	cr, cg, cb, ca := tex.At(b.Min.X+tx, b.Min.Y+ty).RGBA()
+	return color.RGBA{
+		R: uint8(cr >> 8),
+		G: uint8(cg >> 8),
+		B: uint8(cb >> 8),
+		A: uint8(ca >> 8),
+	}
+}
+
+// wrapUnit wraps t into [0, 1).
+func wrapUnit(t float64) float64 {
+	t -= math.Floor(t)
+	if t >= 1 {
+		return 0
+	}
+	return t
+}
@@ -0,0 +1,104 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/raycaster"
+)
+
+func TestWrapUnit(t *testing.T) {
+	tests := []struct{ in, want float64 }{
+		{0, 0},
+		{0.25, 0.25},
+		{1, 0},
+		{-0.25, 0.75},
+		{2.5, 0.5},
+	}
+	for _, tt := range tests {
+		if got := wrapUnit(tt.in); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("wrapUnit(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// gradientSky is a sky layer whose red channel encodes the column.
+type gradientSky struct{ w, h int }
+
+func (g gradientSky) ColorModel() color.Model { return color.RGBAModel }
+func (g gradientSky) Bounds() image.Rectangle { return image.Rect(0, 0, g.w, g.h) }
+func (g gradientSky) At(x, y int) color.Color {
+	return color.RGBA{R: uint8(x * 255 / (g.w - 1)), G: uint8(y), B: 0, A: 255}
+}
+
+func newSkyRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	rc := raycaster.NewRaycaster(66.0, 64, 40)
+	r := NewRenderer(64, 40, rc)
+	r.SetTextureAtlas(&mockAtlas{wallTextures: map[string]image.Image{
+		skyFarTexture: gradientSky{w: 256, h: 20},
+	}})
+	m := NewMaterialMap(10, 10)
+	m.SetRect(0, 0, 5, 10, "", SkyMaterial)
+	r.SetMaterialMap(m)
+	r.resolveSurfaces()
+	r.resolveSky()
+	return r
+}
+
+func TestPrepareSkyFollowsRotation(t *testing.T) {
+	r := newSkyRenderer(t)
+
+	r.prepareSky(1, 0)
+	east := r.skyU[r.Width/2]
+	r.prepareSky(0, 1)
+	south := r.skyU[r.Width/2]
+
+	// A quarter turn moves the sky a quarter of the panorama.
+	if d := wrapUnit(south - east); math.Abs(d-0.25) > 0.02 {
+		t.Errorf("quarter turn moved sky by %v, want 0.25", d)
+	}
+	// Columns sweep across the field of view in one direction.
+	if r.skyU[0] == r.skyU[r.Width-1] {
+		t.Error("sky coordinate does not vary across the screen")
+	}
+}
+
+func TestIsSky(t *testing.T) {
+	r := newSkyRenderer(t)
+	open := raycaster.FloorCeilPixel{WorldX: 2.5, WorldY: 2.5}
+	roofed := raycaster.FloorCeilPixel{WorldX: 7.5, WorldY: 2.5}
+	if !r.isSky(open) {
+		t.Error("tile with sky material is not open to the sky")
+	}
+	if r.isSky(roofed) {
+		t.Error("tile without sky material is open to the sky")
+	}
+
+	// Without a sky texture the ceiling renders normally.
+	r.SetTextureAtlas(&mockAtlas{})
+	r.resolveSky()
+	if r.isSky(open) {
+		t.Error("sky shown without a sky texture")
+	}
+}
+
+func TestComputePixelColorDrawsSky(t *testing.T) {
+	r := newSkyRenderer(t)
+	r.SetLightMap(&mockLightMap{width: 10, height: 10, uniform: 0})
+	r.prepareSky(1, 0)
+
+	surface := make([]raycaster.FloorCeilPixel, r.Width)
+	for x := range surface {
+		surface[x] = raycaster.FloorCeilPixel{WorldX: 2.5, WorldY: 2.5, Distance: 1}
+	}
+	x, y := r.Width/2, 5
+	want := sampleSky(gradientSky{w: 256, h: 20}, r.skyU[x], float64(y)/float64(r.Height/2))
+
+	// The sky is unlit, so a dark light map leaves it unchanged.
+	if got := r.computePixelColor(x, y, nil, surface); got != want {
+		t.Errorf("sky pixel = %v, want %v", got, want)
+	}
+}
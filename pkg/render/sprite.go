@@ -0,0 +1,255 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/opd-ai/violence/pkg/raycaster"
+)
+
+// spriteNearPlane is the closest camera depth at which sprites are drawn.
+// Nearer sprites would project to enormous screen rectangles.
+const spriteNearPlane = 0.1
+
+// Sprite is a world-space billboard registered with the renderer for one
+// frame. Sprites always face the camera and stand on the floor.
+type Sprite struct {
+	X, Y float64 // World position of the sprite's base
+
+	// Image is the sprite texture. When nil the sprite is drawn as a solid
+	// rectangle of Color.
+	Image *ebiten.Image
+	Color color.RGBA
+
+	// Scale is the sprite height in wall heights (0 means 1). Aspect is
+	// width over height; 0 uses the image's aspect ratio, or 1 for solid
+	// sprites.
+	Scale  float64
+	Aspect float64
+
+	// VOffset lifts the sprite's base above the floor, in wall heights.
+	// Negative values sink it below the floor line.
+	VOffset float64
+
+	// ColorScale is an extra caller tint or fade; the zero value is identity.
+	ColorScale ebiten.ColorScale
+
+	// Fullbright sprites ignore the light map (emissive items, UI markers).
+	// Fog still applies.
+	Fullbright bool
+}
+
+// spriteCamera is the camera state captured by Render for the sprite pass.
+type spriteCamera struct {
+	posX, posY     float64
+	dirX, dirY     float64
+	planeX, planeY float64
+}
+
+// spriteProjection is a sprite's screen rectangle and camera depth.
+type spriteProjection struct {
+	left, top     float64 // Unclipped top-left corner in pixels
+	width, height float64 // Unclipped size in pixels
+	depth         float64 // Perpendicular distance from the camera plane
+}
+
+// AddSprite registers a billboard to draw in the next DrawSprites call.
+func (r *Renderer) AddSprite(s Sprite) {
+	r.sprites = append(r.sprites, s)
+}
+
+// DrawSprites draws every registered sprite over screen using the camera and
+// wall depths of the last Render call, then clears the sprite list.
+//
+// Sprites are sorted farthest first and clipped per column against the wall
+// z-buffer, so a sprite behind a wall edge is cut exactly at the edge.
+// Each sprite is shaded by the light map at its position and by distance fog.
+func (r *Renderer) DrawSprites(screen *ebiten.Image) {
+	start := time.Now()
+	r.stats.SpritesDrawn, r.stats.SpriteDraws = 0, 0
+	defer func() {
+		r.sprites = r.sprites[:0]
+		r.stats.Sprites = time.Since(start)
+	}()
+	if len(r.sprites) == 0 || len(r.zbuffer) == 0 {
+		return
+	}
+
+	projected := make([]spriteProjection, len(r.sprites))
+	order := make([]int, 0, len(r.sprites))
+	for i := range r.sprites {
+		p, ok := r.projectSprite(&r.sprites[i])
+		if !ok {
+			continue
+		}
+		projected[i] = p
+		order = append(order, i)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return projected[order[a]].depth > projected[order[b]].depth
+	})
+
+	for _, i := range order {
+		r.drawSprite(screen, &r.sprites[i], projected[i])
+	}
+}
+
+// projectSprite transforms a sprite into screen space. It reports false when
+// the sprite is behind the near plane or entirely off screen.
+func (r *Renderer) projectSprite(s *Sprite) (spriteProjection, bool) {
+	cam := r.spriteCam
+	dx := s.X - cam.posX
+	dy := s.Y - cam.posY
+
+	invDet := 1.0 / (cam.planeX*cam.dirY - cam.dirX*cam.planeY)
+	transformX := invDet * (cam.dirY*dx - cam.dirX*dy)
+	transformY := invDet * (-cam.planeY*dx + cam.planeX*dy)
+	if transformY <= spriteNearPlane {
+		return spriteProjection{}, false
+	}
+
+	scale := s.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+	aspect := s.Aspect
+	if aspect <= 0 {
+		aspect = 1
+		if s.Image != nil {
+			b := s.Image.Bounds()
+			if b.Dy() > 0 {
+				aspect = float64(b.Dx()) / float64(b.Dy())
+			}
+		}
+	}
+
+	// One wall height spans Height/depth pixels centered on the horizon, so
+	// the floor line sits half of that below it. Sprites are drawn at output
+	// resolution, independent of the render scale.
+	unit := float64(r.OutputHeight) / transformY
+	screenX := float64(r.OutputWidth) / 2 * (1 + transformX/transformY)
+	height := scale * unit
+	width := height * aspect
+	bottom := float64(r.OutputHeight)/2 + (0.5-s.VOffset)*unit
+
+	p := spriteProjection{
+		left:   screenX - width/2,
+		top:    bottom - height,
+		width:  width,
+		height: height,
+		depth:  transformY,
+	}
+	if p.left+p.width < 0 || p.left >= float64(r.OutputWidth) || p.top >= float64(r.OutputHeight) || bottom < 0 {
+		return spriteProjection{}, false
+	}
+	return p, true
+}
+
+// visibleRuns returns the inclusive column ranges within [x0, x1] where a
+// sprite at depth is nearer than the wall z-buffer.
+func visibleRuns(zbuffer []float64, x0, x1 int, depth float64) [][2]int {
+	x0 = max(x0, 0)
+	x1 = min(x1, len(zbuffer)-1)
+	var runs [][2]int
+	start := -1
+	for x := x0; x <= x1; x++ {
+		if depth < zbuffer[x] {
+			if start < 0 {
+				start = x
+			}
+			continue
+		}
+		if start >= 0 {
+			runs = append(runs, [2]int{start, x - 1})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		runs = append(runs, [2]int{start, x1})
+	}
+	return runs
+}
+
+// drawSprite draws one projected sprite, clipping to the columns where it is
+// in front of the walls.
+func (r *Renderer) drawSprite(screen *ebiten.Image, s *Sprite, p spriteProjection) {
+	x0 := int(math.Floor(p.left))
+	x1 := int(math.Ceil(p.left+p.width)) - 1
+	runs := visibleRuns(r.zbuffer, x0, x1, p.depth)
+	if len(runs) == 0 {
+		return
+	}
+
+	img := s.Image
+	if img == nil {
+		img = r.solidPixel()
+	}
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return
+	}
+	r.stats.SpritesDrawn++
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(p.width/float64(b.Dx()), p.height/float64(b.Dy()))
+	op.GeoM.Translate(p.left, p.top)
+	op.ColorScale = s.ColorScale
+	if s.Image == nil {
+		op.ColorScale.ScaleWithColor(s.Color)
+	}
+	shade := r.spriteShade(s, p.depth)
+	op.ColorScale.Scale(float32(shade[0]), float32(shade[1]), float32(shade[2]), 1)
+
+	for _, run := range runs {
+		dst := screen.SubImage(image.Rect(run[0], 0, run[1]+1, r.OutputHeight)).(*ebiten.Image)
+		dst.DrawImage(img, op)
+	}
+	r.stats.SpriteDraws += len(runs)
+}
+
+// spriteShade returns the RGB multiplier for a sprite from the light map at
+// its tile and distance fog, matching how walls are lit.
+func (r *Renderer) spriteShade(s *Sprite, depth float64) [3]float64 {
+	light := [3]float64{1, 1, 1}
+	if !s.Fullbright {
+		light = r.getLightColor(s.X, s.Y)
+	}
+	return r.raycaster.ApplyFog(light, depth)
+}
+
+// solidPixel returns a shared 1x1 white image used to draw untextured
+// sprites.
+func (r *Renderer) solidPixel() *ebiten.Image {
+	if r.whitePixel == nil {
+		r.whitePixel = ebiten.NewImage(1, 1)
+		r.whitePixel.Fill(color.White)
+	}
+	return r.whitePixel
+}
+
+// captureSpriteCamera records the camera and wall depths of a frame for the
+// sprite pass. The z-buffer is kept at output width so sprites clip the same
+// way at any render scale.
+func (r *Renderer) captureSpriteCamera(hits []raycaster.RayHit, posX, posY, dirX, dirY float64) {
+	tanHalf := raycaster.Tan(r.raycaster.FOV * math.Pi / 360.0)
+	r.spriteCam = spriteCamera{
+		posX: posX, posY: posY,
+		dirX: dirX, dirY: dirY,
+		planeX: -dirY * tanHalf,
+		planeY: dirX * tanHalf,
+	}
+	if cap(r.zbuffer) < r.OutputWidth {
+		r.zbuffer = make([]float64, r.OutputWidth)
+	}
+	r.zbuffer = r.zbuffer[:r.OutputWidth]
+	for x := range r.zbuffer {
+		r.zbuffer[x] = math.Inf(1)
+		if col := x * r.Width / r.OutputWidth; col < len(hits) {
+			r.zbuffer[x] = hits[col].Distance
+		}
+	}
+}
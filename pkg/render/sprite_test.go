@@ -0,0 +1,140 @@
+package render
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/opd-ai/violence/pkg/raycaster"
+)
+
+func TestVisibleRuns(t *testing.T) {
+	zbuffer := []float64{5, 5, 1, 1, 5, 5, 5, 1}
+
+	tests := []struct {
+		name   string
+		x0, x1 int
+		depth  float64
+		want   [][2]int
+	}{
+		{"Fully visible", 0, 7, 0.5, [][2]int{{0, 7}}},
+		{"Split by near wall", 0, 7, 2, [][2]int{{0, 1}, {4, 6}}},
+		{"Fully hidden", 2, 3, 2, nil},
+		{"Clipped to screen", -3, 20, 2, [][2]int{{0, 1}, {4, 6}}},
+		{"Run ends at range end", 4, 5, 2, [][2]int{{4, 5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := visibleRuns(zbuffer, tt.x0, tt.x1, tt.depth)
+			if len(got) != len(tt.want) {
+				t.Fatalf("visibleRuns() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("run %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func newSpriteTestRenderer() *Renderer {
+	rc := raycaster.NewRaycaster(66.0, 320, 200)
+	r := NewRenderer(320, 200, rc)
+	r.captureSpriteCamera(nil, 5, 5, 1, 0)
+	return r
+}
+
+func TestProjectSprite(t *testing.T) {
+	r := newSpriteTestRenderer()
+
+	tests := []struct {
+		name    string
+		sprite  Sprite
+		visible bool
+	}{
+		{"Ahead", Sprite{X: 8, Y: 5}, true},
+		{"Behind camera", Sprite{X: 2, Y: 5}, false},
+		{"Inside near plane", Sprite{X: 5.05, Y: 5}, false},
+		{"Far to the side", Sprite{X: 6, Y: 50}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := r.projectSprite(&tt.sprite)
+			if ok != tt.visible {
+				t.Errorf("projectSprite() visible = %v, want %v", ok, tt.visible)
+			}
+		})
+	}
+}
+
+func TestProjectSpriteStandsOnFloor(t *testing.T) {
+	r := newSpriteTestRenderer()
+
+	full, ok := r.projectSprite(&Sprite{X: 7, Y: 5})
+	if !ok {
+		t.Fatal("sprite ahead should be visible")
+	}
+	// A full-height sprite spans exactly one wall height at its depth.
+	if math.Abs(full.depth-2) > 1e-9 {
+		t.Errorf("depth = %v, want 2", full.depth)
+	}
+	if math.Abs(full.height-100) > 1e-9 {
+		t.Errorf("height = %v, want 100", full.height)
+	}
+	if math.Abs(full.top-50) > 1e-9 {
+		t.Errorf("top = %v, want 50", full.top)
+	}
+
+	// A half-scale sprite keeps the same floor line.
+	half, _ := r.projectSprite(&Sprite{X: 7, Y: 5, Scale: 0.5})
+	if math.Abs((half.top+half.height)-(full.top+full.height)) > 1e-9 {
+		t.Errorf("half sprite bottom = %v, want %v", half.top+half.height, full.top+full.height)
+	}
+
+	// VOffset lifts the base by a fraction of a wall height.
+	lifted, _ := r.projectSprite(&Sprite{X: 7, Y: 5, Scale: 0.5, VOffset: 0.5})
+	if math.Abs(lifted.top-(half.top-50)) > 1e-9 {
+		t.Errorf("lifted top = %v, want %v", lifted.top, half.top-50)
+	}
+
+	// Aspect defaults to square for solid sprites.
+	if math.Abs(half.width-half.height) > 1e-9 {
+		t.Errorf("width = %v, want %v", half.width, half.height)
+	}
+}
+
+func TestDrawSpritesClearsList(t *testing.T) {
+	r := newSpriteTestRenderer()
+	r.AddSprite(Sprite{X: 7, Y: 5, Color: color.RGBA{255, 0, 0, 255}})
+	r.AddSprite(Sprite{X: 9, Y: 5, Color: color.RGBA{0, 255, 0, 255}})
+
+	screen := ebiten.NewImage(320, 200)
+	r.DrawSprites(screen)
+
+	if len(r.sprites) != 0 {
+		t.Errorf("sprites after DrawSprites = %d, want 0", len(r.sprites))
+	}
+	// Nothing occludes the sprites, so each is one unclipped draw.
+	if st := r.Stats(); st.SpritesDrawn != 2 || st.SpriteDraws != 2 {
+		t.Errorf("stats drawn = %d, draws = %d, want 2 and 2", st.SpritesDrawn, st.SpriteDraws)
+	}
+}
+
+func TestSpriteShade(t *testing.T) {
+	r := newSpriteTestRenderer()
+	r.SetLightMap(&mockLightMap{width: 16, height: 16, uniform: 0.25})
+
+	lit := r.spriteShade(&Sprite{X: 7, Y: 5}, 0)
+	bright := r.spriteShade(&Sprite{X: 7, Y: 5, Fullbright: true}, 0)
+
+	if lit[0] >= bright[0] {
+		t.Errorf("lit shade %v should be darker than fullbright %v", lit[0], bright[0])
+	}
+	if far := r.spriteShade(&Sprite{X: 7, Y: 5, Fullbright: true}, 50); far == bright {
+		t.Error("fog should change the shade of distant sprites")
+	}
+}
@@ -0,0 +1,182 @@
+package render
+
+import (
+	"image"
+
+	"github.com/opd-ai/violence/pkg/raycaster"
+)
+
+// Default surface texture names used where no material is assigned.
+const (
+	defaultFloorTexture   = "floor_main"
+	defaultCeilingTexture = "ceiling_main"
+)
+
+// maxSurfaceMaterials bounds the distinct texture names a MaterialMap holds.
+const maxSurfaceMaterials = 255
+
+// MaterialMap assigns floor and ceiling textures to map tiles, so each room
+// (sector) can have its own surface materials. Tiles without an assignment
+// use the "floor_main" and "ceiling_main" textures.
+//
+// Texture names are interned to small indices so the per-pixel lookup is a
+// slice index rather than a map lookup.
+type MaterialMap struct {
+	width, height int
+	names         []string // Interned texture names; index 0 means default
+	index         map[string]uint8
+	floor         []uint8
+	ceiling       []uint8
+}
+
+// NewMaterialMap creates an empty material map for a width x height tile map.
+func NewMaterialMap(width, height int) *MaterialMap {
+	return &MaterialMap{
+		width:   width,
+		height:  height,
+		names:   []string{""},
+		index:   map[string]uint8{"": 0},
+		floor:   make([]uint8, width*height),
+		ceiling: make([]uint8, width*height),
+	}
+}
+
+// SetRect assigns floor and ceiling texture names to a rectangle of tiles,
+// clipped to the map. An empty name restores the default texture.
+func (m *MaterialMap) SetRect(x, y, w, h int, floorTex, ceilingTex string) {
+	fi := m.intern(floorTex)
+	ci := m.intern(ceilingTex)
+	for ty := max(y, 0); ty < min(y+h, m.height); ty++ {
+		for tx := max(x, 0); tx < min(x+w, m.width); tx++ {
+			m.floor[ty*m.width+tx] = fi
+			m.ceiling[ty*m.width+tx] = ci
+		}
+	}
+}
+
+// Surface returns the floor and ceiling texture names at a tile. Empty
+// names mean the default textures.
+func (m *MaterialMap) Surface(x, y int) (floorTex, ceilingTex string) {
+	if x < 0 || y < 0 || x >= m.width || y >= m.height {
+		return "", ""
+	}
+	i := y*m.width + x
+	return m.names[m.floor[i]], m.names[m.ceiling[i]]
+}
+
+// floorDetailTextures maps the detailing pass's floor variant tiles to their
+// genre floor detail textures.
+var floorDetailTextures = map[int]string{
+	25: "floor_worn",
+	26: "floor_cracked",
+	27: "floor_stained",
+	28: "floor_patterned",
+	29: "floor_grate",
+}
+
+// ApplyFloorDetails gives floor variant tiles their detail texture, keeping
+// each tile's ceiling. Call it after assigning room materials.
+func (m *MaterialMap) ApplyFloorDetails(tiles [][]int) {
+	for y := 0; y < min(len(tiles), m.height); y++ {
+		for x := 0; x < min(len(tiles[y]), m.width); x++ {
+			name, ok := floorDetailTextures[tiles[y][x]]
+			if !ok {
+				continue
+			}
+			i := y*m.width + x
+			m.floor[i] = m.intern(name)
+		}
+	}
+}
+
+// intern returns the index for a texture name, adding it if needed. Names
+// beyond maxSurfaceMaterials fall back to the default texture.
+func (m *MaterialMap) intern(name string) uint8 {
+	if i, ok := m.index[name]; ok {
+		return i
+	}
+	if len(m.names) >= maxSurfaceMaterials {
+		return 0
+	}
+	i := uint8(len(m.names))
+	m.names = append(m.names, name)
+	m.index[name] = i
+	return i
+}
+
+// lookup returns the interned floor or ceiling index at a world position.
+func (m *MaterialMap) lookup(worldX, worldY float64, floor bool) uint8 {
+	x, y := int(worldX), int(worldY)
+	if worldX < 0 || worldY < 0 || x >= m.width || y >= m.height {
+		return 0
+	}
+	if floor {
+		return m.floor[y*m.width+x]
+	}
+	return m.ceiling[y*m.width+x]
+}
+
+// SetMaterialMap assigns per-tile floor and ceiling materials. Pass nil to
+// use the default surface textures everywhere.
+func (r *Renderer) SetMaterialMap(m *MaterialMap) {
+	r.materials = m
+}
+
+// resolveSurfaces fetches the surface textures used this frame from the
+// atlas, so per-pixel sampling does not take the atlas lock.
+func (r *Renderer) resolveSurfaces() {
+	r.floorTex, r.ceilingTex = nil, nil
+	r.surfaceTex = r.surfaceTex[:0]
+	if r.atlas == nil {
+		return
+	}
+
+	get := func(name string) image.Image {
+		if tex, ok := r.atlas.Get(name); ok {
+			return tex
+		}
+		return nil
+	}
+	r.floorTex = get(defaultFloorTexture)
+	r.ceilingTex = get(defaultCeilingTexture)
+	if r.materials == nil {
+		return
+	}
+	for _, name := range r.materials.names {
+		if name == "" {
+			r.surfaceTex = append(r.surfaceTex, nil)
+			continue
+		}
+		r.surfaceTex = append(r.surfaceTex, get(name))
+	}
+}
+
+// surfaceTexture returns the resolved texture for a floor or ceiling pixel,
+// or nil when the palette fallback should be used. Materials whose texture
+// is missing from the atlas fall back to the default surface texture.
+func (r *Renderer) surfaceTexture(px raycaster.FloorCeilPixel, floor bool) image.Image {
+	if r.materials != nil {
+		i := int(r.materials.lookup(px.WorldX, px.WorldY, floor))
+		if i > 0 && i < len(r.surfaceTex) && r.surfaceTex[i] != nil {
+			return r.surfaceTex[i]
+		}
+	}
+	if floor {
+		return r.floorTex
+	}
+	return r.ceilingTex
+}
+
+// castSurfaceRow casts the floor or ceiling world coordinates for screen
+// row y once, so every column in the row can share them. The horizon row
+// returns nil.
+func (r *Renderer) castSurfaceRow(y int, posX, posY, dirX, dirY, pitch float64) []raycaster.FloorCeilPixel {
+	switch {
+	case y < r.Height/2:
+		return r.raycaster.CastFloorCeiling(r.Height-1-y, posX, posY, dirX, dirY, pitch)
+	case y > r.Height/2:
+		return r.raycaster.CastFloorCeiling(y, posX, posY, dirX, dirY, pitch)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,137 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/raycaster"
+)
+
+func TestMaterialMapSetRect(t *testing.T) {
+	m := NewMaterialMap(10, 10)
+	m.SetRect(2, 2, 3, 3, "floor_wood", "ceiling_wood")
+	m.SetRect(8, 8, 5, 5, "floor_tile", "") // Clipped to the map
+
+	tests := []struct {
+		x, y        int
+		floor, ceil string
+	}{
+		{0, 0, "", ""},
+		{2, 2, "floor_wood", "ceiling_wood"},
+		{4, 4, "floor_wood", "ceiling_wood"},
+		{5, 5, "", ""},
+		{9, 9, "floor_tile", ""},
+		{-1, 3, "", ""},
+	}
+	for _, tt := range tests {
+		floor, ceil := m.Surface(tt.x, tt.y)
+		if floor != tt.floor || ceil != tt.ceil {
+			t.Errorf("Surface(%d,%d) = (%q,%q), want (%q,%q)", tt.x, tt.y, floor, ceil, tt.floor, tt.ceil)
+		}
+	}
+}
+
+func TestMaterialMapApplyFloorDetails(t *testing.T) {
+	m := NewMaterialMap(3, 1)
+	m.SetRect(0, 0, 3, 1, "floor_wood", "ceiling_wood")
+	m.ApplyFloorDetails([][]int{{20, 26, 29}})
+
+	want := []string{"floor_wood", "floor_cracked", "floor_grate"}
+	for x, w := range want {
+		floor, ceil := m.Surface(x, 0)
+		if floor != w || ceil != "ceiling_wood" {
+			t.Errorf("Surface(%d,0) = (%q,%q), want (%q,%q)", x, floor, ceil, w, "ceiling_wood")
+		}
+	}
+}
+
+func TestMaterialMapInternReusesNames(t *testing.T) {
+	m := NewMaterialMap(4, 4)
+	m.SetRect(0, 0, 2, 2, "floor_wood", "ceiling_wood")
+	m.SetRect(2, 2, 2, 2, "floor_wood", "ceiling_wood")
+	if len(m.names) != 3 {
+		t.Errorf("interned %d names, want 3 (default, floor, ceiling)", len(m.names))
+	}
+}
+
+func TestRenderSurfaceUsesMaterial(t *testing.T) {
+	rc := raycaster.NewRaycaster(66.0, 320, 200)
+	r := NewRenderer(320, 200, rc)
+	rc.FogDensity = 0
+
+	wood := color.RGBA{R: 200, G: 100, B: 50, A: 255}
+	atlas := &mockAtlas{
+		hasFloor: true,
+		wallTextures: map[string]image.Image{
+			"floor_wood": &mockTexture{w: 8, h: 8, color: wood},
+		},
+	}
+	r.SetTextureAtlas(atlas)
+
+	m := NewMaterialMap(10, 10)
+	m.SetRect(0, 0, 5, 10, "floor_wood", "")
+	r.SetMaterialMap(m)
+	r.resolveSurfaces()
+
+	inRoom := []raycaster.FloorCeilPixel{{WorldX: 2.5, WorldY: 2.5, Distance: 1, IsFloor: true}}
+	if got := r.renderSurface(0, inRoom, true); !colorsClose(got, wood) {
+		t.Errorf("room floor = %v, want wood %v", got, wood)
+	}
+
+	outside := []raycaster.FloorCeilPixel{{WorldX: 7.5, WorldY: 2.5, Distance: 1, IsFloor: true}}
+	want := color.RGBA{R: 80, G: 70, B: 60, A: 255} // mockAtlas floor_main
+	if got := r.renderSurface(0, outside, true); !colorsClose(got, want) {
+		t.Errorf("default floor = %v, want %v", got, want)
+	}
+
+	// Missing material textures fall back to the default surface.
+	m.SetRect(0, 0, 5, 10, "floor_missing", "")
+	r.resolveSurfaces()
+	if got := r.renderSurface(0, inRoom, true); !colorsClose(got, want) {
+		t.Errorf("missing material floor = %v, want default %v", got, want)
+	}
+}
+
+// colorsClose reports whether two colors match within float rounding.
+func colorsClose(a, b color.RGBA) bool {
+	near := func(x, y uint8) bool { return x-y <= 1 || y-x <= 1 }
+	return near(a.R, b.R) && near(a.G, b.G) && near(a.B, b.B) && a.A == b.A
+}
+
+// BenchmarkRenderFrameTexturedSurfaces measures a full 320x200 frame with
+// textured floors and ceilings and per-room materials, excluding the blit.
+func BenchmarkRenderFrameTexturedSurfaces(b *testing.B) {
+	rc := raycaster.NewRaycaster(66.0, 320, 200)
+	tiles := make([][]int, 32)
+	for y := range tiles {
+		tiles[y] = make([]int, 32)
+		for x := range tiles[y] {
+			if x == 0 || y == 0 || x == 31 || y == 31 {
+				tiles[y][x] = 1
+			}
+		}
+	}
+	rc.SetMap(tiles)
+
+	r := NewRenderer(320, 200, rc)
+	tex := &mockTexture{w: 64, h: 64, color: color.RGBA{R: 90, G: 80, B: 70, A: 255}}
+	r.SetTextureAtlas(&mockAtlas{
+		hasFloor:   true,
+		hasCeiling: true,
+		wallTextures: map[string]image.Image{
+			"floor_wood": tex, "ceiling_wood": tex, "floor_tile": tex, "ceiling_tile": tex,
+		},
+	})
+	m := NewMaterialMap(32, 32)
+	m.SetRect(1, 1, 15, 30, "floor_wood", "ceiling_wood")
+	m.SetRect(16, 1, 15, 30, "floor_tile", "ceiling_tile")
+	r.SetMaterialMap(m)
+
+	hits := rc.CastRays(8.5, 16.5, 1.0, 0.0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.renderFrame(hits, 8.5, 16.5, 1.0, 0.0, 0.0)
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N)/320, "ns/column")
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -108,18 +109,31 @@ func (r *ReplayRecorder) Save(path string) error {
 	}
 	copy(header.Magic[:], MagicBytes)
 
-	file, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create replay file: %w", err)
+	var buf bytes.Buffer
+	if err := r.writeHeader(&buf, header); err != nil {
+		return err
 	}
-	defer file.Close()
 
-	if err := r.writeHeader(file, header); err != nil {
+	if err := r.writeInputs(&buf); err != nil {
 		return err
 	}
 
-	if err := r.writeInputs(file); err != nil {
-		return err
+	// Write beside the replay and rename over it, so sync clients never
+	// see half a file
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create replay file: %w", err)
+	}
+	_, err = file.Write(buf.Bytes())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to write replay file: %w", err)
 	}
 
 	logrus.WithFields(logrus.Fields{
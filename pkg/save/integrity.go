@@ -0,0 +1,197 @@
+package save
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Save files start with fileMagic and a mode byte, then an HMAC-SHA256 of
+// the mode and body, then the body: the JSON, gzipped if the mode has
+// modeCompressed, and for encrypted saves then sealed as
+// [salt(32)][nonce(12)][AES-256-GCM ciphertext+tag]. Files without the
+// magic are saves from before checksums and are read as plain JSON.
+const (
+	fileMagic      = "VSAV"
+	modePlain      = byte(0)
+	modeEncrypted  = byte(1 << 0)
+	modeCompressed = byte(1 << 1)
+	headerSize     = len(fileMagic) + 1 + sha256.Size
+	saltSize       = 32
+	nonceSize      = 12
+	keySize        = 32
+	pbkdf2Rounds   = 100000
+	integrityLabel = "violence save integrity v1"
+)
+
+// ErrCorruptSave is returned when a save file fails its checksum or can't
+// be decoded.
+var ErrCorruptSave = errors.New("save file is corrupted")
+
+// ErrPassphraseRequired is returned when loading an encrypted save without
+// a passphrase set.
+var ErrPassphraseRequired = errors.New("save file is encrypted; a passphrase is required")
+
+// ErrWrongPassphrase is returned when an encrypted save doesn't decrypt
+// with the passphrase set.
+var ErrWrongPassphrase = errors.New("wrong passphrase for encrypted save file")
+
+var (
+	passphraseMu sync.RWMutex
+	passphrase   string
+)
+
+// compressSaves gzips files as they're written. Reading handles either.
+var compressSaves = true
+
+// SetPassphrase sets the passphrase saves, the stash and the profile are
+// encrypted with when written and decrypted with when read. An empty
+// passphrase writes them unencrypted; both kinds are checksummed.
+func SetPassphrase(p string) {
+	passphraseMu.Lock()
+	passphrase = p
+	passphraseMu.Unlock()
+}
+
+func currentPassphrase() string {
+	passphraseMu.RLock()
+	defer passphraseMu.RUnlock()
+	return passphrase
+}
+
+// checksum returns the HMAC-SHA256 of a file's mode and body. It guards
+// against corruption, not tampering: the key is no secret.
+func checksum(mode byte, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(integrityLabel))
+	mac.Write([]byte{mode})
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// seal wraps data in the save file format, compressing it and
+// encrypting it if a passphrase is set.
+func seal(data []byte) ([]byte, error) {
+	mode, body := modePlain, data
+	if compressSaves {
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+		if _, err := zw.Write(body); err != nil {
+			return nil, fmt.Errorf("failed to compress save: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress save: %w", err)
+		}
+		mode, body = mode|modeCompressed, buf.Bytes()
+	}
+	if p := currentPassphrase(); p != "" {
+		var err error
+		if body, err = encrypt(body, p); err != nil {
+			return nil, fmt.Errorf("failed to encrypt save: %w", err)
+		}
+		mode |= modeEncrypted
+	}
+
+	out := make([]byte, 0, headerSize+len(body))
+	out = append(out, fileMagic...)
+	out = append(out, mode)
+	out = append(out, checksum(mode, body)...)
+	return append(out, body...), nil
+}
+
+// open verifies and unwraps a file written by seal, decrypting and
+// decompressing it as needed. Files from before checksums are returned as
+// they are.
+func open(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(fileMagic)) {
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			return data, nil
+		}
+		return nil, fmt.Errorf("%w: unrecognised file format", ErrCorruptSave)
+	}
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("%w: truncated header", ErrCorruptSave)
+	}
+
+	mode := data[len(fileMagic)]
+	sum := data[len(fileMagic)+1 : headerSize]
+	body := data[headerSize:]
+	if !hmac.Equal(sum, checksum(mode, body)) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorruptSave)
+	}
+
+	if mode&^(modeEncrypted|modeCompressed) != 0 {
+		return nil, fmt.Errorf("%w: unknown mode %d", ErrCorruptSave, mode)
+	}
+	if mode&modeEncrypted != 0 {
+		p := currentPassphrase()
+		if p == "" {
+			return nil, ErrPassphraseRequired
+		}
+		var err error
+		if body, err = decrypt(body, p); err != nil {
+			return nil, err
+		}
+	}
+	if mode&modeCompressed != 0 {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptSave, err)
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptSave, err)
+		}
+	}
+	return body, nil
+}
+
+// newGCM returns AES-256-GCM keyed from the passphrase and salt.
+func newGCM(p string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, p, salt, pbkdf2Rounds, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt encrypts data under the passphrase with a fresh salt and nonce.
+func encrypt(data []byte, p string) ([]byte, error) {
+	out := make([]byte, saltSize+nonceSize, saltSize+nonceSize+len(data)+16)
+	if _, err := rand.Read(out); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(p, out[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	return gcm.Seal(out, out[saltSize:], data, nil), nil
+}
+
+// decrypt reverses encrypt. The body's checksum has already passed, so a
+// failure here is the passphrase.
+func decrypt(body []byte, p string) ([]byte, error) {
+	if len(body) < saltSize+nonceSize {
+		return nil, fmt.Errorf("%w: truncated encrypted body", ErrCorruptSave)
+	}
+	gcm, err := newGCM(p, body[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	data, err := gcm.Open(nil, body[saltSize:saltSize+nonceSize], body[saltSize+nonceSize:], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return data, nil
+}
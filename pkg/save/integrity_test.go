@@ -0,0 +1,199 @@
+package save
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+// corruptSlot flips a byte near the end of the slot's file.
+func corruptSlot(t *testing.T, slot int) {
+	t.Helper()
+	path, err := getSlotPath(slot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-3] ^= 0x20
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadCorruptSave(t *testing.T) {
+	_, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if err := Save(1, &GameState{Seed: 7, Genre: "scifi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(1); err != nil {
+		t.Fatalf("Load() of intact save: %v", err)
+	}
+
+	corruptSlot(t, 1)
+	if _, err := Load(1); !errors.Is(err, ErrCorruptSave) {
+		t.Errorf("Load() of corrupted save: err = %v, want ErrCorruptSave", err)
+	}
+
+	path, _ := getSlotPath(2)
+	if err := os.WriteFile(path, []byte("\x00\x00garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(2); !errors.Is(err, ErrCorruptSave) {
+		t.Errorf("Load() of garbage: err = %v, want ErrCorruptSave", err)
+	}
+
+	slots, err := ListSlots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slots[1].Exists || !slots[1].Corrupt || !slots[2].Corrupt || slots[3].Corrupt {
+		t.Errorf("slots = %+v, want 1 and 2 listed as corrupt", slots[:4])
+	}
+}
+
+func TestEncryptedSave(t *testing.T) {
+	_, cleanup := setupTestDir(t)
+	defer cleanup()
+	defer SetPassphrase("")
+
+	SetPassphrase("hunter2")
+	if err := Save(1, &GameState{Seed: 7, Genre: "horror"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveProfile(&Profile{}); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := getSlotPath(1)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("horror")) {
+		t.Error("encrypted save contains the plaintext genre")
+	}
+
+	state, err := Load(1)
+	if err != nil || state.Genre != "horror" {
+		t.Fatalf("Load() = %+v, %v", state, err)
+	}
+	if _, err := LoadProfile(); err != nil {
+		t.Errorf("LoadProfile() error = %v", err)
+	}
+
+	SetPassphrase("hunter3")
+	if _, err := Load(1); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("wrong passphrase: err = %v, want ErrWrongPassphrase", err)
+	}
+	SetPassphrase("")
+	if _, err := Load(1); !errors.Is(err, ErrPassphraseRequired) {
+		t.Errorf("no passphrase: err = %v, want ErrPassphraseRequired", err)
+	}
+	if slots, _ := ListSlots(); !slots[1].Exists || !slots[1].Locked {
+		t.Errorf("slot 1 = %+v, want locked", slots[1])
+	}
+
+	SetPassphrase("hunter2")
+	corruptSlot(t, 1)
+	if _, err := Load(1); !errors.Is(err, ErrCorruptSave) {
+		t.Errorf("corrupted encrypted save: err = %v, want ErrCorruptSave", err)
+	}
+}
+
+// largeState returns a save with a 256x256 map of walls and floors.
+func largeState() *GameState {
+	state := &GameState{Seed: 12345, Genre: "fantasy", Map: Map{Width: 256, Height: 256}}
+	state.Map.Tiles = make([][]int, 256)
+	for y := range state.Map.Tiles {
+		state.Map.Tiles[y] = make([]int, 256)
+		for x := range state.Map.Tiles[y] {
+			if x%7 == 0 || y%11 == 0 {
+				state.Map.Tiles[y][x] = 1
+			}
+		}
+	}
+	return state
+}
+
+func TestCompressedSave(t *testing.T) {
+	_, cleanup := setupTestDir(t)
+	defer cleanup()
+	defer func() { compressSaves = true }()
+
+	sizes := map[bool]int64{}
+	for _, compress := range []bool{false, true} {
+		compressSaves = compress
+		if err := Save(1, largeState()); err != nil {
+			t.Fatal(err)
+		}
+		path, _ := getSlotPath(1)
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[compress] = info.Size()
+
+		// Either kind loads whatever is being written now
+		compressSaves = !compress
+		state, err := Load(1)
+		if err != nil {
+			t.Fatalf("compress=%v: Load() error = %v", compress, err)
+		}
+		if len(state.Map.Tiles) != 256 || state.Map.Tiles[0][3] != 1 || state.Map.Tiles[1][1] != 0 {
+			t.Errorf("compress=%v: map not restored", compress)
+		}
+	}
+	if sizes[true]*10 > sizes[false] {
+		t.Errorf("compressed save is %d bytes, uncompressed %d; want under a tenth", sizes[true], sizes[false])
+	}
+}
+
+// benchmarkLargeMap runs fn for a large save, written compressed and not.
+func benchmarkLargeMap(b *testing.B, fn func(b *testing.B, state *GameState)) {
+	tempDir := b.TempDir()
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	defer os.Setenv("HOME", originalHome)
+	defer func() { compressSaves = true }()
+
+	state := largeState()
+	for _, compress := range []bool{false, true} {
+		name := "uncompressed"
+		if compress {
+			name = "gzip"
+		}
+		b.Run(name, func(b *testing.B) {
+			compressSaves = compress
+			fn(b, state)
+		})
+	}
+}
+
+func BenchmarkSaveLargeMap(b *testing.B) {
+	benchmarkLargeMap(b, func(b *testing.B, state *GameState) {
+		for i := 0; i < b.N; i++ {
+			if err := Save(1, state); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkLoadLargeMap(b *testing.B) {
+	benchmarkLargeMap(b, func(b *testing.B, state *GameState) {
+		if err := Save(1, state); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := Load(1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package save
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MigrateFunc upgrades a decoded save, as JSON objects keyed by field tag,
+// from one version to the next. Numbers are json.Number so seeds keep
+// their precision.
+type MigrateFunc func(state map[string]any) error
+
+// migration upgrades saves of one version to the next.
+type migration struct {
+	to      string
+	migrate MigrateFunc
+}
+
+// migrations are the registered migrations by the version they upgrade.
+var migrations = map[string]migration{}
+
+// RegisterMigration registers the migration of saves from version from to
+// version to. Loading a save runs every migration from its version up to
+// CurrentVersion in turn, so each release only registers the step from
+// the version before it.
+func RegisterMigration(from, to string, fn MigrateFunc) {
+	migrations[from] = migration{to: to, migrate: fn}
+}
+
+func init() {
+	// 1.0 saves have no world state and may leave the depth out.
+	RegisterMigration("1.0", "1.1", func(state map[string]any) error {
+		if _, ok := state["depth"]; !ok {
+			state["depth"] = json.Number("1")
+		}
+		return nil
+	})
+}
+
+// migrationPath returns the versions a save of the given version passes
+// through up to CurrentVersion, or an error if there is no way there.
+func migrationPath(version string) ([]string, error) {
+	var path []string
+	for v := version; v != CurrentVersion; {
+		m, ok := migrations[v]
+		if !ok || len(path) > len(migrations) {
+			return nil, fmt.Errorf("%w: save is version %s, game requires version %s",
+				ErrIncompatibleVersion, version, CurrentVersion)
+		}
+		path = append(path, v)
+		v = m.to
+	}
+	return path, nil
+}
+
+// migrate upgrades the save data to CurrentVersion and returns it
+// re-encoded. Current saves are returned as they are.
+func migrate(data []byte) ([]byte, error) {
+	var state map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&state); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal game state: %w", ErrCorruptSave, err)
+	}
+	version, _ := state["version"].(string)
+	if err := validateVersion(version); err != nil {
+		return nil, err
+	}
+	if version == CurrentVersion {
+		return data, nil
+	}
+
+	path, _ := migrationPath(version)
+	for _, v := range path {
+		m := migrations[v]
+		if err := m.migrate(state); err != nil {
+			return nil, fmt.Errorf("failed to migrate save from version %s to %s: %w", v, m.to, err)
+		}
+		state["version"] = m.to
+	}
+	return json.Marshal(state)
+}
@@ -0,0 +1,123 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixtureSeed is the seed of every fixture save; it doesn't fit a float64.
+const fixtureSeed = 9007199254740993
+
+// TestLoadFixtures loads a save written by every released version from
+// testdata. Each release adds its fixture and checks here.
+func TestLoadFixtures(t *testing.T) {
+	_, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	checks := map[string]func(t *testing.T, state *GameState){
+		"1.0": func(t *testing.T, state *GameState) {
+			if state.Depth != 1 {
+				t.Errorf("Depth = %d, want 1 for saves without one", state.Depth)
+			}
+			if state.World != nil || state.Player.Health != 80 || state.AmmoPool["shells"] != 8 {
+				t.Errorf("state = %+v", state)
+			}
+		},
+		"1.1": func(t *testing.T, state *GameState) {
+			if state.Depth != 4 || len(state.Quests) != 1 || !state.Quests[0].Claimed {
+				t.Errorf("depth %d, quests %+v", state.Depth, state.Quests)
+			}
+			if w := state.World; w == nil || len(w.Enemies) != 2 || w.Enemies[1].Health != 35 || !w.Destructibles[0].Destroyed {
+				t.Errorf("World = %+v", state.World)
+			}
+		},
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "save_v*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != len(checks) {
+		t.Errorf("%d fixtures for %d released versions", len(fixtures), len(checks))
+	}
+	for _, fixture := range fixtures {
+		version := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(fixture), "save_v"), ".json")
+		t.Run(version, func(t *testing.T) {
+			data, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slotPath, err := getSlotPath(3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(slotPath, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			state, err := Load(3)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if state.Version != CurrentVersion {
+				t.Errorf("Version = %s, want %s", state.Version, CurrentVersion)
+			}
+			if state.Seed != fixtureSeed {
+				t.Errorf("Seed = %d, want %d", state.Seed, int64(fixtureSeed))
+			}
+			check, ok := checks[version]
+			if !ok {
+				t.Fatalf("no checks for fixture version %s", version)
+			}
+			check(t, state)
+		})
+	}
+}
+
+func TestMigrationChain(t *testing.T) {
+	var ran []string
+	RegisterMigration("0.8", "0.9", func(state map[string]any) error {
+		ran = append(ran, "0.8")
+		state["genre"] = state["theme"]
+		delete(state, "theme")
+		return nil
+	})
+	RegisterMigration("0.9", "1.0", func(state map[string]any) error {
+		ran = append(ran, "0.9")
+		return nil
+	})
+	RegisterMigration("0.7", "0.8", func(state map[string]any) error {
+		return errors.New("corrupt keycards")
+	})
+	defer func() {
+		delete(migrations, "0.7")
+		delete(migrations, "0.8")
+		delete(migrations, "0.9")
+	}()
+
+	data, err := migrate([]byte(`{"version": "0.8", "seed": 9007199254740993, "theme": "horror"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ran, ",") != "0.8,0.9" {
+		t.Errorf("ran migrations %v, want 0.8 then 0.9 then on from 1.0", ran)
+	}
+	var state GameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Version != CurrentVersion || state.Genre != "horror" || state.Seed != fixtureSeed || state.Depth != 1 {
+		t.Errorf("migrated state = %+v", state)
+	}
+
+	if _, err := migrate([]byte(`{"version": "0.7"}`)); err == nil || !strings.Contains(err.Error(), "corrupt keycards") {
+		t.Errorf("failing migration: err = %v", err)
+	}
+	if _, err := migrate([]byte(`{"version": "0.6"}`)); !errors.Is(err, ErrIncompatibleVersion) {
+		t.Errorf("version without migrations: err = %v, want ErrIncompatibleVersion", err)
+	}
+}
@@ -8,13 +8,14 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 )
 
 const (
 	AutoSaveSlot   = 0
 	MaxSlots       = 10
-	CurrentVersion = "1.0"
+	CurrentVersion = "1.1"
 )
 
 // ErrInvalidSlot is returned when the save slot is invalid.
@@ -23,6 +24,12 @@ var ErrInvalidSlot = errors.New("invalid save slot")
 // ErrSlotEmpty is returned when the save slot is empty.
 var ErrSlotEmpty = errors.New("save slot is empty")
 
+// ErrNoStash is returned when no stash has been saved yet.
+var ErrNoStash = errors.New("no stash saved")
+
+// ErrNoProfile is returned when no profile has been saved yet.
+var ErrNoProfile = errors.New("no profile saved")
+
 // ErrIncompatibleVersion is returned when the save file version is incompatible.
 var ErrIncompatibleVersion = errors.New("save file version is incompatible with current game version")
 
@@ -38,6 +45,125 @@ type GameState struct {
 	Progression ProgressionState `json:"progression"`
 	Keycards    map[string]bool  `json:"keycards"`
 	AmmoPool    map[string]int   `json:"ammo_pool"`
+	Lighting    *LightingState   `json:"lighting,omitempty"`
+	Depth       int              `json:"depth,omitempty"` // Dungeon level; 0 in older saves means 1
+	Clips       map[int]int      `json:"clips,omitempty"` // Magazine rounds by weapon slot; nil in older saves keeps them full
+	Rolls       map[int]uint64   `json:"rolls,omitempty"` // Seeds of procedurally rolled weapons by slot
+	Mods        map[int][]string `json:"mods,omitempty"`  // Attachment IDs fitted by weapon slot
+	Loads       map[int]string   `json:"loads,omitempty"` // Ammo variant IDs loaded by weapon slot
+	Armor       []ArmorPiece     `json:"armor,omitempty"` // Armor pieces worn
+	Cache       *Container       `json:"cache,omitempty"` // The current level's player cache
+
+	// Quests is the progress of the campaign's quests.
+	Quests []QuestNode `json:"quests,omitempty"`
+	// World is the state of the current level's systems; nil before 1.1.
+	World *WorldState `json:"world,omitempty"`
+
+	Playtime  time.Duration `json:"playtime,omitempty"`  // Time played in the run
+	Thumbnail []byte        `json:"thumbnail,omitempty"` // PNG map thumbnail shown in the slot list
+}
+
+// WorldState is the state of the current level's systems. On load the
+// level is regenerated from the seed and depth and this is applied over it.
+type WorldState struct {
+	Enemies       []Enemy        `json:"enemies,omitempty"`
+	Boss          *Enemy         `json:"boss,omitempty"`
+	Escort        *Escort        `json:"escort,omitempty"`
+	Destructibles []Destructible `json:"destructibles,omitempty"`
+	Secrets       []Secret       `json:"secrets,omitempty"`
+	Objectives    []Objective    `json:"objectives,omitempty"`
+	Lore          []LoreItem     `json:"lore,omitempty"`
+	Codex         []string       `json:"codex,omitempty"` // IDs of the codex entries found
+	Props         []Prop         `json:"props,omitempty"`
+	Hazards       []Hazard       `json:"hazards,omitempty"`
+}
+
+// Enemy is an enemy's position and health, and what its AI was doing.
+type Enemy struct {
+	ID     string  `json:"id"`
+	Squad  string  `json:"squad,omitempty"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Health float64 `json:"health"`
+	State  int     `json:"state,omitempty"`
+}
+
+// Escort is the level's escortee and whether it follows the player.
+type Escort struct {
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Health    float64 `json:"health"`
+	Following bool    `json:"following,omitempty"`
+}
+
+// Destructible is a destructible object's remaining health.
+type Destructible struct {
+	ID        string  `json:"id"`
+	Health    float64 `json:"health"`
+	Destroyed bool    `json:"destroyed,omitempty"`
+}
+
+// Secret is a secret wall the player has found.
+type Secret struct {
+	X             int     `json:"x"`
+	Y             int     `json:"y"`
+	State         int     `json:"state"`
+	Progress      float64 `json:"progress"`
+	DiscoveredBy  string  `json:"discovered_by,omitempty"`
+	RewardSpawned bool    `json:"reward_spawned,omitempty"`
+}
+
+// Objective is the progress of a level objective and the items its
+// reward gained on completion.
+type Objective struct {
+	ID       string `json:"id"`
+	Progress int64  `json:"progress,omitempty"`
+	Complete bool   `json:"complete,omitempty"`
+	Failed   bool   `json:"failed,omitempty"`
+	Claimed  bool   `json:"claimed,omitempty"`
+	Items    []Item `json:"items,omitempty"`
+}
+
+// LoreItem is a lore item placed in the level.
+type LoreItem struct {
+	ID        string  `json:"id"`
+	Type      int     `json:"type"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Text      string  `json:"text"`
+	Context   string  `json:"context,omitempty"`
+	CodexID   string  `json:"codex_id"`
+	Activated bool    `json:"activated,omitempty"`
+}
+
+// Prop is a decorative prop placed in the level.
+type Prop struct {
+	ID        string  `json:"id"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Type      int     `json:"type"`
+	Collision bool    `json:"collision,omitempty"`
+	Name      string  `json:"name"`
+}
+
+// Hazard is an environmental hazard and where its cycle is.
+type Hazard struct {
+	X                float64 `json:"x"`
+	Y                float64 `json:"y"`
+	Type             int     `json:"type"`
+	State            int     `json:"state"`
+	Timer            float64 `json:"timer"`
+	CycleDuration    float64 `json:"cycle_duration"`
+	ActiveDuration   float64 `json:"active_duration"`
+	ChargeDuration   float64 `json:"charge_duration"`
+	CooldownDuration float64 `json:"cooldown_duration"`
+	Damage           int     `json:"damage"`
+	StatusEffect     string  `json:"status_effect,omitempty"`
+	Persistent       bool    `json:"persistent,omitempty"`
+	Triggered        bool    `json:"triggered,omitempty"`
+	Width            float64 `json:"width"`
+	Height           float64 `json:"height"`
+	Color            uint32  `json:"color"`
 }
 
 // Player holds player state.
@@ -52,6 +178,64 @@ type Player struct {
 	Ammo   int     `json:"ammo"`
 }
 
+// ArmorPiece is a worn armor piece and the durability it has left.
+type ArmorPiece struct {
+	ID        string  `json:"id"`
+	Seed      uint64  `json:"seed,omitempty"` // Seed its rarity and affixes were rolled from
+	Condition float64 `json:"condition"`
+}
+
+// QuestNode is the state of a campaign quest, the progress of each of its
+// objectives and whether its reward was claimed.
+type QuestNode struct {
+	ID       string  `json:"id"`
+	State    int     `json:"state"`
+	Progress []int64 `json:"progress,omitempty"`
+	Claimed  bool    `json:"claimed,omitempty"`
+}
+
+// Profile is what the player keeps across every slot and run.
+type Profile struct {
+	MetaQuests []MetaQuest `json:"meta_quests,omitempty"`
+	Stats      Stats       `json:"stats"`
+}
+
+// Stats are the player's totals over every run, added to as each level is
+// completed.
+type Stats struct {
+	Kills           int                   `json:"kills,omitempty"`
+	WeaponKills     map[string]int        `json:"weapon_kills,omitempty"` // By weapon name
+	Deaths          int                   `json:"deaths,omitempty"`
+	SecretsFound    int                   `json:"secrets_found,omitempty"`
+	LevelsCompleted int                   `json:"levels_completed,omitempty"`
+	DeepestLevel    int                   `json:"deepest_level,omitempty"`
+	Genres          []string              `json:"genres,omitempty"` // Genres whose campaign was completed
+	Achievements    []UnlockedAchievement `json:"achievements,omitempty"`
+}
+
+// UnlockedAchievement is an achievement the player has unlocked.
+type UnlockedAchievement struct {
+	ID         string    `json:"id"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// MetaQuest is the progress of a meta-quest: a count, or the distinct keys
+// found for quests counting those, and whether its reward was claimed.
+type MetaQuest struct {
+	ID       string   `json:"id"`
+	Progress int64    `json:"progress,omitempty"`
+	Keys     []string `json:"keys,omitempty"`
+	Claimed  bool     `json:"claimed,omitempty"`
+}
+
+// Container is a player-owned container and what it holds.
+type Container struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+	Items    []Item `json:"items"`
+}
+
 // Map holds level map data.
 type Map struct {
 	Width  int     `json:"width"`
@@ -69,6 +253,7 @@ type Item struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 	Qty  int    `json:"qty"`
+	Seed uint64 `json:"seed,omitempty"` // Seed its rarity and affixes were rolled from
 }
 
 // ProgressionState holds player progression data.
@@ -77,49 +262,101 @@ type ProgressionState struct {
 	XP    int `json:"xp"`
 }
 
+// LightingState holds the day/night clock and any scripted lighting
+// transition in progress. Saves without it start at the genre's default
+// time of day.
+type LightingState struct {
+	Hour           float64 `json:"hour"`
+	Speed          float64 `json:"speed"`
+	TargetHour     float64 `json:"target_hour,omitempty"`
+	TransitionLeft float64 `json:"transition_left,omitempty"`
+}
+
 // Slot represents a save-game slot with metadata.
 type Slot struct {
-	ID        int       `json:"id"`
-	Timestamp time.Time `json:"timestamp"`
-	Genre     string    `json:"genre"`
-	Seed      int64     `json:"seed"`
-	Exists    bool      `json:"exists"`
+	ID        int           `json:"id"`
+	Timestamp time.Time     `json:"timestamp"`
+	Genre     string        `json:"genre"`
+	Seed      int64         `json:"seed"`
+	Exists    bool          `json:"exists"`
+	Depth     int           `json:"depth,omitempty"`
+	Playtime  time.Duration `json:"playtime,omitempty"`
+	Thumbnail []byte        `json:"thumbnail,omitempty"`
+	Corrupt   bool          `json:"corrupt,omitempty"` // Failed its checksum or can't be decoded
+	Locked    bool          `json:"locked,omitempty"`  // Encrypted under another passphrase, or none is set
 }
 
-// getSavePath returns the platform-specific save directory path.
-// On Windows: %APPDATA%\violence\saves
-// On macOS/Linux/Unix: ~/.violence/saves
+var (
+	dirMu       sync.RWMutex
+	dirOverride string
+)
+
+// SetDir makes dir the save directory, in place of the platform's. An
+// empty dir goes back to the platform's.
+func SetDir(dir string) {
+	dirMu.Lock()
+	dirOverride = dir
+	dirMu.Unlock()
+}
+
+// getSavePath returns the save directory path, creating it if needed.
 func getSavePath() (string, error) {
-	var baseDir string
-	var err error
+	dirMu.RLock()
+	savePath := dirOverride
+	dirMu.RUnlock()
+
+	if savePath == "" {
+		var err error
+		if savePath, err = platformSavePath(); err != nil {
+			return "", err
+		}
+	}
+	if err := os.MkdirAll(savePath, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create save directory: %w", err)
+	}
+	return savePath, nil
+}
 
+// platformSavePath returns the platform-specific save directory path.
+// On Windows: %APPDATA%\violence\saves
+// On macOS: ~/Library/Application Support/violence/saves
+// On Linux/Unix: $XDG_DATA_HOME/violence/saves, by default
+// ~/.local/share/violence/saves
+// Saves already in ~/.violence/saves, where earlier versions kept them
+// outside Windows, stay there.
+func platformSavePath() (string, error) {
 	if runtime.GOOS == "windows" {
 		// Use %APPDATA% on Windows
-		baseDir = os.Getenv("APPDATA")
+		baseDir := os.Getenv("APPDATA")
 		if baseDir == "" {
 			// Fallback to user home directory if APPDATA is not set
-			baseDir, err = os.UserHomeDir()
+			home, err := os.UserHomeDir()
 			if err != nil {
 				return "", fmt.Errorf("failed to get home directory: %w", err)
 			}
+			baseDir = home
 		}
-		savePath := filepath.Join(baseDir, "violence", "saves")
-		if err := os.MkdirAll(savePath, 0o755); err != nil {
-			return "", fmt.Errorf("failed to create save directory: %w", err)
-		}
-		return savePath, nil
+		return filepath.Join(baseDir, "violence", "saves"), nil
 	}
 
-	// Unix/Linux/macOS: use hidden directory in home
-	baseDir, err = os.UserHomeDir()
+	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	savePath := filepath.Join(baseDir, ".violence", "saves")
-	if err := os.MkdirAll(savePath, 0o755); err != nil {
-		return "", fmt.Errorf("failed to create save directory: %w", err)
+	legacy := filepath.Join(home, ".violence", "saves")
+	if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+		return legacy, nil
 	}
-	return savePath, nil
+
+	baseDir := filepath.Join(home, ".local", "share")
+	switch {
+	case runtime.GOOS == "darwin":
+		baseDir = filepath.Join(home, "Library", "Application Support")
+	case filepath.IsAbs(os.Getenv("XDG_DATA_HOME")):
+		// The XDG spec says to ignore relative paths
+		baseDir = os.Getenv("XDG_DATA_HOME")
+	}
+	return filepath.Join(baseDir, "violence", "saves"), nil
 }
 
 // getSlotPath returns the file path for a given slot.
@@ -155,30 +392,34 @@ func Save(slot int, state *GameState) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal game state: %w", err)
 	}
+	if data, err = seal(data); err != nil {
+		return err
+	}
 
 	return atomicWrite(slotPath, data)
 }
 
-// validateVersion checks if the save file version is compatible with the current game version.
+// validateVersion checks if the save file version is the current game
+// version or migrates to it.
 func validateVersion(saveVersion string) error {
 	if saveVersion == "" {
 		return fmt.Errorf("save file missing version field")
 	}
-	if saveVersion != CurrentVersion {
-		return fmt.Errorf("%w: save is version %s, game requires version %s",
-			ErrIncompatibleVersion, saveVersion, CurrentVersion)
-	}
-	return nil
+	_, err := migrationPath(saveVersion)
+	return err
 }
 
-// atomicWrite writes data to path atomically using temp file + rename.
+// atomicWrite writes data to path atomically: it writes a hidden temp
+// file beside it, syncs and closes it, then renames it over path. Sync
+// clients such as Steam Cloud and Nextcloud only ever see a whole file,
+// and no handle is left open on it.
 func atomicWrite(path string, data []byte) error {
-	tmpPath := path + ".tmp"
-
-	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	dir, name := filepath.Split(path)
+	f, err := os.CreateTemp(dir, "."+name+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
+	tmpPath := f.Name()
 
 	if _, err := f.Write(data); err != nil {
 		f.Close()
@@ -192,20 +433,40 @@ func atomicWrite(path string, data []byte) error {
 		return fmt.Errorf("failed to sync temp file: %w", err)
 	}
 
-	closeErr := f.Close()
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	// CreateTemp makes the file private to the user; saves never were
+	if err := os.Chmod(tmpPath, 0o644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to set temp file permissions: %w", err)
+	}
 
 	if err := os.Rename(tmpPath, path); err != nil {
 		os.Remove(tmpPath)
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
 
-	if closeErr != nil {
-		return fmt.Errorf("file saved but close failed: %w", closeErr)
-	}
-
+	syncDir(dir)
 	return nil
 }
 
+// syncDir flushes a directory's entries, so a rename into it survives a
+// crash. Windows can't sync directories; elsewhere it's best effort.
+func syncDir(dir string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
 // Load reads game state from the given slot.
 func Load(slot int) (*GameState, error) {
 	if slot < 0 || slot >= MaxSlots {
@@ -226,16 +487,22 @@ func Load(slot int) (*GameState, error) {
 		return nil, fmt.Errorf("failed to read save file: %w", err)
 	}
 
-	var state GameState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal game state: %w", err)
+	// Verify the checksum and decrypt before reading anything
+	data, err = open(data)
+	if err != nil {
+		return nil, err
 	}
 
-	// Validate save version compatibility
-	if err := validateVersion(state.Version); err != nil {
+	// Validate save version compatibility and bring older saves up to date
+	data, err = migrate(data)
+	if err != nil {
 		return nil, err
 	}
 
+	var state GameState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal game state: %w", ErrCorruptSave, err)
+	}
 	return &state, nil
 }
 
@@ -261,7 +528,16 @@ func ListSlots() ([]Slot, error) {
 		}
 
 		state, err := Load(i)
-		if err != nil {
+		switch {
+		case errors.Is(err, ErrCorruptSave):
+			slots[i].Exists = true
+			slots[i].Corrupt = true
+			continue
+		case errors.Is(err, ErrPassphraseRequired), errors.Is(err, ErrWrongPassphrase):
+			slots[i].Exists = true
+			slots[i].Locked = true
+			continue
+		case err != nil:
 			continue
 		}
 
@@ -269,6 +545,9 @@ func ListSlots() ([]Slot, error) {
 		slots[i].Timestamp = state.Timestamp
 		slots[i].Genre = state.Genre
 		slots[i].Seed = state.Seed
+		slots[i].Depth = max(state.Depth, 1)
+		slots[i].Playtime = state.Playtime
+		slots[i].Thumbnail = state.Thumbnail
 	}
 	return slots, nil
 }
@@ -295,6 +574,31 @@ func DeleteSlot(slot int) error {
 	return nil
 }
 
+// CopySlot copies the save in slot from to slot to, replacing any save
+// there.
+func CopySlot(from, to int) error {
+	if from < 0 || from >= MaxSlots || to < 0 || to >= MaxSlots {
+		return ErrInvalidSlot
+	}
+	fromPath, err := getSlotPath(from)
+	if err != nil {
+		return err
+	}
+	toPath, err := getSlotPath(to)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(fromPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrSlotEmpty
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read save file: %w", err)
+	}
+	return atomicWrite(toPath, data)
+}
+
 // GetReplayPath returns the file path for a replay file associated with a slot.
 func GetReplayPath(slot int) (string, error) {
 	if slot < 0 || slot >= MaxSlots {
@@ -306,3 +610,109 @@ func GetReplayPath(slot int) (string, error) {
 	}
 	return filepath.Join(savePath, fmt.Sprintf("slot_%d.vrep", slot)), nil
 }
+
+// getStashPath returns the file path of the persistent stash.
+func getStashPath() (string, error) {
+	savePath, err := getSavePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(savePath, "stash.json"), nil
+}
+
+// SaveStash writes the persistent stash, which is shared by every slot and
+// outlives runs, using atomic writes.
+func SaveStash(stash *Container) error {
+	if stash == nil {
+		return errors.New("stash is nil")
+	}
+	stashPath, err := getStashPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(stash, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stash: %w", err)
+	}
+	if data, err = seal(data); err != nil {
+		return err
+	}
+	return atomicWrite(stashPath, data)
+}
+
+// LoadStash reads the persistent stash. Returns ErrNoStash if none has
+// been saved.
+func LoadStash() (*Container, error) {
+	stashPath, err := getStashPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(stashPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoStash
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stash: %w", err)
+	}
+	if data, err = open(data); err != nil {
+		return nil, err
+	}
+	var stash Container
+	if err := json.Unmarshal(data, &stash); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal stash: %w", ErrCorruptSave, err)
+	}
+	return &stash, nil
+}
+
+// getProfilePath returns the file path of the player's profile.
+func getProfilePath() (string, error) {
+	savePath, err := getSavePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(savePath, "profile.json"), nil
+}
+
+// SaveProfile writes the player's profile, which is shared by every slot
+// and outlives runs, using atomic writes.
+func SaveProfile(profile *Profile) error {
+	if profile == nil {
+		return errors.New("profile is nil")
+	}
+	profilePath, err := getProfilePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+	if data, err = seal(data); err != nil {
+		return err
+	}
+	return atomicWrite(profilePath, data)
+}
+
+// LoadProfile reads the player's profile. Returns ErrNoProfile if none has
+// been saved.
+func LoadProfile() (*Profile, error) {
+	profilePath, err := getProfilePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(profilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoProfile
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	if data, err = open(data); err != nil {
+		return nil, err
+	}
+	var profile Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal profile: %w", ErrCorruptSave, err)
+	}
+	return &profile, nil
+}
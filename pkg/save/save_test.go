@@ -1,8 +1,10 @@
 package save
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -19,10 +21,13 @@ func setupTestDir(t *testing.T) (string, func()) {
 
 	// Override home directory for testing
 	originalHome := os.Getenv("HOME")
+	originalXDG := os.Getenv("XDG_DATA_HOME")
 	os.Setenv("HOME", tempDir)
+	os.Unsetenv("XDG_DATA_HOME")
 
 	cleanup := func() {
 		os.Setenv("HOME", originalHome)
+		os.Setenv("XDG_DATA_HOME", originalXDG)
 		os.RemoveAll(tempDir)
 	}
 
@@ -172,8 +177,8 @@ func TestLoad(t *testing.T) {
 				if len(state.Inventory.Items) != 1 {
 					t.Errorf("Inventory items count = %d, want 1", len(state.Inventory.Items))
 				}
-				if state.Version != "1.0" {
-					t.Errorf("Version = %s, want 1.0", state.Version)
+				if state.Version != CurrentVersion {
+					t.Errorf("Version = %s, want %s", state.Version, CurrentVersion)
 				}
 			},
 		},
@@ -288,6 +293,14 @@ func TestSaveLoadRoundTrip(t *testing.T) {
 						{ID: "medkit_small", Name: "Small Medkit", Qty: 3},
 					},
 				},
+				Lighting: &LightingState{Hour: 19.5, Speed: 0.02, TargetHour: 23, TransitionLeft: 4.5},
+				Depth:    4,
+				Clips:    map[int]int{1: 7, 4: 0},
+				Rolls:    map[int]uint64{2: 0xfeedface},
+				Mods:     map[int][]string{1: {"scope_reflex", "under_bayonet"}},
+				Armor:    []ArmorPiece{{ID: "chainmail_chest", Seed: 0xbeef, Condition: 72.5}, {ID: "warded_head", Condition: 0}},
+				Cache:    &Container{ID: "cache", Name: "Cache", Capacity: 8, Items: []Item{{ID: "scrap_bone_chips", Name: "bone_chips", Qty: 12}}},
+				Quests:   []QuestNode{{ID: "main_1", State: 2, Progress: []int64{9}, Claimed: true}, {ID: "main_2", State: 1}},
 			},
 		},
 		{
@@ -371,6 +384,33 @@ func TestSaveLoadRoundTrip(t *testing.T) {
 			if len(loaded.Inventory.Items) != len(tt.state.Inventory.Items) {
 				t.Errorf("Inventory items count = %d, want %d", len(loaded.Inventory.Items), len(tt.state.Inventory.Items))
 			}
+			if loaded.Depth != tt.state.Depth {
+				t.Errorf("Depth = %d, want %d", loaded.Depth, tt.state.Depth)
+			}
+			if !reflect.DeepEqual(loaded.Clips, tt.state.Clips) {
+				t.Errorf("Clips = %v, want %v", loaded.Clips, tt.state.Clips)
+			}
+			if !reflect.DeepEqual(loaded.Rolls, tt.state.Rolls) {
+				t.Errorf("Rolls = %v, want %v", loaded.Rolls, tt.state.Rolls)
+			}
+			if !reflect.DeepEqual(loaded.Mods, tt.state.Mods) {
+				t.Errorf("Mods = %v, want %v", loaded.Mods, tt.state.Mods)
+			}
+			if !reflect.DeepEqual(loaded.Quests, tt.state.Quests) {
+				t.Errorf("Quests = %+v, want %+v", loaded.Quests, tt.state.Quests)
+			}
+			if !reflect.DeepEqual(loaded.Cache, tt.state.Cache) {
+				t.Errorf("Cache = %+v, want %+v", loaded.Cache, tt.state.Cache)
+			}
+			if !reflect.DeepEqual(loaded.Armor, tt.state.Armor) {
+				t.Errorf("Armor = %v, want %v", loaded.Armor, tt.state.Armor)
+			}
+			switch {
+			case tt.state.Lighting == nil && loaded.Lighting != nil:
+				t.Errorf("Lighting = %+v, want nil", *loaded.Lighting)
+			case tt.state.Lighting != nil && (loaded.Lighting == nil || *loaded.Lighting != *tt.state.Lighting):
+				t.Errorf("Lighting = %v, want %+v", loaded.Lighting, *tt.state.Lighting)
+			}
 		})
 	}
 }
@@ -515,6 +555,36 @@ func TestDeleteSlot(t *testing.T) {
 	}
 }
 
+func TestCopySlot(t *testing.T) {
+	_, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	state := &GameState{Seed: 7, Genre: "cyberpunk", Depth: 3, Playtime: 95 * time.Minute, Thumbnail: []byte{0x89, 'P', 'N', 'G'}}
+	if err := Save(2, state); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopySlot(2, 5); err != nil {
+		t.Fatalf("CopySlot() error = %v", err)
+	}
+	slots, err := ListSlots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{2, 5} {
+		slot := slots[id]
+		if !slot.Exists || slot.Genre != "cyberpunk" || slot.Depth != 3 || slot.Playtime != 95*time.Minute || len(slot.Thumbnail) != 4 {
+			t.Errorf("slot %d = %+v", id, slot)
+		}
+	}
+
+	if err := CopySlot(4, 6); !errors.Is(err, ErrSlotEmpty) {
+		t.Errorf("copying an empty slot: err = %v, want ErrSlotEmpty", err)
+	}
+	if err := CopySlot(2, MaxSlots); !errors.Is(err, ErrInvalidSlot) {
+		t.Errorf("copying to an invalid slot: err = %v, want ErrInvalidSlot", err)
+	}
+}
+
 func TestCrossPlatformPath(t *testing.T) {
 	_, cleanup := setupTestDir(t)
 	defer cleanup()
@@ -670,8 +740,12 @@ func TestGetSavePath_PlatformSpecific(t *testing.T) {
 			t.Errorf("Windows save path should end with %s, got: %s", expectedSuffix, savePath)
 		}
 	} else {
-		// On Unix/Linux/macOS, should be ~/.violence/saves
-		expectedSuffix := filepath.Join(".violence", "saves")
+		// On macOS, should be ~/Library/Application Support/violence/saves,
+		// and elsewhere ~/.local/share/violence/saves
+		expectedSuffix := filepath.Join(".local", "share", "violence", "saves")
+		if runtime.GOOS == "darwin" {
+			expectedSuffix = filepath.Join("Library", "Application Support", "violence", "saves")
+		}
 		if !filepath.IsAbs(savePath) {
 			t.Errorf("save path should be absolute, got: %s", savePath)
 		}
@@ -694,6 +768,52 @@ func TestGetSavePath_PlatformSpecific(t *testing.T) {
 	}
 }
 
+func TestGetSavePath_Override(t *testing.T) {
+	home, cleanup := setupTestDir(t)
+	defer cleanup()
+	defer SetDir("")
+
+	dir := filepath.Join(home, "Sync", "violence")
+	SetDir(dir)
+	if err := Save(2, &GameState{Seed: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "slot_2.json")); err != nil {
+		t.Errorf("save not written to the override directory: %v", err)
+	}
+
+	SetDir("")
+	if _, err := Load(2); !errors.Is(err, ErrSlotEmpty) {
+		t.Errorf("Load() from the platform directory: err = %v, want ErrSlotEmpty", err)
+	}
+}
+
+func TestGetSavePath_XDGAndLegacy(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG and ~/.violence only apply on Linux and Unix")
+	}
+	home, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	xdg := filepath.Join(home, "data")
+	os.Setenv("XDG_DATA_HOME", xdg)
+	if got, _ := getSavePath(); got != filepath.Join(xdg, "violence", "saves") {
+		t.Errorf("with XDG_DATA_HOME, save path = %s", got)
+	}
+	os.Setenv("XDG_DATA_HOME", "relative/data")
+	if got, _ := getSavePath(); got != filepath.Join(home, ".local", "share", "violence", "saves") {
+		t.Errorf("with a relative XDG_DATA_HOME, save path = %s", got)
+	}
+
+	legacy := filepath.Join(home, ".violence", "saves")
+	if err := os.MkdirAll(legacy, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := getSavePath(); got != legacy {
+		t.Errorf("with saves in ~/.violence, save path = %s, want %s", got, legacy)
+	}
+}
+
 func TestAtomicWrite_NoTempFileLeakage(t *testing.T) {
 	_, cleanup := setupTestDir(t)
 	defer cleanup()
@@ -710,10 +830,14 @@ func TestAtomicWrite_NoTempFileLeakage(t *testing.T) {
 	}
 
 	slotPath, _ := getSlotPath(1)
-	tmpPath := slotPath + ".tmp"
-
-	if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
-		t.Errorf("temporary file should not exist after save: %s", tmpPath)
+	entries, err := os.ReadDir(filepath.Dir(slotPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != filepath.Base(slotPath) {
+			t.Errorf("temporary file should not exist after save: %s", e.Name())
+		}
 	}
 
 	if _, err := os.Stat(slotPath); os.IsNotExist(err) {
@@ -854,6 +978,7 @@ func TestValidateVersion(t *testing.T) {
 		wantErr bool
 	}{
 		{"valid current version", CurrentVersion, false},
+		{"compatible older version", "1.0", false},
 		{"empty version", "", true},
 		{"future version", "2.0", true},
 		{"old version", "0.9", true},
@@ -869,3 +994,93 @@ func TestValidateVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestStash(t *testing.T) {
+	_, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if _, err := LoadStash(); !errors.Is(err, ErrNoStash) {
+		t.Fatalf("LoadStash() before saving = %v, want ErrNoStash", err)
+	}
+	stash := &Container{ID: "stash", Name: "Stash", Capacity: 24, Items: []Item{
+		{ID: "scrap_salvage", Name: "salvage", Qty: 40},
+		{ID: "weapon_roll_postapoc", Name: "Brutal Pipe Gun", Qty: 1, Seed: 0xfeedface},
+	}}
+	if err := SaveStash(stash); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadStash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, stash) {
+		t.Errorf("LoadStash() = %+v, want %+v", loaded, stash)
+	}
+
+	// The stash is kept apart from the save slots.
+	if err := DeleteSlot(AutoSaveSlot); !errors.Is(err, ErrSlotEmpty) {
+		t.Errorf("saving the stash filled a slot: %v", err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	_, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	if _, err := LoadProfile(); !errors.Is(err, ErrNoProfile) {
+		t.Fatalf("LoadProfile() before saving = %v, want ErrNoProfile", err)
+	}
+	profile := &Profile{
+		MetaQuests: []MetaQuest{
+			{ID: "meta_kill_cult", Progress: 42},
+			{ID: "meta_lore_horror", Progress: 2, Keys: []string{"lore_horror_0", "lore_horror_3"}, Claimed: true},
+		},
+		Stats: Stats{
+			Kills: 120, WeaponKills: map[string]int{"Shotgun": 80, "Pistol": 40}, Deaths: 3,
+			SecretsFound: 7, LevelsCompleted: 9, DeepestLevel: 8,
+			Genres:       []string{"horror"},
+			Achievements: []UnlockedAchievement{{ID: "first_blood", UnlockedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}},
+		},
+	}
+	if err := SaveProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, profile) {
+		t.Errorf("LoadProfile() = %+v, want %+v", loaded, profile)
+	}
+	if err := SaveProfile(nil); err == nil {
+		t.Error("SaveProfile(nil) succeeded")
+	}
+}
+
+func TestWorldStateRoundTrip(t *testing.T) {
+	_, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	world := &WorldState{
+		Enemies:       []Enemy{{ID: "enemy_0", Squad: "squad_0", X: 3.5, Y: 4.5, Health: 12, State: 2}, {ID: "enemy_1", X: 8, Y: 9}},
+		Boss:          &Enemy{ID: "boss", X: 20, Y: 20, Health: 640},
+		Escort:        &Escort{X: 5, Y: 6, Health: 30, Following: true},
+		Destructibles: []Destructible{{ID: "barrel_0", Destroyed: true}, {ID: "crate_1", Health: 12}},
+		Secrets:       []Secret{{X: 4, Y: 7, State: 2, Progress: 1, DiscoveredBy: "player", RewardSpawned: true}},
+		Objectives:    []Objective{{ID: "obj_0", Progress: 3}, {ID: "bonus_secrets", Progress: 1, Complete: true, Items: []Item{{ID: "ring", Name: "Ring", Qty: 1, Seed: 9}}}},
+		Lore:          []LoreItem{{ID: "lore_horror_0", Type: 1, X: 2, Y: 3, Text: "A note.", CodexID: "codex_0", Activated: true}},
+		Codex:         []string{"codex_0"},
+		Props:         []Prop{{ID: "prop_1", X: 6, Y: 6, Type: 7, Name: "Torch"}},
+		Hazards:       []Hazard{{X: 9.5, Y: 9.5, Type: 2, State: 1, Timer: 1.25, CycleDuration: 4, Damage: 10, Width: 1, Height: 1, Color: 0xff0000ff}},
+	}
+	if err := Save(2, &GameState{Seed: 1, Genre: "horror", Depth: 3, World: world}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.World, world) {
+		t.Errorf("World = %+v, want %+v", loaded.World, world)
+	}
+}
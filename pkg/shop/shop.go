@@ -64,6 +64,10 @@ type Item struct {
 	Type  ItemType
 	Price int
 	Stock int // -1 = unlimited
+
+	// Seed is the seed a procedurally rolled weapon is generated from; 0
+	// for standard stock.
+	Seed uint64
 }
 
 // ShopInventory manages the complete shop catalog.
@@ -367,6 +371,25 @@ func (s *Shop) updateStockInSlice(items []Item, itemID string) {
 	}
 }
 
+// StockRolled replaces the procedurally rolled weapons on sale with items,
+// keeping the standard stock.
+func (s *Shop) StockRolled(items []Item) {
+	weapons := make([]Item, 0, len(s.Inventory.Weapons)+len(items))
+	for _, it := range s.Inventory.Weapons {
+		if it.Seed == 0 {
+			weapons = append(weapons, it)
+		}
+	}
+	s.Inventory.Weapons = append(weapons, items...)
+	s.Items = s.Inventory.GetAllItems()
+}
+
+// StockArmor adds armor pieces to the armor on sale.
+func (s *Shop) StockArmor(items []Item) {
+	s.Inventory.Armor = append(s.Inventory.Armor, items...)
+	s.Items = s.Inventory.GetAllItems()
+}
+
 // GetShopName returns the genre-specific shop name.
 func (s *Shop) GetShopName() string {
 	if s.shopName == "" {
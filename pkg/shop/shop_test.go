@@ -618,3 +618,34 @@ func TestCredit_ConcurrentAccess(t *testing.T) {
 		t.Errorf("expected 1050 credits after concurrent ops, got %d", c.Get())
 	}
 }
+
+func TestShop_StockRolled(t *testing.T) {
+	shop := NewArmory("scifi")
+	standard := len(shop.Inventory.Weapons)
+
+	shop.StockRolled([]Item{
+		{ID: "weapon_roll_1", Name: "Brutal Blaster", Type: ItemTypeWeapon, Price: 300, Stock: 1, Seed: 1},
+		{ID: "weapon_roll_2", Name: "Pulse Rifle", Type: ItemTypeWeapon, Price: 150, Stock: 1, Seed: 2},
+	})
+	if len(shop.Inventory.Weapons) != standard+2 || shop.GetItem("weapon_roll_2") == nil {
+		t.Fatalf("weapons after stocking = %+v", shop.Inventory.Weapons)
+	}
+
+	shop.StockRolled([]Item{{ID: "weapon_roll_3", Name: "Rapid Blaster", Type: ItemTypeWeapon, Price: 300, Stock: 1, Seed: 3}})
+	if len(shop.Inventory.Weapons) != standard+1 || shop.Inventory.FindItem("weapon_roll_1") != nil {
+		t.Errorf("restocking kept the old rolls: %+v", shop.Inventory.Weapons)
+	}
+	if shop.Inventory.FindItem("weapon_rifle") == nil {
+		t.Error("restocking dropped the standard stock")
+	}
+}
+
+func TestShop_StockArmor(t *testing.T) {
+	shop := NewArmory("fantasy")
+	standard := len(shop.Inventory.Armor)
+
+	shop.StockArmor([]Item{{ID: "chainmail_head", Name: "Chain Coif", Type: ItemTypeArmor, Price: 135, Stock: 1}})
+	if len(shop.Inventory.Armor) != standard+1 || shop.GetItem("chainmail_head") == nil {
+		t.Errorf("armor after stocking = %+v", shop.Inventory.Armor)
+	}
+}
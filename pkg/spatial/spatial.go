@@ -243,6 +243,16 @@ func (g *Grid) QueryBounds(minX, minY, maxX, maxY float64) []engine.Entity {
 	return results
 }
 
+// RemoveBounds removes and returns all entities within the axis-aligned
+// bounding box, e.g. when a world chunk is unloaded.
+func (g *Grid) RemoveBounds(minX, minY, maxX, maxY float64) []engine.Entity {
+	removed := g.QueryBounds(minX, minY, maxX, maxY)
+	for _, e := range removed {
+		g.Remove(e)
+	}
+	return removed
+}
+
 // Clear removes all entities from the grid.
 func (g *Grid) Clear() {
 	g.mu.Lock()
@@ -118,6 +118,26 @@ func TestGrid_Remove(t *testing.T) {
 	}
 }
 
+func TestGrid_RemoveBounds(t *testing.T) {
+	grid := NewGrid(16.0)
+
+	inside := engine.Entity(1)
+	outside := engine.Entity(2)
+	grid.Insert(inside, 10.0, 10.0)
+	grid.Insert(outside, 200.0, 200.0)
+
+	removed := grid.RemoveBounds(0.0, 0.0, 63.0, 63.0)
+	if len(removed) != 1 || removed[0] != inside {
+		t.Errorf("RemoveBounds returned %v, want [%v]", removed, inside)
+	}
+	if grid.Count() != 1 {
+		t.Errorf("expected 1 entity left, got %d", grid.Count())
+	}
+	if len(grid.QueryBounds(0.0, 0.0, 63.0, 63.0)) != 0 {
+		t.Error("entity still indexed after RemoveBounds")
+	}
+}
+
 func TestGrid_QueryBounds(t *testing.T) {
 	grid := NewGrid(10.0)
 
@@ -0,0 +1,142 @@
+package sprite
+
+import (
+	"container/list"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sheetKey identifies the sprites a sprite sheet replaces.
+type sheetKey struct {
+	Type    SpriteType
+	Subtype string
+}
+
+// SheetDirs maps the subdirectories LoadSheets reads to the sprite types
+// their sheets replace.
+var SheetDirs = map[string]SpriteType{
+	"enemy":        SpriteEnemy,
+	"prop":         SpriteProp,
+	"lore":         SpriteLoreItem,
+	"destructible": SpriteDestructible,
+	"pickup":       SpritePickup,
+	"projectile":   SpriteProjectile,
+}
+
+// SetSheet replaces the generated sprites of a type and subtype with the
+// frames of a sprite sheet: a strip of square frames side by side, as tall
+// as each frame is wide. Frames are scaled to the size asked for and
+// played in a loop. It returns the number of frames.
+func (g *Generator) SetSheet(spriteType SpriteType, subtype string, sheet image.Image) int {
+	bounds := sheet.Bounds()
+	side := bounds.Dy()
+	count := 1
+	if side > 0 && bounds.Dx() >= side {
+		count = bounds.Dx() / side
+	} else {
+		side = bounds.Dx()
+	}
+
+	frames := make([]image.Image, count)
+	for i := range frames {
+		frame := image.NewRGBA(image.Rect(0, 0, side, bounds.Dy()))
+		draw.Draw(frame, frame.Bounds(), sheet, bounds.Min.Add(image.Pt(i*side, 0)), draw.Src)
+		frames[i] = frame
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sheets[sheetKey{spriteType, subtype}] = frames
+	g.dropCached(spriteType, subtype)
+	return count
+}
+
+// ClearSheets drops every sprite sheet, returning to generated sprites.
+func (g *Generator) ClearSheets() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sheets = make(map[sheetKey][]image.Image)
+	g.cache = make(map[SpriteKey]*list.Element)
+	g.lruList = list.New()
+}
+
+// LoadSheets sets a sprite sheet from each PNG file in the subdirectories
+// of dir named in SheetDirs, for the subtype named by the file, e.g.
+// enemy/goblin.png or prop/barrel.png. Files that fail to decode are
+// skipped, leaving the generated sprites in place, and reported in the
+// returned error.
+func (g *Generator) LoadSheets(dir string) (int, error) {
+	loaded := 0
+	var failed []string
+	for name, spriteType := range SheetDirs {
+		paths, err := filepath.Glob(filepath.Join(dir, name, "*.png"))
+		if err != nil {
+			return loaded, err
+		}
+		for _, path := range paths {
+			sheet, err := loadPNG(path)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s/%s: %v", name, filepath.Base(path), err))
+				continue
+			}
+			g.SetSheet(spriteType, strings.TrimSuffix(filepath.Base(path), ".png"), sheet)
+			loaded++
+		}
+	}
+	if len(failed) > 0 {
+		return loaded, fmt.Errorf("failed to load sprite sheets: %s", strings.Join(failed, "; "))
+	}
+	return loaded, nil
+}
+
+// sheetFrame returns the sheet frame for a sprite scaled to size, if a
+// sheet replaces it.
+func (g *Generator) sheetFrame(spriteType SpriteType, subtype string, frame, size int) (*image.RGBA, bool) {
+	g.mu.RLock()
+	frames, ok := g.sheets[sheetKey{spriteType, subtype}]
+	g.mu.RUnlock()
+	if !ok || len(frames) == 0 {
+		return nil, false
+	}
+	if frame < 0 {
+		frame = -frame
+	}
+	src := frames[frame%len(frames)]
+	sb := src.Bounds()
+
+	// Nearest-neighbor keeps pixel art crisp
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		sy := sb.Min.Y + y*sb.Dy()/size
+		for x := 0; x < size; x++ {
+			dst.Set(x, y, src.At(sb.Min.X+x*sb.Dx()/size, sy))
+		}
+	}
+	return dst, true
+}
+
+// dropCached removes the cached sprites of a type and subtype (must be
+// called with lock held).
+func (g *Generator) dropCached(spriteType SpriteType, subtype string) {
+	for key, elem := range g.cache {
+		if key.Type == spriteType && key.Subtype == subtype {
+			g.lruList.Remove(elem)
+			delete(g.cache, key)
+		}
+	}
+}
+
+// loadPNG decodes a PNG file.
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
@@ -0,0 +1,85 @@
+package sprite
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// stripSheet builds a sheet of square frames, each filled with one color.
+func stripSheet(side int, colors ...color.RGBA) *image.RGBA {
+	sheet := image.NewRGBA(image.Rect(0, 0, side*len(colors), side))
+	for i, c := range colors {
+		for y := 0; y < side; y++ {
+			for x := 0; x < side; x++ {
+				sheet.SetRGBA(i*side+x, y, c)
+			}
+		}
+	}
+	return sheet
+}
+
+func TestGenerator_SetSheet(t *testing.T) {
+	gen := NewGenerator(10)
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	before := gen.GetSprite(SpriteEnemy, "goblin", 1, 0, 32)
+	if n := gen.SetSheet(SpriteEnemy, "goblin", stripSheet(8, red, blue)); n != 2 {
+		t.Fatalf("SetSheet = %d frames, want 2", n)
+	}
+	if gen.GetSprite(SpriteEnemy, "goblin", 1, 0, 32) == before {
+		t.Error("SetSheet left the generated sprite cached")
+	}
+
+	for frame, want := range []color.RGBA{red, blue, red} {
+		img, ok := gen.sheetFrame(SpriteEnemy, "goblin", frame, 32)
+		if !ok {
+			t.Fatalf("frame %d: no sheet", frame)
+		}
+		if img.Bounds().Dx() != 32 || img.RGBAAt(31, 31) != want {
+			t.Errorf("frame %d: size %v, color %v; want %v", frame, img.Bounds(), img.RGBAAt(31, 31), want)
+		}
+	}
+	if _, ok := gen.sheetFrame(SpriteEnemy, "orc", 0, 32); ok {
+		t.Error("sheet applied to another subtype")
+	}
+
+	gen.ClearSheets()
+	if _, ok := gen.sheetFrame(SpriteEnemy, "goblin", 0, 32); ok {
+		t.Error("ClearSheets left the sheet in place")
+	}
+}
+
+func TestGenerator_LoadSheets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "prop"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, "prop", "barrel.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, stripSheet(4, color.RGBA{0, 255, 0, 255})); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.WriteFile(filepath.Join(dir, "prop", "broken.png"), []byte("not a png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	gen := NewGenerator(10)
+	n, err := gen.LoadSheets(dir)
+	if n != 1 || err == nil {
+		t.Errorf("LoadSheets = %d, %v; want 1 and an error for broken.png", n, err)
+	}
+	if _, ok := gen.sheetFrame(SpriteProp, "barrel", 0, 16); !ok {
+		t.Error("barrel sheet not loaded")
+	}
+	if _, ok := gen.sheetFrame(SpriteProp, "broken", 0, 16); ok {
+		t.Error("broken sheet replaced the generated sprite")
+	}
+}
@@ -56,6 +56,7 @@ type Generator struct {
 	ditherSys    *dither.System
 	rimlightSys  *rimlight.System
 	variationGen *seedvariation.Generator
+	sheets       map[sheetKey][]image.Image // Supplied frames, e.g. from mods
 }
 
 // NewGenerator creates a sprite generator with LRU cache.
@@ -69,6 +70,7 @@ func NewGenerator(maxCacheEntries int) *Generator {
 		ditherSys:    dither.NewSystem(12345),
 		rimlightSys:  rimlight.NewSystem("fantasy"),
 		variationGen: seedvariation.NewGenerator("fantasy"),
+		sheets:       make(map[sheetKey][]image.Image),
 	}
 }
 
@@ -127,8 +129,13 @@ func (g *Generator) GetSprite(spriteType SpriteType, subtype string, seed int64,
 	return img
 }
 
-// generateSprite creates a new procedural sprite.
+// generateSprite creates a new procedural sprite, or takes the frame from
+// a sprite sheet supplied for the type and subtype.
 func (g *Generator) generateSprite(spriteType SpriteType, subtype string, seed int64, frame, size int) *ebiten.Image {
+	if img, ok := g.sheetFrame(spriteType, subtype, frame, size); ok {
+		return ebiten.NewImageFromImage(img)
+	}
+
 	rgba := pool.GlobalPools.Images.Get(size, size)
 	rng := rand.New(rand.NewSource(seed))
 
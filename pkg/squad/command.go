@@ -0,0 +1,227 @@
+package squad
+
+import "errors"
+
+// Order is a command the player gives their squad.
+type Order int
+
+const (
+	// OrderHold makes members hold their current positions.
+	OrderHold Order = iota
+	// OrderRegroup recalls members to the leader's formation.
+	OrderRegroup
+	// OrderAttackTarget focuses every member on one target.
+	OrderAttackTarget
+	// OrderUseAbility makes every ready member use their class ability.
+	OrderUseAbility
+)
+
+// String returns the order name.
+func (o Order) String() string {
+	switch o {
+	case OrderHold:
+		return "hold"
+	case OrderRegroup:
+		return "regroup"
+	case OrderAttackTarget:
+		return "attack"
+	case OrderUseAbility:
+		return "ability"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// ErrNoMembers is returned when an order is given to an empty squad.
+	ErrNoMembers = errors.New("squad has no living members")
+	// ErrAbilityNotReady is returned when no member can use an ability yet.
+	ErrAbilityNotReady = errors.New("no squad ability is ready")
+)
+
+// Ability is a class skill a member uses on command.
+type Ability struct {
+	Name     string
+	Heal     bool    // Heals the leader instead of damaging enemies
+	Amount   float64 // Health restored or damage dealt
+	Radius   float64 // Blast radius around the aim point; 0 for heals
+	Cooldown int     // Ticks before the ability can be used again
+}
+
+// classAbilities holds the ability of each member class.
+var classAbilities = map[string]Ability{
+	"grunt":  {Name: "suppressing_fire", Amount: 20, Radius: 2, Cooldown: 600},
+	"medic":  {Name: "field_dressing", Heal: true, Amount: 25, Cooldown: 900},
+	"demo":   {Name: "breach_charge", Amount: 60, Radius: 2.5, Cooldown: 1200},
+	"mystic": {Name: "mending_ward", Heal: true, Amount: 15, Cooldown: 600},
+}
+
+// AbilityFor returns the ability of a member class.
+func AbilityFor(classID string) (Ability, bool) {
+	a, ok := classAbilities[classID]
+	return a, ok
+}
+
+// AbilityUse is one member's use of their ability, aimed at X, Y.
+type AbilityUse struct {
+	MemberID string
+	Ability  Ability
+	X, Y     float64
+}
+
+// Acknowledgment is the bark a member answers an order with.
+type Acknowledgment struct {
+	MemberID string
+	Voice    string // The member's class, used to pick a voice
+	Order    Order
+	Line     string
+	X, Y     float64
+}
+
+// ackLines are the barks for each order by genre.
+var ackLines = map[string]map[Order][]string{
+	"fantasy": {
+		OrderHold:         {"We hold here!", "Standing fast!"},
+		OrderRegroup:      {"To your side!", "Falling back to you!"},
+		OrderAttackTarget: {"For the realm!", "Steel on that one!"},
+		OrderUseAbility:   {"Let it loose!", "By my oath!"},
+	},
+	"scifi": {
+		OrderHold:         {"Holding position.", "Sector locked."},
+		OrderRegroup:      {"Regrouping on you.", "Moving to formation."},
+		OrderAttackTarget: {"Target acquired.", "Engaging hostile."},
+		OrderUseAbility:   {"Deploying now.", "Systems hot."},
+	},
+	"horror": {
+		OrderHold:         {"I'll stay... I'll stay.", "Not moving."},
+		OrderRegroup:      {"Don't leave me!", "Coming, wait!"},
+		OrderAttackTarget: {"Kill it! Kill it!", "On it!"},
+		OrderUseAbility:   {"Now, now!", "Take this!"},
+	},
+	"cyberpunk": {
+		OrderHold:         {"Holding the block.", "Parked."},
+		OrderRegroup:      {"On your six, choom.", "Rolling back."},
+		OrderAttackTarget: {"Flatlining 'em.", "Tagged and bagged."},
+		OrderUseAbility:   {"Running the program.", "Chrome's hot."},
+	},
+	"postapoc": {
+		OrderHold:         {"Dug in.", "Holding this ground."},
+		OrderRegroup:      {"Back to you, boss.", "Closing up."},
+		OrderAttackTarget: {"Scrap that one!", "Going for it!"},
+		OrderUseAbility:   {"Light it up!", "Here goes nothing!"},
+	},
+}
+
+// HoldPosition orders members to hold where they stand.
+func (s *Squad) HoldPosition() (Acknowledgment, error) {
+	if s.living() == 0 {
+		return Acknowledgment{}, ErrNoMembers
+	}
+	s.Command("hold")
+	return s.acknowledge(OrderHold, nil), nil
+}
+
+// Regroup recalls members to the leader's formation, dropping holds and
+// player follow targets.
+func (s *Squad) Regroup() (Acknowledgment, error) {
+	if s.living() == 0 {
+		return Acknowledgment{}, ErrNoMembers
+	}
+	s.Command("follow")
+	for _, m := range s.Members {
+		m.TargetPlayerID = 0
+	}
+	return s.acknowledge(OrderRegroup, nil), nil
+}
+
+// AttackTarget focuses the squad on the target at x, y.
+func (s *Squad) AttackTarget(x, y float64) (Acknowledgment, error) {
+	if s.living() == 0 {
+		return Acknowledgment{}, ErrNoMembers
+	}
+	s.SetTarget(x, y)
+	s.Command("attack")
+	return s.acknowledge(OrderAttackTarget, nil), nil
+}
+
+// UseAbility makes every living member whose ability is off cooldown use it.
+// Damaging abilities are aimed at x, y; heals are centred on the member.
+func (s *Squad) UseAbility(x, y float64) ([]AbilityUse, Acknowledgment, error) {
+	if s.living() == 0 {
+		return nil, Acknowledgment{}, ErrNoMembers
+	}
+	var uses []AbilityUse
+	for _, m := range s.Members {
+		if !m.AbilityReady() {
+			continue
+		}
+		ability := classAbilities[m.ClassID]
+		m.AbilityCooldown = ability.Cooldown
+		use := AbilityUse{MemberID: m.ID, Ability: ability, X: x, Y: y}
+		if ability.Heal {
+			use.X, use.Y = m.X, m.Y
+		}
+		uses = append(uses, use)
+	}
+	if len(uses) == 0 {
+		return nil, Acknowledgment{}, ErrAbilityNotReady
+	}
+	return uses, s.acknowledge(OrderUseAbility, s.member(uses[0].MemberID)), nil
+}
+
+// AbilityReady reports whether the member is alive and their class ability
+// is off cooldown.
+func (m *SquadMember) AbilityReady() bool {
+	_, ok := classAbilities[m.ClassID]
+	return ok && m.Health > 0 && m.AbilityCooldown == 0
+}
+
+// acknowledge picks the member who answers an order, taking turns among
+// living members unless speaker is given, and the line they bark.
+func (s *Squad) acknowledge(order Order, speaker *SquadMember) Acknowledgment {
+	if speaker == nil {
+		living := make([]*SquadMember, 0, len(s.Members))
+		for _, m := range s.Members {
+			if m.Health > 0 {
+				living = append(living, m)
+			}
+		}
+		speaker = living[s.barks%len(living)]
+	}
+	lines, ok := ackLines[s.CurrentGenre]
+	if !ok {
+		lines = ackLines["fantasy"]
+	}
+	pool := lines[order]
+	line := pool[s.barks%len(pool)]
+	s.barks++
+	return Acknowledgment{
+		MemberID: speaker.ID,
+		Voice:    speaker.ClassID,
+		Order:    order,
+		Line:     line,
+		X:        speaker.X,
+		Y:        speaker.Y,
+	}
+}
+
+// living counts members with health left.
+func (s *Squad) living() int {
+	n := 0
+	for _, m := range s.Members {
+		if m.Health > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// member returns the member with an ID, or nil.
+func (s *Squad) member(id string) *SquadMember {
+	for _, m := range s.Members {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
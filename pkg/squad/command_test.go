@@ -0,0 +1,148 @@
+package squad
+
+import (
+	"errors"
+	"testing"
+)
+
+// commandSquad returns a scifi squad with a grunt and a medic.
+func commandSquad() *Squad {
+	s := NewSquad(3)
+	s.CurrentGenre = "scifi"
+	s.AddMember("grunt_1", "grunt", "rifle", 5, 5, 1)
+	s.AddMember("medic_1", "medic", "pistol", 6, 5, 1)
+	return s
+}
+
+func TestOrders(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    Order
+		issue    func(s *Squad) (Acknowledgment, error)
+		behavior BehaviorState
+	}{
+		{"hold", OrderHold, (*Squad).HoldPosition, BehaviorHold},
+		{"regroup", OrderRegroup, (*Squad).Regroup, BehaviorFollow},
+		{"attack", OrderAttackTarget, func(s *Squad) (Acknowledgment, error) { return s.AttackTarget(9, 3) }, BehaviorAttack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := commandSquad()
+			s.Members[0].TargetPlayerID = 7
+			ack, err := tt.issue(s)
+			if err != nil {
+				t.Fatalf("order failed: %v", err)
+			}
+			if s.Behavior != tt.behavior {
+				t.Errorf("Behavior = %v, want %v", s.Behavior, tt.behavior)
+			}
+			if ack.Order != tt.order || ack.Line == "" || ack.MemberID != "grunt_1" || ack.Voice != "grunt" {
+				t.Errorf("ack = %+v, want the grunt acknowledging %v", ack, tt.order)
+			}
+		})
+	}
+
+	s := commandSquad()
+	s.Members[0].TargetPlayerID = 7
+	s.Regroup()
+	if s.Members[0].TargetPlayerID != 0 {
+		t.Error("Regroup kept the player follow target")
+	}
+	s.AttackTarget(9, 3)
+	if s.TargetX != 9 || s.TargetY != 3 {
+		t.Errorf("target = (%v, %v), want (9, 3)", s.TargetX, s.TargetY)
+	}
+}
+
+func TestOrders_NoMembers(t *testing.T) {
+	s := NewSquad(3)
+	if _, err := s.HoldPosition(); !errors.Is(err, ErrNoMembers) {
+		t.Errorf("HoldPosition on an empty squad: err = %v, want ErrNoMembers", err)
+	}
+
+	s = commandSquad()
+	for _, m := range s.Members {
+		m.Health = 0
+	}
+	if _, _, err := s.UseAbility(0, 0); !errors.Is(err, ErrNoMembers) {
+		t.Errorf("UseAbility with a dead squad: err = %v, want ErrNoMembers", err)
+	}
+}
+
+func TestAcknowledgmentRotates(t *testing.T) {
+	s := commandSquad()
+	first, _ := s.HoldPosition()
+	second, _ := s.HoldPosition()
+	if first.MemberID == second.MemberID || first.Line == second.Line {
+		t.Errorf("acks %+v and %+v repeat the speaker or line", first, second)
+	}
+
+	s.Members[0].Health = 0
+	for i := 0; i < 3; i++ {
+		if ack, _ := s.HoldPosition(); ack.MemberID != "medic_1" {
+			t.Errorf("ack from %s, want only the living medic", ack.MemberID)
+		}
+	}
+
+	s.CurrentGenre = "unknown"
+	if ack, _ := s.Regroup(); ack.Line == "" {
+		t.Error("unknown genre gave no bark")
+	}
+}
+
+func TestUseAbility(t *testing.T) {
+	s := commandSquad()
+	uses, ack, err := s.UseAbility(10, 2)
+	if err != nil {
+		t.Fatalf("UseAbility: %v", err)
+	}
+	if len(uses) != 2 {
+		t.Fatalf("uses = %+v, want both members", uses)
+	}
+	grunt, medic := uses[0], uses[1]
+	if grunt.Ability.Heal || grunt.X != 10 || grunt.Y != 2 {
+		t.Errorf("grunt use = %+v, want a blast at (10, 2)", grunt)
+	}
+	if !medic.Ability.Heal || medic.X != 6 || medic.Y != 5 {
+		t.Errorf("medic use = %+v, want a heal at the medic", medic)
+	}
+	if ack.Order != OrderUseAbility || ack.MemberID != "grunt_1" {
+		t.Errorf("ack = %+v, want the first user acknowledging", ack)
+	}
+
+	if _, _, err := s.UseAbility(10, 2); !errors.Is(err, ErrAbilityNotReady) {
+		t.Errorf("second UseAbility: err = %v, want ErrAbilityNotReady", err)
+	}
+
+	// The grunt's shorter cooldown runs out first.
+	gruntAbility, _ := AbilityFor("grunt")
+	for i := 0; i < gruntAbility.Cooldown; i++ {
+		s.Update(5, 5, nil, 5, 5, 1)
+	}
+	uses, _, err = s.UseAbility(10, 2)
+	if err != nil || len(uses) != 1 || uses[0].MemberID != "grunt_1" {
+		t.Errorf("uses after the grunt cooldown = %+v, %v; want only the grunt", uses, err)
+	}
+}
+
+func TestAbilityReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		classID  string
+		health   float64
+		cooldown int
+		want     bool
+	}{
+		{"ready", "demo", 50, 0, true},
+		{"cooling down", "demo", 50, 10, false},
+		{"dead", "mystic", 0, 0, false},
+		{"no ability", "civilian", 50, 0, false},
+	}
+	for _, tt := range tests {
+		m := &SquadMember{ClassID: tt.classID, Health: tt.health, AbilityCooldown: tt.cooldown}
+		if got := m.AbilityReady(); got != tt.want {
+			t.Errorf("%s: AbilityReady = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -47,6 +47,7 @@ type SquadMember struct {
 	HoldX, HoldY                       float64
 	FormationOffsetX, FormationOffsetY float64
 	TargetPlayerID                     uint64 // Human player target for follow/attack
+	AbilityCooldown                    int    // Ticks until the class ability is ready
 }
 
 // HumanPlayer represents a human player in co-op mode.
@@ -71,6 +72,7 @@ type Squad struct {
 	MaxMembers   int
 	CurrentGenre string
 	HumanPlayers []*HumanPlayer // Connected co-op players
+	barks        int            // Acknowledgments given, rotating speakers and lines
 }
 
 // NewSquad creates a squad with default settings.
@@ -210,6 +212,9 @@ func (s *Squad) Update(leaderX, leaderY float64, tileMap [][]int, playerX, playe
 
 		// Sync member health with agent
 		member.Health = member.Agent.Health
+		if member.AbilityCooldown > 0 {
+			member.AbilityCooldown--
+		}
 	}
 }
 
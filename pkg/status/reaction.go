@@ -0,0 +1,108 @@
+package status
+
+import (
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+// Reaction is an elemental combo. When a hit or effect of the trigger
+// damage type lands on an entity carrying the primer effect, the primer is
+// consumed and the reaction goes off.
+type Reaction struct {
+	Name       string
+	Primer     string  // Effect on the entity that the trigger reacts with
+	Trigger    string  // Combat damage type of the hit or effect that sets it off
+	Apply      string  // Effect the reaction inflicts; "" for none
+	Damage     float64 // Burst damage the reaction deals
+	DamageType string  // Combat damage type of the burst
+	Radius     float64 // Tiles the reaction reaches around the entity; 0 for the entity alone
+	Chain      bool    // Reaches only nearby entities carrying the primer
+	Sound      string  // SFX played where it goes off
+	Color      uint32  // RGBA color of its particles
+}
+
+// ReactionFunc receives a reaction that went off on an entity, to deal its
+// damage, spread it and play its particles and sound.
+type ReactionFunc func(w *engine.World, entity engine.Entity, reaction Reaction)
+
+// genreReactions are the elemental combos per genre, primed by effects
+// from that genre's effect table.
+var genreReactions = map[string][]Reaction{
+	"fantasy": {
+		{Name: "Conduction", Primer: "wet", Trigger: "energy", Apply: "stunned", Radius: 3, Chain: true, Sound: "chain_stun", Color: 0x88CCFFFF},
+		{Name: "Wildfire", Primer: "oiled", Trigger: "fire", Damage: 35, DamageType: "fire", Radius: 2.5, Sound: "explosion", Color: 0xFF8800FF},
+		{Name: "Shatter", Primer: "frozen", Trigger: "physical", Damage: 40, DamageType: "physical", Sound: "shatter", Color: 0xCCEEFFFF},
+	},
+	"scifi": {
+		{Name: "Short Circuit", Primer: "wet", Trigger: "energy", Apply: "emp_stunned", Radius: 3.5, Chain: true, Sound: "chain_stun", Color: 0x00FFFFFF},
+		{Name: "Fuel Detonation", Primer: "fuel_soaked", Trigger: "fire", Damage: 45, DamageType: "explosive", Radius: 3, Sound: "explosion", Color: 0xFF4400FF},
+		{Name: "Cryo Fracture", Primer: "frozen", Trigger: "physical", Damage: 40, DamageType: "physical", Sound: "shatter", Color: 0xAADDFFFF},
+	},
+	"horror": {
+		{Name: "Galvanic Seizure", Primer: "wet", Trigger: "energy", Apply: "stunned", Radius: 3, Chain: true, Sound: "chain_stun", Color: 0xCCCCFFFF},
+		{Name: "Pyre", Primer: "oiled", Trigger: "fire", Apply: "terrified", Damage: 30, DamageType: "fire", Radius: 2.5, Sound: "explosion", Color: 0xFF6622FF},
+		{Name: "Shatter", Primer: "frozen", Trigger: "physical", Damage: 35, DamageType: "physical", Sound: "shatter", Color: 0xDDDDEEFF},
+	},
+	"cyberpunk": {
+		{Name: "Short Circuit", Primer: "wet", Trigger: "energy", Apply: "emp_stunned", Radius: 3.5, Chain: true, Sound: "chain_stun", Color: 0xFF00FFFF},
+		{Name: "Fuel Detonation", Primer: "fuel_soaked", Trigger: "fire", Damage: 45, DamageType: "explosive", Radius: 3, Sound: "explosion", Color: 0xFF0088FF},
+		{Name: "Cryo Fracture", Primer: "frozen", Trigger: "physical", Damage: 40, DamageType: "physical", Sound: "shatter", Color: 0x88FFFFFF},
+	},
+	"postapoc": {
+		{Name: "Live Wire", Primer: "wet", Trigger: "energy", Apply: "stunned", Radius: 3, Chain: true, Sound: "chain_stun", Color: 0xAACCFFFF},
+		{Name: "Fuel Blast", Primer: "oiled", Trigger: "fire", Damage: 40, DamageType: "explosive", Radius: 3, Sound: "explosion", Color: 0xFF6600FF},
+		{Name: "Shatter", Primer: "frozen", Trigger: "physical", Damage: 35, DamageType: "physical", Sound: "shatter", Color: 0xCCDDEEFF},
+	},
+}
+
+// Reactions returns the elemental combos of the registry's genre.
+func (r *Registry) Reactions() []Reaction {
+	return r.reactions
+}
+
+// Strike sets off any reactions a hit of the damage type triggers on the
+// entity, as ApplyToEntity does for effects, and returns them.
+func (r *Registry) Strike(w *engine.World, entity engine.Entity, damageType string) []Reaction {
+	if damageType == "" {
+		return nil
+	}
+	var fired []Reaction
+	for _, re := range r.reactions {
+		if re.Trigger == damageType && Stacks(w, entity, re.Primer) > 0 {
+			r.resolve(w, entity, re)
+			fired = append(fired, re)
+		}
+	}
+	for _, re := range fired {
+		if r.OnReaction != nil {
+			r.OnReaction(w, entity, re)
+		}
+	}
+	return fired
+}
+
+// Spread carries a reaction that went off nearby to the entity. A chain
+// reaction only reaches entities carrying its primer, which it consumes.
+// It reports whether the entity was caught.
+func (r *Registry) Spread(w *engine.World, entity engine.Entity, re Reaction) bool {
+	if re.Chain {
+		if Stacks(w, entity, re.Primer) == 0 {
+			return false
+		}
+		r.Remove(w, entity, re.Primer)
+	}
+	if re.Apply != "" {
+		r.apply(w, entity, re.Apply, false)
+	}
+	return true
+}
+
+// resolve consumes the reaction's primer on the entity and inflicts its
+// effect. The inflicted effect sets off no further reactions, so combos
+// cannot loop.
+func (r *Registry) resolve(w *engine.World, entity engine.Entity, re Reaction) {
+	r.Remove(w, entity, re.Primer)
+	if re.Apply != "" {
+		r.apply(w, entity, re.Apply, false)
+	}
+	r.logger.Debugf("%s went off on entity %d", re.Name, entity)
+}
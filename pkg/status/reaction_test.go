@@ -0,0 +1,93 @@
+package status
+
+import (
+	"testing"
+
+	"github.com/opd-ai/violence/pkg/engine"
+)
+
+func TestReactionOnEffect(t *testing.T) {
+	r := NewRegistry()
+	w := engine.NewWorld()
+	entity := w.AddEntity()
+
+	var fired []string
+	r.OnReaction = func(_ *engine.World, e engine.Entity, re Reaction) {
+		if e != entity {
+			t.Errorf("%s went off on %d", re.Name, e)
+		}
+		fired = append(fired, re.Name)
+	}
+
+	// Shock on a wet entity stuns it and uses up the water.
+	r.ApplyToEntity(w, entity, "wet")
+	r.ApplyToEntity(w, entity, "shocked")
+	if len(fired) != 1 || fired[0] != "Conduction" {
+		t.Fatalf("fired %v, want Conduction", fired)
+	}
+	if Stacks(w, entity, "wet") != 0 || !IsStunned(w, entity) {
+		t.Error("Conduction left the entity wet or unstunned")
+	}
+
+	// With the primer gone, further shocks set off nothing.
+	r.ApplyToEntity(w, entity, "shocked")
+	if len(fired) != 1 {
+		t.Errorf("fired %v after the primer was consumed", fired)
+	}
+}
+
+func TestReactionOnStrike(t *testing.T) {
+	r := NewRegistry()
+	w := engine.NewWorld()
+	entity := w.AddEntity()
+
+	r.ApplyToEntity(w, entity, "frozen")
+	if got := r.Strike(w, entity, "fire"); len(got) != 0 {
+		t.Errorf("fire on a frozen entity set off %v", got)
+	}
+	got := r.Strike(w, entity, "physical")
+	if len(got) != 1 || got[0].Name != "Shatter" || got[0].Damage <= 0 {
+		t.Fatalf("physical hit on a frozen entity set off %+v, want a damaging Shatter", got)
+	}
+	if Stacks(w, entity, "frozen") != 0 {
+		t.Error("Shatter left the entity frozen")
+	}
+}
+
+func TestReactionSpread(t *testing.T) {
+	r := NewRegistry()
+	w := engine.NewWorld()
+	wet, dry := w.AddEntity(), w.AddEntity()
+	r.ApplyToEntity(w, wet, "wet")
+
+	var conduction Reaction
+	for _, re := range r.Reactions() {
+		if re.Name == "Conduction" {
+			conduction = re
+		}
+	}
+	if !r.Spread(w, wet, conduction) || !IsStunned(w, wet) {
+		t.Error("Conduction did not chain to a wet entity")
+	}
+	if r.Spread(w, dry, conduction) || IsStunned(w, dry) {
+		t.Error("Conduction chained to a dry entity")
+	}
+}
+
+func TestGenreReactions(t *testing.T) {
+	for genre, reactions := range genreReactions {
+		r := NewRegistry()
+		r.SetGenre(genre)
+		if len(r.Reactions()) != len(reactions) {
+			t.Errorf("%s registry has %d reactions, want %d", genre, len(r.Reactions()), len(reactions))
+		}
+		for _, re := range reactions {
+			if _, ok := r.Lookup(re.Primer); !ok {
+				t.Errorf("%s %s is primed by unknown effect %q", genre, re.Name, re.Primer)
+			}
+			if _, ok := r.Lookup(re.Apply); re.Apply != "" && !ok {
+				t.Errorf("%s %s inflicts unknown effect %q", genre, re.Name, re.Apply)
+			}
+		}
+	}
+}
@@ -21,6 +21,25 @@ const (
 	EffectDebuff                   // Stat decrease
 )
 
+// Harmful reports whether effects of the type hinder their target, and so
+// are removed by a cleanse rather than a dispel.
+func (t EffectType) Harmful() bool {
+	return t != EffectHeal && t != EffectBuff
+}
+
+// RefreshPolicy decides what reapplying an effect does to its duration.
+type RefreshPolicy int
+
+const (
+	RefreshReset  RefreshPolicy = iota // Restart the full duration
+	RefreshExtend                      // Add the duration to what remains, up to twice the duration
+	RefreshKeep                        // Leave the remaining duration alone
+)
+
+// defaultMaxStacks is how many instances a stackable effect with no
+// MaxStacks can build up to.
+const defaultMaxStacks = 5
+
 // Effect represents a status effect template.
 type Effect struct {
 	Name          string
@@ -31,11 +50,19 @@ type Effect struct {
 	SpeedMul      float64 // Movement speed multiplier (1.0 = normal, 0.5 = half speed)
 	Stackable     bool    // Can multiple instances exist on same entity
 	VisualColor   uint32  // RGBA color for visual effects
+
+	// Stacking, refresh and resistance rules; the zero values reset the
+	// duration on reapplication and are resisted by nothing.
+	MaxStacks  int           // Instances a stackable effect builds up to; 0 is defaultMaxStacks
+	Refresh    RefreshPolicy // What reapplying the effect does to its duration
+	DamageType string        // Combat damage type its ticks deal; resistance to it also shortens the effect
 }
 
 // ActiveEffect represents an effect instance on an entity.
 type ActiveEffect struct {
 	EffectName    string
+	Type          EffectType
+	DamageType    string
 	TimeRemaining time.Duration
 	LastTick      time.Time
 	TickInterval  time.Duration
@@ -47,12 +74,28 @@ type ActiveEffect struct {
 // StatusComponent is an ECS component tracking active effects on an entity.
 type StatusComponent struct {
 	ActiveEffects []ActiveEffect
+	// Resistances shorten effects dealing the resisted damage types and
+	// soften their ticks; an entity fully resisting a type is immune.
+	Resistances Resistances
+}
+
+// Resistances maps combat damage types to the fraction of them resisted.
+type Resistances map[string]float64
+
+// Resist returns amount less the resistance to its damage type.
+func (r Resistances) Resist(amount float64, damageType string) float64 {
+	return amount * (1 - r[damageType])
 }
 
 // Registry holds all known status effect templates.
 type Registry struct {
-	effects map[string]Effect
-	logger  *logrus.Entry
+	effects   map[string]Effect
+	reactions []Reaction
+	logger    *logrus.Entry
+
+	// OnReaction, when set, receives the elemental combos effects and
+	// strikes set off.
+	OnReaction ReactionFunc
 }
 
 // NewRegistry creates a new status effect registry.
@@ -72,57 +115,93 @@ func (r *Registry) loadDefaultEffects(genreID string) {
 	switch genreID {
 	case "fantasy":
 		r.effects = map[string]Effect{
-			"poisoned":     {Name: "poisoned", Type: EffectDamage, Duration: 10 * time.Second, DamagePerTick: 2.0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x88FF0088},
-			"burning":      {Name: "burning", Type: EffectDamage, Duration: 5 * time.Second, DamagePerTick: 5.0, TickInterval: 500 * time.Millisecond, SpeedMul: 1.0, Stackable: false, VisualColor: 0xFF880088},
-			"bleeding":     {Name: "bleeding", Type: EffectDamage, Duration: 15 * time.Second, DamagePerTick: 1.0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: true, VisualColor: 0xAA000088},
-			"stunned":      {Name: "stunned", Type: EffectStun, Duration: 2 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0xFFFF0088},
+			"poisoned":     {Name: "poisoned", Type: EffectDamage, Duration: 10 * time.Second, DamagePerTick: 2.0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x88FF0088, DamageType: "toxic", Refresh: RefreshExtend},
+			"burning":      {Name: "burning", Type: EffectDamage, Duration: 5 * time.Second, DamagePerTick: 5.0, TickInterval: 500 * time.Millisecond, SpeedMul: 1.0, Stackable: false, VisualColor: 0xFF880088, DamageType: "fire"},
+			"bleeding":     {Name: "bleeding", Type: EffectDamage, Duration: 15 * time.Second, DamagePerTick: 1.0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: true, VisualColor: 0xAA000088, DamageType: "physical"},
+			"stunned":      {Name: "stunned", Type: EffectStun, Duration: 2 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0xFFFF0088, Refresh: RefreshKeep},
 			"regeneration": {Name: "regeneration", Type: EffectHeal, Duration: 10 * time.Second, DamagePerTick: -2.0, TickInterval: time.Second, SpeedMul: 1.0, Stackable: false, VisualColor: 0x00FF0088},
 			"blessed":      {Name: "blessed", Type: EffectBuff, Duration: 30 * time.Second, DamagePerTick: -1.0, TickInterval: 2 * time.Second, SpeedMul: 1.1, Stackable: false, VisualColor: 0xFFFFAA88},
-			"cursed":       {Name: "cursed", Type: EffectDebuff, Duration: 20 * time.Second, DamagePerTick: 0.5, TickInterval: 2 * time.Second, SpeedMul: 0.85, Stackable: false, VisualColor: 0x660066AA},
-			"slowed":       {Name: "slowed", Type: EffectSlow, Duration: 5 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.5, Stackable: false, VisualColor: 0x4444FFAA},
+			"cursed":       {Name: "cursed", Type: EffectDebuff, Duration: 20 * time.Second, DamagePerTick: 0.5, TickInterval: 2 * time.Second, SpeedMul: 0.85, Stackable: false, VisualColor: 0x660066AA, DamageType: "arcane"},
+			"slowed":       {Name: "slowed", Type: EffectSlow, Duration: 5 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.5, Stackable: false, VisualColor: 0x4444FFAA, Refresh: RefreshExtend},
+			"wet":          {Name: "wet", Type: EffectDebuff, Duration: 8 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: false, VisualColor: 0x3366FF88},
+			"oiled":        {Name: "oiled", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x44330088},
+			"frozen":       {Name: "frozen", Type: EffectSlow, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.2, Stackable: false, VisualColor: 0xAADDFFAA, Refresh: RefreshKeep},
+			"shocked":      {Name: "shocked", Type: EffectDamage, Duration: 2 * time.Second, DamagePerTick: 3.0, TickInterval: 500 * time.Millisecond, SpeedMul: 0.8, Stackable: false, VisualColor: 0xCCCCFF88, DamageType: "energy"},
 		}
 	case "scifi":
 		r.effects = map[string]Effect{
-			"irradiated":  {Name: "irradiated", Type: EffectDamage, Duration: 15 * time.Second, DamagePerTick: 1.5, TickInterval: time.Second, SpeedMul: 0.9, Stackable: true, VisualColor: 0x00FF0088},
-			"burning":     {Name: "burning", Type: EffectDamage, Duration: 4 * time.Second, DamagePerTick: 6.0, TickInterval: 500 * time.Millisecond, SpeedMul: 1.0, Stackable: false, VisualColor: 0xFF440088},
-			"emp_stunned": {Name: "emp_stunned", Type: EffectStun, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0x00FFFF88},
+			"irradiated":  {Name: "irradiated", Type: EffectDamage, Duration: 15 * time.Second, DamagePerTick: 1.5, TickInterval: time.Second, SpeedMul: 0.9, Stackable: true, VisualColor: 0x00FF0088, DamageType: "toxic", MaxStacks: 10},
+			"burning":     {Name: "burning", Type: EffectDamage, Duration: 4 * time.Second, DamagePerTick: 6.0, TickInterval: 500 * time.Millisecond, SpeedMul: 1.0, Stackable: false, VisualColor: 0xFF440088, DamageType: "fire"},
+			"emp_stunned": {Name: "emp_stunned", Type: EffectStun, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0x00FFFF88, DamageType: "energy", Refresh: RefreshKeep},
 			"nanoheal":    {Name: "nanoheal", Type: EffectHeal, Duration: 8 * time.Second, DamagePerTick: -3.0, TickInterval: time.Second, SpeedMul: 1.0, Stackable: false, VisualColor: 0x00AAFF88},
 			"overcharged": {Name: "overcharged", Type: EffectBuff, Duration: 15 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 1.2, Stackable: false, VisualColor: 0xFFAA0088},
-			"corroded":    {Name: "corroded", Type: EffectDebuff, Duration: 12 * time.Second, DamagePerTick: 1.0, TickInterval: 2 * time.Second, SpeedMul: 0.9, Stackable: true, VisualColor: 0x88880088},
-			"slowed":      {Name: "slowed", Type: EffectSlow, Duration: 6 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.6, Stackable: false, VisualColor: 0x0088FF88},
+			"corroded":    {Name: "corroded", Type: EffectDebuff, Duration: 12 * time.Second, DamagePerTick: 1.0, TickInterval: 2 * time.Second, SpeedMul: 0.9, Stackable: true, VisualColor: 0x88880088, DamageType: "toxic", MaxStacks: 3},
+			"slowed":      {Name: "slowed", Type: EffectSlow, Duration: 6 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.6, Stackable: false, VisualColor: 0x0088FF88, Refresh: RefreshExtend},
+			"wet":         {Name: "wet", Type: EffectDebuff, Duration: 8 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: false, VisualColor: 0x2288FF88},
+			"fuel_soaked": {Name: "fuel_soaked", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x66440088},
+			"frozen":      {Name: "frozen", Type: EffectSlow, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.2, Stackable: false, VisualColor: 0x88DDFFAA, Refresh: RefreshKeep},
+			"bleeding":    {Name: "bleeding", Type: EffectDamage, Duration: 12 * time.Second, DamagePerTick: 1.0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: true, VisualColor: 0xCC000088, DamageType: "physical"},
+			"shocked":     {Name: "shocked", Type: EffectDamage, Duration: 2 * time.Second, DamagePerTick: 3.5, TickInterval: 500 * time.Millisecond, SpeedMul: 0.8, Stackable: false, VisualColor: 0x66FFFF88, DamageType: "energy"},
 		}
 	case "horror":
 		r.effects = map[string]Effect{
-			"poisoned":     {Name: "poisoned", Type: EffectDamage, Duration: 12 * time.Second, DamagePerTick: 2.5, TickInterval: time.Second, SpeedMul: 0.85, Stackable: false, VisualColor: 0x44AA4488},
-			"bleeding":     {Name: "bleeding", Type: EffectDamage, Duration: 20 * time.Second, DamagePerTick: 1.5, TickInterval: time.Second, SpeedMul: 0.9, Stackable: true, VisualColor: 0x880000AA},
+			"poisoned":     {Name: "poisoned", Type: EffectDamage, Duration: 12 * time.Second, DamagePerTick: 2.5, TickInterval: time.Second, SpeedMul: 0.85, Stackable: false, VisualColor: 0x44AA4488, DamageType: "toxic", Refresh: RefreshExtend},
+			"bleeding":     {Name: "bleeding", Type: EffectDamage, Duration: 20 * time.Second, DamagePerTick: 1.5, TickInterval: time.Second, SpeedMul: 0.9, Stackable: true, VisualColor: 0x880000AA, DamageType: "physical"},
 			"terrified":    {Name: "terrified", Type: EffectDebuff, Duration: 8 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.7, Stackable: false, VisualColor: 0xAA00AAAA},
-			"infected":     {Name: "infected", Type: EffectDamage, Duration: 25 * time.Second, DamagePerTick: 0.8, TickInterval: 2 * time.Second, SpeedMul: 0.95, Stackable: true, VisualColor: 0x00882288},
-			"stunned":      {Name: "stunned", Type: EffectStun, Duration: 2500 * time.Millisecond, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0xFFFFFF88},
+			"infected":     {Name: "infected", Type: EffectDamage, Duration: 25 * time.Second, DamagePerTick: 0.8, TickInterval: 2 * time.Second, SpeedMul: 0.95, Stackable: true, VisualColor: 0x00882288, DamageType: "toxic", MaxStacks: 3},
+			"stunned":      {Name: "stunned", Type: EffectStun, Duration: 2500 * time.Millisecond, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0xFFFFFF88, Refresh: RefreshKeep},
 			"regeneration": {Name: "regeneration", Type: EffectHeal, Duration: 10 * time.Second, DamagePerTick: -1.5, TickInterval: time.Second, SpeedMul: 1.0, Stackable: false, VisualColor: 0x44FF4488},
+			"wet":          {Name: "wet", Type: EffectDebuff, Duration: 8 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: false, VisualColor: 0x33445588},
+			"oiled":        {Name: "oiled", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x22110088},
+			"frozen":       {Name: "frozen", Type: EffectSlow, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.2, Stackable: false, VisualColor: 0xBBCCDDAA, Refresh: RefreshKeep},
+			"shocked":      {Name: "shocked", Type: EffectDamage, Duration: 2 * time.Second, DamagePerTick: 3.0, TickInterval: 500 * time.Millisecond, SpeedMul: 0.8, Stackable: false, VisualColor: 0xDDDDFF88, DamageType: "energy"},
+			"burning":      {Name: "burning", Type: EffectDamage, Duration: 5 * time.Second, DamagePerTick: 4.0, TickInterval: 500 * time.Millisecond, SpeedMul: 1.0, Stackable: false, VisualColor: 0xCC440088, DamageType: "fire"},
 		}
 	case "cyberpunk":
 		r.effects = map[string]Effect{
-			"burning":      {Name: "burning", Type: EffectDamage, Duration: 4 * time.Second, DamagePerTick: 7.0, TickInterval: 500 * time.Millisecond, SpeedMul: 1.0, Stackable: false, VisualColor: 0xFF00FF88},
-			"hacked":       {Name: "hacked", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 0.5, TickInterval: time.Second, SpeedMul: 0.8, Stackable: false, VisualColor: 0x00FF8888},
-			"emp_stunned":  {Name: "emp_stunned", Type: EffectStun, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0x00FFFFAA},
+			"burning":      {Name: "burning", Type: EffectDamage, Duration: 4 * time.Second, DamagePerTick: 7.0, TickInterval: 500 * time.Millisecond, SpeedMul: 1.0, Stackable: false, VisualColor: 0xFF00FF88, DamageType: "fire"},
+			"hacked":       {Name: "hacked", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 0.5, TickInterval: time.Second, SpeedMul: 0.8, Stackable: false, VisualColor: 0x00FF8888, DamageType: "energy"},
+			"emp_stunned":  {Name: "emp_stunned", Type: EffectStun, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0x00FFFFAA, DamageType: "energy", Refresh: RefreshKeep},
 			"stim_boosted": {Name: "stim_boosted", Type: EffectBuff, Duration: 12 * time.Second, DamagePerTick: -1.0, TickInterval: 2 * time.Second, SpeedMul: 1.3, Stackable: false, VisualColor: 0xFF0088AA},
 			"nanoheal":     {Name: "nanoheal", Type: EffectHeal, Duration: 6 * time.Second, DamagePerTick: -4.0, TickInterval: time.Second, SpeedMul: 1.0, Stackable: false, VisualColor: 0x00DDFF88},
-			"glitched":     {Name: "glitched", Type: EffectDebuff, Duration: 5 * time.Second, DamagePerTick: 1.0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0xFF88FF88},
-			"slowed":       {Name: "slowed", Type: EffectSlow, Duration: 5 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.5, Stackable: false, VisualColor: 0x8800FF88},
+			"glitched":     {Name: "glitched", Type: EffectDebuff, Duration: 5 * time.Second, DamagePerTick: 1.0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0xFF88FF88, DamageType: "energy", Refresh: RefreshExtend},
+			"slowed":       {Name: "slowed", Type: EffectSlow, Duration: 5 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.5, Stackable: false, VisualColor: 0x8800FF88, Refresh: RefreshExtend},
+			"wet":          {Name: "wet", Type: EffectDebuff, Duration: 8 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: false, VisualColor: 0x0066FF88},
+			"fuel_soaked":  {Name: "fuel_soaked", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x88440088},
+			"frozen":       {Name: "frozen", Type: EffectSlow, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.2, Stackable: false, VisualColor: 0x88FFFFAA, Refresh: RefreshKeep},
+			"bleeding":     {Name: "bleeding", Type: EffectDamage, Duration: 12 * time.Second, DamagePerTick: 1.0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: true, VisualColor: 0xFF004488, DamageType: "physical"},
+			"shocked":      {Name: "shocked", Type: EffectDamage, Duration: 2 * time.Second, DamagePerTick: 3.5, TickInterval: 500 * time.Millisecond, SpeedMul: 0.8, Stackable: false, VisualColor: 0x00FFFF88, DamageType: "energy"},
 		}
 	case "postapoc":
 		r.effects = map[string]Effect{
-			"irradiated": {Name: "irradiated", Type: EffectDamage, Duration: 20 * time.Second, DamagePerTick: 1.2, TickInterval: time.Second, SpeedMul: 0.9, Stackable: true, VisualColor: 0x88FF0088},
-			"poisoned":   {Name: "poisoned", Type: EffectDamage, Duration: 15 * time.Second, DamagePerTick: 2.0, TickInterval: time.Second, SpeedMul: 0.85, Stackable: false, VisualColor: 0x668800AA},
-			"bleeding":   {Name: "bleeding", Type: EffectDamage, Duration: 18 * time.Second, DamagePerTick: 1.3, TickInterval: time.Second, SpeedMul: 0.92, Stackable: true, VisualColor: 0xAA000088},
-			"stunned":    {Name: "stunned", Type: EffectStun, Duration: 2 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0xCCCCCC88},
+			"irradiated": {Name: "irradiated", Type: EffectDamage, Duration: 20 * time.Second, DamagePerTick: 1.2, TickInterval: time.Second, SpeedMul: 0.9, Stackable: true, VisualColor: 0x88FF0088, DamageType: "toxic", MaxStacks: 10},
+			"poisoned":   {Name: "poisoned", Type: EffectDamage, Duration: 15 * time.Second, DamagePerTick: 2.0, TickInterval: time.Second, SpeedMul: 0.85, Stackable: false, VisualColor: 0x668800AA, DamageType: "toxic", Refresh: RefreshExtend},
+			"bleeding":   {Name: "bleeding", Type: EffectDamage, Duration: 18 * time.Second, DamagePerTick: 1.3, TickInterval: time.Second, SpeedMul: 0.92, Stackable: true, VisualColor: 0xAA000088, DamageType: "physical"},
+			"stunned":    {Name: "stunned", Type: EffectStun, Duration: 2 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.0, Stackable: false, VisualColor: 0xCCCCCC88, Refresh: RefreshKeep},
 			"stimmed":    {Name: "stimmed", Type: EffectBuff, Duration: 15 * time.Second, DamagePerTick: -0.5, TickInterval: 2 * time.Second, SpeedMul: 1.15, Stackable: false, VisualColor: 0xFFAA4488},
-			"infected":   {Name: "infected", Type: EffectDamage, Duration: 30 * time.Second, DamagePerTick: 0.7, TickInterval: 2 * time.Second, SpeedMul: 0.95, Stackable: true, VisualColor: 0x448800AA},
-			"corroded":   {Name: "corroded", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 1.5, TickInterval: 2 * time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x886600AA},
+			"infected":   {Name: "infected", Type: EffectDamage, Duration: 30 * time.Second, DamagePerTick: 0.7, TickInterval: 2 * time.Second, SpeedMul: 0.95, Stackable: true, VisualColor: 0x448800AA, DamageType: "toxic", MaxStacks: 3},
+			"corroded":   {Name: "corroded", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 1.5, TickInterval: 2 * time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x886600AA, DamageType: "toxic"},
+			"wet":        {Name: "wet", Type: EffectDebuff, Duration: 8 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.95, Stackable: false, VisualColor: 0x44668888},
+			"oiled":      {Name: "oiled", Type: EffectDebuff, Duration: 10 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.9, Stackable: false, VisualColor: 0x55330088},
+			"frozen":     {Name: "frozen", Type: EffectSlow, Duration: 3 * time.Second, DamagePerTick: 0, TickInterval: time.Second, SpeedMul: 0.2, Stackable: false, VisualColor: 0xCCDDEEAA, Refresh: RefreshKeep},
+			"shocked":    {Name: "shocked", Type: EffectDamage, Duration: 2 * time.Second, DamagePerTick: 3.0, TickInterval: 500 * time.Millisecond, SpeedMul: 0.8, Stackable: false, VisualColor: 0xBBCCFF88, DamageType: "energy"},
 		}
 	default:
 		r.loadDefaultEffects("fantasy")
+		return
 	}
+	r.reactions = genreReactions[genreID]
+}
+
+// SetGenre swaps the registry's effect templates for the genre's.
+func (r *Registry) SetGenre(genreID string) {
+	r.loadDefaultEffects(genreID)
+}
+
+// Lookup returns the template of the named effect.
+func (r *Registry) Lookup(name string) (Effect, bool) {
+	e, ok := r.effects[name]
+	return e, ok
 }
 
 // Apply adds a status effect to an entity.
@@ -131,53 +210,161 @@ func (r *Registry) loadDefaultEffects(genreID string) {
 func (r *Registry) Apply(name string) {
 }
 
-// ApplyToEntity applies a status effect to an entity in the ECS world.
+// ApplyToEntity applies a status effect to an entity in the ECS world. An
+// effect dealing a damage type sets off the reactions it triggers on the
+// entity.
 func (r *Registry) ApplyToEntity(w *engine.World, entity engine.Entity, effectName string) {
+	r.apply(w, entity, effectName, true)
+}
+
+// apply applies a status effect to an entity, setting off reactions when
+// react is true.
+func (r *Registry) apply(w *engine.World, entity engine.Entity, effectName string, react bool) {
 	template, exists := r.effects[effectName]
 	if !exists {
 		r.logger.Warnf("Unknown status effect: %s", effectName)
 		return
 	}
 
-	statusType := reflect.TypeOf(&StatusComponent{})
-	comp, ok := w.GetComponent(entity, statusType)
+	statusComp := statusComponent(w, entity)
 
-	var statusComp *StatusComponent
-	if !ok {
-		// Create new status component
-		statusComp = &StatusComponent{
-			ActiveEffects: []ActiveEffect{},
+	// Resistance to the effect's damage type shortens it, down to immunity.
+	duration := template.Duration
+	if template.DamageType != "" {
+		duration = time.Duration(statusComp.Resistances.Resist(float64(duration), template.DamageType))
+		if duration <= 0 {
+			r.logger.Debugf("Entity %d is immune to %s", entity, effectName)
+			return
 		}
-		w.AddComponent(entity, statusComp)
-	} else {
-		statusComp = comp.(*StatusComponent)
 	}
 
-	// Check if effect already exists and is not stackable
-	if !template.Stackable {
-		for i, active := range statusComp.ActiveEffects {
-			if active.EffectName == effectName {
-				// Refresh duration
-				statusComp.ActiveEffects[i].TimeRemaining = template.Duration
-				r.logger.Debugf("Refreshed %s on entity %d", effectName, entity)
-				return
-			}
+	// A non-stackable effect, or a stackable one at its most stacks,
+	// refreshes the instance closest to running out.
+	maxStacks := 1
+	if template.Stackable {
+		maxStacks = template.MaxStacks
+		if maxStacks <= 0 {
+			maxStacks = defaultMaxStacks
 		}
 	}
+	stacks, oldest := 0, -1
+	for i, active := range statusComp.ActiveEffects {
+		if active.EffectName != effectName {
+			continue
+		}
+		stacks++
+		if oldest < 0 || active.TimeRemaining < statusComp.ActiveEffects[oldest].TimeRemaining {
+			oldest = i
+		}
+	}
+	if stacks >= maxStacks {
+		refresh(&statusComp.ActiveEffects[oldest], template.Refresh, duration)
+		r.logger.Debugf("Refreshed %s on entity %d", effectName, entity)
+	} else {
+		statusComp.ActiveEffects = append(statusComp.ActiveEffects, ActiveEffect{
+			EffectName:    effectName,
+			Type:          template.Type,
+			DamageType:    template.DamageType,
+			TimeRemaining: duration,
+			LastTick:      time.Now(),
+			TickInterval:  template.TickInterval,
+			DamagePerTick: template.DamagePerTick,
+			SpeedMul:      template.SpeedMul,
+			VisualColor:   template.VisualColor,
+		})
+		r.logger.Debugf("Applied %s to entity %d", effectName, entity)
+	}
 
-	// Add new effect instance
-	newEffect := ActiveEffect{
-		EffectName:    effectName,
-		TimeRemaining: template.Duration,
-		LastTick:      time.Now(),
-		TickInterval:  template.TickInterval,
-		DamagePerTick: template.DamagePerTick,
-		SpeedMul:      template.SpeedMul,
-		VisualColor:   template.VisualColor,
+	if react {
+		r.Strike(w, entity, template.DamageType)
 	}
+}
 
-	statusComp.ActiveEffects = append(statusComp.ActiveEffects, newEffect)
-	r.logger.Debugf("Applied %s to entity %d", effectName, entity)
+// statusComponent returns the entity's status component, adding an empty
+// one if it has none.
+func statusComponent(w *engine.World, entity engine.Entity) *StatusComponent {
+	if comp, ok := w.GetComponent(entity, reflect.TypeOf(&StatusComponent{})); ok {
+		return comp.(*StatusComponent)
+	}
+	statusComp := &StatusComponent{ActiveEffects: []ActiveEffect{}}
+	w.AddComponent(entity, statusComp)
+	return statusComp
+}
+
+// refresh applies a refresh policy to a reapplied effect instance.
+func refresh(active *ActiveEffect, policy RefreshPolicy, duration time.Duration) {
+	switch policy {
+	case RefreshReset:
+		active.TimeRemaining = duration
+	case RefreshExtend:
+		active.TimeRemaining = min(active.TimeRemaining+duration, 2*duration)
+	}
+}
+
+// SetResistances sets the resistances that shorten and soften effects on
+// the entity.
+func SetResistances(w *engine.World, entity engine.Entity, res Resistances) {
+	statusComponent(w, entity).Resistances = res
+}
+
+// Stacks returns how many instances of the named effect the entity has.
+func Stacks(w *engine.World, entity engine.Entity, effectName string) int {
+	comp, ok := w.GetComponent(entity, reflect.TypeOf(&StatusComponent{}))
+	if !ok {
+		return 0
+	}
+	stacks := 0
+	for _, active := range comp.(*StatusComponent).ActiveEffects {
+		if active.EffectName == effectName {
+			stacks++
+		}
+	}
+	return stacks
+}
+
+// Remove strips every instance of the named effect from the entity and
+// returns how many it removed.
+func (r *Registry) Remove(w *engine.World, entity engine.Entity, effectName string) int {
+	return r.strip(w, entity, func(active ActiveEffect) bool {
+		return active.EffectName == effectName
+	})
+}
+
+// Cleanse strips every harmful effect from the entity and returns how
+// many it removed.
+func (r *Registry) Cleanse(w *engine.World, entity engine.Entity) int {
+	return r.strip(w, entity, func(active ActiveEffect) bool {
+		return active.Type.Harmful()
+	})
+}
+
+// Dispel strips every beneficial effect from the entity and returns how
+// many it removed.
+func (r *Registry) Dispel(w *engine.World, entity engine.Entity) int {
+	return r.strip(w, entity, func(active ActiveEffect) bool {
+		return !active.Type.Harmful()
+	})
+}
+
+// strip removes the entity's effects that match and returns how many.
+func (r *Registry) strip(w *engine.World, entity engine.Entity, match func(ActiveEffect) bool) int {
+	comp, ok := w.GetComponent(entity, reflect.TypeOf(&StatusComponent{}))
+	if !ok {
+		return 0
+	}
+	statusComp := comp.(*StatusComponent)
+	kept := statusComp.ActiveEffects[:0]
+	for _, active := range statusComp.ActiveEffects {
+		if !match(active) {
+			kept = append(kept, active)
+		}
+	}
+	removed := len(statusComp.ActiveEffects) - len(kept)
+	statusComp.ActiveEffects = kept
+	if removed > 0 {
+		r.logger.Debugf("Removed %d effects from entity %d", removed, entity)
+	}
+	return removed
 }
 
 // Tick advances all active effects by one tick.
@@ -186,10 +373,18 @@ func (r *Registry) ApplyToEntity(w *engine.World, entity engine.Entity, effectNa
 func (r *Registry) Tick() {
 }
 
+// TickFunc receives a periodic tick of an effect on an entity with the
+// damage it deals; negative amounts heal.
+type TickFunc func(w *engine.World, entity engine.Entity, effect ActiveEffect, amount float64)
+
 // System processes all entities with status effects each frame.
 type System struct {
 	registry *Registry
 	logger   *logrus.Entry
+
+	// OnTick, when set, receives effect ticks to run through the combat
+	// damage pipeline instead of the entity's Health component.
+	OnTick TickFunc
 }
 
 // NewSystem creates a new status effect system.
@@ -255,12 +450,17 @@ func (s *System) tickEffectIfNeeded(w *engine.World, entity engine.Entity, effec
 
 	effect.LastTick = now
 
-	if effect.DamagePerTick != 0 {
+	switch {
+	case effect.DamagePerTick == 0:
+	case s.OnTick != nil:
+		s.OnTick(w, entity, *effect, effect.DamagePerTick)
+	default:
 		s.applyEffectDamage(w, entity, effect, healthType)
 	}
 }
 
-// applyEffectDamage applies damage or healing from a status effect.
+// applyEffectDamage applies damage or healing from a status effect, less
+// the entity's resistance to its damage type.
 func (s *System) applyEffectDamage(w *engine.World, entity engine.Entity, effect *ActiveEffect, healthType reflect.Type) {
 	healthComp, hasHealth := w.GetComponent(entity, healthType)
 	if !hasHealth {
@@ -268,7 +468,11 @@ func (s *System) applyEffectDamage(w *engine.World, entity engine.Entity, effect
 	}
 
 	health := healthComp.(*engine.Health)
-	damage := int(effect.DamagePerTick)
+	amount := effect.DamagePerTick
+	if comp, ok := w.GetComponent(entity, reflect.TypeOf(&StatusComponent{})); ok && amount > 0 {
+		amount = comp.(*StatusComponent).Resistances.Resist(amount, effect.DamageType)
+	}
+	damage := int(amount)
 
 	health.Current -= damage
 	clampHealth(health)
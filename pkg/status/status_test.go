@@ -348,3 +348,129 @@ func TestEffectNegativeDamage(t *testing.T) {
 		t.Errorf("Healing effect should have negative damage, got %f", effect.DamagePerTick)
 	}
 }
+
+func TestMaxStacks(t *testing.T) {
+	r := NewRegistry()
+	w := engine.NewWorld()
+	entity := w.AddEntity()
+
+	for i := 0; i < 8; i++ {
+		r.ApplyToEntity(w, entity, "bleeding")
+	}
+	if got := Stacks(w, entity, "bleeding"); got != defaultMaxStacks {
+		t.Errorf("bleeding stacks = %d, want %d", got, defaultMaxStacks)
+	}
+
+	r.SetGenre("horror")
+	for i := 0; i < 5; i++ {
+		r.ApplyToEntity(w, entity, "infected")
+	}
+	if got := Stacks(w, entity, "infected"); got != 3 {
+		t.Errorf("infected stacks = %d, want 3", got)
+	}
+}
+
+func TestRefreshPolicies(t *testing.T) {
+	tests := []struct {
+		policy RefreshPolicy
+		want   time.Duration
+	}{
+		{RefreshReset, 10 * time.Second},
+		{RefreshExtend, 14 * time.Second},
+		{RefreshKeep, 4 * time.Second},
+	}
+	for _, tt := range tests {
+		active := ActiveEffect{TimeRemaining: 4 * time.Second}
+		refresh(&active, tt.policy, 10*time.Second)
+		if active.TimeRemaining != tt.want {
+			t.Errorf("policy %d left %v, want %v", tt.policy, active.TimeRemaining, tt.want)
+		}
+	}
+
+	// Extending caps at twice the duration.
+	active := ActiveEffect{TimeRemaining: 15 * time.Second}
+	refresh(&active, RefreshExtend, 10*time.Second)
+	if active.TimeRemaining != 20*time.Second {
+		t.Errorf("extended to %v, want the 20s cap", active.TimeRemaining)
+	}
+}
+
+func TestResistances(t *testing.T) {
+	r := NewRegistry()
+	w := engine.NewWorld()
+	entity := w.AddEntity()
+
+	SetResistances(w, entity, Resistances{"toxic": 0.5, "fire": 1})
+	r.ApplyToEntity(w, entity, "poisoned")
+	r.ApplyToEntity(w, entity, "burning")
+
+	comp, _ := w.GetComponent(entity, reflect.TypeOf(&StatusComponent{}))
+	effects := comp.(*StatusComponent).ActiveEffects
+	if len(effects) != 1 || effects[0].EffectName != "poisoned" {
+		t.Fatalf("effects = %+v, want poison alone", effects)
+	}
+	if effects[0].TimeRemaining != 5*time.Second {
+		t.Errorf("half-resisted poison lasts %v, want 5s", effects[0].TimeRemaining)
+	}
+}
+
+func TestCleanseAndDispel(t *testing.T) {
+	r := NewRegistry()
+	w := engine.NewWorld()
+	entity := w.AddEntity()
+
+	for _, name := range []string{"poisoned", "bleeding", "bleeding", "slowed", "regeneration", "blessed"} {
+		r.ApplyToEntity(w, entity, name)
+	}
+	if n := r.Remove(w, entity, "bleeding"); n != 2 {
+		t.Errorf("Remove stripped %d bleeds, want 2", n)
+	}
+	if n := r.Cleanse(w, entity); n != 2 {
+		t.Errorf("Cleanse stripped %d effects, want poison and slow", n)
+	}
+	if n := r.Dispel(w, entity); n != 2 {
+		t.Errorf("Dispel stripped %d effects, want regeneration and blessing", n)
+	}
+	if n := r.Cleanse(w, w.AddEntity()); n != 0 {
+		t.Errorf("Cleanse stripped %d effects from an unaffected entity", n)
+	}
+}
+
+func TestOnTick(t *testing.T) {
+	r := NewRegistry()
+	s := NewSystem(r)
+	w := engine.NewWorld()
+	entity := w.AddEntity()
+	w.AddComponent(entity, &engine.Health{Current: 100, Max: 100})
+
+	var ticked []float64
+	s.OnTick = func(_ *engine.World, e engine.Entity, effect ActiveEffect, amount float64) {
+		if e != entity || effect.DamageType != "fire" {
+			t.Errorf("tick on %d of %+v", e, effect)
+		}
+		ticked = append(ticked, amount)
+	}
+	r.ApplyToEntity(w, entity, "burning")
+	comp, _ := w.GetComponent(entity, reflect.TypeOf(&StatusComponent{}))
+	comp.(*StatusComponent).ActiveEffects[0].LastTick = time.Now().Add(-time.Second)
+
+	s.Update(w)
+	if len(ticked) != 1 || ticked[0] != 5 {
+		t.Errorf("ticks = %v, want one for 5", ticked)
+	}
+	health, _ := w.GetComponent(entity, reflect.TypeOf(&engine.Health{}))
+	if health.(*engine.Health).Current != 100 {
+		t.Error("tick handed to OnTick also hurt the Health component")
+	}
+}
+
+func TestRegistrySetGenre(t *testing.T) {
+	r := NewRegistry()
+	if _, ok := r.Lookup("irradiated"); ok {
+		t.Error("fantasy registry has irradiated")
+	}
+	r.SetGenre("scifi")
+	if e, ok := r.Lookup("irradiated"); !ok || !e.Stackable {
+		t.Errorf("scifi irradiated = %+v, %v", e, ok)
+	}
+}
@@ -0,0 +1,110 @@
+package texture
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// Cutout texture names used for see-through wall tiles.
+const (
+	TextureBars       = "wall_bars"
+	TextureWindow     = "wall_window"
+	TextureForceField = "wall_forcefield"
+)
+
+// GenerateCutoutSet creates the bars, window and force field textures for
+// the current genre. Their alpha channel marks cutouts: 0 is fully open,
+// 255 is solid, and values in between are translucent.
+func (a *Atlas) GenerateCutoutSet(genreID string) {
+	a.SetGenre(genreID)
+	_ = a.Generate(TextureBars, 64, "bars")
+	_ = a.Generate(TextureWindow, 64, "window")
+	_ = a.Generate(TextureForceField, 64, "forcefield")
+}
+
+// generateBarsTexture draws vertical bars with top and bottom rails over a
+// fully transparent background.
+func (a *Atlas) generateBarsTexture(img *image.RGBA, r *rng.RNG) {
+	bounds := img.Bounds()
+	size := bounds.Dx()
+	metal := a.applyNoise(a.getGenreBaseColor(), -0.3)
+	barSpacing := size / 6
+	barWidth := max(2, size/16)
+	railHeight := max(2, size/12)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			rail := y < railHeight || y >= size-railHeight
+			bar := (x+barSpacing/2)%barSpacing < barWidth
+			if !rail && !bar {
+				img.Set(x, y, color.NRGBA{})
+				continue
+			}
+			noise := a.perlinNoise(float64(x)/4.0, float64(y)/4.0, r) * 0.3
+			img.Set(x, y, a.applyNoise(metal, noise))
+		}
+	}
+}
+
+// generateWindowTexture draws an opaque frame and mullions around tinted,
+// mostly transparent glass panes.
+func (a *Atlas) generateWindowTexture(img *image.RGBA, r *rng.RNG) {
+	bounds := img.Bounds()
+	size := bounds.Dx()
+	frame := a.getGenreBaseColor()
+	border := max(2, size/10)
+	mullion := max(1, size/32)
+	tint := a.getGenreCeilingColor()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			edge := x < border || y < border || x >= size-border || y >= size-border
+			cross := absInt(x-size/2) < mullion || absInt(y-size/2) < mullion
+			if edge || cross {
+				noise := a.perlinNoise(float64(x)/8.0, float64(y)/8.0, r) * 0.2
+				img.Set(x, y, a.applyNoise(frame, noise))
+				continue
+			}
+			// Faint diagonal streaks read as reflections on the glass.
+			alpha := uint8(60)
+			if (x+y)%24 < 2 {
+				alpha = 120
+			}
+			img.Set(x, y, color.NRGBA{R: tint.R + 60, G: tint.G + 80, B: tint.B + 90, A: alpha})
+		}
+	}
+}
+
+// generateForceFieldTexture draws a translucent energy barrier with
+// horizontal scan bands between solid emitter edges.
+func (a *Atlas) generateForceFieldTexture(img *image.RGBA, r *rng.RNG) {
+	bounds := img.Bounds()
+	size := bounds.Dx()
+	glow := color.RGBA{R: 80, G: 200, B: 255, A: 255}
+	if a.genre == "cyberpunk" {
+		glow = color.RGBA{R: 255, G: 60, B: 200, A: 255}
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			noise := a.perlinNoise(float64(x)/6.0, float64(y)/3.0, r)
+			alpha := 70 + noise*50
+			if y%8 < 2 {
+				alpha += 60
+			}
+			if x < 2 || x >= size-2 {
+				alpha = 255
+			}
+			img.Set(x, y, color.NRGBA{R: glow.R, G: glow.G, B: glow.B, A: clampUint8(alpha)})
+		}
+	}
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
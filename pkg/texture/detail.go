@@ -0,0 +1,146 @@
+package texture
+
+import (
+	"image"
+	"math"
+
+	"github.com/opd-ai/violence/pkg/rng"
+	"github.com/opd-ai/violence/pkg/walltex"
+)
+
+// Detail variants drawn over the genre wall and floor textures for the
+// generator's detailing pass.
+const (
+	DetailWorn      = "worn"
+	DetailCracked   = "cracked"
+	DetailStained   = "stained"
+	DetailPatterned = "patterned"
+	DetailPipes     = "pipes"
+	DetailGrate     = "grate"
+)
+
+// WallDetails and FloorDetails list the variants generated for each surface.
+var (
+	WallDetails  = []string{DetailWorn, DetailCracked, DetailStained, DetailPatterned, DetailPipes}
+	FloorDetails = []string{DetailWorn, DetailCracked, DetailStained, DetailPatterned, DetailGrate}
+)
+
+// DetailTextureName returns the atlas name of a wall ("wall") or floor
+// ("floor") detail variant, e.g. "wall_cracked".
+func DetailTextureName(surface, detail string) string {
+	return surface + "_" + detail
+}
+
+// GenerateDetailSet creates the wall and floor detail variants for a genre.
+// Wall variants start from the genre's primary wall texture and floor
+// variants from its floor color, so each genre's variants match its base
+// tiles.
+func (a *Atlas) GenerateDetailSet(genreID string) {
+	a.SetGenre(genreID)
+	gen := walltex.NewGenerator(genreID)
+	for _, d := range WallDetails {
+		name := DetailTextureName("wall", d)
+		seed := a.seed ^ hashString(name)
+		img := gen.Generate(64, 0, seed)
+		a.applyDetail(img, d, rng.NewRNG(seed))
+		a.mu.Lock()
+		a.textures[name] = img
+		a.mu.Unlock()
+	}
+	for _, d := range FloorDetails {
+		name := DetailTextureName("floor", d)
+		r := rng.NewRNG(a.seed ^ hashString(name))
+		img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+		base := a.getGenreFloorColor()
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				noise := a.perlinNoise(float64(x)/10.0, float64(y)/10.0, r) * 0.3
+				img.Set(x, y, a.applyNoise(base, noise))
+			}
+		}
+		a.applyDetail(img, d, r)
+		a.mu.Lock()
+		a.textures[name] = img
+		a.mu.Unlock()
+	}
+}
+
+// applyDetail draws a detail variant's marks over img.
+func (a *Atlas) applyDetail(img *image.RGBA, detail string, r *rng.RNG) {
+	size := img.Bounds().Dx()
+	shade := func(x, y int, f float64) {
+		if x < 0 || y < 0 || x >= size || y >= size {
+			return
+		}
+		c := img.RGBAAt(x, y)
+		img.SetRGBA(x, y, a.applyNoise(c, f))
+	}
+
+	switch detail {
+	case DetailWorn:
+		// Faded, scuffed surface.
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				shade(x, y, 0.15+a.perlinNoise(float64(x)/6.0, float64(y)/6.0, r)*0.1)
+			}
+		}
+	case DetailCracked:
+		// A few jagged dark cracks.
+		for i := 0; i < 3; i++ {
+			x, y := float64(r.Intn(size)), float64(r.Intn(size))
+			angle := r.Float64() * 2 * math.Pi
+			for step := 0; step < size/2; step++ {
+				shade(int(x), int(y), -0.5)
+				angle += (r.Float64() - 0.5) * 0.8
+				x += math.Cos(angle)
+				y += math.Sin(angle)
+			}
+		}
+	case DetailStained:
+		// Dark blotches.
+		for i := 0; i < 4; i++ {
+			cx, cy, rad := r.Intn(size), r.Intn(size), 4+r.Intn(size/6)
+			for y := cy - rad; y <= cy+rad; y++ {
+				for x := cx - rad; x <= cx+rad; x++ {
+					if d := math.Hypot(float64(x-cx), float64(y-cy)) / float64(rad); d < 1 {
+						shade(x, y, -0.35*(1-d))
+					}
+				}
+			}
+		}
+	case DetailPatterned:
+		// Regular inset panels.
+		cell := size / 4
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				px, py := x%cell, y%cell
+				switch {
+				case px == 0 || py == 0:
+					shade(x, y, -0.3)
+				case px == 1 || py == 1:
+					shade(x, y, 0.15)
+				}
+			}
+		}
+	case DetailPipes:
+		// Two vertical pipes with highlights.
+		width := max(3, size/10)
+		for _, px := range []int{size / 4, size * 3 / 4} {
+			for y := 0; y < size; y++ {
+				for dx := -width / 2; dx <= width/2; dx++ {
+					shade(px+dx, y, 0.25-0.6*math.Abs(float64(dx))/float64(width))
+				}
+			}
+		}
+	case DetailGrate:
+		// Bars over dark gaps.
+		cell := size / 8
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if x%cell > 1 && y%cell > 1 {
+					shade(x, y, -0.6)
+				}
+			}
+		}
+	}
+}
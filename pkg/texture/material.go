@@ -0,0 +1,153 @@
+package texture
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// Default surface texture names used where no room material applies.
+const (
+	TextureFloorMain   = "floor_main"
+	TextureCeilingMain = "ceiling_main"
+)
+
+// SurfaceTextureName returns the atlas name for a floor ("floor") or
+// ceiling ("ceiling") texture of the given material, e.g. "floor_wood".
+func SurfaceTextureName(surface, material string) string {
+	return surface + "_" + material
+}
+
+// GenerateSurfaceSet creates the default floor and ceiling textures plus a
+// floor and ceiling texture for each named material, all tinted for the genre.
+func (a *Atlas) GenerateSurfaceSet(genreID string, materials []string) {
+	a.SetGenre(genreID)
+	_ = a.Generate(TextureFloorMain, 64, "floor")
+	_ = a.Generate(TextureCeilingMain, 64, "ceiling")
+	for _, m := range materials {
+		a.GenerateMaterial(SurfaceTextureName("floor", m), 64, "floor", m)
+		a.GenerateMaterial(SurfaceTextureName("ceiling", m), 64, "ceiling", m)
+	}
+}
+
+// GenerateMaterial procedurally generates a floor or ceiling texture with a
+// material pattern (stone, wood, metal, tile, carpet, dirt, concrete, grate)
+// and adds it to the atlas. Unknown materials use plain noise.
+func (a *Atlas) GenerateMaterial(name string, size int, surface, material string) {
+	r := rng.NewRNG(a.seed ^ hashString(name))
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	base := a.getGenreFloorColor()
+	if surface == "ceiling" {
+		base = a.getGenreCeilingColor()
+	}
+	base = tintMaterial(base, material)
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			noise := a.perlinNoise(float64(x)/10.0, float64(y)/10.0, r) * 0.3
+			noise += materialPattern(material, x, y, size)
+			img.Set(x, y, a.applyNoise(base, noise))
+		}
+	}
+
+	a.mu.Lock()
+	a.textures[name] = img
+	a.mu.Unlock()
+}
+
+// tintMaterial shifts a genre base color toward a material's natural hue.
+func tintMaterial(c color.RGBA, material string) color.RGBA {
+	shift := func(dr, dg, db int) color.RGBA {
+		return color.RGBA{
+			R: clampUint8(float64(int(c.R) + dr)),
+			G: clampUint8(float64(int(c.G) + dg)),
+			B: clampUint8(float64(int(c.B) + db)),
+			A: c.A,
+		}
+	}
+	switch material {
+	case "wood":
+		return shift(25, 10, -10)
+	case "metal", "grate":
+		return shift(0, 5, 15)
+	case "tile":
+		return shift(30, 30, 30)
+	case "carpet":
+		return shift(35, -10, -5)
+	case "dirt":
+		return shift(15, 5, -15)
+	case "concrete":
+		return shift(10, 10, 10)
+	default:
+		return c
+	}
+}
+
+// materialPattern returns a brightness offset forming the material's
+// repeating structure. Patterns tile seamlessly at the texture size.
+func materialPattern(material string, x, y, size int) float64 {
+	switch material {
+	case "stone":
+		// Offset flagstones with dark mortar lines.
+		block := size / 4
+		ox := x
+		if (y/block)%2 == 1 {
+			ox += block / 2
+		}
+		if y%block == 0 || ox%block == 0 {
+			return -0.35
+		}
+	case "wood":
+		// Planks with grain and dark seams.
+		plank := size / 8
+		if x%plank == 0 {
+			return -0.4
+		}
+		if (y+x*7)%9 == 0 {
+			return -0.1
+		}
+	case "metal":
+		// Plates with rivets at the corners.
+		plate := size / 2
+		px, py := x%plate, y%plate
+		if px == 0 || py == 0 {
+			return -0.3
+		}
+		if (px == 3 || px == plate-3) && (py == 3 || py == plate-3) {
+			return 0.35
+		}
+	case "tile":
+		// Checkerboard tiles with thin grout.
+		cell := size / 8
+		if x%cell == 0 || y%cell == 0 {
+			return -0.3
+		}
+		if (x/cell+y/cell)%2 == 0 {
+			return 0.1
+		}
+	case "carpet":
+		// Soft weave with a patterned border band.
+		band := size / 8
+		if x < band/2 || y < band/2 || x >= size-band/2 || y >= size-band/2 {
+			return 0.2
+		}
+		if (x+y)%4 == 0 {
+			return -0.05
+		}
+	case "concrete":
+		// Expansion joints on a coarse grid.
+		if x%(size/2) == 0 || y%(size/2) == 0 {
+			return -0.2
+		}
+	case "grate":
+		// Bars over dark gaps.
+		cell := size / 8
+		if x%cell > 1 && y%cell > 1 {
+			return -0.6
+		}
+		return 0.15
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package texture
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SetOverride replaces a texture with a supplied image, such as one from a
+// mod. The override outlives regeneration, e.g. on a genre change, until it
+// is cleared.
+func (a *Atlas) SetOverride(name string, img image.Image) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.overrides[name] = img
+}
+
+// ClearOverrides drops every override, returning to the generated
+// textures.
+func (a *Atlas) ClearOverrides() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.overrides = make(map[string]image.Image)
+}
+
+// OverrideCount returns the number of overridden textures.
+func (a *Atlas) OverrideCount() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.overrides)
+}
+
+// LoadOverrides overrides a texture with each PNG file in dir, named by
+// the file name without its extension, e.g. wall_1.png replaces wall_1.
+// Files that fail to decode are skipped, leaving the generated texture in
+// place, and reported in the returned error.
+func (a *Atlas) LoadOverrides(dir string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.png"))
+	if err != nil {
+		return 0, err
+	}
+	loaded := 0
+	var failed []string
+	for _, path := range paths {
+		img, err := loadPNG(path)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", filepath.Base(path), err))
+			continue
+		}
+		a.SetOverride(strings.TrimSuffix(filepath.Base(path), ".png"), img)
+		loaded++
+	}
+	if len(failed) > 0 {
+		return loaded, fmt.Errorf("failed to load textures: %s", strings.Join(failed, "; "))
+	}
+	return loaded, nil
+}
+
+// loadPNG decodes a PNG file.
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
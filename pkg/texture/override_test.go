@@ -0,0 +1,52 @@
+package texture
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAtlas_LoadOverrides(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	img.Set(3, 4, color.RGBA{R: 200, A: 255})
+	f, err := os.Create(filepath.Join(dir, "wall_1.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err := os.WriteFile(filepath.Join(dir, "broken.png"), []byte("not a png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	atlas := NewAtlas(1)
+	atlas.GenerateWallSet("fantasy")
+	n, err := atlas.LoadOverrides(dir)
+	if n != 1 || err == nil {
+		t.Errorf("LoadOverrides = %d, %v; want 1 and an error for broken.png", n, err)
+	}
+
+	got, ok := atlas.Get("wall_1")
+	if !ok || got.Bounds().Dx() != 16 || color.RGBAModel.Convert(got.At(3, 4)) != (color.RGBA{R: 200, A: 255}) {
+		t.Error("Get(wall_1) did not return the override")
+	}
+	if _, ok := atlas.Get("broken"); ok {
+		t.Error("undecodable file registered a texture")
+	}
+
+	// Regenerating keeps the override; clearing falls back to the generated one
+	atlas.GenerateWallSet("scifi")
+	if got, _ := atlas.Get("wall_1"); got.Bounds().Dx() != 16 {
+		t.Error("regeneration replaced the override")
+	}
+	atlas.ClearOverrides()
+	if got, ok := atlas.Get("wall_1"); !ok || got.Bounds().Dx() == 16 {
+		t.Error("ClearOverrides did not restore the generated texture")
+	}
+}
@@ -0,0 +1,211 @@
+package texture
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// Sky texture names. The far layer is an opaque 360 degree panorama; the
+// near layer is a translucent band drawn over it that repeats twice per
+// turn, so it slides past the far layer as the camera rotates.
+const (
+	TextureSkyFar  = "sky_far"
+	TextureSkyNear = "sky_near"
+)
+
+// Sky texture dimensions. Row 0 is the zenith and the last row the horizon.
+const (
+	skyFarWidth  = 512
+	skyNearWidth = 256
+	skyHeight    = 128
+)
+
+// skyStyle describes a genre's sky.
+type skyStyle struct {
+	zenith, horizon color.RGBA
+	stars           float64    // Chance per pixel of a star in the far layer
+	glow            color.RGBA // Light pollution at the horizon; A 0 disables
+	glowHeight      float64    // Share of the sky height the glow reaches
+	clouds          color.RGBA // Near layer cloud color; A is peak opacity
+	cloudCover      float64    // Share of the near layer covered by cloud
+	skyline         bool       // Near layer is a city skyline instead of cloud
+}
+
+// skyStyleFor returns the sky of a genre: starry nights for fantasy and
+// scifi, overcast or toxic clouds for horror and postapoc, and a glowing
+// skyline for cyberpunk.
+func skyStyleFor(genreID string) skyStyle {
+	switch genreID {
+	case "scifi":
+		return skyStyle{
+			zenith:     color.RGBA{R: 2, G: 2, B: 10, A: 255},
+			horizon:    color.RGBA{R: 24, G: 12, B: 48, A: 255},
+			stars:      0.012,
+			clouds:     color.RGBA{R: 120, G: 60, B: 170, A: 90},
+			cloudCover: 0.35,
+		}
+	case "horror":
+		return skyStyle{
+			zenith:     color.RGBA{R: 14, G: 8, B: 10, A: 255},
+			horizon:    color.RGBA{R: 58, G: 30, B: 30, A: 255},
+			stars:      0.0008,
+			clouds:     color.RGBA{R: 28, G: 20, B: 22, A: 230},
+			cloudCover: 0.6,
+		}
+	case "cyberpunk":
+		return skyStyle{
+			zenith:     color.RGBA{R: 14, G: 6, B: 30, A: 255},
+			horizon:    color.RGBA{R: 60, G: 20, B: 70, A: 255},
+			stars:      0.0005,
+			glow:       color.RGBA{R: 230, G: 70, B: 150, A: 255},
+			glowHeight: 0.45,
+			skyline:    true,
+		}
+	case "postapoc":
+		return skyStyle{
+			zenith:     color.RGBA{R: 44, G: 46, B: 22, A: 255},
+			horizon:    color.RGBA{R: 150, G: 130, B: 60, A: 255},
+			clouds:     color.RGBA{R: 110, G: 150, B: 40, A: 200},
+			cloudCover: 0.55,
+		}
+	default:
+		return skyStyle{
+			zenith:     color.RGBA{R: 8, G: 12, B: 40, A: 255},
+			horizon:    color.RGBA{R: 62, G: 54, B: 96, A: 255},
+			stars:      0.004,
+			clouds:     color.RGBA{R: 150, G: 150, B: 180, A: 110},
+			cloudCover: 0.3,
+		}
+	}
+}
+
+// GenerateSkySet creates the far and near sky layers for a genre. Both
+// wrap horizontally without a seam.
+func (a *Atlas) GenerateSkySet(genreID string) {
+	a.SetGenre(genreID)
+	style := skyStyleFor(genreID)
+
+	far := image.NewRGBA(image.Rect(0, 0, skyFarWidth, skyHeight))
+	a.generateSkyFar(far, style, rng.NewRNG(a.seed^hashString(TextureSkyFar+genreID)))
+	near := image.NewRGBA(image.Rect(0, 0, skyNearWidth, skyHeight))
+	if style.skyline {
+		a.generateSkyline(near, rng.NewRNG(a.seed^hashString(TextureSkyNear+genreID)))
+	} else {
+		a.generateSkyClouds(near, style, rng.NewRNG(a.seed^hashString(TextureSkyNear+genreID)))
+	}
+
+	a.mu.Lock()
+	a.textures[TextureSkyFar] = far
+	a.textures[TextureSkyNear] = near
+	a.mu.Unlock()
+}
+
+// generateSkyFar paints the zenith to horizon gradient, horizon glow and
+// stars. Stars thin out toward the horizon, where haze would hide them.
+func (a *Atlas) generateSkyFar(img *image.RGBA, style skyStyle, r *rng.RNG) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	for y := 0; y < h; y++ {
+		t := float64(y) / float64(h-1)
+		base := lerpRGBA(style.zenith, style.horizon, t*t)
+		if style.glow.A > 0 && style.glowHeight > 0 {
+			// Glow falls off exponentially above the horizon.
+			g := math.Exp(-(1 - t) / style.glowHeight * 3)
+			base = lerpRGBA(base, style.glow, g*0.8)
+		}
+		for x := 0; x < w; x++ {
+			noise := a.wrappedNoise(float64(x)/32.0, float64(y)/16.0, float64(w)/32.0, r) * 0.08
+			img.Set(x, y, a.applyNoise(base, noise))
+		}
+	}
+
+	stars := int(float64(w*h) * style.stars)
+	for i := 0; i < stars; i++ {
+		x := r.Intn(w)
+		y := int(float64(h) * 0.85 * r.Float64() * r.Float64())
+		bright := 140 + r.Intn(116)
+		star := color.RGBA{R: uint8(bright), G: uint8(bright), B: uint8(min(255, bright+20)), A: 255}
+		img.Set(x, y, star)
+		if bright > 245 {
+			// The brightest stars get a small cross so they read at low
+			// render scales.
+			dim := lerpRGBA(img.RGBAAt(x, y), star, 0.5)
+			img.Set((x+1)%w, y, dim)
+			img.Set((x+w-1)%w, y, dim)
+		}
+	}
+}
+
+// generateSkyClouds paints a translucent cloud band denser toward the
+// horizon, leaving clear sky elsewhere.
+func (a *Atlas) generateSkyClouds(img *image.RGBA, style skyStyle, r *rng.RNG) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	period := float64(w) / 48.0
+
+	for y := 0; y < h; y++ {
+		weight := 0.4 + 0.6*float64(y)/float64(h-1)
+		for x := 0; x < w; x++ {
+			fx, fy := float64(x)/48.0, float64(y)/20.0
+			n := a.wrappedNoise(fx, fy, period, r) + 0.5*a.wrappedNoise(fx*2, fy*2, period*2, r)
+			density := (n*0.5 + 0.5) - (1 - style.cloudCover)
+			if density <= 0 {
+				img.Set(x, y, color.NRGBA{})
+				continue
+			}
+			alpha := math.Min(density*3, 1) * weight * float64(style.clouds.A)
+			shade := a.applyNoise(style.clouds, n*0.2)
+			img.Set(x, y, color.NRGBA{R: shade.R, G: shade.G, B: shade.B, A: clampUint8(alpha)})
+		}
+	}
+}
+
+// generateSkyline paints building silhouettes with lit windows along the
+// bottom of a transparent band.
+func (a *Atlas) generateSkyline(img *image.RGBA, r *rng.RNG) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	body := color.RGBA{R: 12, G: 6, B: 20, A: 255}
+	lights := []color.RGBA{
+		{R: 255, G: 80, B: 200, A: 255},
+		{R: 80, G: 220, B: 255, A: 255},
+		{R: 255, G: 200, B: 90, A: 255},
+	}
+
+	for x := 0; x < w; {
+		bw := 6 + r.Intn(15)
+		top := h - int(float64(h)*(0.12+0.33*r.Float64()))
+		for bx := x; bx < min(x+bw, w); bx++ {
+			for y := top; y < h; y++ {
+				c := body
+				window := (bx-x)%3 == 1 && (y-top)%3 == 1
+				if window && r.Float64() < 0.3 {
+					c = lights[r.Intn(len(lights))]
+				}
+				img.Set(bx, y, c)
+			}
+		}
+		x += bw + r.Intn(3)
+	}
+}
+
+// wrappedNoise is perlinNoise made periodic in x: it blends the noise at x
+// with the noise one period to the left, so x = 0 and x = period match.
+func (a *Atlas) wrappedNoise(x, y, period float64, r *rng.RNG) float64 {
+	t := x / period
+	return lerp(a.perlinNoise(x, y, r), a.perlinNoise(x-period, y, r), t)
+}
+
+// lerpRGBA linearly interpolates between two opaque colors.
+func lerpRGBA(a, b color.RGBA, t float64) color.RGBA {
+	return color.RGBA{
+		R: clampUint8(lerp(float64(a.R), float64(b.R), t)),
+		G: clampUint8(lerp(float64(a.G), float64(b.G), t)),
+		B: clampUint8(lerp(float64(a.B), float64(b.B), t)),
+		A: 255,
+	}
+}
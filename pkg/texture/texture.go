@@ -13,26 +13,29 @@ import (
 
 // Atlas stores procedurally generated textures.
 type Atlas struct {
-	textures map[string]image.Image
-	animated map[string]*AnimatedTexture
-	genre    string
-	seed     uint64
-	mu       sync.RWMutex
+	textures  map[string]image.Image
+	animated  map[string]*AnimatedTexture
+	overrides map[string]image.Image // Supplied textures, e.g. from mods
+	genre     string
+	seed      uint64
+	mu        sync.RWMutex
 }
 
 // NewAtlas creates an empty texture atlas with the given seed.
 func NewAtlas(seed uint64) *Atlas {
 	return &Atlas{
-		textures: make(map[string]image.Image),
-		animated: make(map[string]*AnimatedTexture),
-		genre:    "fantasy",
-		seed:     seed,
+		textures:  make(map[string]image.Image),
+		animated:  make(map[string]*AnimatedTexture),
+		overrides: make(map[string]image.Image),
+		genre:     "fantasy",
+		seed:      seed,
 	}
 }
 
 // Generate procedurally generates a texture and adds it to the atlas.
 // Size is the texture dimensions (typically 64, 128, or 256).
-// Type determines the generation algorithm: "wall", "floor", "ceiling".
+// Type determines the generation algorithm: "wall", "floor", "ceiling", or
+// the cutout types "bars", "window", "forcefield".
 func (a *Atlas) Generate(name string, size int, textureType string) error {
 	r := rng.NewRNG(a.seed ^ hashString(name))
 	img := image.NewRGBA(image.Rect(0, 0, size, size))
@@ -44,6 +47,12 @@ func (a *Atlas) Generate(name string, size int, textureType string) error {
 		a.generateFloorTexture(img, r)
 	case "ceiling":
 		a.generateCeilingTexture(img, r)
+	case "bars":
+		a.generateBarsTexture(img, r)
+	case "window":
+		a.generateWindowTexture(img, r)
+	case "forcefield":
+		a.generateForceFieldTexture(img, r)
 	default:
 		a.generateWallTexture(img, r)
 	}
@@ -54,11 +63,15 @@ func (a *Atlas) Generate(name string, size int, textureType string) error {
 	return nil
 }
 
-// Get retrieves a texture by name.
+// Get retrieves a texture by name. An override of the name is returned in
+// place of the generated texture.
 func (a *Atlas) Get(name string) (image.Image, bool) {
 	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if img, ok := a.overrides[name]; ok {
+		return img, true
+	}
 	img, ok := a.textures[name]
-	a.mu.RUnlock()
 	return img, ok
 }
 
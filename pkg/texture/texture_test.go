@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image"
 	"image/color"
+	"math"
 	"testing"
 )
 
@@ -1098,3 +1099,202 @@ func TestConcurrentMixedAccess(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateCutoutSet(t *testing.T) {
+	atlas := NewAtlas(99)
+	atlas.GenerateCutoutSet("scifi")
+
+	tests := []struct {
+		name        string
+		wantOpen    bool // Some pixels fully transparent
+		wantPartial bool // Some pixels translucent
+	}{
+		{TextureBars, true, false},
+		{TextureWindow, false, true},
+		{TextureForceField, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, ok := atlas.Get(tt.name)
+			if !ok {
+				t.Fatalf("texture %s not generated", tt.name)
+			}
+			open, partial, solid := 0, 0, 0
+			b := img.Bounds()
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					_, _, _, a := img.At(x, y).RGBA()
+					switch {
+					case a == 0:
+						open++
+					case a < 0xffff:
+						partial++
+					default:
+						solid++
+					}
+				}
+			}
+			if tt.wantOpen && open == 0 {
+				t.Error("expected fully transparent cutout pixels")
+			}
+			if tt.wantPartial && partial == 0 {
+				t.Error("expected translucent pixels")
+			}
+			if solid == 0 {
+				t.Error("expected some opaque pixels")
+			}
+		})
+	}
+}
+
+func TestGenerateDetailSet(t *testing.T) {
+	for _, genreID := range []string{"fantasy", "scifi"} {
+		atlas := NewAtlas(11)
+		atlas.GenerateDetailSet(genreID)
+		for _, d := range WallDetails {
+			if _, ok := atlas.Get(DetailTextureName("wall", d)); !ok {
+				t.Errorf("%s: wall detail %q not generated", genreID, d)
+			}
+		}
+		for _, d := range FloorDetails {
+			if _, ok := atlas.Get(DetailTextureName("floor", d)); !ok {
+				t.Errorf("%s: floor detail %q not generated", genreID, d)
+			}
+		}
+	}
+
+	// Genres must tint their variants differently.
+	fantasy, scifi := NewAtlas(11), NewAtlas(11)
+	fantasy.GenerateDetailSet("fantasy")
+	scifi.GenerateDetailSet("scifi")
+	a, _ := fantasy.Get("floor_cracked")
+	b, _ := scifi.Get("floor_cracked")
+	if a.At(5, 5) == b.At(5, 5) && a.At(30, 40) == b.At(30, 40) {
+		t.Error("fantasy and scifi floor_cracked look the same")
+	}
+}
+
+func TestGenerateSurfaceSet(t *testing.T) {
+	atlas := NewAtlas(7)
+	materials := []string{"stone", "wood", "metal", "tile", "carpet", "dirt", "concrete", "grate"}
+	atlas.GenerateSurfaceSet("fantasy", materials)
+
+	names := []string{TextureFloorMain, TextureCeilingMain}
+	for _, m := range materials {
+		names = append(names, SurfaceTextureName("floor", m), SurfaceTextureName("ceiling", m))
+	}
+	for _, name := range names {
+		if _, ok := atlas.Get(name); !ok {
+			t.Errorf("texture %q not generated", name)
+		}
+	}
+
+	// Materials must look different from each other.
+	wood, _ := atlas.Get("floor_wood")
+	tile, _ := atlas.Get("floor_tile")
+	same := true
+	for y := 0; y < 64 && same; y++ {
+		for x := 0; x < 64; x++ {
+			if wood.At(x, y) != tile.At(x, y) {
+				same = false
+				break
+			}
+		}
+	}
+	if same {
+		t.Error("wood and tile floor textures are identical")
+	}
+}
+
+func TestGenerateMaterialDeterministic(t *testing.T) {
+	a1 := NewAtlas(123)
+	a2 := NewAtlas(123)
+	a1.GenerateMaterial("floor_stone", 32, "floor", "stone")
+	a2.GenerateMaterial("floor_stone", 32, "floor", "stone")
+	img1, _ := a1.Get("floor_stone")
+	img2, _ := a2.Get("floor_stone")
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if img1.At(x, y) != img2.At(x, y) {
+				t.Fatalf("pixel (%d,%d) differs between identical seeds", x, y)
+			}
+		}
+	}
+}
+
+func TestGenerateSkySet(t *testing.T) {
+	for _, genreID := range []string{"fantasy", "scifi", "horror", "cyberpunk", "postapoc"} {
+		t.Run(genreID, func(t *testing.T) {
+			atlas := NewAtlas(11)
+			atlas.GenerateSkySet(genreID)
+
+			far, ok := atlas.Get(TextureSkyFar)
+			if !ok {
+				t.Fatal("far sky layer not generated")
+			}
+			near, ok := atlas.Get(TextureSkyNear)
+			if !ok {
+				t.Fatal("near sky layer not generated")
+			}
+
+			b := far.Bounds()
+			if b.Dx() <= b.Dy() {
+				t.Errorf("far layer %v is not a panorama", b)
+			}
+			for y := b.Min.Y; y < b.Max.Y; y++ {
+				for x := b.Min.X; x < b.Max.X; x++ {
+					if _, _, _, a := far.At(x, y).RGBA(); a != 0xffff {
+						t.Fatalf("far layer pixel (%d,%d) is not opaque", x, y)
+					}
+				}
+			}
+
+			open, covered := 0, 0
+			nb := near.Bounds()
+			for y := nb.Min.Y; y < nb.Max.Y; y++ {
+				for x := nb.Min.X; x < nb.Max.X; x++ {
+					if _, _, _, a := near.At(x, y).RGBA(); a == 0 {
+						open++
+					} else {
+						covered++
+					}
+				}
+			}
+			if open == 0 || covered == 0 {
+				t.Errorf("near layer has %d open and %d covered pixels, want both", open, covered)
+			}
+		})
+	}
+}
+
+func TestGenerateSkySetDeterministic(t *testing.T) {
+	a1 := NewAtlas(5)
+	a2 := NewAtlas(5)
+	a1.GenerateSkySet("postapoc")
+	a2.GenerateSkySet("postapoc")
+	for _, name := range []string{TextureSkyFar, TextureSkyNear} {
+		img1, _ := a1.Get(name)
+		img2, _ := a2.Get(name)
+		b := img1.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				if img1.At(x, y) != img2.At(x, y) {
+					t.Fatalf("%s pixel (%d,%d) differs between identical seeds", name, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestWrappedNoiseSeamless(t *testing.T) {
+	atlas := NewAtlas(3)
+	r := atlas.getRNG()
+	const period = 8.0
+	for _, y := range []float64{0.3, 1.7, 4.2} {
+		a := atlas.wrappedNoise(0.01, y, period, r)
+		b := atlas.wrappedNoise(period+0.01, y, period, r)
+		if math.Abs(a-b) > 0.05 {
+			t.Errorf("y=%v: noise at 0 (%v) and at period (%v) differ", y, a, b)
+		}
+	}
+}
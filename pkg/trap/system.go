@@ -226,8 +226,7 @@ func isValidTrapLocation(worldMap [][]int, x, y, width, height int) bool {
 
 // isFloorTile checks if a tile value represents a floor.
 func isFloorTile(tileValue int) bool {
-	return tileValue == 2 || tileValue == 20 || tileValue == 21 ||
-		tileValue == 22 || tileValue == 23 || tileValue == 24
+	return tileValue == 2 || (tileValue >= 20 && tileValue <= 29)
 }
 
 // countFloorNeighbors counts floor tiles adjacent to a position.
@@ -126,13 +126,22 @@ func NewScoreboardEntry(id uint64, name string, team, frags, deaths, assists int
 	}
 }
 
+// ScoreboardTeam is a team's total on the scoreboard.
+type ScoreboardTeam struct {
+	Team      int
+	Score     int
+	RoundsWon int
+}
+
 // Scoreboard displays end-of-match or in-game statistics.
 type Scoreboard struct {
 	Title      string
 	Entries    []ScoreboardEntry
+	Teams      []ScoreboardTeam // Team totals, shown under the header when ShowTeams is set
 	Visible    bool
 	ShowTeams  bool
 	WinnerText string
+	StatusText string // Round, phase and time left, shown under the header
 }
 
 // NewScoreboard creates a new scoreboard display.
@@ -154,6 +163,16 @@ func (sb *Scoreboard) SetWinner(winnerText string) {
 	sb.WinnerText = winnerText
 }
 
+// SetTeams updates the team totals.
+func (sb *Scoreboard) SetTeams(teams []ScoreboardTeam) {
+	sb.Teams = teams
+}
+
+// SetStatus sets the match status line, such as the round and time left.
+func (sb *Scoreboard) SetStatus(status string) {
+	sb.StatusText = status
+}
+
 // Show makes the scoreboard visible.
 func (sb *Scoreboard) Show() {
 	sb.Visible = true
@@ -180,6 +199,10 @@ func (sb *Scoreboard) Draw(screen *ebiten.Image) {
 
 	drawScoreboardBackground(screen, screenWidth, screenHeight)
 	y := drawScoreboardHeader(screen, sb.Title, sb.WinnerText, screenWidth)
+	y = drawScoreboardStatus(screen, sb.StatusText, y, screenWidth)
+	if sb.ShowTeams {
+		y = drawScoreboardTeams(screen, sb.Teams, y, screenWidth)
+	}
 	y = drawScoreboardColumnHeaders(screen, y, sb.ShowTeams)
 	drawScoreboardEntries(screen, sb.Entries, y, sb.ShowTeams)
 }
@@ -212,6 +235,44 @@ func drawScoreboardHeader(screen *ebiten.Image, title, winnerText string, screen
 	return y
 }
 
+// drawScoreboardStatus renders the match status line, returns updated Y position.
+func drawScoreboardStatus(screen *ebiten.Image, status string, y, screenWidth int) int {
+	if status == "" {
+		return y
+	}
+	statusColor := color.RGBA{R: 200, G: 200, B: 200, A: 255}
+	statusX := (screenWidth / 2) - (len(status) * 7 / 2)
+	text.Draw(screen, status, basicfont.Face7x13, statusX, y, statusColor)
+	return y + 25
+}
+
+// drawScoreboardTeams renders each team's total side by side, returns updated Y position.
+func drawScoreboardTeams(screen *ebiten.Image, teams []ScoreboardTeam, y, screenWidth int) int {
+	if len(teams) == 0 {
+		return y
+	}
+	colWidth := (screenWidth - 200) / len(teams)
+	for i, team := range teams {
+		line := formatTeamScore(team)
+		x := 100 + i*colWidth + colWidth/2 - len(line)*7/2
+		text.Draw(screen, line, basicfont.Face7x13, x, y, selectPlayerColor(team.Team, true))
+	}
+	return y + 25
+}
+
+// formatTeamScore returns a team's total as the scoreboard shows it.
+func formatTeamScore(team ScoreboardTeam) string {
+	return fmt.Sprintf("%s %d  (rounds %d)", teamName(team.Team), team.Score, team.RoundsWon)
+}
+
+// teamName returns a team's display name.
+func teamName(team int) string {
+	if team == 1 {
+		return "Blue"
+	}
+	return "Red"
+}
+
 // drawScoreboardColumnHeaders renders column headers, returns updated Y position.
 func drawScoreboardColumnHeaders(screen *ebiten.Image, y int, showTeams bool) int {
 	headerColor := color.RGBA{R: 200, G: 200, B: 200, A: 255}
@@ -278,11 +339,7 @@ func drawScoreboardEntry(screen *ebiten.Image, entry ScoreboardEntry, y int, pla
 
 // drawTeamEntry renders team-specific stats for an entry.
 func drawTeamEntry(screen *ebiten.Image, entry ScoreboardEntry, y int, playerColor, entryColor color.RGBA) {
-	teamName := "Red"
-	if entry.Team == 1 {
-		teamName = "Blue"
-	}
-	text.Draw(screen, teamName, basicfont.Face7x13, 250, y, playerColor)
+	text.Draw(screen, teamName(entry.Team), basicfont.Face7x13, 250, y, playerColor)
 	text.Draw(screen, fmt.Sprintf("%d", entry.Frags), basicfont.Face7x13, 350, y, entryColor)
 	text.Draw(screen, fmt.Sprintf("%d", entry.Deaths), basicfont.Face7x13, 400, y, entryColor)
 	text.Draw(screen, fmt.Sprintf("%d", entry.Assists), basicfont.Face7x13, 450, y, entryColor)
@@ -208,6 +208,22 @@ func TestScoreboardSetWinner(t *testing.T) {
 	}
 }
 
+func TestScoreboardTeams(t *testing.T) {
+	sb := NewScoreboard("Test", true)
+	sb.SetTeams([]ScoreboardTeam{{Team: 0, Score: 12, RoundsWon: 1}, {Team: 1, Score: 9}})
+	sb.SetStatus("Round 2/3 - 1:30")
+
+	if len(sb.Teams) != 2 || sb.StatusText != "Round 2/3 - 1:30" {
+		t.Errorf("Teams = %+v, StatusText = %q", sb.Teams, sb.StatusText)
+	}
+	if got := formatTeamScore(sb.Teams[0]); got != "Red 12  (rounds 1)" {
+		t.Errorf("formatTeamScore(red) = %q", got)
+	}
+	if got := formatTeamScore(sb.Teams[1]); got != "Blue 9  (rounds 0)" {
+		t.Errorf("formatTeamScore(blue) = %q", got)
+	}
+}
+
 func TestScoreboardVisibility(t *testing.T) {
 	sb := NewScoreboard("Test", false)
 
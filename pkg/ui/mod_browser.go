@@ -7,18 +7,20 @@ import (
 	"image/color"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/opd-ai/violence/pkg/input"
 	"github.com/opd-ai/violence/pkg/mod"
 	"github.com/opd-ai/violence/pkg/mod/registry"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/image/font/basicfont"
 )
 
 // ModBrowserState represents the current view in the mod browser.
@@ -49,12 +51,18 @@ type ModBrowser struct {
 	errorMessage    string
 	errorTime       time.Time
 	autoUpdateCheck time.Time
+	modsDir         string   // Where mods are installed
+	searching       bool     // Whether the search query is being edited
+	installedDirs   []string // Directories installed since TakeInstalled
 }
 
+// maxSearchQueryLength caps the search query typed into the browser.
+const maxSearchQueryLength = 32
+
 // NewModBrowser creates a new mod browser UI.
 func NewModBrowser(registryURL string) *ModBrowser {
 	return &ModBrowser{
-		registryURL:     registryURL,
+		registryURL:     strings.TrimSuffix(registryURL, "/"),
 		state:           ModBrowserStateBrowse,
 		mods:            []registry.ModRecord{},
 		installedMods:   make(map[string]string),
@@ -69,7 +77,7 @@ func NewModBrowser(registryURL string) *ModBrowser {
 func (mb *ModBrowser) SetVisible(visible bool) {
 	mb.visible = visible
 	if visible && len(mb.mods) == 0 {
-		mb.RefreshModList()
+		mb.Refresh()
 	}
 }
 
@@ -78,14 +86,23 @@ func (mb *ModBrowser) IsVisible() bool {
 	return mb.visible
 }
 
+// SetModsDir sets the directory mods are installed into.
+func (mb *ModBrowser) SetModsDir(dir string) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.modsDir = dir
+}
+
 // RefreshModList fetches latest mods from registry.
 func (mb *ModBrowser) RefreshModList() error {
-	url := mb.registryURL + "/search"
+	mb.mu.RLock()
+	searchURL := mb.registryURL + "/search"
 	if mb.searchQuery != "" {
-		url += "?name=" + mb.searchQuery
+		searchURL += "?name=" + url.QueryEscape(mb.searchQuery)
 	}
+	mb.mu.RUnlock()
 
-	resp, err := mb.httpClient.Get(url)
+	resp, err := mb.httpClient.Get(searchURL)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch mod list")
 		mb.setError("Failed to connect to mod registry")
@@ -140,8 +157,7 @@ func (mb *ModBrowser) CheckForUpdates() error {
 	// Fetch latest version for each installed mod
 	updates := make(map[string]string)
 	for _, name := range installedNames {
-		url := fmt.Sprintf("%s/search?name=%s", mb.registryURL, name)
-		resp, err := mb.httpClient.Get(url)
+		resp, err := mb.httpClient.Get(mb.registryURL + "/search?name=" + url.QueryEscape(name))
 		if err != nil {
 			continue
 		}
@@ -186,16 +202,16 @@ func (mb *ModBrowser) CheckForUpdates() error {
 
 // DownloadMod fetches and verifies a mod from the registry.
 func (mb *ModBrowser) DownloadMod(name, version string) ([]byte, string, error) {
-	url := fmt.Sprintf("%s/download/%s/%s", mb.registryURL, name, version)
+	downloadURL := fmt.Sprintf("%s/download/%s/%s", mb.registryURL, name, version)
 
 	logrus.WithFields(logrus.Fields{
 		"system_name": "mod_browser",
 		"mod_name":    name,
 		"version":     version,
-		"url":         url,
+		"url":         downloadURL,
 	}).Info("Downloading mod")
 
-	resp, err := mb.httpClient.Get(url)
+	resp, err := mb.httpClient.Get(downloadURL)
 	if err != nil {
 		return nil, "", fmt.Errorf("download failed: %w", err)
 	}
@@ -205,7 +221,7 @@ func (mb *ModBrowser) DownloadMod(name, version string) ([]byte, string, error)
 		return nil, "", fmt.Errorf("download failed with status %d", resp.StatusCode)
 	}
 
-	expectedChecksum := resp.Header.Get("X-SHA256")
+	expectedChecksum := resp.Header.Get("X-Mod-SHA256")
 	if expectedChecksum == "" {
 		return nil, "", fmt.Errorf("no checksum in response headers")
 	}
@@ -218,7 +234,45 @@ func (mb *ModBrowser) DownloadMod(name, version string) ([]byte, string, error)
 	return data, expectedChecksum, nil
 }
 
-// InstallMod downloads and installs a mod with checksum verification.
+// DownloadManifest fetches the manifest a mod version was uploaded with.
+func (mb *ModBrowser) DownloadManifest(name, version string) ([]byte, error) {
+	resp, err := mb.httpClient.Get(fmt.Sprintf("%s/manifest/%s/%s", mb.registryURL, name, version))
+	if err != nil {
+		return nil, fmt.Errorf("manifest download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("manifest download failed with status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// lookupRecord finds the registry record of a mod version.
+func (mb *ModBrowser) lookupRecord(name, version string) (*registry.ModRecord, error) {
+	resp, err := mb.httpClient.Get(mb.registryURL + "/search?name=" + url.QueryEscape(name))
+	if err != nil {
+		return nil, fmt.Errorf("lookup failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Results []registry.ModRecord `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("lookup failed: %w", err)
+	}
+	for i := range result.Results {
+		if result.Results[i].Name == name && result.Results[i].Version == version {
+			return &result.Results[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s v%s is not in the registry", name, version)
+}
+
+// InstallMod downloads a mod, verifies its checksum and installs it into
+// the mods directory, replacing any installed version. The game picks
+// installed mods up with TakeInstalled.
 func (mb *ModBrowser) InstallMod(name, version string) error {
 	mb.mu.Lock()
 	if mb.installing {
@@ -244,14 +298,16 @@ func (mb *ModBrowser) InstallMod(name, version string) error {
 		return err
 	}
 
-	mb.mu.Lock()
-	mb.installProgress = "Verifying checksum..."
-	mb.mu.Unlock()
+	mb.setProgress("Verifying checksum...")
 
-	// Verify checksum
-	actualChecksum := mod.ComputeSHA256(data)
-	if actualChecksum != expectedChecksum {
-		err := fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum)
+	// The checksum must match both the download and the search listing
+	var record *registry.ModRecord
+	if actualChecksum := mod.ComputeSHA256(data); actualChecksum != expectedChecksum {
+		err = fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, actualChecksum)
+	} else if record, err = mb.lookupRecord(name, version); err == nil && record.SHA256 != expectedChecksum {
+		err = fmt.Errorf("registry lists checksum %s, download has %s", record.SHA256, expectedChecksum)
+	}
+	if err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
 			"system_name": "mod_browser",
 			"mod_name":    name,
@@ -260,12 +316,26 @@ func (mb *ModBrowser) InstallMod(name, version string) error {
 		return err
 	}
 
-	mb.mu.Lock()
-	mb.installProgress = "Installing..."
-	mb.mu.Unlock()
+	mb.setProgress("Installing...")
 
-	// Load the mod
-	if err := mod.LoadWASMModule(data); err != nil {
+	manifestData, err := mb.DownloadManifest(name, version)
+	if err != nil {
+		mb.setError(fmt.Sprintf("Installation failed: %v", err))
+		return err
+	}
+	var manifest mod.Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		mb.setError("Installation failed: invalid manifest")
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := manifest.Validate(); err != nil || manifest.Name != name || manifest.Version != version {
+		mb.setError("Installation failed: invalid manifest")
+		return fmt.Errorf("manifest doesn't describe %s v%s: %v", name, version, err)
+	}
+
+	files := registry.PackageFiles(manifestData, data, manifest.EntryPoint)
+	dir, err := mb.installPackage(name, files, record.Signature)
+	if err != nil {
 		mb.setError(fmt.Sprintf("Installation failed: %v", err))
 		return err
 	}
@@ -274,17 +344,74 @@ func (mb *ModBrowser) InstallMod(name, version string) error {
 	mb.mu.Lock()
 	mb.installedMods[name] = version
 	delete(mb.updateAvailable, name)
+	mb.installedDirs = append(mb.installedDirs, dir)
 	mb.mu.Unlock()
 
 	logrus.WithFields(logrus.Fields{
 		"system_name": "mod_browser",
 		"mod_name":    name,
 		"version":     version,
+		"dir":         dir,
 	}).Info("Mod installed successfully")
 
 	return nil
 }
 
+// installPackage writes a mod package, and its signature if it has one, to
+// the mod's directory. The package is written beside it first and swapped
+// in, so a failed install leaves any installed version intact.
+func (mb *ModBrowser) installPackage(name string, files map[string][]byte, sig *mod.PackageSignature) (string, error) {
+	mb.mu.RLock()
+	modsDir := mb.modsDir
+	mb.mu.RUnlock()
+	if modsDir == "" {
+		return "", fmt.Errorf("no mods directory")
+	}
+	if err := os.MkdirAll(modsDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create mods directory: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(modsDir, ".install-"+name+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create install directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for path, data := range files {
+		target := filepath.Join(tmp, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return "", err
+		}
+	}
+	if sig != nil {
+		if err := mod.WriteSignature(tmp, sig); err != nil {
+			return "", err
+		}
+	}
+
+	dir := filepath.Join(modsDir, name)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to remove installed version: %w", err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", fmt.Errorf("failed to install: %w", err)
+	}
+	return dir, nil
+}
+
+// TakeInstalled returns the directories of mods installed since the last
+// call, for the game to load.
+func (mb *ModBrowser) TakeInstalled() []string {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	dirs := mb.installedDirs
+	mb.installedDirs = nil
+	return dirs
+}
+
 // AutoUpdate checks for and installs updates for all installed mods.
 func (mb *ModBrowser) AutoUpdate() error {
 	mb.mu.Lock()
@@ -333,12 +460,16 @@ func (mb *ModBrowser) Update(im *input.Manager) error {
 		return nil
 	}
 
-	// Auto-check for updates every 30 minutes
-	mb.mu.RLock()
-	timeSinceCheck := time.Since(mb.autoUpdateCheck)
-	mb.mu.RUnlock()
+	// Auto-check for updates every 30 minutes. The check time is set
+	// here so frames don't start another check while one runs.
+	mb.mu.Lock()
+	due := time.Since(mb.autoUpdateCheck) > 30*time.Minute
+	if due {
+		mb.autoUpdateCheck = time.Now()
+	}
+	mb.mu.Unlock()
 
-	if timeSinceCheck > 30*time.Minute {
+	if due {
 		go mb.CheckForUpdates()
 	}
 
@@ -415,7 +546,48 @@ func (mb *ModBrowser) Refresh() {
 	go mb.RefreshModList()
 }
 
-// Draw renders the mod browser UI.
+// BeginSearch starts editing the search query.
+func (mb *ModBrowser) BeginSearch() {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	if mb.state == ModBrowserStateBrowse {
+		mb.searching = true
+	}
+}
+
+// IsSearching returns whether the search query is being edited.
+func (mb *ModBrowser) IsSearching() bool {
+	mb.mu.RLock()
+	defer mb.mu.RUnlock()
+	return mb.searching
+}
+
+// EditSearch appends typed text to the search query, after removing its
+// last character if backspace was pressed.
+func (mb *ModBrowser) EditSearch(typed string, backspace bool) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	if backspace && len(mb.searchQuery) > 0 {
+		mb.searchQuery = mb.searchQuery[:len(mb.searchQuery)-1]
+	}
+	mb.searchQuery += typed
+	if len(mb.searchQuery) > maxSearchQueryLength {
+		mb.searchQuery = mb.searchQuery[:maxSearchQueryLength]
+	}
+}
+
+// EndSearch stops editing the search query, and searches for it if apply
+// is set.
+func (mb *ModBrowser) EndSearch(apply bool) {
+	mb.mu.Lock()
+	mb.searching = false
+	mb.mu.Unlock()
+	if apply {
+		mb.Refresh()
+	}
+}
+
+// Draw renders the mod browser over the whole screen.
 func (mb *ModBrowser) Draw(screen *ebiten.Image) {
 	if !mb.visible {
 		return
@@ -424,108 +596,153 @@ func (mb *ModBrowser) Draw(screen *ebiten.Image) {
 	mb.mu.RLock()
 	defer mb.mu.RUnlock()
 
-	// Semi-transparent background
-	vector.DrawFilledRect(screen, 50, 50, 700, 500, color.RGBA{0, 0, 0, 200}, false)
-	vector.StrokeRect(screen, 50, 50, 700, 500, 2, color.RGBA{100, 100, 100, 255}, false)
-
-	// Title
-	title := "Mod Browser"
-	text.Draw(screen, title, basicfont.Face7x13, 60, 70, color.White)
+	bounds := screen.Bounds()
+	screenWidth := float32(bounds.Dx())
+	screenHeight := float32(bounds.Dy())
+	centerX := screenWidth / 2
 
-	// Update count display
-	if len(mb.updateAvailable) > 0 {
-		updateText := fmt.Sprintf("(%d updates available)", len(mb.updateAvailable))
-		text.Draw(screen, updateText, basicfont.Face7x13, 200, 70, color.RGBA{255, 200, 0, 255})
-	}
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{0, 0, 0, 230}, false)
+	drawCenteredLabel(screen, centerX, 25, "MOD BROWSER", color.RGBA{180, 100, 255, 255})
 
-	// Error message
-	if mb.errorMessage != "" && time.Since(mb.errorTime) < 5*time.Second {
-		text.Draw(screen, mb.errorMessage, basicfont.Face7x13, 60, 90, color.RGBA{255, 0, 0, 255})
+	// Search query, or the number of updates when there's no query
+	subtitle := ""
+	switch {
+	case mb.searching:
+		subtitle = "Search: " + mb.searchQuery + "_"
+	case mb.searchQuery != "":
+		subtitle = "Search: " + mb.searchQuery
+	case len(mb.updateAvailable) > 0:
+		subtitle = fmt.Sprintf("%d updates available", len(mb.updateAvailable))
 	}
+	drawCenteredLabel(screen, centerX, 47, subtitle, color.RGBA{255, 200, 0, 255})
 
+	hintY := screenHeight - 15
 	switch mb.state {
 	case ModBrowserStateBrowse:
-		mb.drawBrowse(screen)
+		mb.drawBrowse(screen, screenWidth, hintY)
 	case ModBrowserStateDetails:
-		mb.drawDetails(screen)
+		mb.drawDetails(screen, screenWidth, hintY)
 	case ModBrowserStateInstalling, ModBrowserStateUpdating:
-		mb.drawProgress(screen)
+		drawCenteredLabel(screen, centerX, screenHeight/2, mb.installProgress, color.RGBA{255, 255, 255, 255})
+	}
+
+	if mb.errorMessage != "" && time.Since(mb.errorTime) < 5*time.Second {
+		drawCenteredLabel(screen, centerX, hintY-18, clipLabel(mb.errorMessage, screenWidth-20), color.RGBA{255, 70, 70, 255})
 	}
 }
 
-func (mb *ModBrowser) drawBrowse(screen *ebiten.Image) {
-	y := 110
-	visibleCount := 10
-	for i := mb.scrollOffset; i < mb.scrollOffset+visibleCount && i < len(mb.mods); i++ {
-		mod := mb.mods[i]
+// drawBrowse draws the list of registry mods, scrolled to keep the
+// selection in view.
+func (mb *ModBrowser) drawBrowse(screen *ebiten.Image, screenWidth, hintY float32) {
+	const startY, rowHeight = float32(70), float32(16)
+	rows := int((hintY - 24 - startY) / rowHeight)
+	if rows < 1 {
+		rows = 1
+	}
+	first := mb.scrollOffset
+	if mb.selectedIndex >= first+rows {
+		first = mb.selectedIndex - rows + 1
+	}
+
+	if len(mb.mods) == 0 {
+		drawCenteredLabel(screen, screenWidth/2, startY+rowHeight, "No mods found", color.RGBA{150, 150, 150, 255})
+	}
+	for i := first; i < first+rows && i < len(mb.mods); i++ {
+		record := mb.mods[i]
+		y := startY + float32(i-first)*rowHeight
 
 		fg := color.RGBA{255, 255, 255, 255}
 		if i == mb.selectedIndex {
-			// Highlight selected
-			vector.DrawFilledRect(screen, 60, float32(y-10), 680, 20, color.RGBA{50, 50, 150, 255}, false)
+			vector.DrawFilledRect(screen, 10, y-11, screenWidth-20, rowHeight-2, color.RGBA{80, 60, 120, 150}, false)
 			fg = color.RGBA{255, 255, 0, 255}
 		}
 
-		// Mod name and version
-		line := fmt.Sprintf("%s v%s - %s", mod.Name, mod.Version, mod.Author)
-		text.Draw(screen, line, basicfont.Face7x13, 65, y, fg)
-
-		// Update indicator
-		if newVer, hasUpdate := mb.updateAvailable[mod.Name]; hasUpdate {
-			updateLabel := fmt.Sprintf(" [UPDATE: v%s]", newVer)
-			text.Draw(screen, updateLabel, basicfont.Face7x13, 400, y, color.RGBA{0, 255, 0, 255})
-		}
-
-		// Installed indicator
-		if installedVer, installed := mb.installedMods[mod.Name]; installed && installedVer == mod.Version {
-			text.Draw(screen, " [INSTALLED]", basicfont.Face7x13, 550, y, color.RGBA{100, 255, 100, 255})
+		badge, badgeColor := mb.installBadge(record)
+		line := fmt.Sprintf("%s v%s", record.Name, record.Version)
+		drawLabel(screen, 15, y, clipLabel(line, screenWidth-40-float32(len(badge)*7)), fg)
+		if badge != "" {
+			drawLabel(screen, screenWidth-15-float32(len(badge)*7), y, badge, badgeColor)
 		}
+	}
 
-		y += 22
+	hint := "Enter details, / search, R refresh, ESC back"
+	if len(mb.updateAvailable) > 0 {
+		hint = "Enter details, / search, U update all, ESC back"
 	}
+	if mb.searching {
+		hint = "Type to search, Enter apply, ESC cancel"
+	}
+	drawCenteredLabel(screen, screenWidth/2, hintY, clipLabel(hint, screenWidth), color.RGBA{150, 150, 150, 255})
+}
 
-	// Controls hint
-	controls := "Up/Down: Navigate | Enter: Details | R: Refresh | Esc: Close"
-	text.Draw(screen, controls, basicfont.Face7x13, 60, 530, color.RGBA{150, 150, 150, 255})
+// installBadge returns the badge shown for a mod that is installed or has
+// an update.
+func (mb *ModBrowser) installBadge(record registry.ModRecord) (string, color.RGBA) {
+	if newVer, ok := mb.updateAvailable[record.Name]; ok && newVer == record.Version {
+		return "[UPDATE]", color.RGBA{255, 220, 80, 255}
+	}
+	if installedVer, ok := mb.installedMods[record.Name]; ok && installedVer == record.Version {
+		return "[INSTALLED]", color.RGBA{100, 255, 100, 255}
+	}
+	return "", color.RGBA{}
 }
 
-func (mb *ModBrowser) drawDetails(screen *ebiten.Image) {
+// drawDetails draws the selected mod's registry record.
+func (mb *ModBrowser) drawDetails(screen *ebiten.Image, screenWidth, hintY float32) {
 	if mb.selectedIndex >= len(mb.mods) {
 		return
 	}
 
-	mod := mb.mods[mb.selectedIndex]
-	y := 110
-
-	// Details
+	record := mb.mods[mb.selectedIndex]
+	signed := "Unsigned"
+	if record.Signature != nil {
+		signed = "Signed by " + record.Signature.Author
+	}
+	rating := "Not rated yet"
+	if record.Ratings > 0 {
+		rating = fmt.Sprintf("Rated %.1f/5 by %d", record.Rating, record.Ratings)
+	}
 	details := []string{
-		fmt.Sprintf("Name: %s", mod.Name),
-		fmt.Sprintf("Version: %s", mod.Version),
-		fmt.Sprintf("Author: %s", mod.Author),
-		fmt.Sprintf("Size: %.2f MB", float64(mod.Size)/1024/1024),
-		fmt.Sprintf("Downloads: %d", mod.Downloads),
-		fmt.Sprintf("Description: %s", mod.Description),
+		fmt.Sprintf("%s v%s", record.Name, record.Version),
+		"Author: " + record.Author,
+		fmt.Sprintf("Size: %.2f MB, %d downloads (%d total)", float64(record.Size)/1024/1024, record.Downloads, record.TotalDownloads),
+		rating,
+		signed,
+		record.Description,
 	}
 
+	y := float32(70)
 	for _, line := range details {
-		text.Draw(screen, line, basicfont.Face7x13, 65, y, color.White)
-		y += 20
+		drawLabel(screen, 15, y, clipLabel(line, screenWidth-30), color.RGBA{255, 255, 255, 255})
+		y += 16
 	}
 
-	// Install button hint
-	installHint := "Enter: Install | Esc: Back"
-	if installedVer, installed := mb.installedMods[mod.Name]; installed && installedVer == mod.Version {
-		installHint = "Already installed | Esc: Back"
+	hint := "Enter install, ESC back"
+	if installedVer, installed := mb.installedMods[record.Name]; installed && installedVer == record.Version {
+		hint = "Installed. Enter reinstall, ESC back"
+	} else if installed {
+		hint = "Enter update from v" + installedVer + ", ESC back"
 	}
-	text.Draw(screen, installHint, basicfont.Face7x13, 60, 530, color.RGBA{150, 150, 150, 255})
+	drawCenteredLabel(screen, screenWidth/2, hintY, clipLabel(hint, screenWidth), color.RGBA{150, 150, 150, 255})
 }
 
-func (mb *ModBrowser) drawProgress(screen *ebiten.Image) {
-	text.Draw(screen, mb.installProgress, basicfont.Face7x13, 65, 300, color.White)
+// clipLabel shortens a label to fit a width in pixels.
+func clipLabel(label string, width float32) string {
+	maxChars := int(width / 7)
+	if len(label) <= maxChars {
+		return label
+	}
+	if maxChars < 3 {
+		return ""
+	}
+	return label[:maxChars-3] + "..."
+}
 
-	// Simple progress animation
-	dots := "..."
-	text.Draw(screen, dots, basicfont.Face7x13, 300, 300, color.RGBA{100, 100, 100, 255})
+// setProgress sets the install progress message.
+func (mb *ModBrowser) setProgress(msg string) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.installProgress = msg
 }
 
 func (mb *ModBrowser) setError(msg string) {
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -187,7 +190,7 @@ func TestDownloadMod(t *testing.T) {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 		}
 
-		w.Header().Set("X-SHA256", expectedChecksum)
+		w.Header().Set("X-Mod-SHA256", expectedChecksum)
 		w.Write(wasmData)
 	}))
 	defer server.Close()
@@ -208,7 +211,7 @@ func TestDownloadMod(t *testing.T) {
 
 func TestDownloadModNoChecksum(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// No X-SHA256 header
+		// No X-Mod-SHA256 header
 		w.Write([]byte("data"))
 	}))
 	defer server.Close()
@@ -238,7 +241,7 @@ func TestInstallModChecksumMismatch(t *testing.T) {
 	wrongChecksum := "0000000000000000000000000000000000000000000000000000000000000000"
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("X-SHA256", wrongChecksum)
+		w.Header().Set("X-Mod-SHA256", wrongChecksum)
 		w.Write(wasmData)
 	}))
 	defer server.Close()
@@ -250,6 +253,108 @@ func TestInstallModChecksumMismatch(t *testing.T) {
 	}
 }
 
+// fakeRegistry serves one mod version the way cmd/mod-registry does, with
+// listed as the checksum in its search results.
+func fakeRegistry(t *testing.T, wasmData []byte, listed string, sig *mod.PackageSignature) *httptest.Server {
+	t.Helper()
+	manifest := []byte(`{"name": "test-mod", "version": "1.1.0", "author": "testauthor", "description": "Test mod"}`)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"results": []registry.ModRecord{
+					{Name: "test-mod", Version: "1.1.0", Author: "testauthor", SHA256: listed, Signature: sig},
+				},
+			})
+		case "/download/test-mod/1.1.0":
+			w.Header().Set("X-Mod-SHA256", mod.ComputeSHA256(wasmData))
+			w.Write(wasmData)
+		case "/manifest/test-mod/1.1.0":
+			w.Write(manifest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestInstallMod(t *testing.T) {
+	wasmData := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	sig := &mod.PackageSignature{Author: "testauthor", Key: "key", Signature: "sig"}
+	server := fakeRegistry(t, wasmData, mod.ComputeSHA256(wasmData), sig)
+	defer server.Close()
+
+	modsDir := t.TempDir()
+	// An installed older version is replaced
+	if err := os.MkdirAll(filepath.Join(modsDir, "test-mod"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(modsDir, "test-mod", "stale.txt"), []byte("old"), 0o644)
+
+	mb := NewModBrowser(server.URL)
+	mb.SetModsDir(modsDir)
+	mb.SetInstalledMods(map[string]string{"test-mod": "1.0.0"})
+	if err := mb.InstallMod("test-mod", "1.1.0"); err != nil {
+		t.Fatalf("InstallMod failed: %v", err)
+	}
+
+	dir := filepath.Join(modsDir, "test-mod")
+	manifest, err := mod.LoadManifestFromDir(dir)
+	if err != nil || manifest.Version != "1.1.0" {
+		t.Fatalf("installed manifest = %+v, err = %v", manifest, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, registry.DefaultEntryPoint)); err != nil || len(data) != len(wasmData) {
+		t.Errorf("installed module: %d bytes, err = %v", len(data), err)
+	}
+	if got, err := mod.ReadSignature(dir); err != nil || got == nil || got.Author != "testauthor" {
+		t.Errorf("installed signature = %+v, err = %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stale.txt")); !os.IsNotExist(err) {
+		t.Error("files of the old version were left behind")
+	}
+
+	if dirs := mb.TakeInstalled(); len(dirs) != 1 || dirs[0] != dir {
+		t.Errorf("TakeInstalled = %v", dirs)
+	}
+	if dirs := mb.TakeInstalled(); len(dirs) != 0 {
+		t.Errorf("second TakeInstalled = %v", dirs)
+	}
+}
+
+func TestInstallModListedChecksumMismatch(t *testing.T) {
+	wasmData := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	server := fakeRegistry(t, wasmData, "0000", nil)
+	defer server.Close()
+
+	modsDir := t.TempDir()
+	mb := NewModBrowser(server.URL)
+	mb.SetModsDir(modsDir)
+	if err := mb.InstallMod("test-mod", "1.1.0"); err == nil {
+		t.Fatal("expected error when the listed checksum differs")
+	}
+	if entries, _ := os.ReadDir(modsDir); len(entries) != 0 {
+		t.Errorf("failed install left %d entries in the mods directory", len(entries))
+	}
+}
+
+func TestModBrowserSearchEditing(t *testing.T) {
+	mb := NewModBrowser("http://test")
+	mb.BeginSearch()
+	if !mb.IsSearching() {
+		t.Fatal("expected search editing to start")
+	}
+	mb.EditSearch("weapons", false)
+	mb.EditSearch("", true)
+	mb.EditSearch(strings.Repeat("x", 100), false)
+	if len(mb.searchQuery) != maxSearchQueryLength || !strings.HasPrefix(mb.searchQuery, "weapon") ||
+		strings.HasPrefix(mb.searchQuery, "weapons") {
+		t.Errorf("searchQuery = %q", mb.searchQuery)
+	}
+	mb.EndSearch(false)
+	if mb.IsSearching() {
+		t.Error("expected search editing to end")
+	}
+}
+
 func TestSetInstalledMods(t *testing.T) {
 	mb := NewModBrowser("http://test")
 	installed := map[string]string{
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"image/color"
 	"sync/atomic"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text"
@@ -28,6 +29,25 @@ type HUD struct {
 	theme       *Theme
 	Message     string
 	MessageTime int
+	SquadOrder  string           // Current squad order, shown above the squad list
+	Squad       []SquadIndicator // Companion status, top-left; empty hides it
+
+	// Current weapon's magazine; ClipSize 0 (melee) shows only Ammo.
+	Clip     int
+	ClipSize int
+	Reload   float64 // Reload progress from 0 to 1; 0 when not reloading
+
+	// Carried weight against carry capacity; Capacity 0 hides the load.
+	Load     float64
+	Capacity float64
+}
+
+// SquadIndicator is one companion's entry in the HUD squad list.
+type SquadIndicator struct {
+	Name         string
+	Health       float64
+	MaxHealth    float64
+	AbilityReady bool
 }
 
 // MenuType represents different menu screens.
@@ -170,8 +190,9 @@ func DrawHUD(screen *ebiten.Image, h *HUD) {
 	// Bottom-center: Ammo and Weapon
 	centerX := screenWidth / 2
 	ammoBarW := screenWidth * 0.25 // ~80px at 320
-	drawStatusBar(screen, centerX-ammoBarW/2, screenHeight-20, ammoBarW, barHeight, h.Ammo, h.MaxAmmo, h.theme.AmmoColor, h.theme.BarBG, h.theme.BarBorder)
-	drawLabel(screen, centerX-ammoBarW/2, screenHeight-24, "AMMO", h.theme.TextColor)
+	ammo, maxAmmo, ammoLabel := h.ammoGauge()
+	drawStatusBar(screen, centerX-ammoBarW/2, screenHeight-20, ammoBarW, barHeight, ammo, maxAmmo, h.theme.AmmoColor, h.theme.BarBG, h.theme.BarBorder)
+	drawLabel(screen, centerX-ammoBarW/2, screenHeight-24, ammoLabel, h.theme.TextColor)
 	drawLabel(screen, centerX-ammoBarW/2, screenHeight-4, h.WeaponName, h.theme.TextColor)
 
 	// Bottom-right: Keycards
@@ -183,11 +204,64 @@ func DrawHUD(screen *ebiten.Image, h *HUD) {
 	}
 	drawLabel(screen, keycardX, screenHeight-34, "KEYS", h.theme.TextColor)
 
+	// Bottom-right, above the keycards: carried load, red when over capacity
+	if label, over := h.loadLabel(); label != "" {
+		loadColor := h.theme.TextColor
+		if over {
+			loadColor = h.theme.HealthColor
+		}
+		drawLabel(screen, keycardX, screenHeight-48, label, loadColor)
+	}
+
 	// Center message (above HUD)
 	if h.MessageTime > 0 && h.Message != "" {
 		msgX := centerX - float32(len(h.Message)*7/2)
 		drawLabel(screen, msgX, screenHeight-55, h.Message, h.theme.TextColor)
 	}
+
+	// Top-left: Squad order and companions
+	drawSquadIndicators(screen, padding, h)
+}
+
+// ammoGauge returns what the ammo bar shows: the magazine against its
+// size, labeled with the reserve, or the reload's progress while
+// reloading. Weapons without a magazine show the reserve.
+func (h *HUD) ammoGauge() (current, max int, label string) {
+	switch {
+	case h.Reload > 0:
+		return int(h.Reload * 100), 100, "RELOADING"
+	case h.ClipSize > 0:
+		return h.Clip, h.ClipSize, fmt.Sprintf("AMMO %d/%d", h.Clip, h.Ammo)
+	default:
+		return h.Ammo, h.MaxAmmo, "AMMO"
+	}
+}
+
+// loadLabel returns the carried load against capacity and whether it is
+// over capacity, or "" when encumbrance is off.
+func (h *HUD) loadLabel() (string, bool) {
+	if h.Capacity <= 0 {
+		return "", false
+	}
+	return fmt.Sprintf("LOAD %.0f/%.0f", h.Load, h.Capacity), h.Load > h.Capacity
+}
+
+// drawSquadIndicators renders the squad order and a health bar per companion,
+// with a marker when their ability is ready.
+func drawSquadIndicators(screen *ebiten.Image, x float32, h *HUD) {
+	if len(h.Squad) == 0 {
+		return
+	}
+	y := float32(14)
+	drawLabel(screen, x, y, "SQUAD: "+h.SquadOrder, h.theme.TextColor)
+	for _, m := range h.Squad {
+		y += 14
+		drawLabel(screen, x, y, m.Name, h.theme.TextColor)
+		drawStatusBar(screen, x+50, y-7, 40, 5, int(m.Health), int(m.MaxHealth), h.theme.HealthColor, h.theme.BarBG, h.theme.BarBorder)
+		if m.AbilityReady {
+			vector.DrawFilledCircle(screen, x+97, y-5, 3, h.theme.AmmoColor, false)
+		}
+	}
 }
 
 // drawStatusBar renders a horizontal status bar.
@@ -252,6 +326,7 @@ func NewMenuManager() *MenuManager {
 	mm.menuItems[MenuTypeMain] = []string{
 		"New Game",
 		"Load Game",
+		"Statistics",
 		"Settings",
 		"Quit",
 	}
@@ -1206,6 +1281,10 @@ func drawCraftingScrapInventory(screen *ebiten.Image, state *CraftingState, cent
 	return scrapY
 }
 
+// craftingVisibleRecipes is how many recipes the crafting screen lists at
+// once.
+const craftingVisibleRecipes = 10
+
 // drawCraftingRecipesList renders the list of available recipes with highlighting.
 func drawCraftingRecipesList(screen *ebiten.Image, state *CraftingState, centerX, startY float32) {
 	itemHeight := float32(25)
@@ -1215,9 +1294,14 @@ func drawCraftingRecipesList(screen *ebiten.Image, state *CraftingState, centerX
 		return
 	}
 
+	// Scroll long lists to keep the selection in view.
+	first := max(0, min(state.Selected-craftingVisibleRecipes/2, len(state.Recipes)-craftingVisibleRecipes))
+	last := min(first+craftingVisibleRecipes, len(state.Recipes))
+
 	theme := currentTheme.Load()
-	for i, recipe := range state.Recipes {
-		itemY := startY + float32(i)*itemHeight
+	for i := first; i < last; i++ {
+		recipe := state.Recipes[i]
+		itemY := startY + float32(i-first)*itemHeight
 
 		if i == state.Selected {
 			highlightX := centerX - 180
@@ -1274,6 +1358,8 @@ func (mm *MenuManager) Select() string {
 			return "new_game"
 		case "Load Game":
 			return "load_game"
+		case "Statistics":
+			return "stats"
 		case "Settings":
 			return "settings"
 		case "Quit":
@@ -1720,6 +1806,9 @@ type ModInfo struct {
 	Description string
 	Author      string
 	Enabled     bool
+	Error       string // Why an enabled mod can't load; empty if it loads
+	Trust       string // "signed", "unsigned" or "blocked"
+	TrustNote   string // Why the mod isn't signed or is blocked
 }
 
 // ModsState holds the mods screen display state.
@@ -1774,16 +1863,48 @@ func DrawMods(screen *ebiten.Image, state *ModsState) {
 			if !mod.Enabled {
 				statusColor = color.RGBA{255, 100, 100, 255}
 				statusText = "[OFF]"
+			} else if mod.Error != "" {
+				statusColor = color.RGBA{255, 180, 60, 255}
+				statusText = "[CAN'T LOAD]"
 			}
 
 			modText := fmt.Sprintf("%s v%s - %s %s", mod.Name, mod.Version, mod.Author, statusText)
 			drawLabel(screen, 30, y+12, modText, statusColor)
+
+			if badge, badgeColor := modTrustBadge(mod.Trust); badge != "" {
+				drawLabel(screen, screenWidth-30-float32(len(badge)*7), y+12, badge, badgeColor)
+			}
 		}
 	}
 
-	// Draw controls hint
+	// Explain why the selected mod can't load or isn't trusted
 	hintY := screenHeight - 40
-	drawCenteredLabel(screen, centerX, hintY, "↑/↓ select, Enter toggle, ESC back", color.RGBA{150, 150, 150, 255})
+	if state.Selected >= 0 && state.Selected < len(state.Mods) {
+		mod := state.Mods[state.Selected]
+		if mod.Enabled && mod.Error != "" {
+			drawCenteredLabel(screen, centerX, hintY-22, mod.Error, color.RGBA{255, 180, 60, 255})
+		} else if mod.TrustNote != "" {
+			_, noteColor := modTrustBadge(mod.Trust)
+			drawCenteredLabel(screen, centerX, hintY-22, mod.TrustNote, noteColor)
+		}
+	}
+
+	// Draw controls hint
+	drawCenteredLabel(screen, centerX, hintY, "↑/↓ select, Enter toggle, B browse, ESC back", color.RGBA{150, 150, 150, 255})
+}
+
+// modTrustBadge returns the badge and color the mods screen shows for a
+// mod's trust level.
+func modTrustBadge(trust string) (string, color.RGBA) {
+	switch trust {
+	case "signed":
+		return "[SIGNED]", color.RGBA{100, 200, 255, 255}
+	case "unsigned":
+		return "[UNSIGNED]", color.RGBA{255, 220, 80, 255}
+	case "blocked":
+		return "[BLOCKED]", color.RGBA{255, 70, 70, 255}
+	}
+	return "", color.RGBA{}
 }
 
 // MultiplayerMode represents a multiplayer game mode.
@@ -1794,6 +1915,20 @@ type MultiplayerMode struct {
 	MaxPlayers  int
 }
 
+// ServerRow is one server in the multiplayer server browser.
+type ServerRow struct {
+	Name       string
+	Address    string
+	Mode       string
+	Genre      string
+	Region     string
+	Players    int
+	MaxPlayers int
+	PingMS     int // Round trip in milliseconds; -1 = not pinged yet, -2 = unreachable
+	Favorite   bool
+	Verified   bool // The hub checked the server's signed announcement
+}
+
 // MultiplayerState holds the multiplayer lobby display state.
 type MultiplayerState struct {
 	Modes      []MultiplayerMode
@@ -1801,6 +1936,14 @@ type MultiplayerState struct {
 	Connected  bool
 	ServerAddr string
 	StatusMsg  string
+
+	// Server browser, shown instead of the modes when Browsing
+	Browsing       bool
+	Servers        []ServerRow
+	ServerSelected int
+	FilterText     string // Active filters and sort order
+	AddressInput   string // Address typed for direct connect
+	AddressActive  bool   // The direct connect field has focus
 }
 
 // DrawMultiplayer renders the multiplayer lobby screen.
@@ -1833,6 +1976,11 @@ func DrawMultiplayer(screen *ebiten.Image, state *MultiplayerState) {
 	}
 	drawCenteredLabel(screen, centerX, statusY, statusText, statusColor)
 
+	if state.Browsing {
+		drawServerBrowser(screen, state, statusY+22)
+		return
+	}
+
 	// Draw game modes
 	modesY := statusY + 30
 	drawCenteredLabel(screen, centerX, modesY, "GAME MODES", color.RGBA{200, 200, 200, 255})
@@ -1866,3 +2014,503 @@ func DrawMultiplayer(screen *ebiten.Image, state *MultiplayerState) {
 	hintY := screenHeight - 40
 	drawCenteredLabel(screen, centerX, hintY, "↑/↓ select, Enter join, ESC back", color.RGBA{150, 150, 150, 255})
 }
+
+// serverRowHeight is the height of a server browser row.
+const serverRowHeight = float32(14)
+
+// drawServerBrowser renders the server list, filters and direct connect
+// field below the connection status.
+func drawServerBrowser(screen *ebiten.Image, state *MultiplayerState, top float32) {
+	bounds := screen.Bounds()
+	screenWidth := float32(bounds.Dx())
+	screenHeight := float32(bounds.Dy())
+	centerX := screenWidth / 2
+
+	drawCenteredLabel(screen, centerX, top, state.FilterText, color.RGBA{180, 180, 220, 255})
+
+	// Direct connect field
+	addrY := top + 16
+	fieldColor := color.RGBA{40, 40, 60, 200}
+	if state.AddressActive {
+		fieldColor = color.RGBA{60, 80, 120, 220}
+	}
+	vector.DrawFilledRect(screen, 20, addrY-10, screenWidth-40, 13, fieldColor, false)
+	addrText := "Connect to: " + state.AddressInput
+	if state.AddressActive {
+		addrText += "_"
+	}
+	drawLabel(screen, 24, addrY, addrText, color.RGBA{220, 220, 220, 255})
+
+	// Server rows, scrolled to keep the selection in view
+	listY := addrY + 16
+	hintY := screenHeight - 20
+	msgHeight := float32(0)
+	if state.StatusMsg != "" {
+		msgHeight = serverRowHeight
+	}
+	rows := int((hintY - msgHeight - listY) / serverRowHeight)
+	if rows < 1 {
+		rows = 1
+	}
+	first := 0
+	if state.ServerSelected >= rows {
+		first = state.ServerSelected - rows + 1
+	}
+
+	if len(state.Servers) == 0 {
+		drawCenteredLabel(screen, centerX, listY+serverRowHeight, "No servers match", color.RGBA{150, 150, 150, 255})
+	}
+	for i := first; i < len(state.Servers) && i < first+rows; i++ {
+		srv := state.Servers[i]
+		y := listY + float32(i-first)*serverRowHeight
+		nameColor := color.RGBA{200, 200, 255, 255}
+		if i == state.ServerSelected && !state.AddressActive {
+			vector.DrawFilledRect(screen, 20, y-10, screenWidth-40, serverRowHeight-1, color.RGBA{60, 80, 120, 150}, false)
+			nameColor = color.RGBA{255, 255, 255, 255}
+		}
+		star := " "
+		if srv.Favorite {
+			star = "*"
+		}
+		label := fmt.Sprintf("%s%s", star, srv.Name)
+		drawLabel(screen, 24, y, label, nameColor)
+		if srv.Verified {
+			drawVerifiedBadge(screen, 24+float32(len(label)+1)*7, y)
+		}
+		details := fmt.Sprintf("%s %s %s %d/%d", srv.Mode, srv.Genre, srv.Region, srv.Players, srv.MaxPlayers)
+		drawLabel(screen, screenWidth*0.4, y, details, color.RGBA{170, 170, 190, 255})
+		drawLabel(screen, screenWidth-70, y, pingLabel(srv.PingMS), pingColor(srv.PingMS))
+	}
+
+	if state.StatusMsg != "" {
+		drawCenteredLabel(screen, centerX, hintY-serverRowHeight, state.StatusMsg, color.RGBA{255, 255, 100, 255})
+	}
+	drawCenteredLabel(screen, centerX, hintY, "Enter join, F favorite, 1-4 filter, V favorites, O sort, Tab address, R refresh, P replay", color.RGBA{150, 150, 150, 255})
+}
+
+// drawVerifiedBadge draws a green check mark with its baseline at y.
+func drawVerifiedBadge(screen *ebiten.Image, x, y float32) {
+	badge := color.RGBA{100, 255, 100, 255}
+	vector.StrokeLine(screen, x, y-4, x+3, y-1, 2, badge, false)
+	vector.StrokeLine(screen, x+3, y-1, x+8, y-8, 2, badge, false)
+}
+
+// pingLabel formats a server row's ping.
+func pingLabel(ms int) string {
+	switch {
+	case ms == -2:
+		return "timeout"
+	case ms < 0:
+		return "..."
+	default:
+		return fmt.Sprintf("%dms", ms)
+	}
+}
+
+// pingColor grades a ping green, yellow or red.
+func pingColor(ms int) color.RGBA {
+	switch {
+	case ms < 0:
+		return color.RGBA{150, 150, 150, 255}
+	case ms < 80:
+		return color.RGBA{100, 255, 100, 255}
+	case ms < 200:
+		return color.RGBA{255, 220, 100, 255}
+	default:
+		return color.RGBA{255, 100, 100, 255}
+	}
+}
+
+// SpectatorState holds the display state of a dead player spectating.
+type SpectatorState struct {
+	Mode            string  // "kill-cam", "follow" or "free"
+	TargetName      string  // Player being followed
+	KillerName      string  // Empty after a suicide
+	KillCamProgress float64 // 0 to 1 through the kill-cam replay
+	RespawnIn       float64 // Seconds until respawn
+	Hint            string  // Controls line along the bottom, if any
+}
+
+// DrawSpectator renders the spectator overlay: letterbox bars during the
+// kill-cam, who is being watched and the respawn countdown.
+func DrawSpectator(screen *ebiten.Image, state *SpectatorState) {
+	if state == nil {
+		return
+	}
+
+	bounds := screen.Bounds()
+	screenWidth := float32(bounds.Dx())
+	screenHeight := float32(bounds.Dy())
+	centerX := screenWidth / 2
+	barHeight := float32(22)
+
+	if state.Mode == "kill-cam" {
+		bar := color.RGBA{0, 0, 0, 255}
+		vector.DrawFilledRect(screen, 0, 0, screenWidth, barHeight, bar, false)
+		vector.DrawFilledRect(screen, 0, screenHeight-barHeight, screenWidth, barHeight, bar, false)
+		progress := float32(state.KillCamProgress)
+		if progress < 0 {
+			progress = 0
+		} else if progress > 1 {
+			progress = 1
+		}
+		vector.DrawFilledRect(screen, 0, screenHeight-barHeight, screenWidth*progress, 2, color.RGBA{200, 40, 40, 255}, false)
+
+		title := "KILL CAM"
+		if state.KillerName != "" {
+			title = fmt.Sprintf("KILL CAM - killed by %s", state.KillerName)
+		}
+		drawCenteredLabel(screen, centerX, 15, title, color.RGBA{255, 90, 90, 255})
+		if state.Hint != "" {
+			drawCenteredLabel(screen, centerX, screenHeight-7, state.Hint, color.RGBA{150, 150, 150, 255})
+		}
+		return
+	}
+
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, barHeight, color.RGBA{0, 0, 0, 150}, false)
+	title := "SPECTATING - free camera"
+	if state.Mode == "follow" && state.TargetName != "" {
+		title = fmt.Sprintf("SPECTATING %s", state.TargetName)
+	}
+	drawCenteredLabel(screen, centerX, 15, title, color.RGBA{200, 200, 255, 255})
+
+	if state.RespawnIn > 0 {
+		drawCenteredLabel(screen, centerX, screenHeight/2+30, fmt.Sprintf("Respawn in %.1f", state.RespawnIn), color.RGBA{255, 255, 100, 255})
+	}
+	if state.Hint != "" {
+		drawCenteredLabel(screen, centerX, screenHeight-10, state.Hint, color.RGBA{150, 150, 150, 255})
+	}
+}
+
+// ReplayState holds the display state of match replay playback.
+type ReplayState struct {
+	Tick     uint64
+	Elapsed  time.Duration // Match time at the tick shown
+	Duration time.Duration // Length of the whole match
+	Progress float64       // 0 to 1 through the replay
+	Paused   bool
+	Speed    float64
+	Hint     string // Controls line, if any
+}
+
+// DrawReplayControls renders the replay timeline, time and playback state
+// along the bottom of the screen.
+func DrawReplayControls(screen *ebiten.Image, state *ReplayState) {
+	if state == nil {
+		return
+	}
+
+	bounds := screen.Bounds()
+	screenWidth := float32(bounds.Dx())
+	screenHeight := float32(bounds.Dy())
+	panelY := screenHeight - 34
+
+	vector.DrawFilledRect(screen, 0, panelY, screenWidth, 34, color.RGBA{0, 0, 0, 180}, false)
+
+	// Timeline
+	barX, barW := float32(10), screenWidth-20
+	progress := float32(state.Progress)
+	if progress < 0 {
+		progress = 0
+	} else if progress > 1 {
+		progress = 1
+	}
+	vector.DrawFilledRect(screen, barX, panelY+4, barW, 4, color.RGBA{70, 70, 90, 255}, false)
+	vector.DrawFilledRect(screen, barX, panelY+4, barW*progress, 4, color.RGBA{100, 200, 255, 255}, false)
+	vector.DrawFilledRect(screen, barX+barW*progress-1, panelY+2, 3, 8, color.RGBA{255, 255, 255, 255}, false)
+
+	playState := "PLAY"
+	if state.Paused {
+		playState = "PAUSED"
+	}
+	status := fmt.Sprintf("%s %.2gx  %s / %s  tick %d", playState, state.Speed,
+		formatClock(state.Elapsed), formatClock(state.Duration), state.Tick)
+	drawLabel(screen, 10, panelY+20, status, color.RGBA{220, 220, 220, 255})
+	if state.Hint != "" {
+		drawLabel(screen, 10, panelY+31, state.Hint, color.RGBA{150, 150, 150, 255})
+	}
+}
+
+// formatClock formats a duration as m:ss.
+func formatClock(d time.Duration) string {
+	secs := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// VoiceIndicator is a player shown in the voice list.
+type VoiceIndicator struct {
+	Name     string
+	Speaking bool
+	Muted    bool
+}
+
+// DrawVoiceIndicators lists the players talking, and muted players, down
+// the right edge of the screen, each with a speaker icon: lit while they
+// talk, crossed out when muted.
+func DrawVoiceIndicators(screen *ebiten.Image, players []VoiceIndicator) {
+	screenWidth := float32(screen.Bounds().Dx())
+	y := float32(40)
+	for _, p := range players {
+		if !p.Speaking && !p.Muted {
+			continue
+		}
+		width := float32(len(p.Name)*7 + 22)
+		x := screenWidth - width - 6
+		vector.DrawFilledRect(screen, x, y, width, 16, color.RGBA{0, 0, 0, 150}, false)
+
+		icon := color.RGBA{100, 255, 120, 255}
+		nameColor := color.RGBA{230, 230, 230, 255}
+		if p.Muted {
+			icon = color.RGBA{140, 140, 140, 255}
+			nameColor = color.RGBA{150, 150, 150, 255}
+		}
+		drawSpeakerIcon(screen, x+4, y+4, icon)
+		if p.Muted {
+			vector.StrokeLine(screen, x+3, y+13, x+14, y+3, 1.5, color.RGBA{255, 80, 80, 255}, false)
+		}
+		drawLabel(screen, x+18, y+12, p.Name, nameColor)
+		y += 18
+	}
+}
+
+// drawSpeakerIcon draws an 8x8 speaker at (x, y).
+func drawSpeakerIcon(screen *ebiten.Image, x, y float32, c color.RGBA) {
+	vector.DrawFilledRect(screen, x, y+2, 3, 4, c, false)
+	vector.DrawFilledRect(screen, x+3, y+1, 2, 6, c, false)
+	vector.DrawFilledRect(screen, x+5, y, 2, 8, c, false)
+}
+
+// LevelSummaryState holds the end-of-level summary display state.
+type LevelSummaryState struct {
+	Depth int      // Level just cleared
+	Lines []string // Statistics, one per line
+}
+
+// DrawLevelSummary renders the end-of-level statistics screen. Lines that
+// don't fit above the controls hint are dropped.
+func DrawLevelSummary(screen *ebiten.Image, state *LevelSummaryState) {
+	if state == nil {
+		return
+	}
+
+	bounds := screen.Bounds()
+	screenWidth := float32(bounds.Dx())
+	screenHeight := float32(bounds.Dy())
+
+	// Draw semi-transparent overlay
+	overlay := color.RGBA{0, 0, 0, 210}
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, overlay, false)
+
+	centerX := screenWidth / 2
+
+	// Draw title
+	titleY := float32(25)
+	drawCenteredLabel(screen, centerX, titleY, fmt.Sprintf("LEVEL %d CLEARED", state.Depth), color.RGBA{255, 220, 120, 255})
+
+	lineHeight := float32(13)
+	hintY := screenHeight - 20
+	y := titleY + 22
+	for _, line := range state.Lines {
+		if y > hintY-lineHeight {
+			break
+		}
+		drawLabel(screen, 30, y, line, color.RGBA{220, 220, 220, 255})
+		y += lineHeight
+	}
+
+	// Draw controls hint
+	drawCenteredLabel(screen, centerX, hintY, "Fire or Use to continue", color.RGBA{150, 150, 150, 255})
+}
+
+// AchievementInfo is an achievement as listed on the stats screen.
+type AchievementInfo struct {
+	Name     string
+	Unlocked bool
+}
+
+// StatsScreenState holds the player's lifetime statistics display state.
+type StatsScreenState struct {
+	Lines        []string // One statistic per line, e.g. "Kills: 120"
+	Achievements []AchievementInfo
+}
+
+// DrawStatsScreen renders the player's lifetime statistics, with their
+// achievements beside them.
+func DrawStatsScreen(screen *ebiten.Image, state *StatsScreenState) {
+	if state == nil {
+		return
+	}
+
+	bounds := screen.Bounds()
+	screenWidth := float32(bounds.Dx())
+	screenHeight := float32(bounds.Dy())
+
+	// Draw semi-transparent overlay
+	overlay := color.RGBA{0, 0, 0, 210}
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, overlay, false)
+
+	centerX := screenWidth / 2
+
+	// Draw title
+	titleY := float32(25)
+	drawCenteredLabel(screen, centerX, titleY, "STATISTICS", color.RGBA{100, 200, 255, 255})
+
+	lineHeight := float32(13)
+	hintY := screenHeight - 20
+	startY := titleY + 22
+	y := startY
+	for _, line := range state.Lines {
+		if y > hintY-lineHeight {
+			break
+		}
+		drawLabel(screen, 20, y, line, color.RGBA{220, 220, 220, 255})
+		y += lineHeight
+	}
+
+	unlocked := 0
+	for _, a := range state.Achievements {
+		if a.Unlocked {
+			unlocked++
+		}
+	}
+	achievementsX := centerX + 10
+	drawLabel(screen, achievementsX, startY, fmt.Sprintf("Achievements %d/%d", unlocked, len(state.Achievements)), color.RGBA{255, 220, 120, 255})
+	y = startY + lineHeight
+	for _, a := range state.Achievements {
+		if y > hintY-lineHeight {
+			break
+		}
+		c := color.RGBA{110, 110, 130, 255}
+		if a.Unlocked {
+			c = color.RGBA{120, 255, 120, 255}
+		}
+		drawLabel(screen, achievementsX, y, a.Name, c)
+		y += lineHeight
+	}
+
+	// Draw controls hint
+	drawCenteredLabel(screen, centerX, hintY, "ESC to go back", color.RGBA{150, 150, 150, 255})
+}
+
+// SaveSlotInfo is a save slot as listed by the save browser.
+type SaveSlotInfo struct {
+	ID        int
+	Exists    bool
+	Timestamp time.Time
+	Genre     string
+	Depth     int
+	Playtime  time.Duration
+	Thumbnail *ebiten.Image // Map of the saved level; nil for none
+	Corrupt   bool          // Failed its integrity check
+	Locked    bool          // Encrypted under a passphrase that isn't set
+}
+
+// SaveBrowserState holds the save/load browser display state.
+type SaveBrowserState struct {
+	Saving    bool // Picking a slot to save to, rather than one to load
+	Slots     []SaveSlotInfo
+	Selected  int
+	StatusMsg string
+}
+
+// saveThumbnailSize is the side of the square the selected slot's
+// thumbnail is drawn in.
+const saveThumbnailSize = 64
+
+// DrawSaveBrowser renders the save slot list, with the selected slot's
+// thumbnail and details beside it.
+func DrawSaveBrowser(screen *ebiten.Image, state *SaveBrowserState) {
+	if state == nil {
+		return
+	}
+
+	bounds := screen.Bounds()
+	screenWidth := float32(bounds.Dx())
+	screenHeight := float32(bounds.Dy())
+
+	// Draw semi-transparent overlay
+	overlay := color.RGBA{0, 0, 0, 210}
+	vector.DrawFilledRect(screen, 0, 0, screenWidth, screenHeight, overlay, false)
+
+	centerX := screenWidth / 2
+
+	// Draw title
+	titleY := float32(25)
+	title := "LOAD GAME"
+	if state.Saving {
+		title = "SAVE GAME"
+	}
+	drawCenteredLabel(screen, centerX, titleY, title, color.RGBA{100, 200, 255, 255})
+
+	listX := float32(20)
+	detailX := centerX + 10
+	lineHeight := float32(13)
+	startY := titleY + 22
+	for i, slot := range state.Slots {
+		y := startY + float32(i)*lineHeight
+		if i == state.Selected {
+			vector.DrawFilledRect(screen, listX-4, y-10, detailX-listX-8, lineHeight, color.RGBA{60, 80, 120, 150}, false)
+		}
+		id := fmt.Sprint(slot.ID)
+		if slot.ID == 0 {
+			id = "A" // The auto-save slot
+		}
+		label := id + "  - empty -"
+		c := color.RGBA{130, 130, 150, 255}
+		switch {
+		case slot.Corrupt:
+			label = id + "  CORRUPTED"
+			c = color.RGBA{255, 90, 90, 255}
+		case slot.Locked:
+			label = id + "  LOCKED"
+			c = color.RGBA{220, 180, 90, 255}
+		case slot.Exists:
+			label = fmt.Sprintf("%s  %s L%d", id, slot.Genre, slot.Depth)
+			c = color.RGBA{200, 200, 255, 255}
+		}
+		if i == state.Selected {
+			c = color.RGBA{255, 255, 255, 255}
+		}
+		drawLabel(screen, listX, y, label, c)
+	}
+
+	if state.Selected >= 0 && state.Selected < len(state.Slots) {
+		drawSaveSlotDetails(screen, detailX, startY-10, state.Slots[state.Selected])
+	}
+
+	hintY := screenHeight - 20
+	if state.StatusMsg != "" {
+		drawCenteredLabel(screen, centerX, hintY-lineHeight, state.StatusMsg, color.RGBA{255, 255, 100, 255})
+	}
+	action := "load"
+	if state.Saving {
+		action = "save"
+	}
+	drawCenteredLabel(screen, centerX, hintY, "Enter "+action+", C copy, X delete, ESC back", color.RGBA{150, 150, 150, 255})
+}
+
+// drawSaveSlotDetails draws a slot's thumbnail with its save time and
+// playtime below, from (x, y).
+func drawSaveSlotDetails(screen *ebiten.Image, x, y float32, slot SaveSlotInfo) {
+	vector.StrokeRect(screen, x, y, saveThumbnailSize, saveThumbnailSize, 1, color.RGBA{100, 100, 150, 255}, false)
+	if !slot.Exists || slot.Corrupt || slot.Locked {
+		return
+	}
+	if slot.Thumbnail != nil {
+		b := slot.Thumbnail.Bounds()
+		scale := float64(saveThumbnailSize) / float64(max(b.Dx(), b.Dy()))
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(float64(x), float64(y))
+		screen.DrawImage(slot.Thumbnail, op)
+	}
+	textY := y + saveThumbnailSize + 14
+	drawLabel(screen, x, textY, slot.Timestamp.Local().Format("2006-01-02 15:04"), color.RGBA{220, 220, 220, 255})
+	drawLabel(screen, x, textY+13, "Played "+formatPlaytime(slot.Playtime), color.RGBA{220, 220, 220, 255})
+}
+
+// formatPlaytime formats a playtime as hours and minutes, e.g. "1h05m".
+func formatPlaytime(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -238,10 +239,16 @@ func TestMenuManager_Select(t *testing.T) {
 			selectedIndex:  0,
 			expectedAction: "new_game",
 		},
+		{
+			name:           "main_menu_stats",
+			menu:           MenuTypeMain,
+			selectedIndex:  2,
+			expectedAction: "stats",
+		},
 		{
 			name:           "main_menu_quit",
 			menu:           MenuTypeMain,
-			selectedIndex:  3,
+			selectedIndex:  4,
 			expectedAction: "quit",
 		},
 		{
@@ -523,6 +530,28 @@ func TestDrawMods(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "mod_that_cannot_load",
+			state: &ModsState{
+				ModsDir:  "/mods",
+				Selected: 0,
+				Mods: []ModInfo{
+					{Name: "addon", Version: "1.0.0", Enabled: true, Error: "missing dependency: requires base ^1.0.0, which is not installed or not enabled"},
+				},
+			},
+		},
+		{
+			name: "mod_trust_levels",
+			state: &ModsState{
+				ModsDir:  "/mods",
+				Selected: 1,
+				Mods: []ModInfo{
+					{Name: "signed", Version: "1.0.0", Enabled: true, Trust: "signed"},
+					{Name: "unsigned", Version: "1.0.0", Enabled: true, Trust: "unsigned", TrustNote: "mod is unsigned: no mod.sig"},
+					{Name: "blocked", Version: "1.0.0", Enabled: true, Trust: "blocked", Error: "mod is blocked: files changed after signing"},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -581,6 +610,26 @@ func TestDrawMultiplayer(t *testing.T) {
 				StatusMsg:  "Server not available",
 			},
 		},
+		{
+			name: "server_browser",
+			state: &MultiplayerState{
+				Browsing: true,
+				Servers: []ServerRow{
+					{Name: "Alpha", Address: "a:7777", Mode: "coop", Genre: "fantasy", Region: "eu-west", Players: 2, MaxPlayers: 4, PingMS: 35, Favorite: true, Verified: true},
+					{Name: "Bravo", Address: "b:7777", Mode: "ffa", PingMS: -1},
+					{Name: "Charlie", Address: "c:7777", Mode: "team", PingMS: -2},
+				},
+				ServerSelected: 2,
+				FilterText:     "Mode any | Genre fantasy",
+				AddressInput:   "10.0.0.5",
+				AddressActive:  true,
+				StatusMsg:      "Found 3 servers",
+			},
+		},
+		{
+			name:  "empty_server_browser",
+			state: &MultiplayerState{Browsing: true, ServerSelected: 40},
+		},
 	}
 
 	for _, tt := range tests {
@@ -590,3 +639,143 @@ func TestDrawMultiplayer(t *testing.T) {
 		})
 	}
 }
+
+// TestDrawSpectator tests the DrawSpectator function.
+func TestDrawSpectator(t *testing.T) {
+	states := []*SpectatorState{
+		nil,
+		{Mode: "kill-cam", KillerName: "Player 3", KillCamProgress: 0.4, Hint: "Space skip"},
+		{Mode: "kill-cam", KillCamProgress: 2},
+		{Mode: "follow", TargetName: "Player 2", RespawnIn: 1.5, Hint: "Q/E switch player"},
+		{Mode: "free"},
+	}
+	for _, state := range states {
+		screen := ebiten.NewImage(320, 200)
+		DrawSpectator(screen, state) // Should not panic
+	}
+}
+
+// TestDrawReplayControls tests the DrawReplayControls function.
+func TestDrawReplayControls(t *testing.T) {
+	states := []*ReplayState{
+		nil,
+		{Tick: 120, Elapsed: 6 * time.Second, Duration: 95 * time.Second, Progress: 0.06, Speed: 1, Hint: "Space pause"},
+		{Paused: true, Progress: -1, Speed: 0.25},
+		{Progress: 3, Speed: 4},
+	}
+	for _, state := range states {
+		screen := ebiten.NewImage(320, 200)
+		DrawReplayControls(screen, state) // Should not panic
+	}
+	if got := formatClock(95 * time.Second); got != "1:35" {
+		t.Errorf("formatClock(95s) = %q, want 1:35", got)
+	}
+}
+
+// TestDrawVoiceIndicators tests the DrawVoiceIndicators function.
+func TestDrawVoiceIndicators(t *testing.T) {
+	screen := ebiten.NewImage(320, 200)
+	DrawVoiceIndicators(screen, nil) // Should not panic
+	DrawVoiceIndicators(screen, []VoiceIndicator{
+		{Name: "You", Speaking: true},
+		{Name: "Player 2"},
+		{Name: "Player 3", Speaking: true, Muted: true},
+	})
+}
+
+// TestDrawLevelSummary tests the DrawLevelSummary function.
+func TestDrawLevelSummary(t *testing.T) {
+	many := make([]string, 40)
+	for i := range many {
+		many[i] = "  enemy 10"
+	}
+	tests := []struct {
+		name  string
+		state *LevelSummaryState
+	}{
+		{
+			name:  "nil_state",
+			state: nil,
+		},
+		{
+			name:  "no_lines",
+			state: &LevelSummaryState{Depth: 1},
+		},
+		{
+			name:  "more_lines_than_fit",
+			state: &LevelSummaryState{Depth: 3, Lines: many},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screen := ebiten.NewImage(320, 200)
+			DrawLevelSummary(screen, tt.state) // Should not panic
+		})
+	}
+}
+
+func TestDrawStatsScreen(t *testing.T) {
+	tests := []struct {
+		name  string
+		state *StatsScreenState
+	}{
+		{"nil_state", nil},
+		{"empty", &StatsScreenState{}},
+		{"stats", &StatsScreenState{
+			Lines: []string{"Kills: 120", "Favorite weapon: Shotgun (80 kills)"},
+			Achievements: []AchievementInfo{
+				{Name: "First Blood", Unlocked: true},
+				{Name: "Centurion"},
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screen := ebiten.NewImage(320, 200)
+			DrawStatsScreen(screen, tt.state) // Should not panic
+		})
+	}
+}
+
+func TestDrawSaveBrowser(t *testing.T) {
+	thumb := ebiten.NewImage(40, 24)
+	slots := []SaveSlotInfo{
+		{ID: 0, Exists: true, Genre: "scifi", Depth: 2, Playtime: 90 * time.Minute},
+		{ID: 1},
+		{ID: 2, Exists: true, Genre: "cyberpunk", Depth: 12, Timestamp: time.Now(), Thumbnail: thumb},
+		{ID: 3, Exists: true, Corrupt: true},
+		{ID: 4, Exists: true, Locked: true},
+	}
+	tests := []struct {
+		name  string
+		state *SaveBrowserState
+	}{
+		{"nil_state", nil},
+		{"no_slots", &SaveBrowserState{}},
+		{"empty_selected", &SaveBrowserState{Slots: slots, Selected: 1, Saving: true}},
+		{"thumbnail_selected", &SaveBrowserState{Slots: slots, Selected: 2, StatusMsg: "Copied to slot 3"}},
+		{"corrupt_selected", &SaveBrowserState{Slots: slots, Selected: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screen := ebiten.NewImage(320, 200)
+			DrawSaveBrowser(screen, tt.state) // Should not panic
+		})
+	}
+}
+
+func TestFormatPlaytime(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                             "0h00m",
+		65 * time.Minute:              "1h05m",
+		26*time.Hour + 59*time.Second: "26h00m",
+	}
+	for d, want := range tests {
+		if got := formatPlaytime(d); got != want {
+			t.Errorf("formatPlaytime(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
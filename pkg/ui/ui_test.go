@@ -41,6 +41,44 @@ func TestDrawHUD_NilHUD(t *testing.T) {
 	DrawHUD(screen, nil) // Should not panic
 }
 
+func TestHUD_AmmoGauge(t *testing.T) {
+	tests := []struct {
+		name         string
+		hud          HUD
+		current, max int
+		label        string
+	}{
+		{"magazine", HUD{Ammo: 40, MaxAmmo: 200, Clip: 7, ClipSize: 12}, 7, 12, "AMMO 7/40"},
+		{"reloading", HUD{Ammo: 40, Clip: 0, ClipSize: 12, Reload: 0.25}, 25, 100, "RELOADING"},
+		{"melee", HUD{Ammo: 40, MaxAmmo: 200}, 40, 200, "AMMO"},
+	}
+	for _, tt := range tests {
+		current, max, label := tt.hud.ammoGauge()
+		if current != tt.current || max != tt.max || label != tt.label {
+			t.Errorf("%s: ammoGauge() = %d, %d, %q, want %d, %d, %q", tt.name, current, max, label, tt.current, tt.max, tt.label)
+		}
+	}
+}
+
+func TestHUD_LoadLabel(t *testing.T) {
+	tests := []struct {
+		name  string
+		hud   HUD
+		label string
+		over  bool
+	}{
+		{"light", HUD{Load: 12, Capacity: 40}, "LOAD 12/40", false},
+		{"heavy", HUD{Load: 52.5, Capacity: 40}, "LOAD 52/40", true},
+		{"off", HUD{Load: 52}, "", false},
+	}
+	for _, tt := range tests {
+		label, over := tt.hud.loadLabel()
+		if label != tt.label || over != tt.over {
+			t.Errorf("%s: loadLabel() = %q, %v, want %q, %v", tt.name, label, over, tt.label, tt.over)
+		}
+	}
+}
+
 func TestDrawHUD_Rendering(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -112,6 +150,24 @@ func TestDrawHUD_Rendering(t *testing.T) {
 			width:  1024,
 			height: 768,
 		},
+		{
+			name: "squad_indicators",
+			hud: &HUD{
+				Health:     80,
+				MaxHealth:  100,
+				MaxArmor:   100,
+				MaxAmmo:    100,
+				WeaponName: "Pistol",
+				SquadOrder: "HOLD",
+				Squad: []SquadIndicator{
+					{Name: "GRUNT", Health: 60, MaxHealth: 100, AbilityReady: true},
+					{Name: "MEDIC", Health: 0, MaxHealth: 80},
+				},
+				theme: getDefaultTheme(),
+			},
+			width:  320,
+			height: 200,
+		},
 	}
 
 	for _, tt := range tests {
@@ -441,13 +497,13 @@ func TestMenuManager_Navigation(t *testing.T) {
 			menuType:      MenuTypeMain,
 			initialIndex:  0,
 			moveUp:        1,
-			expectedIndex: 3, // Wraps to last item
+			expectedIndex: 4, // Wraps to last item
 		},
 		{
 			name:          "main_menu_move_down_wrap",
 			menuType:      MenuTypeMain,
 			initialIndex:  0,
-			moveDown:      5, // More than items
+			moveDown:      6, // More than items
 			expectedIndex: 1, // Wraps around
 		},
 		{
@@ -509,7 +565,7 @@ func TestMenuManager_GetSelectedItem(t *testing.T) {
 		{
 			name:         "main_menu_quit",
 			menuType:     MenuTypeMain,
-			selectedIdx:  3,
+			selectedIdx:  4,
 			expectedItem: "Quit",
 		},
 		{
@@ -698,7 +754,7 @@ func TestDrawMenu_WithSelection(t *testing.T) {
 	screen := ebiten.NewImage(640, 480)
 
 	// Test different selections
-	for i := 0; i < 4; i++ {
+	for i := 0; i < 5; i++ {
 		mm.selectedIndex = i
 		DrawMenu(screen, mm) // Should not panic
 	}
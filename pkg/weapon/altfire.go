@@ -0,0 +1,133 @@
+package weapon
+
+// AltMode is the mechanic of a weapon's secondary fire.
+type AltMode int
+
+const (
+	// AltNone is a weapon without secondary fire.
+	AltNone AltMode = iota
+	// AltBurst fires several rounds at once in a tight spread.
+	AltBurst
+	// AltSlug zooms in while held and fires one heavy, precise round on
+	// release.
+	AltSlug
+	// AltCharge charges up while held and releases a stronger attack.
+	AltCharge
+	// AltGrenade lobs an explosive through the arsenal's launcher.
+	AltGrenade
+)
+
+// AltFire describes a weapon's secondary fire. Cooldown and ammo are
+// tracked apart from the primary fire, though both draw on the same
+// magazine.
+type AltFire struct {
+	Mode       AltMode
+	Cooldown   float64 // Frames between uses (60 TPS)
+	AmmoCost   int     // Rounds drawn from the magazine per use
+	Damage     float64 // Damage multiplier of a slug, a grenade or a full charge
+	Rounds     int     // Burst: rounds fired
+	Spread     float64 // Burst: spread angle in degrees
+	Zoom       float64 // Slug: view magnification while aiming
+	ChargeTime float64 // Charge: frames held to reach full charge
+	Speed      float64 // Grenade: flight speed in tiles per second
+	Gravity    float64 // Grenade: drop in tiles per second squared
+	Splash     float64 // Grenade: explosion radius in tiles
+}
+
+// Held reports whether the mode is aimed or charged by holding the button
+// and fires on release, rather than firing on press.
+func (f AltFire) Held() bool {
+	return f.Mode == AltSlug || f.Mode == AltCharge
+}
+
+// shot returns the weapon as the alt fire shoots it at a charge from 0
+// to 1.
+func (f AltFire) shot(w Weapon, charge float64) Weapon {
+	switch f.Mode {
+	case AltBurst:
+		w.RayCount = f.Rounds
+		w.SpreadAngle = f.Spread
+	case AltSlug:
+		w.RayCount = 1
+		w.SpreadAngle = 0
+		w.Damage *= f.Damage
+	case AltCharge:
+		w.Damage *= 1 + (f.Damage-1)*charge
+	case AltGrenade:
+		w.Type = TypeProjectile
+		w.Projectile = true
+		w.RayCount = 1
+		w.SpreadAngle = 0
+		w.Damage *= f.Damage
+		w.Speed, w.Gravity, w.Splash = f.Speed, f.Gravity, f.Splash
+	}
+	return w
+}
+
+// HoldAlt records another update of the alt fire button held down, aiming
+// or charging the current weapon's held alt fire.
+func (a *Arsenal) HoldAlt() {
+	if a.weapon(a.CurrentSlot).Alt.Held() {
+		a.altHeld++
+	}
+}
+
+// AltHeld reports whether the current weapon's alt fire is being aimed or
+// charged.
+func (a *Arsenal) AltHeld() bool {
+	return a.altHeld > 0
+}
+
+// AltCharge returns how far the current weapon's charge has built, from 0
+// to 1.
+func (a *Arsenal) AltCharge() float64 {
+	alt := a.weapon(a.CurrentSlot).Alt
+	if alt.Mode != AltCharge || alt.ChargeTime <= 0 {
+		return 0
+	}
+	return min(a.altHeld/alt.ChargeTime, 1)
+}
+
+// Zoom returns the view magnification of the current weapon: its slug zoom
+// while aimed, otherwise 1.
+func (a *Arsenal) Zoom() float64 {
+	alt := a.weapon(a.CurrentSlot).Alt
+	if alt.Mode != AltSlug || !a.AltHeld() || alt.Zoom <= 0 {
+		return 1
+	}
+	return alt.Zoom
+}
+
+// FireAlt discharges the current weapon's secondary fire, releasing any
+// aim or charge. It returns the weapon as the alt fire shot it, with
+// damage and flight adjusted, and hit results as Fire does; results are
+// nil when the weapon has no alt fire, is cooling down or lacks the rounds,
+// in which case a reload starts.
+func (a *Arsenal) FireAlt(posX, posY, dirX, dirY float64, raycast func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64)) (Weapon, []HitResult) {
+	slot := a.CurrentSlot
+	weapon := a.weapon(slot)
+	charge := a.AltCharge()
+	a.altHeld = 0
+
+	if weapon.Alt.Mode == AltNone || a.AltFramesSinceFire[slot] < int(weapon.Alt.Cooldown) {
+		return weapon, nil
+	}
+	if weapon.Type != TypeMelee {
+		if a.Clips[slot] < weapon.Alt.AmmoCost {
+			a.StartReload()
+			return weapon, nil
+		}
+		a.CancelReload()
+		a.Clips[slot] -= weapon.Alt.AmmoCost
+	}
+	a.AltFramesSinceFire[slot] = 0
+
+	if a.Animator != nil {
+		a.Animator.SetState(AnimFire)
+	}
+
+	shot := weapon.Alt.shot(weapon, charge)
+	dirX, dirY = a.stray(dirX, dirY)
+	a.kick(shot)
+	return shot, a.discharge(shot, posX, posY, dirX, dirY, raycast)
+}
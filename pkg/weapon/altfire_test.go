@@ -0,0 +1,137 @@
+package weapon
+
+import (
+	"math"
+	"testing"
+)
+
+// hitAll is a raycast that hits entity 1 a tile away.
+func hitAll(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64) {
+	return true, 1, x + dx, y + dy, 1
+}
+
+func TestFireAltBurst(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(3) // Chaingun: 5 round burst
+
+	shot, results := a.FireAlt(0, 0, 1, 0, hitAll)
+	if len(results) != 5 {
+		t.Fatalf("burst fired %d rounds, want 5", len(results))
+	}
+	if a.Clips[3] != 95 {
+		t.Errorf("clip after burst = %d, want 95", a.Clips[3])
+	}
+	if shot.Damage != a.Weapons[3].Damage || results[0].Damage != shot.Damage {
+		t.Errorf("burst round damage = %v, want the chaingun's %v", results[0].Damage, a.Weapons[3].Damage)
+	}
+
+	// The alt cooldown blocks a second burst but not the primary fire.
+	if _, results := a.FireAlt(0, 0, 1, 0, hitAll); results != nil {
+		t.Error("second burst ignored the alt cooldown")
+	}
+	if a.Fire(0, 0, 1, 0, hitAll) == nil {
+		t.Error("alt fire put the primary fire on cooldown")
+	}
+	for i := 0; i < 30; i++ {
+		a.Update()
+	}
+	if _, results := a.FireAlt(0, 0, 1, 0, hitAll); results == nil {
+		t.Error("burst still cooling down after 30 frames")
+	}
+}
+
+func TestFireAltSlug(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(1) // Pistol: zoomed slug
+
+	if a.Zoom() != 1 {
+		t.Errorf("zoom before aiming = %v, want 1", a.Zoom())
+	}
+	a.HoldAlt()
+	if !a.AltHeld() || a.Zoom() != 2 {
+		t.Errorf("aiming: held %v, zoom %v, want true and 2", a.AltHeld(), a.Zoom())
+	}
+
+	shot, results := a.FireAlt(0, 0, 1, 0, hitAll)
+	if len(results) != 1 || math.Abs(results[0].Damage-37.5) > 1e-9 || shot.Damage != results[0].Damage {
+		t.Errorf("slug results = %+v, want one hit for 37.5", results)
+	}
+	if a.AltHeld() || a.Zoom() != 1 {
+		t.Error("firing the slug did not release the aim")
+	}
+	if a.Clips[1] != 10 {
+		t.Errorf("clip after slug = %d, want 10", a.Clips[1])
+	}
+}
+
+func TestFireAltCharge(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(6) // Knife: charged swing, 45 frames to full
+
+	a.HoldAlt()
+	_, results := a.FireAlt(0, 0, 1, 0, hitAll)
+	if len(results) != 1 || math.Abs(results[0].Damage-25*(1+1.5/45)) > 1e-9 {
+		t.Errorf("quick release results = %+v, want barely charged", results)
+	}
+
+	for i := 0; i < 30; i++ {
+		a.Update()
+	}
+	for i := 0; i < 100; i++ {
+		a.HoldAlt()
+	}
+	if a.AltCharge() != 1 {
+		t.Errorf("charge after holding past full = %v, want 1", a.AltCharge())
+	}
+	if _, results := a.FireAlt(0, 0, 1, 0, hitAll); len(results) != 1 || results[0].Damage != 62.5 {
+		t.Errorf("full charge results = %+v, want 62.5 damage", results)
+	}
+
+	// Holding does nothing for weapons that fire alt on press.
+	a.SwitchTo(3)
+	a.HoldAlt()
+	if a.AltHeld() {
+		t.Error("holding a burst weapon's alt fire aims it")
+	}
+}
+
+func TestFireAltGrenade(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(2) // Shotgun: grenade launcher
+
+	var launched []Weapon
+	a.Launch = func(w Weapon, posX, posY, dirX, dirY float64) {
+		launched = append(launched, w)
+	}
+	shot, results := a.FireAlt(0, 0, 1, 0, hitAll)
+	if results == nil || len(results) != 0 {
+		t.Errorf("grenade results = %v, want an empty volley", results)
+	}
+	if len(launched) != 1 || launched[0] != shot {
+		t.Fatalf("launched %+v, want the grenade shot", launched)
+	}
+	if shot.Damage != 50 || shot.Gravity <= 0 || shot.Splash <= 0 || !shot.Projectile {
+		t.Errorf("grenade = %+v, want a lobbed explosive for 50", shot)
+	}
+	if a.Clips[2] != 6 {
+		t.Errorf("clip after grenade = %d, want 6", a.Clips[2])
+	}
+}
+
+func TestFireAltUnavailable(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(4) // Rocket Launcher has no alt fire
+	if _, results := a.FireAlt(0, 0, 1, 0, hitAll); results != nil {
+		t.Error("weapon without alt fire fired it")
+	}
+
+	a.SwitchTo(3)
+	a.Clips[3] = 4
+	a.Ammo["bullets"] = 50
+	if _, results := a.FireAlt(0, 0, 1, 0, hitAll); results != nil {
+		t.Error("burst fired without enough rounds")
+	}
+	if a.Clips[3] != 4 || !a.Reloading() {
+		t.Errorf("short magazine: clip %d, reloading %v, want 4 and a reload", a.Clips[3], a.Reloading())
+	}
+}
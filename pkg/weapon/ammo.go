@@ -0,0 +1,83 @@
+package weapon
+
+import "github.com/opd-ai/violence/pkg/ammo"
+
+// load returns the weapon firing rounds of the variant. The variant's
+// status effect applies only to weapons that inflict none of their own.
+func load(w Weapon, v ammo.Variant) Weapon {
+	w.AmmoVariant = v.ID
+	w.Damage *= scale(v.Damage, 1)
+	if v.DamageType != "" {
+		w.DamageType = v.DamageType
+	}
+	w.Penetration += v.Penetration
+	if v.Status != "" && w.Status == "" {
+		w.Status, w.StatusChance = v.Status, v.StatusChance
+	}
+	return w
+}
+
+// ReservePool returns the reserve pool the weapon in slot reloads from:
+// that of its ammo type, or of the variant loaded in it.
+func (a *Arsenal) ReservePool(slot int) string {
+	return ammo.PoolKey(a.Weapons[slot].AmmoType, a.Loaded[slot])
+}
+
+// LoadAmmo loads the weapon in slot with an ammo variant, or "" for
+// standard rounds. The rounds left in its magazine go back to their pool,
+// and the current weapon starts reloading with the new load. It reports
+// false for melee weapons and variants not made in the weapon's ammo type.
+func (a *Arsenal) LoadAmmo(slot int, variant string) bool {
+	if slot < 0 || slot >= len(a.Weapons) {
+		return false
+	}
+	w := a.Weapons[slot]
+	if w.Type == TypeMelee || w.AmmoType == "" {
+		return false
+	}
+	if v, ok := ammo.FindVariant(variant); variant != "" && (!ok || !v.Fits(w.AmmoType)) {
+		return false
+	}
+	if a.Loaded[slot] == variant {
+		return true
+	}
+
+	a.reserve().Add(a.ReservePool(slot), a.Clips[slot])
+	a.Clips[slot] = 0
+	if a.Loaded == nil {
+		a.Loaded = make(map[int]string)
+	}
+	a.Loaded[slot] = variant
+	if slot == a.CurrentSlot {
+		a.CancelReload()
+		a.StartReload()
+	}
+	return true
+}
+
+// CycleAmmo loads the current weapon with the next of its ammo variants
+// that has rounds in reserve, coming back round to standard rounds. It
+// returns the variant loaded, and false when there was nothing to switch
+// to.
+func (a *Arsenal) CycleAmmo() (string, bool) {
+	slot := a.CurrentSlot
+	w := a.Weapons[slot]
+	loads := []string{""}
+	for _, v := range ammo.VariantsFor(w.AmmoType) {
+		loads = append(loads, v.ID)
+	}
+
+	current := 0
+	for i, id := range loads {
+		if id == a.Loaded[slot] {
+			current = i
+		}
+	}
+	for i := 1; i < len(loads); i++ {
+		next := loads[(current+i)%len(loads)]
+		if next == "" || a.reserve().Get(ammo.PoolKey(w.AmmoType, next)) > 0 {
+			return next, a.LoadAmmo(slot, next)
+		}
+	}
+	return a.Loaded[slot], false
+}
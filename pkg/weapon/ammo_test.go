@@ -0,0 +1,69 @@
+package weapon
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLoadAmmo(t *testing.T) {
+	a := NewArsenal()
+	reserve := testReserve{"bullets": 30, "bullets_incendiary": 20}
+	a.Reserve = reserve
+	a.SwitchTo(1) // Pistol: 15 damage, 12-round magazine
+	a.Clips[1] = 5
+
+	if a.LoadAmmo(1, "emp") || a.LoadAmmo(0, "incendiary") || a.LoadAmmo(1, "nonexistent") {
+		t.Fatal("loaded a variant the weapon cannot take")
+	}
+	if !a.LoadAmmo(1, "incendiary") {
+		t.Fatal("pistol refused incendiary rounds")
+	}
+	if reserve["bullets"] != 35 || a.ReservePool(1) != "bullets_incendiary" {
+		t.Errorf("standard pool %d after unloading, reloading from %q", reserve["bullets"], a.ReservePool(1))
+	}
+	for a.Reloading() {
+		a.Update()
+	}
+	if a.Clips[1] != 12 || reserve["bullets_incendiary"] != 8 {
+		t.Errorf("loaded %d incendiary rounds leaving %d, want 12 leaving 8", a.Clips[1], reserve["bullets_incendiary"])
+	}
+
+	w := a.GetCurrentWeapon()
+	if w.AmmoVariant != "incendiary" || w.DamageType != "fire" || w.Status != "burning" || math.Abs(w.Damage-13.5) > 1e-9 {
+		t.Errorf("incendiary pistol = %+v", w)
+	}
+	if a.Weapons[1].DamageType != "" {
+		t.Error("loading changed the stock weapon")
+	}
+}
+
+func TestLoadAmmoKeepsRolledStatus(t *testing.T) {
+	a := NewArsenal()
+	a.Weapons[1].Status, a.Weapons[1].StatusChance = "poisoned", 0.25
+	a.LoadAmmo(1, "hollow_point")
+	if w := a.Fitted(1); w.Status != "poisoned" || w.Damage <= a.Weapons[1].Damage {
+		t.Errorf("hollow-point venom pistol = %+v", w)
+	}
+}
+
+func TestCycleAmmo(t *testing.T) {
+	a := NewArsenal()
+	a.Reserve = testReserve{"bullets": 30, "bullets_ap": 10}
+	a.SwitchTo(1)
+
+	// Variants without rounds in reserve are skipped.
+	if id, ok := a.CycleAmmo(); !ok || id != "ap" {
+		t.Fatalf("first cycle loaded %q, want ap", id)
+	}
+	if a.GetCurrentWeapon().Penetration != 2 {
+		t.Error("armor-piercing rounds do not penetrate")
+	}
+	if id, ok := a.CycleAmmo(); !ok || id != "" {
+		t.Errorf("second cycle loaded %q, want standard rounds", id)
+	}
+
+	a.SwitchTo(6)
+	if _, ok := a.CycleAmmo(); ok {
+		t.Error("cycled ammo on a knife")
+	}
+}
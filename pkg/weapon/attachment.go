@@ -0,0 +1,184 @@
+package weapon
+
+import "github.com/opd-ai/violence/pkg/ammo"
+
+// Mount is an attachment point on a ranged weapon.
+type Mount int
+
+const (
+	// MountScope takes sights and scopes.
+	MountScope Mount = iota
+	// MountBarrel takes barrels and muzzle devices.
+	MountBarrel
+	// MountMagazine takes magazines and loaders.
+	MountMagazine
+	// MountUnderbarrel takes grips and bayonets.
+	MountUnderbarrel
+	// MountCount is the number of mounts on a weapon.
+	MountCount
+)
+
+// Attachment is a part fitted to one of a weapon's mounts. Multipliers of
+// 0 leave the stat unchanged.
+type Attachment struct {
+	ID       string
+	Name     string
+	Mount    Mount
+	Damage   float64 // Damage multiplier
+	FireRate float64 // Multiplier on frames between shots; below 1 fires faster
+	Spread   float64 // Spread angle and bloom multiplier
+	Recoil   float64 // Recoil multiplier
+	Range    float64 // Tiles added to the range
+	Capacity float64 // Magazine size multiplier
+	Reload   float64 // Reload time multiplier
+	Zoom     float64 // Magnification of an aimed shot
+	Muffle   float64 // Share of the shot's noise suppressed, from 0 to 1
+	Bayonet  float64 // Damage of a melee stab with the weapon
+}
+
+// Attachments is the catalogue of attachments that can be fitted.
+var Attachments = []Attachment{
+	{ID: "scope_reflex", Name: "Reflex Sight", Mount: MountScope, Spread: 0.7},
+	{ID: "scope_long", Name: "Long Scope", Mount: MountScope, Zoom: 2.5, Range: 30, FireRate: 1.1},
+	{ID: "barrel_heavy", Name: "Heavy Barrel", Mount: MountBarrel, Damage: 1.25, FireRate: 1.1, Recoil: 1.2},
+	{ID: "barrel_ported", Name: "Ported Barrel", Mount: MountBarrel, FireRate: 0.85, Spread: 1.15, Recoil: 0.7},
+	{ID: "barrel_suppressor", Name: "Suppressor", Mount: MountBarrel, Muffle: 0.7, Damage: 0.9},
+	{ID: "mag_extended", Name: "Extended Magazine", Mount: MountMagazine, Capacity: 1.5},
+	{ID: "mag_speed", Name: "Speed Loader", Mount: MountMagazine, Reload: 0.6},
+	{ID: "under_grip", Name: "Foregrip", Mount: MountUnderbarrel, Spread: 0.6, Recoil: 0.6},
+	{ID: "under_bayonet", Name: "Bayonet", Mount: MountUnderbarrel, Bayonet: 30},
+}
+
+// FindAttachment looks up an attachment in the catalogue by ID.
+func FindAttachment(id string) (Attachment, bool) {
+	for _, at := range Attachments {
+		if at.ID == id {
+			return at, true
+		}
+	}
+	return Attachment{}, false
+}
+
+// Fits reports whether the attachment can be fitted to the weapon. Melee
+// weapons have no mounts.
+func (at Attachment) Fits(w Weapon) bool {
+	if w.Type == TypeMelee {
+		return false
+	}
+	return at.Mount != MountMagazine || w.ClipSize > 0
+}
+
+// apply returns the weapon with the attachment fitted.
+func (at Attachment) apply(w Weapon) Weapon {
+	w.Damage *= scale(at.Damage, 1)
+	w.FireRate *= scale(at.FireRate, 1)
+	w.SpreadAngle *= scale(at.Spread, 1)
+	w.Bloom *= scale(at.Spread, 1)
+	w.Recoil *= scale(at.Recoil, 1)
+	w.Range += at.Range
+	w.ReloadTime *= scale(at.Reload, 1)
+	if at.Capacity > 0 {
+		w.ClipSize = max(int(float64(w.ClipSize)*at.Capacity), w.ClipSize+1)
+	}
+	w.Muffle = 1 - (1-w.Muffle)*(1-at.Muffle)
+	w.Bayonet += at.Bayonet
+
+	// A scope zooms an existing aimed shot further, or lets a weapon
+	// without alt fire aim down it.
+	switch {
+	case at.Zoom <= 0:
+	case w.Alt.Mode == AltSlug:
+		w.Alt.Zoom = max(w.Alt.Zoom, at.Zoom)
+	case w.Alt.Mode == AltNone:
+		w.Alt = AltFire{Mode: AltSlug, Cooldown: w.FireRate, AmmoCost: 1, Damage: 1, Zoom: at.Zoom}
+	}
+	return w
+}
+
+// Attach fits the attachment with the given ID to the weapon in slot,
+// replacing whatever occupied its mount. It returns the ID of the
+// replaced attachment, and false when the attachment is unknown or does
+// not fit the weapon.
+func (a *Arsenal) Attach(slot int, id string) (string, bool) {
+	at, ok := FindAttachment(id)
+	if !ok || slot < 0 || slot >= len(a.Weapons) || !at.Fits(a.Weapons[slot]) {
+		return "", false
+	}
+	if a.Attached == nil {
+		a.Attached = make(map[int][MountCount]string)
+	}
+	fitted := a.Attached[slot]
+	old := fitted[at.Mount]
+	fitted[at.Mount] = id
+	a.Attached[slot] = fitted
+	a.Clips[slot] = min(a.Clips[slot], a.MagazineSize(slot))
+	return old, true
+}
+
+// Detach removes the attachment on the mount of the weapon in slot,
+// returning its ID, or "" when the mount was empty.
+func (a *Arsenal) Detach(slot int, m Mount) string {
+	fitted, ok := a.Attached[slot]
+	if !ok || m < 0 || m >= MountCount {
+		return ""
+	}
+	old := fitted[m]
+	fitted[m] = ""
+	a.Attached[slot] = fitted
+	a.Clips[slot] = min(a.Clips[slot], a.MagazineSize(slot))
+	return old
+}
+
+// AttachedTo returns the attachments fitted to the weapon in slot.
+func (a *Arsenal) AttachedTo(slot int) []Attachment {
+	var fitted []Attachment
+	for _, id := range a.Attached[slot] {
+		if at, ok := FindAttachment(id); ok {
+			fitted = append(fitted, at)
+		}
+	}
+	return fitted
+}
+
+// weapon returns the weapon in slot with its attachments fitted and its
+// ammo loaded.
+func (a *Arsenal) weapon(slot int) Weapon {
+	w := a.Weapons[slot]
+	for _, at := range a.AttachedTo(slot) {
+		w = at.apply(w)
+	}
+	if v, ok := ammo.FindVariant(a.Loaded[slot]); ok {
+		w = load(w, v)
+	}
+	return w
+}
+
+// Fitted returns the weapon in slot as it fires, with its attachments
+// fitted and its ammo loaded.
+func (a *Arsenal) Fitted(slot int) Weapon {
+	return a.weapon(slot)
+}
+
+// Bayonet stab reach in tiles, and the least frames between stabs.
+const (
+	bayonetRange = 1.5
+	bayonetRate  = 30
+)
+
+// Bash stabs with the current weapon's bayonet. Stabs share the weapon's
+// fire cooldown, no shorter than bayonetRate, and draw no ammunition. It
+// returns the stab as a melee weapon with its hit results; results are nil
+// when the weapon has no bayonet or is cooling down.
+func (a *Arsenal) Bash(posX, posY, dirX, dirY float64, raycast func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64)) (Weapon, []HitResult) {
+	slot := a.CurrentSlot
+	w := a.weapon(slot)
+	stab := Weapon{Name: w.Name, Type: TypeMelee, Damage: w.Bayonet, FireRate: max(w.FireRate, bayonetRate), Range: bayonetRange, RayCount: 1, Knockback: 1}
+	if w.Bayonet <= 0 || a.FramesSinceFire[slot] < int(stab.FireRate) {
+		return stab, nil
+	}
+	a.FramesSinceFire[slot] = 0
+	if a.Animator != nil {
+		a.Animator.SetState(AnimFire)
+	}
+	return stab, a.discharge(stab, posX, posY, dirX, dirY, raycast)
+}
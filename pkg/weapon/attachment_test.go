@@ -0,0 +1,105 @@
+package weapon
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAttachStats(t *testing.T) {
+	a := NewArsenal()
+	base := a.Weapons[2] // Shotgun
+
+	for _, id := range []string{"barrel_heavy", "under_grip", "mag_extended", "mag_speed"} {
+		if _, ok := a.Attach(2, id); !ok {
+			t.Fatalf("could not attach %s", id)
+		}
+	}
+	// The speed loader replaced the extended magazine.
+	fitted := a.Attached[2]
+	if fitted[MountMagazine] != "mag_speed" || fitted[MountBarrel] != "barrel_heavy" || fitted[MountScope] != "" {
+		t.Errorf("mounts = %v", fitted)
+	}
+
+	a.SwitchTo(2)
+	w := a.GetCurrentWeapon()
+	if math.Abs(w.Damage-base.Damage*1.25) > 1e-9 || math.Abs(w.FireRate-base.FireRate*1.1) > 1e-9 {
+		t.Errorf("damage %v, fire rate %v, want the heavy barrel's", w.Damage, w.FireRate)
+	}
+	if math.Abs(w.SpreadAngle-base.SpreadAngle*0.6) > 1e-9 || math.Abs(w.ReloadTime-base.ReloadTime*0.6) > 1e-9 {
+		t.Errorf("spread %v, reload %v, want the grip's and the loader's", w.SpreadAngle, w.ReloadTime)
+	}
+	if a.Weapons[2] != base {
+		t.Error("attaching changed the stock weapon")
+	}
+
+	if old := a.Detach(2, MountMagazine); old != "mag_speed" {
+		t.Errorf("detached %q, want mag_speed", old)
+	}
+	a.Attach(2, "mag_extended")
+	if a.MagazineSize(2) != 12 {
+		t.Errorf("extended magazine holds %d, want 12", a.MagazineSize(2))
+	}
+	if a.Detach(2, MountMagazine); a.Clips[2] != 8 {
+		t.Errorf("clip after removing the extended magazine = %d, want 8", a.Clips[2])
+	}
+}
+
+func TestAttachFits(t *testing.T) {
+	a := NewArsenal()
+	if _, ok := a.Attach(6, "barrel_heavy"); ok {
+		t.Error("attached a barrel to the knife")
+	}
+	if _, ok := a.Attach(1, "no_such_part"); ok {
+		t.Error("attached an unknown part")
+	}
+	if len(a.AttachedTo(1)) != 0 {
+		t.Error("failed attachments were fitted")
+	}
+}
+
+func TestAttachScope(t *testing.T) {
+	a := NewArsenal()
+	a.Attach(3, "scope_long") // Chaingun keeps its burst
+	a.Attach(4, "scope_long") // Rocket Launcher gains an aimed shot
+	a.Attach(1, "scope_long") // Pistol's slug zooms further
+
+	a.SwitchTo(3)
+	a.HoldAlt()
+	if a.Zoom() != 1 {
+		t.Errorf("scoped chaingun zoom = %v, want 1", a.Zoom())
+	}
+	a.SwitchTo(4)
+	a.HoldAlt()
+	if a.Zoom() != 2.5 {
+		t.Errorf("scoped launcher zoom = %v, want 2.5", a.Zoom())
+	}
+	a.SwitchTo(1)
+	a.HoldAlt()
+	if a.Zoom() != 2.5 || a.GetCurrentWeapon().Range != 130 {
+		t.Errorf("scoped pistol zoom %v, range %v, want 2.5 and 130", a.Zoom(), a.GetCurrentWeapon().Range)
+	}
+}
+
+func TestBash(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(1)
+	if _, results := a.Bash(0, 0, 1, 0, hitAll); results != nil {
+		t.Error("stabbed without a bayonet")
+	}
+
+	a.Attach(1, "under_bayonet")
+	a.Attach(1, "barrel_suppressor")
+	if w := a.GetCurrentWeapon(); math.Abs(w.Muffle-0.7) > 1e-9 {
+		t.Errorf("suppressed muffle = %v, want 0.7", w.Muffle)
+	}
+	stab, results := a.Bash(0, 0, 1, 0, hitAll)
+	if len(results) != 1 || results[0].Damage != 30 || stab.Type != TypeMelee {
+		t.Errorf("stab %+v hit %+v, want a melee hit for 30", stab, results)
+	}
+	if a.Clips[1] != 12 {
+		t.Errorf("stab used ammo: clip %d", a.Clips[1])
+	}
+	if _, results := a.Bash(0, 0, 1, 0, hitAll); results != nil {
+		t.Error("second stab ignored the cooldown")
+	}
+}
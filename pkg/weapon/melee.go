@@ -0,0 +1,93 @@
+package weapon
+
+// Swing is one attack of a melee combo. Multipliers apply to the weapon
+// swung.
+type Swing struct {
+	Name      string
+	Shape     string  // Collision attack shape the swing sweeps
+	Damage    float64 // Damage multiplier
+	Reach     float64 // Range multiplier
+	Knockback float64 // Knockback multiplier
+	Recovery  float64 // Multiplier on frames before the next swing
+	Stagger   int     // Ticks a hit staggers its target; 0 for none
+}
+
+// Light attacks run through lightChain while each follows the last inside
+// the combo window. A heavy attack opens with a cleave or, inside the
+// window, ends the chain with a smash that hits harder for each light
+// attack before it.
+var (
+	lightChain = []Swing{
+		{Name: "Slash", Shape: "sword_slash_h", Damage: 1, Reach: 1, Knockback: 1, Recovery: 1},
+		{Name: "Cross Cut", Shape: "sword_slash_v", Damage: 1.15, Reach: 1, Knockback: 1, Recovery: 0.9},
+		{Name: "Thrust", Shape: "sword_thrust", Damage: 1.4, Reach: 1.3, Knockback: 1.5, Recovery: 1.2},
+	}
+	heavyOpener   = Swing{Name: "Cleave", Shape: "cleave", Damage: 1.8, Reach: 1.1, Knockback: 2, Recovery: 2, Stagger: 15}
+	heavyFinisher = Swing{Name: "Smash", Shape: "hammer_smash", Damage: 2, Reach: 1, Knockback: 3, Recovery: 2.5, Stagger: 40}
+)
+
+// Combo timing: frames after a swing recovers that the next one still
+// chains, and the finisher's damage added per light attack before it.
+const (
+	comboWindow   = 30
+	finisherBonus = 0.25
+)
+
+// apply returns the weapon with the swing's multipliers.
+func (s Swing) apply(w Weapon) Weapon {
+	w.Damage *= s.Damage
+	w.Range *= s.Reach
+	w.Knockback *= s.Knockback
+	w.FireRate *= s.Recovery
+	return w
+}
+
+// Swing attacks with the current melee weapon, light or heavy. Light
+// swings advance the combo when they follow the last swing within the
+// combo window; a heavy swing ends it. It returns the swing and the weapon
+// as swung, and false for ranged weapons or while the last swing is
+// recovering.
+func (a *Arsenal) Swing(heavy bool) (Swing, Weapon, bool) {
+	slot := a.CurrentSlot
+	w := a.weapon(slot)
+	since := a.FramesSinceFire[slot]
+	if w.Type != TypeMelee || since < int(a.recovery) {
+		return Swing{}, w, false
+	}
+	if since > int(a.recovery)+comboWindow {
+		a.combo = 0
+	}
+
+	var s Swing
+	switch {
+	case heavy && a.combo > 0:
+		s = heavyFinisher
+		s.Damage += finisherBonus * float64(a.combo)
+		a.combo = 0
+	case heavy:
+		s = heavyOpener
+	default:
+		s = lightChain[a.combo%len(lightChain)]
+		a.combo++
+	}
+	a.recovery = w.FireRate * s.Recovery
+	a.FramesSinceFire[slot] = 0
+	if a.Animator != nil {
+		a.Animator.SetState(AnimFire)
+	}
+	return s, s.apply(w), true
+}
+
+// Combo returns how many light attacks the current combo has chained, or
+// 0 once its window has passed.
+func (a *Arsenal) Combo() int {
+	if a.FramesSinceFire[a.CurrentSlot] > int(a.recovery)+comboWindow {
+		return 0
+	}
+	return a.combo
+}
+
+// BreakCombo drops the current combo, as when the player is hit.
+func (a *Arsenal) BreakCombo() {
+	a.combo = 0
+}
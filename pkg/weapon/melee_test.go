@@ -0,0 +1,94 @@
+package weapon
+
+import (
+	"math"
+	"testing"
+)
+
+// recoverSwing updates the arsenal until the current weapon can swing again.
+func recoverSwing(a *Arsenal) {
+	for a.FramesSinceFire[a.CurrentSlot] < int(a.recovery) {
+		a.Update()
+	}
+}
+
+func TestSwingChain(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(6) // Knife: 25 damage, 18 frames between swings
+	base := a.Weapons[6]
+
+	var names []string
+	for i := 0; i < 4; i++ {
+		s, w, ok := a.Swing(false)
+		if !ok {
+			t.Fatalf("light swing %d refused", i)
+		}
+		if w.Damage != base.Damage*s.Damage || w.Type != TypeMelee {
+			t.Errorf("%s swung for %v, want %v", s.Name, w.Damage, base.Damage*s.Damage)
+		}
+		if _, _, ok := a.Swing(false); ok {
+			t.Errorf("%s did not recover before the next swing", s.Name)
+		}
+		names = append(names, s.Name)
+		recoverSwing(a)
+	}
+	want := []string{"Slash", "Cross Cut", "Thrust", "Slash"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("swing %d = %s, want %s", i, names[i], want[i])
+		}
+	}
+	if a.Combo() != 4 {
+		t.Errorf("combo = %d, want 4", a.Combo())
+	}
+
+	// Waiting out the window starts the chain over.
+	for i := 0; i <= comboWindow; i++ {
+		a.Update()
+	}
+	if a.Combo() != 0 {
+		t.Errorf("combo after the window = %d, want 0", a.Combo())
+	}
+	if s, _, _ := a.Swing(false); s.Name != "Slash" {
+		t.Errorf("swing after the window = %s, want Slash", s.Name)
+	}
+}
+
+func TestSwingHeavy(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(6)
+
+	s, w, _ := a.Swing(true)
+	if s.Name != "Cleave" || s.Stagger == 0 || w.Damage != 45 {
+		t.Errorf("heavy opener = %s for %v, want a staggering Cleave for 45", s.Name, w.Damage)
+	}
+	recoverSwing(a)
+
+	// Two light swings then a heavy one finish the combo.
+	a.Swing(false)
+	recoverSwing(a)
+	a.Swing(false)
+	recoverSwing(a)
+	s, w, _ = a.Swing(true)
+	if s.Name != "Smash" || math.Abs(w.Damage-25*2.5) > 1e-9 {
+		t.Errorf("finisher = %s for %v, want Smash for 62.5", s.Name, w.Damage)
+	}
+	if a.Combo() != 0 {
+		t.Errorf("combo after the finisher = %d, want 0", a.Combo())
+	}
+}
+
+func TestSwingRanged(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(1)
+	if _, _, ok := a.Swing(false); ok {
+		t.Error("swung the pistol")
+	}
+
+	a.SwitchTo(0)
+	a.Swing(false)
+	a.BreakCombo()
+	if a.Combo() != 0 {
+		t.Error("BreakCombo left the combo running")
+	}
+}
@@ -0,0 +1,220 @@
+package weapon
+
+import (
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// rarityNames are the display names of each rarity.
+var rarityNames = [...]string{"Common", "Uncommon", "Rare", "Epic", "Legendary"}
+
+// Label returns the rarity's display name.
+func (r Rarity) Label() string {
+	if r < 0 || int(r) >= len(rarityNames) {
+		return rarityNames[RarityCommon]
+	}
+	return rarityNames[r]
+}
+
+// Value returns what a weapon of the rarity is worth in credits.
+func (r Rarity) Value() int {
+	return 150 << max(r, 0)
+}
+
+// Rolled weapon tables by rarity: the odds out of 100 of rolling each
+// tier, its base damage multiplier, and the strength of its affixes.
+var (
+	rarityWeights  = [...]int{52, 27, 13, 6, 2}
+	rarityDamage   = [...]float64{1.0, 1.1, 1.2, 1.3, 1.4}
+	rarityStrength = [...]float64{1, 1, 1, 1.25, 1.5}
+)
+
+// Affix is a named modifier rolled onto a weapon. Multipliers of 0 leave
+// the stat unchanged.
+type Affix struct {
+	Name         string
+	Damage       float64 // Damage multiplier
+	FireRate     float64 // Multiplier on frames between shots; below 1 fires faster
+	Spread       float64 // Spread angle multiplier
+	Speed        float64 // Projectile speed multiplier
+	Splash       float64 // Tiles added to the explosion radius
+	Status       string  // Status effect inflicted on hit
+	StatusChance float64 // Chance per hit of inflicting Status
+	Projectile   bool    // Only rolled onto projectile weapons
+}
+
+// prefixes modify how a weapon shoots.
+var prefixes = []Affix{
+	{Name: "Brutal", Damage: 1.25},
+	{Name: "Heavy", Damage: 1.4, FireRate: 1.25},
+	{Name: "Rapid", FireRate: 0.75},
+	{Name: "Hasty", Damage: 0.9, FireRate: 0.6},
+	{Name: "Precise", Spread: 0.5, Damage: 1.1},
+	{Name: "Swift", Speed: 1.5, Projectile: true},
+	{Name: "Volatile", Splash: 1, Damage: 1.1, Projectile: true},
+}
+
+// genreSuffixes are the status-inflicting suffixes per genre, naming
+// effects from that genre's status registry.
+var genreSuffixes = map[string][]Affix{
+	"fantasy": {
+		{Name: "of Embers", Status: "burning", StatusChance: 0.2},
+		{Name: "of Venom", Status: "poisoned", StatusChance: 0.25},
+		{Name: "of Rending", Status: "bleeding", StatusChance: 0.3},
+		{Name: "of Thunder", Status: "stunned", StatusChance: 0.1},
+		{Name: "of Storms", Status: "shocked", StatusChance: 0.2},
+		{Name: "of Tides", Status: "wet", StatusChance: 0.3},
+		{Name: "of Pitch", Status: "oiled", StatusChance: 0.3},
+		{Name: "of Frost", Status: "frozen", StatusChance: 0.15},
+	},
+	"scifi": {
+		{Name: "of Ignition", Status: "burning", StatusChance: 0.2},
+		{Name: "of Fallout", Status: "irradiated", StatusChance: 0.25},
+		{Name: "of Disruption", Status: "emp_stunned", StatusChance: 0.1},
+		{Name: "of Corrosion", Status: "corroded", StatusChance: 0.25},
+		{Name: "of Coolant", Status: "wet", StatusChance: 0.3},
+		{Name: "of Fuel", Status: "fuel_soaked", StatusChance: 0.3},
+		{Name: "of Stasis", Status: "frozen", StatusChance: 0.15},
+	},
+	"horror": {
+		{Name: "of Plague", Status: "poisoned", StatusChance: 0.25},
+		{Name: "of Butchery", Status: "bleeding", StatusChance: 0.3},
+		{Name: "of Dread", Status: "terrified", StatusChance: 0.15},
+		{Name: "of Contagion", Status: "infected", StatusChance: 0.25},
+		{Name: "of Galvanism", Status: "shocked", StatusChance: 0.2},
+		{Name: "of Drowning", Status: "wet", StatusChance: 0.3},
+		{Name: "of Tallow", Status: "oiled", StatusChance: 0.3},
+		{Name: "of Rime", Status: "frozen", StatusChance: 0.15},
+	},
+	"cyberpunk": {
+		{Name: "of Immolation", Status: "burning", StatusChance: 0.2},
+		{Name: "of Intrusion", Status: "hacked", StatusChance: 0.2},
+		{Name: "of Shorting", Status: "emp_stunned", StatusChance: 0.1},
+		{Name: "of Glitching", Status: "glitched", StatusChance: 0.25},
+		{Name: "of Flooding", Status: "wet", StatusChance: 0.3},
+		{Name: "of Fuel Lines", Status: "fuel_soaked", StatusChance: 0.3},
+		{Name: "of Cryo", Status: "frozen", StatusChance: 0.15},
+	},
+	"postapoc": {
+		{Name: "of Fallout", Status: "irradiated", StatusChance: 0.25},
+		{Name: "of Toxins", Status: "poisoned", StatusChance: 0.25},
+		{Name: "of Tetanus", Status: "bleeding", StatusChance: 0.3},
+		{Name: "of Blight", Status: "infected", StatusChance: 0.2},
+		{Name: "of Live Wire", Status: "shocked", StatusChance: 0.2},
+		{Name: "of Floodwater", Status: "wet", StatusChance: 0.3},
+		{Name: "of Diesel", Status: "oiled", StatusChance: 0.3},
+		{Name: "of Frostbite", Status: "frozen", StatusChance: 0.15},
+	},
+}
+
+// suffixes returns the suffix table for a genre, falling back to fantasy.
+func suffixes(genreID string) []Affix {
+	if s, ok := genreSuffixes[genreID]; ok {
+		return s
+	}
+	return genreSuffixes["fantasy"]
+}
+
+// scale returns a multiplier of 0 as 1 and strengthens the rest by
+// strength.
+func scale(mul, strength float64) float64 {
+	if mul == 0 {
+		return 1
+	}
+	return 1 + (mul-1)*strength
+}
+
+// apply returns the weapon with the affix's modifiers at strength.
+func (f Affix) apply(w Weapon, strength float64) Weapon {
+	w.Damage *= scale(f.Damage, strength)
+	w.FireRate *= scale(f.FireRate, strength)
+	w.SpreadAngle *= scale(f.Spread, strength)
+	w.Speed *= scale(f.Speed, strength)
+	if f.Splash > 0 {
+		w.Splash += f.Splash * strength
+	}
+	if f.Status != "" {
+		w.Status = f.Status
+		w.StatusChance = min(f.StatusChance*strength, 1)
+	}
+	return w
+}
+
+// Roll generates a weapon from seed for the genre: a stock weapon from
+// slot 1-6 as its base and a rarity, with no affixes for common weapons,
+// one for uncommon ones and a prefix and a suffix, growing stronger, for
+// the rest. The same seed and
+// genre always roll the same weapon. It returns the slot the weapon
+// belongs in.
+func Roll(seed uint64, genreID string) (int, Weapon) {
+	r := rng.NewRNG(seed)
+	stock := NewArsenal()
+	stock.SetGenre(genreID)
+
+	slot := 1 + r.Intn(len(stock.Weapons)-1)
+	w := stock.Weapons[slot]
+
+	rarity := RarityCommon
+	for roll := r.Intn(100); roll >= rarityWeights[rarity]; rarity++ {
+		roll -= rarityWeights[rarity]
+	}
+	w.Rarity = rarity
+	w.Seed = seed
+	w.Damage *= rarityDamage[rarity]
+
+	strength := rarityStrength[rarity]
+
+	var prefix, suffix *Affix
+	switch rarity {
+	case RarityUncommon:
+		if r.Intn(2) == 0 {
+			prefix = pickPrefix(r, w)
+		} else {
+			suffix = pickSuffix(r, genreID)
+		}
+	case RarityRare, RarityEpic, RarityLegendary:
+		prefix = pickPrefix(r, w)
+		suffix = pickSuffix(r, genreID)
+	}
+
+	name := w.Name
+	if prefix != nil {
+		w = prefix.apply(w, strength)
+		name = prefix.Name + " " + name
+	}
+	if suffix != nil {
+		w = suffix.apply(w, strength)
+		name += " " + suffix.Name
+	}
+	w.Name = name
+	return slot, w
+}
+
+// pickPrefix chooses a prefix that suits the weapon.
+func pickPrefix(r *rng.RNG, w Weapon) *Affix {
+	fits := make([]*Affix, 0, len(prefixes))
+	for i := range prefixes {
+		if !prefixes[i].Projectile || w.Projectile {
+			fits = append(fits, &prefixes[i])
+		}
+	}
+	return fits[r.Intn(len(fits))]
+}
+
+// pickSuffix chooses one of the genre's suffixes.
+func pickSuffix(r *rng.RNG, genreID string) *Affix {
+	s := suffixes(genreID)
+	return &s[r.Intn(len(s))]
+}
+
+// Equip puts w in slot with a full magazine, replacing the weapon there.
+func (a *Arsenal) Equip(slot int, w Weapon) bool {
+	if slot <= 0 || slot >= len(a.Weapons) {
+		return false
+	}
+	if a.Reloading() && a.reloadSlot == slot {
+		a.CancelReload()
+	}
+	a.Weapons[slot] = w
+	a.Clips[slot] = a.MagazineSize(slot)
+	return true
+}
@@ -0,0 +1,100 @@
+package weapon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRollDeterministic(t *testing.T) {
+	for seed := uint64(1); seed <= 50; seed++ {
+		slotA, a := Roll(seed, "scifi")
+		slotB, b := Roll(seed, "scifi")
+		if slotA != slotB || a != b {
+			t.Fatalf("seed %d rolled %+v in slot %d, then %+v in slot %d", seed, a, slotA, b, slotB)
+		}
+		if a.Seed != seed {
+			t.Errorf("seed %d rolled a weapon recording seed %d", seed, a.Seed)
+		}
+	}
+}
+
+func TestRollRarities(t *testing.T) {
+	counts := make(map[Rarity]int)
+	for seed := uint64(1); seed <= 2000; seed++ {
+		slot, w := Roll(seed, "fantasy")
+		counts[w.Rarity]++
+
+		stock := NewArsenal()
+		stock.SetGenre("fantasy")
+		base := stock.Weapons[slot]
+		if slot < 1 || slot > 6 {
+			t.Fatalf("seed %d rolled slot %d", seed, slot)
+		}
+		if !strings.Contains(w.Name, base.Name) {
+			t.Errorf("seed %d rolled %q, not named after its base %q", seed, w.Name, base.Name)
+		}
+		if w.Type != base.Type || w.AmmoType != base.AmmoType || w.ClipSize != base.ClipSize {
+			t.Errorf("seed %d rolled %+v, changing the base %+v", seed, w, base)
+		}
+
+		switch w.Rarity {
+		case RarityCommon:
+			if w.Name != base.Name || w.Damage != base.Damage || w.Status != "" {
+				t.Errorf("common roll %+v differs from its base %+v", w, base)
+			}
+		case RarityRare, RarityEpic, RarityLegendary:
+			if w.Status == "" || w.StatusChance <= 0 || !strings.Contains(w.Name, " of ") {
+				t.Errorf("%v roll %+v lacks a suffix", w.Rarity, w)
+			}
+		}
+		if !w.Projectile && (w.Speed != base.Speed || w.Splash != base.Splash) {
+			t.Errorf("seed %d gave %q projectile affixes", seed, w.Name)
+		}
+	}
+
+	for r := RarityCommon; r <= RarityLegendary; r++ {
+		if counts[r] == 0 {
+			t.Errorf("no %v weapons in 2000 rolls", r)
+		}
+	}
+	for r := RarityUncommon; r <= RarityLegendary; r++ {
+		if counts[r] > counts[r-1] || r.Value() <= (r-1).Value() {
+			t.Errorf("%s: %d rolls worth %d, want rarer and dearer than %s", r.Label(), counts[r], r.Value(), (r - 1).Label())
+		}
+	}
+}
+
+func TestRollGenreSuffixes(t *testing.T) {
+	statuses := map[string]bool{}
+	for _, f := range suffixes("cyberpunk") {
+		statuses[f.Status] = true
+	}
+	for seed := uint64(1); seed <= 500; seed++ {
+		_, w := Roll(seed, "cyberpunk")
+		if w.Status != "" && !statuses[w.Status] {
+			t.Errorf("cyberpunk roll %q inflicts %q", w.Name, w.Status)
+		}
+	}
+	if len(suffixes("unknown")) != len(genreSuffixes["fantasy"]) {
+		t.Error("unknown genre did not fall back to fantasy suffixes")
+	}
+}
+
+func TestEquip(t *testing.T) {
+	a := NewArsenal()
+	a.MagazineBonus[2] = 4
+	a.Clips[2] = 1
+
+	w := a.Weapons[2]
+	w.Name = "Brutal Shotgun"
+	w.Damage = 15
+	if !a.Equip(2, w) {
+		t.Fatal("Equip failed")
+	}
+	if a.Weapons[2] != w || a.Clips[2] != 12 {
+		t.Errorf("slot 2 = %+v with %d rounds, want the new weapon with 12", a.Weapons[2], a.Clips[2])
+	}
+	if a.Equip(0, w) || a.Equip(7, w) {
+		t.Error("Equip accepted the fist slot or an out of range slot")
+	}
+}
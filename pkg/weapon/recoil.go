@@ -0,0 +1,102 @@
+package weapon
+
+import (
+	"hash/fnv"
+	"math"
+
+	"github.com/opd-ai/violence/pkg/rng"
+)
+
+// Spread bloom and recoil tuning.
+const (
+	// bloomShots is how many shots of bloom a weapon's spread can build
+	// up to.
+	bloomShots = 8
+	// bloomDelay is how many updates after a shot spread starts to
+	// settle.
+	bloomDelay = 10
+	// bloomRecovery is the spread in degrees that settles per update.
+	bloomRecovery = 0.2
+	// recoilPatternLength is how many shots a recoil pattern runs before
+	// repeating.
+	recoilPatternLength = 12
+	// recoilSway scales a pattern's sideways drift against its climb.
+	recoilSway = 0.6
+)
+
+// recoilDrift returns the sideways drift, from -1 to 1, of the weapon's
+// recoil pattern at the given shot. The pattern is seeded from the
+// weapon's name, so a weapon kicks the same way every time and can be
+// learned.
+func recoilDrift(name string, shot int) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	r := rng.NewRNG(h.Sum64())
+
+	// A damped random walk drifts smoothly to one side and back.
+	drift := 0.0
+	for i := 0; i <= shot%recoilPatternLength; i++ {
+		drift = drift*0.7 + (r.Float64()*2-1)*0.6
+	}
+	return max(-1, min(1, drift))
+}
+
+// handling returns the multiplier on bloom and recoil from stance, skills
+// and mastery.
+func (a *Arsenal) handling() float64 {
+	if a.Handling <= 0 {
+		return 1
+	}
+	return a.Handling
+}
+
+// Spread returns the current weapon's spread bloom in degrees: the cone
+// the next shot may stray within.
+func (a *Arsenal) Spread() float64 {
+	return a.bloom
+}
+
+// TakeKick returns the recoil, in degrees of yaw and pitch, built up by
+// shots since the last call, and clears it.
+func (a *Arsenal) TakeKick() (yaw, pitch float64) {
+	yaw, pitch = a.kickYaw, a.kickPitch
+	a.kickYaw, a.kickPitch = 0, 0
+	return yaw, pitch
+}
+
+// kick applies a shot's recoil and spread bloom.
+func (a *Arsenal) kick(w Weapon) {
+	h := a.handling()
+	a.kickPitch += w.Recoil * h
+	a.kickYaw += w.Recoil * recoilSway * recoilDrift(w.Name, a.shots) * h
+	a.shots++
+	a.bloom = min(a.bloom+w.Bloom*h, w.Bloom*h*bloomShots)
+}
+
+// settle lets spread bloom recover over one update once the current
+// weapon has stopped firing, restarting the recoil pattern when the aim
+// has fully settled.
+func (a *Arsenal) settle() {
+	slot := a.CurrentSlot
+	if min(a.FramesSinceFire[slot], a.AltFramesSinceFire[slot]) < bloomDelay {
+		return
+	}
+	a.bloom = max(0, a.bloom-bloomRecovery)
+	if a.bloom == 0 {
+		a.shots = 0
+	}
+}
+
+// stray rotates the aim direction by a random angle within the current
+// spread bloom.
+func (a *Arsenal) stray(dirX, dirY float64) (float64, float64) {
+	if a.bloom <= 0 {
+		return dirX, dirY
+	}
+	if a.spreadRNG == nil {
+		a.spreadRNG = rng.NewRNG(42)
+	}
+	angle := (a.spreadRNG.Float64() - 0.5) * a.bloom * math.Pi / 180
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	return dirX*cos - dirY*sin, dirX*sin + dirY*cos
+}
@@ -0,0 +1,130 @@
+package weapon
+
+import (
+	"math"
+	"testing"
+)
+
+// settleFully updates the arsenal until the current weapon's bloom is gone.
+func settleFully(t *testing.T, a *Arsenal) {
+	t.Helper()
+	for i := 0; i < 1000 && a.Spread() > 0; i++ {
+		a.Update()
+	}
+	if a.Spread() != 0 {
+		t.Fatalf("spread still %v after 1000 updates", a.Spread())
+	}
+}
+
+func TestBloom(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(3) // Chaingun: 0.8 degrees per shot
+	w := a.Weapons[3]
+
+	// The first shot goes exactly where it is aimed.
+	var gotX, gotY float64
+	a.Fire(0, 0, 1, 0, func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64) {
+		gotX, gotY = dx, dy
+		return false, 0, 0, 0, 0
+	})
+	if gotX != 1 || gotY != 0 {
+		t.Errorf("first shot went (%v, %v), want (1, 0)", gotX, gotY)
+	}
+	if a.Spread() != w.Bloom {
+		t.Errorf("spread after one shot = %v, want %v", a.Spread(), w.Bloom)
+	}
+
+	// Bloom builds while firing, up to its cap, and holds while firing.
+	for i := 0; i < 40; i++ {
+		a.FramesSinceFire[3] = 1000
+		a.Fire(0, 0, 1, 0, hitAll)
+	}
+	if want := w.Bloom * bloomShots; math.Abs(a.Spread()-want) > 1e-9 {
+		t.Errorf("spread after sustained fire = %v, want the cap %v", a.Spread(), want)
+	}
+	a.FramesSinceFire[3] = 0
+	for i := 0; i < bloomDelay-1; i++ {
+		a.Update()
+	}
+	if want := w.Bloom * bloomShots; math.Abs(a.Spread()-want) > 1e-9 {
+		t.Errorf("spread recovered to %v before the delay", a.Spread())
+	}
+
+	settleFully(t, a)
+	if a.shots != 0 {
+		t.Errorf("recoil pattern at shot %d after settling, want 0", a.shots)
+	}
+}
+
+func TestBloomSwitchResets(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(1)
+	a.Fire(0, 0, 1, 0, hitAll)
+	if a.Spread() == 0 {
+		t.Fatal("pistol shot did not bloom")
+	}
+	a.SwitchTo(2)
+	if a.Spread() != 0 {
+		t.Errorf("spread after switching = %v, want 0", a.Spread())
+	}
+}
+
+func TestRecoilPattern(t *testing.T) {
+	kicks := func() []float64 {
+		a := NewArsenal()
+		a.SwitchTo(3)
+		var yaws []float64
+		for i := 0; i < recoilPatternLength; i++ {
+			a.FramesSinceFire[3] = 1000
+			a.Fire(0, 0, 1, 0, hitAll)
+			yaw, pitch := a.TakeKick()
+			if pitch != a.Weapons[3].Recoil {
+				t.Fatalf("shot %d kicked up %v, want %v", i, pitch, a.Weapons[3].Recoil)
+			}
+			yaws = append(yaws, yaw)
+		}
+		if yaw, pitch := a.TakeKick(); yaw != 0 || pitch != 0 {
+			t.Errorf("TakeKick left (%v, %v) after clearing", yaw, pitch)
+		}
+		return yaws
+	}
+
+	first, second := kicks(), kicks()
+	sideways := false
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("shot %d kicked %v, then %v", i, first[i], second[i])
+		}
+		if math.Abs(first[i]) > 0.6*recoilSway+1e-9 {
+			t.Errorf("shot %d kicked %v sideways, beyond the sway", i, first[i])
+		}
+		sideways = sideways || first[i] != 0
+	}
+	if !sideways {
+		t.Error("recoil pattern never drifted sideways")
+	}
+	if recoilDrift("Chaingun", 3) == recoilDrift("Pistol", 3) {
+		t.Error("different weapons share a recoil pattern")
+	}
+}
+
+func TestHandling(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(1)
+	a.Handling = 0.5
+	a.Fire(0, 0, 1, 0, hitAll)
+	if _, pitch := a.TakeKick(); pitch != 0.6 || a.Spread() != 0.75 {
+		t.Errorf("steadied shot kicked %v with %v spread, want 0.6 and 0.75", pitch, a.Spread())
+	}
+
+	// A foregrip steadies the weapon it is fitted to.
+	b := NewArsenal()
+	b.SwitchTo(1)
+	if _, ok := b.Attach(1, "under_grip"); !ok {
+		t.Fatal("foregrip did not fit the pistol")
+	}
+	b.Fire(0, 0, 1, 0, hitAll)
+	if _, pitch := b.TakeKick(); math.Abs(pitch-0.72) > 1e-9 || math.Abs(b.Spread()-0.9) > 1e-9 {
+		t.Errorf("gripped shot kicked %v with %v spread, want 0.72 and 0.9", pitch, b.Spread())
+	}
+}
@@ -4,6 +4,8 @@ package weapon
 import (
 	"math"
 	"math/rand"
+
+	"github.com/opd-ai/violence/pkg/rng"
 )
 
 // WeaponType defines the weapon firing mechanic.
@@ -45,7 +47,34 @@ type Weapon struct {
 	SpreadAngle float64 // Degrees; for shotgun multi-ray spread
 	RayCount    int     // Number of rays per shot (shotgun = 7, others = 1)
 	Range       float64 // Max distance; melee = 1.5, hitscan = 100
+	ReloadTime  float64 // Frames to refill the magazine (60 TPS)
 	Projectile  bool    // True if spawns projectile entity
+	Knockback   float64 // Shove force per hit; 1 = a pistol round, 0 = none
+	Alt         AltFire // Secondary fire; the zero value has none
+
+	// Projectile flight; used when Projectile is set.
+	Speed   float64 // Tiles per second
+	Gravity float64 // Drop in tiles per second squared; 0 flies straight
+	Splash  float64 // Explosion radius in tiles; 0 hits a single target
+
+	// Procedural roll; the zero values are a stock weapon.
+	Rarity       Rarity
+	Seed         uint64  // Seed the weapon was rolled from
+	Status       string  // Status effect inflicted on hit
+	StatusChance float64 // Chance per hit of inflicting Status
+
+	// Attachment behavior; the zero values change nothing.
+	Muffle  float64 // Share of the shot's noise suppressed, from 0 to 1
+	Bayonet float64 // Damage of a melee stab with the weapon; 0 has none
+
+	// Handling; the zero values shoot dead straight.
+	Recoil float64 // Degrees the aim kicks up per shot
+	Bloom  float64 // Degrees of spread each shot adds
+
+	// Loaded ammo; the zero values are standard rounds.
+	AmmoVariant string // ID of the ammo variant loaded
+	DamageType  string // Combat damage type dealt; "" is the ammo type's
+	Penetration int    // Extra targets each shot passes through
 }
 
 // AnimFrame represents a single animation frame with procedural parameters.
@@ -82,6 +111,44 @@ type Arsenal struct {
 	FramesSinceFire map[int]int    // Weapon slot -> cooldown counter
 	genre           string
 	Animator        *WeaponAnimator
+
+	// Reserve holds the spare rounds magazines are refilled from. Nil
+	// draws on Ammo.
+	Reserve Reserve
+	// MagazineBonus holds extra rounds per weapon slot from upgrades.
+	MagazineBonus map[int]int
+	// Attached holds the attachment IDs fitted to each mount per weapon
+	// slot; "" is an empty mount.
+	Attached map[int][MountCount]string
+	// Loaded holds the ammo variant ID loaded in each weapon slot; "" is
+	// standard rounds.
+	Loaded map[int]string
+
+	// AltFramesSinceFire is the alt fire cooldown counter per weapon slot.
+	AltFramesSinceFire map[int]int
+	altHeld            float64 // Frames alt fire has been held down
+
+	// Handling multiplies spread bloom and recoil, from stance, skills
+	// and mastery; 0 counts as 1.
+	Handling  float64
+	bloom     float64  // Current spread in degrees
+	shots     int      // Shots into the recoil pattern
+	kickYaw   float64  // Recoil not yet taken, in degrees
+	kickPitch float64  // Recoil not yet taken, in degrees
+	spreadRNG *rng.RNG // Rolls where shots stray within the spread
+
+	// Melee combo; recovery is the frames the last swing takes.
+	combo    int
+	recovery float64
+
+	// Reload in progress; reloadFrames is 0 when not reloading.
+	reloadSlot   int
+	reloadFrames float64
+
+	// Launch puts a projectile weapon's shot in flight along (dirX, dirY)
+	// from (posX, posY). When set, Fire routes projectile weapons through
+	// it instead of raycasting them.
+	Launch func(w Weapon, posX, posY, dirX, dirY float64)
 }
 
 // NewArsenal creates an empty arsenal with default weapons.
@@ -94,24 +161,28 @@ func NewArsenal() *Arsenal {
 		FramesSinceFire: make(map[int]int),
 		genre:           "fantasy",
 		Animator:        NewWeaponAnimator(42),
+		MagazineBonus:   make(map[int]int),
+
+		AltFramesSinceFire: make(map[int]int),
 	}
 	a.loadDefaultWeapons()
 	// Initialize cooldowns to allow immediate fire
 	for i := range a.Weapons {
 		a.FramesSinceFire[i] = 1000
+		a.AltFramesSinceFire[i] = 1000
 	}
 	return a
 }
 
 // loadDefaultWeapons initializes the 7-weapon loadout.
 func (a *Arsenal) loadDefaultWeapons() {
-	a.Weapons[0] = Weapon{Name: "Fist", Type: TypeMelee, Damage: 10, FireRate: 20, Range: 1.2, RayCount: 1}
-	a.Weapons[1] = Weapon{Name: "Pistol", Type: TypeHitscan, Damage: 15, FireRate: 15, AmmoType: "bullets", ClipSize: 12, Range: 100, RayCount: 1}
-	a.Weapons[2] = Weapon{Name: "Shotgun", Type: TypeHitscan, Damage: 10, FireRate: 30, AmmoType: "shells", ClipSize: 8, SpreadAngle: 10, RayCount: 7, Range: 30}
-	a.Weapons[3] = Weapon{Name: "Chaingun", Type: TypeHitscan, Damage: 12, FireRate: 5, AmmoType: "bullets", ClipSize: 100, Range: 100, RayCount: 1}
-	a.Weapons[4] = Weapon{Name: "Rocket Launcher", Type: TypeProjectile, Damage: 100, FireRate: 45, AmmoType: "rockets", ClipSize: 5, Range: 200, RayCount: 1, Projectile: true}
-	a.Weapons[5] = Weapon{Name: "Plasma Gun", Type: TypeProjectile, Damage: 40, FireRate: 10, AmmoType: "cells", ClipSize: 40, Range: 150, RayCount: 1, Projectile: true}
-	a.Weapons[6] = Weapon{Name: "Knife", Type: TypeMelee, Damage: 25, FireRate: 18, Range: 1.5, RayCount: 1}
+	a.Weapons[0] = Weapon{Name: "Fist", Type: TypeMelee, Damage: 10, FireRate: 20, Range: 1.2, RayCount: 1, Knockback: 1.5}
+	a.Weapons[1] = Weapon{Name: "Pistol", Type: TypeHitscan, Damage: 15, FireRate: 15, AmmoType: "bullets", ClipSize: 12, ReloadTime: 60, Range: 100, RayCount: 1, Knockback: 1, Recoil: 1.2, Bloom: 1.5, Alt: AltFire{Mode: AltSlug, Cooldown: 45, AmmoCost: 2, Damage: 2.5, Zoom: 2}}
+	a.Weapons[2] = Weapon{Name: "Shotgun", Type: TypeHitscan, Damage: 10, FireRate: 30, AmmoType: "shells", ClipSize: 8, ReloadTime: 90, SpreadAngle: 10, RayCount: 7, Range: 30, Knockback: 0.8, Recoil: 4, Bloom: 2, Alt: AltFire{Mode: AltGrenade, Cooldown: 60, AmmoCost: 2, Damage: 5, Speed: 10, Gravity: 3, Splash: 2}}
+	a.Weapons[3] = Weapon{Name: "Chaingun", Type: TypeHitscan, Damage: 12, FireRate: 5, AmmoType: "bullets", ClipSize: 100, ReloadTime: 120, Range: 100, RayCount: 1, Knockback: 0.5, Recoil: 0.6, Bloom: 0.8, Alt: AltFire{Mode: AltBurst, Cooldown: 30, AmmoCost: 5, Rounds: 5, Spread: 4}}
+	a.Weapons[4] = Weapon{Name: "Rocket Launcher", Type: TypeProjectile, Damage: 100, FireRate: 45, AmmoType: "rockets", ClipSize: 5, ReloadTime: 100, Range: 200, RayCount: 1, Projectile: true, Knockback: 4, Recoil: 3, Speed: 14, Splash: 2.5}
+	a.Weapons[5] = Weapon{Name: "Plasma Gun", Type: TypeProjectile, Damage: 40, FireRate: 10, AmmoType: "cells", ClipSize: 40, ReloadTime: 80, Range: 150, RayCount: 1, Projectile: true, Knockback: 1.5, Recoil: 0.5, Bloom: 1, Speed: 24, Alt: AltFire{Mode: AltCharge, Cooldown: 45, AmmoCost: 5, Damage: 3, ChargeTime: 90}}
+	a.Weapons[6] = Weapon{Name: "Knife", Type: TypeMelee, Damage: 25, FireRate: 18, Range: 1.5, RayCount: 1, Knockback: 1, Alt: AltFire{Mode: AltCharge, Cooldown: 30, Damage: 2.5, ChargeTime: 45}}
 
 	// Initialize ammo pools
 	a.Ammo["bullets"] = 50
@@ -125,6 +196,46 @@ func (a *Arsenal) loadDefaultWeapons() {
 	}
 }
 
+// Reserve is a store of spare ammunition by type, such as an ammo.Pool.
+type Reserve interface {
+	Get(ammoType string) int
+	Consume(ammoType string, amount int) bool
+	Add(ammoType string, amount int)
+}
+
+// ammoMap adapts the Arsenal's own Ammo map to a Reserve.
+type ammoMap map[string]int
+
+func (m ammoMap) Get(ammoType string) int {
+	return m[ammoType]
+}
+
+func (m ammoMap) Consume(ammoType string, amount int) bool {
+	if m[ammoType] < amount {
+		return false
+	}
+	m[ammoType] -= amount
+	return true
+}
+
+func (m ammoMap) Add(ammoType string, amount int) {
+	m[ammoType] += amount
+}
+
+// reserve returns the store magazines are refilled from.
+func (a *Arsenal) reserve() Reserve {
+	if a.Reserve != nil {
+		return a.Reserve
+	}
+	return ammoMap(a.Ammo)
+}
+
+// MagazineSize returns how many rounds the weapon in slot holds, including
+// upgrades.
+func (a *Arsenal) MagazineSize(slot int) int {
+	return a.weapon(slot).ClipSize + a.MagazineBonus[slot]
+}
+
 // HitResult contains the result of a weapon firing.
 type HitResult struct {
 	Hit      bool
@@ -136,11 +247,14 @@ type HitResult struct {
 }
 
 // Fire discharges the current weapon.
-// Returns hit results for each ray cast (shotgun = 7, others = 1).
+// Returns hit results for each ray cast (shotgun = 7, others = 1). Shots
+// handed to Launch have no result yet, so a launched volley returns an
+// empty, non-nil slice. Firing a loaded weapon interrupts its reload;
+// pulling the trigger on an empty magazine starts one.
 // posX, posY: shooter position; dirX, dirY: aim direction normalized.
 // raycast: function that casts a ray and returns (hit, distance, hitX, hitY, entityID).
 func (a *Arsenal) Fire(posX, posY, dirX, dirY float64, raycast func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64)) []HitResult {
-	weapon := a.Weapons[a.CurrentSlot]
+	weapon := a.weapon(a.CurrentSlot)
 
 	// Check cooldown
 	if a.FramesSinceFire[a.CurrentSlot] < int(weapon.FireRate) {
@@ -150,8 +264,10 @@ func (a *Arsenal) Fire(posX, posY, dirX, dirY float64, raycast func(x, y, dx, dy
 	// Check ammo for non-melee
 	if weapon.Type != TypeMelee {
 		if a.Clips[a.CurrentSlot] <= 0 {
+			a.StartReload()
 			return nil // Out of ammo
 		}
+		a.CancelReload()
 		a.Clips[a.CurrentSlot]--
 	}
 
@@ -163,6 +279,13 @@ func (a *Arsenal) Fire(posX, posY, dirX, dirY float64, raycast func(x, y, dx, dy
 		a.Animator.SetState(AnimFire)
 	}
 
+	dirX, dirY = a.stray(dirX, dirY)
+	a.kick(weapon)
+	return a.discharge(weapon, posX, posY, dirX, dirY, raycast)
+}
+
+// discharge casts or launches each ray of a shot from the weapon.
+func (a *Arsenal) discharge(weapon Weapon, posX, posY, dirX, dirY float64, raycast func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64)) []HitResult {
 	results := make([]HitResult, 0, weapon.RayCount)
 
 	for i := 0; i < weapon.RayCount; i++ {
@@ -179,6 +302,11 @@ func (a *Arsenal) Fire(posX, posY, dirX, dirY float64, raycast func(x, y, dx, dy
 		rayDirX := dirX*cos - dirY*sin
 		rayDirY := dirX*sin + dirY*cos
 
+		if weapon.Projectile && a.Launch != nil {
+			a.Launch(weapon, posX, posY, rayDirX, rayDirY)
+			continue
+		}
+
 		// Cast ray
 		hit, dist, hitX, hitY, entityID := raycast(posX, posY, rayDirX, rayDirY, weapon.Range)
 
@@ -200,45 +328,81 @@ func (a *Arsenal) Fire(posX, posY, dirX, dirY float64, raycast func(x, y, dx, dy
 	return results
 }
 
-// Reload reloads the current weapon from the ammo pool.
+// Reload instantly reloads the current weapon from the reserve.
 func (a *Arsenal) Reload() bool {
-	weapon := a.Weapons[a.CurrentSlot]
-
-	// Melee weapons don't reload
-	if weapon.Type == TypeMelee {
+	if !a.refill(a.CurrentSlot) {
 		return false
 	}
 
-	// Already full
-	if a.Clips[a.CurrentSlot] >= weapon.ClipSize {
-		return false
+	// Trigger reload animation
+	if a.Animator != nil {
+		a.Animator.SetState(AnimReload)
 	}
 
-	// Check ammo pool
-	available := a.Ammo[weapon.AmmoType]
-	if available <= 0 {
+	return true
+}
+
+// canReload reports whether the weapon in slot has room in its magazine
+// and rounds in reserve to fill it with.
+func (a *Arsenal) canReload(slot int) bool {
+	weapon := a.Weapons[slot]
+	return weapon.Type != TypeMelee && a.Clips[slot] < a.MagazineSize(slot) && a.reserve().Get(a.ReservePool(slot)) > 0
+}
+
+// refill moves as many rounds from the reserve into the magazine of the
+// weapon in slot as fit.
+func (a *Arsenal) refill(slot int) bool {
+	if !a.canReload(slot) {
 		return false
 	}
-
-	// Calculate ammo needed
-	needed := weapon.ClipSize - a.Clips[a.CurrentSlot]
-	toReload := needed
-	if available < needed {
-		toReload = available
+	pool := a.ReservePool(slot)
+	toReload := min(a.MagazineSize(slot)-a.Clips[slot], a.reserve().Get(pool))
+	if !a.reserve().Consume(pool, toReload) {
+		return false
 	}
+	a.Clips[slot] += toReload
+	return true
+}
 
-	// Transfer ammo from pool to clip
-	a.Clips[a.CurrentSlot] += toReload
-	a.Ammo[weapon.AmmoType] -= toReload
-
-	// Trigger reload animation
+// StartReload begins refilling the current weapon's magazine, which
+// finishes after its ReloadTime of updates. It reports false when the
+// magazine is full, there are no rounds in reserve or a reload is already
+// under way.
+func (a *Arsenal) StartReload() bool {
+	weapon := a.weapon(a.CurrentSlot)
+	if a.Reloading() || !a.canReload(a.CurrentSlot) {
+		return false
+	}
+	if weapon.ReloadTime <= 0 {
+		return a.Reload()
+	}
+	a.reloadSlot = a.CurrentSlot
+	a.reloadFrames = weapon.ReloadTime
 	if a.Animator != nil {
 		a.Animator.SetState(AnimReload)
 	}
-
 	return true
 }
 
+// Reloading reports whether a reload is under way.
+func (a *Arsenal) Reloading() bool {
+	return a.reloadFrames > 0
+}
+
+// ReloadProgress returns how far the reload under way has got, from 0 to
+// 1, or 0 when not reloading.
+func (a *Arsenal) ReloadProgress() float64 {
+	if !a.Reloading() {
+		return 0
+	}
+	return 1 - a.reloadFrames/a.weapon(a.reloadSlot).ReloadTime
+}
+
+// CancelReload abandons the reload under way without loading any rounds.
+func (a *Arsenal) CancelReload() {
+	a.reloadFrames = 0
+}
+
 // SwitchTo changes the active weapon slot (0-6).
 func (a *Arsenal) SwitchTo(slot int) bool {
 	if slot < 0 || slot >= len(a.Weapons) {
@@ -246,8 +410,14 @@ func (a *Arsenal) SwitchTo(slot int) bool {
 	}
 
 	// Trigger lower animation for current weapon, then raise for new
-	if a.Animator != nil && slot != a.CurrentSlot {
-		a.Animator.SetState(AnimLower)
+	if slot != a.CurrentSlot {
+		a.CancelReload()
+		a.altHeld = 0
+		a.bloom, a.shots = 0, 0
+		a.combo, a.recovery = 0, 0
+		if a.Animator != nil {
+			a.Animator.SetState(AnimLower)
+		}
 	}
 
 	a.CurrentSlot = slot
@@ -260,11 +430,24 @@ func (a *Arsenal) SwitchTo(slot int) bool {
 	return true
 }
 
-// Update increments frame counters for cooldown tracking and animations.
+// Update increments frame counters for cooldown tracking and animations,
+// lets spread bloom recover and finishes a reload once its time is up.
 func (a *Arsenal) Update() {
 	for i := range a.FramesSinceFire {
 		a.FramesSinceFire[i]++
 	}
+	for i := range a.AltFramesSinceFire {
+		a.AltFramesSinceFire[i]++
+	}
+	a.settle()
+
+	if a.Reloading() {
+		a.reloadFrames--
+		if a.reloadFrames <= 0 {
+			a.reloadFrames = 0
+			a.refill(a.reloadSlot)
+		}
+	}
 
 	// Update weapon animation
 	if a.Animator != nil {
@@ -272,9 +455,9 @@ func (a *Arsenal) Update() {
 	}
 }
 
-// GetCurrentWeapon returns the active weapon.
+// GetCurrentWeapon returns the active weapon with its attachments fitted.
 func (a *Arsenal) GetCurrentWeapon() Weapon {
-	return a.Weapons[a.CurrentSlot]
+	return a.weapon(a.CurrentSlot)
 }
 
 // AddAmmo adds ammo to the pool.
@@ -282,10 +465,20 @@ func (a *Arsenal) AddAmmo(ammoType string, amount int) {
 	a.Ammo[ammoType] += amount
 }
 
+// lobGravity is the drop of lobbed launcher shots, in tiles per second
+// squared.
+const lobGravity = 3.0
+
 // SetGenre configures weapon names and visuals for a genre.
 func (a *Arsenal) SetGenre(genreID string) {
 	a.genre = genreID
 	a.applyGenreNames()
+
+	// Improvised launchers and thrown orbs lob their shots in an arc.
+	a.Weapons[4].Gravity = 0
+	if genreID == "postapoc" || genreID == "fantasy" {
+		a.Weapons[4].Gravity = lobGravity
+	}
 }
 
 // applyGenreNames remaps weapon names per genre.
@@ -262,6 +262,108 @@ func TestReloadMelee(t *testing.T) {
 	}
 }
 
+// testReserve is a Reserve outside the arsenal.
+type testReserve map[string]int
+
+func (r testReserve) Get(ammoType string) int { return r[ammoType] }
+
+func (r testReserve) Consume(ammoType string, amount int) bool {
+	if r[ammoType] < amount {
+		return false
+	}
+	r[ammoType] -= amount
+	return true
+}
+
+func (r testReserve) Add(ammoType string, amount int) { r[ammoType] += amount }
+
+func TestTimedReload(t *testing.T) {
+	a := NewArsenal()
+	reserve := testReserve{"bullets": 30}
+	a.Reserve = reserve
+	a.SwitchTo(1) // Pistol, ClipSize=12, ReloadTime=60
+	a.Clips[1] = 2
+
+	if !a.StartReload() {
+		t.Fatal("StartReload failed with room in the magazine")
+	}
+	if a.StartReload() {
+		t.Error("StartReload restarted a reload under way")
+	}
+	for i := 0; i < 30; i++ {
+		a.Update()
+	}
+	if got := a.ReloadProgress(); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("ReloadProgress halfway = %v, want 0.5", got)
+	}
+	if a.Clips[1] != 2 || reserve["bullets"] != 30 {
+		t.Errorf("rounds moved before the reload finished: clip %d, reserve %d", a.Clips[1], reserve["bullets"])
+	}
+	for i := 0; i < 30; i++ {
+		a.Update()
+	}
+	if a.Reloading() || a.ReloadProgress() != 0 {
+		t.Error("still reloading after ReloadTime")
+	}
+	if a.Clips[1] != 12 || reserve["bullets"] != 20 {
+		t.Errorf("after reload clip = %d, reserve = %d, want 12 and 20", a.Clips[1], reserve["bullets"])
+	}
+	if a.StartReload() {
+		t.Error("StartReload with a full magazine")
+	}
+}
+
+func TestReloadInterrupted(t *testing.T) {
+	a := NewArsenal()
+	a.Ammo["bullets"] = 30
+	a.SwitchTo(1)
+	a.Clips[1] = 2
+	raycast := func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64) {
+		return false, 0, 0, 0, 0
+	}
+
+	a.StartReload()
+	if a.Fire(0, 0, 1, 0, raycast) == nil || a.Reloading() {
+		t.Error("firing a loaded weapon did not interrupt its reload")
+	}
+
+	a.StartReload()
+	a.SwitchTo(3)
+	if a.Reloading() {
+		t.Error("switching weapons did not cancel the reload")
+	}
+	for i := 0; i < 60; i++ {
+		a.Update()
+	}
+	if a.Clips[1] != 1 || a.Ammo["bullets"] != 30 {
+		t.Errorf("cancelled reload moved rounds: clip %d, reserve %d", a.Clips[1], a.Ammo["bullets"])
+	}
+
+	// Pulling the trigger on an empty magazine starts a reload.
+	a.SwitchTo(1)
+	a.Clips[1] = 0
+	for i := 0; i < 20; i++ {
+		a.Update()
+	}
+	if a.Fire(0, 0, 1, 0, raycast) != nil || !a.Reloading() {
+		t.Error("dry fire did not start a reload")
+	}
+}
+
+func TestMagazineBonus(t *testing.T) {
+	a := NewArsenal()
+	a.Ammo["bullets"] = 100
+	a.SwitchTo(1)
+	a.MagazineBonus[1] = 5
+
+	if got := a.MagazineSize(1); got != 17 {
+		t.Errorf("MagazineSize = %d, want 17", got)
+	}
+	if !a.Reload() || a.Clips[1] != 17 || a.Ammo["bullets"] != 95 {
+		t.Errorf("reload with bonus: clip %d, reserve %d, want 17 and 95", a.Clips[1], a.Ammo["bullets"])
+	}
+}
+
 func TestSwitchWeapon(t *testing.T) {
 	a := NewArsenal()
 
@@ -399,6 +501,61 @@ func TestFireProjectileCooldown(t *testing.T) {
 	}
 }
 
+func TestFireLaunchesProjectiles(t *testing.T) {
+	a := NewArsenal()
+	a.SwitchTo(4) // Rocket Launcher
+
+	var launched []Weapon
+	a.Launch = func(w Weapon, posX, posY, dirX, dirY float64) {
+		if posX != 2 || posY != 3 || dirX != 1 || dirY != 0 {
+			t.Errorf("launched from (%v, %v) along (%v, %v), want from (2, 3) along (1, 0)", posX, posY, dirX, dirY)
+		}
+		launched = append(launched, w)
+	}
+	raycast := func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64) {
+		t.Error("projectile weapon raycast its shot")
+		return false, 0, 0, 0, 0
+	}
+
+	results := a.Fire(2, 3, 1, 0, raycast)
+	if results == nil || len(results) != 0 {
+		t.Errorf("Fire returned %v, want an empty volley", results)
+	}
+	if len(launched) != 1 || launched[0].Speed <= 0 || launched[0].Splash <= 0 {
+		t.Errorf("launched %+v, want one explosive rocket", launched)
+	}
+	if a.Clips[4] != 4 {
+		t.Errorf("clip after launch = %d, want 4", a.Clips[4])
+	}
+
+	// Hitscan weapons still raycast with a launcher set.
+	a.SwitchTo(1)
+	hits := a.Fire(2, 3, 1, 0, func(x, y, dx, dy, maxDist float64) (bool, float64, float64, float64, uint64) {
+		return true, 5, 7, 3, 1
+	})
+	if len(hits) != 1 || len(launched) != 1 {
+		t.Errorf("pistol fire = %v with %d launches, want one raycast hit", hits, len(launched))
+	}
+}
+
+func TestGenreLobbedLauncher(t *testing.T) {
+	a := NewArsenal()
+	for _, tt := range []struct {
+		genre string
+		lobs  bool
+	}{
+		{"postapoc", true},
+		{"scifi", false},
+		{"fantasy", true},
+		{"cyberpunk", false},
+	} {
+		a.SetGenre(tt.genre)
+		if lobs := a.Weapons[4].Gravity > 0; lobs != tt.lobs {
+			t.Errorf("%s launcher lobs = %v, want %v", tt.genre, lobs, tt.lobs)
+		}
+	}
+}
+
 func TestGetCurrentWeapon(t *testing.T) {
 	a := NewArsenal()
 	a.SwitchTo(3)
@@ -115,6 +115,11 @@ func (w *WeatherSystem) SetWind(x, y float64) {
 	w.windY = y
 }
 
+// Wind returns the wind direction and strength.
+func (w *WeatherSystem) Wind() (float64, float64) {
+	return w.windX, w.windY
+}
+
 // SetCamera updates the camera position for particle spawning bounds.
 func (w *WeatherSystem) SetCamera(x, y, width, height float64) {
 	w.cameraX = x
@@ -68,8 +68,8 @@ func TestSetWeather(t *testing.T) {
 func TestSetWind(t *testing.T) {
 	ws := NewWeatherSystem(500, 42)
 	ws.SetWind(10.0, 20.0)
-	if ws.windX != 10.0 || ws.windY != 20.0 {
-		t.Errorf("wind = (%f, %f), want (10.0, 20.0)", ws.windX, ws.windY)
+	if x, y := ws.Wind(); x != 10.0 || y != 20.0 {
+		t.Errorf("Wind() = (%f, %f), want (10.0, 20.0)", x, y)
 	}
 }
 